@@ -0,0 +1,209 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"go-hep.org/x/hep/groot"
+	"go-hep.org/x/hep/groot/rhist"
+	"go-hep.org/x/hep/groot/riofs"
+	_ "go-hep.org/x/hep/groot/riofs/plugin/http"
+	_ "go-hep.org/x/hep/groot/riofs/plugin/xrootd"
+	"go-hep.org/x/hep/groot/rtree"
+)
+
+// Config describes the checks to run over a dataset.
+type Config struct {
+	Files []FileCheck `json:"files"`
+}
+
+// FileCheck describes the checks to run over a set of files.
+type FileCheck struct {
+	Name  string      `json:"name"`            // file name or glob pattern
+	Trees []TreeCheck `json:"trees,omitempty"` // trees to check
+	Hists []string    `json:"hists,omitempty"` // histograms that must exist and be non-empty
+}
+
+// TreeCheck describes the checks to run over a tree.
+type TreeCheck struct {
+	Name       string   `json:"name"`                  // path to the tree in the file
+	Branches   []string `json:"branches,omitempty"`    // branches the tree must have
+	Entries    *int64   `json:"entries,omitempty"`     // exact number of entries, if any
+	MinEntries int64    `json:"min-entries,omitempty"` // minimal number of entries
+	Weights    []string `json:"weights,omitempty"`     // branches whose values must be finite
+}
+
+func (cfg Config) validate() *Report {
+	rep := new(Report)
+	for _, fc := range cfg.Files {
+		fc.validate(rep)
+	}
+	return rep
+}
+
+func (fc FileCheck) validate(rep *Report) {
+	fnames := []string{fc.Name}
+	if !strings.Contains(fc.Name, "://") {
+		matches, err := filepath.Glob(fc.Name)
+		if err != nil {
+			rep.fail(fc.Name, "glob", time.Now(), "invalid file pattern: %+v", err)
+			return
+		}
+		if len(matches) > 0 {
+			fnames = matches
+		}
+	}
+
+	for _, fname := range fnames {
+		fc.validateFile(rep, fname)
+	}
+}
+
+func (fc FileCheck) validateFile(rep *Report, fname string) {
+	start := time.Now()
+	f, err := groot.Open(fname)
+	if err != nil {
+		rep.fail(fname, "open", start, "could not open file: %+v", err)
+		return
+	}
+	defer f.Close()
+	rep.pass(fname, "open", start)
+
+	dir := riofs.Dir(f)
+	for _, tc := range fc.Trees {
+		tc.validate(rep, fname, dir)
+	}
+
+	for _, name := range fc.Hists {
+		start := time.Now()
+		check := "hist:" + name
+		obj, err := dir.Get(name)
+		if err != nil {
+			rep.fail(fname, check, start, "could not retrieve histogram: %+v", err)
+			continue
+		}
+		var n float64
+		switch h := obj.(type) {
+		case rhist.H1:
+			n = h.Entries()
+		case rhist.H2:
+			n = h.Entries()
+		default:
+			rep.fail(fname, check, start, "object is not a histogram (type=%T)", obj)
+			continue
+		}
+		if n <= 0 {
+			rep.fail(fname, check, start, "histogram is empty")
+			continue
+		}
+		rep.pass(fname, check, start)
+	}
+}
+
+func (tc TreeCheck) validate(rep *Report, fname string, dir riofs.Directory) {
+	start := time.Now()
+	check := "tree:" + tc.Name
+	obj, err := dir.Get(tc.Name)
+	if err != nil {
+		rep.fail(fname, check, start, "could not retrieve tree: %+v", err)
+		return
+	}
+	tree, ok := obj.(rtree.Tree)
+	if !ok {
+		rep.fail(fname, check, start, "object is not a tree (type=%T)", obj)
+		return
+	}
+	rep.pass(fname, check, start)
+
+	start = time.Now()
+	var missing []string
+	for _, name := range tc.Branches {
+		if tree.Branch(name) == nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		rep.fail(fname, check+":branches", start, "missing branches: %q", missing)
+	} else {
+		rep.pass(fname, check+":branches", start)
+	}
+
+	start = time.Now()
+	n := tree.Entries()
+	switch {
+	case tc.Entries != nil && n != *tc.Entries:
+		rep.fail(fname, check+":entries", start, "invalid number of entries (got=%d, want=%d)", n, *tc.Entries)
+	case n < tc.MinEntries:
+		rep.fail(fname, check+":entries", start, "not enough entries (got=%d, want>=%d)", n, tc.MinEntries)
+	default:
+		rep.pass(fname, check+":entries", start)
+	}
+
+	if len(tc.Weights) == 0 {
+		return
+	}
+
+	start = time.Now()
+	err = checkFinite(tree, tc.Weights)
+	if err != nil {
+		rep.fail(fname, check+":weights", start, "%+v", err)
+		return
+	}
+	rep.pass(fname, check+":weights", start)
+}
+
+// checkFinite checks that all the values held by the named branches are finite.
+func checkFinite(tree rtree.Tree, names []string) error {
+	want := make(map[string]bool, len(names))
+	for _, name := range names {
+		if tree.Branch(name) == nil {
+			return fmt.Errorf("missing weight branch %q", name)
+		}
+		want[name] = true
+	}
+
+	var rvars []rtree.ReadVar
+	for _, rv := range rtree.NewReadVars(tree) {
+		if want[rv.Name] {
+			rvars = append(rvars, rv)
+		}
+	}
+
+	r, err := rtree.NewReader(tree, rvars)
+	if err != nil {
+		return fmt.Errorf("could not create tree reader: %w", err)
+	}
+	defer r.Close()
+
+	return r.Read(func(ctx rtree.RCtx) error {
+		for _, rv := range rvars {
+			if !isFinite(reflect.ValueOf(rv.Value).Elem()) {
+				return fmt.Errorf("non-finite value for branch %q at entry %d", rv.Name, ctx.Entry)
+			}
+		}
+		return nil
+	})
+}
+
+func isFinite(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v := rv.Float()
+		return !math.IsNaN(v) && !math.IsInf(v, 0)
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			if !isFinite(rv.Index(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
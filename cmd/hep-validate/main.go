@@ -0,0 +1,143 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// hep-validate runs a configurable set of sanity checks over a dataset of
+// ROOT files and emits a JSON or JUnit report.
+//
+// The checks are described in a JSON configuration file:
+//
+//  {
+//    "files": [
+//      {
+//        "name": "data/run-*.root",
+//        "trees": [
+//          {
+//            "name": "events",
+//            "branches": ["pt", "eta", "weight"],
+//            "min-entries": 1000,
+//            "weights": ["weight"]
+//          }
+//        ],
+//        "hists": ["dir/h_pt"]
+//      }
+//    ]
+//  }
+//
+// For each file matching the "name" pattern, hep-validate checks that:
+//  - the file can be opened,
+//  - each tree exists, has the expected branches and number of entries,
+//  - the "weights" branches only hold finite values,
+//  - each histogram exists and is not empty.
+//
+// Usage: hep-validate [options] config.json
+//
+// Example:
+//
+//  $> hep-validate ./config.json
+//  $> hep-validate -format=junit -o report.xml ./config.json
+//
+// hep-validate exits with a non-zero status if any check failed.
+package main // import "go-hep.org/x/hep/cmd/hep-validate"
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	log.SetPrefix("hep-validate: ")
+	log.SetFlags(0)
+
+	flag.Usage = func() {
+		fmt.Fprintf(
+			os.Stderr,
+			`Usage: hep-validate [options] config.json
+
+ex:
+ $> hep-validate ./config.json
+ $> hep-validate -format=junit -o report.xml ./config.json
+
+options:
+`,
+		)
+		flag.PrintDefaults()
+	}
+
+	var (
+		format = flag.String("format", "json", "report format (json, junit)")
+		oname  = flag.String("o", "", "path to output report file (default: stdout)")
+	)
+
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		log.Fatalf("missing input configuration file")
+	}
+
+	ok, err := run(*oname, flag.Arg(0), *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func run(oname, cname, format string) (bool, error) {
+	cfg, err := loadConfig(cname)
+	if err != nil {
+		return false, err
+	}
+
+	rep := cfg.validate()
+
+	if oname == "" {
+		err = rep.write(os.Stdout, format)
+		if err != nil {
+			return false, fmt.Errorf("could not write report: %w", err)
+		}
+		return rep.ok(), nil
+	}
+
+	f, err := os.Create(oname)
+	if err != nil {
+		return false, fmt.Errorf("could not create output report: %w", err)
+	}
+	defer f.Close()
+
+	err = rep.write(f, format)
+	if err != nil {
+		return false, fmt.Errorf("could not write report: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return false, fmt.Errorf("could not close output report: %w", err)
+	}
+
+	return rep.ok(), nil
+}
+
+func loadConfig(fname string) (Config, error) {
+	var cfg Config
+
+	f, err := os.Open(fname)
+	if err != nil {
+		return cfg, fmt.Errorf("could not open configuration file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("could not decode configuration file %q: %w", fname, err)
+	}
+
+	return cfg, nil
+}
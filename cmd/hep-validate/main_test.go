@@ -0,0 +1,166 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main // import "go-hep.org/x/hep/cmd/hep-validate"
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go-hep.org/x/hep/groot"
+	"go-hep.org/x/hep/groot/rtree"
+)
+
+func TestValidate(t *testing.T) {
+	cfg, err := loadConfig("testdata/config.json")
+	if err != nil {
+		t.Fatalf("could not load config: %+v", err)
+	}
+
+	rep := cfg.validate()
+
+	type result struct {
+		file  string
+		check string
+		pass  bool
+	}
+
+	const (
+		simple = "../../groot/testdata/simple.root"
+		dirs   = "../../groot/testdata/dirs-6.14.00.root"
+	)
+
+	want := []result{
+		{simple, "open", true},
+		{simple, "tree:tree", true},
+		{simple, "tree:tree:branches", true},
+		{simple, "tree:tree:entries", true},
+		{simple, "tree:tree:weights", true},
+		{simple, "tree:tree", true},
+		{simple, "tree:tree:branches", false},
+		{simple, "tree:tree:entries", false},
+		{dirs, "open", true},
+		{dirs, "hist:dir1/dir11/h1", true},
+		{dirs, "hist:dir2", false},
+		{"testdata/not-there.root", "open", false},
+	}
+
+	got := make([]result, len(rep.Results))
+	for i, res := range rep.Results {
+		got[i] = result{res.File, res.Check, res.Pass}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid results:\ngot= %v\nwant=%v", got, want)
+	}
+
+	if rep.ok() {
+		t.Fatalf("report should have failed")
+	}
+
+	if got, want := rep.failures(), 4; got != want {
+		t.Fatalf("invalid number of failures: got=%d, want=%d", got, want)
+	}
+
+	var buf bytes.Buffer
+	err = rep.write(&buf, "junit")
+	if err != nil {
+		t.Fatalf("could not write JUnit report: %+v", err)
+	}
+
+	var suites junitSuites
+	err = xml.Unmarshal(buf.Bytes(), &suites)
+	if err != nil {
+		t.Fatalf("could not decode JUnit report: %+v", err)
+	}
+
+	if got, want := len(suites.Suites), 3; got != want {
+		t.Fatalf("invalid number of test suites: got=%d, want=%d", got, want)
+	}
+	if got, want := suites.Tests, len(want); got != want {
+		t.Fatalf("invalid number of tests: got=%d, want=%d", got, want)
+	}
+	if got, want := suites.Failures, 4; got != want {
+		t.Fatalf("invalid number of failures: got=%d, want=%d", got, want)
+	}
+
+	buf.Reset()
+	err = rep.write(&buf, "json")
+	if err != nil {
+		t.Fatalf("could not write JSON report: %+v", err)
+	}
+
+	err = rep.write(&buf, "txt")
+	if err == nil {
+		t.Fatalf("expected an error for an invalid report format")
+	}
+}
+
+func TestNonFiniteWeights(t *testing.T) {
+	tmp, err := os.MkdirTemp("", "hep-validate-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	fname := filepath.Join(tmp, "weights.root")
+	func() {
+		f, err := groot.Create(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		var w float64
+		tree, err := rtree.NewWriter(f, "tree", []rtree.WriteVar{{Name: "w", Value: &w}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tree.Close()
+
+		for _, v := range []float64{1, 2, math.NaN(), 3} {
+			w = v
+			_, err = tree.Write()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err = tree.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	cfg := Config{
+		Files: []FileCheck{{
+			Name: fname,
+			Trees: []TreeCheck{{
+				Name:    "tree",
+				Weights: []string{"w"},
+			}},
+		}},
+	}
+
+	rep := cfg.validate()
+	res := rep.Results[len(rep.Results)-1]
+	if res.Check != "tree:tree:weights" || res.Pass {
+		t.Fatalf("invalid weights check result: %+v", res)
+	}
+
+	const want = `rtree: could not process entry 2: non-finite value for branch "w" at entry 2`
+	if got := res.Msg; got != want {
+		t.Fatalf("invalid error message:\ngot= %q\nwant=%q", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Report holds the results of all the checks run over a dataset.
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	File    string        `json:"file"`
+	Check   string        `json:"check"`
+	Pass    bool          `json:"pass"`
+	Msg     string        `json:"msg,omitempty"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+func (rep *Report) pass(fname, check string, start time.Time) {
+	rep.Results = append(rep.Results, Result{
+		File:    fname,
+		Check:   check,
+		Pass:    true,
+		Elapsed: time.Since(start),
+	})
+}
+
+func (rep *Report) fail(fname, check string, start time.Time, format string, args ...interface{}) {
+	rep.Results = append(rep.Results, Result{
+		File:    fname,
+		Check:   check,
+		Pass:    false,
+		Msg:     fmt.Sprintf(format, args...),
+		Elapsed: time.Since(start),
+	})
+}
+
+func (rep *Report) ok() bool {
+	return rep.failures() == 0
+}
+
+func (rep *Report) failures() int {
+	n := 0
+	for _, res := range rep.Results {
+		if !res.Pass {
+			n++
+		}
+	}
+	return n
+}
+
+func (rep *Report) write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return rep.writeJSON(w)
+	case "junit":
+		return rep.writeJUnit(w)
+	default:
+		return fmt.Errorf("invalid report format %q", format)
+	}
+}
+
+func (rep *Report) writeJSON(w io.Writer) error {
+	out := struct {
+		Pass     bool     `json:"pass"`
+		Checks   int      `json:"checks"`
+		Failures int      `json:"failures"`
+		Results  []Result `json:"results"`
+	}{
+		Pass:     rep.ok(),
+		Checks:   len(rep.Results),
+		Failures: rep.failures(),
+		Results:  rep.Results,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name    string        `xml:"name,attr"`
+	Class   string        `xml:"classname,attr"`
+	Time    string        `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Msg string `xml:"message,attr"`
+}
+
+// writeJUnit writes the report in the JUnit XML format, with one test
+// suite per file.
+func (rep *Report) writeJUnit(w io.Writer) error {
+	var (
+		out  junitSuites
+		idx  = make(map[string]int)
+		secs = func(d time.Duration) string {
+			return fmt.Sprintf("%.3f", d.Seconds())
+		}
+		durs []time.Duration
+	)
+	for _, res := range rep.Results {
+		i, ok := idx[res.File]
+		if !ok {
+			i = len(out.Suites)
+			idx[res.File] = i
+			out.Suites = append(out.Suites, junitSuite{Name: res.File})
+			durs = append(durs, 0)
+		}
+		suite := &out.Suites[i]
+		tc := junitCase{
+			Name:  res.Check,
+			Class: res.File,
+			Time:  secs(res.Elapsed),
+		}
+		if !res.Pass {
+			tc.Failure = &junitFailure{Msg: res.Msg}
+			suite.Failures++
+			out.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+		durs[i] += res.Elapsed
+		out.Tests++
+	}
+	for i := range out.Suites {
+		out.Suites[i].Time = secs(durs[i])
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(out)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,266 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// lcio2root converts the events of a LCIO file to a ROOT file and tree.
+//
+// Each LCIO event is stored as an entry of the tree, holding the run and
+// event numbers, the time stamp and the weight of the event, and the
+// particles of a MCParticle collection as variable-length arrays.
+//
+// Usage: lcio2root [OPTIONS] input.slcio
+//
+// Example:
+//
+//	$> lcio2root -o output.root -t evts ./input.slcio
+//	$> root-ls -t ./output.root
+//	=== [./output.root] ===
+//	version: 62600
+//	  TTree          evts                           (entries=2)
+//	    RunNumber    "RunNumber/I"          TBranch
+//	    EventNumber  "EventNumber/I"        TBranch
+//	    TimeStamp    "TimeStamp/L"          TBranch
+//	    Weight       "Weight/D"             TBranch
+//	    MC_N         "MC_N/I"               TBranch
+//	    MC_PDG       "MC_PDG[MC_N]/I"       TBranch
+//	    MC_GenStatus "MC_GenStatus[MC_N]/I" TBranch
+//	    MC_SimStatus "MC_SimStatus[MC_N]/i" TBranch
+//	    MC_Charge    "MC_Charge[MC_N]/F"    TBranch
+//	    MC_Mass      "MC_Mass[MC_N]/D"      TBranch
+//	    MC_Px        "MC_Px[MC_N]/D"        TBranch
+//	    MC_Py        "MC_Py[MC_N]/D"        TBranch
+//	    MC_Pz        "MC_Pz[MC_N]/D"        TBranch
+//	    MC_E         "MC_E[MC_N]/D"         TBranch
+//	    MC_Vx        "MC_Vx[MC_N]/D"        TBranch
+//	    MC_Vy        "MC_Vy[MC_N]/D"        TBranch
+//	    MC_Vz        "MC_Vz[MC_N]/D"        TBranch
+//	    MC_Time      "MC_Time[MC_N]/F"      TBranch
+//	    MC_Parent    "MC_Parent[MC_N]/I"    TBranch
+//
+// MC_Parent is the index of the first parent of each particle, or -1.
+//
+// Options:
+//
+//	-c string
+//	  	name of the LCIO MCParticle collection to convert (default "MCParticle")
+//	-o string
+//	  	path to output ROOT file (default "output.root")
+//	-t string
+//	  	name of the output ROOT tree (default "lcio")
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+
+	"go-hep.org/x/hep/groot"
+	"go-hep.org/x/hep/groot/rtree"
+	"go-hep.org/x/hep/lcio"
+)
+
+func main() {
+	log.SetPrefix("lcio2root: ")
+	log.SetFlags(0)
+
+	oname := flag.String("o", "output.root", "path to output ROOT file")
+	tname := flag.String("t", "lcio", "name of the output ROOT tree")
+	cname := flag.String("c", "MCParticle", "name of the LCIO MCParticle collection to convert")
+
+	flag.Usage = func() {
+		fmt.Printf(`lcio2root converts the events of a LCIO file to a ROOT file and tree.
+
+Usage: lcio2root [OPTIONS] input.slcio
+
+Example:
+
+ $> lcio2root -o output.root -t evts ./input.slcio
+
+Options:
+`)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		log.Fatalf("missing input LCIO filename argument")
+	}
+
+	err := process(*oname, *tname, *cname, flag.Arg(0))
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+}
+
+// event is the content of an entry of the output tree.
+type event struct {
+	RunNumber   int32
+	EventNumber int32
+	TimeStamp   int64
+	Weight      float64
+
+	N         int32
+	PDG       []int32
+	GenStatus []int32
+	SimStatus []uint32
+	Charge    []float32
+	Mass      []float64
+	Px        []float64
+	Py        []float64
+	Pz        []float64
+	E         []float64
+	Vx        []float64
+	Vy        []float64
+	Vz        []float64
+	Time      []float32
+	Parent    []int32 // index of the first parent of each particle, or -1
+}
+
+func (evt *event) wvars() []rtree.WriteVar {
+	return []rtree.WriteVar{
+		{Name: "RunNumber", Value: &evt.RunNumber},
+		{Name: "EventNumber", Value: &evt.EventNumber},
+		{Name: "TimeStamp", Value: &evt.TimeStamp},
+		{Name: "Weight", Value: &evt.Weight},
+		{Name: "MC_N", Value: &evt.N},
+		{Name: "MC_PDG", Value: &evt.PDG, Count: "MC_N"},
+		{Name: "MC_GenStatus", Value: &evt.GenStatus, Count: "MC_N"},
+		{Name: "MC_SimStatus", Value: &evt.SimStatus, Count: "MC_N"},
+		{Name: "MC_Charge", Value: &evt.Charge, Count: "MC_N"},
+		{Name: "MC_Mass", Value: &evt.Mass, Count: "MC_N"},
+		{Name: "MC_Px", Value: &evt.Px, Count: "MC_N"},
+		{Name: "MC_Py", Value: &evt.Py, Count: "MC_N"},
+		{Name: "MC_Pz", Value: &evt.Pz, Count: "MC_N"},
+		{Name: "MC_E", Value: &evt.E, Count: "MC_N"},
+		{Name: "MC_Vx", Value: &evt.Vx, Count: "MC_N"},
+		{Name: "MC_Vy", Value: &evt.Vy, Count: "MC_N"},
+		{Name: "MC_Vz", Value: &evt.Vz, Count: "MC_N"},
+		{Name: "MC_Time", Value: &evt.Time, Count: "MC_N"},
+		{Name: "MC_Parent", Value: &evt.Parent, Count: "MC_N"},
+	}
+}
+
+func (evt *event) fill(lce *lcio.Event, cname string) error {
+	evt.RunNumber = lce.RunNumber
+	evt.EventNumber = lce.EventNumber
+	evt.TimeStamp = lce.TimeStamp
+	evt.Weight = lce.Weight()
+
+	var mcs []lcio.McParticle
+	if lce.Has(cname) {
+		coll, ok := lce.Get(cname).(*lcio.McParticleContainer)
+		if !ok {
+			return fmt.Errorf("collection %q is not a MCParticle collection (%T)", cname, lce.Get(cname))
+		}
+		mcs = coll.Particles
+	}
+
+	n := len(mcs)
+	evt.N = int32(n)
+	evt.PDG = resize(evt.PDG, n)
+	evt.GenStatus = resize(evt.GenStatus, n)
+	evt.SimStatus = resize(evt.SimStatus, n)
+	evt.Charge = resize(evt.Charge, n)
+	evt.Mass = resize(evt.Mass, n)
+	evt.Px = resize(evt.Px, n)
+	evt.Py = resize(evt.Py, n)
+	evt.Pz = resize(evt.Pz, n)
+	evt.E = resize(evt.E, n)
+	evt.Vx = resize(evt.Vx, n)
+	evt.Vy = resize(evt.Vy, n)
+	evt.Vz = resize(evt.Vz, n)
+	evt.Time = resize(evt.Time, n)
+	evt.Parent = resize(evt.Parent, n)
+
+	idx := make(map[*lcio.McParticle]int32, n)
+	for i := range mcs {
+		idx[&mcs[i]] = int32(i)
+	}
+
+	for i := range mcs {
+		mc := &mcs[i]
+		evt.PDG[i] = mc.PDG
+		evt.GenStatus[i] = mc.GenStatus
+		evt.SimStatus[i] = mc.SimStatus
+		evt.Charge[i] = mc.Charge
+		evt.Mass[i] = mc.Mass
+		evt.Px[i] = mc.P[0]
+		evt.Py[i] = mc.P[1]
+		evt.Pz[i] = mc.P[2]
+		evt.E[i] = mc.Energy()
+		evt.Vx[i] = mc.Vertex[0]
+		evt.Vy[i] = mc.Vertex[1]
+		evt.Vz[i] = mc.Vertex[2]
+		evt.Time[i] = mc.Time
+		evt.Parent[i] = -1
+		if len(mc.Parents) > 0 {
+			j, ok := idx[mc.Parents[0]]
+			if !ok {
+				return fmt.Errorf("parent of particle %d is not in collection %q", i, cname)
+			}
+			evt.Parent[i] = j
+		}
+	}
+
+	return nil
+}
+
+func resize[T any](vs []T, n int) []T {
+	if cap(vs) < n {
+		return make([]T, n)
+	}
+	return vs[:n]
+}
+
+func process(oname, tname, cname, fname string) error {
+	r, err := lcio.Open(fname)
+	if err != nil {
+		return fmt.Errorf("could not open input LCIO file %q: %w", fname, err)
+	}
+	defer r.Close()
+
+	o, err := groot.Create(oname)
+	if err != nil {
+		return fmt.Errorf("could not create output ROOT file %q: %w", oname, err)
+	}
+	defer o.Close()
+
+	var evt event
+	tree, err := rtree.NewWriter(o, tname, evt.wvars())
+	if err != nil {
+		return fmt.Errorf("could not create output ROOT tree %q: %w", tname, err)
+	}
+
+	var ievt int64
+	for r.Next() {
+		lce := r.Event()
+		err = evt.fill(&lce, cname)
+		if err != nil {
+			return fmt.Errorf("could not convert event %d: %w", ievt, err)
+		}
+
+		_, err = tree.Write()
+		if err != nil {
+			return fmt.Errorf("could not write event %d: %w", ievt, err)
+		}
+		ievt++
+	}
+	if err := r.Err(); err != nil && err != io.EOF {
+		return fmt.Errorf("could not read event %d: %w", ievt, err)
+	}
+
+	err = tree.Close()
+	if err != nil {
+		return fmt.Errorf("could not close ROOT tree writer: %w", err)
+	}
+
+	err = o.Close()
+	if err != nil {
+		return fmt.Errorf("could not close output ROOT file %q: %w", oname, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-hep.org/x/hep/groot/rcmd"
+	"go-hep.org/x/hep/lcio"
+)
+
+func TestConvert(t *testing.T) {
+	tmp := t.TempDir()
+	fname := filepath.Join(tmp, "input.slcio")
+	oname := filepath.Join(tmp, "output.root")
+
+	func() {
+		w, err := lcio.Create(fname)
+		if err != nil {
+			t.Fatalf("could not create input LCIO file: %+v", err)
+		}
+		defer w.Close()
+
+		for i := 0; i < 2; i++ {
+			mcs := lcio.McParticleContainer{
+				Particles: []lcio.McParticle{
+					{PDG: 23, GenStatus: 2, Mass: 90},
+					{PDG: 13, GenStatus: 1, P: [3]float64{3, 4, 0}, Charge: -1, Vertex: [3]float64{1, 2, 3}, Time: 1},
+					{PDG: -13, GenStatus: 1, P: [3]float64{-3, -4, 0}, Charge: +1, Vertex: [3]float64{1, 2, 3}, Time: 1},
+				},
+			}
+			for j := range mcs.Particles[1:] {
+				dau := &mcs.Particles[j+1]
+				dau.Parents = []*lcio.McParticle{&mcs.Particles[0]}
+				mcs.Particles[0].Children = append(mcs.Particles[0].Children, dau)
+			}
+			mcs.Particles = mcs.Particles[:1+2*i]
+
+			evt := lcio.Event{
+				RunNumber:   42,
+				EventNumber: int32(i + 1),
+				TimeStamp:   1234567890,
+				Params: lcio.Params{
+					Floats: map[string][]float32{"_weight": {2}},
+				},
+			}
+			evt.Add("MCParticle", &mcs)
+
+			err = w.WriteEvent(&evt)
+			if err != nil {
+				t.Fatalf("could not write event %d: %+v", i, err)
+			}
+		}
+
+		err = w.Close()
+		if err != nil {
+			t.Fatalf("could not close input LCIO file: %+v", err)
+		}
+	}()
+
+	err := process(oname, "evts", "MCParticle", fname)
+	if err != nil {
+		t.Fatalf("could not convert LCIO file: %+v", err)
+	}
+
+	const deep = true
+	got := new(strings.Builder)
+	err = rcmd.Dump(got, oname, deep, nil)
+	if err != nil {
+		t.Fatalf("could not run root-dump: %+v", err)
+	}
+
+	want := `key[000]: evts;1 "" (TTree)
+[000][RunNumber]: 42
+[000][EventNumber]: 1
+[000][TimeStamp]: 1234567890
+[000][Weight]: 2
+[000][MC_N]: 1
+[000][MC_PDG]: [23]
+[000][MC_GenStatus]: [2]
+[000][MC_SimStatus]: [0]
+[000][MC_Charge]: [0]
+[000][MC_Mass]: [90]
+[000][MC_Px]: [0]
+[000][MC_Py]: [0]
+[000][MC_Pz]: [0]
+[000][MC_E]: [90]
+[000][MC_Vx]: [0]
+[000][MC_Vy]: [0]
+[000][MC_Vz]: [0]
+[000][MC_Time]: [0]
+[000][MC_Parent]: [-1]
+[001][RunNumber]: 42
+[001][EventNumber]: 2
+[001][TimeStamp]: 1234567890
+[001][Weight]: 2
+[001][MC_N]: 3
+[001][MC_PDG]: [23 13 -13]
+[001][MC_GenStatus]: [2 1 1]
+[001][MC_SimStatus]: [0 0 0]
+[001][MC_Charge]: [0 -1 1]
+[001][MC_Mass]: [90 0 0]
+[001][MC_Px]: [0 3 -3]
+[001][MC_Py]: [0 4 -4]
+[001][MC_Pz]: [0 0 0]
+[001][MC_E]: [90 5 5]
+[001][MC_Vx]: [0 1 1]
+[001][MC_Vy]: [0 2 2]
+[001][MC_Vz]: [0 3 3]
+[001][MC_Time]: [0 1 1]
+[001][MC_Parent]: [-1 0 0]
+`
+	if got, want := got.String(), want; got != want {
+		t.Fatalf("lcio2root conversion failed:\ngot:\n%s\nwant:\n%s\n", got, want)
+	}
+}
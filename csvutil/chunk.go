@@ -0,0 +1,145 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csvutil
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Coercion converts the raw string value of a CSV field into the value
+// pointed at by ptr.
+type Coercion func(field string, ptr interface{}) error
+
+// ChunkOptions configures how a Table is read by chunks of rows.
+type ChunkOptions struct {
+	// Size is the maximum number of rows of a chunk.
+	// The default is 1024 rows.
+	Size int
+
+	// NA lists the tokens denoting missing values (e.g. "NA", "", "n/a").
+	// Missing floating-point values are read as NaN, and other missing
+	// values as the zero value of their type.
+	NA []string
+
+	// Coerce holds the rules converting CSV fields into values, indexed by
+	// column. Columns without a rule are converted according to the type of
+	// the destination value.
+	// Missing values are handled before the coercion rules are applied.
+	Coerce map[int]Coercion
+
+	// Progress, if not nil, is called after each chunk is read with
+	// the total number of rows read so far.
+	Progress func(rows int64)
+}
+
+const defaultChunkSize = 1024
+
+// Chunks is an iterator over chunks of rows of a CSV file.
+type Chunks struct {
+	rows     *Rows
+	size     int
+	n        int64 // number of rows read so far
+	progress func(rows int64)
+	err      error
+}
+
+// ReadChunks returns an iterator over chunks of rows, semantically
+// equivalent to [beg,end).
+// If end==-1, the iterator will be configured to read rows until EOF.
+func (tbl *Table) ReadChunks(beg, end int64, opts ChunkOptions) (*Chunks, error) {
+	rows, err := tbl.ReadRows(beg, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opts.NA) > 0 {
+		rows.na = make(map[string]struct{}, len(opts.NA))
+		for _, tok := range opts.NA {
+			rows.na[tok] = struct{}{}
+		}
+	}
+	rows.coerce = opts.Coerce
+
+	chunks := &Chunks{
+		rows:     rows,
+		size:     opts.Size,
+		progress: opts.Progress,
+	}
+	if chunks.size <= 0 {
+		chunks.size = defaultChunkSize
+	}
+	return chunks, nil
+}
+
+// Next reads the next chunk of rows into dst, a pointer to a slice of
+// struct values whose fields are the columns of the CSV file.
+// The slice is truncated and re-used if its capacity allows.
+// Next returns false once all rows have been read or an error occurred.
+func (chunks *Chunks) Next(dst interface{}) bool {
+	if chunks.err != nil {
+		return false
+	}
+
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice ||
+		rv.Elem().Type().Elem().Kind() != reflect.Struct {
+		chunks.err = fmt.Errorf("csvutil: invalid chunk type %T (want pointer to slice of structs)", dst)
+		return false
+	}
+	slice := rv.Elem()
+	slice.SetLen(0)
+
+	for slice.Len() < chunks.size && chunks.rows.Next() {
+		i := slice.Len()
+		if i < slice.Cap() {
+			slice.SetLen(i + 1)
+			slice.Index(i).Set(reflect.Zero(slice.Type().Elem()))
+		} else {
+			slice.Set(reflect.Append(slice, reflect.Zero(slice.Type().Elem())))
+		}
+		err := chunks.rows.scanStruct(slice.Index(i))
+		if err != nil {
+			chunks.err = fmt.Errorf("csvutil: could not read row %d: %w", chunks.rows.cur, err)
+			return false
+		}
+	}
+
+	if err := chunks.rows.Err(); err != nil && err != io.EOF {
+		chunks.err = err
+		return false
+	}
+
+	if slice.Len() == 0 {
+		return false
+	}
+
+	chunks.n += int64(slice.Len())
+	if chunks.progress != nil {
+		chunks.progress(chunks.n)
+	}
+	return true
+}
+
+// Err returns the error, if any, that was encountered during iteration.
+func (chunks *Chunks) Err() error {
+	return chunks.err
+}
+
+// Close closes the Chunks, preventing further enumeration.
+func (chunks *Chunks) Close() error {
+	return chunks.rows.Close()
+}
+
+func setNA(rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		rv.SetFloat(math.NaN())
+	default:
+		rv.Set(reflect.Zero(rv.Type()))
+	}
+}
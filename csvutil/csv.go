@@ -11,13 +11,18 @@ package csvutil // import "go-hep.org/x/hep/csvutil"
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 func min(a, b int) int {
@@ -64,18 +69,66 @@ func formatValue(val interface{}, quotes bool, recBuilder *strings.Builder) erro
 }
 
 // Open opens a Table in read mode connected to a CSV file.
+//
+// Open transparently decompresses gzip and zstd compressed CSV files.
 func Open(fname string) (*Table, error) {
 	r, err := os.Open(fname)
 	if err != nil {
 		return nil, err
 	}
+	src, dec, err := decompress(bufio.NewReader(r))
+	if err != nil {
+		_ = r.Close()
+		return nil, fmt.Errorf("csvutil: could not open %q: %w", fname, err)
+	}
 	table := &Table{
-		Reader: csv.NewReader(bufio.NewReader(r)),
+		Reader: csv.NewReader(src),
 		f:      r,
+		dec:    dec,
 	}
 	return table, err
 }
 
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompress inspects the first bytes of the provided reader and returns a
+// reader decompressing the underlying gzip or zstd stream, if any.
+func decompress(r *bufio.Reader) (io.Reader, io.Closer, error) {
+	hdr, err := r.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(hdr, gzipMagic):
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not create gzip reader: %w", err)
+		}
+		return bufio.NewReader(zr), zr, nil
+	case bytes.HasPrefix(hdr, zstdMagic):
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not create zstd reader: %w", err)
+		}
+		return bufio.NewReader(zr), zstdCloser{zr}, nil
+	default:
+		return r, nil, nil
+	}
+}
+
+type zstdCloser struct {
+	r *zstd.Decoder
+}
+
+func (z zstdCloser) Close() error {
+	z.r.Close()
+	return nil
+}
+
 // Create creates a new CSV file and returns a Table in write mode.
 func Create(fname string) (*Table, error) {
 	w, err := os.Create(fname)
@@ -117,6 +170,7 @@ type Table struct {
 	Writer *csv.Writer
 
 	f      *os.File
+	dec    io.Closer // decompressor, if any
 	closed bool
 	err    error
 }
@@ -132,6 +186,14 @@ func (tbl *Table) Close() error {
 		tbl.err = tbl.Writer.Error()
 	}
 
+	if tbl.dec != nil {
+		err := tbl.dec.Close()
+		if err != nil && tbl.err == nil {
+			tbl.err = err
+		}
+		tbl.dec = nil
+	}
+
 	if tbl.f != nil {
 		err := tbl.f.Close()
 		if err != nil && tbl.err == nil {
@@ -232,11 +294,13 @@ func (tbl *Table) writeStruct(rv reflect.Value) error {
 // Rows is an iterator over an interval of rows inside a CSV file.
 type Rows struct {
 	tbl    *Table
-	i      int64    // number of rows iterated over
-	n      int64    // number of rows this iterator iters over
-	inc    int64    // number of rows to increment by at each iteration
-	cur    int64    // current row index
-	record []string // last read record
+	i      int64               // number of rows iterated over
+	n      int64               // number of rows this iterator iters over
+	inc    int64               // number of rows to increment by at each iteration
+	cur    int64               // current row index
+	record []string            // last read record
+	na     map[string]struct{} // tokens denoting missing values
+	coerce map[int]Coercion    // type coercion rules, by column index
 	closed bool
 	err    error // last error
 }
@@ -310,6 +374,17 @@ func (rows *Rows) scan(args ...interface{}) error {
 		rec := rows.record[i]
 		rv := reflect.ValueOf(args[i]).Elem()
 		rt := reflect.TypeOf(args[i]).Elem()
+		if _, ok := rows.na[rec]; ok {
+			setNA(rv)
+			continue
+		}
+		if coerce, ok := rows.coerce[i]; ok {
+			err := coerce(rec, args[i])
+			if err != nil {
+				return fmt.Errorf("csvutil: could not coerce column %d: %w", i, err)
+			}
+			continue
+		}
 		switch rt.Kind() {
 		case reflect.Bool:
 			v, err := strconv.ParseBool(rec)
@@ -143,3 +143,48 @@ func Example_writeSlice() {
 		log.Fatalf("error closing table: %v\n", err)
 	}
 }
+
+func Example_readChunks() {
+	fname := "testdata/chunks.csv"
+	tbl, err := Open(fname)
+	if err != nil {
+		log.Fatalf("could not open %s: %v\n", fname, err)
+	}
+	defer tbl.Close()
+	tbl.Reader.Comma = ';'
+	tbl.Reader.Comment = '#'
+
+	chunks, err := tbl.ReadChunks(0, -1, ChunkOptions{
+		Size: 3,
+		NA:   []string{"NA", ""},
+		Coerce: map[int]Coercion{
+			3: func(field string, ptr interface{}) error {
+				*ptr.(*bool) = field == "yes"
+				return nil
+			},
+		},
+	})
+	if err != nil {
+		log.Fatalf("could not read chunks: %v\n", err)
+	}
+	defer chunks.Close()
+
+	var data []struct {
+		I int64
+		F float64
+		S string
+		B bool
+	}
+	for chunks.Next(&data) {
+		fmt.Printf("chunk: %v\n", data)
+	}
+	err = chunks.Err()
+	if err != nil {
+		log.Fatalf("error: %v\n", err)
+	}
+
+	// Output:
+	// chunk: [{0 0.5 str-0 true} {1 NaN str-1 false} {2 2.5  true}]
+	// chunk: [{0 3.5 str-3 false} {4 NaN str-4 false} {5 5.5 str-5 true}]
+	// chunk: [{6 6.5 str-6 false}]
+}
@@ -8,7 +8,10 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"go-hep.org/x/hep/csvutil"
@@ -318,7 +321,7 @@ func TestCSVReaderScanArgsSubSample(t *testing.T) {
 }
 
 func TestCSVWriterArgs(t *testing.T) {
-	fname := "testdata/out-args.csv"
+	fname := filepath.Join(t.TempDir(), "out-args.csv")
 	tbl, err := csvutil.Create(fname)
 	if err != nil {
 		t.Errorf("could not create %s: %+v\n", fname, err)
@@ -356,7 +359,7 @@ func TestCSVWriterArgs(t *testing.T) {
 }
 
 func TestCSVWriterStruct(t *testing.T) {
-	fname := "testdata/out-struct.csv"
+	fname := filepath.Join(t.TempDir(), "out-struct.csv")
 	tbl, err := csvutil.Create(fname)
 	if err != nil {
 		t.Errorf("could not create %s: %+v\n", fname, err)
@@ -401,7 +404,7 @@ func TestCSVWriterStruct(t *testing.T) {
 }
 
 func TestCSVWriterArgsSlice(t *testing.T) {
-	fname := "testdata/out-args-slice.csv"
+	fname := filepath.Join(t.TempDir(), "out-args-slice.csv")
 	tbl, err := csvutil.Create(fname)
 	if err != nil {
 		t.Fatalf("could not create %s: %+v", fname, err)
@@ -439,7 +442,7 @@ func TestCSVWriterArgsSlice(t *testing.T) {
 }
 
 func TestCSVAppend(t *testing.T) {
-	fname := "testdata/append-test.csv"
+	fname := filepath.Join(t.TempDir(), "append-test.csv")
 	tbl, err := csvutil.Create(fname)
 	if err != nil {
 		t.Fatal(err)
@@ -601,7 +604,7 @@ func TestCSVReaderTypes(t *testing.T) {
 }
 
 func TestCSVWriterTypes(t *testing.T) {
-	fname := "testdata/out-types.csv"
+	fname := filepath.Join(t.TempDir(), "out-types.csv")
 	tbl, err := csvutil.Create(fname)
 	if err != nil {
 		t.Errorf("could not create %s: %+v\n", fname, err)
@@ -655,6 +658,147 @@ func TestCSVWriterTypes(t *testing.T) {
 	}
 }
 
+func TestCSVReaderChunks(t *testing.T) {
+	type Data struct {
+		I int64
+		F float64
+		S string
+		B bool
+	}
+
+	want := []Data{
+		{0, 0.5, "str-0", true},
+		{1, math.NaN(), "str-1", false},
+		{2, 2.5, "", true},
+		{0, 3.5, "str-3", false},
+		{4, math.NaN(), "str-4", false},
+		{5, 5.5, "str-5", true},
+		{6, 6.5, "str-6", false},
+	}
+
+	for _, tc := range []struct {
+		size   int
+		chunks []int
+	}{
+		{size: 0, chunks: []int{7}},
+		{size: 1, chunks: []int{1, 1, 1, 1, 1, 1, 1}},
+		{size: 3, chunks: []int{3, 3, 1}},
+		{size: 7, chunks: []int{7}},
+		{size: 10, chunks: []int{7}},
+	} {
+		t.Run(fmt.Sprintf("size=%d", tc.size), func(t *testing.T) {
+			fname := "testdata/chunks.csv"
+			tbl, err := csvutil.Open(fname)
+			if err != nil {
+				t.Fatalf("could not open %s: %+v", fname, err)
+			}
+			defer tbl.Close()
+			tbl.Reader.Comma = ';'
+			tbl.Reader.Comment = '#'
+
+			var progress []int64
+			chunks, err := tbl.ReadChunks(0, -1, csvutil.ChunkOptions{
+				Size: tc.size,
+				NA:   []string{"NA", ""},
+				Coerce: map[int]csvutil.Coercion{
+					3: func(field string, ptr interface{}) error {
+						switch field {
+						case "yes":
+							*ptr.(*bool) = true
+						case "no":
+							*ptr.(*bool) = false
+						default:
+							return fmt.Errorf("invalid boolean %q", field)
+						}
+						return nil
+					},
+				},
+				Progress: func(rows int64) {
+					progress = append(progress, rows)
+				},
+			})
+			if err != nil {
+				t.Fatalf("could not read chunks: %+v", err)
+			}
+			defer chunks.Close()
+
+			var (
+				got  []Data
+				lens []int
+				data []Data
+			)
+			for chunks.Next(&data) {
+				lens = append(lens, len(data))
+				got = append(got, data...)
+			}
+			if err := chunks.Err(); err != nil {
+				t.Fatalf("could not read chunks: %+v", err)
+			}
+
+			if !reflect.DeepEqual(lens, tc.chunks) {
+				t.Fatalf("invalid chunk sizes: got=%v, want=%v", lens, tc.chunks)
+			}
+
+			var (
+				n    int64
+				prog []int64
+			)
+			for _, v := range lens {
+				n += int64(v)
+				prog = append(prog, n)
+			}
+			if !reflect.DeepEqual(progress, prog) {
+				t.Fatalf("invalid progress: got=%v, want=%v", progress, prog)
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("invalid number of rows: got=%d, want=%d", len(got), len(want))
+			}
+			for i := range got {
+				g, w := got[i], want[i]
+				if math.IsNaN(w.F) != math.IsNaN(g.F) || (!math.IsNaN(w.F) && g.F != w.F) {
+					t.Fatalf("row %d: invalid F: got=%v, want=%v", i, g.F, w.F)
+				}
+				g.F, w.F = 0, 0
+				if g != w {
+					t.Fatalf("row %d: got=%+v, want=%+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVReaderChunksInvalid(t *testing.T) {
+	fname := "testdata/chunks.csv"
+	tbl, err := csvutil.Open(fname)
+	if err != nil {
+		t.Fatalf("could not open %s: %+v", fname, err)
+	}
+	defer tbl.Close()
+	tbl.Reader.Comma = ';'
+	tbl.Reader.Comment = '#'
+
+	chunks, err := tbl.ReadChunks(0, -1, csvutil.ChunkOptions{Size: 2})
+	if err != nil {
+		t.Fatalf("could not read chunks: %+v", err)
+	}
+	defer chunks.Close()
+
+	var data []struct {
+		I int64
+		F float64
+	}
+	for chunks.Next(&data) {
+	}
+	err = chunks.Err()
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if got, want := err.Error(), `csvutil: could not read row 1: strconv.ParseFloat: parsing "NA": invalid syntax`; got != want {
+		t.Fatalf("invalid error:\ngot= %s\nwant=%s", got, want)
+	}
+}
+
 func diff(ref, chk string) error {
 	cmd := exec.Command("diff", "-urN", ref, chk)
 	buf := new(bytes.Buffer)
@@ -0,0 +1,187 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package csvarrow handles conversion between CSV tables and ARROW data models.
+//
+// Only flat schemas, made of booleans, signed and unsigned integers,
+// floating-point numbers and strings, are supported.
+package csvarrow // import "go-hep.org/x/hep/csvutil/csvarrow"
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+)
+
+// parserFrom returns a function appending the value of a CSV field
+// to an Arrow builder of the provided type.
+func parserFrom(dt arrow.DataType) (func(bldr array.Builder, field string) error, error) {
+	switch dt.ID() {
+	case arrow.BOOL:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseBool(field)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.BooleanBuilder).Append(v)
+			return nil
+		}, nil
+	case arrow.INT8:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseInt(field, 10, 8)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Int8Builder).Append(int8(v))
+			return nil
+		}, nil
+	case arrow.INT16:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseInt(field, 10, 16)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Int16Builder).Append(int16(v))
+			return nil
+		}, nil
+	case arrow.INT32:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseInt(field, 10, 32)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Int32Builder).Append(int32(v))
+			return nil
+		}, nil
+	case arrow.INT64:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Int64Builder).Append(v)
+			return nil
+		}, nil
+	case arrow.UINT8:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseUint(field, 10, 8)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Uint8Builder).Append(uint8(v))
+			return nil
+		}, nil
+	case arrow.UINT16:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseUint(field, 10, 16)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Uint16Builder).Append(uint16(v))
+			return nil
+		}, nil
+	case arrow.UINT32:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Uint32Builder).Append(uint32(v))
+			return nil
+		}, nil
+	case arrow.UINT64:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Uint64Builder).Append(v)
+			return nil
+		}, nil
+	case arrow.FLOAT32:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseFloat(field, 32)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Float32Builder).Append(float32(v))
+			return nil
+		}, nil
+	case arrow.FLOAT64:
+		return func(bldr array.Builder, field string) error {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return err
+			}
+			bldr.(*array.Float64Builder).Append(v)
+			return nil
+		}, nil
+	case arrow.STRING:
+		return func(bldr array.Builder, field string) error {
+			bldr.(*array.StringBuilder).Append(field)
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("csvarrow: unsupported data type %v", dt)
+	}
+}
+
+// formatterFrom returns a function formatting the i-th value of an Arrow
+// array of the provided type as a CSV field.
+func formatterFrom(dt arrow.DataType) (func(arr array.Interface, i int) string, error) {
+	switch dt.ID() {
+	case arrow.BOOL:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatBool(arr.(*array.Boolean).Value(i))
+		}, nil
+	case arrow.INT8:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int8).Value(i)), 10)
+		}, nil
+	case arrow.INT16:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int16).Value(i)), 10)
+		}, nil
+	case arrow.INT32:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatInt(int64(arr.(*array.Int32).Value(i)), 10)
+		}, nil
+	case arrow.INT64:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatInt(arr.(*array.Int64).Value(i), 10)
+		}, nil
+	case arrow.UINT8:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint8).Value(i)), 10)
+		}, nil
+	case arrow.UINT16:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint16).Value(i)), 10)
+		}, nil
+	case arrow.UINT32:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatUint(uint64(arr.(*array.Uint32).Value(i)), 10)
+		}, nil
+	case arrow.UINT64:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatUint(arr.(*array.Uint64).Value(i), 10)
+		}, nil
+	case arrow.FLOAT32:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatFloat(float64(arr.(*array.Float32).Value(i)), 'g', -1, 32)
+		}, nil
+	case arrow.FLOAT64:
+		return func(arr array.Interface, i int) string {
+			return strconv.FormatFloat(arr.(*array.Float64).Value(i), 'g', -1, 64)
+		}, nil
+	case arrow.STRING:
+		return func(arr array.Interface, i int) string {
+			return arr.(*array.String).Value(i)
+		}, nil
+	default:
+		return nil, fmt.Errorf("csvarrow: unsupported data type %v", dt)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csvarrow
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"go-hep.org/x/hep/csvutil"
+)
+
+func TestRecordReader(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "run", Type: arrow.PrimitiveTypes.Uint32},
+		{Name: "pt_min", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "pt_max", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "region", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "sf", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "valid", Type: arrow.FixedWidthTypes.Boolean},
+	}, nil)
+
+	for _, tc := range []struct {
+		chunks int64
+		want   []string
+	}{
+		{
+			chunks: -1,
+			want: []string{
+				`rec[0][run]: [1 1 1 2 2]
+rec[0][pt_min]: [20 30 50 20 30]
+rec[0][pt_max]: [30 50 100 30 50]
+rec[0][region]: ["barrel" "barrel" "endcap" "barrel" (null)]
+rec[0][sf]: [0.98 0.99 (null) 1.01 1.02]
+rec[0][valid]: [true true false true true]
+`,
+			},
+		},
+		{
+			chunks: 2,
+			want: []string{
+				`rec[0][run]: [1 1]
+rec[0][pt_min]: [20 30]
+rec[0][pt_max]: [30 50]
+rec[0][region]: ["barrel" "barrel"]
+rec[0][sf]: [0.98 0.99]
+rec[0][valid]: [true true]
+`,
+				`rec[1][run]: [1 2]
+rec[1][pt_min]: [50 20]
+rec[1][pt_max]: [100 30]
+rec[1][region]: ["endcap" "barrel"]
+rec[1][sf]: [(null) 1.01]
+rec[1][valid]: [false true]
+`,
+				`rec[2][run]: [2]
+rec[2][pt_min]: [30]
+rec[2][pt_max]: [50]
+rec[2][region]: [(null)]
+rec[2][sf]: [1.02]
+rec[2][valid]: [true]
+`,
+			},
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+			defer mem.AssertSize(t, 0)
+
+			tbl, err := csvutil.Open("testdata/scale-factors.csv")
+			if err != nil {
+				t.Fatalf("could not open CSV table: %+v", err)
+			}
+			defer tbl.Close()
+
+			r, err := NewRecordReader(
+				tbl, schema,
+				WithAllocator(mem),
+				WithChunk(tc.chunks),
+				WithHeader(),
+				WithNA("NA"),
+			)
+			if err != nil {
+				t.Fatalf("could not create record reader: %+v", err)
+			}
+			defer r.Release()
+
+			n := 0
+			for r.Next() {
+				if n >= len(tc.want) {
+					t.Fatalf("too many records")
+				}
+				got := recordString(n, r.Record())
+				if got != tc.want[n] {
+					t.Fatalf("invalid record %d:\ngot:\n%s\nwant:\n%s", n, got, tc.want[n])
+				}
+				n++
+			}
+			if err := r.Err(); err != nil {
+				t.Fatalf("could not read records: %+v", err)
+			}
+			if n != len(tc.want) {
+				t.Fatalf("invalid number of records: got=%d, want=%d", n, len(tc.want))
+			}
+		})
+	}
+}
+
+func TestRecordReaderInvalid(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "run", Type: arrow.PrimitiveTypes.Uint32},
+		{Name: "pt_min", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "pt_max", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "region", Type: arrow.BinaryTypes.String},
+		{Name: "sf", Type: arrow.PrimitiveTypes.Float32},
+		{Name: "valid", Type: arrow.FixedWidthTypes.Boolean},
+	}, nil)
+
+	tbl, err := csvutil.Open("testdata/scale-factors.csv")
+	if err != nil {
+		t.Fatalf("could not open CSV table: %+v", err)
+	}
+	defer tbl.Close()
+
+	r, err := NewRecordReader(tbl, schema, WithHeader())
+	if err != nil {
+		t.Fatalf("could not create record reader: %+v", err)
+	}
+	defer r.Release()
+
+	for r.Next() {
+	}
+
+	want := `csvarrow: could not read row 2: could not parse column "sf": strconv.ParseFloat: parsing "NA": invalid syntax`
+	if err := r.Err(); err == nil || err.Error() != want {
+		t.Fatalf("invalid error:\ngot= %v\nwant=%s", err, want)
+	}
+
+	_, err = NewRecordReader(tbl, arrow.NewSchema([]arrow.Field{
+		{Name: "list", Type: arrow.ListOf(arrow.PrimitiveTypes.Int32)},
+	}, nil))
+	if err == nil {
+		t.Fatalf("expected an error for an unsupported data type")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "run", Type: arrow.PrimitiveTypes.Uint32},
+		{Name: "pt_min", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "pt_max", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "region", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "sf", Type: arrow.PrimitiveTypes.Float32, Nullable: true},
+		{Name: "valid", Type: arrow.FixedWidthTypes.Boolean},
+	}, nil)
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	src, err := csvutil.Open("testdata/scale-factors.csv")
+	if err != nil {
+		t.Fatalf("could not open CSV table: %+v", err)
+	}
+	defer src.Close()
+
+	r, err := NewRecordReader(
+		src, schema,
+		WithAllocator(mem), WithChunk(2), WithHeader(), WithNA("NA"),
+	)
+	if err != nil {
+		t.Fatalf("could not create record reader: %+v", err)
+	}
+	defer r.Release()
+
+	fname := filepath.Join(t.TempDir(), "out.csv")
+	dst, err := csvutil.Create(fname)
+	if err != nil {
+		t.Fatalf("could not create CSV table: %+v", err)
+	}
+	defer dst.Close()
+
+	w, err := NewWriter(dst, schema, WithHeader(), WithNA("NA"))
+	if err != nil {
+		t.Fatalf("could not create writer: %+v", err)
+	}
+
+	for r.Next() {
+		err = w.Write(r.Record())
+		if err != nil {
+			t.Fatalf("could not write record: %+v", err)
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("could not read records: %+v", err)
+	}
+
+	err = dst.Close()
+	if err != nil {
+		t.Fatalf("could not close CSV table: %+v", err)
+	}
+
+	got, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatalf("could not read output CSV file: %+v", err)
+	}
+	want, err := os.ReadFile("testdata/scale-factors.csv")
+	if err != nil {
+		t.Fatalf("could not read input CSV file: %+v", err)
+	}
+
+	if string(got) != string(want) {
+		t.Fatalf("invalid CSV file:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	other := arrow.NewSchema([]arrow.Field{
+		{Name: "run", Type: arrow.PrimitiveTypes.Uint32},
+	}, nil)
+	bldr := array.NewRecordBuilder(mem, other)
+	defer bldr.Release()
+	bldr.Field(0).(*array.Uint32Builder).Append(1)
+	rec := bldr.NewRecord()
+	defer rec.Release()
+
+	err = w.Write(rec)
+	if err == nil {
+		t.Fatalf("expected an error for a record with a different schema")
+	}
+}
+
+func recordString(n int, rec array.Record) string {
+	o := new(strings.Builder)
+	for i, col := range rec.Columns() {
+		fmt.Fprintf(o, "rec[%d][%s]: %v\n", n, rec.Schema().Field(i).Name, col)
+	}
+	return o.String()
+}
@@ -0,0 +1,71 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csvarrow // import "go-hep.org/x/hep/csvutil/csvarrow"
+
+import (
+	"github.com/apache/arrow/go/arrow/memory"
+)
+
+type config struct {
+	mem    memory.Allocator
+	chunks int64
+	header bool
+	na     []string
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		mem: memory.NewGoAllocator(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Option allows to configure how Records are read from and written to
+// CSV tables.
+type Option func(*config)
+
+// WithAllocator configures an Arrow value to use the specified memory allocator
+// instead of the default Go one.
+func WithAllocator(mem memory.Allocator) Option {
+	return func(cfg *config) {
+		cfg.mem = mem
+	}
+}
+
+// WithChunk specifies the number of rows to populate Records with.
+//
+// The default is to populate Records with the whole set of rows the input
+// CSV table contains.
+func WithChunk(nrows int64) Option {
+	return func(cfg *config) {
+		cfg.chunks = nrows
+	}
+}
+
+// WithHeader specifies that the CSV table has a header line, holding the
+// names of the columns.
+//
+// When reading, the header line is skipped.
+// When writing, the names of the fields of the schema are written as the
+// header line.
+func WithHeader() Option {
+	return func(cfg *config) {
+		cfg.header = true
+	}
+}
+
+// WithNA specifies the tokens denoting missing values.
+//
+// When reading, fields equal to one of these tokens are read as nulls.
+// When writing, nulls are written as the first token.
+// The default is to write nulls as empty fields.
+func WithNA(tokens ...string) Option {
+	return func(cfg *config) {
+		cfg.na = append([]string(nil), tokens...)
+	}
+}
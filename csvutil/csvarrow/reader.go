@@ -0,0 +1,171 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csvarrow // import "go-hep.org/x/hep/csvutil/csvarrow"
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"go-hep.org/x/hep/csvutil"
+)
+
+// RecordReader is an Arrow RecordReader reading the rows of a CSV table.
+type RecordReader struct {
+	refs int64
+
+	tbl    *csvutil.Table
+	schema *arrow.Schema
+	bldr   *array.RecordBuilder
+	parse  []func(bldr array.Builder, field string) error
+	na     map[string]struct{}
+	header bool
+	chunks int64
+
+	rec  array.Record
+	irow int64 // number of rows read so far
+	done bool
+	err  error
+}
+
+// NewRecordReader creates a new Arrow RecordReader reading the rows of
+// the provided CSV table, with the provided schema.
+// The columns of the CSV table are mapped onto the fields of the schema,
+// in order.
+func NewRecordReader(tbl *csvutil.Table, schema *arrow.Schema, opts ...Option) (*RecordReader, error) {
+	cfg := newConfig(opts)
+
+	r := &RecordReader{
+		refs:   1,
+		tbl:    tbl,
+		schema: schema,
+		parse:  make([]func(array.Builder, string) error, len(schema.Fields())),
+		header: cfg.header,
+		chunks: cfg.chunks,
+	}
+
+	for i, field := range schema.Fields() {
+		parse, err := parserFrom(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("csvarrow: invalid field %q: %w", field.Name, err)
+		}
+		r.parse[i] = parse
+	}
+
+	if len(cfg.na) > 0 {
+		r.na = make(map[string]struct{}, len(cfg.na))
+		for _, tok := range cfg.na {
+			r.na[tok] = struct{}{}
+		}
+	}
+
+	r.bldr = array.NewRecordBuilder(cfg.mem, schema)
+	return r, nil
+}
+
+// Retain increases the reference count by 1.
+// Retain may be called simultaneously from multiple goroutines.
+func (r *RecordReader) Retain() {
+	atomic.AddInt64(&r.refs, 1)
+}
+
+// Release decreases the reference count by 1.
+// When the reference count goes to zero, the memory is freed.
+// Release may be called simultaneously from multiple goroutines.
+func (r *RecordReader) Release() {
+	if atomic.AddInt64(&r.refs, -1) == 0 {
+		if r.rec != nil {
+			r.rec.Release()
+			r.rec = nil
+		}
+		if r.bldr != nil {
+			r.bldr.Release()
+			r.bldr = nil
+		}
+	}
+}
+
+func (r *RecordReader) Schema() *arrow.Schema { return r.schema }
+func (r *RecordReader) Record() array.Record  { return r.rec }
+
+// Err returns the error, if any, that was encountered while reading
+// the CSV table.
+func (r *RecordReader) Err() error { return r.err }
+
+// Next loads the next chunk of rows of the CSV table into a new Record.
+// Next returns false once all rows have been read or an error occurred.
+func (r *RecordReader) Next() bool {
+	if r.rec != nil {
+		r.rec.Release()
+		r.rec = nil
+	}
+
+	if r.done || r.err != nil {
+		return false
+	}
+
+	if r.header {
+		r.header = false
+		_, err := r.tbl.Reader.Read()
+		if err != nil {
+			r.done = true
+			if err != io.EOF {
+				r.err = fmt.Errorf("csvarrow: could not read header: %w", err)
+			}
+			return false
+		}
+	}
+
+	var n int64
+	for r.chunks <= 0 || n < r.chunks {
+		rec, err := r.tbl.Reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				r.done = true
+				break
+			}
+			r.err = fmt.Errorf("csvarrow: could not read row %d: %w", r.irow, err)
+			return false
+		}
+		err = r.read(rec)
+		if err != nil {
+			r.err = fmt.Errorf("csvarrow: could not read row %d: %w", r.irow, err)
+			return false
+		}
+		r.irow++
+		n++
+	}
+
+	if n == 0 {
+		return false
+	}
+
+	r.rec = r.bldr.NewRecord()
+	return true
+}
+
+func (r *RecordReader) read(rec []string) error {
+	if len(rec) != len(r.parse) {
+		return fmt.Errorf("invalid number of fields (got=%d, want=%d)", len(rec), len(r.parse))
+	}
+	for i, field := range rec {
+		bldr := r.bldr.Field(i)
+		if _, ok := r.na[field]; ok {
+			bldr.AppendNull()
+			continue
+		}
+		err := r.parse[i](bldr, field)
+		if err != nil {
+			return fmt.Errorf("could not parse column %q: %w", r.schema.Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+var (
+	_ array.RecordReader = (*RecordReader)(nil)
+)
@@ -0,0 +1,89 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package csvarrow // import "go-hep.org/x/hep/csvutil/csvarrow"
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"go-hep.org/x/hep/csvutil"
+)
+
+// Writer writes Arrow Records as rows of a CSV table.
+type Writer struct {
+	tbl    *csvutil.Table
+	schema *arrow.Schema
+	format []func(arr array.Interface, i int) string
+	header bool
+	na     string
+}
+
+// NewWriter creates a new Writer writing Arrow Records with the provided
+// schema to the CSV table.
+// The table must be in write mode.
+func NewWriter(tbl *csvutil.Table, schema *arrow.Schema, opts ...Option) (*Writer, error) {
+	if tbl.Writer == nil {
+		return nil, fmt.Errorf("csvarrow: table is not in write mode")
+	}
+
+	cfg := newConfig(opts)
+	w := &Writer{
+		tbl:    tbl,
+		schema: schema,
+		format: make([]func(array.Interface, int) string, len(schema.Fields())),
+		header: cfg.header,
+	}
+	if len(cfg.na) > 0 {
+		w.na = cfg.na[0]
+	}
+
+	for i, field := range schema.Fields() {
+		format, err := formatterFrom(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("csvarrow: invalid field %q: %w", field.Name, err)
+		}
+		w.format[i] = format
+	}
+
+	return w, nil
+}
+
+// Write writes the rows of the provided Record to the CSV table.
+func (w *Writer) Write(rec array.Record) error {
+	if !rec.Schema().Equal(w.schema) {
+		return fmt.Errorf("csvarrow: invalid record schema")
+	}
+
+	if w.header {
+		w.header = false
+		names := make([]string, len(w.schema.Fields()))
+		for i, field := range w.schema.Fields() {
+			names[i] = field.Name
+		}
+		err := w.tbl.Writer.Write(names)
+		if err != nil {
+			return fmt.Errorf("csvarrow: could not write header: %w", err)
+		}
+	}
+
+	row := make([]string, rec.NumCols())
+	for i := 0; i < int(rec.NumRows()); i++ {
+		for j := range row {
+			arr := rec.Column(j)
+			if arr.IsNull(i) {
+				row[j] = w.na
+				continue
+			}
+			row[j] = w.format[j](arr, i)
+		}
+		err := w.tbl.Writer.Write(row)
+		if err != nil {
+			return fmt.Errorf("csvarrow: could not write row %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
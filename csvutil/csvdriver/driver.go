@@ -14,6 +14,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -41,10 +42,11 @@ type Conn struct {
 	File    string      `json:"file"`    // name of the file to be open
 	Mode    int         `json:"mode"`    // r/w mode (default: read-only)
 	Perm    os.FileMode `json:"perm"`    // file permissions
-	Comma   rune        `json:"comma"`   // field delimiter (default: ',')
+	Comma   rune        `json:"comma"`   // field delimiter (default: ',', or '\t' for TSV files)
 	Comment rune        `json:"comment"` // comment character for start of line (default: '#')
 	Header  bool        `json:"header"`  // whether the CSV-file has a column header
 	Names   []string    `json:"names"`   // column names
+	Sample  int         `json:"sample"`  // number of rows used to infer column types (default: 100)
 }
 
 func (c *Conn) setDefaults() {
@@ -54,10 +56,31 @@ func (c *Conn) setDefaults() {
 	}
 	if c.Comma == 0 {
 		c.Comma = ','
+		if isTSV(c.File) {
+			c.Comma = '\t'
+		}
 	}
 	if c.Comment == 0 {
 		c.Comment = '#'
 	}
+	if c.Sample <= 0 {
+		c.Sample = 100
+	}
+}
+
+// isTSV returns whether the named file is a (possibly compressed)
+// tab-separated values file.
+func isTSV(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	switch ext {
+	case ".gz", ".zst":
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(name, filepath.Ext(name))))
+	}
+	switch ext {
+	case ".tsv", ".tab":
+		return true
+	}
+	return false
 }
 
 func (c Conn) toJSON() (string, error) {
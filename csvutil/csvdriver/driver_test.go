@@ -154,11 +154,94 @@ func TestOpen(t *testing.T) {
 			},
 			q: "var1, var2, var3",
 		},
+		{
+			c: csvdriver.Conn{
+				File:    "testdata/simple.csv.gz",
+				Comment: '#', Comma: ';',
+			},
+			q: "var1, var2, var3",
+		},
+		{
+			c: csvdriver.Conn{
+				File:    "testdata/simple.tsv.zst",
+				Comment: '#',
+			},
+			q: "var1, var2, var3",
+		},
 	} {
 		testDB(t, test.c, test.q)
 	}
 }
 
+func TestInferTypes(t *testing.T) {
+	for _, tc := range []struct {
+		sample int
+		want   []string
+	}{
+		{
+			sample: 2,
+			want:   []string{"int64", "float64", "[]uint8"},
+		},
+		{
+			sample: 0, // default
+			want:   []string{"int64", "float64", "[]uint8"},
+		},
+	} {
+		t.Run(fmt.Sprintf("sample=%d", tc.sample), func(t *testing.T) {
+			c := csvdriver.Conn{
+				File:   "testdata/mixed-types.csv",
+				Comma:  ';',
+				Sample: tc.sample,
+			}
+			db, err := c.Open()
+			if err != nil {
+				t.Fatalf("could not open CSV file: %+v", err)
+			}
+			defer db.Close()
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatalf("could not start tx: %+v", err)
+			}
+			defer func() {
+				_ = tx.Commit()
+			}()
+
+			rows, err := tx.Query("select * from csv order by id();")
+			if err != nil {
+				t.Fatalf("could not query db: %+v", err)
+			}
+			defer rows.Close()
+
+			var got []string
+			for rows.Next() {
+				var (
+					vs = make([]interface{}, 3)
+					ps = []interface{}{&vs[0], &vs[1], &vs[2]}
+				)
+				err = rows.Scan(ps...)
+				if err != nil {
+					t.Fatalf("could not scan row: %+v", err)
+				}
+				if got != nil {
+					continue
+				}
+				for _, v := range vs {
+					got = append(got, fmt.Sprintf("%T", v))
+				}
+			}
+			err = rows.Err()
+			if err != nil {
+				t.Fatalf("could not iterate over rows: %+v", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("invalid column types:\ngot= %q\nwant=%q", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestQL(t *testing.T) {
 	db, err := sql.Open("ql", "memory://out-create-ql.csv")
 	if err != nil {
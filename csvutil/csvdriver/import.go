@@ -104,13 +104,19 @@ func inferSchema(conn *csvConn, header bool, names []string) (schemaType, error)
 	tbl.Reader.Comma = conn.cfg.Comma
 	tbl.Reader.Comment = conn.cfg.Comment
 
-	return inferSchemaFromTable(tbl, header, names)
+	return inferSchemaFromTable(tbl, header, names, conn.cfg.Sample)
 }
 
-func inferSchemaFromTable(tbl *csvutil.Table, header bool, names []string) (schemaType, error) {
+// inferSchemaFromTable infers the schema of the table from its first
+// sample rows.
+// Column types are widened from int64 to float64 to string as needed.
+func inferSchemaFromTable(tbl *csvutil.Table, header bool, names []string, sample int) (schemaType, error) {
+	if sample <= 0 {
+		sample = 1
+	}
 	var (
 		beg int64 = 0
-		end int64 = 1
+		end       = int64(sample)
 	)
 	if header {
 		end++
@@ -134,7 +140,30 @@ func inferSchemaFromTable(tbl *csvutil.Table, header bool, names []string) (sche
 		return nil, rows.Err()
 	}
 
-	return inferSchemaFromFields(rows.Fields(), names)
+	schema, err := inferSchemaFromFields(rows.Fields(), names)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		fields := rows.Fields()
+		if len(fields) != len(schema) {
+			return nil, fmt.Errorf(
+				"csvdriver: inconsistent number of fields (got=%d, want=%d)",
+				len(fields), len(schema),
+			)
+		}
+		for i, field := range fields {
+			schema[i].v = widenType(schema[i].v, field)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return schema, nil
 }
 
 func inferSchemaFromFields(fields []string, names []string) (schemaType, error) {
@@ -143,28 +172,49 @@ func inferSchemaFromFields(fields []string, names []string) (schemaType, error)
 	}
 	schema := make(schemaType, len(fields))
 	for i, field := range fields {
-		var err error
 		name := names[i]
 		if name == "" {
 			name = fmt.Sprintf("var%d", i+1)
 		}
 
 		schema[i].n = name
-		_, err = strconv.ParseInt(field, 10, 64)
-		if err == nil {
-			schema[i].v = reflect.ValueOf(int64(0))
-			continue
-		}
+		schema[i].v = inferType(field)
+	}
+	return schema, nil
+}
 
-		_, err = strconv.ParseFloat(field, 64)
-		if err == nil {
-			schema[i].v = reflect.ValueOf(float64(0))
-			continue
-		}
+func inferType(field string) reflect.Value {
+	_, err := strconv.ParseInt(field, 10, 64)
+	if err == nil {
+		return reflect.ValueOf(int64(0))
+	}
 
-		schema[i].v = reflect.ValueOf("")
+	_, err = strconv.ParseFloat(field, 64)
+	if err == nil {
+		return reflect.ValueOf(float64(0))
 	}
-	return schema, nil
+
+	return reflect.ValueOf("")
+}
+
+// widenType returns the narrowest type able to hold both values of
+// the provided type and the provided field.
+func widenType(v reflect.Value, field string) reflect.Value {
+	rank := func(k reflect.Kind) int {
+		switch k {
+		case reflect.Int64:
+			return 0
+		case reflect.Float64:
+			return 1
+		default:
+			return 2
+		}
+	}
+	o := inferType(field)
+	if rank(o.Kind()) > rank(v.Kind()) {
+		return o
+	}
+	return v
 }
 
 type schemaType []struct {
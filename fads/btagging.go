@@ -36,6 +36,17 @@ func (btag btagclassifier) Category(parton *Candidate) int {
 	return 0
 }
 
+// BTagging tags jets originating from heavy quarks.
+//
+// The flavour of a jet is the highest PDG code of the partons within DeltaR
+// of the jet axis, gluons having the lowest priority (21 for gluon jets, 0
+// for unmatched jets).
+// Jets are tagged with the efficiency Eff[flavour](pt, eta), or Eff[0] if
+// there is no efficiency for this flavour, which gives the mis-tag rates.
+// The efficiencies may also be configured as pT/η formulas, with the
+// Efficiency property (see TagEfficiency).
+// The tag decision is set at the BitNumber bit of the BTag field of the
+// output jets, and the flavour in their Flavor field.
 type BTagging struct {
 	fwk.TaskBase
 
@@ -48,6 +59,7 @@ type BTagging struct {
 
 	btag btagclassifier
 	eff  map[int]func(pt, eta float64) float64
+	effs TagEfficiency
 
 	seed uint64
 	src  *rand.Rand
@@ -74,6 +86,13 @@ func (tsk *BTagging) Configure(ctx fwk.Context) error {
 		return err
 	}
 
+	if len(tsk.effs.Formulas) > 0 {
+		tsk.eff, err = tsk.effs.compile()
+		if err != nil {
+			return err
+		}
+	}
+
 	tsk.src = rand.New(rand.NewSource(tsk.seed))
 	tsk.flat = distuv.Uniform{Min: 0, Max: 1, Src: tsk.src}
 	return err
@@ -112,7 +131,7 @@ func (tsk *BTagging) Process(ctx fwk.Context) error {
 
 	output := make([]Candidate, 0, len(jets))
 	defer func() {
-		err = store.Put(tsk.output, jets)
+		err = store.Put(tsk.output, output)
 	}()
 
 	msg.Debugf("partons: %d\n", len(allpartons))
@@ -169,6 +188,7 @@ func (tsk *BTagging) Process(ctx fwk.Context) error {
 		}
 		tsk.flatmu.Unlock()
 		jet.BTag |= tag << tsk.bit
+		jet.Flavor = int32(pdgmax)
 
 		output = append(output, *jet)
 	}
@@ -239,6 +259,12 @@ func newBTagging(typ, name string, mgr fwk.App) (fwk.Component, error) {
 		return nil, err
 	}
 
+	// Efficiency overrides Eff, when it has formulas.
+	err = tsk.DeclProp("Efficiency", &tsk.effs)
+	if err != nil {
+		return nil, err
+	}
+
 	err = tsk.DeclProp("Seed", &tsk.seed)
 	if err != nil {
 		return nil, err
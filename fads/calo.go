@@ -159,6 +159,11 @@ type Calorimeter struct {
 	ecalres func(eta, ene float64) float64
 	hcalres func(eta, ene float64) float64
 
+	ecalresp EnergyResponse
+	hcalresp EnergyResponse
+	ecalrsp  *energyResponse // compiled ECal energy response, if any
+	hcalrsp  *energyResponse // compiled HCal energy response, if any
+
 	particles   string
 	tracks      string
 	towers      string
@@ -206,6 +211,22 @@ func (tsk *Calorimeter) Configure(ctx fwk.Context) error {
 		return err
 	}
 
+	if len(tsk.ecalresp.Regions) > 0 {
+		tsk.ecalrsp, err = tsk.ecalresp.compile()
+		if err != nil {
+			return err
+		}
+		tsk.ecalres = tsk.ecalrsp.resolution
+	}
+
+	if len(tsk.hcalresp.Regions) > 0 {
+		tsk.hcalrsp, err = tsk.hcalresp.compile()
+		if err != nil {
+			return err
+		}
+		tsk.hcalres = tsk.hcalrsp.resolution
+	}
+
 	tsk.src = rand.New(rand.NewSource(tsk.seed))
 	tsk.gauss = distuv.Normal{Mu: 0, Sigma: 1, Src: tsk.src}
 	return err
@@ -435,14 +456,14 @@ func (tsk *Calorimeter) Process(ctx fwk.Context) error {
 		}
 
 		ecalSigma := tsk.ecalres(calotower.Eta, calotower.ECal.Ene)
-		ecalEne := tsk.lognormal(calotower.ECal.Ene, ecalSigma)
+		ecalEne := tsk.lognormal(tsk.ecalrsp.mean(calotower.Eta, calotower.ECal.Ene), ecalSigma)
 		ecalTime := 0.0
 		if calotower.ECal.WeightTime >= 1e-9 {
 			ecalTime = calotower.ECal.Time / calotower.ECal.WeightTime
 		}
 
 		hcalSigma := tsk.hcalres(calotower.Eta, calotower.HCal.Ene)
-		hcalEne := tsk.lognormal(calotower.HCal.Ene, hcalSigma)
+		hcalEne := tsk.lognormal(tsk.hcalrsp.mean(calotower.Eta, calotower.HCal.Ene), hcalSigma)
 		hcalTime := 0.0
 		if calotower.HCal.WeightTime >= 1e-9 {
 			hcalTime = calotower.HCal.Time / calotower.HCal.WeightTime
@@ -533,6 +554,18 @@ func newCalorimeter(typ, name string, mgr fwk.App) (fwk.Component, error) {
 		return nil, err
 	}
 
+	// ECalResponse and HCalResponse override ECalResolution and
+	// HCalResolution, when they have regions.
+	err = tsk.DeclProp("ECalResponse", &tsk.ecalresp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tsk.DeclProp("HCalResponse", &tsk.hcalresp)
+	if err != nil {
+		return nil, err
+	}
+
 	// --
 
 	err = tsk.DeclProp("Particles", &tsk.particles)
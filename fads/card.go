@@ -0,0 +1,669 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go-hep.org/x/hep/fastjet"
+	"go-hep.org/x/hep/fwk"
+	"go-hep.org/x/hep/fwk/job"
+)
+
+// Card is a Delphes detector card.
+//
+// Delphes detector cards are Tcl scripts declaring the modules of a detector
+// simulation and their parameters, e.g.:
+//
+//	set ExecutionPath {
+//	  ParticlePropagator
+//	  ChargedHadronTrackingEfficiency
+//	}
+//
+//	module ParticlePropagator ParticlePropagator {
+//	  set InputArray Delphes/stableParticles
+//	  set OutputArray stableParticles
+//	  set Radius 1.15
+//	}
+//
+//	module Efficiency ChargedHadronTrackingEfficiency {
+//	  set InputArray ParticlePropagator/chargedHadrons
+//	  set OutputArray chargedHadrons
+//	  set EfficiencyFormula {
+//	    (pt <= 0.1) * (0.00) +
+//	    (abs(eta) <= 2.5) * (pt > 0.1) * (0.95)
+//	  }
+//	}
+//
+// Only the subset of Tcl used by detector cards is supported: the set, add,
+// module, for, foreach, incr, expr and list commands.
+type Card struct {
+	ExecutionPath []string          // names of the modules to run, in order
+	Modules       []*CardModule     // modules declared by the card
+	Vars          map[string]string // global variables of the card
+}
+
+// CardModule is a module declared in a Delphes detector card.
+type CardModule struct {
+	Type   string            // Delphes type of the module (e.g. "Efficiency")
+	Name   string            // name of the module
+	Params map[string]string // parameters of the module, as Tcl values
+}
+
+// ParseCard parses a Delphes detector card.
+func ParseCard(r io.Reader) (*Card, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("fads: could not read card: %w", err)
+	}
+
+	card := &Card{
+		Vars: make(map[string]string),
+	}
+	_, err = newTclInterp(card).eval(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if path, ok := card.Vars["ExecutionPath"]; ok {
+		card.ExecutionPath, err = splitTclList(path)
+		if err != nil {
+			return nil, fmt.Errorf("fads: invalid execution path: %w", err)
+		}
+	}
+
+	return card, nil
+}
+
+// Module returns the module with the provided name, or nil.
+func (card *Card) Module(name string) *CardModule {
+	for _, m := range card.Modules {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Configs returns the configurations of the fads components corresponding
+// to the modules of the execution path of the card, in order.
+//
+// The arrays of the card are mapped to "/fads/<module>/<array>" keys of the
+// event store, and the Delphes/allParticles, Delphes/stableParticles and
+// Delphes/partons arrays to the outputs of HepMcReader.
+// As the BTagging and TauTagging modules of Delphes modify their input jets
+// in place, the subsequent modules of the execution path read their output
+// jets instead.
+//
+// Configs returns the names of the modules of the execution path without a
+// fads counterpart, which are skipped.
+func (card *Card) Configs() ([]job.C, []string, error) {
+	var (
+		cfgs    []job.C
+		skipped []string
+		aliases = make(map[string]string)
+	)
+	for _, name := range card.ExecutionPath {
+		m := card.Module(name)
+		if m == nil {
+			return nil, nil, fmt.Errorf("fads: no module %q in card", name)
+		}
+		conv, ok := cardModules[m.Type]
+		if !ok {
+			skipped = append(skipped, name)
+			continue
+		}
+		p := cardParams{m: m, aliases: aliases}
+		cfg := conv(&p)
+		if p.err != nil {
+			return nil, nil, fmt.Errorf("fads: could not configure module %q: %w", name, p.err)
+		}
+		cfgs = append(cfgs, cfg)
+	}
+	return cfgs, skipped, nil
+}
+
+// Branches returns the ports of the event store of the arrays written out by
+// the TreeWriter modules of the execution path of the card, and produced by
+// HepMcReader or by the fads components of the card.
+func (card *Card) Branches() ([]fwk.Port, error) {
+	var ports []fwk.Port
+	for _, name := range card.ExecutionPath {
+		m := card.Module(name)
+		if m == nil || m.Type != "TreeWriter" {
+			continue
+		}
+		branches, err := splitTclList(m.Params["Branch"])
+		if err != nil {
+			return nil, fmt.Errorf("fads: invalid branches of module %q: %w", name, err)
+		}
+		// branches are triplets of (input array, branch name, class name).
+		for i := 0; i < len(branches); i += 3 {
+			var (
+				array = branches[i]
+				typ   = reflect.TypeOf([]Candidate{})
+			)
+			mod, arr, _ := strings.Cut(array, "/")
+			if mod != "Delphes" {
+				m := card.Module(mod)
+				if m == nil || !card.inPath(m.Name) {
+					continue
+				}
+				if _, ok := cardModules[m.Type]; !ok {
+					continue
+				}
+				if m.Type == "Merger" {
+					p := cardParams{m: m}
+					if arr == p.str("MomentumOutputArray", "momentum") || arr == p.str("EnergyOutputArray", "energy") {
+						typ = reflect.TypeOf(Candidate{})
+					}
+				}
+			}
+			ports = append(ports, fwk.Port{Name: cardArray(array), Type: typ})
+		}
+	}
+	return ports, nil
+}
+
+func (card *Card) inPath(name string) bool {
+	for _, v := range card.ExecutionPath {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// cardArray returns the key in the event store of a Delphes array.
+func cardArray(name string) string {
+	switch name {
+	case "Delphes/allParticles":
+		return "/fads/AllParticles"
+	case "Delphes/stableParticles":
+		return "/fads/StableParticles"
+	case "Delphes/partons":
+		return "/fads/Partons"
+	}
+	return "/fads/" + name
+}
+
+// cardParams reads the parameters of a module.
+// The first error encountered is recorded, after which the methods of
+// cardParams return their default values.
+type cardParams struct {
+	m       *CardModule
+	aliases map[string]string // arrays modified in place, and their fads counterpart
+	err     error
+}
+
+func (p *cardParams) get(name string) (string, bool) {
+	if p.err != nil {
+		return "", false
+	}
+	v, ok := p.m.Params[name]
+	return strings.TrimSpace(v), ok
+}
+
+func (p *cardParams) str(name, def string) string {
+	v, ok := p.get(name)
+	if !ok {
+		return def
+	}
+	return v
+}
+
+func (p *cardParams) float(name string, def float64) float64 {
+	v, ok := p.get(name)
+	if !ok {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		p.err = fmt.Errorf("invalid parameter %s=%q: %w", name, v, err)
+		return def
+	}
+	return f
+}
+
+func (p *cardParams) int(name string, def int) int {
+	v, ok := p.get(name)
+	if !ok {
+		return def
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		p.err = fmt.Errorf("invalid parameter %s=%q: %w", name, v, err)
+		return def
+	}
+	return i
+}
+
+func (p *cardParams) bool(name string, def bool) bool {
+	v, ok := p.get(name)
+	if !ok {
+		return def
+	}
+	switch strings.ToLower(v) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	}
+	p.err = fmt.Errorf("invalid parameter %s=%q: not a boolean", name, v)
+	return def
+}
+
+func (p *cardParams) list(name string) []string {
+	v, ok := p.get(name)
+	if !ok {
+		return nil
+	}
+	elems, err := splitTclList(v)
+	if err != nil {
+		p.err = fmt.Errorf("invalid parameter %s: %w", name, err)
+		return nil
+	}
+	return elems
+}
+
+// floats returns the numbers of a list read from the parameter name.
+func (p *cardParams) floats(name, list string) []float64 {
+	if p.err != nil {
+		return nil
+	}
+	elems, err := splitTclList(list)
+	if err != nil {
+		p.err = fmt.Errorf("invalid parameter %s: %w", name, err)
+		return nil
+	}
+	vs := make([]float64, len(elems))
+	for i, elem := range elems {
+		vs[i], err = strconv.ParseFloat(elem, 64)
+		if err != nil {
+			p.err = fmt.Errorf("invalid parameter %s: %w", name, err)
+			return nil
+		}
+	}
+	return vs
+}
+
+// pairs returns the elements of a list parameter, grouped by pairs.
+func (p *cardParams) pairs(name string) [][2]string {
+	elems := p.list(name)
+	if len(elems)%2 != 0 {
+		p.err = fmt.Errorf("invalid parameter %s: odd number of elements", name)
+		return nil
+	}
+	pairs := make([][2]string, 0, len(elems)/2)
+	for i := 0; i < len(elems); i += 2 {
+		pairs = append(pairs, [2]string{elems[i], elems[i+1]})
+	}
+	return pairs
+}
+
+// input returns the key of an input array.
+func (p *cardParams) input(name, def string) string {
+	return p.array(p.str(name, def))
+}
+
+// array returns the key of an array read by the module.
+func (p *cardParams) array(name string) string {
+	key := cardArray(name)
+	for {
+		alias, ok := p.aliases[key]
+		if !ok {
+			return key
+		}
+		key = alias
+	}
+}
+
+// output returns the key of an output array of the module.
+func (p *cardParams) output(name, def string) string {
+	return "/fads/" + p.m.Name + "/" + p.str(name, def)
+}
+
+func (p *cardParams) formula(name, def string, vars ...string) formula {
+	src := p.str(name, def)
+	if p.err != nil {
+		return nil
+	}
+	f, err := compileFormula(src, vars...)
+	if err != nil {
+		p.err = fmt.Errorf("invalid parameter %s: %w", name, err)
+		return nil
+	}
+	return f
+}
+
+// ptEtaFunc returns a formula parameter as a function of pt and eta.
+func (p *cardParams) ptEtaFunc(name, def string) func(pt, eta float64) float64 {
+	f := p.formula(name, def, "pt", "eta")
+	if f == nil {
+		return nil
+	}
+	return func(pt, eta float64) float64 {
+		return f(formulaVars{pt: pt, eta: eta})
+	}
+}
+
+// etaEneFunc returns a formula parameter as a function of eta and energy.
+func (p *cardParams) etaEneFunc(name, def string) func(eta, ene float64) float64 {
+	f := p.formula(name, def, "eta", "energy")
+	if f == nil {
+		return nil
+	}
+	return func(eta, ene float64) float64 {
+		return f(formulaVars{eta: eta, energy: ene})
+	}
+}
+
+// effs returns the efficiency formulas of a tagging module, indexed by PDG code.
+func (p *cardParams) effs(name string) map[int]func(pt, eta float64) float64 {
+	te := TagEfficiency{Formulas: make(map[int]string)}
+	for _, pair := range p.pairs(name) {
+		pdg, err := strconv.Atoi(strings.TrimSpace(pair[0]))
+		if err != nil {
+			p.err = fmt.Errorf("invalid parameter %s: invalid PDG code %q: %w", name, pair[0], err)
+			return nil
+		}
+		te.Formulas[pdg] = pair[1]
+	}
+	effs, err := te.compile()
+	if err != nil {
+		p.err = fmt.Errorf("invalid parameter %s: %w", name, err)
+		return nil
+	}
+	return effs
+}
+
+// cardModules converts Delphes modules into fads components configurations.
+var cardModules = map[string]func(p *cardParams) job.C{
+	"ParticlePropagator": func(p *cardParams) job.C {
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.Propagator",
+			Name: p.m.Name,
+			Props: job.P{
+				"Input":          p.input("InputArray", "Delphes/stableParticles"),
+				"Output":         p.output("OutputArray", "stableParticles"),
+				"ChargedHadrons": p.output("ChargedHadronOutputArray", "chargedHadrons"),
+				"Electrons":      p.output("ElectronOutputArray", "electrons"),
+				"Muons":          p.output("MuonOutputArray", "muons"),
+				"Radius":         p.float("Radius", 1.0),
+				"HalfLength":     p.float("HalfLength", 3.0),
+				"Bz":             p.float("Bz", 0.0),
+			},
+		}
+	},
+
+	"Efficiency": func(p *cardParams) job.C {
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.Efficiency",
+			Name: p.m.Name,
+			Props: job.P{
+				"Input":  p.input("InputArray", "ParticlePropagator/stableParticles"),
+				"Output": p.output("OutputArray", "stableParticles"),
+				"Eff":    p.ptEtaFunc("EfficiencyFormula", "1.0"),
+			},
+		}
+	},
+
+	"MomentumSmearing": func(p *cardParams) job.C {
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.MomentumSmearing",
+			Name: p.m.Name,
+			Props: job.P{
+				"Input":      p.input("InputArray", "ParticlePropagator/stableParticles"),
+				"Output":     p.output("OutputArray", "stableParticles"),
+				"Resolution": p.ptEtaFunc("ResolutionFormula", "0.0"),
+			},
+		}
+	},
+
+	"EnergySmearing": func(p *cardParams) job.C {
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.EnergySmearing",
+			Name: p.m.Name,
+			Props: job.P{
+				"Input":      p.input("InputArray", "ParticlePropagator/stableParticles"),
+				"Output":     p.output("OutputArray", "stableParticles"),
+				"Resolution": p.etaEneFunc("ResolutionFormula", "0.0"),
+			},
+		}
+	},
+
+	"EnergyScale": func(p *cardParams) job.C {
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.EnergyScale",
+			Name: p.m.Name,
+			Props: job.P{
+				"Input":  p.input("InputArray", "FastJetFinder/jets"),
+				"Output": p.output("OutputArray", "jets"),
+				"Scale":  p.ptEtaFunc("ScaleFormula", "1.0"),
+			},
+		}
+	},
+
+	"Merger": func(p *cardParams) job.C {
+		var inputs []string
+		for _, v := range p.list("InputArray") {
+			inputs = append(inputs, p.array(v))
+		}
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.Merger",
+			Name: p.m.Name,
+			Props: job.P{
+				"Inputs":         inputs,
+				"Output":         p.output("OutputArray", "candidates"),
+				"MomentumOutput": p.output("MomentumOutputArray", "momentum"),
+				"EnergyOutput":   p.output("EnergyOutputArray", "energy"),
+			},
+		}
+	},
+
+	"Calorimeter": func(p *cardParams) job.C {
+		var (
+			bins []EtaPhiBin
+			idx  = make(map[string]int) // phi bins -> index in bins
+		)
+		for _, pair := range p.pairs("EtaPhiBins") {
+			eta, err := strconv.ParseFloat(strings.TrimSpace(pair[0]), 64)
+			if err != nil {
+				p.err = fmt.Errorf("invalid parameter EtaPhiBins: %w", err)
+				break
+			}
+			i, ok := idx[pair[1]]
+			if !ok {
+				i = len(bins)
+				idx[pair[1]] = i
+				bins = append(bins, EtaPhiBin{PhiBins: p.floats("EtaPhiBins", pair[1])})
+			}
+			bins[i].EtaBins = append(bins[i].EtaBins, eta)
+		}
+
+		efrac := make(map[int]EneFrac)
+		for _, pair := range p.pairs("EnergyFraction") {
+			pdg, err := strconv.Atoi(strings.TrimSpace(pair[0]))
+			if err != nil {
+				p.err = fmt.Errorf("invalid parameter EnergyFraction: invalid PDG code %q: %w", pair[0], err)
+				break
+			}
+			fs := p.floats("EnergyFraction", pair[1])
+			if len(fs) != 2 {
+				if p.err == nil {
+					p.err = fmt.Errorf("invalid parameter EnergyFraction: invalid fractions %q", pair[1])
+				}
+				break
+			}
+			efrac[pdg] = EneFrac{ECal: fs[0], HCal: fs[1]}
+		}
+
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.Calorimeter",
+			Name: p.m.Name,
+			Props: job.P{
+				"Particles":   p.input("ParticleInputArray", "ParticlePropagator/stableParticles"),
+				"Tracks":      p.input("TrackInputArray", "ParticlePropagator/tracks"),
+				"Towers":      p.output("TowerOutputArray", "towers"),
+				"Photons":     p.output("PhotonOutputArray", "photons"),
+				"EFlowTracks": p.output("EFlowTrackOutputArray", "eflowTracks"),
+				"EFlowTowers": p.output("EFlowTowerOutputArray", "eflowTowers"),
+
+				"EtaPhiBins":     NewEtaPhiGrid(bins),
+				"EnergyFraction": efrac,
+				"ECalResolution": p.etaEneFunc("ECalResolutionFormula", "0.0"),
+				"HCalResolution": p.etaEneFunc("HCalResolutionFormula", "0.0"),
+			},
+		}
+	},
+
+	"Isolation": func(p *cardParams) job.C {
+		rhos := ""
+		if _, ok := p.get("RhoInputArray"); ok {
+			rhos = p.input("RhoInputArray", "")
+		}
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.Isolation",
+			Name: p.m.Name,
+			Props: job.P{
+				"Candidates": p.input("CandidateInputArray", "Calorimeter/electrons"),
+				"Isolations": p.input("IsolationInputArray", "Delphes/partons"),
+				"Rhos":       rhos,
+				"Output":     p.output("OutputArray", "electrons"),
+
+				"DeltaRMax":  p.float("DeltaRMax", 0.5),
+				"PtRatioMax": p.float("PTRatioMax", 0.1),
+				"PtSumMax":   p.float("PTSumMax", 5.0),
+				"UsePtSum":   p.bool("UsePTSum", false),
+				"PtMin":      p.float("PTMin", 0.5),
+			},
+		}
+	},
+
+	"FastJetFinder": func(p *cardParams) job.C {
+		var alg fastjet.JetAlgorithm
+		switch v := p.int("JetAlgorithm", 6); v {
+		case 4:
+			alg = fastjet.KtAlgorithm
+		case 5:
+			alg = fastjet.CambridgeAlgorithm
+		case 6:
+			alg = fastjet.AntiKtAlgorithm
+		default:
+			if p.err == nil {
+				p.err = fmt.Errorf("unsupported jet algorithm %d", v)
+			}
+		}
+
+		etas := make(map[float64]float64)
+		for _, pair := range p.pairs("RhoEtaRange") {
+			vs := p.floats("RhoEtaRange", pair[0]+" "+pair[1])
+			if len(vs) == 2 {
+				etas[vs[0]] = vs[1]
+			}
+		}
+
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.FastJetFinder",
+			Name: p.m.Name,
+			Props: job.P{
+				"Input":  p.input("InputArray", "Calorimeter/towers"),
+				"Output": p.output("OutputArray", "jets"),
+				"Rho":    p.output("RhoOutputArray", "rho"),
+
+				"JetAlgorithm":     alg,
+				"ParameterR":       p.float("ParameterR", 0.5),
+				"JetPtMin":         p.float("JetPTMin", 10.0),
+				"ConeRadius":       p.float("ConeRadius", 0.5),
+				"SeedThreshold":    p.float("SeedThreshold", 1.0),
+				"ConeAreaFraction": p.float("ConeAreaFraction", 1.0),
+				"MaxIterations":    p.int("MaxIterations", 100),
+				"MaxPairSize":      p.int("MaxPairSize", 2),
+				"Iratch":           p.int("Iratch", 1),
+				"AdjacencyCut":     p.int("AdjacencyCut", 2),
+				"OverlapThreshold": p.float("OverlapThreshold", 0.75),
+
+				"AreaAlgorithm": p.int("AreaAlgorithm", 0),
+				"ComputeRho":    p.bool("ComputeRho", false),
+				"GhostEtaMax":   p.float("GhostEtaMax", 5.0),
+				"Repeat":        p.int("Repeat", 1),
+				"GhostArea":     p.float("GhostArea", 0.01),
+				"GridScatter":   p.float("GridScatter", 1.0),
+				"PtScatter":     p.float("PtScatter", 0.1),
+				"MeanGhostPt":   p.float("MeanGhostPt", 1e-100),
+
+				"EffectiveRfact": p.float("EffectiveRfact", 1.0),
+				"RhoEtaRange":    etas,
+			},
+		}
+	},
+
+	"BTagging": func(p *cardParams) job.C {
+		jets := p.input("JetInputArray", "FastJetFinder/jets")
+		output := p.output("OutputArray", "jets")
+		if _, ok := p.get("OutputArray"); !ok {
+			p.aliases[jets] = output
+		}
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.BTagging",
+			Name: p.m.Name,
+			Props: job.P{
+				"Partons": p.input("PartonInputArray", "Delphes/partons"),
+				"Jets":    jets,
+				"Output":  output,
+
+				"BitNumber":    uint(p.int("BitNumber", 0)),
+				"DeltaR":       p.float("DeltaR", 0.5),
+				"PartonPtMin":  p.float("PartonPTMin", 1.0),
+				"PartonEtaMax": p.float("PartonEtaMax", 2.5),
+				"Eff":          p.effs("EfficiencyFormula"),
+			},
+		}
+	},
+
+	"TauTagging": func(p *cardParams) job.C {
+		jets := p.input("JetInputArray", "FastJetFinder/jets")
+		output := p.output("OutputArray", "jets")
+		if _, ok := p.get("OutputArray"); !ok {
+			p.aliases[jets] = output
+		}
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.TauTagging",
+			Name: p.m.Name,
+			Props: job.P{
+				"Particles": p.input("ParticleInputArray", "Delphes/allParticles"),
+				"Partons":   p.input("PartonInputArray", "Delphes/partons"),
+				"Jets":      jets,
+				"Output":    output,
+
+				"DeltaR":    p.float("DeltaR", 0.5),
+				"TauPtMin":  p.float("TauPTMin", 1.0),
+				"TauEtaMax": p.float("TauEtaMax", 2.5),
+				"Eff":       p.effs("EfficiencyFormula"),
+			},
+		}
+	},
+
+	"UniqueObjectFinder": func(p *cardParams) job.C {
+		var keys []ObjPair
+		for _, pair := range p.pairs("InputArray") {
+			keys = append(keys, ObjPair{
+				In:  p.array(pair[0]),
+				Out: "/fads/" + p.m.Name + "/" + pair[1],
+			})
+		}
+		return job.C{
+			Type: "go-hep.org/x/hep/fads.UniqueObjectFinder",
+			Name: p.m.Name,
+			Props: job.P{
+				"Keys": keys,
+			},
+		}
+	},
+}
@@ -0,0 +1,187 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"go-hep.org/x/hep/fwk/job"
+)
+
+func TestParseCardATLAS(t *testing.T) {
+	f, err := os.Open("testdata/delphes_card_ATLAS.tcl")
+	if err != nil {
+		t.Fatalf("could not open card: %+v", err)
+	}
+	defer f.Close()
+
+	card, err := ParseCard(f)
+	if err != nil {
+		t.Fatalf("could not parse card: %+v", err)
+	}
+
+	if got, want := len(card.ExecutionPath), 25; got != want {
+		t.Fatalf("invalid execution path length: got=%d, want=%d", got, want)
+	}
+	if got, want := card.ExecutionPath[0], "ParticlePropagator"; got != want {
+		t.Fatalf("invalid first module: got=%q, want=%q", got, want)
+	}
+	for _, name := range card.ExecutionPath {
+		if card.Module(name) == nil {
+			t.Fatalf("no module %q in card", name)
+		}
+	}
+
+	t.Run("calorimeter", func(t *testing.T) {
+		m := card.Module("Calorimeter")
+		if m == nil {
+			t.Fatalf("no calorimeter module")
+		}
+		pi, err := strconv.ParseFloat(m.Params["pi"], 64)
+		if err != nil {
+			t.Fatalf("invalid pi: %+v", err)
+		}
+		if pi != math.Pi {
+			t.Fatalf("invalid pi: got=%v, want=%v", pi, math.Pi)
+		}
+
+		// the last PhiBins are the 20 degrees towers.
+		phis, err := splitTclList(m.Params["PhiBins"])
+		if err != nil {
+			t.Fatalf("invalid phi bins: %+v", err)
+		}
+		if got, want := len(phis), 19; got != want {
+			t.Fatalf("invalid number of phi bins: got=%d, want=%d", got, want)
+		}
+
+		bins, err := splitTclList(m.Params["EtaPhiBins"])
+		if err != nil {
+			t.Fatalf("invalid eta-phi bins: %+v", err)
+		}
+		if got, want := len(bins), 2*(54+23); got != want {
+			t.Fatalf("invalid number of eta-phi bins: got=%d, want=%d", got, want)
+		}
+		if got, want := bins[0], "-3.2"; got != want {
+			t.Fatalf("invalid first eta bin: got=%q, want=%q", got, want)
+		}
+		phis, err = splitTclList(bins[1])
+		if err != nil {
+			t.Fatalf("invalid phi bins: %+v", err)
+		}
+		if got, want := len(phis), 37; got != want {
+			t.Fatalf("invalid number of phi bins: got=%d, want=%d", got, want)
+		}
+
+		fracs, err := splitTclList(m.Params["EnergyFraction"])
+		if err != nil {
+			t.Fatalf("invalid energy fractions: %+v", err)
+		}
+		if got, want := fracs[:4], []string{"0", "0.0 1.0", "11", "1.0 0.0"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid energy fractions: got=%q, want=%q", got, want)
+		}
+	})
+
+	cfgs, skipped, err := card.Configs()
+	if err != nil {
+		t.Fatalf("could not configure card: %+v", err)
+	}
+	if len(cfgs)+len(skipped) != len(card.ExecutionPath) {
+		t.Fatalf("invalid number of configurations: got=%d+%d, want=%d", len(cfgs), len(skipped), len(card.ExecutionPath))
+	}
+
+	props := func(name string) job.P {
+		t.Helper()
+		for _, cfg := range cfgs {
+			if cfg.Name == name {
+				return cfg.Props
+			}
+		}
+		t.Fatalf("no configuration for module %q", name)
+		return nil
+	}
+
+	for _, tc := range []struct {
+		name string
+		prop string
+		pt   float64
+		eta  float64
+		want float64
+	}{
+		{"ChargedHadronTrackingEfficiency", "Eff", 0.05, 0.0, 0.00},
+		{"ChargedHadronTrackingEfficiency", "Eff", 0.5, 1.0, 0.70},
+		{"ChargedHadronTrackingEfficiency", "Eff", 10, -1.0, 0.95},
+		{"ChargedHadronTrackingEfficiency", "Eff", 0.5, 2.0, 0.60},
+		{"ChargedHadronTrackingEfficiency", "Eff", 10, -2.0, 0.85},
+		{"ChargedHadronTrackingEfficiency", "Eff", 10, 3.0, 0.00},
+		{"ElectronTrackingEfficiency", "Eff", 200, 0.0, 0.99},
+		{"ElectronTrackingEfficiency", "Eff", 50, 2.0, 0.83},
+		{"MuonTrackingEfficiency", "Eff", 10, 0.0, 0.99},
+		{"MuonTrackingEfficiency", "Eff", 10, 2.0, 0.98},
+		{"ChargedHadronMomentumSmearing", "Resolution", 5, 0.0, 0.01},
+		{"ChargedHadronMomentumSmearing", "Resolution", 100, 0.0, 0.03},
+		{"ChargedHadronMomentumSmearing", "Resolution", 300, -2.0, 0.05},
+		{"MuonMomentumSmearing", "Resolution", 75, 0.0, 0.04},
+		{"MuonMomentumSmearing", "Resolution", 150, 2.0, 0.10},
+	} {
+		fct, ok := props(tc.name)[tc.prop].(func(pt, eta float64) float64)
+		if !ok {
+			t.Fatalf("%s: invalid %s type: %T", tc.name, tc.prop, props(tc.name)[tc.prop])
+		}
+		if got := fct(tc.pt, tc.eta); math.Abs(got-tc.want) > 1e-12 {
+			t.Fatalf("%s: invalid %s(pt=%v, eta=%v): got=%v, want=%v", tc.name, tc.prop, tc.pt, tc.eta, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		eta  float64
+		ene  float64
+		want float64
+	}{
+		{0.0, 10, 10 * 0.015},
+		{1.0, 100, math.Sqrt(100*100*0.005*0.005 + 100*0.05*0.05 + 0.25*0.25)},
+		{2.8, 100, math.Sqrt(100*100*0.005*0.005 + 100*0.05*0.05 + 0.25*0.25)},
+		{-4.0, 100, math.Sqrt(100*100*0.107*0.107 + 100*2.08*2.08)},
+		{6.0, 100, 0},
+	} {
+		fct, ok := props("ElectronEnergySmearing")["Resolution"].(func(eta, ene float64) float64)
+		if !ok {
+			t.Fatalf("invalid resolution type: %T", props("ElectronEnergySmearing")["Resolution"])
+		}
+		if got := fct(tc.eta, tc.ene); math.Abs(got-tc.want) > 1e-12 {
+			t.Fatalf("invalid electron resolution(eta=%v, e=%v): got=%v, want=%v", tc.eta, tc.ene, got, tc.want)
+		}
+	}
+
+	effs, ok := props("BTagging")["Eff"].(map[int]func(pt, eta float64) float64)
+	if !ok {
+		t.Fatalf("invalid b-tagging efficiencies type: %T", props("BTagging")["Eff"])
+	}
+	for _, tc := range []struct {
+		pdg  int
+		pt   float64
+		eta  float64
+		want float64
+	}{
+		{0, 50, 0.0, 0.001},
+		{4, 10, 0.0, 0},
+		{4, 50, 0.0, 0.2 * math.Tanh(50*0.03-0.4)},
+		{4, 50, 2.0, 0.1 * math.Tanh(50*0.03-0.4)},
+		{5, 50, 0.0, 0.5 * math.Tanh(50*0.03-0.4)},
+		{5, 50, -2.0, 0.4 * math.Tanh(50*0.03-0.4)},
+		{5, 50, 3.0, 0},
+	} {
+		eff, ok := effs[tc.pdg]
+		if !ok {
+			t.Fatalf("no b-tagging efficiency for pdg=%d", tc.pdg)
+		}
+		if got := eff(tc.pt, tc.eta); math.Abs(got-tc.want) > 1e-12 {
+			t.Fatalf("invalid b-tagging efficiency(pdg=%d, pt=%v, eta=%v): got=%v, want=%v", tc.pdg, tc.pt, tc.eta, got, tc.want)
+		}
+	}
+}
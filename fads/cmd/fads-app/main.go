@@ -4,6 +4,7 @@
 
 // fads-app is a command that runs a simple ATLAS-like detector simulation,
 // modelled after the C++ Delphes ATLAS data-card.
+// The detector simulation can also be described by a Delphes detector card.
 //
 // Example:
 //
@@ -12,8 +13,11 @@
 //
 //  ex:
 //   $ fads-app -l=INFO -evtmax=-1 ./testdata/hepmc.data
+//   $ fads-app -card=./testdata/delphes_card_ATLAS.tcl ./testdata/hepmc.data
 //
 //  options:
+//    -card string
+//      	path to a Delphes detector card describing the detector
 //    -cpu-prof
 //      	enable CPU profiling
 //    -evtmax int
@@ -69,6 +73,7 @@ var (
 	cpuprof = flag.Bool("cpu-prof", false, "enable CPU profiling")
 	ptrace  = flag.String("trace", "", "path to file where to store traces")
 	output  = flag.String("o", "data.rio", "name of output events file")
+	card    = flag.String("card", "", "path to a Delphes detector card describing the detector")
 
 	abs  = math.Abs
 	sqrt = math.Sqrt
@@ -82,6 +87,7 @@ func main() {
 
 ex:
  $ fads-app -l=INFO -evtmax=-1 ./testdata/hepmc.data
+ $ fads-app -card=./testdata/delphes_card_ATLAS.tcl ./testdata/hepmc.data
 
 options:
 `,
@@ -133,26 +139,6 @@ options:
 		"MsgLevel": job.MsgLevel(*lvl),
 	})
 
-	// propagate particles in cylinder
-	app.Create(job.C{
-		Type: "go-hep.org/x/hep/fads.Propagator",
-		Name: "pprop",
-		Props: job.P{
-			"Input":          "/fads/StableParticles",
-			"Output":         "/fads/pprop/StableParticles",
-			"ChargedHadrons": "/fads/pprop/ChargedHadrons",
-			"Electrons":      "/fads/pprop/Electrons",
-			"Muons":          "/fads/pprop/Muons",
-
-			// radius of the magnetic field coverage, in meters
-			"Radius": 1.15,
-			// half-length of the magnetic field coverage, in meters
-			"HalfLength": 3.51,
-			// magnetic field
-			"Bz": 2.0,
-		},
-	})
-
 	input := "testdata/hepmc.data"
 	//input := "testdata/full.hepmc.data"
 	if flag.NArg() > 0 {
@@ -185,6 +171,58 @@ options:
 		},
 	})
 
+	var ports []fwk.Port
+	switch *card {
+	case "":
+		ports = atlas(app)
+	default:
+		ports = fromCard(app, *card)
+	}
+
+	// output
+	app.Create(job.C{
+		Type: "go-hep.org/x/hep/fwk.OutputStream",
+		Name: "rio-output",
+		Props: job.P{
+			"Ports": append([]fwk.Port{
+				{
+					Name: "/fads/McEvent",
+					Type: reflect.TypeOf(hepmc.Event{}),
+				},
+			}, ports...),
+			"Streamer": &rio.OutputStreamer{
+				Name: *output,
+			},
+		},
+	})
+
+	app.Run()
+	fmt.Printf("::: fads-app... [done] (time=%v)\n", time.Since(start))
+}
+
+// atlas configures an ATLAS-like detector simulation, and returns the ports
+// of the collections to write out.
+func atlas(app *job.Job) []fwk.Port {
+	// propagate particles in cylinder
+	app.Create(job.C{
+		Type: "go-hep.org/x/hep/fads.Propagator",
+		Name: "pprop",
+		Props: job.P{
+			"Input":          "/fads/StableParticles",
+			"Output":         "/fads/pprop/StableParticles",
+			"ChargedHadrons": "/fads/pprop/ChargedHadrons",
+			"Electrons":      "/fads/pprop/Electrons",
+			"Muons":          "/fads/pprop/Muons",
+
+			// radius of the magnetic field coverage, in meters
+			"Radius": 1.15,
+			// half-length of the magnetic field coverage, in meters
+			"HalfLength": 3.51,
+			// magnetic field
+			"Bz": 2.0,
+		},
+	})
+
 	// charged hadron tracking efficiency
 	app.Create(job.C{
 		Type: "go-hep.org/x/hep/fads.Efficiency",
@@ -799,39 +837,54 @@ options:
 		},
 	})
 
-	// output
-	app.Create(job.C{
-		Type: "go-hep.org/x/hep/fwk.OutputStream",
-		Name: "rio-output",
-		Props: job.P{
-			"Ports": []fwk.Port{
-				{
-					Name: "/fads/McEvent",
-					Type: reflect.TypeOf(hepmc.Event{}),
-				},
-				{
-					Name: "/fads/uobj-finder/jets",
-					Type: reflect.TypeOf([]fads.Candidate{}),
-				},
-				{
-					Name: "/fads/uobj-finder/electrons",
-					Type: reflect.TypeOf([]fads.Candidate{}),
-				},
-				{
-					Name: "/fads/uobj-finder/photons",
-					Type: reflect.TypeOf([]fads.Candidate{}),
-				},
-				{
-					Name: "/fads/uobj-finder/muons",
-					Type: reflect.TypeOf([]fads.Candidate{}),
-				},
-			},
-			"Streamer": &rio.OutputStreamer{
-				Name: *output,
-			},
+	return []fwk.Port{
+		{
+			Name: "/fads/uobj-finder/jets",
+			Type: reflect.TypeOf([]fads.Candidate{}),
 		},
-	})
+		{
+			Name: "/fads/uobj-finder/electrons",
+			Type: reflect.TypeOf([]fads.Candidate{}),
+		},
+		{
+			Name: "/fads/uobj-finder/photons",
+			Type: reflect.TypeOf([]fads.Candidate{}),
+		},
+		{
+			Name: "/fads/uobj-finder/muons",
+			Type: reflect.TypeOf([]fads.Candidate{}),
+		},
+	}
+}
 
-	app.Run()
-	fmt.Printf("::: fads-app... [done] (time=%v)\n", time.Since(start))
+// fromCard configures the detector simulation from a Delphes detector card,
+// and returns the ports of the collections to write out.
+func fromCard(app *job.Job, fname string) []fwk.Port {
+	f, err := os.Open(fname)
+	if err != nil {
+		log.Fatalf("could not open detector card: %+v", err)
+	}
+	defer f.Close()
+
+	card, err := fads.ParseCard(f)
+	if err != nil {
+		log.Fatalf("could not parse detector card %q: %+v", fname, err)
+	}
+
+	cfgs, skipped, err := card.Configs()
+	if err != nil {
+		log.Fatalf("could not configure detector card %q: %+v", fname, err)
+	}
+	for _, name := range skipped {
+		log.Printf("skipping unsupported module %q of detector card", name)
+	}
+	for _, cfg := range cfgs {
+		app.Create(cfg)
+	}
+
+	ports, err := card.Branches()
+	if err != nil {
+		log.Fatalf("could not configure output of detector card %q: %+v", fname, err)
+	}
+	return ports
 }
@@ -178,6 +178,7 @@ type Candidate struct {
 	IsConstituent byte   // 0 or 1 for particles being constituents
 	BTag          uint32 // b-tag information (bit-mask)
 	TauTag        uint32 // tau-tag information (bit-mask)
+	Flavor        int32  // jet flavour (PDG code of the matched parton)
 
 	Eem  float64 // electromagnetic energy
 	Ehad float64 // hadronic energy
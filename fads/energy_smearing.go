@@ -22,6 +22,8 @@ type EnergySmearing struct {
 	output string
 
 	smear func(eta, ene float64) float64
+	resp  EnergyResponse
+	rsp   *energyResponse // compiled energy response, if any
 	seed  uint64
 	src   *rand.Rand
 	srcmu sync.Mutex
@@ -40,6 +42,14 @@ func (tsk *EnergySmearing) Configure(ctx fwk.Context) error {
 		return err
 	}
 
+	if len(tsk.resp.Regions) > 0 {
+		tsk.rsp, err = tsk.resp.compile()
+		if err != nil {
+			return err
+		}
+		tsk.smear = tsk.rsp.resolution
+	}
+
 	return err
 }
 
@@ -80,7 +90,7 @@ func (tsk *EnergySmearing) Process(ctx fwk.Context) error {
 
 		// apply smearing
 		tsk.srcmu.Lock()
-		smearEne := distuv.Normal{Mu: ene, Sigma: tsk.smear(eta, ene), Src: tsk.src}
+		smearEne := distuv.Normal{Mu: tsk.rsp.mean(eta, ene), Sigma: tsk.smear(eta, ene), Src: tsk.src}
 		ene = smearEne.Rand()
 		tsk.srcmu.Unlock()
 
@@ -136,6 +146,12 @@ func init() {
 				return nil, err
 			}
 
+			// Response overrides Resolution, when it has regions.
+			err = tsk.DeclProp("Response", &tsk.resp)
+			if err != nil {
+				return nil, err
+			}
+
 			err = tsk.DeclProp("Seed", &tsk.seed)
 			if err != nil {
 				return nil, err
@@ -3,4 +3,9 @@
 // license that can be found in the LICENSE file.
 
 // Package fads exposes building blocks for a fast simulation of a HEP detector.
+//
+// Detector parameterizations, such as EnergyResponse and TagEfficiency, are
+// described with formulas using the syntax of Delphes detector cards rather
+// than with Go functions: they only hold data, so they can be saved with the
+// configuration of a job.
 package fads // import "go-hep.org/x/hep/fads"
@@ -0,0 +1,379 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// formulaVars holds the values of the variables of a formula.
+type formulaVars struct {
+	pt     float64
+	eta    float64
+	phi    float64
+	energy float64
+}
+
+// formula is a compiled Delphes formula, as used in detector cards for
+// efficiencies, resolutions and scales.
+type formula func(vs formulaVars) float64
+
+var formulaVarNames = map[string]formula{
+	"pt":     func(vs formulaVars) float64 { return vs.pt },
+	"eta":    func(vs formulaVars) float64 { return vs.eta },
+	"phi":    func(vs formulaVars) float64 { return vs.phi },
+	"energy": func(vs formulaVars) float64 { return vs.energy },
+}
+
+var formulaFuncs = map[string]interface{}{
+	"abs":    math.Abs,
+	"fabs":   math.Abs,
+	"sqrt":   math.Sqrt,
+	"exp":    math.Exp,
+	"log":    math.Log,
+	"log10":  math.Log10,
+	"sin":    math.Sin,
+	"cos":    math.Cos,
+	"tan":    math.Tan,
+	"asin":   math.Asin,
+	"acos":   math.Acos,
+	"atan":   math.Atan,
+	"sinh":   math.Sinh,
+	"cosh":   math.Cosh,
+	"tanh":   math.Tanh,
+	"floor":  math.Floor,
+	"ceil":   math.Ceil,
+	"round":  math.Round,
+	"int":    math.Trunc,
+	"double": func(x float64) float64 { return x },
+	"pow":    math.Pow,
+	"atan2":  math.Atan2,
+	"min":    math.Min,
+	"max":    math.Max,
+	"quad":   formulaQuad,
+}
+
+// formulaQuad returns the quadratic sum of its arguments.
+func formulaQuad(xs ...float64) float64 {
+	sum := 0.0
+	for _, x := range xs {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+// compileFormula compiles a Delphes formula.
+//
+// Formulas are arithmetic expressions, with the C operators, the ^ (or **)
+// power operator, the pi constant and the usual mathematical functions.
+// The quad function returns the quadratic sum of its arguments, e.g.
+// quad(a/sqrt(energy), b, c/energy) for a/√E ⊕ b ⊕ c/E.
+// Comparisons and logical operators evaluate to 1 or 0, so formulas are
+// usually written as sums of products of conditions and values:
+//
+//	(abs(eta) <= 1.5) * (pt > 1.0) * 0.95 + (abs(eta) > 1.5) * 0.85
+//
+// Formulas may only use the provided variables, among pt, eta, phi and
+// energy.
+func compileFormula(src string, vars ...string) (formula, error) {
+	toks, err := lexFormula(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid formula %q: %w", src, err)
+	}
+	p := formulaParser{toks: toks, vars: vars}
+	f, err := p.parseBinary(1)
+	if err == nil && p.pos < len(p.toks) {
+		err = fmt.Errorf("unexpected %q", p.toks[p.pos].s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid formula %q: %w", src, err)
+	}
+	return f, nil
+}
+
+type formulaToken struct {
+	num bool // whether the token is a number
+	s   string
+	v   float64
+}
+
+func lexFormula(src string) ([]formulaToken, error) {
+	var toks []formulaToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case isTclSpace(c) || c == '\\':
+			i++
+
+		case ('0' <= c && c <= '9') || (c == '.' && i+1 < len(src) && '0' <= src[i+1] && src[i+1] <= '9'):
+			j := i
+			for j < len(src) && (('0' <= src[j] && src[j] <= '9') || src[j] == '.') {
+				j++
+			}
+			if j < len(src) && (src[j] == 'e' || src[j] == 'E') {
+				k := j + 1
+				if k < len(src) && (src[k] == '+' || src[k] == '-') {
+					k++
+				}
+				if k < len(src) && '0' <= src[k] && src[k] <= '9' {
+					for j = k; j < len(src) && '0' <= src[j] && src[j] <= '9'; j++ {
+					}
+				}
+			}
+			v, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", src[i:j])
+			}
+			toks = append(toks, formulaToken{num: true, s: src[i:j], v: v})
+			i = j
+
+		case c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			j := i
+			for j < len(src) && isTclVarChar(src[j]) && src[j] != ':' {
+				j++
+			}
+			toks = append(toks, formulaToken{s: src[i:j]})
+			i = j
+
+		default:
+			op := ""
+			for _, v := range []string{
+				"||", "&&", "==", "!=", "<=", ">=", "**",
+				"<", ">", "+", "-", "*", "/", "%", "^", "!", "(", ")", ",",
+			} {
+				if strings.HasPrefix(src[i:], v) {
+					op = v
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("invalid character %q", c)
+			}
+			toks = append(toks, formulaToken{s: op})
+			i += len(op)
+		}
+	}
+	return toks, nil
+}
+
+type formulaParser struct {
+	toks []formulaToken
+	pos  int
+	vars []string
+}
+
+func (p *formulaParser) next() (formulaToken, bool) {
+	if p.pos >= len(p.toks) {
+		return formulaToken{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+func (p *formulaParser) expect(s string) error {
+	tok, ok := p.next()
+	if !ok {
+		return fmt.Errorf("missing %q", s)
+	}
+	if tok.num || tok.s != s {
+		return fmt.Errorf("unexpected %q (want %q)", tok.s, s)
+	}
+	p.pos++
+	return nil
+}
+
+// formulaPrecs holds the precedence of the binary operators.
+var formulaPrecs = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+func formulaBool(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// parseBinary parses a sequence of binary operations with a precedence
+// greater or equal to prec.
+func (p *formulaParser) parseBinary(prec int) (formula, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.next()
+		if !ok || tok.num {
+			return lhs, nil
+		}
+		oprec, ok := formulaPrecs[tok.s]
+		if !ok || oprec < prec {
+			return lhs, nil
+		}
+		p.pos++
+		rhs, err := p.parseBinary(oprec + 1)
+		if err != nil {
+			return nil, err
+		}
+		x, y := lhs, rhs
+		switch tok.s {
+		case "||":
+			lhs = func(vs formulaVars) float64 { return formulaBool(x(vs) != 0 || y(vs) != 0) }
+		case "&&":
+			lhs = func(vs formulaVars) float64 { return formulaBool(x(vs) != 0 && y(vs) != 0) }
+		case "==":
+			lhs = func(vs formulaVars) float64 { return formulaBool(x(vs) == y(vs)) }
+		case "!=":
+			lhs = func(vs formulaVars) float64 { return formulaBool(x(vs) != y(vs)) }
+		case "<":
+			lhs = func(vs formulaVars) float64 { return formulaBool(x(vs) < y(vs)) }
+		case "<=":
+			lhs = func(vs formulaVars) float64 { return formulaBool(x(vs) <= y(vs)) }
+		case ">":
+			lhs = func(vs formulaVars) float64 { return formulaBool(x(vs) > y(vs)) }
+		case ">=":
+			lhs = func(vs formulaVars) float64 { return formulaBool(x(vs) >= y(vs)) }
+		case "+":
+			lhs = func(vs formulaVars) float64 { return x(vs) + y(vs) }
+		case "-":
+			lhs = func(vs formulaVars) float64 { return x(vs) - y(vs) }
+		case "*":
+			lhs = func(vs formulaVars) float64 { return x(vs) * y(vs) }
+		case "/":
+			lhs = func(vs formulaVars) float64 { return x(vs) / y(vs) }
+		case "%":
+			lhs = func(vs formulaVars) float64 { return math.Mod(x(vs), y(vs)) }
+		}
+	}
+}
+
+func (p *formulaParser) parseUnary() (formula, error) {
+	tok, ok := p.next()
+	if ok && !tok.num {
+		switch tok.s {
+		case "-", "+", "!":
+			p.pos++
+			x, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			switch tok.s {
+			case "-":
+				return func(vs formulaVars) float64 { return -x(vs) }, nil
+			case "!":
+				return func(vs formulaVars) float64 { return formulaBool(x(vs) == 0) }, nil
+			}
+			return x, nil
+		}
+	}
+	return p.parsePower()
+}
+
+// parsePower parses a power operation, which is right-associative and binds
+// tighter than the unary operators.
+func (p *formulaParser) parsePower() (formula, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	tok, ok := p.next()
+	if !ok || tok.num || (tok.s != "^" && tok.s != "**") {
+		return x, nil
+	}
+	p.pos++
+	y, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return func(vs formulaVars) float64 { return math.Pow(x(vs), y(vs)) }, nil
+}
+
+func (p *formulaParser) parsePrimary() (formula, error) {
+	tok, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of formula")
+	}
+	p.pos++
+
+	switch {
+	case tok.num:
+		v := tok.v
+		return func(formulaVars) float64 { return v }, nil
+
+	case tok.s == "(":
+		x, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+
+	case tok.s == "pi":
+		return func(formulaVars) float64 { return math.Pi }, nil
+	}
+
+	if fct, ok := formulaFuncs[tok.s]; ok {
+		err := p.expect("(")
+		if err != nil {
+			return nil, err
+		}
+		var args []formula
+		for {
+			arg, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if next, ok := p.next(); ok && !next.num && next.s == "," {
+				p.pos++
+				continue
+			}
+			break
+		}
+		err = p.expect(")")
+		if err != nil {
+			return nil, err
+		}
+
+		switch fct := fct.(type) {
+		case func(float64) float64:
+			if len(args) != 1 {
+				return nil, fmt.Errorf("invalid number of arguments to %s (got=%d, want=1)", tok.s, len(args))
+			}
+			x := args[0]
+			return func(vs formulaVars) float64 { return fct(x(vs)) }, nil
+		case func(float64, float64) float64:
+			if len(args) != 2 {
+				return nil, fmt.Errorf("invalid number of arguments to %s (got=%d, want=2)", tok.s, len(args))
+			}
+			x, y := args[0], args[1]
+			return func(vs formulaVars) float64 { return fct(x(vs), y(vs)) }, nil
+		case func(...float64) float64:
+			return func(vs formulaVars) float64 {
+				xs := make([]float64, len(args))
+				for i, arg := range args {
+					xs[i] = arg(vs)
+				}
+				return fct(xs...)
+			}, nil
+		}
+	}
+
+	for _, name := range p.vars {
+		if name == tok.s {
+			return formulaVarNames[name], nil
+		}
+	}
+	if c := tok.s[0]; c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') {
+		return nil, fmt.Errorf("unknown identifier %q", tok.s)
+	}
+	return nil, fmt.Errorf("unexpected %q", tok.s)
+}
@@ -0,0 +1,130 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormulaQuad(t *testing.T) {
+	for _, tc := range []struct {
+		xs   []float64
+		want float64
+	}{
+		{nil, 0},
+		{[]float64{-2}, 2},
+		{[]float64{3, 4}, 5},
+		{[]float64{1, 2, 2}, 3},
+	} {
+		if got := formulaQuad(tc.xs...); got != tc.want {
+			t.Fatalf("invalid quad%v: got=%v, want=%v", tc.xs, got, tc.want)
+		}
+	}
+
+	f, err := compileFormula("quad(0.5/sqrt(energy), 0.02, 1/energy)", "energy")
+	if err != nil {
+		t.Fatalf("could not compile formula: %+v", err)
+	}
+	want := math.Sqrt(0.05*0.05 + 0.02*0.02 + 0.01*0.01)
+	if got := f(formulaVars{energy: 100}); math.Abs(got-want) > 1e-15 {
+		t.Fatalf("invalid formula value: got=%v, want=%v", got, want)
+	}
+}
+
+func TestFormula(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		vs   formulaVars
+		want float64
+	}{
+		{src: "1", want: 1},
+		{src: "1.5e2", want: 150},
+		{src: ".5", want: 0.5},
+		{src: "pi", want: math.Pi},
+		{src: "1 + 2*3", want: 7},
+		{src: "(1 + 2)*3", want: 9},
+		{src: "1 - 2 - 3", want: -4},
+		{src: "8 / 4 / 2", want: 1},
+		{src: "7 % 4 * 2", want: 6},
+		{src: "2^3", want: 8},
+		{src: "2**3", want: 8},
+		{src: "2^3^2", want: 512},
+		{src: "2*3^2", want: 18},
+		{src: "-2^2", want: -4},
+		{src: "2^-1", want: 0.5},
+		{src: "(-2)^2", want: 4},
+		{src: "--1", want: 1},
+		{src: "!0", want: 1},
+		{src: "!2", want: 0},
+		{src: "1 < 2", want: 1},
+		{src: "2 < 1", want: 0},
+		{src: "1 <= 1", want: 1},
+		{src: "1 > 1", want: 0},
+		{src: "1 >= 1", want: 1},
+		{src: "1 == 1", want: 1},
+		{src: "1 != 1", want: 0},
+		{src: "1 + 1 == 2", want: 1},
+		{src: "1 < 2 == 1", want: 1},
+		{src: "0 && 1 || 1", want: 1},
+		{src: "1 || 0 && 0", want: 1},
+		{src: "(1 || 0) && 0", want: 0},
+		{src: "2 * (3 > 1)", want: 2},
+		{src: "abs(-2) + fabs(-1)", want: 3},
+		{src: "sqrt(16)", want: 4},
+		{src: "pow(2, 10)", want: 1024},
+		{src: "min(1, 2) + max(1, 2)", want: 3},
+		{src: "atan2(1, 1)", want: math.Pi / 4},
+		{src: "quad(3, 4)", want: 5},
+		{src: "pt * eta", vs: formulaVars{pt: 2, eta: -3}, want: -6},
+		{
+			src:  "(abs(eta) <= 1.5) * (pt > 1.0) * 0.95 + (abs(eta) > 1.5) * 0.85",
+			vs:   formulaVars{pt: 10, eta: -1},
+			want: 0.95,
+		},
+		{
+			src:  "(abs(eta) <= 1.5) * (pt > 1.0) * 0.95 + (abs(eta) > 1.5) * 0.85",
+			vs:   formulaVars{pt: 0.5, eta: 1},
+			want: 0,
+		},
+		{
+			src:  "(abs(eta) <= 1.5) * (pt > 1.0) * 0.95 + (abs(eta) > 1.5) * 0.85",
+			vs:   formulaVars{pt: 10, eta: 2},
+			want: 0.85,
+		},
+		{src: "1 + \\\n 2", want: 3},
+	} {
+		t.Run(tc.src, func(t *testing.T) {
+			f, err := compileFormula(tc.src, "pt", "eta")
+			if err != nil {
+				t.Fatalf("could not compile formula: %+v", err)
+			}
+			if got := f(tc.vs); math.Abs(got-tc.want) > 1e-12 {
+				t.Fatalf("invalid formula value: got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormulaErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 2",
+		"energy",
+		"foo(1)",
+		"sqrt(1, 2)",
+		"pow(1)",
+		"1 @ 2",
+	} {
+		t.Run(src, func(t *testing.T) {
+			_, err := compileFormula(src, "pt", "eta")
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
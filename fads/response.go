@@ -0,0 +1,121 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"fmt"
+	"math"
+)
+
+// EnergyResponse describes the energy response of a detector, per region in
+// pseudo-rapidity, e.g. for a calorimeter with a barrel and a forward part:
+//
+//	fads.EnergyResponse{
+//		Regions: []fads.ResponseRegion{
+//			{EtaMax: 3.2, Resolution: "quad(0.101/sqrt(energy), 0.0017)"},
+//			{EtaMin: 3.2, EtaMax: 4.9, Resolution: "quad(0.285/sqrt(energy), 0.035)", Scale: "0.98"},
+//		},
+//	}
+//
+// Energies outside of all the regions are not smeared.
+type EnergyResponse struct {
+	Regions []ResponseRegion
+}
+
+// ResponseRegion is the energy response of a region of a detector.
+//
+// The resolution and scale are formulas of energy and eta, with the syntax
+// of the formulas of Delphes detector cards.
+type ResponseRegion struct {
+	EtaMin float64 // lower edge of the region in |η|
+	EtaMax float64 // upper edge of the region in |η|
+
+	// Resolution is the relative energy resolution σ(E)/E, e.g.
+	// "quad(a/sqrt(energy), b, c/energy)" for a/√E ⊕ b ⊕ c/E.
+	// An empty Resolution means no smearing.
+	Resolution string
+
+	// Scale is the ratio of the mean measured energy to the true energy.
+	// An empty Scale means 1.
+	Scale string
+}
+
+// energyResponse is a compiled EnergyResponse.
+type energyResponse struct {
+	regions []responseRegion
+}
+
+type responseRegion struct {
+	min, max float64
+	sigma    formula
+	scale    formula
+}
+
+func (resp EnergyResponse) compile() (*energyResponse, error) {
+	o := &energyResponse{
+		regions: make([]responseRegion, len(resp.Regions)),
+	}
+	for i, r := range resp.Regions {
+		if !(r.EtaMin <= r.EtaMax) {
+			return nil, fmt.Errorf("fads: invalid |eta| range [%v, %v] of response region %d", r.EtaMin, r.EtaMax, i)
+		}
+		reg := &o.regions[i]
+		reg.min = r.EtaMin
+		reg.max = r.EtaMax
+		for _, v := range []struct {
+			src string
+			def string
+			f   *formula
+		}{
+			{r.Resolution, "0", &reg.sigma},
+			{r.Scale, "1", &reg.scale},
+		} {
+			src := v.src
+			if src == "" {
+				src = v.def
+			}
+			f, err := compileFormula(src, "eta", "energy")
+			if err != nil {
+				return nil, fmt.Errorf("fads: invalid response region %d: %w", i, err)
+			}
+			*v.f = f
+		}
+	}
+	return o, nil
+}
+
+// region returns the first region containing eta, or nil.
+func (resp *energyResponse) region(eta float64) *responseRegion {
+	eta = math.Abs(eta)
+	for i := range resp.regions {
+		r := &resp.regions[i]
+		if r.min <= eta && eta <= r.max {
+			return r
+		}
+	}
+	return nil
+}
+
+// resolution returns the absolute energy resolution σ(E).
+func (resp *energyResponse) resolution(eta, ene float64) float64 {
+	r := resp.region(eta)
+	if r == nil || ene <= 0 {
+		return 0
+	}
+	return ene * r.sigma(formulaVars{eta: eta, energy: ene})
+}
+
+// mean returns the mean measured energy.
+// mean returns the true energy if resp is nil.
+func (resp *energyResponse) mean(eta, ene float64) float64 {
+	if resp == nil {
+		return ene
+	}
+	r := resp.region(eta)
+	if r == nil {
+		return ene
+	}
+	return ene * r.scale(formulaVars{eta: eta, energy: ene})
+}
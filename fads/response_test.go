@@ -0,0 +1,97 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestEnergyResponse(t *testing.T) {
+	resp, err := EnergyResponse{
+		Regions: []ResponseRegion{
+			{EtaMax: 3.2, Resolution: "quad(0.1/sqrt(energy), 0.01)"},
+			{EtaMin: 3.2, EtaMax: 4.9, Resolution: "quad(1.0/sqrt(energy), 0.05)", Scale: "0.98"},
+		},
+	}.compile()
+	if err != nil {
+		t.Fatalf("could not compile response: %+v", err)
+	}
+
+	for _, tc := range []struct {
+		eta, ene float64
+		region   int // index of the region, -1 if none.
+		sigma    float64
+		mean     float64
+	}{
+		{eta: 0, ene: 100, region: 0, sigma: 100 * math.Hypot(0.01, 0.01), mean: 100},
+		{eta: -2.5, ene: 25, region: 0, sigma: 25 * math.Hypot(0.02, 0.01), mean: 25},
+		{eta: 3.2, ene: 100, region: 0, sigma: 100 * math.Hypot(0.01, 0.01), mean: 100}, // first region wins at the edge.
+		{eta: -4.0, ene: 100, region: 1, sigma: 100 * math.Hypot(0.1, 0.05), mean: 98},
+		{eta: 4.9, ene: 100, region: 1, sigma: 100 * math.Hypot(0.1, 0.05), mean: 98},
+		{eta: 5.0, ene: 100, region: -1, sigma: 0, mean: 100},
+		{eta: -6.0, ene: 100, region: -1, sigma: 0, mean: 100},
+		{eta: 0, ene: 0, region: 0, sigma: 0, mean: 0},
+	} {
+		t.Run(fmt.Sprintf("eta=%v-e=%v", tc.eta, tc.ene), func(t *testing.T) {
+			var want *responseRegion
+			if tc.region >= 0 {
+				want = &resp.regions[tc.region]
+			}
+			if got := resp.region(tc.eta); got != want {
+				t.Fatalf("invalid region: got=%p, want=%p", got, want)
+			}
+			if got := resp.resolution(tc.eta, tc.ene); math.Abs(got-tc.sigma) > 1e-12 {
+				t.Fatalf("invalid resolution: got=%v, want=%v", got, tc.sigma)
+			}
+			if got := resp.mean(tc.eta, tc.ene); math.Abs(got-tc.mean) > 1e-12 {
+				t.Fatalf("invalid mean: got=%v, want=%v", got, tc.mean)
+			}
+		})
+	}
+
+	var none *energyResponse
+	if got, want := none.mean(1, 42), 42.0; got != want {
+		t.Fatalf("invalid mean w/o response: got=%v, want=%v", got, want)
+	}
+}
+
+func TestEnergyResponseInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		reg  ResponseRegion
+		want string
+	}{
+		{
+			name: "eta-range",
+			reg:  ResponseRegion{EtaMin: 2.5, EtaMax: 1.5},
+			want: "fads: invalid |eta| range [2.5, 1.5] of response region 0",
+		},
+		{
+			name: "eta-nan",
+			reg:  ResponseRegion{EtaMin: math.NaN(), EtaMax: 1.5},
+			want: "fads: invalid |eta| range [NaN, 1.5] of response region 0",
+		},
+		{
+			name: "resolution",
+			reg:  ResponseRegion{EtaMax: 2.5, Resolution: "quad(pt, 0.1)"},
+		},
+		{
+			name: "scale",
+			reg:  ResponseRegion{EtaMax: 2.5, Scale: "0.98 *"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := EnergyResponse{Regions: []ResponseRegion{tc.reg}}.compile()
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if tc.want != "" && err.Error() != tc.want {
+				t.Fatalf("invalid error:\ngot= %v\nwant=%v", err, tc.want)
+			}
+		})
+	}
+}
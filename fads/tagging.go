@@ -0,0 +1,58 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TagEfficiency describes the flavour-dependent efficiencies of a jet tagger,
+// parameterized in pT and η, e.g. for a b-tagger with light-jet and c-jet
+// mis-tag rates:
+//
+//	fads.TagEfficiency{
+//		Formulas: map[int]string{
+//			0: "0.001",
+//			4: "(pt > 15.0) * (abs(eta) <= 2.5) * 0.2*tanh(pt*0.03 - 0.4)",
+//			5: "(pt > 15.0) * (abs(eta) <= 1.2) * 0.5*tanh(pt*0.03 - 0.4) + " +
+//				"(pt > 15.0) * (abs(eta) > 1.2 && abs(eta) <= 2.5) * 0.4*tanh(pt*0.03 - 0.4)",
+//		},
+//	}
+type TagEfficiency struct {
+	// Formulas holds the efficiency formulas, indexed by the PDG code of
+	// the flavour of the jets.
+	// Formulas are functions of pt and eta, with the syntax of the formulas
+	// of Delphes detector cards.
+	//
+	// The formula of the 0 code is used for the flavours without a formula,
+	// and gives the mis-tag rate.
+	// A missing formula for the 0 code means a null mis-tag rate.
+	Formulas map[int]string
+}
+
+// compile returns the efficiency functions of the tagger, indexed by
+// the PDG code of the flavour of the jets.
+func (te TagEfficiency) compile() (map[int]func(pt, eta float64) float64, error) {
+	pdgs := make([]int, 0, len(te.Formulas))
+	for pdg := range te.Formulas {
+		pdgs = append(pdgs, pdg)
+	}
+	sort.Ints(pdgs)
+
+	effs := map[int]func(pt, eta float64) float64{
+		0: func(pt, eta float64) float64 { return 0 },
+	}
+	for _, pdg := range pdgs {
+		f, err := compileFormula(te.Formulas[pdg], "pt", "eta")
+		if err != nil {
+			return nil, fmt.Errorf("fads: invalid efficiency for PDG code %d: %w", pdg, err)
+		}
+		effs[pdg] = func(pt, eta float64) float64 {
+			return f(formulaVars{pt: pt, eta: eta})
+		}
+	}
+	return effs, nil
+}
@@ -0,0 +1,265 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/fwk"
+	"go-hep.org/x/hep/fwk/job"
+)
+
+func TestTagEfficiency(t *testing.T) {
+	te := TagEfficiency{
+		Formulas: map[int]string{
+			4: "(pt > 15.0) * (abs(eta) <= 2.5) * 0.2*tanh(pt*0.03 - 0.4)",
+			5: "(pt > 15.0) * (abs(eta) <= 1.2) * 0.5*tanh(pt*0.03 - 0.4) + " +
+				"(pt > 15.0) * (abs(eta) > 1.2 && abs(eta) <= 2.5) * 0.4*tanh(pt*0.03 - 0.4)",
+		},
+	}
+
+	effs, err := te.compile()
+	if err != nil {
+		t.Fatalf("could not compile efficiencies: %+v", err)
+	}
+
+	for _, tc := range []struct {
+		pdg     int
+		pt, eta float64
+		want    float64
+	}{
+		{pdg: 5, pt: 10, eta: 0, want: 0},
+		{pdg: 5, pt: 50, eta: 0.5, want: 0.5 * math.Tanh(50*0.03-0.4)},
+		{pdg: 5, pt: 50, eta: -2.0, want: 0.4 * math.Tanh(50*0.03-0.4)},
+		{pdg: 5, pt: 50, eta: 3.0, want: 0},
+		{pdg: 4, pt: 100, eta: -1.0, want: 0.2 * math.Tanh(100*0.03-0.4)},
+		{pdg: 0, pt: 100, eta: 0, want: 0}, // no mis-tag formula
+	} {
+		t.Run(fmt.Sprintf("pdg=%d-pt=%v-eta=%v", tc.pdg, tc.pt, tc.eta), func(t *testing.T) {
+			eff, ok := effs[tc.pdg]
+			if !ok {
+				t.Fatalf("missing efficiency")
+			}
+			if got := eff(tc.pt, tc.eta); math.Abs(got-tc.want) > 1e-12 {
+				t.Fatalf("invalid efficiency: got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+
+	te.Formulas[0] = "0.001 * (pt > 20)"
+	effs, err = te.compile()
+	if err != nil {
+		t.Fatalf("could not compile efficiencies: %+v", err)
+	}
+	if got, want := effs[0](50, 0), 0.001; got != want {
+		t.Fatalf("invalid mis-tag rate: got=%v, want=%v", got, want)
+	}
+
+	for _, src := range []string{
+		"pt *",
+		"energy > 10",
+		"foo(pt)",
+	} {
+		_, err := TagEfficiency{Formulas: map[int]string{5: src}}.compile()
+		if err == nil {
+			t.Fatalf("expected an error for formula %q", src)
+		}
+	}
+}
+
+func TestBTaggingEfficiency(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		jets []Candidate
+	)
+
+	app := job.NewJob(nil, job.P{
+		"EvtMax":   int64(1),
+		"NProcs":   0,
+		"MsgLevel": job.MsgLevel("ERROR"),
+	})
+
+	app.Create(job.C{
+		Type: "go-hep.org/x/hep/fads.tagTestInput",
+		Name: "input",
+		Props: job.P{
+			"Partons": "/fads/partons",
+			"Jets":    "/fads/jets",
+		},
+	})
+
+	app.Create(job.C{
+		Type: "go-hep.org/x/hep/fads.BTagging",
+		Name: "btag",
+		Props: job.P{
+			"Partons":   "/fads/partons",
+			"Jets":      "/fads/jets",
+			"Output":    "/fads/btag/jets",
+			"BitNumber": uint(2),
+			"Efficiency": TagEfficiency{
+				Formulas: map[int]string{
+					4: "0",
+					5: "(pt > 20) * (abs(eta) <= 2.5)",
+				},
+			},
+		},
+	})
+
+	app.Create(job.C{
+		Type: "go-hep.org/x/hep/fads.tagTestOutput",
+		Name: "output",
+		Props: job.P{
+			"Input": "/fads/btag/jets",
+			"Func": func(vs []Candidate) {
+				mu.Lock()
+				defer mu.Unlock()
+				jets = append(jets, vs...)
+			},
+		},
+	})
+
+	app.Run()
+
+	want := []struct {
+		flavor int32
+		btag   uint32
+	}{
+		{flavor: 5, btag: 1 << 2}, // b-jet, within acceptance
+		{flavor: 5, btag: 0},      // b-jet, below pt threshold
+		{flavor: 4, btag: 0},      // c-jet
+		{flavor: 0, btag: 0},      // unmatched jet
+	}
+
+	if got, want := len(jets), len(want); got != want {
+		t.Fatalf("invalid number of jets: got=%d, want=%d", got, want)
+	}
+	for i, jet := range jets {
+		if got, want := jet.Flavor, want[i].flavor; got != want {
+			t.Errorf("jet[%d]: invalid flavor: got=%d, want=%d", i, got, want)
+		}
+		if got, want := jet.BTag, want[i].btag; got != want {
+			t.Errorf("jet[%d]: invalid b-tag: got=%#b, want=%#b", i, got, want)
+		}
+	}
+}
+
+func newTagTestCand(pid int32, pt, eta, phi float64) Candidate {
+	var (
+		px = pt * math.Cos(phi)
+		py = pt * math.Sin(phi)
+		pz = pt * math.Sinh(eta)
+		e  = pt * math.Cosh(eta)
+	)
+	return Candidate{
+		Pid: pid,
+		Mom: fmom.NewPxPyPzE(px, py, pz, e),
+	}
+}
+
+// tagTestInput puts partons and jets into the event store.
+type tagTestInput struct {
+	fwk.TaskBase
+
+	partons string
+	jets    string
+}
+
+func (tsk *tagTestInput) Configure(ctx fwk.Context) error {
+	err := tsk.DeclOutPort(tsk.partons, reflect.TypeOf([]Candidate{}))
+	if err != nil {
+		return err
+	}
+	return tsk.DeclOutPort(tsk.jets, reflect.TypeOf([]Candidate{}))
+}
+
+func (tsk *tagTestInput) StartTask(ctx fwk.Context) error { return nil }
+func (tsk *tagTestInput) StopTask(ctx fwk.Context) error  { return nil }
+
+func (tsk *tagTestInput) Process(ctx fwk.Context) error {
+	partons := []Candidate{
+		newTagTestCand(-5, 50, 0.5, 0),
+		newTagTestCand(+5, 15, -1.0, 1.5),
+		newTagTestCand(+4, 50, 0.0, 3.0),
+		newTagTestCand(21, 30, 0.5, 0), // gluons have the lowest priority
+	}
+	jets := []Candidate{
+		newTagTestCand(0, 52, 0.5, 0.1),
+		newTagTestCand(0, 16, -1.0, 1.5),
+		newTagTestCand(0, 48, 0.1, 3.0),
+		newTagTestCand(0, 40, -2.0, -1.5),
+	}
+
+	store := ctx.Store()
+	err := store.Put(tsk.partons, partons)
+	if err != nil {
+		return err
+	}
+	return store.Put(tsk.jets, jets)
+}
+
+// tagTestOutput passes the jets of the event store to a function.
+type tagTestOutput struct {
+	fwk.TaskBase
+
+	input string
+	fct   func(jets []Candidate)
+}
+
+func (tsk *tagTestOutput) Configure(ctx fwk.Context) error {
+	return tsk.DeclInPort(tsk.input, reflect.TypeOf([]Candidate{}))
+}
+
+func (tsk *tagTestOutput) StartTask(ctx fwk.Context) error { return nil }
+func (tsk *tagTestOutput) StopTask(ctx fwk.Context) error  { return nil }
+
+func (tsk *tagTestOutput) Process(ctx fwk.Context) error {
+	v, err := ctx.Store().Get(tsk.input)
+	if err != nil {
+		return err
+	}
+	tsk.fct(v.([]Candidate))
+	return nil
+}
+
+func init() {
+	fwk.Register(reflect.TypeOf(tagTestInput{}),
+		func(typ, name string, mgr fwk.App) (fwk.Component, error) {
+			tsk := &tagTestInput{
+				TaskBase: fwk.NewTask(typ, name, mgr),
+			}
+			err := tsk.DeclProp("Partons", &tsk.partons)
+			if err != nil {
+				return nil, err
+			}
+			err = tsk.DeclProp("Jets", &tsk.jets)
+			if err != nil {
+				return nil, err
+			}
+			return tsk, nil
+		},
+	)
+
+	fwk.Register(reflect.TypeOf(tagTestOutput{}),
+		func(typ, name string, mgr fwk.App) (fwk.Component, error) {
+			tsk := &tagTestOutput{
+				TaskBase: fwk.NewTask(typ, name, mgr),
+			}
+			err := tsk.DeclProp("Input", &tsk.input)
+			if err != nil {
+				return nil, err
+			}
+			err = tsk.DeclProp("Func", &tsk.fct)
+			if err != nil {
+				return nil, err
+			}
+			return tsk, nil
+		},
+	)
+}
@@ -52,6 +52,16 @@ func (tag tauclassifier) Category(tau *Candidate, particles []Candidate) int {
 	return 0
 }
 
+// TauTagging tags jets originating from hadronic tau decays.
+//
+// Jets are matched to the generated taus decaying hadronically, whose visible
+// momentum is within DeltaR of the jet axis.
+// Jets are tagged with the efficiency Eff[15](pt, eta) for matched jets, and
+// Eff[0](pt, eta) otherwise, which gives the mis-tag rate.
+// The efficiencies may also be configured as pT/η formulas, with the
+// Efficiency property (see TagEfficiency).
+// The tag decision is set in the TauTag field of the output jets, and the
+// charge of matched jets is the charge of their tau.
 type TauTagging struct {
 	fwk.TaskBase
 
@@ -62,8 +72,9 @@ type TauTagging struct {
 
 	dR float64
 
-	tag tauclassifier
-	eff map[int]func(pt, eta float64) float64
+	tag  tauclassifier
+	eff  map[int]func(pt, eta float64) float64
+	effs TagEfficiency
 
 	seed uint64
 	src  *rand.Rand
@@ -75,6 +86,11 @@ type TauTagging struct {
 func (tsk *TauTagging) Configure(ctx fwk.Context) error {
 	var err error
 
+	err = tsk.DeclInPort(tsk.particles, reflect.TypeOf([]Candidate{}))
+	if err != nil {
+		return err
+	}
+
 	err = tsk.DeclInPort(tsk.partons, reflect.TypeOf([]Candidate{}))
 	if err != nil {
 		return err
@@ -90,6 +106,13 @@ func (tsk *TauTagging) Configure(ctx fwk.Context) error {
 		return err
 	}
 
+	if len(tsk.effs.Formulas) > 0 {
+		tsk.eff, err = tsk.effs.compile()
+		if err != nil {
+			return err
+		}
+	}
+
 	tsk.src = rand.New(rand.NewSource(tsk.seed))
 	tsk.flat = distuv.Uniform{Min: 0, Max: 1, Src: tsk.src}
 	return err
@@ -171,7 +194,7 @@ func (tsk *TauTagging) Process(ctx fwk.Context) error {
 			}
 
 			var p4 fmom.PxPyPzE
-			for ii := mc.D1; ii < mc.D2; ii++ {
+			for ii := mc.D1; ii <= mc.D2; ii++ {
 				daughter := &particles[ii]
 				pdg := daughter.Pid
 				if pdg == -16 || pdg == 16 {
@@ -270,6 +293,12 @@ func newTauTagging(typ, name string, mgr fwk.App) (fwk.Component, error) {
 		return nil, err
 	}
 
+	// Efficiency overrides Eff, when it has formulas.
+	err = tsk.DeclProp("Efficiency", &tsk.effs)
+	if err != nil {
+		return nil, err
+	}
+
 	err = tsk.DeclProp("Seed", &tsk.seed)
 	if err != nil {
 		return nil, err
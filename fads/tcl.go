@@ -0,0 +1,527 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tclInterp is a minimal interpreter for the subset of the Tcl language used
+// by Delphes detector cards.
+//
+// All values are strings. Lists are strings of whitespace separated
+// elements, where elements may be grouped with braces or quotes.
+// The supported commands are:
+//   - set, add (appending to a list variable), module,
+//   - for, foreach, incr, expr and list.
+type tclInterp struct {
+	card *Card
+	mod  *CardModule // module being declared, if any
+	vars map[string]string
+}
+
+func newTclInterp(card *Card) *tclInterp {
+	return &tclInterp{
+		card: card,
+		vars: card.Vars,
+	}
+}
+
+// tclParser scans a Tcl script.
+type tclParser struct {
+	src string
+	pos int
+}
+
+func (p *tclParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tclParser) peek() byte {
+	return p.src[p.pos]
+}
+
+// eval evaluates a script and returns the result of its last command.
+func (in *tclInterp) eval(script string) (string, error) {
+	var (
+		p   = &tclParser{src: script}
+		res string
+	)
+	for !p.eof() {
+		words, err := in.parseCommand(p)
+		if err != nil {
+			return "", err
+		}
+		if len(words) == 0 {
+			continue
+		}
+		res, err = in.call(words)
+		if err != nil {
+			return "", err
+		}
+	}
+	return res, nil
+}
+
+// parseCommand parses and substitutes the words of the next command.
+func (in *tclInterp) parseCommand(p *tclParser) ([]string, error) {
+	skipTclBlanks(p)
+
+	var words []string
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+			continue
+		case c == '\\' && strings.HasPrefix(p.src[p.pos:], "\\\n"):
+			p.pos += 2
+			continue
+		case c == '\n' || c == ';':
+			p.pos++
+			return words, nil
+		}
+		w, err := in.parseWord(p, true)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, w)
+	}
+	return words, nil
+}
+
+// parseWord parses the next word, performing substitutions if subst is true.
+func (in *tclInterp) parseWord(p *tclParser, subst bool) (string, error) {
+	switch p.peek() {
+	case '{':
+		beg := p.pos
+		depth := 0
+		for ; !p.eof(); p.pos++ {
+			switch p.peek() {
+			case '\\':
+				p.pos++
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if p.eof() {
+			return "", fmt.Errorf("fads: missing close-brace in card (offset=%d)", beg)
+		}
+		p.pos++
+		if !p.eof() && !isTclSep(p.peek()) {
+			return "", fmt.Errorf("fads: extra characters after close-brace in card (offset=%d)", p.pos)
+		}
+		return unfoldTclLines(p.src[beg+1 : p.pos-1]), nil
+
+	case '"':
+		beg := p.pos
+		p.pos++
+		w, err := in.subst(p, subst, func(c byte) bool { return c == '"' })
+		if err != nil {
+			return "", err
+		}
+		if p.eof() {
+			return "", fmt.Errorf("fads: missing close-quote in card (offset=%d)", beg)
+		}
+		p.pos++
+		if !p.eof() && !isTclSep(p.peek()) {
+			return "", fmt.Errorf("fads: extra characters after close-quote in card (offset=%d)", p.pos)
+		}
+		return w, nil
+
+	default:
+		return in.subst(p, subst, isTclSep)
+	}
+}
+
+// subst reads characters until stop returns true, performing backslash,
+// variable and command substitutions.
+// Only backslash substitutions are performed if cmds is false.
+func (in *tclInterp) subst(p *tclParser, cmds bool, stop func(c byte) bool) (string, error) {
+	var o strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		if stop(c) {
+			break
+		}
+		switch {
+		case c == '\\':
+			p.pos++
+			if p.eof() {
+				o.WriteByte('\\')
+				break
+			}
+			switch c := p.peek(); c {
+			case 'n':
+				o.WriteByte('\n')
+			case 't':
+				o.WriteByte('\t')
+			case '\n':
+				o.WriteByte(' ')
+				for p.pos+1 < len(p.src) && (p.src[p.pos+1] == ' ' || p.src[p.pos+1] == '\t') {
+					p.pos++
+				}
+			default:
+				o.WriteByte(c)
+			}
+			p.pos++
+
+		case c == '$' && cmds:
+			p.pos++
+			name := ""
+			switch {
+			case !p.eof() && p.peek() == '{':
+				end := strings.IndexByte(p.src[p.pos:], '}')
+				if end < 0 {
+					return "", fmt.Errorf("fads: missing close-brace for variable name in card")
+				}
+				name = p.src[p.pos+1 : p.pos+end]
+				p.pos += end + 1
+			default:
+				beg := p.pos
+				for !p.eof() && isTclVarChar(p.peek()) {
+					p.pos++
+				}
+				name = p.src[beg:p.pos]
+			}
+			if name == "" {
+				o.WriteByte('$')
+				continue
+			}
+			v, err := in.get(name)
+			if err != nil {
+				return "", err
+			}
+			o.WriteString(v)
+
+		case c == '[' && cmds:
+			beg := p.pos
+			depth := 0
+			for ; !p.eof(); p.pos++ {
+				switch p.peek() {
+				case '\\':
+					p.pos++
+				case '[':
+					depth++
+				case ']':
+					depth--
+				}
+				if depth == 0 {
+					break
+				}
+			}
+			if p.eof() {
+				return "", fmt.Errorf("fads: missing close-bracket in card (offset=%d)", beg)
+			}
+			p.pos++
+			v, err := in.eval(p.src[beg+1 : p.pos-1])
+			if err != nil {
+				return "", err
+			}
+			o.WriteString(v)
+
+		default:
+			o.WriteByte(c)
+			p.pos++
+		}
+	}
+	return o.String(), nil
+}
+
+// get returns the value of a variable, looked up in the scope of the module
+// being declared and then in the global scope.
+func (in *tclInterp) get(name string) (string, error) {
+	if in.mod != nil {
+		if v, ok := in.mod.Params[name]; ok {
+			return v, nil
+		}
+	}
+	v, ok := in.vars[name]
+	if !ok {
+		return "", fmt.Errorf("fads: can't read %q in card: no such variable", name)
+	}
+	return v, nil
+}
+
+// set sets the value of a variable in the current scope.
+func (in *tclInterp) set(name, value string) {
+	if in.mod != nil {
+		in.mod.Params[name] = value
+		return
+	}
+	in.vars[name] = value
+}
+
+func (in *tclInterp) call(words []string) (string, error) {
+	var (
+		cmd  = words[0]
+		args = words[1:]
+	)
+	nargs := func(min, max int) error {
+		if len(args) < min || (max >= 0 && len(args) > max) {
+			return fmt.Errorf("fads: wrong number of arguments to %q in card", cmd)
+		}
+		return nil
+	}
+
+	switch cmd {
+	case "set":
+		if err := nargs(1, 2); err != nil {
+			return "", err
+		}
+		if len(args) == 2 {
+			in.set(args[0], args[1])
+		}
+		return in.get(args[0])
+
+	case "add":
+		if err := nargs(1, -1); err != nil {
+			return "", err
+		}
+		v := in.vars[args[0]]
+		if in.mod != nil {
+			v = in.mod.Params[args[0]]
+		}
+		elems := make([]string, 0, len(args))
+		if v != "" {
+			elems = append(elems, v)
+		}
+		for _, arg := range args[1:] {
+			elems = append(elems, quoteTclElem(arg))
+		}
+		v = strings.Join(elems, " ")
+		in.set(args[0], v)
+		return v, nil
+
+	case "module":
+		if err := nargs(2, 3); err != nil {
+			return "", err
+		}
+		if in.mod != nil {
+			return "", fmt.Errorf("fads: nested module %q in module %q", args[1], in.mod.Name)
+		}
+		if in.card.Module(args[1]) != nil {
+			return "", fmt.Errorf("fads: duplicate module %q in card", args[1])
+		}
+		mod := &CardModule{
+			Type:   args[0],
+			Name:   args[1],
+			Params: make(map[string]string),
+		}
+		in.card.Modules = append(in.card.Modules, mod)
+		if len(args) == 3 {
+			in.mod = mod
+			defer func() { in.mod = nil }()
+			_, err := in.eval(args[2])
+			if err != nil {
+				return "", fmt.Errorf("fads: could not declare module %q: %w", mod.Name, err)
+			}
+		}
+		return "", nil
+
+	case "for":
+		if err := nargs(4, 4); err != nil {
+			return "", err
+		}
+		_, err := in.eval(args[0])
+		if err != nil {
+			return "", err
+		}
+		for {
+			v, err := in.expr(args[1])
+			if err != nil {
+				return "", err
+			}
+			if v == 0 {
+				break
+			}
+			_, err = in.eval(args[3])
+			if err != nil {
+				return "", err
+			}
+			_, err = in.eval(args[2])
+			if err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+
+	case "foreach":
+		if err := nargs(3, 3); err != nil {
+			return "", err
+		}
+		names, err := splitTclList(args[0])
+		if err != nil {
+			return "", err
+		}
+		if len(names) == 0 {
+			return "", fmt.Errorf("fads: foreach without variables in card")
+		}
+		elems, err := splitTclList(args[1])
+		if err != nil {
+			return "", err
+		}
+		for i := 0; i < len(elems); i += len(names) {
+			for j, name := range names {
+				v := ""
+				if i+j < len(elems) {
+					v = elems[i+j]
+				}
+				in.set(name, v)
+			}
+			_, err = in.eval(args[2])
+			if err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+
+	case "incr":
+		if err := nargs(1, 2); err != nil {
+			return "", err
+		}
+		inc := 1
+		if len(args) == 2 {
+			v, err := strconv.Atoi(args[1])
+			if err != nil {
+				return "", fmt.Errorf("fads: invalid increment %q in card: %w", args[1], err)
+			}
+			inc = v
+		}
+		v, err := in.get(args[0])
+		if err != nil {
+			return "", err
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return "", fmt.Errorf("fads: invalid integer variable %q in card: %w", args[0], err)
+		}
+		v = strconv.Itoa(i + inc)
+		in.set(args[0], v)
+		return v, nil
+
+	case "expr":
+		if err := nargs(1, -1); err != nil {
+			return "", err
+		}
+		v, err := in.expr(strings.Join(args, " "))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+
+	case "list":
+		elems := make([]string, len(args))
+		for i, arg := range args {
+			elems[i] = quoteTclElem(arg)
+		}
+		return strings.Join(elems, " "), nil
+	}
+
+	return "", fmt.Errorf("fads: invalid card command %q", cmd)
+}
+
+// expr evaluates an arithmetic expression, after variable and command
+// substitutions.
+// All numbers are handled as floating point numbers.
+func (in *tclInterp) expr(src string) (float64, error) {
+	v, err := in.subst(&tclParser{src: src}, true, func(byte) bool { return false })
+	if err != nil {
+		return 0, err
+	}
+	f, err := compileFormula(v)
+	if err != nil {
+		return 0, fmt.Errorf("fads: %w", err)
+	}
+	return f(formulaVars{}), nil
+}
+
+// skipTclBlanks skips blank lines, command separators and comments.
+func skipTclBlanks(p *tclParser) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ';':
+			p.pos++
+		case c == '\\' && strings.HasPrefix(p.src[p.pos:], "\\\n"):
+			p.pos += 2
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				if p.peek() == '\\' {
+					p.pos++
+				}
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// splitTclList returns the elements of a Tcl list.
+func splitTclList(list string) ([]string, error) {
+	var (
+		in    tclInterp
+		p     = &tclParser{src: list}
+		elems []string
+	)
+	for {
+		for !p.eof() && isTclSpace(p.peek()) {
+			p.pos++
+		}
+		if p.eof() {
+			return elems, nil
+		}
+		elem, err := in.parseWord(p, false)
+		if err != nil {
+			return nil, fmt.Errorf("fads: invalid list %q: %w", list, err)
+		}
+		elems = append(elems, elem)
+	}
+}
+
+// quoteTclElem quotes a string so it is a single element of a Tcl list.
+func quoteTclElem(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n;\"\\$[]{}") {
+		return "{" + s + "}"
+	}
+	return s
+}
+
+// unfoldTclLines replaces backslash-newline sequences, and the following
+// whitespace, with a single space.
+func unfoldTclLines(s string) string {
+	for {
+		i := strings.Index(s, "\\\n")
+		if i < 0 {
+			return s
+		}
+		j := i + 2
+		for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+			j++
+		}
+		s = s[:i] + " " + s[j:]
+	}
+}
+
+func isTclSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isTclSep(c byte) bool {
+	return isTclSpace(c) || c == ';'
+}
+
+func isTclVarChar(c byte) bool {
+	return c == '_' || c == ':' ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z') ||
+		('0' <= c && c <= '9')
+}
@@ -0,0 +1,177 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fads
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTclEval(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"set", "set a 1", "1"},
+		{"get", "set a 1; set a", "1"},
+		{"comment", "# set a 1\nset a 2", "2"},
+		{"semicolons", "set a 1;set b 2;", "2"},
+		{"braces", "set a {x y}", "x y"},
+		{"nested-braces", "set a {x {y z}}", "x {y z}"},
+		{"braces-no-subst", "set a 1; set b {$a [expr 1]}", "$a [expr 1]"},
+		{"quotes", `set a "x y"`, "x y"},
+		{"quotes-subst", `set a 1; set b "a=$a"`, "a=1"},
+		{"quotes-escapes", `set a "x\ty\"z"`, "x\ty\"z"},
+		{"var", "set a 1; set b $a", "1"},
+		{"var-concat", "set a 1; set b x$a.y", "x1.y"},
+		{"var-braced", "set a 1; set b ${a}x", "1x"},
+		{"var-scoped", "set a::b 1; set c $a::b", "1"},
+		{"dollar", "set a $", "$"},
+		{"expr", "expr 1 + 2", "3"},
+		{"expr-braced", "set i 3; expr {$i * 2}", "6"},
+		{"cmd-subst", "set a [expr {1 + 2*3}]", "7"},
+		{"cmd-subst-nested", "set a [expr {[expr 2] + 1}]", "3"},
+		{"cmd-subst-concat", "set a x[expr 1]y", "x1y"},
+		{"expr-pi", "expr {acos(-1)}", "3.141592653589793"},
+		{"incr", "set i 1; incr i; incr i 3", "5"},
+		{"list", `list a {b c} "" d`, "a {b c} {} d"},
+		{"add", "add l a; add l {b c}; add l d e", "a {b c} d e"},
+		{"add-empty", "set l {}; add l a", "a"},
+		{"for", "set l {}; for {set i 0} {$i < 3} {incr i} { add l $i }; set l", "0 1 2"},
+		{"foreach", "set l {}; foreach v {1 2 3} { add l [expr {$v * 2}] }; set l", "2 4 6"},
+		{"foreach-pairs", "set l {}; foreach {k v} {a 1 b 2} { add l $k=$v }; set l", "a=1 b=2"},
+		{"foreach-missing", "set l {}; foreach {k v} {a 1 b} { add l $k=$v }; set l", "a=1 b="},
+		{"foreach-list", "set l {}; foreach v {{a b} c} { add l $v }; set l", "{a b} c"},
+		{"continuation", "set a \\\n    1", "1"},
+		{"continuation-braces", "set a {x \\\n    y}", "x  y"},
+		{"continuation-quotes", "set a \"x\\\n    y\"", "x y"},
+		{"continuation-comment", "# set a 1 \\\nset a 2\nset a 3", "3"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			card := &Card{Vars: make(map[string]string)}
+			got, err := newTclInterp(card).eval(tc.script)
+			if err != nil {
+				t.Fatalf("could not evaluate script: %+v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("invalid result: got=%q, want=%q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTclEvalErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		script string
+	}{
+		{"unknown-cmd", "puts a"},
+		{"unknown-var", "set a $b"},
+		{"missing-brace", "set a {x"},
+		{"missing-quote", `set a "x`},
+		{"missing-bracket", "set a [expr 1"},
+		{"missing-var-brace", "set a ${b"},
+		{"extra-brace", "set a {x}y"},
+		{"extra-quote", `set a "x"y`},
+		{"set-nargs", "set a b c"},
+		{"incr-not-int", "set a x; incr a"},
+		{"expr-invalid", "expr {1 +}"},
+		{"foreach-no-vars", "foreach {} {a b} {}"},
+		{"nested-module", "module A a { module B b }"},
+		{"dup-module", "module A a; module A a"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			card := &Card{Vars: make(map[string]string)}
+			_, err := newTclInterp(card).eval(tc.script)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
+
+func TestTclModule(t *testing.T) {
+	card := &Card{Vars: make(map[string]string)}
+	_, err := newTclInterp(card).eval(`
+set r 1.5
+module Efficiency Eff {
+  set Radius $r
+  set r 2.5
+  set Scoped $r
+  add Arrays a
+  add Arrays b
+}
+`)
+	if err != nil {
+		t.Fatalf("could not evaluate script: %+v", err)
+	}
+
+	if got, want := card.Vars["r"], "1.5"; got != want {
+		t.Fatalf("invalid global variable: got=%q, want=%q", got, want)
+	}
+
+	m := card.Module("Eff")
+	if m == nil {
+		t.Fatalf("could not find module")
+	}
+	if got, want := m.Type, "Efficiency"; got != want {
+		t.Fatalf("invalid module type: got=%q, want=%q", got, want)
+	}
+	want := map[string]string{
+		"Radius": "1.5",
+		"r":      "2.5",
+		"Scoped": "2.5",
+		"Arrays": "a b",
+	}
+	if got := m.Params; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid module parameters:\ngot= %v\nwant=%v", got, want)
+	}
+}
+
+func TestSplitTclList(t *testing.T) {
+	for _, tc := range []struct {
+		list string
+		want []string
+	}{
+		{"", nil},
+		{"  \n ", nil},
+		{"a b  c", []string{"a", "b", "c"}},
+		{"a\n\tb", []string{"a", "b"}},
+		{"{a b} c", []string{"a b", "c"}},
+		{"{a {b c}} d", []string{"a {b c}", "d"}},
+		{`"a b" c`, []string{"a b", "c"}},
+		{"$a [b]", []string{"$a", "[b]"}},
+		{"{} a", []string{"", "a"}},
+	} {
+		t.Run(tc.list, func(t *testing.T) {
+			got, err := splitTclList(tc.list)
+			if err != nil {
+				t.Fatalf("could not split list: %+v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("invalid list: got=%q, want=%q", got, tc.want)
+			}
+		})
+	}
+
+	_, err := splitTclList("{a b")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestQuoteTclElem(t *testing.T) {
+	for _, s := range []string{"", "a", "a b", "$a", "[a]", "{a}", `a"b`, "a;b", "a\nb"} {
+		list := quoteTclElem(s) + " " + quoteTclElem("x")
+		got, err := splitTclList(list)
+		if err != nil {
+			t.Fatalf("could not split list %q: %+v", list, err)
+		}
+		if want := []string{s, "x"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid round-trip of %q: got=%q, want=%q", s, got, want)
+		}
+	}
+}
@@ -4,4 +4,124 @@
 
 package fastjet
 
-type AreaDefinition struct{}
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/exp/rand"
+)
+
+// AreaType defines the kind of jet area to compute.
+type AreaType int
+
+const (
+	// ActiveArea computes jet areas by clustering the particles together
+	// with a dense coverage of infinitely soft ghost particles.
+	// Ghosts are not reported as jet constituents and jets made only of
+	// ghosts are discarded.
+	ActiveArea AreaType = iota
+
+	// ActiveAreaExplicitGhosts is like ActiveArea, except ghosts are
+	// kept in the list of jet constituents and pure-ghost jets are kept.
+	ActiveAreaExplicitGhosts
+)
+
+func (t AreaType) String() string {
+	switch t {
+	case ActiveArea:
+		return "ActiveArea"
+	case ActiveAreaExplicitGhosts:
+		return "ActiveAreaExplicitGhosts"
+	default:
+		panic(fmt.Errorf("fastjet: invalid AreaType (%d)", int(t)))
+	}
+}
+
+// AreaDefinition describes how jet areas are computed.
+//
+// The zero value computes active areas with the ghosts described by
+// NewGhostedAreaSpec(DefaultGhostRapMax).
+type AreaDefinition struct {
+	Type   AreaType
+	Ghosts GhostedAreaSpec
+}
+
+// NewAreaDefinition returns a new area definition.
+func NewAreaDefinition(typ AreaType, ghosts GhostedAreaSpec) AreaDefinition {
+	return AreaDefinition{Type: typ, Ghosts: ghosts}
+}
+
+// DefaultGhostRapMax is the default maximal rapidity of ghosts.
+const DefaultGhostRapMax = 6
+
+// GhostedAreaSpec describes the ghost particles used to compute active areas.
+//
+// Ghosts are placed on a rapidity-phi grid covering |y| < RapMax,
+// with cells of area (close to) Area.
+// The position of each ghost is randomly shifted within its cell by a
+// fraction GridScatter of the cell size, and its transverse momentum is
+// MeanPt, randomly scattered by a fraction PtScatter.
+type GhostedAreaSpec struct {
+	RapMax      float64 // maximal rapidity of ghosts
+	Repeat      int     // number of clusterings with different ghost sets
+	Area        float64 // requested area of each ghost
+	GridScatter float64 // fractional random shift of ghosts within their grid cell
+	PtScatter   float64 // fractional random fluctuation of ghosts transverse momentum
+	MeanPt      float64 // mean transverse momentum of ghosts
+	Seed        uint64  // seed of the ghosts random number generator
+}
+
+// NewGhostedAreaSpec returns a ghost specification covering |y| < rapmax,
+// with FastJet's default settings for the other parameters.
+func NewGhostedAreaSpec(rapmax float64) GhostedAreaSpec {
+	return GhostedAreaSpec{
+		RapMax:      rapmax,
+		Repeat:      1,
+		Area:        0.01,
+		GridScatter: 1,
+		PtScatter:   0.1,
+		MeanPt:      1e-100,
+		Seed:        1234,
+	}
+}
+
+// grid returns the number of cells along the rapidity and phi directions,
+// and the actual area of each cell.
+func (spec GhostedAreaSpec) grid() (nrap, nphi int, area float64) {
+	size := math.Sqrt(spec.Area)
+	nrap = int(math.Ceil(spec.RapMax / size))
+	nphi = int(math.Ceil(2 * math.Pi / size))
+	drap := spec.RapMax / float64(nrap)
+	dphi := 2 * math.Pi / float64(nphi)
+	return 2 * nrap, nphi, drap * dphi
+}
+
+// ghosts returns a new set of ghosts, drawing random numbers from rnd.
+func (spec GhostedAreaSpec) ghosts(rnd *rand.Rand) []Jet {
+	nrap, nphi, _ := spec.grid()
+	var (
+		drap   = 2 * spec.RapMax / float64(nrap)
+		dphi   = 2 * math.Pi / float64(nphi)
+		ghosts = make([]Jet, 0, nrap*nphi)
+	)
+	for irap := 0; irap < nrap; irap++ {
+		for iphi := 0; iphi < nphi; iphi++ {
+			var (
+				rap = -spec.RapMax + (float64(irap)+0.5+spec.GridScatter*(rnd.Float64()-0.5))*drap
+				phi = (float64(iphi) + 0.5 + spec.GridScatter*(rnd.Float64()-0.5)) * dphi
+				pt  = spec.MeanPt * (1 + spec.PtScatter*(rnd.Float64()-0.5))
+			)
+			ghosts = append(ghosts, newPtYPhiJet(pt, rap, phi))
+		}
+	}
+	return ghosts
+}
+
+// newPtYPhiJet returns a massless jet with the provided transverse momentum,
+// rapidity and azimuthal angle.
+func newPtYPhiJet(pt, rap, phi float64) Jet {
+	return NewJet(
+		pt*math.Cos(phi), pt*math.Sin(phi),
+		pt*math.Sinh(rap), pt*math.Cosh(rap),
+	)
+}
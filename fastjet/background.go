@@ -0,0 +1,261 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// BackgroundEstimator estimates the transverse momentum density of the
+// (pileup or underlying event) background of an event.
+type BackgroundEstimator interface {
+	// SetParticles sets the particles of the event.
+	SetParticles(particles []Jet) error
+
+	// Rho returns the background transverse momentum density per unit area.
+	Rho() float64
+
+	// Sigma returns the fluctuations of the background transverse momentum
+	// density, for a unit area.
+	Sigma() float64
+}
+
+var (
+	_ BackgroundEstimator = (*GridMedianBackgroundEstimator)(nil)
+	_ BackgroundEstimator = (*JetMedianBackgroundEstimator)(nil)
+)
+
+// GridMedianBackgroundEstimator estimates the background density as the
+// median of the transverse momentum densities of the cells of a
+// rapidity-phi grid.
+type GridMedianBackgroundEstimator struct {
+	rapMax float64
+	nrap   int
+	nphi   int
+	drap   float64
+	dphi   float64
+
+	rho   float64
+	sigma float64
+}
+
+// NewGridMedianBackgroundEstimator returns a new background estimator using
+// a grid covering |y| < rapmax, with cells of (approximately) the provided size.
+func NewGridMedianBackgroundEstimator(rapmax, size float64) *GridMedianBackgroundEstimator {
+	if rapmax <= 0 || size <= 0 {
+		panic(fmt.Errorf("fastjet: invalid grid (rapmax=%v, size=%v)", rapmax, size))
+	}
+	est := &GridMedianBackgroundEstimator{
+		rapMax: rapmax,
+		nrap:   imax(1, int(2*rapmax/size+0.5)),
+		nphi:   imax(1, int(2*math.Pi/size+0.5)),
+	}
+	est.drap = 2 * rapmax / float64(est.nrap)
+	est.dphi = 2 * math.Pi / float64(est.nphi)
+	return est
+}
+
+// SetParticles sets the particles of the event.
+// Particles outside of the grid are ignored.
+func (est *GridMedianBackgroundEstimator) SetParticles(particles []Jet) error {
+	pts := make([]float64, est.nrap*est.nphi)
+	for i := range particles {
+		p := &particles[i]
+		rap := p.Rapidity()
+		if math.Abs(rap) >= est.rapMax {
+			continue
+		}
+		phi := p.Phi()
+		if phi < 0 {
+			phi += 2 * math.Pi
+		}
+		irap := imin(est.nrap-1, int((rap+est.rapMax)/est.drap))
+		iphi := imin(est.nphi-1, int(phi/est.dphi))
+		pts[irap*est.nphi+iphi] += p.Pt()
+	}
+
+	area := est.drap * est.dphi
+	for i := range pts {
+		pts[i] /= area
+	}
+	sort.Float64s(pts)
+
+	est.rho = percentile(pts, 0.5)
+	est.sigma = (est.rho - percentile(pts, oneSigmaBelow)) * math.Sqrt(area)
+	return nil
+}
+
+// Rho returns the background transverse momentum density per unit area.
+func (est *GridMedianBackgroundEstimator) Rho() float64 { return est.rho }
+
+// Sigma returns the fluctuations of the background transverse momentum
+// density, for a unit area.
+func (est *GridMedianBackgroundEstimator) Sigma() float64 { return est.sigma }
+
+// JetMedianBackgroundEstimator estimates the background density as the
+// median of the pt/area ratios of the jets of the event.
+//
+// Jets are clustered with the provided jet and area definitions.
+// Using ActiveAreaExplicitGhosts area definitions ensures empty regions
+// of the event are correctly accounted for.
+type JetMedianBackgroundEstimator struct {
+	def  JetDefinition
+	area AreaDefinition
+
+	// RapMax is the maximal absolute rapidity of the jets used for
+	// the estimation.
+	RapMax float64
+
+	// NHardest is the number of hardest jets (within RapMax) removed
+	// from the estimation.
+	NHardest int
+
+	rho   float64
+	sigma float64
+}
+
+// NewJetMedianBackgroundEstimator returns a new background estimator using
+// jets clustered with the provided definitions.
+// Jets within |y| < rapmax are used, except for the 2 hardest ones.
+func NewJetMedianBackgroundEstimator(def JetDefinition, area AreaDefinition, rapmax float64) *JetMedianBackgroundEstimator {
+	return &JetMedianBackgroundEstimator{
+		def:      def,
+		area:     area,
+		RapMax:   rapmax,
+		NHardest: 2,
+	}
+}
+
+// SetParticles sets the particles of the event.
+func (est *JetMedianBackgroundEstimator) SetParticles(particles []Jet) error {
+	csa, err := NewClusterSequenceArea(particles, est.def, est.area)
+	if err != nil {
+		return fmt.Errorf("fastjet: could not cluster event: %w", err)
+	}
+
+	jets, err := csa.InclusiveJets(0)
+	if err != nil {
+		return fmt.Errorf("fastjet: could not retrieve inclusive jets: %w", err)
+	}
+
+	sel := SelectorNHardest(est.NHardest).Not().Mul(SelectorAbsRapMax(est.RapMax)).Select(jets)
+
+	var (
+		rhos = make([]float64, 0, len(sel))
+		area = 0.0
+	)
+	for i := range sel {
+		jet := &sel[i]
+		a := jet.Area()
+		if a <= 0 {
+			continue
+		}
+		rhos = append(rhos, jet.Pt()/a)
+		area += a
+	}
+	sort.Float64s(rhos)
+
+	est.rho = 0
+	est.sigma = 0
+	if len(rhos) > 0 {
+		area /= float64(len(rhos))
+		est.rho = percentile(rhos, 0.5)
+		est.sigma = (est.rho - percentile(rhos, oneSigmaBelow)) * math.Sqrt(area)
+	}
+	return nil
+}
+
+// Rho returns the background transverse momentum density per unit area.
+func (est *JetMedianBackgroundEstimator) Rho() float64 { return est.rho }
+
+// Sigma returns the fluctuations of the background transverse momentum
+// density, for a unit area.
+func (est *JetMedianBackgroundEstimator) Sigma() float64 { return est.sigma }
+
+// oneSigmaBelow is the fraction of a normal distribution lying below
+// one standard deviation from its mean.
+const oneSigmaBelow = 0.15865525393145705
+
+// percentile returns the p-th percentile of the sorted values, linearly
+// interpolating between values.
+func percentile(vs []float64, p float64) float64 {
+	switch len(vs) {
+	case 0:
+		return 0
+	case 1:
+		return vs[0]
+	}
+	pos := p * float64(len(vs)-1)
+	i := int(pos)
+	if i >= len(vs)-1 {
+		return vs[len(vs)-1]
+	}
+	f := pos - float64(i)
+	return (1-f)*vs[i] + f*vs[i+1]
+}
+
+// Subtractor corrects jets for the background contamination by subtracting
+// rho times the 4-vector area of jets.
+type Subtractor struct {
+	est BackgroundEstimator
+	rho float64
+}
+
+// NewSubtractor returns a subtractor using the background density computed
+// by the provided estimator.
+func NewSubtractor(est BackgroundEstimator) *Subtractor {
+	return &Subtractor{est: est}
+}
+
+// NewSubtractorWithRho returns a subtractor using a fixed background density.
+func NewSubtractorWithRho(rho float64) *Subtractor {
+	return &Subtractor{rho: rho}
+}
+
+// Rho returns the background density used by the subtractor.
+func (sub *Subtractor) Rho() float64 {
+	if sub.est != nil {
+		return sub.est.Rho()
+	}
+	return sub.rho
+}
+
+// Subtract returns the jet corrected for the background contamination.
+// Jets whose transverse momentum is smaller than the background
+// contamination are returned with a null 4-momentum.
+//
+// Subtract panics if the jet has no area information.
+func (sub *Subtractor) Subtract(jet *Jet) Jet {
+	var (
+		rho = sub.Rho()
+		a4  = jet.AreaFourVector()
+		o   Jet
+	)
+	if rho*a4.Pt() < jet.Pt() {
+		o = NewJet(
+			jet.Px()-rho*a4.Px(),
+			jet.Py()-rho*a4.Py(),
+			jet.Pz()-rho*a4.Pz(),
+			jet.E()-rho*a4.E(),
+		)
+	} else {
+		o = NewJet(0, 0, 0, 0)
+	}
+	o.UserInfo = jet.UserInfo
+	o.hidx = jet.hidx
+	o.structure = jet.structure
+	return o
+}
+
+// SubtractJets returns the jets corrected for the background contamination.
+func (sub *Subtractor) SubtractJets(jets []Jet) []Jet {
+	o := make([]Jet, len(jets))
+	for i := range jets {
+		o[i] = sub.Subtract(&jets[i])
+	}
+	return o
+}
@@ -0,0 +1,156 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"go-hep.org/x/hep/fastjet"
+	"golang.org/x/exp/rand"
+)
+
+func TestBackgroundEstimators(t *testing.T) {
+	const (
+		rapmax = 4.0
+		npu    = 5000
+		ptpu   = 0.5
+	)
+
+	hard, err := loadParticles("testdata/single-pp-event.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// uniform pileup with a known transverse momentum density.
+	rnd := rand.New(rand.NewSource(1234))
+	pileup := make([]fastjet.Jet, npu)
+	for i := range pileup {
+		var (
+			rap = (2*rnd.Float64() - 1) * (rapmax + 1)
+			phi = 2 * math.Pi * rnd.Float64()
+		)
+		pileup[i] = fastjet.NewJet(
+			ptpu*math.Cos(phi), ptpu*math.Sin(phi),
+			ptpu*math.Sinh(rap), ptpu*math.Cosh(rap),
+		)
+	}
+	rho0 := npu * ptpu / (2 * (rapmax + 1) * 2 * math.Pi)
+
+	particles := append(append([]fastjet.Jet{}, hard...), pileup...)
+
+	ghosts := fastjet.NewGhostedAreaSpec(rapmax + 1)
+	for _, tc := range []struct {
+		name string
+		est  fastjet.BackgroundEstimator
+	}{
+		{
+			name: "grid",
+			est:  fastjet.NewGridMedianBackgroundEstimator(rapmax, 0.55),
+		},
+		{
+			name: "jet",
+			est: fastjet.NewJetMedianBackgroundEstimator(
+				fastjet.NewJetDefinition(fastjet.KtAlgorithm, 0.4, fastjet.EScheme, fastjet.BestStrategy),
+				fastjet.NewAreaDefinition(fastjet.ActiveAreaExplicitGhosts, ghosts),
+				rapmax-0.4,
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.est.SetParticles(particles)
+			if err != nil {
+				t.Fatalf("could not set particles: %+v", err)
+			}
+
+			rho := tc.est.Rho()
+			if math.Abs(rho-rho0) > 0.1*rho0 {
+				t.Fatalf("invalid rho: got=%v, want=%v", rho, rho0)
+			}
+			if sigma := tc.est.Sigma(); sigma <= 0 || sigma > rho {
+				t.Fatalf("invalid sigma: got=%v (rho=%v)", sigma, rho)
+			}
+
+			err = tc.est.SetParticles(nil)
+			if err != nil {
+				t.Fatalf("could not set empty event: %+v", err)
+			}
+			if rho := tc.est.Rho(); rho > 1e-10 {
+				t.Fatalf("invalid rho for empty event: got=%v", rho)
+			}
+
+			err = tc.est.SetParticles(particles)
+			if err != nil {
+				t.Fatalf("could not set particles: %+v", err)
+			}
+
+			def := fastjet.NewJetDefinition(fastjet.AntiKtAlgorithm, 0.4, fastjet.EScheme, fastjet.BestStrategy)
+			ref := inclusiveJets(t, hard, def)
+
+			csa, err := fastjet.NewClusterSequenceArea(particles, def, fastjet.NewAreaDefinition(fastjet.ActiveArea, ghosts))
+			if err != nil {
+				t.Fatalf("could not cluster event: %+v", err)
+			}
+			jets, err := csa.InclusiveJets(20)
+			if err != nil {
+				t.Fatalf("could not retrieve inclusive jets: %+v", err)
+			}
+			sort.Sort(fastjet.ByPt(jets))
+
+			sub := fastjet.NewSubtractor(tc.est)
+			subs := sub.SubtractJets(jets[:2])
+			for i := range subs {
+				var (
+					raw  = jets[i].Pt()
+					got  = subs[i].Pt()
+					want = ref[i].Pt()
+				)
+				if math.Abs(got-want) > math.Abs(raw-want) {
+					t.Errorf("jet #%d: subtraction did not improve pt: raw=%v, sub=%v, want=%v", i, raw, got, want)
+				}
+				if math.Abs(got-want) > 0.02*want {
+					t.Errorf("jet #%d: invalid subtracted pt: got=%v, want=%v", i, got, want)
+				}
+				if got, want := subs[i].Area(), jets[i].Area(); got != want {
+					t.Errorf("jet #%d: invalid subtracted jet area: got=%v, want=%v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSubtractorWithRho(t *testing.T) {
+	particles, err := loadParticles("testdata/single-pp-event.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := fastjet.NewJetDefinition(fastjet.AntiKtAlgorithm, 0.4, fastjet.EScheme, fastjet.BestStrategy)
+	csa, err := fastjet.NewClusterSequenceArea(particles, def, fastjet.AreaDefinition{})
+	if err != nil {
+		t.Fatalf("could not cluster event: %+v", err)
+	}
+	jets, err := csa.InclusiveJets(5)
+	if err != nil {
+		t.Fatalf("could not retrieve inclusive jets: %+v", err)
+	}
+
+	sub := fastjet.NewSubtractorWithRho(1e6)
+	for i := range jets {
+		got := sub.Subtract(&jets[i])
+		if got.Pt() != 0 || got.E() != 0 {
+			t.Fatalf("jet #%d: expected a null jet, got=%v", i, got.PxPyPzE)
+		}
+	}
+
+	sub = fastjet.NewSubtractorWithRho(0)
+	for i := range jets {
+		got := sub.Subtract(&jets[i])
+		if got.PxPyPzE != jets[i].PxPyPzE {
+			t.Fatalf("jet #%d: invalid subtraction: got=%v, want=%v", i, got.PxPyPzE, jets[i].PxPyPzE)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Batch clusters independent events concurrently.
+//
+// Cluster sequences are recycled from one event to the next, so clustering
+// many events does not allocate a new cluster sequence (and its internal
+// buffers) for each event.
+// A Batch may be used concurrently by multiple goroutines.
+type Batch struct {
+	def  JetDefinition
+	nwrk int
+	pool sync.Pool
+}
+
+// NewBatch returns a new batch clustering events with the provided jet
+// definition, using at most nworkers goroutines.
+// If nworkers is not strictly positive, runtime.NumCPU() workers are used.
+func NewBatch(def JetDefinition, nworkers int) *Batch {
+	if nworkers <= 0 {
+		nworkers = runtime.NumCPU()
+	}
+	return &Batch{
+		def:  def,
+		nwrk: nworkers,
+		pool: sync.Pool{
+			New: func() interface{} { return new(ClusterSequence) },
+		},
+	}
+}
+
+// Run clusters each event and calls fn with the index of the event and
+// its cluster sequence.
+//
+// fn is called concurrently from multiple goroutines.
+// The cluster sequence, and the jets it returns, are only valid for the
+// duration of the call to fn: the cluster sequence is reused for another
+// event afterwards.
+//
+// Run returns the first error encountered, either while clustering an event
+// or returned by fn. Events not yet processed are then skipped.
+func (b *Batch) Run(events [][]Jet, fn func(i int, cs *ClusterSequence) error) error {
+	var (
+		grp  errgroup.Group
+		idx  = make(chan int)
+		quit = make(chan struct{})
+		once sync.Once
+	)
+
+	n := b.nwrk
+	if n > len(events) {
+		n = len(events)
+	}
+	for w := 0; w < n; w++ {
+		grp.Go(func() error {
+			cs := b.pool.Get().(*ClusterSequence)
+			defer b.pool.Put(cs)
+
+			for i := range idx {
+				err := b.process(cs, i, events[i], fn)
+				if err != nil {
+					once.Do(func() { close(quit) })
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+loop:
+	for i := range events {
+		select {
+		case idx <- i:
+		case <-quit:
+			break loop
+		}
+	}
+	close(idx)
+
+	return grp.Wait()
+}
+
+func (b *Batch) process(cs *ClusterSequence, i int, event []Jet, fn func(i int, cs *ClusterSequence) error) error {
+	err := cs.reset(event, b.def)
+	if err != nil {
+		return fmt.Errorf("fastjet: could not cluster event %d: %w", i, err)
+	}
+	err = fn(i, cs)
+	if err != nil {
+		return fmt.Errorf("fastjet: could not process event %d: %w", i, err)
+	}
+	return nil
+}
+
+// InclusiveJets clusters each event and returns, for each event, the
+// inclusive jets with pt >= ptmin.
+//
+// The returned jets do not refer to the (recycled) cluster sequences:
+// their constituents are stored alongside them, but clustering history
+// and areas are not available.
+func (b *Batch) InclusiveJets(events [][]Jet, ptmin float64) ([][]Jet, error) {
+	out := make([][]Jet, len(events))
+	err := b.Run(events, func(i int, cs *ClusterSequence) error {
+		jets, err := cs.InclusiveJets(ptmin)
+		if err != nil {
+			return fmt.Errorf("fastjet: could not retrieve inclusive jets: %w", err)
+		}
+		for j := range jets {
+			jet := &jets[j]
+			cons, err := cs.Constituents(jet)
+			if err != nil {
+				return fmt.Errorf("fastjet: could not retrieve jet constituents: %w", err)
+			}
+			for k := range cons {
+				cons[k].hidx = -1
+				cons[k].structure = nil
+			}
+			jet.hidx = -1
+			jet.structure = compositeStructure(cons)
+		}
+		out[i] = jets
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,143 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"go-hep.org/x/hep/fastjet"
+)
+
+func TestBatch(t *testing.T) {
+	pp, err := loadParticles("testdata/single-pp-event.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := [][]fastjet.Jet{pp, nil}
+	for i := 0; i < 40; i++ {
+		events = append(events, rndParticles(10+(i*37)%200, uint64(i+1)))
+	}
+	events = append(events, pp)
+
+	def := fastjet.NewJetDefinition(fastjet.AntiKtAlgorithm, 0.4, fastjet.EScheme, fastjet.BestStrategy)
+	for _, nwrk := range []int{0, 1, 3} {
+		t.Run(fmt.Sprintf("nworkers=%d", nwrk), func(t *testing.T) {
+			batch := fastjet.NewBatch(def, nwrk)
+			got, err := batch.InclusiveJets(events, 5)
+			if err != nil {
+				t.Fatalf("could not cluster events: %+v", err)
+			}
+			if len(got) != len(events) {
+				t.Fatalf("invalid number of events: got=%d, want=%d", len(got), len(events))
+			}
+
+			for i, event := range events {
+				cs, err := fastjet.NewClusterSequence(event, def)
+				if err != nil {
+					t.Fatalf("event %d: could not cluster event: %+v", i, err)
+				}
+				want, err := cs.InclusiveJets(5)
+				if err != nil {
+					t.Fatalf("event %d: could not retrieve inclusive jets: %+v", i, err)
+				}
+				if len(got[i]) != len(want) {
+					t.Fatalf("event %d: invalid number of jets: got=%d, want=%d", i, len(got[i]), len(want))
+				}
+				for j := range want {
+					if got[i][j].PxPyPzE != want[j].PxPyPzE {
+						t.Fatalf("event %d, jet %d: invalid 4-momentum: got=%v, want=%v", i, j, got[i][j].PxPyPzE, want[j].PxPyPzE)
+					}
+					if g, w := len(got[i][j].Constituents()), len(want[j].Constituents()); g != w {
+						t.Fatalf("event %d, jet %d: invalid number of constituents: got=%d, want=%d", i, j, g, w)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBatchRun(t *testing.T) {
+	events := make([][]fastjet.Jet, 20)
+	for i := range events {
+		events[i] = rndParticles(50, uint64(i+1))
+	}
+
+	def := fastjet.NewJetDefinition(fastjet.KtAlgorithm, 0.6, fastjet.EScheme, fastjet.BestStrategy)
+	batch := fastjet.NewBatch(def, 4)
+
+	njets := make([]int, len(events))
+	err := batch.Run(events, func(i int, cs *fastjet.ClusterSequence) error {
+		njets[i] = cs.NumExclusiveJets(0)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("could not run batch: %+v", err)
+	}
+	for i, n := range njets {
+		if n != len(events[i]) {
+			t.Fatalf("event %d: invalid number of exclusive jets: got=%d, want=%d", i, n, len(events[i]))
+		}
+	}
+
+	errBoom := fmt.Errorf("boom")
+	err = batch.Run(events, func(i int, cs *fastjet.ClusterSequence) error {
+		if i == 3 {
+			return errBoom
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if got, want := err.Error(), "fastjet: could not process event 3: boom"; got != want {
+		t.Fatalf("invalid error:\ngot= %q\nwant=%q", got, want)
+	}
+}
+
+func BenchmarkBatch(b *testing.B) {
+	events := make([][]fastjet.Jet, 100)
+	for i := range events {
+		events[i] = rndParticles(300, uint64(i+1))
+	}
+	def := fastjet.NewJetDefinition(fastjet.AntiKtAlgorithm, 0.4, fastjet.EScheme, fastjet.BestStrategy)
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for _, event := range events {
+				cs, err := fastjet.NewClusterSequence(event, def)
+				if err != nil {
+					b.Fatal(err)
+				}
+				jets, err := cs.InclusiveJets(5)
+				if err != nil {
+					b.Fatal(err)
+				}
+				sort.Sort(fastjet.ByPt(jets))
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		batch := fastjet.NewBatch(def, 0)
+		for i := 0; i < b.N; i++ {
+			err := batch.Run(events, func(i int, cs *fastjet.ClusterSequence) error {
+				jets, err := cs.InclusiveJets(5)
+				if err != nil {
+					return err
+				}
+				sort.Sort(fastjet.ByPt(jets))
+				return nil
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -41,17 +41,39 @@ type ClusterSequence struct {
 	jets      []Jet
 	history   []history
 	structure JetStructure
+
+	// scratch space for the N2 strategies.
+	bjs    []briefJet
+	active []int
+	tiles  tiling
+	seen   []bool
+	cands  []int
+	nbrs   []int
 }
 
 func NewClusterSequence(jets []Jet, def JetDefinition) (*ClusterSequence, error) {
+	cs := &ClusterSequence{}
+	err := cs.reset(jets, def)
+	if err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// reset runs the clustering of the provided jets, reusing the memory
+// previously allocated by the cluster sequence.
+func (cs *ClusterSequence) reset(jets []Jet, def JetDefinition) error {
 	var err error
-	cs := &ClusterSequence{
-		def:      def,
-		alg:      def.Algorithm(),
-		strategy: def.Strategy(),
-		r:        def.R(),
-		jets:     make([]Jet, len(jets), len(jets)*2),
+	cs.def = def
+	cs.alg = def.Algorithm()
+	cs.strategy = def.Strategy()
+	cs.r = def.R()
+
+	n := len(jets)
+	if cap(cs.jets) < 2*n {
+		cs.jets = make([]Jet, n, 2*n)
 	}
+	cs.jets = cs.jets[:n]
 
 	cs.r2 = cs.r * cs.r
 	cs.invR2 = 1.0 / cs.r2
@@ -60,15 +82,15 @@ func NewClusterSequence(jets []Jet, def JetDefinition) (*ClusterSequence, error)
 	copy(cs.jets, jets)
 	err = cs.init()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	err = cs.run()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return cs, err
+	return err
 }
 
 // NumExclusiveJets returns the number of exclusive jets that would have been obtained
@@ -86,11 +108,16 @@ func (cs *ClusterSequence) NumExclusiveJets(dcut float64) int {
 	return njets
 }
 
+// ExclusiveJets returns the jets that would have been obtained running
+// the algorithm in exclusive mode with the given dcut.
 func (cs *ClusterSequence) ExclusiveJets(dcut float64) ([]Jet, error) {
 	njets := cs.NumExclusiveJets(dcut)
 	return cs.ExclusiveJetsUpTo(njets)
 }
 
+// ExclusiveJetsUpTo returns the jets obtained by stopping the clustering
+// when njets jets are left (or all the particles if there are fewer than
+// njets particles).
 func (cs *ClusterSequence) ExclusiveJetsUpTo(njets int) ([]Jet, error) {
 	var err error
 	if njets > cs.initn {
@@ -130,6 +157,119 @@ func (cs *ClusterSequence) ExclusiveJetsUpTo(njets int) ([]Jet, error) {
 	return ljets, err
 }
 
+// NumExclusiveJetsYcut returns the number of exclusive jets that would have
+// been obtained running the algorithm in exclusive mode with the given
+// ycut = dcut/Q², where Q is the total energy of the event.
+func (cs *ClusterSequence) NumExclusiveJetsYcut(ycut float64) int {
+	return cs.NumExclusiveJets(ycut * cs.qtot * cs.qtot)
+}
+
+// ExclusiveJetsYcut returns the jets that would have been obtained running
+// the algorithm in exclusive mode with the given ycut = dcut/Q², where Q is
+// the total energy of the event.
+func (cs *ClusterSequence) ExclusiveJetsYcut(ycut float64) ([]Jet, error) {
+	njets := cs.NumExclusiveJetsYcut(ycut)
+	return cs.ExclusiveJetsUpTo(njets)
+}
+
+// DMerge returns the dmin corresponding to the recombination that went
+// from njets+1 to njets jets.
+// DMerge returns 0 if njets is larger than the number of particles.
+func (cs *ClusterSequence) DMerge(njets int) float64 {
+	if njets < 0 || njets >= cs.initn {
+		return 0
+	}
+	return cs.history[2*cs.initn-njets-1].dij
+}
+
+// DMergeMax returns the maximum of the dmin encountered during all
+// recombinations up to the one that led to an njets jets final state.
+// DMergeMax is identical to DMerge for algorithms with ordered dmin, like
+// the kt algorithm.
+// DMergeMax returns 0 if njets is larger than the number of particles.
+func (cs *ClusterSequence) DMergeMax(njets int) float64 {
+	if njets < 0 || njets >= cs.initn {
+		return 0
+	}
+	return cs.history[2*cs.initn-njets-1].maxdij
+}
+
+// YMerge returns DMerge(njets)/Q², where Q is the total energy of the event.
+func (cs *ClusterSequence) YMerge(njets int) float64 {
+	return cs.DMerge(njets) / (cs.qtot * cs.qtot)
+}
+
+// YMergeMax returns DMergeMax(njets)/Q², where Q is the total energy of the
+// event.
+func (cs *ClusterSequence) YMergeMax(njets int) float64 {
+	return cs.DMergeMax(njets) / (cs.qtot * cs.qtot)
+}
+
+// Q returns the total energy of the event.
+func (cs *ClusterSequence) Q() float64 {
+	return cs.qtot
+}
+
+// Jets returns all the jets of the clustering sequence: the initial
+// particles followed by the jets created at each recombination step.
+func (cs *ClusterSequence) Jets() []Jet {
+	jets := make([]Jet, len(cs.jets))
+	copy(jets, cs.jets)
+	return jets
+}
+
+// HistoryElement describes a step of the clustering sequence.
+type HistoryElement struct {
+	Parent1 int     // index of the first parent in the history (-2 for initial particles)
+	Parent2 int     // index of the second parent in the history (-1 for the beam, -2 for initial particles)
+	Child   int     // index in the history of the step where this jet was recombined (-3 if none)
+	Jet     int     // index of the jet created at this step in Jets (-3 for beam recombinations)
+	Dij     float64 // distance at which this step happened
+	MaxDij  float64 // maximal distance encountered up to this step
+}
+
+// History returns the clustering history.
+// The first steps of the history correspond to the initial particles.
+func (cs *ClusterSequence) History() []HistoryElement {
+	o := make([]HistoryElement, len(cs.history))
+	for i, h := range cs.history {
+		o[i] = HistoryElement{
+			Parent1: h.parent1,
+			Parent2: h.parent2,
+			Child:   h.child,
+			Jet:     h.jet,
+			Dij:     h.dij,
+			MaxDij:  h.maxdij,
+		}
+	}
+	return o
+}
+
+// ParticleJetIndices returns, for each initial particle, the index of the
+// jet containing it among the provided jets, or -1 if none of the jets
+// contains the particle.
+// The provided jets must have been clustered by this cluster sequence.
+func (cs *ClusterSequence) ParticleJetIndices(jets []Jet) ([]int, error) {
+	indices := make([]int, cs.initn)
+	for i := range indices {
+		indices[i] = -1
+	}
+	for i := range jets {
+		jet := &jets[i]
+		if jet.hidx < 0 || jet.hidx >= len(cs.history) {
+			return nil, fmt.Errorf("fastjet: jet #%d does not belong to the cluster sequence", i)
+		}
+		cons, err := cs.Constituents(jet)
+		if err != nil {
+			return nil, fmt.Errorf("fastjet: could not retrieve constituents of jet #%d: %w", i, err)
+		}
+		for _, c := range cons {
+			indices[c.hidx] = i
+		}
+	}
+	return indices, nil
+}
+
 func (cs *ClusterSequence) InclusiveJets(ptmin float64) ([]Jet, error) {
 	var err error
 	dcut := ptmin * ptmin
@@ -187,7 +327,10 @@ func (cs *ClusterSequence) InclusiveJets(ptmin float64) ([]Jet, error) {
 
 func (cs *ClusterSequence) init() error {
 	var err error
-	cs.history = make([]history, 0, len(cs.jets)*2)
+	if cap(cs.history) < 2*len(cs.jets) {
+		cs.history = make([]history, 0, 2*len(cs.jets))
+	}
+	cs.history = cs.history[:0]
 	cs.qtot = 0
 
 	for i := range cs.jets {
@@ -228,11 +371,27 @@ func (cs *ClusterSequence) run() error {
 
 	run := cs.runN3Dumb
 
-	switch cs.strategy {
-	case NlnNStrategy:
-		run = cs.runNlnN
-	case N3DumbStrategy:
-		run = cs.runN3Dumb
+	switch cs.alg {
+	case EeKtAlgorithm, EeGenKtAlgorithm:
+		// e+e- algorithms do not use the rapidity-phi distance.
+	default:
+		switch cs.strategy {
+		case N3DumbStrategy:
+			run = cs.runN3Dumb
+		case N2PlainStrategy:
+			run = cs.runN2Plain
+		case BestStrategy:
+			run = cs.runN2Tiled
+			if len(cs.jets) <= maxPlainN {
+				run = cs.runN2Plain
+			}
+		case N2TiledStrategy, N2PoorTiledStrategy, N2MinHeapTiledStrategy,
+			NlnNStrategy, NlnN3piStrategy, NlnN4piStrategy,
+			NlnNCamStrategy, NlnNCam2pi2RStrategy, NlnNCam4piStrategy:
+			// TODO(sbinet): implement the NlnN strategies.
+			// the tiled strategy is used in the meantime.
+			run = cs.runN2Tiled
+		}
 	}
 
 	err := run()
@@ -243,6 +402,25 @@ func (cs *ClusterSequence) run() error {
 	return nil
 }
 
+// Parents returns the two jets that were recombined to create the given jet,
+// the harder one first.
+// Parents returns false if the jet was not created by a recombination.
+func (cs *ClusterSequence) Parents(jet *Jet) (Jet, Jet, bool) {
+	if jet.hidx < 0 || jet.hidx >= len(cs.history) {
+		return Jet{}, Jet{}, false
+	}
+	hh := cs.history[jet.hidx]
+	if hh.parent1 < 0 || hh.parent2 < 0 {
+		return Jet{}, Jet{}, false
+	}
+	p1 := cs.jets[cs.history[hh.parent1].jet]
+	p2 := cs.jets[cs.history[hh.parent2].jet]
+	if p1.Pt2() < p2.Pt2() {
+		p1, p2 = p2, p1
+	}
+	return p1, p2, true
+}
+
 // Constituents retrieves the list of constituents of a given jet
 func (cs *ClusterSequence) Constituents(jet *Jet) ([]Jet, error) {
 	return cs.addConstituents(jet)
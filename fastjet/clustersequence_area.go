@@ -4,44 +4,260 @@
 
 package fastjet
 
+import (
+	"fmt"
+	"math"
+
+	"go-hep.org/x/hep/fmom"
+	"golang.org/x/exp/rand"
+)
+
+// ClusterSequenceArea clusters particles and computes the areas of
+// the resulting jets.
+//
+// Jets returned by a ClusterSequenceArea carry their area information,
+// available through their Area, AreaError and AreaFourVector methods.
 type ClusterSequenceArea struct {
-	cs   *ClusterSequence
-	area AreaDefinition
+	cs    *ClusterSequence // clustering of the particles and the first set of ghosts
+	area  AreaDefinition
+	nreal int     // number of real particles
+	ghost float64 // area of each ghost
+
+	// areas of the inclusive jets, averaged over all the ghost sets,
+	// indexed by the smallest index of their real constituents.
+	areas map[int]*jetArea
+}
+
+// jetArea accumulates the areas of a jet over all the ghost sets.
+type jetArea struct {
+	n    int
+	sum  float64
+	sum2 float64
+	p4   fmom.PxPyPzE
 }
 
 func NewClusterSequenceArea(jets []Jet, def JetDefinition, area AreaDefinition) (*ClusterSequenceArea, error) {
-	cs, err := NewClusterSequence(jets, def)
-	if err != nil {
-		return nil, err
+	if area.Ghosts == (GhostedAreaSpec{}) {
+		area.Ghosts = NewGhostedAreaSpec(DefaultGhostRapMax)
+	}
+	switch area.Type {
+	case ActiveArea, ActiveAreaExplicitGhosts:
+		// ok.
+	default:
+		return nil, fmt.Errorf("fastjet: invalid area type (%d)", int(area.Type))
+	}
+	if area.Ghosts.RapMax <= 0 || area.Ghosts.Area <= 0 {
+		return nil, fmt.Errorf(
+			"fastjet: invalid ghosts specification (rapmax=%v, area=%v)",
+			area.Ghosts.RapMax, area.Ghosts.Area,
+		)
 	}
 
+	_, _, ghost := area.Ghosts.grid()
 	csa := ClusterSequenceArea{
-		cs:   cs,
-		area: area,
+		area:  area,
+		nreal: len(jets),
+		ghost: ghost,
+		areas: make(map[int]*jetArea),
+	}
+
+	var (
+		rnd    = rand.New(rand.NewSource(area.Ghosts.Seed))
+		repeat = imax(1, area.Ghosts.Repeat)
+	)
+	for i := 0; i < repeat; i++ {
+		ghosts := area.Ghosts.ghosts(rnd)
+		particles := make([]Jet, 0, len(jets)+len(ghosts))
+		particles = append(particles, jets...)
+		particles = append(particles, ghosts...)
+
+		cs, err := NewClusterSequence(particles, def)
+		if err != nil {
+			return nil, fmt.Errorf("fastjet: could not cluster ghosted event: %w", err)
+		}
+		if i == 0 {
+			csa.cs = cs
+		}
+
+		err = csa.accumulate(cs)
+		if err != nil {
+			return nil, fmt.Errorf("fastjet: could not compute jet areas: %w", err)
+		}
 	}
+
 	return &csa, nil
 }
 
+// accumulate adds the areas of the inclusive jets of the provided
+// cluster sequence.
+func (csa *ClusterSequenceArea) accumulate(cs *ClusterSequence) error {
+	jets, err := cs.InclusiveJets(0)
+	if err != nil {
+		return err
+	}
+	for i := range jets {
+		key, area, p4, err := csa.measure(cs, &jets[i])
+		if err != nil {
+			return err
+		}
+		if key < 0 {
+			// pure ghost jet.
+			continue
+		}
+		ja, ok := csa.areas[key]
+		if !ok {
+			ja = new(jetArea)
+			csa.areas[key] = ja
+		}
+		ja.n++
+		ja.sum += area
+		ja.sum2 += area * area
+		fmom.IAdd(&ja.p4, &p4)
+	}
+	return nil
+}
+
+// measure returns the smallest index of the real constituents of the jet
+// (or -1 for a pure ghost jet), the area and the area 4-vector of the jet.
+func (csa *ClusterSequenceArea) measure(cs *ClusterSequence, jet *Jet) (int, float64, fmom.PxPyPzE, error) {
+	var (
+		key = -1
+		n   = 0
+		p4  fmom.PxPyPzE
+	)
+	cons, err := cs.Constituents(jet)
+	if err != nil {
+		return key, 0, p4, err
+	}
+	for i := range cons {
+		c := &cons[i]
+		if c.hidx < csa.nreal {
+			if key < 0 || c.hidx < key {
+				key = c.hidx
+			}
+			continue
+		}
+		n++
+		g := newPtYPhiJet(csa.ghost, c.Rapidity(), c.Phi())
+		fmom.IAdd(&p4, &g.PxPyPzE)
+	}
+	return key, float64(n) * csa.ghost, p4, nil
+}
+
+// jetArea returns the areas of the provided jet, averaged over all the
+// ghost sets for inclusive jets.
+func (csa *ClusterSequenceArea) jetArea(jet *Jet) jetArea {
+	key, area, p4, err := csa.measure(csa.cs, jet)
+	if err != nil {
+		panic(err)
+	}
+	if key >= 0 && csa.isInclusive(jet) {
+		if ja, ok := csa.areas[key]; ok {
+			return *ja
+		}
+	}
+	return jetArea{n: 1, sum: area, sum2: area * area, p4: p4}
+}
+
+// isInclusive returns whether the jet has been recombined with the beam.
+func (csa *ClusterSequenceArea) isInclusive(jet *Jet) bool {
+	if jet.hidx < 0 || jet.hidx >= len(csa.cs.history) {
+		return false
+	}
+	child := csa.cs.history[jet.hidx].child
+	return child >= 0 && csa.cs.history[child].parent2 == beamJetIndex
+}
+
+// Area returns the area of a jet clustered by this cluster sequence.
+//
+// The area of inclusive jets is averaged over all the ghost sets.
 func (csa *ClusterSequenceArea) Area(jet *Jet) float64 {
-	panic("not implemented")
+	ja := csa.jetArea(jet)
+	return ja.sum / float64(ja.n)
+}
+
+// AreaError returns the uncertainty on the area of a jet clustered by
+// this cluster sequence, computed as the standard deviation of the areas
+// obtained with each ghost set.
+func (csa *ClusterSequenceArea) AreaError(jet *Jet) float64 {
+	ja := csa.jetArea(jet)
+	mean := ja.sum / float64(ja.n)
+	return math.Sqrt(math.Abs(ja.sum2/float64(ja.n) - mean*mean))
+}
+
+// AreaFourVector returns the 4-vector area of a jet clustered by this
+// cluster sequence, ie: the sum of the ghosts 4-momenta, each ghost
+// transverse momentum being set to its area.
+func (csa *ClusterSequenceArea) AreaFourVector(jet *Jet) fmom.PxPyPzE {
+	ja := csa.jetArea(jet)
+	inv := 1 / float64(ja.n)
+	return fmom.NewPxPyPzE(
+		inv*ja.p4.Px(), inv*ja.p4.Py(), inv*ja.p4.Pz(), inv*ja.p4.E(),
+	)
 }
 
-func (csa *ClusterSequenceArea) AreaErr(jet *Jet) float64 {
-	panic("not implemented")
+// Constituents returns the constituents of a jet clustered by this cluster
+// sequence.
+// Ghosts are only reported with the ActiveAreaExplicitGhosts area type.
+func (csa *ClusterSequenceArea) Constituents(jet *Jet) ([]Jet, error) {
+	cons, err := csa.cs.Constituents(jet)
+	if err != nil {
+		return nil, err
+	}
+	if csa.area.Type == ActiveAreaExplicitGhosts {
+		return cons, nil
+	}
+	o := cons[:0]
+	for _, c := range cons {
+		if c.hidx < csa.nreal {
+			o = append(o, c)
+		}
+	}
+	return o, nil
 }
 
 func (csa *ClusterSequenceArea) NumExclusiveJets(dcut float64) int {
-	panic("not implemented")
+	return csa.cs.NumExclusiveJets(dcut)
 }
 
-func (cs *ClusterSequenceArea) ExclusiveJets(dcut float64) ([]Jet, error) {
-	panic("not implemented")
+func (csa *ClusterSequenceArea) ExclusiveJets(dcut float64) ([]Jet, error) {
+	jets, err := csa.cs.ExclusiveJets(dcut)
+	return csa.jets(jets), err
 }
 
-func (cs *ClusterSequenceArea) ExclusiveJetsUpTo(njets int) ([]Jet, error) {
-	panic("not implemented")
+func (csa *ClusterSequenceArea) ExclusiveJetsUpTo(njets int) ([]Jet, error) {
+	jets, err := csa.cs.ExclusiveJetsUpTo(njets)
+	return csa.jets(jets), err
 }
 
+// InclusiveJets returns all jets with pt > ptmin.
+// Pure ghost jets are only reported with the ActiveAreaExplicitGhosts
+// area type.
 func (csa *ClusterSequenceArea) InclusiveJets(ptmin float64) ([]Jet, error) {
-	panic("not implemented")
+	jets, err := csa.cs.InclusiveJets(ptmin)
+	if err != nil {
+		return nil, err
+	}
+	if csa.area.Type != ActiveAreaExplicitGhosts {
+		o := jets[:0]
+		for i := range jets {
+			key, _, _, err := csa.measure(csa.cs, &jets[i])
+			if err != nil {
+				return nil, err
+			}
+			if key >= 0 {
+				o = append(o, jets[i])
+			}
+		}
+		jets = o
+	}
+	return csa.jets(jets), nil
+}
+
+// jets attaches the area information to the provided jets.
+func (csa *ClusterSequenceArea) jets(jets []Jet) []Jet {
+	for i := range jets {
+		jets[i].structure = csa
+	}
+	return jets
 }
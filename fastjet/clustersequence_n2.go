@@ -0,0 +1,414 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet
+
+import (
+	"math"
+)
+
+// briefJet holds the minimal information needed by the N2 strategies
+// to find the nearest neighbour of a jet.
+type briefJet struct {
+	jet    int     // index of the jet in the cluster sequence
+	kt2    float64 // jet scale of the algorithm
+	nn     int     // index of the nearest neighbour (-1 for the beam)
+	nnDist float64 // distance to the nearest neighbour
+	diJ    float64 // kt-distance of the jet, times R²
+
+	tile       int // tile holding this jet
+	prev, next int // previous and next jets in the tile
+}
+
+// bjDiJ returns the kt-distance (times R²) between the jet and its nearest
+// neighbour (or the beam.)
+func (cs *ClusterSequence) bjDiJ(bjs []briefJet, i int) float64 {
+	bj := &bjs[i]
+	kt2 := bj.kt2
+	if bj.nn >= 0 && bjs[bj.nn].kt2 < kt2 {
+		kt2 = bjs[bj.nn].kt2
+	}
+	return bj.nnDist * kt2
+}
+
+// bjInit initializes bj from the i-th jet of the cluster sequence.
+func (cs *ClusterSequence) bjInit(bj *briefJet, jet int) {
+	*bj = briefJet{
+		jet:    jet,
+		kt2:    cs.jetScaleForAlgorithm(&cs.jets[jet]),
+		nn:     -1,
+		nnDist: cs.r2,
+		tile:   -1,
+		prev:   -1,
+		next:   -1,
+	}
+}
+
+// bjDist returns the squared rapidity-phi distance between two brief jets.
+func (cs *ClusterSequence) bjDist(bjs []briefJet, i, j int) float64 {
+	return Distance(&cs.jets[bjs[i].jet], &cs.jets[bjs[j].jet])
+}
+
+// runN2Plain runs the clustering, keeping track of the nearest neighbour
+// of each jet, in O(N²).
+func (cs *ClusterSequence) runN2Plain() error {
+	n := len(cs.jets)
+	bjs, active := cs.scratch(n)
+	for i := range bjs {
+		cs.bjInit(&bjs[i], i)
+	}
+
+	for i := range bjs {
+		for j := i + 1; j < n; j++ {
+			dist := cs.bjDist(bjs, i, j)
+			if dist < bjs[i].nnDist {
+				bjs[i].nnDist = dist
+				bjs[i].nn = j
+			}
+			if dist < bjs[j].nnDist {
+				bjs[j].nnDist = dist
+				bjs[j].nn = i
+			}
+		}
+	}
+	for i := range bjs {
+		bjs[i].diJ = cs.bjDiJ(bjs, i)
+	}
+
+	for len(active) > 0 {
+		ia, pos := cs.bjMinDiJ(bjs, active)
+		ib := bjs[ia].nn
+		dmin := bjs[ia].diJ * cs.invR2
+
+		active[pos] = active[len(active)-1]
+		active = active[:len(active)-1]
+
+		if ib >= 0 {
+			nn, err := cs.ijRecombinationStep(bjs[ia].jet, bjs[ib].jet, dmin)
+			if err != nil {
+				return err
+			}
+			cs.bjInit(&bjs[ib], nn)
+		} else {
+			err := cs.ibRecombinationStep(bjs[ia].jet, dmin)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, k := range active {
+			cs.bjUpdate(bjs, k, ia, ib, active)
+		}
+		if ib >= 0 {
+			bjs[ib].diJ = cs.bjDiJ(bjs, ib)
+		}
+	}
+
+	return nil
+}
+
+// scratch returns the brief jets and the indices of the active jets
+// for n jets, reusing the memory of previous clusterings.
+func (cs *ClusterSequence) scratch(n int) ([]briefJet, []int) {
+	if cap(cs.bjs) < n {
+		cs.bjs = make([]briefJet, n)
+		cs.active = make([]int, n)
+	}
+	cs.bjs = cs.bjs[:n]
+	cs.active = cs.active[:n]
+	for i := range cs.active {
+		cs.active[i] = i
+	}
+	return cs.bjs, cs.active
+}
+
+// visited returns a slice to mark the visited tiles, reusing the memory of
+// previous clusterings.
+func (cs *ClusterSequence) visited(n int) []bool {
+	if cap(cs.seen) < n {
+		cs.seen = make([]bool, n)
+	}
+	cs.seen = cs.seen[:n]
+	return cs.seen
+}
+
+// bjMinDiJ returns the index of the active jet with the smallest
+// kt-distance and its position in the active slice.
+func (cs *ClusterSequence) bjMinDiJ(bjs []briefJet, active []int) (int, int) {
+	var (
+		imin = active[0]
+		pos  = 0
+		dmin = bjs[imin].diJ
+	)
+	for i, k := range active[1:] {
+		if bjs[k].diJ < dmin {
+			dmin = bjs[k].diJ
+			imin = k
+			pos = i + 1
+		}
+	}
+	return imin, pos
+}
+
+// bjUpdate updates the nearest neighbour of jet k after the jet ia
+// has been clustered with the beam or with jet ib.
+// When ib is a valid index, it holds the newly recombined jet.
+// Nearest neighbours are looked for among the provided candidates.
+func (cs *ClusterSequence) bjUpdate(bjs []briefJet, k, ia, ib int, candidates []int) {
+	bk := &bjs[k]
+	if k == ib {
+		return
+	}
+	if bk.nn == ia || (ib >= 0 && bk.nn == ib) {
+		bk.nn = -1
+		bk.nnDist = cs.r2
+		for _, j := range candidates {
+			if j == k {
+				continue
+			}
+			dist := cs.bjDist(bjs, k, j)
+			if dist < bk.nnDist {
+				bk.nnDist = dist
+				bk.nn = j
+			}
+		}
+	}
+	if ib >= 0 {
+		dist := cs.bjDist(bjs, k, ib)
+		if dist < bk.nnDist {
+			bk.nnDist = dist
+			bk.nn = ib
+		}
+		if dist < bjs[ib].nnDist {
+			bjs[ib].nnDist = dist
+			bjs[ib].nn = k
+		}
+	}
+	bk.diJ = cs.bjDiJ(bjs, k)
+}
+
+// tiling partitions the rapidity-phi cylinder into tiles, at least R wide,
+// so the nearest neighbour of a jet is either in the same tile or in one of
+// the neighbouring tiles.
+type tiling struct {
+	rapMin  float64
+	rapSize float64
+	phiSize float64
+	nrap    int
+	nphi    int
+
+	heads []int   // index of the first jet of each tile (-1 if empty)
+	nbrs  [][]int // neighbouring tiles of each tile (including the tile itself)
+	buf   []int   // storage for nbrs
+}
+
+// maxTiledRap is the maximum rapidity covered by tiles.
+// Jets with larger rapidities are stored in the edge tiles.
+const maxTiledRap = 10
+
+// reset partitions the rapidity-phi cylinder for the provided jets,
+// reusing the memory previously allocated by the tiling.
+func (t *tiling) reset(jets []Jet, r float64) {
+	size := math.Max(0.1, r)
+	rapMin, rapMax := 0.0, 0.0
+	for i := range jets {
+		rap := jets[i].Rapidity()
+		rapMin = math.Min(rapMin, rap)
+		rapMax = math.Max(rapMax, rap)
+	}
+	rapMin = math.Max(rapMin, -maxTiledRap)
+	rapMax = math.Min(rapMax, +maxTiledRap)
+
+	t.rapMin = rapMin
+	t.rapSize = size
+	t.nrap = imax(1, int(math.Floor((rapMax-rapMin)/size))+1)
+	t.nphi = imax(3, int(math.Floor(2*math.Pi/size)))
+	t.phiSize = 2 * math.Pi / float64(t.nphi)
+
+	n := t.nrap * t.nphi
+	if cap(t.heads) < n {
+		t.heads = make([]int, n)
+		t.nbrs = make([][]int, n)
+		t.buf = make([]int, 0, 9*n)
+	}
+	t.heads = t.heads[:n]
+	t.nbrs = t.nbrs[:n]
+	t.buf = t.buf[:0]
+	for i := range t.heads {
+		t.heads[i] = -1
+	}
+	for irap := 0; irap < t.nrap; irap++ {
+		for iphi := 0; iphi < t.nphi; iphi++ {
+			tile := irap*t.nphi + iphi
+			beg := len(t.buf)
+			for jrap := imax(0, irap-1); jrap <= imin(t.nrap-1, irap+1); jrap++ {
+				for _, dphi := range [...]int{-1, 0, +1} {
+					jphi := (iphi + dphi + t.nphi) % t.nphi
+					t.buf = append(t.buf, jrap*t.nphi+jphi)
+				}
+			}
+			t.nbrs[tile] = t.buf[beg:len(t.buf):len(t.buf)]
+		}
+	}
+}
+
+// index returns the index of the tile holding the provided jet.
+func (t *tiling) index(jet *Jet) int {
+	irap := int(math.Floor((jet.Rapidity() - t.rapMin) / t.rapSize))
+	irap = imax(0, imin(t.nrap-1, irap))
+
+	phi := jet.Phi()
+	if phi < 0 {
+		phi += 2 * math.Pi
+	}
+	iphi := int(math.Floor(phi/t.phiSize)) % t.nphi
+	if iphi < 0 {
+		iphi += t.nphi
+	}
+	return irap*t.nphi + iphi
+}
+
+func (t *tiling) add(bjs []briefJet, i, tile int) {
+	bj := &bjs[i]
+	bj.tile = tile
+	bj.prev = -1
+	bj.next = t.heads[tile]
+	if bj.next >= 0 {
+		bjs[bj.next].prev = i
+	}
+	t.heads[tile] = i
+}
+
+func (t *tiling) remove(bjs []briefJet, i int) {
+	bj := &bjs[i]
+	if bj.prev >= 0 {
+		bjs[bj.prev].next = bj.next
+	} else {
+		t.heads[bj.tile] = bj.next
+	}
+	if bj.next >= 0 {
+		bjs[bj.next].prev = bj.prev
+	}
+	bj.prev = -1
+	bj.next = -1
+}
+
+// jets appends to o the jets held by the neighbouring tiles of the
+// provided tiles, skipping the tiles already marked as visited.
+func (t *tiling) jets(o []int, bjs []briefJet, visited []bool, tiles ...int) []int {
+	for _, tile := range tiles {
+		for _, nbr := range t.nbrs[tile] {
+			if visited[nbr] {
+				continue
+			}
+			visited[nbr] = true
+			for i := t.heads[nbr]; i >= 0; i = bjs[i].next {
+				o = append(o, i)
+			}
+		}
+	}
+	return o
+}
+
+// runN2Tiled runs the clustering, keeping track of the nearest neighbour
+// of each jet and restricting the nearest neighbour searches to the
+// neighbouring tiles of each jet.
+func (cs *ClusterSequence) runN2Tiled() error {
+	n := len(cs.jets)
+	bjs, active := cs.scratch(n)
+	tiles := &cs.tiles
+	tiles.reset(cs.jets, cs.r)
+	for i := range bjs {
+		cs.bjInit(&bjs[i], i)
+		tiles.add(bjs, i, tiles.index(&cs.jets[i]))
+	}
+
+	var (
+		visited = cs.visited(len(tiles.heads))
+		cands   = cs.cands[:0]
+		nbrs    = cs.nbrs[:0]
+	)
+	defer func() {
+		cs.cands = cands
+		cs.nbrs = nbrs
+	}()
+	reset := func() {
+		for i := range visited {
+			visited[i] = false
+		}
+	}
+
+	for i := range bjs {
+		reset()
+		cands = tiles.jets(cands[:0], bjs, visited, bjs[i].tile)
+		for _, j := range cands {
+			if j <= i {
+				continue
+			}
+			dist := cs.bjDist(bjs, i, j)
+			if dist < bjs[i].nnDist {
+				bjs[i].nnDist = dist
+				bjs[i].nn = j
+			}
+			if dist < bjs[j].nnDist {
+				bjs[j].nnDist = dist
+				bjs[j].nn = i
+			}
+		}
+	}
+	for i := range bjs {
+		bjs[i].diJ = cs.bjDiJ(bjs, i)
+	}
+
+	for len(active) > 0 {
+		ia, pos := cs.bjMinDiJ(bjs, active)
+		ib := bjs[ia].nn
+		dmin := bjs[ia].diJ * cs.invR2
+
+		active[pos] = active[len(active)-1]
+		active = active[:len(active)-1]
+
+		reset()
+		nbrs = tiles.jets(nbrs[:0], bjs, visited, bjs[ia].tile)
+		tiles.remove(bjs, ia)
+
+		if ib >= 0 {
+			nn, err := cs.ijRecombinationStep(bjs[ia].jet, bjs[ib].jet, dmin)
+			if err != nil {
+				return err
+			}
+			nbrs = tiles.jets(nbrs, bjs, visited, bjs[ib].tile)
+			tiles.remove(bjs, ib)
+			cs.bjInit(&bjs[ib], nn)
+			tile := tiles.index(&cs.jets[nn])
+			tiles.add(bjs, ib, tile)
+			nbrs = tiles.jets(nbrs, bjs, visited, tile)
+		} else {
+			err := cs.ibRecombinationStep(bjs[ia].jet, dmin)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, k := range nbrs {
+			if k == ia {
+				continue
+			}
+			if bjs[k].nn == ia || (ib >= 0 && bjs[k].nn == ib && k != ib) {
+				reset()
+				cands = tiles.jets(cands[:0], bjs, visited, bjs[k].tile)
+			}
+			cs.bjUpdate(bjs, k, ia, ib, cands)
+		}
+		if ib >= 0 {
+			bjs[ib].diJ = cs.bjDiJ(bjs, ib)
+		}
+	}
+
+	return nil
+}
+
+// maxPlainN is the maximum number of particles for which the BestStrategy
+// selects the N2Plain strategy over the N2Tiled one.
+const maxPlainN = 30
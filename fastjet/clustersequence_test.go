@@ -0,0 +1,187 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/fastjet"
+)
+
+func TestClusterSequenceHistory(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		def   fastjet.JetDefinition
+	}{
+		{
+			name:  "ee-kt",
+			input: "testdata/single-ee-event.dat",
+			def: fastjet.NewJetDefinitionExtra(
+				fastjet.EeKtAlgorithm, 0.4, fastjet.EScheme, fastjet.BestStrategy, 1,
+			),
+		},
+		{
+			name:  "pp-kt",
+			input: "testdata/single-pp-event.dat",
+			def: fastjet.NewJetDefinition(
+				fastjet.KtAlgorithm, 0.4, fastjet.EScheme, fastjet.BestStrategy,
+			),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			particles, err := loadParticles(tc.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cs, err := fastjet.NewClusterSequence(particles, tc.def)
+			if err != nil {
+				t.Fatalf("could not cluster event: %+v", err)
+			}
+
+			hist := cs.History()
+			if got, want := len(hist), 2*len(particles); got != want {
+				t.Fatalf("invalid history length: got=%d, want=%d", got, want)
+			}
+			for i, h := range hist[:len(particles)] {
+				if h.Parent1 != -2 || h.Parent2 != -2 || h.Jet != i {
+					t.Fatalf("invalid history element #%d: %+v", i, h)
+				}
+			}
+			if got, want := len(cs.Jets()), len(particles); got < want {
+				t.Fatalf("invalid number of jets: got=%d, want>=%d", got, want)
+			}
+
+			if got := cs.DMerge(len(particles)); got != 0 {
+				t.Fatalf("invalid dmerge for all particles: got=%v", got)
+			}
+
+			q2 := cs.Q() * cs.Q()
+			for n := 1; n < 6; n++ {
+				dmerge := cs.DMerge(n)
+				if dmerge <= 0 {
+					t.Fatalf("n=%d: invalid dmerge: %v", n, dmerge)
+				}
+				if got, want := cs.DMergeMax(n), dmerge; got != want {
+					// the kt algorithms have ordered dij.
+					t.Fatalf("n=%d: invalid dmerge-max: got=%v, want=%v", n, got, want)
+				}
+				if got, want := cs.YMerge(n), dmerge/q2; got != want {
+					t.Fatalf("n=%d: invalid ymerge: got=%v, want=%v", n, got, want)
+				}
+				if got, want := cs.YMergeMax(n), cs.DMergeMax(n)/q2; got != want {
+					t.Fatalf("n=%d: invalid ymerge-max: got=%v, want=%v", n, got, want)
+				}
+				if n > 1 && cs.DMerge(n-1) < dmerge {
+					t.Fatalf("n=%d: dmerge not ordered: d(%d)=%v < d(%d)=%v", n, n-1, cs.DMerge(n-1), n, dmerge)
+				}
+
+				if got := cs.NumExclusiveJets(cs.DMergeMax(n)); got != n {
+					t.Fatalf("n=%d: invalid number of exclusive jets: got=%d", n, got)
+				}
+				if got := cs.NumExclusiveJets(cs.DMergeMax(n) * (1 - 1e-9)); got != n+1 {
+					t.Fatalf("n=%d: invalid number of exclusive jets below dmerge: got=%d, want=%d", n, got, n+1)
+				}
+				if got := cs.NumExclusiveJetsYcut(cs.YMergeMax(n) * (1 + 1e-9)); got != n {
+					t.Fatalf("n=%d: invalid number of exclusive jets (ycut): got=%d", n, got)
+				}
+
+				jets, err := cs.ExclusiveJetsUpTo(n)
+				if err != nil {
+					t.Fatalf("n=%d: could not retrieve exclusive jets: %+v", n, err)
+				}
+				if len(jets) != n {
+					t.Fatalf("n=%d: invalid number of exclusive jets: got=%d", n, len(jets))
+				}
+				ycut, err := cs.ExclusiveJetsYcut(cs.YMergeMax(n) * (1 + 1e-9))
+				if err != nil {
+					t.Fatalf("n=%d: could not retrieve exclusive jets (ycut): %+v", n, err)
+				}
+				if len(ycut) != n {
+					t.Fatalf("n=%d: invalid number of exclusive jets (ycut): got=%d", n, len(ycut))
+				}
+
+				idx, err := cs.ParticleJetIndices(jets)
+				if err != nil {
+					t.Fatalf("n=%d: could not retrieve particle-jet indices: %+v", n, err)
+				}
+				if len(idx) != len(particles) {
+					t.Fatalf("n=%d: invalid number of indices: got=%d", n, len(idx))
+				}
+				sums := make([][4]float64, n)
+				for i, j := range idx {
+					if j < 0 {
+						// particle recombined with the beam.
+						continue
+					}
+					if j >= n {
+						t.Fatalf("n=%d: invalid jet index for particle #%d: %d", n, i, j)
+					}
+					p := &particles[i]
+					sums[j][0] += p.Px()
+					sums[j][1] += p.Py()
+					sums[j][2] += p.Pz()
+					sums[j][3] += p.E()
+				}
+				for j := range jets {
+					jet := &jets[j]
+					for k, v := range []float64{jet.Px(), jet.Py(), jet.Pz(), jet.E()} {
+						if math.Abs(v-sums[j][k]) > 1e-9*cs.Q() {
+							t.Fatalf("n=%d: jet #%d: 4-momentum mismatch: got=%v, want=%v", n, j, sums[j], jet.PxPyPzE)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParticleJetIndices(t *testing.T) {
+	particles, err := loadParticles("testdata/single-pp-event.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := fastjet.NewJetDefinition(fastjet.AntiKtAlgorithm, 0.4, fastjet.EScheme, fastjet.BestStrategy)
+	cs, err := fastjet.NewClusterSequence(particles, def)
+	if err != nil {
+		t.Fatalf("could not cluster event: %+v", err)
+	}
+	jets, err := cs.InclusiveJets(20)
+	if err != nil {
+		t.Fatalf("could not retrieve inclusive jets: %+v", err)
+	}
+
+	idx, err := cs.ParticleJetIndices(jets)
+	if err != nil {
+		t.Fatalf("could not retrieve particle-jet indices: %+v", err)
+	}
+
+	counts := make([]int, len(jets))
+	unassigned := 0
+	for _, j := range idx {
+		if j < 0 {
+			unassigned++
+			continue
+		}
+		counts[j]++
+	}
+	for i := range jets {
+		if got, want := counts[i], len(jets[i].Constituents()); got != want {
+			t.Fatalf("jet #%d: invalid number of particles: got=%d, want=%d", i, got, want)
+		}
+	}
+	if unassigned == 0 {
+		t.Fatalf("expected some particles outside of the pt>20 jets")
+	}
+
+	bad := []fastjet.Jet{fastjet.NewJet(1, 2, 3, 4)}
+	_, err = cs.ParticleJetIndices(bad)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
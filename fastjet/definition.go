@@ -118,4 +118,3 @@ func (def JetDefinition) Plugin() Plugin {
 // to impl:
 //  - ClusterSequence
 //  - PseudoJet
-//  - Selector + JetMedianBkgEstimator (only if compute-rho)
@@ -87,6 +87,32 @@ func (jet *Jet) Constituents() []Jet {
 	return subjets
 }
 
+// Area returns the area of this jet.
+// Area panics if the jet was not clustered by a ClusterSequenceArea.
+func (jet *Jet) Area() float64 {
+	return jet.areaStructure().Area(jet)
+}
+
+// AreaError returns the uncertainty on the area of this jet.
+// AreaError panics if the jet was not clustered by a ClusterSequenceArea.
+func (jet *Jet) AreaError() float64 {
+	return jet.areaStructure().AreaError(jet)
+}
+
+// AreaFourVector returns the 4-vector area of this jet.
+// AreaFourVector panics if the jet was not clustered by a ClusterSequenceArea.
+func (jet *Jet) AreaFourVector() fmom.PxPyPzE {
+	return jet.areaStructure().AreaFourVector(jet)
+}
+
+func (jet *Jet) areaStructure() AreaStructure {
+	area, ok := jet.structure.(AreaStructure)
+	if !ok {
+		panic("fastjet: jet has no area information")
+	}
+	return area
+}
+
 // Distance returns the squared cylinder (rapidity-phi) distance between 2 jets
 func Distance(j1, j2 *Jet) float64 {
 	dphi := deltaPhi(j1, j2)
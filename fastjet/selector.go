@@ -0,0 +1,297 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Selector selects jets from a slice of jets.
+//
+// Selectors can be combined with And, Or, Not and Mul:
+//
+//	sel := SelectorPtMin(30).And(SelectorAbsEtaMax(2.5))
+//	jets = sel.Select(jets)
+//
+// Selectors are either jet-by-jet selectors (e.g. SelectorPtMin) whose
+// decision only depends on the jet being tested, or selectors whose decision
+// depends on the whole set of jets (e.g. SelectorNHardest).
+type Selector struct {
+	desc string
+	pass func(jet *Jet) bool           // decision for jet-by-jet selectors
+	fn   func(jets []Jet, keep []bool) // rejects jets among the ones still kept
+}
+
+// SelectorFunc returns a jet-by-jet selector keeping jets for which
+// the provided function returns true.
+func SelectorFunc(desc string, f func(jet *Jet) bool) Selector {
+	return Selector{desc: desc, pass: f}
+}
+
+// SelectorIdentity returns a selector keeping all jets.
+func SelectorIdentity() Selector {
+	return SelectorFunc("Identity", func(*Jet) bool { return true })
+}
+
+// SelectorPtMin returns a selector keeping jets with pt >= ptmin.
+func SelectorPtMin(ptmin float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("pt >= %v", ptmin),
+		func(jet *Jet) bool { return jet.Pt2() >= ptmin*ptmin },
+	)
+}
+
+// SelectorPtMax returns a selector keeping jets with pt <= ptmax.
+func SelectorPtMax(ptmax float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("pt <= %v", ptmax),
+		func(jet *Jet) bool { return jet.Pt2() <= ptmax*ptmax },
+	)
+}
+
+// SelectorPtRange returns a selector keeping jets with ptmin <= pt <= ptmax.
+func SelectorPtRange(ptmin, ptmax float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("%v <= pt <= %v", ptmin, ptmax),
+		func(jet *Jet) bool {
+			pt2 := jet.Pt2()
+			return ptmin*ptmin <= pt2 && pt2 <= ptmax*ptmax
+		},
+	)
+}
+
+// SelectorEMin returns a selector keeping jets with E >= emin.
+func SelectorEMin(emin float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("E >= %v", emin),
+		func(jet *Jet) bool { return jet.E() >= emin },
+	)
+}
+
+// SelectorMassMin returns a selector keeping jets with m >= mmin.
+func SelectorMassMin(mmin float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("m >= %v", mmin),
+		func(jet *Jet) bool { return jet.M() >= mmin },
+	)
+}
+
+// SelectorMassMax returns a selector keeping jets with m <= mmax.
+func SelectorMassMax(mmax float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("m <= %v", mmax),
+		func(jet *Jet) bool { return jet.M() <= mmax },
+	)
+}
+
+// SelectorAbsEtaMax returns a selector keeping jets with |eta| <= etamax.
+func SelectorAbsEtaMax(etamax float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("|eta| <= %v", etamax),
+		func(jet *Jet) bool { return math.Abs(jet.Eta()) <= etamax },
+	)
+}
+
+// SelectorEtaRange returns a selector keeping jets with etamin <= eta <= etamax.
+func SelectorEtaRange(etamin, etamax float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("%v <= eta <= %v", etamin, etamax),
+		func(jet *Jet) bool {
+			eta := jet.Eta()
+			return etamin <= eta && eta <= etamax
+		},
+	)
+}
+
+// SelectorAbsRapMax returns a selector keeping jets with |y| <= rapmax.
+func SelectorAbsRapMax(rapmax float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("|rap| <= %v", rapmax),
+		func(jet *Jet) bool { return math.Abs(jet.Rapidity()) <= rapmax },
+	)
+}
+
+// SelectorRapRange returns a selector keeping jets with rapmin <= y <= rapmax.
+func SelectorRapRange(rapmin, rapmax float64) Selector {
+	return SelectorFunc(
+		fmt.Sprintf("%v <= rap <= %v", rapmin, rapmax),
+		func(jet *Jet) bool {
+			rap := jet.Rapidity()
+			return rapmin <= rap && rap <= rapmax
+		},
+	)
+}
+
+// SelectorNHardest returns a selector keeping the n hardest jets (in pt).
+func SelectorNHardest(n int) Selector {
+	return Selector{
+		desc: fmt.Sprintf("%d hardest", n),
+		fn: func(jets []Jet, keep []bool) {
+			idx := make([]int, 0, len(jets))
+			for i := range jets {
+				if keep[i] {
+					idx = append(idx, i)
+				}
+			}
+			if len(idx) <= n {
+				return
+			}
+			sort.SliceStable(idx, func(i, j int) bool {
+				return jets[idx[i]].Pt2() > jets[idx[j]].Pt2()
+			})
+			for _, i := range idx[imax(0, n):] {
+				keep[i] = false
+			}
+		},
+	}
+}
+
+// Description returns a description of the selection.
+func (sel Selector) Description() string {
+	return sel.desc
+}
+
+func (sel Selector) String() string {
+	return sel.desc
+}
+
+// IsJetByJet returns whether the selector decision for a jet only depends
+// on that jet.
+func (sel Selector) IsJetByJet() bool {
+	return sel.pass != nil
+}
+
+// Pass returns whether the jet passes the selection.
+// Pass panics if the selector is not a jet-by-jet selector.
+func (sel Selector) Pass(jet *Jet) bool {
+	if sel.pass == nil {
+		panic(fmt.Errorf("fastjet: selector %q is not a jet-by-jet selector", sel.desc))
+	}
+	return sel.pass(jet)
+}
+
+// Select returns the jets passing the selection, in their original order.
+func (sel Selector) Select(jets []Jet) []Jet {
+	keep := make([]bool, len(jets))
+	for i := range keep {
+		keep[i] = true
+	}
+	sel.apply(jets, keep)
+
+	o := make([]Jet, 0, len(jets))
+	for i, ok := range keep {
+		if ok {
+			o = append(o, jets[i])
+		}
+	}
+	return o
+}
+
+// Count returns the number of jets passing the selection.
+func (sel Selector) Count(jets []Jet) int {
+	return len(sel.Select(jets))
+}
+
+// apply rejects jets, among the ones still kept, not passing the selection.
+func (sel Selector) apply(jets []Jet, keep []bool) {
+	if sel.pass == nil {
+		sel.fn(jets, keep)
+		return
+	}
+	for i := range jets {
+		if keep[i] && !sel.pass(&jets[i]) {
+			keep[i] = false
+		}
+	}
+}
+
+// And returns a selector keeping jets passing both selections.
+// Both selections are applied independently to the jets.
+func (sel Selector) And(o Selector) Selector {
+	desc := "(" + sel.desc + " && " + o.desc + ")"
+	if sel.IsJetByJet() && o.IsJetByJet() {
+		return SelectorFunc(desc, func(jet *Jet) bool {
+			return sel.pass(jet) && o.pass(jet)
+		})
+	}
+	return Selector{
+		desc: desc,
+		fn: func(jets []Jet, keep []bool) {
+			k1 := append([]bool(nil), keep...)
+			sel.apply(jets, k1)
+			o.apply(jets, keep)
+			for i := range keep {
+				keep[i] = keep[i] && k1[i]
+			}
+		},
+	}
+}
+
+// Or returns a selector keeping jets passing either selection.
+// Both selections are applied independently to the jets.
+func (sel Selector) Or(o Selector) Selector {
+	desc := "(" + sel.desc + " || " + o.desc + ")"
+	if sel.IsJetByJet() && o.IsJetByJet() {
+		return SelectorFunc(desc, func(jet *Jet) bool {
+			return sel.pass(jet) || o.pass(jet)
+		})
+	}
+	return Selector{
+		desc: desc,
+		fn: func(jets []Jet, keep []bool) {
+			k1 := append([]bool(nil), keep...)
+			sel.apply(jets, k1)
+			o.apply(jets, keep)
+			for i := range keep {
+				keep[i] = keep[i] || k1[i]
+			}
+		},
+	}
+}
+
+// Not returns a selector keeping jets failing the selection.
+func (sel Selector) Not() Selector {
+	desc := "!" + sel.desc
+	if sel.IsJetByJet() {
+		return SelectorFunc(desc, func(jet *Jet) bool {
+			return !sel.pass(jet)
+		})
+	}
+	return Selector{
+		desc: desc,
+		fn: func(jets []Jet, keep []bool) {
+			k1 := append([]bool(nil), keep...)
+			sel.apply(jets, k1)
+			for i := range keep {
+				keep[i] = keep[i] && !k1[i]
+			}
+		},
+	}
+}
+
+// Mul returns a selector applying the o selection and then this selection
+// to the jets passing the o selection.
+//
+// For example, SelectorNHardest(2).Mul(SelectorAbsRapMax(2.5)) keeps the
+// 2 hardest jets with |y| <= 2.5, while
+// SelectorNHardest(2).And(SelectorAbsRapMax(2.5)) keeps the jets among the
+// 2 hardest ones with |y| <= 2.5.
+func (sel Selector) Mul(o Selector) Selector {
+	desc := "(" + sel.desc + " * " + o.desc + ")"
+	if sel.IsJetByJet() && o.IsJetByJet() {
+		return SelectorFunc(desc, func(jet *Jet) bool {
+			return o.pass(jet) && sel.pass(jet)
+		})
+	}
+	return Selector{
+		desc: desc,
+		fn: func(jets []Jet, keep []bool) {
+			o.apply(jets, keep)
+			sel.apply(jets, keep)
+		},
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"go-hep.org/x/hep/fastjet"
+)
+
+func TestSelector(t *testing.T) {
+	jets := []fastjet.Jet{
+		newPtYPhiM(10, 0.5, 0, 0),
+		newPtYPhiM(50, -3.0, 1, 0),
+		newPtYPhiM(40, 1.0, 2, 10),
+		newPtYPhiM(5, 2.0, 3, 0),
+		newPtYPhiM(100, -0.5, 4, 20),
+	}
+
+	for _, tc := range []struct {
+		sel   fastjet.Selector
+		want  []int
+		desc  string
+		byjet bool
+	}{
+		{
+			sel:   fastjet.SelectorIdentity(),
+			want:  []int{0, 1, 2, 3, 4},
+			desc:  "Identity",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorPtMin(30),
+			want:  []int{1, 2, 4},
+			desc:  "pt >= 30",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorPtMax(30),
+			want:  []int{0, 3},
+			desc:  "pt <= 30",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorPtRange(10, 50),
+			want:  []int{0, 1, 2},
+			desc:  "10 <= pt <= 50",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorAbsRapMax(1),
+			want:  []int{0, 2, 4},
+			desc:  "|rap| <= 1",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorRapRange(0, 2.5),
+			want:  []int{0, 2, 3},
+			desc:  "0 <= rap <= 2.5",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorAbsEtaMax(2.5),
+			want:  []int{0, 2, 3, 4},
+			desc:  "|eta| <= 2.5",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorEtaRange(-5, 0),
+			want:  []int{1, 4},
+			desc:  "-5 <= eta <= 0",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorMassMin(5),
+			want:  []int{2, 4},
+			desc:  "m >= 5",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorMassMax(15),
+			want:  []int{0, 1, 2, 3},
+			desc:  "m <= 15",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorEMin(100),
+			want:  []int{1, 4},
+			desc:  "E >= 100",
+			byjet: true,
+		},
+		{
+			sel:  fastjet.SelectorNHardest(2),
+			want: []int{1, 4},
+			desc: "2 hardest",
+		},
+		{
+			sel:  fastjet.SelectorNHardest(10),
+			want: []int{0, 1, 2, 3, 4},
+			desc: "10 hardest",
+		},
+		{
+			sel:  fastjet.SelectorNHardest(0),
+			want: []int{},
+			desc: "0 hardest",
+		},
+		{
+			sel:   fastjet.SelectorPtMin(30).And(fastjet.SelectorAbsEtaMax(2.5)),
+			want:  []int{2, 4},
+			desc:  "(pt >= 30 && |eta| <= 2.5)",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorPtMin(30).Or(fastjet.SelectorAbsRapMax(1)),
+			want:  []int{0, 1, 2, 4},
+			desc:  "(pt >= 30 || |rap| <= 1)",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorPtMin(30).Not(),
+			want:  []int{0, 3},
+			desc:  "!pt >= 30",
+			byjet: true,
+		},
+		{
+			sel:   fastjet.SelectorPtMin(30).Mul(fastjet.SelectorAbsRapMax(1)),
+			want:  []int{2, 4},
+			desc:  "(pt >= 30 * |rap| <= 1)",
+			byjet: true,
+		},
+		{
+			sel:  fastjet.SelectorNHardest(2).And(fastjet.SelectorAbsRapMax(1)),
+			want: []int{4},
+			desc: "(2 hardest && |rap| <= 1)",
+		},
+		{
+			sel:  fastjet.SelectorNHardest(2).Mul(fastjet.SelectorAbsRapMax(1)),
+			want: []int{2, 4},
+			desc: "(2 hardest * |rap| <= 1)",
+		},
+		{
+			sel:  fastjet.SelectorNHardest(1).Or(fastjet.SelectorPtMax(5)),
+			want: []int{3, 4},
+			desc: "(1 hardest || pt <= 5)",
+		},
+		{
+			sel:  fastjet.SelectorNHardest(2).Not(),
+			want: []int{0, 2, 3},
+			desc: "!2 hardest",
+		},
+		{
+			sel:  fastjet.SelectorNHardest(2).Not().Mul(fastjet.SelectorAbsRapMax(2)),
+			want: []int{0, 3},
+			desc: "(!2 hardest * |rap| <= 2)",
+		},
+		{
+			sel: fastjet.SelectorFunc("phi > 2", func(jet *fastjet.Jet) bool {
+				return jet.Phi() > 2
+			}),
+			want:  []int{3},
+			desc:  "phi > 2",
+			byjet: true,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got, want := tc.sel.Description(), tc.desc; got != want {
+				t.Fatalf("invalid description: got=%q, want=%q", got, want)
+			}
+			if got, want := tc.sel.IsJetByJet(), tc.byjet; got != want {
+				t.Fatalf("invalid jet-by-jet: got=%v, want=%v", got, want)
+			}
+
+			got := indices(jets, tc.sel.Select(jets))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("invalid selection:\ngot= %v\nwant=%v", got, tc.want)
+			}
+			if got, want := tc.sel.Count(jets), len(tc.want); got != want {
+				t.Fatalf("invalid count: got=%d, want=%d", got, want)
+			}
+
+			if !tc.byjet {
+				return
+			}
+			pass := []int{}
+			for i := range jets {
+				if tc.sel.Pass(&jets[i]) {
+					pass = append(pass, i)
+				}
+			}
+			if !reflect.DeepEqual(pass, tc.want) {
+				t.Fatalf("invalid pass:\ngot= %v\nwant=%v", pass, tc.want)
+			}
+		})
+	}
+
+	t.Run("pass-panics", func(t *testing.T) {
+		defer func() {
+			if e := recover(); e == nil {
+				t.Fatalf("expected a panic")
+			}
+		}()
+		fastjet.SelectorNHardest(2).Pass(&jets[0])
+	})
+}
+
+// indices returns the indices in jets of the selected jets.
+func indices(jets, sel []fastjet.Jet) []int {
+	o := make([]int, 0, len(sel))
+	for _, jet := range sel {
+		for i := range jets {
+			if jet.PxPyPzE == jets[i].PxPyPzE {
+				o = append(o, i)
+			}
+		}
+	}
+	return o
+}
+
+func newPtYPhiM(pt, y, phi, m float64) fastjet.Jet {
+	mt := math.Sqrt(pt*pt + m*m)
+	return fastjet.NewJet(
+		pt*math.Cos(phi), pt*math.Sin(phi),
+		mt*math.Sinh(y), mt*math.Cosh(y),
+	)
+}
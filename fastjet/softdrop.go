@@ -0,0 +1,127 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// maxAllowableR is the radius used to recluster the whole content
+// of a jet into a single jet.
+const maxAllowableR = 1000
+
+// SoftDrop grooms jets by declustering their Cambridge/Aachen clustering
+// history, dropping the softer branch until the two branches satisfy:
+//
+//	min(pt1, pt2) / (pt1 + pt2) > ZCut * (ΔR12 / R0)^Beta
+//
+// See arXiv:1402.2657 for details.
+type SoftDrop struct {
+	Beta float64 // angular exponent
+	ZCut float64 // symmetry cut
+	R0   float64 // characteristic radius of the jet
+}
+
+// NewSoftDrop returns a new SoftDrop groomer with R0=1.
+func NewSoftDrop(beta, zcut float64) SoftDrop {
+	return SoftDrop{Beta: beta, ZCut: zcut, R0: 1}
+}
+
+// NewModifiedMassDrop returns a new modified Mass-Drop tagger (mMDT),
+// ie: a SoftDrop groomer with Beta=0.
+//
+// See arXiv:1307.0007 for details.
+func NewModifiedMassDrop(zcut float64) SoftDrop {
+	return NewSoftDrop(0, zcut)
+}
+
+// SoftDropJet is a jet groomed by SoftDrop.
+type SoftDropJet struct {
+	Jet // groomed jet
+
+	Zg      float64 // momentum sharing of the two branches passing the SoftDrop condition
+	Rg      float64 // rapidity-phi distance between the two branches passing the SoftDrop condition
+	Dropped int     // number of dropped branches
+}
+
+// Groom returns the jet groomed by SoftDrop.
+// The constituents of the groomed jet are available, and Zg and Rg are
+// zero when no pair of branches satisfied the SoftDrop condition.
+func (sd SoftDrop) Groom(jet *Jet) (SoftDropJet, error) {
+	if sd.R0 <= 0 {
+		return SoftDropJet{}, fmt.Errorf("fastjet: invalid SoftDrop R0 (%v)", sd.R0)
+	}
+	def := NewJetDefinition(CambridgeAlgorithm, maxAllowableR, EScheme, BestStrategy)
+	cs, jets, err := recluster(jet, def)
+	if err != nil {
+		return SoftDropJet{}, fmt.Errorf("fastjet: could not recluster jet: %w", err)
+	}
+	if len(jets) == 0 {
+		return SoftDropJet{}, fmt.Errorf("fastjet: could not recluster jet: no jet")
+	}
+
+	var (
+		cur = jets[0]
+		o   SoftDropJet
+	)
+	for {
+		p1, p2, ok := cs.Parents(&cur)
+		if !ok {
+			break
+		}
+		var (
+			pt1 = p1.Pt()
+			pt2 = p2.Pt()
+			z   = math.Min(pt1, pt2) / (pt1 + pt2)
+			dr  = math.Sqrt(Distance(&p1, &p2))
+		)
+		if z > sd.ZCut*math.Pow(dr/sd.R0, sd.Beta) {
+			o.Zg = z
+			o.Rg = dr
+			break
+		}
+		o.Dropped++
+		cur = p1
+	}
+	o.Jet = cur
+	return o, nil
+}
+
+// Transform returns the jet groomed by SoftDrop.
+func (sd SoftDrop) Transform(jet *Jet) (Jet, error) {
+	sdj, err := sd.Groom(jet)
+	return sdj.Jet, err
+}
+
+// recluster clusters the constituents of the provided jet with the provided
+// jet definition, and returns the resulting inclusive jets, sorted by
+// decreasing pt.
+func recluster(jet *Jet, def JetDefinition) (*ClusterSequence, []Jet, error) {
+	if jet.structure == nil {
+		return nil, nil, fmt.Errorf("fastjet: jet has no constituents")
+	}
+	cons, err := jet.structure.Constituents(jet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fastjet: could not retrieve jet constituents: %w", err)
+	}
+	// reset the clustering information of the constituents.
+	for i := range cons {
+		cons[i].hidx = -1
+		cons[i].structure = nil
+	}
+
+	cs, err := NewClusterSequence(cons, def)
+	if err != nil {
+		return nil, nil, err
+	}
+	jets, err := cs.InclusiveJets(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(ByPt(jets))
+	return cs, jets, nil
+}
@@ -0,0 +1,118 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"go-hep.org/x/hep/fastjet"
+)
+
+func TestSoftDrop(t *testing.T) {
+	particles, err := loadParticles("testdata/single-pp-event.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := fastjet.NewJetDefinition(fastjet.AntiKtAlgorithm, 1.0, fastjet.EScheme, fastjet.BestStrategy)
+	cs, err := fastjet.NewClusterSequence(particles, def)
+	if err != nil {
+		t.Fatalf("could not cluster event: %+v", err)
+	}
+	jets, err := cs.InclusiveJets(20)
+	if err != nil {
+		t.Fatalf("could not retrieve inclusive jets: %+v", err)
+	}
+	sort.Sort(fastjet.ByPt(jets))
+
+	for _, tc := range []struct {
+		name string
+		sd   fastjet.SoftDrop
+	}{
+		{"beta=0,zcut=0.1", fastjet.NewModifiedMassDrop(0.1)},
+		{"beta=1,zcut=0.1", fastjet.NewSoftDrop(1, 0.1)},
+		{"beta=2,zcut=0.2", fastjet.NewSoftDrop(2, 0.2)},
+		{"beta=-1,zcut=0.05", fastjet.NewSoftDrop(-1, 0.05)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := range jets {
+				jet := &jets[i]
+				sdj, err := tc.sd.Groom(jet)
+				if err != nil {
+					t.Fatalf("jet #%d: could not groom: %+v", i, err)
+				}
+
+				if sdj.Pt() > jet.Pt()*(1+1e-12) {
+					t.Fatalf("jet #%d: groomed pt larger than original pt: %v > %v", i, sdj.Pt(), jet.Pt())
+				}
+
+				var (
+					orig = len(jet.Constituents())
+					ncon = len(sdj.Constituents())
+				)
+				switch {
+				case ncon > orig:
+					t.Fatalf("jet #%d: groomed jet has more constituents (%d) than original (%d)", i, ncon, orig)
+				case ncon == 1:
+					if sdj.Zg != 0 || sdj.Rg != 0 {
+						t.Fatalf("jet #%d: invalid single particle groomed jet: zg=%v, rg=%v", i, sdj.Zg, sdj.Rg)
+					}
+				default:
+					if sdj.Zg <= tc.sd.ZCut*math.Pow(sdj.Rg/tc.sd.R0, tc.sd.Beta) {
+						t.Fatalf("jet #%d: groomed jet fails SoftDrop condition: zg=%v, rg=%v", i, sdj.Zg, sdj.Rg)
+					}
+					if sdj.Zg > 0.5 || sdj.Rg <= 0 {
+						t.Fatalf("jet #%d: invalid groomed jet: zg=%v, rg=%v", i, sdj.Zg, sdj.Rg)
+					}
+				}
+				if sdj.Dropped == 0 && math.Abs(sdj.Pt()-jet.Pt()) > 1e-9*jet.Pt() {
+					t.Fatalf("jet #%d: invalid ungroomed jet pt: got=%v, want=%v", i, sdj.Pt(), jet.Pt())
+				}
+			}
+		})
+	}
+
+	t.Run("zcut=0", func(t *testing.T) {
+		sd := fastjet.NewModifiedMassDrop(0)
+		for i := range jets {
+			sdj, err := sd.Groom(&jets[i])
+			if err != nil {
+				t.Fatalf("jet #%d: could not groom: %+v", i, err)
+			}
+			if sdj.Dropped != 0 {
+				t.Fatalf("jet #%d: invalid number of dropped branches: %d", i, sdj.Dropped)
+			}
+			if got, want := len(sdj.Constituents()), len(jets[i].Constituents()); got != want {
+				t.Fatalf("jet #%d: invalid number of constituents: got=%d, want=%d", i, got, want)
+			}
+		}
+	})
+
+	t.Run("zcut=0.5", func(t *testing.T) {
+		sd := fastjet.NewModifiedMassDrop(0.5)
+		for i := range jets {
+			sdj, err := sd.Groom(&jets[i])
+			if err != nil {
+				t.Fatalf("jet #%d: could not groom: %+v", i, err)
+			}
+			if got := len(sdj.Constituents()); got != 1 {
+				t.Fatalf("jet #%d: invalid number of constituents: got=%d, want=1", i, got)
+			}
+			if sdj.Zg != 0 || sdj.Rg != 0 {
+				t.Fatalf("jet #%d: invalid zg=%v, rg=%v", i, sdj.Zg, sdj.Rg)
+			}
+		}
+	})
+
+	t.Run("no-constituents", func(t *testing.T) {
+		jet := fastjet.NewJet(1, 2, 3, 4)
+		_, err := fastjet.NewSoftDrop(0, 0.1).Groom(&jet)
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+}
@@ -9,6 +9,10 @@ import (
 )
 
 // Strategy defines the algorithmic strategy used while clustering.
+//
+// BestStrategy selects N2PlainStrategy for small events and N2TiledStrategy
+// otherwise.
+// The NlnN strategies are not implemented yet and use N2TiledStrategy.
 type Strategy int
 
 const (
@@ -0,0 +1,135 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet_test
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"go-hep.org/x/hep/fastjet"
+	"golang.org/x/exp/rand"
+)
+
+func TestStrategies(t *testing.T) {
+	const tol = 1e-9
+
+	pp, err := loadParticles("testdata/single-pp-event.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		particles []fastjet.Jet
+	}{
+		{"single-pp-event", pp},
+		{"rnd-10", rndParticles(10, 1234)},
+		{"rnd-100", rndParticles(100, 1234)},
+		{"rnd-300", rndParticles(300, 1234)},
+	} {
+		for _, alg := range []struct {
+			name string
+			alg  fastjet.JetAlgorithm
+		}{
+			{"kt", fastjet.KtAlgorithm},
+			{"cam", fastjet.CambridgeAlgorithm},
+			{"antikt", fastjet.AntiKtAlgorithm},
+		} {
+			for _, r := range []float64{0.05, 0.4, 1.0, 2.5} {
+				ref := inclusiveJets(t, tc.particles, fastjet.NewJetDefinition(
+					alg.alg, r, fastjet.EScheme, fastjet.N3DumbStrategy,
+				))
+				for _, strategy := range []fastjet.Strategy{
+					fastjet.N2PlainStrategy,
+					fastjet.N2TiledStrategy,
+					fastjet.NlnNStrategy,
+					fastjet.BestStrategy,
+				} {
+					name := fmt.Sprintf("%s-%s-r%v-%v", tc.name, alg.name, r, strategy)
+					t.Run(name, func(t *testing.T) {
+						got := inclusiveJets(t, tc.particles, fastjet.NewJetDefinition(
+							alg.alg, r, fastjet.EScheme, strategy,
+						))
+						if len(got) != len(ref) {
+							t.Fatalf("got %d jets, want %d", len(got), len(ref))
+						}
+						for i := range got {
+							g := []float64{got[i].Rapidity(), got[i].Phi(), got[i].Pt()}
+							w := []float64{ref[i].Rapidity(), ref[i].Phi(), ref[i].Pt()}
+							for j := range g {
+								if math.Abs(g[j]-w[j]) > tol {
+									t.Fatalf("jet #%d\ngot= %v\nwant=%v", i, g, w)
+								}
+							}
+						}
+					})
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkStrategies(b *testing.B) {
+	for _, n := range []int{100, 1000, 4000} {
+		particles := rndParticles(n, 1234)
+		for _, strategy := range []fastjet.Strategy{
+			fastjet.N3DumbStrategy,
+			fastjet.N2PlainStrategy,
+			fastjet.N2TiledStrategy,
+		} {
+			if strategy == fastjet.N3DumbStrategy && n > 1000 {
+				continue
+			}
+			def := fastjet.NewJetDefinition(
+				fastjet.AntiKtAlgorithm, 0.4, fastjet.EScheme, strategy,
+			)
+			b.Run(fmt.Sprintf("%v-%d", strategy, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_, err := fastjet.NewClusterSequence(particles, def)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func inclusiveJets(t *testing.T, particles []fastjet.Jet, def fastjet.JetDefinition) []fastjet.Jet {
+	t.Helper()
+
+	cs, err := fastjet.NewClusterSequence(particles, def)
+	if err != nil {
+		t.Fatalf("could not create cluster sequence: %+v", err)
+	}
+
+	jets, err := cs.InclusiveJets(0)
+	if err != nil {
+		t.Fatalf("could not retrieve inclusive jets: %+v", err)
+	}
+	sort.Sort(fastjet.ByPt(jets))
+	return jets
+}
+
+// rndParticles generates n massless particles uniformly distributed
+// in rapidity (|y|<5) and phi.
+func rndParticles(n int, seed uint64) []fastjet.Jet {
+	rnd := rand.New(rand.NewSource(seed))
+	particles := make([]fastjet.Jet, n)
+	for i := range particles {
+		var (
+			pt  = 1 + 50*rnd.Float64()
+			y   = 10 * (rnd.Float64() - 0.5)
+			phi = 2 * math.Pi * rnd.Float64()
+		)
+		particles[i] = fastjet.NewJet(
+			pt*math.Cos(phi), pt*math.Sin(phi),
+			pt*math.Sinh(y), pt*math.Cosh(y),
+		)
+	}
+	return particles
+}
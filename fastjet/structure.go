@@ -4,7 +4,20 @@
 
 package fastjet
 
+import (
+	"go-hep.org/x/hep/fmom"
+)
+
 // JetStructure allows to retrieve information related to the clustering.
 type JetStructure interface {
 	Constituents(jet *Jet) ([]Jet, error)
 }
+
+// AreaStructure allows to retrieve information related to the area of jets.
+type AreaStructure interface {
+	JetStructure
+
+	Area(jet *Jet) float64
+	AreaError(jet *Jet) float64
+	AreaFourVector(jet *Jet) fmom.PxPyPzE
+}
@@ -0,0 +1,218 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet
+
+import (
+	"fmt"
+	"math"
+)
+
+// Transformer transforms a jet into a new jet, e.g. by grooming it.
+type Transformer interface {
+	Transform(jet *Jet) (Jet, error)
+}
+
+var (
+	_ Transformer = (*Filter)(nil)
+	_ Transformer = (*Pruner)(nil)
+	_ Transformer = (*SoftDrop)(nil)
+)
+
+// Filter reclusters the constituents of a jet into subjets and only keeps
+// the hardest ones.
+//
+// Subjets are kept if their transverse momentum is larger than PtFrac times
+// the transverse momentum of the jet.
+// At most NHardest of these subjets are kept.
+type Filter struct {
+	Def      JetDefinition // jet definition used to recluster the jet into subjets
+	NHardest int           // maximal number of subjets kept (0 to keep all subjets)
+	PtFrac   float64       // minimal fraction of the jet transverse momentum of kept subjets
+}
+
+// NewFilter returns a new filter, keeping the nhardest subjets obtained with
+// the provided jet definition.
+//
+// See arXiv:0802.2470 for details.
+func NewFilter(def JetDefinition, nhardest int) Filter {
+	return Filter{Def: def, NHardest: nhardest}
+}
+
+// NewTrimmer returns a new trimmer, keeping the subjets obtained with the
+// provided jet definition and carrying at least a fraction fcut of the jet
+// transverse momentum.
+//
+// See arXiv:0912.1342 for details.
+func NewTrimmer(def JetDefinition, fcut float64) Filter {
+	return Filter{Def: def, PtFrac: fcut}
+}
+
+// Transform returns the jet made of the kept subjets.
+// The returned jet has no constituents if no subjet was kept.
+func (f Filter) Transform(jet *Jet) (Jet, error) {
+	_, subjets, err := recluster(jet, f.Def)
+	if err != nil {
+		return Jet{}, fmt.Errorf("fastjet: could not recluster jet: %w", err)
+	}
+
+	var (
+		ptmin = f.PtFrac * jet.Pt()
+		kept  = subjets[:0]
+	)
+	for _, sub := range subjets {
+		if sub.Pt() < ptmin {
+			break
+		}
+		kept = append(kept, sub)
+	}
+	if f.NHardest > 0 && len(kept) > f.NHardest {
+		kept = kept[:f.NHardest]
+	}
+
+	return newCompositeJet(kept)
+}
+
+// Pruner reclusters the constituents of a jet, pruning at each
+// recombination step the softer branch when:
+//
+//	min(pt1, pt2) / pt12 < ZCut and ΔR12 > RCutFactor * 2m/pt
+//
+// where m and pt are the mass and transverse momentum of the original jet.
+//
+// See arXiv:0903.5081 for details.
+type Pruner struct {
+	Def        JetDefinition // jet definition used to recluster the jet
+	ZCut       float64       // symmetry cut
+	RCutFactor float64       // angular cut factor
+}
+
+// NewPruner returns a new pruner reclustering jets with the provided
+// algorithm.
+func NewPruner(alg JetAlgorithm, zcut, rcutFactor float64) Pruner {
+	return Pruner{
+		Def:        NewJetDefinition(alg, maxAllowableR, EScheme, BestStrategy),
+		ZCut:       zcut,
+		RCutFactor: rcutFactor,
+	}
+}
+
+// Transform returns the pruned jet.
+// When the reclustering yields more than one jet, the hardest one is pruned.
+func (p Pruner) Transform(jet *Jet) (Jet, error) {
+	rcut := p.RCutFactor * 2 * jet.M() / jet.Pt()
+	rec := &pruningRecombiner{
+		rec:    p.Def.Recombiner(),
+		zcut:   p.ZCut,
+		rcut2:  rcut * rcut,
+		pruned: make(map[int]bool),
+	}
+	def := p.Def
+	def.recombiner = rec
+
+	cs, jets, err := recluster(jet, def)
+	if err != nil {
+		return Jet{}, fmt.Errorf("fastjet: could not recluster jet: %w", err)
+	}
+	if len(jets) == 0 {
+		return Jet{}, fmt.Errorf("fastjet: could not recluster jet: no jet")
+	}
+
+	o := jets[0]
+	o.hidx = -1
+	o.structure = compositeStructure(rec.constituents(cs, jets[0].hidx, nil))
+	return o, nil
+}
+
+// pruningRecombiner recombines jets, discarding the softer jet when
+// the pruning condition is met.
+type pruningRecombiner struct {
+	rec    Recombiner
+	zcut   float64
+	rcut2  float64
+	pruned map[int]bool // history indices of the pruned jets
+}
+
+func (rec *pruningRecombiner) Description() string {
+	return fmt.Sprintf(
+		"%s with pruning (zcut=%v, rcut=%v)",
+		rec.rec.Description(), rec.zcut, math.Sqrt(rec.rcut2),
+	)
+}
+
+func (rec *pruningRecombiner) Recombine(j1, j2 *Jet) (Jet, error) {
+	jet, err := rec.rec.Recombine(j1, j2)
+	if err != nil {
+		return jet, err
+	}
+	z := math.Min(j1.Pt(), j2.Pt()) / jet.Pt()
+	if z >= rec.zcut || Distance(j1, j2) <= rec.rcut2 {
+		return jet, nil
+	}
+	hard, soft := j1, j2
+	if hard.Pt2() < soft.Pt2() {
+		hard, soft = soft, hard
+	}
+	rec.pruned[soft.hidx] = true
+	return NewJet(hard.Px(), hard.Py(), hard.Pz(), hard.E()), nil
+}
+
+func (rec *pruningRecombiner) Preprocess(jet *Jet) error {
+	return rec.rec.Preprocess(jet)
+}
+
+func (rec *pruningRecombiner) Scheme() RecombinationScheme {
+	return rec.rec.Scheme()
+}
+
+// constituents appends to o the constituents of the i-th history element
+// that were not pruned away.
+func (rec *pruningRecombiner) constituents(cs *ClusterSequence, i int, o []Jet) []Jet {
+	if rec.pruned[i] {
+		return o
+	}
+	hh := cs.history[i]
+	if hh.parent1 == inexistentParent {
+		return append(o, cs.jets[hh.jet])
+	}
+	o = rec.constituents(cs, hh.parent1, o)
+	if hh.parent2 >= 0 {
+		o = rec.constituents(cs, hh.parent2, o)
+	}
+	return o
+}
+
+// compositeStructure is the structure of a jet made of an explicit list
+// of constituents.
+type compositeStructure []Jet
+
+func (cs compositeStructure) Constituents(jet *Jet) ([]Jet, error) {
+	o := make([]Jet, len(cs))
+	copy(o, cs)
+	return o, nil
+}
+
+// newCompositeJet returns the jet made of the provided jets.
+func newCompositeJet(jets []Jet) (Jet, error) {
+	var (
+		px, py, pz, e float64
+		cons          []Jet
+	)
+	for i := range jets {
+		jet := &jets[i]
+		px += jet.Px()
+		py += jet.Py()
+		pz += jet.Pz()
+		e += jet.E()
+
+		sub, err := jet.structure.Constituents(jet)
+		if err != nil {
+			return Jet{}, fmt.Errorf("fastjet: could not retrieve jet constituents: %w", err)
+		}
+		cons = append(cons, sub...)
+	}
+	o := NewJet(px, py, pz, e)
+	o.structure = compositeStructure(cons)
+	return o, nil
+}
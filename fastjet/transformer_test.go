@@ -0,0 +1,106 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fastjet_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"go-hep.org/x/hep/fastjet"
+)
+
+func TestTransformers(t *testing.T) {
+	particles, err := loadParticles("testdata/single-pp-event.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	def := fastjet.NewJetDefinition(fastjet.AntiKtAlgorithm, 1.0, fastjet.EScheme, fastjet.BestStrategy)
+	cs, err := fastjet.NewClusterSequence(particles, def)
+	if err != nil {
+		t.Fatalf("could not cluster event: %+v", err)
+	}
+	jets, err := cs.InclusiveJets(20)
+	if err != nil {
+		t.Fatalf("could not retrieve inclusive jets: %+v", err)
+	}
+	sort.Sort(fastjet.ByPt(jets))
+
+	var (
+		cam02 = fastjet.NewJetDefinition(fastjet.CambridgeAlgorithm, 0.2, fastjet.EScheme, fastjet.BestStrategy)
+		kt02  = fastjet.NewJetDefinition(fastjet.KtAlgorithm, 0.2, fastjet.EScheme, fastjet.BestStrategy)
+	)
+
+	for _, tc := range []struct {
+		name  string
+		tr    fastjet.Transformer
+		ident bool // whether the transformer leaves jets untouched
+	}{
+		{name: "filter-cam0.2-n3", tr: fastjet.NewFilter(cam02, 3)},
+		{name: "filter-cam0.2-all", tr: fastjet.NewFilter(cam02, 0), ident: true},
+		{name: "trimmer-kt0.2-f0.05", tr: fastjet.NewTrimmer(kt02, 0.05)},
+		{name: "trimmer-kt0.2-f0", tr: fastjet.NewTrimmer(kt02, 0), ident: true},
+		{name: "pruner-cam-z0.1-r0.5", tr: fastjet.NewPruner(fastjet.CambridgeAlgorithm, 0.1, 0.5)},
+		{name: "pruner-cam-z0", tr: fastjet.NewPruner(fastjet.CambridgeAlgorithm, 0, 0.5), ident: true},
+		{name: "softdrop-b0-z0.1", tr: fastjet.NewSoftDrop(0, 0.1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := range jets {
+				jet := &jets[i]
+				got, err := tc.tr.Transform(jet)
+				if err != nil {
+					t.Fatalf("jet #%d: could not transform jet: %+v", i, err)
+				}
+
+				if got.Pt() > jet.Pt()*(1+1e-12) {
+					t.Fatalf("jet #%d: transformed pt larger than original pt: %v > %v", i, got.Pt(), jet.Pt())
+				}
+
+				cons := got.Constituents()
+				if n := len(jet.Constituents()); len(cons) > n {
+					t.Fatalf("jet #%d: transformed jet has more constituents (%d) than original (%d)", i, len(cons), n)
+				}
+
+				var px, py, pz, e float64
+				for _, c := range cons {
+					px += c.Px()
+					py += c.Py()
+					pz += c.Pz()
+					e += c.E()
+				}
+				for _, v := range [][2]float64{
+					{px, got.Px()},
+					{py, got.Py()},
+					{pz, got.Pz()},
+					{e, got.E()},
+				} {
+					if math.Abs(v[0]-v[1]) > 1e-9*jet.E() {
+						t.Fatalf("jet #%d: constituents 4-momentum mismatch: got=%v, want=%v", i, v[0], v[1])
+					}
+				}
+
+				if tc.ident {
+					if len(cons) != len(jet.Constituents()) {
+						t.Fatalf("jet #%d: invalid number of constituents: got=%d, want=%d", i, len(cons), len(jet.Constituents()))
+					}
+					if math.Abs(got.Pt()-jet.Pt()) > 1e-9*jet.Pt() {
+						t.Fatalf("jet #%d: invalid pt: got=%v, want=%v", i, got.Pt(), jet.Pt())
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("trimmer-f1", func(t *testing.T) {
+		got, err := fastjet.NewTrimmer(kt02, 1).Transform(&jets[0])
+		if err != nil {
+			t.Fatalf("could not transform jet: %+v", err)
+		}
+		if got.E() != 0 || len(got.Constituents()) != 0 {
+			t.Fatalf("expected an empty jet, got=%v", got.PxPyPzE)
+		}
+	})
+}
@@ -10,20 +10,30 @@ import (
 
 // Curve1D returns the result of a non-linear least squares to fit
 // a function f to the underlying data with method m.
-func Curve1D(f Func1D, settings *optimize.Settings, m optimize.Method) (*optimize.Result, error) {
+//
+// The Hessian matrix of the cost function (half the chi-square) at the
+// optimum is always filled in the returned result, even when the method m
+// does not compute it.
+// Its inverse is the covariance matrix of the fitted parameters, also
+// returned in the result together with the parameters errors and their
+// correlation matrix.
+//
+// The fit can be further configured with options, e.g. to fix, bound or
+// constrain parameters (see WithFixed, WithBounds and WithConstraint).
+// Options setting the settings or the method of the minimization take
+// precedence over the settings and m arguments.
+func Curve1D(f Func1D, settings *optimize.Settings, m optimize.Method, opts ...Option) (*Result, error) {
 	f.init()
+	cfg := newConfig(append([]Option{WithSettings(settings), WithMethod(m)}, opts...))
+	return f.fit(cfg)
+}
 
-	p := optimize.Problem{
-		Func: f.fct,
-		Grad: f.grad,
-		Hess: f.hess,
-	}
-
-	if m == nil {
-		m = &optimize.NelderMead{}
+// fit minimizes the cost function of f.
+func (f *Func1D) fit(cfg *config) (*Result, error) {
+	prob, err := newProblem(f.fct, f.grad, f.Ps, cfg)
+	if err != nil {
+		return nil, err
 	}
-
-	p0 := make([]float64, len(f.Ps))
-	copy(p0, f.Ps)
-	return optimize.Minimize(p, p0, settings, m)
+	prob.ndata = len(f.X)
+	return prob.solve(cfg)
 }
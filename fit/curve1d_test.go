@@ -17,6 +17,9 @@ import (
 	"go-hep.org/x/hep/fit"
 	"go-hep.org/x/hep/hplot"
 	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/num/dual"
+	"gonum.org/v1/gonum/optimize"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
 )
@@ -172,3 +175,31 @@ func readXYerr(fname string) (xs, ys, yerrs []float64, err error) {
 
 	return
 }
+
+func TestCurve1DDual(t *testing.T) {
+	line := fit.Func1D{
+		F: func(x float64, ps []float64) float64 {
+			return ps[0] + ps[1]*x
+		},
+		Dual: func(x float64, ps []dual.Number) dual.Number {
+			return dual.Add(ps[0], dual.Scale(x, ps[1]))
+		},
+		X:   []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		Y:   []float64{1.1, 2.9, 5.2, 7.1, 8.8, 11.2, 12.9, 15.1, 17.2, 18.8},
+		Err: []float64{0.1, 0.2, 0.1, 0.3, 0.2, 0.1, 0.2, 0.3, 0.1, 0.2},
+		N:   2,
+	}
+	res, err := fit.Curve1D(line, &optimize.Settings{GradientThreshold: 1e-6}, &optimize.BFGS{})
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	ref, err := fit.Curve1D(line, nil, &optimize.NelderMead{})
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	for i := range res.X {
+		if got, want := res.X[i], ref.X[i]; !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-6) {
+			t.Fatalf("invalid p[%d]: got=%v, want=%v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+)
+
+var (
+	_ optimize.Method   = (*DiffEvolution)(nil)
+	_ optimize.Statuser = (*DiffEvolution)(nil)
+)
+
+// DiffEvolution is a global optimization method implementing the
+// differential evolution algorithm of Storn and Price (DE/rand/1/bin).
+//
+// DiffEvolution only uses the values of the cost function, and explores
+// the parameters space with a population of candidates, which makes it
+// suited to multi-modal problems where local methods get trapped in local
+// minima.
+// It is much slower than local methods: its result can be refined with a
+// local method.
+//
+// DiffEvolution evaluates the cost function sequentially.
+type DiffEvolution struct {
+	// Population is the number of candidates.
+	// If Population is 0, ten times the number of parameters (and at least
+	// 10) candidates are used.
+	Population int
+
+	// Mutation is the differential weight of the mutation, in (0, 2].
+	// If Mutation is 0, 0.8 is used.
+	Mutation float64
+
+	// Crossover is the crossover probability, in [0, 1].
+	// If Crossover is 0, 0.9 is used.
+	Crossover float64
+
+	// InitStepSize is the half-width of the range, around the initial
+	// location, of the initial population.
+	// If InitStepSize is 0, 1 is used.
+	InitStepSize float64
+
+	// Tolerance is the relative spread of the values of the cost function
+	// over the population below which the method has converged.
+	// If Tolerance is 0, 1e-8 is used.
+	Tolerance float64
+
+	// Src is the source of random numbers.
+	// If Src is nil, a source seeded with 1 is used.
+	Src rand.Source
+
+	rnd *rand.Rand
+	pop int
+
+	xs    [][]float64 // candidates
+	fs    []float64   // values of the cost function of the candidates
+	trial []float64   // trial candidate
+	idx   int         // index of the evaluated candidate
+	init  bool        // whether the initial population is evaluated
+	bestF float64
+	bestX []float64
+}
+
+func (*DiffEvolution) Uses(has optimize.Available) (optimize.Available, error) {
+	return optimize.Available{}, nil
+}
+
+func (de *DiffEvolution) Status() (optimize.Status, error) {
+	if !de.init && de.converged() {
+		return optimize.MethodConverge, nil
+	}
+	return optimize.NotTerminated, nil
+}
+
+func (de *DiffEvolution) Init(dim, tasks int) int {
+	if dim <= 0 {
+		panic("fit: invalid dimension for differential evolution")
+	}
+
+	de.pop = de.Population
+	if de.pop == 0 {
+		de.pop = 10 * dim
+		if de.pop < 10 {
+			de.pop = 10
+		}
+	}
+	if de.pop < 4 {
+		panic("fit: differential evolution population too small")
+	}
+
+	src := de.Src
+	if src == nil {
+		src = rand.NewSource(1)
+	}
+	de.rnd = rand.New(src)
+
+	de.xs = make([][]float64, de.pop)
+	for i := range de.xs {
+		de.xs[i] = make([]float64, dim)
+	}
+	de.fs = make([]float64, de.pop)
+	de.trial = make([]float64, dim)
+	de.bestX = make([]float64, dim)
+	de.bestF = math.Inf(+1)
+	de.idx = 0
+	de.init = true
+
+	if tasks > 1 {
+		tasks = 1
+	}
+	return tasks
+}
+
+func (de *DiffEvolution) Run(operation chan<- optimize.Task, result <-chan optimize.Task, tasks []optimize.Task) {
+	de.populate(tasks[0].X)
+	de.sendEval(operation, tasks[0])
+
+Loop:
+	for {
+		task := <-result
+		switch task.Op {
+		default:
+			panic("fit: unknown operation")
+		case optimize.PostIteration:
+			break Loop
+		case optimize.MajorIteration:
+			if de.converged() {
+				task.Op = optimize.MethodDone
+				operation <- task
+				continue
+			}
+			de.sendEval(operation, task)
+		case optimize.FuncEvaluation:
+			de.update(task.X, task.F)
+			de.idx++
+			if de.idx < de.pop {
+				de.sendEval(operation, task)
+				continue
+			}
+			// end of a generation.
+			de.idx = 0
+			de.init = false
+			task.Op = optimize.MajorIteration
+			task.F = de.bestF
+			copy(task.X, de.bestX)
+			operation <- task
+		}
+	}
+
+	// drain the last results.
+	for task := range result {
+		switch task.Op {
+		default:
+			panic("fit: unknown operation")
+		case optimize.MajorIteration:
+		case optimize.FuncEvaluation:
+			if task.F < de.bestF {
+				de.update(task.X, task.F)
+				task.Op = optimize.MajorIteration
+				operation <- task
+			}
+		}
+	}
+	close(operation)
+}
+
+// populate creates the initial population around x0.
+func (de *DiffEvolution) populate(x0 []float64) {
+	step := de.InitStepSize
+	if step == 0 {
+		step = 1
+	}
+	copy(de.xs[0], x0)
+	for _, x := range de.xs[1:] {
+		for j := range x {
+			x[j] = x0[j] + step*(2*de.rnd.Float64()-1)
+		}
+	}
+}
+
+// sendEval sends the evaluation of the current candidate.
+func (de *DiffEvolution) sendEval(operation chan<- optimize.Task, task optimize.Task) {
+	switch {
+	case de.init:
+		copy(task.X, de.xs[de.idx])
+	default:
+		de.mutate(de.idx)
+		copy(task.X, de.trial)
+	}
+	task.Op = optimize.FuncEvaluation
+	operation <- task
+}
+
+// mutate creates the trial candidate for the i-th candidate.
+func (de *DiffEvolution) mutate(i int) {
+	w := de.Mutation
+	if w == 0 {
+		w = 0.8
+	}
+	cr := de.Crossover
+	if cr == 0 {
+		cr = 0.9
+	}
+
+	pick := func(excl ...int) int {
+	loop:
+		for {
+			k := de.rnd.Intn(de.pop)
+			for _, j := range excl {
+				if k == j {
+					continue loop
+				}
+			}
+			return k
+		}
+	}
+	var (
+		a = pick(i)
+		b = pick(i, a)
+		c = pick(i, a, b)
+		x = de.xs[i]
+		r = de.rnd.Intn(len(x))
+	)
+	// avoid null difference vectors from duplicated candidates, which would
+	// copy candidates and make the population collapse.
+	for n := 0; n < de.pop && floats.Equal(de.xs[b], de.xs[c]); n++ {
+		c = pick(i, a, b)
+	}
+	for j := range de.trial {
+		switch {
+		case j == r || de.rnd.Float64() < cr:
+			de.trial[j] = de.xs[a][j] + w*(de.xs[b][j]-de.xs[c][j])
+		default:
+			de.trial[j] = x[j]
+		}
+	}
+}
+
+// update updates the current candidate with the evaluated location x.
+func (de *DiffEvolution) update(x []float64, f float64) {
+	if math.IsNaN(f) {
+		f = math.Inf(+1)
+	}
+	if de.init || f <= de.fs[de.idx] {
+		copy(de.xs[de.idx], x)
+		de.fs[de.idx] = f
+	}
+	if f < de.bestF {
+		de.bestF = f
+		copy(de.bestX, x)
+	}
+}
+
+// converged returns whether the values of the cost function over the
+// population are within the tolerance.
+func (de *DiffEvolution) converged() bool {
+	tol := de.Tolerance
+	if tol == 0 {
+		tol = 1e-8
+	}
+	var (
+		lo = math.Inf(+1)
+		hi = math.Inf(-1)
+	)
+	for _, f := range de.fs {
+		lo = math.Min(lo, f)
+		hi = math.Max(hi, f)
+	}
+	if math.IsInf(hi, 0) {
+		return false
+	}
+	return hi-lo <= tol*(1+math.Abs(lo))
+}
@@ -0,0 +1,58 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestDiffEvolution(t *testing.T) {
+	// fitting the frequency of a sine has many local minima.
+	const freq = 3
+	var (
+		xs = make([]float64, 50)
+		ys = make([]float64, len(xs))
+	)
+	for i := range xs {
+		xs[i] = 0.2 * float64(i)
+		ys[i] = math.Sin(freq * xs[i])
+	}
+	f := fit.Func1D{
+		F: func(x float64, ps []float64) float64 {
+			return math.Sin(ps[0] * x)
+		},
+		X:  xs,
+		Y:  ys,
+		Ps: []float64{1},
+	}
+
+	local, err := fit.Curve1D(f, nil, &optimize.NelderMead{}, fit.WithBounds(0, 0.5, 5))
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	if scalar.EqualWithinAbs(local.X[0], freq, 1e-2) {
+		t.Fatalf("local method found the global minimum")
+	}
+
+	res, err := fit.Curve1D(
+		f, nil, &fit.DiffEvolution{Src: rand.NewSource(1234)},
+		fit.WithBounds(0, 0.5, 5),
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	if got, want := res.X[0], float64(freq); !scalar.EqualWithinAbs(got, want, 1e-4) {
+		t.Fatalf("invalid frequency: got=%v, want=%v", got, want)
+	}
+	if !(res.F < local.F) {
+		t.Fatalf("invalid minimum: got=%v, local=%v", res.F, local.F)
+	}
+}
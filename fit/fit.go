@@ -6,8 +6,7 @@
 package fit // import "go-hep.org/x/hep/fit"
 
 import (
-	"gonum.org/v1/gonum/diff/fd"
-	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/num/dual"
 )
 
 //go:generate go get github.com/campoy/embedmd
@@ -19,6 +18,11 @@ type Func1D struct {
 	// ps is the slice of parameters to optimize during the fit.
 	F func(x float64, ps []float64) float64
 
+	// Dual is an optional implementation of F with dual numbers.
+	// When Dual is provided, the gradient of the cost function is computed
+	// by automatic differentiation of Dual, instead of finite differences.
+	Dual func(x float64, ps []dual.Number) dual.Number
+
 	// N is the number of parameters to optimize during the fit.
 	// If N is 0, Ps must not be nil.
 	N int
@@ -35,8 +39,7 @@ type Func1D struct {
 	sig2 []float64 // inverse of squares of measurement errors along Y.
 
 	fct  func(ps []float64) float64 // cost function (objective function)
-	grad func(grad, ps []float64)
-	hess func(hess *mat.SymDense, x []float64)
+	grad func(grad, ps []float64)   // gradient of the cost function (may be nil)
 }
 
 func (f *Func1D) init() {
@@ -78,12 +81,34 @@ func (f *Func1D) init() {
 		return 0.5 * chi2
 	}
 
-	f.grad = func(grad, ps []float64) {
-		fd.Gradient(grad, f.fct, ps, nil)
-	}
+	f.grad = f.gradient(func(i int, v float64) float64 {
+		return (v - f.Y[i]) * f.sig2[i]
+	})
+}
 
-	f.hess = func(hess *mat.SymDense, x []float64) {
-		fd.Hessian(hess, f.fct, x, nil)
+// gradient returns the gradient of a cost function computed by automatic
+// differentiation of f.Dual, or nil if f.Dual is nil.
+// dcost(i, v) is the derivative of the cost function with respect to the
+// value v of the function at the i-th data point.
+func (f *Func1D) gradient(dcost func(i int, v float64) float64) func(grad, ps []float64) {
+	if f.Dual == nil {
+		return nil
+	}
+	return func(grad, ps []float64) {
+		dps := make([]dual.Number, len(ps))
+		for k, v := range ps {
+			dps[k].Real = v
+		}
+		for k := range grad {
+			dps[k].Emag = 1
+			var g float64
+			for i, x := range f.X {
+				v := f.Dual(x, dps)
+				g += dcost(i, v.Real) * v.Emag
+			}
+			grad[k] = g
+			dps[k].Emag = 0
+		}
 	}
 }
 
@@ -115,9 +140,7 @@ type FuncND struct {
 
 	sig2 []float64 // inverse of squares of measurement errors along Y.
 
-	fct  func(ps []float64) float64 // cost function (objective function)
-	grad func(grad, ps []float64)
-	hess func(hess *mat.SymDense, x []float64) // hessian matrix
+	fct func(ps []float64) float64 // cost function (objective function)
 }
 
 func (f *FuncND) init() {
@@ -158,12 +181,4 @@ func (f *FuncND) init() {
 		}
 		return 0.5 * chi2
 	}
-
-	f.grad = func(grad []float64, ps []float64) {
-		fd.Gradient(grad, f.fct, ps, nil)
-	}
-
-	f.hess = func(hess *mat.SymDense, x []float64) {
-		fd.Hessian(hess, f.fct, x, nil)
-	}
 }
@@ -0,0 +1,309 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// GoF is the result of a goodness-of-fit test.
+type GoF struct {
+	Stat float64 // test statistic
+	NDF  int     // number of degrees of freedom (chi-square test only)
+	Prob float64 // p-value of the test
+}
+
+// Chi2 returns the chi-square goodness-of-fit of a fit result, for fits of
+// histograms or curves (including template fits), together with its number
+// of degrees of freedom and its p-value.
+//
+// The chi-square is twice the cost function at the minimum: for Poisson
+// likelihood fits, it is the likelihood ratio of Baker and Cousins.
+// The number of degrees of freedom is the number of fitted data points,
+// plus the number of Gaussian constraints, minus the number of free
+// parameters.
+func Chi2(res *Result) (GoF, error) {
+	if res == nil || res.prob == nil {
+		return GoF{}, fmt.Errorf("fit: fit result has no cost function")
+	}
+	if res.prob.ndata == 0 {
+		return GoF{}, fmt.Errorf("fit: fit result has no chi-square")
+	}
+
+	var (
+		chi2 = 2 * res.F
+		ndf  = res.prob.ndata + len(res.prob.cs) - len(res.prob.free())
+	)
+	if ndf <= 0 {
+		return GoF{}, fmt.Errorf("fit: invalid number of degrees of freedom %d", ndf)
+	}
+
+	prob := distuv.ChiSquared{K: float64(ndf)}.Survival(chi2)
+	return GoF{Stat: chi2, NDF: ndf, Prob: prob}, nil
+}
+
+// Reduced returns the chi-square divided by its number of degrees of freedom.
+func (gof GoF) Reduced() float64 {
+	return gof.Stat / float64(gof.NDF)
+}
+
+// KSTest returns the Kolmogorov-Smirnov test of the sample xs against the
+// cumulative distribution function cdf, e.g. of a fitted model.
+func KSTest(xs []float64, cdf func(x float64) float64) GoF {
+	xs = sorted(xs)
+	var (
+		n = float64(len(xs))
+		d float64
+	)
+	for i, x := range xs {
+		v := cdf(x)
+		d = math.Max(d, math.Max(v-float64(i)/n, float64(i+1)/n-v))
+	}
+
+	// asymptotic distribution, with the correction of Stephens for small
+	// samples.
+	sqn := math.Sqrt(n)
+	return GoF{Stat: d, Prob: kolmogorovProb((sqn + 0.12 + 0.11/sqn) * d)}
+}
+
+// KSTestH1D returns the Kolmogorov-Smirnov test of the histogram h against
+// the cumulative distribution function cdf, e.g. of a fitted model.
+//
+// The distribution is normalized over the range of the histogram, whose
+// underflow and overflow bins are ignored.
+// Binning the data reduces the power of the test: the p-value is only
+// approximate.
+func KSTestH1D(h *hbook.H1D, cdf func(x float64) float64) GoF {
+	var (
+		hcdf, n = cumulative(h)
+		fcdf    = modelCumulative(h, cdf)
+		d       float64
+	)
+	for i, v := range hcdf {
+		d = math.Max(d, math.Abs(v-fcdf[i]))
+	}
+	return GoF{Stat: d, Prob: kolmogorovProb(math.Sqrt(n) * d)}
+}
+
+// KSTestH1Ds returns the Kolmogorov-Smirnov test of the compatibility of the
+// histograms h1 and h2, which must have the same binning.
+//
+// Underflow and overflow bins are ignored.
+// Binning the data reduces the power of the test: the p-value is only
+// approximate.
+func KSTestH1Ds(h1, h2 *hbook.H1D) (GoF, error) {
+	err := sameBinning(h1, h2)
+	if err != nil {
+		return GoF{}, err
+	}
+
+	var (
+		cdf1, n1 = cumulative(h1)
+		cdf2, n2 = cumulative(h2)
+		d        float64
+	)
+	for i, v := range cdf1 {
+		d = math.Max(d, math.Abs(v-cdf2[i]))
+	}
+	n := n1 * n2 / (n1 + n2)
+	return GoF{Stat: d, Prob: kolmogorovProb(math.Sqrt(n) * d)}, nil
+}
+
+// ADTest returns the Anderson-Darling test of the sample xs against the
+// cumulative distribution function cdf, e.g. of a fitted model.
+//
+// Compared to the Kolmogorov-Smirnov test, the Anderson-Darling test is
+// more sensitive to the tails of the distribution.
+func ADTest(xs []float64, cdf func(x float64) float64) GoF {
+	xs = sorted(xs)
+	var (
+		n  = len(xs)
+		a2 = -float64(n)
+	)
+	for i, x := range xs {
+		lo := cdf(x)
+		hi := cdf(xs[n-1-i])
+		a2 -= float64(2*i+1) / float64(n) * (math.Log(lo) + math.Log1p(-hi))
+	}
+	return GoF{Stat: a2, Prob: 1 - adProb(float64(n), a2)}
+}
+
+// ADTestH1D returns the Anderson-Darling test of the histogram h against
+// the cumulative distribution function cdf, e.g. of a fitted model.
+//
+// The distribution is normalized over the range of the histogram, whose
+// underflow and overflow bins are ignored.
+// Binning the data reduces the power of the test: the p-value is only
+// approximate.
+func ADTestH1D(h *hbook.H1D, cdf func(x float64) float64) GoF {
+	var (
+		hcdf, n = cumulative(h)
+		fcdf    = modelCumulative(h, cdf)
+		a2      float64
+		prev    float64
+	)
+	for i, v := range fcdf {
+		df := v - prev
+		prev = v
+		if !(v > 0 && v < 1) {
+			continue
+		}
+		d := hcdf[i] - v
+		a2 += d * d / (v * (1 - v)) * df
+	}
+	a2 *= n
+	return GoF{Stat: a2, Prob: 1 - adProb(n, a2)}
+}
+
+// ADTestH1Ds returns the Anderson-Darling test of the compatibility of the
+// histograms h1 and h2, which must have the same binning.
+//
+// Underflow and overflow bins are ignored.
+// The p-value is computed from the asymptotic distribution of the
+// statistic, and is only approximate for binned data.
+func ADTestH1Ds(h1, h2 *hbook.H1D) (GoF, error) {
+	err := sameBinning(h1, h2)
+	if err != nil {
+		return GoF{}, err
+	}
+
+	var (
+		cdf1, n1 = cumulative(h1)
+		cdf2, n2 = cumulative(h2)
+		a2       float64
+		prev     float64
+	)
+	for i, v1 := range cdf1 {
+		v2 := cdf2[i]
+		// cumulative distribution of the pooled sample.
+		v := (n1*v1 + n2*v2) / (n1 + n2)
+		dv := v - prev
+		prev = v
+		if !(v > 0 && v < 1) {
+			continue
+		}
+		d := v1 - v2
+		a2 += d * d / (v * (1 - v)) * dv
+	}
+	a2 *= n1 * n2 / (n1 + n2)
+	return GoF{Stat: a2, Prob: 1 - adInf(a2)}, nil
+}
+
+// sorted returns a sorted copy of xs.
+func sorted(xs []float64) []float64 {
+	o := make([]float64, len(xs))
+	copy(o, xs)
+	sort.Float64s(o)
+	return o
+}
+
+// cumulative returns the normalized cumulative distribution of h at the upper
+// edges of its bins, and the effective number of entries of h.
+func cumulative(h *hbook.H1D) ([]float64, float64) {
+	var (
+		bins       = h.Binning.Bins
+		cdf        = make([]float64, len(bins))
+		sumw, sum2 float64
+	)
+	for i := range bins {
+		sumw += bins[i].SumW()
+		sum2 += bins[i].SumW2()
+		cdf[i] = sumw
+	}
+	for i := range cdf {
+		cdf[i] /= sumw
+	}
+	return cdf, sumw * sumw / sum2
+}
+
+// modelCumulative returns the cumulative distribution function cdf,
+// normalized over the range of h, at the upper edges of the bins of h.
+func modelCumulative(h *hbook.H1D, cdf func(x float64) float64) []float64 {
+	var (
+		bins = h.Binning.Bins
+		o    = make([]float64, len(bins))
+		lo   = cdf(h.XMin())
+		norm = cdf(h.XMax()) - lo
+	)
+	for i := range bins {
+		o[i] = (cdf(bins[i].XMax()) - lo) / norm
+	}
+	return o
+}
+
+func sameBinning(h1, h2 *hbook.H1D) error {
+	if h1.Len() != h2.Len() || h1.XMin() != h2.XMin() || h1.XMax() != h2.XMax() {
+		return fmt.Errorf(
+			"fit: histograms with different binnings (%d bins in [%v, %v], %d bins in [%v, %v])",
+			h1.Len(), h1.XMin(), h1.XMax(), h2.Len(), h2.XMin(), h2.XMax(),
+		)
+	}
+	return nil
+}
+
+// kolmogorovProb returns the probability that the Kolmogorov-Smirnov
+// statistic, scaled by the square root of the number of entries, exceeds z.
+func kolmogorovProb(z float64) float64 {
+	if z < 0.2 {
+		return 1
+	}
+	var (
+		p    float64
+		sign = 1.0
+	)
+	for k := 1; k <= 100; k++ {
+		v := sign * math.Exp(-2*float64(k*k)*z*z)
+		p += v
+		if math.Abs(v) <= 1e-10*p {
+			break
+		}
+		sign = -sign
+	}
+	return math.Max(0, math.Min(1, 2*p))
+}
+
+// adInf returns the asymptotic cumulative distribution function of the
+// Anderson-Darling statistic, from:
+//
+//	G. Marsaglia and J. Marsaglia, "Evaluating the Anderson-Darling
+//	Distribution", Journal of Statistical Software 9 (2), 2004.
+func adInf(z float64) float64 {
+	if !(z > 0) {
+		return 0
+	}
+	if z < 2 {
+		return math.Exp(-1.2337141/z) / math.Sqrt(z) *
+			(2.00012 + (0.247105-(0.0649821-(0.0347962-(0.011672-0.00168691*z)*z)*z)*z)*z)
+	}
+	return math.Exp(-math.Exp(1.0776 - (2.30695-(0.43424-(0.082433-(0.008056-0.0003146*z)*z)*z)*z)*z))
+}
+
+// adProb returns the cumulative distribution function of the
+// Anderson-Darling statistic for a sample of size n, correcting the
+// asymptotic distribution as proposed by Marsaglia and Marsaglia.
+func adProb(n, z float64) float64 {
+	x := adInf(z)
+	return math.Max(0, math.Min(1, x+adErrFix(n, x)))
+}
+
+func adErrFix(n, x float64) float64 {
+	if x > 0.8 {
+		return (-130.2137 + (745.2337-(1705.091-(1950.646-(1116.360-255.7844*x)*x)*x)*x)*x) / n
+	}
+	c := 0.01265 + 0.1757/n
+	if x < c {
+		t := x / c
+		t = math.Sqrt(t) * (1 - t) * (49*t - 102)
+		return t * (0.0037/(n*n) + 0.00078/n + 0.00006) / n
+	}
+	t := (x - c) / (0.8 - c)
+	t = -0.00022633 + (6.54034-(14.6538-(14.458-(8.259-1.91864*t)*t)*t)*t)*t
+	return t * (0.04213 + 0.01365/n) / n
+}
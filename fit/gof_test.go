@@ -0,0 +1,175 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"go-hep.org/x/hep/hbook"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestChi2(t *testing.T) {
+	var (
+		xs   = []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		ys   = []float64{1.1, 2.9, 5.2, 7.1, 8.8, 11.2, 12.9, 15.1, 17.2, 18.8}
+		errs = []float64{0.1, 0.2, 0.1, 0.3, 0.2, 0.1, 0.2, 0.3, 0.1, 0.2}
+		line = func(x float64, ps []float64) float64 {
+			return ps[0] + ps[1]*x
+		}
+	)
+
+	res, err := fit.Curve1D(
+		fit.Func1D{F: line, X: xs, Y: ys, Err: errs, N: 2},
+		nil, &optimize.NelderMead{},
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+
+	gof, err := fit.Chi2(res)
+	if err != nil {
+		t.Fatalf("could not compute chi2: %+v", err)
+	}
+
+	var chi2 float64
+	for i, x := range xs {
+		d := (line(x, res.X) - ys[i]) / errs[i]
+		chi2 += d * d
+	}
+	if got, want := gof.Stat, chi2; !scalar.EqualWithinRel(got, want, 1e-6) {
+		t.Fatalf("invalid chi2: got=%v, want=%v", got, want)
+	}
+	if got, want := gof.NDF, len(xs)-2; got != want {
+		t.Fatalf("invalid ndf: got=%d, want=%d", got, want)
+	}
+	if got, want := gof.Reduced(), chi2/float64(len(xs)-2); !scalar.EqualWithinRel(got, want, 1e-6) {
+		t.Fatalf("invalid reduced chi2: got=%v, want=%v", got, want)
+	}
+	if got, want := gof.Prob, 1-(distuv.ChiSquared{K: 8}).CDF(chi2); !scalar.EqualWithinAbsOrRel(got, want, 1e-9, 1e-6) {
+		t.Fatalf("invalid p-value: got=%v, want=%v", got, want)
+	}
+
+	// fixing a parameter adds a degree of freedom.
+	res, err = fit.Curve1D(
+		fit.Func1D{F: line, X: xs, Y: ys, Err: errs, Ps: []float64{1, 2}},
+		nil, &optimize.NelderMead{},
+		fit.WithFixed(0),
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	gof, err = fit.Chi2(res)
+	if err != nil {
+		t.Fatalf("could not compute chi2: %+v", err)
+	}
+	if got, want := gof.NDF, len(xs)-1; got != want {
+		t.Fatalf("invalid ndf: got=%d, want=%d", got, want)
+	}
+
+	unb, err := fit.Unbinned(fit.PDF1D{
+		F: func(x float64, ps []float64) float64 {
+			return math.Exp(-ps[0] * x)
+		},
+		Ps:  []float64{1},
+		Min: 0,
+		Max: 5,
+		X:   expoSamples(100, 1234),
+	})
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	_, err = fit.Chi2(unb)
+	if err == nil {
+		t.Fatalf("expected an error for an unbinned fit")
+	}
+}
+
+func TestKSADTests(t *testing.T) {
+	var (
+		xs   = expoSamples(1000, 1234)
+		good = distuv.Exponential{Rate: 0.5}.CDF
+		bad  = distuv.Exponential{Rate: 0.6}.CDF
+	)
+
+	hist := func(xs []float64) *hbook.H1D {
+		h := hbook.NewH1D(50, 0, 10)
+		for _, x := range xs {
+			h.Fill(x, 1)
+		}
+		return h
+	}
+	var (
+		h1 = hist(xs)
+		h2 = hist(expoSamples(1000, 42))
+		h3 = hbook.NewH1D(50, 0, 10)
+	)
+	dist := distuv.Exponential{Rate: 0.7, Src: rand.New(rand.NewSource(42))}
+	for i := 0; i < 1000; i++ {
+		h3.Fill(dist.Rand(), 1)
+	}
+
+	check := func(t *testing.T, good, bad fit.GoF) {
+		t.Helper()
+		if good.Prob < 0.05 {
+			t.Fatalf("compatible distributions rejected: %+v", good)
+		}
+		if bad.Prob > 1e-3 {
+			t.Fatalf("incompatible distributions accepted: %+v", bad)
+		}
+		if !(good.Stat < bad.Stat) {
+			t.Fatalf("invalid statistics: good=%v, bad=%v", good.Stat, bad.Stat)
+		}
+	}
+
+	t.Run("ks", func(t *testing.T) {
+		check(t, fit.KSTest(xs, good), fit.KSTest(xs, bad))
+	})
+	t.Run("ks-h1d", func(t *testing.T) {
+		check(t, fit.KSTestH1D(h1, good), fit.KSTestH1D(h1, bad))
+	})
+	t.Run("ks-h1ds", func(t *testing.T) {
+		good, err := fit.KSTestH1Ds(h1, h2)
+		if err != nil {
+			t.Fatalf("could not run test: %+v", err)
+		}
+		bad, err := fit.KSTestH1Ds(h1, h3)
+		if err != nil {
+			t.Fatalf("could not run test: %+v", err)
+		}
+		check(t, good, bad)
+	})
+	t.Run("ad", func(t *testing.T) {
+		check(t, fit.ADTest(xs, good), fit.ADTest(xs, bad))
+	})
+	t.Run("ad-h1d", func(t *testing.T) {
+		check(t, fit.ADTestH1D(h1, good), fit.ADTestH1D(h1, bad))
+	})
+	t.Run("ad-h1ds", func(t *testing.T) {
+		good, err := fit.ADTestH1Ds(h1, h2)
+		if err != nil {
+			t.Fatalf("could not run test: %+v", err)
+		}
+		bad, err := fit.ADTestH1Ds(h1, h3)
+		if err != nil {
+			t.Fatalf("could not run test: %+v", err)
+		}
+		check(t, good, bad)
+	})
+
+	_, err := fit.KSTestH1Ds(h1, hbook.NewH1D(10, 0, 10))
+	if err == nil {
+		t.Fatalf("expected an error for histograms with different binnings")
+	}
+	_, err = fit.ADTestH1Ds(h1, hbook.NewH1D(50, 0, 5))
+	if err == nil {
+		t.Fatalf("expected an error for histograms with different binnings")
+	}
+}
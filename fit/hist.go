@@ -5,36 +5,173 @@
 package fit
 
 import (
+	"fmt"
+	"math"
+
 	"go-hep.org/x/hep/hbook"
-	"gonum.org/v1/gonum/optimize"
 )
 
-// H1D returns the fit of histogram h with function f and optimization method m.
+// Cost is the cost function minimized by a binned fit.
+type Cost int
+
+const (
+	// NeymanChi2 is the chi-square using the errors of the bin contents.
+	// Empty bins are ignored.
+	NeymanChi2 Cost = iota
+
+	// PearsonChi2 is the chi-square using the expected bin contents as
+	// variances.
+	PearsonChi2
+
+	// PoissonNLL is the Poisson negative log-likelihood of the bin contents,
+	// offset by the saturated model as proposed by Baker and Cousins.
+	// Twice its value at the minimum follows a chi-square distribution.
+	PoissonNLL
+)
+
+func (c Cost) String() string {
+	switch c {
+	case NeymanChi2:
+		return "Neyman chi2"
+	case PearsonChi2:
+		return "Pearson chi2"
+	case PoissonNLL:
+		return "Poisson NLL"
+	}
+	return fmt.Sprintf("Cost(%d)", int(c))
+}
+
+// H1D returns the fit of histogram h with function f.
+//
+// The cost function minimized by the fit is set with WithCost.
+// For chi-square cost functions, the minimized quantity is half the
+// chi-square so that, for all cost functions, the inverse of the Hessian
+// matrix at the minimum is the covariance matrix of the fitted parameters.
+//
+// Bin contents are compared with the value of f at the center of the bins,
+// or with the value of f times the width of the bins when WithDensity is
+// enabled.
 //
-// Only bins with at least an entry are considered for the fit.
-// In case settings is nil, the optimize.DefaultSettingsLocal is used.
-// In case m is nil, the same default optimization method than for Curve1D is used.
-func H1D(h *hbook.H1D, f Func1D, settings *optimize.Settings, m optimize.Method) (*optimize.Result, error) {
+// By default, the Neyman chi-square is minimized (considering only bins
+// with at least an entry) with the optimize.NelderMead method and the
+// optimize.DefaultSettingsLocal settings.
+// Parameters can be fixed, bounded or constrained with WithFixed, WithBounds
+// and WithConstraint.
+func H1D(h *hbook.H1D, f Func1D, opts ...Option) (*Result, error) {
+	cfg := newConfig(opts)
+
 	var (
 		n     = h.Len()
 		xdata = make([]float64, 0, n)
 		ydata = make([]float64, 0, n)
 		yerrs = make([]float64, 0, n)
+		width = make([]float64, 0, n)
 		bins  = h.Binning.Bins
 	)
 
-	for _, bin := range bins {
-		if bin.Entries() <= 0 {
+	for i := range bins {
+		bin := &bins[i]
+		if cfg.cost == NeymanChi2 && bin.Entries() <= 0 {
 			continue
 		}
 		xdata = append(xdata, bin.XMid())
 		ydata = append(ydata, bin.SumW())
 		yerrs = append(yerrs, bin.ErrW())
+		width = append(width, bin.XWidth())
 	}
 
 	f.X = xdata
 	f.Y = ydata
-	f.Err = yerrs
+	if cfg.cost == NeymanChi2 {
+		f.Err = yerrs
+	}
+	f.init()
+
+	expected := func(ps []float64, i int) float64 {
+		nu := f.F(f.X[i], ps)
+		if cfg.density {
+			nu *= width[i]
+		}
+		return nu
+	}
+
+	// dcost returns the derivative of the cost function with respect to the
+	// value v of f for the i-th bin, from its derivative dnu with respect to
+	// the expected content nu of the bin.
+	dcost := func(dnu func(i int, nu float64) float64) func(i int, v float64) float64 {
+		return func(i int, v float64) float64 {
+			if !cfg.density {
+				return dnu(i, v)
+			}
+			return dnu(i, v*width[i]) * width[i]
+		}
+	}
+
+	switch cfg.cost {
+	case NeymanChi2:
+		f.fct = func(ps []float64) float64 {
+			var chi2 float64
+			for i, n := range f.Y {
+				res := expected(ps, i) - n
+				chi2 += res * res * f.sig2[i]
+			}
+			return 0.5 * chi2
+		}
+		f.grad = f.gradient(dcost(func(i int, nu float64) float64 {
+			return (nu - f.Y[i]) * f.sig2[i]
+		}))
+
+	case PearsonChi2:
+		f.fct = func(ps []float64) float64 {
+			var chi2 float64
+			for i, n := range f.Y {
+				nu := expected(ps, i)
+				switch {
+				case nu > 0:
+					res := nu - n
+					chi2 += res * res / nu
+				case n != 0 || nu < 0:
+					return math.Inf(+1)
+				}
+			}
+			return 0.5 * chi2
+		}
+		f.grad = f.gradient(dcost(func(i int, nu float64) float64 {
+			n := f.Y[i]
+			if n == 0 {
+				return 0.5
+			}
+			return 0.5 * (1 - n*n/(nu*nu))
+		}))
+
+	case PoissonNLL:
+		f.fct = func(ps []float64) float64 {
+			var nll float64
+			for i, n := range f.Y {
+				nu := expected(ps, i)
+				switch {
+				case nu > 0:
+					nll += nu - n
+					if n > 0 {
+						nll += n * math.Log(n/nu)
+					}
+				case n > 0 || nu < 0:
+					return math.Inf(+1)
+				}
+			}
+			return nll
+		}
+		f.grad = f.gradient(dcost(func(i int, nu float64) float64 {
+			n := f.Y[i]
+			if n == 0 {
+				return 1
+			}
+			return 1 - n/nu
+		}))
+
+	default:
+		return nil, fmt.Errorf("fit: invalid cost function %v", cfg.cost)
+	}
 
-	return Curve1D(f, settings, m)
+	return f.fit(cfg)
 }
@@ -60,7 +60,7 @@ func ExampleH1D_gaussian() {
 			},
 			N: len(want),
 		},
-		nil, &optimize.NelderMead{},
+		fit.WithMethod(&optimize.NelderMead{}),
 	)
 	if err != nil {
 		log.Fatal(err)
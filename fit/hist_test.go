@@ -5,9 +5,183 @@
 package fit_test
 
 import (
+	"math"
 	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"go-hep.org/x/hep/hbook"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/num/dual"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
 )
 
 func TestH1D(t *testing.T) {
 	checkPlot(ExampleH1D_gaussian, t, "h1d-gauss-plot.png")
 }
+
+func TestH1DCost(t *testing.T) {
+	var (
+		dist = distuv.Exponential{Rate: 1, Src: rand.New(rand.NewSource(1234))}
+		hist = hbook.NewH1D(40, 0, 8)
+	)
+	for i := 0; i < 1000; i++ {
+		hist.Fill(dist.Rand(), 1)
+	}
+
+	empty := 0
+	for _, bin := range hist.Binning.Bins {
+		if bin.Entries() == 0 {
+			empty++
+		}
+	}
+	if empty == 0 {
+		t.Fatalf("test requires empty bins")
+	}
+
+	expo := func(x float64, ps []float64) float64 {
+		return ps[0] * math.Exp(-ps[1]*x)
+	}
+
+	sum := func(ps []float64) float64 {
+		var nu float64
+		for _, bin := range hist.Binning.Bins {
+			nu += expo(bin.XMid(), ps)
+		}
+		return nu
+	}
+
+	ntot := hist.SumW()
+	for _, tc := range []struct {
+		cost fit.Cost
+		norm func(nu float64) bool
+	}{
+		{
+			// Neyman chi-square underestimates the normalization.
+			cost: fit.NeymanChi2,
+			norm: func(nu float64) bool { return nu < ntot },
+		},
+		{
+			// Pearson chi-square overestimates the normalization.
+			cost: fit.PearsonChi2,
+			norm: func(nu float64) bool { return nu > ntot },
+		},
+		{
+			// Poisson likelihood preserves the normalization.
+			cost: fit.PoissonNLL,
+			norm: func(nu float64) bool { return scalar.EqualWithinRel(nu, ntot, 1e-4) },
+		},
+	} {
+		t.Run(tc.cost.String(), func(t *testing.T) {
+			res, err := fit.H1D(
+				hist,
+				fit.Func1D{F: expo, Ps: []float64{100, 2}},
+				fit.WithCost(tc.cost),
+				fit.WithSettings(&optimize.Settings{
+					Converger: &optimize.FunctionConverge{Absolute: 1e-12, Iterations: 100},
+				}),
+			)
+			if err != nil {
+				t.Fatalf("could not fit histogram: %+v", err)
+			}
+			if !res.Valid() {
+				t.Fatalf("invalid fit: status=%v, cov=%v", res.Status, res.CovStatus)
+			}
+			if got, want := res.X[1], 1.0; math.Abs(got-want) > 3*res.Errs[1] {
+				t.Fatalf("invalid slope: got=%v +/- %v, want=%v", got, res.Errs[1], want)
+			}
+			if nu := sum(res.X); !tc.norm(nu) {
+				t.Fatalf("invalid normalization: got=%v, data=%v", nu, ntot)
+			}
+		})
+	}
+}
+
+func TestH1DDensity(t *testing.T) {
+	var (
+		dist = distuv.Uniform{Min: 0, Max: 10, Src: rand.New(rand.NewSource(1234))}
+		hist = hbook.NewH1DFromEdges([]float64{0, 1, 3, 4, 7, 10})
+	)
+	for i := 0; i < 1000; i++ {
+		hist.Fill(dist.Rand(), 1)
+	}
+
+	flat := fit.Func1D{
+		F:  func(x float64, ps []float64) float64 { return ps[0] },
+		Ps: []float64{1},
+	}
+
+	res, err := fit.H1D(hist, flat, fit.WithCost(fit.PoissonNLL), fit.WithDensity(true))
+	if err != nil {
+		t.Fatalf("could not fit histogram: %+v", err)
+	}
+	if got, want := res.X[0], 100.0; !scalar.EqualWithinRel(got, want, 1e-4) {
+		t.Fatalf("invalid density: got=%v, want=%v", got, want)
+	}
+	if got, want := res.Errs[0], math.Sqrt(100.0/10); !scalar.EqualWithinRel(got, want, 1e-3) {
+		t.Fatalf("invalid density error: got=%v, want=%v", got, want)
+	}
+}
+
+func TestH1DDual(t *testing.T) {
+	var (
+		dist = distuv.Exponential{Rate: 1, Src: rand.New(rand.NewSource(1234))}
+		hist = hbook.NewH1D(40, 0, 8)
+	)
+	for i := 0; i < 1000; i++ {
+		hist.Fill(dist.Rand(), 1)
+	}
+
+	f := fit.Func1D{
+		F: func(x float64, ps []float64) float64 {
+			return ps[0] * math.Exp(-ps[1]*x)
+		},
+		Dual: func(x float64, ps []dual.Number) dual.Number {
+			return dual.Mul(ps[0], dual.Exp(dual.Scale(-x, ps[1])))
+		},
+		Ps: []float64{100, 2},
+	}
+	noDual := f
+	noDual.Dual = nil
+
+	for _, tc := range []struct {
+		name    string
+		cost    fit.Cost
+		density bool
+	}{
+		{name: "neyman", cost: fit.NeymanChi2},
+		{name: "pearson", cost: fit.PearsonChi2},
+		{name: "poisson", cost: fit.PoissonNLL},
+		{name: "poisson-density", cost: fit.PoissonNLL, density: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := fit.H1D(
+				hist, noDual,
+				fit.WithCost(tc.cost),
+				fit.WithDensity(tc.density),
+			)
+			if err != nil {
+				t.Fatalf("could not fit: %+v", err)
+			}
+			res, err := fit.H1D(
+				hist, f,
+				fit.WithCost(tc.cost),
+				fit.WithDensity(tc.density),
+				fit.WithMethod(&optimize.BFGS{}),
+				fit.WithSettings(&optimize.Settings{GradientThreshold: 1e-6}),
+			)
+			if err != nil {
+				t.Fatalf("could not fit: %+v", err)
+			}
+			if !res.Valid() {
+				t.Fatalf("invalid fit: status=%v, cov=%v", res.Status, res.CovStatus)
+			}
+			for i := range res.X {
+				if got, want := res.X[i], ref.X[i]; !scalar.EqualWithinRel(got, want, 1e-4) {
+					t.Fatalf("invalid p[%d]: got=%v, want=%v", i, got, want)
+				}
+			}
+		})
+	}
+}
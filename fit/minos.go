@@ -0,0 +1,188 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// up is the increase of the cost function defining the ±1σ errors.
+//
+// The cost functions of the fit package are half a chi-square or a negative
+// log-likelihood: Δχ²=1 and ΔlnL=0.5 both correspond to an increase of 0.5.
+const up = 0.5
+
+// AsymError is the asymmetric error of a fitted parameter.
+type AsymError struct {
+	Lower float64 // lower error (negative)
+	Upper float64 // upper error (positive)
+}
+
+// Minos returns the asymmetric errors of the provided parameters of a fit
+// result, or of all the parameters if none is provided.
+//
+// The errors are obtained, as with the MINOS algorithm of MINUIT, from the
+// values of each parameter for which the cost function, minimized with
+// respect to all the other parameters, increases by Δχ²=1 (or ΔlnL=0.5)
+// with respect to its minimum.
+// Contrary to the errors derived from the covariance matrix, these errors
+// are correct for non-parabolic cost functions.
+func Minos(res *Result, params ...int) ([]AsymError, error) {
+	if res == nil || res.prob == nil {
+		return nil, fmt.Errorf("fit: fit result has no cost function")
+	}
+
+	if len(params) == 0 {
+		params = make([]int, len(res.X))
+		for i := range params {
+			params[i] = i
+		}
+	}
+
+	errs := make([]AsymError, len(params))
+	for i, k := range params {
+		if k < 0 || k >= len(res.X) {
+			return nil, fmt.Errorf("fit: invalid parameter index %d", k)
+		}
+		if res.prob.fixed[k] {
+			return nil, fmt.Errorf("fit: parameter %d is fixed", k)
+		}
+		lo, err := crossing(res, k, -1)
+		if err != nil {
+			return nil, fmt.Errorf("fit: could not compute lower error of parameter %d: %w", k, err)
+		}
+		hi, err := crossing(res, k, +1)
+		if err != nil {
+			return nil, fmt.Errorf("fit: could not compute upper error of parameter %d: %w", k, err)
+		}
+		errs[i] = AsymError{Lower: lo, Upper: hi}
+	}
+	return errs, nil
+}
+
+// crossing returns the signed distance from the best-fit value of the k-th
+// parameter to the point, in the direction dir, where the profile of the
+// cost function crosses up.
+func crossing(res *Result, k int, dir float64) (float64, error) {
+	step := 0.0
+	if res.Errs != nil {
+		step = res.Errs[k]
+	}
+	if !(step > 0) || math.IsInf(step, 0) {
+		step = math.Max(0.1*math.Abs(res.X[k]), 0.1)
+	}
+
+	var (
+		prof = newProfiler(res, k)
+		x0   = res.X[k]
+	)
+	t, err := root(func(t float64) (float64, error) {
+		return prof.at(x0 + dir*t)
+	}, step, up)
+	if err != nil {
+		return 0, err
+	}
+	return dir * t, nil
+}
+
+// root returns the smallest positive t, with a precision relative to step,
+// for which the increasing function f crosses the provided level, with
+// f(0) < level.
+func root(f func(t float64) (float64, error), step, level float64) (float64, error) {
+	const (
+		maxSteps = 30
+		tol      = 1e-5
+	)
+
+	var (
+		lo = 0.0
+		hi = step
+	)
+
+	// bracket the crossing.
+	for i := 0; ; i++ {
+		if i == maxSteps {
+			return 0, fmt.Errorf("no crossing within %v", hi)
+		}
+		v, err := f(hi)
+		if err != nil {
+			return 0, err
+		}
+		if v >= level {
+			break
+		}
+		lo = hi
+		hi *= 2
+	}
+
+	// bisect the crossing.
+	for hi-lo > tol*step {
+		mid := 0.5 * (lo + hi)
+		v, err := f(mid)
+		if err != nil {
+			return 0, err
+		}
+		if v >= level {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return 0.5 * (lo + hi), nil
+}
+
+// profiler computes the profile of the cost function of a fit along some of
+// its parameters: the cost function minimized with respect to all the other
+// free parameters, relative to the minimum of the fit.
+type profiler struct {
+	prob *problem
+	fmin float64
+	ks   []int     // profiled parameters
+	free []int     // free parameters of the profile
+	ps   []float64 // parameters of the last evaluated point
+}
+
+func newProfiler(res *Result, ks ...int) *profiler {
+	ps := make([]float64, len(res.X))
+	copy(ps, res.X)
+	return &profiler{
+		prob: res.prob,
+		fmin: res.F,
+		ks:   ks,
+		free: res.prob.free(ks...),
+		ps:   ps,
+	}
+}
+
+// at returns the value of the profile for the values vs of the profiled
+// parameters.
+// The other parameters are minimized starting from their values at the
+// previously evaluated point.
+// The profile is infinite outside of the bounds of the parameters.
+func (p *profiler) at(vs ...float64) (float64, error) {
+	for i, k := range p.ks {
+		if !p.prob.inBounds(k, vs[i]) {
+			return math.Inf(+1), nil
+		}
+	}
+
+	for i, k := range p.ks {
+		p.ps[k] = vs[i]
+	}
+	if len(p.free) == 0 {
+		return p.prob.fct(p.ps) - p.fmin, nil
+	}
+
+	res, err := p.prob.minimize(p.ps, p.free, nil, &optimize.NelderMead{})
+	if err != nil {
+		return 0, fmt.Errorf("could not minimize cost function at p%v=%v: %w", p.ks, vs, err)
+	}
+	copy(p.ps, res.X)
+	return res.F - p.fmin, nil
+}
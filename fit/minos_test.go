@@ -0,0 +1,91 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestMinosParabolic(t *testing.T) {
+	res, err := fit.Curve1D(
+		fit.Func1D{
+			F: func(x float64, ps []float64) float64 {
+				return ps[0] + ps[1]*x
+			},
+			X:   []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			Y:   []float64{1.1, 2.9, 5.2, 7.1, 8.8, 11.2, 12.9, 15.1, 17.2, 18.8},
+			Err: []float64{0.1, 0.2, 0.1, 0.3, 0.2, 0.1, 0.2, 0.3, 0.1, 0.2},
+			N:   2,
+		},
+		nil, &optimize.NelderMead{},
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+
+	errs, err := fit.Minos(res)
+	if err != nil {
+		t.Fatalf("could not compute minos errors: %+v", err)
+	}
+	if got, want := len(errs), 2; got != want {
+		t.Fatalf("invalid number of errors: got=%d, want=%d", got, want)
+	}
+
+	// for a linear model, the chi-square is parabolic.
+	for i, e := range errs {
+		if got, want := e.Lower, -res.Errs[i]; !scalar.EqualWithinRel(got, want, 1e-3) {
+			t.Fatalf("p[%d]: invalid lower error: got=%v, want=%v", i, got, want)
+		}
+		if got, want := e.Upper, +res.Errs[i]; !scalar.EqualWithinRel(got, want, 1e-3) {
+			t.Fatalf("p[%d]: invalid upper error: got=%v, want=%v", i, got, want)
+		}
+	}
+
+	errs, err = fit.Minos(res, 1)
+	if err != nil {
+		t.Fatalf("could not compute minos errors: %+v", err)
+	}
+	if got, want := len(errs), 1; got != want {
+		t.Fatalf("invalid number of errors: got=%d, want=%d", got, want)
+	}
+
+	_, err = fit.Minos(res, 2)
+	if err == nil {
+		t.Fatalf("expected an error for an invalid parameter")
+	}
+}
+
+func TestMinosPoisson(t *testing.T) {
+	// fit of the mean of a Poisson distribution, from a single count n=3.
+	data := hbook.NewH1D(1, 0, 1)
+	data.Fill(0.5, 3)
+	tmpl := hbook.NewH1D(1, 0, 1)
+	tmpl.Fill(0.5, 1)
+
+	res, err := fit.H1DTemplates(data, fit.Templates{H: []*hbook.H1D{tmpl}}, nil, nil)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	if got, want := res.X[0], 3.0; !scalar.EqualWithinAbsOrRel(got, want, 1e-5, 1e-5) {
+		t.Fatalf("invalid best-fit value: got=%v, want=%v", got, want)
+	}
+
+	errs, err := fit.Minos(res)
+	if err != nil {
+		t.Fatalf("could not compute minos errors: %+v", err)
+	}
+
+	// solutions of mu - n*ln(mu) = n - n*ln(n) + 0.5
+	want := fit.AsymError{Lower: -1.4160257442241626, Upper: 2.0802366974966606}
+	if got := errs[0]; !scalar.EqualWithinAbsOrRel(got.Lower, want.Lower, 1e-4, 1e-4) ||
+		!scalar.EqualWithinAbsOrRel(got.Upper, want.Upper, 1e-4, 1e-4) {
+		t.Fatalf("invalid minos errors: got=%+v, want=%+v", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"fmt"
+	"math"
+
+	"go-hep.org/x/hep/hbook"
+)
+
+// Morphing is a histogram template whose shape depends on systematic
+// uncertainties, described by nuisance parameters.
+//
+// The content of each bin is interpolated vertically, independently of the
+// other bins, between the nominal histogram and its variations at ±1σ of
+// each nuisance parameter α.
+// The variations are added linearly:
+//
+//	n(α) = nominal + sum_k δ_k(α_k)
+//
+// where δ_k is interpolated quadratically for |α_k| <= 1, and extrapolated
+// linearly beyond, so n is continuous and has a continuous derivative
+// (as the interpolation code 2 of HistFactory).
+// Negative contents are set to zero.
+//
+// Morphing.F can be used within the F function of a Func1D of a binned fit
+// (see H1D), e.g.:
+//
+//	f := fit.Func1D{
+//		F: func(x float64, ps []float64) float64 {
+//			return ps[0] * m.F(x, ps[1:])
+//		},
+//		Ps: []float64{1, 0},
+//	}
+type Morphing struct {
+	nom  *hbook.H1D
+	up   []*hbook.H1D
+	down []*hbook.H1D
+}
+
+// NewMorphing returns a template morphing between the nominal histogram and
+// its variations up and down, at +1σ and -1σ of each nuisance parameter.
+// All histograms must have the same binning.
+func NewMorphing(nominal *hbook.H1D, up, down []*hbook.H1D) (*Morphing, error) {
+	if len(up) != len(down) {
+		return nil, fmt.Errorf("fit: mismatch number of variations (up=%d, down=%d)", len(up), len(down))
+	}
+	for k := range up {
+		for _, h := range []*hbook.H1D{up[k], down[k]} {
+			err := sameBinning(nominal, h)
+			if err != nil {
+				return nil, fmt.Errorf("fit: invalid variation %d: %w", k, err)
+			}
+		}
+	}
+
+	return &Morphing{
+		nom:  nominal,
+		up:   append([]*hbook.H1D(nil), up...),
+		down: append([]*hbook.H1D(nil), down...),
+	}, nil
+}
+
+// Len returns the number of nuisance parameters of the morphing.
+func (m *Morphing) Len() int {
+	return len(m.up)
+}
+
+// F returns the content of the bin containing x of the template morphed
+// with the nuisance parameters alphas.
+// F returns 0 outside of the range of the nominal histogram.
+func (m *Morphing) F(x float64, alphas []float64) float64 {
+	if len(alphas) != len(m.up) {
+		panic(fmt.Errorf("fit: invalid number of nuisance parameters (got=%d, want=%d)", len(alphas), len(m.up)))
+	}
+	bin := m.nom.Bin(x)
+	if bin == nil {
+		return 0
+	}
+
+	var (
+		nom = bin.SumW()
+		v   = nom
+	)
+	for k, alpha := range alphas {
+		var (
+			up   = m.up[k].Bin(x).SumW()
+			down = m.down[k].Bin(x).SumW()
+		)
+		v += morph(alpha, nom, up, down)
+	}
+	return math.Max(0, v)
+}
+
+// morph returns the variation δ(α) of a bin content, interpolated
+// quadratically between the nominal content and its variations at ±1σ
+// for |α| <= 1, and extrapolated linearly beyond.
+func morph(alpha, nom, up, down float64) float64 {
+	var (
+		a = 0.5 * (up - down)
+		b = 0.5*(up+down) - nom
+	)
+	switch {
+	case alpha > 1:
+		return (up - nom) + (alpha-1)*(a+2*b)
+	case alpha < -1:
+		return (down - nom) + (alpha+1)*(a-2*b)
+	default:
+		return alpha * (a + alpha*b)
+	}
+}
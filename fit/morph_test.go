@@ -0,0 +1,112 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/floats/scalar"
+)
+
+func TestMorphing(t *testing.T) {
+	hist := func(vs ...float64) *hbook.H1D {
+		h := hbook.NewH1D(len(vs), 0, float64(len(vs)))
+		for i, v := range vs {
+			h.Fill(float64(i)+0.5, v)
+		}
+		return h
+	}
+
+	var (
+		nom  = hist(100, 80, 60, 40)
+		up   = hist(90, 80, 70, 60)
+		down = hist(120, 85, 55, 30)
+	)
+
+	m, err := fit.NewMorphing(nom, []*hbook.H1D{up}, []*hbook.H1D{down})
+	if err != nil {
+		t.Fatalf("could not create morphing: %+v", err)
+	}
+	if got, want := m.Len(), 1; got != want {
+		t.Fatalf("invalid number of nuisance parameters: got=%d, want=%d", got, want)
+	}
+
+	for _, tc := range []struct {
+		alpha float64
+		want  *hbook.H1D
+	}{
+		{0, nom},
+		{+1, up},
+		{-1, down},
+	} {
+		for i := 0; i < nom.Len(); i++ {
+			x := float64(i) + 0.5
+			if got, want := m.F(x, []float64{tc.alpha}), tc.want.Value(i); !scalar.EqualWithinAbs(got, want, 1e-12) {
+				t.Fatalf("alpha=%v: invalid bin %d: got=%v, want=%v", tc.alpha, i, got, want)
+			}
+		}
+	}
+
+	// the morphing is continuous, with a continuous derivative.
+	const eps = 1e-6
+	for _, alpha := range []float64{-1, 0, 1} {
+		for i := 0; i < nom.Len(); i++ {
+			var (
+				x  = float64(i) + 0.5
+				lo = m.F(x, []float64{alpha - eps})
+				v  = m.F(x, []float64{alpha})
+				hi = m.F(x, []float64{alpha + eps})
+			)
+			if got, want := (hi-v)/eps, (v-lo)/eps; !scalar.EqualWithinAbs(got, want, 1e-3) {
+				t.Fatalf("alpha=%v: discontinuous derivative in bin %d: got=%v, want=%v", alpha, i, got, want)
+			}
+		}
+	}
+
+	// negative contents are set to zero.
+	if got, want := m.F(3.5, []float64{-10}), 0.0; got != want {
+		t.Fatalf("invalid negative content: got=%v, want=%v", got, want)
+	}
+	if got, want := m.F(10, []float64{0}), 0.0; got != want {
+		t.Fatalf("invalid content outside of range: got=%v, want=%v", got, want)
+	}
+
+	// fit the normalization and the nuisance parameter of Asimov data.
+	data := hbook.NewH1D(nom.Len(), 0, float64(nom.Len()))
+	for i := 0; i < nom.Len(); i++ {
+		x := float64(i) + 0.5
+		data.Fill(x, 1.2*m.F(x, []float64{0.5}))
+	}
+
+	res, err := fit.H1D(
+		data,
+		fit.Func1D{
+			F: func(x float64, ps []float64) float64 {
+				return ps[0] * m.F(x, ps[1:])
+			},
+			Ps: []float64{1, 0},
+		},
+		fit.WithCost(fit.PoissonNLL),
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	for i, want := range []float64{1.2, 0.5} {
+		if got := res.X[i]; !scalar.EqualWithinAbs(got, want, 1e-3) {
+			t.Fatalf("invalid p[%d]: got=%v, want=%v", i, got, want)
+		}
+	}
+
+	_, err = fit.NewMorphing(nom, []*hbook.H1D{up}, nil)
+	if err == nil {
+		t.Fatalf("expected an error for a mismatched number of variations")
+	}
+	_, err = fit.NewMorphing(nom, []*hbook.H1D{up}, []*hbook.H1D{hist(1, 2)})
+	if err == nil {
+		t.Fatalf("expected an error for a variation with a different binning")
+	}
+}
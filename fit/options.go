@@ -0,0 +1,117 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Option configures a fit.
+type Option func(cfg *config)
+
+type config struct {
+	settings *optimize.Settings
+	method   optimize.Method
+	restarts int
+	cost     Cost
+	density  bool
+
+	fixed       []int        // indices of the fixed parameters
+	bounds      []bound      // bounds of the parameters
+	constraints []constraint // Gaussian constraints on the parameters
+}
+
+type bound struct {
+	i      int
+	lo, hi float64
+}
+
+type constraint struct {
+	i           int
+	mean, sigma float64
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{cost: NeymanChi2}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithSettings sets the settings of the minimization.
+// By default, optimize.DefaultSettingsLocal is used.
+func WithSettings(settings *optimize.Settings) Option {
+	return func(cfg *config) {
+		cfg.settings = settings
+	}
+}
+
+// WithMethod sets the method used for the minimization.
+// By default, optimize.NelderMead is used.
+//
+// Any optimize.Method can be used, e.g. optimize.LBFGS, which acts as a
+// bounded L-BFGS when combined with WithBounds, or global methods such as
+// optimize.CmaEsChol and DiffEvolution for multi-modal problems.
+func WithMethod(m optimize.Method) Option {
+	return func(cfg *config) {
+		cfg.method = m
+	}
+}
+
+// WithRestarts restarts the minimization from its result, at most n times,
+// as long as the cost function decreases.
+// Restarts help methods such as optimize.NelderMead, whose simplex may
+// collapse before reaching the minimum.
+func WithRestarts(n int) Option {
+	return func(cfg *config) {
+		cfg.restarts = n
+	}
+}
+
+// WithFixed fixes the i-th parameter to its initial value.
+func WithFixed(i int) Option {
+	return func(cfg *config) {
+		cfg.fixed = append(cfg.fixed, i)
+	}
+}
+
+// WithBounds restricts the i-th parameter to the [lo, hi] range.
+// One-sided bounds are set with an infinite lo or hi.
+//
+// Bounded parameters are internally transformed into unbounded parameters,
+// so gradient-based methods can still be used.
+// Parameters close to their bounds may however have inaccurate errors.
+func WithBounds(i int, lo, hi float64) Option {
+	return func(cfg *config) {
+		cfg.bounds = append(cfg.bounds, bound{i: i, lo: lo, hi: hi})
+	}
+}
+
+// WithConstraint adds a Gaussian constraint of the provided mean and
+// width on the i-th parameter (e.g. a nuisance parameter): the cost
+// function is increased by 0.5*((p_i-mean)/sigma)^2.
+func WithConstraint(i int, mean, sigma float64) Option {
+	return func(cfg *config) {
+		cfg.constraints = append(cfg.constraints, constraint{i: i, mean: mean, sigma: sigma})
+	}
+}
+
+// WithCost sets the cost function of a binned fit.
+// By default, NeymanChi2 is used.
+func WithCost(c Cost) Option {
+	return func(cfg *config) {
+		cfg.cost = c
+	}
+}
+
+// WithDensity sets whether the fitted function is a density.
+// When enabled, the expected content of a bin is the value of the function
+// at the center of the bin times the width of the bin.
+func WithDensity(v bool) Option {
+	return func(cfg *config) {
+		cfg.density = v
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestWithFixed(t *testing.T) {
+	var (
+		xs = []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		ys = []float64{1.1, 2.9, 5.2, 7.1, 8.8, 11.2, 12.9, 15.1, 17.2, 18.8}
+	)
+
+	res, err := fit.Curve1D(
+		fit.Func1D{
+			F: func(x float64, ps []float64) float64 {
+				return ps[0] + ps[1]*x
+			},
+			X:  xs,
+			Y:  ys,
+			Ps: []float64{1.5, 1},
+		},
+		&optimize.Settings{GradientThreshold: 1e-6}, &optimize.BFGS{},
+		fit.WithFixed(0),
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	if !res.Valid() {
+		t.Fatalf("invalid fit: status=%v, cov=%v", res.Status, res.CovStatus)
+	}
+
+	if got, want := res.X[0], 1.5; got != want {
+		t.Fatalf("fixed parameter was modified: got=%v, want=%v", got, want)
+	}
+
+	var sxy, sxx float64
+	for i, x := range xs {
+		sxy += x * (ys[i] - 1.5)
+		sxx += x * x
+	}
+	if got, want := res.X[1], sxy/sxx; !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-6) {
+		t.Fatalf("invalid slope: got=%v, want=%v", got, want)
+	}
+	if got, want := res.Errs[1], 1/math.Sqrt(sxx); !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-4) {
+		t.Fatalf("invalid slope error: got=%v, want=%v", got, want)
+	}
+	if res.Errs[0] != 0 || res.Cov.At(0, 1) != 0 || res.Corr.At(0, 1) != 0 {
+		t.Fatalf("fixed parameter has non-null covariance: errs=%v, cov=%v", res.Errs, res.Cov)
+	}
+
+	_, err = fit.Minos(res, 0)
+	if err == nil {
+		t.Fatalf("expected an error for a fixed parameter")
+	}
+}
+
+func TestWithBounds(t *testing.T) {
+	cst := fit.Func1D{
+		F: func(x float64, ps []float64) float64 {
+			return ps[0]
+		},
+		X:  []float64{0, 1, 2, 3},
+		Y:  []float64{-1.2, -0.8, -1.1, -0.9},
+		Ps: []float64{1},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		lo, hi float64
+		p0     float64
+		want   float64
+	}{
+		{"lower", 0, math.Inf(+1), 1, 0},
+		{"double", 0, 10, 1, 0},
+		{"inactive", -10, 10, 1, -1},
+		{"upper", math.Inf(-1), 0, -0.5, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cst := cst
+			cst.Ps = []float64{tc.p0}
+			settings := &optimize.Settings{GradientThreshold: 1e-6}
+			for _, m := range []optimize.Method{&optimize.NelderMead{}, &optimize.BFGS{}, &optimize.LBFGS{}} {
+				res, err := fit.Curve1D(cst, settings, m, fit.WithBounds(0, tc.lo, tc.hi))
+				if err != nil {
+					t.Fatalf("%T: could not fit: %+v", m, err)
+				}
+				got := res.X[0]
+				if got < tc.lo || tc.hi < got {
+					t.Fatalf("%T: parameter outside of bounds: %v", m, got)
+				}
+				if !scalar.EqualWithinAbs(got, tc.want, 1e-3) {
+					t.Fatalf("%T: invalid parameter: got=%v, want=%v", m, got, tc.want)
+				}
+			}
+		})
+	}
+
+	_, err := fit.Curve1D(cst, nil, nil, fit.WithBounds(0, 2, 3))
+	if err == nil {
+		t.Fatalf("expected an error for an initial value outside of the bounds")
+	}
+	_, err = fit.Curve1D(cst, nil, nil, fit.WithBounds(0, 3, 2))
+	if err == nil {
+		t.Fatalf("expected an error for invalid bounds")
+	}
+	_, err = fit.Curve1D(cst, nil, nil, fit.WithBounds(1, 0, 2))
+	if err == nil {
+		t.Fatalf("expected an error for an invalid parameter index")
+	}
+}
+
+func TestWithConstraint(t *testing.T) {
+	var (
+		ys   = []float64{1.8, 2.3, 2.1, 1.9, 2.4, 2.0, 1.7, 2.2}
+		xs   = make([]float64, len(ys))
+		errs = make([]float64, len(ys))
+	)
+	for i := range errs {
+		errs[i] = 0.5
+	}
+
+	const (
+		mean  = 1.5
+		sigma = 0.25
+	)
+
+	res, err := fit.Curve1D(
+		fit.Func1D{
+			F: func(x float64, ps []float64) float64 {
+				return ps[0]
+			},
+			X:   xs,
+			Y:   ys,
+			Err: errs,
+			Ps:  []float64{1},
+		},
+		&optimize.Settings{GradientThreshold: 1e-6}, &optimize.BFGS{},
+		fit.WithConstraint(0, mean, sigma),
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+
+	// the constraint acts as an additional measurement.
+	var (
+		sw  = 1 / (sigma * sigma)
+		swy = mean / (sigma * sigma)
+	)
+	for i, y := range ys {
+		w := 1 / (errs[i] * errs[i])
+		sw += w
+		swy += w * y
+	}
+	if got, want := res.X[0], swy/sw; !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-6) {
+		t.Fatalf("invalid parameter: got=%v, want=%v", got, want)
+	}
+	if got, want := res.Errs[0], 1/math.Sqrt(sw); !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-4) {
+		t.Fatalf("invalid error: got=%v, want=%v", got, want)
+	}
+}
+
+func TestWithRestarts(t *testing.T) {
+	model := fit.FuncND{
+		F: func(x []float64, ps []float64) float64 {
+			return ps[0]*x[0] + ps[1]*x[1] + ps[2]*x[0]*x[1]
+		},
+		X:  [][]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {2, 1}, {1, 2}, {2, 2}},
+		Y:  []float64{0, 1, 2, 4.5, 6.9, 8.1, 13.8},
+		Ps: []float64{10, -10, 5},
+	}
+
+	settings := &optimize.Settings{
+		Converger: &optimize.FunctionConverge{Absolute: 1e-3, Iterations: 5},
+	}
+	ref, err := fit.CurveND(model, settings, &optimize.NelderMead{})
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	res, err := fit.CurveND(model, settings, &optimize.NelderMead{}, fit.WithRestarts(10))
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+
+	if !(res.F <= ref.F) {
+		t.Fatalf("restarts did not improve the minimum: got=%v, want<=%v", res.F, ref.F)
+	}
+	if !(res.Stats.FuncEvaluations > ref.Stats.FuncEvaluations) {
+		t.Fatalf("invalid number of evaluations: got=%d, want>%d", res.Stats.FuncEvaluations, ref.Stats.FuncEvaluations)
+	}
+}
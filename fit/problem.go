@@ -0,0 +1,318 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/diff/fd"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// problem is the minimization of the cost function of a fit, with
+// optionally fixed, bounded or constrained parameters.
+//
+// Bounded parameters are transformed into unbounded internal parameters,
+// as in MINUIT, so gradient-based methods can be used:
+//
+//	p = lo + (hi-lo)/2 * (sin(u)+1)  for lo <= p <= hi
+//	p = lo - 1 + sqrt(u*u+1)         for lo <= p
+//	p = hi + 1 - sqrt(u*u+1)         for p <= hi
+type problem struct {
+	fct  func(ps []float64) float64 // cost function, including the constraints
+	grad func(grad, ps []float64)   // gradient of the cost function (may be nil)
+
+	ps    []float64 // initial values of the parameters
+	fixed []bool    // fixed parameters
+	lo    []float64 // lower bounds of the parameters
+	hi    []float64 // upper bounds of the parameters
+
+	cs []constraint // Gaussian constraints on the parameters
+
+	ndata int // number of measurements of a chi-square-like cost function (0 if none)
+}
+
+func newProblem(fct func(ps []float64) float64, grad func(grad, ps []float64), ps []float64, cfg *config) (*problem, error) {
+	n := len(ps)
+	p := &problem{
+		fct:   fct,
+		grad:  grad,
+		ps:    make([]float64, n),
+		fixed: make([]bool, n),
+		lo:    make([]float64, n),
+		hi:    make([]float64, n),
+	}
+	copy(p.ps, ps)
+	for i := range p.lo {
+		p.lo[i] = math.Inf(-1)
+		p.hi[i] = math.Inf(+1)
+	}
+
+	valid := func(i int) error {
+		if i < 0 || i >= n {
+			return fmt.Errorf("fit: invalid parameter index %d", i)
+		}
+		return nil
+	}
+
+	for _, i := range cfg.fixed {
+		if err := valid(i); err != nil {
+			return nil, err
+		}
+		p.fixed[i] = true
+	}
+
+	for _, b := range cfg.bounds {
+		if err := valid(b.i); err != nil {
+			return nil, err
+		}
+		if !(b.lo < b.hi) {
+			return nil, fmt.Errorf("fit: invalid bounds [%v, %v] for parameter %d", b.lo, b.hi, b.i)
+		}
+		if v := p.ps[b.i]; v < b.lo || b.hi < v {
+			return nil, fmt.Errorf("fit: initial value %v of parameter %d outside of bounds [%v, %v]", v, b.i, b.lo, b.hi)
+		}
+		p.lo[b.i] = b.lo
+		p.hi[b.i] = b.hi
+	}
+
+	for _, c := range cfg.constraints {
+		if err := valid(c.i); err != nil {
+			return nil, err
+		}
+		if !(c.sigma > 0) {
+			return nil, fmt.Errorf("fit: invalid width %v of the constraint on parameter %d", c.sigma, c.i)
+		}
+	}
+	p.cs = cfg.constraints
+	p.fct = p.withConstraints(fct)
+	if grad != nil && len(p.cs) > 0 {
+		p.grad = func(g, ps []float64) {
+			grad(g, ps)
+			for _, c := range p.cs {
+				g[c.i] += (ps[c.i] - c.mean) / (c.sigma * c.sigma)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// withConstraints returns the provided cost function with the penalty terms
+// of the Gaussian constraints on the parameters.
+func (p *problem) withConstraints(fct func(ps []float64) float64) func(ps []float64) float64 {
+	if len(p.cs) == 0 {
+		return fct
+	}
+	return func(ps []float64) float64 {
+		v := fct(ps)
+		for _, c := range p.cs {
+			d := (ps[c.i] - c.mean) / c.sigma
+			v += 0.5 * d * d
+		}
+		return v
+	}
+}
+
+// solve minimizes the cost function with respect to all the free parameters,
+// starting from their initial values.
+func (p *problem) solve(cfg *config) (*Result, error) {
+	free := p.free()
+	res, err := p.minimize(p.ps, free, cfg.settings, cfg.method)
+	for i := 0; i < cfg.restarts && err == nil; i++ {
+		var o *optimize.Result
+		o, err = p.minimize(res.X, free, cfg.settings, cfg.method)
+		if o == nil {
+			break
+		}
+		o.Stats.MajorIterations += res.Stats.MajorIterations
+		o.Stats.FuncEvaluations += res.Stats.FuncEvaluations
+		o.Stats.GradEvaluations += res.Stats.GradEvaluations
+		o.Stats.HessEvaluations += res.Stats.HessEvaluations
+		o.Stats.Runtime += res.Stats.Runtime
+		improved := o.F < res.F-1e-10*(1+math.Abs(res.F))
+		if o.F <= res.F {
+			res = o
+		} else {
+			res.Stats = o.Stats
+		}
+		if !improved {
+			break
+		}
+	}
+	return newResult(res, err, p)
+}
+
+// free returns the indices of the parameters that are not fixed, excluding
+// the provided parameters.
+func (p *problem) free(excl ...int) []int {
+	free := make([]int, 0, len(p.ps))
+loop:
+	for i, fixed := range p.fixed {
+		if fixed {
+			continue
+		}
+		for _, j := range excl {
+			if i == j {
+				continue loop
+			}
+		}
+		free = append(free, i)
+	}
+	return free
+}
+
+// identity returns whether the internal parameters are the external ones.
+func (p *problem) identity(free []int) bool {
+	if len(free) != len(p.ps) {
+		return false
+	}
+	for i := range p.ps {
+		if !math.IsInf(p.lo[i], -1) || !math.IsInf(p.hi[i], +1) {
+			return false
+		}
+	}
+	return true
+}
+
+// inBounds returns whether the value v of the i-th parameter is within
+// its bounds.
+func (p *problem) inBounds(i int, v float64) bool {
+	return p.lo[i] <= v && v <= p.hi[i]
+}
+
+// external returns the external value of the i-th parameter from its internal
+// value u.
+func (p *problem) external(i int, u float64) float64 {
+	lo, hi := p.lo[i], p.hi[i]
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, +1):
+		return u
+	case math.IsInf(hi, +1):
+		return lo - 1 + math.Sqrt(u*u+1)
+	case math.IsInf(lo, -1):
+		return hi + 1 - math.Sqrt(u*u+1)
+	default:
+		return lo + 0.5*(hi-lo)*(math.Sin(u)+1)
+	}
+}
+
+// internal returns the internal value of the i-th parameter from its external
+// value v.
+func (p *problem) internal(i int, v float64) float64 {
+	lo, hi := p.lo[i], p.hi[i]
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, +1):
+		return v
+	case math.IsInf(hi, +1):
+		d := v - lo + 1
+		return math.Sqrt(math.Max(0, d*d-1))
+	case math.IsInf(lo, -1):
+		d := hi - v + 1
+		return math.Sqrt(math.Max(0, d*d-1))
+	default:
+		return math.Asin(math.Max(-1, math.Min(+1, 2*(v-lo)/(hi-lo)-1)))
+	}
+}
+
+// dexternal returns the derivative of the external value of the i-th parameter
+// with respect to its internal value u.
+func (p *problem) dexternal(i int, u float64) float64 {
+	lo, hi := p.lo[i], p.hi[i]
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, +1):
+		return 1
+	case math.IsInf(hi, +1):
+		return u / math.Sqrt(u*u+1)
+	case math.IsInf(lo, -1):
+		return -u / math.Sqrt(u*u+1)
+	default:
+		return 0.5 * (hi - lo) * math.Cos(u)
+	}
+}
+
+// minimize minimizes the cost function with respect to the free parameters,
+// starting from the parameters ps.
+// The returned result holds the external values of all the parameters.
+func (p *problem) minimize(ps []float64, free []int, settings *optimize.Settings, m optimize.Method) (*optimize.Result, error) {
+	if m == nil {
+		m = &optimize.NelderMead{}
+	}
+
+	if p.identity(free) {
+		prob := optimize.Problem{
+			Func: p.fct,
+			Grad: p.grad,
+			Hess: func(hess *mat.SymDense, x []float64) {
+				fd.Hessian(hess, p.fct, x, nil)
+			},
+		}
+		if prob.Grad == nil {
+			prob.Grad = func(grad, x []float64) {
+				fd.Gradient(grad, p.fct, x, nil)
+			}
+		}
+		x0 := make([]float64, len(ps))
+		copy(x0, ps)
+		return optimize.Minimize(prob, x0, settings, m)
+	}
+
+	toExt := func(dst, u []float64) {
+		copy(dst, ps)
+		for j, i := range free {
+			dst[i] = p.external(i, u[j])
+		}
+	}
+
+	fct := func(u []float64) float64 {
+		x := make([]float64, len(ps))
+		toExt(x, u)
+		return p.fct(x)
+	}
+
+	grad := func(grad, u []float64) {
+		fd.Gradient(grad, fct, u, nil)
+	}
+	if p.grad != nil {
+		grad = func(grad, u []float64) {
+			var (
+				x = make([]float64, len(ps))
+				g = make([]float64, len(ps))
+			)
+			toExt(x, u)
+			p.grad(g, x)
+			for j, i := range free {
+				grad[j] = g[i] * p.dexternal(i, u[j])
+			}
+		}
+	}
+
+	prob := optimize.Problem{
+		Func: fct,
+		Grad: grad,
+		Hess: func(hess *mat.SymDense, u []float64) {
+			fd.Hessian(hess, fct, u, nil)
+		},
+	}
+
+	u0 := make([]float64, len(free))
+	for j, i := range free {
+		u0[j] = p.internal(i, ps[i])
+	}
+
+	res, err := optimize.Minimize(prob, u0, settings, m)
+	if res == nil {
+		return nil, err
+	}
+
+	x := make([]float64, len(ps))
+	toExt(x, res.X)
+	res.X = x
+	res.Gradient = nil
+	res.Hessian = nil
+	return res, err
+}
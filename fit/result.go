@@ -0,0 +1,201 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// CovStatus describes the status of the covariance matrix of a fit result.
+type CovStatus int
+
+const (
+	CovNotAvailable CovStatus = iota // the Hessian matrix could not be computed
+	CovNotPosDef                     // the Hessian matrix is not positive definite
+	CovAccurate                      // the covariance matrix is the inverse of the Hessian matrix
+)
+
+func (st CovStatus) String() string {
+	switch st {
+	case CovNotAvailable:
+		return "not available"
+	case CovNotPosDef:
+		return "not positive definite"
+	case CovAccurate:
+		return "accurate"
+	}
+	return "invalid"
+}
+
+// Result is the result of a fit.
+//
+// The embedded optimize.Result holds the best-fit values of the parameters
+// (X), the value of the cost function at the optimum (F), the status of the
+// minimization and the Hessian matrix of the cost function at the optimum.
+//
+// The cost functions of the fit package are half a chi-square or a negative
+// log-likelihood, so the covariance matrix of the fitted parameters is the
+// inverse of their Hessian matrix.
+type Result struct {
+	optimize.Result
+
+	Cov       *mat.SymDense // covariance matrix of the parameters (nil if not available)
+	Errs      []float64     // parabolic errors of the parameters (nil if not available)
+	Corr      *mat.SymDense // correlation matrix of the parameters (nil if not available)
+	CovStatus CovStatus     // status of the covariance matrix
+
+	prob *problem // minimization problem of the fit
+}
+
+// Valid returns whether the minimization converged and the covariance
+// matrix is accurate.
+func (res *Result) Valid() bool {
+	return res.Status.Err() == nil && res.CovStatus == CovAccurate
+}
+
+// newResult returns the fit result from the result of the minimization of
+// the problem p, filling the Hessian matrix (if the optimization method did
+// not compute it) and the covariance matrix of the parameters.
+func newResult(res *optimize.Result, err error, p *problem) (*Result, error) {
+	if res == nil {
+		return nil, err
+	}
+	o := &Result{Result: *res, prob: p}
+	if err != nil {
+		return o, err
+	}
+	if o.Hessian == nil {
+		o.Hessian = mat.NewSymDense(len(o.X), nil)
+		hessian(o.Hessian, p.fct, o.X)
+	}
+	o.covariance()
+	return o, nil
+}
+
+// covariance computes the covariance and correlation matrices, and the
+// parabolic errors, from the Hessian matrix of the result.
+// Fixed parameters have null covariances, errors and correlations.
+func (res *Result) covariance() {
+	res.Cov = nil
+	res.Errs = nil
+	res.Corr = nil
+	res.CovStatus = CovNotAvailable
+
+	if res.Hessian == nil || !finite(res.Hessian) {
+		return
+	}
+
+	var (
+		n    = res.Hessian.SymmetricDim()
+		free = res.prob.free()
+		hess = mat.NewSymDense(len(free), nil)
+	)
+	for i, ii := range free {
+		for j, jj := range free[i:] {
+			hess.SetSym(i, i+j, res.Hessian.At(ii, jj))
+		}
+	}
+
+	var chol mat.Cholesky
+	if !chol.Factorize(hess) {
+		res.CovStatus = CovNotPosDef
+		return
+	}
+
+	inv := new(mat.SymDense)
+	err := chol.InverseTo(inv)
+	if err != nil {
+		res.CovStatus = CovNotPosDef
+		return
+	}
+
+	cov := mat.NewSymDense(n, nil)
+	for i, ii := range free {
+		for j, jj := range free[i:] {
+			cov.SetSym(ii, jj, inv.At(i, i+j))
+		}
+	}
+	res.setCov(cov)
+}
+
+// setCov sets the covariance matrix of the result, and the parabolic errors
+// and correlation matrix derived from it.
+func (res *Result) setCov(cov *mat.SymDense) {
+	var (
+		n    = cov.SymmetricDim()
+		free = res.prob.free()
+		errs = make([]float64, n)
+		corr = mat.NewSymDense(n, nil)
+	)
+	for _, i := range free {
+		errs[i] = math.Sqrt(cov.At(i, i))
+	}
+	for i, ii := range free {
+		for _, jj := range free[i:] {
+			corr.SetSym(ii, jj, cov.At(ii, jj)/(errs[ii]*errs[jj]))
+		}
+	}
+
+	res.Cov = cov
+	res.Errs = errs
+	res.Corr = corr
+	res.CovStatus = CovAccurate
+}
+
+// hessian computes the Hessian matrix of f at x with central finite
+// differences.
+// Steps are scaled with the magnitude of the parameters, so parameters with
+// large values (e.g. yields) do not suffer from round-off errors.
+func hessian(dst *mat.SymDense, f func(x []float64) float64, x []float64) {
+	const step = 1e-3
+
+	var (
+		n  = len(x)
+		xx = make([]float64, n)
+		hs = make([]float64, n)
+		f0 = f(x)
+	)
+	copy(xx, x)
+	for i, v := range x {
+		hs[i] = step * math.Max(1, math.Abs(v))
+	}
+
+	eval := func(i int, di float64, j int, dj float64) float64 {
+		xx[i] += di
+		xx[j] += dj
+		v := f(xx)
+		xx[i] = x[i]
+		xx[j] = x[j]
+		return v
+	}
+
+	for i := 0; i < n; i++ {
+		hi := hs[i]
+		v := (eval(i, +hi, i, 0) - 2*f0 + eval(i, -hi, i, 0)) / (hi * hi)
+		dst.SetSym(i, i, v)
+		for j := i + 1; j < n; j++ {
+			hj := hs[j]
+			v := (eval(i, +hi, j, +hj) - eval(i, +hi, j, -hj) -
+				eval(i, -hi, j, +hj) + eval(i, -hi, j, -hj)) / (4 * hi * hj)
+			dst.SetSym(i, j, v)
+		}
+	}
+}
+
+func finite(m mat.Matrix) bool {
+	r, c := m.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v := m.At(i, j)
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestResultCovariance(t *testing.T) {
+	var (
+		xs   = []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		ys   = []float64{1.1, 2.9, 5.2, 7.1, 8.8, 11.2, 12.9, 15.1, 17.2, 18.8}
+		errs = []float64{0.1, 0.2, 0.1, 0.3, 0.2, 0.1, 0.2, 0.3, 0.1, 0.2}
+	)
+
+	res, err := fit.Curve1D(
+		fit.Func1D{
+			F: func(x float64, ps []float64) float64 {
+				return ps[0] + ps[1]*x
+			},
+			X:   xs,
+			Y:   ys,
+			Err: errs,
+			N:   2,
+		},
+		nil, &optimize.NelderMead{},
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	if !res.Valid() {
+		t.Fatalf("invalid fit: status=%v, cov=%v", res.Status, res.CovStatus)
+	}
+	if got, want := res.CovStatus, fit.CovAccurate; got != want {
+		t.Fatalf("invalid covariance status: got=%v, want=%v", got, want)
+	}
+
+	// analytical covariance matrix of a weighted linear least squares.
+	var s, sx, sxx float64
+	for i, x := range xs {
+		w := 1 / (errs[i] * errs[i])
+		s += w
+		sx += w * x
+		sxx += w * x * x
+	}
+	det := s*sxx - sx*sx
+	want := mat.NewSymDense(2, []float64{
+		sxx / det, -sx / det,
+		-sx / det, s / det,
+	})
+
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			if got, want := res.Cov.At(i, j), want.At(i, j); !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-4) {
+				t.Fatalf("invalid cov[%d,%d]: got=%v, want=%v", i, j, got, want)
+			}
+		}
+		if got, want := res.Errs[i], math.Sqrt(want.At(i, i)); !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-4) {
+			t.Fatalf("invalid error #%d: got=%v, want=%v", i, got, want)
+		}
+		if got := res.Corr.At(i, i); !scalar.EqualWithinAbsOrRel(got, 1, 1e-12, 1e-12) {
+			t.Fatalf("invalid corr[%d,%d]: got=%v, want=1", i, i, got)
+		}
+	}
+	if got, want := res.Corr.At(0, 1), -sx/math.Sqrt(s*sxx); !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-4) {
+		t.Fatalf("invalid correlation: got=%v, want=%v", got, want)
+	}
+}
+
+func TestResultCovarianceNotPosDef(t *testing.T) {
+	res, err := fit.Curve1D(
+		fit.Func1D{
+			F: func(x float64, ps []float64) float64 {
+				// ps[1] is not constrained by the data.
+				return ps[0] * x
+			},
+			X:  []float64{1, 2, 3, 4},
+			Y:  []float64{2, 4, 6, 8},
+			Ps: []float64{1, 1},
+		},
+		nil, &optimize.NelderMead{},
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	if got, want := res.CovStatus, fit.CovNotPosDef; got != want {
+		t.Fatalf("invalid covariance status: got=%v, want=%v", got, want)
+	}
+	if res.Valid() {
+		t.Fatalf("fit should not be valid")
+	}
+	if res.Cov != nil || res.Errs != nil || res.Corr != nil {
+		t.Fatalf("unexpected covariance: cov=%v, errs=%v, corr=%v", res.Cov, res.Errs, res.Corr)
+	}
+}
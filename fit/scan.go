@@ -0,0 +1,171 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"fmt"
+	"math"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Profile is the profile of the cost function of a fit along one of its
+// parameters.
+//
+// Profile implements the gonum.org/v1/plot/plotter.XYer interface, so it
+// can be drawn with hplot.
+type Profile struct {
+	X []float64 // values of the parameter
+	Y []float64 // values of Δχ²=-2ΔlnL of the profile
+}
+
+// Len returns the number of points of the profile.
+func (p *Profile) Len() int { return len(p.X) }
+
+// XY returns the coordinates of the i-th point of the profile.
+func (p *Profile) XY(i int) (float64, float64) { return p.X[i], p.Y[i] }
+
+// Scan returns the profile of the cost function of a fit result along the
+// k-th parameter, for n values of the parameter evenly spaced over rng.
+//
+// For each value of the parameter, the cost function is minimized with
+// respect to all the other free parameters.
+// The profile is expressed as Δχ²=-2ΔlnL with respect to the minimum of the
+// fit: its crossings with 1 are the MINOS errors of the parameter.
+func Scan(res *Result, k int, rng hbook.Range, n int) (*Profile, error) {
+	if res == nil || res.prob == nil {
+		return nil, fmt.Errorf("fit: fit result has no cost function")
+	}
+	if k < 0 || k >= len(res.X) {
+		return nil, fmt.Errorf("fit: invalid parameter index %d", k)
+	}
+	if res.prob.fixed[k] {
+		return nil, fmt.Errorf("fit: parameter %d is fixed", k)
+	}
+	if !(rng.Min < rng.Max) || n < 2 {
+		return nil, fmt.Errorf("fit: invalid scan range [%v, %v] with %d points", rng.Min, rng.Max, n)
+	}
+	if !res.prob.inBounds(k, rng.Min) || !res.prob.inBounds(k, rng.Max) {
+		return nil, fmt.Errorf("fit: scan range [%v, %v] outside of the bounds of parameter %d", rng.Min, rng.Max, k)
+	}
+
+	var (
+		prof = newProfiler(res, k)
+		o    = &Profile{
+			X: make([]float64, n),
+			Y: make([]float64, n),
+		}
+		step = rng.Width() / float64(n-1)
+	)
+	for i := range o.X {
+		x := rng.Min + float64(i)*step
+		v, err := prof.at(x)
+		if err != nil {
+			return nil, fmt.Errorf("fit: could not scan parameter %d: %w", k, err)
+		}
+		o.X[i] = x
+		o.Y[i] = 2 * v
+	}
+	return o, nil
+}
+
+// ContourLine is a closed contour of the profile of the cost function of a
+// fit in the plane of two of its parameters.
+//
+// ContourLine implements the gonum.org/v1/plot/plotter.XYer interface, so it
+// can be drawn with hplot. Its last point is its first point.
+type ContourLine struct {
+	Level float64   // confidence level of the contour
+	X     []float64 // values of the first parameter
+	Y     []float64 // values of the second parameter
+}
+
+// Len returns the number of points of the contour.
+func (c *ContourLine) Len() int { return len(c.X) }
+
+// XY returns the coordinates of the i-th point of the contour.
+func (c *ContourLine) XY(i int) (float64, float64) { return c.X[i], c.Y[i] }
+
+// Contour returns the contours of a fit result, with n points each, in the
+// plane of the parameters p1 and p2, for the provided confidence levels
+// (e.g. 0.68 and 0.95).
+//
+// The contours are the points where the cost function, minimized with
+// respect to all the other free parameters, increases with respect to the
+// minimum of the fit by the Δχ² quantile of the confidence level for two
+// degrees of freedom (2.30 for 68%, 5.99 for 95%).
+// They are found along n rays, evenly spaced in angle, starting from the
+// best-fit values of the parameters.
+func Contour(res *Result, p1, p2 int, levels []float64, n int) ([]ContourLine, error) {
+	if res == nil || res.prob == nil {
+		return nil, fmt.Errorf("fit: fit result has no cost function")
+	}
+	for _, k := range []int{p1, p2} {
+		if k < 0 || k >= len(res.X) {
+			return nil, fmt.Errorf("fit: invalid parameter index %d", k)
+		}
+		if res.prob.fixed[k] {
+			return nil, fmt.Errorf("fit: parameter %d is fixed", k)
+		}
+	}
+	if p1 == p2 {
+		return nil, fmt.Errorf("fit: contour of parameter %d with itself", p1)
+	}
+	if n < 3 {
+		return nil, fmt.Errorf("fit: invalid number of contour points %d", n)
+	}
+
+	scale := func(k int) float64 {
+		if res.Errs != nil {
+			if v := res.Errs[k]; v > 0 && !math.IsInf(v, 0) {
+				return v
+			}
+		}
+		return math.Max(0.1*math.Abs(res.X[k]), 0.1)
+	}
+
+	var (
+		x0   = res.X[p1]
+		y0   = res.X[p2]
+		sx   = scale(p1)
+		sy   = scale(p2)
+		prof = newProfiler(res, p1, p2)
+		chi2 = distuv.ChiSquared{K: 2}
+		cs   = make([]ContourLine, len(levels))
+	)
+	for i, cl := range levels {
+		if !(0 < cl && cl < 1) {
+			return nil, fmt.Errorf("fit: invalid confidence level %v", cl)
+		}
+		var (
+			dchi2 = chi2.Quantile(cl)
+			c     = ContourLine{
+				Level: cl,
+				X:     make([]float64, n+1),
+				Y:     make([]float64, n+1),
+			}
+		)
+		for j := 0; j < n; j++ {
+			var (
+				phi = 2 * math.Pi * float64(j) / float64(n)
+				dx  = sx * math.Cos(phi)
+				dy  = sy * math.Sin(phi)
+			)
+			t, err := root(func(t float64) (float64, error) {
+				return prof.at(x0+t*dx, y0+t*dy)
+			}, math.Sqrt(dchi2), 0.5*dchi2)
+			if err != nil {
+				return nil, fmt.Errorf("fit: could not compute %v contour of parameters (%d, %d): %w", cl, p1, p2, err)
+			}
+			c.X[j] = x0 + t*dx
+			c.Y[j] = y0 + t*dy
+		}
+		c.X[n] = c.X[0]
+		c.Y[n] = c.Y[0]
+		cs[i] = c
+	}
+	return cs, nil
+}
@@ -0,0 +1,120 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func linearFit(t *testing.T) *fit.Result {
+	t.Helper()
+	res, err := fit.Curve1D(
+		fit.Func1D{
+			F: func(x float64, ps []float64) float64 {
+				return ps[0] + ps[1]*x
+			},
+			X:   []float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			Y:   []float64{1.1, 2.9, 5.2, 7.1, 8.8, 11.2, 12.9, 15.1, 17.2, 18.8},
+			Err: []float64{0.1, 0.2, 0.1, 0.3, 0.2, 0.1, 0.2, 0.3, 0.1, 0.2},
+			N:   2,
+		},
+		nil, &optimize.NelderMead{},
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	return res
+}
+
+func TestScan(t *testing.T) {
+	res := linearFit(t)
+
+	var (
+		x0  = res.X[1]
+		err = res.Errs[1]
+	)
+	prof, e := fit.Scan(res, 1, hbook.Range{Min: x0 - 2*err, Max: x0 + 2*err}, 5)
+	if e != nil {
+		t.Fatalf("could not scan: %+v", e)
+	}
+	if got, want := prof.Len(), 5; got != want {
+		t.Fatalf("invalid number of points: got=%d, want=%d", got, want)
+	}
+
+	// for a linear model, the profile is a parabola.
+	for i, want := range []float64{4, 1, 0, 1, 4} {
+		x, y := prof.XY(i)
+		if got, want := x, x0+float64(i-2)*err; !scalar.EqualWithinAbsOrRel(got, want, 1e-9, 1e-9) {
+			t.Fatalf("invalid x[%d]: got=%v, want=%v", i, got, want)
+		}
+		if got := y; !scalar.EqualWithinAbs(got, want, 1e-3) {
+			t.Fatalf("invalid y[%d]: got=%v, want=%v", i, got, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		k    int
+		rng  hbook.Range
+	}{
+		{"index", 2, hbook.Range{Min: 0, Max: 1}},
+		{"range", 1, hbook.Range{Min: 1, Max: 0}},
+	} {
+		_, err := fit.Scan(res, tc.k, tc.rng, 10)
+		if err == nil {
+			t.Fatalf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestContour(t *testing.T) {
+	res := linearFit(t)
+
+	levels := []float64{0.68, 0.95}
+	cs, err := fit.Contour(res, 0, 1, levels, 16)
+	if err != nil {
+		t.Fatalf("could not compute contours: %+v", err)
+	}
+	if got, want := len(cs), len(levels); got != want {
+		t.Fatalf("invalid number of contours: got=%d, want=%d", got, want)
+	}
+
+	// for a linear model, the contours are the ellipses of the covariance
+	// matrix.
+	var inv mat.Dense
+	err = inv.Inverse(res.Cov)
+	if err != nil {
+		t.Fatalf("could not invert covariance matrix: %+v", err)
+	}
+
+	for i, c := range cs {
+		want := []float64{2.2788, 5.9915}[i]
+		if got, want := c.Len(), 17; got != want {
+			t.Fatalf("invalid number of points: got=%d, want=%d", got, want)
+		}
+		for j := 0; j < c.Len(); j++ {
+			x, y := c.XY(j)
+			d := mat.NewVecDense(2, []float64{x - res.X[0], y - res.X[1]})
+			if got := mat.Inner(d, &inv, d); !scalar.EqualWithinRel(got, want, 1e-3) {
+				t.Fatalf("contour %v: invalid point %d: got=%v, want=%v", c.Level, j, got, want)
+			}
+		}
+	}
+
+	_, err = fit.Contour(res, 0, 0, levels, 16)
+	if err == nil {
+		t.Fatalf("expected an error for a contour of a parameter with itself")
+	}
+	_, err = fit.Contour(res, 0, 1, []float64{1.5}, 16)
+	if err == nil {
+		t.Fatalf("expected an error for an invalid confidence level")
+	}
+}
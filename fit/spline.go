@@ -0,0 +1,69 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/interp"
+)
+
+// CubicSpline is a natural cubic spline model, whose parameters are its
+// values at fixed knots.
+// It describes smooth shapes, such as backgrounds, without assuming a
+// functional form.
+//
+// CubicSpline.F can be used as the F function of a Func1D, e.g.:
+//
+//	spl, err := fit.NewCubicSpline([]float64{0, 1, 2, 3, 4})
+//	f := fit.Func1D{F: spl.F, Ps: []float64{10, 8, 6, 5, 4}}
+//
+// CubicSpline is not safe for concurrent use.
+type CubicSpline struct {
+	knots []float64
+
+	ps  []float64 // parameters of the current spline
+	spl interp.NaturalCubic
+}
+
+// NewCubicSpline returns a natural cubic spline model with the provided
+// knots, in strictly increasing order.
+// The model has one parameter per knot.
+func NewCubicSpline(knots []float64) (*CubicSpline, error) {
+	if len(knots) < 2 {
+		return nil, fmt.Errorf("fit: invalid number of spline knots %d", len(knots))
+	}
+	for i := 1; i < len(knots); i++ {
+		if !(knots[i-1] < knots[i]) {
+			return nil, fmt.Errorf("fit: spline knots not strictly increasing (knots[%d]=%v, knots[%d]=%v)", i-1, knots[i-1], i, knots[i])
+		}
+	}
+
+	spl := &CubicSpline{
+		knots: make([]float64, len(knots)),
+	}
+	copy(spl.knots, knots)
+	return spl, nil
+}
+
+// Knots returns the knots of the spline.
+func (s *CubicSpline) Knots() []float64 {
+	return s.knots
+}
+
+// F returns the value at x of the spline going through the values ps at
+// its knots.
+// The spline is constant outside of its knots.
+func (s *CubicSpline) F(x float64, ps []float64) float64 {
+	if len(ps) != len(s.knots) {
+		panic(fmt.Errorf("fit: invalid number of spline parameters (got=%d, want=%d)", len(ps), len(s.knots)))
+	}
+	if s.ps == nil || !floats.Equal(s.ps, ps) {
+		s.ps = append(s.ps[:0], ps...)
+		_ = s.spl.Fit(s.knots, s.ps)
+	}
+	return s.spl.Predict(x)
+}
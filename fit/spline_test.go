@@ -0,0 +1,63 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/optimize"
+)
+
+func TestCubicSpline(t *testing.T) {
+	knots := []float64{0, 1, 2, 3, 4}
+	spl, err := fit.NewCubicSpline(knots)
+	if err != nil {
+		t.Fatalf("could not create spline: %+v", err)
+	}
+
+	// the spline goes through its knots.
+	ps := []float64{5, 3, 4, 1, 2}
+	for i, x := range knots {
+		if got, want := spl.F(x, ps), ps[i]; !scalar.EqualWithinAbs(got, want, 1e-12) {
+			t.Fatalf("invalid spline value at knot %d: got=%v, want=%v", i, got, want)
+		}
+	}
+	if got, want := spl.F(-1, ps), ps[0]; got != want {
+		t.Fatalf("invalid spline value before first knot: got=%v, want=%v", got, want)
+	}
+
+	// fit a smooth shape.
+	var (
+		shape = func(x float64) float64 { return 100 * math.Exp(-0.5*x) }
+		xs    = make([]float64, 41)
+		ys    = make([]float64, len(xs))
+	)
+	for i := range xs {
+		xs[i] = 0.1 * float64(i)
+		ys[i] = shape(xs[i])
+	}
+	res, err := fit.Curve1D(
+		fit.Func1D{F: spl.F, X: xs, Y: ys, Ps: []float64{1, 1, 1, 1, 1}},
+		&optimize.Settings{GradientThreshold: 1e-6}, &optimize.BFGS{},
+	)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	for i, x := range knots {
+		if got, want := res.X[i], shape(x); !scalar.EqualWithinRel(got, want, 1e-2) {
+			t.Fatalf("invalid value at knot %d: got=%v, want=%v", i, got, want)
+		}
+	}
+
+	for _, knots := range [][]float64{nil, {1}, {0, 2, 1}, {0, 1, 1}} {
+		_, err := fit.NewCubicSpline(knots)
+		if err == nil {
+			t.Fatalf("expected an error for knots %v", knots)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"fmt"
+	"math"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/optimize"
+)
+
+// Templates describes a set of histogram templates (e.g. Monte Carlo
+// predictions of signal and background processes) whose normalizations
+// are fitted simultaneously to a data histogram.
+//
+// The expected content of bin i is:
+//  nu_i = sum_j Ps[j] * gamma_ij * t_ij
+// where t_ij is the content of bin i of template j and gamma_ij is the
+// statistical nuisance parameter of that bin, when MCStat is enabled
+// (gamma_ij = 1 otherwise).
+type Templates struct {
+	// H is the list of template histograms.
+	// All templates must have the same binning than the data histogram.
+	H []*hbook.H1D
+
+	// Ps is the initial values for the normalization factors of the
+	// templates.
+	// If Ps is nil, all normalization factors start at 1.
+	Ps []float64
+
+	// MCStat enables the Barlow-Beeston-lite treatment of the limited
+	// statistics of the templates: each bin of each template carries a
+	// nuisance parameter gamma_ij, constrained by a Gaussian of mean 1
+	// and width the relative statistical error of that bin.
+	MCStat bool
+
+	nbins int
+	data  []float64   // data contents
+	tmpl  [][]float64 // template contents, [template][bin]
+	isig2 [][]float64 // inverse squared relative errors of the template bins, [template][bin]
+
+	fct  func(ps []float64) float64 // cost function (objective function)
+	grad func(grad, ps []float64)
+}
+
+// Len returns the number of parameters of the fit.
+func (f *Templates) Len() int {
+	n := len(f.H)
+	if f.MCStat && n > 0 {
+		n += len(f.H) * f.H[0].Len()
+	}
+	return n
+}
+
+// Nuisance returns the index into the fit parameters of the statistical
+// nuisance parameter of the i-th bin of the j-th template.
+// Nuisance returns -1 when MCStat is disabled or when there are no templates.
+func (f *Templates) Nuisance(j, i int) int {
+	if !f.MCStat || len(f.H) == 0 {
+		return -1
+	}
+	return len(f.H) + j*f.H[0].Len() + i
+}
+
+func (f *Templates) init(data *hbook.H1D) error {
+	if len(f.H) == 0 {
+		return fmt.Errorf("fit: no template histograms")
+	}
+
+	bins := data.Binning.Bins
+	f.nbins = len(bins)
+	f.data = make([]float64, f.nbins)
+	for i, bin := range bins {
+		f.data[i] = bin.SumW()
+	}
+
+	f.tmpl = make([][]float64, len(f.H))
+	f.isig2 = make([][]float64, len(f.H))
+	for j, h := range f.H {
+		if h.Len() != f.nbins {
+			return fmt.Errorf("fit: template %d has an invalid number of bins (got=%d, want=%d)", j, h.Len(), f.nbins)
+		}
+		f.tmpl[j] = make([]float64, f.nbins)
+		f.isig2[j] = make([]float64, f.nbins)
+		for i, bin := range h.Binning.Bins {
+			if bin.XMin() != bins[i].XMin() || bin.XMax() != bins[i].XMax() {
+				return fmt.Errorf("fit: template %d has an invalid binning (bin %d)", j, i)
+			}
+			sumw := bin.SumW()
+			f.tmpl[j][i] = sumw
+			if sumw2 := bin.SumW2(); sumw2 > 0 {
+				f.isig2[j][i] = sumw * sumw / sumw2
+			}
+		}
+	}
+
+	switch {
+	case f.Ps == nil:
+		f.Ps = make([]float64, len(f.H))
+		for i := range f.Ps {
+			f.Ps[i] = 1
+		}
+	case len(f.Ps) != len(f.H):
+		return fmt.Errorf("fit: invalid number of initial parameters (got=%d, want=%d)", len(f.Ps), len(f.H))
+	}
+
+	// gamma returns the nuisance parameter of the i-th bin of the j-th template.
+	gamma := func(ps []float64, j, i int) float64 {
+		if !f.MCStat || f.isig2[j][i] == 0 {
+			return 1
+		}
+		return ps[f.Nuisance(j, i)]
+	}
+
+	// expected returns the expected content of the i-th bin.
+	expected := func(ps []float64, i int) float64 {
+		var nu float64
+		for j := range f.tmpl {
+			nu += ps[j] * gamma(ps, j, i) * f.tmpl[j][i]
+		}
+		return nu
+	}
+
+	f.fct = func(ps []float64) float64 {
+		var nll float64
+		for i, n := range f.data {
+			nu := expected(ps, i)
+			switch {
+			case nu > 0:
+				// offset by the saturated model, for numerical stability.
+				nll += nu - n
+				if n > 0 {
+					nll += n * math.Log(n/nu)
+				}
+			case n > 0 || nu < 0:
+				return math.Inf(+1)
+			}
+		}
+		if f.MCStat {
+			for j := range f.tmpl {
+				for i, isig2 := range f.isig2[j] {
+					if isig2 == 0 {
+						continue
+					}
+					d := ps[f.Nuisance(j, i)] - 1
+					nll += 0.5 * d * d * isig2
+				}
+			}
+		}
+		return nll
+	}
+
+	f.grad = func(grad, ps []float64) {
+		for k := range grad {
+			grad[k] = 0
+		}
+		for i, n := range f.data {
+			nu := expected(ps, i)
+			dnu := 1.0
+			if nu > 0 {
+				dnu = 1 - n/nu
+			}
+			for j, t := range f.tmpl {
+				g := gamma(ps, j, i)
+				grad[j] += dnu * g * t[i]
+				if f.MCStat && f.isig2[j][i] != 0 {
+					grad[f.Nuisance(j, i)] += dnu * ps[j] * t[i]
+				}
+			}
+		}
+		if f.MCStat {
+			for j := range f.tmpl {
+				for i, isig2 := range f.isig2[j] {
+					if isig2 == 0 {
+						continue
+					}
+					k := f.Nuisance(j, i)
+					grad[k] += (ps[k] - 1) * isig2
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// H1DTemplates returns the binned maximum likelihood fit of the data
+// histogram with the linear combination of the provided templates.
+//
+// The first len(tmpl.H) parameters of the result are the normalization
+// factors of the templates.
+// When tmpl.MCStat is enabled, they are followed by the statistical nuisance
+// parameters of the template bins (see Templates.Nuisance).
+// Nuisance parameters of empty template bins do not contribute to the fit
+// and are left at 1.
+//
+// The data histogram is treated as a histogram of counts following Poisson
+// statistics.
+// In case settings is nil, the optimize.DefaultSettingsLocal is used, with
+// a gradient threshold of 1e-6.
+// In case m is nil, optimize.BFGS is used.
+//
+// The returned result holds the covariance matrix of all the parameters,
+// including the nuisance parameters.
+//
+// The fit can be further configured with options, e.g. to fix, bound or
+// constrain parameters (see WithFixed, WithBounds and WithConstraint).
+// Options setting the settings or the method of the minimization take
+// precedence over the settings and m arguments.
+func H1DTemplates(data *hbook.H1D, tmpl Templates, settings *optimize.Settings, m optimize.Method, opts ...Option) (*Result, error) {
+	err := tmpl.init(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings == nil {
+		settings = &optimize.Settings{
+			// the likelihood is offset by the saturated model: its
+			// gradient can not be computed much more precisely.
+			GradientThreshold: 1e-6,
+		}
+	}
+
+	if m == nil {
+		m = &optimize.BFGS{}
+	}
+
+	p0 := make([]float64, tmpl.Len())
+	copy(p0, tmpl.Ps)
+	for k := len(tmpl.Ps); k < len(p0); k++ {
+		p0[k] = 1
+	}
+
+	cfg := newConfig(append([]Option{WithSettings(settings), WithMethod(m)}, opts...))
+	prob, err := newProblem(tmpl.fct, tmpl.grad, p0, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// the nuisance parameters are constrained by the MC statistics.
+	prob.ndata = tmpl.nbins + tmpl.Len() - len(tmpl.H)
+	return prob.solve(cfg)
+}
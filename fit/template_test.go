@@ -0,0 +1,175 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"go-hep.org/x/hep/hbook"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func newTemplates(nsig, nbkg int, seed uint64) (sig, bkg *hbook.H1D) {
+	var (
+		src   = rand.New(rand.NewSource(seed))
+		gauss = distuv.Normal{Mu: 5, Sigma: 1, Src: src}
+		flat  = distuv.Uniform{Min: 0, Max: 10, Src: src}
+	)
+	sig = hbook.NewH1D(20, 0, 10)
+	for i := 0; i < nsig; i++ {
+		sig.Fill(gauss.Rand(), 1)
+	}
+	bkg = hbook.NewH1D(20, 0, 10)
+	for i := 0; i < nbkg; i++ {
+		bkg.Fill(flat.Rand(), 1)
+	}
+	return sig, bkg
+}
+
+func TestH1DTemplatesAsimov(t *testing.T) {
+	sig, bkg := newTemplates(1000, 4000, 1234)
+
+	// Asimov data set: data is exactly 2*sig + 0.5*bkg.
+	data := hbook.NewH1D(20, 0, 10)
+	for i := range data.Binning.Bins {
+		x := data.Binning.Bins[i].XMid()
+		data.Fill(x, 2*sig.Value(i)+0.5*bkg.Value(i))
+	}
+
+	for _, mcstat := range []bool{false, true} {
+		res, err := fit.H1DTemplates(
+			data,
+			fit.Templates{
+				H:      []*hbook.H1D{sig, bkg},
+				MCStat: mcstat,
+			},
+			nil, nil,
+		)
+		if err != nil {
+			t.Fatalf("mcstat=%v: could not fit templates: %+v", mcstat, err)
+		}
+
+		want := []float64{2, 0.5}
+		for i, v := range want {
+			if got := res.X[i]; !scalar.EqualWithinAbsOrRel(got, v, 1e-4, 1e-4) {
+				t.Fatalf("mcstat=%v: invalid normalization #%d: got=%v, want=%v", mcstat, i, got, v)
+			}
+		}
+
+		wantLen := 2
+		if mcstat {
+			wantLen += 2 * 20
+		}
+		if got, want := len(res.X), wantLen; got != want {
+			t.Fatalf("mcstat=%v: invalid number of parameters: got=%d, want=%d", mcstat, got, want)
+		}
+		for k := 2; k < len(res.X); k++ {
+			if got := res.X[k]; !scalar.EqualWithinAbsOrRel(got, 1, 1e-3, 1e-3) {
+				t.Fatalf("mcstat=%v: invalid nuisance #%d: got=%v, want=1", mcstat, k, got)
+			}
+		}
+	}
+}
+
+func TestH1DTemplatesMCStat(t *testing.T) {
+	// low statistics templates.
+	sig, bkg := newTemplates(100, 200, 1234)
+
+	// data generated from the true, high statistics, distributions.
+	tsig, tbkg := newTemplates(100000, 200000, 42)
+	data := hbook.NewH1D(20, 0, 10)
+	for i := range data.Binning.Bins {
+		x := data.Binning.Bins[i].XMid()
+		data.Fill(x, 3*tsig.Value(i)/1000+2*tbkg.Value(i)/1000)
+	}
+
+	tmpl := fit.Templates{
+		H:      []*hbook.H1D{sig, bkg},
+		MCStat: true,
+	}
+	res, err := fit.H1DTemplates(data, tmpl, nil, nil)
+	if err != nil {
+		t.Fatalf("could not fit templates: %+v", err)
+	}
+
+	for i, v := range []float64{3, 2} {
+		if got := res.X[i]; !scalar.EqualWithinAbsOrRel(got, v, 0.2, 0.2) {
+			t.Fatalf("invalid normalization #%d: got=%v, want=%v", i, got, v)
+		}
+	}
+
+	// nuisances must have been pulled to absorb the template fluctuations.
+	var pulled int
+	for j := range tmpl.H {
+		for i := 0; i < sig.Len(); i++ {
+			k := tmpl.Nuisance(j, i)
+			if k < 0 {
+				t.Fatalf("invalid nuisance index")
+			}
+			if res.X[k] != 1 {
+				pulled++
+			}
+		}
+	}
+	if pulled == 0 {
+		t.Fatalf("no nuisance parameter was pulled")
+	}
+
+	// the fit with nuisances must be at least as good as without.
+	nominal, err := fit.H1DTemplates(data, fit.Templates{H: tmpl.H}, nil, nil)
+	if err != nil {
+		t.Fatalf("could not fit templates w/o MC statistical nuisances: %+v", err)
+	}
+	if got, max := res.F, nominal.F; got > max {
+		t.Fatalf("invalid likelihood: got=%v > %v", got, max)
+	}
+}
+
+func TestH1DTemplatesInvalid(t *testing.T) {
+	data := hbook.NewH1D(20, 0, 10)
+	for _, tc := range []struct {
+		name string
+		tmpl fit.Templates
+	}{
+		{
+			name: "no-templates",
+		},
+		{
+			name: "invalid-nbins",
+			tmpl: fit.Templates{H: []*hbook.H1D{hbook.NewH1D(10, 0, 10)}},
+		},
+		{
+			name: "invalid-range",
+			tmpl: fit.Templates{H: []*hbook.H1D{hbook.NewH1D(20, 0, 20)}},
+		},
+		{
+			name: "invalid-ps",
+			tmpl: fit.Templates{
+				H:  []*hbook.H1D{hbook.NewH1D(20, 0, 10)},
+				Ps: []float64{1, 2},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fit.H1DTemplates(data, tc.tmpl, nil, nil)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
+
+func TestTemplatesEmpty(t *testing.T) {
+	tmpl := fit.Templates{MCStat: true}
+	if got, want := tmpl.Len(), 0; got != want {
+		t.Fatalf("invalid number of parameters: got=%d, want=%d", got, want)
+	}
+	if got, want := tmpl.Nuisance(0, 0), -1; got != want {
+		t.Fatalf("invalid nuisance index: got=%d, want=%d", got, want)
+	}
+}
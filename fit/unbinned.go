@@ -0,0 +1,215 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/integrate/quad"
+	"gonum.org/v1/gonum/mat"
+)
+
+// PDF1D describes a 1D probability density function whose parameters
+// are fitted on a sample with an unbinned maximum likelihood fit.
+type PDF1D struct {
+	// F is the (not necessarily normalized) density.
+	// ps is the slice of parameters to optimize during the fit.
+	//
+	// For extended fits, F is the density of events: its integral over the
+	// fit range is the expected number of events.
+	F func(x float64, ps []float64) float64
+
+	// Norm returns the integral of F over the fit range.
+	// If Norm is nil, the integral is computed numerically.
+	Norm func(ps []float64) float64
+
+	// N is the number of parameters to optimize during the fit.
+	// If N is 0, Ps must not be nil.
+	N int
+
+	// Ps is the initial values for the parameters.
+	// If Ps is nil, the set of initial parameters values is a slice of
+	// length N filled with zeros.
+	Ps []float64
+
+	// Min and Max define the fit range.
+	// Samples outside of the fit range are ignored.
+	Min, Max float64
+
+	// Extended enables the extended maximum likelihood fit, where the
+	// number of events is a Poisson variable whose expectation is the
+	// integral of F over the fit range.
+	Extended bool
+
+	X []float64 // samples
+	W []float64 // weights of the samples (nil for unweighted samples)
+
+	xs []float64 // samples within the fit range
+	ws []float64 // weights of the samples within the fit range
+
+	qx []float64 // quadrature locations
+	qw []float64 // quadrature weights
+
+	fct func(ps []float64) float64 // cost function (objective function)
+}
+
+// nquad is the number of points of the Gauss-Legendre quadrature used to
+// normalize densities.
+const nquad = 200
+
+func (f *PDF1D) init() error {
+	if f.Ps == nil {
+		f.Ps = make([]float64, f.N)
+	}
+
+	if len(f.Ps) == 0 {
+		return fmt.Errorf("fit: invalid number of initial parameters")
+	}
+
+	if !(f.Min < f.Max) || math.IsInf(f.Min, 0) || math.IsInf(f.Max, 0) {
+		return fmt.Errorf("fit: invalid fit range [%v, %v]", f.Min, f.Max)
+	}
+
+	if f.W != nil && len(f.W) != len(f.X) {
+		return fmt.Errorf("fit: mismatch length (samples=%d, weights=%d)", len(f.X), len(f.W))
+	}
+
+	f.xs = make([]float64, 0, len(f.X))
+	f.ws = make([]float64, 0, len(f.X))
+	for i, x := range f.X {
+		if x < f.Min || f.Max < x {
+			continue
+		}
+		w := 1.0
+		if f.W != nil {
+			w = f.W[i]
+		}
+		f.xs = append(f.xs, x)
+		f.ws = append(f.ws, w)
+	}
+
+	if f.Norm == nil {
+		f.qx = make([]float64, nquad)
+		f.qw = make([]float64, nquad)
+		quad.Legendre{}.FixedLocations(f.qx, f.qw, f.Min, f.Max)
+	}
+
+	f.fct = f.nll(f.ws)
+	return nil
+}
+
+// norm returns the integral of the density over the fit range.
+func (f *PDF1D) norm(ps []float64) float64 {
+	if f.Norm != nil {
+		return f.Norm(ps)
+	}
+	var v float64
+	for i, x := range f.qx {
+		v += f.qw[i] * f.F(x, ps)
+	}
+	return v
+}
+
+// nll returns the negative log-likelihood of the samples with the
+// provided weights.
+func (f *PDF1D) nll(ws []float64) func(ps []float64) float64 {
+	var sumw float64
+	for _, w := range ws {
+		sumw += w
+	}
+	return func(ps []float64) float64 {
+		norm := f.norm(ps)
+		if !(norm > 0) || math.IsInf(norm, 0) {
+			return math.Inf(+1)
+		}
+		var nll float64
+		for i, x := range f.xs {
+			v := f.F(x, ps)
+			if !(v > 0) {
+				return math.Inf(+1)
+			}
+			nll -= ws[i] * math.Log(v)
+		}
+		switch {
+		case f.Extended:
+			nll += norm
+		default:
+			nll += sumw * math.Log(norm)
+		}
+		return nll
+	}
+}
+
+// Unbinned returns the unbinned maximum likelihood fit of the samples
+// f.X, weighted by f.W, with the density f.F.
+//
+// The minimized cost function is the negative log-likelihood:
+//
+//	-lnL = -sum_i w_i ln(F(x_i)/norm)      (standard)
+//	-lnL = norm - sum_i w_i ln(F(x_i))     (extended)
+//
+// where norm is the integral of F over the fit range.
+//
+// For weighted samples, the covariance matrix of the result is corrected
+// as C = H^-1 H2 H^-1, where H is the Hessian matrix of the cost function
+// and H2 the one of the cost function with squared weights, so the errors
+// reflect the statistical power of the weighted sample.
+//
+// By default, the optimize.NelderMead method is used with the
+// optimize.DefaultSettingsLocal settings.
+// Parameters can be fixed, bounded or constrained with WithFixed, WithBounds
+// and WithConstraint.
+func Unbinned(f PDF1D, opts ...Option) (*Result, error) {
+	err := f.init()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newConfig(opts)
+	prob, err := newProblem(f.fct, nil, f.Ps, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := prob.solve(cfg)
+	if err != nil || f.W == nil || res.Cov == nil {
+		return res, err
+	}
+
+	ws2 := make([]float64, len(f.ws))
+	var sumw, sumw2 float64
+	for i, w := range f.ws {
+		ws2[i] = w * w
+		sumw += w
+		sumw2 += w * w
+	}
+	nll2 := f.nll(ws2)
+	if f.Extended {
+		// scale the extended term as the sum of the squared weights.
+		nll := nll2
+		nll2 = func(ps []float64) float64 {
+			norm := f.norm(ps)
+			return nll(ps) + norm*(sumw2/sumw-1)
+		}
+	}
+
+	n := len(res.X)
+	hess2 := mat.NewSymDense(n, nil)
+	hessian(hess2, prob.withConstraints(nll2), res.X)
+
+	var cov mat.Dense
+	cov.Product(res.Cov, hess2, res.Cov)
+
+	sym := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			sym.SetSym(i, j, 0.5*(cov.At(i, j)+cov.At(j, i)))
+		}
+	}
+	res.setCov(sym)
+
+	return res, nil
+}
@@ -0,0 +1,183 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fit_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/fit"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func expoSamples(n int, seed uint64) []float64 {
+	dist := distuv.Exponential{Rate: 0.5, Src: rand.New(rand.NewSource(seed))}
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = dist.Rand()
+	}
+	return xs
+}
+
+func TestUnbinned(t *testing.T) {
+	xs := expoSamples(2000, 1234)
+
+	expo := func(x float64, ps []float64) float64 {
+		return math.Exp(-ps[0] * x)
+	}
+
+	res, err := fit.Unbinned(fit.PDF1D{
+		F:   expo,
+		Ps:  []float64{1},
+		Min: 0,
+		Max: 5,
+		X:   xs,
+	})
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	if !res.Valid() {
+		t.Fatalf("invalid fit: status=%v, cov=%v", res.Status, res.CovStatus)
+	}
+
+	// same fit with an analytical normalization.
+	ana, err := fit.Unbinned(fit.PDF1D{
+		F: expo,
+		Norm: func(ps []float64) float64 {
+			return (1 - math.Exp(-5*ps[0])) / ps[0]
+		},
+		Ps:  []float64{1},
+		Min: 0,
+		Max: 5,
+		X:   xs,
+	})
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+
+	if got, want := res.X[0], ana.X[0]; !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-6) {
+		t.Fatalf("invalid rate: got=%v, want=%v", got, want)
+	}
+	if got, want := res.Errs[0], ana.Errs[0]; !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-4) {
+		t.Fatalf("invalid rate error: got=%v, want=%v", got, want)
+	}
+	if got, want := res.X[0], 0.5; math.Abs(got-want) > 3*res.Errs[0] {
+		t.Fatalf("invalid rate: got=%v +/- %v, want=%v", got, res.Errs[0], want)
+	}
+}
+
+func TestUnbinnedExtended(t *testing.T) {
+	xs := expoSamples(2000, 1234)
+	n := 0
+	for _, x := range xs {
+		if x <= 5 {
+			n++
+		}
+	}
+
+	pdf := fit.PDF1D{
+		F: func(x float64, ps []float64) float64 {
+			return ps[0] * ps[1] * math.Exp(-ps[1]*x)
+		},
+		Ps:       []float64{1000, 1},
+		Min:      0,
+		Max:      5,
+		Extended: true,
+		X:        xs,
+	}
+
+	res, err := fit.Unbinned(pdf, fit.WithBounds(0, 0, 1e5), fit.WithBounds(1, 0, 10))
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+	if !res.Valid() {
+		t.Fatalf("invalid fit: status=%v, cov=%v", res.Status, res.CovStatus)
+	}
+
+	// the fitted yield in the fit range is the number of samples.
+	yield := res.X[0] * (1 - math.Exp(-5*res.X[1]))
+	if got, want := yield, float64(n); !scalar.EqualWithinRel(got, want, 1e-4) {
+		t.Fatalf("invalid yield: got=%v, want=%v", got, want)
+	}
+	if got, want := res.X[1], 0.5; math.Abs(got-want) > 3*res.Errs[1] {
+		t.Fatalf("invalid rate: got=%v +/- %v, want=%v", got, res.Errs[1], want)
+	}
+
+	t.Run("weighted", func(t *testing.T) {
+		pdf := pdf
+		pdf.W = make([]float64, len(xs))
+		for i := range pdf.W {
+			pdf.W[i] = 2
+		}
+		pdf.Ps = []float64{2000, 1}
+
+		wgt, err := fit.Unbinned(pdf, fit.WithBounds(0, 0, 1e5), fit.WithBounds(1, 0, 10))
+		if err != nil {
+			t.Fatalf("could not fit: %+v", err)
+		}
+
+		// doubling the weights doubles the yield, and its error, but
+		// leaves the shape and its error unchanged.
+		if got, want := wgt.X[0], 2*res.X[0]; !scalar.EqualWithinRel(got, want, 1e-3) {
+			t.Fatalf("invalid yield: got=%v, want=%v", got, want)
+		}
+		if got, want := wgt.X[1], res.X[1]; !scalar.EqualWithinRel(got, want, 1e-3) {
+			t.Fatalf("invalid rate: got=%v, want=%v", got, want)
+		}
+		if got, want := wgt.Errs[0], 2*res.Errs[0]; !scalar.EqualWithinRel(got, want, 1e-2) {
+			t.Fatalf("invalid yield error: got=%v, want=%v", got, want)
+		}
+		if got, want := wgt.Errs[1], res.Errs[1]; !scalar.EqualWithinRel(got, want, 1e-2) {
+			t.Fatalf("invalid rate error: got=%v, want=%v", got, want)
+		}
+	})
+}
+
+func TestUnbinnedWeighted(t *testing.T) {
+	xs := expoSamples(2000, 1234)
+	ws := make([]float64, len(xs))
+	for i := range ws {
+		ws[i] = 0.5
+	}
+
+	pdf := fit.PDF1D{
+		F: func(x float64, ps []float64) float64 {
+			return math.Exp(-ps[0] * x)
+		},
+		Ps:  []float64{1},
+		Min: 0,
+		Max: 5,
+		X:   xs,
+	}
+
+	ref, err := fit.Unbinned(pdf)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+
+	pdf.W = ws
+	res, err := fit.Unbinned(pdf)
+	if err != nil {
+		t.Fatalf("could not fit: %+v", err)
+	}
+
+	if got, want := res.X[0], ref.X[0]; !scalar.EqualWithinRel(got, want, 1e-4) {
+		t.Fatalf("invalid rate: got=%v, want=%v", got, want)
+	}
+	if got, want := res.Errs[0], ref.Errs[0]; !scalar.EqualWithinRel(got, want, 1e-3) {
+		t.Fatalf("invalid rate error: got=%v, want=%v", got, want)
+	}
+
+	_, err = fit.Unbinned(fit.PDF1D{F: pdf.F, Ps: pdf.Ps, Min: 1, Max: 0, X: xs})
+	if err == nil {
+		t.Fatalf("expected an error for an invalid fit range")
+	}
+	_, err = fit.Unbinned(fit.PDF1D{F: pdf.F, Ps: pdf.Ps, Min: 0, Max: 5, X: xs, W: ws[:10]})
+	if err == nil {
+		t.Fatalf("expected an error for invalid weights")
+	}
+}
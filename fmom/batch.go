@@ -0,0 +1,230 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+
+package fmom
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/exp/constraints"
+)
+
+var (
+	errLength = fmt.Errorf("fmom: length mismatch")
+)
+
+// PtEtaPhiMs is a collection of Lorentz 4-vectors in the (pt, eta, phi, m)
+// basis, stored as a structure of arrays: the i-th four-vector is
+// (Pt[i], Eta[i], Phi[i], M[i]).
+//
+// This is the layout of four-vectors in ntuples, e.g. the jets of an event.
+// All slices must have the same length: operations on collections
+// panic otherwise.
+type PtEtaPhiMs[F constraints.Float] struct {
+	Pt, Eta, Phi, M []F
+}
+
+// Len returns the number of four-vectors of the collection.
+func (ps PtEtaPhiMs[F]) Len() int { return len(ps.Pt) }
+
+// At returns the i-th four-vector of the collection.
+func (ps PtEtaPhiMs[F]) At(i int) PtEtaPhiMOf[F] {
+	return PtEtaPhiMOf[F]{
+		Pt:  ps.Pt[i],
+		Eta: ps.Eta[i],
+		Phi: ps.Phi[i],
+		M:   ps.M[i],
+	}
+}
+
+// Append appends the provided four-vectors to the collection.
+func (ps *PtEtaPhiMs[F]) Append(vs ...PtEtaPhiMOf[F]) {
+	for _, v := range vs {
+		ps.Pt = append(ps.Pt, v.Pt)
+		ps.Eta = append(ps.Eta, v.Eta)
+		ps.Phi = append(ps.Phi, v.Phi)
+		ps.M = append(ps.M, v.M)
+	}
+}
+
+func (ps PtEtaPhiMs[F]) check() {
+	n := len(ps.Pt)
+	if len(ps.Eta) != n || len(ps.Phi) != n || len(ps.M) != n {
+		panic(errLength)
+	}
+}
+
+// PxPyPzEs is a collection of Lorentz 4-vectors in the (px, py, pz, e)
+// basis, stored as a structure of arrays: the i-th four-vector is
+// (Px[i], Py[i], Pz[i], E[i]).
+//
+// All slices must have the same length: operations on collections
+// panic otherwise.
+type PxPyPzEs[F constraints.Float] struct {
+	Px, Py, Pz, E []F
+}
+
+// Len returns the number of four-vectors of the collection.
+func (ps PxPyPzEs[F]) Len() int { return len(ps.Px) }
+
+// At returns the i-th four-vector of the collection.
+func (ps PxPyPzEs[F]) At(i int) PxPyPzEOf[F] {
+	return PxPyPzEOf[F]{
+		Px: ps.Px[i],
+		Py: ps.Py[i],
+		Pz: ps.Pz[i],
+		E:  ps.E[i],
+	}
+}
+
+// Append appends the provided four-vectors to the collection.
+func (ps *PxPyPzEs[F]) Append(vs ...PxPyPzEOf[F]) {
+	for _, v := range vs {
+		ps.Px = append(ps.Px, v.Px)
+		ps.Py = append(ps.Py, v.Py)
+		ps.Pz = append(ps.Pz, v.Pz)
+		ps.E = append(ps.E, v.E)
+	}
+}
+
+func (ps PxPyPzEs[F]) check() {
+	n := len(ps.Px)
+	if len(ps.Py) != n || len(ps.Pz) != n || len(ps.E) != n {
+		panic(errLength)
+	}
+}
+
+// ToPxPyPzE converts the collection to the (px, py, pz, e) basis.
+// ToPxPyPzE uses dst as work buffer, and allocates new slices if dst
+// slices are too small.
+func (ps PtEtaPhiMs[F]) ToPxPyPzE(dst PxPyPzEs[F]) PxPyPzEs[F] {
+	ps.check()
+	n := ps.Len()
+	dst.Px = resize(dst.Px, n)
+	dst.Py = resize(dst.Py, n)
+	dst.Pz = resize(dst.Pz, n)
+	dst.E = resize(dst.E, n)
+
+	var (
+		pt  = ps.Pt[:n]
+		eta = ps.Eta[:n]
+		phi = ps.Phi[:n]
+		m   = ps.M[:n]
+	)
+	for i := range pt {
+		px, py, pz, e := toPxPyPzE(float64(pt[i]), float64(eta[i]), float64(phi[i]), float64(m[i]))
+		dst.Px[i] = F(px)
+		dst.Py[i] = F(py)
+		dst.Pz[i] = F(pz)
+		dst.E[i] = F(e)
+	}
+	return dst
+}
+
+// ToPtEtaPhiM converts the collection to the (pt, eta, phi, m) basis.
+// ToPtEtaPhiM uses dst as work buffer, and allocates new slices if dst
+// slices are too small.
+func (ps PxPyPzEs[F]) ToPtEtaPhiM(dst PtEtaPhiMs[F]) PtEtaPhiMs[F] {
+	ps.check()
+	n := ps.Len()
+	dst.Pt = resize(dst.Pt, n)
+	dst.Eta = resize(dst.Eta, n)
+	dst.Phi = resize(dst.Phi, n)
+	dst.M = resize(dst.M, n)
+
+	for i := 0; i < n; i++ {
+		p := ps.At(i).PtEtaPhiM()
+		dst.Pt[i] = p.Pt
+		dst.Eta[i] = p.Eta
+		dst.Phi[i] = p.Phi
+		dst.M[i] = p.M
+	}
+	return dst
+}
+
+// DeltaRs computes the matrix of the ΔR distances between the four-vectors
+// of the collections a and b, stored in row-major order: the element (i,j)
+// at index i*b.Len()+j is the ΔR between a.At(i) and b.At(j).
+// DeltaRs uses dst as work buffer, and allocates a new slice if dst is
+// too small.
+func DeltaRs[F constraints.Float](dst []F, a, b PtEtaPhiMs[F]) []F {
+	a.check()
+	b.check()
+	var (
+		na = a.Len()
+		nb = b.Len()
+	)
+	dst = resize(dst, na*nb)
+
+	var (
+		etas = b.Eta[:nb]
+		phis = b.Phi[:nb]
+	)
+	for i := 0; i < na; i++ {
+		var (
+			eta = float64(a.Eta[i])
+			phi = float64(a.Phi[i])
+			row = dst[i*nb : (i+1)*nb]
+		)
+		for j := range row {
+			var (
+				deta = eta - float64(etas[j])
+				dphi = math.Remainder(phi-float64(phis[j]), twopi)
+			)
+			row[j] = F(math.Sqrt(deta*deta + dphi*dphi))
+		}
+	}
+	return dst
+}
+
+// InvMasses computes the invariant masses of the pairs of four-vectors of
+// the collection ps, whose indices are provided.
+// InvMasses uses dst as work buffer, and allocates a new slice if dst is
+// too small.
+func InvMasses[F constraints.Float](dst []F, ps PtEtaPhiMs[F], pairs [][2]int) []F {
+	ps.check()
+	dst = resize(dst, len(pairs))
+	for k, pair := range pairs {
+		var (
+			i, j    = pair[0], pair[1]
+			pt1     = float64(ps.Pt[i])
+			pt2     = float64(ps.Pt[j])
+			m1      = float64(ps.M[i])
+			m2      = float64(ps.M[j])
+			pz1     = pt1 * math.Sinh(float64(ps.Eta[i]))
+			pz2     = pt2 * math.Sinh(float64(ps.Eta[j]))
+			e1      = math.Sqrt(pt1*pt1 + pz1*pz1 + m1*m1)
+			e2      = math.Sqrt(pt2*pt2 + pz2*pz2 + m2*m2)
+			dphi    = float64(ps.Phi[i]) - float64(ps.Phi[j])
+			p1dotp2 = pt1*pt2*math.Cos(dphi) + pz1*pz2
+		)
+		dst[k] = F(signedSqrt(m1*m1 + m2*m2 + 2*(e1*e2-p1dotp2)))
+	}
+	return dst
+}
+
+// InvMassOf returns the invariant mass of the sum of the four-vectors of the
+// collection ps, whose indices are provided.
+func InvMassOf[F constraints.Float](ps PtEtaPhiMs[F], indices ...int) F {
+	ps.check()
+	var px, py, pz, e float64
+	for _, i := range indices {
+		x, y, z, t := toPxPyPzE(float64(ps.Pt[i]), float64(ps.Eta[i]), float64(ps.Phi[i]), float64(ps.M[i]))
+		px += x
+		py += y
+		pz += z
+		e += t
+	}
+	return F(signedSqrt(e*e - (px*px + py*py + pz*pz)))
+}
+
+func resize[F constraints.Float](vs []F, n int) []F {
+	if cap(vs) < n {
+		return make([]F, n)
+	}
+	return vs[:n]
+}
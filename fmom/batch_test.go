@@ -0,0 +1,156 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+
+package fmom
+
+import (
+	"math"
+	"testing"
+)
+
+func newBatch() PtEtaPhiMs[float64] {
+	var ps PtEtaPhiMs[float64]
+	ps.Append(
+		PtEtaPhiMOf[float64]{Pt: 50, Eta: 0.5, Phi: 3.0, M: 5},
+		PtEtaPhiMOf[float64]{Pt: 40, Eta: -1.2, Phi: -3.0, M: 0.1},
+		PtEtaPhiMOf[float64]{Pt: 30, Eta: 2.1, Phi: 0.2, M: 10},
+	)
+	return ps
+}
+
+func TestGenericP4(t *testing.T) {
+	ps := newBatch()
+	for i := 0; i < ps.Len(); i++ {
+		var (
+			p   = ps.At(i)
+			ref = p.P4()
+			v   = p.PxPyPzE()
+		)
+		if !p4equal(v.P4(), ref, 1e-12) {
+			t.Fatalf("invalid conversion:\ngot= %v\nwant=%v", v.P4(), ref)
+		}
+		if got, want := float64(v.M()), ref.M(); !cmpeq(got, want, 1e-10) {
+			t.Fatalf("invalid mass: got=%v, want=%v", got, want)
+		}
+		if got, want := v.PtEtaPhiM(), p; !cmpeq(got.Pt, want.Pt, 1e-12) ||
+			!cmpeq(got.Eta, want.Eta, 1e-12) ||
+			!cmpeq(got.Phi, want.Phi, 1e-12) ||
+			!cmpeq(got.M, want.M, 1e-10) {
+			t.Fatalf("invalid round-trip:\ngot= %v\nwant=%v", got, want)
+		}
+	}
+
+	var (
+		p1 = ps.At(0).PxPyPzE()
+		p2 = ps.At(1).PxPyPzE()
+	)
+	if got, want := float64(p1.Add(p2).M()), InvMass(p1.P4(), p2.P4()); !cmpeq(got, want, 1e-10) {
+		t.Fatalf("invalid invariant mass: got=%v, want=%v", got, want)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	ps := newBatch()
+	var ps32 PtEtaPhiMs[float32]
+	for i := 0; i < ps.Len(); i++ {
+		p := ps.At(i)
+		ps32.Append(PtEtaPhiMOf[float32]{
+			Pt:  float32(p.Pt),
+			Eta: float32(p.Eta),
+			Phi: float32(p.Phi),
+			M:   float32(p.M),
+		})
+	}
+
+	t.Run("conversion", func(t *testing.T) {
+		vs := ps.ToPxPyPzE(PxPyPzEs[float64]{})
+		if got, want := vs.Len(), ps.Len(); got != want {
+			t.Fatalf("invalid length: got=%d, want=%d", got, want)
+		}
+		for i := 0; i < vs.Len(); i++ {
+			if got, want := vs.At(i).P4(), ps.At(i).P4(); !p4equal(got, want, 1e-12) {
+				t.Fatalf("invalid conversion %d:\ngot= %v\nwant=%v", i, got, want)
+			}
+		}
+		rt := vs.ToPtEtaPhiM(PtEtaPhiMs[float64]{})
+		for i := 0; i < rt.Len(); i++ {
+			if got, want := rt.At(i).P4(), ps.At(i).P4(); !p4equal(got, want, 1e-10) {
+				t.Fatalf("invalid round-trip %d:\ngot= %v\nwant=%v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("delta-r", func(t *testing.T) {
+		drs := DeltaRs(nil, ps, ps)
+		drs32 := DeltaRs(nil, ps32, ps32)
+		for i := 0; i < ps.Len(); i++ {
+			for j := 0; j < ps.Len(); j++ {
+				want := DeltaR(ps.At(i).P4(), ps.At(j).P4())
+				if got := drs[i*ps.Len()+j]; !cmpeq(got, want, 1e-12) {
+					t.Fatalf("invalid ΔR(%d,%d): got=%v, want=%v", i, j, got, want)
+				}
+				if got := float64(drs32[i*ps.Len()+j]); !cmpeq(got, want, 1e-5) {
+					t.Fatalf("invalid float32 ΔR(%d,%d): got=%v, want=%v", i, j, got, want)
+				}
+			}
+		}
+	})
+
+	t.Run("inv-mass", func(t *testing.T) {
+		pairs := [][2]int{{0, 1}, {1, 2}, {0, 2}, {2, 0}}
+		ms := InvMasses(make([]float64, 1), ps, pairs)
+		ms32 := InvMasses(nil, ps32, pairs)
+		for k, pair := range pairs {
+			want := InvMass(ps.At(pair[0]).P4(), ps.At(pair[1]).P4())
+			if got := ms[k]; !cmpeq(got, want, 1e-10) {
+				t.Fatalf("invalid mass of pair %v: got=%v, want=%v", pair, got, want)
+			}
+			if got := float64(ms32[k]); math.Abs(got-want) > 1e-5*want {
+				t.Fatalf("invalid float32 mass of pair %v: got=%v, want=%v", pair, got, want)
+			}
+			if got := InvMassOf(ps, pair[0], pair[1]); !cmpeq(got, want, 1e-10) {
+				t.Fatalf("invalid mass of %v: got=%v, want=%v", pair, got, want)
+			}
+		}
+
+		sum := Add(Add(ps.At(0).P4(), ps.At(1).P4()), ps.At(2).P4())
+		if got, want := InvMassOf(ps, 0, 1, 2), sum.M(); !cmpeq(got, want, 1e-10) {
+			t.Fatalf("invalid 3-body mass: got=%v, want=%v", got, want)
+		}
+	})
+
+	t.Run("length-mismatch", func(t *testing.T) {
+		defer func() {
+			e := recover()
+			if e == nil {
+				t.Fatalf("expected a panic")
+			}
+			if got, want := e.(error), errLength; got != want {
+				t.Fatalf("invalid panic: got=%v, want=%v", got, want)
+			}
+		}()
+		bad := newBatch()
+		bad.M = bad.M[:1]
+		_ = DeltaRs(nil, ps, bad)
+	})
+}
+
+func BenchmarkDeltaRs(b *testing.B) {
+	var ps PtEtaPhiMs[float32]
+	for i := 0; i < 20; i++ {
+		ps.Append(PtEtaPhiMOf[float32]{
+			Pt:  float32(10 + i),
+			Eta: float32(-2.5 + 0.25*float64(i)),
+			Phi: float32(-3 + 0.3*float64(i)),
+			M:   1,
+		})
+	}
+	dst := make([]float32, ps.Len()*ps.Len())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dst = DeltaRs(dst, ps, ps)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fmom
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/spatial/r3"
+)
+
+// Frame is the rest frame of a four-vector, with its own axes.
+//
+// Frames are used in angular analyses, to express the momentum of the
+// decay products of a particle in its rest frame.
+type Frame struct {
+	Boost r3.Vec // boost vector from the laboratory frame to the frame
+
+	// X, Y and Z are the unit vectors of the axes of the frame,
+	// expressed in the laboratory axes.
+	X, Y, Z r3.Vec
+}
+
+// RestFrame returns the rest frame of the provided four-vector, with the
+// axes of the laboratory frame.
+// It panics if p isn't a timelike four-vector.
+func RestFrame(p P4) Frame {
+	return Frame{
+		Boost: r3.Scale(-1, BoostOf(p)),
+		X:     r3.Vec{X: 1},
+		Y:     r3.Vec{Y: 1},
+		Z:     r3.Vec{Z: 1},
+	}
+}
+
+// CollinsSoperFrame returns the Collins-Soper frame of the pair of
+// four-vectors p1 and p2 (e.g. the two leptons of a Drell-Yan event),
+// for beams colliding along the z axis of the laboratory frame.
+//
+// The Collins-Soper frame is the rest frame of p1+p2, whose Z axis
+// bisects the angle between the direction of the first beam and the
+// opposite of the direction of the second beam, whose Y axis is normal
+// to the plane of the beams, and whose X axis completes a right-handed
+// frame.
+// If p1+p2 has no transverse momentum, the Y axis is the y axis of the
+// laboratory frame.
+// In proton-proton collisions, the sign of the Z axis is usually chosen
+// as the sign of the longitudinal momentum of p1+p2.
+//
+// It panics if p1+p2 isn't a timelike four-vector.
+func CollinsSoperFrame(p1, p2 P4) Frame {
+	var (
+		q     = NewPxPyPzE(p1.Px()+p2.Px(), p1.Py()+p2.Py(), p1.Pz()+p2.Pz(), p1.E()+p2.E())
+		frame = RestFrame(&q)
+
+		beam1 = NewPxPyPzE(0, 0, +1, 1)
+		beam2 = NewPxPyPzE(0, 0, -1, 1)
+
+		b1 = r3.Unit(VecOf(Boost(&beam1, frame.Boost)))
+		b2 = r3.Unit(VecOf(Boost(&beam2, frame.Boost)))
+	)
+
+	frame.Z = r3.Unit(r3.Sub(b1, b2))
+	frame.Y = r3.Cross(b1, b2)
+	switch {
+	case r3.Norm2(frame.Y) == 0:
+		frame.Y = r3.Vec{Y: 1}
+	default:
+		frame.Y = r3.Unit(frame.Y)
+	}
+	frame.X = r3.Unit(r3.Cross(frame.Y, frame.Z))
+	return frame
+}
+
+// HelicityFrame returns the helicity frame of the pair of four-vectors p1
+// and p2, for beams colliding along the z axis of the laboratory frame.
+//
+// The helicity frame is the rest frame of p1+p2, whose Z axis is the
+// direction of flight of p1+p2 in the laboratory frame, whose Y axis is
+// normal to the plane of the Z axis and of the beams, and whose X axis
+// completes a right-handed frame.
+// If p1+p2 flies along the beams, the Y axis is the y axis of the
+// laboratory frame.
+//
+// It panics if p1+p2 isn't a timelike four-vector.
+func HelicityFrame(p1, p2 P4) Frame {
+	var (
+		q     = NewPxPyPzE(p1.Px()+p2.Px(), p1.Py()+p2.Py(), p1.Pz()+p2.Pz(), p1.E()+p2.E())
+		frame = RestFrame(&q)
+	)
+	if q.P2() == 0 {
+		return frame
+	}
+
+	frame.Z = r3.Unit(VecOf(&q))
+	frame.Y = r3.Cross(r3.Vec{Z: 1}, frame.Z)
+	switch {
+	case r3.Norm2(frame.Y) == 0:
+		frame.Y = r3.Vec{Y: 1}
+	default:
+		frame.Y = r3.Unit(frame.Y)
+	}
+	frame.X = r3.Unit(r3.Cross(frame.Y, frame.Z))
+	return frame
+}
+
+// Transform returns a copy of the provided four-vector boosted to the
+// frame, and whose momentum components are expressed along the axes of
+// the frame.
+func (f Frame) Transform(p P4) P4 {
+	var (
+		o   = p.Clone()
+		b   = Boost(p, f.Boost)
+		vec = VecOf(b)
+		pp  = NewPxPyPzE(r3.Dot(vec, f.X), r3.Dot(vec, f.Y), r3.Dot(vec, f.Z), b.E())
+	)
+	o.Set(&pp)
+	return o
+}
+
+// Angles returns the cosine of the polar angle and the azimuthal angle,
+// in [-pi,pi], of the momentum of the provided four-vector in the frame.
+func (f Frame) Angles(p P4) (cosTheta, phi float64) {
+	vec := VecOf(Boost(p, f.Boost))
+	norm := r3.Norm(vec)
+	if norm == 0 {
+		return 0, 0
+	}
+	cosTheta = r3.Dot(vec, f.Z) / norm
+	phi = math.Atan2(r3.Dot(vec, f.Y), r3.Dot(vec, f.X))
+	return cosTheta, phi
+}
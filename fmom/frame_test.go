@@ -0,0 +1,167 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fmom
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/spatial/r3"
+)
+
+func TestBoostToCM(t *testing.T) {
+	var (
+		p1 = NewPxPyPzE(10, 20, 30, 50)
+		p2 = NewPtEtaPhiM(40, 1.5, -2, 5)
+
+		c1, c2 = BoostToCM(&p1, &p2)
+		sum    = VecOf(Add(c1, c2))
+	)
+	if got := r3.Norm(sum); !cmpeq(got, 0, 1e-12) {
+		t.Fatalf("invalid center-of-mass momentum: got=%v", sum)
+	}
+	if got, want := InvMass(c1, c2), InvMass(&p1, &p2); !cmpeq(got, want, 1e-12) {
+		t.Fatalf("invalid invariant mass: got=%v, want=%v", got, want)
+	}
+	if got, want := c1.M(), p1.M(); !cmpeq(got, want, 1e-12) {
+		t.Fatalf("invalid mass: got=%v, want=%v", got, want)
+	}
+	if _, ok := c2.(*PtEtaPhiM); !ok {
+		t.Fatalf("invalid four-vector type: %T", c2)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	p := NewPxPyPzE(1, 0, 3, 10)
+
+	got := Rotate(&p, r3.Vec{Z: 1}, 0.5*math.Pi)
+	want := NewPxPyPzE(0, 1, 3, 10)
+	if !p4equal(got, &want, 1e-14) {
+		t.Fatalf("invalid rotation:\ngot= %v\nwant=%v", got, &want)
+	}
+
+	got = Rotate(&p, r3.Vec{X: 1, Y: 1, Z: 1}, 2*math.Pi/3)
+	want = NewPxPyPzE(3, 1, 0, 10)
+	if !p4equal(got, &want, 1e-14) {
+		t.Fatalf("invalid rotation:\ngot= %v\nwant=%v", got, &want)
+	}
+
+	if got, want := Rotate(&p, r3.Vec{Z: 1}, 0), &p; !Equal(got, want) {
+		t.Fatalf("invalid null rotation:\ngot= %v\nwant=%v", got, want)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	// decay products back-to-back in the rest frame, at polar angle theta
+	// and azimuthal angle phi.
+	decay := func(m, theta, phi float64) (PxPyPzE, PxPyPzE) {
+		var (
+			e  = 0.5 * m
+			st = math.Sin(theta)
+			p1 = NewPxPyPzE(+e*st*math.Cos(phi), +e*st*math.Sin(phi), +e*math.Cos(theta), e)
+			p2 = NewPxPyPzE(-e*st*math.Cos(phi), -e*st*math.Sin(phi), -e*math.Cos(theta), e)
+		)
+		return p1, p2
+	}
+
+	const (
+		theta = 0.7
+		phi   = 1.2
+	)
+
+	for _, tc := range []struct {
+		name  string
+		frame func(p1, p2 P4) Frame
+		boost r3.Vec
+		cos   float64
+		phi   float64
+	}{
+		{
+			name:  "collins-soper",
+			frame: CollinsSoperFrame,
+			boost: r3.Vec{Z: 0.6},
+			cos:   math.Cos(theta),
+			phi:   phi,
+		},
+		{
+			name:  "helicity",
+			frame: HelicityFrame,
+			boost: r3.Vec{Z: 0.6},
+			cos:   math.Cos(theta),
+			phi:   phi,
+		},
+		{
+			name:  "helicity-transverse",
+			frame: HelicityFrame,
+			boost: r3.Vec{X: 0.6},
+			// Z axis along x, Y axis along y, X axis along -z.
+			cos: math.Sin(theta) * math.Cos(phi),
+			phi: math.Atan2(math.Sin(theta)*math.Sin(phi), -math.Cos(theta)),
+		},
+		{
+			name:  "collins-soper-transverse",
+			frame: CollinsSoperFrame,
+			boost: r3.Vec{X: 0.3, Y: 0.2, Z: -0.5},
+			cos:   math.NaN(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r1, r2 := decay(91, theta, phi)
+			var (
+				p1 = Boost(&r1, tc.boost)
+				p2 = Boost(&r2, tc.boost)
+				f  = tc.frame(p1, p2)
+			)
+
+			for _, v := range []struct {
+				name string
+				got  float64
+				want float64
+			}{
+				{"X.X", r3.Dot(f.X, f.X), 1},
+				{"Y.Y", r3.Dot(f.Y, f.Y), 1},
+				{"Z.Z", r3.Dot(f.Z, f.Z), 1},
+				{"X.Y", r3.Dot(f.X, f.Y), 0},
+				{"Y.Z", r3.Dot(f.Y, f.Z), 0},
+				{"Z.X", r3.Dot(f.Z, f.X), 0},
+				{"X^Y.Z", r3.Dot(r3.Cross(f.X, f.Y), f.Z), 1},
+			} {
+				if !cmpeq(v.got, v.want, 1e-12) {
+					t.Fatalf("invalid frame axes %s: got=%v, want=%v", v.name, v.got, v.want)
+				}
+			}
+
+			q := f.Transform(Add(p1, p2))
+			if got := q.P(); !cmpeq(got, 0, 1e-10) {
+				t.Fatalf("invalid momentum in rest frame: got=%v", got)
+			}
+			if got, want := q.E(), 91.0; !cmpeq(got, want, 1e-10) {
+				t.Fatalf("invalid energy in rest frame: got=%v, want=%v", got, want)
+			}
+
+			cos1, phi1 := f.Angles(p1)
+			cos2, phi2 := f.Angles(p2)
+			if !cmpeq(cos1, -cos2, 1e-12) {
+				t.Fatalf("decay products not back-to-back: cos1=%v, cos2=%v", cos1, cos2)
+			}
+			if got := math.Abs(math.Remainder(phi1-phi2, 2*math.Pi)); !cmpeq(got, math.Pi, 1e-12) {
+				t.Fatalf("decay products not back-to-back: phi1=%v, phi2=%v", phi1, phi2)
+			}
+			if got := f.Transform(p1); !cmpeq(got.CosTh(), cos1, 1e-12) {
+				t.Fatalf("invalid transformed polar angle: got=%v, want=%v", got.CosTh(), cos1)
+			}
+
+			if math.IsNaN(tc.cos) {
+				return
+			}
+			if got, want := cos1, tc.cos; !cmpeq(got, want, 1e-12) {
+				t.Fatalf("invalid cos(theta): got=%v, want=%v", got, want)
+			}
+			if got, want := phi1, tc.phi; !cmpeq(got, want, 1e-12) {
+				t.Fatalf("invalid phi: got=%v, want=%v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+
+package fmom
+
+import (
+	"math"
+
+	"golang.org/x/exp/constraints"
+)
+
+// PxPyPzEOf is a Lorentz 4-vector in the (px, py, pz, e) basis, with
+// components of floating-point type F.
+//
+// Computations are carried in float64.
+type PxPyPzEOf[F constraints.Float] struct {
+	Px, Py, Pz, E F
+}
+
+// PtEtaPhiMOf is a Lorentz 4-vector in the (pt, eta, phi, m) basis, with
+// components of floating-point type F.
+//
+// Computations are carried in float64.
+type PtEtaPhiMOf[F constraints.Float] struct {
+	Pt, Eta, Phi, M F
+}
+
+// Add returns the sum p+o.
+func (p PxPyPzEOf[F]) Add(o PxPyPzEOf[F]) PxPyPzEOf[F] {
+	return PxPyPzEOf[F]{
+		Px: p.Px + o.Px,
+		Py: p.Py + o.Py,
+		Pz: p.Pz + o.Pz,
+		E:  p.E + o.E,
+	}
+}
+
+// M2 returns the mass squared of the four-vector.
+func (p PxPyPzEOf[F]) M2() F {
+	var (
+		px = float64(p.Px)
+		py = float64(p.Py)
+		pz = float64(p.Pz)
+		e  = float64(p.E)
+	)
+	return F(e*e - (px*px + py*py + pz*pz))
+}
+
+// M returns the mass of the four-vector.
+// M is negative for space-like four-vectors.
+func (p PxPyPzEOf[F]) M() F {
+	return F(signedSqrt(float64(p.M2())))
+}
+
+// PtEtaPhiM returns the four-vector in the (pt, eta, phi, m) basis.
+func (p PxPyPzEOf[F]) PtEtaPhiM() PtEtaPhiMOf[F] {
+	var (
+		v = NewPxPyPzE(float64(p.Px), float64(p.Py), float64(p.Pz), float64(p.E))
+		o PtEtaPhiM
+	)
+	o.Set(&v)
+	return PtEtaPhiMOf[F]{
+		Pt:  F(o.Pt()),
+		Eta: F(o.Eta()),
+		Phi: F(o.Phi()),
+		M:   F(o.M()),
+	}
+}
+
+// P4 returns the four-vector as a float64 P4.
+func (p PxPyPzEOf[F]) P4() P4 {
+	v := NewPxPyPzE(float64(p.Px), float64(p.Py), float64(p.Pz), float64(p.E))
+	return &v
+}
+
+// PxPyPzE returns the four-vector in the (px, py, pz, e) basis.
+func (p PtEtaPhiMOf[F]) PxPyPzE() PxPyPzEOf[F] {
+	px, py, pz, e := toPxPyPzE(float64(p.Pt), float64(p.Eta), float64(p.Phi), float64(p.M))
+	return PxPyPzEOf[F]{
+		Px: F(px),
+		Py: F(py),
+		Pz: F(pz),
+		E:  F(e),
+	}
+}
+
+// P4 returns the four-vector as a float64 P4.
+func (p PtEtaPhiMOf[F]) P4() P4 {
+	v := NewPtEtaPhiM(float64(p.Pt), float64(p.Eta), float64(p.Phi), float64(p.M))
+	return &v
+}
+
+// toPxPyPzE converts (pt, eta, phi, m) components into (px, py, pz, e).
+func toPxPyPzE(pt, eta, phi, m float64) (px, py, pz, e float64) {
+	sin, cos := math.Sincos(phi)
+	px = pt * cos
+	py = pt * sin
+	pz = pt * math.Sinh(eta)
+	e = math.Sqrt(pt*pt + pz*pz + m*m)
+	return px, py, pz, e
+}
@@ -0,0 +1,278 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fmom
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+// TransverseMass returns the transverse mass of the system of the two
+// provided four-vectors, e.g. a lepton and the missing transverse momentum
+// of a W decay:
+//
+//	mT² = (Et1+Et2)² - |pT1+pT2|²
+//
+// where Et=sqrt(m²+pT²).
+func TransverseMass(p1, p2 P4) float64 {
+	var (
+		et1 = transverseEnergy(p1.M(), p1.Px(), p1.Py())
+		et2 = transverseEnergy(p2.M(), p2.Px(), p2.Py())
+		px  = p1.Px() + p2.Px()
+		py  = p1.Py() + p2.Py()
+		et  = et1 + et2
+	)
+	return signedSqrt(et*et - px*px - py*py)
+}
+
+// MT2 returns the stransverse mass of a pair of decays, each with a visible
+// four-vector (p1 and p2), and an invisible particle of mass m:
+//
+//	mT2 = min_{q1+q2=met} max(mT(p1, q1), mT(p2, q2))
+//
+// where the minimum is taken over all the splittings of the missing
+// transverse momentum met between the two invisible particles.
+// Only the transverse components of met are used.
+//
+// MT2 computes the stransverse mass as the maximum over λ in [0,1] of the
+// minimum over q1 of λ·mT²(p1, q1) + (1-λ)·mT²(p2, met-q1), which is
+// equivalent, as both squared transverse masses are convex in q1.
+func MT2(p1, p2, met P4, m float64) float64 {
+	var (
+		v1 = newMT2Side(p1, m)
+		v2 = newMT2Side(p2, m)
+
+		mx = met.Px()
+		my = met.Py()
+		q2 = make([]float64, 2)
+
+		lambda float64
+		x0     = []float64{0.5 * mx, 0.5 * my}
+	)
+
+	p := optimize.Problem{
+		Func: func(q []float64) float64 {
+			q2[0] = mx - q[0]
+			q2[1] = my - q[1]
+			return lambda*v1.mt2(q) + (1-lambda)*v2.mt2(q2)
+		},
+		Grad: func(grad, q []float64) {
+			q2[0] = mx - q[0]
+			q2[1] = my - q[1]
+			var (
+				gx1, gy1 = v1.grad(q)
+				gx2, gy2 = v2.grad(q2)
+			)
+			grad[0] = lambda*gx1 - (1-lambda)*gx2
+			grad[1] = lambda*gy1 - (1-lambda)*gy2
+		},
+	}
+
+	scale := v1.et + v2.et + math.Hypot(mx, my) + m
+	settings := &optimize.Settings{
+		GradientThreshold: 1e-12 * scale,
+	}
+
+	// inner returns the minimum over q1 of the combination of the squared
+	// transverse masses for a given λ.
+	inner := func(l float64) float64 {
+		lambda = l
+		res, err := optimize.Minimize(p, x0, settings, &optimize.BFGS{})
+		if res == nil {
+			panic(fmt.Errorf("fmom: could not compute mT2: %w", err))
+		}
+		if err == nil {
+			copy(x0, res.X)
+		}
+		return res.F
+	}
+
+	// golden-section search of the maximum of the concave inner function.
+	const (
+		tol  = 1e-10
+		iphi = 0.6180339887498949 // 1/golden-ratio
+	)
+	var (
+		a, b = 0.0, 1.0
+		c    = b - iphi*(b-a)
+		d    = a + iphi*(b-a)
+		fc   = inner(c)
+		fd   = inner(d)
+	)
+	for b-a > tol {
+		switch {
+		case fc > fd:
+			b, d, fd = d, c, fc
+			c = b - iphi*(b-a)
+			fc = inner(c)
+		default:
+			a, c, fc = c, d, fd
+			d = a + iphi*(b-a)
+			fd = inner(d)
+		}
+	}
+	return signedSqrt(math.Max(fc, fd))
+}
+
+// mt2Side is one of the two decays of an mT2 computation.
+type mt2Side struct {
+	m2   float64 // squared mass of the visible particle
+	chi2 float64 // squared mass of the invisible particle
+	et   float64 // transverse energy of the visible particle
+	px   float64
+	py   float64
+}
+
+func newMT2Side(p P4, m float64) mt2Side {
+	var (
+		mv = p.M()
+		px = p.Px()
+		py = p.Py()
+	)
+	return mt2Side{
+		m2:   mv * mv,
+		chi2: m * m,
+		et:   transverseEnergy(mv, px, py),
+		px:   px,
+		py:   py,
+	}
+}
+
+// mt2 returns the squared transverse mass of the visible particle with an
+// invisible particle of transverse momentum q.
+func (s mt2Side) mt2(q []float64) float64 {
+	eq := math.Sqrt(s.chi2 + q[0]*q[0] + q[1]*q[1])
+	return s.m2 + s.chi2 + 2*(s.et*eq-s.px*q[0]-s.py*q[1])
+}
+
+// grad returns the gradient of the squared transverse mass with respect to
+// the transverse momentum q of the invisible particle.
+func (s mt2Side) grad(q []float64) (float64, float64) {
+	eq := math.Sqrt(s.chi2 + q[0]*q[0] + q[1]*q[1])
+	if eq == 0 {
+		return -2 * s.px, -2 * s.py
+	}
+	return 2 * (s.et*q[0]/eq - s.px), 2 * (s.et*q[1]/eq - s.py)
+}
+
+// CollinearMass returns the invariant mass of a pair of decays (e.g. a pair
+// of tau leptons), with the visible four-vectors p1 and p2, in the collinear
+// approximation: the invisible decay products are assumed to be collinear
+// with the visible ones, and to make up all the missing transverse momentum
+// met.
+//
+// CollinearMass also returns the fractions x1 and x2 of the momenta of the
+// decaying particles carried by the visible ones:
+//
+//	m = m(p1, p2) / sqrt(x1*x2)
+//
+// CollinearMass returns an error if p1 and p2 are collinear in the
+// transverse plane, or if the fractions are not positive.
+// Fractions larger than 1 (i.e. unphysical) are not rejected.
+func CollinearMass(p1, p2, met P4) (m, x1, x2 float64, err error) {
+	var (
+		px1 = p1.Px()
+		py1 = p1.Py()
+		px2 = p2.Px()
+		py2 = p2.Py()
+		det = px1*py2 - py1*px2
+	)
+	if det == 0 {
+		return 0, 0, 0, fmt.Errorf("fmom: collinear visible four-vectors")
+	}
+
+	// met = r1*pT1 + r2*pT2, with r=1/x-1.
+	var (
+		r1 = (met.Px()*py2 - met.Py()*px2) / det
+		r2 = (px1*met.Py() - py1*met.Px()) / det
+	)
+	x1 = 1 / (1 + r1)
+	x2 = 1 / (1 + r2)
+	if !(x1 > 0 && x2 > 0) {
+		return 0, x1, x2, fmt.Errorf("fmom: invalid collinear momentum fractions (x1=%v, x2=%v)", x1, x2)
+	}
+
+	m = InvMass(p1, p2) / math.Sqrt(x1*x2)
+	return m, x1, x2, nil
+}
+
+// InvMassN returns the invariant mass of the sum of the provided
+// four-vectors.
+func InvMassN(ps ...P4) float64 {
+	var sum PxPyPzE
+	for _, p := range ps {
+		sum.P4.X += p.Px()
+		sum.P4.Y += p.Py()
+		sum.P4.Z += p.Pz()
+		sum.P4.T += p.E()
+	}
+	return sum.M()
+}
+
+// Combinations calls f with the indices, in increasing order, and the
+// invariant mass of each combination of k four-vectors among ps.
+// The indices slice is reused between calls.
+// Combinations stops when f returns false.
+func Combinations(ps []P4, k int, f func(indices []int, m float64) bool) {
+	n := len(ps)
+	if k <= 0 || k > n {
+		return
+	}
+	var (
+		idx = make([]int, k)
+		sub = make([]P4, k)
+	)
+	for i := range idx {
+		idx[i] = i
+	}
+	for {
+		for i, j := range idx {
+			sub[i] = ps[j]
+		}
+		if !f(idx, InvMassN(sub...)) {
+			return
+		}
+
+		// next combination, in lexicographic order.
+		i := k - 1
+		for i >= 0 && idx[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
+
+// ClosestInvMass returns the indices of the combination of k four-vectors
+// among ps whose invariant mass is the closest to the target mass, and its
+// invariant mass (e.g. to reconstruct a W boson from a pair of jets).
+// ClosestInvMass returns nil if there are fewer than k four-vectors.
+func ClosestInvMass(ps []P4, k int, target float64) ([]int, float64) {
+	var (
+		best []int
+		mass float64
+		dist = math.Inf(+1)
+	)
+	Combinations(ps, k, func(idx []int, m float64) bool {
+		if d := math.Abs(m - target); d < dist {
+			dist = d
+			mass = m
+			best = append(best[:0], idx...)
+		}
+		return true
+	})
+	return best, mass
+}
+
+func transverseEnergy(m, px, py float64) float64 {
+	return math.Sqrt(m*m + px*px + py*py)
+}
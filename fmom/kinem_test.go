@@ -0,0 +1,204 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fmom
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTransverseMass(t *testing.T) {
+	var (
+		lep = NewPtEtaPhiM(40, 1.2, 0.3, 0)
+		met = NewPtEtaPhiM(30, 0, 0.3+2, 0)
+	)
+	want := math.Sqrt(2 * 40 * 30 * (1 - math.Cos(2)))
+	if got := TransverseMass(&lep, &met); !cmpeq(got, want, 1e-12) {
+		t.Fatalf("invalid transverse mass: got=%v, want=%v", got, want)
+	}
+
+	// the transverse mass of a particle at rest in the transverse plane
+	// is its mass.
+	p := NewPxPyPzE(0, 0, 30, 50)
+	var zero PxPyPzE
+	if got, want := TransverseMass(&p, &zero), p.M(); !cmpeq(got, want, 1e-12) {
+		t.Fatalf("invalid transverse mass: got=%v, want=%v", got, want)
+	}
+}
+
+func TestMT2(t *testing.T) {
+	// brute-force mT2 over a grid of splittings, refined around its minimum.
+	bruteMT2 := func(p1, p2, met P4, m float64) float64 {
+		var (
+			mt = func(qx, qy float64) float64 {
+				var (
+					q1 = NewPxPyPzE(qx, qy, 0, math.Sqrt(m*m+qx*qx+qy*qy))
+					q2 = NewPxPyPzE(met.Px()-qx, met.Py()-qy, 0, 0)
+				)
+				q2.P4.T = math.Sqrt(m*m + q2.P4.X*q2.P4.X + q2.P4.Y*q2.P4.Y)
+				return math.Max(TransverseMass(p1, &q1), TransverseMass(p2, &q2))
+			}
+			cx, cy = 0.5 * met.Px(), 0.5 * met.Py()
+			width  = 500.0
+			best   = math.Inf(+1)
+		)
+		for iter := 0; iter < 40; iter++ {
+			bx, by := cx, cy
+			for i := -20; i <= 20; i++ {
+				for j := -20; j <= 20; j++ {
+					x := cx + width*float64(i)/20
+					y := cy + width*float64(j)/20
+					if v := mt(x, y); v < best {
+						best, bx, by = v, x, y
+					}
+				}
+			}
+			cx, cy = bx, by
+			width *= 0.5
+		}
+		return best
+	}
+
+	for _, tc := range []struct {
+		name   string
+		p1, p2 P4
+		met    P4
+		m      float64
+	}{
+		{
+			name: "massless",
+			p1:   newPtEtaPhiM(NewPxPyPzE(30, 10, 5, 40)),
+			p2:   newPtEtaPhiM(NewPxPyPzE(-20, 25, -10, 50)),
+			met:  newPxPyPzE(NewPxPyPzE(15, -30, 0, 0)),
+		},
+		{
+			name: "massive",
+			p1:   newPxPyPzE(NewPxPyPzE(30, 10, 5, 40)),
+			p2:   newPxPyPzE(NewPxPyPzE(-20, 25, -10, 50)),
+			met:  newPxPyPzE(NewPxPyPzE(15, -30, 0, 0)),
+			m:    50,
+		},
+		{
+			name: "unbalanced",
+			p1:   newPxPyPzE(NewPxPyPzE(100, 0, 0, 150)),
+			p2:   newPxPyPzE(NewPxPyPzE(0, 5, 0, 6)),
+			met:  newPxPyPzE(NewPxPyPzE(-10, -2, 0, 0)),
+			m:    10,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MT2(tc.p1, tc.p2, tc.met, tc.m)
+			want := bruteMT2(tc.p1, tc.p2, tc.met, tc.m)
+			if !cmpeq(got, want, 1e-6*want) {
+				t.Fatalf("invalid mT2: got=%v, want=%v", got, want)
+			}
+			if lo := math.Max(tc.p1.M(), tc.p2.M()) + tc.m; got < lo*(1-1e-9) {
+				t.Fatalf("mT2 below its lower bound: got=%v, min=%v", got, lo)
+			}
+		})
+	}
+
+	// massless visible and invisible particles, without upstream transverse
+	// momentum: mT2² = 2(pT1·pT2 + |pT1||pT2|).
+	var (
+		p1  = NewPxPyPzE(30, 10, 5, math.Sqrt(30*30+10*10+5*5))
+		p2  = NewPxPyPzE(-20, 25, -10, math.Sqrt(20*20+25*25+10*10))
+		met = NewPxPyPzE(-p1.Px()-p2.Px(), -p1.Py()-p2.Py(), 0, 0)
+	)
+	want := math.Sqrt(2 * (p1.Px()*p2.Px() + p1.Py()*p2.Py() + p1.Pt()*p2.Pt()))
+	if got := MT2(&p1, &p2, &met, 0); !cmpeq(got, want, 1e-6*want) {
+		t.Fatalf("invalid mT2: got=%v, want=%v", got, want)
+	}
+}
+
+func TestCollinearMass(t *testing.T) {
+	var (
+		tau1 = NewPtEtaPhiM(60, 0.5, 0.2, 0)
+		tau2 = NewPtEtaPhiM(45, -0.3, 2.5, 0)
+
+		vis1 = Scale(0.7, &tau1)
+		vis2 = Scale(0.4, &tau2)
+		inv1 = Scale(0.3, &tau1)
+		inv2 = Scale(0.6, &tau2)
+		met  = Add(inv1, inv2)
+	)
+
+	m, x1, x2, err := CollinearMass(vis1, vis2, met)
+	if err != nil {
+		t.Fatalf("could not compute collinear mass: %+v", err)
+	}
+	if !cmpeq(x1, 0.7, 1e-12) || !cmpeq(x2, 0.4, 1e-12) {
+		t.Fatalf("invalid fractions: got=(%v, %v), want=(0.7, 0.4)", x1, x2)
+	}
+	if got, want := m, InvMass(&tau1, &tau2); !cmpeq(got, want, 1e-10) {
+		t.Fatalf("invalid collinear mass: got=%v, want=%v", got, want)
+	}
+
+	_, _, _, err = CollinearMass(vis1, Scale(2, vis1), met)
+	if err == nil {
+		t.Fatalf("expected an error for collinear visible four-vectors")
+	}
+	_, _, _, err = CollinearMass(vis1, vis2, Scale(-10, met))
+	if err == nil {
+		t.Fatalf("expected an error for negative fractions")
+	}
+}
+
+func TestInvMassCombinations(t *testing.T) {
+	var (
+		j1 = NewPtEtaPhiM(50, 0.1, 0.0, 5)
+		j2 = NewPtEtaPhiM(40, -0.5, 2.5, 5)
+		j3 = NewPtEtaPhiM(30, 1.0, -1.0, 5)
+		j4 = NewPtEtaPhiM(20, 2.0, 1.5, 5)
+		ps = []P4{&j1, &j2, &j3, &j4}
+	)
+
+	sum := Add(Add(&j1, &j2), &j3)
+	if got, want := InvMassN(&j1, &j2, &j3), sum.M(); !cmpeq(got, want, 1e-10) {
+		t.Fatalf("invalid 3-body mass: got=%v, want=%v", got, want)
+	}
+	if got, want := InvMassN(&j1, &j2), InvMass(&j1, &j2); !cmpeq(got, want, 1e-10) {
+		t.Fatalf("invalid 2-body mass: got=%v, want=%v", got, want)
+	}
+
+	var combs [][]int
+	Combinations(ps, 3, func(idx []int, m float64) bool {
+		var sub []P4
+		for _, i := range idx {
+			sub = append(sub, ps[i])
+		}
+		if want := InvMassN(sub...); m != want {
+			t.Fatalf("invalid mass of %v: got=%v, want=%v", idx, m, want)
+		}
+		combs = append(combs, append([]int(nil), idx...))
+		return true
+	})
+	want := [][]int{{0, 1, 2}, {0, 1, 3}, {0, 2, 3}, {1, 2, 3}}
+	if !reflect.DeepEqual(combs, want) {
+		t.Fatalf("invalid combinations:\ngot= %v\nwant=%v", combs, want)
+	}
+
+	n := 0
+	Combinations(ps, 2, func([]int, float64) bool {
+		n++
+		return n < 2
+	})
+	if n != 2 {
+		t.Fatalf("invalid number of combinations after stop: got=%d, want=2", n)
+	}
+
+	target := InvMass(&j2, &j4)
+	idx, m := ClosestInvMass(ps, 2, target+0.1)
+	if got, want := idx, []int{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid closest combination: got=%v, want=%v", got, want)
+	}
+	if m != target {
+		t.Fatalf("invalid closest mass: got=%v, want=%v", m, target)
+	}
+	if idx, _ := ClosestInvMass(ps, 5, target); idx != nil {
+		t.Fatalf("invalid closest combination: got=%v, want=nil", idx)
+	}
+}
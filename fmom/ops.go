@@ -200,3 +200,30 @@ func VecOf(p P4) r3.Vec {
 		Z: p.Pz(),
 	}
 }
+
+// BoostToCM returns copies of the provided four-vectors boosted to
+// their center-of-mass frame.
+// It panics if p1+p2 isn't a timelike four-vector.
+func BoostToCM(p1, p2 P4) (P4, P4) {
+	var (
+		sum = NewPxPyPzE(p1.Px()+p2.Px(), p1.Py()+p2.Py(), p1.Pz()+p2.Pz(), p1.E()+p2.E())
+		vec = r3.Scale(-1, BoostOf(&sum))
+	)
+	return Boost(p1, vec), Boost(p2, vec)
+}
+
+// Rotate returns a copy of the provided four-vector whose momentum
+// is rotated by angle (in radians) around the provided axis.
+// The rotation follows the right-hand rule.
+func Rotate(p P4, axis r3.Vec, angle float64) P4 {
+	o := p.Clone()
+	if angle == 0 {
+		return o
+	}
+
+	vec := r3.Rotate(VecOf(p), angle, axis)
+	pp := NewPxPyPzE(vec.X, vec.Y, vec.Z, p.E())
+	o.Set(&pp)
+
+	return o
+}
@@ -47,3 +47,10 @@ func CosTheta(p1, p2 P4) float64 {
 	cosTh := dot / math.Sqrt(mag1*mag2)
 	return cosTh
 }
+
+func signedSqrt(v float64) float64 {
+	if v < 0 {
+		return -math.Sqrt(-v)
+	}
+	return math.Sqrt(v)
+}
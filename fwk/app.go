@@ -26,8 +26,20 @@ type appmgr struct {
 	store *datastore
 	msg   msgstream
 
-	evtmax int64
-	nprocs int
+	evtmax   int64
+	nprocs   int
+	nthreads int           // maximum number of concurrent tasks per event
+	evttmo   time.Duration // per-event time budget
+
+	quarantine quarantine // events aborted after exceeding their time budget
+
+	ckptfile  string        // path to the checkpoint file
+	ckptevery int64         // number of processed events between checkpoints
+	resume    bool          // whether to resume the job from its checkpoint
+	ckpt      *checkpointer // saves the state of the job
+	ievt0     int64         // index of the first event to process
+
+	mons []Monitor // services monitoring the event loop
 
 	comps   map[string]Component
 	tsks    []Task
@@ -37,6 +49,27 @@ type appmgr struct {
 }
 
 // NewApp creates a (default) fwk application with (default and) sensible options.
+//
+// The "EvtTimeout" property (a time.Duration) sets the time budget of each
+// event: events exceeding it are aborted and quarantined (see Quarantiner),
+// and processing continues with the next event.
+// The event loop does not wait for the aborted tasks to return.
+//
+// Tasks of an event are scheduled following the data dependencies declared
+// with their input and output ports: a task is started once all the
+// producers of its inputs have completed, and independent tasks run
+// concurrently. The "NThreads" property (an int) sets the maximum number
+// of tasks running concurrently for each event (no limit if <= 0, the
+// default), while the "NProcs" property sets the number of events
+// processed concurrently.
+//
+// The "Checkpoint" property (a string) sets the path to a file where the
+// state of the job (the number of processed events and the states of the
+// components implementing Checkpointer) is saved every "CheckpointEvery"
+// (an int64, 1000 by default) events, and at the end of the event loop.
+// When the "Resume" property (a bool) is true, the job restores its state
+// from that file and skips the events already processed: these events are
+// still read from the input stream, but not processed by the tasks.
 func NewApp() App {
 
 	var err error
@@ -57,11 +90,14 @@ func NewApp() App {
 			//LvlError,
 			nil,
 		),
-		evtmax: -1,
-		nprocs: -1,
-		comps:  make(map[string]Component),
-		tsks:   make([]Task, 0),
-		svcs:   make([]Svc, 0),
+		evtmax:   -1,
+		nprocs:   -1,
+		nthreads: 0,
+
+		ckptevery: 1000,
+		comps:     make(map[string]Component),
+		tsks:      make([]Task, 0),
+		svcs:      make([]Svc, 0),
 	}
 
 	svc, err := app.New("go-hep.org/x/hep/fwk.datastore", "evtstore")
@@ -102,12 +138,42 @@ func NewApp() App {
 		return nil
 	}
 
+	err = app.DeclProp(app, "NThreads", &app.nthreads)
+	if err != nil {
+		app.msg.Errorf("fwk.NewApp: could not declare property 'NThreads': %w\n", err)
+		return nil
+	}
+
+	err = app.DeclProp(app, "Checkpoint", &app.ckptfile)
+	if err != nil {
+		app.msg.Errorf("fwk.NewApp: could not declare property 'Checkpoint': %w\n", err)
+		return nil
+	}
+
+	err = app.DeclProp(app, "CheckpointEvery", &app.ckptevery)
+	if err != nil {
+		app.msg.Errorf("fwk.NewApp: could not declare property 'CheckpointEvery': %w\n", err)
+		return nil
+	}
+
+	err = app.DeclProp(app, "Resume", &app.resume)
+	if err != nil {
+		app.msg.Errorf("fwk.NewApp: could not declare property 'Resume': %w\n", err)
+		return nil
+	}
+
 	err = app.DeclProp(app, "MsgLevel", &app.msg.lvl)
 	if err != nil {
 		app.msg.Errorf("fwk.NewApp: could not declare property 'MsgLevel': %w\n", err)
 		return nil
 	}
 
+	err = app.DeclProp(app, "EvtTimeout", &app.evttmo)
+	if err != nil {
+		app.msg.Errorf("fwk.NewApp: could not declare property 'EvtTimeout': %w\n", err)
+		return nil
+	}
+
 	return app
 }
 
@@ -409,6 +475,12 @@ func (app *appmgr) Run() error {
 	return err
 }
 
+// Quarantine returns the sorted list of IDs of the events whose processing
+// was aborted because it exceeded the EvtTimeout time budget.
+func (app *appmgr) Quarantine() []int64 {
+	return app.quarantine.list()
+}
+
 func (app *appmgr) Scripter() Scripter {
 	return &irunner{app}
 }
@@ -514,6 +586,30 @@ func (app *appmgr) run(ctx Context) error {
 	defer app.msg.flush()
 	app.state = fsm.Running
 
+	app.ievt0 = 0
+	if app.resume {
+		if app.ckptfile == "" {
+			return fmt.Errorf("fwk: no checkpoint file to resume from")
+		}
+		app.ievt0, err = app.restore()
+		if err != nil {
+			return err
+		}
+		app.msg.Infof("resuming from evt=%d...\n", app.ievt0)
+	}
+
+	app.ckpt = nil
+	if app.ckptfile != "" {
+		app.ckpt = newCheckpointer(app, app.ievt0)
+	}
+
+	app.mons = app.mons[:0]
+	for _, svc := range app.svcs {
+		if mon, ok := svc.(Monitor); ok {
+			app.mons = append(app.mons, mon)
+		}
+	}
+
 	maxprocs := runtime.GOMAXPROCS(app.nprocs)
 
 	switch app.nprocs {
@@ -525,6 +621,12 @@ func (app *appmgr) run(ctx Context) error {
 
 	runtime.GOMAXPROCS(maxprocs)
 
+	if err == nil || err == io.EOF {
+		if e := app.ckpt.flush(); e != nil {
+			return e
+		}
+	}
+
 	return err
 }
 
@@ -536,12 +638,13 @@ func (app *appmgr) runSequential(ctx Context) error {
 
 	keys := app.dflow.keys()
 	ctxs := make([]ctxType, len(app.tsks))
-	store := *app.store
+	store := new(datastore)
+	*store = *app.store
 	for j, tsk := range app.tsks {
 		ctxs[j] = ctxType{
 			id:    -1,
 			slot:  0,
-			store: &store,
+			store: store,
 			msg:   newMsgStream(tsk.Name(), app.msg.lvl, nil),
 			mgr:   app,
 		}
@@ -560,8 +663,11 @@ func (app *appmgr) runSequential(ctx Context) error {
 
 	defer close(octrl.Quit)
 
+	graph := newTaskGraph(app.dflow, app.tsks)
+
 	for ievt := int64(0); ievt < app.evtmax; ievt++ {
 		evtctx, evtCancel := context.WithCancel(runctx)
+		evtstart := time.Now()
 
 		app.msg.Infof(">>> running evt=%d...\n", ievt)
 		err = store.reset(keys)
@@ -576,31 +682,69 @@ func (app *appmgr) runSequential(ctx Context) error {
 			app.msg.flush()
 			return err
 		}
+		if ievt < app.ievt0 {
+			// already processed before the job was resumed.
+			evtCancel()
+			continue
+		}
 		run := taskrunner{
 			ievt:   ievt,
 			errc:   make(chan error, len(app.tsks)),
 			evtctx: evtctx,
+			mons:   app.mons,
 		}
-		for i, tsk := range app.tsks {
-			go run.run(i, ctxs[i], tsk)
-		}
+		go run.schedule(graph, ctxs, app.tsks, app.nthreads)
+		tmo, stop := watchdog(app.evttmo)
 		ndone := 0
+		aborted := false
 	errloop:
-		for err = range run.errc {
-			ndone++
-			if err != nil {
-				evtCancel()
-				store.close()
-				app.msg.flush()
-				return err
-			}
-			if ndone == len(app.tsks) {
+		for {
+			select {
+			case err = <-run.errc:
+				ndone++
+				if err != nil {
+					stop()
+					evtCancel()
+					store.close()
+					app.msg.flush()
+					return err
+				}
+				if ndone == len(app.tsks) {
+					break errloop
+				}
+			case <-tmo:
+				app.msg.Warnf("evt=%d exceeded time budget (%v): quarantined\n", ievt, app.evttmo)
+				app.quarantine.add(ievt)
+				aborted = true
 				break errloop
 			}
 		}
+		stop()
 		evtCancel()
 		store.close()
 		app.msg.flush()
+
+		if !aborted {
+			for _, mon := range app.mons {
+				mon.ProcessedEvent(ievt, time.Since(evtstart))
+			}
+		}
+
+		err = app.ckpt.complete(ievt)
+		if err != nil {
+			return err
+		}
+
+		if aborted {
+			// tasks of the aborted event may still be running:
+			// give a fresh store to the next events.
+			store = new(datastore)
+			*store = *app.store
+			store.store = make(map[string]achan, len(keys))
+			for i := range ctxs {
+				ctxs[i].store = store
+			}
+		}
 	}
 
 	return err
@@ -617,6 +761,8 @@ func (app *appmgr) runConcurrent(ctx Context) error {
 		done:   make(chan struct{}),
 		errc:   make(chan error),
 		runctx: runctx,
+		evttmo: app.evttmo,
+		quar:   &app.quarantine,
 	}
 
 	istream, err := app.startInputStream()
@@ -631,6 +777,11 @@ func (app *appmgr) runConcurrent(ctx Context) error {
 	}
 	defer close(ostream.Quit)
 
+	ctrl.graph = newTaskGraph(app.dflow, app.tsks)
+	ctrl.nthreads = app.nthreads
+	ctrl.ckpt = app.ckpt
+	ctrl.mons = app.mons
+
 	workers := make([]worker, app.nprocs)
 	for i := 0; i < app.nprocs; i++ {
 		workers[i] = *newWorker(i, app, &ctrl)
@@ -668,6 +819,11 @@ func (app *appmgr) runConcurrent(ctx Context) error {
 				evtCancel()
 				return
 			}
+			if ievt < app.ievt0 {
+				// already processed before the job was resumed.
+				evtCancel()
+				continue
+			}
 			ctrl.evts <- ctx
 			evtCancel()
 		}
@@ -839,6 +995,10 @@ func (app *appmgr) printDataFlow() error {
 	return err
 }
 
+var (
+	_ Quarantiner = (*appmgr)(nil)
+)
+
 func init() {
 	Register(
 		reflect.TypeOf(appmgr{}),
@@ -0,0 +1,171 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fwk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// checkpoint is the state of a job, saved on disk to resume it.
+type checkpoint struct {
+	Event      int64             `json:"event"`      // number of processed events
+	Quarantine []int64           `json:"quarantine"` // IDs of the quarantined events
+	Comps      map[string][]byte `json:"comps"`      // states of the components
+}
+
+// checkpointer periodically saves the state of a job.
+//
+// In concurrent mode, events complete out of order: the saved event index
+// is the number of events whose processing, as well as the processing of
+// all the preceding events, has completed.
+type checkpointer struct {
+	mu    sync.Mutex
+	fname string
+	every int64 // number of processed events between checkpoints
+	comps []Component
+	quar  *quarantine
+
+	next int64              // index of the next event to complete
+	last int64              // index of the last checkpoint
+	done map[int64]struct{} // completed events beyond next
+}
+
+func newCheckpointer(app *appmgr, first int64) *checkpointer {
+	ck := &checkpointer{
+		fname: app.ckptfile,
+		every: app.ckptevery,
+		quar:  &app.quarantine,
+		next:  first,
+		last:  first,
+		done:  make(map[int64]struct{}),
+	}
+	for _, svc := range app.svcs {
+		if _, ok := svc.(Checkpointer); ok {
+			ck.comps = append(ck.comps, svc)
+		}
+	}
+	for _, tsk := range app.tsks {
+		if _, ok := tsk.(Checkpointer); ok {
+			ck.comps = append(ck.comps, tsk)
+		}
+	}
+	return ck
+}
+
+// complete marks the processing of the event id as completed, and saves a
+// checkpoint when needed.
+func (ck *checkpointer) complete(id int64) error {
+	if ck == nil {
+		return nil
+	}
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	ck.done[id] = struct{}{}
+	for {
+		if _, ok := ck.done[ck.next]; !ok {
+			break
+		}
+		delete(ck.done, ck.next)
+		ck.next++
+	}
+
+	if ck.every <= 0 || ck.next-ck.last < ck.every {
+		return nil
+	}
+	return ck.save()
+}
+
+// flush saves a checkpoint with the current state of the job.
+func (ck *checkpointer) flush() error {
+	if ck == nil {
+		return nil
+	}
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+	return ck.save()
+}
+
+func (ck *checkpointer) save() error {
+	state := checkpoint{
+		Event:      ck.next,
+		Quarantine: ck.quar.list(),
+		Comps:      make(map[string][]byte, len(ck.comps)),
+	}
+	for _, c := range ck.comps {
+		raw, err := c.(Checkpointer).MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("fwk: could not checkpoint component [%s]: %w", c.Name(), err)
+		}
+		state.Comps[c.Name()] = raw
+	}
+
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fwk: could not encode checkpoint: %w", err)
+	}
+
+	// write to a temporary file first, so a preempted job does not leave a
+	// corrupted checkpoint behind.
+	tmp, err := os.CreateTemp(filepath.Dir(ck.fname), filepath.Base(ck.fname)+".*")
+	if err != nil {
+		return fmt.Errorf("fwk: could not create checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(raw)
+	if err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("fwk: could not write checkpoint file: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("fwk: could not close checkpoint file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), ck.fname)
+	if err != nil {
+		return fmt.Errorf("fwk: could not save checkpoint file: %w", err)
+	}
+
+	ck.last = ck.next
+	return nil
+}
+
+// restore restores the state of the job from the checkpoint file, and
+// returns the index of the first event to process.
+func (app *appmgr) restore() (int64, error) {
+	raw, err := os.ReadFile(app.ckptfile)
+	if err != nil {
+		return 0, fmt.Errorf("fwk: could not read checkpoint file: %w", err)
+	}
+
+	var state checkpoint
+	err = json.Unmarshal(raw, &state)
+	if err != nil {
+		return 0, fmt.Errorf("fwk: could not decode checkpoint file %q: %w", app.ckptfile, err)
+	}
+
+	for name, raw := range state.Comps {
+		c, ok := app.comps[name].(Checkpointer)
+		if !ok {
+			return 0, fmt.Errorf("fwk: no checkpointable component [%s] to restore", name)
+		}
+		err = c.UnmarshalBinary(raw)
+		if err != nil {
+			return 0, fmt.Errorf("fwk: could not restore component [%s]: %w", name, err)
+		}
+	}
+
+	for _, id := range state.Quarantine {
+		app.quarantine.add(id)
+	}
+
+	return state.Event, nil
+}
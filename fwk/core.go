@@ -5,6 +5,7 @@
 package fwk
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
 
@@ -103,6 +104,26 @@ type App interface {
 	Msg() MsgStream
 }
 
+// Quarantiner is the interface implemented by applications able to skip
+// events whose processing exceeds a time budget (see the EvtTimeout
+// property of the default fwk.App.)
+//
+// Quarantine returns the sorted list of IDs of the skipped events.
+type Quarantiner interface {
+	Quarantine() []int64
+}
+
+// Checkpointer is the interface implemented by components whose state is
+// saved in the checkpoints of a job, and restored when the job is resumed
+// (see the Checkpoint and Resume properties of the default fwk.App.)
+//
+// In concurrent mode, MarshalBinary may be called while events are being
+// processed.
+type Checkpointer interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
 // Runner runs a fwk App in a batch fashion:
 //  - Configure
 //  - Start
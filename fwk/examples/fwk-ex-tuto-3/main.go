@@ -25,6 +25,8 @@ var (
 	lvl    = flag.String("l", "INFO", "message level (DEBUG|INFO|WARN|ERROR)")
 	evtmax = flag.Int64("evtmax", -1, "number of events to process")
 	nprocs = flag.Int("nprocs", -1, "number of events to process concurrently")
+	ckpt   = flag.String("checkpoint", "", "path to the checkpoint file of the job")
+	resume = flag.Bool("resume", false, "resume the job from its checkpoint file")
 )
 
 func main() {
@@ -59,9 +61,11 @@ options:
 	// create a default fwk application, with some properties
 	// extracted from the CLI
 	app := job.New(job.P{
-		"EvtMax":   *evtmax,
-		"NProcs":   *nprocs,
-		"MsgLevel": job.MsgLevel(*lvl),
+		"EvtMax":     *evtmax,
+		"NProcs":     *nprocs,
+		"Checkpoint": *ckpt,
+		"Resume":     *resume,
+		"MsgLevel":   job.MsgLevel(*lvl),
 	})
 
 	r, err := os.Open(ifname)
@@ -41,7 +41,8 @@
 //  - event-level concurrency: multiple events are processed concurrently
 //    at any given time, during the event loop;
 //  - task-level concurrency: during the event loop, multiple tasks are
-//    executing concurrently.
+//    executing concurrently, as soon as the data they depend on have been
+//    produced by upstream tasks.
 //
 // To ensure the proper self-consistency of the global processed event,
 // components need to express their data dependencies (input(s)) as well
@@ -9,8 +9,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"go-hep.org/x/hep/fwk"
 	"go-hep.org/x/hep/fwk/job"
@@ -532,3 +535,280 @@ func Benchmark__ConcApp(b *testing.B) {
 		}
 	}
 }
+
+func TestEvtTimeout(t *testing.T) {
+	for _, nprocs := range []int{0, 1, 4} {
+		t.Run(fmt.Sprintf("nprocs=%d", nprocs), func(t *testing.T) {
+			app := job.NewJob(nil, job.P{
+				"EvtMax":     int64(10),
+				"NProcs":     nprocs,
+				"MsgLevel":   job.MsgLevel("ERROR"),
+				"EvtTimeout": 100 * time.Millisecond,
+			})
+
+			app.Create(job.C{
+				Type: "go-hep.org/x/hep/fwk/testdata.task1",
+				Name: "t1",
+				Props: job.P{
+					"Ints1": "t1-ints1",
+					"Ints2": "t1-ints2",
+				},
+			})
+
+			app.Create(job.C{
+				Type: "go-hep.org/x/hep/fwk/testdata.task2",
+				Name: "t2",
+				Props: job.P{
+					"Input":  "t1-ints1",
+					"Output": "t1-ints1-massaged",
+				},
+			})
+
+			app.Create(job.C{
+				Type: "go-hep.org/x/hep/fwk/testdata.task5",
+				Name: "t5",
+				Props: job.P{
+					"Slow":  []int64{2, 5},
+					"Sleep": 2 * time.Second,
+				},
+			})
+
+			err := app.App().Run()
+			if err != nil {
+				t.Fatalf("could not run app: %+v", err)
+			}
+
+			got := app.App().(fwk.Quarantiner).Quarantine()
+			want := []int64{2, 5}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("invalid quarantined events:\ngot= %v\nwant=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestTaskScheduling(t *testing.T) {
+	type step struct {
+		name  string
+		id    int64
+		start bool
+	}
+
+	// data dependencies: a -> (b, c) -> d, and e.
+	parents := map[string][]string{
+		"a": nil,
+		"b": {"a"},
+		"c": {"a"},
+		"d": {"b", "c"},
+		"e": nil,
+	}
+
+	for _, tc := range []struct {
+		nprocs   int
+		nthreads int
+	}{
+		{nprocs: 0, nthreads: 0},
+		{nprocs: 0, nthreads: 1},
+		{nprocs: 2, nthreads: 0},
+		{nprocs: 2, nthreads: 1},
+	} {
+		t.Run(fmt.Sprintf("nprocs=%d-nthreads=%d", tc.nprocs, tc.nthreads), func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				steps []step
+			)
+			trace := func(name string, id int64, start bool) {
+				mu.Lock()
+				steps = append(steps, step{name, id, start})
+				mu.Unlock()
+			}
+
+			app := job.NewJob(nil, job.P{
+				"EvtMax":   int64(4),
+				"NProcs":   tc.nprocs,
+				"NThreads": tc.nthreads,
+				"MsgLevel": job.MsgLevel("ERROR"),
+			})
+
+			for _, name := range []string{"d", "c", "b", "a", "e"} {
+				app.Create(job.C{
+					Type: "go-hep.org/x/hep/fwk/testdata.task6",
+					Name: name,
+					Props: job.P{
+						"Inputs": parents[name],
+						"Output": name,
+						"Sleep":  10 * time.Millisecond,
+						"Trace":  trace,
+					},
+				})
+			}
+
+			err := app.App().Run()
+			if err != nil {
+				t.Fatalf("could not run app: %+v", err)
+			}
+
+			if got, want := len(steps), 2*4*len(parents); got != want {
+				t.Fatalf("invalid number of steps: got=%d, want=%d", got, want)
+			}
+
+			var (
+				ended   = make(map[int64]map[string]bool)
+				running = make(map[int64]int)
+				maxtsks = 0 // maximum number of concurrent tasks of an event
+				maxevts = 0 // maximum number of events in flight
+			)
+			for _, s := range steps {
+				if ended[s.id] == nil {
+					ended[s.id] = make(map[string]bool)
+				}
+				if !s.start {
+					running[s.id]--
+					ended[s.id][s.name] = true
+					if running[s.id] == 0 && len(ended[s.id]) == len(parents) {
+						delete(running, s.id)
+					}
+					continue
+				}
+				for _, p := range parents[s.name] {
+					if !ended[s.id][p] {
+						t.Fatalf("evt=%d: task %q started before its producer %q ended", s.id, s.name, p)
+					}
+				}
+				running[s.id]++
+				if n := running[s.id]; n > maxtsks {
+					maxtsks = n
+				}
+				if n := len(running); n > maxevts {
+					maxevts = n
+				}
+			}
+
+			switch tc.nthreads {
+			case 1:
+				if maxtsks != 1 {
+					t.Fatalf("invalid number of concurrent tasks: got=%d, want=1", maxtsks)
+				}
+			default:
+				if maxtsks < 2 {
+					t.Fatalf("independent tasks did not run concurrently")
+				}
+			}
+
+			if tc.nprocs > 1 && maxevts < 2 {
+				t.Fatalf("events were not processed concurrently")
+			}
+		})
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	const evtmax = 10
+
+	tmp, err := os.MkdirTemp("", "fwk-checkpoint-")
+	if err != nil {
+		t.Fatalf("could not create tmp dir: %+v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	newJob := func(nprocs int, failat int64, props job.P, trace func(id int64, v uint64)) *job.Job {
+		app := job.NewJob(nil, job.P{
+			"EvtMax":   int64(evtmax),
+			"NProcs":   nprocs,
+			"MsgLevel": job.MsgLevel("ERROR"),
+		})
+		for k, v := range props {
+			app.SetProp(app.App(), k, v)
+		}
+		app.Create(job.C{
+			Type: "go-hep.org/x/hep/fwk/testdata.svc2",
+			Name: "rng",
+		})
+		app.Create(job.C{
+			Type: "go-hep.org/x/hep/fwk/testdata.task7",
+			Name: "t7",
+			Props: job.P{
+				"FailAt": failat,
+				"Trace":  trace,
+			},
+		})
+		return app
+	}
+
+	for _, nprocs := range []int{0, 2} {
+		t.Run(fmt.Sprintf("nprocs=%d", nprocs), func(t *testing.T) {
+			var (
+				mu   sync.Mutex
+				want = make(map[int64]uint64)
+				got  = make(map[int64]uint64)
+				ckpt = filepath.Join(tmp, fmt.Sprintf("ckpt-%d.json", nprocs))
+			)
+			tracer := func(m map[int64]uint64) func(int64, uint64) {
+				return func(id int64, v uint64) {
+					mu.Lock()
+					m[id] = v
+					mu.Unlock()
+				}
+			}
+
+			ref := newJob(nprocs, -1, nil, tracer(want))
+			err := ref.App().Run()
+			if err != nil {
+				t.Fatalf("could not run reference job: %+v", err)
+			}
+
+			app := newJob(nprocs, 6, job.P{
+				"Checkpoint":      ckpt,
+				"CheckpointEvery": int64(2),
+			}, tracer(got))
+			err = app.App().Run()
+			if err == nil {
+				t.Fatalf("expected a preempted job")
+			}
+
+			done := make(map[int64]uint64)
+			app = newJob(nprocs, -1, job.P{
+				"Checkpoint": ckpt,
+				"Resume":     true,
+			}, tracer(done))
+			err = app.App().Run()
+			if err != nil {
+				t.Fatalf("could not resume job: %+v", err)
+			}
+
+			if len(done) == 0 || len(done) > evtmax-2 {
+				t.Fatalf("invalid number of resumed events: %d", len(done))
+			}
+			for id := int64(evtmax - len(done)); id < evtmax; id++ {
+				if _, ok := done[id]; !ok {
+					t.Fatalf("event %d not processed after resume", id)
+				}
+			}
+			if nprocs == 0 {
+				if got, want := len(done), evtmax-6; got != want {
+					t.Fatalf("invalid number of resumed events: got=%d, want=%d", got, want)
+				}
+				// random numbers sequences are restored.
+				for id, v := range done {
+					if v != want[id] {
+						t.Fatalf("evt=%d: invalid random number after resume: got=%d, want=%d", id, v, want[id])
+					}
+				}
+			}
+
+			// resuming a completed job processes no event.
+			rerun := make(map[int64]uint64)
+			app = newJob(nprocs, -1, job.P{
+				"Checkpoint": ckpt,
+				"Resume":     true,
+			}, tracer(rerun))
+			err = app.App().Run()
+			if err != nil {
+				t.Fatalf("could not resume completed job: %+v", err)
+			}
+			if len(rerun) != 0 {
+				t.Fatalf("invalid number of events after completion: %d", len(rerun))
+			}
+		})
+	}
+}
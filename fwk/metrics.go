@@ -0,0 +1,52 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fwk
+
+import (
+	"time"
+)
+
+// Monitor is the interface implemented by services monitoring the event
+// loop of an application.
+//
+// The methods of a Monitor are called concurrently.
+type Monitor interface {
+	Svc
+
+	// ProcessedTask is called after the task named task processed the
+	// event id in dt, with the returned error.
+	ProcessedTask(task string, id int64, dt time.Duration, err error)
+
+	// ProcessedEvent is called after all the tasks processed the event id,
+	// dt after the start of its processing.
+	ProcessedEvent(id int64, dt time.Duration)
+}
+
+// Counter is a metric whose value only increases.
+// Counter is safe for concurrent use.
+type Counter interface {
+	Add(v float64) // Add adds v (>= 0) to the counter.
+}
+
+// Gauge is a metric whose value can go up and down.
+// Gauge is safe for concurrent use.
+type Gauge interface {
+	Set(v float64) // Set sets the value of the gauge.
+	Add(v float64) // Add adds v to the gauge.
+}
+
+// MetricSvc is the interface of services monitoring applications, and
+// providing custom metrics.
+type MetricSvc interface {
+	Monitor
+
+	// Counter returns the counter with the provided name, creating it
+	// with the provided help string if needed.
+	Counter(name, help string) (Counter, error)
+
+	// Gauge returns the gauge with the provided name, creating it with
+	// the provided help string if needed.
+	Gauge(name, help string) (Gauge, error)
+}
@@ -0,0 +1,330 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package metricsvc provides a fwk service monitoring fwk applications.
+//
+// The service exposes the metrics of an application (per-task processing
+// times, event throughput, memory statistics and custom metrics) in the
+// Prometheus text format, over an HTTP /metrics endpoint.
+package metricsvc // import "go-hep.org/x/hep/fwk/metricsvc"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-hep.org/x/hep/fwk"
+)
+
+var validName = regexp.MustCompile("^[a-zA-Z_:][a-zA-Z0-9_:]*$")
+
+// taskstat holds the processing statistics of a task.
+type taskstat struct {
+	n    int64   // number of processed events
+	nerr int64   // number of failed events
+	secs float64 // total processing time
+}
+
+type metric struct {
+	name string
+	help string
+	kind string // counter or gauge
+
+	mu  sync.Mutex
+	val float64
+}
+
+func (m *metric) Add(v float64) {
+	m.mu.Lock()
+	m.val += v
+	m.mu.Unlock()
+}
+
+func (m *metric) Set(v float64) {
+	m.mu.Lock()
+	m.val = v
+	m.mu.Unlock()
+}
+
+func (m *metric) value() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.val
+}
+
+type counter struct{ *metric }
+
+func (c counter) Add(v float64) {
+	if v < 0 {
+		panic(fmt.Errorf("metricsvc: negative increment of counter %q", c.name))
+	}
+	c.metric.Add(v)
+}
+
+// msvc is a monitoring service, serving metrics over HTTP.
+//
+// msvc declares a property 'Addr', the address (a string, e.g. ":9100")
+// the HTTP server listens on. No server is started if Addr is empty.
+type msvc struct {
+	fwk.SvcBase
+
+	addr string
+	srv  *http.Server
+	lis  net.Listener
+
+	mu      sync.RWMutex
+	start   time.Time
+	tasks   map[string]*taskstat
+	nevts   int64   // number of processed events
+	evtsecs float64 // total processing time of events
+	metrics map[string]*metric
+}
+
+func (svc *msvc) Configure(ctx fwk.Context) error {
+	return nil
+}
+
+func (svc *msvc) StartSvc(ctx fwk.Context) error {
+	svc.mu.Lock()
+	svc.start = time.Now()
+	svc.mu.Unlock()
+
+	if svc.addr == "" {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", svc.addr)
+	if err != nil {
+		return fmt.Errorf("%s: could not listen on %q: %w", svc.Name(), svc.addr, err)
+	}
+	svc.lis = lis
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", svc)
+	svc.srv = &http.Server{Handler: mux}
+	go func() {
+		_ = svc.srv.Serve(lis)
+	}()
+
+	return nil
+}
+
+func (svc *msvc) StopSvc(ctx fwk.Context) error {
+	if svc.srv == nil {
+		return nil
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := svc.srv.Shutdown(shutdown)
+	svc.srv = nil
+	svc.lis = nil
+	if err != nil {
+		return fmt.Errorf("%s: could not shutdown HTTP server: %w", svc.Name(), err)
+	}
+	return nil
+}
+
+func (svc *msvc) ProcessedTask(task string, id int64, dt time.Duration, err error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	st, ok := svc.tasks[task]
+	if !ok {
+		st = &taskstat{}
+		svc.tasks[task] = st
+	}
+	st.n++
+	st.secs += dt.Seconds()
+	if err != nil {
+		st.nerr++
+	}
+}
+
+func (svc *msvc) ProcessedEvent(id int64, dt time.Duration) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.nevts++
+	svc.evtsecs += dt.Seconds()
+}
+
+func (svc *msvc) Counter(name, help string) (fwk.Counter, error) {
+	m, err := svc.metric(name, help, "counter")
+	if err != nil {
+		return nil, err
+	}
+	return counter{m}, nil
+}
+
+func (svc *msvc) Gauge(name, help string) (fwk.Gauge, error) {
+	return svc.metric(name, help, "gauge")
+}
+
+func (svc *msvc) metric(name, help, kind string) (*metric, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("%s: invalid metric name %q", svc.Name(), name)
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+
+	m, ok := svc.metrics[name]
+	if ok {
+		if m.kind != kind {
+			return nil, fmt.Errorf("%s: metric %q already declared as a %s", svc.Name(), name, m.kind)
+		}
+		return m, nil
+	}
+
+	m = &metric{name: name, help: help, kind: kind}
+	svc.metrics[name] = m
+	return m, nil
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (svc *msvc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	svc.write(w)
+}
+
+func (svc *msvc) write(w io.Writer) {
+	var (
+		mem runtime.MemStats
+		out strings.Builder
+	)
+	runtime.ReadMemStats(&mem)
+
+	svc.mu.RLock()
+
+	uptime := time.Since(svc.start).Seconds()
+	if svc.start.IsZero() {
+		uptime = 0
+	}
+
+	names := make([]string, 0, len(svc.tasks))
+	for name := range svc.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	header(&out, "fwk_task_processed_events_total", "counter", "Number of events processed by a task.")
+	for _, name := range names {
+		sample(&out, "fwk_task_processed_events_total", name, float64(svc.tasks[name].n))
+	}
+	header(&out, "fwk_task_failed_events_total", "counter", "Number of events whose processing by a task failed.")
+	for _, name := range names {
+		sample(&out, "fwk_task_failed_events_total", name, float64(svc.tasks[name].nerr))
+	}
+	header(&out, "fwk_task_process_seconds_total", "counter", "Time spent by a task processing events.")
+	for _, name := range names {
+		sample(&out, "fwk_task_process_seconds_total", name, svc.tasks[name].secs)
+	}
+
+	throughput := 0.0
+	if uptime > 0 {
+		throughput = float64(svc.nevts) / uptime
+	}
+
+	header(&out, "fwk_processed_events_total", "counter", "Number of processed events.")
+	sample(&out, "fwk_processed_events_total", "", float64(svc.nevts))
+	header(&out, "fwk_event_process_seconds_total", "counter", "Time spent processing events.")
+	sample(&out, "fwk_event_process_seconds_total", "", svc.evtsecs)
+	header(&out, "fwk_events_per_second", "gauge", "Average event throughput since the start of the application.")
+	sample(&out, "fwk_events_per_second", "", throughput)
+	header(&out, "fwk_uptime_seconds", "gauge", "Time since the start of the application.")
+	sample(&out, "fwk_uptime_seconds", "", uptime)
+
+	metrics := make([]*metric, 0, len(svc.metrics))
+	for _, m := range svc.metrics {
+		metrics = append(metrics, m)
+	}
+	svc.mu.RUnlock()
+
+	header(&out, "go_goroutines", "gauge", "Number of goroutines.")
+	sample(&out, "go_goroutines", "", float64(runtime.NumGoroutine()))
+	header(&out, "go_memstats_alloc_bytes", "gauge", "Number of bytes allocated and still in use.")
+	sample(&out, "go_memstats_alloc_bytes", "", float64(mem.Alloc))
+	header(&out, "go_memstats_alloc_bytes_total", "counter", "Total number of bytes allocated.")
+	sample(&out, "go_memstats_alloc_bytes_total", "", float64(mem.TotalAlloc))
+	header(&out, "go_memstats_heap_inuse_bytes", "gauge", "Number of heap bytes in use.")
+	sample(&out, "go_memstats_heap_inuse_bytes", "", float64(mem.HeapInuse))
+	header(&out, "go_memstats_sys_bytes", "gauge", "Number of bytes obtained from the system.")
+	sample(&out, "go_memstats_sys_bytes", "", float64(mem.Sys))
+	header(&out, "go_gc_cycles_total", "counter", "Number of completed GC cycles.")
+	sample(&out, "go_gc_cycles_total", "", float64(mem.NumGC))
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].name < metrics[j].name
+	})
+	for _, m := range metrics {
+		header(&out, m.name, m.kind, m.help)
+		sample(&out, m.name, "", m.value())
+	}
+
+	_, _ = io.WriteString(w, out.String())
+}
+
+func header(w *strings.Builder, name, kind, help string) {
+	if help != "" {
+		help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+		fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+func sample(w *strings.Builder, name, task string, v float64) {
+	w.WriteString(name)
+	if task != "" {
+		fmt.Fprintf(w, "{task=%q}", task)
+	}
+	w.WriteString(" ")
+	switch {
+	case math.IsInf(v, +1):
+		w.WriteString("+Inf")
+	case math.IsInf(v, -1):
+		w.WriteString("-Inf")
+	case math.IsNaN(v):
+		w.WriteString("NaN")
+	default:
+		w.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	w.WriteString("\n")
+}
+
+func newmsvc(typ, name string, mgr fwk.App) (fwk.Component, error) {
+	var err error
+	svc := &msvc{
+		SvcBase: fwk.NewSvc(typ, name, mgr),
+		addr:    ":9100",
+		tasks:   make(map[string]*taskstat),
+		metrics: make(map[string]*metric),
+	}
+
+	err = svc.DeclProp("Addr", &svc.addr)
+	if err != nil {
+		return nil, err
+	}
+	return svc, err
+}
+
+func init() {
+	fwk.Register(reflect.TypeOf(msvc{}), newmsvc)
+}
+
+var (
+	_ fwk.MetricSvc = (*msvc)(nil)
+	_ http.Handler  = (*msvc)(nil)
+)
@@ -0,0 +1,148 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metricsvc
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-hep.org/x/hep/fwk"
+	"go-hep.org/x/hep/fwk/job"
+	_ "go-hep.org/x/hep/fwk/testdata"
+)
+
+func TestMetricSvc(t *testing.T) {
+	for _, nprocs := range []int{0, 2} {
+		t.Run(fmt.Sprintf("nprocs=%d", nprocs), func(t *testing.T) {
+			app := job.NewJob(nil, job.P{
+				"EvtMax":   int64(10),
+				"NProcs":   nprocs,
+				"MsgLevel": job.MsgLevel("ERROR"),
+			})
+
+			app.Create(job.C{
+				Type: "go-hep.org/x/hep/fwk/testdata.task1",
+				Name: "t1",
+				Props: job.P{
+					"Ints1": "t1-ints1",
+					"Ints2": "t1-ints2",
+				},
+			})
+
+			app.Create(job.C{
+				Type: "go-hep.org/x/hep/fwk/testdata.task2",
+				Name: "t2",
+				Props: job.P{
+					"Input":  "t1-ints1",
+					"Output": "t1-ints1-massaged",
+				},
+			})
+
+			svc := app.Create(job.C{
+				Type: "go-hep.org/x/hep/fwk/metricsvc.msvc",
+				Name: "metrics",
+				Props: job.P{
+					"Addr": "",
+				},
+			}).(*msvc)
+
+			nevts, err := svc.Counter("my_events_total", "Number of my events.")
+			if err != nil {
+				t.Fatalf("could not create counter: %+v", err)
+			}
+			nevts.Add(42)
+
+			err = app.App().Run()
+			if err != nil {
+				t.Fatalf("could not run app: %+v", err)
+			}
+
+			var out strings.Builder
+			svc.write(&out)
+			for _, want := range []string{
+				"# TYPE fwk_processed_events_total counter\nfwk_processed_events_total 10\n",
+				"fwk_task_processed_events_total{task=\"t1\"} 10\n",
+				"fwk_task_processed_events_total{task=\"t2\"} 10\n",
+				"fwk_task_failed_events_total{task=\"t2\"} 0\n",
+				"fwk_task_process_seconds_total{task=\"t2\"} ",
+				"# TYPE fwk_events_per_second gauge\n",
+				"# TYPE go_memstats_alloc_bytes gauge\n",
+				"# HELP my_events_total Number of my events.\n# TYPE my_events_total counter\nmy_events_total 42\n",
+			} {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("missing metric %q in:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	v, err := newmsvc("go-hep.org/x/hep/fwk/metricsvc.msvc", "metrics", fwk.NewApp())
+	if err != nil {
+		t.Fatalf("could not create service: %+v", err)
+	}
+	svc := v.(*msvc)
+	svc.addr = "127.0.0.1:0"
+
+	gauge, err := svc.Gauge("my_queue_size", "")
+	if err != nil {
+		t.Fatalf("could not create gauge: %+v", err)
+	}
+	gauge.Set(3)
+	gauge.Add(-1)
+
+	same, err := svc.Gauge("my_queue_size", "")
+	if err != nil {
+		t.Fatalf("could not retrieve gauge: %+v", err)
+	}
+	same.Add(10)
+
+	_, err = svc.Counter("my_queue_size", "")
+	if err == nil {
+		t.Fatalf("expected an error for a metric declared with another type")
+	}
+	_, err = svc.Counter("my-counter", "")
+	if err == nil {
+		t.Fatalf("expected an error for an invalid metric name")
+	}
+
+	err = svc.StartSvc(nil)
+	if err != nil {
+		t.Fatalf("could not start service: %+v", err)
+	}
+	defer svc.StopSvc(nil)
+
+	resp, err := http.Get("http://" + svc.lis.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatalf("could not get metrics: %+v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("invalid status: %v", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("could not read metrics: %+v", err)
+	}
+	for _, want := range []string{
+		"# TYPE my_queue_size gauge\nmy_queue_size 12\n",
+		"# TYPE fwk_uptime_seconds gauge\n",
+		"# TYPE go_goroutines gauge\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("missing metric %q in:\n%s", want, body)
+		}
+	}
+
+	err = svc.StopSvc(nil)
+	if err != nil {
+		t.Fatalf("could not stop service: %+v", err)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fwk
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// quarantine records the IDs of the events whose processing was aborted
+// because it exceeded the per-event time budget.
+type quarantine struct {
+	mu  sync.Mutex
+	ids []int64
+}
+
+func (q *quarantine) add(id int64) {
+	q.mu.Lock()
+	q.ids = append(q.ids, id)
+	q.mu.Unlock()
+}
+
+// list returns the sorted list of quarantined event IDs.
+func (q *quarantine) list() []int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ids := make([]int64, len(q.ids))
+	copy(ids, q.ids)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// watchdog returns a channel that fires once the provided time budget has
+// elapsed, and a function to release the associated resources.
+// The returned channel never fires if the time budget is not positive.
+func watchdog(timeout time.Duration) (<-chan time.Time, func()) {
+	if timeout <= 0 {
+		return nil, func() {}
+	}
+	timer := time.NewTimer(timeout)
+	return timer.C, func() { timer.Stop() }
+}
@@ -0,0 +1,132 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fwk
+
+import (
+	"sort"
+	"time"
+)
+
+// taskgraph is the directed acyclic graph of the data dependencies between
+// the tasks of an event, built from their declared input and output ports.
+type taskgraph struct {
+	nparents []int   // number of producers of each task
+	children [][]int // consumers of each task
+}
+
+// newTaskGraph returns the graph of the data dependencies between tasks.
+// Inputs produced by components outside of tsks (e.g. the input stream)
+// are considered available at the start of the event.
+func newTaskGraph(dflow *dflowsvc, tsks []Task) *taskgraph {
+	g := &taskgraph{
+		nparents: make([]int, len(tsks)),
+		children: make([][]int, len(tsks)),
+	}
+
+	producers := make(map[string]int) // outport-name -> producer index
+	for i, tsk := range tsks {
+		node, ok := dflow.nodes[tsk.Name()]
+		if !ok {
+			continue
+		}
+		for k := range node.out {
+			producers[k] = i
+		}
+	}
+
+	for i, tsk := range tsks {
+		node, ok := dflow.nodes[tsk.Name()]
+		if !ok {
+			continue
+		}
+		parents := make(map[int]struct{})
+		for k := range node.in {
+			j, ok := producers[k]
+			if !ok || j == i {
+				continue
+			}
+			parents[j] = struct{}{}
+		}
+		for j := range parents {
+			g.children[j] = append(g.children[j], i)
+		}
+		g.nparents[i] = len(parents)
+	}
+
+	// sort consumers for reproducibility.
+	for _, children := range g.children {
+		sort.Ints(children)
+	}
+
+	return g
+}
+
+type taskresult struct {
+	i   int
+	err error
+}
+
+// schedule runs the tasks of an event following the data dependencies
+// graph: tasks are started once all their producers have completed, and
+// independent tasks run concurrently, with at most nthreads tasks running
+// at any given time (or without limit if nthreads <= 0).
+// The result of each task is sent to run.errc.
+// No task is started after a task failed or the event was aborted.
+func (run taskrunner) schedule(g *taskgraph, ctxs []ctxType, tsks []Task, nthreads int) {
+	var (
+		pending = make([]int, len(tsks))
+		ready   = make([]int, 0, len(tsks))
+		done    = make(chan taskresult, len(tsks))
+		running = 0
+	)
+	copy(pending, g.nparents)
+	for i, n := range pending {
+		if n == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	for ndone := 0; ndone < len(tsks); ndone++ {
+		for len(ready) > 0 && (nthreads <= 0 || running < nthreads) {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func(i int, ctx ctxType) {
+				ctx.id = run.ievt
+				start := time.Now()
+				err := tsks[i].Process(ctx)
+				for _, mon := range run.mons {
+					mon.ProcessedTask(tsks[i].Name(), run.ievt, time.Since(start), err)
+				}
+				// FIXME(sbinet) dont be so eager to flush...
+				ctx.msg.flush()
+				done <- taskresult{i: i, err: err}
+			}(i, ctxs[i])
+		}
+
+		var res taskresult
+		select {
+		case res = <-done:
+			running--
+		case <-run.evtctx.Done():
+			return
+		}
+
+		select {
+		case run.errc <- res.err:
+		case <-run.evtctx.Done():
+			return
+		}
+		if res.err != nil {
+			return
+		}
+		for _, c := range g.children[res.i] {
+			pending[c]--
+			if pending[c] == 0 {
+				ready = append(ready, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testdata
+
+import (
+	"reflect"
+	"sync"
+
+	"go-hep.org/x/hep/fwk"
+	"golang.org/x/exp/rand"
+)
+
+// svc2 is a random numbers service, whose state is checkpointed.
+type svc2 struct {
+	fwk.SvcBase
+
+	seed uint64
+
+	mu  sync.Mutex
+	src *rand.PCGSource
+}
+
+func (svc *svc2) Configure(ctx fwk.Context) error {
+	return nil
+}
+
+func (svc *svc2) StartSvc(ctx fwk.Context) error {
+	svc.src.Seed(svc.seed)
+	return nil
+}
+
+func (svc *svc2) StopSvc(ctx fwk.Context) error {
+	return nil
+}
+
+// Uint64 returns a pseudo-random number.
+func (svc *svc2) Uint64() uint64 {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return svc.src.Uint64()
+}
+
+func (svc *svc2) MarshalBinary() ([]byte, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return svc.src.MarshalBinary()
+}
+
+func (svc *svc2) UnmarshalBinary(data []byte) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return svc.src.UnmarshalBinary(data)
+}
+
+func init() {
+	fwk.Register(reflect.TypeOf(svc2{}),
+		func(typ, name string, mgr fwk.App) (fwk.Component, error) {
+			var err error
+			svc := &svc2{
+				SvcBase: fwk.NewSvc(typ, name, mgr),
+				seed:    1234,
+				src:     &rand.PCGSource{},
+			}
+
+			err = svc.DeclProp("Seed", &svc.seed)
+			if err != nil {
+				return nil, err
+			}
+
+			return svc, err
+		},
+	)
+}
+
+var (
+	_ fwk.Checkpointer = (*svc2)(nil)
+)
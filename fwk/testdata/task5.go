@@ -0,0 +1,68 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testdata
+
+import (
+	"reflect"
+	"time"
+
+	"go-hep.org/x/hep/fwk"
+)
+
+// task5 simulates a task taking a long time to process some events.
+type task5 struct {
+	fwk.TaskBase
+
+	slow  []int64       // IDs of the events that take a long time to process
+	sleep time.Duration // processing time of the slow events
+}
+
+func (tsk *task5) StartTask(ctx fwk.Context) error {
+	msg := ctx.Msg()
+	msg.Infof("start...\n")
+	return nil
+}
+
+func (tsk *task5) StopTask(ctx fwk.Context) error {
+	msg := ctx.Msg()
+	msg.Infof("stop...\n")
+	return nil
+}
+
+func (tsk *task5) Process(ctx fwk.Context) error {
+	msg := ctx.Msg()
+	for _, id := range tsk.slow {
+		if id == ctx.ID() {
+			msg.Infof("proc... (id=%d|%d) => sleeping %v\n", ctx.ID(), ctx.Slot(), tsk.sleep)
+			time.Sleep(tsk.sleep)
+			break
+		}
+	}
+	return nil
+}
+
+func init() {
+	fwk.Register(reflect.TypeOf(task5{}),
+		func(typ, name string, mgr fwk.App) (fwk.Component, error) {
+			var err error
+			tsk := &task5{
+				TaskBase: fwk.NewTask(typ, name, mgr),
+				sleep:    time.Second,
+			}
+
+			err = tsk.DeclProp("Slow", &tsk.slow)
+			if err != nil {
+				return nil, err
+			}
+
+			err = tsk.DeclProp("Sleep", &tsk.sleep)
+			if err != nil {
+				return nil, err
+			}
+
+			return tsk, err
+		},
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testdata
+
+import (
+	"reflect"
+	"time"
+
+	"go-hep.org/x/hep/fwk"
+)
+
+// task6 traces the start and the end of the processing of each event, to
+// check the scheduling of tasks.
+type task6 struct {
+	fwk.TaskBase
+
+	inputs []string
+	output string
+	sleep  time.Duration // processing time of each event
+
+	trace func(name string, id int64, start bool)
+}
+
+func (tsk *task6) Configure(ctx fwk.Context) error {
+	var err error
+	for _, in := range tsk.inputs {
+		err = tsk.DeclInPort(in, reflect.TypeOf(int64(1)))
+		if err != nil {
+			return err
+		}
+	}
+
+	if tsk.output != "" {
+		err = tsk.DeclOutPort(tsk.output, reflect.TypeOf(int64(1)))
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (tsk *task6) StartTask(ctx fwk.Context) error {
+	return nil
+}
+
+func (tsk *task6) StopTask(ctx fwk.Context) error {
+	return nil
+}
+
+func (tsk *task6) Process(ctx fwk.Context) error {
+	if tsk.trace != nil {
+		tsk.trace(tsk.Name(), ctx.ID(), true)
+	}
+
+	time.Sleep(tsk.sleep)
+
+	store := ctx.Store()
+	sum := int64(1)
+	for _, in := range tsk.inputs {
+		v, err := store.Get(in)
+		if err != nil {
+			return err
+		}
+		sum += v.(int64)
+	}
+
+	if tsk.output != "" {
+		err := store.Put(tsk.output, sum)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tsk.trace != nil {
+		tsk.trace(tsk.Name(), ctx.ID(), false)
+	}
+	return nil
+}
+
+func init() {
+	fwk.Register(reflect.TypeOf(task6{}),
+		func(typ, name string, mgr fwk.App) (fwk.Component, error) {
+			var err error
+			tsk := &task6{
+				TaskBase: fwk.NewTask(typ, name, mgr),
+			}
+
+			err = tsk.DeclProp("Inputs", &tsk.inputs)
+			if err != nil {
+				return nil, err
+			}
+
+			err = tsk.DeclProp("Output", &tsk.output)
+			if err != nil {
+				return nil, err
+			}
+
+			err = tsk.DeclProp("Sleep", &tsk.sleep)
+			if err != nil {
+				return nil, err
+			}
+
+			err = tsk.DeclProp("Trace", &tsk.trace)
+			if err != nil {
+				return nil, err
+			}
+
+			return tsk, err
+		},
+	)
+}
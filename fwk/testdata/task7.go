@@ -0,0 +1,78 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testdata
+
+import (
+	"fmt"
+	"reflect"
+
+	"go-hep.org/x/hep/fwk"
+)
+
+// task7 draws a random number from a svc2 service for each event, and
+// fails at a given event, to simulate the preemption of a job.
+type task7 struct {
+	fwk.TaskBase
+
+	svcname string
+	failat  int64 // ID of the event at which the task fails
+	trace   func(id int64, v uint64)
+
+	rng *svc2
+}
+
+func (tsk *task7) StartTask(ctx fwk.Context) error {
+	svc, err := ctx.Svc(tsk.svcname)
+	if err != nil {
+		return err
+	}
+	tsk.rng = svc.(*svc2)
+	return nil
+}
+
+func (tsk *task7) StopTask(ctx fwk.Context) error {
+	return nil
+}
+
+func (tsk *task7) Process(ctx fwk.Context) error {
+	if ctx.ID() == tsk.failat {
+		return fmt.Errorf("preempted at evt=%d", ctx.ID())
+	}
+	v := tsk.rng.Uint64()
+	if tsk.trace != nil {
+		tsk.trace(ctx.ID(), v)
+	}
+	return nil
+}
+
+func init() {
+	fwk.Register(reflect.TypeOf(task7{}),
+		func(typ, name string, mgr fwk.App) (fwk.Component, error) {
+			var err error
+			tsk := &task7{
+				TaskBase: fwk.NewTask(typ, name, mgr),
+				svcname:  "rng",
+				failat:   -1,
+			}
+
+			err = tsk.DeclProp("Svc", &tsk.svcname)
+			if err != nil {
+				return nil, err
+			}
+
+			err = tsk.DeclProp("FailAt", &tsk.failat)
+			if err != nil {
+				return nil, err
+			}
+
+			err = tsk.DeclProp("Trace", &tsk.trace)
+			if err != nil {
+				return nil, err
+			}
+
+			return tsk, err
+		},
+	)
+}
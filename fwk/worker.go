@@ -7,6 +7,7 @@ package fwk
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 type workercontrol struct {
@@ -14,6 +15,14 @@ type workercontrol struct {
 	done   chan struct{}
 	errc   chan error
 	runctx context.Context
+
+	evttmo time.Duration // per-event time budget
+	quar   *quarantine
+
+	graph    *taskgraph    // data dependencies between tasks
+	nthreads int           // maximum number of concurrent tasks per event
+	ckpt     *checkpointer // saves the state of the job
+	mons     []Monitor     // services monitoring the event loop
 }
 
 type worker struct {
@@ -27,6 +36,14 @@ type worker struct {
 	done   chan<- struct{}
 	errc   chan<- error
 	runctx context.Context
+
+	evttmo time.Duration
+	quar   *quarantine
+
+	graph    *taskgraph
+	nthreads int
+	ckpt     *checkpointer
+	mons     []Monitor
 }
 
 func newWorker(i int, app *appmgr, ctrl *workercontrol) *worker {
@@ -39,6 +56,13 @@ func newWorker(i int, app *appmgr, ctrl *workercontrol) *worker {
 		done:   ctrl.done,
 		errc:   ctrl.errc,
 		runctx: ctrl.runctx,
+		evttmo: ctrl.evttmo,
+		quar:   ctrl.quar,
+
+		graph:    ctrl.graph,
+		nthreads: ctrl.nthreads,
+		ckpt:     ctrl.ckpt,
+		mons:     ctrl.mons,
 	}
 	for j, tsk := range app.tsks {
 		wrk.ctxs[j] = ctxType{
@@ -77,6 +101,7 @@ func (wrk *worker) run(tsks []Task) {
 func (wrk *worker) runTask(ctx context.Context, ievt ctxType, tsks []Task) {
 	wrk.msg.Debugf(">>> running evt=%d...\n", ievt.ID())
 
+	start := time.Now()
 	evtstore := ievt.store.(*datastore)
 	evtctx, evtCancel := context.WithCancel(wrk.runctx)
 	defer evtCancel()
@@ -85,13 +110,17 @@ func (wrk *worker) runTask(ctx context.Context, ievt ctxType, tsks []Task) {
 		ievt:   ievt.ID(),
 		errc:   make(chan error, len(tsks)),
 		evtctx: evtctx,
+		mons:   wrk.mons,
 	}
-	for i, tsk := range tsks {
-		ctx := wrk.ctxs[i]
-		ctx.store = evtstore
-		ctx.ctx = evtctx
-		go evt.run(i, ctx, tsk)
+	ctxs := make([]ctxType, len(tsks))
+	for i := range tsks {
+		ctxs[i] = wrk.ctxs[i]
+		ctxs[i].store = evtstore
+		ctxs[i].ctx = evtctx
 	}
+	go evt.schedule(wrk.graph, ctxs, tsks, wrk.nthreads)
+	tmo, stop := watchdog(wrk.evttmo)
+	defer stop()
 	ndone := 0
 errloop:
 	for {
@@ -115,6 +144,15 @@ errloop:
 			evtstore.close()
 			wrk.msg.flush()
 			return
+		case <-tmo:
+			wrk.msg.Warnf("evt=%d exceeded time budget (%v): quarantined\n", ievt.ID(), wrk.evttmo)
+			wrk.quar.add(ievt.ID())
+			evtstore.close()
+			wrk.msg.flush()
+			if err := wrk.ckpt.complete(ievt.ID()); err != nil {
+				wrk.errc <- err
+			}
+			return
 		}
 	}
 	err := evtstore.reset(wrk.keys)
@@ -125,22 +163,22 @@ errloop:
 		wrk.errc <- err
 		return
 	}
+
+	for _, mon := range wrk.mons {
+		mon.ProcessedEvent(ievt.ID(), time.Since(start))
+	}
+
+	err = wrk.ckpt.complete(ievt.ID())
+	if err != nil {
+		wrk.errc <- err
+		return
+	}
 }
 
 type taskrunner struct {
 	errc   chan error
 	evtctx context.Context
+	mons   []Monitor // services monitoring the event loop
 
 	ievt int64
 }
-
-func (run taskrunner) run(i int, ctx ctxType, tsk Task) {
-	ctx.id = run.ievt
-	select {
-	case run.errc <- tsk.Process(ctx):
-		// FIXME(sbinet) dont be so eager to flush...
-		ctx.msg.flush()
-	case <-run.evtctx.Done():
-		ctx.msg.flush()
-	}
-}
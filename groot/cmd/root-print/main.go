@@ -186,6 +186,10 @@ func printObject(odir, otyp string, obj root.Object, verbose bool) error {
 
 		p.Add(hh)
 
+	case rhist.Graph2D:
+		h := rootcnv.H2DFromGraph2D(o, 40, 40)
+		p.Add(hplot.NewH2D(h, nil))
+
 	case rhist.GraphErrors:
 		h := rootcnv.S2D(o)
 		if name := h.Name(); name != "" {
@@ -39,6 +39,7 @@ type plot struct {
 
 type plotRequest struct {
 	cookie *http.Cookie
+	src    *http.Request // client request
 	req    plot
 	resp   chan plotResponse
 }
@@ -17,9 +17,17 @@
 //
 //  $> root-srv -addr :8080 -serv https -host example.com
 //  2017/04/06 15:13:59 https server listening on :8080 at example.com
+//
+// Access to the server can be restricted with a file of authentication
+// tokens, one "token user" pair per line.
+// Clients then authenticate with an "Authorization: Bearer <token>" header,
+// or by browsing to the server with a "?token=<token>" query parameter.
+//
+//  $> root-srv -addr :8080 -tokens ./tokens.txt -idle 1h -max-remote 10
 package main // import "go-hep.org/x/hep/groot/cmd/root-srv"
 
 import (
+	"bufio"
 	"crypto/tls"
 	"flag"
 	"fmt"
@@ -27,7 +35,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 
+	"go-hep.org/x/hep/groot/rsrv"
 	"golang.org/x/crypto/acme/autocert"
 )
 
@@ -35,6 +45,10 @@ var (
 	addrFlag = flag.String("addr", ":8080", "server address:port")
 	servFlag = flag.String("serv", "http", "server protocol")
 	hostFlag = flag.String("host", "", "server domain name for TLS ")
+
+	tokensFlag = flag.String("tokens", "", "file of authentication tokens (one 'token user' pair per line)")
+	idleFlag   = flag.Duration("idle", 0, "duration after which idle files are closed (0: never)")
+	remoteFlag = flag.Int("max-remote", 0, "maximum number of open remote files per user (0: no limit)")
 )
 
 func main() {
@@ -49,6 +63,8 @@ ex:
  $> root-srv -addr :8080 -serv https -host example.com
  2017/04/06 15:13:59 https server listening on :8080 at example.com
 
+ $> root-srv -addr :8080 -tokens ./tokens.txt -idle 1h -max-remote 10
+
 options:
 `,
 		)
@@ -79,7 +95,19 @@ func run(dir string, c chan os.Signal) {
 
 	log.Printf("%s server listening on %s", *servFlag, *addrFlag)
 
-	srv := newServer(*hostFlag == "", dir, http.DefaultServeMux)
+	opts := []rsrv.Option{
+		rsrv.WithIdleTimeout(*idleFlag),
+		rsrv.WithMaxRemoteFiles(*remoteFlag),
+	}
+	if *tokensFlag != "" {
+		tokens, err := loadTokens(*tokensFlag)
+		if err != nil {
+			log.Fatalf("could not load authentication tokens: %+v", err)
+		}
+		opts = append(opts, rsrv.WithTokens(tokens))
+	}
+
+	srv := newServer(*hostFlag == "", dir, http.DefaultServeMux, opts...)
 	defer srv.Shutdown()
 
 	go func() {
@@ -102,3 +130,36 @@ func run(dir string, c chan os.Signal) {
 	}()
 	<-c
 }
+
+// loadTokens loads authentication tokens from the named file.
+// Each non-empty line holds a token and the name of its user, separated by
+// white space.
+// Lines starting with '#' are ignored.
+func loadTokens(fname string) (map[string]string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("could not open tokens file: %w", err)
+	}
+	defer f.Close()
+
+	tokens := make(map[string]string)
+	sc := bufio.NewScanner(f)
+	for i := 1; sc.Scan(); i++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		toks := strings.Fields(line)
+		if len(toks) != 2 {
+			return nil, fmt.Errorf("invalid line %d in tokens file %q", i, fname)
+		}
+		tokens[toks[0]] = toks[1]
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("could not read tokens file: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no token in tokens file %q", fname)
+	}
+	return tokens, nil
+}
@@ -23,7 +23,10 @@ import (
 	"go-hep.org/x/hep/groot/rsrv"
 )
 
-const cookieName = "GROOT_SRV"
+const (
+	cookieName      = "GROOT_SRV"
+	tokenCookieName = "GROOT_SRV_TOKEN"
+)
 
 type server struct {
 	local bool
@@ -35,10 +38,10 @@ type server struct {
 	cookies map[string]*http.Cookie
 }
 
-func newServer(local bool, dir string, mux *http.ServeMux) *server {
+func newServer(local bool, dir string, mux *http.ServeMux, opts ...rsrv.Option) *server {
 	app := &server{
 		local:   local,
-		srv:     rsrv.New(dir),
+		srv:     rsrv.New(dir, opts...),
 		quit:    make(chan int),
 		cmds:    make(chan plotRequest),
 		cookies: make(map[string]*http.Cookie),
@@ -55,6 +58,7 @@ func newServer(local bool, dir string, mux *http.ServeMux) *server {
 	mux.HandleFunc("/plot-h2", app.srv.PlotH2)
 	mux.HandleFunc("/plot-s2", app.srv.PlotS2)
 	mux.HandleFunc("/plot-branch", app.srv.PlotTree)
+	mux.HandleFunc("/hist-tree", app.srv.HistTree)
 
 	return app
 }
@@ -149,6 +153,16 @@ func (srv *server) rootHandle(w http.ResponseWriter, r *http.Request) error {
 		return fmt.Errorf("invalid request %q for /", r.Method)
 	}
 
+	if token := r.URL.Query().Get("token"); token != "" {
+		cookie := &http.Cookie{
+			Name:     tokenCookieName,
+			Value:    token,
+			HttpOnly: true,
+		}
+		http.SetCookie(w, cookie)
+		r.AddCookie(cookie)
+	}
+
 	crutime := time.Now().Unix()
 	h := md5.New()
 	_, err := io.WriteString(h, strconv.FormatInt(crutime, 10))
@@ -185,6 +199,7 @@ func (srv *server) uploadHandle(w http.ResponseWriter, r *http.Request) error {
 		return fmt.Errorf("could not create upload-file request: %w", err)
 	}
 	req.AddCookie(cookie)
+	forwardToken(req, r)
 	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
 
 	ww := newResponseWriter()
@@ -230,6 +245,7 @@ func (srv *server) openHandle(w http.ResponseWriter, r *http.Request) error {
 		return fmt.Errorf("could not create open-file request: %w", err)
 	}
 	req.AddCookie(cookie)
+	forwardToken(req, r)
 
 	ww := newResponseWriter()
 	srv.srv.OpenFile(ww, req)
@@ -276,6 +292,7 @@ func (srv *server) plotHandle(w http.ResponseWriter, r *http.Request) error {
 
 	cmd := plotRequest{
 		cookie: cookie,
+		src:    r,
 		req:    req,
 		resp:   make(chan plotResponse),
 	}
@@ -364,6 +381,7 @@ func (srv *server) process(preq plotRequest) {
 		return
 	}
 	hreq.AddCookie(preq.cookie)
+	forwardToken(hreq, preq.src)
 
 	w := newResponseWriter()
 	w.code = http.StatusInternalServerError
@@ -416,6 +434,7 @@ func (srv *server) ping(r *http.Request) error {
 		return err
 	}
 	req.AddCookie(cookie)
+	forwardToken(req, r)
 
 	ww := newResponseWriter()
 	srv.srv.Ping(ww, req)
@@ -426,3 +445,14 @@ func (srv *server) ping(r *http.Request) error {
 
 	return nil
 }
+
+// forwardToken forwards the authentication token of the client request,
+// if any, to the request sent to the ROOT server.
+func forwardToken(dst, src *http.Request) {
+	if auth := src.Header.Get("Authorization"); auth != "" {
+		dst.Header.Set("Authorization", auth)
+	}
+	if cookie, err := src.Cookie(tokenCookieName); err == nil {
+		dst.AddCookie(cookie)
+	}
+}
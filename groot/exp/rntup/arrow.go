@@ -0,0 +1,218 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rntup
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+)
+
+// Schema returns the Arrow schema of the RNTuple.
+// Collections are mapped to Arrow lists and records to Arrow structs.
+func (r *Reader) Schema() *arrow.Schema {
+	fields := make([]arrow.Field, len(r.fields))
+	for i, n := range r.fields {
+		fields[i] = arrowField(n)
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+func arrowField(n *node) arrow.Field {
+	return arrow.Field{
+		Name: n.fd.Name,
+		Type: arrowType(n),
+	}
+}
+
+func arrowType(n *node) arrow.DataType {
+	switch n.kind {
+	case reflect.Bool:
+		return arrow.FixedWidthTypes.Boolean
+	case reflect.Int8:
+		return arrow.PrimitiveTypes.Int8
+	case reflect.Int16:
+		return arrow.PrimitiveTypes.Int16
+	case reflect.Int32:
+		return arrow.PrimitiveTypes.Int32
+	case reflect.Int64:
+		return arrow.PrimitiveTypes.Int64
+	case reflect.Uint8:
+		return arrow.PrimitiveTypes.Uint8
+	case reflect.Uint16:
+		return arrow.PrimitiveTypes.Uint16
+	case reflect.Uint32:
+		return arrow.PrimitiveTypes.Uint32
+	case reflect.Uint64:
+		return arrow.PrimitiveTypes.Uint64
+	case reflect.Float32:
+		return arrow.PrimitiveTypes.Float32
+	case reflect.Float64:
+		return arrow.PrimitiveTypes.Float64
+	case reflect.String:
+		return arrow.BinaryTypes.String
+	case reflect.Slice:
+		return arrow.ListOf(arrowType(n.subs[0]))
+	case reflect.Struct:
+		fields := make([]arrow.Field, len(n.subs))
+		for i, sub := range n.subs {
+			fields[i] = arrowField(sub)
+		}
+		return arrow.StructOf(fields...)
+	}
+	panic(fmt.Errorf("rntup: invalid field kind %v", n.kind))
+}
+
+// RecordReader is an Arrow RecordReader for RNTuples.
+//
+// RecordReader reads the pages of the RNTuple directly, and creates one
+// record per cluster.
+// RecordReader does not materialize more than one record at a time.
+type RecordReader struct {
+	refs int64
+
+	mem    memory.Allocator
+	schema *arrow.Schema
+	r      *Reader
+
+	cur int // index of the next cluster to read.
+	rec array.Record
+	err error
+}
+
+// NewRecordReader creates a new Arrow RecordReader from the provided
+// RNTuple reader.
+// The Go allocator is used if mem is nil.
+func NewRecordReader(r *Reader, mem memory.Allocator) *RecordReader {
+	if mem == nil {
+		mem = memory.NewGoAllocator()
+	}
+	return &RecordReader{
+		refs:   1,
+		mem:    mem,
+		schema: r.Schema(),
+		r:      r,
+	}
+}
+
+// Retain increases the reference count by 1.
+// Retain may be called simultaneously from multiple goroutines.
+func (rr *RecordReader) Retain() {
+	atomic.AddInt64(&rr.refs, 1)
+}
+
+// Release decreases the reference count by 1.
+// When the reference count goes to zero, the memory is freed.
+// Release may be called simultaneously from multiple goroutines.
+func (rr *RecordReader) Release() {
+	if atomic.LoadInt64(&rr.refs) <= 0 {
+		panic("groot/rntup: too many releases")
+	}
+
+	if atomic.AddInt64(&rr.refs, -1) == 0 {
+		if rr.rec != nil {
+			rr.rec.Release()
+			rr.rec = nil
+		}
+	}
+}
+
+func (rr *RecordReader) Schema() *arrow.Schema { return rr.schema }
+func (rr *RecordReader) Record() array.Record  { return rr.rec }
+
+// Err returns the error that stopped the iteration over the clusters, if any.
+func (rr *RecordReader) Err() error { return rr.err }
+
+func (rr *RecordReader) Next() bool {
+	if rr.err != nil || rr.cur >= len(rr.r.ftr.Clusters) {
+		return false
+	}
+
+	if rr.rec != nil {
+		rr.rec.Release()
+		rr.rec = nil
+	}
+
+	rec, err := rr.load(&rr.r.ftr.Clusters[rr.cur])
+	if err != nil {
+		rr.err = err
+		return false
+	}
+	rr.rec = rec
+	rr.cur++
+	return true
+}
+
+func (rr *RecordReader) load(cl *Cluster) (array.Record, error) {
+	vs, err := rr.r.load(cl)
+	if err != nil {
+		return nil, err
+	}
+
+	bldr := array.NewRecordBuilder(rr.mem, rr.schema)
+	defer bldr.Release()
+
+	for i, n := range rr.r.fields {
+		appendRange(bldr.Field(i), n, vs[i], 0, vs[i].n)
+	}
+	return bldr.NewRecord(), nil
+}
+
+// appendRange appends the elements [beg, end) of the field n to bldr.
+func appendRange(bldr array.Builder, n *node, d *data, beg, end int) {
+	switch bldr := bldr.(type) {
+	case *array.BooleanBuilder:
+		bldr.AppendValues(d.values.([]bool)[beg:end], nil)
+	case *array.Int8Builder:
+		bldr.AppendValues(d.values.([]int8)[beg:end], nil)
+	case *array.Int16Builder:
+		bldr.AppendValues(d.values.([]int16)[beg:end], nil)
+	case *array.Int32Builder:
+		bldr.AppendValues(d.values.([]int32)[beg:end], nil)
+	case *array.Int64Builder:
+		bldr.AppendValues(d.values.([]int64)[beg:end], nil)
+	case *array.Uint8Builder:
+		bldr.AppendValues(d.values.([]uint8)[beg:end], nil)
+	case *array.Uint16Builder:
+		bldr.AppendValues(d.values.([]uint16)[beg:end], nil)
+	case *array.Uint32Builder:
+		bldr.AppendValues(d.values.([]uint32)[beg:end], nil)
+	case *array.Uint64Builder:
+		bldr.AppendValues(d.values.([]uint64)[beg:end], nil)
+	case *array.Float32Builder:
+		bldr.AppendValues(d.values.([]float32)[beg:end], nil)
+	case *array.Float64Builder:
+		bldr.AppendValues(d.values.([]float64)[beg:end], nil)
+	case *array.StringBuilder:
+		for i := beg; i < end; i++ {
+			b, e := d.span(i)
+			bldr.Append(string(d.chars[b:e]))
+		}
+	case *array.ListBuilder:
+		sub := bldr.ValueBuilder()
+		for i := beg; i < end; i++ {
+			bldr.Append(true)
+			b, e := d.span(i)
+			appendRange(sub, n.subs[0], d.subs[0], b, e)
+		}
+	case *array.StructBuilder:
+		for i := beg; i < end; i++ {
+			bldr.Append(true)
+		}
+		for i, sub := range n.subs {
+			appendRange(bldr.FieldBuilder(i), sub, d.subs[i], beg, end)
+		}
+	default:
+		panic(fmt.Errorf("rntup: invalid Arrow builder %T", bldr))
+	}
+}
+
+var (
+	_ array.RecordReader = (*RecordReader)(nil)
+)
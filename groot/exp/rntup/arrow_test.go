@@ -0,0 +1,97 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rntup
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+)
+
+func TestRecordReader(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rr := NewRecordReader(newTestNTuple(t), mem)
+	defer rr.Release()
+
+	want := `schema:
+  fields: 5
+    - n: type=int32
+    - vf: type=list<item: float32>
+    - p: type=struct<x: float64, s: utf8>
+    - ok: type=bool
+    - vv: type=list<item: list<item: int16>>`
+	if got := rr.Schema().String(); got != want {
+		t.Fatalf("invalid schema:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	var got []string
+	for rr.Next() {
+		rec := rr.Record()
+		for i, col := range rec.Columns() {
+			got = append(got, fmt.Sprintf("%s: %v", rec.ColumnName(i), col))
+		}
+	}
+	if err := rr.Err(); err != nil {
+		t.Fatalf("could not read records: %+v", err)
+	}
+
+	want = strings.Join([]string{
+		// cluster 0
+		`n: [1 2]`,
+		`vf: [[1 2] []]`,
+		`p: {[1.5 2.5] ["a" ""]}`,
+		`ok: [true false]`,
+		`vv: [[[1] []] []]`,
+		// cluster 1
+		`n: [3]`,
+		`vf: [[3]]`,
+		`p: {[3.5] ["xyz"]}`,
+		`ok: [true]`,
+		`vv: [[[2 3]]]`,
+	}, "\n")
+	if got := strings.Join(got, "\n"); got != want {
+		t.Fatalf("invalid records:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRecordReaderStaff(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	rr := NewRecordReader(openStaff(t), mem)
+	defer rr.Release()
+
+	if got, want := len(rr.Schema().Fields()), 11; got != want {
+		t.Fatalf("invalid number of fields: got=%d, want=%d", got, want)
+	}
+
+	var n int64
+	for rr.Next() {
+		rec := rr.Record()
+		if n == 0 {
+			cat := rec.Column(0).(*array.Int32).Int32Values()[:3]
+			if got, want := cat, []int32{202, 530, 316}; !reflect.DeepEqual(got, want) {
+				t.Fatalf("invalid Category: got=%v, want=%v", got, want)
+			}
+			nation := rec.Column(10).(*array.String)
+			if got, want := nation.Value(2), "FR"; got != want {
+				t.Fatalf("invalid Nation: got=%q, want=%q", got, want)
+			}
+		}
+		n += rec.NumRows()
+	}
+	if err := rr.Err(); err != nil {
+		t.Fatalf("could not read records: %+v", err)
+	}
+	if got, want := n, int64(3354); got != want {
+		t.Fatalf("invalid number of rows: got=%d, want=%d", got, want)
+	}
+}
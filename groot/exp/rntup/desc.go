@@ -0,0 +1,412 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rntup
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+// Structure describes how a field is mapped onto its columns and sub-fields.
+type Structure uint32
+
+const (
+	Leaf       Structure = 0 // field with its own columns, e.g. a float or a std::string.
+	Collection Structure = 1 // variable-size collection of its sub-field, e.g. a std::vector<T>.
+	Record     Structure = 2 // fixed set of sub-fields, e.g. a struct.
+	Variant    Structure = 3 // one of its sub-fields, e.g. a std::variant<T...>.
+	Reference  Structure = 4 // reference to another field.
+)
+
+func (s Structure) String() string {
+	switch s {
+	case Leaf:
+		return "leaf"
+	case Collection:
+		return "collection"
+	case Record:
+		return "record"
+	case Variant:
+		return "variant"
+	case Reference:
+		return "reference"
+	}
+	return fmt.Sprintf("Structure(%d)", uint32(s))
+}
+
+// ColumnType is the on-disk type of the elements of a column.
+type ColumnType uint32
+
+const (
+	ColumnUnknown ColumnType = 0
+	ColumnIndex   ColumnType = 1 // offsets of collections, relative to the cluster.
+	ColumnSwitch  ColumnType = 2 // index and tag of variants.
+	ColumnByte    ColumnType = 3
+	ColumnBit     ColumnType = 4
+	ColumnReal64  ColumnType = 5
+	ColumnReal32  ColumnType = 6
+	ColumnReal16  ColumnType = 7
+	ColumnReal8   ColumnType = 8
+	ColumnInt64   ColumnType = 9
+	ColumnInt32   ColumnType = 10
+	ColumnInt16   ColumnType = 11
+)
+
+func (ct ColumnType) String() string {
+	switch ct {
+	case ColumnUnknown:
+		return "unknown"
+	case ColumnIndex:
+		return "index"
+	case ColumnSwitch:
+		return "switch"
+	case ColumnByte:
+		return "byte"
+	case ColumnBit:
+		return "bit"
+	case ColumnReal64:
+		return "real64"
+	case ColumnReal32:
+		return "real32"
+	case ColumnReal16:
+		return "real16"
+	case ColumnReal8:
+		return "real8"
+	case ColumnInt64:
+		return "int64"
+	case ColumnInt32:
+		return "int32"
+	case ColumnInt16:
+		return "int16"
+	}
+	return fmt.Sprintf("ColumnType(%d)", uint32(ct))
+}
+
+// size returns the size in bytes of n elements of the column type.
+func (ct ColumnType) size(n int) int {
+	switch ct {
+	case ColumnByte, ColumnReal8:
+		return n
+	case ColumnBit:
+		return (n + 7) / 8
+	case ColumnReal16, ColumnInt16:
+		return 2 * n
+	case ColumnIndex, ColumnReal32, ColumnInt32:
+		return 4 * n
+	case ColumnSwitch, ColumnReal64, ColumnInt64:
+		return 8 * n
+	}
+	return -1
+}
+
+// invalidID is the parent ID of the zero field, the root of the fields tree.
+const invalidID = math.MaxUint64
+
+// Header describes the schema of an RNTuple.
+type Header struct {
+	Name        string
+	Description string
+	Author      string
+	Custodian   string
+
+	Fields  []Field
+	Columns []Column
+}
+
+// Field describes a field of an RNTuple.
+type Field struct {
+	ID           uint64
+	Name         string
+	Description  string
+	Type         string // C++ type name of the field.
+	NRepetitions uint64 // number of items of fixed-size arrays.
+	Structure    Structure
+	Parent       uint64   // ID of the parent field.
+	Links        []uint64 // IDs of the sub-fields, in order.
+}
+
+// Column describes a column of an RNTuple.
+type Column struct {
+	ID    uint64
+	Type  ColumnType
+	Field uint64 // ID of the field the column belongs to.
+	Index uint32 // index of the column among the columns of its field.
+}
+
+// Footer describes the clusters of an RNTuple.
+type Footer struct {
+	Clusters []Cluster
+}
+
+// Cluster describes a range of entries of an RNTuple, and the location of
+// the pages holding their data.
+type Cluster struct {
+	ID         uint64
+	FirstEntry uint64
+	NEntries   uint64
+	Columns    []ColumnRange
+}
+
+// ColumnRange describes the elements of a column within a cluster.
+type ColumnRange struct {
+	Column       uint64 // ID of the column.
+	FirstElement uint64
+	NElements    uint32
+	Compression  int64 // compression settings of the pages.
+	Pages        []Page
+}
+
+// Page describes a page of elements of a column.
+type Page struct {
+	NElements uint32
+	Locator   Locator
+}
+
+// Locator describes the location of a page.
+type Locator struct {
+	Pos    int64  // offset of the page in the file.
+	NBytes uint32 // size of the page on storage.
+	URL    string
+}
+
+// postscriptLen is the size of the trailer of the footer: the versions of
+// the format, the sizes of the header and of the footer, and the checksum of
+// the footer.
+const postscriptLen = 16
+
+// unmarshalHeader decodes the (uncompressed) header of an RNTuple.
+func unmarshalHeader(raw []byte) (*Header, error) {
+	r, err := newRBuffer(raw)
+	if err != nil {
+		return nil, fmt.Errorf("rntup: invalid header: %w", err)
+	}
+
+	var hdr Header
+	r.frame()
+	r.skip(8)
+	hdr.Name = r.str()
+	hdr.Description = r.str()
+	hdr.Author = r.str()
+	hdr.Custodian = r.str()
+	r.skip(8 + 8) // time stamps of data and of writing.
+	r.version()
+	r.uuid() // own UUID.
+	r.uuid() // group UUID.
+
+	hdr.Fields = make([]Field, r.count(8))
+	for i := range hdr.Fields {
+		end := r.frame()
+		fd := &hdr.Fields[i]
+		fd.ID = r.u64()
+		r.version() // field version.
+		r.version() // type version.
+		fd.Name = r.str()
+		fd.Description = r.str()
+		fd.Type = r.str()
+		fd.NRepetitions = r.u64()
+		fd.Structure = Structure(r.u32())
+		fd.Parent = r.u64()
+		fd.Links = make([]uint64, r.count(8))
+		for j := range fd.Links {
+			fd.Links[j] = r.u64()
+		}
+		r.seek(end)
+	}
+
+	hdr.Columns = make([]Column, r.count(8))
+	for i := range hdr.Columns {
+		end := r.frame()
+		col := &hdr.Columns[i]
+		col.ID = r.u64()
+		r.version()
+		model := r.frame()
+		col.Type = ColumnType(r.u32())
+		r.seek(model)
+		col.Field = r.u64()
+		col.Index = r.u32()
+		r.seek(end)
+	}
+
+	if r.err != nil {
+		return nil, fmt.Errorf("rntup: invalid header: %w", r.err)
+	}
+	return &hdr, nil
+}
+
+// unmarshalFooter decodes the (uncompressed) footer of an RNTuple.
+func unmarshalFooter(raw []byte) (*Footer, error) {
+	if len(raw) < postscriptLen {
+		return nil, fmt.Errorf("rntup: invalid footer: too short (%d bytes)", len(raw))
+	}
+	r, err := newRBuffer(raw)
+	if err != nil {
+		return nil, fmt.Errorf("rntup: invalid footer: %w", err)
+	}
+	r.buf = r.buf[:len(raw)-postscriptLen]
+
+	var ftr Footer
+	r.frame()
+	r.skip(8)
+	ftr.Clusters = make([]Cluster, r.count64(8))
+	for i := range ftr.Clusters {
+		cl := &ftr.Clusters[i]
+		r.uuid()
+		end := r.frame()
+		cl.ID = r.u64()
+		r.version()
+		cl.FirstEntry = r.u64()
+		cl.NEntries = r.u64()
+		r.locator() // location of the whole cluster.
+		r.seek(end)
+
+		cl.Columns = make([]ColumnRange, r.count(8))
+		for j := range cl.Columns {
+			col := &cl.Columns[j]
+			col.Column = r.u64()
+			col.FirstElement = r.u64()
+			col.NElements = r.u32()
+			col.Compression = r.i64()
+			col.Pages = make([]Page, r.count(4))
+			for k := range col.Pages {
+				col.Pages[k].NElements = r.u32()
+				col.Pages[k].Locator = r.locator()
+			}
+		}
+	}
+
+	switch {
+	case r.err != nil:
+		return nil, fmt.Errorf("rntup: invalid footer: %w", r.err)
+	case r.pos != len(r.buf):
+		return nil, fmt.Errorf("rntup: invalid footer: %d trailing bytes", len(r.buf)-r.pos)
+	}
+	return &ftr, nil
+}
+
+// rbuffer decodes the little-endian serialization of RNTuple descriptors.
+type rbuffer struct {
+	buf []byte
+	pos int
+	err error
+}
+
+// newRBuffer checks the CRC32 checksum trailing raw, and returns a buffer
+// reading the rest of raw.
+func newRBuffer(raw []byte) (*rbuffer, error) {
+	if len(raw) < 4 {
+		return nil, fmt.Errorf("too short (%d bytes)", len(raw))
+	}
+	n := len(raw) - 4
+	if got, want := crc32.ChecksumIEEE(raw[:n]), binary.LittleEndian.Uint32(raw[n:]); got != want {
+		return nil, fmt.Errorf("invalid checksum (got=0x%08x, want=0x%08x)", got, want)
+	}
+	return &rbuffer{buf: raw[:n]}, nil
+}
+
+func (r *rbuffer) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.buf) {
+		r.err = fmt.Errorf("could not read %d bytes at %d: buffer too short (%d bytes)", n, r.pos, len(r.buf))
+		return nil
+	}
+	p := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return p
+}
+
+func (r *rbuffer) skip(n int) { r.next(n) }
+
+// seek moves the read position to pos, the end of a frame.
+func (r *rbuffer) seek(pos int) {
+	if r.err != nil {
+		return
+	}
+	if pos < r.pos || pos > len(r.buf) {
+		r.err = fmt.Errorf("invalid frame end %d (pos=%d)", pos, r.pos)
+		return
+	}
+	r.pos = pos
+}
+
+func (r *rbuffer) u32() uint32 {
+	p := r.next(4)
+	if p == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(p)
+}
+
+func (r *rbuffer) u64() uint64 {
+	p := r.next(8)
+	if p == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(p)
+}
+
+func (r *rbuffer) i64() int64 { return int64(r.u64()) }
+
+// count reads the number of items of a list, whose items are serialized
+// with at least size bytes each.
+func (r *rbuffer) count(size int) int {
+	return r.checkCount(uint64(r.u32()), size)
+}
+
+func (r *rbuffer) count64(size int) int {
+	return r.checkCount(r.u64(), size)
+}
+
+func (r *rbuffer) checkCount(n uint64, size int) int {
+	if r.err != nil {
+		return 0
+	}
+	if n > uint64((len(r.buf)-r.pos)/size) {
+		r.err = fmt.Errorf("invalid number of items %d at %d: buffer too short (%d bytes)", n, r.pos, len(r.buf))
+		return 0
+	}
+	return int(n)
+}
+
+func (r *rbuffer) str() string {
+	n := r.u32()
+	return string(r.next(int(n)))
+}
+
+// frame reads the header of a frame (its versions and size) and returns the
+// position of the end of the frame.
+func (r *rbuffer) frame() int {
+	beg := r.pos
+	r.skip(4) // current and minimal versions.
+	n := int(r.u32())
+	if n == 0 {
+		// frames of the header and footer do not record their size.
+		return len(r.buf)
+	}
+	return beg + n
+}
+
+// version reads a version frame.
+func (r *rbuffer) version() {
+	r.seek(r.frame())
+}
+
+// uuid reads a UUID frame.
+func (r *rbuffer) uuid() {
+	end := r.frame()
+	r.str()
+	r.seek(end)
+}
+
+func (r *rbuffer) locator() Locator {
+	return Locator{
+		Pos:    r.i64(),
+		NBytes: r.u32(),
+		URL:    r.str(),
+	}
+}
@@ -0,0 +1,449 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rntup
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"reflect"
+)
+
+// Parquet physical types.
+const (
+	pqBoolean   = 0
+	pqInt32     = 1
+	pqInt64     = 2
+	pqFloat     = 4
+	pqDouble    = 5
+	pqByteArray = 6
+)
+
+// Parquet converted types.
+const (
+	pqUTF8   = 0
+	pqList   = 3
+	pqUint8  = 11
+	pqUint16 = 12
+	pqUint32 = 13
+	pqUint64 = 14
+	pqInt8   = 15
+	pqInt16  = 16
+)
+
+// Parquet repetition types.
+const (
+	pqRequired = 0
+	pqRepeated = 2
+)
+
+// Parquet encodings.
+const (
+	pqPlain = 0
+	pqRLE   = 3
+)
+
+const pqMagic = "PAR1"
+
+// WriteParquet writes the content of the RNTuple read by r to w, as a
+// Parquet file with one row group per cluster.
+//
+// Collections are written as Parquet LIST groups and records as Parquet
+// groups. All fields are required.
+// Pages are written uncompressed and PLAIN encoded.
+func WriteParquet(w io.Writer, r *Reader) error {
+	pw := &pqWriter{w: w, leaves: make(map[*node]*pqLeaf)}
+	err := pw.write([]byte(pqMagic))
+	if err != nil {
+		return fmt.Errorf("rntup: could not write parquet header: %w", err)
+	}
+
+	schema := newTEncoder()
+	schema.list(tStruct, 1+pqSchemaSize(r.fields))
+	pqSchemaElement(schema, "schema", -1, pqRequired, len(r.fields), -1)
+	for _, n := range r.fields {
+		pw.schema(schema, n, n.fd.Name, nil)
+	}
+
+	var (
+		groups = newTEncoder()
+		nrows  int64
+	)
+	groups.list(tStruct, len(r.ftr.Clusters))
+	for i := range r.ftr.Clusters {
+		cl := &r.ftr.Clusters[i]
+		vs, err := r.load(cl)
+		if err != nil {
+			return err
+		}
+		for _, leaf := range pw.order {
+			leaf.reset()
+		}
+		for i, n := range r.fields {
+			for j := 0; j < vs[i].n; j++ {
+				pw.shred(n, vs[i], j, 0, 0)
+			}
+		}
+
+		groups.beginStruct()
+		groups.field(1, tList)
+		groups.list(tStruct, len(pw.order))
+		var size int64
+		for _, leaf := range pw.order {
+			n, err := pw.writeChunk(groups, leaf)
+			if err != nil {
+				return fmt.Errorf("rntup: could not write column %q: %w", leaf.path, err)
+			}
+			size += n
+		}
+		groups.field(2, tI64)
+		groups.i64(size)
+		groups.field(3, tI64)
+		groups.i64(int64(cl.NEntries))
+		groups.endStruct()
+		nrows += int64(cl.NEntries)
+	}
+
+	meta := newTEncoder()
+	meta.beginStruct()
+	meta.field(1, tI32)
+	meta.i32(1)
+	meta.field(2, tList)
+	meta.raw(schema.buf)
+	meta.field(3, tI64)
+	meta.i64(nrows)
+	meta.field(4, tList)
+	meta.raw(groups.buf)
+	meta.field(6, tBinary)
+	meta.str("go-hep.org/x/hep/groot/exp/rntup")
+	meta.endStruct()
+
+	var footer [8]byte
+	binary.LittleEndian.PutUint32(footer[:4], uint32(len(meta.buf)))
+	copy(footer[4:], pqMagic)
+	err = pw.write(append(meta.buf, footer[:]...))
+	if err != nil {
+		return fmt.Errorf("rntup: could not write parquet footer: %w", err)
+	}
+	return nil
+}
+
+// pqWriter writes the column chunks of a Parquet file.
+type pqWriter struct {
+	w   io.Writer
+	pos int64 // current offset in the file.
+
+	leaves map[*node]*pqLeaf
+	order  []*pqLeaf // leaves, in schema order.
+}
+
+// pqLeaf holds the shredded values of a leaf field, within a row group.
+type pqLeaf struct {
+	n     *node
+	path  []string
+	level int // maximum repetition and definition levels.
+
+	reps []int
+	defs []int
+	vals []byte // PLAIN encoded values.
+	bits []bool // values of boolean leaves, bit-packed when written.
+}
+
+func (leaf *pqLeaf) reset() {
+	leaf.reps = leaf.reps[:0]
+	leaf.defs = leaf.defs[:0]
+	leaf.vals = leaf.vals[:0]
+	leaf.bits = leaf.bits[:0]
+}
+
+func (pw *pqWriter) write(p []byte) error {
+	n, err := pw.w.Write(p)
+	pw.pos += int64(n)
+	return err
+}
+
+// pqSchemaSize returns the number of schema elements of the fields ns.
+func pqSchemaSize(ns []*node) int {
+	n := 0
+	for _, sub := range ns {
+		n += 1 + pqSchemaSize(sub.subs)
+		if sub.kind == reflect.Slice {
+			n++ // repeated "list" group.
+		}
+	}
+	return n
+}
+
+// schema writes the schema elements of the field n, named name, and
+// registers its leaves.
+func (pw *pqWriter) schema(enc *tEncoder, n *node, name string, path []string) {
+	path = append(path[:len(path):len(path)], name)
+	switch n.kind {
+	case reflect.Slice:
+		pqSchemaElement(enc, name, -1, pqRequired, 1, pqList)
+		pqSchemaElement(enc, "list", -1, pqRepeated, 1, -1)
+		pw.schema(enc, n.subs[0], "element", append(path, "list"))
+		return
+	case reflect.Struct:
+		pqSchemaElement(enc, name, -1, pqRequired, len(n.subs), -1)
+		for _, sub := range n.subs {
+			pw.schema(enc, sub, sub.fd.Name, path)
+		}
+		return
+	}
+
+	typ, conv := pqTypeOf(n.kind)
+	pqSchemaElement(enc, name, typ, pqRequired, -1, conv)
+	leaf := &pqLeaf{n: n, path: path, level: n.rep}
+	pw.leaves[n] = leaf
+	pw.order = append(pw.order, leaf)
+}
+
+// shred appends the i-th element of the field n to the leaves of n, with
+// the repetition level rep and the definition level def.
+func (pw *pqWriter) shred(n *node, d *data, i, rep, def int) {
+	switch n.kind {
+	case reflect.Struct:
+		for j, sub := range n.subs {
+			pw.shred(sub, d.subs[j], i, rep, def)
+		}
+		return
+	case reflect.Slice:
+		beg, end := d.span(i)
+		if beg == end {
+			pw.empty(n.subs[0], rep, def)
+			return
+		}
+		for k := beg; k < end; k++ {
+			if k > beg {
+				rep = n.rep + 1
+			}
+			pw.shred(n.subs[0], d.subs[0], k, rep, def+1)
+		}
+		return
+	}
+
+	leaf := pw.leaves[n]
+	leaf.reps = append(leaf.reps, rep)
+	leaf.defs = append(leaf.defs, def)
+	switch vs := d.values.(type) {
+	case []bool:
+		leaf.bits = append(leaf.bits, vs[i])
+	case []int8:
+		leaf.vals = appendU32(leaf.vals, uint32(int32(vs[i])))
+	case []int16:
+		leaf.vals = appendU32(leaf.vals, uint32(int32(vs[i])))
+	case []int32:
+		leaf.vals = appendU32(leaf.vals, uint32(vs[i]))
+	case []int64:
+		leaf.vals = appendU64(leaf.vals, uint64(vs[i]))
+	case []uint8:
+		leaf.vals = appendU32(leaf.vals, uint32(vs[i]))
+	case []uint16:
+		leaf.vals = appendU32(leaf.vals, uint32(vs[i]))
+	case []uint32:
+		leaf.vals = appendU32(leaf.vals, vs[i])
+	case []uint64:
+		leaf.vals = appendU64(leaf.vals, vs[i])
+	case []float32:
+		leaf.vals = appendU32(leaf.vals, math.Float32bits(vs[i]))
+	case []float64:
+		leaf.vals = appendU64(leaf.vals, math.Float64bits(vs[i]))
+	case nil:
+		beg, end := d.span(i)
+		leaf.vals = appendU32(leaf.vals, uint32(end-beg))
+		leaf.vals = append(leaf.vals, d.chars[beg:end]...)
+	default:
+		panic(fmt.Errorf("rntup: invalid leaf values %T", vs))
+	}
+}
+
+// empty appends an empty collection to the leaves of the field n.
+func (pw *pqWriter) empty(n *node, rep, def int) {
+	if leaf, ok := pw.leaves[n]; ok {
+		leaf.reps = append(leaf.reps, rep)
+		leaf.defs = append(leaf.defs, def)
+		return
+	}
+	for _, sub := range n.subs {
+		pw.empty(sub, rep, def)
+	}
+}
+
+// writeChunk writes the column chunk of leaf as a single data page, and
+// encodes its metadata into enc.
+// writeChunk returns the number of bytes written.
+func (pw *pqWriter) writeChunk(enc *tEncoder, leaf *pqLeaf) (int64, error) {
+	var body []byte
+	if leaf.level > 0 {
+		body = appendLevels(body, leaf.reps, leaf.level)
+		body = appendLevels(body, leaf.defs, leaf.level)
+	}
+	if leaf.n.kind == reflect.Bool {
+		body = appendBits(body, leaf.bits)
+	} else {
+		body = append(body, leaf.vals...)
+	}
+
+	hdr := newTEncoder()
+	hdr.beginStruct()
+	hdr.field(1, tI32)
+	hdr.i32(0) // DATA_PAGE
+	hdr.field(2, tI32)
+	hdr.i32(int32(len(body)))
+	hdr.field(3, tI32)
+	hdr.i32(int32(len(body)))
+	hdr.field(5, tStruct)
+	hdr.beginStruct()
+	hdr.field(1, tI32)
+	hdr.i32(int32(len(leaf.defs)))
+	hdr.field(2, tI32)
+	hdr.i32(pqPlain)
+	hdr.field(3, tI32)
+	hdr.i32(pqRLE)
+	hdr.field(4, tI32)
+	hdr.i32(pqRLE)
+	hdr.endStruct()
+	hdr.endStruct()
+
+	pos := pw.pos
+	err := pw.write(append(hdr.buf, body...))
+	if err != nil {
+		return 0, err
+	}
+	size := pw.pos - pos
+
+	typ, _ := pqTypeOf(leaf.n.kind)
+	enc.beginStruct()
+	enc.field(2, tI64)
+	enc.i64(pos)
+	enc.field(3, tStruct)
+	enc.beginStruct()
+	enc.field(1, tI32)
+	enc.i32(int32(typ))
+	enc.field(2, tList)
+	enc.list(tI32, 2)
+	enc.i32(pqPlain)
+	enc.i32(pqRLE)
+	enc.field(3, tList)
+	enc.list(tBinary, len(leaf.path))
+	for _, name := range leaf.path {
+		enc.str(name)
+	}
+	enc.field(4, tI32)
+	enc.i32(0) // UNCOMPRESSED
+	enc.field(5, tI64)
+	enc.i64(int64(len(leaf.defs)))
+	enc.field(6, tI64)
+	enc.i64(size)
+	enc.field(7, tI64)
+	enc.i64(size)
+	enc.field(9, tI64)
+	enc.i64(pos)
+	enc.endStruct()
+	enc.endStruct()
+
+	return size, nil
+}
+
+// appendLevels appends the levels vs, at most level, RLE encoded and
+// prefixed with their size, to buf.
+func appendLevels(buf []byte, vs []int, level int) []byte {
+	var (
+		beg   = len(buf)
+		width = (bits.Len(uint(level)) + 7) / 8
+	)
+	buf = append(buf, 0, 0, 0, 0)
+	for i := 0; i < len(vs); {
+		j := i + 1
+		for j < len(vs) && vs[j] == vs[i] {
+			j++
+		}
+		buf = appendUvarint(buf, uint64(j-i)<<1)
+		for k := 0; k < width; k++ {
+			buf = append(buf, byte(vs[i]>>(8*k)))
+		}
+		i = j
+	}
+	binary.LittleEndian.PutUint32(buf[beg:], uint32(len(buf)-beg-4))
+	return buf
+}
+
+// appendBits appends the bit-packed values vs to buf.
+func appendBits(buf []byte, vs []bool) []byte {
+	beg := len(buf)
+	buf = append(buf, make([]byte, (len(vs)+7)/8)...)
+	for i, v := range vs {
+		if v {
+			buf[beg+i/8] |= 1 << (i % 8)
+		}
+	}
+	return buf
+}
+
+// pqSchemaElement writes a Parquet SchemaElement.
+// Negative values of typ, nchildren and conv are omitted.
+func pqSchemaElement(enc *tEncoder, name string, typ, rep, nchildren, conv int) {
+	enc.beginStruct()
+	if typ >= 0 {
+		enc.field(1, tI32)
+		enc.i32(int32(typ))
+	}
+	enc.field(3, tI32)
+	enc.i32(int32(rep))
+	enc.field(4, tBinary)
+	enc.str(name)
+	if nchildren >= 0 {
+		enc.field(5, tI32)
+		enc.i32(int32(nchildren))
+	}
+	if conv >= 0 {
+		enc.field(6, tI32)
+		enc.i32(int32(conv))
+	}
+	enc.endStruct()
+}
+
+// pqTypeOf returns the Parquet physical and converted types of leaves of
+// the given kind.
+func pqTypeOf(kind reflect.Kind) (typ, conv int) {
+	switch kind {
+	case reflect.Bool:
+		return pqBoolean, -1
+	case reflect.Int8:
+		return pqInt32, pqInt8
+	case reflect.Int16:
+		return pqInt32, pqInt16
+	case reflect.Int32:
+		return pqInt32, -1
+	case reflect.Int64:
+		return pqInt64, -1
+	case reflect.Uint8:
+		return pqInt32, pqUint8
+	case reflect.Uint16:
+		return pqInt32, pqUint16
+	case reflect.Uint32:
+		return pqInt32, pqUint32
+	case reflect.Uint64:
+		return pqInt64, pqUint64
+	case reflect.Float32:
+		return pqFloat, -1
+	case reflect.Float64:
+		return pqDouble, -1
+	case reflect.String:
+		return pqByteArray, pqUTF8
+	}
+	panic(fmt.Errorf("rntup: invalid leaf kind %v", kind))
+}
+
+func appendU32(buf []byte, v uint32) []byte {
+	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendU64(buf []byte, v uint64) []byte {
+	return appendU32(appendU32(buf, uint32(v)), uint32(v>>32))
+}
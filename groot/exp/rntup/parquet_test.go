@@ -0,0 +1,327 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rntup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteParquet(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteParquet(&buf, newTestNTuple(t))
+	if err != nil {
+		t.Fatalf("could not write parquet file: %+v", err)
+	}
+
+	got, err := readParquet(buf.Bytes())
+	if err != nil {
+		t.Fatalf("could not read parquet file: %+v", err)
+	}
+
+	want := pqFile{
+		schema: []string{
+			"schema(5)",
+			"n:INT32",
+			"vf(1):LIST", "list(1)*", "element:FLOAT",
+			"p(2)", "x:DOUBLE", "s:BYTE_ARRAY:UTF8",
+			"ok:BOOLEAN",
+			"vv(1):LIST", "list(1)*", "element(1):LIST", "list(1)*", "element:INT32:INT_16",
+		},
+		nrows: 3,
+		groups: [][]string{
+			{
+				"n: 1 2",
+				"vf.list.element: 0:1:1 1:1:2 0:0",
+				"p.x: 1.5 2.5",
+				"p.s: a ",
+				"ok: true false",
+				"vv.list.element.list.element: 0:2:1 1:1 0:0",
+			},
+			{
+				"n: 3",
+				"vf.list.element: 0:1:3",
+				"p.x: 3.5",
+				"p.s: xyz",
+				"ok: true",
+				"vv.list.element.list.element: 0:2:2 2:2:3",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid parquet file:\ngot= %+v\nwant=%+v", got, want)
+	}
+}
+
+func TestWriteParquetStaff(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteParquet(&buf, openStaff(t))
+	if err != nil {
+		t.Fatalf("could not write parquet file: %+v", err)
+	}
+
+	got, err := readParquet(buf.Bytes())
+	if err != nil {
+		t.Fatalf("could not read parquet file: %+v", err)
+	}
+
+	if got, want := got.nrows, int64(3354); got != want {
+		t.Fatalf("invalid number of rows: got=%d, want=%d", got, want)
+	}
+	if got, want := got.schema[:3], []string{"schema(11)", "Category:INT32", "Flag:INT32:UINT_32"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid schema: got=%q, want=%q", got, want)
+	}
+	if got, want := got.groups[0][0][:len("Category: 202 530 316 ")], "Category: 202 530 316 "; got != want {
+		t.Fatalf("invalid Category: got=%q, want=%q", got, want)
+	}
+	if got, want := got.groups[0][10][:len("Nation: DE CH FR ")], "Nation: DE CH FR "; got != want {
+		t.Fatalf("invalid Nation: got=%q, want=%q", got, want)
+	}
+}
+
+// pqFile is a textual representation of the content of a Parquet file.
+type pqFile struct {
+	schema []string   // schema elements, as name(children)[*]:type:converted-type.
+	nrows  int64      // number of rows.
+	groups [][]string // columns of row groups, as path: [rep:def:]value...
+}
+
+var (
+	pqTypeNames = map[int64]string{
+		pqBoolean: "BOOLEAN", pqInt32: "INT32", pqInt64: "INT64",
+		pqFloat: "FLOAT", pqDouble: "DOUBLE", pqByteArray: "BYTE_ARRAY",
+	}
+	pqConvNames = map[int64]string{
+		pqUTF8: "UTF8", pqList: "LIST",
+		pqUint8: "UINT_8", pqUint16: "UINT_16", pqUint32: "UINT_32", pqUint64: "UINT_64",
+		pqInt8: "INT_8", pqInt16: "INT_16",
+	}
+)
+
+// readParquet decodes the Parquet files written by WriteParquet.
+func readParquet(raw []byte) (pqFile, error) {
+	var f pqFile
+	n := len(raw)
+	if string(raw[:4]) != pqMagic || string(raw[n-4:]) != pqMagic {
+		return f, fmt.Errorf("invalid magic")
+	}
+	size := int(binary.LittleEndian.Uint32(raw[n-8:]))
+	dec := &tDecoder{buf: raw[n-8-size : n-8]}
+	meta := dec.value(tStruct).(map[int16]interface{})
+	if dec.err != nil {
+		return f, fmt.Errorf("could not decode metadata: %w", dec.err)
+	}
+
+	levels := make(map[string]int)
+	var (
+		walk  func(path []string, rep int)
+		elems = meta[2].([]interface{})
+		i     = 0
+	)
+	walk = func(path []string, rep int) {
+		elem := elems[i].(map[int16]interface{})
+		i++
+		name := elem[4].(string)
+		desc := name
+		nsubs, group := elem[5].(int64)
+		if group {
+			desc += fmt.Sprintf("(%d)", nsubs)
+		}
+		if elem[3].(int64) == pqRepeated {
+			desc += "*"
+			rep++
+		}
+		if typ, ok := elem[1].(int64); ok {
+			desc += ":" + pqTypeNames[typ]
+		}
+		if conv, ok := elem[6].(int64); ok {
+			desc += ":" + pqConvNames[conv]
+		}
+		f.schema = append(f.schema, desc)
+		if len(path) > 0 || i > 1 {
+			path = append(path[:len(path):len(path)], name)
+		}
+		if !group {
+			levels[strings.Join(path, ".")] = rep
+		}
+		for j := 0; j < int(nsubs); j++ {
+			walk(path, rep)
+		}
+	}
+	walk(nil, 0)
+
+	f.nrows = meta[3].(int64)
+	for _, rg := range meta[4].([]interface{}) {
+		var cols []string
+		for _, cc := range rg.(map[int16]interface{})[1].([]interface{}) {
+			md := cc.(map[int16]interface{})[3].(map[int16]interface{})
+			var path []string
+			for _, p := range md[3].([]interface{}) {
+				path = append(path, p.(string))
+			}
+			name := strings.Join(path, ".")
+			col, err := readPage(raw, md[9].(int64), md[1].(int64), levels[name])
+			if err != nil {
+				return f, fmt.Errorf("could not read column %q: %w", name, err)
+			}
+			cols = append(cols, name+": "+strings.Join(col, " "))
+		}
+		f.groups = append(f.groups, cols)
+	}
+	return f, nil
+}
+
+// readPage decodes the data page at pos, as [rep:def:]value.
+func readPage(raw []byte, pos, typ int64, level int) ([]string, error) {
+	dec := &tDecoder{buf: raw[pos:]}
+	hdr := dec.value(tStruct).(map[int16]interface{})
+	if dec.err != nil {
+		return nil, fmt.Errorf("could not decode page header: %w", dec.err)
+	}
+	n := int(hdr[5].(map[int16]interface{})[1].(int64))
+	body := raw[int(pos)+dec.pos:][:hdr[3].(int64)]
+
+	var reps, defs []int
+	if level > 0 {
+		reps, body = readLevels(body, n)
+		defs, body = readLevels(body, n)
+	}
+
+	var out []string
+	for i := 0; i < n; i++ {
+		var s string
+		if level > 0 {
+			s = fmt.Sprintf("%d:%d", reps[i], defs[i])
+			if defs[i] < level {
+				out = append(out, s)
+				continue
+			}
+			s += ":"
+		}
+		switch typ {
+		case pqBoolean:
+			s += fmt.Sprint(body[i/8]>>(i%8)&1 == 1)
+		case pqInt32:
+			s += fmt.Sprint(int32(binary.LittleEndian.Uint32(body)))
+			body = body[4:]
+		case pqInt64:
+			s += fmt.Sprint(int64(binary.LittleEndian.Uint64(body)))
+			body = body[8:]
+		case pqFloat:
+			s += fmt.Sprint(math.Float32frombits(binary.LittleEndian.Uint32(body)))
+			body = body[4:]
+		case pqDouble:
+			s += fmt.Sprint(math.Float64frombits(binary.LittleEndian.Uint64(body)))
+			body = body[8:]
+		case pqByteArray:
+			n := binary.LittleEndian.Uint32(body)
+			s += string(body[4 : 4+n])
+			body = body[4+n:]
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// readLevels decodes n levels, encoded as RLE runs of one byte values.
+func readLevels(raw []byte, n int) ([]int, []byte) {
+	size := binary.LittleEndian.Uint32(raw)
+	dec := &tDecoder{buf: raw[4 : 4+size]}
+	var vs []int
+	for len(vs) < n {
+		run := int(dec.uvarint() >> 1)
+		v := int(dec.buf[dec.pos])
+		dec.pos++
+		for i := 0; i < run; i++ {
+			vs = append(vs, v)
+		}
+	}
+	return vs, raw[4+size:]
+}
+
+// tDecoder decodes Thrift compact protocol values, with integers as int64,
+// binaries as string, lists as []interface{} and structs as
+// map[int16]interface{}.
+type tDecoder struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (dec *tDecoder) byte() byte {
+	if dec.pos >= len(dec.buf) {
+		dec.err = fmt.Errorf("buffer too short")
+		return 0
+	}
+	b := dec.buf[dec.pos]
+	dec.pos++
+	return b
+}
+
+func (dec *tDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(dec.buf[dec.pos:])
+	if n <= 0 {
+		dec.err = fmt.Errorf("invalid varint")
+		return 0
+	}
+	dec.pos += n
+	return v
+}
+
+func (dec *tDecoder) varint() int64 {
+	v := dec.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (dec *tDecoder) value(typ byte) interface{} {
+	switch typ {
+	case tI32, tI64:
+		return dec.varint()
+	case tBinary:
+		n := int(dec.uvarint())
+		if dec.pos+n > len(dec.buf) {
+			dec.err = fmt.Errorf("buffer too short")
+			return ""
+		}
+		v := string(dec.buf[dec.pos : dec.pos+n])
+		dec.pos += n
+		return v
+	case tList:
+		b := dec.byte()
+		n := int(b >> 4)
+		if n == 15 {
+			n = int(dec.uvarint())
+		}
+		vs := make([]interface{}, 0, n)
+		for i := 0; i < n && dec.err == nil; i++ {
+			vs = append(vs, dec.value(b&0xf))
+		}
+		return vs
+	case tStruct:
+		vs := make(map[int16]interface{})
+		var id int16
+		for dec.err == nil {
+			b := dec.byte()
+			if b == 0 {
+				break
+			}
+			if delta := int16(b >> 4); delta != 0 {
+				id += delta
+			} else {
+				id = int16(dec.varint())
+			}
+			vs[id] = dec.value(b & 0xf)
+		}
+		return vs
+	}
+	dec.err = fmt.Errorf("unsupported thrift type %d", typ)
+	return nil
+}
@@ -0,0 +1,497 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rntup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+
+	"go-hep.org/x/hep/groot/internal/rcompress"
+)
+
+// Reader reads the header, the footer and the pages of an RNTuple.
+type Reader struct {
+	r   io.ReaderAt
+	hdr *Header
+	ftr *Footer
+
+	fields []*node // top-level fields.
+}
+
+// NewReader creates a new reader for the RNTuple nt, read from the file
+// nt was loaded from.
+func NewReader(nt *NTuple) (*Reader, error) {
+	if nt.f == nil {
+		return nil, fmt.Errorf("rntup: RNTuple is not attached to a file")
+	}
+	return newReader(nt.f, nt)
+}
+
+func newReader(r io.ReaderAt, nt *NTuple) (*Reader, error) {
+	raw, err := readBlob(r, nt.header)
+	if err != nil {
+		return nil, fmt.Errorf("rntup: could not read header: %w", err)
+	}
+	hdr, err := unmarshalHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err = readBlob(r, nt.footer)
+	if err != nil {
+		return nil, fmt.Errorf("rntup: could not read footer: %w", err)
+	}
+	ftr, err := unmarshalFooter(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReaderFrom(r, hdr, ftr)
+}
+
+func newReaderFrom(r io.ReaderAt, hdr *Header, ftr *Footer) (*Reader, error) {
+	fields, err := newFields(hdr)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{r: r, hdr: hdr, ftr: ftr, fields: fields}, nil
+}
+
+// Header returns the header of the RNTuple.
+func (r *Reader) Header() *Header { return r.hdr }
+
+// Footer returns the footer of the RNTuple.
+func (r *Reader) Footer() *Footer { return r.ftr }
+
+// Entries returns the number of entries of the RNTuple.
+func (r *Reader) Entries() int64 {
+	var n int64
+	for _, cl := range r.ftr.Clusters {
+		n += int64(cl.NEntries)
+	}
+	return n
+}
+
+// readBlob reads the (possibly compressed) blob described by s.
+func readBlob(r io.ReaderAt, s span) ([]byte, error) {
+	buf := make([]byte, s.nbytes)
+	_, err := r.ReadAt(buf, int64(s.seek))
+	if err != nil {
+		return nil, err
+	}
+	if s.nbytes == s.length {
+		return buf, nil
+	}
+	raw := make([]byte, s.length)
+	err = rcompress.Decompress(raw, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// node is a field of the RNTuple, with its columns and sub-fields.
+type node struct {
+	fd   *Field
+	kind reflect.Kind // kind of the values of leaves, reflect.Slice for collections and reflect.Struct for records.
+
+	cols []*Column // columns of the field, by index.
+	subs []*node   // sub-fields, in order.
+	rep  int       // number of collections enclosing the values of the field.
+}
+
+// leafKinds are the kinds of the leaf fields, by C++ type name.
+var leafKinds = map[string]reflect.Kind{
+	"bool":          reflect.Bool,
+	"char":          reflect.Int8,
+	"std::int8_t":   reflect.Int8,
+	"std::uint8_t":  reflect.Uint8,
+	"std::int16_t":  reflect.Int16,
+	"std::uint16_t": reflect.Uint16,
+	"std::int32_t":  reflect.Int32,
+	"std::uint32_t": reflect.Uint32,
+	"std::int64_t":  reflect.Int64,
+	"std::uint64_t": reflect.Uint64,
+	"float":         reflect.Float32,
+	"double":        reflect.Float64,
+	"std::string":   reflect.String,
+}
+
+// columnTypes are the column types of the leaf fields, by kind.
+var columnTypes = map[reflect.Kind][]ColumnType{
+	reflect.Bool:    {ColumnBit},
+	reflect.Int8:    {ColumnByte},
+	reflect.Uint8:   {ColumnByte},
+	reflect.Int16:   {ColumnInt16},
+	reflect.Uint16:  {ColumnInt16},
+	reflect.Int32:   {ColumnInt32},
+	reflect.Uint32:  {ColumnInt32},
+	reflect.Int64:   {ColumnInt64},
+	reflect.Uint64:  {ColumnInt64},
+	reflect.Float32: {ColumnReal32},
+	reflect.Float64: {ColumnReal64},
+	reflect.String:  {ColumnIndex, ColumnByte},
+	reflect.Slice:   {ColumnIndex},
+	reflect.Struct:  {},
+}
+
+// newFields builds the tree of fields described by hdr, and returns the
+// top-level fields.
+func newFields(hdr *Header) ([]*node, error) {
+	nodes := make(map[uint64]*node, len(hdr.Fields))
+	var root *node
+	for i := range hdr.Fields {
+		fd := &hdr.Fields[i]
+		if _, dup := nodes[fd.ID]; dup {
+			return nil, fmt.Errorf("rntup: duplicate field ID %d", fd.ID)
+		}
+		n := &node{fd: fd}
+		nodes[fd.ID] = n
+		if fd.Parent == invalidID {
+			root = n
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("rntup: missing zero field")
+	}
+
+	for i := range hdr.Columns {
+		col := &hdr.Columns[i]
+		n, ok := nodes[col.Field]
+		if !ok {
+			return nil, fmt.Errorf("rntup: column %d refers to unknown field %d", col.ID, col.Field)
+		}
+		n.cols = append(n.cols, col)
+	}
+
+	var build func(n *node, rep int) error
+	build = func(n *node, rep int) error {
+		sort.Slice(n.cols, func(i, j int) bool { return n.cols[i].Index < n.cols[j].Index })
+		n.rep = rep
+		fd := n.fd
+		if fd.NRepetitions != 0 {
+			return fmt.Errorf("rntup: field %q: fixed-size arrays not supported", fd.Name)
+		}
+		switch fd.Structure {
+		case Leaf:
+			kind, ok := leafKinds[fd.Type]
+			if !ok {
+				return fmt.Errorf("rntup: field %q: unsupported type %q", fd.Name, fd.Type)
+			}
+			n.kind = kind
+		case Collection:
+			if len(fd.Links) != 1 {
+				return fmt.Errorf("rntup: collection %q has %d sub-fields", fd.Name, len(fd.Links))
+			}
+			n.kind = reflect.Slice
+			rep++
+		case Record:
+			n.kind = reflect.Struct
+		default:
+			return fmt.Errorf("rntup: field %q: unsupported %v structure", fd.Name, fd.Structure)
+		}
+
+		want := columnTypes[n.kind]
+		if len(n.cols) != len(want) {
+			return fmt.Errorf("rntup: field %q: invalid number of columns (got=%d, want=%d)", fd.Name, len(n.cols), len(want))
+		}
+		for i, col := range n.cols {
+			if col.Type != want[i] {
+				return fmt.Errorf("rntup: field %q: invalid type of column %d (got=%v, want=%v)", fd.Name, i, col.Type, want[i])
+			}
+		}
+
+		for _, id := range fd.Links {
+			sub, ok := nodes[id]
+			if !ok {
+				return fmt.Errorf("rntup: field %q refers to unknown field %d", fd.Name, id)
+			}
+			if sub.fd.Parent != fd.ID {
+				return fmt.Errorf("rntup: field %q is not a sub-field of %q", sub.fd.Name, fd.Name)
+			}
+			n.subs = append(n.subs, sub)
+			err := build(sub, rep)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if root.fd.Structure != Record {
+		return nil, fmt.Errorf("rntup: zero field is not a record")
+	}
+	for _, id := range root.fd.Links {
+		n, ok := nodes[id]
+		if !ok {
+			return nil, fmt.Errorf("rntup: zero field refers to unknown field %d", id)
+		}
+		err := build(n, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fields := make([]*node, len(root.fd.Links))
+	for i, id := range root.fd.Links {
+		fields[i] = nodes[id]
+	}
+	return fields, nil
+}
+
+// data holds the elements of a field within a cluster.
+type data struct {
+	n int // number of elements.
+
+	values interface{} // values of leaves, as a slice of the kind of the leaf.
+	offs   []uint32    // end offsets of collections and strings, relative to the cluster.
+	chars  []byte      // characters of strings.
+	subs   []*data     // elements of the sub-fields.
+}
+
+// span returns the range of the items of the i-th element of a collection
+// or of a string.
+func (d *data) span(i int) (beg, end int) {
+	if i > 0 {
+		beg = int(d.offs[i-1])
+	}
+	return beg, int(d.offs[i])
+}
+
+// load reads the elements of the fields of the given cluster.
+func (r *Reader) load(cl *Cluster) ([]*data, error) {
+	ranges := make(map[uint64]*ColumnRange, len(cl.Columns))
+	for i := range cl.Columns {
+		ranges[cl.Columns[i].Column] = &cl.Columns[i]
+	}
+
+	var load func(n *node, nelems int) (*data, error)
+	load = func(n *node, nelems int) (*data, error) {
+		d := &data{n: nelems}
+		var raws [][]byte
+		for _, col := range n.cols {
+			rng, ok := ranges[col.ID]
+			if !ok {
+				return nil, fmt.Errorf("rntup: cluster %d has no range for column %d", cl.ID, col.ID)
+			}
+			raw, err := r.readColumn(col, rng)
+			if err != nil {
+				return nil, fmt.Errorf("rntup: could not read column %d of field %q: %w", col.ID, n.fd.Name, err)
+			}
+			raws = append(raws, raw)
+		}
+
+		switch n.kind {
+		case reflect.Struct:
+			// the elements of records are the elements of their sub-fields.
+		case reflect.Slice, reflect.String:
+			d.offs = decodeU32s(raws[0])
+			if len(d.offs) != nelems {
+				return nil, fmt.Errorf("rntup: field %q: invalid number of offsets (got=%d, want=%d)", n.fd.Name, len(d.offs), nelems)
+			}
+			for i := range d.offs {
+				if (i > 0 && d.offs[i] < d.offs[i-1]) || (n.kind == reflect.String && int(d.offs[i]) > len(raws[1])) {
+					return nil, fmt.Errorf("rntup: field %q: invalid offset %d of element %d", n.fd.Name, d.offs[i], i)
+				}
+			}
+			if n.kind == reflect.String {
+				d.chars = raws[1]
+			}
+		default:
+			var err error
+			d.values, err = decodeValues(n.kind, raws[0], nelems)
+			if err != nil {
+				return nil, fmt.Errorf("rntup: field %q: %w", n.fd.Name, err)
+			}
+		}
+
+		nsubs := nelems
+		if n.kind == reflect.Slice && nelems > 0 {
+			nsubs = int(d.offs[nelems-1])
+		}
+		for _, sub := range n.subs {
+			v, err := load(sub, nsubs)
+			if err != nil {
+				return nil, err
+			}
+			d.subs = append(d.subs, v)
+		}
+		return d, nil
+	}
+
+	vs := make([]*data, len(r.fields))
+	for i, n := range r.fields {
+		v, err := load(n, int(cl.NEntries))
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+// readColumn reads and decompresses the pages of the column col within
+// a cluster.
+func (r *Reader) readColumn(col *Column, rng *ColumnRange) ([]byte, error) {
+	var (
+		out []byte
+		n   int
+	)
+	for _, page := range rng.Pages {
+		loc := page.Locator
+		if loc.URL != "" {
+			return nil, fmt.Errorf("page stored in %q not supported", loc.URL)
+		}
+		size := col.Type.size(int(page.NElements))
+		if size < 0 {
+			return nil, fmt.Errorf("unsupported column type %v", col.Type)
+		}
+		buf := make([]byte, loc.NBytes)
+		_, err := r.r.ReadAt(buf, loc.Pos)
+		if err != nil {
+			return nil, fmt.Errorf("could not read page: %w", err)
+		}
+		if int(loc.NBytes) != size {
+			raw := make([]byte, size)
+			err = rcompress.Decompress(raw, bytes.NewReader(buf))
+			if err != nil {
+				return nil, fmt.Errorf("could not decompress page: %w", err)
+			}
+			buf = raw
+		}
+		if col.Type == ColumnBit {
+			buf = unpackBits(buf, int(page.NElements))
+		}
+		out = append(out, buf...)
+		n += int(page.NElements)
+	}
+	if n != int(rng.NElements) {
+		return nil, fmt.Errorf("invalid number of elements in pages (got=%d, want=%d)", n, rng.NElements)
+	}
+	return out, nil
+}
+
+// unpackBits returns the n bits of raw as bytes.
+func unpackBits(raw []byte, n int) []byte {
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = (raw[i/8] >> (i % 8)) & 1
+	}
+	return out
+}
+
+func decodeU32s(raw []byte) []uint32 {
+	vs := make([]uint32, len(raw)/4)
+	for i := range vs {
+		vs[i] = binary.LittleEndian.Uint32(raw[4*i:])
+	}
+	return vs
+}
+
+// decodeValues decodes the n little-endian values of the given kind.
+func decodeValues(kind reflect.Kind, raw []byte, n int) (interface{}, error) {
+	size := int(typeOf(kind).Size())
+	if len(raw) != n*size {
+		return nil, fmt.Errorf("invalid number of bytes (got=%d, want=%d)", len(raw), n*size)
+	}
+
+	switch kind {
+	case reflect.Bool:
+		vs := make([]bool, n)
+		for i := range vs {
+			vs[i] = raw[i] != 0
+		}
+		return vs, nil
+	case reflect.Int8:
+		vs := make([]int8, n)
+		for i := range vs {
+			vs[i] = int8(raw[i])
+		}
+		return vs, nil
+	case reflect.Uint8:
+		return append([]uint8(nil), raw...), nil
+	case reflect.Int16:
+		vs := make([]int16, n)
+		for i := range vs {
+			vs[i] = int16(binary.LittleEndian.Uint16(raw[2*i:]))
+		}
+		return vs, nil
+	case reflect.Uint16:
+		vs := make([]uint16, n)
+		for i := range vs {
+			vs[i] = binary.LittleEndian.Uint16(raw[2*i:])
+		}
+		return vs, nil
+	case reflect.Int32:
+		vs := make([]int32, n)
+		for i := range vs {
+			vs[i] = int32(binary.LittleEndian.Uint32(raw[4*i:]))
+		}
+		return vs, nil
+	case reflect.Uint32:
+		return decodeU32s(raw), nil
+	case reflect.Int64:
+		vs := make([]int64, n)
+		for i := range vs {
+			vs[i] = int64(binary.LittleEndian.Uint64(raw[8*i:]))
+		}
+		return vs, nil
+	case reflect.Uint64:
+		vs := make([]uint64, n)
+		for i := range vs {
+			vs[i] = binary.LittleEndian.Uint64(raw[8*i:])
+		}
+		return vs, nil
+	case reflect.Float32:
+		vs := make([]float32, n)
+		for i := range vs {
+			vs[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:]))
+		}
+		return vs, nil
+	case reflect.Float64:
+		vs := make([]float64, n)
+		for i := range vs {
+			vs[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw[8*i:]))
+		}
+		return vs, nil
+	}
+	return nil, fmt.Errorf("unsupported kind %v", kind)
+}
+
+// typeOf returns the Go type of the values of leaves of the given kind.
+func typeOf(kind reflect.Kind) reflect.Type {
+	switch kind {
+	case reflect.Bool:
+		return reflect.TypeOf(false)
+	case reflect.Int8:
+		return reflect.TypeOf(int8(0))
+	case reflect.Uint8:
+		return reflect.TypeOf(uint8(0))
+	case reflect.Int16:
+		return reflect.TypeOf(int16(0))
+	case reflect.Uint16:
+		return reflect.TypeOf(uint16(0))
+	case reflect.Int32:
+		return reflect.TypeOf(int32(0))
+	case reflect.Uint32:
+		return reflect.TypeOf(uint32(0))
+	case reflect.Int64:
+		return reflect.TypeOf(int64(0))
+	case reflect.Uint64:
+		return reflect.TypeOf(uint64(0))
+	case reflect.Float32:
+		return reflect.TypeOf(float32(0))
+	case reflect.Float64:
+		return reflect.TypeOf(float64(0))
+	case reflect.String:
+		return reflect.TypeOf("")
+	}
+	panic(fmt.Errorf("rntup: invalid leaf kind %v", kind))
+}
@@ -0,0 +1,280 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rntup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+
+	"go-hep.org/x/hep/groot/riofs"
+)
+
+func openStaff(t *testing.T) *Reader {
+	t.Helper()
+
+	f, err := riofs.Open("../../testdata/ntpl001_staff.root")
+	if err != nil {
+		t.Fatalf("could not open file: %+v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	obj, err := f.Get("Staff")
+	if err != nil {
+		t.Fatalf("could not get RNTuple: %+v", err)
+	}
+
+	r, err := NewReader(obj.(*NTuple))
+	if err != nil {
+		t.Fatalf("could not create reader: %+v", err)
+	}
+	return r
+}
+
+// newTestNTuple creates an in-memory RNTuple with nested collections and
+// records, and 3 entries split into 2 clusters:
+//
+//	n:   1, 2, 3
+//	vf:  [1 2], [], [3]
+//	p:   {1.5 "a"}, {2.5 ""}, {3.5 "xyz"}
+//	ok:  true, false, true
+//	vv:  [[1] []], [], [[2 3]]
+func newTestNTuple(t *testing.T) *Reader {
+	t.Helper()
+
+	hdr := &Header{
+		Name: "test",
+		Fields: []Field{
+			{ID: 0, Structure: Record, Parent: invalidID, Links: []uint64{1, 2, 4, 7, 8}},
+			{ID: 1, Name: "n", Type: "std::int32_t", Structure: Leaf},
+			{ID: 2, Name: "vf", Type: "std::vector<float>", Structure: Collection, Links: []uint64{3}},
+			{ID: 3, Name: "_0", Type: "float", Structure: Leaf, Parent: 2},
+			{ID: 4, Name: "p", Type: "P", Structure: Record, Links: []uint64{5, 6}},
+			{ID: 5, Name: "x", Type: "double", Structure: Leaf, Parent: 4},
+			{ID: 6, Name: "s", Type: "std::string", Structure: Leaf, Parent: 4},
+			{ID: 7, Name: "ok", Type: "bool", Structure: Leaf},
+			{ID: 8, Name: "vv", Type: "std::vector<std::vector<std::int16_t>>", Structure: Collection, Links: []uint64{9}},
+			{ID: 9, Name: "_0", Type: "std::vector<std::int16_t>", Structure: Collection, Parent: 8, Links: []uint64{10}},
+			{ID: 10, Name: "_0", Type: "std::int16_t", Structure: Leaf, Parent: 9},
+		},
+		Columns: []Column{
+			{ID: 0, Type: ColumnInt32, Field: 1},
+			{ID: 1, Type: ColumnIndex, Field: 2},
+			{ID: 2, Type: ColumnReal32, Field: 3},
+			{ID: 3, Type: ColumnReal64, Field: 5},
+			{ID: 5, Type: ColumnByte, Field: 6, Index: 1},
+			{ID: 4, Type: ColumnIndex, Field: 6},
+			{ID: 6, Type: ColumnBit, Field: 7},
+			{ID: 7, Type: ColumnIndex, Field: 8},
+			{ID: 8, Type: ColumnIndex, Field: 9},
+			{ID: 9, Type: ColumnInt16, Field: 10},
+		},
+	}
+
+	var (
+		buf bytes.Buffer
+		le  = binary.LittleEndian
+	)
+	page := func(col uint64, n int, raw []byte) ColumnRange {
+		loc := Locator{Pos: int64(buf.Len()), NBytes: uint32(len(raw))}
+		buf.Write(raw)
+		return ColumnRange{
+			Column:    col,
+			NElements: uint32(n),
+			Pages:     []Page{{NElements: uint32(n), Locator: loc}},
+		}
+	}
+	u32s := func(vs ...uint32) []byte {
+		raw := make([]byte, 4*len(vs))
+		for i, v := range vs {
+			le.PutUint32(raw[4*i:], v)
+		}
+		return raw
+	}
+	f32s := func(vs ...float32) []byte {
+		raw := make([]byte, 4*len(vs))
+		for i, v := range vs {
+			le.PutUint32(raw[4*i:], math.Float32bits(v))
+		}
+		return raw
+	}
+	f64s := func(vs ...float64) []byte {
+		raw := make([]byte, 8*len(vs))
+		for i, v := range vs {
+			le.PutUint64(raw[8*i:], math.Float64bits(v))
+		}
+		return raw
+	}
+	i16s := func(vs ...int16) []byte {
+		raw := make([]byte, 2*len(vs))
+		for i, v := range vs {
+			le.PutUint16(raw[2*i:], uint16(v))
+		}
+		return raw
+	}
+
+	ftr := &Footer{
+		Clusters: []Cluster{
+			{
+				ID: 0, FirstEntry: 0, NEntries: 2,
+				Columns: []ColumnRange{
+					page(0, 2, u32s(1, 2)),
+					page(1, 2, u32s(2, 2)),
+					page(2, 2, f32s(1, 2)),
+					page(3, 2, f64s(1.5, 2.5)),
+					page(4, 2, u32s(1, 1)),
+					page(5, 1, []byte("a")),
+					page(6, 2, []byte{0x01}),
+					page(7, 2, u32s(2, 2)),
+					page(8, 2, u32s(1, 1)),
+					page(9, 1, i16s(1)),
+				},
+			},
+			{
+				ID: 1, FirstEntry: 2, NEntries: 1,
+				Columns: []ColumnRange{
+					page(0, 1, u32s(3)),
+					page(1, 1, u32s(1)),
+					page(2, 1, f32s(3)),
+					page(3, 1, f64s(3.5)),
+					page(4, 1, u32s(3)),
+					page(5, 3, []byte("xyz")),
+					page(6, 1, []byte{0x01}),
+					page(7, 1, u32s(1)),
+					page(8, 1, u32s(2)),
+					page(9, 2, i16s(2, 3)),
+				},
+			},
+		},
+	}
+
+	r, err := newReaderFrom(bytes.NewReader(buf.Bytes()), hdr, ftr)
+	if err != nil {
+		t.Fatalf("could not create reader: %+v", err)
+	}
+	return r
+}
+
+func TestReaderStaff(t *testing.T) {
+	r := openStaff(t)
+
+	if got, want := r.Header().Name, "Staff"; got != want {
+		t.Fatalf("invalid name: got=%q, want=%q", got, want)
+	}
+	if got, want := r.Entries(), int64(3354); got != want {
+		t.Fatalf("invalid number of entries: got=%d, want=%d", got, want)
+	}
+
+	var names []string
+	for _, n := range r.fields {
+		names = append(names, n.fd.Name+":"+n.fd.Type)
+	}
+	want := []string{
+		"Category:std::int32_t", "Flag:std::uint32_t", "Age:std::int32_t",
+		"Service:std::int32_t", "Children:std::int32_t", "Grade:std::int32_t",
+		"Step:std::int32_t", "Hrweek:std::int32_t", "Cost:std::int32_t",
+		"Division:std::string", "Nation:std::string",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("invalid fields:\ngot= %q\nwant=%q", names, want)
+	}
+
+	vs, err := r.load(&r.Footer().Clusters[0])
+	if err != nil {
+		t.Fatalf("could not load cluster: %+v", err)
+	}
+	for i, want := range []interface{}{
+		[]int32{202, 530, 316}, []uint32{15, 15, 15}, []int32{58, 63, 56},
+		[]int32{28, 33, 31}, []int32{0, 0, 2}, []int32{10, 9, 9},
+		[]int32{13, 13, 13}, []int32{40, 40, 40}, []int32{11975, 10228, 10730},
+		[]string{"PS", "EP", "PS"}, []string{"DE", "CH", "FR"},
+	} {
+		d := vs[i]
+		if d.n != 3354 {
+			t.Fatalf("invalid number of elements for %q: got=%d", r.fields[i].fd.Name, d.n)
+		}
+		var got interface{}
+		switch d.values.(type) {
+		case []int32:
+			got = d.values.([]int32)[:3]
+		case []uint32:
+			got = d.values.([]uint32)[:3]
+		default:
+			var strs []string
+			for j := 0; j < 3; j++ {
+				beg, end := d.span(j)
+				strs = append(strs, string(d.chars[beg:end]))
+			}
+			got = strs
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid values for %q:\ngot= %v\nwant=%v", r.fields[i].fd.Name, got, want)
+		}
+	}
+}
+
+func TestReaderNested(t *testing.T) {
+	r := newTestNTuple(t)
+
+	if got, want := r.Entries(), int64(3); got != want {
+		t.Fatalf("invalid number of entries: got=%d, want=%d", got, want)
+	}
+
+	vs, err := r.load(&r.Footer().Clusters[0])
+	if err != nil {
+		t.Fatalf("could not load cluster: %+v", err)
+	}
+
+	if got, want := vs[0].values, []int32{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid n: got=%v, want=%v", got, want)
+	}
+	if got, want := vs[1].subs[0].values, []float32{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid vf: got=%v, want=%v", got, want)
+	}
+	if got, want := vs[3].values, []bool{true, false}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid ok: got=%v, want=%v", got, want)
+	}
+	if got, want := vs[4].subs[0].offs, []uint32{1, 1}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid vv offsets: got=%v, want=%v", got, want)
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		edit func(hdr *Header)
+		want string
+	}{
+		{
+			name: "variant",
+			edit: func(hdr *Header) { hdr.Fields[1].Structure = Variant },
+			want: `rntup: field "n": unsupported variant structure`,
+		},
+		{
+			name: "type",
+			edit: func(hdr *Header) { hdr.Fields[1].Type = "std::complex<float>" },
+			want: `rntup: field "n": unsupported type "std::complex<float>"`,
+		},
+		{
+			name: "column",
+			edit: func(hdr *Header) { hdr.Columns[0].Type = ColumnReal32 },
+			want: `rntup: field "n": invalid type of column 0 (got=real32, want=int32)`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newTestNTuple(t)
+			tc.edit(r.hdr)
+			_, err := newFields(r.hdr)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got, want := err.Error(), tc.want; got != want {
+				t.Fatalf("invalid error:\ngot= %s\nwant=%s", got, want)
+			}
+		})
+	}
+}
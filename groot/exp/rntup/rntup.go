@@ -3,6 +3,11 @@
 // license that can be found in the LICENSE file.
 
 // Package rntup contains types to handle RNTuple-related data.
+//
+// The RNTuple anchor (NTuple) can be read and written.
+// The header, footer and pages it points to can be read with a Reader,
+// and converted to Arrow record batches (NewRecordReader) or to Parquet
+// row groups (WriteParquet), one per cluster.
 package rntup // import "go-hep.org/x/hep/groot/exp/rntup"
 
 import (
@@ -10,6 +15,7 @@ import (
 	"reflect"
 
 	"go-hep.org/x/hep/groot/rbytes"
+	"go-hep.org/x/hep/groot/riofs"
 	"go-hep.org/x/hep/groot/root"
 	"go-hep.org/x/hep/groot/rtypes"
 )
@@ -28,6 +34,8 @@ type NTuple struct {
 	footer span
 
 	reserved uint64
+
+	f *riofs.File // file the RNTuple was read from.
 }
 
 func (*NTuple) Class() string {
@@ -38,6 +46,10 @@ func (*NTuple) RVersion() int16 {
 	return 0 // FIXME(sbinet): generate through gen.rboot
 }
 
+// SetFile attaches the RNTuple to the file holding its header, footer
+// and pages.
+func (nt *NTuple) SetFile(f *riofs.File) { nt.f = f }
+
 func (nt *NTuple) String() string {
 	return fmt.Sprintf("NTuple{version:%d, size:%d, header:%v, footer:%v}",
 		nt.rvers, nt.size, nt.header, nt.footer,
@@ -106,4 +118,5 @@ var (
 	_ rbytes.RVersioner  = (*NTuple)(nil)
 	_ rbytes.Marshaler   = (*NTuple)(nil)
 	_ rbytes.Unmarshaler = (*NTuple)(nil)
+	_ riofs.SetFiler     = (*NTuple)(nil)
 )
@@ -18,7 +18,7 @@ func TestNTuple(t *testing.T) {
 		want rtests.ROOTer
 	}{
 		{
-			want: &NTuple{1, 2, span{1, 2, 3}, span{4, 5, 6}, 7},
+			want: &NTuple{1, 2, span{1, 2, 3}, span{4, 5, 6}, 7, nil},
 		},
 	} {
 		t.Run("", func(t *testing.T) {
@@ -75,6 +75,7 @@ func TestReadNTuple(t *testing.T) {
 			length: 804,
 		},
 		reserved: 0,
+		f:        f,
 	}
 
 	if got, want := *nt, want; got != want {
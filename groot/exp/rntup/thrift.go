@@ -0,0 +1,75 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rntup
+
+// Thrift compact protocol types.
+const (
+	tI32    = 5
+	tI64    = 6
+	tBinary = 8
+	tList   = 9
+	tStruct = 12
+)
+
+// tEncoder encodes Parquet metadata with the Thrift compact protocol.
+type tEncoder struct {
+	buf  []byte
+	last int16   // ID of the last field of the current struct.
+	ids  []int16 // IDs of the last fields of the enclosing structs.
+}
+
+func newTEncoder() *tEncoder {
+	return &tEncoder{}
+}
+
+func (enc *tEncoder) beginStruct() {
+	enc.ids = append(enc.ids, enc.last)
+	enc.last = 0
+}
+
+func (enc *tEncoder) endStruct() {
+	enc.buf = append(enc.buf, 0) // stop field.
+	enc.last = enc.ids[len(enc.ids)-1]
+	enc.ids = enc.ids[:len(enc.ids)-1]
+}
+
+func (enc *tEncoder) field(id int16, typ byte) {
+	if delta := id - enc.last; delta > 0 && delta <= 15 {
+		enc.buf = append(enc.buf, byte(delta)<<4|typ)
+	} else {
+		enc.buf = append(enc.buf, typ)
+		enc.buf = appendUvarint(enc.buf, zigzag(int64(id)))
+	}
+	enc.last = id
+}
+
+func (enc *tEncoder) list(typ byte, n int) {
+	if n < 15 {
+		enc.buf = append(enc.buf, byte(n)<<4|typ)
+		return
+	}
+	enc.buf = append(enc.buf, 0xf0|typ)
+	enc.buf = appendUvarint(enc.buf, uint64(n))
+}
+
+func (enc *tEncoder) i32(v int32) { enc.buf = appendUvarint(enc.buf, zigzag(int64(v))) }
+func (enc *tEncoder) i64(v int64) { enc.buf = appendUvarint(enc.buf, zigzag(v)) }
+
+func (enc *tEncoder) str(v string) {
+	enc.buf = appendUvarint(enc.buf, uint64(len(v)))
+	enc.buf = append(enc.buf, v...)
+}
+
+func (enc *tEncoder) raw(p []byte) { enc.buf = append(enc.buf, p...) }
+
+func zigzag(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
@@ -188,6 +188,8 @@ func New{{.Name}}From(h *hbook.H1D) *{{.Name}} {
 		hroot.th1.SetTitle(v.(string))
 	}
 	hroot.th1.xaxis.xbins.Data = edges
+	hroot.th1.xaxis.fromAnnotation(h.Annotation(), AnnXLabel, AnnXBinLabels)
+	hroot.th1.yaxis.fromAnnotation(h.Annotation(), AnnYLabel, "")
 	return hroot
 }
 
@@ -334,6 +336,8 @@ func (h *{{.Name}}) AsH1D() *hbook.H1D {
 		"name":  h.Name(),
 		"title": h.Title(),
 	}
+	h.th1.xaxis.toAnnotation(hh.Ann, AnnXLabel, AnnXBinLabels)
+	h.th1.yaxis.toAnnotation(hh.Ann, AnnYLabel, "")
 
 	hh.Binning.Dist = hbook.Dist1D{
 		Dist: hbook.Dist0D{
@@ -476,18 +480,17 @@ func New{{.Name}}From(h *hbook.H2D) *{{.Name}} {
 		}
 	}
 
-	oflows := h.Binning.Outflows[:]
-	for i, v := range []struct{ix,iy int}{
-		{0, 0},
-		{0, 1},
-		{0, nybins+1},
-		{nxbins + 1, 0},
-		{nxbins + 1, 1},
-		{nxbins + 1, nybins + 1},
-		{1, 0},
-		{1, nybins + 1},
-	}{
-		hroot.setDist2D(v.ix, v.iy, oflows[i].SumW(), oflows[i].SumW2())
+	var (
+		oflows   = h.Binning.Outflows
+		regions  = h2Outflows(nxbins, nybins)
+		cells, _ = annFloats(h.Annotation()[AnnOutflows])
+	)
+	if !hroot.setOutflowCells(regions, oflows, cells) {
+		// no per-cell information: store the whole outflow region
+		// into its first ROOT bin.
+		for i, r := range regions {
+			hroot.setDist2D(r.ix1, r.iy1, oflows[i].SumW(), oflows[i].SumW2())
+		}
 	}
 
 	xedges = append(xedges, bins[ibin(h.Binning.Nx-1, 0)].XMax())
@@ -500,6 +503,10 @@ func New{{.Name}}From(h *hbook.H2D) *{{.Name}} {
 	hroot.th2.th1.xaxis.xbins.Data = xedges
 	hroot.th2.th1.yaxis.xbins.Data = yedges
 
+	hroot.th2.th1.xaxis.fromAnnotation(h.Annotation(), AnnXLabel, AnnXBinLabels)
+	hroot.th2.th1.yaxis.fromAnnotation(h.Annotation(), AnnYLabel, AnnYBinLabels)
+	hroot.th2.th1.zaxis.fromAnnotation(h.Annotation(), AnnZLabel, "")
+
 	return hroot
 }
 
@@ -656,6 +663,62 @@ func (h *{{.Name}}) setDist2D(ix, iy int, sumw, sumw2 float64) {
 	h.th1.sumw2.Data[i] = sumw2
 }
 
+// dist2DRegion returns the distribution summed over all the ROOT bins of
+// the provided region.
+func (h *{{.Name}}) dist2DRegion(r h2Region) hbook.Dist2D {
+	var o hbook.Dist2D
+	r.each(func(ix, iy int) {
+		d := h.dist2D(ix, iy)
+		o.X.Dist.N += d.X.Dist.N
+		o.X.Dist.SumW += d.X.Dist.SumW
+		o.X.Dist.SumW2 += d.X.Dist.SumW2
+		o.Y.Dist.N += d.Y.Dist.N
+		o.Y.Dist.SumW += d.Y.Dist.SumW
+		o.Y.Dist.SumW2 += d.Y.Dist.SumW2
+	})
+	return o
+}
+
+// setOutflowCells sets the content of the outflow ROOT bins from the
+// per-cell (sumw, sumw2) values of the N, E, S and W outflow regions.
+// setOutflowCells returns false and leaves the histogram untouched if
+// these values are not consistent with the hbook outflows.
+func (h *{{.Name}}) setOutflowCells(regions [8]h2Region, oflows [8]hbook.Dist2D, cells []float64) bool {
+	n := 0
+	for i := 1; i < len(regions); i += 2 {
+		n += regions[i].cells()
+	}
+	if len(cells) != 2*n {
+		return false
+	}
+
+	j := 0
+	for i := 1; i < len(regions); i += 2 {
+		var sumw, sumw2 float64
+		regions[i].each(func(ix, iy int) {
+			sumw += cells[j]
+			sumw2 += cells[j+1]
+			j += 2
+		})
+		if sumw != oflows[i].SumW() || sumw2 != oflows[i].SumW2() {
+			return false
+		}
+	}
+
+	j = 0
+	for i, r := range regions {
+		if i%2 == 0 {
+			h.setDist2D(r.ix1, r.iy1, oflows[i].SumW(), oflows[i].SumW2())
+			continue
+		}
+		r.each(func(ix, iy int) {
+			h.setDist2D(ix, iy, cells[j], cells[j+1])
+			j += 2
+		})
+	}
+	return true
+}
+
 func (h *{{.Name}}) entries(height, err float64) int64 {
 	if height <= 0 {
 		return 0
@@ -673,22 +736,39 @@ func (h *{{.Name}}) AsH2D() *hbook.H2D {
 			nx, h.XAxis().XMin(), h.XAxis().XMax(),
 			ny, h.YAxis().XMin(), h.YAxis().XMax(),
 		)
-		xinrange = 1
-		yinrange = 1
+		regions = h2Outflows(nx, ny)
+		cells []float64
+		split bool
 	)
 	hh.Ann = hbook.Annotation{
 		"name":  h.Name(),
 		"title": h.Title(),
 	}
-	hh.Binning.Outflows = [8]hbook.Dist2D{
-		h.dist2D(0, 0),
-		h.dist2D(0, yinrange),
-		h.dist2D(0, ny+1),
-		h.dist2D(nx+1, 0),
-		h.dist2D(nx+1, yinrange),
-		h.dist2D(nx+1, ny+1),
-		h.dist2D(xinrange, 0),
-		h.dist2D(xinrange, ny+1),
+	h.th1.xaxis.toAnnotation(hh.Ann, AnnXLabel, AnnXBinLabels)
+	h.th1.yaxis.toAnnotation(hh.Ann, AnnYLabel, AnnYBinLabels)
+	h.th1.zaxis.toAnnotation(hh.Ann, AnnZLabel, "")
+
+	for i, r := range regions {
+		hh.Binning.Outflows[i] = h.dist2DRegion(r)
+		if i%2 == 0 {
+			continue
+		}
+		// keep track of the per-cell content of the N, E, S and W
+		// outflow regions, to be able to convert back to ROOT.
+		filled := 0
+		r.each(func(ix, iy int) {
+			d := h.dist2D(ix, iy)
+			if d.SumW() != 0 || d.SumW2() != 0 {
+				filled++
+			}
+			cells = append(cells, d.SumW(), d.SumW2())
+		})
+		if filled > 1 {
+			split = true
+		}
+	}
+	if split {
+		hh.Ann[AnnOutflows] = cells
 	}
 
 	hh.Binning.Dist = hbook.Dist2D{
@@ -90,6 +90,9 @@ func (cmd *dumpCmd) dumpObj(obj root.Object) error {
 	case rhist.H1: // keep after rhist.H2
 		fmt.Fprintf(cmd.w, "\n")
 		err = cmd.dumpH1(obj)
+	case rhist.Graph2D: // keep before rhist.Graph
+		fmt.Fprintf(cmd.w, "\n")
+		err = cmd.dumpGraph2D(obj)
 	case rhist.Graph:
 		fmt.Fprintf(cmd.w, "\n")
 		err = cmd.dumpGraph(obj)
@@ -194,3 +197,12 @@ func (cmd *dumpCmd) dumpGraph(gr rhist.Graph) error {
 	g := rootcnv.S2D(gr)
 	return yodacnv.Write(cmd.w, g)
 }
+
+func (cmd *dumpCmd) dumpGraph2D(gr rhist.Graph2D) error {
+	fmt.Fprintf(cmd.w, "# xval\t yval\t zval\n")
+	for i := 0; i < gr.Len(); i++ {
+		x, y, z := gr.XYZ(i)
+		fmt.Fprintf(cmd.w, "%e\t%e\t%e\n", x, y, z)
+	}
+	return nil
+}
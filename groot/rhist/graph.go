@@ -627,6 +627,13 @@ func (g *tgraphasymmerrs) UnmarshalYODA(raw []byte) error {
 	return nil
 }
 
+// errors summation modes of TGraphMultiErrors.
+const (
+	sumErrFirst  = 0 // only the first Y error dimension is used
+	sumErrSquare = 1 // Y errors are summed in quadrature
+	sumErrAbs    = 2 // Y errors are summed linearly
+)
+
 // tgraphmultierrs is a graph with asymmetric error bars and
 // multiple y error dimensions.
 type tgraphmultierrs struct {
@@ -702,8 +709,44 @@ func (g *tgraphmultierrs) XError(i int) (float64, float64) {
 	return g.xerrlo[i], g.xerrhi[i]
 }
 
+// YError returns the low and high Y errors of the i-th point,
+// combined according to the errors summation mode of the graph.
 func (g *tgraphmultierrs) YError(i int) (float64, float64) {
-	return g.yerrlo[0].At(i), g.yerrhi[0].At(i)
+	if len(g.yerrlo) == 0 {
+		return 0, 0
+	}
+
+	switch g.sumErrMode {
+	case sumErrSquare:
+		var lo, hi float64
+		for e := range g.yerrlo {
+			elo, ehi := g.YErrorN(i, e)
+			lo += elo * elo
+			hi += ehi * ehi
+		}
+		return math.Sqrt(lo), math.Sqrt(hi)
+	case sumErrAbs:
+		var lo, hi float64
+		for e := range g.yerrlo {
+			elo, ehi := g.YErrorN(i, e)
+			lo += elo
+			hi += ehi
+		}
+		return lo, hi
+	default:
+		return g.YErrorN(i, 0)
+	}
+}
+
+// NYErrors returns the number of Y error dimensions.
+func (g *tgraphmultierrs) NYErrors() int {
+	return len(g.yerrlo)
+}
+
+// YErrorN returns the low and high Y errors of the i-th point,
+// for the e-th error dimension.
+func (g *tgraphmultierrs) YErrorN(i, e int) (float64, float64) {
+	return g.yerrlo[e].At(i), g.yerrhi[e].At(i)
 }
 
 // MarshalROOT implements rbytes.Marshaler
@@ -859,6 +902,7 @@ var (
 	_ root.Merger         = (*tgraphmultierrs)(nil)
 	_ Graph               = (*tgraphmultierrs)(nil)
 	_ GraphErrors         = (*tgraphmultierrs)(nil)
+	_ GraphMultiErrors    = (*tgraphmultierrs)(nil)
 	_ rbytes.Marshaler    = (*tgraphmultierrs)(nil)
 	_ rbytes.Unmarshaler  = (*tgraphmultierrs)(nil)
 	_ yodacnv.Marshaler   = (*tgraphmultierrs)(nil)
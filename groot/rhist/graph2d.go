@@ -0,0 +1,405 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rhist
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"go-hep.org/x/hep/groot/rbase"
+	"go-hep.org/x/hep/groot/rbytes"
+	"go-hep.org/x/hep/groot/rcont"
+	"go-hep.org/x/hep/groot/root"
+	"go-hep.org/x/hep/groot/rtypes"
+	"go-hep.org/x/hep/groot/rvers"
+	"go-hep.org/x/hep/hbook"
+)
+
+// tgraph2d is a set of (x,y,z) points.
+type tgraph2d struct {
+	rbase.Named
+	attline   rbase.AttLine
+	attfill   rbase.AttFill
+	attmarker rbase.AttMarker
+
+	npoints   int32 // number of points in the data set
+	npx       int32 // number of bins along X in the interpolation histogram
+	npy       int32 // number of bins along Y in the interpolation histogram
+	maxiter   int32 // maximum number of iterations to find Delaunay triangles
+	x         []float64
+	y         []float64
+	z         []float64
+	min       float64 // minimum value for plotting along z
+	max       float64 // maximum value for plotting along z
+	margin    float64 // extra space (in %) around interpolated area
+	zout      float64 // value for points lying outside the interpolated area
+	funcs     root.List
+	userHisto bool
+
+	dt *delaunay // Delaunay triangulation of the points, built lazily
+}
+
+func newGraph2D(n int) *tgraph2d {
+	return &tgraph2d{
+		Named:     *rbase.NewNamed("", ""),
+		attline:   *rbase.NewAttLine(),
+		attfill:   *rbase.NewAttFill(),
+		attmarker: *rbase.NewAttMarker(),
+		npoints:   int32(n),
+		npx:       40,
+		npy:       40,
+		maxiter:   100000,
+		x:         make([]float64, n),
+		y:         make([]float64, n),
+		z:         make([]float64, n),
+		min:       -1111,
+		max:       -1111,
+		funcs:     rcont.NewList("", nil),
+	}
+}
+
+// NewGraph2DFrom creates a new Graph2D from a 2-dim hbook histogram.
+// Each non-empty bin of the histogram gives a point located at the center
+// of the bin, with the bin content as z value.
+func NewGraph2DFrom(h2 *hbook.H2D) Graph2D {
+	bins := h2.Binning.Bins
+	groot := newGraph2D(0)
+	for i := range bins {
+		bin := &bins[i]
+		if bin.SumW() == 0 && bin.SumW2() == 0 {
+			continue
+		}
+		x, y := bin.XYMid()
+		groot.x = append(groot.x, x)
+		groot.y = append(groot.y, y)
+		groot.z = append(groot.z, bin.SumW())
+	}
+	groot.npoints = int32(len(groot.x))
+
+	groot.Named.SetName(h2.Name())
+	if v, ok := h2.Annotation()["title"]; ok {
+		groot.Named.SetTitle(v.(string))
+	}
+
+	return groot
+}
+
+func (*tgraph2d) RVersion() int16 {
+	return rvers.Graph2D
+}
+
+func (*tgraph2d) Class() string {
+	return "TGraph2D"
+}
+
+func (g *tgraph2d) Len() int {
+	return len(g.x)
+}
+
+func (g *tgraph2d) XY(i int) (float64, float64) {
+	return g.x[i], g.y[i]
+}
+
+func (g *tgraph2d) XYZ(i int) (float64, float64, float64) {
+	return g.x[i], g.y[i], g.z[i]
+}
+
+// Interpolate returns the value at (x,y) of the linear interpolation
+// of the graph points over their Delaunay triangulation.
+// The triangulation is computed on the first call to Interpolate.
+func (g *tgraph2d) Interpolate(x, y float64) float64 {
+	if g.dt == nil {
+		g.dt = newDelaunay(g.x, g.y, g.z)
+	}
+	z, ok := g.dt.interpolate(x, y)
+	if !ok {
+		return g.zout
+	}
+	return z
+}
+
+func (g *tgraph2d) ROOTMerge(src root.Object) error {
+	switch src := src.(type) {
+	case *tgraph2d:
+		g.npoints += src.npoints
+		g.x = append(g.x, src.x...)
+		g.y = append(g.y, src.y...)
+		g.z = append(g.z, src.z...)
+		g.dt = nil
+		// FIXME(sbinet): handle g.funcs
+		return nil
+	default:
+		return fmt.Errorf("rhist: can not merge %T into %T", src, g)
+	}
+}
+
+// MarshalROOT implements rbytes.Marshaler
+func (g *tgraph2d) MarshalROOT(w *rbytes.WBuffer) (int, error) {
+	if w.Err() != nil {
+		return 0, w.Err()
+	}
+
+	hdr := w.WriteHeader(g.Class(), g.RVersion())
+
+	w.WriteObject(&g.Named)
+	w.WriteObject(&g.attline)
+	w.WriteObject(&g.attfill)
+	w.WriteObject(&g.attmarker)
+
+	w.WriteI32(g.npoints)
+	w.WriteI32(g.npx)
+	w.WriteI32(g.npy)
+	w.WriteI32(g.maxiter)
+	{
+		w.WriteI8(1)
+		w.WriteArrayF64(g.x)
+		w.WriteI8(1)
+		w.WriteArrayF64(g.y)
+		w.WriteI8(1)
+		w.WriteArrayF64(g.z)
+	}
+	w.WriteF64(g.min)
+	w.WriteF64(g.max)
+	w.WriteF64(g.margin)
+	w.WriteF64(g.zout)
+	w.WriteObjectAny(g.funcs)
+	w.WriteBool(g.userHisto)
+
+	return w.SetHeader(hdr)
+}
+
+// UnmarshalROOT implements rbytes.Unmarshaler
+func (g *tgraph2d) UnmarshalROOT(r *rbytes.RBuffer) error {
+	if r.Err() != nil {
+		return r.Err()
+	}
+
+	hdr := r.ReadHeader(g.Class())
+	if hdr.Vers > rvers.Graph2D {
+		panic(fmt.Errorf("rhist: invalid TGraph2D version=%d > %d", hdr.Vers, rvers.Graph2D))
+	}
+
+	r.ReadObject(&g.Named)
+	r.ReadObject(&g.attline)
+	r.ReadObject(&g.attfill)
+	r.ReadObject(&g.attmarker)
+
+	g.npoints = r.ReadI32()
+	g.npx = r.ReadI32()
+	g.npy = r.ReadI32()
+	g.maxiter = r.ReadI32()
+	{
+		n := int(g.npoints)
+		_ = r.ReadI8()
+		g.x = rbytes.ResizeF64(nil, n)
+		r.ReadArrayF64(g.x)
+		_ = r.ReadI8()
+		g.y = rbytes.ResizeF64(nil, n)
+		r.ReadArrayF64(g.y)
+		_ = r.ReadI8()
+		g.z = rbytes.ResizeF64(nil, n)
+		r.ReadArrayF64(g.z)
+	}
+	g.min = r.ReadF64()
+	g.max = r.ReadF64()
+	g.margin = r.ReadF64()
+	g.zout = r.ReadF64()
+
+	g.funcs = nil
+	if funcs := r.ReadObjectAny(); funcs != nil {
+		g.funcs = funcs.(root.List)
+	}
+	g.userHisto = r.ReadBool()
+	g.dt = nil
+
+	r.CheckHeader(hdr)
+	return r.Err()
+}
+
+// delaunay is a Delaunay triangulation of a set of (x,y) points, used to
+// linearly interpolate the z values associated with these points.
+//
+// Points are normalized to the unit square before being triangulated, so
+// the triangulation does not depend on the relative scales of x and y.
+type delaunay struct {
+	xmin, xscale float64
+	ymin, yscale float64
+
+	xs, ys, zs []float64 // normalized points, followed by the 3 super-triangle vertices
+	tris       [][3]int  // counter-clockwise triangles, indices into xs,ys,zs
+}
+
+func newDelaunay(xs, ys, zs []float64) *delaunay {
+	var (
+		dt   = &delaunay{xscale: 1, yscale: 1}
+		xmin = +math.MaxFloat64
+		xmax = -math.MaxFloat64
+		ymin = +math.MaxFloat64
+		ymax = -math.MaxFloat64
+	)
+	for i := range xs {
+		xmin = math.Min(xmin, xs[i])
+		xmax = math.Max(xmax, xs[i])
+		ymin = math.Min(ymin, ys[i])
+		ymax = math.Max(ymax, ys[i])
+	}
+	dt.xmin = xmin
+	dt.ymin = ymin
+	if xmax > xmin {
+		dt.xscale = 1 / (xmax - xmin)
+	}
+	if ymax > ymin {
+		dt.yscale = 1 / (ymax - ymin)
+	}
+
+	// remove duplicate points: ROOT only considers the first one.
+	seen := make(map[[2]float64]struct{}, len(xs))
+	for i := range xs {
+		x, y := dt.norm(xs[i], ys[i])
+		k := [2]float64{x, y}
+		if _, dup := seen[k]; dup {
+			continue
+		}
+		seen[k] = struct{}{}
+		dt.xs = append(dt.xs, x)
+		dt.ys = append(dt.ys, y)
+		dt.zs = append(dt.zs, zs[i])
+	}
+	if len(dt.xs) < 3 {
+		return dt
+	}
+
+	// Bowyer-Watson algorithm, starting from a triangle enclosing
+	// the unit square.
+	const big = 100
+	n := len(dt.xs)
+	dt.xs = append(dt.xs, -big, +big, -big)
+	dt.ys = append(dt.ys, -big, -big, +big)
+	dt.zs = append(dt.zs, 0, 0, 0)
+	tris := [][3]int{{n, n + 1, n + 2}}
+
+	for p := 0; p < n; p++ {
+		var (
+			bad   []int
+			edges = make(map[[2]int]int)
+		)
+		for i, t := range tris {
+			if !dt.inCircle(t, p) {
+				continue
+			}
+			bad = append(bad, i)
+			for j := 0; j < 3; j++ {
+				a, b := t[j], t[(j+1)%3]
+				if a > b {
+					a, b = b, a
+				}
+				edges[[2]int{a, b}]++
+			}
+		}
+
+		var (
+			keep = tris[:0:0]
+			ibad = 0
+		)
+		for i, t := range tris {
+			if ibad < len(bad) && bad[ibad] == i {
+				ibad++
+				for j := 0; j < 3; j++ {
+					a, b := t[j], t[(j+1)%3]
+					k := [2]int{a, b}
+					if a > b {
+						k = [2]int{b, a}
+					}
+					if edges[k] != 1 {
+						continue
+					}
+					// boundary edges of the cavity are kept in
+					// the counter-clockwise order of the removed triangle.
+					keep = append(keep, [3]int{a, b, p})
+				}
+				continue
+			}
+			keep = append(keep, t)
+		}
+		tris = keep
+	}
+
+	for _, t := range tris {
+		if t[0] >= n || t[1] >= n || t[2] >= n {
+			continue
+		}
+		dt.tris = append(dt.tris, t)
+	}
+	return dt
+}
+
+func (dt *delaunay) norm(x, y float64) (float64, float64) {
+	return (x - dt.xmin) * dt.xscale, (y - dt.ymin) * dt.yscale
+}
+
+// inCircle returns whether the point p lies inside the circumcircle of
+// the counter-clockwise triangle t.
+func (dt *delaunay) inCircle(t [3]int, p int) bool {
+	var (
+		px  = dt.xs[p]
+		py  = dt.ys[p]
+		ax  = dt.xs[t[0]] - px
+		ay  = dt.ys[t[0]] - py
+		bx  = dt.xs[t[1]] - px
+		by  = dt.ys[t[1]] - py
+		cx  = dt.xs[t[2]] - px
+		cy  = dt.ys[t[2]] - py
+		det = (ax*ax+ay*ay)*(bx*cy-cx*by) -
+			(bx*bx+by*by)*(ax*cy-cx*ay) +
+			(cx*cx+cy*cy)*(ax*by-bx*ay)
+	)
+	return det > 0
+}
+
+// interpolate returns the linear interpolation at (x,y) over the triangle
+// containing that point, and whether such a triangle was found.
+func (dt *delaunay) interpolate(x, y float64) (float64, bool) {
+	const eps = 1e-12
+	x, y = dt.norm(x, y)
+	for _, t := range dt.tris {
+		var (
+			x0, y0 = dt.xs[t[0]], dt.ys[t[0]]
+			x1, y1 = dt.xs[t[1]], dt.ys[t[1]]
+			x2, y2 = dt.xs[t[2]], dt.ys[t[2]]
+			det    = (y1-y2)*(x0-x2) + (x2-x1)*(y0-y2)
+		)
+		if det == 0 {
+			continue
+		}
+		var (
+			w0 = ((y1-y2)*(x-x2) + (x2-x1)*(y-y2)) / det
+			w1 = ((y2-y0)*(x-x2) + (x0-x2)*(y-y2)) / det
+			w2 = 1 - w0 - w1
+		)
+		if w0 < -eps || w1 < -eps || w2 < -eps {
+			continue
+		}
+		return w0*dt.zs[t[0]] + w1*dt.zs[t[1]] + w2*dt.zs[t[2]], true
+	}
+	return 0, false
+}
+
+func init() {
+	f := func() reflect.Value {
+		o := newGraph2D(0)
+		return reflect.ValueOf(o)
+	}
+	rtypes.Factory.Add("TGraph2D", f)
+}
+
+var (
+	_ root.Object        = (*tgraph2d)(nil)
+	_ root.Named         = (*tgraph2d)(nil)
+	_ root.Merger        = (*tgraph2d)(nil)
+	_ Graph2D            = (*tgraph2d)(nil)
+	_ rbytes.Marshaler   = (*tgraph2d)(nil)
+	_ rbytes.Unmarshaler = (*tgraph2d)(nil)
+)
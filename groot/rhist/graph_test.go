@@ -5,6 +5,8 @@
 package rhist_test
 
 import (
+	"math"
+	"path/filepath"
 	"testing"
 
 	"go-hep.org/x/hep/groot"
@@ -186,6 +188,109 @@ func TestGraphMultiErrors(t *testing.T) {
 			t.Errorf("yerr[%d].high=%v want=%v", i, yhi, want)
 		}
 	}
+
+	gme, ok := g.(rhist.GraphMultiErrors)
+	if !ok {
+		t.Fatalf("'gme' not a rhist.GraphMultiErrors: %T", obj)
+	}
+	if n, want := gme.NYErrors(), 2; n != want {
+		t.Fatalf("nyerrs=%d. want=%d", n, want)
+	}
+
+	var (
+		ylosys = []float64{0.5, 0.4, 0.8, 0.3, 1.2}
+		yhisys = []float64{0.6, 0.7, 0.6, 0.4, 0.8}
+	)
+	for i := 0; i < gme.Len(); i++ {
+		ylo, yhi := gme.YErrorN(i, 1)
+		if want := ylosys[i]; want != ylo {
+			t.Errorf("yerr[%d][1].low=%v want=%v", i, ylo, want)
+		}
+		if want := yhisys[i]; want != yhi {
+			t.Errorf("yerr[%d][1].high=%v want=%v", i, yhi, want)
+		}
+	}
+}
+
+func TestGraph2D(t *testing.T) {
+	fct := func(x, y float64) float64 { return 2*x - 3*y + 1 }
+
+	h2 := hbook.NewH2D(5, 0, 5, 4, -2, 2)
+	h2.Annotation()["name"] = "g2d"
+	h2.Annotation()["title"] = "my title"
+	for i := range h2.Binning.Bins {
+		bin := &h2.Binning.Bins[i]
+		x, y := bin.XYMid()
+		h2.Fill(x, y, fct(x, y))
+	}
+
+	fname := filepath.Join(t.TempDir(), "graph2d.root")
+	f, err := groot.Create(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	err = f.Put("g2d", rhist.NewGraph2DFrom(h2))
+	if err != nil {
+		t.Fatalf("could not write graph: %+v", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		t.Fatalf("could not close ROOT file: %+v", err)
+	}
+
+	r, err := groot.Open(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	obj, err := r.Get("g2d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, ok := obj.(rhist.Graph2D)
+	if !ok {
+		t.Fatalf("'g2d' not a rhist.Graph2D: %T", obj)
+	}
+
+	if got, want := g.Name(), "g2d"; got != want {
+		t.Errorf("invalid name: got=%q, want=%q", got, want)
+	}
+	if got, want := g.Title(), "my title"; got != want {
+		t.Errorf("invalid title: got=%q, want=%q", got, want)
+	}
+	if n, want := g.Len(), 20; n != want {
+		t.Fatalf("npts=%d. want=%d", n, want)
+	}
+
+	for i := 0; i < g.Len(); i++ {
+		x, y, z := g.XYZ(i)
+		if want := fct(x, y); z != want {
+			t.Errorf("z[%d]=%v. want=%v", i, z, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		x, y float64
+		want float64
+	}{
+		{0.5, -1.5, fct(0.5, -1.5)},
+		{1.2, 0.3, fct(1.2, 0.3)},
+		{3.7, -0.9, fct(3.7, -0.9)},
+		{4.5, 1.5, fct(4.5, 1.5)},
+		{2.5, 1.1, fct(2.5, 1.1)},
+		{0, 0, 0},  // outside of the convex hull
+		{6, 1, 0},  // outside of the convex hull
+		{2, -3, 0}, // outside of the convex hull
+	} {
+		got := g.Interpolate(tc.x, tc.y)
+		if math.Abs(got-tc.want) > 1e-12 {
+			t.Errorf("invalid interpolation at (%v,%v): got=%v, want=%v", tc.x, tc.y, got, tc.want)
+		}
+	}
 }
 
 func TestInvalidGraphMerger(t *testing.T) {
@@ -216,6 +321,11 @@ func TestInvalidGraphMerger(t *testing.T) {
 			obj:  rhist.NewGraphAsymmErrorsFrom(gr).(root.Merger),
 			want: "rhist: can not merge *rbase.ObjString into *rhist.tgraphasymmerrs",
 		},
+		{
+			name: "graph2d",
+			obj:  rhist.NewGraph2DFrom(hbook.NewH2D(2, 0, 1, 2, 0, 1)).(root.Merger),
+			want: "rhist: can not merge *rbase.ObjString into *rhist.tgraph2d",
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := tc.obj.ROOTMerge(src)
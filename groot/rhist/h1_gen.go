@@ -71,6 +71,8 @@ func NewH1FFrom(h *hbook.H1D) *H1F {
 		hroot.th1.SetTitle(v.(string))
 	}
 	hroot.th1.xaxis.xbins.Data = edges
+	hroot.th1.xaxis.fromAnnotation(h.Annotation(), AnnXLabel, AnnXBinLabels)
+	hroot.th1.yaxis.fromAnnotation(h.Annotation(), AnnYLabel, "")
 	return hroot
 }
 
@@ -217,6 +219,8 @@ func (h *H1F) AsH1D() *hbook.H1D {
 		"name":  h.Name(),
 		"title": h.Title(),
 	}
+	h.th1.xaxis.toAnnotation(hh.Ann, AnnXLabel, AnnXBinLabels)
+	h.th1.yaxis.toAnnotation(hh.Ann, AnnYLabel, "")
 
 	hh.Binning.Dist = hbook.Dist1D{
 		Dist: hbook.Dist0D{
@@ -348,6 +352,8 @@ func NewH1DFrom(h *hbook.H1D) *H1D {
 		hroot.th1.SetTitle(v.(string))
 	}
 	hroot.th1.xaxis.xbins.Data = edges
+	hroot.th1.xaxis.fromAnnotation(h.Annotation(), AnnXLabel, AnnXBinLabels)
+	hroot.th1.yaxis.fromAnnotation(h.Annotation(), AnnYLabel, "")
 	return hroot
 }
 
@@ -494,6 +500,8 @@ func (h *H1D) AsH1D() *hbook.H1D {
 		"name":  h.Name(),
 		"title": h.Title(),
 	}
+	h.th1.xaxis.toAnnotation(hh.Ann, AnnXLabel, AnnXBinLabels)
+	h.th1.yaxis.toAnnotation(hh.Ann, AnnYLabel, "")
 
 	hh.Binning.Dist = hbook.Dist1D{
 		Dist: hbook.Dist0D{
@@ -625,6 +633,8 @@ func NewH1IFrom(h *hbook.H1D) *H1I {
 		hroot.th1.SetTitle(v.(string))
 	}
 	hroot.th1.xaxis.xbins.Data = edges
+	hroot.th1.xaxis.fromAnnotation(h.Annotation(), AnnXLabel, AnnXBinLabels)
+	hroot.th1.yaxis.fromAnnotation(h.Annotation(), AnnYLabel, "")
 	return hroot
 }
 
@@ -771,6 +781,8 @@ func (h *H1I) AsH1D() *hbook.H1D {
 		"name":  h.Name(),
 		"title": h.Title(),
 	}
+	h.th1.xaxis.toAnnotation(hh.Ann, AnnXLabel, AnnXBinLabels)
+	h.th1.yaxis.toAnnotation(hh.Ann, AnnYLabel, "")
 
 	hh.Binning.Dist = hbook.Dist1D{
 		Dist: hbook.Dist0D{
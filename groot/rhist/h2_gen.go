@@ -81,18 +81,17 @@ func NewH2FFrom(h *hbook.H2D) *H2F {
 		}
 	}
 
-	oflows := h.Binning.Outflows[:]
-	for i, v := range []struct{ ix, iy int }{
-		{0, 0},
-		{0, 1},
-		{0, nybins + 1},
-		{nxbins + 1, 0},
-		{nxbins + 1, 1},
-		{nxbins + 1, nybins + 1},
-		{1, 0},
-		{1, nybins + 1},
-	} {
-		hroot.setDist2D(v.ix, v.iy, oflows[i].SumW(), oflows[i].SumW2())
+	var (
+		oflows   = h.Binning.Outflows
+		regions  = h2Outflows(nxbins, nybins)
+		cells, _ = annFloats(h.Annotation()[AnnOutflows])
+	)
+	if !hroot.setOutflowCells(regions, oflows, cells) {
+		// no per-cell information: store the whole outflow region
+		// into its first ROOT bin.
+		for i, r := range regions {
+			hroot.setDist2D(r.ix1, r.iy1, oflows[i].SumW(), oflows[i].SumW2())
+		}
 	}
 
 	xedges = append(xedges, bins[ibin(h.Binning.Nx-1, 0)].XMax())
@@ -105,6 +104,10 @@ func NewH2FFrom(h *hbook.H2D) *H2F {
 	hroot.th2.th1.xaxis.xbins.Data = xedges
 	hroot.th2.th1.yaxis.xbins.Data = yedges
 
+	hroot.th2.th1.xaxis.fromAnnotation(h.Annotation(), AnnXLabel, AnnXBinLabels)
+	hroot.th2.th1.yaxis.fromAnnotation(h.Annotation(), AnnYLabel, AnnYBinLabels)
+	hroot.th2.th1.zaxis.fromAnnotation(h.Annotation(), AnnZLabel, "")
+
 	return hroot
 }
 
@@ -261,6 +264,62 @@ func (h *H2F) setDist2D(ix, iy int, sumw, sumw2 float64) {
 	h.th1.sumw2.Data[i] = sumw2
 }
 
+// dist2DRegion returns the distribution summed over all the ROOT bins of
+// the provided region.
+func (h *H2F) dist2DRegion(r h2Region) hbook.Dist2D {
+	var o hbook.Dist2D
+	r.each(func(ix, iy int) {
+		d := h.dist2D(ix, iy)
+		o.X.Dist.N += d.X.Dist.N
+		o.X.Dist.SumW += d.X.Dist.SumW
+		o.X.Dist.SumW2 += d.X.Dist.SumW2
+		o.Y.Dist.N += d.Y.Dist.N
+		o.Y.Dist.SumW += d.Y.Dist.SumW
+		o.Y.Dist.SumW2 += d.Y.Dist.SumW2
+	})
+	return o
+}
+
+// setOutflowCells sets the content of the outflow ROOT bins from the
+// per-cell (sumw, sumw2) values of the N, E, S and W outflow regions.
+// setOutflowCells returns false and leaves the histogram untouched if
+// these values are not consistent with the hbook outflows.
+func (h *H2F) setOutflowCells(regions [8]h2Region, oflows [8]hbook.Dist2D, cells []float64) bool {
+	n := 0
+	for i := 1; i < len(regions); i += 2 {
+		n += regions[i].cells()
+	}
+	if len(cells) != 2*n {
+		return false
+	}
+
+	j := 0
+	for i := 1; i < len(regions); i += 2 {
+		var sumw, sumw2 float64
+		regions[i].each(func(ix, iy int) {
+			sumw += cells[j]
+			sumw2 += cells[j+1]
+			j += 2
+		})
+		if sumw != oflows[i].SumW() || sumw2 != oflows[i].SumW2() {
+			return false
+		}
+	}
+
+	j = 0
+	for i, r := range regions {
+		if i%2 == 0 {
+			h.setDist2D(r.ix1, r.iy1, oflows[i].SumW(), oflows[i].SumW2())
+			continue
+		}
+		r.each(func(ix, iy int) {
+			h.setDist2D(ix, iy, cells[j], cells[j+1])
+			j += 2
+		})
+	}
+	return true
+}
+
 func (h *H2F) entries(height, err float64) int64 {
 	if height <= 0 {
 		return 0
@@ -278,22 +337,39 @@ func (h *H2F) AsH2D() *hbook.H2D {
 			nx, h.XAxis().XMin(), h.XAxis().XMax(),
 			ny, h.YAxis().XMin(), h.YAxis().XMax(),
 		)
-		xinrange = 1
-		yinrange = 1
+		regions = h2Outflows(nx, ny)
+		cells   []float64
+		split   bool
 	)
 	hh.Ann = hbook.Annotation{
 		"name":  h.Name(),
 		"title": h.Title(),
 	}
-	hh.Binning.Outflows = [8]hbook.Dist2D{
-		h.dist2D(0, 0),
-		h.dist2D(0, yinrange),
-		h.dist2D(0, ny+1),
-		h.dist2D(nx+1, 0),
-		h.dist2D(nx+1, yinrange),
-		h.dist2D(nx+1, ny+1),
-		h.dist2D(xinrange, 0),
-		h.dist2D(xinrange, ny+1),
+	h.th1.xaxis.toAnnotation(hh.Ann, AnnXLabel, AnnXBinLabels)
+	h.th1.yaxis.toAnnotation(hh.Ann, AnnYLabel, AnnYBinLabels)
+	h.th1.zaxis.toAnnotation(hh.Ann, AnnZLabel, "")
+
+	for i, r := range regions {
+		hh.Binning.Outflows[i] = h.dist2DRegion(r)
+		if i%2 == 0 {
+			continue
+		}
+		// keep track of the per-cell content of the N, E, S and W
+		// outflow regions, to be able to convert back to ROOT.
+		filled := 0
+		r.each(func(ix, iy int) {
+			d := h.dist2D(ix, iy)
+			if d.SumW() != 0 || d.SumW2() != 0 {
+				filled++
+			}
+			cells = append(cells, d.SumW(), d.SumW2())
+		})
+		if filled > 1 {
+			split = true
+		}
+	}
+	if split {
+		hh.Ann[AnnOutflows] = cells
 	}
 
 	hh.Binning.Dist = hbook.Dist2D{
@@ -466,18 +542,17 @@ func NewH2DFrom(h *hbook.H2D) *H2D {
 		}
 	}
 
-	oflows := h.Binning.Outflows[:]
-	for i, v := range []struct{ ix, iy int }{
-		{0, 0},
-		{0, 1},
-		{0, nybins + 1},
-		{nxbins + 1, 0},
-		{nxbins + 1, 1},
-		{nxbins + 1, nybins + 1},
-		{1, 0},
-		{1, nybins + 1},
-	} {
-		hroot.setDist2D(v.ix, v.iy, oflows[i].SumW(), oflows[i].SumW2())
+	var (
+		oflows   = h.Binning.Outflows
+		regions  = h2Outflows(nxbins, nybins)
+		cells, _ = annFloats(h.Annotation()[AnnOutflows])
+	)
+	if !hroot.setOutflowCells(regions, oflows, cells) {
+		// no per-cell information: store the whole outflow region
+		// into its first ROOT bin.
+		for i, r := range regions {
+			hroot.setDist2D(r.ix1, r.iy1, oflows[i].SumW(), oflows[i].SumW2())
+		}
 	}
 
 	xedges = append(xedges, bins[ibin(h.Binning.Nx-1, 0)].XMax())
@@ -490,6 +565,10 @@ func NewH2DFrom(h *hbook.H2D) *H2D {
 	hroot.th2.th1.xaxis.xbins.Data = xedges
 	hroot.th2.th1.yaxis.xbins.Data = yedges
 
+	hroot.th2.th1.xaxis.fromAnnotation(h.Annotation(), AnnXLabel, AnnXBinLabels)
+	hroot.th2.th1.yaxis.fromAnnotation(h.Annotation(), AnnYLabel, AnnYBinLabels)
+	hroot.th2.th1.zaxis.fromAnnotation(h.Annotation(), AnnZLabel, "")
+
 	return hroot
 }
 
@@ -646,6 +725,62 @@ func (h *H2D) setDist2D(ix, iy int, sumw, sumw2 float64) {
 	h.th1.sumw2.Data[i] = sumw2
 }
 
+// dist2DRegion returns the distribution summed over all the ROOT bins of
+// the provided region.
+func (h *H2D) dist2DRegion(r h2Region) hbook.Dist2D {
+	var o hbook.Dist2D
+	r.each(func(ix, iy int) {
+		d := h.dist2D(ix, iy)
+		o.X.Dist.N += d.X.Dist.N
+		o.X.Dist.SumW += d.X.Dist.SumW
+		o.X.Dist.SumW2 += d.X.Dist.SumW2
+		o.Y.Dist.N += d.Y.Dist.N
+		o.Y.Dist.SumW += d.Y.Dist.SumW
+		o.Y.Dist.SumW2 += d.Y.Dist.SumW2
+	})
+	return o
+}
+
+// setOutflowCells sets the content of the outflow ROOT bins from the
+// per-cell (sumw, sumw2) values of the N, E, S and W outflow regions.
+// setOutflowCells returns false and leaves the histogram untouched if
+// these values are not consistent with the hbook outflows.
+func (h *H2D) setOutflowCells(regions [8]h2Region, oflows [8]hbook.Dist2D, cells []float64) bool {
+	n := 0
+	for i := 1; i < len(regions); i += 2 {
+		n += regions[i].cells()
+	}
+	if len(cells) != 2*n {
+		return false
+	}
+
+	j := 0
+	for i := 1; i < len(regions); i += 2 {
+		var sumw, sumw2 float64
+		regions[i].each(func(ix, iy int) {
+			sumw += cells[j]
+			sumw2 += cells[j+1]
+			j += 2
+		})
+		if sumw != oflows[i].SumW() || sumw2 != oflows[i].SumW2() {
+			return false
+		}
+	}
+
+	j = 0
+	for i, r := range regions {
+		if i%2 == 0 {
+			h.setDist2D(r.ix1, r.iy1, oflows[i].SumW(), oflows[i].SumW2())
+			continue
+		}
+		r.each(func(ix, iy int) {
+			h.setDist2D(ix, iy, cells[j], cells[j+1])
+			j += 2
+		})
+	}
+	return true
+}
+
 func (h *H2D) entries(height, err float64) int64 {
 	if height <= 0 {
 		return 0
@@ -663,22 +798,39 @@ func (h *H2D) AsH2D() *hbook.H2D {
 			nx, h.XAxis().XMin(), h.XAxis().XMax(),
 			ny, h.YAxis().XMin(), h.YAxis().XMax(),
 		)
-		xinrange = 1
-		yinrange = 1
+		regions = h2Outflows(nx, ny)
+		cells   []float64
+		split   bool
 	)
 	hh.Ann = hbook.Annotation{
 		"name":  h.Name(),
 		"title": h.Title(),
 	}
-	hh.Binning.Outflows = [8]hbook.Dist2D{
-		h.dist2D(0, 0),
-		h.dist2D(0, yinrange),
-		h.dist2D(0, ny+1),
-		h.dist2D(nx+1, 0),
-		h.dist2D(nx+1, yinrange),
-		h.dist2D(nx+1, ny+1),
-		h.dist2D(xinrange, 0),
-		h.dist2D(xinrange, ny+1),
+	h.th1.xaxis.toAnnotation(hh.Ann, AnnXLabel, AnnXBinLabels)
+	h.th1.yaxis.toAnnotation(hh.Ann, AnnYLabel, AnnYBinLabels)
+	h.th1.zaxis.toAnnotation(hh.Ann, AnnZLabel, "")
+
+	for i, r := range regions {
+		hh.Binning.Outflows[i] = h.dist2DRegion(r)
+		if i%2 == 0 {
+			continue
+		}
+		// keep track of the per-cell content of the N, E, S and W
+		// outflow regions, to be able to convert back to ROOT.
+		filled := 0
+		r.each(func(ix, iy int) {
+			d := h.dist2D(ix, iy)
+			if d.SumW() != 0 || d.SumW2() != 0 {
+				filled++
+			}
+			cells = append(cells, d.SumW(), d.SumW2())
+		})
+		if filled > 1 {
+			split = true
+		}
+	}
+	if split {
+		hh.Ann[AnnOutflows] = cells
 	}
 
 	hh.Binning.Dist = hbook.Dist2D{
@@ -851,18 +1003,17 @@ func NewH2IFrom(h *hbook.H2D) *H2I {
 		}
 	}
 
-	oflows := h.Binning.Outflows[:]
-	for i, v := range []struct{ ix, iy int }{
-		{0, 0},
-		{0, 1},
-		{0, nybins + 1},
-		{nxbins + 1, 0},
-		{nxbins + 1, 1},
-		{nxbins + 1, nybins + 1},
-		{1, 0},
-		{1, nybins + 1},
-	} {
-		hroot.setDist2D(v.ix, v.iy, oflows[i].SumW(), oflows[i].SumW2())
+	var (
+		oflows   = h.Binning.Outflows
+		regions  = h2Outflows(nxbins, nybins)
+		cells, _ = annFloats(h.Annotation()[AnnOutflows])
+	)
+	if !hroot.setOutflowCells(regions, oflows, cells) {
+		// no per-cell information: store the whole outflow region
+		// into its first ROOT bin.
+		for i, r := range regions {
+			hroot.setDist2D(r.ix1, r.iy1, oflows[i].SumW(), oflows[i].SumW2())
+		}
 	}
 
 	xedges = append(xedges, bins[ibin(h.Binning.Nx-1, 0)].XMax())
@@ -875,6 +1026,10 @@ func NewH2IFrom(h *hbook.H2D) *H2I {
 	hroot.th2.th1.xaxis.xbins.Data = xedges
 	hroot.th2.th1.yaxis.xbins.Data = yedges
 
+	hroot.th2.th1.xaxis.fromAnnotation(h.Annotation(), AnnXLabel, AnnXBinLabels)
+	hroot.th2.th1.yaxis.fromAnnotation(h.Annotation(), AnnYLabel, AnnYBinLabels)
+	hroot.th2.th1.zaxis.fromAnnotation(h.Annotation(), AnnZLabel, "")
+
 	return hroot
 }
 
@@ -1031,6 +1186,62 @@ func (h *H2I) setDist2D(ix, iy int, sumw, sumw2 float64) {
 	h.th1.sumw2.Data[i] = sumw2
 }
 
+// dist2DRegion returns the distribution summed over all the ROOT bins of
+// the provided region.
+func (h *H2I) dist2DRegion(r h2Region) hbook.Dist2D {
+	var o hbook.Dist2D
+	r.each(func(ix, iy int) {
+		d := h.dist2D(ix, iy)
+		o.X.Dist.N += d.X.Dist.N
+		o.X.Dist.SumW += d.X.Dist.SumW
+		o.X.Dist.SumW2 += d.X.Dist.SumW2
+		o.Y.Dist.N += d.Y.Dist.N
+		o.Y.Dist.SumW += d.Y.Dist.SumW
+		o.Y.Dist.SumW2 += d.Y.Dist.SumW2
+	})
+	return o
+}
+
+// setOutflowCells sets the content of the outflow ROOT bins from the
+// per-cell (sumw, sumw2) values of the N, E, S and W outflow regions.
+// setOutflowCells returns false and leaves the histogram untouched if
+// these values are not consistent with the hbook outflows.
+func (h *H2I) setOutflowCells(regions [8]h2Region, oflows [8]hbook.Dist2D, cells []float64) bool {
+	n := 0
+	for i := 1; i < len(regions); i += 2 {
+		n += regions[i].cells()
+	}
+	if len(cells) != 2*n {
+		return false
+	}
+
+	j := 0
+	for i := 1; i < len(regions); i += 2 {
+		var sumw, sumw2 float64
+		regions[i].each(func(ix, iy int) {
+			sumw += cells[j]
+			sumw2 += cells[j+1]
+			j += 2
+		})
+		if sumw != oflows[i].SumW() || sumw2 != oflows[i].SumW2() {
+			return false
+		}
+	}
+
+	j = 0
+	for i, r := range regions {
+		if i%2 == 0 {
+			h.setDist2D(r.ix1, r.iy1, oflows[i].SumW(), oflows[i].SumW2())
+			continue
+		}
+		r.each(func(ix, iy int) {
+			h.setDist2D(ix, iy, cells[j], cells[j+1])
+			j += 2
+		})
+	}
+	return true
+}
+
 func (h *H2I) entries(height, err float64) int64 {
 	if height <= 0 {
 		return 0
@@ -1048,22 +1259,39 @@ func (h *H2I) AsH2D() *hbook.H2D {
 			nx, h.XAxis().XMin(), h.XAxis().XMax(),
 			ny, h.YAxis().XMin(), h.YAxis().XMax(),
 		)
-		xinrange = 1
-		yinrange = 1
+		regions = h2Outflows(nx, ny)
+		cells   []float64
+		split   bool
 	)
 	hh.Ann = hbook.Annotation{
 		"name":  h.Name(),
 		"title": h.Title(),
 	}
-	hh.Binning.Outflows = [8]hbook.Dist2D{
-		h.dist2D(0, 0),
-		h.dist2D(0, yinrange),
-		h.dist2D(0, ny+1),
-		h.dist2D(nx+1, 0),
-		h.dist2D(nx+1, yinrange),
-		h.dist2D(nx+1, ny+1),
-		h.dist2D(xinrange, 0),
-		h.dist2D(xinrange, ny+1),
+	h.th1.xaxis.toAnnotation(hh.Ann, AnnXLabel, AnnXBinLabels)
+	h.th1.yaxis.toAnnotation(hh.Ann, AnnYLabel, AnnYBinLabels)
+	h.th1.zaxis.toAnnotation(hh.Ann, AnnZLabel, "")
+
+	for i, r := range regions {
+		hh.Binning.Outflows[i] = h.dist2DRegion(r)
+		if i%2 == 0 {
+			continue
+		}
+		// keep track of the per-cell content of the N, E, S and W
+		// outflow regions, to be able to convert back to ROOT.
+		filled := 0
+		r.each(func(ix, iy int) {
+			d := h.dist2D(ix, iy)
+			if d.SumW() != 0 || d.SumW2() != 0 {
+				filled++
+			}
+			cells = append(cells, d.SumW(), d.SumW2())
+		})
+		if filled > 1 {
+			split = true
+		}
+	}
+	if split {
+		hh.Ann[AnnOutflows] = cells
 	}
 
 	hh.Binning.Dist = hbook.Dist2D{
@@ -0,0 +1,180 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rhist
+
+import (
+	"go-hep.org/x/hep/groot/rbase"
+	"go-hep.org/x/hep/groot/rcont"
+	"go-hep.org/x/hep/groot/root"
+	"go-hep.org/x/hep/hbook"
+)
+
+// Annotation keys used to carry ROOT histogram metadata through
+// hbook histograms.
+//
+// The axis titles use the YODA plotting conventions.
+const (
+	AnnXLabel     = "XLabel"       // title of the X-axis
+	AnnYLabel     = "YLabel"       // title of the Y-axis
+	AnnZLabel     = "ZLabel"       // title of the Z-axis
+	AnnXBinLabels = "XBinLabels"   // labels of the bins along X
+	AnnYBinLabels = "YBinLabels"   // labels of the bins along Y
+	AnnOutflows   = "ROOTOutflows" // per-cell (sumw, sumw2) of the N, E, S and W outflow regions of a TH2
+)
+
+// BinLabels returns the labels of the bins of this axis, if any.
+// The label of the i-th bin is stored at index i-1.
+func (a *taxis) BinLabels() []string {
+	if a.labels == nil || a.labels.Len() == 0 {
+		return nil
+	}
+	labels := make([]string, a.nbins)
+	n := 0
+	for i := 0; i < a.labels.Len(); i++ {
+		lbl, ok := a.labels.At(i).(*rbase.ObjString)
+		if !ok {
+			continue
+		}
+		bin := int(lbl.UID())
+		if bin < 1 || bin > a.nbins {
+			continue
+		}
+		labels[bin-1] = lbl.String()
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	return labels
+}
+
+// setBinLabels sets the labels of the bins of this axis.
+// Empty labels are ignored.
+func (a *taxis) setBinLabels(labels []string) {
+	var objs []root.Object
+	for i, v := range labels {
+		if v == "" || i >= a.nbins {
+			continue
+		}
+		lbl := rbase.NewObjString(v)
+		lbl.SetUID(uint32(i + 1))
+		objs = append(objs, lbl)
+	}
+	if len(objs) == 0 {
+		a.labels = nil
+		return
+	}
+	a.labels = &rcont.HashList{List: *rcont.NewList("", objs)}
+}
+
+// toAnnotation stores the title and bin labels of this axis into ann.
+func (a *taxis) toAnnotation(ann hbook.Annotation, title, labels string) {
+	if v := a.Title(); v != "" {
+		ann[title] = v
+	}
+	if labels == "" {
+		return
+	}
+	if v := a.BinLabels(); v != nil {
+		ann[labels] = v
+	}
+}
+
+// fromAnnotation sets the title and bin labels of this axis from ann.
+func (a *taxis) fromAnnotation(ann hbook.Annotation, title, labels string) {
+	if v, ok := ann[title].(string); ok {
+		a.SetTitle(v)
+	}
+	if labels == "" {
+		return
+	}
+	if v, ok := annStrings(ann[labels]); ok {
+		a.setBinLabels(v)
+	}
+}
+
+// annStrings converts an annotation value to a slice of strings.
+// annStrings handles values decoded from YODA files.
+func annStrings(v interface{}) ([]string, bool) {
+	switch v := v.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		o := make([]string, len(v))
+		for i, e := range v {
+			switch e := e.(type) {
+			case string:
+				o[i] = e
+			case nil:
+			default:
+				return nil, false
+			}
+		}
+		return o, true
+	}
+	return nil, false
+}
+
+// annFloats converts an annotation value to a slice of float64.
+// annFloats handles values decoded from YODA files.
+func annFloats(v interface{}) ([]float64, bool) {
+	switch v := v.(type) {
+	case []float64:
+		return v, true
+	case []interface{}:
+		o := make([]float64, len(v))
+		for i, e := range v {
+			switch e := e.(type) {
+			case float64:
+				o[i] = e
+			case int:
+				o[i] = float64(e)
+			case int64:
+				o[i] = float64(e)
+			default:
+				return nil, false
+			}
+		}
+		return o, true
+	}
+	return nil, false
+}
+
+// h2Region is a rectangular range [ix1,ix2]x[iy1,iy2] of ROOT bins
+// making up an hbook outflow region.
+type h2Region struct {
+	ix1, ix2 int
+	iy1, iy2 int
+}
+
+// cells returns the number of ROOT bins in the region.
+func (r h2Region) cells() int {
+	return (r.ix2 - r.ix1 + 1) * (r.iy2 - r.iy1 + 1)
+}
+
+// each calls f for each ROOT bin of the region.
+func (r h2Region) each(f func(ix, iy int)) {
+	for iy := r.iy1; iy <= r.iy2; iy++ {
+		for ix := r.ix1; ix <= r.ix2; ix++ {
+			f(ix, iy)
+		}
+	}
+}
+
+// h2Outflows returns the ROOT bins corresponding to each of the hbook
+// outflow regions of a 2-dim histogram with nx*ny in-range bins, in the
+// order of hbook.Binning2D.Outflows.
+func h2Outflows(nx, ny int) [8]h2Region {
+	return [8]h2Region{
+		{0, 0, ny + 1, ny + 1},           // NW
+		{1, nx, ny + 1, ny + 1},          // N
+		{nx + 1, nx + 1, ny + 1, ny + 1}, // NE
+		{nx + 1, nx + 1, 1, ny},          // E
+		{nx + 1, nx + 1, 0, 0},           // SE
+		{1, nx, 0, 0},                    // S
+		{0, 0, 0, 0},                     // SW
+		{0, 0, 1, ny},                    // W
+	}
+}
@@ -86,6 +86,30 @@ type GraphErrors interface {
 	YError(i int) (float64, float64)
 }
 
+// GraphMultiErrors describes a ROOT TGraphMultiErrors
+type GraphMultiErrors interface {
+	GraphErrors
+	// NYErrors returns the number of Y error dimensions.
+	NYErrors() int
+	// YErrorN returns two error values for the e-th Y error dimension of Y data.
+	YErrorN(i, e int) (float64, float64)
+}
+
+// Graph2D describes a ROOT TGraph2D
+type Graph2D interface {
+	root.Named
+
+	Len() int
+	XY(i int) (float64, float64)
+	XYZ(i int) (float64, float64, float64)
+
+	// Interpolate returns the value at (x,y) of the linear interpolation
+	// of the graph points over their Delaunay triangulation.
+	// Points outside of the convex hull of the graph are given
+	// the graph's Z-out value (0 by default).
+	Interpolate(x, y float64) float64
+}
+
 // F1Composition describes a 1-dim functions composition.
 type F1Composition interface {
 	root.Object
@@ -280,6 +280,22 @@ func TestWRBuffer(t *testing.T) {
 			name: "TMultiGraph",
 			want: loadFrom("../testdata/tgme.root", "mg"),
 		},
+		{
+			name: "TGraph2D",
+			want: func() rtests.ROOTer {
+				g := newGraph2D(3)
+				g.SetName("g2d")
+				g.SetTitle("graph-2d")
+				copy(g.x, []float64{1, 2, 3})
+				copy(g.y, []float64{4, 5, 6})
+				copy(g.z, []float64{7, 8, 9})
+				g.min = 7
+				g.max = 9
+				g.zout = -1
+				g.funcs = rcont.NewList("", []root.Object{})
+				return g
+			}(),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			{
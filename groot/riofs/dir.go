@@ -245,6 +245,10 @@ func (dir *tdirectoryFile) readKeys() error {
 		return err
 	}
 
+	if hdr.objlen < 4 {
+		return fmt.Errorf("riofs: invalid list of keys (objlen=%d)", hdr.objlen)
+	}
+
 	buf = make([]byte, hdr.objlen)
 	_, err = dir.file.ReadAt(buf, dir.seekkeys+int64(hdr.keylen))
 	if err != nil {
@@ -618,6 +622,12 @@ func (dir *tdirectoryFile) writeKeys() error {
 		nbytes += key.keylen
 	}
 
+	if dir.seekkeys != 0 {
+		// the list of keys has already been written (e.g. when syncing
+		// the file): the previous record is now obsolete.
+		dir.file.markFree(dir.seekkeys, dir.seekkeys+int64(dir.nbyteskeys)-1)
+	}
+
 	hdr := newKey(dir, dir.Name(), dir.Title(), "TDirectory", nbytes, dir.file)
 
 	buf := rbytes.NewWBuffer(make([]byte, nbytes), nil, 0, nil)
@@ -40,6 +40,21 @@ type Writer interface {
 	io.Closer
 }
 
+// ReaderV is implemented by readers supporting vectored reads, ie reading
+// many byte ranges of a file in a single request.
+type ReaderV interface {
+	// ReadV reads the segments segs of the file.
+	// The Data of a segment is shortened to the number of bytes read
+	// when the segment extends past the end of the file.
+	ReadV(segs []Segment) error
+}
+
+// Segment is a byte range of a file, as read by ReaderV.ReadV.
+type Segment struct {
+	Offset int64  // offset of the segment in the file
+	Data   []byte // buffer receiving the len(Data) bytes of the segment
+}
+
 type syncer interface {
 	// Sync commits the current contents of the file to stable storage.
 	Sync() error
@@ -274,6 +289,27 @@ func (f *File) ReadAt(p []byte, off int64) (int, error) {
 	return f.r.ReadAt(p, off)
 }
 
+// ReadV reads the segments segs of the file.
+// The Data of a segment is shortened to the number of bytes read
+// when the segment extends past the end of the file.
+//
+// ReadV fetches all the segments in a single request when the underlying
+// reader implements ReaderV, and reads them one at a time otherwise.
+func (f *File) ReadV(segs []Segment) error {
+	if r, ok := f.r.(ReaderV); ok {
+		return r.ReadV(segs)
+	}
+	for i := range segs {
+		seg := &segs[i]
+		n, err := f.r.ReadAt(seg.Data, seg.Offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("riofs: could not read segment [%d, %d): %w", seg.Offset, seg.Offset+int64(len(seg.Data)), err)
+		}
+		seg.Data = seg.Data[:n]
+	}
+	return nil
+}
+
 // WriteAt implements io.WriterAt
 func (f *File) WriteAt(p []byte, off int64) (int, error) {
 	return f.w.WriteAt(p, off)
@@ -437,23 +473,8 @@ func (f *File) Close() error {
 
 	var err error
 
-	err = f.dir.close()
-	if err != nil {
-		return err
-	}
-
 	if f.w != nil {
-		err = f.writeStreamerInfo()
-		if err != nil {
-			return err
-		}
-
-		err = f.writeFreeSegments()
-		if err != nil {
-			return err
-		}
-
-		err = f.writeHeader()
+		err = f.sync()
 		if err != nil {
 			return err
 		}
@@ -471,6 +492,36 @@ func (f *File) Close() error {
 	return err
 }
 
+// Sync writes the list of keys of the directories, the streamers and the
+// header of the File to the underlying storage, so the objects put into
+// the File so far can be read back (e.g. by another process) while the File
+// is still being written to.
+func (f *File) Sync() error {
+	if f.w == nil {
+		return fmt.Errorf("could not sync file %q: %w", f.Name(), ErrReadOnly)
+	}
+	return f.sync()
+}
+
+func (f *File) sync() error {
+	err := f.dir.close()
+	if err != nil {
+		return err
+	}
+
+	err = f.writeStreamerInfo()
+	if err != nil {
+		return err
+	}
+
+	err = f.writeFreeSegments()
+	if err != nil {
+		return err
+	}
+
+	return f.writeHeader()
+}
+
 // Keys returns the list of keys this File contains
 func (f *File) Keys() []Key {
 	return f.dir.Keys()
@@ -6,6 +6,7 @@ package riofs_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -547,3 +548,71 @@ func TestReadOnlyFile(t *testing.T) {
 		t.Fatalf("expected an error. got nil")
 	}
 }
+
+func TestFileSync(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "sync.root")
+
+	w, err := riofs.Create(fname)
+	if err != nil {
+		t.Fatalf("could not create file: %+v", err)
+	}
+	defer w.Close()
+
+	dir, err := w.Mkdir("dir")
+	if err != nil {
+		t.Fatalf("could not create directory: %+v", err)
+	}
+
+	check := func(want ...string) {
+		t.Helper()
+		r, err := riofs.Open(fname)
+		if err != nil {
+			t.Fatalf("could not open synced file: %+v", err)
+		}
+		defer r.Close()
+
+		for _, name := range want {
+			o, err := riofs.Dir(r).Get(name)
+			if err != nil {
+				t.Fatalf("could not get %q: %+v", name, err)
+			}
+			if got, want := o.(root.Named).Name(), strings.Replace(name, "dir/", "", 1); got != want {
+				t.Fatalf("invalid object: got=%q, want=%q", got, want)
+			}
+		}
+	}
+
+	for i, name := range []string{"o1", "o2"} {
+		err = w.Put(name, rbase.NewObjString(name))
+		if err != nil {
+			t.Fatalf("could not put %q: %+v", name, err)
+		}
+		err = dir.Put(name, rbase.NewObjString(name))
+		if err != nil {
+			t.Fatalf("could not put %q: %+v", name, err)
+		}
+
+		err = w.Sync()
+		if err != nil {
+			t.Fatalf("could not sync file (iter=%d): %+v", i, err)
+		}
+		check(name, "dir/"+name)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("could not close file: %+v", err)
+	}
+	check("o1", "o2", "dir/o1", "dir/o2")
+
+	r, err := riofs.Open(fname)
+	if err != nil {
+		t.Fatalf("could not open file: %+v", err)
+	}
+	defer r.Close()
+
+	err = r.Sync()
+	if !errors.Is(err, riofs.ErrReadOnly) {
+		t.Fatalf("invalid error: got=%v, want=%v", err, riofs.ErrReadOnly)
+	}
+}
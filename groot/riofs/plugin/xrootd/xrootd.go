@@ -3,10 +3,14 @@
 // license that can be found in the LICENSE file.
 
 // Package xrootd is a plugin for riofs.Open to support opening ROOT files over xrootd.
+//
+// Files opened with this plugin implement riofs.ReaderV, so many byte ranges
+// (e.g. baskets) are fetched in a single round trip.
 package xrootd
 
 import (
 	"go-hep.org/x/hep/groot/riofs"
+	"go-hep.org/x/hep/xrootd/xrdfs"
 	"go-hep.org/x/hep/xrootd/xrdio"
 )
 
@@ -16,10 +20,36 @@ func init() {
 }
 
 func openFile(path string) (riofs.Reader, error) {
-	return xrdio.Open(path)
+	f, err := xrdio.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &file{f}, nil
+}
+
+// file is a remote file, reading segments with vectored reads.
+type file struct {
+	*xrdio.File
+}
+
+// ReadV implements riofs.ReaderV.
+func (f *file) ReadV(segs []riofs.Segment) error {
+	vs := make([]xrdfs.Segment, len(segs))
+	for i, seg := range segs {
+		vs[i] = xrdfs.Segment{Offset: seg.Offset, Data: seg.Data}
+	}
+	err := f.File.ReadV(vs)
+	if err != nil {
+		return err
+	}
+	for i := range segs {
+		segs[i].Data = vs[i].Data
+	}
+	return nil
 }
 
 var (
-	_ riofs.Reader = (*xrdio.File)(nil)
-	_ riofs.Writer = (*xrdio.File)(nil)
+	_ riofs.Reader  = (*file)(nil)
+	_ riofs.Writer  = (*file)(nil)
+	_ riofs.ReaderV = (*file)(nil)
 )
@@ -128,6 +128,60 @@ type PlotResponse struct {
 	Data string `json:"data"`
 }
 
+type HistTreeRequest struct {
+	URI string `json:"uri"`
+	Dir string `json:"dir"`
+	Obj string `json:"obj"`
+
+	Name    string  `json:"name,omitempty"`    // name of the histogram (default: "h1")
+	Expr    string  `json:"expr"`              // expression to histogram
+	Cut     string  `json:"cut,omitempty"`     // selection expression
+	Binning Binning `json:"binning"`           // binning of the histogram
+	Entries int64   `json:"entries,omitempty"` // maximum number of entries to scan (default: all)
+
+	Format string `json:"format,omitempty"` // format of the histogram: "json" (default) or "root"
+	Plot   bool   `json:"plot,omitempty"`   // whether to render the histogram
+
+	Options PlotOptions `json:"options"`
+}
+
+// Binning describes the binning of a 1-dim histogram.
+// If Bins is zero, 100 bins are used.
+// If Min is not lower than Max, the range is inferred from the data:
+// the values are then buffered and requests yielding more than 1<<20 values
+// are rejected, so larger data sets need an explicit range.
+type Binning struct {
+	Bins int     `json:"bins"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+}
+
+type HistTreeResponse struct {
+	URI string `json:"uri"`
+	Dir string `json:"dir"`
+	Obj string `json:"obj"`
+
+	Entries int64 `json:"entries"` // number of scanned entries
+
+	H1   *H1    `json:"h1,omitempty"`   // histogram, in JSON format
+	ROOT string `json:"root,omitempty"` // base64 encoded ROOT file holding the histogram
+	Data string `json:"data,omitempty"` // base64 encoded representation of the plot
+}
+
+// H1 is the JSON representation of a 1-dim histogram.
+type H1 struct {
+	Name      string    `json:"name"`
+	Title     string    `json:"title"`
+	Entries   int64     `json:"entries"`
+	Edges     []float64 `json:"edges"` // edges of the bins, including the upper edge of the last bin
+	SumW      []float64 `json:"sumw"`  // sum of weights, per bin
+	SumW2     []float64 `json:"sumw2"` // sum of squared weights, per bin
+	Underflow float64   `json:"underflow"`
+	Overflow  float64   `json:"overflow"`
+	Mean      float64   `json:"mean"`
+	StdDev    float64   `json:"stddev"`
+}
+
 type PlotOptions struct {
 	Title string `json:"title,omitempty"`
 	X     string `json:"x,omitempty"`
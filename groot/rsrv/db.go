@@ -5,18 +5,33 @@
 package rsrv
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go-hep.org/x/hep/groot/riofs"
 )
 
+var (
+	errDupFile = errors.New("rsrv: file already open")
+	errQuota   = errors.New("rsrv: too many open remote files")
+)
+
 type DB struct {
 	sync.RWMutex
 	dir   string
 	files map[string]*riofs.File // a map of URI -> ROOT file
+	infos map[string]*fileInfo   // a map of URI -> file usage
+}
+
+type fileInfo struct {
+	atime  int64 // last access time (in ns), accessed atomically
+	remote bool  // whether the file was opened from a remote server
 }
 
 func NewDB(dir string) *DB {
@@ -27,6 +42,7 @@ func NewDB(dir string) *DB {
 	return &DB{
 		dir:   dir,
 		files: make(map[string]*riofs.File),
+		infos: make(map[string]*fileInfo),
 	}
 }
 
@@ -37,6 +53,7 @@ func (db *DB) Close() {
 		f.Close()
 	}
 	db.files = nil
+	db.infos = nil
 	os.RemoveAll(db.dir)
 }
 
@@ -58,6 +75,7 @@ func (db *DB) Tx(uri string, fct func(f *riofs.File) error) error {
 	if f == nil {
 		return fmt.Errorf("rsrv: no such file %q", uri)
 	}
+	db.touch(uri)
 	return fct(db.files[uri])
 }
 
@@ -74,6 +92,84 @@ func (db *DB) set(uri string, f *riofs.File) {
 		old.Close()
 	}
 	db.files[uri] = f
+	db.infos[uri] = &fileInfo{}
+	db.touch(uri)
+}
+
+// add registers the file under the provided URI, unless a file is already
+// registered under that URI, or more than max remote files would be open.
+// If max is zero, the number of remote files is not limited.
+func (db *DB) add(uri string, f *riofs.File, max int) error {
+	db.Lock()
+	defer db.Unlock()
+	if _, dup := db.files[uri]; dup {
+		return errDupFile
+	}
+	remote := isRemote(uri)
+	if err := db.quota(remote, max); err != nil {
+		return err
+	}
+	db.files[uri] = f
+	db.infos[uri] = &fileInfo{remote: remote}
+	db.touch(uri)
+	return nil
+}
+
+// checkQuota returns errQuota if opening the file located by the provided
+// URI would exceed the max number of open remote files.
+// checkQuota does not open the file and only serves to reject requests
+// early: add performs the same check when registering the file.
+func (db *DB) checkQuota(uri string, max int) error {
+	db.RLock()
+	defer db.RUnlock()
+	return db.quota(isRemote(uri), max)
+}
+
+// quota returns errQuota if one more remote file would exceed the max
+// number of open remote files.
+// quota must be called with the lock held.
+func (db *DB) quota(remote bool, max int) error {
+	if max <= 0 || !remote {
+		return nil
+	}
+	n := 0
+	for _, info := range db.infos {
+		if info.remote {
+			n++
+		}
+	}
+	if n >= max {
+		return errQuota
+	}
+	return nil
+}
+
+// touch records the current time as the last access time of the file.
+// touch must be called with the lock held.
+func (db *DB) touch(uri string) {
+	if info, ok := db.infos[uri]; ok {
+		atomic.StoreInt64(&info.atime, time.Now().UnixNano())
+	}
+}
+
+// evict closes the files that were not accessed since the provided time,
+// and returns their URIs.
+func (db *DB) evict(since time.Time) []string {
+	db.Lock()
+	defer db.Unlock()
+
+	var uris []string
+	for uri, f := range db.files {
+		if atomic.LoadInt64(&db.infos[uri].atime) >= since.UnixNano() {
+			continue
+		}
+		f.Close()
+		delete(db.files, uri)
+		delete(db.infos, uri)
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
 }
 
 func (db *DB) del(uri string) {
@@ -86,4 +182,19 @@ func (db *DB) del(uri string) {
 	}
 	f.Close()
 	delete(db.files, uri)
+	delete(db.infos, uri)
+}
+
+// isRemote returns whether the URI locates a file on a remote server.
+func isRemote(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "", "file":
+		return false
+	}
+	// single letter schemes are Windows drive letters.
+	return len(u.Scheme) > 1
 }
@@ -5,9 +5,11 @@
 package rsrv
 
 import (
+	"errors"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"go-hep.org/x/hep/groot/riofs"
 )
@@ -56,3 +58,80 @@ func TestDB(t *testing.T) {
 
 	db.Close()
 }
+
+func TestDBQuota(t *testing.T) {
+	db := NewDB(t.TempDir())
+	defer db.Close()
+
+	open := func() *riofs.File {
+		f, err := riofs.Open("../testdata/simple.root")
+		if err != nil {
+			t.Fatalf("could not open ROOT file: %v", err)
+		}
+		return f
+	}
+
+	for _, tc := range []struct {
+		uri string
+		err error
+	}{
+		{"file:///data/simple.root", nil},
+		{"/data/simple.root", nil},
+		{"root://example.org/simple-1.root", nil},
+		{"root://example.org/simple-1.root", errDupFile},
+		{"https://example.org/simple-2.root", nil},
+		{"root://example.org/simple-3.root", errQuota},
+		{"/data/other.root", nil},
+	} {
+		if err := db.checkQuota(tc.uri, 2); !errors.Is(err, tc.err) && !errors.Is(tc.err, errDupFile) {
+			t.Fatalf("%s: invalid quota error: got=%v, want=%v", tc.uri, err, tc.err)
+		}
+		f := open()
+		err := db.add(tc.uri, f, 2)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("%s: invalid error: got=%v, want=%v", tc.uri, err, tc.err)
+		}
+		if err != nil {
+			f.Close()
+		}
+	}
+
+	db.del("https://example.org/simple-2.root")
+	f := open()
+	err := db.add("root://example.org/simple-3.root", f, 2)
+	if err != nil {
+		f.Close()
+		t.Fatalf("could not add remote file: %+v", err)
+	}
+}
+
+func TestDBEvict(t *testing.T) {
+	db := NewDB(t.TempDir())
+	defer db.Close()
+
+	for _, uri := range []string{"f1.root", "f2.root", "f3.root"} {
+		f, err := riofs.Open("../testdata/simple.root")
+		if err != nil {
+			t.Fatalf("could not open ROOT file: %v", err)
+		}
+		db.set(uri, f)
+	}
+
+	if got := db.evict(time.Now().Add(-time.Hour)); len(got) != 0 {
+		t.Fatalf("invalid evicted files: %v", got)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	since := time.Now()
+	err := db.Tx("f2.root", func(f *riofs.File) error { return nil })
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if got, want := db.evict(since), []string{"f1.root", "f3.root"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid evicted files: got=%v, want=%v", got, want)
+	}
+	if got, want := db.Files(), []string{"f2.root"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid list of files: got=%v, want=%v", got, want)
+	}
+}
@@ -5,8 +5,10 @@
 package rsrv
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -45,6 +47,8 @@ func (srv *Server) handlePing(w http.ResponseWriter, r *http.Request) error {
 //   {"uri": "root://example.org/some/file.root"}
 //
 // OpenFile replies with a STATUS/OK or STATUS/NotFound if no such file exist.
+// OpenFile replies with a STATUS/TooManyRequests if the user already has
+// the maximum number of remote files open.
 func (srv *Server) OpenFile(w http.ResponseWriter, r *http.Request) {
 	srv.wrap(srv.handleOpen)(w, r)
 }
@@ -71,12 +75,38 @@ func (srv *Server) handleOpen(w http.ResponseWriter, r *http.Request) error {
 		return json.NewEncoder(w).Encode(nil)
 	}
 
+	err = db.checkQuota(req.URI, srv.maxRemote)
+	if err != nil {
+		return &statusError{
+			code: http.StatusTooManyRequests,
+			err:  fmt.Errorf("could not open ROOT file %q: %w", req.URI, err),
+		}
+	}
+
 	f, err := riofs.Open(req.URI)
 	if err != nil {
 		return fmt.Errorf("could not open ROOT file: %w", err)
 	}
 
-	db.set(req.URI, f)
+	err = db.add(req.URI, f, srv.maxRemote)
+	switch {
+	case err == nil:
+		// ok.
+	case errors.Is(err, errDupFile):
+		f.Close()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		return json.NewEncoder(w).Encode(nil)
+	case errors.Is(err, errQuota):
+		f.Close()
+		return &statusError{
+			code: http.StatusTooManyRequests,
+			err:  fmt.Errorf("could not open ROOT file %q: %w", req.URI, err),
+		}
+	default:
+		f.Close()
+		return fmt.Errorf("could not register ROOT file: %w", err)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -676,6 +706,9 @@ func (srv *Server) handlePlotS2(w http.ResponseWriter, r *http.Request) error {
 		}
 
 		robj, ok := obj.(rhist.Graph)
+		if _, g2d := obj.(rhist.Graph2D); g2d {
+			ok = false
+		}
 		if !ok {
 			return fmt.Errorf("rsrv: object %v:%s/%q is not a 2-dim scatter (type=%s)", req.URI, req.Dir, req.Obj, obj.Class())
 		}
@@ -880,3 +913,359 @@ func (srv *Server) handlePlotTree(w http.ResponseWriter, r *http.Request) error
 	w.WriteHeader(http.StatusOK)
 	return json.NewEncoder(w).Encode(resp)
 }
+
+// HistTree fills a histogram with the values of an expression over the
+// entries of a Tree, as specified by the HistTreeRequest:
+//  {"uri": "file:///some/file.root", "dir": "/some/dir", "obj": "tree",
+//   "expr": "sqrt(px*px + py*py)", "cut": "n > 2",
+//   "binning": {"bins": 50, "min": 0, "max": 100}}
+//  {"uri": "file:///some/file.root", "dir": "/some/dir", "obj": "tree",
+//   "expr": "pt", "format": "root", "plot": true,
+//   "options": {"type": "svg", "title": "my plot title"}}
+// Expressions use the Go syntax. Identifiers name branches of the tree.
+// Array branches are iterated over element-wise.
+// Entries are only histogrammed when the cut expression is non-zero.
+//
+// HistTree replies with a HistTreeResponse, where "h1" contains the
+// histogram in JSON format or "root" contains the base64 encoded ROOT
+// file holding the histogram, and "data" contains the base64 encoded
+// representation of the plot, if requested.
+//
+// Requests are processed by a pool of workers, and are subject to the
+// limits of the server.
+func (srv *Server) HistTree(w http.ResponseWriter, r *http.Request) {
+	srv.wrap(srv.handleHistTree)(w, r)
+}
+
+func (srv *Server) handleHistTree(w http.ResponseWriter, r *http.Request) error {
+	dec := json.NewDecoder(r.Body)
+	defer r.Body.Close()
+
+	var (
+		req  HistTreeRequest
+		resp HistTreeResponse
+	)
+
+	err := dec.Decode(&req)
+	if err != nil {
+		return fmt.Errorf("could not decode hist-tree request: %w", err)
+	}
+
+	if req.Name == "" {
+		req.Name = "h1"
+	}
+	switch req.Format {
+	case "":
+		req.Format = "json"
+	case "json", "root":
+	default:
+		return &statusError{
+			code: http.StatusBadRequest,
+			err:  fmt.Errorf("rsrv: invalid histogram format %q", req.Format),
+		}
+	}
+	if req.Binning.Bins <= 0 {
+		req.Binning.Bins = 100
+	}
+	if lim := srv.limits.Bins; lim > 0 && req.Binning.Bins > lim {
+		return &statusError{
+			code: http.StatusBadRequest,
+			err:  fmt.Errorf("rsrv: too many bins (got=%d, max=%d)", req.Binning.Bins, lim),
+		}
+	}
+
+	env := newExprEnv()
+	expr, err := env.compile(req.Expr)
+	if err != nil {
+		return &statusError{code: http.StatusBadRequest, err: err}
+	}
+	var cut func() float64
+	if req.Cut != "" {
+		cut, err = env.compile(req.Cut)
+		if err != nil {
+			return &statusError{code: http.StatusBadRequest, err: err}
+		}
+	}
+
+	ctx := r.Context()
+	if srv.limits.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, srv.limits.Duration)
+		defer cancel()
+	}
+
+	release, err := srv.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	db, err := srv.db(r)
+	if err != nil {
+		return fmt.Errorf("could not open ROOT file database: %w", err)
+	}
+
+	err = db.Tx(req.URI, func(f *riofs.File) error {
+		if f == nil {
+			return fmt.Errorf("rsrv: could not find ROOT file named %q", req.URI)
+		}
+
+		obj, err := riofs.Dir(f).Get(req.Dir)
+		if err != nil {
+			return fmt.Errorf("could not find directory %q in file %q: %w", req.Dir, req.URI, err)
+		}
+		dir, ok := obj.(riofs.Directory)
+		if !ok {
+			return fmt.Errorf("rsrv: %q in file %q is not a directory", req.Dir, req.URI)
+		}
+
+		obj, err = dir.Get(req.Obj)
+		if err != nil {
+			return fmt.Errorf("could not find object %q under directory %q in file %q: %w", req.Obj, req.Dir, req.URI, err)
+		}
+
+		tree, ok := obj.(rtree.Tree)
+		if !ok {
+			return fmt.Errorf("rsrv: object %v:%s/%q is not a tree (type=%s)", req.URI, req.Dir, req.Obj, obj.Class())
+		}
+
+		end := tree.Entries()
+		if req.Entries > 0 && req.Entries < end {
+			end = req.Entries
+		}
+		if lim := srv.limits.Entries; lim > 0 && end > lim {
+			return &statusError{
+				code: http.StatusRequestEntityTooLarge,
+				err:  fmt.Errorf("rsrv: too many entries to scan (got=%d, max=%d)", end, lim),
+			}
+		}
+
+		var (
+			rvars = make([]rtree.ReadVar, len(env.names))
+			fvs   = make([]floats, len(env.names))
+			slots = make([]*float64, len(env.names))
+		)
+		for i, name := range env.names {
+			br := tree.Branch(name)
+			if br == nil {
+				return &statusError{
+					code: http.StatusBadRequest,
+					err:  fmt.Errorf("rsrv: tree %v:%s/%s has no branch %q", req.URI, req.Dir, req.Obj, name),
+				}
+			}
+			leaf := br.Leaves()[0] // FIXME(sbinet) handle sub-leaves
+			fv, err := newFloats(leaf)
+			if err != nil {
+				return fmt.Errorf("could not create float-leaf: %w", err)
+			}
+			fvs[i] = fv
+			slots[i] = env.vars[name]
+			rvars[i] = rtree.ReadVar{Name: name, Leaf: leaf.Name(), Value: fv.ptr}
+		}
+
+		var (
+			auto = req.Binning.Min >= req.Binning.Max
+			vals []float64
+			h1   *hbook.H1D
+		)
+		if !auto {
+			h1 = hbook.NewH1D(req.Binning.Bins, req.Binning.Min, req.Binning.Max)
+		}
+		fill := func(v float64) error {
+			if !auto {
+				h1.Fill(v, 1)
+				return nil
+			}
+			if len(vals) >= maxAutoValues {
+				return &statusError{
+					code: http.StatusRequestEntityTooLarge,
+					err:  fmt.Errorf("rsrv: too many values to infer the histogram range (max=%d): provide an explicit binning range", maxAutoValues),
+				}
+			}
+			vals = append(vals, v)
+			return nil
+		}
+
+		r, err := rtree.NewReader(tree, rvars, rtree.WithRange(0, end))
+		if err != nil {
+			return fmt.Errorf(
+				"could not create reader for tree %q of file %q: %w",
+				tree.Name(), req.URI, err,
+			)
+		}
+		defer r.Close()
+
+		vs := make([][]float64, len(fvs))
+		err = r.Read(func(rctx rtree.RCtx) error {
+			if err := ctx.Err(); err != nil {
+				return &statusError{
+					code: http.StatusServiceUnavailable,
+					err:  fmt.Errorf("rsrv: could not scan tree: %w", err),
+				}
+			}
+
+			// scalar branches are broadcast over the elements of array branches.
+			n := 1
+			array := false
+			for i, fv := range fvs {
+				vs[i] = fv.vals()
+				if fv.scalar() {
+					continue
+				}
+				if !array || len(vs[i]) < n {
+					n = len(vs[i])
+				}
+				array = true
+			}
+
+			for j := 0; j < n; j++ {
+				for i, v := range vs {
+					if fvs[i].scalar() {
+						*slots[i] = v[0]
+						continue
+					}
+					*slots[i] = v[j]
+				}
+				if cut != nil && cut() == 0 {
+					continue
+				}
+				if err := fill(expr()); err != nil {
+					return err
+				}
+			}
+			resp.Entries++
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not complete scan: %w", err)
+		}
+
+		err = r.Close()
+		if err != nil {
+			return fmt.Errorf("could not close reader: %w", err)
+		}
+
+		if auto {
+			min := +math.MaxFloat64
+			max := -math.MaxFloat64
+			for _, v := range vals {
+				if !math.IsNaN(v) && !math.IsInf(v, 0) {
+					max = math.Max(max, v)
+					min = math.Min(min, v)
+				}
+			}
+			if min > max {
+				min, max = 0, 1
+			}
+			min = math.Nextafter(min, min-1)
+			max = math.Nextafter(max, max+1)
+			h1 = hbook.NewH1D(req.Binning.Bins, min, max)
+			for _, v := range vals {
+				h1.Fill(v, 1)
+			}
+		}
+		h1.Ann["name"] = req.Name
+		h1.Ann["title"] = req.Expr
+
+		switch req.Format {
+		case "json":
+			resp.H1 = newH1(h1)
+		case "root":
+			raw, err := srv.marshalROOT(req.Name, h1)
+			if err != nil {
+				return fmt.Errorf("could not create ROOT file: %w", err)
+			}
+			resp.ROOT = base64.StdEncoding.EncodeToString(raw)
+		}
+
+		if req.Plot {
+			req.Options.init()
+
+			pl := hplot.New()
+			pl.Title.Text = req.Expr
+			if req.Options.Title != "" {
+				pl.Title.Text = req.Options.Title
+			}
+			pl.X.Label.Text = req.Options.X
+			pl.Y.Label.Text = req.Options.Y
+
+			h := hplot.NewH1D(h1)
+			h.Infos.Style = hplot.HInfoSummary
+			h.Color = req.Options.Line.Color
+			h.FillColor = req.Options.FillColor
+
+			pl.Add(h, hplot.NewGrid())
+
+			out, err := srv.render(pl, req.Options)
+			if err != nil {
+				return fmt.Errorf("could not render histogram: %w", err)
+			}
+			resp.Data = base64.StdEncoding.EncodeToString(out)
+		}
+
+		resp.URI = req.URI
+		resp.Dir = req.Dir
+		resp.Obj = req.Obj
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func newH1(h *hbook.H1D) *H1 {
+	bins := h.Binning.Bins
+	o := &H1{
+		Name:      h.Name(),
+		Entries:   h.Entries(),
+		Edges:     make([]float64, len(bins)+1),
+		SumW:      make([]float64, len(bins)),
+		SumW2:     make([]float64, len(bins)),
+		Underflow: h.Binning.Outflows[0].SumW(),
+		Overflow:  h.Binning.Outflows[1].SumW(),
+		Mean:      h.XMean(),
+		StdDev:    h.XStdDev(),
+	}
+	if v, ok := h.Ann["title"].(string); ok {
+		o.Title = v
+	}
+	for i, bin := range bins {
+		o.Edges[i] = bin.XMin()
+		o.SumW[i] = bin.SumW()
+		o.SumW2[i] = bin.SumW2()
+	}
+	o.Edges[len(bins)] = h.XMax()
+	return o
+}
+
+// marshalROOT returns the content of a ROOT file holding the histogram.
+func (srv *Server) marshalROOT(name string, h *hbook.H1D) ([]byte, error) {
+	tmp, err := os.MkdirTemp(srv.dir, "hist-tree-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	fname := filepath.Join(tmp, "hist.root")
+	f, err := riofs.Create(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	err = riofs.Dir(f).Put(name, rhist.NewH1DFrom(h))
+	if err != nil {
+		return nil, fmt.Errorf("could not store histogram %q: %w", name, err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(fname)
+}
@@ -0,0 +1,201 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rsrv
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+)
+
+// exprEnv holds the variables of a set of expressions evaluated over
+// the branches of a tree.
+//
+// Expressions use the Go syntax, e.g. "sqrt(px*px + py*py)" or
+// "n > 2 && abs(eta) < 2.5".
+// Identifiers name branches, comparisons and logical operators evaluate
+// to 1 (true) or 0 (false).
+type exprEnv struct {
+	names []string            // names of the variables, in order of appearance
+	vars  map[string]*float64 // current values of the variables
+}
+
+func newExprEnv() *exprEnv {
+	return &exprEnv{vars: make(map[string]*float64)}
+}
+
+func (env *exprEnv) slot(name string) *float64 {
+	v, ok := env.vars[name]
+	if !ok {
+		v = new(float64)
+		env.vars[name] = v
+		env.names = append(env.names, name)
+	}
+	return v
+}
+
+var exprFuncs1 = map[string]func(float64) float64{
+	"abs":   math.Abs,
+	"acos":  math.Acos,
+	"asin":  math.Asin,
+	"atan":  math.Atan,
+	"cbrt":  math.Cbrt,
+	"ceil":  math.Ceil,
+	"cos":   math.Cos,
+	"cosh":  math.Cosh,
+	"exp":   math.Exp,
+	"floor": math.Floor,
+	"log":   math.Log,
+	"log10": math.Log10,
+	"sin":   math.Sin,
+	"sinh":  math.Sinh,
+	"sqrt":  math.Sqrt,
+	"tan":   math.Tan,
+	"tanh":  math.Tanh,
+}
+
+var exprFuncs2 = map[string]func(float64, float64) float64{
+	"atan2": math.Atan2,
+	"hypot": math.Hypot,
+	"max":   math.Max,
+	"min":   math.Min,
+	"mod":   math.Mod,
+	"pow":   math.Pow,
+}
+
+// compile compiles the provided expression into a function evaluating it
+// with the current values of the variables of the environment.
+func (env *exprEnv) compile(src string) (func() float64, error) {
+	node, err := parser.ParseExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("rsrv: could not parse expression %q: %w", src, err)
+	}
+	f, err := env.compileNode(node)
+	if err != nil {
+		return nil, fmt.Errorf("rsrv: could not compile expression %q: %w", src, err)
+	}
+	return f, nil
+}
+
+func (env *exprEnv) compileNode(node ast.Expr) (func() float64, error) {
+	switch node := node.(type) {
+	case *ast.BasicLit:
+		switch node.Kind {
+		case token.INT, token.FLOAT:
+			v, err := strconv.ParseFloat(node.Value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q: %w", node.Value, err)
+			}
+			return func() float64 { return v }, nil
+		default:
+			return nil, fmt.Errorf("invalid literal %s", node.Value)
+		}
+
+	case *ast.Ident:
+		v := env.slot(node.Name)
+		return func() float64 { return *v }, nil
+
+	case *ast.ParenExpr:
+		return env.compileNode(node.X)
+
+	case *ast.UnaryExpr:
+		x, err := env.compileNode(node.X)
+		if err != nil {
+			return nil, err
+		}
+		switch node.Op {
+		case token.ADD:
+			return x, nil
+		case token.SUB:
+			return func() float64 { return -x() }, nil
+		case token.NOT:
+			return func() float64 { return b2f(x() == 0) }, nil
+		default:
+			return nil, fmt.Errorf("invalid unary operator %s", node.Op)
+		}
+
+	case *ast.BinaryExpr:
+		x, err := env.compileNode(node.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := env.compileNode(node.Y)
+		if err != nil {
+			return nil, err
+		}
+		switch node.Op {
+		case token.ADD:
+			return func() float64 { return x() + y() }, nil
+		case token.SUB:
+			return func() float64 { return x() - y() }, nil
+		case token.MUL:
+			return func() float64 { return x() * y() }, nil
+		case token.QUO:
+			return func() float64 { return x() / y() }, nil
+		case token.REM:
+			return func() float64 { return math.Mod(x(), y()) }, nil
+		case token.EQL:
+			return func() float64 { return b2f(x() == y()) }, nil
+		case token.NEQ:
+			return func() float64 { return b2f(x() != y()) }, nil
+		case token.LSS:
+			return func() float64 { return b2f(x() < y()) }, nil
+		case token.LEQ:
+			return func() float64 { return b2f(x() <= y()) }, nil
+		case token.GTR:
+			return func() float64 { return b2f(x() > y()) }, nil
+		case token.GEQ:
+			return func() float64 { return b2f(x() >= y()) }, nil
+		case token.LAND:
+			return func() float64 { return b2f(x() != 0 && y() != 0) }, nil
+		case token.LOR:
+			return func() float64 { return b2f(x() != 0 || y() != 0) }, nil
+		default:
+			return nil, fmt.Errorf("invalid binary operator %s", node.Op)
+		}
+
+	case *ast.CallExpr:
+		id, ok := node.Fun.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("invalid function call")
+		}
+		args := make([]func() float64, len(node.Args))
+		for i, arg := range node.Args {
+			f, err := env.compileNode(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = f
+		}
+		if fct, ok := exprFuncs1[id.Name]; ok {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("invalid number of arguments to %s (got=%d, want=1)", id.Name, len(args))
+			}
+			x := args[0]
+			return func() float64 { return fct(x()) }, nil
+		}
+		if fct, ok := exprFuncs2[id.Name]; ok {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("invalid number of arguments to %s (got=%d, want=2)", id.Name, len(args))
+			}
+			x, y := args[0], args[1]
+			return func() float64 { return fct(x(), y()) }, nil
+		}
+		return nil, fmt.Errorf("unknown function %q", id.Name)
+
+	default:
+		return nil, fmt.Errorf("invalid expression (%T)", node)
+	}
+}
+
+func b2f(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
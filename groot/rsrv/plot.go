@@ -57,6 +57,11 @@ type floats struct {
 	vals func() []float64
 }
 
+// scalar returns whether the leaf holds a single value per entry.
+func (fv floats) scalar() bool {
+	return fv.leaf.LeafCount() == nil && fv.leaf.Len() <= 1
+}
+
 func newFloats(leaf rtree.Leaf) (floats, error) {
 	fv := floats{leaf: leaf}
 	n := 1 // scalar
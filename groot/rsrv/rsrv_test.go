@@ -6,8 +6,10 @@ package rsrv
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"image/color"
 	"io"
 	"log"
@@ -23,6 +25,8 @@ import (
 	"time"
 
 	uuid "github.com/hashicorp/go-uuid"
+	"go-hep.org/x/hep/groot/rhist"
+	"go-hep.org/x/hep/groot/riofs"
 	_ "go-hep.org/x/hep/groot/riofs/plugin/http"
 	_ "go-hep.org/x/hep/groot/riofs/plugin/xrootd"
 	"gonum.org/v1/plot/cmpimg"
@@ -57,6 +61,7 @@ func newTestServer() *httptest.Server {
 	mux.HandleFunc("/plot-h2", srv.PlotH2)
 	mux.HandleFunc("/plot-s2", srv.PlotS2)
 	mux.HandleFunc("/plot-tree", srv.PlotTree)
+	mux.HandleFunc("/hist-tree", srv.HistTree)
 
 	return httptest.NewServer(mux)
 }
@@ -999,6 +1004,429 @@ func testPlotTree(t *testing.T, ts *httptest.Server, req PlotTreeRequest, resp *
 	}
 }
 
+func TestHistTree(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	local, err := filepath.Abs("../testdata/small-flat-tree.root")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	uri := "file://" + local
+	testOpenFile(t, ts, uri, http.StatusOK)
+	defer testCloseFile(t, ts, uri)
+
+	t.Run("json", func(t *testing.T) {
+		var resp HistTreeResponse
+		testHistTree(t, ts, HistTreeRequest{
+			URI:     uri,
+			Obj:     "tree",
+			Expr:    "Float64 + 0.5",
+			Binning: Binning{Bins: 10, Min: 0, Max: 100},
+		}, http.StatusOK, &resp)
+
+		if got, want := resp.Entries, int64(100); got != want {
+			t.Fatalf("invalid number of scanned entries: got=%d, want=%d", got, want)
+		}
+		want := &H1{
+			Name:    "h1",
+			Title:   "Float64 + 0.5",
+			Entries: 100,
+			Edges:   []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
+			SumW:    []float64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10},
+			SumW2:   []float64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10},
+			Mean:    50,
+			StdDev:  resp.H1.StdDev,
+		}
+		if !reflect.DeepEqual(resp.H1, want) {
+			t.Fatalf("invalid histogram:\ngot= %+v\nwant=%+v", resp.H1, want)
+		}
+	})
+
+	t.Run("array-cut", func(t *testing.T) {
+		var resp HistTreeResponse
+		testHistTree(t, ts, HistTreeRequest{
+			URI:     uri,
+			Obj:     "tree",
+			Expr:    "2 * SliceFloat64",
+			Cut:     "Int32 < 10 && SliceFloat64 >= 0",
+			Entries: 50,
+			Binning: Binning{Bins: 5},
+		}, http.StatusOK, &resp)
+
+		if got, want := resp.Entries, int64(50); got != want {
+			t.Fatalf("invalid number of scanned entries: got=%d, want=%d", got, want)
+		}
+		if got, want := resp.H1.Entries, int64(0+1+2+3+4+5+6+7+8+9); got != want {
+			t.Fatalf("invalid number of histogram entries: got=%d, want=%d", got, want)
+		}
+		if got, want := len(resp.H1.SumW), 5; got != want {
+			t.Fatalf("invalid number of bins: got=%d, want=%d", got, want)
+		}
+	})
+
+	t.Run("root+plot", func(t *testing.T) {
+		var resp HistTreeResponse
+		testHistTree(t, ts, HistTreeRequest{
+			URI:     uri,
+			Dir:     "/",
+			Obj:     "tree",
+			Name:    "hpt",
+			Expr:    "sqrt(Float64*Float64 + Float32*Float32)",
+			Binning: Binning{Bins: 20, Min: 0, Max: 200},
+			Format:  "root",
+			Plot:    true,
+		}, http.StatusOK, &resp)
+
+		if resp.H1 != nil {
+			t.Fatalf("unexpected JSON histogram")
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(resp.ROOT)
+		if err != nil {
+			t.Fatalf("could not decode ROOT file: %+v", err)
+		}
+		fname := filepath.Join(t.TempDir(), "hist.root")
+		err = os.WriteFile(fname, raw, 0644)
+		if err != nil {
+			t.Fatalf("could not write ROOT file: %+v", err)
+		}
+		f, err := riofs.Open(fname)
+		if err != nil {
+			t.Fatalf("could not open ROOT file: %+v", err)
+		}
+		defer f.Close()
+
+		obj, err := f.Get("hpt")
+		if err != nil {
+			t.Fatalf("could not retrieve histogram: %+v", err)
+		}
+		h, ok := obj.(rhist.H1)
+		if !ok {
+			t.Fatalf("invalid histogram type %T", obj)
+		}
+		if got, want := h.Entries(), 100.0; got != want {
+			t.Fatalf("invalid number of histogram entries: got=%v, want=%v", got, want)
+		}
+
+		img, err := base64.StdEncoding.DecodeString(resp.Data)
+		if err != nil {
+			t.Fatalf("could not decode plot: %+v", err)
+		}
+		if !bytes.HasPrefix(img, []byte("\x89PNG")) {
+			t.Fatalf("invalid plot format")
+		}
+	})
+
+	t.Run("limit-auto-values", func(t *testing.T) {
+		defer func(n int) {
+			maxAutoValues = n
+		}(maxAutoValues)
+		maxAutoValues = 10
+
+		var resp HistTreeResponse
+		testHistTree(t, ts, HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64"}, http.StatusRequestEntityTooLarge, &resp)
+		testHistTree(t, ts, HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64", Entries: 10}, http.StatusOK, &resp)
+		testHistTree(t, ts, HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64", Binning: Binning{Min: 0, Max: 100}}, http.StatusOK, &resp)
+	})
+
+	for _, tc := range []struct {
+		name   string
+		req    HistTreeRequest
+		limits Limits
+		status int
+	}{
+		{
+			name:   "invalid-expr",
+			req:    HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64 +"},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid-func",
+			req:    HistTreeRequest{URI: uri, Obj: "tree", Expr: "erf(Float64)"},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid-branch",
+			req:    HistTreeRequest{URI: uri, Obj: "tree", Expr: "NoSuchBranch"},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid-format",
+			req:    HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64", Format: "yoda"},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid-tree",
+			req:    HistTreeRequest{URI: uri, Obj: "no-such-tree", Expr: "Float64"},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "limit-bins",
+			req:    HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64", Binning: Binning{Bins: 1000}},
+			limits: Limits{Bins: 100},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "limit-entries",
+			req:    HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64"},
+			limits: Limits{Entries: 10},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "limit-entries-ok",
+			req:    HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64", Entries: 10},
+			limits: Limits{Entries: 10},
+			status: http.StatusOK,
+		},
+		{
+			name:   "limit-duration",
+			req:    HistTreeRequest{URI: uri, Obj: "tree", Expr: "Float64"},
+			limits: Limits{Duration: time.Nanosecond},
+			status: http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(lim Limits) {
+				srv.limits = lim
+			}(srv.limits)
+			srv.limits = tc.limits
+
+			var resp HistTreeResponse
+			testHistTree(t, ts, tc.req, tc.status, &resp)
+		})
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	dir := t.TempDir()
+	srv := New(dir, WithWorkers(1))
+	defer srv.Shutdown()
+
+	release, err := srv.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("could not acquire worker: %+v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = srv.acquire(ctx)
+	var serr *statusError
+	if !errors.As(err, &serr) || serr.code != http.StatusServiceUnavailable {
+		t.Fatalf("invalid error: %+v", err)
+	}
+
+	release()
+	release, err = srv.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("could not acquire worker: %+v", err)
+	}
+	release()
+}
+
+func TestAuth(t *testing.T) {
+	srv := New(t.TempDir(), WithTokens(map[string]string{
+		"token-alice": "alice",
+		"token-bob":   "bob",
+	}))
+	defer srv.Shutdown()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/open-file", srv.OpenFile)
+	mux.HandleFunc("/list-files", srv.ListFiles)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	local, err := filepath.Abs("../testdata/simple.root")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	uri := "file://" + local
+
+	do := func(ep, token string, cookie bool, req, resp interface{}) int {
+		t.Helper()
+		body := new(bytes.Buffer)
+		if req != nil {
+			err := json.NewEncoder(body).Encode(req)
+			if err != nil {
+				t.Fatalf("could not encode request: %v", err)
+			}
+		}
+		hreq, err := http.NewRequest(http.MethodPost, ts.URL+ep, body)
+		if err != nil {
+			t.Fatalf("could not create http request: %v", err)
+		}
+		switch {
+		case token == "":
+		case cookie:
+			hreq.AddCookie(&http.Cookie{Name: tokenCookieName, Value: token})
+		default:
+			hreq.Header.Set("Authorization", "Bearer "+token)
+		}
+		hresp, err := ts.Client().Do(hreq)
+		if err != nil {
+			t.Fatalf("could not post http request: %v", err)
+		}
+		defer hresp.Body.Close()
+		if hresp.StatusCode == http.StatusOK && resp != nil {
+			err = json.NewDecoder(hresp.Body).Decode(resp)
+			if err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+		}
+		return hresp.StatusCode
+	}
+
+	for _, tc := range []struct {
+		token  string
+		cookie bool
+		status int
+	}{
+		{"", false, http.StatusUnauthorized},
+		{"token-eve", false, http.StatusUnauthorized},
+		{"token-eve", true, http.StatusUnauthorized},
+		{"token-alice", false, http.StatusOK},
+		{"token-alice", true, http.StatusOK},
+	} {
+		if got, want := do("/list-files", tc.token, tc.cookie, nil, nil), tc.status; got != want {
+			t.Fatalf("token=%q cookie=%v: invalid status: got=%d, want=%d", tc.token, tc.cookie, got, want)
+		}
+	}
+
+	if got, want := do("/open-file", "token-alice", false, OpenFileRequest{URI: uri}, nil), http.StatusOK; got != want {
+		t.Fatalf("could not open file: got=%d, want=%d", got, want)
+	}
+	if got, want := do("/open-file", "token-alice", true, OpenFileRequest{URI: uri}, nil), http.StatusConflict; got != want {
+		t.Fatalf("invalid status for re-opened file: got=%d, want=%d", got, want)
+	}
+
+	for _, tc := range []struct {
+		token string
+		want  []File
+	}{
+		{"token-alice", []File{{URI: uri, Version: 60600}}},
+		{"token-bob", nil},
+	} {
+		var resp ListResponse
+		if got, want := do("/list-files", tc.token, false, nil, &resp), http.StatusOK; got != want {
+			t.Fatalf("could not list files: got=%d, want=%d", got, want)
+		}
+		if !reflect.DeepEqual(resp.Files, tc.want) {
+			t.Fatalf("%s: invalid files: got=%v, want=%v", tc.token, resp.Files, tc.want)
+		}
+	}
+}
+
+func TestOpenFileQuota(t *testing.T) {
+	srv := New(t.TempDir(), WithMaxRemoteFiles(1))
+	defer srv.Shutdown()
+	setupCookie(srv)
+
+	f, err := riofs.Open("../testdata/simple.root")
+	if err != nil {
+		t.Fatalf("could not open ROOT file: %v", err)
+	}
+
+	var db *DB
+	for _, v := range srv.sessions {
+		db = v
+	}
+	err = db.add("root://example.org/simple.root", f, 1)
+	if err != nil {
+		f.Close()
+		t.Fatalf("could not add remote file: %+v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/open-file", srv.OpenFile)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	body := new(bytes.Buffer)
+	err = json.NewEncoder(body).Encode(OpenFileRequest{
+		// the quota must be enforced before trying to reach the server.
+		URI: "root://unreachable.invalid/simple.root",
+	})
+	if err != nil {
+		t.Fatalf("could not encode request: %v", err)
+	}
+
+	hreq, err := http.NewRequest(http.MethodPost, ts.URL+"/open-file", body)
+	if err != nil {
+		t.Fatalf("could not create http request: %v", err)
+	}
+	srv.addCookies(hreq)
+
+	hresp, err := ts.Client().Do(hreq)
+	if err != nil {
+		t.Fatalf("could not post http request: %v", err)
+	}
+	defer hresp.Body.Close()
+
+	if got, want := hresp.StatusCode, http.StatusTooManyRequests; got != want {
+		t.Fatalf("invalid status code: got=%v, want=%v", got, want)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	srv := New(t.TempDir(), WithIdleTimeout(time.Nanosecond))
+	defer srv.Shutdown()
+	setupCookie(srv)
+
+	f, err := riofs.Open("../testdata/simple.root")
+	if err != nil {
+		t.Fatalf("could not open ROOT file: %v", err)
+	}
+
+	var db *DB
+	for _, v := range srv.sessions {
+		db = v
+	}
+	db.set("simple.root", f)
+	time.Sleep(time.Millisecond)
+
+	srv.gc()
+	if got := db.Files(); len(got) != 0 {
+		t.Fatalf("idle files were not closed: %v", got)
+	}
+}
+
+func testHistTree(t *testing.T, ts *httptest.Server, req HistTreeRequest, status int, resp *HistTreeResponse) {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	err := json.NewEncoder(body).Encode(req)
+	if err != nil {
+		t.Fatalf("could not encode request: %v", err)
+	}
+
+	hreq, err := http.NewRequest(http.MethodPost, ts.URL+"/hist-tree", body)
+	if err != nil {
+		t.Fatalf("could not create http request: %v", err)
+	}
+	srv.addCookies(hreq)
+
+	hresp, err := ts.Client().Do(hreq)
+	if err != nil {
+		t.Fatalf("could not post http request: %v", err)
+	}
+	defer hresp.Body.Close()
+
+	if got, want := hresp.StatusCode, status; got != want {
+		msg, _ := io.ReadAll(hresp.Body)
+		t.Fatalf("invalid status code: got=%v, want=%v (%s)", got, want, msg)
+	}
+
+	if status != http.StatusOK {
+		return
+	}
+
+	err = json.NewDecoder(hresp.Body).Decode(resp)
+	if err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+}
+
 func (srv *Server) addCookies(req *http.Request) {
 	for _, cookie := range srv.cookies {
 		req.AddCookie(cookie)
@@ -5,10 +5,16 @@
 package rsrv
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,7 +22,8 @@ import (
 )
 
 const (
-	cookieName = "GROOT_SRV"
+	cookieName      = "GROOT_SRV"
+	tokenCookieName = "GROOT_SRV_TOKEN"
 )
 
 // Server serves and manages ROOT files.
@@ -28,15 +35,96 @@ type Server struct {
 	sessions map[string]*DB
 
 	dir string
+
+	workers chan struct{} // pool of workers for tree scanning requests
+	limits  Limits
+
+	tokens    map[string]string // authentication tokens, mapped to user names
+	idle      time.Duration     // idle time after which open files are closed
+	maxRemote int               // maximum number of open remote files per user
+}
+
+// Limits describes the resources a single tree scanning request may use.
+// A zero value means no limit.
+type Limits struct {
+	Entries  int64         // maximum number of tree entries scanned
+	Bins     int           // maximum number of histogram bins
+	Duration time.Duration // maximum duration of the request
+}
+
+// maxAutoValues is the maximum number of values buffered by a tree
+// histogramming request to infer the range of its histogram.
+var maxAutoValues = 1 << 20
+
+// Option configures a Server.
+type Option func(srv *Server)
+
+// WithWorkers sets the maximum number of tree scanning requests processed
+// concurrently.
+// Other requests wait for a worker to be available.
+// The default is the number of CPUs.
+func WithWorkers(n int) Option {
+	return func(srv *Server) {
+		if n > 0 {
+			srv.workers = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithLimits sets the resources a single tree scanning request may use.
+func WithLimits(lim Limits) Option {
+	return func(srv *Server) {
+		srv.limits = lim
+	}
+}
+
+// WithTokens enables token-based authentication.
+// tokens maps authentication tokens to user names.
+//
+// Requests must then provide a token, either with an "Authorization: Bearer <token>"
+// header or with a GROOT_SRV_TOKEN cookie, and the files opened by a user
+// are shared by all the requests authenticated as that user.
+// Otherwise, files are associated with the GROOT_SRV session cookie of
+// the client.
+func WithTokens(tokens map[string]string) Option {
+	return func(srv *Server) {
+		srv.tokens = make(map[string]string, len(tokens))
+		for k, v := range tokens {
+			srv.tokens[k] = v
+		}
+	}
+}
+
+// WithIdleTimeout closes the files that have not been accessed for the
+// provided duration.
+// The default is to keep files open until they are closed by their user,
+// or until their session expires.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(srv *Server) {
+		srv.idle = d
+	}
+}
+
+// WithMaxRemoteFiles sets the maximum number of remote files (e.g. opened
+// via HTTP or XRootD) a user or session may have open concurrently.
+// The default is no limit.
+func WithMaxRemoteFiles(n int) Option {
+	return func(srv *Server) {
+		srv.maxRemote = n
+	}
 }
 
 // New creates a new server.
-func New(dir string) *Server {
+func New(dir string, opts ...Option) *Server {
 	srv := &Server{
 		quit:     make(chan int),
 		cookies:  make(map[string]*http.Cookie),
 		sessions: make(map[string]*DB),
 		dir:      dir,
+		workers:  make(chan struct{}, runtime.NumCPU()),
+	}
+	for _, opt := range opts {
+		opt(srv)
 	}
 
 	go srv.run()
@@ -47,8 +135,8 @@ func New(dir string) *Server {
 func (srv *Server) Shutdown() {
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
-	for name := range srv.cookies {
-		srv.sessions[name].Close()
+	for name, db := range srv.sessions {
+		db.Close()
 		delete(srv.sessions, name)
 		delete(srv.cookies, name)
 	}
@@ -56,7 +144,14 @@ func (srv *Server) Shutdown() {
 }
 
 func (srv *Server) run() {
-	ticker := time.NewTicker(5 * time.Minute)
+	freq := 5 * time.Minute
+	if srv.idle > 0 && srv.idle/2 < freq {
+		freq = srv.idle / 2
+		if freq < time.Second {
+			freq = time.Second
+		}
+	}
+	ticker := time.NewTicker(freq)
 	defer ticker.Stop()
 	srv.gc()
 	for {
@@ -81,24 +176,121 @@ func (srv *Server) gc() {
 			cookie.MaxAge = -1
 		}
 	}
+
+	if srv.idle <= 0 {
+		return
+	}
+	since := time.Now().Add(-srv.idle)
+	for name, db := range srv.sessions {
+		for _, uri := range db.evict(since) {
+			log.Printf("closed idle file %q of session %q", uri, name)
+		}
+	}
 }
 
 func (srv *Server) wrap(fn func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := srv.setCookie(w, r)
-		if err != nil {
-			log.Printf("error retrieving cookie: %v\n", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+		if srv.tokens != nil {
+			err := srv.setUser(r)
+			if err != nil {
+				log.Printf("error authenticating request: %v\n", err)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		} else {
+			err := srv.setCookie(w, r)
+			if err != nil {
+				log.Printf("error retrieving cookie: %v\n", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
 		}
 
 		if err := fn(w, r); err != nil {
 			log.Printf("error %q: %v\n", r.URL.Path, err.Error())
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			code := http.StatusInternalServerError
+			var serr *statusError
+			if errors.As(err, &serr) {
+				code = serr.code
+			}
+			http.Error(w, err.Error(), code)
+		}
+	}
+}
+
+var (
+	errNoToken      = errors.New("rsrv: missing authentication token")
+	errInvalidToken = errors.New("rsrv: invalid authentication token")
+	errNoSession    = errors.New("rsrv: no session for user")
+)
+
+// statusError is an error replied with a specific HTTP status code.
+type statusError struct {
+	code int
+	err  error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+// acquire waits for a worker to be available to process a request.
+// The returned function releases the worker.
+func (srv *Server) acquire(ctx context.Context) (func(), error) {
+	select {
+	case srv.workers <- struct{}{}:
+		return func() { <-srv.workers }, nil
+	case <-ctx.Done():
+		return nil, &statusError{
+			code: http.StatusServiceUnavailable,
+			err:  fmt.Errorf("rsrv: no worker available: %w", ctx.Err()),
 		}
 	}
 }
 
+// user returns the name of the user the request is authenticated as.
+func (srv *Server) user(r *http.Request) (string, error) {
+	var token string
+	switch auth := r.Header.Get("Authorization"); {
+	case strings.HasPrefix(auth, "Bearer "):
+		token = strings.TrimPrefix(auth, "Bearer ")
+	default:
+		cookie, err := r.Cookie(tokenCookieName)
+		if err != nil {
+			return "", errNoToken
+		}
+		token = cookie.Value
+	}
+
+	for k, user := range srv.tokens {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(token)) == 1 {
+			return user, nil
+		}
+	}
+	return "", errInvalidToken
+}
+
+// userSession returns the name of the session of a user.
+func userSession(user string) string {
+	return "user-" + url.PathEscape(user)
+}
+
+// setUser authenticates the request and creates the session of the user,
+// if needed.
+func (srv *Server) setUser(r *http.Request) error {
+	user, err := srv.user(r)
+	if err != nil {
+		return err
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	name := userSession(user)
+	if _, ok := srv.sessions[name]; !ok {
+		srv.sessions[name] = NewDB(filepath.Join(srv.dir, name))
+	}
+	return nil
+}
+
 func (srv *Server) setCookie(w http.ResponseWriter, r *http.Request) error {
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
@@ -146,6 +338,20 @@ func (srv *Server) cookie(r *http.Request) (*http.Cookie, error) {
 }
 
 func (srv *Server) db(r *http.Request) (*DB, error) {
+	if srv.tokens != nil {
+		user, err := srv.user(r)
+		if err != nil {
+			return nil, err
+		}
+		srv.mu.RLock()
+		defer srv.mu.RUnlock()
+		db, ok := srv.sessions[userSession(user)]
+		if !ok {
+			return nil, errNoSession
+		}
+		return db, nil
+	}
+
 	srv.mu.RLock()
 	defer srv.mu.RUnlock()
 	cookie, err := srv.cookie(r)
@@ -159,7 +365,7 @@ func (srv *Server) db(r *http.Request) (*DB, error) {
 }
 
 // DB returns the underlying data base of files associated with the user
-// identified by their cookie.
+// identified by their authentication token or by their cookie.
 func (srv *Server) DB(r *http.Request) (*DB, error) {
 	return srv.db(r)
 }
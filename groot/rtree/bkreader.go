@@ -9,6 +9,7 @@ import (
 	"io"
 	"runtime"
 
+	"go-hep.org/x/hep/groot/rbytes"
 	"go-hep.org/x/hep/groot/riofs"
 )
 
@@ -26,6 +27,9 @@ type bkreader struct {
 	cur    *rbasket      // current buffer being served
 	closed chan struct{} // channel is closed when the async reader shuts down
 
+	raws [][]byte        // on-disk records of the baskets being fetched
+	segs []riofs.Segment // segments of the baskets being fetched
+
 	name string
 }
 
@@ -150,15 +154,68 @@ func (bkr *bkreader) findBaskets(beg, end int64) (int, int) {
 func (bkr *bkreader) run(eoff, beg, end int) {
 	defer close(bkr.closed)
 	defer close(bkr.ready)
-	for i, span := range bkr.spans[beg:end] {
-		select {
-		case tok := <-bkr.reuse:
-			tok.err = tok.bkt.inflate(bkr.name, beg+i, span, eoff, bkr.f)
-			bkr.ready <- tok
-		case <-bkr.exit:
-			return
+	for i := beg; i < end; {
+		// fetch the on-disk records of the next n baskets at once,
+		// in a single request for readers supporting vectored reads.
+		j := i + bkr.n
+		if j > end {
+			j = end
+		}
+		raws, err := bkr.fetch(bkr.spans[i:j])
+		for k, span := range bkr.spans[i:j] {
+			select {
+			case tok := <-bkr.reuse:
+				tok.err = err
+				if err == nil {
+					tok.err = tok.bkt.inflate(bkr.name, i+k, span, eoff, bkr.f, raws[k])
+				}
+				bkr.ready <- tok
+			case <-bkr.exit:
+				return
+			}
+		}
+		i = j
+	}
+}
+
+// fetch reads the on-disk records of the provided baskets.
+// Recovered baskets, which are already in memory, have a nil record.
+func (bkr *bkreader) fetch(spans []rspan) ([][]byte, error) {
+	if n := len(spans); len(bkr.raws) < n {
+		bkr.raws = append(bkr.raws, make([][]byte, n-len(bkr.raws))...)
+	}
+	raws := bkr.raws[:len(spans)]
+	bkr.segs = bkr.segs[:0]
+	for i, span := range spans {
+		if span.sz == 0 {
+			raws[i] = nil
+			continue
+		}
+		raws[i] = rbytes.ResizeU8(raws[i], int(span.sz))
+		bkr.segs = append(bkr.segs, riofs.Segment{
+			Offset: span.pos,
+			Data:   raws[i],
+		})
+	}
+	if len(bkr.segs) == 0 {
+		return raws, nil
+	}
+
+	err := bkr.f.ReadV(bkr.segs)
+	if err != nil {
+		return nil, fmt.Errorf("rtree: could not read baskets of branch %q: %w", bkr.name, err)
+	}
+
+	// account for short reads.
+	j := 0
+	for i := range raws {
+		if raws[i] == nil {
+			continue
 		}
+		raws[i] = bkr.segs[j].Data
+		j++
 	}
+	return raws, nil
 }
 
 func (bkr *bkreader) read() (*rbasket, error) {
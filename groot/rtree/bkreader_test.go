@@ -4,7 +4,15 @@
 
 package rtree
 
-import "testing"
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"go-hep.org/x/hep/groot/riofs"
+)
 
 func TestBkReaderFindBaskets(t *testing.T) {
 	for _, tc := range []struct {
@@ -65,3 +73,170 @@ func TestBkReaderFindBaskets(t *testing.T) {
 		})
 	}
 }
+
+// countReaderV is a riofs.ReaderV counting the requests made to a file.
+type countReaderV struct {
+	*os.File
+
+	mu    sync.Mutex
+	reads int // number of ReadAt requests
+	readv int // number of ReadV requests
+	segs  int // number of segments read with ReadV
+}
+
+func (r *countReaderV) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	r.reads++
+	r.mu.Unlock()
+	return r.File.ReadAt(p, off)
+}
+
+func (r *countReaderV) ReadV(segs []riofs.Segment) error {
+	r.mu.Lock()
+	r.readv++
+	r.segs += len(segs)
+	r.mu.Unlock()
+	for i := range segs {
+		seg := &segs[i]
+		n, err := r.File.ReadAt(seg.Data, seg.Offset)
+		if err != nil {
+			return err
+		}
+		seg.Data = seg.Data[:n]
+	}
+	return nil
+}
+
+func (r *countReaderV) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads = 0
+	r.readv = 0
+	r.segs = 0
+}
+
+func TestBkReaderReadV(t *testing.T) {
+	const nevts = 10000
+
+	fname := filepath.Join(t.TempDir(), "readv.root")
+	func() {
+		f, err := riofs.Create(fname)
+		if err != nil {
+			t.Fatalf("could not create file: %+v", err)
+		}
+		defer f.Close()
+
+		var (
+			i32 int32
+			f64 float64
+		)
+		w, err := NewWriter(f, "tree", []WriteVar{
+			{Name: "I32", Value: &i32},
+			{Name: "F64", Value: &f64},
+		}, WithBasketSize(1024))
+		if err != nil {
+			t.Fatalf("could not create tree writer: %+v", err)
+		}
+		defer w.Close()
+
+		for i := 0; i < nevts; i++ {
+			i32 = int32(i)
+			f64 = float64(i)
+			_, err = w.Write()
+			if err != nil {
+				t.Fatalf("could not write event %d: %+v", i, err)
+			}
+		}
+
+		err = w.Close()
+		if err != nil {
+			t.Fatalf("could not close tree writer: %+v", err)
+		}
+
+		err = f.Close()
+		if err != nil {
+			t.Fatalf("could not close file: %+v", err)
+		}
+	}()
+
+	for _, nprefetch := range []int{1, 4, 16} {
+		t.Run("", func(t *testing.T) {
+			fd, err := os.Open(fname)
+			if err != nil {
+				t.Fatalf("could not open file: %+v", err)
+			}
+			r := &countReaderV{File: fd}
+
+			f, err := riofs.NewReader(r)
+			if err != nil {
+				t.Fatalf("could not open ROOT file: %+v", err)
+			}
+			defer f.Close()
+
+			o, err := riofs.Dir(f).Get("tree")
+			if err != nil {
+				t.Fatalf("could not retrieve tree: %+v", err)
+			}
+			tree := o.(Tree)
+
+			for _, b := range tree.Branches() {
+				n := len(asBranch(b).basketSeek)
+				if n < 2*nprefetch {
+					t.Fatalf("branch %q has too few baskets: %d", b.Name(), n)
+				}
+
+				r.reset()
+				bkr := newBkReader(b, nprefetch, 0, tree.Entries())
+				nevts := int64(0)
+				for {
+					bkt, err := bkr.read()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatalf("could not read basket of branch %q: %+v", b.Name(), err)
+					}
+					nevts += bkt.span.end - bkt.span.beg
+				}
+				bkr.close()
+
+				if got, want := nevts, tree.Entries(); got != want {
+					t.Fatalf("invalid number of entries for branch %q: got=%d, want=%d", b.Name(), got, want)
+				}
+
+				r.mu.Lock()
+				if got, want := r.readv, (n+nprefetch-1)/nprefetch; got != want {
+					t.Errorf("branch %q: invalid number of ReadV requests: got=%d, want=%d", b.Name(), got, want)
+				}
+				if got, want := r.segs, n; got != want {
+					t.Errorf("branch %q: invalid number of segments: got=%d, want=%d", b.Name(), got, want)
+				}
+				if got, want := r.reads, 0; got != want {
+					t.Errorf("branch %q: invalid number of ReadAt requests: got=%d, want=%d", b.Name(), got, want)
+				}
+				r.mu.Unlock()
+			}
+
+			// check the content of the baskets.
+			var data struct {
+				I32 int32
+				F64 float64
+			}
+			rr, err := NewReader(tree, ReadVarsFromStruct(&data), WithPrefetchBaskets(nprefetch))
+			if err != nil {
+				t.Fatalf("could not create tree reader: %+v", err)
+			}
+			defer rr.Close()
+
+			err = rr.Read(func(ctx RCtx) error {
+				if data.I32 != int32(ctx.Entry) || data.F64 != float64(ctx.Entry) {
+					t.Fatalf("invalid entry %d: %+v", ctx.Entry, data)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("could not read tree: %+v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-hep.org/x/hep/groot/riofs"
+)
+
+// Follower reads a Tree stored in a ROOT file that is being appended to
+// by another process (e.g. a DAQ writer periodically auto-saving its tree.)
+//
+// Each time the file is polled, Follower re-opens it, loads the highest
+// cycle of the tree and only yields the entries that were not already
+// read during a previous poll.
+//
+// As the file is concurrently written to, failing to open it or to read
+// the tree from it may be transient (e.g. the file was polled while being
+// auto-saved): such errors are retried on the next poll.
+type Follower struct {
+	fname string
+	tname string
+	rvars []ReadVar
+	nrab  int
+	freq  time.Duration
+	retry int // maximum number of consecutive polls failing with a transient error
+
+	next int64 // next entry to read
+}
+
+// FollowOption configures how a ROOT tree should be followed.
+type FollowOption func(f *Follower) error
+
+// WithPollInterval specifies the time interval between two consecutive
+// inspections of the followed file.
+// The default is 1 second.
+func WithPollInterval(d time.Duration) FollowOption {
+	return func(f *Follower) error {
+		if d <= 0 {
+			return fmt.Errorf("rtree: invalid poll interval %v", d)
+		}
+		f.freq = d
+		return nil
+	}
+}
+
+// WithFollowStart specifies the first entry to be yielded by the follower.
+// The default is 0.
+func WithFollowStart(beg int64) FollowOption {
+	return func(f *Follower) error {
+		if beg < 0 {
+			return fmt.Errorf("rtree: invalid follow start entry %d", beg)
+		}
+		f.next = beg
+		return nil
+	}
+}
+
+// WithFollowPrefetchBaskets specifies the number of baskets to read-ahead,
+// per branch, when reading new entries.
+// The default is 2.
+func WithFollowPrefetchBaskets(n int) FollowOption {
+	return func(f *Follower) error {
+		f.nrab = n
+		return nil
+	}
+}
+
+// WithFollowRetries specifies the maximum number of consecutive polls
+// that may fail with a transient error before Follow gives up.
+// The default is 10.
+func WithFollowRetries(n int) FollowOption {
+	return func(f *Follower) error {
+		if n < 0 {
+			return fmt.Errorf("rtree: invalid number of follow retries %d", n)
+		}
+		f.retry = n
+		return nil
+	}
+}
+
+// NewFollower creates a new Follower for the tree named tname, located in
+// the ROOT file fname, reading data into the provided set of read-variables.
+//
+// tname may contain a path to the tree, relative to the top-level
+// directory of the file (e.g. "dir/sub/tree".)
+func NewFollower(fname, tname string, rvars []ReadVar, opts ...FollowOption) (*Follower, error) {
+	f := Follower{
+		fname: fname,
+		tname: tname,
+		rvars: rvars,
+		nrab:  2,
+		freq:  1 * time.Second,
+		retry: 10,
+	}
+
+	for i, opt := range opts {
+		err := opt(&f)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"rtree: could not set follower option %d: %w",
+				i, err,
+			)
+		}
+	}
+
+	return &f, nil
+}
+
+// Next returns the index of the next entry the follower will yield.
+func (f *Follower) Next() int64 { return f.next }
+
+// Poll inspects the followed file once and calls the provided user function
+// for each new entry appended to the tree since the last poll.
+// Poll returns the number of new entries read.
+func (f *Follower) Poll(fct func(ctx RCtx) error) (int64, error) {
+	file, err := riofs.Open(f.fname)
+	if err != nil {
+		return 0, transientError{fmt.Errorf("rtree: could not open followed file: %w", err)}
+	}
+	defer file.Close()
+
+	obj, err := riofs.Dir(file).Get(f.tname)
+	if err != nil {
+		return 0, transientError{fmt.Errorf("rtree: could not retrieve followed tree: %w", err)}
+	}
+
+	tree, ok := obj.(Tree)
+	if !ok {
+		return 0, fmt.Errorf(
+			"rtree: object %q in file %q is not a Tree (type=%T)",
+			f.tname, f.fname, obj,
+		)
+	}
+
+	var (
+		beg = f.next
+		end = tree.Entries()
+	)
+	switch {
+	case end < beg:
+		return 0, fmt.Errorf(
+			"rtree: followed tree %q shrank (entries=%d < next=%d)",
+			f.tname, end, beg,
+		)
+	case end == beg:
+		return 0, nil
+	}
+
+	r, err := NewReader(tree, f.rvars, WithRange(beg, end), WithPrefetchBaskets(f.nrab))
+	if err != nil {
+		return 0, fmt.Errorf("rtree: could not create follower reader: %w", err)
+	}
+	defer r.Close()
+
+	var uerr error // error from the user function
+	err = r.Read(func(ctx RCtx) error {
+		uerr = fct(ctx)
+		if uerr != nil {
+			return uerr
+		}
+		f.next = ctx.Entry + 1
+		return nil
+	})
+	switch {
+	case uerr != nil:
+		return f.next - beg, fmt.Errorf("rtree: could not read followed tree: %w", err)
+	case err != nil:
+		// the baskets of the tree may have been read while being written.
+		// the next poll will resume from the last entry successfully read.
+		return f.next - beg, transientError{fmt.Errorf("rtree: could not read followed tree: %w", err)}
+	}
+
+	err = r.Close()
+	if err != nil {
+		return f.next - beg, fmt.Errorf("rtree: could not close follower reader: %w", err)
+	}
+
+	return f.next - beg, nil
+}
+
+// Follow polls the followed file at regular intervals and calls the
+// provided user function for each new entry, until the provided context
+// is canceled or an error occurs.
+// Transient errors are retried on the next poll, until too many consecutive
+// polls failed (see WithFollowRetries).
+// Follow returns the context error when the context is canceled.
+func (f *Follower) Follow(ctx context.Context, fct func(ctx RCtx) error) error {
+	tick := time.NewTicker(f.freq)
+	defer tick.Stop()
+
+	fails := 0
+	for {
+		_, err := f.Poll(fct)
+		switch {
+		case err == nil:
+			fails = 0
+		case isTransient(err) && fails < f.retry:
+			fails++
+		default:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+	}
+}
+
+// isTransient returns whether the error, returned by a Follower, may be
+// caused by the followed file being concurrently written to, and may thus
+// go away on the next poll.
+func isTransient(err error) bool {
+	var terr transientError
+	return errors.As(err, &terr)
+}
+
+type transientError struct {
+	err error
+}
+
+func (e transientError) Error() string { return e.err.Error() }
+func (e transientError) Unwrap() error { return e.err }
@@ -0,0 +1,246 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rtree
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-hep.org/x/hep/groot/riofs"
+)
+
+func TestFollower(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "daq.root")
+
+	// the writer simulates an external DAQ process, auto-saving its tree
+	// every 3 entries.
+	f, err := riofs.Create(fname)
+	if err != nil {
+		t.Fatalf("could not create root file: %+v", err)
+	}
+	defer f.Close()
+
+	var wv int64
+	w, err := NewWriter(f, "tree", []WriteVar{{Name: "I64", Value: &wv}}, WithAutoSave(3))
+	if err != nil {
+		t.Fatalf("could not create tree writer: %+v", err)
+	}
+	defer w.Close()
+
+	write := func(n int) error {
+		for i := 0; i < n; i++ {
+			_, err := w.Write()
+			if err != nil {
+				return fmt.Errorf("could not write event %d: %w", wv, err)
+			}
+			wv++
+		}
+		return nil
+	}
+
+	var (
+		v    int64
+		got  []int64
+		rvar = []ReadVar{{Name: "I64", Value: &v}}
+	)
+
+	fol, err := NewFollower(fname, "tree", rvar, WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("could not create follower: %+v", err)
+	}
+
+	poll := func(want []int64) {
+		t.Helper()
+		got = got[:0]
+		n, err := fol.Poll(func(ctx RCtx) error {
+			if ctx.Entry != v {
+				t.Fatalf("invalid entry: got=%d, want=%d", ctx.Entry, v)
+			}
+			got = append(got, v)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("could not poll: %+v", err)
+		}
+		if n != int64(len(want)) {
+			t.Fatalf("invalid number of new entries: got=%d, want=%d", n, len(want))
+		}
+		if len(got) == 0 && len(want) == 0 {
+			return
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid entries:\ngot= %v\nwant=%v", got, want)
+		}
+	}
+
+	if err := write(3); err != nil {
+		t.Fatal(err)
+	}
+	poll([]int64{0, 1, 2})
+	poll(nil)
+
+	if err := write(4); err != nil {
+		t.Fatal(err)
+	}
+	// entry 6 has not been auto-saved yet.
+	poll([]int64{3, 4, 5})
+	poll(nil)
+
+	if got, want := fol.Next(), int64(6); got != want {
+		t.Fatalf("invalid next entry: got=%d, want=%d", got, want)
+	}
+
+	// follow the tree while it is being concurrently filled.
+	const nevts = 60
+	errc := make(chan error, 1)
+	go func() {
+		for wv < nevts {
+			err := write(1)
+			if err != nil {
+				errc <- err
+				return
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+		errc <- nil
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	got = got[:0]
+	err = fol.Follow(ctx, func(RCtx) error {
+		got = append(got, v)
+		if v == nevts-1 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("invalid follow error: %+v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("could not fill tree: %+v", err)
+	}
+
+	want := make([]int64, 0, nevts)
+	for i := int64(6); i < nevts; i++ {
+		want = append(want, i)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid entries:\ngot= %v\nwant=%v", got, want)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatalf("could not close tree writer: %+v", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		t.Fatalf("could not close root file: %+v", err)
+	}
+	poll(nil)
+}
+
+func TestFollowerErrors(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "daq.root")
+
+	create := func(n int) {
+		t.Helper()
+		f, err := riofs.Create(fname)
+		if err != nil {
+			t.Fatalf("could not create root file: %+v", err)
+		}
+		defer f.Close()
+
+		var v int64
+		w, err := NewWriter(f, "tree", []WriteVar{{Name: "I64", Value: &v}})
+		if err != nil {
+			t.Fatalf("could not create tree writer: %+v", err)
+		}
+		defer w.Close()
+
+		for i := 0; i < n; i++ {
+			v = int64(i)
+			_, err = w.Write()
+			if err != nil {
+				t.Fatalf("could not write event %d: %+v", i, err)
+			}
+		}
+
+		err = w.Close()
+		if err != nil {
+			t.Fatalf("could not close tree writer: %+v", err)
+		}
+
+		err = f.Close()
+		if err != nil {
+			t.Fatalf("could not close root file: %+v", err)
+		}
+	}
+
+	var (
+		v    int64
+		rvar = []ReadVar{{Name: "I64", Value: &v}}
+	)
+
+	fol, err := NewFollower(fname, "tree", rvar, WithPollInterval(time.Millisecond), WithFollowRetries(2))
+	if err != nil {
+		t.Fatalf("could not create follower: %+v", err)
+	}
+
+	// missing files are retried, until too many polls failed.
+	err = fol.Follow(context.Background(), func(RCtx) error { return nil })
+	if err == nil || !isTransient(err) {
+		t.Fatalf("invalid error for missing file: %+v", err)
+	}
+
+	// errors from the user function are not retried.
+	create(3)
+	errUser := errors.New("user error")
+	err = fol.Follow(context.Background(), func(ctx RCtx) error {
+		if ctx.Entry == 1 {
+			return errUser
+		}
+		return nil
+	})
+	if !errors.Is(err, errUser) || isTransient(err) {
+		t.Fatalf("invalid error for user function: %+v", err)
+	}
+	if got, want := fol.Next(), int64(1); got != want {
+		t.Fatalf("invalid next entry: got=%d, want=%d", got, want)
+	}
+
+	// shrinking trees are not retried.
+	create(0)
+	err = fol.Follow(context.Background(), func(RCtx) error { return nil })
+	if err == nil || isTransient(err) {
+		t.Fatalf("invalid error for shrinking tree: %+v", err)
+	}
+}
+
+func TestFollowerInvalidOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opt  FollowOption
+	}{
+		{"poll-interval", WithPollInterval(0)},
+		{"start", WithFollowStart(-1)},
+		{"retries", WithFollowRetries(-1)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewFollower("file.root", "tree", nil, tc.opt)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
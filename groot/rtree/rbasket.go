@@ -5,8 +5,11 @@
 package rtree
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 
+	"go-hep.org/x/hep/groot/internal/rcompress"
 	"go-hep.org/x/hep/groot/rbytes"
 	"go-hep.org/x/hep/groot/riofs"
 )
@@ -34,7 +37,10 @@ func (rbk *rbasket) loadRLeaf(entry int64, leaf rleaf) error {
 	return leaf.readFromBuffer(rbk.bk.rbuf)
 }
 
-func (rbk *rbasket) inflate(name string, id int, span rspan, eoff int, f *riofs.File) error {
+// inflate loads the basket described by span.
+// raw holds the on-disk record of the basket, as fetched by the bkreader,
+// and is ignored for recovered baskets.
+func (rbk *rbasket) inflate(name string, id int, span rspan, eoff int, f *riofs.File, raw []byte) error {
 	var (
 		bufsz = span.sz
 		seek  = span.pos
@@ -68,25 +74,39 @@ func (rbk *rbasket) inflate(name string, id int, span rspan, eoff int, f *riofs.
 		rbk.bk.rbuf = rbk.bk.rbuf.Reset(rbk.buf, nil, keylen, sictx)
 
 	default:
-		rbk.buf = rbytes.ResizeU8(rbk.buf, int(bufsz))
-		_, err = f.ReadAt(rbk.buf, seek)
-		if err != nil {
-			return fmt.Errorf("rtree: could not read basket buffer from file: %w", err)
+		if len(raw) != int(bufsz) {
+			return fmt.Errorf(
+				"rtree: could not read basket buffer from file: short read at %d (got=%d, want=%d): %w",
+				seek, len(raw), bufsz, io.ErrUnexpectedEOF,
+			)
 		}
 
-		rbk.bk.rbuf = rbk.bk.rbuf.Reset(rbk.buf, nil, 0, sictx)
+		rbk.bk.rbuf = rbk.bk.rbuf.Reset(raw, nil, 0, sictx)
 		err = rbk.bk.UnmarshalROOT(rbk.bk.rbuf)
 		if err != nil {
 			return fmt.Errorf("rtree: could not unmarshal basket buffer from file: %w", err)
 		}
 		rbk.bk.key.SetFile(f)
 
-		rbk.buf = rbytes.ResizeU8(rbk.buf, int(rbk.bk.key.ObjLen()))
-		_, err = rbk.bk.key.Load(rbk.buf)
-		if err != nil {
-			return err
+		var (
+			key     = &rbk.bk.key
+			nbytes  = int(key.Nbytes())
+			payload = int(key.KeyLen())
+		)
+		if nbytes > len(raw) || payload > nbytes {
+			return fmt.Errorf("rtree: invalid basket key (nbytes=%d, keylen=%d, record=%d)", nbytes, payload, len(raw))
+		}
+		rbk.buf = rbytes.ResizeU8(rbk.buf, int(key.ObjLen()))
+		switch {
+		case key.ObjLen() != key.Nbytes()-key.KeyLen():
+			err = rcompress.Decompress(rbk.buf, bytes.NewReader(raw[payload:nbytes]))
+			if err != nil {
+				return fmt.Errorf("rtree: could not decompress basket payload: %w", err)
+			}
+		default:
+			copy(rbk.buf, raw[payload:nbytes])
 		}
-		keylen = uint32(rbk.bk.key.KeyLen())
+		keylen = uint32(key.KeyLen())
 		rbk.bk.rbuf = rbk.bk.rbuf.Reset(rbk.buf, nil, keylen, sictx)
 
 		if eoff > 0 {
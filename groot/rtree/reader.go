@@ -41,6 +41,9 @@ func WithRange(beg, end int64) ReadOption {
 // WithPrefetchBaskets specifies the number of baskets to read-ahead, per branch.
 // The default is 2.
 // The number of prefetch baskets is cap'ed by the number of baskets, per branch.
+//
+// The prefetch baskets of a branch are fetched together, in a single request
+// when the file supports vectored reads (see riofs.ReaderV).
 func WithPrefetchBaskets(n int) ReadOption {
 	return func(r *Reader) error {
 		r.nrab = n
@@ -38,6 +38,7 @@ type wopt struct {
 	bufsize  int32  // buffer size for branches
 	splitlvl int32  // maximum split-level for branches
 	compress int32  // compression algorithm name and compression level
+	autosave int64  // number of entries between two auto-saves of the tree
 }
 
 // WithLZ4 configures a ROOT tree to use LZ4 as a compression mechanism.
@@ -100,6 +101,21 @@ func WithSplitLevel(lvl int) WriteOption {
 	}
 }
 
+// WithAutoSave configures a ROOT tree to be saved, together with the
+// metadata of its file, every n entries.
+// Readers (e.g. a Follower) can then access the entries written so far,
+// while the tree is still being filled.
+// Each auto-save creates a new cycle of the tree in its directory.
+func WithAutoSave(n int) WriteOption {
+	return func(opt *wopt) error {
+		if n <= 0 {
+			return fmt.Errorf("rtree: invalid auto-save number of entries %d", n)
+		}
+		opt.autosave = int64(n)
+		return nil
+	}
+}
+
 type wtree struct {
 	ttree
 	wvars []WriteVar
@@ -150,6 +166,9 @@ func NewWriter(dir riofs.Directory, name string, vars []WriteVar, opts ...WriteO
 	}
 
 	w.ttree.named.SetTitle(cfg.title)
+	if cfg.autosave > 0 {
+		w.ttree.autoSave = cfg.autosave
+	}
 
 	for _, v := range vars {
 		b, err := newBranchFromWVar(w, v.Name, v, nil, 0, cfg)
@@ -202,9 +221,48 @@ func (w *wtree) Write() (int, error) {
 	w.ttree.zipBytes += int64(zip)
 	// FIXME(sbinet): autoflush
 
+	if w.ttree.autoSave > 0 && w.ttree.entries%w.ttree.autoSave == 0 {
+		err := w.save()
+		if err != nil {
+			return tot, fmt.Errorf("rtree: could not auto-save tree %q: %w", w.Name(), err)
+		}
+	}
+
 	return tot, nil
 }
 
+// save flushes the baskets of the tree, writes a new cycle of the tree
+// and syncs its file, so the entries written so far can be read back.
+func (w *wtree) save() error {
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+
+	var renew func(bs []Branch)
+	renew = func(bs []Branch) {
+		for _, b := range bs {
+			if b, ok := b.(interface{ createNewBasket() }); ok {
+				b.createNewBasket()
+			}
+			renew(b.Branches())
+		}
+	}
+	renew(w.ttree.branches)
+
+	err = w.ttree.dir.Put(w.Name(), w)
+	if err != nil {
+		return fmt.Errorf("could not save tree: %w", err)
+	}
+
+	err = w.ttree.f.Sync()
+	if err != nil {
+		return fmt.Errorf("could not sync file: %w", err)
+	}
+
+	return nil
+}
+
 // Flush commits the current contents of the tree to stable storage.
 func (w *wtree) Flush() error {
 	for _, b := range w.ttree.branches {
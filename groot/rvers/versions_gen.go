@@ -70,6 +70,7 @@ const (
 	GraphErrors              = 3  // ROOT version for TGraphErrors
 	GraphAsymmErrors         = 3  // ROOT version for TGraphAsymmErrors
 	GraphMultiErrors         = 1  // ROOT version for TGraphMultiErrors
+	Graph2D                  = 1  // ROOT version for TGraph2D
 	H1                       = 8  // ROOT version for TH1
 	H1C                      = 3  // ROOT version for TH1C
 	H1D                      = 3  // ROOT version for TH1D
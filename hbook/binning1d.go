@@ -36,6 +36,8 @@ type Binning1D struct {
 	Dist     Dist1D
 	Outflows [2]Dist1D
 	XRange   Range
+
+	kahan *kbinning1D // compensation terms, when Kahan summation is enabled.
 }
 
 func newBinning1D(n int, xmin, xmax float64) Binning1D {
@@ -116,6 +118,7 @@ func (bng *Binning1D) clone() Binning1D {
 			bng.Outflows[1].clone(),
 		},
 		XRange: bng.XRange.clone(),
+		kahan:  bng.kahan.clone(),
 	}
 
 	for i, bin := range bng.Bins {
@@ -133,6 +136,14 @@ func (bng *Binning1D) effEntries() float64 {
 	return bng.Dist.EffEntries()
 }
 
+// xMoment returns the k-th standardized central moment in X.
+func (bng *Binning1D) xMoment(k float64) float64 {
+	return stdMoment(k, len(bng.Bins), func(i int) (float64, *Dist1D) {
+		bin := &bng.Bins[i]
+		return bin.XMid(), &bin.Dist
+	})
+}
+
 // xMin returns the low edge of the X-axis
 func (bng *Binning1D) xMin() float64 {
 	return bng.XRange.Min
@@ -144,6 +155,10 @@ func (bng *Binning1D) xMax() float64 {
 }
 
 func (bng *Binning1D) fill(x, w float64) {
+	if bng.kahan != nil {
+		bng.kfill(x, w)
+		return
+	}
 	idx := bng.coordToIndex(x)
 	bng.Dist.fill(x, w)
 	if idx < 0 {
@@ -157,6 +172,21 @@ func (bng *Binning1D) fill(x, w float64) {
 	bng.Bins[idx].fill(x, w)
 }
 
+// kfill fills the binning using Kahan-compensated summation.
+func (bng *Binning1D) kfill(x, w float64) {
+	idx := bng.coordToIndex(x)
+	bng.kahan.dist.fill(&bng.Dist, x, w)
+	if idx < 0 {
+		bng.kahan.outflows[-idx-1].fill(&bng.Outflows[-idx-1], x, w)
+		return
+	}
+	if idx == len(bng.Bins) {
+		// gap bin.
+		return
+	}
+	bng.kahan.bins[idx].fill(&bng.Bins[idx].Dist, x, w)
+}
+
 // coordToIndex returns the bin index corresponding to the coordinate x.
 func (bng *Binning1D) coordToIndex(x float64) int {
 	switch {
@@ -176,6 +206,9 @@ func (bng *Binning1D) scaleW(f float64) {
 		bin := &bng.Bins[i]
 		bin.scaleW(f)
 	}
+	if bng.kahan != nil {
+		bng.kahan.scaleW(f)
+	}
 }
 
 func (bng *Binning1D) Underflow() *Dist1D {
@@ -28,6 +28,8 @@ type Binning2D struct {
 	Ny       int
 	XEdges   []Bin1D
 	YEdges   []Bin1D
+
+	kahan *kbinning2D // compensation terms, when Kahan summation is enabled.
 }
 
 func newBinning2D(nx int, xlow, xhigh float64, ny int, ylow, yhigh float64) Binning2D {
@@ -141,6 +143,22 @@ func (bng *Binning2D) effEntries() float64 {
 	return bng.Dist.EffEntries()
 }
 
+// xMoment returns the k-th standardized central moment in X.
+func (bng *Binning2D) xMoment(k float64) float64 {
+	return stdMoment(k, len(bng.Bins), func(i int) (float64, *Dist1D) {
+		bin := &bng.Bins[i]
+		return bin.XMid(), &bin.Dist.X
+	})
+}
+
+// yMoment returns the k-th standardized central moment in Y.
+func (bng *Binning2D) yMoment(k float64) float64 {
+	return stdMoment(k, len(bng.Bins), func(i int) (float64, *Dist1D) {
+		bin := &bng.Bins[i]
+		return bin.YMid(), &bin.Dist.Y
+	})
+}
+
 // xMin returns the low edge of the X-axis
 func (bng *Binning2D) xMin() float64 {
 	return bng.XRange.Min
@@ -162,6 +180,10 @@ func (bng *Binning2D) yMax() float64 {
 }
 
 func (bng *Binning2D) fill(x, y, w float64) {
+	if bng.kahan != nil {
+		bng.kfill(x, y, w)
+		return
+	}
 	idx := bng.coordToIndex(x, y)
 	bng.Dist.fill(x, y, w)
 	if idx == len(bng.Bins) {
@@ -175,6 +197,21 @@ func (bng *Binning2D) fill(x, y, w float64) {
 	bng.Bins[idx].fill(x, y, w)
 }
 
+// kfill fills the binning using Kahan-compensated summation.
+func (bng *Binning2D) kfill(x, y, w float64) {
+	idx := bng.coordToIndex(x, y)
+	bng.kahan.dist.fill(&bng.Dist, x, y, w)
+	if idx == len(bng.Bins) {
+		// GAP bin
+		return
+	}
+	if idx < 0 {
+		bng.kahan.outflows[-idx-1].fill(&bng.Outflows[-idx-1], x, y, w)
+		return
+	}
+	bng.kahan.bins[idx].fill(&bng.Bins[idx].Dist, x, y, w)
+}
+
 func (bng *Binning2D) coordToIndex(x, y float64) int {
 	ix := Bin1Ds(bng.XEdges).IndexOf(x)
 	iy := Bin1Ds(bng.YEdges).IndexOf(y)
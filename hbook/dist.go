@@ -311,3 +311,48 @@ func (d *Dist2D) scaleW(f float64) {
 	d.Y.scaleW(f)
 	d.Stats.SumWXY *= f
 }
+
+// stdMoment returns the k-th standardized central moment of a binned
+// distribution with n bins.
+// The bin function returns the center and the x-moments of the i-th bin.
+//
+// The mean and the (population) standard deviation are computed from the
+// stored moments of the in-range bins, while higher order moments are
+// computed from the bin centers, as ROOT's TH1::GetSkewness and
+// TH1::GetKurtosis do.
+func stdMoment(k float64, n int, bin func(i int) (float64, *Dist1D)) float64 {
+	var sumw, sumwx, sumwx2 float64
+	for i := 0; i < n; i++ {
+		_, d := bin(i)
+		sumw += d.SumW()
+		sumwx += d.SumWX()
+		sumwx2 += d.SumWX2()
+	}
+	if sumw == 0 {
+		return 0
+	}
+
+	mean := sumwx / sumw
+	sig := math.Sqrt(math.Abs(sumwx2/sumw - mean*mean))
+	if sig == 0 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i < n; i++ {
+		x, d := bin(i)
+		sum += d.SumW() * math.Pow(x-mean, k)
+	}
+	return sum / sumw / math.Pow(sig, k)
+}
+
+// excessKurtosis returns the excess kurtosis from the 4-th standardized
+// moment m4, as returned by stdMoment.
+// Empty or zero-width distributions, for which stdMoment returns 0, have a
+// null excess kurtosis, consistently with their skewness.
+func excessKurtosis(m4 float64) float64 {
+	if m4 == 0 {
+		return 0
+	}
+	return m4 - 3
+}
@@ -0,0 +1,230 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hbook
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// BinomialInterval describes how the confidence interval of a binomial
+// efficiency is computed.
+type BinomialInterval int
+
+const (
+	// IntervalClopperPearson computes the exact Clopper-Pearson interval.
+	IntervalClopperPearson BinomialInterval = iota
+	// IntervalNormal computes the interval from the normal approximation.
+	IntervalNormal
+	// IntervalWilson computes the Wilson score interval.
+	IntervalWilson
+	// IntervalAgrestiCoull computes the Agresti-Coull interval.
+	IntervalAgrestiCoull
+	// IntervalBayes computes the shortest Bayesian interval, using a
+	// uniform Beta(1,1) prior.
+	IntervalBayes
+)
+
+func (bi BinomialInterval) String() string {
+	switch bi {
+	case IntervalClopperPearson:
+		return "clopper-pearson"
+	case IntervalNormal:
+		return "normal"
+	case IntervalWilson:
+		return "wilson"
+	case IntervalAgrestiCoull:
+		return "agresti-coull"
+	case IntervalBayes:
+		return "bayes"
+	}
+	return fmt.Sprintf("BinomialInterval(%d)", int(bi))
+}
+
+// defaultConfLevel is the default confidence level of binomial intervals (1 sigma).
+const defaultConfLevel = 0.682689492137
+
+// DivInterval configures DivideBinomial to compute the asymmetric errors
+// with the provided interval kind.
+func DivInterval(kind BinomialInterval) DivOptions {
+	return func(c *divConfig) {
+		c.interval = kind
+	}
+}
+
+// DivConfLevel configures DivideBinomial to compute the asymmetric errors
+// with the provided confidence level.
+func DivConfLevel(cl float64) DivOptions {
+	return func(c *divConfig) {
+		c.cl = cl
+	}
+}
+
+// DivideBinomial divides 2 1D-histograms, where the numerator is a subset
+// of the denominator (e.g. events passing a trigger over all events), and
+// returns a 2D scatter of the efficiencies with asymmetric binomial errors.
+//
+// The efficiency in each bin is k/n, with k (resp. n) the sum of weights
+// of the numerator (resp. denominator) bin: DivideBinomial is thus meant to
+// be used with unweighted histograms.
+//
+// By default, errors are Clopper-Pearson intervals at the 68.27% confidence
+// level, as for ROOT's TGraphAsymmErrors::Divide.
+// This can be configured with the DivInterval and DivConfLevel options.
+// Bins with an empty denominator are handled as NaNs (see DivIgnoreNaNs and
+// DivReplaceNaNs).
+//
+// DivideBinomial returns an error if the binnings of the 1D histograms are
+// not compatible or if a numerator bin is larger than its denominator bin.
+func DivideBinomial(num, den *H1D, opts ...DivOptions) (*S2D, error) {
+	cfg := newDivConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if !(cfg.cl > 0 && cfg.cl < 1) {
+		return nil, fmt.Errorf("hbook: invalid confidence level %v", cfg.cl)
+	}
+
+	bins1 := num.Binning.Bins
+	bins2 := den.Binning.Bins
+	if len(bins1) != len(bins2) {
+		return nil, fmt.Errorf("hbook: x binnings are not equivalent in %v / %v", num.Name(), den.Name())
+	}
+
+	var s2d S2D
+	for i := range bins1 {
+		b1 := bins1[i]
+		b2 := bins2[i]
+
+		if !fuzzyEq(b1.XMin(), b2.XMin()) || !fuzzyEq(b1.XMax(), b2.XMax()) {
+			return nil, fmt.Errorf("hbook: x binnings are not equivalent in %v / %v", num.Name(), den.Name())
+		}
+
+		x := b1.XMid()
+		exm := x - b1.XMin()
+		exp := b1.XMax() - x
+
+		k := b1.SumW()
+		n := b2.SumW()
+		if k < 0 || k > n {
+			return nil, fmt.Errorf("hbook: invalid numerator in bin %d (num=%v, den=%v)", i, k, n)
+		}
+
+		if n == 0 {
+			if cfg.ignoreNaN {
+				continue
+			}
+			s2d.Fill(Point2D{X: x, Y: cfg.replaceNaN, ErrX: Range{Min: exm, Max: exp}})
+			continue
+		}
+
+		y := k / n
+		lo, hi, err := binomialInterval(cfg.interval, k, n, cfg.cl)
+		if err != nil {
+			return nil, err
+		}
+
+		s2d.Fill(Point2D{X: x, Y: y, ErrX: Range{Min: exm, Max: exp}, ErrY: Range{Min: y - lo, Max: hi - y}})
+	}
+	return &s2d, nil
+}
+
+// BayesDivide divides 2 1D-histograms, where the numerator is a subset of
+// the denominator, and returns a 2D scatter of the efficiencies with errors
+// computed from the shortest Bayesian interval, using a uniform prior.
+//
+// The efficiency in each bin is the mode of the posterior, k/n.
+// BayesDivide is equivalent to:
+//  DivideBinomial(num, den, append(opts, DivInterval(IntervalBayes))...)
+// and mirrors ROOT's TGraphAsymmErrors::BayesDivide.
+func BayesDivide(num, den *H1D, opts ...DivOptions) (*S2D, error) {
+	opts = append(opts[:len(opts):len(opts)], DivInterval(IntervalBayes))
+	return DivideBinomial(num, den, opts...)
+}
+
+// binomialInterval returns the lower and upper bounds of the confidence
+// interval at level cl for the efficiency of k successes out of n trials.
+func binomialInterval(kind BinomialInterval, k, n, cl float64) (lo, hi float64, err error) {
+	switch kind {
+	case IntervalClopperPearson:
+		alpha := 0.5 * (1 - cl)
+		lo, hi = 0, 1
+		if k > 0 {
+			lo = distuv.Beta{Alpha: k, Beta: n - k + 1}.Quantile(alpha)
+		}
+		if k < n {
+			hi = distuv.Beta{Alpha: k + 1, Beta: n - k}.Quantile(1 - alpha)
+		}
+		return lo, hi, nil
+
+	case IntervalNormal:
+		kappa := distuv.UnitNormal.Quantile(1 - 0.5*(1-cl))
+		p := k / n
+		delta := kappa * math.Sqrt(p*(1-p)/n)
+		return math.Max(0, p-delta), math.Min(1, p+delta), nil
+
+	case IntervalWilson:
+		kappa := distuv.UnitNormal.Quantile(1 - 0.5*(1-cl))
+		k2 := kappa * kappa
+		p := k / n
+		mode := (k + 0.5*k2) / (n + k2)
+		delta := kappa / (n + k2) * math.Sqrt(n*p*(1-p)+0.25*k2)
+		return math.Max(0, mode-delta), math.Min(1, mode+delta), nil
+
+	case IntervalAgrestiCoull:
+		kappa := distuv.UnitNormal.Quantile(1 - 0.5*(1-cl))
+		k2 := kappa * kappa
+		mode := (k + 0.5*k2) / (n + k2)
+		delta := kappa * math.Sqrt(mode*(1-mode)/(n+k2))
+		return math.Max(0, mode-delta), math.Min(1, mode+delta), nil
+
+	case IntervalBayes:
+		lo, hi = betaShortestInterval(distuv.Beta{Alpha: k + 1, Beta: n - k + 1}, cl)
+		return lo, hi, nil
+	}
+
+	return 0, 0, fmt.Errorf("hbook: invalid binomial interval kind %v", kind)
+}
+
+// betaShortestInterval returns the shortest interval holding a fraction cl
+// of the provided (unimodal) Beta distribution.
+func betaShortestInterval(beta distuv.Beta, cl float64) (lo, hi float64) {
+	switch {
+	case beta.Alpha == 1 && beta.Beta == 1:
+		// flat distribution: any interval works, pick the central one.
+		return 0.5 * (1 - cl), 0.5 * (1 + cl)
+	case beta.Alpha <= 1:
+		return 0, beta.Quantile(cl)
+	case beta.Beta <= 1:
+		return beta.Quantile(1 - cl), 1
+	}
+
+	// golden-section search of the lower tail probability that minimizes
+	// the width of the interval.
+	var (
+		width = func(p float64) float64 {
+			return beta.Quantile(p+cl) - beta.Quantile(p)
+		}
+		gr = 0.5 * (math.Sqrt(5) - 1)
+		a  = 0.0
+		b  = 1 - cl
+		c  = b - gr*(b-a)
+		d  = a + gr*(b-a)
+	)
+	for i := 0; i < 200 && b-a > 1e-12; i++ {
+		if width(c) < width(d) {
+			b = d
+		} else {
+			a = c
+		}
+		c = b - gr*(b-a)
+		d = a + gr*(b-a)
+	}
+	p := 0.5 * (a + b)
+	return beta.Quantile(p), beta.Quantile(p + cl)
+}
@@ -0,0 +1,179 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hbook
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats/scalar"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestBinomialInterval(t *testing.T) {
+	const cl = defaultConfLevel
+	alpha := 0.5 * (1 - cl)
+
+	for _, tc := range []struct {
+		k, n float64
+	}{
+		{0, 10},
+		{3, 10},
+		{10, 10},
+		{1, 1},
+		{42, 100},
+		{999, 1000},
+	} {
+		p := tc.k / tc.n
+		for _, kind := range []BinomialInterval{
+			IntervalClopperPearson,
+			IntervalNormal,
+			IntervalWilson,
+			IntervalAgrestiCoull,
+			IntervalBayes,
+		} {
+			lo, hi, err := binomialInterval(kind, tc.k, tc.n, cl)
+			if err != nil {
+				t.Fatalf("%v: k=%v, n=%v: %+v", kind, tc.k, tc.n, err)
+			}
+			if !(0 <= lo && lo <= p && p <= hi && hi <= 1) {
+				t.Errorf("%v: k=%v, n=%v: invalid interval [%v, %v]", kind, tc.k, tc.n, lo, hi)
+			}
+		}
+
+		// Clopper-Pearson bounds are such that the binomial tails are alpha.
+		lo, hi, _ := binomialInterval(IntervalClopperPearson, tc.k, tc.n, cl)
+		if tc.k > 0 {
+			got := 1 - distuv.Binomial{N: tc.n, P: lo}.CDF(tc.k-1)
+			if !scalar.EqualWithinAbs(got, alpha, 1e-8) {
+				t.Errorf("clopper-pearson: k=%v, n=%v: invalid lower tail: got=%v, want=%v", tc.k, tc.n, got, alpha)
+			}
+		}
+		if tc.k < tc.n {
+			got := distuv.Binomial{N: tc.n, P: hi}.CDF(tc.k)
+			if !scalar.EqualWithinAbs(got, alpha, 1e-8) {
+				t.Errorf("clopper-pearson: k=%v, n=%v: invalid upper tail: got=%v, want=%v", tc.k, tc.n, got, alpha)
+			}
+		}
+
+		// the shortest Bayesian interval holds cl of the posterior and,
+		// away from the boundaries, has the same density on both ends.
+		beta := distuv.Beta{Alpha: tc.k + 1, Beta: tc.n - tc.k + 1}
+		lo, hi, _ = binomialInterval(IntervalBayes, tc.k, tc.n, cl)
+		if got := beta.CDF(hi) - beta.CDF(lo); !scalar.EqualWithinAbs(got, cl, 1e-8) {
+			t.Errorf("bayes: k=%v, n=%v: invalid coverage: got=%v, want=%v", tc.k, tc.n, got, cl)
+		}
+		if lo > 0 && hi < 1 {
+			if plo, phi := beta.Prob(lo), beta.Prob(hi); !scalar.EqualWithinAbsOrRel(plo, phi, 1e-4, 1e-4) {
+				t.Errorf("bayes: k=%v, n=%v: interval is not the shortest: p(lo)=%v, p(hi)=%v", tc.k, tc.n, plo, phi)
+			}
+		}
+	}
+
+	lo, hi, _ := binomialInterval(IntervalWilson, 3, 10, cl)
+	if !scalar.EqualWithinAbs(lo, 0.178821, 1e-6) || !scalar.EqualWithinAbs(hi, 0.457543, 1e-6) {
+		t.Errorf("wilson: invalid interval [%v, %v]", lo, hi)
+	}
+
+	lo, hi, _ = binomialInterval(IntervalNormal, 3, 10, cl)
+	if delta := math.Sqrt(0.3 * 0.7 / 10); !scalar.EqualWithinAbs(lo, 0.3-delta, 1e-8) || !scalar.EqualWithinAbs(hi, 0.3+delta, 1e-8) {
+		t.Errorf("normal: invalid interval [%v, %v]", lo, hi)
+	}
+
+	_, _, err := binomialInterval(BinomialInterval(42), 3, 10, cl)
+	if err == nil {
+		t.Errorf("expected an error for an invalid interval kind")
+	}
+}
+
+func TestDivideBinomial(t *testing.T) {
+	var (
+		num = NewH1D(4, 0, 4)
+		den = NewH1D(4, 0, 4)
+	)
+	for i, v := range []struct{ k, n int }{{0, 10}, {3, 10}, {5, 5}, {0, 0}} {
+		x := float64(i) + 0.5
+		for j := 0; j < v.n; j++ {
+			den.Fill(x, 1)
+			if j < v.k {
+				num.Fill(x, 1)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		fct  func() (*S2D, error)
+		kind BinomialInterval
+		n    int
+	}{
+		{
+			name: "cp",
+			fct:  func() (*S2D, error) { return DivideBinomial(num, den) },
+			kind: IntervalClopperPearson,
+			n:    4,
+		},
+		{
+			name: "wilson-ignore-nans",
+			fct: func() (*S2D, error) {
+				return DivideBinomial(num, den, DivInterval(IntervalWilson), DivIgnoreNaNs())
+			},
+			kind: IntervalWilson,
+			n:    3,
+		},
+		{
+			name: "bayes",
+			fct:  func() (*S2D, error) { return BayesDivide(num, den, DivReplaceNaNs(-1)) },
+			kind: IntervalBayes,
+			n:    4,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := tc.fct()
+			if err != nil {
+				t.Fatalf("could not divide: %+v", err)
+			}
+			if got, want := s.Len(), tc.n; got != want {
+				t.Fatalf("invalid number of points: got=%d, want=%d", got, want)
+			}
+			for i, want := range []float64{0, 0.3, 1} {
+				pt := s.Point(i)
+				if pt.Y != want {
+					t.Fatalf("invalid efficiency in bin %d: got=%v, want=%v", i, pt.Y, want)
+				}
+				if got, want := pt.ErrX, (Range{Min: 0.5, Max: 0.5}); got != want {
+					t.Fatalf("invalid x-error in bin %d: got=%v, want=%v", i, got, want)
+				}
+				lo, hi, _ := binomialInterval(tc.kind, num.Binning.Bins[i].SumW(), den.Binning.Bins[i].SumW(), defaultConfLevel)
+				if got, want := pt.ErrY, (Range{Min: pt.Y - lo, Max: hi - pt.Y}); got != want {
+					t.Fatalf("invalid y-error in bin %d: got=%v, want=%v", i, got, want)
+				}
+			}
+			if tc.n == 4 {
+				y := s.Point(3).Y
+				switch tc.name {
+				case "bayes":
+					if y != -1 {
+						t.Fatalf("invalid replaced NaN: got=%v", y)
+					}
+				default:
+					if !math.IsNaN(y) {
+						t.Fatalf("expected a NaN: got=%v", y)
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := DivideBinomial(den, num); err == nil {
+		t.Fatalf("expected an error for numerator > denominator")
+	}
+	if _, err := DivideBinomial(num, den, DivConfLevel(1)); err == nil {
+		t.Fatalf("expected an error for an invalid confidence level")
+	}
+	if _, err := DivideBinomial(num, NewH1D(4, 0, 8)); err == nil {
+		t.Fatalf("expected an error for incompatible binnings")
+	}
+}
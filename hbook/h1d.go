@@ -146,11 +146,47 @@ func (h *H1D) XRMS() float64 {
 	return h.Binning.Dist.rms()
 }
 
+// XSkewness returns the skewness in X.
+// Overflows are not included in the computation.
+func (h *H1D) XSkewness() float64 {
+	return h.Binning.xMoment(3)
+}
+
+// XKurtosis returns the excess kurtosis in X.
+// Overflows are not included in the computation.
+func (h *H1D) XKurtosis() float64 {
+	return excessKurtosis(h.Binning.xMoment(4))
+}
+
 // Fill fills this histogram with x and weight w.
 func (h *H1D) Fill(x, w float64) {
 	h.Binning.fill(x, w)
 }
 
+// SetKahanSum enables or disables the Kahan-compensated summation of
+// the weights when filling this histogram.
+//
+// Compensated summation reduces the floating point error accumulated in the
+// sums of weights (and of squared weights) of the bins and of the global
+// statistics, at the expense of slower fills.
+// It is mostly useful when filling billions of entries with small weights.
+//
+// Enabling compensated summation only affects subsequent fills.
+// The compensation terms are not persisted when the histogram is serialized.
+func (h *H1D) SetKahanSum(enable bool) {
+	switch {
+	case !enable:
+		h.Binning.kahan = nil
+	case h.Binning.kahan == nil:
+		h.Binning.kahan = newKBinning1D(len(h.Binning.Bins))
+	}
+}
+
+// KahanSum returns whether this histogram uses Kahan-compensated summation.
+func (h *H1D) KahanSum() bool {
+	return h.Binning.kahan != nil
+}
+
 // FillN fills this histogram with the provided slices of xs and weight ws.
 // if ws is nil, the histogram will be filled with entries of weight 1.
 // Otherwise, FillN panics if the slices lengths differ.
@@ -737,3 +737,105 @@ func TestH1DClone(t *testing.T) {
 		)
 	}
 }
+
+func TestH1DMoments(t *testing.T) {
+	h := NewH1D(4, 0, 4)
+	for _, v := range []struct{ x, w float64 }{
+		{0.5, 1}, {1.5, 2}, {3.5, 1}, {2.5, 0.5},
+		{10, 1}, // overflows are not considered.
+	} {
+		h.Fill(v.x, v.w)
+	}
+
+	for _, tc := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"skewness", h.XSkewness(), 0.44271887242357316},
+		{"kurtosis", h.XKurtosis(), -1.0200000000000018},
+		{"neff", h.EffEntries(), 30.25 / 7.25},
+	} {
+		if !scalar.EqualWithinAbsOrRel(tc.got, tc.want, 1e-12, 1e-12) {
+			t.Errorf("invalid %s: got=%v, want=%v", tc.name, tc.got, tc.want)
+		}
+	}
+
+	empty := NewH1D(4, 0, 4)
+	if got := empty.XSkewness(); got != 0 {
+		t.Errorf("invalid skewness for empty histogram: got=%v", got)
+	}
+	if got := empty.XKurtosis(); got != 0 {
+		t.Errorf("invalid kurtosis for empty histogram: got=%v", got)
+	}
+
+	single := NewH1D(4, 0, 4)
+	single.Fill(1.5, 1)
+	if got := single.XSkewness(); got != 0 {
+		t.Errorf("invalid skewness for zero-width histogram: got=%v", got)
+	}
+	if got := single.XKurtosis(); got != 0 {
+		t.Errorf("invalid kurtosis for zero-width histogram: got=%v", got)
+	}
+}
+
+func TestH1DKahanSum(t *testing.T) {
+	const n = 1000000
+	var (
+		w    = 0.1
+		want = w * n // correctly rounded sum of n weights w.
+	)
+
+	h := NewH1D(10, 0, 10)
+	h.SetKahanSum(true)
+	if !h.KahanSum() {
+		t.Fatalf("kahan summation should be enabled")
+	}
+	ref := NewH1D(10, 0, 10)
+	for i := 0; i < n; i++ {
+		h.Fill(0.5, w)
+		h.Fill(-1, w)
+		ref.Fill(0.5, w)
+	}
+
+	if got := h.Binning.Bins[0].SumW(); got != want {
+		t.Fatalf("invalid bin sumw: got=%v, want=%v", got, want)
+	}
+	if got := h.Binning.Outflows[0].SumW(); got != want {
+		t.Fatalf("invalid underflow sumw: got=%v, want=%v", got, want)
+	}
+	if got := h.SumW(); got != 2*want {
+		t.Fatalf("invalid sumw: got=%v, want=%v", got, 2*want)
+	}
+	if got := ref.Binning.Bins[0].SumW(); got == want {
+		t.Fatalf("naive summation should not be exact: got=%v", got)
+	}
+	if got, want := h.Entries(), int64(2*n); got != want {
+		t.Fatalf("invalid number of entries: got=%d, want=%d", got, want)
+	}
+	if got, want := h.XMean(), ref.XMean()/2-0.5; !scalar.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+		t.Fatalf("invalid x-mean: got=%v, want=%v", got, want)
+	}
+
+	c := h.Clone()
+	if !c.KahanSum() {
+		t.Fatalf("clone should use kahan summation")
+	}
+	c.Fill(0.5, w)
+	if got, want := c.Binning.Bins[0].SumW(), h.Binning.Bins[0].SumW(); got == want {
+		t.Fatalf("clone should not share state with original")
+	}
+
+	h.Scale(2)
+	for i := 0; i < n; i++ {
+		h.Fill(0.5, w)
+	}
+	if got, want := h.Binning.Bins[0].SumW(), 3*want; !scalar.EqualWithinAbsOrRel(got, want, 0, 1e-15) {
+		t.Fatalf("invalid bin sumw after scale: got=%v, want=%v", got, want)
+	}
+
+	h.SetKahanSum(false)
+	if h.KahanSum() {
+		t.Fatalf("kahan summation should be disabled")
+	}
+}
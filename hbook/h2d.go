@@ -174,11 +174,59 @@ func (h *H2D) YRMS() float64 {
 	return h.Binning.Dist.yRMS()
 }
 
+// XSkewness returns the skewness in X.
+// Overflows are not included in the computation.
+func (h *H2D) XSkewness() float64 {
+	return h.Binning.xMoment(3)
+}
+
+// YSkewness returns the skewness in Y.
+// Overflows are not included in the computation.
+func (h *H2D) YSkewness() float64 {
+	return h.Binning.yMoment(3)
+}
+
+// XKurtosis returns the excess kurtosis in X.
+// Overflows are not included in the computation.
+func (h *H2D) XKurtosis() float64 {
+	return excessKurtosis(h.Binning.xMoment(4))
+}
+
+// YKurtosis returns the excess kurtosis in Y.
+// Overflows are not included in the computation.
+func (h *H2D) YKurtosis() float64 {
+	return excessKurtosis(h.Binning.yMoment(4))
+}
+
 // Fill fills this histogram with (x,y) and weight w.
 func (h *H2D) Fill(x, y, w float64) {
 	h.Binning.fill(x, y, w)
 }
 
+// SetKahanSum enables or disables the Kahan-compensated summation of
+// the weights when filling this histogram.
+//
+// Compensated summation reduces the floating point error accumulated in the
+// sums of weights (and of squared weights) of the bins and of the global
+// statistics, at the expense of slower fills.
+// It is mostly useful when filling billions of entries with small weights.
+//
+// Enabling compensated summation only affects subsequent fills.
+// The compensation terms are not persisted when the histogram is serialized.
+func (h *H2D) SetKahanSum(enable bool) {
+	switch {
+	case !enable:
+		h.Binning.kahan = nil
+	case h.Binning.kahan == nil:
+		h.Binning.kahan = newKBinning2D(len(h.Binning.Bins))
+	}
+}
+
+// KahanSum returns whether this histogram uses Kahan-compensated summation.
+func (h *H2D) KahanSum() bool {
+	return h.Binning.kahan != nil
+}
+
 // FillN fills this histogram with the provided slices (xs,ys) and weights ws.
 // if ws is nil, the histogram will be filled with entries of weight 1.
 // Otherwise, FillN panics if the slices lengths differ.
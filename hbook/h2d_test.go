@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"gonum.org/v1/gonum/floats/scalar"
 	"gonum.org/v1/plot/plotter"
 )
 
@@ -487,3 +488,59 @@ func TestH2DFillN(t *testing.T) {
 		h2.FillN(xs, ys, []float64{1})
 	}()
 }
+
+func TestH2DMoments(t *testing.T) {
+	h := NewH2D(4, 0, 4, 4, 0, 4)
+	for _, v := range []struct{ x, y, w float64 }{
+		{0.5, 3.5, 1}, {1.5, 2.5, 2}, {3.5, 0.5, 1}, {2.5, 1.5, 0.5},
+		{10, 10, 1}, // overflows are not considered.
+	} {
+		h.Fill(v.x, v.y, v.w)
+	}
+
+	for _, tc := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"x-skewness", h.XSkewness(), +0.44271887242357316},
+		{"y-skewness", h.YSkewness(), -0.44271887242357316},
+		{"x-kurtosis", h.XKurtosis(), -1.0200000000000018},
+		{"y-kurtosis", h.YKurtosis(), -1.0200000000000018},
+	} {
+		if !scalar.EqualWithinAbsOrRel(tc.got, tc.want, 1e-12, 1e-12) {
+			t.Errorf("invalid %s: got=%v, want=%v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestH2DKahanSum(t *testing.T) {
+	const n = 1000000
+	var (
+		w    = 0.1
+		want = w * n // correctly rounded sum of n weights w.
+	)
+
+	h := NewH2D(10, 0, 10, 10, 0, 10)
+	h.SetKahanSum(true)
+	ref := NewH2D(10, 0, 10, 10, 0, 10)
+	for i := 0; i < n; i++ {
+		h.Fill(0.5, 0.5, w)
+		ref.Fill(0.5, 0.5, w)
+	}
+
+	if got := h.Binning.Bins[0].SumW(); got != want {
+		t.Fatalf("invalid bin sumw: got=%v, want=%v", got, want)
+	}
+	if got := h.SumW(); got != want {
+		t.Fatalf("invalid sumw: got=%v, want=%v", got, want)
+	}
+	if got := ref.SumW(); got == want {
+		t.Fatalf("naive summation should not be exact: got=%v", got)
+	}
+
+	h.SetKahanSum(false)
+	if h.KahanSum() {
+		t.Fatalf("kahan summation should be disabled")
+	}
+}
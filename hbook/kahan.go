@@ -0,0 +1,122 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hbook
+
+// kahan holds the running compensation of a Kahan summation.
+type kahan float64
+
+// add adds v to *sum, compensating for the floating point error
+// accumulated so far.
+func (c *kahan) add(sum *float64, v float64) {
+	y := v - float64(*c)
+	t := *sum + y
+	*c = kahan((t - *sum) - y)
+	*sum = t
+}
+
+// kdist0D holds the compensation terms of a Dist0D.
+type kdist0D struct {
+	sumw  kahan
+	sumw2 kahan
+}
+
+func (k *kdist0D) fill(d *Dist0D, w float64) {
+	d.N++
+	k.sumw.add(&d.SumW, w)
+	k.sumw2.add(&d.SumW2, w*w)
+}
+
+func (k *kdist0D) scaleW(f float64) {
+	k.sumw *= kahan(f)
+	k.sumw2 *= kahan(f * f)
+}
+
+// kdist1D holds the compensation terms of a Dist1D.
+type kdist1D struct {
+	dist   kdist0D
+	sumwx  kahan
+	sumwx2 kahan
+}
+
+func (k *kdist1D) fill(d *Dist1D, x, w float64) {
+	k.dist.fill(&d.Dist, w)
+	k.sumwx.add(&d.Stats.SumWX, w*x)
+	k.sumwx2.add(&d.Stats.SumWX2, w*x*x)
+}
+
+func (k *kdist1D) scaleW(f float64) {
+	k.dist.scaleW(f)
+	k.sumwx *= kahan(f)
+	k.sumwx2 *= kahan(f)
+}
+
+// kdist2D holds the compensation terms of a Dist2D.
+type kdist2D struct {
+	x      kdist1D
+	y      kdist1D
+	sumwxy kahan
+}
+
+func (k *kdist2D) fill(d *Dist2D, x, y, w float64) {
+	k.x.fill(&d.X, x, w)
+	k.y.fill(&d.Y, y, w)
+	k.sumwxy.add(&d.Stats.SumWXY, w*x*y)
+}
+
+func (k *kdist2D) scaleW(f float64) {
+	k.x.scaleW(f)
+	k.y.scaleW(f)
+	k.sumwxy *= kahan(f)
+}
+
+// kbinning1D holds the compensation terms of a Binning1D.
+type kbinning1D struct {
+	bins     []kdist1D
+	dist     kdist1D
+	outflows [2]kdist1D
+}
+
+func newKBinning1D(n int) *kbinning1D {
+	return &kbinning1D{bins: make([]kdist1D, n)}
+}
+
+func (k *kbinning1D) clone() *kbinning1D {
+	if k == nil {
+		return nil
+	}
+	o := *k
+	o.bins = append([]kdist1D(nil), k.bins...)
+	return &o
+}
+
+func (k *kbinning1D) scaleW(f float64) {
+	k.dist.scaleW(f)
+	k.outflows[0].scaleW(f)
+	k.outflows[1].scaleW(f)
+	for i := range k.bins {
+		k.bins[i].scaleW(f)
+	}
+}
+
+// kbinning2D holds the compensation terms of a Binning2D.
+type kbinning2D struct {
+	bins     []kdist2D
+	dist     kdist2D
+	outflows [8]kdist2D
+}
+
+func newKBinning2D(n int) *kbinning2D {
+	return &kbinning2D{bins: make([]kdist2D, n)}
+}
+
+func (k *kbinning2D) scaleW(f float64) {
+	k.dist.scaleW(f)
+	for i := range k.outflows {
+		k.outflows[i].scaleW(f)
+	}
+	for i := range k.bins {
+		k.bins[i].scaleW(f)
+	}
+}
@@ -0,0 +1,490 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package npycnv provides tools to read/write go-hep/hbook histograms
+// from/to NumPy data files.
+//
+// Histograms are stored in .npz archives, following the conventions of
+// numpy.histogram and numpy.histogram2d:
+//
+//  - 1-dim histograms: "edges" (n+1), "contents" (n) and "errors" (n) arrays,
+//  - 2-dim histograms: "xedges" (nx+1), "yedges" (ny+1), "contents" (nx,ny)
+//    and "errors" (nx,ny) arrays.
+//
+// The "errors" array is optional when reading: if it is missing, the errors
+// are taken as the square root of the contents.
+//
+// Histograms can also be stored as a single table in a .npy file, with one
+// row per bin:
+//
+//  - 1-dim histograms: (n,4) array of (xlow, xhigh, content, error),
+//  - 2-dim histograms: (nx*ny,6) array of (xlow, xhigh, ylow, yhigh, content, error),
+//    with the Y index running fastest.
+//
+// Only the in-range bins are stored: outflows and the moments of the
+// distributions within each bin are not preserved.
+// When reading, the entries of each bin are set to its effective number of
+// entries and the moments are computed from the bin centers.
+package npycnv // import "go-hep.org/x/hep/hbook/npycnv"
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+
+	"github.com/sbinet/npyio"
+	"github.com/sbinet/npyio/npz"
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/mat"
+)
+
+// WriteH1D writes the provided 1-dim histogram to w, as a .npz archive.
+func WriteH1D(w io.Writer, h *hbook.H1D) error {
+	var (
+		bins     = h.Binning.Bins
+		edges    = make([]float64, 0, len(bins)+1)
+		contents = make([]float64, len(bins))
+		errors   = make([]float64, len(bins))
+	)
+	for i, bin := range bins {
+		edges = append(edges, bin.XMin())
+		contents[i] = bin.SumW()
+		errors[i] = math.Sqrt(bin.SumW2())
+	}
+	edges = append(edges, bins[len(bins)-1].XMax())
+
+	return writeNPZ(w, []string{"edges", "contents", "errors"}, edges, contents, errors)
+}
+
+// ReadH1D reads a 1-dim histogram from the .npz archive r of the provided size.
+func ReadH1D(r io.ReaderAt, size int64) (*hbook.H1D, error) {
+	rz, err := npz.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("npycnv: could not open npz archive: %w", err)
+	}
+	defer rz.Close()
+
+	edges, _, err := readArray(rz, "edges", true)
+	if err != nil {
+		return nil, err
+	}
+	contents, _, err := readArray(rz, "contents", true)
+	if err != nil {
+		return nil, err
+	}
+	errors, _, err := readArray(rz, "errors", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newH1D(edges, contents, errors)
+}
+
+// WriteH2D writes the provided 2-dim histogram to w, as a .npz archive.
+func WriteH2D(w io.Writer, h *hbook.H2D) error {
+	var (
+		nx       = h.Binning.Nx
+		ny       = h.Binning.Ny
+		xedges   = edgesOf(h.Binning.XEdges)
+		yedges   = edgesOf(h.Binning.YEdges)
+		contents = mat.NewDense(nx, ny, nil)
+		errors   = mat.NewDense(nx, ny, nil)
+	)
+	for ix := 0; ix < nx; ix++ {
+		for iy := 0; iy < ny; iy++ {
+			bin := &h.Binning.Bins[iy*nx+ix]
+			contents.Set(ix, iy, bin.SumW())
+			errors.Set(ix, iy, math.Sqrt(bin.SumW2()))
+		}
+	}
+
+	return writeNPZ(w, []string{"xedges", "yedges", "contents", "errors"}, xedges, yedges, *contents, *errors)
+}
+
+// ReadH2D reads a 2-dim histogram from the .npz archive r of the provided size.
+func ReadH2D(r io.ReaderAt, size int64) (*hbook.H2D, error) {
+	rz, err := npz.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("npycnv: could not open npz archive: %w", err)
+	}
+	defer rz.Close()
+
+	xedges, _, err := readArray(rz, "xedges", true)
+	if err != nil {
+		return nil, err
+	}
+	yedges, _, err := readArray(rz, "yedges", true)
+	if err != nil {
+		return nil, err
+	}
+	contents, shape, err := readArray(rz, "contents", true)
+	if err != nil {
+		return nil, err
+	}
+	nx, ny := len(xedges)-1, len(yedges)-1
+	if len(shape) != 2 || shape[0] != nx || shape[1] != ny {
+		return nil, fmt.Errorf("npycnv: invalid contents shape %v (want [%d %d])", shape, nx, ny)
+	}
+	errors, _, err := readArray(rz, "errors", false)
+	if err != nil {
+		return nil, err
+	}
+
+	return newH2D(xedges, yedges, contents, errors)
+}
+
+// WriteNpyH1D writes the provided 1-dim histogram to w, as a .npy table.
+func WriteNpyH1D(w io.Writer, h *hbook.H1D) error {
+	var (
+		bins = h.Binning.Bins
+		tbl  = mat.NewDense(len(bins), 4, nil)
+	)
+	for i, bin := range bins {
+		tbl.SetRow(i, []float64{bin.XMin(), bin.XMax(), bin.SumW(), math.Sqrt(bin.SumW2())})
+	}
+
+	err := npyio.Write(w, *tbl)
+	if err != nil {
+		return fmt.Errorf("npycnv: could not write npy table: %w", err)
+	}
+	return nil
+}
+
+// ReadNpyH1D reads a 1-dim histogram from the .npy table r.
+func ReadNpyH1D(r io.Reader) (*hbook.H1D, error) {
+	data, n, err := readTable(r, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		edges    = make([]float64, 0, n+1)
+		contents = make([]float64, n)
+		errors   = make([]float64, n)
+	)
+	for i := 0; i < n; i++ {
+		row := data[4*i : 4*i+4]
+		if i > 0 && row[0] != edges[i] {
+			return nil, fmt.Errorf("npycnv: non-contiguous bins in npy table (row %d)", i)
+		}
+		if i == 0 {
+			edges = append(edges, row[0])
+		}
+		edges = append(edges, row[1])
+		contents[i] = row[2]
+		errors[i] = row[3]
+	}
+
+	return newH1D(edges, contents, errors)
+}
+
+// WriteNpyH2D writes the provided 2-dim histogram to w, as a .npy table.
+func WriteNpyH2D(w io.Writer, h *hbook.H2D) error {
+	var (
+		nx  = h.Binning.Nx
+		ny  = h.Binning.Ny
+		tbl = mat.NewDense(nx*ny, 6, nil)
+	)
+	for ix := 0; ix < nx; ix++ {
+		for iy := 0; iy < ny; iy++ {
+			bin := &h.Binning.Bins[iy*nx+ix]
+			tbl.SetRow(ix*ny+iy, []float64{
+				bin.XMin(), bin.XMax(),
+				bin.YMin(), bin.YMax(),
+				bin.SumW(), math.Sqrt(bin.SumW2()),
+			})
+		}
+	}
+
+	err := npyio.Write(w, *tbl)
+	if err != nil {
+		return fmt.Errorf("npycnv: could not write npy table: %w", err)
+	}
+	return nil
+}
+
+// ReadNpyH2D reads a 2-dim histogram from the .npy table r.
+func ReadNpyH2D(r io.Reader) (*hbook.H2D, error) {
+	data, n, err := readTable(r, 6)
+	if err != nil {
+		return nil, err
+	}
+
+	// the Y index runs fastest: count the rows of the first X-slice.
+	ny := 1
+	for ny < n && data[6*ny] == data[0] && data[6*ny+1] == data[1] {
+		ny++
+	}
+	if n%ny != 0 {
+		return nil, fmt.Errorf("npycnv: invalid number of rows %d in npy table (ny=%d)", n, ny)
+	}
+	nx := n / ny
+
+	var (
+		xedges   = make([]float64, 0, nx+1)
+		yedges   = make([]float64, 0, ny+1)
+		contents = make([]float64, n)
+		errors   = make([]float64, n)
+	)
+	xedges = append(xedges, data[0])
+	yedges = append(yedges, data[2])
+	for ix := 0; ix < nx; ix++ {
+		xedges = append(xedges, data[6*ix*ny+1])
+	}
+	for iy := 0; iy < ny; iy++ {
+		yedges = append(yedges, data[6*iy+3])
+	}
+
+	for i := 0; i < n; i++ {
+		var (
+			ix  = i / ny
+			iy  = i % ny
+			row = data[6*i : 6*i+6]
+		)
+		if row[0] != xedges[ix] || row[1] != xedges[ix+1] ||
+			row[2] != yedges[iy] || row[3] != yedges[iy+1] {
+			return nil, fmt.Errorf("npycnv: inconsistent bin edges in npy table (row %d)", i)
+		}
+		contents[i] = row[4]
+		errors[i] = row[5]
+	}
+
+	return newH2D(xedges, yedges, contents, errors)
+}
+
+func writeNPZ(w io.Writer, names []string, vs ...interface{}) error {
+	wz := npz.NewWriter(w)
+	for i, name := range names {
+		err := wz.Write(name, vs[i])
+		if err != nil {
+			_ = wz.Close()
+			return fmt.Errorf("npycnv: could not write %q array: %w", name, err)
+		}
+	}
+	err := wz.Close()
+	if err != nil {
+		return fmt.Errorf("npycnv: could not close npz archive: %w", err)
+	}
+	return nil
+}
+
+// readArray reads the named array from the npz archive, converting its
+// elements to float64, in C-order.
+// readArray returns a nil slice if the array is missing and not required.
+func readArray(rz *npz.Reader, name string, required bool) ([]float64, []int, error) {
+	if rz.Header(name) == nil {
+		if required {
+			return nil, nil, fmt.Errorf("npycnv: missing %q array", name)
+		}
+		return nil, nil, nil
+	}
+
+	rc, err := rz.Open(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("npycnv: could not open %q array: %w", name, err)
+	}
+	defer rc.Close()
+
+	vs, shape, err := readFloats(rc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("npycnv: could not read %q array: %w", name, err)
+	}
+	return vs, shape, nil
+}
+
+// readTable reads a (n,ncols) table from r and returns its data in
+// C-order, together with its number of rows.
+func readTable(r io.Reader, ncols int) ([]float64, int, error) {
+	data, shape, err := readFloats(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("npycnv: could not read npy table: %w", err)
+	}
+	if len(shape) != 2 || shape[1] != ncols || shape[0] == 0 {
+		return nil, 0, fmt.Errorf("npycnv: invalid npy table shape %v (want [n %d])", shape, ncols)
+	}
+	return data, shape[0], nil
+}
+
+// readFloats reads a numpy array of numbers from r and converts it into
+// a slice of float64, in C-order.
+func readFloats(r io.Reader) ([]float64, []int, error) {
+	rnpy, err := npyio.NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		hdr   = rnpy.Header
+		shape = hdr.Descr.Shape
+		rt    = npyio.TypeFrom(hdr.Descr.Type)
+	)
+	if rt == nil {
+		return nil, nil, fmt.Errorf("unknown dtype %q", hdr.Descr.Type)
+	}
+	if len(shape) > 2 {
+		return nil, nil, fmt.Errorf("invalid array shape %v", shape)
+	}
+
+	ptr := reflect.New(reflect.SliceOf(rt))
+	err = rnpy.Read(ptr.Interface())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		sli = ptr.Elem()
+		vs  = make([]float64, sli.Len())
+	)
+	for i := range vs {
+		v := sli.Index(i)
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			vs[i] = v.Float()
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			vs[i] = float64(v.Int())
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			vs[i] = float64(v.Uint())
+		default:
+			return nil, nil, fmt.Errorf("invalid array element type %v", rt)
+		}
+	}
+
+	if hdr.Descr.Fortran && len(shape) == 2 {
+		// transpose to C-order.
+		var (
+			nrows = shape[0]
+			ncols = shape[1]
+			o     = make([]float64, len(vs))
+		)
+		for i, v := range vs {
+			o[(i%nrows)*ncols+i/nrows] = v
+		}
+		vs = o
+	}
+
+	return vs, shape, nil
+}
+
+func edgesOf(bins []hbook.Bin1D) []float64 {
+	edges := make([]float64, 0, len(bins)+1)
+	for _, bin := range bins {
+		edges = append(edges, bin.XMin())
+	}
+	return append(edges, bins[len(bins)-1].XMax())
+}
+
+// newH1D creates a new 1-dim histogram from the provided edges, contents
+// and errors.
+// If errors is nil, the errors are taken as sqrt(|contents|).
+func newH1D(edges, contents, errors []float64) (h *hbook.H1D, err error) {
+	n := len(edges) - 1
+	switch {
+	case n < 1:
+		return nil, fmt.Errorf("npycnv: not enough edges (n=%d)", len(edges))
+	case len(contents) != n:
+		return nil, fmt.Errorf("npycnv: invalid number of contents (got=%d, want=%d)", len(contents), n)
+	case errors != nil && len(errors) != n:
+		return nil, fmt.Errorf("npycnv: invalid number of errors (got=%d, want=%d)", len(errors), n)
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			h = nil
+			err = fmt.Errorf("npycnv: invalid edges: %v", e)
+		}
+	}()
+	h = hbook.NewH1DFromEdges(edges)
+
+	var tot hbook.Dist1D
+	for i := range h.Binning.Bins {
+		bin := &h.Binning.Bins[i]
+		bin.Dist = dist1D(bin.XMid(), contents[i], sumw2(contents, errors, i))
+		addDist1D(&tot, bin.Dist)
+	}
+	h.Binning.Dist = tot
+
+	return h, nil
+}
+
+// newH2D creates a new 2-dim histogram from the provided edges, and the
+// contents and errors in C-order (ie: with the Y index running fastest).
+// If errors is nil, the errors are taken as sqrt(|contents|).
+func newH2D(xedges, yedges, contents, errors []float64) (h *hbook.H2D, err error) {
+	var (
+		nx = len(xedges) - 1
+		ny = len(yedges) - 1
+	)
+	switch {
+	case nx < 1:
+		return nil, fmt.Errorf("npycnv: not enough X edges (n=%d)", len(xedges))
+	case ny < 1:
+		return nil, fmt.Errorf("npycnv: not enough Y edges (n=%d)", len(yedges))
+	case len(contents) != nx*ny:
+		return nil, fmt.Errorf("npycnv: invalid number of contents (got=%d, want=%d)", len(contents), nx*ny)
+	case errors != nil && len(errors) != nx*ny:
+		return nil, fmt.Errorf("npycnv: invalid number of errors (got=%d, want=%d)", len(errors), nx*ny)
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			h = nil
+			err = fmt.Errorf("npycnv: invalid edges: %v", e)
+		}
+	}()
+	h = hbook.NewH2DFromEdges(xedges, yedges)
+
+	var tot hbook.Dist2D
+	for ix := 0; ix < nx; ix++ {
+		for iy := 0; iy < ny; iy++ {
+			var (
+				bin  = &h.Binning.Bins[iy*nx+ix]
+				i    = ix*ny + iy
+				x    = bin.XMid()
+				y    = bin.YMid()
+				sumw = contents[i]
+			)
+			bin.Dist = hbook.Dist2D{
+				X: dist1D(x, sumw, sumw2(contents, errors, i)),
+				Y: dist1D(y, sumw, sumw2(contents, errors, i)),
+			}
+			bin.Dist.Stats.SumWXY = sumw * x * y
+			addDist1D(&tot.X, bin.Dist.X)
+			addDist1D(&tot.Y, bin.Dist.Y)
+			tot.Stats.SumWXY += bin.Dist.Stats.SumWXY
+		}
+	}
+	h.Binning.Dist = tot
+
+	return h, nil
+}
+
+func sumw2(contents, errors []float64, i int) float64 {
+	if errors == nil {
+		return math.Abs(contents[i])
+	}
+	return errors[i] * errors[i]
+}
+
+// dist1D returns the distribution of a bin centered on x, with the
+// provided sum of weights and sum of squared weights.
+func dist1D(x, sumw, sumw2 float64) hbook.Dist1D {
+	var d hbook.Dist1D
+	d.Dist.SumW = sumw
+	d.Dist.SumW2 = sumw2
+	if sumw2 > 0 {
+		d.Dist.N = int64(math.Round(sumw * sumw / sumw2))
+	}
+	d.Stats.SumWX = sumw * x
+	d.Stats.SumWX2 = sumw * x * x
+	return d
+}
+
+func addDist1D(dst *hbook.Dist1D, src hbook.Dist1D) {
+	dst.Dist.N += src.Dist.N
+	dst.Dist.SumW += src.Dist.SumW
+	dst.Dist.SumW2 += src.Dist.SumW2
+	dst.Stats.SumWX += src.Stats.SumWX
+	dst.Stats.SumWX2 += src.Stats.SumWX2
+}
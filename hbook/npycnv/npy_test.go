@@ -0,0 +1,216 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package npycnv_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/sbinet/npyio/npz"
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hbook/npycnv"
+	"gonum.org/v1/gonum/floats/scalar"
+)
+
+func newH1D() *hbook.H1D {
+	h := hbook.NewH1DFromEdges([]float64{0, 1, 2, 4, 8})
+	for i, x := range []float64{0.5, 1.5, 1.5, 3, 5, 5, 5, 9, -1} {
+		h.Fill(x, 1+0.5*float64(i))
+	}
+	return h
+}
+
+func newH2D() *hbook.H2D {
+	h := hbook.NewH2DFromEdges([]float64{0, 1, 3}, []float64{-1, 0, 1, 2})
+	for i, v := range [][2]float64{{0.5, -0.5}, {0.5, 0.5}, {2, 1.5}, {2, 1.5}, {2.5, -0.5}, {4, 0}} {
+		h.Fill(v[0], v[1], 1+float64(i))
+	}
+	return h
+}
+
+func TestH1D(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		write func(w *bytes.Buffer, h *hbook.H1D) error
+		read  func(r *bytes.Reader) (*hbook.H1D, error)
+	}{
+		{
+			name:  "npz",
+			write: func(w *bytes.Buffer, h *hbook.H1D) error { return npycnv.WriteH1D(w, h) },
+			read:  func(r *bytes.Reader) (*hbook.H1D, error) { return npycnv.ReadH1D(r, r.Size()) },
+		},
+		{
+			name:  "npy",
+			write: func(w *bytes.Buffer, h *hbook.H1D) error { return npycnv.WriteNpyH1D(w, h) },
+			read:  func(r *bytes.Reader) (*hbook.H1D, error) { return npycnv.ReadNpyH1D(r) },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want := newH1D()
+			buf := new(bytes.Buffer)
+			err := tc.write(buf, want)
+			if err != nil {
+				t.Fatalf("could not write histogram: %+v", err)
+			}
+
+			got, err := tc.read(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("could not read histogram: %+v", err)
+			}
+
+			if got, want := got.Len(), want.Len(); got != want {
+				t.Fatalf("invalid number of bins: got=%d, want=%d", got, want)
+			}
+			for i := range want.Binning.Bins {
+				var (
+					gbin = got.Binning.Bins[i]
+					wbin = want.Binning.Bins[i]
+				)
+				if gbin.Range != wbin.Range {
+					t.Fatalf("bin[%d]: invalid range: got=%v, want=%v", i, gbin.Range, wbin.Range)
+				}
+				if !scalar.EqualWithinAbsOrRel(gbin.SumW(), wbin.SumW(), 1e-12, 1e-12) {
+					t.Fatalf("bin[%d]: invalid sumw: got=%v, want=%v", i, gbin.SumW(), wbin.SumW())
+				}
+				if !scalar.EqualWithinAbsOrRel(gbin.SumW2(), wbin.SumW2(), 1e-12, 1e-12) {
+					t.Fatalf("bin[%d]: invalid sumw2: got=%v, want=%v", i, gbin.SumW2(), wbin.SumW2())
+				}
+			}
+
+			// outflows are not stored.
+			var sumw float64
+			for _, bin := range want.Binning.Bins {
+				sumw += bin.SumW()
+			}
+			if got, want := got.SumW(), sumw; !scalar.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+				t.Fatalf("invalid total sumw: got=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestH2D(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		write func(w *bytes.Buffer, h *hbook.H2D) error
+		read  func(r *bytes.Reader) (*hbook.H2D, error)
+	}{
+		{
+			name:  "npz",
+			write: func(w *bytes.Buffer, h *hbook.H2D) error { return npycnv.WriteH2D(w, h) },
+			read:  func(r *bytes.Reader) (*hbook.H2D, error) { return npycnv.ReadH2D(r, r.Size()) },
+		},
+		{
+			name:  "npy",
+			write: func(w *bytes.Buffer, h *hbook.H2D) error { return npycnv.WriteNpyH2D(w, h) },
+			read:  func(r *bytes.Reader) (*hbook.H2D, error) { return npycnv.ReadNpyH2D(r) },
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want := newH2D()
+			buf := new(bytes.Buffer)
+			err := tc.write(buf, want)
+			if err != nil {
+				t.Fatalf("could not write histogram: %+v", err)
+			}
+
+			got, err := tc.read(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("could not read histogram: %+v", err)
+			}
+
+			if got.Binning.Nx != want.Binning.Nx || got.Binning.Ny != want.Binning.Ny {
+				t.Fatalf("invalid binning: got=(%d,%d), want=(%d,%d)",
+					got.Binning.Nx, got.Binning.Ny,
+					want.Binning.Nx, want.Binning.Ny,
+				)
+			}
+			for i := range want.Binning.Bins {
+				var (
+					gbin = got.Binning.Bins[i]
+					wbin = want.Binning.Bins[i]
+				)
+				if gbin.XRange != wbin.XRange || gbin.YRange != wbin.YRange {
+					t.Fatalf("bin[%d]: invalid ranges: got=(%v,%v), want=(%v,%v)",
+						i, gbin.XRange, gbin.YRange, wbin.XRange, wbin.YRange,
+					)
+				}
+				if !scalar.EqualWithinAbsOrRel(gbin.SumW(), wbin.SumW(), 1e-12, 1e-12) {
+					t.Fatalf("bin[%d]: invalid sumw: got=%v, want=%v", i, gbin.SumW(), wbin.SumW())
+				}
+				if !scalar.EqualWithinAbsOrRel(gbin.SumW2(), wbin.SumW2(), 1e-12, 1e-12) {
+					t.Fatalf("bin[%d]: invalid sumw2: got=%v, want=%v", i, gbin.SumW2(), wbin.SumW2())
+				}
+			}
+		})
+	}
+}
+
+func TestReadNumpyHistogram(t *testing.T) {
+	// mimic np.savez("h.npz", contents=counts, edges=edges),
+	// with counts from an unweighted np.histogram.
+	buf := new(bytes.Buffer)
+	wz := npz.NewWriter(buf)
+	err := wz.Write("edges", []float64{0, 1, 2, 3})
+	if err != nil {
+		t.Fatalf("could not write edges: %+v", err)
+	}
+	err = wz.Write("contents", []int64{4, 0, 9})
+	if err != nil {
+		t.Fatalf("could not write contents: %+v", err)
+	}
+	err = wz.Close()
+	if err != nil {
+		t.Fatalf("could not close npz: %+v", err)
+	}
+
+	r := bytes.NewReader(buf.Bytes())
+	h, err := npycnv.ReadH1D(r, r.Size())
+	if err != nil {
+		t.Fatalf("could not read histogram: %+v", err)
+	}
+
+	for i, want := range []float64{4, 0, 9} {
+		if got := h.Value(i); got != want {
+			t.Fatalf("bin[%d]: invalid content: got=%v, want=%v", i, got, want)
+		}
+		if got, want := h.Error(i), math.Sqrt(want); got != want {
+			t.Fatalf("bin[%d]: invalid error: got=%v, want=%v", i, got, want)
+		}
+	}
+	if got, want := h.Entries(), int64(13); got != want {
+		t.Fatalf("invalid entries: got=%d, want=%d", got, want)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	buf := new(bytes.Buffer)
+	wz := npz.NewWriter(buf)
+	err := wz.Write("edges", []float64{0, 1, 2, 3})
+	if err != nil {
+		t.Fatalf("could not write edges: %+v", err)
+	}
+	err = wz.Write("contents", []float64{1, 2})
+	if err != nil {
+		t.Fatalf("could not write contents: %+v", err)
+	}
+	err = wz.Close()
+	if err != nil {
+		t.Fatalf("could not close npz: %+v", err)
+	}
+
+	r := bytes.NewReader(buf.Bytes())
+	_, err = npycnv.ReadH1D(r, r.Size())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	r = bytes.NewReader(buf.Bytes())
+	_, err = npycnv.ReadH2D(r, r.Size())
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
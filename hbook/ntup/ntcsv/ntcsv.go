@@ -31,11 +31,29 @@
 // Override the names from the CSV header with our own:
 //
 //  nt, err := ntcsv.Open("testdata/simple-with-header.csv", ntcsv.Header(), ntcsv.Columns("v1", "v2", "v3")
+//
+// Compressed (gzip or zstd) CSV files are transparently decompressed.
+// TSV files (".tsv" or ".tab" files, possibly compressed) use a tab as the
+// default comma delimiter:
+//
+//  nt, err := ntcsv.Open("testdata/simple.tsv.zst")
+//
+// Column types are inferred from the first rows of the file (default: 100):
+//
+//  nt, err := ntcsv.Open("testdata/simple-with-header.csv.gz", ntcsv.Header(), ntcsv.Sample(1000))
+//
+// The content of a n-tuple can be written back to a CSV file:
+//
+//  err := ntcsv.Write("out.csv", nt, ntcsv.Header())
 package ntcsv // import "go-hep.org/x/hep/hbook/ntup/ntcsv"
 
 import (
 	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
 
+	"go-hep.org/x/hep/csvutil"
 	"go-hep.org/x/hep/csvutil/csvdriver"
 	"go-hep.org/x/hep/hbook/ntup"
 )
@@ -62,6 +80,96 @@ func Open(name string, opts ...Option) (*ntup.Ntuple, error) {
 	return nt, nil
 }
 
+// Write writes the rows of the n-tuple to a new CSV file.
+//
+// The Comma, Header and Columns options configure the comma delimiter
+// (default: ',', or '\t' for ".tsv" and ".tab" files), whether a header
+// line with the names of the columns is written, and the names of these
+// columns (default: the names of the n-tuple columns).
+// Missing values are written as empty fields.
+func Write(name string, nt *ntup.Ntuple, opts ...Option) error {
+	c := csvdriver.Conn{File: name}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.Comma == 0 {
+		c.Comma = ','
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".tsv", ".tab":
+			c.Comma = '\t'
+		}
+	}
+
+	rows, err := nt.DB().Query("select * from " + nt.Name() + " order by id()")
+	if err != nil {
+		return fmt.Errorf("could not query n-tuple: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("could not retrieve n-tuple columns: %w", err)
+	}
+	if c.Names != nil && len(c.Names) != len(cols) {
+		return fmt.Errorf("invalid number of column names (got=%d, want=%d)", len(c.Names), len(cols))
+	}
+
+	tbl, err := csvutil.Create(name)
+	if err != nil {
+		return fmt.Errorf("could not create CSV file: %w", err)
+	}
+	defer tbl.Close()
+	tbl.Writer.Comma = c.Comma
+
+	if c.Header {
+		names := cols
+		if c.Names != nil {
+			names = c.Names
+		}
+		err = tbl.Writer.Write(names)
+		if err != nil {
+			return fmt.Errorf("could not write CSV header: %w", err)
+		}
+	}
+
+	var (
+		vals = make([]interface{}, len(cols))
+		args = make([]interface{}, len(cols))
+	)
+	for i := range args {
+		args[i] = &vals[i]
+	}
+	for rows.Next() {
+		err = rows.Scan(args...)
+		if err != nil {
+			return fmt.Errorf("could not scan n-tuple row: %w", err)
+		}
+		for i, v := range vals {
+			switch v := v.(type) {
+			case nil:
+				vals[i] = ""
+			case []byte:
+				vals[i] = string(v)
+			}
+		}
+		err = tbl.WriteRow(vals...)
+		if err != nil {
+			return fmt.Errorf("could not write CSV row: %w", err)
+		}
+	}
+	err = rows.Err()
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("could not iterate over n-tuple rows: %w", err)
+	}
+
+	err = tbl.Close()
+	if err != nil {
+		return fmt.Errorf("could not close CSV file: %w", err)
+	}
+
+	return nil
+}
+
 // Option configures the underlying sql.DB connection to the n-tuple.
 type Option func(c *csvdriver.Conn)
 
@@ -97,3 +205,11 @@ func Columns(names ...string) Option {
 		copy(c.Names, names)
 	}
 }
+
+// Sample configures the n-tuple to infer the type of the columns from
+// the first n rows of the CSV file.
+func Sample(n int) Option {
+	return func(c *csvdriver.Conn) {
+		c.Sample = n
+	}
+}
@@ -5,6 +5,8 @@
 package ntcsv_test
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -71,6 +73,19 @@ func TestOpen(t *testing.T) {
 				ntcsv.Comment('#'),
 			},
 		},
+		{"testdata/simple-with-header.csv.gz", `i, f, str`,
+			[]ntcsv.Option{
+				ntcsv.Header(),
+				ntcsv.Comma(';'),
+				ntcsv.Comment('#'),
+			},
+		},
+		{"testdata/simple.tsv.zst", `var1, var2, var3`,
+			[]ntcsv.Option{
+				ntcsv.Comment('#'),
+				ntcsv.Sample(5),
+			},
+		},
 		{"http://github.com/go-hep/hep/raw/main/hbook/ntup/ntcsv/testdata/simple-with-comment.csv", `v1, v2, v3`,
 			[]ntcsv.Option{
 				ntcsv.Comma(';'),
@@ -93,6 +108,67 @@ func TestOpen(t *testing.T) {
 	}
 }
 
+func TestWrite(t *testing.T) {
+	tmp := t.TempDir()
+
+	for _, test := range []struct {
+		name  string
+		query string
+		opts  []ntcsv.Option
+		want  string
+	}{
+		{
+			name:  "out.csv",
+			query: `var1, var2, var3`,
+			want:  "0,0,str-0\n1,1,str-1\n2,2,str-2\n",
+		},
+		{
+			name:  "out-header.csv",
+			query: `i, f, str`,
+			opts:  []ntcsv.Option{ntcsv.Header()},
+			want:  "i,f,str\n0,0,str-0\n1,1,str-1\n2,2,str-2\n",
+		},
+		{
+			name:  "out-columns.csv",
+			query: `v1, v2, v3`,
+			opts:  []ntcsv.Option{ntcsv.Header(), ntcsv.Columns("v1", "v2", "v3"), ntcsv.Comma(';')},
+			want:  "v1;v2;v3\n0;0;str-0\n1;1;str-1\n2;2;str-2\n",
+		},
+		{
+			name:  "out.tsv",
+			query: `var1, var2, var3`,
+			want:  "0\t0\tstr-0\n1\t1\tstr-1\n2\t2\tstr-2\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			nt, err := ntcsv.Open(
+				"testdata/simple-with-header.csv",
+				ntcsv.Header(), ntcsv.Comma(';'), ntcsv.Comment('#'),
+			)
+			if err != nil {
+				t.Fatalf("could not open n-tuple: %v", err)
+			}
+			defer nt.DB().Close()
+
+			fname := filepath.Join(tmp, test.name)
+			err = ntcsv.Write(fname, nt, test.opts...)
+			if err != nil {
+				t.Fatalf("could not write n-tuple: %v", err)
+			}
+
+			raw, err := os.ReadFile(fname)
+			if err != nil {
+				t.Fatalf("could not read CSV file: %v", err)
+			}
+			if got := string(raw); len(got) < len(test.want) || got[:len(test.want)] != test.want {
+				t.Fatalf("invalid CSV file:\ngot:\n%s\nwant prefix:\n%s", got, test.want)
+			}
+
+			testCSV(t, fname, test.query, test.opts...)
+		})
+	}
+}
+
 func testCSV(t *testing.T, name, query string, opts ...ntcsv.Option) {
 	nt, err := ntcsv.Open(name, opts...)
 	if err != nil {
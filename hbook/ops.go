@@ -77,7 +77,7 @@ func DivideH1D(num, den *H1D, opts ...DivOptions) (*S2D, error) {
 	return &s2d, nil
 }
 
-// DivOptions allows to customize the behaviour of DivideH1D
+// DivOptions allows to customize the behaviour of DivideH1D and DivideBinomial.
 type DivOptions func(c *divConfig)
 
 // divConfig type specifies the possible configurations
@@ -85,12 +85,19 @@ type DivOptions func(c *divConfig)
 type divConfig struct {
 	ignoreNaN  bool
 	replaceNaN float64
+
+	interval BinomialInterval // kind of binomial interval (DivideBinomial)
+	cl       float64          // confidence level of binomial intervals (DivideBinomial)
 }
 
 // newDivConfig function builds the default configuration
 // for DivideH1D() option.
 func newDivConfig() *divConfig {
-	return &divConfig{replaceNaN: math.NaN()}
+	return &divConfig{
+		replaceNaN: math.NaN(),
+		interval:   IntervalClopperPearson,
+		cl:         defaultConfLevel,
+	}
 }
 
 // DivIgnoreNaNs function configures DivideH1D to
@@ -126,11 +126,47 @@ func (p *P1D) XRMS() float64 {
 	return p.bng.dist.xRMS()
 }
 
+// XSkewness returns the skewness in X.
+// Overflows are not included in the computation.
+func (p *P1D) XSkewness() float64 {
+	return p.bng.xMoment(3)
+}
+
+// XKurtosis returns the excess kurtosis in X.
+// Overflows are not included in the computation.
+func (p *P1D) XKurtosis() float64 {
+	return excessKurtosis(p.bng.xMoment(4))
+}
+
 // Fill fills this histogram with x,y and weight w.
 func (p *P1D) Fill(x, y, w float64) {
 	p.bng.fill(x, y, w)
 }
 
+// SetKahanSum enables or disables the Kahan-compensated summation of
+// the weights when filling this profile histogram.
+//
+// Compensated summation reduces the floating point error accumulated in the
+// sums of weights (and of squared weights) of the bins and of the global
+// statistics, at the expense of slower fills.
+// It is mostly useful when filling billions of entries with small weights.
+//
+// Enabling compensated summation only affects subsequent fills.
+// The compensation terms are not persisted when the profile histogram is serialized.
+func (p *P1D) SetKahanSum(enable bool) {
+	switch {
+	case !enable:
+		p.bng.kahan = nil
+	case p.bng.kahan == nil:
+		p.bng.kahan = newKBinning2D(len(p.bng.bins))
+	}
+}
+
+// KahanSum returns whether this profile histogram uses Kahan-compensated summation.
+func (p *P1D) KahanSum() bool {
+	return p.bng.kahan != nil
+}
+
 // XMin returns the low edge of the X-axis of this profile histogram.
 func (p *P1D) XMin() float64 {
 	return p.bng.xMin()
@@ -564,6 +600,10 @@ type binningP1D struct {
 	outflows [2]Dist2D
 	xrange   Range
 	xstep    float64
+
+	// compensation terms, when Kahan summation is enabled.
+	// only the first 2 outflows are used.
+	kahan *kbinning2D
 }
 
 func newBinningP1D(n int, xmin, xmax float64) binningP1D {
@@ -596,6 +636,14 @@ func (bng *binningP1D) effEntries() float64 {
 	return bng.dist.EffEntries()
 }
 
+// xMoment returns the k-th standardized central moment in X.
+func (bng *binningP1D) xMoment(k float64) float64 {
+	return stdMoment(k, len(bng.bins), func(i int) (float64, *Dist1D) {
+		bin := &bng.bins[i]
+		return bin.XMid(), &bin.dist.X
+	})
+}
+
 // xMin returns the low edge of the X-axis
 func (bng *binningP1D) xMin() float64 {
 	return bng.xrange.Min
@@ -607,6 +655,10 @@ func (bng *binningP1D) xMax() float64 {
 }
 
 func (bng *binningP1D) fill(x, y, w float64) {
+	if bng.kahan != nil {
+		bng.kfill(x, y, w)
+		return
+	}
 	idx := bng.coordToIndex(x)
 	bng.dist.fill(x, y, w)
 	if idx < 0 {
@@ -616,6 +668,17 @@ func (bng *binningP1D) fill(x, y, w float64) {
 	bng.bins[idx].fill(x, y, w)
 }
 
+// kfill fills the binning using Kahan-compensated summation.
+func (bng *binningP1D) kfill(x, y, w float64) {
+	idx := bng.coordToIndex(x)
+	bng.kahan.dist.fill(&bng.dist, x, y, w)
+	if idx < 0 {
+		bng.kahan.outflows[-idx-1].fill(&bng.outflows[-idx-1], x, y, w)
+		return
+	}
+	bng.kahan.bins[idx].fill(&bng.bins[idx].dist, x, y, w)
+}
+
 // coordToIndex returns the bin index corresponding to the coordinate x.
 func (bng *binningP1D) coordToIndex(x float64) int {
 	switch {
@@ -637,6 +700,9 @@ func (bng *binningP1D) scaleW(f float64) {
 		bin := &bng.bins[i]
 		bin.scaleW(f)
 	}
+	if bng.kahan != nil {
+		bng.kahan.scaleW(f)
+	}
 }
 
 // Bins returns the slice of bins for this binning.
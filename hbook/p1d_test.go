@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"gonum.org/v1/gonum/floats/scalar"
 )
 
 func TestP1D(t *testing.T) {
@@ -211,3 +212,58 @@ func TestP1DSerialization(t *testing.T) {
 		}
 	}
 }
+
+func TestP1DMoments(t *testing.T) {
+	p := NewP1D(4, 0, 4)
+	for _, v := range []struct{ x, y, w float64 }{
+		{0.5, 3.5, 1}, {1.5, 2.5, 2}, {3.5, 0.5, 1}, {2.5, 1.5, 0.5},
+		{-1, 10, 1}, // underflows are not considered.
+	} {
+		p.Fill(v.x, v.y, v.w)
+	}
+
+	for _, tc := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"skewness", p.XSkewness(), 0.44271887242357316},
+		{"kurtosis", p.XKurtosis(), -1.0200000000000018},
+	} {
+		if !scalar.EqualWithinAbsOrRel(tc.got, tc.want, 1e-12, 1e-12) {
+			t.Errorf("invalid %s: got=%v, want=%v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+func TestP1DKahanSum(t *testing.T) {
+	const n = 1000000
+	var (
+		w    = 0.1
+		want = w * n // correctly rounded sum of n weights w.
+	)
+
+	p := NewP1D(10, 0, 10)
+	p.SetKahanSum(true)
+	ref := NewP1D(10, 0, 10)
+	for i := 0; i < n; i++ {
+		p.Fill(0.5, 1, w)
+		p.Fill(11, 1, w)
+		ref.Fill(0.5, 1, w)
+	}
+
+	if got := p.Binning().Bins()[0].SumW(); got != want {
+		t.Fatalf("invalid bin sumw: got=%v, want=%v", got, want)
+	}
+	if got := p.bng.outflows[1].SumW(); got != want {
+		t.Fatalf("invalid overflow sumw: got=%v, want=%v", got, want)
+	}
+	if got := ref.Binning().Bins()[0].SumW(); got == want {
+		t.Fatalf("naive summation should not be exact: got=%v", got)
+	}
+
+	p.Scale(0.5)
+	if got := p.Binning().Bins()[0].SumW(); got != 0.5*want {
+		t.Fatalf("invalid bin sumw after scale: got=%v, want=%v", got, 0.5*want)
+	}
+}
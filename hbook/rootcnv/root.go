@@ -3,9 +3,16 @@
 // license that can be found in the LICENSE file.
 
 // Package rootcnv provides tools to convert ROOT histograms and graphs to go-hep/hbook ones.
+//
+// ROOT metadata without an hbook equivalent (axis titles, bin labels and
+// the per-bin content of the outflow regions of 2-dim histograms) are
+// stored in the annotations of the hbook histograms, under the rhist.AnnXXX
+// keys, so that ROOT -> hbook -> ROOT conversions are lossless.
 package rootcnv
 
 import (
+	"math"
+
 	"go-hep.org/x/hep/groot/rhist"
 	"go-hep.org/x/hep/hbook"
 )
@@ -28,7 +35,11 @@ func H2D(h2 rhist.H2) *hbook.H2D {
 	return h2.(h2der).AsH2D()
 }
 
-// S2D creates a new S2D from a TGraph, TGraphErrors or TGraphAsymmErrors.
+// S2D creates a new S2D from a TGraph, TGraphErrors, TGraphAsymmErrors or
+// TGraphMultiErrors.
+//
+// The Y errors of a TGraphMultiErrors are combined according to the
+// errors summation mode of the graph.
 func S2D(g rhist.Graph) *hbook.S2D {
 	pts := make([]hbook.Point2D, g.Len())
 	for i := range pts {
@@ -52,6 +63,56 @@ func S2D(g rhist.Graph) *hbook.S2D {
 	return s2d
 }
 
+// H2DFromGraph2D creates a new H2D from a TGraph2D, with nx bins along X and
+// ny bins along Y, spanning the range of the graph points.
+// Each bin is filled with the Delaunay interpolation of the graph at the
+// center of that bin.
+// Like ROOT, a range without extent (e.g. all the points have the same X)
+// is widened by 1 on each side, and a graph without points spans [0, 1].
+//
+// The returned histogram can be displayed with hplot.NewH2D,
+// hplot.NewH2DContour or hplot.NewLego.
+func H2DFromGraph2D(g rhist.Graph2D, nx, ny int) *hbook.H2D {
+	var (
+		xmin = +math.MaxFloat64
+		xmax = -math.MaxFloat64
+		ymin = +math.MaxFloat64
+		ymax = -math.MaxFloat64
+	)
+	for i := 0; i < g.Len(); i++ {
+		x, y := g.XY(i)
+		xmin = math.Min(xmin, x)
+		xmax = math.Max(xmax, x)
+		ymin = math.Min(ymin, y)
+		ymax = math.Max(ymax, y)
+	}
+
+	xmin, xmax = graph2DRange(xmin, xmax)
+	ymin, ymax = graph2DRange(ymin, ymax)
+
+	h2 := hbook.NewH2D(nx, xmin, xmax, ny, ymin, ymax)
+	bins := h2.Binning.Bins
+	for i := range bins {
+		x, y := bins[i].XYMid()
+		h2.Fill(x, y, g.Interpolate(x, y))
+	}
+	h2.Annotation()["name"] = g.Name()
+	h2.Annotation()["title"] = g.Title()
+	return h2
+}
+
+// graph2DRange returns a valid histogram range from the [min, max] range
+// of the points of a TGraph2D.
+func graph2DRange(min, max float64) (float64, float64) {
+	switch {
+	case min > max:
+		return 0, 1
+	case min == max:
+		return min - 1, max + 1
+	}
+	return min, max
+}
+
 // FromH1D creates a new ROOT TH1D from a 1-dim hbook histogram.
 func FromH1D(h1 *hbook.H1D) *rhist.H1D {
 	return rhist.NewH1DFrom(h1)
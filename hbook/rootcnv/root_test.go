@@ -7,7 +7,11 @@ package rootcnv_test
 import (
 	"bytes"
 	"fmt"
+	"go-hep.org/x/hep/groot/root"
 	"log"
+	"math"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -18,8 +22,10 @@ import (
 	"go-hep.org/x/hep/hbook"
 	"go-hep.org/x/hep/hbook/rootcnv"
 	"go-hep.org/x/hep/hbook/yodacnv"
+	"go-hep.org/x/hep/hplot"
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot"
 )
 
 func ExampleH1D() {
@@ -294,6 +300,31 @@ func TestH2D(t *testing.T) {
 			name: "h2f",
 			want: []byte(`BEGIN YODA_HISTO2D_V2 /h2f
 Path: /h2f
+ROOTOutflows:
+    - 256
+    - 10558
+    - 105
+    - 105
+    - 66
+    - 66
+    - 267
+    - 11823
+    - 174
+    - 174
+    - 110
+    - 110
+    - 579
+    - 11709
+    - 368
+    - 368
+    - 217
+    - 217
+    - 1365
+    - 12077
+    - 1339
+    - 1339
+    - 812
+    - 812
 Title: h2f
 Type: Histo2D
 ---
@@ -320,6 +351,31 @@ END YODA_HISTO2D_V2
 			name: "h2d",
 			want: []byte(`BEGIN YODA_HISTO2D_V2 /h2d
 Path: /h2d
+ROOTOutflows:
+    - 256
+    - 10558
+    - 105
+    - 105
+    - 66
+    - 66
+    - 267
+    - 11823
+    - 174
+    - 174
+    - 110
+    - 110
+    - 579
+    - 11709
+    - 368
+    - 368
+    - 217
+    - 217
+    - 1365
+    - 12077
+    - 1339
+    - 1339
+    - 812
+    - 812
 Title: h2d
 Type: Histo2D
 ---
@@ -586,3 +642,284 @@ func TestFromS2D(t *testing.T) {
 		)
 	}
 }
+
+func TestH2DFromGraph2D(t *testing.T) {
+	fct := func(x, y float64) float64 { return 2*x - 3*y + 1 }
+
+	src := hbook.NewH2D(5, 0, 5, 4, -2, 2)
+	src.Annotation()["name"] = "g2d"
+	for i := range src.Binning.Bins {
+		x, y := src.Binning.Bins[i].XYMid()
+		src.Fill(x, y, fct(x, y))
+	}
+
+	h2 := rootcnv.H2DFromGraph2D(rhist.NewGraph2DFrom(src), 8, 6)
+
+	if got, want := h2.Name(), "g2d"; got != want {
+		t.Fatalf("invalid name: got=%q, want=%q", got, want)
+	}
+	if got, want := h2.XMin(), 0.5; got != want {
+		t.Fatalf("invalid x-min: got=%v, want=%v", got, want)
+	}
+	if got, want := h2.XMax(), 4.5; got != want {
+		t.Fatalf("invalid x-max: got=%v, want=%v", got, want)
+	}
+	if got, want := h2.YMin(), -1.5; got != want {
+		t.Fatalf("invalid y-min: got=%v, want=%v", got, want)
+	}
+	if got, want := h2.YMax(), 1.5; got != want {
+		t.Fatalf("invalid y-max: got=%v, want=%v", got, want)
+	}
+
+	for i := range h2.Binning.Bins {
+		bin := &h2.Binning.Bins[i]
+		x, y := bin.XYMid()
+		if got, want := bin.SumW(), fct(x, y); math.Abs(got-want) > 1e-12 {
+			t.Errorf("invalid bin content at (%v,%v): got=%v, want=%v", x, y, got, want)
+		}
+	}
+}
+
+func TestH2DFromGraph2DDegenerate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		fill   [][3]float64
+		xrange [2]float64
+		yrange [2]float64
+	}{
+		{
+			name:   "no-points",
+			xrange: [2]float64{0, 1},
+			yrange: [2]float64{0, 1},
+		},
+		{
+			name:   "one-point",
+			fill:   [][3]float64{{1.5, -0.5, 2}},
+			xrange: [2]float64{0.5, 2.5},
+			yrange: [2]float64{-1.5, 0.5},
+		},
+		{
+			name:   "same-x",
+			fill:   [][3]float64{{1.5, -1.5, 1}, {1.5, 0.5, 2}, {1.5, 1.5, 3}},
+			xrange: [2]float64{0.5, 2.5},
+			yrange: [2]float64{-1.5, 1.5},
+		},
+		{
+			name:   "same-y",
+			fill:   [][3]float64{{0.5, 1.5, 1}, {2.5, 1.5, 2}, {4.5, 1.5, 3}},
+			xrange: [2]float64{0.5, 4.5},
+			yrange: [2]float64{0.5, 2.5},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			src := hbook.NewH2D(5, 0, 5, 4, -2, 2)
+			for _, v := range tc.fill {
+				src.Fill(v[0], v[1], v[2])
+			}
+
+			h2 := rootcnv.H2DFromGraph2D(rhist.NewGraph2DFrom(src), 4, 4)
+			if got, want := [2]float64{h2.XMin(), h2.XMax()}, tc.xrange; got != want {
+				t.Fatalf("invalid x-range: got=%v, want=%v", got, want)
+			}
+			if got, want := [2]float64{h2.YMin(), h2.YMax()}, tc.yrange; got != want {
+				t.Fatalf("invalid y-range: got=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestH2DFromGraph2DPlot(t *testing.T) {
+	fct := func(x, y float64) float64 { return x*x + y*y }
+
+	src := hbook.NewH2D(10, -5, 5, 10, -5, 5)
+	for i := range src.Binning.Bins {
+		x, y := src.Binning.Bins[i].XYMid()
+		src.Fill(x, y, fct(x, y))
+	}
+	h2 := rootcnv.H2DFromGraph2D(rhist.NewGraph2DFrom(src), 20, 20)
+
+	for _, tc := range []struct {
+		name string
+		plt  plot.Plotter
+	}{
+		{"h2d", hplot.NewH2D(h2, nil)},
+		{"contour", hplot.NewH2DContour(h2, nil)},
+		{"lego", hplot.NewLego(h2)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := hplot.New()
+			p.Add(tc.plt)
+
+			err := hplot.Save(p, -1, -1, filepath.Join(t.TempDir(), tc.name+".png"))
+			if err != nil {
+				t.Fatalf("could not plot converted TGraph2D: %+v", err)
+			}
+		})
+	}
+}
+
+func TestRoundTripLossless(t *testing.T) {
+	tmp, err := os.MkdirTemp("", "rootcnv-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	f, err := groot.Open("testdata/gauss-h2.root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	obj, err := f.Get("h2d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2 := obj.(*rhist.H2D)
+
+	h1 := hbook.NewH1D(3, 0, 3)
+	h1.Fill(-1, 1)
+	h1.Fill(0.5, 2)
+	h1.Fill(1.5, 3)
+	h1.Fill(4, 4)
+	h1.Annotation()["name"] = "h1"
+	h1.Annotation()["title"] = "my title"
+	h1.Annotation()[rhist.AnnXLabel] = "x [GeV]"
+	h1.Annotation()[rhist.AnnYLabel] = "events"
+	h1.Annotation()[rhist.AnnXBinLabels] = []string{"a", "", "c"}
+
+	hh := hbook.NewH2D(2, 0, 2, 2, 0, 2)
+	hh.Fill(-1, 0.5, 1)
+	hh.Fill(-1, 1.5, 2)
+	hh.Fill(0.5, 3, 3)
+	hh.Fill(1.5, 3, 4)
+	hh.Fill(3, 3, 5)
+	hh.Fill(1.5, 1.5, 6)
+	hh.Annotation()["name"] = "h2"
+	hh.Annotation()[rhist.AnnXLabel] = "x"
+	hh.Annotation()[rhist.AnnYLabel] = "y"
+	hh.Annotation()[rhist.AnnZLabel] = "z"
+	hh.Annotation()[rhist.AnnYBinLabels] = []string{"lo", "hi"}
+
+	// hbook -> ROOT -> hbook -> YODA -> hbook -> ROOT
+	yoda := func(h *rhist.H2D) *rhist.H2D {
+		raw, err := rootcnv.H2D(h).MarshalYODA()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var o hbook.H2D
+		err = o.UnmarshalYODA(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rootcnv.FromH2D(&o)
+	}
+
+	for _, tc := range []struct {
+		name string
+		want root.Object
+	}{
+		{"h2d", h2},
+		{"h2d-yoda", yoda(h2)},
+		{"h1", rootcnv.FromH1D(h1)},
+		{"h2", rootcnv.FromH2D(hh)},
+		{"h2-yoda", yoda(rootcnv.FromH2D(hh))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fname := filepath.Join(tmp, tc.name+".root")
+			o, err := groot.Create(fname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer o.Close()
+
+			err = o.Put("h", tc.want)
+			if err != nil {
+				t.Fatalf("could not write histogram: %+v", err)
+			}
+			err = o.Close()
+			if err != nil {
+				t.Fatalf("could not close file: %+v", err)
+			}
+
+			f, err := groot.Open(fname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			obj, err := f.Get("h")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch h := obj.(type) {
+			case rhist.H1:
+				cmpH1(t, rootcnv.FromH1D(rootcnv.H1D(h)), tc.want.(*rhist.H1D))
+			case rhist.H2:
+				cmpH2(t, rootcnv.FromH2D(rootcnv.H2D(h)), tc.want.(*rhist.H2D))
+			}
+		})
+	}
+}
+
+type binLabeler interface {
+	BinLabels() []string
+}
+
+func cmpAxis(t *testing.T, name string, got, want rhist.Axis) {
+	t.Helper()
+	if got, want := got.Title(), want.Title(); got != want {
+		t.Fatalf("invalid %s title: got=%q, want=%q", name, got, want)
+	}
+	if got, want := got.(binLabeler).BinLabels(), want.(binLabeler).BinLabels(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid %s bin labels: got=%q, want=%q", name, got, want)
+	}
+}
+
+func cmpH1(t *testing.T, got, want *rhist.H1D) {
+	t.Helper()
+	for _, tc := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"name", got.Name(), want.Name()},
+		{"title", got.Title(), want.Title()},
+		{"entries", got.Entries(), want.Entries()},
+		{"stats", []float64{got.SumW(), got.SumW2(), got.SumWX(), got.SumWX2()}, []float64{want.SumW(), want.SumW2(), want.SumWX(), want.SumWX2()}},
+		{"sumw", got.Array().Data, want.Array().Data},
+		{"sumw2", got.SumW2s(), want.SumW2s()},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Fatalf("invalid %s:\ngot= %v\nwant=%v", tc.name, tc.got, tc.want)
+		}
+	}
+	cmpAxis(t, "x-axis", got.XAxis(), want.XAxis())
+}
+
+func cmpH2(t *testing.T, got, want *rhist.H2D) {
+	t.Helper()
+	stats := func(h *rhist.H2D) []float64 {
+		return []float64{
+			h.SumW(), h.SumW2(), h.SumWX(), h.SumWX2(),
+			h.SumWY(), h.SumWY2(), h.SumWXY(),
+		}
+	}
+	for _, tc := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"name", got.Name(), want.Name()},
+		{"title", got.Title(), want.Title()},
+		{"entries", got.Entries(), want.Entries()},
+		{"stats", stats(got), stats(want)},
+		{"sumw", got.Array().Data, want.Array().Data},
+		{"sumw2", got.SumW2s(), want.SumW2s()},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Fatalf("invalid %s:\ngot= %v\nwant=%v", tc.name, tc.got, tc.want)
+		}
+	}
+	cmpAxis(t, "x-axis", got.XAxis(), want.XAxis())
+	cmpAxis(t, "y-axis", got.YAxis(), want.YAxis())
+}
@@ -0,0 +1,255 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hbook
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/interp"
+)
+
+// LinearInterp returns a function evaluating the piecewise linear
+// interpolation of the data points of this scatter.
+//
+// Outside of the X-range of the scatter, the returned function evaluates
+// to the Y value of the nearest data point.
+// LinearInterp returns an error if the scatter has less than 2 data points
+// or if two data points share the same X coordinate.
+func (s *S2D) LinearInterp() (func(x float64) float64, error) {
+	xs, ys, err := s.interpData()
+	if err != nil {
+		return nil, err
+	}
+	var pl interp.PiecewiseLinear
+	err = pl.Fit(xs, ys)
+	if err != nil {
+		return nil, fmt.Errorf("hbook: could not fit linear interpolation: %w", err)
+	}
+	return pl.Predict, nil
+}
+
+// SplineInterp returns a function evaluating the natural cubic spline
+// interpolation of the data points of this scatter.
+//
+// Outside of the X-range of the scatter, the returned function evaluates
+// to the Y value of the nearest data point.
+// SplineInterp returns an error if the scatter has less than 2 data points
+// or if two data points share the same X coordinate.
+func (s *S2D) SplineInterp() (func(x float64) float64, error) {
+	xs, ys, err := s.interpData()
+	if err != nil {
+		return nil, err
+	}
+	var nc interp.NaturalCubic
+	err = nc.Fit(xs, ys)
+	if err != nil {
+		return nil, fmt.Errorf("hbook: could not fit cubic spline: %w", err)
+	}
+	return nc.Predict, nil
+}
+
+// Lowess returns a function evaluating the LOWESS (locally weighted
+// scatterplot smoothing) of the data points of this scatter.
+//
+// frac is the fraction of the data points, in (0, 1], used for each local
+// linear regression, and iter is the number of robustifying iterations
+// that down-weight outliers (3 is a typical value, 0 disables them).
+// The smoothed values are computed at each X coordinate of the scatter and
+// linearly interpolated in between.
+//
+// See:
+//  W. S. Cleveland, "Robust Locally Weighted Regression and Smoothing
+//  Scatterplots", J. Am. Stat. Assoc. 74 (1979) 829.
+func (s *S2D) Lowess(frac float64, iter int) (func(x float64) float64, error) {
+	switch {
+	case !(frac > 0 && frac <= 1):
+		return nil, fmt.Errorf("hbook: invalid LOWESS fraction %v", frac)
+	case iter < 0:
+		return nil, fmt.Errorf("hbook: invalid number of LOWESS iterations %d", iter)
+	}
+
+	xs, ys := s.sortedData()
+	n := len(xs)
+	if n < 2 {
+		return nil, fmt.Errorf("hbook: not enough data points for LOWESS (n=%d)", n)
+	}
+
+	r := int(math.Ceil(frac * float64(n)))
+	if r < 2 {
+		r = 2
+	}
+
+	var (
+		fit = make([]float64, n)
+		res = make([]float64, n)
+		rob = make([]float64, n)
+		wgt = make([]float64, n)
+	)
+	for i := range rob {
+		rob[i] = 1
+	}
+
+	for it := 0; it <= iter; it++ {
+		for i, x0 := range xs {
+			fit[i] = lowessFit(xs, ys, rob, wgt, x0, r)
+		}
+		if it == iter {
+			break
+		}
+
+		for i := range res {
+			res[i] = math.Abs(ys[i] - fit[i])
+		}
+		med := median(res)
+		if med == 0 {
+			break
+		}
+		for i := range rob {
+			u := res[i] / (6 * med)
+			switch {
+			case u < 1:
+				u = 1 - u*u
+				rob[i] = u * u
+			default:
+				rob[i] = 0
+			}
+		}
+	}
+
+	// remove duplicate X coordinates: they share the same smoothed value.
+	var (
+		ux = xs[:1]
+		uy = fit[:1]
+	)
+	for i := 1; i < n; i++ {
+		if xs[i] == ux[len(ux)-1] {
+			continue
+		}
+		ux = append(ux, xs[i])
+		uy = append(uy, fit[i])
+	}
+
+	if len(ux) < 2 {
+		y := uy[0]
+		return func(float64) float64 { return y }, nil
+	}
+
+	var pl interp.PiecewiseLinear
+	err := pl.Fit(ux, uy)
+	if err != nil {
+		return nil, fmt.Errorf("hbook: could not interpolate LOWESS values: %w", err)
+	}
+	return pl.Predict, nil
+}
+
+// lowessFit returns the value at x0 of the weighted linear regression over
+// the r nearest neighbours of x0, using tricube weights scaled by the
+// robustness weights rob.
+// xs must be sorted.
+func lowessFit(xs, ys, rob, wgt []float64, x0 float64, r int) float64 {
+	n := len(xs)
+
+	// find the window [lo, hi) of the r nearest neighbours of x0.
+	lo := sort.SearchFloat64s(xs, x0)
+	hi := lo
+	for hi-lo < r {
+		switch {
+		case lo == 0:
+			hi++
+		case hi == n:
+			lo--
+		case x0-xs[lo-1] <= xs[hi]-x0:
+			lo--
+		default:
+			hi++
+		}
+	}
+	h := math.Max(x0-xs[lo], xs[hi-1]-x0)
+
+	var sw, swx, swy float64
+	for i := lo; i < hi; i++ {
+		w := rob[i]
+		if h > 0 {
+			d := math.Abs(xs[i]-x0) / h
+			switch {
+			case d < 1:
+				d = 1 - d*d*d
+				w *= d * d * d
+			default:
+				w = 0
+			}
+		}
+		wgt[i] = w
+		sw += w
+		swx += w * xs[i]
+		swy += w * ys[i]
+	}
+	if sw == 0 {
+		// all neighbours were down-weighted: fall back on a plain average.
+		for i := lo; i < hi; i++ {
+			swy += ys[i]
+		}
+		return swy / float64(hi-lo)
+	}
+
+	xm := swx / sw
+	ym := swy / sw
+
+	var sxx, sxy float64
+	for i := lo; i < hi; i++ {
+		dx := xs[i] - xm
+		sxx += wgt[i] * dx * dx
+		sxy += wgt[i] * dx * (ys[i] - ym)
+	}
+	if sxx == 0 {
+		return ym
+	}
+	return ym + sxy/sxx*(x0-xm)
+}
+
+// median returns the median of vs, leaving vs untouched.
+func median(vs []float64) float64 {
+	o := make([]float64, len(vs))
+	copy(o, vs)
+	sort.Float64s(o)
+	n := len(o)
+	if n%2 == 1 {
+		return o[n/2]
+	}
+	return 0.5 * (o[n/2-1] + o[n/2])
+}
+
+// sortedData returns the X and Y coordinates of the data points of this
+// scatter, sorted by X.
+func (s *S2D) sortedData() (xs, ys []float64) {
+	pts := make([]Point2D, len(s.pts))
+	copy(pts, s.pts)
+	sort.Stable(points2D(pts))
+
+	xs = make([]float64, len(pts))
+	ys = make([]float64, len(pts))
+	for i, pt := range pts {
+		xs[i] = pt.X
+		ys[i] = pt.Y
+	}
+	return xs, ys
+}
+
+// interpData returns the X and Y coordinates of the data points of this
+// scatter, sorted by X, and checks they are suitable for interpolation.
+func (s *S2D) interpData() (xs, ys []float64, err error) {
+	xs, ys = s.sortedData()
+	if len(xs) < 2 {
+		return nil, nil, fmt.Errorf("hbook: not enough data points to interpolate (n=%d)", len(xs))
+	}
+	for i := 1; i < len(xs); i++ {
+		if xs[i] == xs[i-1] {
+			return nil, nil, fmt.Errorf("hbook: duplicate X coordinate %v", xs[i])
+		}
+	}
+	return xs, ys, nil
+}
@@ -0,0 +1,148 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hbook
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/floats/scalar"
+)
+
+func TestS2DInterp(t *testing.T) {
+	// unsorted on purpose.
+	s := NewS2DFrom([]float64{2, 0, 1, 3}, []float64{4, 0, 1, 9})
+
+	lin, err := s.LinearInterp()
+	if err != nil {
+		t.Fatalf("could not create linear interpolation: %+v", err)
+	}
+
+	spl, err := s.SplineInterp()
+	if err != nil {
+		t.Fatalf("could not create spline interpolation: %+v", err)
+	}
+
+	for _, tc := range []struct {
+		x   float64
+		lin float64
+	}{
+		{-1, 0},
+		{0, 0},
+		{0.5, 0.5},
+		{1, 1},
+		{1.5, 2.5},
+		{2, 4},
+		{2.25, 5.25},
+		{3, 9},
+		{4, 9},
+	} {
+		if got, want := lin(tc.x), tc.lin; !scalar.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+			t.Errorf("invalid linear interpolation at x=%v: got=%v, want=%v", tc.x, got, want)
+		}
+	}
+
+	for _, pt := range s.Points() {
+		if got, want := spl(pt.X), pt.Y; !scalar.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+			t.Errorf("spline does not go through data point x=%v: got=%v, want=%v", pt.X, got, want)
+		}
+	}
+
+	// a natural cubic spline over a straight line is that straight line.
+	s = NewS2DFrom([]float64{0, 1, 3, 4, 7}, []float64{1, 3, 7, 9, 15})
+	spl, err = s.SplineInterp()
+	if err != nil {
+		t.Fatalf("could not create spline interpolation: %+v", err)
+	}
+	for _, x := range []float64{0, 0.3, 1.7, 2.5, 5, 6.9} {
+		if got, want := spl(x), 2*x+1; !scalar.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+			t.Errorf("invalid spline interpolation at x=%v: got=%v, want=%v", x, got, want)
+		}
+	}
+}
+
+func TestS2DInterpErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		s    *S2D
+	}{
+		{"empty", NewS2D()},
+		{"one-point", NewS2DFrom([]float64{1}, []float64{2})},
+		{"duplicates", NewS2DFrom([]float64{1, 2, 2}, []float64{1, 2, 3})},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.s.LinearInterp()
+			if err == nil {
+				t.Fatalf("expected an error from linear interpolation")
+			}
+			_, err = tc.s.SplineInterp()
+			if err == nil {
+				t.Fatalf("expected an error from spline interpolation")
+			}
+		})
+	}
+}
+
+func TestS2DLowess(t *testing.T) {
+	var (
+		xs []float64
+		ys []float64
+	)
+	for i := 0; i < 50; i++ {
+		x := float64(i) / 5
+		xs = append(xs, x)
+		ys = append(ys, 0.5*x-1)
+	}
+
+	// LOWESS reproduces straight lines.
+	s := NewS2DFrom(xs, ys)
+	f, err := s.Lowess(0.3, 0)
+	if err != nil {
+		t.Fatalf("could not smooth: %+v", err)
+	}
+	for _, x := range []float64{-1, 0, 0.1, 2.5, 7.3, 9.8, 12} {
+		want := 0.5*math.Max(math.Min(x, xs[len(xs)-1]), 0) - 1
+		if got := f(x); !scalar.EqualWithinAbsOrRel(got, want, 1e-12, 1e-12) {
+			t.Errorf("invalid LOWESS value at x=%v: got=%v, want=%v", x, got, want)
+		}
+	}
+
+	// robustifying iterations remove the effect of outliers.
+	ys[25] += 100
+	s = NewS2DFrom(xs, ys)
+	f0, err := s.Lowess(0.3, 0)
+	if err != nil {
+		t.Fatalf("could not smooth: %+v", err)
+	}
+	f3, err := s.Lowess(0.3, 3)
+	if err != nil {
+		t.Fatalf("could not smooth: %+v", err)
+	}
+	var (
+		x    = xs[25]
+		want = 0.5*x - 1
+	)
+	if got := f0(x); math.Abs(got-want) < 1 {
+		t.Fatalf("non-robust LOWESS should be affected by outlier: got=%v, want=%v", got, want)
+	}
+	if got := f3(x); !scalar.EqualWithinAbsOrRel(got, want, 1e-6, 1e-6) {
+		t.Fatalf("invalid robust LOWESS value: got=%v, want=%v", got, want)
+	}
+
+	for _, tc := range []struct {
+		frac float64
+		iter int
+	}{
+		{0, 0},
+		{1.1, 0},
+		{math.NaN(), 0},
+		{0.5, -1},
+	} {
+		_, err := s.Lowess(tc.frac, tc.iter)
+		if err == nil {
+			t.Errorf("expected an error for frac=%v, iter=%d", tc.frac, tc.iter)
+		}
+	}
+}
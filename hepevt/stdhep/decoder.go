@@ -0,0 +1,188 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdhep
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"go-hep.org/x/hep/hepevt"
+)
+
+// Decoder decodes binary StdHep files.
+type Decoder struct {
+	r   io.Reader
+	hdr FileHeader
+}
+
+// NewDecoder creates a new Decoder, reading from the provided io.Reader.
+// NewDecoder reads the file header of the StdHep stream.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	dec := &Decoder{r: bufio.NewReader(r)}
+	blk, err := readBlock(dec.r)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("stdhep: could not read file header: %w", err)
+	}
+	if blk.id != blkFileHeader {
+		return nil, fmt.Errorf("stdhep: invalid file header block id (got=%d, want=%d)", blk.id, blkFileHeader)
+	}
+
+	var (
+		v1  = blk.version < "2.00"
+		hdr = &dec.hdr
+		br  = blk.r
+	)
+	hdr.Version = blk.version
+	hdr.Title = br.str()
+	hdr.Comment = br.str()
+	hdr.Date = br.str()
+	if !v1 {
+		hdr.CloseDate = br.str()
+	}
+	hdr.NumEvtsReq = br.i32()
+	hdr.NumEvts = br.i32()
+	hdr.FirstTable = br.i32()
+	hdr.DimTable = br.i32()
+	_ = br.i32() // number of blocks
+	if !v1 {
+		_ = br.i32() // number of n-tuples
+	}
+	hdr.BlockIDs = br.i32s()
+	hdr.BlockNames = br.strs()
+	if br.err != nil {
+		return nil, fmt.Errorf("stdhep: could not decode file header: %w", br.err)
+	}
+
+	return dec, nil
+}
+
+// Header returns the file header of the StdHep stream.
+func (dec *Decoder) Header() FileHeader {
+	return dec.hdr
+}
+
+// Decode decodes the next logical record from the underlying reader.
+// Decode returns io.EOF when no more records are available.
+func (dec *Decoder) Decode(evt *Event) error {
+	for {
+		blk, err := readBlock(dec.r)
+		if err != nil {
+			return err
+		}
+		switch blk.id {
+		case blkEventHeader:
+			return dec.decodeEvent(blk, evt)
+		case blkEventTable, blkSequentialHeader, blkNothing:
+			// event tables are only needed for random access.
+			continue
+		default:
+			return fmt.Errorf("stdhep: unexpected block id %d", blk.id)
+		}
+	}
+}
+
+func (dec *Decoder) decodeEvent(blk block, evt *Event) error {
+	br := blk.r
+	*evt = Event{
+		Number:  br.i32(),
+		Store:   br.i32(),
+		Run:     br.i32(),
+		Trigger: br.i32(),
+	}
+	n := br.i32()
+	if br.err != nil {
+		return fmt.Errorf("stdhep: could not decode event header: %w", br.err)
+	}
+	if n < 0 {
+		return fmt.Errorf("stdhep: invalid number of blocks (%d)", n)
+	}
+
+	for i := 0; i < n; i++ {
+		blk, err := readBlock(dec.r)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("stdhep: could not read block %d of event %d: %w", i, evt.Number, err)
+		}
+		switch blk.id {
+		case blkStdHep:
+			evt.HEPEVT, err = decodeHEPEVT(blk)
+		case blkStdHepBeg:
+			evt.BeginRun, err = decodeRun(blk)
+		case blkStdHepEnd:
+			evt.EndRun, err = decodeRun(blk)
+		default:
+			// skip unknown blocks.
+		}
+		if err != nil {
+			return fmt.Errorf("stdhep: could not decode block %d of event %d: %w", blk.id, evt.Number, err)
+		}
+	}
+	return nil
+}
+
+func decodeHEPEVT(blk block) (*hepevt.Event, error) {
+	var (
+		br  = blk.r
+		evt = &hepevt.Event{
+			Nevhep: br.i32(),
+			Nhep:   br.i32(),
+		}
+		isthep = br.i32s()
+		idhep  = br.i32s()
+		jmohep = br.i32s()
+		jdahep = br.i32s()
+		phep   = br.f64s()
+		vhep   = br.f64s()
+	)
+	if br.err != nil {
+		return nil, br.err
+	}
+
+	n := evt.Nhep
+	switch {
+	case n < 0,
+		len(isthep) != n, len(idhep) != n,
+		len(jmohep) != 2*n, len(jdahep) != 2*n,
+		len(phep) != 5*n, len(vhep) != 4*n:
+		return nil, fmt.Errorf("stdhep: inconsistent HEPEVT block sizes (nhep=%d)", n)
+	}
+
+	evt.Isthep = isthep
+	evt.Idhep = idhep
+	evt.Jmohep = make([][2]int, n)
+	evt.Jdahep = make([][2]int, n)
+	evt.Phep = make([][5]float64, n)
+	evt.Vhep = make([][4]float64, n)
+	for i := 0; i < n; i++ {
+		// convert 1-based indices to 0-based ones
+		evt.Jmohep[i] = [2]int{jmohep[2*i] - 1, jmohep[2*i+1] - 1}
+		evt.Jdahep[i] = [2]int{jdahep[2*i] - 1, jdahep[2*i+1] - 1}
+		copy(evt.Phep[i][:], phep[5*i:])
+		copy(evt.Vhep[i][:], vhep[4*i:])
+	}
+	return evt, nil
+}
+
+func decodeRun(blk block) (*Run, error) {
+	br := blk.r
+	run := &Run{
+		NumEvtsReq: br.i32(),
+		NumEvtsGen: br.i32(),
+		NumEvtsWrt: br.i32(),
+		Ecm:        br.f32(),
+		XSection:   br.f32(),
+		Seeds:      [2]float64{br.f64(), br.f64()},
+	}
+	if br.err != nil {
+		return nil, br.err
+	}
+	return run, nil
+}
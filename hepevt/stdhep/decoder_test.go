@@ -0,0 +1,343 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdhep_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"go-hep.org/x/hep/hepevt"
+	"go-hep.org/x/hep/hepevt/stdhep"
+)
+
+func TestDecoder(t *testing.T) {
+	evts := []hepevt.Event{
+		{
+			Nevhep: 1,
+			Nhep:   3,
+			Isthep: []int{3, 3, 1},
+			Idhep:  []int{2212, 2212, 22},
+			Jmohep: [][2]int{{-1, -1}, {-1, -1}, {0, 1}},
+			Jdahep: [][2]int{{2, 2}, {2, 2}, {-1, -1}},
+			Phep: [][5]float64{
+				{0, 0, +7000, 7000, 0.938},
+				{0, 0, -7000, 7000, 0.938},
+				{1, 2, 3, 3.7416573867739413, 0},
+			},
+			Vhep: [][4]float64{{}, {}, {1, 2, 3, 4}},
+		},
+		{
+			Nevhep: 2,
+			Nhep:   0,
+			Isthep: []int{},
+			Idhep:  []int{},
+			Jmohep: [][2]int{},
+			Jdahep: [][2]int{},
+			Phep:   [][5]float64{},
+			Vhep:   [][4]float64{},
+		},
+	}
+	beg := stdhep.Run{NumEvtsReq: 2, Ecm: 14000, XSection: 0.5, Seeds: [2]float64{42, 1337}}
+	end := stdhep.Run{NumEvtsReq: 2, NumEvtsGen: 3, NumEvtsWrt: 2, Ecm: 14000, XSection: 0.25, Seeds: [2]float64{43, 1338}}
+
+	raw := newFile(t, "2.00", evts, beg, end)
+
+	dec, err := stdhep.NewDecoder(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("could not create decoder: %+v", err)
+	}
+
+	hdr := dec.Header()
+	if got, want := hdr.Title, "go-hep test"; got != want {
+		t.Fatalf("invalid title: got=%q, want=%q", got, want)
+	}
+	if got, want := hdr.CloseDate, "today"; got != want {
+		t.Fatalf("invalid close date: got=%q, want=%q", got, want)
+	}
+	if got, want := hdr.NumEvts, 4; got != want {
+		t.Fatalf("invalid number of events: got=%d, want=%d", got, want)
+	}
+	if got, want := hdr.BlockNames, []string{"stdhep", "stdhepbeg", "stdhepend"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid block names: got=%q, want=%q", got, want)
+	}
+
+	var evt stdhep.Event
+	err = dec.Decode(&evt)
+	if err != nil {
+		t.Fatalf("could not decode begin run: %+v", err)
+	}
+	if evt.BeginRun == nil || evt.HEPEVT != nil || evt.EndRun != nil {
+		t.Fatalf("invalid begin run record: %+v", evt)
+	}
+	if got, want := *evt.BeginRun, beg; got != want {
+		t.Fatalf("invalid begin run:\ngot= %+v\nwant=%+v", got, want)
+	}
+
+	for i := range evts {
+		err = dec.Decode(&evt)
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+		if got, want := evt.Number, i+1; got != want {
+			t.Fatalf("invalid event number: got=%d, want=%d", got, want)
+		}
+		if got, want := evt.Run, 7; got != want {
+			t.Fatalf("invalid run number: got=%d, want=%d", got, want)
+		}
+		if evt.HEPEVT == nil {
+			t.Fatalf("event %d: missing HEPEVT block", i)
+		}
+		if got, want := *evt.HEPEVT, evts[i]; !reflect.DeepEqual(got, want) {
+			t.Fatalf("event %d: invalid HEPEVT:\ngot= %+v\nwant=%+v", i, got, want)
+		}
+	}
+
+	err = dec.Decode(&evt)
+	if err != nil {
+		t.Fatalf("could not decode end run: %+v", err)
+	}
+	if evt.EndRun == nil || evt.HEPEVT != nil || evt.BeginRun != nil {
+		t.Fatalf("invalid end run record: %+v", evt)
+	}
+	if got, want := *evt.EndRun, end; got != want {
+		t.Fatalf("invalid end run:\ngot= %+v\nwant=%+v", got, want)
+	}
+
+	err = dec.Decode(&evt)
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF, got=%+v", err)
+	}
+}
+
+func TestDecoderV1(t *testing.T) {
+	raw := newFile(t, "1.00", nil, stdhep.Run{}, stdhep.Run{})
+	dec, err := stdhep.NewDecoder(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("could not create decoder: %+v", err)
+	}
+	if got, want := dec.Header().CloseDate, ""; got != want {
+		t.Fatalf("invalid close date: got=%q, want=%q", got, want)
+	}
+	if got, want := dec.Header().Comment, "no comment"; got != want {
+		t.Fatalf("invalid comment: got=%q, want=%q", got, want)
+	}
+}
+
+func TestDecoderFail(t *testing.T) {
+	evts := []hepevt.Event{{
+		Nevhep: 1,
+		Nhep:   1,
+		Isthep: []int{1},
+		Idhep:  []int{22},
+		Jmohep: [][2]int{{-1, -1}},
+		Jdahep: [][2]int{{-1, -1}},
+		Phep:   [][5]float64{{1, 0, 0, 1, 0}},
+		Vhep:   [][4]float64{{}},
+	}}
+	raw := newFile(t, "2.00", evts, stdhep.Run{}, stdhep.Run{})
+
+	for i := 0; i < 64; i += 4 {
+		_, err := stdhep.NewDecoder(bytes.NewReader(raw[:i]))
+		if err == nil || errors.Is(err, io.EOF) {
+			t.Fatalf("truncated file header (%d bytes): invalid error %v", i, err)
+		}
+	}
+
+	dec, err := stdhep.NewDecoder(bytes.NewReader(raw[:len(raw)-4]))
+	if err != nil {
+		t.Fatalf("could not create decoder: %+v", err)
+	}
+	for {
+		var evt stdhep.Event
+		err = dec.Decode(&evt)
+		if err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		t.Fatalf("truncated stream should not yield io.EOF")
+	}
+
+	w := new(xdrWriter)
+	w.block(5, "1.00", func(w *xdrWriter) {})
+	_, err = stdhep.NewDecoder(bytes.NewReader(w.Bytes()))
+	if err == nil {
+		t.Fatalf("expected an error for an invalid file header")
+	}
+
+	w = new(xdrWriter)
+	w.i32(1)
+	w.i32(4)
+	_, err = stdhep.NewDecoder(bytes.NewReader(w.Bytes()))
+	if err == nil {
+		t.Fatalf("expected an error for an invalid block size")
+	}
+
+	w = new(xdrWriter)
+	w.header("2.00", 1)
+	w.block(4, "2.00", func(w *xdrWriter) {
+		w.i32s(1, 0, 7, 0, 1, 1, 0, 0)
+	})
+	w.block(101, "2.00", func(w *xdrWriter) {
+		w.i32s(1, 2)
+		w.array(1)
+		w.array(22)
+		w.array(0, 0)
+		w.array(0, 0)
+		w.f64array(1, 0, 0, 1, 0)
+		w.f64array(0, 0, 0, 0)
+	})
+	dec, err = stdhep.NewDecoder(bytes.NewReader(w.Bytes()))
+	if err != nil {
+		t.Fatalf("could not create decoder: %+v", err)
+	}
+	var evt stdhep.Event
+	err = dec.Decode(&evt)
+	if err == nil {
+		t.Fatalf("expected an error for an inconsistent HEPEVT block")
+	}
+}
+
+// newFile creates a StdHep stream holding a begin run record, the provided
+// events and an end run record.
+func newFile(t *testing.T, vers string, evts []hepevt.Event, beg, end stdhep.Run) []byte {
+	t.Helper()
+
+	w := new(xdrWriter)
+	w.header(vers, len(evts)+2)
+
+	// event table: only used for random access.
+	w.block(2, vers, func(w *xdrWriter) {
+		w.i32s(-1, len(evts)+2)
+		w.array(0, 1, 2, 3)
+	})
+
+	run := func(id int, run stdhep.Run) {
+		w.block(4, vers, func(w *xdrWriter) {
+			w.i32s(0, 0, 7, 0, 1, 1, 0, 0)
+			w.array(id)
+			w.array(0)
+		})
+		w.block(id, "1.00", func(w *xdrWriter) {
+			w.i32s(run.NumEvtsReq, run.NumEvtsGen, run.NumEvtsWrt)
+			w.f32(run.Ecm)
+			w.f32(run.XSection)
+			w.f64(run.Seeds[0])
+			w.f64(run.Seeds[1])
+		})
+	}
+
+	run(106, beg)
+	for i, evt := range evts {
+		w.block(4, vers, func(w *xdrWriter) {
+			w.i32s(i+1, 0, 7, 0, 2, 2, 0, 0)
+			w.array(101, 999)
+			w.array(0, 0)
+		})
+		w.block(101, "2.00", func(w *xdrWriter) {
+			w.i32s(evt.Nevhep, evt.Nhep)
+			var (
+				jmo  []int
+				jda  []int
+				phep []float64
+				vhep []float64
+			)
+			for i := 0; i < evt.Nhep; i++ {
+				jmo = append(jmo, evt.Jmohep[i][0]+1, evt.Jmohep[i][1]+1)
+				jda = append(jda, evt.Jdahep[i][0]+1, evt.Jdahep[i][1]+1)
+				phep = append(phep, evt.Phep[i][:]...)
+				vhep = append(vhep, evt.Vhep[i][:]...)
+			}
+			w.array(evt.Isthep...)
+			w.array(evt.Idhep...)
+			w.array(jmo...)
+			w.array(jda...)
+			w.f64array(phep...)
+			w.f64array(vhep...)
+		})
+		// unknown block, to be skipped.
+		w.block(999, "1.00", func(w *xdrWriter) {
+			w.i32s(1, 2, 3)
+		})
+	}
+	run(107, end)
+
+	return w.Bytes()
+}
+
+// xdrWriter writes MCFio blocks.
+type xdrWriter struct {
+	bytes.Buffer
+}
+
+func (w *xdrWriter) header(vers string, nevts int) {
+	w.block(1, vers, func(w *xdrWriter) {
+		w.str("go-hep test")
+		w.str("no comment")
+		w.str("now")
+		if vers >= "2.00" {
+			w.str("today")
+		}
+		w.i32s(nevts, nevts, 0, 100, 3)
+		if vers >= "2.00" {
+			w.i32(0)
+		}
+		w.array(101, 106, 107)
+		w.i32(3)
+		w.str("stdhep")
+		w.str("stdhepbeg")
+		w.str("stdhepend")
+	})
+}
+
+func (w *xdrWriter) block(id int, vers string, f func(w *xdrWriter)) {
+	body := new(xdrWriter)
+	body.str(vers)
+	f(body)
+	w.i32(id)
+	w.i32(8 + body.Len())
+	w.Write(body.Bytes())
+}
+
+func (w *xdrWriter) i32(v int) {
+	_ = binary.Write(w, binary.BigEndian, int32(v))
+}
+
+func (w *xdrWriter) i32s(vs ...int) {
+	for _, v := range vs {
+		w.i32(v)
+	}
+}
+
+func (w *xdrWriter) f32(v float64) {
+	_ = binary.Write(w, binary.BigEndian, math.Float32bits(float32(v)))
+}
+
+func (w *xdrWriter) f64(v float64) {
+	_ = binary.Write(w, binary.BigEndian, math.Float64bits(v))
+}
+
+func (w *xdrWriter) str(s string) {
+	w.i32(len(s))
+	w.WriteString(s)
+	w.Write(make([]byte, (4-len(s)%4)%4))
+}
+
+func (w *xdrWriter) array(vs ...int) {
+	w.i32(len(vs))
+	w.i32s(vs...)
+}
+
+func (w *xdrWriter) f64array(vs ...float64) {
+	w.i32(len(vs))
+	for _, v := range vs {
+		w.f64(v)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stdhep provides read access to binary StdHep files.
+//
+// StdHep files are written with the MCFio library as a sequence of XDR
+// blocks:
+//   - a file header, describing the content of the file,
+//   - event tables, indexing the following events,
+//   - event headers, each followed by the data blocks of a logical record.
+//
+// Logical records hold either a HEPEVT event or begin/end of run
+// informations.
+// Blocks that are not understood by this package (e.g. multiple-interaction
+// or LHA blocks) are skipped.
+package stdhep // import "go-hep.org/x/hep/hepevt/stdhep"
+
+import (
+	"go-hep.org/x/hep/hepevt"
+)
+
+// MCFio block identifiers.
+const (
+	blkFileHeader       = 1
+	blkEventTable       = 2
+	blkSequentialHeader = 3
+	blkEventHeader      = 4
+	blkNothing          = 5
+
+	blkStdHep         = 101
+	blkOffTrackArrays = 102
+	blkOffTrackStruct = 103
+	blkTraceArrays    = 104
+	blkStdHepM        = 105
+	blkStdHepBeg      = 106
+	blkStdHepEnd      = 107
+	blkStdHepCXX      = 108
+)
+
+// FileHeader describes the content of a StdHep file.
+type FileHeader struct {
+	Version    string   // MCFio version of the file header
+	Title      string   // title of the file
+	Comment    string   // comment
+	Date       string   // creation date
+	CloseDate  string   // closing date (empty for version 1 files)
+	NumEvtsReq int      // number of events expected
+	NumEvts    int      // number of events written
+	FirstTable int      // location of the first event table
+	DimTable   int      // number of events per event table
+	BlockIDs   []int    // identifiers of the blocks stored in the file
+	BlockNames []string // names of the blocks stored in the file
+}
+
+// Run holds the begin or end of run informations of a StdHep file.
+type Run struct {
+	NumEvtsReq int     // number of events requested
+	NumEvtsGen int     // number of events generated
+	NumEvtsWrt int     // number of events written
+	Ecm        float64 // center-of-mass energy, in GeV
+	XSection   float64 // cross-section, in mb
+	Seeds      [2]float64
+}
+
+// Event is a StdHep logical record.
+//
+// An Event holds either a HEPEVT event or begin/end of run informations.
+type Event struct {
+	Number  int // event number
+	Store   int // store number
+	Run     int // run number
+	Trigger int // trigger mask
+
+	HEPEVT   *hepevt.Event // HEPEVT event, nil if the record holds none
+	BeginRun *Run          // begin of run informations, nil if the record holds none
+	EndRun   *Run          // end of run informations, nil if the record holds none
+}
@@ -0,0 +1,145 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stdhep
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// xdrReader decodes XDR data from a byte slice.
+// The first error encountered is sticky.
+type xdrReader struct {
+	p   []byte
+	c   int
+	err error
+}
+
+func (r *xdrReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.p)-r.c {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	p := r.p[r.c : r.c+n]
+	r.c += n
+	return p
+}
+
+func (r *xdrReader) u32() uint32 {
+	p := r.next(4)
+	if p == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(p)
+}
+
+func (r *xdrReader) i32() int {
+	return int(int32(r.u32()))
+}
+
+func (r *xdrReader) f32() float64 {
+	return float64(math.Float32frombits(r.u32()))
+}
+
+func (r *xdrReader) f64() float64 {
+	p := r.next(8)
+	if p == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(p))
+}
+
+func (r *xdrReader) str() string {
+	n := int(r.u32())
+	p := r.next(n)
+	r.next((4 - n%4) % 4)
+	return string(p)
+}
+
+// len reads the length of an array holding elements of sz bytes.
+func (r *xdrReader) len(sz int) int {
+	n := int(r.u32())
+	if r.err == nil && n > (len(r.p)-r.c)/sz {
+		r.err = fmt.Errorf("stdhep: invalid XDR array length (%d)", n)
+		return 0
+	}
+	return n
+}
+
+func (r *xdrReader) i32s() []int {
+	o := make([]int, r.len(4))
+	for i := range o {
+		o[i] = r.i32()
+	}
+	return o
+}
+
+func (r *xdrReader) f64s() []float64 {
+	o := make([]float64, r.len(8))
+	for i := range o {
+		o[i] = r.f64()
+	}
+	return o
+}
+
+func (r *xdrReader) strs() []string {
+	o := make([]string, r.len(4))
+	for i := range o {
+		o[i] = r.str()
+	}
+	return o
+}
+
+// block is an MCFio block.
+type block struct {
+	id      int
+	version string
+	r       *xdrReader // block payload, after the version
+}
+
+// maxBlockSize is the maximum size of an MCFio block.
+const maxBlockSize = 1 << 30
+
+// readBlock reads a full MCFio block from r.
+//
+// A block starts with its identifier and its total size in bytes (including
+// the identifier and the size words), followed by a version string.
+func readBlock(r io.Reader) (block, error) {
+	var hdr [8]byte
+	_, err := io.ReadFull(r, hdr[:])
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return block{}, fmt.Errorf("stdhep: could not read block header: %w", err)
+		}
+		return block{}, err
+	}
+	var (
+		id   = int(int32(binary.BigEndian.Uint32(hdr[:4])))
+		ntot = int64(int32(binary.BigEndian.Uint32(hdr[4:])))
+	)
+	if ntot < int64(len(hdr)) || ntot > maxBlockSize {
+		return block{}, fmt.Errorf("stdhep: invalid block size %d (id=%d)", ntot, id)
+	}
+	p := make([]byte, ntot-int64(len(hdr)))
+	_, err = io.ReadFull(r, p)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return block{}, fmt.Errorf("stdhep: could not read block (id=%d): %w", id, err)
+	}
+
+	blk := block{id: id, r: &xdrReader{p: p}}
+	blk.version = blk.r.str()
+	if blk.r.err != nil {
+		return blk, fmt.Errorf("stdhep: could not read block version (id=%d): %w", id, blk.r.err)
+	}
+	return blk, nil
+}
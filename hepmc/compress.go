@@ -0,0 +1,243 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression describes the compression algorithm applied to a HepMC stream.
+type Compression int
+
+const (
+	NoCompression Compression = iota // uncompressed stream
+	Gzip                             // gzip compressed stream (.gz)
+	Bzip2                            // bzip2 compressed stream (.bz2)
+	Xz                               // xz compressed stream (.xz)
+	Zstd                             // zstd compressed stream (.zst)
+)
+
+func (c Compression) String() string {
+	switch c {
+	case NoCompression:
+		return "none"
+	case Gzip:
+		return "gzip"
+	case Bzip2:
+		return "bzip2"
+	case Xz:
+		return "xz"
+	case Zstd:
+		return "zstd"
+	}
+	return fmt.Sprintf("Compression(%d)", int(c))
+}
+
+// CompressionFromName returns the compression algorithm associated with
+// the extension of the provided file name (e.g. "evts.hepmc.gz").
+func CompressionFromName(fname string) Compression {
+	switch {
+	case strings.HasSuffix(fname, ".gz"):
+		return Gzip
+	case strings.HasSuffix(fname, ".bz2"):
+		return Bzip2
+	case strings.HasSuffix(fname, ".xz"):
+		return Xz
+	case strings.HasSuffix(fname, ".zst"), strings.HasSuffix(fname, ".zstd"):
+		return Zstd
+	}
+	return NoCompression
+}
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// compressionOf inspects the first bytes of the provided reader and
+// returns the compression algorithm of the underlying stream.
+func compressionOf(r *bufio.Reader) (Compression, error) {
+	hdr, err := r.Peek(len(xzMagic))
+	if err != nil && err != io.EOF {
+		return NoCompression, err
+	}
+
+	switch {
+	case bytes.HasPrefix(hdr, gzipMagic):
+		return Gzip, nil
+	case bytes.HasPrefix(hdr, bzip2Magic):
+		return Bzip2, nil
+	case bytes.HasPrefix(hdr, xzMagic):
+		return Xz, nil
+	case bytes.HasPrefix(hdr, zstdMagic):
+		return Zstd, nil
+	}
+	return NoCompression, nil
+}
+
+// NewReader returns a reader that transparently decompresses the gzip,
+// bzip2, xz or zstd stream read from r.
+// The compression algorithm is detected from the first bytes of the stream.
+// Uncompressed streams are returned as is.
+//
+// Closing the returned reader does not close the underlying io.Reader.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	c, err := compressionOf(br)
+	if err != nil {
+		return nil, fmt.Errorf("hepmc: could not detect stream compression: %w", err)
+	}
+
+	switch c {
+	case Gzip:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc: could not create gzip reader: %w", err)
+		}
+		return zr, nil
+	case Bzip2:
+		return io.NopCloser(bzip2.NewReader(br)), nil
+	case Xz:
+		zr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc: could not create xz reader: %w", err)
+		}
+		return io.NopCloser(zr), nil
+	case Zstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc: could not create zstd reader: %w", err)
+		}
+		return zstdReader{zr}, nil
+	}
+	return io.NopCloser(br), nil
+}
+
+type zstdReader struct {
+	*zstd.Decoder
+}
+
+func (z zstdReader) Close() error {
+	z.Decoder.Close()
+	return nil
+}
+
+// NewWriter returns a writer that compresses data written to it into w,
+// using the provided compression algorithm.
+// Compressing with bzip2 is not supported.
+//
+// Closing the returned writer flushes any pending compressed data but
+// does not close the underlying io.Writer.
+func NewWriter(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case NoCompression:
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Xz:
+		zw, err := xz.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc: could not create xz writer: %w", err)
+		}
+		return zw, nil
+	case Zstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc: could not create zstd writer: %w", err)
+		}
+		return zw, nil
+	}
+	return nil, fmt.Errorf("hepmc: %v compression not supported for writing", c)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// Open opens the named HepMC file for reading, transparently decompressing
+// gzip, bzip2, xz and zstd compressed files.
+func Open(fname string) (io.ReadCloser, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("hepmc: could not open %q: %w", fname, err)
+	}
+	return &file{f: f, c: r, r: r}, nil
+}
+
+// Create creates the named HepMC file for writing.
+// The compression algorithm is inferred from the extension of the file
+// name (e.g. "evts.hepmc.gz" or "evts.hepmc.zst").
+//
+// The returned writer must be closed to flush any pending compressed data.
+func Create(fname string) (io.WriteCloser, error) {
+	f, err := os.Create(fname)
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(f)
+	w, err := NewWriter(bw, CompressionFromName(fname))
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(fname)
+		return nil, fmt.Errorf("hepmc: could not create %q: %w", fname, err)
+	}
+	return &file{f: f, c: w, w: w, bw: bw}, nil
+}
+
+// file is a HepMC file, possibly compressed.
+type file struct {
+	f  *os.File
+	c  io.Closer // (de)compressor
+	r  io.Reader
+	w  io.Writer
+	bw *bufio.Writer
+}
+
+func (f *file) Read(p []byte) (int, error)  { return f.r.Read(p) }
+func (f *file) Write(p []byte) (int, error) { return f.w.Write(p) }
+
+// Close closes the (de)compressor, flushes pending data and closes the
+// underlying file.
+func (f *file) Close() error {
+	if f.f == nil {
+		return nil
+	}
+	defer func() { f.f = nil }()
+
+	err := f.c.Close()
+	if err != nil {
+		_ = f.f.Close()
+		return fmt.Errorf("hepmc: could not close compressor: %w", err)
+	}
+
+	if f.bw != nil {
+		err = f.bw.Flush()
+		if err != nil {
+			_ = f.f.Close()
+			return fmt.Errorf("hepmc: could not flush file: %w", err)
+		}
+	}
+
+	return f.f.Close()
+}
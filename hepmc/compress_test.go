@@ -0,0 +1,172 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-hep.org/x/hep/hepmc"
+)
+
+func TestCompressionFromName(t *testing.T) {
+	for _, tc := range []struct {
+		fname string
+		want  hepmc.Compression
+	}{
+		{"evts.hepmc", hepmc.NoCompression},
+		{"evts.hepmc.gz", hepmc.Gzip},
+		{"evts.hepmc.bz2", hepmc.Bzip2},
+		{"evts.hepmc.xz", hepmc.Xz},
+		{"evts.hepmc.zst", hepmc.Zstd},
+		{"evts.hepmc.zstd", hepmc.Zstd},
+	} {
+		t.Run(tc.fname, func(t *testing.T) {
+			got := hepmc.CompressionFromName(tc.fname)
+			if got != tc.want {
+				t.Fatalf("invalid compression: got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompressedRW(t *testing.T) {
+	want, err := os.ReadFile("testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmp := t.TempDir()
+	for _, ext := range []string{"", ".gz", ".xz", ".zst"} {
+		t.Run("hepmc"+ext, func(t *testing.T) {
+			fname := filepath.Join(tmp, "out.hepmc"+ext)
+			w, err := hepmc.Create(fname)
+			if err != nil {
+				t.Fatalf("could not create output file: %+v", err)
+			}
+			defer w.Close()
+
+			n := recode(t, w, bytes.NewReader(want))
+			if n != 6 {
+				t.Fatalf("invalid number of events: got=%d, want=%d", n, 6)
+			}
+
+			err = w.Close()
+			if err != nil {
+				t.Fatalf("could not close output file: %+v", err)
+			}
+
+			raw, err := os.ReadFile(fname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ext != "" && bytes.Equal(raw, want) {
+				t.Fatalf("output file was not compressed")
+			}
+
+			// read back, directly and through Open.
+			f, err := os.Open(fname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			got := new(bytes.Buffer)
+			_ = recode(t, got, f)
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Fatalf("round-trip failed")
+			}
+
+			r, err := hepmc.Open(fname)
+			if err != nil {
+				t.Fatalf("could not open file: %+v", err)
+			}
+			defer r.Close()
+
+			raw, err = io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("could not read file: %+v", err)
+			}
+			if !bytes.Equal(raw, want) {
+				t.Fatalf("invalid decompressed content")
+			}
+
+			err = r.Close()
+			if err != nil {
+				t.Fatalf("could not close file: %+v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeBzip2(t *testing.T) {
+	want, err := os.ReadFile("testdata/small.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open("testdata/small.hepmc.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got := new(bytes.Buffer)
+	n := recode(t, got, f)
+	if n != 1 {
+		t.Fatalf("invalid number of events: got=%d, want=%d", n, 1)
+	}
+	if !bytes.Equal(got.Bytes(), want) {
+		t.Fatalf("round-trip failed:\ngot:\n%s\nwant:\n%s\n", got.Bytes(), want)
+	}
+}
+
+func TestCreateBzip2(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "out.hepmc.bz2")
+	_, err := hepmc.Create(fname)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	_, err = os.Stat(fname)
+	if !os.IsNotExist(err) {
+		t.Fatalf("output file should have been removed: %+v", err)
+	}
+}
+
+// recode decodes all the events from r and encodes them into w.
+func recode(t *testing.T, w io.Writer, r io.Reader) int {
+	t.Helper()
+
+	dec := hepmc.NewDecoder(r)
+	enc := hepmc.NewEncoder(w)
+	n := 0
+	for {
+		var evt hepmc.Event
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", n, err)
+		}
+		err = enc.Encode(&evt)
+		if err != nil {
+			t.Fatalf("could not encode event %d: %+v", n, err)
+		}
+		err = hepmc.Delete(&evt)
+		if err != nil {
+			t.Fatalf("could not delete event %d: %+v", n, err)
+		}
+		n++
+	}
+	err := enc.Close()
+	if err != nil {
+		t.Fatalf("could not close encoder: %+v", err)
+	}
+	return n
+}
@@ -27,20 +27,32 @@ type Decoder struct {
 	sigProcBc int // barcode of signal vertex
 	bp1       int // barcode of beam1
 	bp2       int // barcode of beam2
+
+	stats Stats // run-level informations of the decoded events
 }
 
 // NewDecoder returns a new hepmc Decoder that reads from the io.Reader.
+//
+// gzip, bzip2, xz and zstd compressed streams are transparently
+// decompressed.
 func NewDecoder(r io.Reader) *Decoder {
 	dec := &Decoder{
 		stream: make(chan rstream),
 	}
-	go dec.readlines(bufio.NewReader(r))
+	go dec.readlines(r)
 
 	return dec
 }
 
-func (dec *Decoder) readlines(r *bufio.Reader) {
-	s := bufio.NewScanner(r)
+func (dec *Decoder) readlines(r io.Reader) {
+	rc, err := NewReader(r)
+	if err != nil {
+		dec.stream <- rstream{err: err}
+		return
+	}
+	defer rc.Close()
+
+	s := bufio.NewScanner(rc)
 	for s.Scan() {
 		dec.stream <- rstream{
 			tokens: newtokens(strings.Split(s.Text(), " ")),
@@ -48,7 +60,7 @@ func (dec *Decoder) readlines(r *bufio.Reader) {
 		}
 	}
 
-	err := s.Err()
+	err = s.Err()
 	if err == nil {
 		err = io.EOF
 	}
@@ -101,6 +113,12 @@ func (dec *Decoder) Decode(evt *Event) error {
 		}
 	}
 
+	// cross-section, heavy-ion and PDF informations are optional:
+	// do not carry over the ones of a previously decoded event.
+	evt.CrossSection = nil
+	evt.HeavyIon = nil
+	evt.PdfInfo = nil
+
 	nVtx := 0
 loop:
 	for {
@@ -242,9 +260,17 @@ loop:
 		}
 		sort.Sort(Particles(vtx.ParticlesIn))
 	}
+
+	dec.stats.Add(evt)
 	return err
 }
 
+// Stats returns the run-level informations accumulated over all the events
+// decoded so far.
+func (dec *Decoder) Stats() Stats {
+	return dec.stats
+}
+
 func (dec *Decoder) findFileType() error {
 
 	for {
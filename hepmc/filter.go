@@ -0,0 +1,328 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// FilterFunc inspects, and possibly modifies, an event and reports
+// whether it should be kept.
+type FilterFunc func(evt *Event) (bool, error)
+
+// Filter reads all the events from dec, applies f to each of them and
+// writes the kept events to enc.
+// Filter returns the number of events read and the number of events kept.
+//
+// Filter does not close the encoder.
+func Filter(dec *Decoder, enc *Encoder, f FilterFunc) (nread, nkept int, err error) {
+	for {
+		var evt Event
+		err = dec.Decode(&evt)
+		if err == io.EOF {
+			return nread, nkept, nil
+		}
+		if err != nil {
+			return nread, nkept, fmt.Errorf("hepmc: could not decode event %d: %w", nread, err)
+		}
+		nread++
+
+		keep, err := f(&evt)
+		if err != nil {
+			return nread, nkept, fmt.Errorf("hepmc: could not filter event %d: %w", evt.EventNumber, err)
+		}
+		if keep {
+			err = enc.Encode(&evt)
+			if err != nil {
+				return nread, nkept, fmt.Errorf("hepmc: could not encode event %d: %w", evt.EventNumber, err)
+			}
+			nkept++
+		}
+
+		err = Delete(&evt)
+		if err != nil {
+			return nread, nkept, fmt.Errorf("hepmc: could not delete event %d: %w", evt.EventNumber, err)
+		}
+	}
+}
+
+// All returns a filter keeping events kept by all the provided filters.
+// Filters are applied in order, so events may be modified by a filter
+// before being inspected by the next one.
+// Evaluation stops at the first filter rejecting the event.
+func All(fs ...FilterFunc) FilterFunc {
+	return func(evt *Event) (bool, error) {
+		for _, f := range fs {
+			keep, err := f(evt)
+			if err != nil || !keep {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// Any returns a filter keeping events kept by any of the provided filters.
+// Evaluation stops at the first filter accepting the event.
+func Any(fs ...FilterFunc) FilterFunc {
+	return func(evt *Event) (bool, error) {
+		for _, f := range fs {
+			keep, err := f(evt)
+			if err != nil {
+				return false, err
+			}
+			if keep {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// Not returns a filter keeping events rejected by f.
+func Not(f FilterFunc) FilterFunc {
+	return func(evt *Event) (bool, error) {
+		keep, err := f(evt)
+		if err != nil {
+			return false, err
+		}
+		return !keep, nil
+	}
+}
+
+// AtLeast returns a filter keeping events with at least n particles
+// satisfying the provided selection.
+func AtLeast(n int, sel ParticleSelector) FilterFunc {
+	return func(evt *Event) (bool, error) {
+		if n <= 0 {
+			return true, nil
+		}
+		found := 0
+		for _, p := range evt.Particles {
+			if !sel(p) {
+				continue
+			}
+			found++
+			if found >= n {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// PruneParticles returns a filter removing the particles satisfying the
+// provided selection from the event.
+// All events are kept.
+//
+// See RemoveParticles for details.
+func PruneParticles(sel ParticleSelector) FilterFunc {
+	return func(evt *Event) (bool, error) {
+		err := RemoveParticles(evt, sel)
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// PruneStatus returns a filter removing the particles with the provided
+// status codes from the event.
+// All events are kept.
+func PruneStatus(codes ...int) FilterFunc {
+	return PruneParticles(WithStatus(codes...))
+}
+
+// KeepFinalState returns a filter slimming events down to their beam
+// and final state particles.
+// All events are kept.
+func KeepFinalState() FilterFunc {
+	return PruneParticles(ParticleSelector(IsFinalState).Not())
+}
+
+// UseUnits returns a filter converting events to the provided momentum
+// and length units.
+// All events are kept.
+func UseUnits(mu MomentumUnit, lu LengthUnit) FilterFunc {
+	return func(evt *Event) (bool, error) {
+		err := ConvertUnits(evt, mu, lu)
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// ParticleSelector reports whether a particle is selected.
+type ParticleSelector func(p *Particle) bool
+
+// And returns a selector selecting particles selected by both sel and o.
+func (sel ParticleSelector) And(o ParticleSelector) ParticleSelector {
+	return func(p *Particle) bool {
+		return sel(p) && o(p)
+	}
+}
+
+// Or returns a selector selecting particles selected by either sel or o.
+func (sel ParticleSelector) Or(o ParticleSelector) ParticleSelector {
+	return func(p *Particle) bool {
+		return sel(p) || o(p)
+	}
+}
+
+// Not returns a selector selecting particles rejected by sel.
+func (sel ParticleSelector) Not() ParticleSelector {
+	return func(p *Particle) bool {
+		return !sel(p)
+	}
+}
+
+// IsFinalState reports whether p is a final state particle, ie: a
+// particle with status 1 and no decay vertex.
+func IsFinalState(p *Particle) bool {
+	return p.Status == 1 && p.EndVertex == nil
+}
+
+// WithStatus returns a selector selecting particles with any of the
+// provided status codes.
+func WithStatus(codes ...int) ParticleSelector {
+	return func(p *Particle) bool {
+		for _, code := range codes {
+			if p.Status == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithPdgID returns a selector selecting particles with any of the
+// provided PDG IDs.
+func WithPdgID(ids ...int64) ParticleSelector {
+	return func(p *Particle) bool {
+		for _, id := range ids {
+			if p.PdgID == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithAbsPdgID returns a selector selecting particles and anti-particles
+// with any of the provided PDG IDs.
+func WithAbsPdgID(ids ...int64) ParticleSelector {
+	return func(p *Particle) bool {
+		pid := p.PdgID
+		if pid < 0 {
+			pid = -pid
+		}
+		for _, id := range ids {
+			if id < 0 {
+				id = -id
+			}
+			if pid == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithMinPt returns a selector selecting particles with a transverse
+// momentum greater or equal to pt.
+func WithMinPt(pt float64) ParticleSelector {
+	return func(p *Particle) bool {
+		return p.Momentum.Pt() >= pt
+	}
+}
+
+// RemoveParticles removes the particles satisfying the provided selection
+// from the event. Beam particles are never removed.
+//
+// The event graph is kept connected: when a particle with both a production
+// and a decay vertex is removed, its decay vertex is merged into its
+// production vertex.
+// Vertices left without any particle are removed from the event.
+func RemoveParticles(evt *Event, sel ParticleSelector) error {
+	ps := make([]*Particle, 0, len(evt.Particles))
+	for _, p := range evt.Particles {
+		if p == evt.Beams[0] || p == evt.Beams[1] || !sel(p) {
+			continue
+		}
+		ps = append(ps, p)
+	}
+	sort.Sort(Particles(ps))
+
+	for _, p := range ps {
+		var (
+			pv = p.ProdVertex
+			ev = p.EndVertex
+		)
+		if pv != nil {
+			err := pv.removeParticleOut(p)
+			if err != nil {
+				return err
+			}
+		}
+		if ev != nil {
+			err := ev.removeParticleIn(p)
+			if err != nil {
+				return err
+			}
+		}
+		p.ProdVertex = nil
+		p.EndVertex = nil
+		evt.removeParticle(p.Barcode)
+
+		if pv != nil && ev != nil && pv != ev {
+			mergeVertex(evt, pv, ev)
+		}
+	}
+
+	for bc, vtx := range evt.Vertices {
+		if len(vtx.ParticlesIn) == 0 && len(vtx.ParticlesOut) == 0 {
+			if evt.SignalVertex == vtx {
+				evt.SignalVertex = nil
+			}
+			vtx.Event = nil
+			delete(evt.Vertices, bc)
+		}
+	}
+
+	return nil
+}
+
+// mergeVertex moves all the particles of src into dst and removes src
+// from the event.
+// Particles that would both start and end at dst lose their decay vertex.
+func mergeVertex(evt *Event, dst, src *Vertex) {
+	for _, p := range src.ParticlesIn {
+		if p.ProdVertex == dst {
+			p.EndVertex = nil
+			continue
+		}
+		p.EndVertex = dst
+		dst.ParticlesIn = append(dst.ParticlesIn, p)
+	}
+	for _, p := range src.ParticlesOut {
+		p.ProdVertex = dst
+		dst.ParticlesOut = append(dst.ParticlesOut, p)
+		if p.EndVertex == dst {
+			_ = dst.removeParticleIn(p)
+			p.EndVertex = nil
+		}
+	}
+
+	if evt.SignalVertex == src {
+		evt.SignalVertex = dst
+	}
+	src.ParticlesIn = nil
+	src.ParticlesOut = nil
+	src.Event = nil
+	evt.removeVertex(src.Barcode)
+}
@@ -0,0 +1,317 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"testing"
+
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/hepmc"
+)
+
+func TestFilter(t *testing.T) {
+	raw, err := os.ReadFile("testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		f     hepmc.FilterFunc
+		nkept int
+	}{
+		{"all", hepmc.All(), 6},
+		{"units", hepmc.UseUnits(hepmc.MEV, hepmc.CM), 6},
+		{"any", hepmc.Any(), 0},
+		{"electrons", hepmc.AtLeast(1, hepmc.WithAbsPdgID(11)), 3},
+		{"positrons", hepmc.AtLeast(1, hepmc.WithPdgID(-11)), 3},
+		{"no-electrons", hepmc.Not(hepmc.AtLeast(1, hepmc.WithAbsPdgID(11))), 3},
+		{"final-state-100", hepmc.AtLeast(100, hepmc.IsFinalState), 2},
+		{
+			"electrons-and-final-state-90",
+			hepmc.All(
+				hepmc.AtLeast(1, hepmc.WithAbsPdgID(11)),
+				hepmc.AtLeast(90, hepmc.IsFinalState),
+			),
+			1,
+		},
+		{
+			"electrons-or-final-state-100",
+			hepmc.Any(
+				hepmc.AtLeast(1, hepmc.WithAbsPdgID(11)),
+				hepmc.AtLeast(100, hepmc.IsFinalState),
+			),
+			5,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				dec = hepmc.NewDecoder(bytes.NewReader(raw))
+				out = new(bytes.Buffer)
+				enc = hepmc.NewEncoder(out)
+			)
+			nread, nkept, err := hepmc.Filter(dec, enc, tc.f)
+			if err != nil {
+				t.Fatalf("could not filter events: %+v", err)
+			}
+			if got, want := nread, 6; got != want {
+				t.Fatalf("invalid number of events read: got=%d, want=%d", got, want)
+			}
+			if got, want := nkept, tc.nkept; got != want {
+				t.Fatalf("invalid number of events kept: got=%d, want=%d", got, want)
+			}
+			err = enc.Close()
+			if err != nil {
+				t.Fatalf("could not close encoder: %+v", err)
+			}
+
+			if tc.nkept == 0 {
+				if out.Len() != 0 {
+					t.Fatalf("unexpected output:\n%s", out.Bytes())
+				}
+				return
+			}
+			if got, want := len(decodeAll(t, out)), tc.nkept; got != want {
+				t.Fatalf("invalid number of events written: got=%d, want=%d", got, want)
+			}
+		})
+	}
+}
+
+func TestFilterPrune(t *testing.T) {
+	raw, err := os.ReadFile("testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := decodeAll(t, bytes.NewReader(raw))
+
+	for _, tc := range []struct {
+		name  string
+		f     hepmc.FilterFunc
+		check func(t *testing.T, p *hepmc.Particle)
+	}{
+		{
+			name: "status-2",
+			f:    hepmc.PruneStatus(2),
+			check: func(t *testing.T, p *hepmc.Particle) {
+				if p.Status == 2 {
+					t.Fatalf("particle %d has a pruned status", p.Barcode)
+				}
+			},
+		},
+		{
+			name: "final-state",
+			f:    hepmc.KeepFinalState(),
+			check: func(t *testing.T, p *hepmc.Particle) {
+				if p.Status != 1 && p.Status != 4 {
+					t.Fatalf("particle %d is not a beam nor a final state particle (status=%d)", p.Barcode, p.Status)
+				}
+			},
+		},
+		{
+			name: "soft-gluons",
+			f: hepmc.PruneParticles(
+				hepmc.WithPdgID(21).And(hepmc.WithMinPt(1).Not()),
+			),
+			check: func(t *testing.T, p *hepmc.Particle) {
+				if p.PdgID == 21 && p.Momentum.Pt() < 1 && p.Status != 4 {
+					t.Fatalf("particle %d is a soft gluon", p.Barcode)
+				}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				dec = hepmc.NewDecoder(bytes.NewReader(raw))
+				out = new(bytes.Buffer)
+				enc = hepmc.NewEncoder(out)
+			)
+			_, nkept, err := hepmc.Filter(dec, enc, tc.f)
+			if err != nil {
+				t.Fatalf("could not filter events: %+v", err)
+			}
+			if nkept != len(ref) {
+				t.Fatalf("invalid number of events kept: got=%d, want=%d", nkept, len(ref))
+			}
+			err = enc.Close()
+			if err != nil {
+				t.Fatalf("could not close encoder: %+v", err)
+			}
+
+			evts := decodeAll(t, out)
+			if len(evts) != len(ref) {
+				t.Fatalf("invalid number of events: got=%d, want=%d", len(evts), len(ref))
+			}
+			for i, evt := range evts {
+				if len(evt.Particles) >= len(ref[i].Particles) {
+					t.Fatalf("evt %d: no particle pruned", i)
+				}
+				for _, p := range evt.Particles {
+					tc.check(t, p)
+					checkLinks(t, p)
+				}
+				if got, want := evt.Beams, ref[i].Beams; got[0].Barcode != want[0].Barcode || got[1].Barcode != want[1].Barcode {
+					t.Fatalf("evt %d: invalid beams", i)
+				}
+
+				// final state is left untouched.
+				got, want := finalState(evt), finalState(ref[i])
+				if got.n != want.n {
+					t.Fatalf("evt %d: invalid number of final state particles: got=%d, want=%d", i, got.n, want.n)
+				}
+				if !p4Equal(got.p4, want.p4) {
+					t.Fatalf("evt %d: invalid final state momentum: got=%v, want=%v", i, got.p4, want.p4)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveParticles(t *testing.T) {
+	// b1 -> v1 -> p3 -> v2 -> (p4, p5)
+	// b2 ----------------^
+	evt := hepmc.Event{
+		Particles: make(map[int]*hepmc.Particle),
+		Vertices:  make(map[int]*hepmc.Vertex),
+	}
+	var (
+		v1 = &hepmc.Vertex{Barcode: -1}
+		v2 = &hepmc.Vertex{Barcode: -2}
+		b1 = &hepmc.Particle{Barcode: 1, Status: 4}
+		b2 = &hepmc.Particle{Barcode: 2, Status: 4}
+		p3 = &hepmc.Particle{Barcode: 3, Status: 2}
+		p4 = &hepmc.Particle{Barcode: 4, Status: 1}
+		p5 = &hepmc.Particle{Barcode: 5, Status: 1}
+	)
+	for _, vtx := range []*hepmc.Vertex{v1, v2} {
+		err := evt.AddVertex(vtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, link := range []struct {
+		vtx *hepmc.Vertex
+		in  []*hepmc.Particle
+		out []*hepmc.Particle
+	}{
+		{v1, []*hepmc.Particle{b1}, []*hepmc.Particle{p3}},
+		{v2, []*hepmc.Particle{p3, b2}, []*hepmc.Particle{p4, p5}},
+	} {
+		for _, p := range link.in {
+			err := link.vtx.AddParticleIn(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, p := range link.out {
+			err := link.vtx.AddParticleOut(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	evt.Beams = [2]*hepmc.Particle{b1, b2}
+	evt.SignalVertex = v2
+
+	err := hepmc.RemoveParticles(&evt, hepmc.WithStatus(2, 4))
+	if err != nil {
+		t.Fatalf("could not remove particles: %+v", err)
+	}
+
+	if got, want := len(evt.Particles), 4; got != want {
+		t.Fatalf("invalid number of particles: got=%d, want=%d", got, want)
+	}
+	if got, want := len(evt.Vertices), 1; got != want {
+		t.Fatalf("invalid number of vertices: got=%d, want=%d", got, want)
+	}
+	if evt.SignalVertex != v1 {
+		t.Fatalf("invalid signal vertex: got=%v", evt.SignalVertex)
+	}
+	if got, want := len(v1.ParticlesIn), 2; got != want {
+		t.Fatalf("invalid number of incoming particles: got=%d, want=%d", got, want)
+	}
+	if got, want := len(v1.ParticlesOut), 2; got != want {
+		t.Fatalf("invalid number of outgoing particles: got=%d, want=%d", got, want)
+	}
+	for _, p := range evt.Particles {
+		checkLinks(t, p)
+	}
+}
+
+type fstate struct {
+	n  int
+	p4 fmom.PxPyPzE
+}
+
+func finalState(evt *hepmc.Event) fstate {
+	var o fstate
+	for _, p := range evt.Particles {
+		if !hepmc.IsFinalState(p) {
+			continue
+		}
+		o.n++
+		o.p4.Set(fmom.Add(&o.p4, &p.Momentum))
+	}
+	return o
+}
+
+// p4Equal compares 4-vectors, allowing for summation order effects.
+func p4Equal(a, b fmom.PxPyPzE) bool {
+	const tol = 1e-9
+	for i, v := range []float64{a.Px(), a.Py(), a.Pz(), a.E()} {
+		w := []float64{b.Px(), b.Py(), b.Pz(), b.E()}[i]
+		if math.Abs(v-w) > tol*math.Max(1, math.Abs(w)) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkLinks checks the consistency of the particle and its vertices.
+func checkLinks(t *testing.T, p *hepmc.Particle) {
+	t.Helper()
+
+	has := func(ps []*hepmc.Particle) bool {
+		for _, pp := range ps {
+			if pp == p {
+				return true
+			}
+		}
+		return false
+	}
+	if p.ProdVertex == nil && p.EndVertex == nil {
+		t.Fatalf("particle %d is not attached to any vertex", p.Barcode)
+	}
+	if vtx := p.ProdVertex; vtx != nil && !has(vtx.ParticlesOut) {
+		t.Fatalf("particle %d not in its production vertex %d", p.Barcode, vtx.Barcode)
+	}
+	if vtx := p.EndVertex; vtx != nil && !has(vtx.ParticlesIn) {
+		t.Fatalf("particle %d not in its decay vertex %d", p.Barcode, vtx.Barcode)
+	}
+}
+
+func decodeAll(t *testing.T, r io.Reader) []*hepmc.Event {
+	t.Helper()
+
+	var (
+		dec  = hepmc.NewDecoder(r)
+		evts []*hepmc.Event
+	)
+	for {
+		var evt hepmc.Event
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			return evts
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", len(evts), err)
+		}
+		evts = append(evts, &evt)
+	}
+}
@@ -3,7 +3,7 @@
 // license that can be found in the LICENSE file.
 
 // go-hepmc-dump is a simple command to dump in an almost human-friendly format
-// the content of a hepmc file.
+// the content of a hepmc file, possibly compressed.
 // ex:
 //  $ go-hepmc-dump foo.hepmc | head -n20
 // ________________________________________________________________________________
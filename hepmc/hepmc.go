@@ -203,7 +203,15 @@ func (evt *Event) Print(w io.Writer) error {
 		return err
 	}
 
+	// print weights in the order they were added.
+	names := make([]string, 0, len(evt.Weights.Map))
 	for n := range evt.Weights.Map {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return evt.Weights.Map[names[i]] < evt.Weights.Map[names[j]]
+	})
+	for _, n := range names {
 		_, err = fmt.Fprintf(w, "(%s,%f) ", n, evt.Weights.At(n))
 		if err != nil {
 			return err
@@ -0,0 +1,68 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"strings"
+)
+
+const (
+	startAsciiv3 = "HepMC::Asciiv3-START_EVENT_LISTING"
+	endAsciiv3   = "HepMC::Asciiv3-END_EVENT_LISTING"
+	versionKey   = "HepMC::Version"
+)
+
+// VersionName returns the version of the HepMC3 library whose ASCII
+// format is implemented by this package.
+func VersionName() string {
+	return "3.02.05"
+}
+
+// escape escapes the backslashes and the new lines of s, so that it
+// fits on a single line.
+func escape(s string) string {
+	if !strings.ContainsAny(s, "\\\n") {
+		return s
+	}
+	var o strings.Builder
+	o.Grow(len(s) + 8)
+	for _, c := range s {
+		switch c {
+		case '\\':
+			o.WriteString(`\\`)
+		case '\n':
+			o.WriteString(`\|`)
+		default:
+			o.WriteRune(c)
+		}
+	}
+	return o.String()
+}
+
+// unescape reverts the transformation applied by escape.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var o strings.Builder
+	o.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case '\\':
+				o.WriteByte('\\')
+				i++
+				continue
+			case '|':
+				o.WriteByte('\n')
+				i++
+				continue
+			}
+		}
+		o.WriteByte(c)
+	}
+	return o.String()
+}
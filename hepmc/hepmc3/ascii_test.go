@@ -0,0 +1,424 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/hepmc"
+)
+
+func TestDecode(t *testing.T) {
+	f, err := os.Open("testdata/small.hepmc3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var (
+		dec  = NewDecoder(f)
+		evts []*Event
+	)
+	for {
+		evt := NewEvent()
+		err := dec.Decode(evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", len(evts), err)
+		}
+		evts = append(evts, evt)
+	}
+
+	if got, want := len(evts), 2; got != want {
+		t.Fatalf("invalid number of events: got=%d, want=%d", got, want)
+	}
+
+	ids := func(ps []*Particle) []int {
+		o := make([]int, len(ps))
+		for i, p := range ps {
+			o[i] = p.ID
+		}
+		return o
+	}
+
+	type vertex struct {
+		status  int
+		pos     fmom.PxPyPzE
+		in, out []int
+	}
+	checkVertices := func(t *testing.T, evt *Event, want []vertex) {
+		t.Helper()
+		if got, want := len(evt.Vertices), len(want); got != want {
+			t.Fatalf("invalid number of vertices: got=%d, want=%d", got, want)
+		}
+		for i, vtx := range evt.Vertices {
+			got := vertex{vtx.Status, vtx.Position, ids(vtx.ParticlesIn), ids(vtx.ParticlesOut)}
+			if !reflect.DeepEqual(got, want[i]) {
+				t.Fatalf("invalid vertex %d:\ngot= %+v\nwant=%+v", vtx.ID, got, want[i])
+			}
+			for _, p := range vtx.ParticlesIn {
+				if p.EndVertex != vtx {
+					t.Fatalf("invalid end vertex for particle %d", p.ID)
+				}
+			}
+			for _, p := range vtx.ParticlesOut {
+				if p.ProdVertex != vtx {
+					t.Fatalf("invalid production vertex for particle %d", p.ID)
+				}
+			}
+		}
+	}
+
+	t.Run("event-0", func(t *testing.T) {
+		evt := evts[0]
+		if got, want := evt.Number, 0; got != want {
+			t.Fatalf("invalid event number: got=%d, want=%d", got, want)
+		}
+		if evt.MomentumUnit != hepmc.GEV || evt.LengthUnit != hepmc.MM {
+			t.Fatalf("invalid units: got=(%v, %v)", evt.MomentumUnit, evt.LengthUnit)
+		}
+		if got, want := evt.Weights, []float64{1, 0.5}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid weights: got=%v, want=%v", got, want)
+		}
+
+		run := evt.RunInfo
+		if run == nil {
+			t.Fatalf("missing run info")
+		}
+		if got, want := run.WeightNames, []string{"Default", "MUR0.5_MUF0.5"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid weight names: got=%q, want=%q", got, want)
+		}
+		if got, want := run.Tools, []Tool{{"Pythia8", "8.306", "Pythia8 event generator"}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid tools: got=%q, want=%q", got, want)
+		}
+		if got, want := run.Attributes, map[string]string{
+			"beams":   "p p",
+			"comment": "first line\nsecond line",
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid run attributes: got=%q, want=%q", got, want)
+		}
+
+		for _, tc := range []struct {
+			name string
+			id   int
+			want string
+		}{
+			{AttrCrossSection, 0, "4.2000000000000000e+01 1.0000000000000000e-01 1 1"},
+			{AttrSignalProcessID, 0, "101"},
+			{AttrFlow + "1", 3, "501"},
+			{"note", -4, `decay \ vertex`},
+		} {
+			got, ok := evt.Attribute(tc.name, tc.id)
+			if !ok || got != tc.want {
+				t.Fatalf("invalid attribute %q (id=%d): got=%q, want=%q", tc.name, tc.id, got, tc.want)
+			}
+		}
+
+		if got, want := len(evt.Particles), 8; got != want {
+			t.Fatalf("invalid number of particles: got=%d, want=%d", got, want)
+		}
+		p := evt.Particle(8)
+		if p.PID != 5 || p.Status != 1 || p.GeneratedMass != 4.8 || p.Momentum != fmom.NewPxPyPzE(5, 10, 25, 125) {
+			t.Fatalf("invalid particle: %+v", *p)
+		}
+
+		checkVertices(t, evt, []vertex{
+			{in: []int{1}, out: []int{3}},
+			{in: []int{2}, out: []int{4}},
+			{in: []int{3, 4}, out: []int{5, 6}},
+			{status: 2, pos: fmom.NewPxPyPzE(0.1, 0.2, 0.3, 0.4), in: []int{5}, out: []int{7, 8}},
+		})
+	})
+
+	t.Run("event-1", func(t *testing.T) {
+		evt := evts[1]
+		if got, want := evt.Number, 1; got != want {
+			t.Fatalf("invalid event number: got=%d, want=%d", got, want)
+		}
+		if evt.MomentumUnit != hepmc.MEV || evt.LengthUnit != hepmc.CM {
+			t.Fatalf("invalid units: got=(%v, %v)", evt.MomentumUnit, evt.LengthUnit)
+		}
+		if got, want := evt.Position, fmom.NewPxPyPzE(1, 2, 3, 4); got != want {
+			t.Fatalf("invalid event position: got=%v, want=%v", got, want)
+		}
+		if evt.RunInfo == evts[0].RunInfo {
+			t.Fatalf("run info not updated")
+		}
+		if got, want := evt.RunInfo.WeightNames, []string{"nominal"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid weight names: got=%q, want=%q", got, want)
+		}
+		if got, want := evt.RunInfo.Attributes["beams"], "e+ e-"; got != want {
+			t.Fatalf("invalid run attribute: got=%q, want=%q", got, want)
+		}
+
+		checkVertices(t, evt, []vertex{
+			{in: []int{1, 2}, out: []int{3, 4}},
+			{in: []int{3}, out: []int{}},
+		})
+	})
+}
+
+func TestEncode(t *testing.T) {
+	raw, err := os.ReadFile("testdata/small.hepmc3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		dec = NewDecoder(bytes.NewReader(raw))
+		got = new(bytes.Buffer)
+		enc = NewEncoder(got)
+	)
+	for i := 0; ; i++ {
+		var evt Event
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+		err = enc.Encode(&evt)
+		if err != nil {
+			t.Fatalf("could not encode event %d: %+v", i, err)
+		}
+	}
+	err = enc.Close()
+	if err != nil {
+		t.Fatalf("could not close encoder: %+v", err)
+	}
+
+	// vertices are always written with their status.
+	want := strings.Replace(string(raw), "V -1 [1,2]", "V -1 0 [1,2]", 1)
+	if got := got.String(); got != want {
+		t.Fatalf("invalid encoding:\ngot:\n%s\nwant:\n%s\n", got, want)
+	}
+}
+
+func TestDecodeCompressed(t *testing.T) {
+	want, err := os.ReadFile("testdata/small.hepmc3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []hepmc.Compression{hepmc.Gzip, hepmc.Xz, hepmc.Zstd} {
+		t.Run(c.String(), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			w, err := hepmc.NewWriter(buf, c)
+			if err != nil {
+				t.Fatalf("could not create compressor: %+v", err)
+			}
+			_, err = w.Write(want)
+			if err != nil {
+				t.Fatalf("could not compress: %+v", err)
+			}
+			err = w.Close()
+			if err != nil {
+				t.Fatalf("could not close compressor: %+v", err)
+			}
+
+			var (
+				dec = NewDecoder(buf)
+				got = new(bytes.Buffer)
+				enc = NewEncoder(got)
+			)
+			for i := 0; ; i++ {
+				var evt Event
+				err := dec.Decode(&evt)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("could not decode event %d: %+v", i, err)
+				}
+				err = enc.Encode(&evt)
+				if err != nil {
+					t.Fatalf("could not encode event %d: %+v", i, err)
+				}
+			}
+			err = enc.Close()
+			if err != nil {
+				t.Fatalf("could not close encoder: %+v", err)
+			}
+
+			want := strings.Replace(string(want), "V -1 [1,2]", "V -1 0 [1,2]", 1)
+			if got := got.String(); got != want {
+				t.Fatalf("invalid round-trip:\ngot:\n%s\nwant:\n%s\n", got, want)
+			}
+		})
+	}
+}
+
+func TestAsciiv3RoundTrip(t *testing.T) {
+	f, err := os.Open("../testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var (
+		dec2 = hepmc.NewDecoder(f)
+		run  = NewRunInfo()
+		want = new(bytes.Buffer)
+		enc  = NewEncoder(want)
+		evts []*hepmc.Event
+	)
+	run.Tools = []Tool{{Name: "go-hep", Version: "v0.31", Description: "HepMC2\nconversion"}}
+
+	for i := 0; ; i++ {
+		var evt hepmc.Event
+		err := dec2.Decode(&evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+		evts = append(evts, &evt)
+
+		evt3, err := FromHepMC2(&evt, run)
+		if err != nil {
+			t.Fatalf("could not convert event %d to HepMC3: %+v", i, err)
+		}
+		evt3.RunInfo = run
+
+		err = enc.Encode(evt3)
+		if err != nil {
+			t.Fatalf("could not encode event %d: %+v", i, err)
+		}
+	}
+	err = enc.Close()
+	if err != nil {
+		t.Fatalf("could not close encoder: %+v", err)
+	}
+
+	var (
+		dec = NewDecoder(bytes.NewReader(want.Bytes()))
+		got = new(bytes.Buffer)
+		ref = new(bytes.Buffer)
+		chk = new(bytes.Buffer)
+	)
+	enc = NewEncoder(got)
+	for i := 0; ; i++ {
+		var evt Event
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			if i != len(evts) {
+				t.Fatalf("invalid number of events: got=%d, want=%d", i, len(evts))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+		err = enc.Encode(&evt)
+		if err != nil {
+			t.Fatalf("could not re-encode event %d: %+v", i, err)
+		}
+
+		evt2, err := ToHepMC2(&evt)
+		if err != nil {
+			t.Fatalf("could not convert event %d to HepMC2: %+v", i, err)
+		}
+		ref.Reset()
+		chk.Reset()
+		_ = evts[i].Print(ref)
+		_ = evt2.Print(chk)
+		if !bytes.Equal(chk.Bytes(), ref.Bytes()) {
+			t.Fatalf("HepMC2 round-trip failed for event %d:\ngot:\n%s\nwant:\n%s\n", i, chk.Bytes(), ref.Bytes())
+		}
+	}
+	err = enc.Close()
+	if err != nil {
+		t.Fatalf("could not close encoder: %+v", err)
+	}
+
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Fatalf("Asciiv3 round-trip failed")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	const hdr = "HepMC::Version 3.02.05\n" + startAsciiv3 + "\n"
+	for _, tc := range []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "hepmc2",
+			data: "HepMC::Version 2.06.09\nHepMC::IO_GenEvent-START_EVENT_LISTING\n",
+			want: `hepmc3: HepMC2 stream (header="HepMC::IO_GenEvent-START_EVENT_LISTING"), use hepmc.NewDecoder`,
+		},
+		{
+			name: "empty",
+			data: "",
+			want: "unexpected EOF",
+		},
+		{
+			name: "bad-header",
+			data: hdr + "E 0 1\n",
+			want: `hepmc3: line 3: could not decode event header "E 0 1": invalid number of fields`,
+		},
+		{
+			name: "truncated",
+			data: hdr + "E 0 1 1\nU GEV MM\nP 1 -1 11 0 0 0 0 0 1\n",
+			want: `hepmc3: line 5: unexpected end of stream in event 0`,
+		},
+		{
+			name: "missing-vertex",
+			data: hdr + "E 0 1 1\nU GEV MM\nP 1 -1 11 0 0 0 0 0 1\n" + endAsciiv3 + "\n",
+			want: `hepmc3: line 6: invalid production vertex -1 for particle 1 in event 0`,
+		},
+		{
+			name: "bad-units",
+			data: hdr + "E 0 0 0\nU TEV MM\n",
+			want: `hepmc3: line 4: could not decode momentum unit: hepmc.units: invalid MomentumUnit string-value (TEV)`,
+		},
+		{
+			name: "bad-particle-id",
+			data: hdr + "E 0 0 1\nP 2 0 11 0 0 0 0 0 1\n",
+			want: `hepmc3: line 4: invalid particle ID 2 (nparticles=1)`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var evt Event
+			err := NewDecoder(strings.NewReader(tc.data)).Decode(&evt)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got, want := err.Error(), tc.want; got != want {
+				t.Fatalf("invalid error:\ngot= %q\nwant=%q", got, want)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"hello",
+		"a\nb",
+		`back\slash`,
+		"\\|\n\\\\",
+		"trailing\\",
+	} {
+		esc := escape(s)
+		if strings.Contains(esc, "\n") {
+			t.Fatalf("escaped string %q contains a new line", esc)
+		}
+		if got := unescape(esc); got != s {
+			t.Fatalf("invalid round-trip: got=%q, want=%q", got, s)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"encoding"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Attribute is a typed attribute attached to a run, an event, a particle
+// or a vertex.
+//
+// Attributes are stored in their string representation, following the
+// conventions of the HepMC3 C++ library, and are decoded on demand.
+type Attribute interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+var (
+	_ Attribute = (*BoolAttribute)(nil)
+	_ Attribute = (*IntAttribute)(nil)
+	_ Attribute = (*LongAttribute)(nil)
+	_ Attribute = (*DoubleAttribute)(nil)
+	_ Attribute = (*StringAttribute)(nil)
+	_ Attribute = (*VectorIntAttribute)(nil)
+	_ Attribute = (*VectorLongAttribute)(nil)
+	_ Attribute = (*VectorDoubleAttribute)(nil)
+	_ Attribute = (*VectorStringAttribute)(nil)
+	_ Attribute = (*CrossSection)(nil)
+	_ Attribute = (*PdfInfo)(nil)
+)
+
+// BoolAttribute is a boolean attribute, stored as "1" or "0".
+type BoolAttribute bool
+
+func (a BoolAttribute) MarshalText() ([]byte, error) {
+	if a {
+		return []byte("1"), nil
+	}
+	return []byte("0"), nil
+}
+
+func (a *BoolAttribute) UnmarshalText(p []byte) error {
+	v, err := strconv.Atoi(strings.TrimSpace(string(p)))
+	if err != nil {
+		return err
+	}
+	*a = v != 0
+	return nil
+}
+
+// IntAttribute is an integer attribute.
+type IntAttribute int
+
+func (a IntAttribute) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(a), 10), nil
+}
+
+func (a *IntAttribute) UnmarshalText(p []byte) error {
+	v, err := strconv.Atoi(strings.TrimSpace(string(p)))
+	if err != nil {
+		return err
+	}
+	*a = IntAttribute(v)
+	return nil
+}
+
+// LongAttribute is a 64b integer attribute.
+type LongAttribute int64
+
+func (a LongAttribute) MarshalText() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(a), 10), nil
+}
+
+func (a *LongAttribute) UnmarshalText(p []byte) error {
+	v, err := strconv.ParseInt(strings.TrimSpace(string(p)), 10, 64)
+	if err != nil {
+		return err
+	}
+	*a = LongAttribute(v)
+	return nil
+}
+
+// DoubleAttribute is a floating point attribute.
+type DoubleAttribute float64
+
+func (a DoubleAttribute) MarshalText() ([]byte, error) {
+	return []byte(ftoa(float64(a))), nil
+}
+
+func (a *DoubleAttribute) UnmarshalText(p []byte) error {
+	v, err := strconv.ParseFloat(strings.TrimSpace(string(p)), 64)
+	if err != nil {
+		return err
+	}
+	*a = DoubleAttribute(v)
+	return nil
+}
+
+// StringAttribute is a string attribute.
+// Its value may contain spaces and new lines.
+type StringAttribute string
+
+func (a StringAttribute) MarshalText() ([]byte, error) {
+	return []byte(a), nil
+}
+
+func (a *StringAttribute) UnmarshalText(p []byte) error {
+	*a = StringAttribute(p)
+	return nil
+}
+
+// VectorIntAttribute is a list of integers, stored space-separated.
+type VectorIntAttribute []int
+
+func (a VectorIntAttribute) MarshalText() ([]byte, error) {
+	var o []byte
+	for i, v := range a {
+		if i > 0 {
+			o = append(o, ' ')
+		}
+		o = strconv.AppendInt(o, int64(v), 10)
+	}
+	return o, nil
+}
+
+func (a *VectorIntAttribute) UnmarshalText(p []byte) error {
+	toks := strings.Fields(string(p))
+	vs := make(VectorIntAttribute, len(toks))
+	for i, tok := range toks {
+		v, err := strconv.Atoi(tok)
+		if err != nil {
+			return fmt.Errorf("could not decode element %d: %w", i, err)
+		}
+		vs[i] = v
+	}
+	*a = vs
+	return nil
+}
+
+// VectorLongAttribute is a list of 64b integers, stored space-separated.
+type VectorLongAttribute []int64
+
+func (a VectorLongAttribute) MarshalText() ([]byte, error) {
+	var o []byte
+	for i, v := range a {
+		if i > 0 {
+			o = append(o, ' ')
+		}
+		o = strconv.AppendInt(o, v, 10)
+	}
+	return o, nil
+}
+
+func (a *VectorLongAttribute) UnmarshalText(p []byte) error {
+	toks := strings.Fields(string(p))
+	vs := make(VectorLongAttribute, len(toks))
+	for i, tok := range toks {
+		v, err := strconv.ParseInt(tok, 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not decode element %d: %w", i, err)
+		}
+		vs[i] = v
+	}
+	*a = vs
+	return nil
+}
+
+// VectorDoubleAttribute is a list of floating point values, stored
+// space-separated.
+type VectorDoubleAttribute []float64
+
+func (a VectorDoubleAttribute) MarshalText() ([]byte, error) {
+	return []byte(joinf(a)), nil
+}
+
+func (a *VectorDoubleAttribute) UnmarshalText(p []byte) error {
+	toks := strings.Fields(string(p))
+	vs := make(VectorDoubleAttribute, len(toks))
+	for i, tok := range toks {
+		v, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return fmt.Errorf("could not decode element %d: %w", i, err)
+		}
+		vs[i] = v
+	}
+	*a = vs
+	return nil
+}
+
+// VectorStringAttribute is a list of strings, stored space-separated.
+// Elements can not contain white spaces.
+type VectorStringAttribute []string
+
+func (a VectorStringAttribute) MarshalText() ([]byte, error) {
+	for i, v := range a {
+		if v == "" || strings.ContainsAny(v, " \t\n") {
+			return nil, fmt.Errorf("invalid element %d (%q)", i, v)
+		}
+	}
+	return []byte(strings.Join(a, " ")), nil
+}
+
+func (a *VectorStringAttribute) UnmarshalText(p []byte) error {
+	*a = strings.Fields(string(p))
+	return nil
+}
+
+// CrossSection holds the cross-sections of a sample, as stored in the
+// "GenCrossSection" attribute.
+//
+// Values and Errors hold a cross-section (and its error) for each event
+// weight, in pb.
+type CrossSection struct {
+	Values          []float64 // cross-sections, one per event weight
+	Errors          []float64 // cross-section errors, one per event weight
+	AcceptedEvents  int64     // number of accepted events (-1 if unknown)
+	AttemptedEvents int64     // number of attempted events (-1 if unknown)
+}
+
+// MarshalText encodes the cross-section as:
+//
+//	xs0 err0 accepted attempted [xs1 err1 [...]]
+func (xs CrossSection) MarshalText() ([]byte, error) {
+	if len(xs.Values) != len(xs.Errors) {
+		return nil, fmt.Errorf(
+			"inconsistent number of cross-sections (%d) and errors (%d)",
+			len(xs.Values), len(xs.Errors),
+		)
+	}
+	var v, e float64
+	if len(xs.Values) > 0 {
+		v, e = xs.Values[0], xs.Errors[0]
+	}
+	o := []byte(ftoa(v) + " " + ftoa(e) + " ")
+	o = strconv.AppendInt(o, xs.AcceptedEvents, 10)
+	o = append(o, ' ')
+	o = strconv.AppendInt(o, xs.AttemptedEvents, 10)
+	for i := 1; i < len(xs.Values); i++ {
+		o = append(o, ' ')
+		o = append(o, ftoa(xs.Values[i])+" "+ftoa(xs.Errors[i])...)
+	}
+	return o, nil
+}
+
+func (xs *CrossSection) UnmarshalText(p []byte) error {
+	toks := strings.Fields(string(p))
+	if len(toks) < 2 {
+		return fmt.Errorf("invalid number of fields (%d)", len(toks))
+	}
+	var v, e float64
+	err := scan(toks[:2], &v, &e)
+	if err != nil {
+		return err
+	}
+	*xs = CrossSection{
+		Values:          []float64{v},
+		Errors:          []float64{e},
+		AcceptedEvents:  -1,
+		AttemptedEvents: -1,
+	}
+	toks = toks[2:]
+	if len(toks) == 0 {
+		return nil
+	}
+	if len(toks) < 2 || len(toks)%2 != 0 {
+		return fmt.Errorf("invalid number of fields (%d)", len(toks)+2)
+	}
+	for i, ptr := range []*int64{&xs.AcceptedEvents, &xs.AttemptedEvents} {
+		*ptr, err = strconv.ParseInt(toks[i], 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not decode field %d (%q): %w", i+2, toks[i], err)
+		}
+	}
+	for i := 2; i < len(toks); i += 2 {
+		err = scan(toks[i:i+2], &v, &e)
+		if err != nil {
+			return err
+		}
+		xs.Values = append(xs.Values, v)
+		xs.Errors = append(xs.Errors, e)
+	}
+	return nil
+}
+
+// PdfInfo holds the parton density function information of an event,
+// as stored in the "GenPdfInfo" attribute.
+type PdfInfo struct {
+	PartonID [2]int     // flavour codes of the incoming partons
+	X        [2]float64 // momentum fractions of the incoming partons
+	Scale    float64    // factorization scale (in GeV)
+	XF       [2]float64 // PDF values, x*f(x)
+	PdfID    [2]int     // LHAPDF set identifiers
+}
+
+// MarshalText encodes the PDF information as:
+//
+//	id1 id2 x1 x2 scale xf1 xf2 pdf1 pdf2
+func (pdf PdfInfo) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf(
+		"%d %d %s %s %s %s %s %d %d",
+		pdf.PartonID[0], pdf.PartonID[1],
+		ftoa(pdf.X[0]), ftoa(pdf.X[1]), ftoa(pdf.Scale),
+		ftoa(pdf.XF[0]), ftoa(pdf.XF[1]),
+		pdf.PdfID[0], pdf.PdfID[1],
+	)), nil
+}
+
+func (pdf *PdfInfo) UnmarshalText(p []byte) error {
+	var v PdfInfo
+	err := scan(
+		strings.Fields(string(p)),
+		&v.PartonID[0], &v.PartonID[1],
+		&v.X[0], &v.X[1], &v.Scale,
+		&v.XF[0], &v.XF[1],
+		&v.PdfID[0], &v.PdfID[1],
+	)
+	if err != nil {
+		return err
+	}
+	*pdf = v
+	return nil
+}
+
+// SetAttr encodes the provided attribute and attaches it to the object
+// with the provided ID (0 for the event itself).
+func (evt *Event) SetAttr(name string, id int, a encoding.TextMarshaler) error {
+	v, err := a.MarshalText()
+	if err != nil {
+		return fmt.Errorf("hepmc3: could not encode attribute %q (id=%d): %w", name, id, err)
+	}
+	evt.SetAttribute(name, id, string(v))
+	return nil
+}
+
+// Attr decodes into a the named attribute attached to the object with
+// the provided ID (0 for the event itself).
+// Attr reports whether the attribute was found.
+func (evt *Event) Attr(name string, id int, a encoding.TextUnmarshaler) (bool, error) {
+	v, ok := evt.Attribute(name, id)
+	if !ok {
+		return false, nil
+	}
+	err := a.UnmarshalText([]byte(v))
+	if err != nil {
+		return true, fmt.Errorf("hepmc3: could not decode attribute %q (id=%d, value=%q): %w", name, id, v, err)
+	}
+	return true, nil
+}
+
+// SetAttr encodes the provided attribute and attaches it to the particle.
+// The particle must be attached to an event.
+func (p *Particle) SetAttr(name string, a encoding.TextMarshaler) error {
+	if p.Event == nil {
+		return fmt.Errorf("hepmc3: particle not attached to an event")
+	}
+	return p.Event.SetAttr(name, p.ID, a)
+}
+
+// Attr decodes into a the named attribute attached to the particle.
+// Attr reports whether the attribute was found.
+func (p *Particle) Attr(name string, a encoding.TextUnmarshaler) (bool, error) {
+	if p.Event == nil {
+		return false, nil
+	}
+	return p.Event.Attr(name, p.ID, a)
+}
+
+// SetAttr encodes the provided attribute and attaches it to the vertex.
+// The vertex must be attached to an event.
+func (vtx *Vertex) SetAttr(name string, a encoding.TextMarshaler) error {
+	if vtx.Event == nil {
+		return fmt.Errorf("hepmc3: vertex not attached to an event")
+	}
+	return vtx.Event.SetAttr(name, vtx.ID, a)
+}
+
+// Attr decodes into a the named attribute attached to the vertex.
+// Attr reports whether the attribute was found.
+func (vtx *Vertex) Attr(name string, a encoding.TextUnmarshaler) (bool, error) {
+	if vtx.Event == nil {
+		return false, nil
+	}
+	return vtx.Event.Attr(name, vtx.ID, a)
+}
+
+// SetAttr encodes the provided attribute and attaches it to the run.
+func (run *RunInfo) SetAttr(name string, a encoding.TextMarshaler) error {
+	v, err := a.MarshalText()
+	if err != nil {
+		return fmt.Errorf("hepmc3: could not encode run attribute %q: %w", name, err)
+	}
+	if run.Attributes == nil {
+		run.Attributes = make(map[string]string)
+	}
+	run.Attributes[name] = string(v)
+	return nil
+}
+
+// Attr decodes into a the named attribute attached to the run.
+// Attr reports whether the attribute was found.
+func (run *RunInfo) Attr(name string, a encoding.TextUnmarshaler) (bool, error) {
+	v, ok := run.Attributes[name]
+	if !ok {
+		return false, nil
+	}
+	err := a.UnmarshalText([]byte(v))
+	if err != nil {
+		return true, fmt.Errorf("hepmc3: could not decode run attribute %q (value=%q): %w", name, v, err)
+	}
+	return true, nil
+}
+
+func joinf(vs []float64) string {
+	o := make([]string, len(vs))
+	for i, v := range vs {
+		o[i] = ftoa(v)
+	}
+	return strings.Join(o, " ")
+}
@@ -0,0 +1,237 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"bytes"
+	"encoding"
+	"reflect"
+	"testing"
+)
+
+func TestAttributes(t *testing.T) {
+	var (
+		vbool   = BoolAttribute(true)
+		vint    = IntAttribute(-42)
+		vlong   = LongAttribute(1 << 40)
+		vdouble = DoubleAttribute(0.118)
+		vstring = StringAttribute("a b\nc")
+	)
+	for _, tc := range []struct {
+		name string
+		attr Attribute
+		want string
+	}{
+		{"bool", &vbool, "1"},
+		{"int", &vint, "-42"},
+		{"long", &vlong, "1099511627776"},
+		{"double", &vdouble, "0.118"},
+		{"string", &vstring, "a b\nc"},
+		{"vint", &VectorIntAttribute{1, -2, 3}, "1 -2 3"},
+		{"vlong", &VectorLongAttribute{1 << 40, -2}, "1099511627776 -2"},
+		{"vdouble", &VectorDoubleAttribute{1.5, -2e-3}, "1.5 -0.002"},
+		{"vstring", &VectorStringAttribute{"u", "ubar"}, "u ubar"},
+		{
+			"xsection",
+			&CrossSection{
+				Values:          []float64{42, 43},
+				Errors:          []float64{0.1, 0.2},
+				AcceptedEvents:  100,
+				AttemptedEvents: 200,
+			},
+			"42 0.1 100 200 43 0.2",
+		},
+		{
+			"pdf",
+			&PdfInfo{
+				PartonID: [2]int{2, -1},
+				X:        [2]float64{0.1, 0.02},
+				Scale:    91.2,
+				XF:       [2]float64{0.5, 0.25},
+				PdfID:    [2]int{10042, 10042},
+			},
+			"2 -1 0.1 0.02 91.2 0.5 0.25 10042 10042",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			evt := NewEvent()
+			err := evt.AddParticle(&Particle{PID: 21})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = evt.SetAttr(tc.name, 1, tc.attr)
+			if err != nil {
+				t.Fatalf("could not set attribute: %+v", err)
+			}
+			if got, _ := evt.Attribute(tc.name, 1); got != tc.want {
+				t.Fatalf("invalid encoding:\ngot= %q\nwant=%q", got, tc.want)
+			}
+
+			// round-trip through the ASCII format.
+			buf := new(bytes.Buffer)
+			enc := NewEncoder(buf)
+			err = enc.Encode(evt)
+			if err != nil {
+				t.Fatalf("could not encode event: %+v", err)
+			}
+			err = enc.Close()
+			if err != nil {
+				t.Fatalf("could not close encoder: %+v", err)
+			}
+
+			var evt2 Event
+			err = NewDecoder(buf).Decode(&evt2)
+			if err != nil {
+				t.Fatalf("could not decode event: %+v", err)
+			}
+
+			got := reflect.New(reflect.TypeOf(tc.attr).Elem()).Interface().(Attribute)
+			ok, err := evt2.Particle(1).Attr(tc.name, got)
+			if err != nil {
+				t.Fatalf("could not decode attribute: %+v", err)
+			}
+			if !ok {
+				t.Fatalf("could not find attribute")
+			}
+			if !reflect.DeepEqual(got, tc.attr) {
+				t.Fatalf("invalid round-trip:\ngot= %#v\nwant=%#v", got, tc.attr)
+			}
+		})
+	}
+}
+
+func TestAttributeTargets(t *testing.T) {
+	evt := NewEvent()
+	vtx := &Vertex{}
+	p := &Particle{PID: 11}
+
+	if err := p.SetAttr("x", IntAttribute(1)); err == nil {
+		t.Fatalf("expected an error for detached particle")
+	}
+	if err := vtx.SetAttr("x", IntAttribute(1)); err == nil {
+		t.Fatalf("expected an error for detached vertex")
+	}
+
+	err := vtx.AddParticleOut(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = evt.AddVertex(vtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		set func(a encoding.TextMarshaler) error
+		get func(a encoding.TextUnmarshaler) (bool, error)
+		id  int
+	}{
+		{
+			set: func(a encoding.TextMarshaler) error { return evt.SetAttr("x", 0, a) },
+			get: func(a encoding.TextUnmarshaler) (bool, error) { return evt.Attr("x", 0, a) },
+			id:  0,
+		},
+		{
+			set: func(a encoding.TextMarshaler) error { return p.SetAttr("x", a) },
+			get: func(a encoding.TextUnmarshaler) (bool, error) { return p.Attr("x", a) },
+			id:  p.ID,
+		},
+		{
+			set: func(a encoding.TextMarshaler) error { return vtx.SetAttr("x", a) },
+			get: func(a encoding.TextUnmarshaler) (bool, error) { return vtx.Attr("x", a) },
+			id:  vtx.ID,
+		},
+	} {
+		var v IntAttribute
+		ok, err := tc.get(&v)
+		if err != nil || ok {
+			t.Fatalf("id=%d: unexpected attribute: ok=%v, err=%v", tc.id, ok, err)
+		}
+
+		err = tc.set(IntAttribute(tc.id))
+		if err != nil {
+			t.Fatalf("id=%d: could not set attribute: %+v", tc.id, err)
+		}
+		ok, err = tc.get(&v)
+		if err != nil || !ok {
+			t.Fatalf("id=%d: could not get attribute: ok=%v, err=%v", tc.id, ok, err)
+		}
+		if got, want := int(v), tc.id; got != want {
+			t.Fatalf("invalid attribute value: got=%d, want=%d", got, want)
+		}
+	}
+
+	run := NewRunInfo()
+	err = run.SetAttr("beams", VectorStringAttribute{"p", "p"})
+	if err != nil {
+		t.Fatalf("could not set run attribute: %+v", err)
+	}
+	var beams VectorStringAttribute
+	ok, err := run.Attr("beams", &beams)
+	if err != nil || !ok {
+		t.Fatalf("could not get run attribute: ok=%v, err=%v", ok, err)
+	}
+	if got, want := beams, (VectorStringAttribute{"p", "p"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid run attribute: got=%q, want=%q", got, want)
+	}
+}
+
+func TestAttributeErrors(t *testing.T) {
+	evt := NewEvent()
+	for _, tc := range []struct {
+		name string
+		attr encoding.TextMarshaler
+	}{
+		{"vstring", VectorStringAttribute{"a b"}},
+		{"xsection", CrossSection{Values: []float64{1}}},
+	} {
+		err := evt.SetAttr(tc.name, 0, tc.attr)
+		if err == nil {
+			t.Fatalf("%s: expected an encoding error", tc.name)
+		}
+	}
+
+	for _, tc := range []struct {
+		value string
+		attr  encoding.TextUnmarshaler
+	}{
+		{"x", new(BoolAttribute)},
+		{"1.5", new(IntAttribute)},
+		{"1.5", new(LongAttribute)},
+		{"x", new(DoubleAttribute)},
+		{"1 x", new(VectorIntAttribute)},
+		{"1 x", new(VectorLongAttribute)},
+		{"1 x", new(VectorDoubleAttribute)},
+		{"1", new(CrossSection)},
+		{"1 2 3", new(CrossSection)},
+		{"1 2 3 4 5", new(CrossSection)},
+		{"1 2 3.5 4", new(CrossSection)},
+		{"1 2 0.1 0.2 91.2 0.5 0.25 1", new(PdfInfo)},
+	} {
+		evt.SetAttribute("x", 0, tc.value)
+		ok, err := evt.Attr("x", 0, tc.attr)
+		if !ok || err == nil {
+			t.Fatalf("%T(%q): expected a decoding error", tc.attr, tc.value)
+		}
+	}
+}
+
+func TestCrossSectionShort(t *testing.T) {
+	var xs CrossSection
+	err := xs.UnmarshalText([]byte("1.5 0.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CrossSection{
+		Values:          []float64{1.5},
+		Errors:          []float64{0.5},
+		AcceptedEvents:  -1,
+		AttemptedEvents: -1,
+	}
+	if !reflect.DeepEqual(xs, want) {
+		t.Fatalf("invalid cross-section:\ngot= %+v\nwant=%+v", xs, want)
+	}
+}
@@ -0,0 +1,536 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-hep.org/x/hep/hepmc"
+)
+
+// Names of the attributes used to store HepMC2 information that has no
+// direct equivalent in the HepMC3 event model.
+// These names follow the conventions of the HepMC3 C++ library, except for
+// the barcode and beam attributes, used to allow lossless round-trips.
+const (
+	AttrSignalProcessID = "signal_process_id"
+	AttrMPI             = "mpi"
+	AttrEventScale      = "event_scale"
+	AttrAlphaQCD        = "alphaQCD"
+	AttrAlphaQED        = "alphaQED"
+	AttrSignalVertexID  = "signal_vertex_id"
+	AttrRandomStates    = "random_states"
+	AttrCrossSection    = "GenCrossSection"
+	AttrPdfInfo         = "GenPdfInfo"
+	AttrHeavyIon        = "GenHeavyIon"
+	AttrTheta           = "theta"
+	AttrPhi             = "phi"
+	AttrFlow            = "flow" // flow attributes are named "flow1", "flow2", ...
+	AttrWeights         = "weights"
+	AttrBarcode         = "barcode"
+	AttrBeamParticle1   = "beam_particle1"
+	AttrBeamParticle2   = "beam_particle2"
+)
+
+// FromHepMC2 converts a HepMC2 event into a HepMC3 event.
+//
+// Event information without a HepMC3 equivalent (signal process id,
+// scale, couplings, random states, cross-section, PDF and heavy-ion
+// information, particle flows and polarizations, vertex weights, ...)
+// is stored as attributes.
+// Barcodes of particles and vertices are stored under the "barcode"
+// attribute so the original event can be recovered with ToHepMC2.
+//
+// Weight names are stored in the provided run info, which is created if nil.
+func FromHepMC2(evt *hepmc.Event, run *RunInfo) (*Event, error) {
+	if evt == nil {
+		return nil, fmt.Errorf("hepmc3: nil HepMC2 event")
+	}
+	if run == nil {
+		run = NewRunInfo()
+	}
+
+	names := make([]string, len(evt.Weights.Slice))
+	for i := range names {
+		names[i] = strconv.Itoa(i)
+	}
+	for n, i := range evt.Weights.Map {
+		if i < 0 || i >= len(names) {
+			return nil, fmt.Errorf("hepmc3: invalid index %d for weight %q", i, n)
+		}
+		names[i] = n
+	}
+	switch {
+	case run.WeightNames == nil:
+		run.WeightNames = names
+	case len(run.WeightNames) != len(names):
+		return nil, fmt.Errorf(
+			"hepmc3: inconsistent number of weights (run=%d, event=%d)",
+			len(run.WeightNames), len(names),
+		)
+	}
+
+	o := NewEvent()
+	o.Number = evt.EventNumber
+	o.MomentumUnit = evt.MomentumUnit
+	o.LengthUnit = evt.LengthUnit
+	o.RunInfo = run
+	o.Weights = append([]float64(nil), evt.Weights.Slice...)
+
+	o.SetAttribute(AttrSignalProcessID, 0, strconv.Itoa(evt.SignalProcessID))
+	o.SetAttribute(AttrMPI, 0, strconv.Itoa(evt.Mpi))
+	o.SetAttribute(AttrEventScale, 0, ftoa(evt.Scale))
+	o.SetAttribute(AttrAlphaQCD, 0, ftoa(evt.AlphaQCD))
+	o.SetAttribute(AttrAlphaQED, 0, ftoa(evt.AlphaQED))
+	if len(evt.RandomStates) > 0 {
+		vs := make([]string, len(evt.RandomStates))
+		for i, v := range evt.RandomStates {
+			vs[i] = strconv.FormatInt(v, 10)
+		}
+		o.SetAttribute(AttrRandomStates, 0, strings.Join(vs, " "))
+	}
+	if xs := evt.CrossSection; xs != nil {
+		err := o.SetAttr(AttrCrossSection, 0, CrossSection{
+			Values:          []float64{xs.Value},
+			Errors:          []float64{xs.Error},
+			AcceptedEvents:  -1,
+			AttemptedEvents: -1,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if pdf := evt.PdfInfo; pdf != nil {
+		err := o.SetAttr(AttrPdfInfo, 0, PdfInfo{
+			PartonID: [2]int{pdf.ID1, pdf.ID2},
+			X:        [2]float64{pdf.X1, pdf.X2},
+			Scale:    pdf.ScalePDF,
+			XF:       [2]float64{pdf.Pdf1, pdf.Pdf2},
+			PdfID:    [2]int{pdf.LHAPdf1, pdf.LHAPdf2},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if hi := evt.HeavyIon; hi != nil {
+		o.SetAttribute(AttrHeavyIon, 0, fmt.Sprintf(
+			"%d %d %d %d %d %d %d %d %d %s %s %s %s 0",
+			hi.NCollHard, hi.NPartProj, hi.NPartTarg, hi.NColl,
+			hi.SpectatorNeutrons, hi.SpectatorProtons,
+			hi.NNwColl, hi.NwNColl, hi.NwNwColl,
+			f32toa(hi.ImpactParameter), f32toa(hi.EventPlaneAngle),
+			f32toa(hi.Eccentricity), f32toa(hi.SigmaInelNN),
+		))
+	}
+
+	// particles, ordered by barcode.
+	ps := make([]*hepmc.Particle, 0, len(evt.Particles))
+	for _, p := range evt.Particles {
+		ps = append(ps, p)
+	}
+	sort.Sort(hepmc.Particles(ps))
+
+	pmap := make(map[*hepmc.Particle]*Particle, len(ps))
+	for _, p := range ps {
+		pp := &Particle{
+			PID:           p.PdgID,
+			Status:        p.Status,
+			Momentum:      p.Momentum,
+			GeneratedMass: p.GeneratedMass,
+		}
+		err := o.AddParticle(pp)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc3: could not add particle %d: %w", p.Barcode, err)
+		}
+		pmap[p] = pp
+
+		o.SetAttribute(AttrBarcode, pp.ID, strconv.Itoa(p.Barcode))
+		if p.Polarization.Theta != 0 || p.Polarization.Phi != 0 {
+			o.SetAttribute(AttrTheta, pp.ID, ftoa(p.Polarization.Theta))
+			o.SetAttribute(AttrPhi, pp.ID, ftoa(p.Polarization.Phi))
+		}
+		for idx, code := range p.Flow.Icode {
+			o.SetAttribute(AttrFlow+strconv.Itoa(idx), pp.ID, strconv.Itoa(code))
+		}
+	}
+
+	// vertices, ordered by decreasing barcode (-1, -2, ...)
+	vs := make([]*hepmc.Vertex, 0, len(evt.Vertices))
+	for _, v := range evt.Vertices {
+		vs = append(vs, v)
+	}
+	sort.Slice(vs, func(i, j int) bool {
+		return vs[i].Barcode > vs[j].Barcode
+	})
+
+	vmap := make(map[*hepmc.Vertex]*Vertex, len(vs))
+	for _, v := range vs {
+		vv := &Vertex{
+			Status:   v.ID,
+			Position: v.Position,
+		}
+		err := o.AddVertex(vv)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc3: could not add vertex %d: %w", v.Barcode, err)
+		}
+		vmap[v] = vv
+
+		o.SetAttribute(AttrBarcode, vv.ID, strconv.Itoa(v.Barcode))
+		if len(v.Weights.Slice) > 0 {
+			o.SetAttribute(AttrWeights, vv.ID, joinf(v.Weights.Slice))
+		}
+
+		for _, p := range v.ParticlesIn {
+			pp, ok := pmap[p]
+			if !ok {
+				return nil, fmt.Errorf("hepmc3: unknown incoming particle %d for vertex %d", p.Barcode, v.Barcode)
+			}
+			err = vv.AddParticleIn(pp)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, p := range v.ParticlesOut {
+			pp, ok := pmap[p]
+			if !ok {
+				return nil, fmt.Errorf("hepmc3: unknown outgoing particle %d for vertex %d", p.Barcode, v.Barcode)
+			}
+			err = vv.AddParticleOut(pp)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if v := evt.SignalVertex; v != nil {
+		vv, ok := vmap[v]
+		if !ok {
+			return nil, fmt.Errorf("hepmc3: unknown signal vertex %d", v.Barcode)
+		}
+		o.SetAttribute(AttrSignalVertexID, 0, strconv.Itoa(vv.ID))
+	}
+	for i, name := range []string{AttrBeamParticle1, AttrBeamParticle2} {
+		p := evt.Beams[i]
+		if p == nil {
+			continue
+		}
+		pp, ok := pmap[p]
+		if !ok {
+			return nil, fmt.Errorf("hepmc3: unknown beam particle %d", p.Barcode)
+		}
+		o.SetAttribute(name, 0, strconv.Itoa(pp.ID))
+	}
+
+	return o, nil
+}
+
+// ToHepMC2 converts a HepMC3 event into a HepMC2 event.
+//
+// Attributes created by FromHepMC2 are used to restore the HepMC2 specific
+// information. Particles and vertices without a "barcode" attribute are
+// given their HepMC3 ID as barcode.
+// When no beam attributes are present, the first two particles with
+// status 4 are used as beam particles.
+func ToHepMC2(evt *Event) (*hepmc.Event, error) {
+	if evt == nil {
+		return nil, fmt.Errorf("hepmc3: nil HepMC3 event")
+	}
+
+	o := &hepmc.Event{
+		EventNumber:  evt.Number,
+		MomentumUnit: evt.MomentumUnit,
+		LengthUnit:   evt.LengthUnit,
+		Weights:      hepmc.NewWeights(),
+		Vertices:     make(map[int]*hepmc.Vertex, len(evt.Vertices)),
+		Particles:    make(map[int]*hepmc.Particle, len(evt.Particles)),
+	}
+
+	o.Weights.Slice = append(o.Weights.Slice, evt.Weights...)
+	if run := evt.RunInfo; run != nil {
+		if len(run.WeightNames) != len(evt.Weights) {
+			return nil, fmt.Errorf(
+				"hepmc3: inconsistent number of weights (run=%d, event=%d)",
+				len(run.WeightNames), len(evt.Weights),
+			)
+		}
+		for i, n := range run.WeightNames {
+			o.Weights.Map[n] = i
+		}
+	}
+
+	var err error
+	attr := func(name string, id int, fct func(v string) error) {
+		if err != nil {
+			return
+		}
+		v, ok := evt.Attribute(name, id)
+		if !ok {
+			return
+		}
+		e := fct(v)
+		if e != nil {
+			err = fmt.Errorf("hepmc3: could not decode attribute %q (id=%d, value=%q): %w", name, id, v, e)
+		}
+	}
+
+	attr(AttrSignalProcessID, 0, atoi(&o.SignalProcessID))
+	attr(AttrMPI, 0, atoi(&o.Mpi))
+	attr(AttrEventScale, 0, atof(&o.Scale))
+	attr(AttrAlphaQCD, 0, atof(&o.AlphaQCD))
+	attr(AttrAlphaQED, 0, atof(&o.AlphaQED))
+	attr(AttrRandomStates, 0, func(v string) error {
+		for _, tok := range strings.Fields(v) {
+			i, err := strconv.ParseInt(tok, 10, 64)
+			if err != nil {
+				return err
+			}
+			o.RandomStates = append(o.RandomStates, i)
+		}
+		return nil
+	})
+	var (
+		xs  CrossSection
+		pdf PdfInfo
+	)
+	if ok, e := evt.Attr(AttrCrossSection, 0, &xs); ok && e == nil {
+		o.CrossSection = &hepmc.CrossSection{Value: xs.Values[0], Error: xs.Errors[0]}
+	} else if e != nil {
+		return nil, e
+	}
+	if ok, e := evt.Attr(AttrPdfInfo, 0, &pdf); ok && e == nil {
+		o.PdfInfo = &hepmc.PdfInfo{
+			ID1: pdf.PartonID[0], ID2: pdf.PartonID[1],
+			X1: pdf.X[0], X2: pdf.X[1], ScalePDF: pdf.Scale,
+			Pdf1: pdf.XF[0], Pdf2: pdf.XF[1],
+			LHAPdf1: pdf.PdfID[0], LHAPdf2: pdf.PdfID[1],
+		}
+	} else if e != nil {
+		return nil, e
+	}
+	attr(AttrHeavyIon, 0, func(v string) error {
+		toks := strings.Fields(v)
+		if len(toks) < 13 {
+			return fmt.Errorf("invalid number of fields (%d)", len(toks))
+		}
+		var hi hepmc.HeavyIon
+		err := scan(
+			toks[:13],
+			&hi.NCollHard, &hi.NPartProj, &hi.NPartTarg, &hi.NColl,
+			&hi.SpectatorNeutrons, &hi.SpectatorProtons,
+			&hi.NNwColl, &hi.NwNColl, &hi.NwNwColl,
+			&hi.ImpactParameter, &hi.EventPlaneAngle,
+			&hi.Eccentricity, &hi.SigmaInelNN,
+		)
+		if err != nil {
+			return err
+		}
+		o.HeavyIon = &hi
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	barcode := func(id int) (int, error) {
+		v, ok := evt.Attribute(AttrBarcode, id)
+		if !ok {
+			return id, nil
+		}
+		return strconv.Atoi(v)
+	}
+
+	ps := make([]*hepmc.Particle, len(evt.Particles))
+	for i, p := range evt.Particles {
+		bc, err := barcode(p.ID)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc3: invalid barcode for particle %d: %w", p.ID, err)
+		}
+		if _, dup := o.Particles[bc]; dup {
+			return nil, fmt.Errorf("hepmc3: duplicate particle barcode %d", bc)
+		}
+		pp := &hepmc.Particle{
+			Momentum:      p.Momentum,
+			PdgID:         p.PID,
+			Status:        p.Status,
+			Barcode:       bc,
+			GeneratedMass: p.GeneratedMass,
+		}
+		pp.Flow.Particle = pp
+		attr(AttrTheta, p.ID, atof(&pp.Polarization.Theta))
+		attr(AttrPhi, p.ID, atof(&pp.Polarization.Phi))
+		for _, name := range evt.AttributeNames(p.ID) {
+			if !strings.HasPrefix(name, AttrFlow) {
+				continue
+			}
+			idx, e := strconv.Atoi(strings.TrimPrefix(name, AttrFlow))
+			if e != nil {
+				continue
+			}
+			attr(name, p.ID, func(v string) error {
+				code, err := strconv.Atoi(v)
+				if err != nil {
+					return err
+				}
+				if pp.Flow.Icode == nil {
+					pp.Flow.Icode = make(map[int]int)
+				}
+				pp.Flow.Icode[idx] = code
+				return nil
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		ps[i] = pp
+		o.Particles[bc] = pp
+	}
+
+	vs := make([]*hepmc.Vertex, len(evt.Vertices))
+	for i, v := range evt.Vertices {
+		bc, err := barcode(v.ID)
+		if err != nil {
+			return nil, fmt.Errorf("hepmc3: invalid barcode for vertex %d: %w", v.ID, err)
+		}
+		if _, dup := o.Vertices[bc]; dup {
+			return nil, fmt.Errorf("hepmc3: duplicate vertex barcode %d", bc)
+		}
+		vv := &hepmc.Vertex{
+			Position: v.Position,
+			ID:       v.Status,
+			Event:    o,
+			Barcode:  bc,
+		}
+		attr(AttrWeights, v.ID, func(s string) error {
+			for _, tok := range strings.Fields(s) {
+				w, err := strconv.ParseFloat(tok, 64)
+				if err != nil {
+					return err
+				}
+				vv.Weights.Slice = append(vv.Weights.Slice, w)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range v.ParticlesIn {
+			pp := ps[p.ID-1]
+			pp.EndVertex = vv
+			vv.ParticlesIn = append(vv.ParticlesIn, pp)
+		}
+		for _, p := range v.ParticlesOut {
+			pp := ps[p.ID-1]
+			pp.ProdVertex = vv
+			vv.ParticlesOut = append(vv.ParticlesOut, pp)
+		}
+		vs[i] = vv
+		o.Vertices[bc] = vv
+	}
+
+	vertex := func(v string) (*hepmc.Vertex, error) {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		if evt.Vertex(id) == nil {
+			return nil, fmt.Errorf("unknown vertex")
+		}
+		return vs[-id-1], nil
+	}
+	particle := func(v string) (*hepmc.Particle, error) {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		if evt.Particle(id) == nil {
+			return nil, fmt.Errorf("unknown particle")
+		}
+		return ps[id-1], nil
+	}
+
+	attr(AttrSignalVertexID, 0, func(v string) (err error) {
+		o.SignalVertex, err = vertex(v)
+		return err
+	})
+	attr(AttrBeamParticle1, 0, func(v string) (err error) {
+		o.Beams[0], err = particle(v)
+		return err
+	})
+	attr(AttrBeamParticle2, 0, func(v string) (err error) {
+		o.Beams[1], err = particle(v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Beams[0] == nil && o.Beams[1] == nil {
+		i := 0
+		for _, p := range ps {
+			if p.Status != 4 {
+				continue
+			}
+			o.Beams[i] = p
+			i++
+			if i == len(o.Beams) {
+				break
+			}
+		}
+	}
+
+	return o, nil
+}
+
+func ftoa(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func f32toa(v float32) string {
+	return strconv.FormatFloat(float64(v), 'g', -1, 32)
+}
+
+func atoi(ptr *int) func(v string) error {
+	return func(v string) (err error) {
+		*ptr, err = strconv.Atoi(v)
+		return err
+	}
+}
+
+func atof(ptr *float64) func(v string) error {
+	return func(v string) (err error) {
+		*ptr, err = strconv.ParseFloat(v, 64)
+		return err
+	}
+}
+
+// scan decodes the provided tokens into the provided pointers to
+// int, float32 or float64 values.
+func scan(toks []string, ptrs ...interface{}) error {
+	if len(toks) != len(ptrs) {
+		return fmt.Errorf("invalid number of fields (got=%d, want=%d)", len(toks), len(ptrs))
+	}
+	for i, ptr := range ptrs {
+		var err error
+		switch ptr := ptr.(type) {
+		case *int:
+			*ptr, err = strconv.Atoi(toks[i])
+		case *float32:
+			var v float64
+			v, err = strconv.ParseFloat(toks[i], 32)
+			*ptr = float32(v)
+		case *float64:
+			*ptr, err = strconv.ParseFloat(toks[i], 64)
+		default:
+			panic(fmt.Errorf("hepmc3: invalid pointer type %T", ptr))
+		}
+		if err != nil {
+			return fmt.Errorf("could not decode field %d (%q): %w", i, toks[i], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"go-hep.org/x/hep/hepmc"
+)
+
+func TestHepMC2RoundTrip(t *testing.T) {
+	for _, fname := range []string{
+		"../testdata/small.hepmc",
+		"../testdata/test.hepmc",
+	} {
+		t.Run(fname, func(t *testing.T) {
+			f, err := os.Open(fname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			var (
+				dec  = hepmc.NewDecoder(f)
+				run  = NewRunInfo()
+				want = new(bytes.Buffer)
+				got  = new(bytes.Buffer)
+				ewnt = hepmc.NewEncoder(want)
+				egot = hepmc.NewEncoder(got)
+			)
+
+			for i := 0; ; i++ {
+				var evt hepmc.Event
+				err := dec.Decode(&evt)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("could not decode event %d: %+v", i, err)
+				}
+
+				evt3, err := FromHepMC2(&evt, run)
+				if err != nil {
+					t.Fatalf("could not convert event %d to HepMC3: %+v", i, err)
+				}
+
+				if got, want := len(evt3.Particles), len(evt.Particles); got != want {
+					t.Fatalf("invalid number of particles: got=%d, want=%d", got, want)
+				}
+				if got, want := len(evt3.Vertices), len(evt.Vertices); got != want {
+					t.Fatalf("invalid number of vertices: got=%d, want=%d", got, want)
+				}
+
+				evt2, err := ToHepMC2(evt3)
+				if err != nil {
+					t.Fatalf("could not convert event %d to HepMC2: %+v", i, err)
+				}
+
+				err = ewnt.Encode(&evt)
+				if err != nil {
+					t.Fatalf("could not encode event %d: %+v", i, err)
+				}
+				err = egot.Encode(evt2)
+				if err != nil {
+					t.Fatalf("could not encode round-tripped event %d: %+v", i, err)
+				}
+			}
+
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Fatalf("round-trip failed:\ngot:\n%s\nwant:\n%s\n", got.Bytes(), want.Bytes())
+			}
+		})
+	}
+}
+
+func TestHepMC2Attributes(t *testing.T) {
+	evt := hepmc.Event{
+		SignalProcessID: 42,
+		EventNumber:     3,
+		Mpi:             2,
+		Scale:           91.2,
+		AlphaQCD:        0.118,
+		AlphaQED:        1. / 137,
+		Weights:         hepmc.NewWeights(),
+		RandomStates:    []int64{1, 2, 3},
+		Vertices:        make(map[int]*hepmc.Vertex),
+		Particles:       make(map[int]*hepmc.Particle),
+		CrossSection:    &hepmc.CrossSection{Value: 1.5, Error: 0.1},
+		HeavyIon: &hepmc.HeavyIon{
+			NCollHard: 1, NPartProj: 2, NPartTarg: 3, NColl: 4,
+			NNwColl: 5, NwNColl: 6, NwNwColl: 7,
+			SpectatorNeutrons: 8, SpectatorProtons: 9,
+			ImpactParameter: 1.1, EventPlaneAngle: 2.2,
+			Eccentricity: 0.3, SigmaInelNN: 70.1,
+		},
+		MomentumUnit: hepmc.GEV,
+		LengthUnit:   hepmc.CM,
+	}
+	_ = evt.Weights.Add("nominal", 1)
+	_ = evt.Weights.Add("muR=2", 0.5)
+
+	vtx := &hepmc.Vertex{ID: -7, Weights: hepmc.NewWeights()}
+	vtx.Weights.Slice = append(vtx.Weights.Slice, 0.25)
+	err := evt.AddVertex(vtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beam := &hepmc.Particle{PdgID: 2212, Status: 4}
+	beam.Flow.Particle = beam
+	err = vtx.AddParticleIn(beam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evt.Beams[0] = beam
+	evt.SignalVertex = vtx
+
+	out := &hepmc.Particle{
+		PdgID: 21, Status: 1,
+		Polarization: hepmc.Polarization{Theta: 0.5, Phi: 1.5},
+	}
+	out.Flow.Particle = out
+	out.Flow.Icode = map[int]int{1: 501, 2: 502}
+	err = vtx.AddParticleOut(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evt3, err := FromHepMC2(&evt, nil)
+	if err != nil {
+		t.Fatalf("could not convert to HepMC3: %+v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		id   int
+		want string
+	}{
+		{AttrSignalProcessID, 0, "42"},
+		{AttrMPI, 0, "2"},
+		{AttrEventScale, 0, "91.2"},
+		{AttrRandomStates, 0, "1 2 3"},
+		{AttrCrossSection, 0, "1.5 0.1 -1 -1"},
+		{AttrHeavyIon, 0, "1 2 3 4 8 9 5 6 7 1.1 2.2 0.3 70.1 0"},
+		{AttrSignalVertexID, 0, "-1"},
+		{AttrBeamParticle1, 0, "1"},
+		{AttrFlow + "1", 2, "501"},
+		{AttrFlow + "2", 2, "502"},
+		{AttrTheta, 2, "0.5"},
+		{AttrPhi, 2, "1.5"},
+		{AttrWeights, -1, "0.25"},
+	} {
+		got, ok := evt3.Attribute(tc.name, tc.id)
+		if !ok {
+			t.Errorf("missing attribute %q (id=%d)", tc.name, tc.id)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("invalid attribute %q (id=%d): got=%q, want=%q", tc.name, tc.id, got, tc.want)
+		}
+	}
+
+	if got, want := evt3.RunInfo.WeightNames, []string{"nominal", "muR=2"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("invalid weight names: got=%q, want=%q", got, want)
+	}
+	if got, want := evt3.Vertex(-1).Status, -7; got != want {
+		t.Fatalf("invalid vertex status: got=%d, want=%d", got, want)
+	}
+
+	evt2, err := ToHepMC2(evt3)
+	if err != nil {
+		t.Fatalf("could not convert to HepMC2: %+v", err)
+	}
+
+	var (
+		got  = new(bytes.Buffer)
+		want = new(bytes.Buffer)
+	)
+	err = evt.Print(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = evt2.Print(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Fatalf("round-trip failed:\ngot:\n%s\nwant:\n%s\n", got.Bytes(), want.Bytes())
+	}
+	if *evt2.HeavyIon != *evt.HeavyIon {
+		t.Fatalf("invalid heavy-ion round-trip:\ngot= %+v\nwant=%+v", *evt2.HeavyIon, *evt.HeavyIon)
+	}
+	if got, want := evt2.Beams[0].Flow.Icode, out.Flow.Icode; got != nil {
+		t.Fatalf("invalid beam flow: got=%v", got)
+	} else if got := evt2.Particles[out.Barcode].Flow.Icode; len(got) != len(want) || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("invalid flow: got=%v, want=%v", got, want)
+	}
+}
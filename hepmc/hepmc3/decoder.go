@@ -0,0 +1,486 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/hepmc"
+)
+
+// Decoder decodes HepMC3 events from a stream, using the HepMC3 ASCII
+// format (HepMC::Asciiv3).
+type Decoder struct {
+	r    io.Reader
+	rc   io.ReadCloser // decompressed stream
+	s    *bufio.Scanner
+	line string // current line
+	peek bool   // whether the current line has been pushed back
+	nl   int    // current line number
+
+	run *RunInfo // current run info
+
+	seenEvtHdr bool
+	done       bool
+}
+
+// NewDecoder returns a new HepMC3 Decoder that reads from the io.Reader.
+//
+// gzip, bzip2, xz and zstd compressed streams are transparently
+// decompressed.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// RunInfo returns the run info read so far from the stream, or nil.
+func (dec *Decoder) RunInfo() *RunInfo {
+	return dec.run
+}
+
+func (dec *Decoder) readline() (string, error) {
+	if dec.peek {
+		dec.peek = false
+		return dec.line, nil
+	}
+	if dec.s == nil {
+		rc, err := hepmc.NewReader(dec.r)
+		if err != nil {
+			return "", fmt.Errorf("hepmc3: could not open stream: %w", err)
+		}
+		dec.rc = rc
+		dec.s = bufio.NewScanner(rc)
+		dec.s.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	}
+	for dec.s.Scan() {
+		dec.nl++
+		line := strings.TrimSuffix(dec.s.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		dec.line = line
+		return line, nil
+	}
+	dec.release()
+	err := dec.s.Err()
+	if err != nil {
+		return "", fmt.Errorf("hepmc3: could not read line %d: %w", dec.nl+1, err)
+	}
+	return "", io.EOF
+}
+
+// release releases the resources held by the decompressor, if any.
+func (dec *Decoder) release() {
+	if dec.rc == nil {
+		return
+	}
+	_ = dec.rc.Close()
+	dec.rc = nil
+}
+
+func (dec *Decoder) unread() {
+	dec.peek = true
+}
+
+func (dec *Decoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("hepmc3: line %d: "+format, append([]interface{}{dec.nl}, args...)...)
+}
+
+// Decode reads the next event from the stream and stores it into evt.
+// Decode returns io.EOF when there are no more events in the stream.
+//
+// Events share the run info read from the stream, until a new run info
+// is encountered.
+func (dec *Decoder) Decode(evt *Event) error {
+	if dec.done {
+		return io.EOF
+	}
+
+	if !dec.seenEvtHdr {
+		err := dec.findHeader()
+		if err != nil {
+			return err
+		}
+		dec.seenEvtHdr = true
+	}
+
+	// run info and event header.
+	var newRun *RunInfo
+	run := func() *RunInfo {
+		if newRun == nil {
+			newRun = NewRunInfo()
+		}
+		return newRun
+	}
+	for {
+		line, err := dec.readline()
+		if err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+
+		switch line[0] {
+		case 'E':
+			dec.unread()
+		case 'W':
+			run().WeightNames = strings.Fields(unescape(rest(line, 1)))
+			continue
+		case 'T':
+			toks := strings.SplitN(unescape(rest(line, 1)), "\n", 3)
+			var tool Tool
+			for i, ptr := range []*string{&tool.Name, &tool.Version, &tool.Description} {
+				if i < len(toks) {
+					*ptr = toks[i]
+				}
+			}
+			run().Tools = append(run().Tools, tool)
+			continue
+		case 'A':
+			toks := strings.SplitN(line, " ", 3)
+			if len(toks) < 2 {
+				return dec.errorf("invalid run attribute %q", line)
+			}
+			run().Attributes[toks[1]] = unescape(rest(line, 2))
+			continue
+		default:
+			if line == endAsciiv3 {
+				dec.done = true
+				dec.release()
+				return io.EOF
+			}
+			if strings.HasPrefix(line, "HepMC::") {
+				// ignore additional headers (e.g. concatenated files.)
+				continue
+			}
+			return dec.errorf("unexpected line %q", line)
+		}
+		break
+	}
+	if newRun != nil {
+		dec.run = newRun
+	}
+
+	return dec.decodeEvent(evt)
+}
+
+func (dec *Decoder) findHeader() error {
+	for {
+		line, err := dec.readline()
+		if err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		switch {
+		case line == startAsciiv3:
+			return nil
+		case strings.HasPrefix(line, versionKey):
+			// no-op
+		case strings.HasPrefix(line, "HepMC::IO_GenEvent"), strings.HasPrefix(line, "HepMC::Asciiv2"):
+			return fmt.Errorf("hepmc3: HepMC2 stream (header=%q), use hepmc.NewDecoder", line)
+		default:
+			return dec.errorf("invalid HepMC3 header %q", line)
+		}
+	}
+}
+
+// vertexRecord holds the content of a 'V' line.
+type vertexRecord struct {
+	id  int
+	vtx *Vertex
+	in  []int
+}
+
+func (dec *Decoder) decodeEvent(evt *Event) error {
+	line, err := dec.readline()
+	if err != nil {
+		return err
+	}
+
+	*evt = *NewEvent()
+	evt.RunInfo = dec.run
+
+	var (
+		nvtx  int
+		npart int
+		toks  = strings.Fields(line)
+	)
+	switch {
+	case len(toks) == 4:
+		err = scan(toks[1:], &evt.Number, &nvtx, &npart)
+	case len(toks) == 9 && toks[4] == "@":
+		var pos fmom.Vec4
+		err = scan(
+			[]string{toks[1], toks[2], toks[3], toks[5], toks[6], toks[7], toks[8]},
+			&evt.Number, &nvtx, &npart, &pos.X, &pos.Y, &pos.Z, &pos.T,
+		)
+		evt.Position.P4 = pos
+	default:
+		err = fmt.Errorf("invalid number of fields")
+	}
+	if err == nil && (nvtx < 0 || npart < 0) {
+		err = fmt.Errorf("invalid number of vertices (%d) or particles (%d)", nvtx, npart)
+	}
+	if err != nil {
+		return dec.errorf("could not decode event header %q: %w", line, err)
+	}
+
+	var (
+		parts   = make([]*Particle, npart)
+		parents = make([]int, npart)
+		vtxs    []vertexRecord
+		body    bool // whether vertices or particles have been read
+	)
+
+loop:
+	for {
+		line, err := dec.readline()
+		if err != nil {
+			if err == io.EOF {
+				return dec.errorf("unexpected end of stream in event %d", evt.Number)
+			}
+			return err
+		}
+
+		switch line[0] {
+		case 'U':
+			toks := strings.Fields(line)
+			if len(toks) != 3 {
+				return dec.errorf("invalid units %q", line)
+			}
+			evt.MomentumUnit, err = hepmc.MomentumUnitFromString(toks[1])
+			if err != nil {
+				return dec.errorf("could not decode momentum unit: %w", err)
+			}
+			evt.LengthUnit, err = hepmc.LengthUnitFromString(toks[2])
+			if err != nil {
+				return dec.errorf("could not decode length unit: %w", err)
+			}
+
+		case 'W':
+			if body {
+				// run info of the next event.
+				dec.unread()
+				break loop
+			}
+			toks := strings.Fields(line)[1:]
+			evt.Weights = make([]float64, len(toks))
+			for i, tok := range toks {
+				evt.Weights[i], err = strconv.ParseFloat(tok, 64)
+				if err != nil {
+					return dec.errorf("could not decode weight %d: %w", i, err)
+				}
+			}
+
+		case 'A':
+			toks := strings.SplitN(line, " ", 4)
+			id, err := strconv.Atoi(toks[1])
+			if err != nil || len(toks) < 3 {
+				// run attribute of the next event.
+				dec.unread()
+				break loop
+			}
+			evt.SetAttribute(toks[2], id, unescape(rest(line, 3)))
+
+		case 'V':
+			body = true
+			rec, err := dec.decodeVertex(line)
+			if err != nil {
+				return err
+			}
+			if -rec.id > nvtx {
+				return dec.errorf("invalid vertex ID %d (nvertices=%d)", rec.id, nvtx)
+			}
+			vtxs = append(vtxs, rec)
+
+		case 'P':
+			body = true
+			var (
+				p      Particle
+				parent int
+				toks   = strings.Fields(line)
+				mom    fmom.Vec4
+			)
+			if len(toks) != 10 {
+				return dec.errorf("invalid particle %q", line)
+			}
+			err := scan(toks[1:3], &p.ID, &parent)
+			if err == nil {
+				p.PID, err = strconv.ParseInt(toks[3], 10, 64)
+			}
+			if err == nil {
+				err = scan(toks[4:], &mom.X, &mom.Y, &mom.Z, &mom.T, &p.GeneratedMass, &p.Status)
+			}
+			if err != nil {
+				return dec.errorf("could not decode particle %q: %w", line, err)
+			}
+			if p.ID <= 0 || p.ID > npart || parts[p.ID-1] != nil {
+				return dec.errorf("invalid particle ID %d (nparticles=%d)", p.ID, npart)
+			}
+			p.Momentum.P4 = mom
+			p.Event = evt
+			parts[p.ID-1] = &p
+			parents[p.ID-1] = parent
+
+		case 'E', 'T':
+			dec.unread()
+			break loop
+
+		default:
+			if line == endAsciiv3 {
+				dec.unread()
+				break loop
+			}
+			return dec.errorf("unexpected line %q in event %d", line, evt.Number)
+		}
+	}
+
+	for i, p := range parts {
+		if p == nil {
+			return dec.errorf("missing particle %d in event %d", i+1, evt.Number)
+		}
+	}
+	evt.Particles = parts
+	evt.Vertices = make([]*Vertex, nvtx)
+
+	// explicit vertices.
+	for _, rec := range vtxs {
+		if evt.Vertices[-rec.id-1] != nil {
+			return dec.errorf("duplicate vertex %d in event %d", rec.id, evt.Number)
+		}
+		evt.Vertices[-rec.id-1] = rec.vtx
+		rec.vtx.ID = rec.id
+		rec.vtx.Event = evt
+		for _, id := range rec.in {
+			p := evt.Particle(id)
+			if p == nil {
+				return dec.errorf("invalid incoming particle %d for vertex %d in event %d", id, rec.id, evt.Number)
+			}
+			p.EndVertex = rec.vtx
+			rec.vtx.ParticlesIn = append(rec.vtx.ParticlesIn, p)
+		}
+	}
+
+	// production vertices, implicit vertices taking the free IDs.
+	free := 0
+	for i, p := range parts {
+		var vtx *Vertex
+		switch parent := parents[i]; {
+		case parent == 0:
+			continue
+		case parent < 0:
+			vtx = evt.Vertex(parent)
+			if vtx == nil {
+				return dec.errorf("invalid production vertex %d for particle %d in event %d", parent, p.ID, evt.Number)
+			}
+		default:
+			mother := evt.Particle(parent)
+			if mother == nil {
+				return dec.errorf("invalid mother particle %d for particle %d in event %d", parent, p.ID, evt.Number)
+			}
+			vtx = mother.EndVertex
+			if vtx == nil {
+				for free < nvtx && evt.Vertices[free] != nil {
+					free++
+				}
+				if free == nvtx {
+					return dec.errorf("too many vertices in event %d (nvertices=%d)", evt.Number, nvtx)
+				}
+				vtx = &Vertex{
+					ID:          -(free + 1),
+					ParticlesIn: []*Particle{mother},
+					Event:       evt,
+				}
+				mother.EndVertex = vtx
+				evt.Vertices[free] = vtx
+			}
+		}
+		p.ProdVertex = vtx
+		vtx.ParticlesOut = append(vtx.ParticlesOut, p)
+	}
+
+	for i, vtx := range evt.Vertices {
+		if vtx == nil {
+			return dec.errorf("missing vertex %d in event %d", -(i + 1), evt.Number)
+		}
+	}
+
+	return nil
+}
+
+func (dec *Decoder) decodeVertex(line string) (vertexRecord, error) {
+	var (
+		rec  = vertexRecord{vtx: new(Vertex)}
+		toks = strings.Fields(line)
+		err  error
+	)
+	if len(toks) < 3 {
+		return rec, dec.errorf("invalid vertex %q", line)
+	}
+
+	rec.id, err = strconv.Atoi(toks[1])
+	if err != nil || rec.id >= 0 {
+		return rec, dec.errorf("invalid vertex ID in %q", line)
+	}
+	toks = toks[2:]
+
+	// the status of the vertex is absent from files written by
+	// HepMC3 versions prior to 3.1.
+	if !strings.HasPrefix(toks[0], "[") {
+		rec.vtx.Status, err = strconv.Atoi(toks[0])
+		if err != nil {
+			return rec, dec.errorf("could not decode vertex status in %q: %w", line, err)
+		}
+		toks = toks[1:]
+	}
+
+	if len(toks) == 0 || !strings.HasPrefix(toks[0], "[") || !strings.HasSuffix(toks[0], "]") {
+		return rec, dec.errorf("invalid incoming particles in %q", line)
+	}
+	if ids := strings.Trim(toks[0], "[]"); ids != "" {
+		for _, tok := range strings.Split(ids, ",") {
+			id, err := strconv.Atoi(tok)
+			if err != nil {
+				return rec, dec.errorf("could not decode incoming particle in %q: %w", line, err)
+			}
+			rec.in = append(rec.in, id)
+		}
+	}
+	toks = toks[1:]
+
+	switch len(toks) {
+	case 0:
+	case 5:
+		if toks[0] != "@" {
+			return rec, dec.errorf("invalid vertex position in %q", line)
+		}
+		var pos fmom.Vec4
+		err = scan(toks[1:], &pos.X, &pos.Y, &pos.Z, &pos.T)
+		if err != nil {
+			return rec, dec.errorf("could not decode vertex position in %q: %w", line, err)
+		}
+		rec.vtx.Position.P4 = pos
+	default:
+		return rec, dec.errorf("invalid vertex %q", line)
+	}
+
+	return rec, nil
+}
+
+// rest returns the content of the line after the n-th space-separated field.
+func rest(line string, n int) string {
+	toks := strings.SplitN(line, " ", n+1)
+	if len(toks) <= n {
+		return ""
+	}
+	return toks[n]
+}
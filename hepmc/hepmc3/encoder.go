@@ -0,0 +1,247 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-hep.org/x/hep/fmom"
+)
+
+// Encoder encodes HepMC3 events into a stream, using the HepMC3 ASCII
+// format (HepMC::Asciiv3).
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+	run *RunInfo // last run info written to the stream
+
+	seenEvtHdr bool
+}
+
+// NewEncoder returns a new HepMC3 Encoder that writes into the io.Writer.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Close closes the encoder and adds a footer to the stream.
+// Close does not close the underlying io.Writer.
+func (enc *Encoder) Close() error {
+	if !enc.seenEvtHdr {
+		return nil
+	}
+	_, err := fmt.Fprintf(enc.w, "%s\n", endAsciiv3)
+	if err != nil {
+		return fmt.Errorf("hepmc3: could not write footer: %w", err)
+	}
+	return nil
+}
+
+// Encode writes evt into the stream.
+//
+// The run info of the event is written before the event, unless it was
+// already written with a previous event.
+func (enc *Encoder) Encode(evt *Event) error {
+	enc.buf = enc.buf[:0]
+
+	if !enc.seenEvtHdr {
+		enc.buf = append(enc.buf, versionKey+" "+VersionName()+"\n"...)
+		enc.buf = append(enc.buf, startAsciiv3+"\n"...)
+		enc.seenEvtHdr = true
+	}
+
+	if evt.RunInfo != nil && evt.RunInfo != enc.run {
+		enc.encodeRunInfo(evt.RunInfo)
+		enc.run = evt.RunInfo
+	}
+
+	err := enc.encodeEvent(evt)
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.w.Write(enc.buf)
+	if err != nil {
+		return fmt.Errorf("hepmc3: could not write event %d: %w", evt.Number, err)
+	}
+	return nil
+}
+
+func (enc *Encoder) encodeRunInfo(run *RunInfo) {
+	if len(run.WeightNames) > 0 {
+		enc.buf = append(enc.buf, "W "...)
+		enc.buf = append(enc.buf, escape(strings.Join(run.WeightNames, "\n"))...)
+		enc.buf = append(enc.buf, '\n')
+	}
+
+	for _, tool := range run.Tools {
+		enc.buf = append(enc.buf, "T "...)
+		enc.buf = append(enc.buf, escape(tool.Name+"\n"+tool.Version+"\n"+tool.Description)...)
+		enc.buf = append(enc.buf, '\n')
+	}
+
+	names := make([]string, 0, len(run.Attributes))
+	for name := range run.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		enc.buf = append(enc.buf, "A "+name+" "...)
+		enc.buf = append(enc.buf, escape(run.Attributes[name])...)
+		enc.buf = append(enc.buf, '\n')
+	}
+}
+
+func (enc *Encoder) encodeEvent(evt *Event) error {
+	enc.buf = append(enc.buf, 'E', ' ')
+	enc.buf = strconv.AppendInt(enc.buf, int64(evt.Number), 10)
+	enc.buf = append(enc.buf, ' ')
+	enc.buf = strconv.AppendInt(enc.buf, int64(len(evt.Vertices)), 10)
+	enc.buf = append(enc.buf, ' ')
+	enc.buf = strconv.AppendInt(enc.buf, int64(len(evt.Particles)), 10)
+	if !isZero(evt.Position) {
+		enc.encodePosition(evt.Position)
+	}
+	enc.buf = append(enc.buf, '\n')
+
+	enc.buf = append(enc.buf, "U "+evt.MomentumUnit.String()+" "+evt.LengthUnit.String()+"\n"...)
+
+	if len(evt.Weights) > 0 {
+		enc.buf = append(enc.buf, 'W')
+		for _, w := range evt.Weights {
+			enc.buf = append(enc.buf, ' ')
+			enc.buf = appendFloat(enc.buf, w)
+		}
+		enc.buf = append(enc.buf, '\n')
+	}
+
+	// attributes are written sorted by name and then by ID.
+	names := make([]string, 0, len(evt.Attributes))
+	for name := range evt.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hasAttrs := make(map[int]bool)
+	for _, name := range names {
+		attrs := evt.Attributes[name]
+		ids := make([]int, 0, len(attrs))
+		for id := range attrs {
+			ids = append(ids, id)
+			hasAttrs[id] = true
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			enc.buf = append(enc.buf, 'A', ' ')
+			enc.buf = strconv.AppendInt(enc.buf, int64(id), 10)
+			enc.buf = append(enc.buf, ' ')
+			enc.buf = append(enc.buf, name...)
+			enc.buf = append(enc.buf, ' ')
+			enc.buf = append(enc.buf, escape(attrs[id])...)
+			enc.buf = append(enc.buf, '\n')
+		}
+	}
+
+	written := make(map[*Vertex]bool, len(evt.Vertices))
+	for i, p := range evt.Particles {
+		if p == nil {
+			return fmt.Errorf("hepmc3: nil particle %d in event %d", i+1, evt.Number)
+		}
+		if p.ID != i+1 {
+			return fmt.Errorf("hepmc3: invalid ID %d for particle %d in event %d", p.ID, i+1, evt.Number)
+		}
+
+		// the production vertex of a particle is only written when it
+		// can not be inferred from its single incoming particle.
+		parent := 0
+		if vtx := p.ProdVertex; vtx != nil {
+			switch {
+			case len(vtx.ParticlesIn) == 1 && vtx.Status == 0 && isZero(vtx.Position) && !hasAttrs[vtx.ID]:
+				parent = vtx.ParticlesIn[0].ID
+			default:
+				parent = vtx.ID
+				if !written[vtx] {
+					enc.encodeVertex(vtx)
+					written[vtx] = true
+				}
+			}
+		}
+		enc.encodeParticle(p, parent)
+	}
+
+	// vertices without outgoing particles.
+	for i, vtx := range evt.Vertices {
+		if vtx == nil {
+			return fmt.Errorf("hepmc3: nil vertex %d in event %d", -(i + 1), evt.Number)
+		}
+		if vtx.ID != -(i + 1) {
+			return fmt.Errorf("hepmc3: invalid ID %d for vertex %d in event %d", vtx.ID, -(i + 1), evt.Number)
+		}
+		if written[vtx] || len(vtx.ParticlesOut) > 0 {
+			continue
+		}
+		enc.encodeVertex(vtx)
+	}
+
+	return nil
+}
+
+func (enc *Encoder) encodeVertex(vtx *Vertex) {
+	enc.buf = append(enc.buf, 'V', ' ')
+	enc.buf = strconv.AppendInt(enc.buf, int64(vtx.ID), 10)
+	enc.buf = append(enc.buf, ' ')
+	enc.buf = strconv.AppendInt(enc.buf, int64(vtx.Status), 10)
+	enc.buf = append(enc.buf, ' ', '[')
+	for i, p := range vtx.ParticlesIn {
+		if i > 0 {
+			enc.buf = append(enc.buf, ',')
+		}
+		enc.buf = strconv.AppendInt(enc.buf, int64(p.ID), 10)
+	}
+	enc.buf = append(enc.buf, ']')
+	if !isZero(vtx.Position) {
+		enc.encodePosition(vtx.Position)
+	}
+	enc.buf = append(enc.buf, '\n')
+}
+
+func (enc *Encoder) encodeParticle(p *Particle, parent int) {
+	enc.buf = append(enc.buf, 'P', ' ')
+	enc.buf = strconv.AppendInt(enc.buf, int64(p.ID), 10)
+	enc.buf = append(enc.buf, ' ')
+	enc.buf = strconv.AppendInt(enc.buf, int64(parent), 10)
+	enc.buf = append(enc.buf, ' ')
+	enc.buf = strconv.AppendInt(enc.buf, p.PID, 10)
+	for _, v := range []float64{
+		p.Momentum.Px(), p.Momentum.Py(), p.Momentum.Pz(), p.Momentum.E(),
+		p.GeneratedMass,
+	} {
+		enc.buf = append(enc.buf, ' ')
+		enc.buf = appendFloat(enc.buf, v)
+	}
+	enc.buf = append(enc.buf, ' ')
+	enc.buf = strconv.AppendInt(enc.buf, int64(p.Status), 10)
+	enc.buf = append(enc.buf, '\n')
+}
+
+func (enc *Encoder) encodePosition(pos fmom.PxPyPzE) {
+	enc.buf = append(enc.buf, " @"...)
+	for _, v := range []float64{pos.X(), pos.Y(), pos.Z(), pos.T()} {
+		enc.buf = append(enc.buf, ' ')
+		enc.buf = appendFloat(enc.buf, v)
+	}
+}
+
+// appendFloat appends v with the precision used by the HepMC3 C++ library,
+// allowing a lossless round-trip.
+func appendFloat(buf []byte, v float64) []byte {
+	return strconv.AppendFloat(buf, v, 'e', 16, 64)
+}
+
+func isZero(p4 fmom.PxPyPzE) bool {
+	return p4 == fmom.PxPyPzE{}
+}
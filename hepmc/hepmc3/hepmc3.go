@@ -0,0 +1,267 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hepmc3 implements the HepMC3 event record model.
+//
+// In HepMC3, particles and vertices are identified by their position in
+// the event: the i-th particle has ID i+1 while the i-th vertex has ID -(i+1).
+// Additional information is attached to the event, its particles or its
+// vertices via attributes, stored in their string representation.
+// Typed attributes (integers, floats, strings, vectors thereof, cross-sections
+// and PDF information) are encoded and decoded with SetAttr and Attr.
+//
+// Events are read from and written to streams in the HepMC3 ASCII format
+// (HepMC::Asciiv3) with a Decoder and an Encoder.
+// Legacy HepMC2 streams are handled by the hepmc package, and can be
+// converted with FromHepMC2 and ToHepMC2.
+package hepmc3 // import "go-hep.org/x/hep/hepmc/hepmc3"
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/hepmc"
+)
+
+var (
+	errNilVtx      = errors.New("hepmc3: nil Vertex")
+	errNilParticle = errors.New("hepmc3: nil Particle")
+)
+
+// Tool describes a tool (e.g. a generator) used to produce events.
+type Tool struct {
+	Name        string
+	Version     string
+	Description string
+}
+
+// RunInfo holds run-level information shared by a set of events.
+type RunInfo struct {
+	Tools       []Tool            // tools used to produce the events
+	WeightNames []string          // names of the event weights
+	Attributes  map[string]string // run-level attributes
+}
+
+// NewRunInfo creates a new, empty, run info.
+func NewRunInfo() *RunInfo {
+	return &RunInfo{
+		Attributes: make(map[string]string),
+	}
+}
+
+// WeightIndex returns the index of the named weight, or -1 if not found.
+func (run *RunInfo) WeightIndex(name string) int {
+	if run == nil {
+		return -1
+	}
+	for i, n := range run.WeightNames {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Event represents a record for MC generators.
+type Event struct {
+	Number       int                // event number
+	MomentumUnit hepmc.MomentumUnit // momentum unit
+	LengthUnit   hepmc.LengthUnit   // length unit
+	Position     fmom.PxPyPzE       // position offset of the event
+	Weights      []float64          // event weights, as named by the run info
+	RunInfo      *RunInfo           // run info associated with this event
+
+	Particles []*Particle // particles of this event, ordered by ID
+	Vertices  []*Vertex   // vertices of this event, ordered by decreasing ID
+
+	// Attributes holds the attributes of the event, its particles and
+	// vertices, indexed by attribute name and then by object ID.
+	// Event-level attributes are associated with ID 0.
+	Attributes map[string]map[int]string
+}
+
+// NewEvent creates a new, empty, event.
+func NewEvent() *Event {
+	return &Event{
+		MomentumUnit: hepmc.GEV,
+		LengthUnit:   hepmc.MM,
+		Attributes:   make(map[string]map[int]string),
+	}
+}
+
+// AddParticle adds the provided particle to the event and assigns it an ID.
+func (evt *Event) AddParticle(p *Particle) error {
+	if p == nil {
+		return errNilParticle
+	}
+	if p.Event != nil {
+		return fmt.Errorf("hepmc3: particle %d already attached to an event", p.ID)
+	}
+	p.Event = evt
+	p.ID = len(evt.Particles) + 1
+	evt.Particles = append(evt.Particles, p)
+	return nil
+}
+
+// AddVertex adds the provided vertex to the event and assigns it an ID.
+// Particles attached to the vertex are added to the event as well.
+func (evt *Event) AddVertex(vtx *Vertex) error {
+	if vtx == nil {
+		return errNilVtx
+	}
+	if vtx.Event != nil {
+		return fmt.Errorf("hepmc3: vertex %d already attached to an event", vtx.ID)
+	}
+	vtx.Event = evt
+	vtx.ID = -(len(evt.Vertices) + 1)
+	evt.Vertices = append(evt.Vertices, vtx)
+
+	for _, ps := range [][]*Particle{vtx.ParticlesIn, vtx.ParticlesOut} {
+		for _, p := range ps {
+			if p.Event != nil {
+				continue
+			}
+			err := evt.AddParticle(p)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Particle returns the particle with the provided ID, or nil.
+func (evt *Event) Particle(id int) *Particle {
+	if id <= 0 || id > len(evt.Particles) {
+		return nil
+	}
+	return evt.Particles[id-1]
+}
+
+// Vertex returns the vertex with the provided ID, or nil.
+func (evt *Event) Vertex(id int) *Vertex {
+	if id >= 0 || -id > len(evt.Vertices) {
+		return nil
+	}
+	return evt.Vertices[-id-1]
+}
+
+// Attribute returns the string representation of the named attribute
+// attached to the object with the provided ID (0 for the event itself),
+// and whether it was found.
+func (evt *Event) Attribute(name string, id int) (string, bool) {
+	attrs, ok := evt.Attributes[name]
+	if !ok {
+		return "", false
+	}
+	v, ok := attrs[id]
+	return v, ok
+}
+
+// SetAttribute attaches the named attribute, in its string representation,
+// to the object with the provided ID (0 for the event itself).
+func (evt *Event) SetAttribute(name string, id int, v string) {
+	if evt.Attributes == nil {
+		evt.Attributes = make(map[string]map[int]string)
+	}
+	attrs, ok := evt.Attributes[name]
+	if !ok {
+		attrs = make(map[int]string)
+		evt.Attributes[name] = attrs
+	}
+	attrs[id] = v
+}
+
+// RemoveAttribute removes the named attribute attached to the object
+// with the provided ID.
+func (evt *Event) RemoveAttribute(name string, id int) {
+	attrs, ok := evt.Attributes[name]
+	if !ok {
+		return
+	}
+	delete(attrs, id)
+	if len(attrs) == 0 {
+		delete(evt.Attributes, name)
+	}
+}
+
+// AttributeNames returns the sorted list of attribute names attached to
+// the object with the provided ID.
+func (evt *Event) AttributeNames(id int) []string {
+	var names []string
+	for name, attrs := range evt.Attributes {
+		if _, ok := attrs[id]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Particle represents a generator particle within an event.
+type Particle struct {
+	ID            int          // unique identifier in the event (1-based)
+	PID           int64        // id according to PDG convention
+	Status        int          // status code
+	Momentum      fmom.PxPyPzE // momentum vector
+	GeneratedMass float64      // mass of this particle when it was generated
+	ProdVertex    *Vertex      // production vertex (nil if beam)
+	EndVertex     *Vertex      // decay vertex (nil if not-decayed)
+	Event         *Event       // event owning this particle
+}
+
+// Vertex represents a generator vertex within an event.
+type Vertex struct {
+	ID           int          // unique identifier in the event (negative)
+	Status       int          // status code
+	Position     fmom.PxPyPzE // 4-vector of the vertex
+	ParticlesIn  []*Particle  // all incoming particles
+	ParticlesOut []*Particle  // all outgoing particles
+	Event        *Event       // event owning this vertex
+}
+
+// AddParticleIn adds a particle to the list of incoming particles to
+// this vertex.
+func (vtx *Vertex) AddParticleIn(p *Particle) error {
+	if p == nil {
+		return errNilParticle
+	}
+	if p.EndVertex != nil && p.EndVertex != vtx {
+		p.EndVertex.ParticlesIn = removeParticle(p.EndVertex.ParticlesIn, p)
+	}
+	p.EndVertex = vtx
+	vtx.ParticlesIn = append(removeParticle(vtx.ParticlesIn, p), p)
+	if vtx.Event != nil && p.Event == nil {
+		return vtx.Event.AddParticle(p)
+	}
+	return nil
+}
+
+// AddParticleOut adds a particle to the list of outgoing particles from
+// this vertex.
+func (vtx *Vertex) AddParticleOut(p *Particle) error {
+	if p == nil {
+		return errNilParticle
+	}
+	if p.ProdVertex != nil && p.ProdVertex != vtx {
+		p.ProdVertex.ParticlesOut = removeParticle(p.ProdVertex.ParticlesOut, p)
+	}
+	p.ProdVertex = vtx
+	vtx.ParticlesOut = append(removeParticle(vtx.ParticlesOut, p), p)
+	if vtx.Event != nil && p.Event == nil {
+		return vtx.Event.AddParticle(p)
+	}
+	return nil
+}
+
+func removeParticle(ps []*Particle, p *Particle) []*Particle {
+	for i, pp := range ps {
+		if pp == p {
+			return append(ps[:i], ps[i+1:]...)
+		}
+	}
+	return ps
+}
@@ -0,0 +1,47 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/hepmc"
+)
+
+// ConvertUnits converts the event to the provided momentum and length
+// units.
+// Momenta and generated masses of all the particles, and positions of the
+// event and of all the vertices are rescaled accordingly.
+// Attributes are left untouched.
+func ConvertUnits(evt *Event, mu hepmc.MomentumUnit, lu hepmc.LengthUnit) error {
+	pf, err := evt.MomentumUnit.ConversionFactor(mu)
+	if err != nil {
+		return err
+	}
+	lf, err := evt.LengthUnit.ConversionFactor(lu)
+	if err != nil {
+		return err
+	}
+
+	if pf != 1 {
+		for _, p := range evt.Particles {
+			p.Momentum = scale(p.Momentum, pf)
+			p.GeneratedMass *= pf
+		}
+	}
+	if lf != 1 {
+		evt.Position = scale(evt.Position, lf)
+		for _, vtx := range evt.Vertices {
+			vtx.Position = scale(vtx.Position, lf)
+		}
+	}
+
+	evt.MomentumUnit = mu
+	evt.LengthUnit = lu
+	return nil
+}
+
+func scale(p4 fmom.PxPyPzE, f float64) fmom.PxPyPzE {
+	return fmom.NewPxPyPzE(f*p4.Px(), f*p4.Py(), f*p4.Pz(), f*p4.E())
+}
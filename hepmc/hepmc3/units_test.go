@@ -0,0 +1,57 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc3
+
+import (
+	"testing"
+
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/hepmc"
+)
+
+func TestConvertUnits(t *testing.T) {
+	evt := NewEvent()
+	evt.Position = fmom.NewPxPyPzE(1, 2, 3, 4)
+	vtx := &Vertex{Position: fmom.NewPxPyPzE(10, 20, 30, 40)}
+	p := &Particle{
+		PID:           11,
+		Momentum:      fmom.NewPxPyPzE(1, 2, 3, 5),
+		GeneratedMass: 0.5,
+	}
+	err := vtx.AddParticleOut(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = evt.AddVertex(vtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ConvertUnits(evt, hepmc.MEV, hepmc.CM)
+	if err != nil {
+		t.Fatalf("could not convert units: %+v", err)
+	}
+
+	if got, want := evt.Position, fmom.NewPxPyPzE(0.1, 0.2, 0.30000000000000004, 0.4); got != want {
+		t.Fatalf("invalid event position: got=%v, want=%v", got, want)
+	}
+	if got, want := vtx.Position, fmom.NewPxPyPzE(1, 2, 3, 4); got != want {
+		t.Fatalf("invalid vertex position: got=%v, want=%v", got, want)
+	}
+	if got, want := p.Momentum, fmom.NewPxPyPzE(1e3, 2e3, 3e3, 5e3); got != want {
+		t.Fatalf("invalid momentum: got=%v, want=%v", got, want)
+	}
+	if got, want := p.GeneratedMass, 500.0; got != want {
+		t.Fatalf("invalid generated mass: got=%v, want=%v", got, want)
+	}
+	if evt.MomentumUnit != hepmc.MEV || evt.LengthUnit != hepmc.CM {
+		t.Fatalf("invalid units: got=(%v, %v)", evt.MomentumUnit, evt.LengthUnit)
+	}
+
+	err = ConvertUnits(evt, hepmc.MEV, hepmc.LengthUnit(42))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
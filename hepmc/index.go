@@ -0,0 +1,295 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Index holds the byte offsets of the events of an uncompressed HepMC
+// stream, allowing random access to these events.
+type Index struct {
+	Key     string  // start key of the event listing
+	Offsets []int64 // byte offsets of the events
+	End     int64   // byte offset of the end of the last event
+	Size    int64   // size of the indexed stream
+}
+
+// NewIndex scans the provided uncompressed HepMC stream and records the
+// byte offsets of its events.
+func NewIndex(r io.Reader) (*Index, error) {
+	var (
+		idx = &Index{End: -1}
+		br  = bufio.NewReader(r)
+		pos int64
+		beg = true // whether we are at the beginning of a line
+	)
+	for {
+		line, err := br.ReadSlice('\n')
+		if len(line) > 0 && beg {
+			switch {
+			case line[0] == 'E' && idx.Key != "":
+				idx.Offsets = append(idx.Offsets, pos)
+			case bytes.HasPrefix(line, []byte("HepMC::")):
+				key := string(bytes.TrimSpace(line))
+				switch {
+				case strings.HasSuffix(key, "-START_EVENT_LISTING"):
+					if idx.Key == "" {
+						idx.Key = key
+					}
+				case strings.HasSuffix(key, "-END_EVENT_LISTING"):
+					if idx.Key != "" && idx.End < 0 {
+						idx.End = pos
+					}
+				}
+			}
+		}
+		pos += int64(len(line))
+		beg = len(line) > 0 && line[len(line)-1] == '\n'
+
+		switch err {
+		case nil, bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			if idx.Key == "" {
+				return nil, fmt.Errorf("hepmc: could not find event listing start key")
+			}
+			if idx.End < 0 {
+				idx.End = pos
+			}
+			idx.Size = pos
+			return idx, nil
+		default:
+			return nil, fmt.Errorf("hepmc: could not index stream: %w", err)
+		}
+	}
+}
+
+// Len returns the number of indexed events.
+func (idx *Index) Len() int {
+	return len(idx.Offsets)
+}
+
+// section returns the byte range spanned by the events [beg, end).
+func (idx *Index) section(beg, end int) (int64, int64, error) {
+	if beg < 0 || end > len(idx.Offsets) || beg > end {
+		return 0, 0, fmt.Errorf("hepmc: invalid event range [%d, %d) (nevts=%d)", beg, end, len(idx.Offsets))
+	}
+	if beg == end {
+		return 0, 0, nil
+	}
+	start := idx.Offsets[beg]
+	stop := idx.End
+	if end < len(idx.Offsets) {
+		stop = idx.Offsets[end]
+	}
+	return start, stop, nil
+}
+
+var indexMagic = [8]byte{'H', 'E', 'P', 'M', 'C', 'I', 'D', 'X'}
+
+const indexVersion = 1
+
+// MarshalBinary encodes the index into a binary form.
+func (idx *Index) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.Write(indexMagic[:])
+	for _, v := range []interface{}{
+		uint32(indexVersion),
+		int64(idx.Size),
+		int64(idx.End),
+		uint32(len(idx.Key)),
+	} {
+		_ = binary.Write(buf, binary.LittleEndian, v)
+	}
+	buf.WriteString(idx.Key)
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(idx.Offsets)))
+	_ = binary.Write(buf, binary.LittleEndian, idx.Offsets)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes the index from its binary form.
+func (idx *Index) UnmarshalBinary(p []byte) error {
+	r := bytes.NewReader(p)
+
+	var magic [8]byte
+	_, err := io.ReadFull(r, magic[:])
+	if err != nil || magic != indexMagic {
+		return fmt.Errorf("hepmc: invalid index header")
+	}
+
+	var (
+		vers uint32
+		klen uint32
+		n    uint64
+		o    Index
+	)
+	for _, ptr := range []interface{}{&vers, &o.Size, &o.End, &klen} {
+		err = binary.Read(r, binary.LittleEndian, ptr)
+		if err != nil {
+			return fmt.Errorf("hepmc: could not read index header: %w", err)
+		}
+	}
+	if vers != indexVersion {
+		return fmt.Errorf("hepmc: invalid index version (got=%d, want=%d)", vers, indexVersion)
+	}
+	if int64(klen) > int64(r.Len()) {
+		return fmt.Errorf("hepmc: invalid index key length (%d)", klen)
+	}
+	key := make([]byte, klen)
+	_, err = io.ReadFull(r, key)
+	if err != nil {
+		return fmt.Errorf("hepmc: could not read index key: %w", err)
+	}
+	o.Key = string(key)
+
+	err = binary.Read(r, binary.LittleEndian, &n)
+	if err != nil {
+		return fmt.Errorf("hepmc: could not read number of indexed events: %w", err)
+	}
+	if n*8 != uint64(r.Len()) {
+		return fmt.Errorf("hepmc: invalid number of indexed events (%d)", n)
+	}
+	o.Offsets = make([]int64, n)
+	err = binary.Read(r, binary.LittleEndian, o.Offsets)
+	if err != nil {
+		return fmt.Errorf("hepmc: could not read index offsets: %w", err)
+	}
+
+	*idx = o
+	return nil
+}
+
+// IndexName returns the name of the index file associated with the
+// provided HepMC file name.
+func IndexName(fname string) string {
+	return fname + ".idx"
+}
+
+// IndexedFile gives random access to the events of an uncompressed HepMC
+// file.
+//
+// IndexedFile is safe for concurrent use: decoders over disjoint ranges of
+// events may be used from multiple goroutines to process a file in parallel.
+type IndexedFile struct {
+	f   *os.File
+	idx *Index
+}
+
+// OpenIndexed opens the named uncompressed HepMC file for random access.
+//
+// The index of the file is loaded from its sidecar file (see IndexName) when
+// it exists and is up-to-date.
+// Otherwise, the file is scanned and the index is saved in the sidecar file,
+// if possible.
+func OpenIndexed(fname string) (*IndexedFile, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := loadIndex(f, fname)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &IndexedFile{f: f, idx: idx}, nil
+}
+
+func loadIndex(f *os.File, fname string) (*Index, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("hepmc: could not stat %q: %w", fname, err)
+	}
+
+	iname := IndexName(fname)
+	if ii, err := os.Stat(iname); err == nil && !ii.ModTime().Before(fi.ModTime()) {
+		raw, err := os.ReadFile(iname)
+		if err == nil {
+			var idx Index
+			if err := idx.UnmarshalBinary(raw); err == nil && idx.Size == fi.Size() {
+				return &idx, nil
+			}
+		}
+	}
+
+	c, err := compressionOf(bufio.NewReader(io.NewSectionReader(f, 0, fi.Size())))
+	if err != nil {
+		return nil, fmt.Errorf("hepmc: could not read %q: %w", fname, err)
+	}
+	if c != NoCompression {
+		return nil, fmt.Errorf("hepmc: could not index %v compressed file %q", c, fname)
+	}
+
+	idx, err := NewIndex(io.NewSectionReader(f, 0, fi.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("hepmc: could not index %q: %w", fname, err)
+	}
+
+	// the sidecar file is a cache: failing to write it (e.g. because of a
+	// read-only directory) is not an error.
+	raw, err := idx.MarshalBinary()
+	if err == nil {
+		_ = os.WriteFile(iname, raw, 0644)
+	}
+
+	return idx, nil
+}
+
+// Index returns the index of the file.
+func (f *IndexedFile) Index() *Index {
+	return f.idx
+}
+
+// Len returns the number of events in the file.
+func (f *IndexedFile) Len() int {
+	return f.idx.Len()
+}
+
+// Decoder returns a decoder reading the events [beg, end) of the file.
+// The decoder returns io.EOF after the last event of the range.
+func (f *IndexedFile) Decoder(beg, end int) (*Decoder, error) {
+	start, stop, err := f.idx.section(beg, end)
+	if err != nil {
+		return nil, err
+	}
+	r := io.MultiReader(
+		strings.NewReader(f.idx.Key+"\n"),
+		io.NewSectionReader(f.f, start, stop-start),
+	)
+	return NewDecoder(r), nil
+}
+
+// ReadEvent reads the i-th event of the file into evt.
+func (f *IndexedFile) ReadEvent(i int, evt *Event) error {
+	dec, err := f.Decoder(i, i+1)
+	if err != nil {
+		return err
+	}
+	err = dec.Decode(evt)
+	if err != nil {
+		return fmt.Errorf("hepmc: could not decode event %d: %w", i, err)
+	}
+
+	// drain the decoder.
+	var tail Event
+	err = dec.Decode(&tail)
+	if err != io.EOF {
+		return fmt.Errorf("hepmc: invalid index for event %d", i)
+	}
+	return nil
+}
+
+// Close closes the underlying file.
+func (f *IndexedFile) Close() error {
+	return f.f.Close()
+}
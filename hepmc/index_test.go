@@ -0,0 +1,223 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"go-hep.org/x/hep/hepmc"
+)
+
+func TestIndex(t *testing.T) {
+	raw, err := os.ReadFile("testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := hepmc.NewIndex(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("could not build index: %+v", err)
+	}
+	if got, want := idx.Len(), 6; got != want {
+		t.Fatalf("invalid number of events: got=%d, want=%d", got, want)
+	}
+	if got, want := idx.Key, "HepMC::IO_GenEvent-START_EVENT_LISTING"; got != want {
+		t.Fatalf("invalid key: got=%q, want=%q", got, want)
+	}
+	if got, want := idx.Size, int64(len(raw)); got != want {
+		t.Fatalf("invalid size: got=%d, want=%d", got, want)
+	}
+	for i, off := range idx.Offsets {
+		if !bytes.HasPrefix(raw[off:], []byte("E ")) {
+			t.Fatalf("event %d: invalid offset %d", i, off)
+		}
+	}
+	if !bytes.HasPrefix(raw[idx.End:], []byte("HepMC::IO_GenEvent-END_EVENT_LISTING")) {
+		t.Fatalf("invalid end offset %d", idx.End)
+	}
+
+	bin, err := idx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not marshal index: %+v", err)
+	}
+	var got hepmc.Index
+	err = got.UnmarshalBinary(bin)
+	if err != nil {
+		t.Fatalf("could not unmarshal index: %+v", err)
+	}
+	if !reflect.DeepEqual(&got, idx) {
+		t.Fatalf("invalid index round-trip:\ngot= %+v\nwant=%+v", got, *idx)
+	}
+
+	for _, bin := range [][]byte{
+		nil,
+		[]byte("HEPMCIDY"),
+		bin[:20],
+		bin[:len(bin)-1],
+	} {
+		err = got.UnmarshalBinary(bin)
+		if err == nil {
+			t.Fatalf("expected an error for %q", bin)
+		}
+	}
+
+	_, err = hepmc.NewIndex(bytes.NewReader([]byte("E 1 2 3\n")))
+	if err == nil {
+		t.Fatalf("expected an error for a stream without start key")
+	}
+}
+
+func TestIndexedFile(t *testing.T) {
+	raw, err := os.ReadFile("testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := printAll(t, decodeAll(t, bytes.NewReader(raw)))
+
+	fname := filepath.Join(t.TempDir(), "test.hepmc")
+	err = os.WriteFile(fname, raw, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"build", "sidecar"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := hepmc.OpenIndexed(fname)
+			if err != nil {
+				t.Fatalf("could not open file: %+v", err)
+			}
+			defer f.Close()
+
+			if _, err := os.Stat(hepmc.IndexName(fname)); err != nil {
+				t.Fatalf("could not find sidecar index file: %+v", err)
+			}
+
+			if got, want := f.Len(), len(want); got != want {
+				t.Fatalf("invalid number of events: got=%d, want=%d", got, want)
+			}
+
+			for i := f.Len() - 1; i >= 0; i-- {
+				var evt hepmc.Event
+				err = f.ReadEvent(i, &evt)
+				if err != nil {
+					t.Fatalf("could not read event %d: %+v", i, err)
+				}
+				if got := printAll(t, []*hepmc.Event{&evt}); got[0] != want[i] {
+					t.Fatalf("invalid event %d:\ngot:\n%s\nwant:\n%s\n", i, got[0], want[i])
+				}
+			}
+
+			for _, i := range []int{-1, f.Len()} {
+				var evt hepmc.Event
+				err = f.ReadEvent(i, &evt)
+				if err == nil {
+					t.Fatalf("expected an error reading event %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestIndexedFileParallel(t *testing.T) {
+	f, err := hepmc.OpenIndexed(copyFile(t, "testdata/test.hepmc"))
+	if err != nil {
+		t.Fatalf("could not open file: %+v", err)
+	}
+	defer f.Close()
+
+	const chunk = 2
+	var (
+		wg   sync.WaitGroup
+		nevt = make([]int, (f.Len()+chunk-1)/chunk)
+		errs = make([]error, len(nevt))
+	)
+	for i := range nevt {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			beg := i * chunk
+			end := beg + chunk
+			if end > f.Len() {
+				end = f.Len()
+			}
+			dec, err := f.Decoder(beg, end)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for {
+				var evt hepmc.Event
+				err := dec.Decode(&evt)
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				if got, want := evt.EventNumber, beg+nevt[i]; got != want {
+					t.Errorf("chunk %d: invalid event number: got=%d, want=%d", i, got, want)
+				}
+				nevt[i]++
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	n := 0
+	for i := range nevt {
+		if errs[i] != nil {
+			t.Fatalf("chunk %d: %+v", i, errs[i])
+		}
+		n += nevt[i]
+	}
+	if n != f.Len() {
+		t.Fatalf("invalid number of events: got=%d, want=%d", n, f.Len())
+	}
+}
+
+func TestIndexedFileCompressed(t *testing.T) {
+	fname := copyFile(t, "testdata/small.hepmc.bz2")
+	_, err := hepmc.OpenIndexed(fname)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func copyFile(t *testing.T, src string) string {
+	t.Helper()
+
+	raw, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), filepath.Base(src))
+	err = os.WriteFile(dst, raw, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func printAll(t *testing.T, evts []*hepmc.Event) []string {
+	t.Helper()
+
+	o := make([]string, len(evts))
+	for i, evt := range evts {
+		buf := new(bytes.Buffer)
+		err := evt.Print(buf)
+		if err != nil {
+			t.Fatalf("could not print event %d: %+v", i, err)
+		}
+		o[i] = buf.String()
+	}
+	return o
+}
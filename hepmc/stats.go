@@ -0,0 +1,92 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc
+
+import (
+	"math"
+)
+
+// Stats summarizes run-level informations accumulated over a set of events,
+// as needed to normalize a sample.
+//
+// HepMC events carry the current best estimate of the cross-section of the
+// run: the latest cross-section seen is thus the best estimate for the whole
+// run, and is stored in CrossSection.
+// MeanCrossSection holds instead the average of the cross-sections carried
+// by the events, which is the relevant estimate when the events come from
+// independent runs (e.g. merged files.)
+//
+// PDF informations are per-event quantities: PdfInfo holds the latest value
+// seen while MeanPdfInfo holds the average of the momentum fractions, scales
+// and PDF values of the events (flavour and LHAPDF identifiers are the ones
+// of the latest event.)
+type Stats struct {
+	NumEvents int     // number of accumulated events
+	SumW      float64 // sum of the default event weights
+	SumW2     float64 // sum of the squared default event weights
+
+	NumCrossSections int          // number of events with a cross-section
+	CrossSection     CrossSection // latest cross-section seen
+	MeanCrossSection CrossSection // average of the cross-sections seen
+
+	NumPdfInfos int     // number of events with PDF informations
+	PdfInfo     PdfInfo // latest PDF informations seen
+	MeanPdfInfo PdfInfo // average of the PDF informations seen
+
+	sumXS  float64 // sum of cross-section values
+	sumXE2 float64 // sum of squared cross-section errors
+	sumPdf [5]float64
+}
+
+// Add accumulates the run-level informations of the provided event.
+func (st *Stats) Add(evt *Event) {
+	w := 1.0
+	if len(evt.Weights.Slice) > 0 {
+		w = evt.Weights.Slice[0]
+	}
+	st.NumEvents++
+	st.SumW += w
+	st.SumW2 += w * w
+
+	if xs := evt.CrossSection; xs != nil {
+		st.NumCrossSections++
+		st.CrossSection = *xs
+		st.sumXS += xs.Value
+		st.sumXE2 += xs.Error * xs.Error
+
+		n := float64(st.NumCrossSections)
+		st.MeanCrossSection = CrossSection{
+			Value: st.sumXS / n,
+			Error: math.Sqrt(st.sumXE2) / n,
+		}
+	}
+
+	if pdf := evt.PdfInfo; pdf != nil {
+		st.NumPdfInfos++
+		st.PdfInfo = *pdf
+		for i, v := range []float64{pdf.X1, pdf.X2, pdf.ScalePDF, pdf.Pdf1, pdf.Pdf2} {
+			st.sumPdf[i] += v
+		}
+
+		n := float64(st.NumPdfInfos)
+		st.MeanPdfInfo = *pdf
+		st.MeanPdfInfo.X1 = st.sumPdf[0] / n
+		st.MeanPdfInfo.X2 = st.sumPdf[1] / n
+		st.MeanPdfInfo.ScalePDF = st.sumPdf[2] / n
+		st.MeanPdfInfo.Pdf1 = st.sumPdf[3] / n
+		st.MeanPdfInfo.Pdf2 = st.sumPdf[4] / n
+	}
+}
+
+// NormFactor returns the factor (in pb) by which event weights should be
+// scaled to normalize the accumulated events to the latest cross-section
+// estimate.
+// NormFactor returns 0 if no cross-section or no weight was accumulated.
+func (st *Stats) NormFactor() float64 {
+	if st.NumCrossSections == 0 || st.SumW == 0 {
+		return 0
+	}
+	return st.CrossSection.Value / st.SumW
+}
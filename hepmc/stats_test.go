@@ -0,0 +1,115 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"go-hep.org/x/hep/hepmc"
+)
+
+func TestStats(t *testing.T) {
+	raw, err := os.ReadFile("testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// drop the cross-section and PDF informations of the second event.
+	var (
+		lines = strings.Split(string(raw), "\n")
+		nevts = 0
+		o     = make([]string, 0, len(lines))
+	)
+	for _, line := range lines {
+		if strings.HasPrefix(line, "E ") {
+			nevts++
+		}
+		if nevts == 2 && (strings.HasPrefix(line, "C ") || strings.HasPrefix(line, "F ")) {
+			continue
+		}
+		o = append(o, line)
+	}
+	raw = []byte(strings.Join(o, "\n"))
+
+	var (
+		want  hepmc.Stats
+		sumX1 float64
+		sumXS float64
+		sumE2 float64
+		last  hepmc.CrossSection
+		nxs   int
+	)
+	for _, evt := range decodeAll(t, bytes.NewReader(raw)) {
+		want.NumEvents++
+		want.SumW += evt.Weights.Slice[0]
+		if evt.CrossSection != nil {
+			nxs++
+			last = *evt.CrossSection
+			sumXS += evt.CrossSection.Value
+			sumE2 += evt.CrossSection.Error * evt.CrossSection.Error
+		}
+		if evt.PdfInfo != nil {
+			sumX1 += evt.PdfInfo.X1
+		}
+	}
+
+	dec := hepmc.NewDecoder(bytes.NewReader(raw))
+	var evt hepmc.Event // re-use the same event for all the decoding.
+	for i := 0; ; i++ {
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+		if i == 1 && (evt.CrossSection != nil || evt.PdfInfo != nil) {
+			t.Fatalf("event %d: cross-section and PDF informations carried over", i)
+		}
+	}
+
+	st := dec.Stats()
+	if got, want := st.NumEvents, 6; got != want {
+		t.Fatalf("invalid number of events: got=%d, want=%d", got, want)
+	}
+	if got, want := st.SumW, want.SumW; got != want {
+		t.Fatalf("invalid sum of weights: got=%v, want=%v", got, want)
+	}
+	if got, want := st.NumCrossSections, 5; got != want || nxs != want {
+		t.Fatalf("invalid number of cross-sections: got=%d, want=%d", got, want)
+	}
+	if got, want := st.NumPdfInfos, 5; got != want {
+		t.Fatalf("invalid number of PDF infos: got=%d, want=%d", got, want)
+	}
+	if got, want := st.CrossSection, last; got != want {
+		t.Fatalf("invalid latest cross-section: got=%+v, want=%+v", got, want)
+	}
+	for _, tc := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"mean xsec", st.MeanCrossSection.Value, sumXS / 5},
+		{"mean xsec error", st.MeanCrossSection.Error, math.Sqrt(sumE2) / 5},
+		{"mean x1", st.MeanPdfInfo.X1, sumX1 / 5},
+		{"norm", st.NormFactor(), last.Value / want.SumW},
+	} {
+		if math.Abs(tc.got-tc.want) > 1e-9*math.Abs(tc.want) {
+			t.Fatalf("invalid %s: got=%v, want=%v", tc.name, tc.got, tc.want)
+		}
+	}
+	if got, want := st.MeanPdfInfo.ID1, st.PdfInfo.ID1; got != want {
+		t.Fatalf("invalid mean PDF id1: got=%d, want=%d", got, want)
+	}
+
+	var empty hepmc.Stats
+	if got := empty.NormFactor(); got != 0 {
+		t.Fatalf("invalid norm factor for empty stats: %v", got)
+	}
+}
@@ -6,6 +6,8 @@ package hepmc
 
 import (
 	"fmt"
+
+	"go-hep.org/x/hep/fmom"
 )
 
 // MomentumUnit describes the units of momentum quantities (MeV or GeV)
@@ -69,3 +71,92 @@ func LengthUnitFromString(s string) (LengthUnit, error) {
 	}
 	return -1, fmt.Errorf("hepmc.units: invalid LengthUnit string-value (%s)", s)
 }
+
+// ConversionFactor returns the factor by which a momentum expressed in mu
+// has to be multiplied to be expressed in o.
+func (mu MomentumUnit) ConversionFactor(o MomentumUnit) (float64, error) {
+	var (
+		from, err1 = mu.mev()
+		to, err2   = o.mev()
+	)
+	switch {
+	case err1 != nil:
+		return 0, err1
+	case err2 != nil:
+		return 0, err2
+	}
+	return from / to, nil
+}
+
+func (mu MomentumUnit) mev() (float64, error) {
+	switch mu {
+	case MEV:
+		return 1, nil
+	case GEV:
+		return 1e3, nil
+	}
+	return 0, fmt.Errorf("hepmc.units: invalid MomentumUnit value (%d)", int(mu))
+}
+
+// ConversionFactor returns the factor by which a length expressed in lu
+// has to be multiplied to be expressed in o.
+func (lu LengthUnit) ConversionFactor(o LengthUnit) (float64, error) {
+	var (
+		from, err1 = lu.mm()
+		to, err2   = o.mm()
+	)
+	switch {
+	case err1 != nil:
+		return 0, err1
+	case err2 != nil:
+		return 0, err2
+	}
+	return from / to, nil
+}
+
+func (lu LengthUnit) mm() (float64, error) {
+	switch lu {
+	case MM:
+		return 1, nil
+	case CM:
+		return 10, nil
+	}
+	return 0, fmt.Errorf("hepmc.units: invalid LengthUnit value (%d)", int(lu))
+}
+
+// ConvertUnits converts the event to the provided momentum and length
+// units.
+// Momenta and generated masses of all the particles, and positions of all
+// the vertices are rescaled accordingly.
+// Other event quantities (scale, cross-section, PDF information, ...)
+// are left untouched, following the HepMC conventions.
+func ConvertUnits(evt *Event, mu MomentumUnit, lu LengthUnit) error {
+	pf, err := evt.MomentumUnit.ConversionFactor(mu)
+	if err != nil {
+		return err
+	}
+	lf, err := evt.LengthUnit.ConversionFactor(lu)
+	if err != nil {
+		return err
+	}
+
+	if pf != 1 {
+		for _, p := range evt.Particles {
+			p.Momentum = scale(p.Momentum, pf)
+			p.GeneratedMass *= pf
+		}
+	}
+	if lf != 1 {
+		for _, vtx := range evt.Vertices {
+			vtx.Position = scale(vtx.Position, lf)
+		}
+	}
+
+	evt.MomentumUnit = mu
+	evt.LengthUnit = lu
+	return nil
+}
+
+func scale(p4 fmom.PxPyPzE, f float64) fmom.PxPyPzE {
+	return fmom.NewPxPyPzE(f*p4.Px(), f*p4.Py(), f*p4.Pz(), f*p4.E())
+}
@@ -0,0 +1,118 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hepmc_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"go-hep.org/x/hep/hepmc"
+)
+
+func TestConversionFactor(t *testing.T) {
+	for _, tc := range []struct {
+		from, to hepmc.MomentumUnit
+		want     float64
+	}{
+		{hepmc.MEV, hepmc.MEV, 1},
+		{hepmc.GEV, hepmc.GEV, 1},
+		{hepmc.GEV, hepmc.MEV, 1e3},
+		{hepmc.MEV, hepmc.GEV, 1e-3},
+	} {
+		got, err := tc.from.ConversionFactor(tc.to)
+		if err != nil {
+			t.Fatalf("%v -> %v: %+v", tc.from, tc.to, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%v -> %v: got=%v, want=%v", tc.from, tc.to, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		from, to hepmc.LengthUnit
+		want     float64
+	}{
+		{hepmc.MM, hepmc.MM, 1},
+		{hepmc.CM, hepmc.CM, 1},
+		{hepmc.CM, hepmc.MM, 10},
+		{hepmc.MM, hepmc.CM, 0.1},
+	} {
+		got, err := tc.from.ConversionFactor(tc.to)
+		if err != nil {
+			t.Fatalf("%v -> %v: %+v", tc.from, tc.to, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%v -> %v: got=%v, want=%v", tc.from, tc.to, got, tc.want)
+		}
+	}
+
+	if _, err := hepmc.GEV.ConversionFactor(hepmc.MomentumUnit(42)); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, err := hepmc.LengthUnit(42).ConversionFactor(hepmc.MM); err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestConvertUnits(t *testing.T) {
+	raw, err := os.ReadFile("testdata/small.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		ref = decodeAll(t, bytes.NewReader(raw))[0]
+		evt = decodeAll(t, bytes.NewReader(raw))[0]
+	)
+
+	err = hepmc.ConvertUnits(evt, hepmc.MEV, hepmc.CM)
+	if err != nil {
+		t.Fatalf("could not convert units: %+v", err)
+	}
+	if evt.MomentumUnit != hepmc.MEV || evt.LengthUnit != hepmc.CM {
+		t.Fatalf("invalid units: got=(%v, %v)", evt.MomentumUnit, evt.LengthUnit)
+	}
+	for bc, p := range evt.Particles {
+		pp := ref.Particles[bc]
+		if got, want := p.Momentum.E(), 1e3*pp.Momentum.E(); got != want {
+			t.Fatalf("particle %d: invalid energy: got=%v, want=%v", bc, got, want)
+		}
+		if got, want := p.GeneratedMass, 1e3*pp.GeneratedMass; got != want {
+			t.Fatalf("particle %d: invalid generated mass: got=%v, want=%v", bc, got, want)
+		}
+	}
+	for bc, vtx := range evt.Vertices {
+		vv := ref.Vertices[bc]
+		if got, want := vtx.Position.Z(), 0.1*vv.Position.Z(); got != want {
+			t.Fatalf("vertex %d: invalid position: got=%v, want=%v", bc, got, want)
+		}
+	}
+
+	// and back.
+	err = hepmc.ConvertUnits(evt, hepmc.GEV, hepmc.MM)
+	if err != nil {
+		t.Fatalf("could not convert units: %+v", err)
+	}
+	for bc, p := range evt.Particles {
+		pp := ref.Particles[bc]
+		if !p4Equal(p.Momentum, pp.Momentum) {
+			t.Fatalf("particle %d: invalid momentum: got=%v, want=%v", bc, p.Momentum, pp.Momentum)
+		}
+	}
+	for bc, vtx := range evt.Vertices {
+		vv := ref.Vertices[bc]
+		if !p4Equal(vtx.Position, vv.Position) {
+			t.Fatalf("vertex %d: invalid position: got=%v, want=%v", bc, vtx.Position, vv.Position)
+		}
+	}
+
+	err = hepmc.ConvertUnits(evt, hepmc.MomentumUnit(42), hepmc.MM)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if evt.MomentumUnit != hepmc.GEV {
+		t.Fatalf("units modified on error")
+	}
+}
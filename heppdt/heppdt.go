@@ -29,3 +29,33 @@ func ParticleByID(pid PID) *Particle {
 func ParticleByName(n string) *Particle {
 	return defaultTable.ParticleByName(n)
 }
+
+// Particles returns the particles of the default particle data table,
+// sorted by particle ID.
+func Particles() []*Particle {
+	return defaultTable.Particles()
+}
+
+// Select returns the particles of the default particle data table for
+// which f returns true, sorted by particle ID.
+func Select(f func(p *Particle) bool) []*Particle {
+	return defaultTable.Select(f)
+}
+
+// MatchName returns the particles of the default particle data table whose
+// name matches the provided shell pattern, sorted by particle ID.
+func MatchName(pattern string) ([]*Particle, error) {
+	return defaultTable.MatchName(pattern)
+}
+
+// ByQuantumNumbers returns the particles of the default particle data table
+// with the provided electrical charge and total spin (as 2J+1).
+func ByQuantumNumbers(charge float64, jspin int) []*Particle {
+	return defaultTable.ByQuantumNumbers(charge, jspin)
+}
+
+// ChargeConjugate returns the charge conjugate of the particle with the
+// provided ID from the default particle data table.
+func ChargeConjugate(pid PID) *Particle {
+	return defaultTable.ChargeConjugate(pid)
+}
@@ -5,6 +5,10 @@
 package heppdt_test
 
 import (
+	"math"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"go-hep.org/x/hep/heppdt"
@@ -24,3 +28,281 @@ func TestDefaultTable(t *testing.T) {
 		t.Fatalf("invalid particle for pid=1. got=%q, want=%q", got, want)
 	}
 }
+
+func TestNewFromPDG(t *testing.T) {
+	f, err := os.Open("testdata/mass_width.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	table, err := heppdt.NewFromPDG(f, "mass_width.txt")
+	if err != nil {
+		t.Fatalf("could not load PDG table: %+v", err)
+	}
+
+	if got, want := table.Len(), 24; got != want {
+		t.Fatalf("invalid table length. got=%d, want=%d", got, want)
+	}
+
+	for _, tc := range []struct {
+		pid    heppdt.PID
+		name   string
+		mass   float64
+		width  float64
+		charge float64
+	}{
+		{1, "d", 4.67e-3, 0, -1. / 3},
+		{-1, "d~", 4.67e-3, 0, +1. / 3},
+		{11, "e^-", 0.5109989500e-3, 0, -1},
+		{-11, "e^+", 0.5109989500e-3, 0, +1},
+		{22, "gamma", 0, 0, 0},
+		{24, "W^+", 80.369, 2.085, +1},
+		{-24, "W^-", 80.369, 2.085, -1},
+		{111, "pi^0", 0.1349768, 7.81e-9, 0},
+		{211, "pi^+", 0.13957039, 2.5284e-17, +1},
+		{-211, "pi^-", 0.13957039, 2.5284e-17, -1},
+		{311, "K^0", 0.497611, 0, 0},
+		{-311, "K~^0", 0.497611, 0, 0},
+		{-2212, "p~^-", 0.93827208816, 0, -1},
+		{2224, "Delta(1232)^++", 1.232, 0.117, +2},
+		{-2224, "Delta(1232)~^--", 1.232, 0.117, -2},
+		{1114, "Delta(1232)^-", 1.232, 0.117, -1},
+	} {
+		p := table.ParticleByID(tc.pid)
+		if p == nil {
+			t.Fatalf("could not find pid=%d", tc.pid)
+		}
+		if got, want := p.Name, tc.name; got != want {
+			t.Fatalf("pid=%d: invalid name. got=%q, want=%q", tc.pid, got, want)
+		}
+		if table.ParticleByName(tc.name) != p {
+			t.Fatalf("pid=%d: could not find particle by name %q", tc.pid, tc.name)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"mass", p.Mass, tc.mass},
+			{"width", p.Resonance.Width.Value, tc.width},
+			{"charge", p.Charge, tc.charge},
+		} {
+			if math.Abs(v.got-v.want) > 1e-12 {
+				t.Fatalf("pid=%d: invalid %s. got=%v, want=%v", tc.pid, v.name, v.got, v.want)
+			}
+		}
+	}
+
+	if got, want := table.ParticleByID(24).Resonance.Mass.Sigma, 13e-3; math.Abs(got-want) > 1e-12 {
+		t.Fatalf("invalid W mass error. got=%v, want=%v", got, want)
+	}
+	if got, want := table.ParticleByID(2212).Spin.TotalSpin, 0.5; got != want {
+		t.Fatalf("invalid proton spin. got=%v, want=%v", got, want)
+	}
+
+	for _, pid := range []heppdt.PID{-22, -111, -221, -443} {
+		if p := table.ParticleByID(pid); p != nil {
+			t.Fatalf("unexpected anti-particle for self-conjugate pid=%d", -pid)
+		}
+	}
+
+	for _, line := range []string{
+		"      11 0.5",
+		"      11                          5.1E-01            +1.5E-10 -1.5E-10 0.0E+00            +0.0E+00 -0.0E+00 e                    -,-",
+		"      11                          5.1E-01            +1.5E-10 -1.5E-10 0.0E+00            +0.0E+00 -0.0E+00 e                    x",
+		"      1x                          5.1E-01            +1.5E-10 -1.5E-10 0.0E+00            +0.0E+00 -0.0E+00 e                    -",
+		"      11                          5.1X-01            +1.5E-10 -1.5E-10 0.0E+00            +0.0E+00 -0.0E+00 e                    -",
+	} {
+		_, err := heppdt.NewFromPDG(strings.NewReader(line), "invalid")
+		if err == nil {
+			t.Fatalf("expected an error for %q", line)
+		}
+	}
+}
+
+func TestNewFromROOT(t *testing.T) {
+	f, err := os.Open("testdata/pdg_table.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	table, err := heppdt.NewFromROOT(f, "pdg_table.txt")
+	if err != nil {
+		t.Fatalf("could not load ROOT table: %+v", err)
+	}
+
+	if got, want := table.Len(), 9; got != want {
+		t.Fatalf("invalid table length. got=%d, want=%d", got, want)
+	}
+
+	for _, tc := range []struct {
+		pid    heppdt.PID
+		name   string
+		mass   float64
+		width  float64
+		charge float64
+	}{
+		{1, "d", 0.33, 0, -1. / 3},
+		{-1, "d_bar", 0.33, 0, +1. / 3},
+		{-11, "e+", 0.000511, 0, +1},
+		{111, "pi0", 0.134977, 7.81e-9, 0},
+		{-211, "pi-", 0.13957, 2.5284e-17, -1},
+		{113, "rho0", 0.77526, 0.1491, 0},
+	} {
+		p := table.ParticleByName(tc.name)
+		if p == nil {
+			t.Fatalf("could not find %q", tc.name)
+		}
+		if got, want := p.ID, tc.pid; got != want {
+			t.Fatalf("%s: invalid pid. got=%d, want=%d", tc.name, got, want)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"mass", p.Mass, tc.mass},
+			{"width", p.Resonance.Width.Value, tc.width},
+			{"charge", p.Charge, tc.charge},
+		} {
+			if math.Abs(v.got-v.want) > 1e-12 {
+				t.Fatalf("%s: invalid %s. got=%v, want=%v", tc.name, v.name, v.got, v.want)
+			}
+		}
+	}
+
+	for _, txt := range []string{
+		"0 d 1",
+		"0 d 1 1 1 Quark -1 0.33",
+		"0 d 1 1 1 Quark x 0.33 0 0 0 1 0 -1 0",
+		"0 d 1 1 1 Quark -1 0.33 0 0 0 1 0 -1 x",
+		"0 d x 1",
+		"1 d_bar -1 0",
+	} {
+		_, err := heppdt.NewFromROOT(strings.NewReader(txt), "invalid")
+		if err == nil {
+			t.Fatalf("expected an error for %q", txt)
+		}
+	}
+}
+
+func TestQueries(t *testing.T) {
+	f, err := os.Open("testdata/mass_width.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	table, err := heppdt.NewFromPDG(f, "mass_width.txt")
+	if err != nil {
+		t.Fatalf("could not load PDG table: %+v", err)
+	}
+
+	ids := func(ps []*heppdt.Particle) []heppdt.PID {
+		o := make([]heppdt.PID, len(ps))
+		for i, p := range ps {
+			o[i] = p.ID
+		}
+		return o
+	}
+
+	ps := table.Particles()
+	if got, want := len(ps), table.Len(); got != want {
+		t.Fatalf("invalid number of particles. got=%d, want=%d", got, want)
+	}
+	for i := 1; i < len(ps); i++ {
+		if ps[i-1].ID >= ps[i].ID {
+			t.Fatalf("particles not sorted by ID: %d, %d", ps[i-1].ID, ps[i].ID)
+		}
+	}
+
+	ps, err = table.MatchName("pi^*")
+	if err != nil {
+		t.Fatalf("could not match names: %+v", err)
+	}
+	if got, want := ids(ps), []heppdt.PID{-211, 111, 211}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid match. got=%v, want=%v", got, want)
+	}
+	_, err = table.MatchName("pi[")
+	if err == nil {
+		t.Fatalf("expected an error for an invalid pattern")
+	}
+
+	ps = table.ByQuantumNumbers(0, 1)
+	if got, want := ids(ps), []heppdt.PID{-311, 111, 221, 311}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid neutral pseudo-scalars. got=%v, want=%v", got, want)
+	}
+	ps = table.ByQuantumNumbers(+2, 4)
+	if got, want := ids(ps), []heppdt.PID{2224}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid Delta^++. got=%v, want=%v", got, want)
+	}
+
+	ps = table.Select((*heppdt.Particle).IsResonance)
+	if got, want := ids(ps), []heppdt.PID{-2224, -2214, -2114, -1114, -24, 24, 443, 1114, 2114, 2214, 2224}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid resonances. got=%v, want=%v", got, want)
+	}
+
+	ps = table.Select((*heppdt.Particle).IsHadron)
+	if got, want := len(ps), 17; got != want {
+		t.Fatalf("invalid number of hadrons. got=%d (%v), want=%d", got, ids(ps), want)
+	}
+
+	for _, tc := range []struct {
+		pid  heppdt.PID
+		want heppdt.PID
+	}{
+		{211, -211},
+		{-211, 211},
+		{111, 111},
+		{22, 22},
+		{311, -311},
+		{443, 443},
+		{2212, -2212},
+		{-11, 11},
+	} {
+		p := table.ChargeConjugate(tc.pid)
+		if p == nil {
+			t.Fatalf("could not find charge conjugate of pid=%d", tc.pid)
+		}
+		if got, want := p.ID, tc.want; got != want {
+			t.Fatalf("invalid charge conjugate of pid=%d. got=%d, want=%d", tc.pid, got, want)
+		}
+	}
+	if p := table.ChargeConjugate(321); p != nil {
+		t.Fatalf("unexpected charge conjugate for pid=321: %v", p.ID)
+	}
+}
+
+func TestDefaultQueries(t *testing.T) {
+	if got, want := len(heppdt.Particles()), heppdt.Len(); got != want {
+		t.Fatalf("invalid number of particles. got=%d, want=%d", got, want)
+	}
+
+	ps, err := heppdt.MatchName("pi^?")
+	if err != nil {
+		t.Fatalf("could not match names: %+v", err)
+	}
+	if got, want := len(ps), 3; got != want {
+		t.Fatalf("invalid number of pions. got=%d, want=%d", got, want)
+	}
+
+	if p := heppdt.ChargeConjugate(2212); p == nil || p.Name != "p~^-" {
+		t.Fatalf("invalid charge conjugate of proton: %v", p)
+	}
+
+	ps = heppdt.ByQuantumNumbers(+1, 2)
+	found := false
+	for _, p := range ps {
+		if p.ID == 2212 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("could not find proton in J=1/2, Q=+1 particles")
+	}
+
+	ps = heppdt.Select(func(p *heppdt.Particle) bool { return p.ID.IsLepton() && p.Charge < 0 })
+	if len(ps) == 0 {
+		t.Fatalf("could not select negative leptons")
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
+	"strings"
 )
 
 // parse fills a Table from the content of r
@@ -143,3 +145,262 @@ func parse(r io.Reader, table *Table) error {
 
 	return err
 }
+
+// parsePDG fills a Table from the content of r, a PDG mass and width table.
+//
+// Each non-comment line of a PDG table holds, in fixed columns:
+//   - up to 4 particle IDs sharing the same mass and width (columns 1-32),
+//   - the mass and its positive and negative errors (columns 34-69),
+//   - the width and its positive and negative errors (columns 71-106),
+//   - the particle name and the comma-separated list of the charges of the
+//     particles (columns 108-).
+func parsePDG(r io.Reader, table *Table) error {
+	const MeV = 1e-3 // in GeV
+
+	s := bufio.NewScanner(r)
+	lineno := 0
+	for s.Scan() {
+		lineno++
+		line := strings.TrimRight(s.Text(), " \t\r\n")
+		if strings.TrimSpace(line) == "" || line[0] == '*' {
+			continue
+		}
+		if len(line) < 108 {
+			return fmt.Errorf("heppdt: malformed line:%d: %v", lineno, line)
+		}
+
+		var vs [6]float64
+		for i, col := range [][2]int{
+			{33, 51}, {52, 60}, {61, 69}, // mass
+			{70, 88}, {89, 97}, {98, 106}, // width
+		} {
+			v, err := parsePDGFloat(line[col[0]:col[1]])
+			if err != nil {
+				return fmt.Errorf("heppdt: line:%d: %w", lineno, err)
+			}
+			vs[i] = v * MeV
+		}
+		mass := Measurement{Value: vs[0], Sigma: math.Max(math.Abs(vs[1]), math.Abs(vs[2]))}
+		width := Measurement{Value: vs[3], Sigma: math.Max(math.Abs(vs[4]), math.Abs(vs[5]))}
+
+		ids := strings.Fields(line[:32])
+		toks := strings.Fields(line[107:])
+		if len(ids) == 0 || len(toks) != 2 {
+			return fmt.Errorf("heppdt: malformed line:%d: %v", lineno, line)
+		}
+		name := toks[0]
+		charges := strings.Split(toks[1], ",")
+		if len(charges) != len(ids) {
+			return fmt.Errorf("heppdt: line:%d: invalid number of charges (got=%d, want=%d)", lineno, len(charges), len(ids))
+		}
+
+		for i, sid := range ids {
+			id, err := strconv.ParseInt(sid, 10, 64)
+			if err != nil {
+				return fmt.Errorf("heppdt: line:%d: %w", lineno, err)
+			}
+			charge, err := parsePDGCharge(charges[i])
+			if err != nil {
+				return fmt.Errorf("heppdt: line:%d: %w", lineno, err)
+			}
+
+			pid := PID(id)
+			part := Particle{
+				ID:     pid,
+				Name:   pdgName(pid, name, charges[i], false),
+				PDG:    int(id),
+				Mass:   mass.Value,
+				Charge: charge,
+				Spin:   spinOf(pid),
+				Resonance: Resonance{
+					Mass:  mass,
+					Width: width,
+				},
+			}
+			table.add(&part)
+
+			if pid.isSelfConjugate() {
+				continue
+			}
+			anti := part
+			anti.ID = -pid
+			anti.PDG = -part.PDG
+			anti.Charge = -charge
+			if anti.Charge == 0 {
+				// avoid negative zeros.
+				anti.Charge = 0
+			}
+			anti.Name = pdgName(pid, name, charges[i], true)
+			table.add(&anti)
+		}
+	}
+
+	return s.Err()
+}
+
+func parsePDGFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parsePDGCharge parses charges of the form "0", "+", "--" or "-1/3".
+func parsePDGCharge(s string) (float64, error) {
+	if i := strings.Index(s, "/"); i >= 0 {
+		num, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("heppdt: invalid charge %q: %w", s, err)
+		}
+		den, err := strconv.Atoi(s[i+1:])
+		if err != nil || den == 0 {
+			return 0, fmt.Errorf("heppdt: invalid charge %q", s)
+		}
+		return float64(num) / float64(den), nil
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	var q float64
+	for _, c := range s {
+		switch c {
+		case '+':
+			q++
+		case '-':
+			q--
+		default:
+			return 0, fmt.Errorf("heppdt: invalid charge %q", s)
+		}
+	}
+	return q, nil
+}
+
+// pdgName returns the name of a particle (or of its anti-particle) from its
+// PDG name and charge, following the naming scheme of the default table
+// (e.g. "pi^+", "pi^-", "p~^-", "K~^0", "e^+" or "d~".)
+func pdgName(pid PID, name, charge string, anti bool) string {
+	var (
+		fid     = pid.FundamentalID()
+		quark   = fid > 0 && fid <= 8
+		neutral = charge == "0"
+	)
+	if anti {
+		if neutral || quark || pid.IsBaryon() {
+			name += "~"
+		}
+		charge = strings.Map(func(r rune) rune {
+			switch r {
+			case '+':
+				return '-'
+			case '-':
+				return '+'
+			}
+			return r
+		}, charge)
+	}
+	if quark || (neutral && !pid.IsHadron()) {
+		return name
+	}
+	return name + "^" + charge
+}
+
+// spinOf returns the spin state of a particle, as inferred from its ID.
+func spinOf(pid PID) SpinState {
+	j := pid.JSpin()
+	if j <= 0 {
+		return SpinState{}
+	}
+	return SpinState{TotalSpin: 0.5 * float64(j-1)}
+}
+
+// parseROOT fills a Table from the content of r, a ROOT TDatabasePDG table.
+//
+// Each particle is described by a line holding:
+//
+//	index name pdg anti class-id class-name charge mass width isospin i3 spin flavor tracking ndecays
+//
+// followed by ndecays lines describing its decay channels (ignored here.)
+// Anti-particles are described by a line holding:
+//
+//	index name pdg anti
+//
+// and inherit the mass and width of their particle.
+// Charges are given in units of |e|/3, masses and widths in GeV.
+func parseROOT(r io.Reader, table *Table) error {
+	s := bufio.NewScanner(r)
+	lineno := 0
+	ndecays := 0
+	for s.Scan() {
+		lineno++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if ndecays > 0 {
+			ndecays--
+			continue
+		}
+
+		toks := strings.Fields(line)
+		if len(toks) < 4 {
+			return fmt.Errorf("heppdt: malformed line:%d: %v", lineno, line)
+		}
+		id, err := strconv.ParseInt(toks[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("heppdt: line:%d: %w", lineno, err)
+		}
+		pid := PID(id)
+		name := toks[1]
+
+		if id < 0 {
+			p := table.ParticleByID(-pid)
+			if p == nil {
+				return fmt.Errorf("heppdt: line:%d: no particle for anti-particle %q (pid=%d)", lineno, name, id)
+			}
+			anti := *p
+			anti.ID = pid
+			anti.PDG = int(id)
+			anti.Name = name
+			anti.Charge = -p.Charge
+			if anti.Charge == 0 {
+				// avoid negative zeros.
+				anti.Charge = 0
+			}
+			table.add(&anti)
+			continue
+		}
+
+		if len(toks) != 15 {
+			return fmt.Errorf("heppdt: malformed line:%d: %v", lineno, line)
+		}
+		var vs [3]float64
+		for i, tok := range toks[6:9] {
+			vs[i], err = strconv.ParseFloat(tok, 64)
+			if err != nil {
+				return fmt.Errorf("heppdt: line:%d: %w", lineno, err)
+			}
+		}
+		n, err := strconv.Atoi(toks[14])
+		if err != nil {
+			return fmt.Errorf("heppdt: line:%d: %w", lineno, err)
+		}
+		ndecays = n
+
+		part := Particle{
+			ID:     pid,
+			Name:   name,
+			PDG:    int(id),
+			Mass:   vs[1],
+			Charge: vs[0] * onethird,
+			Spin:   spinOf(pid),
+			Resonance: Resonance{
+				Mass:  Measurement{Value: vs[1]},
+				Width: Measurement{Value: vs[2]},
+			},
+		}
+		table.add(&part)
+	}
+
+	return s.Err()
+}
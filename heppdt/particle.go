@@ -35,3 +35,15 @@ func (p *Particle) IsStable() bool {
 	}
 	return true
 }
+
+// IsHadron returns whether this particle is a hadron
+func (p *Particle) IsHadron() bool {
+	return p.ID.IsHadron()
+}
+
+// IsResonance returns whether this particle is a short-lived resonance,
+// i.e. a particle with a total width larger than 10 keV.
+func (p *Particle) IsResonance() bool {
+	const width = 10e-6 // in GeV
+	return p.Resonance.Width.Value > width
+}
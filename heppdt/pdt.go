@@ -6,7 +6,11 @@ package heppdt
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"math"
+	"path"
+	"sort"
 )
 
 var defaultTable Table
@@ -29,6 +33,34 @@ func New(r io.Reader, n string) (Table, error) {
 	return t, err
 }
 
+// NewFromPDG returns a new particle data table, initialized from r
+// holding a PDG mass and width table (mass_width_YYYY.txt).
+//
+// Masses and widths are converted from MeV to GeV.
+// Anti-particles, which are not listed in PDG tables, are added to the
+// returned table for all particles that are not self-conjugate.
+func NewFromPDG(r io.Reader, n string) (Table, error) {
+	t := Table{
+		name: n,
+		pdt:  make(map[PID]*Particle),
+		pid:  make(map[string]PID),
+	}
+	err := parsePDG(r, &t)
+	return t, err
+}
+
+// NewFromROOT returns a new particle data table, initialized from r
+// holding a ROOT TDatabasePDG table (pdg_table.txt).
+func NewFromROOT(r io.Reader, n string) (Table, error) {
+	t := Table{
+		name: n,
+		pdt:  make(map[PID]*Particle),
+		pid:  make(map[string]PID),
+	}
+	err := parseROOT(r, &t)
+	return t, err
+}
+
 // Name returns the name of this particle data table
 func (t *Table) Name() string {
 	return t.name
@@ -67,6 +99,66 @@ func (t *Table) ParticleByName(n string) *Particle {
 	return p
 }
 
+// Particles returns the particles of the table, sorted by particle ID.
+func (t *Table) Particles() []*Particle {
+	return t.Select(func(*Particle) bool { return true })
+}
+
+// Select returns the particles of the table for which f returns true,
+// sorted by particle ID.
+func (t *Table) Select(f func(p *Particle) bool) []*Particle {
+	var ps []*Particle
+	for _, p := range t.pdt {
+		if f(p) {
+			ps = append(ps, p)
+		}
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].ID < ps[j].ID })
+	return ps
+}
+
+// MatchName returns the particles whose name matches the provided shell
+// pattern (see path.Match), sorted by particle ID.
+func (t *Table) MatchName(pattern string) ([]*Particle, error) {
+	_, err := path.Match(pattern, "")
+	if err != nil {
+		return nil, fmt.Errorf("heppdt: invalid name pattern %q: %w", pattern, err)
+	}
+	return t.Select(func(p *Particle) bool {
+		ok, _ := path.Match(pattern, p.Name)
+		return ok
+	}), nil
+}
+
+// ByQuantumNumbers returns the particles with the provided electrical
+// charge and total spin (as 2J+1, see PID.JSpin), sorted by particle ID.
+func (t *Table) ByQuantumNumbers(charge float64, jspin int) []*Particle {
+	const eps = 1e-6
+	return t.Select(func(p *Particle) bool {
+		return math.Abs(p.Charge-charge) < eps && p.ID.JSpin() == jspin
+	})
+}
+
+// ChargeConjugate returns the charge conjugate of the particle with the
+// provided ID.
+// Self-conjugate particles are their own charge conjugate.
+// ChargeConjugate returns nil if the charge conjugate is not in the table.
+func (t *Table) ChargeConjugate(pid PID) *Particle {
+	if p := t.ParticleByID(-pid); p != nil {
+		return p
+	}
+	if pid.isSelfConjugate() {
+		return t.ParticleByID(pid)
+	}
+	return nil
+}
+
+// add adds the provided particle to the table.
+func (t *Table) add(p *Particle) {
+	t.pdt[p.ID] = p
+	t.pid[p.Name] = p.ID
+}
+
 func init() {
 	var err error
 	defaultTable, err = New(bytes.NewBufferString(tabledata), "particle.tbl")
@@ -707,3 +707,19 @@ func (pid PID) Quarks() Quarks {
 		Nq3: int16(pid.Digit(Nq3)),
 	}
 }
+
+// isSelfConjugate returns whether this particle is its own anti-particle.
+func (pid PID) isSelfConjugate() bool {
+	if pid.threeCharge() != 0 {
+		return false
+	}
+	if fid := pid.FundamentalID(); fid > 0 && fid <= 100 {
+		// gluon, photon, Z, Higgs bosons and other neutral gauge bosons.
+		return fid >= 21 && fid <= 40
+	}
+	switch pid.AbsPID() {
+	case 130, 310:
+		return true
+	}
+	return pid.IsMeson() && pid.Digit(Nq2) == pid.Digit(Nq3)
+}
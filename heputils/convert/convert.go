@@ -0,0 +1,567 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package convert provides conversions between the HepMC, LHEF and HEPEVT
+// event records, and LCIO MCParticle collections.
+//
+// HEPEVT and LHEF events are flat lists of particles, linked together by the
+// indices of their mothers and daughters, while HepMC events are graphs of
+// particles connected by vertices.
+// When converting to HepMC, vertices are rebuilt from these indices: particles
+// sharing the same mothers (or mothers sharing the same daughters) are
+// connected by the same vertex.
+// When converting from HepMC, particles are laid out by increasing barcode,
+// the outgoing particles of a vertex being kept together, and their mother
+// and daughter indices are the [first, last] ranges spanned by the incoming
+// particles of their production vertex and by the outgoing particles of their
+// decay vertex.
+// As daughters ranges are exact, the HepMC graph is rebuilt from daughters
+// first and from mothers for the remaining particles.
+//
+// Indices of hepevt.Event are 0-based (-1 meaning "no particle"), while
+// indices of lhef.HEPEUP are 1-based (0 meaning "no particle"), as in the
+// corresponding file formats.
+//
+// LCIO particles are linked together by pointers to their parents and
+// daughters, from which HepMC vertices are rebuilt as for HEPEVT events.
+//
+// Momenta and positions of HEPEVT, LHEF and LCIO events are expressed in GeV
+// and mm.
+package convert // import "go-hep.org/x/hep/heputils/convert"
+
+import (
+	"fmt"
+	"sort"
+
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/hepevt"
+	"go-hep.org/x/hep/hepmc"
+	"go-hep.org/x/hep/lhef"
+)
+
+// StatusFromLHEF converts a LHEF status code into a HEPEVT (and HepMC)
+// status code.
+// Incoming particles (-1) are converted to beam particles (4), all the other
+// status codes are left untouched.
+func StatusFromLHEF(status int32) int {
+	switch status {
+	case -1:
+		return 4
+	default:
+		return int(status)
+	}
+}
+
+// StatusToLHEF converts a HEPEVT (or HepMC) status code into a LHEF status
+// code.
+// Beam particles (4) are converted to incoming particles (-1), all the other
+// status codes are left untouched.
+func StatusToLHEF(status int) int32 {
+	switch status {
+	case 4:
+		return -1
+	default:
+		return int32(status)
+	}
+}
+
+// HEPEVTFromHepMC converts a HepMC event into a HEPEVT event.
+func HEPEVTFromHepMC(evt *hepmc.Event) (*hepevt.Event, error) {
+	o, _, err := hepevtFromHepMC(evt)
+	return o, err
+}
+
+func hepevtFromHepMC(evt *hepmc.Event) (*hepevt.Event, []*hepmc.Particle, error) {
+	mom, err := evt.MomentumUnit.ConversionFactor(hepmc.GEV)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert: could not convert momenta: %w", err)
+	}
+	pos, err := evt.LengthUnit.ConversionFactor(hepmc.MM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert: could not convert positions: %w", err)
+	}
+
+	all := make([]*hepmc.Particle, 0, len(evt.Particles))
+	for _, p := range evt.Particles {
+		all = append(all, p)
+	}
+	sort.Sort(hepmc.Particles(all))
+
+	// lay out particles by increasing barcode, keeping the outgoing
+	// particles of each vertex together so their range of indices is exact.
+	var (
+		ps  = make([]*hepmc.Particle, 0, len(all))
+		idx = make(map[*hepmc.Particle]int, len(all))
+	)
+	add := func(p *hepmc.Particle) {
+		if _, dup := idx[p]; dup {
+			return
+		}
+		idx[p] = len(ps)
+		ps = append(ps, p)
+	}
+	for _, p := range all {
+		vtx := p.ProdVertex
+		if vtx == nil {
+			add(p)
+			continue
+		}
+		out := make([]*hepmc.Particle, len(vtx.ParticlesOut))
+		copy(out, vtx.ParticlesOut)
+		sort.Sort(hepmc.Particles(out))
+		for _, pp := range out {
+			add(pp)
+		}
+	}
+
+	n := len(ps)
+	o := &hepevt.Event{
+		Nevhep: evt.EventNumber,
+		Nhep:   n,
+		Isthep: make([]int, n),
+		Idhep:  make([]int, n),
+		Jmohep: make([][2]int, n),
+		Jdahep: make([][2]int, n),
+		Phep:   make([][5]float64, n),
+		Vhep:   make([][4]float64, n),
+	}
+	for i, p := range ps {
+		o.Isthep[i] = p.Status
+		o.Idhep[i] = int(p.PdgID)
+		o.Jmohep[i] = [2]int{-1, -1}
+		o.Jdahep[i] = [2]int{-1, -1}
+		o.Phep[i] = [5]float64{
+			p.Momentum.Px() * mom,
+			p.Momentum.Py() * mom,
+			p.Momentum.Pz() * mom,
+			p.Momentum.E() * mom,
+			p.GeneratedMass * mom,
+		}
+		if vtx := p.ProdVertex; vtx != nil {
+			o.Vhep[i] = [4]float64{
+				vtx.Position.X() * pos,
+				vtx.Position.Y() * pos,
+				vtx.Position.Z() * pos,
+				vtx.Position.T() * pos,
+			}
+			beg, end, err := span(idx, vtx.ParticlesIn)
+			if err != nil {
+				return nil, nil, fmt.Errorf("convert: invalid mothers for particle %d: %w", p.Barcode, err)
+			}
+			if beg == end {
+				end = -1
+			}
+			o.Jmohep[i] = [2]int{beg, end}
+		}
+		if vtx := p.EndVertex; vtx != nil {
+			beg, end, err := span(idx, vtx.ParticlesOut)
+			if err != nil {
+				return nil, nil, fmt.Errorf("convert: invalid daughters for particle %d: %w", p.Barcode, err)
+			}
+			o.Jdahep[i] = [2]int{beg, end}
+		}
+	}
+
+	return o, ps, nil
+}
+
+// span returns the [first, last] range of indices spanned by the provided
+// particles.
+func span(idx map[*hepmc.Particle]int, ps []*hepmc.Particle) (beg, end int, err error) {
+	beg, end = -1, -1
+	for _, p := range ps {
+		i, ok := idx[p]
+		if !ok {
+			return -1, -1, fmt.Errorf("particle %d not in event", p.Barcode)
+		}
+		if beg < 0 || i < beg {
+			beg = i
+		}
+		if i > end {
+			end = i
+		}
+	}
+	return beg, end, nil
+}
+
+// HepMCFromHEPEVT converts a HEPEVT event into a HepMC event.
+//
+// Particles are given the barcode i+1, where i is their HEPEVT index.
+// The beams of the HepMC event are the first two particles without mothers.
+func HepMCFromHEPEVT(evt *hepevt.Event) (*hepmc.Event, error) {
+	n := evt.Nhep
+	switch {
+	case n < 0:
+		return nil, fmt.Errorf("convert: invalid number of particles (%d)", n)
+	case len(evt.Isthep) < n, len(evt.Idhep) < n,
+		len(evt.Jmohep) < n, len(evt.Jdahep) < n,
+		len(evt.Phep) < n, len(evt.Vhep) < n:
+		return nil, fmt.Errorf("convert: inconsistent HEPEVT event (nhep=%d)", n)
+	}
+
+	o := &hepmc.Event{
+		EventNumber:  evt.Nevhep,
+		Weights:      hepmc.NewWeights(),
+		Vertices:     make(map[int]*hepmc.Vertex),
+		Particles:    make(map[int]*hepmc.Particle),
+		MomentumUnit: hepmc.GEV,
+		LengthUnit:   hepmc.MM,
+	}
+
+	ps := make([]*hepmc.Particle, n)
+	for i := range ps {
+		phep := evt.Phep[i]
+		p := &hepmc.Particle{
+			Momentum:      fmom.NewPxPyPzE(phep[0], phep[1], phep[2], phep[3]),
+			PdgID:         int64(evt.Idhep[i]),
+			Status:        evt.Isthep[i],
+			Barcode:       i + 1,
+			GeneratedMass: phep[4],
+		}
+		p.Flow.Particle = p
+		ps[i] = p
+	}
+
+	newVertex := func(i int) (*hepmc.Vertex, error) {
+		vhep := evt.Vhep[i]
+		vtx := &hepmc.Vertex{
+			Position: fmom.NewPxPyPzE(vhep[0], vhep[1], vhep[2], vhep[3]),
+		}
+		err := o.AddVertex(vtx)
+		if err != nil {
+			return nil, fmt.Errorf("convert: could not add vertex for particle %d: %w", i, err)
+		}
+		return vtx, nil
+	}
+
+	// decay vertices, from daughters.
+	for i, p := range ps {
+		for _, d := range indices(evt.Jdahep[i], i, n) {
+			var (
+				dau = ps[d]
+				err error
+			)
+			switch {
+			case p.EndVertex == nil && dau.ProdVertex == nil:
+				var vtx *hepmc.Vertex
+				vtx, err = newVertex(d)
+				if err != nil {
+					return nil, err
+				}
+				err = vtx.AddParticleIn(p)
+				if err == nil {
+					err = vtx.AddParticleOut(dau)
+				}
+			case p.EndVertex == nil:
+				err = dau.ProdVertex.AddParticleIn(p)
+			case dau.ProdVertex == nil:
+				err = p.EndVertex.AddParticleOut(dau)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("convert: could not attach daughter %d of particle %d: %w", d, i, err)
+			}
+		}
+	}
+
+	// production vertices of the remaining particles, from mothers.
+	for i, p := range ps {
+		if p.ProdVertex != nil {
+			continue
+		}
+		ms := indices(evt.Jmohep[i], i, n)
+		if len(ms) == 0 {
+			continue
+		}
+		var vtx *hepmc.Vertex
+		for _, m := range ms {
+			if ps[m].EndVertex != nil {
+				vtx = ps[m].EndVertex
+				break
+			}
+		}
+		if vtx == nil {
+			var err error
+			vtx, err = newVertex(i)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, m := range ms {
+			if ps[m].EndVertex != nil {
+				continue
+			}
+			err := vtx.AddParticleIn(ps[m])
+			if err != nil {
+				return nil, fmt.Errorf("convert: could not attach mother %d of particle %d: %w", m, i, err)
+			}
+		}
+		err := vtx.AddParticleOut(p)
+		if err != nil {
+			return nil, fmt.Errorf("convert: could not attach particle %d: %w", i, err)
+		}
+	}
+
+	nbeams := 0
+	for _, p := range ps {
+		if nbeams == len(o.Beams) {
+			break
+		}
+		if p.ProdVertex == nil {
+			o.Beams[nbeams] = p
+			nbeams++
+		}
+	}
+
+	// isolated particles.
+	for i, p := range ps {
+		if p.ProdVertex != nil || p.EndVertex != nil {
+			continue
+		}
+		vtx, err := newVertex(i)
+		if err != nil {
+			return nil, err
+		}
+		err = vtx.AddParticleOut(p)
+		if err != nil {
+			return nil, fmt.Errorf("convert: could not attach particle %d: %w", i, err)
+		}
+	}
+
+	return o, nil
+}
+
+// indices returns the valid indices of the [first, last] range r of
+// mothers or daughters of the i-th particle of an event with n particles.
+// A negative last index denotes a single particle.
+func indices(r [2]int, i, n int) []int {
+	beg, end := r[0], r[1]
+	if beg < 0 {
+		beg = end
+	}
+	if end < beg {
+		end = beg
+	}
+	if beg < 0 {
+		return nil
+	}
+	if end >= n {
+		end = n - 1
+	}
+	var o []int
+	for j := beg; j <= end; j++ {
+		if j == i {
+			continue
+		}
+		o = append(o, j)
+	}
+	return o
+}
+
+// HEPEVTFromLHEF converts a LHEF event into a HEPEVT event.
+//
+// Status codes are converted with StatusFromLHEF and daughters are computed
+// from the mothers of each particle.
+// Colour flow, lifetime and spin informations are not converted.
+func HEPEVTFromLHEF(evt *lhef.HEPEUP) (*hepevt.Event, error) {
+	n := int(evt.NUP)
+	switch {
+	case n < 0:
+		return nil, fmt.Errorf("convert: invalid number of particles (%d)", n)
+	case len(evt.IDUP) < n, len(evt.ISTUP) < n,
+		len(evt.MOTHUP) < n, len(evt.PUP) < n:
+		return nil, fmt.Errorf("convert: inconsistent LHEF event (nup=%d)", n)
+	}
+
+	o := &hepevt.Event{
+		Nhep:   n,
+		Isthep: make([]int, n),
+		Idhep:  make([]int, n),
+		Jmohep: make([][2]int, n),
+		Jdahep: make([][2]int, n),
+		Phep:   make([][5]float64, n),
+		Vhep:   make([][4]float64, n),
+	}
+	for i := 0; i < n; i++ {
+		o.Isthep[i] = StatusFromLHEF(evt.ISTUP[i])
+		o.Idhep[i] = int(evt.IDUP[i])
+		o.Jmohep[i] = [2]int{int(evt.MOTHUP[i][0]) - 1, int(evt.MOTHUP[i][1]) - 1}
+		o.Jdahep[i] = [2]int{-1, -1}
+		o.Phep[i] = evt.PUP[i]
+	}
+
+	for i := 0; i < n; i++ {
+		for _, m := range indices(o.Jmohep[i], i, n) {
+			dau := &o.Jdahep[m]
+			if dau[0] < 0 || i < dau[0] {
+				dau[0] = i
+			}
+			if i > dau[1] {
+				dau[1] = i
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// LHEFFromHEPEVT converts a HEPEVT event into a LHEF event.
+//
+// Status codes are converted with StatusToLHEF.
+// Daughters are not converted, as they are implied by the mothers of each
+// particle.
+// The spins of all particles are set to 9 (unknown).
+func LHEFFromHEPEVT(evt *hepevt.Event) (*lhef.HEPEUP, error) {
+	n := evt.Nhep
+	switch {
+	case n < 0:
+		return nil, fmt.Errorf("convert: invalid number of particles (%d)", n)
+	case len(evt.Isthep) < n, len(evt.Idhep) < n,
+		len(evt.Jmohep) < n, len(evt.Phep) < n:
+		return nil, fmt.Errorf("convert: inconsistent HEPEVT event (nhep=%d)", n)
+	}
+
+	o := &lhef.HEPEUP{
+		NUP:    int32(n),
+		XWGTUP: 1,
+		IDUP:   make([]int64, n),
+		ISTUP:  make([]int32, n),
+		MOTHUP: make([][2]int32, n),
+		ICOLUP: make([][2]int32, n),
+		PUP:    make([][5]float64, n),
+		VTIMUP: make([]float64, n),
+		SPINUP: make([]float64, n),
+	}
+	for i := 0; i < n; i++ {
+		o.IDUP[i] = int64(evt.Idhep[i])
+		o.ISTUP[i] = StatusToLHEF(evt.Isthep[i])
+		for j, m := range evt.Jmohep[i] {
+			if m < 0 || m >= n {
+				m = -1
+			}
+			o.MOTHUP[i][j] = int32(m + 1)
+		}
+		o.PUP[i] = evt.Phep[i]
+		o.SPINUP[i] = 9
+	}
+
+	return o, nil
+}
+
+// HepMCFromLHEF converts a LHEF event into a HepMC event.
+//
+// The event weight is stored as the first weight of the HepMC event, under
+// the name "0", followed by the named weights of the LHEF event.
+// Colour lines are stored as the flow codes 1 (colour) and 2 (anti-colour)
+// of each particle.
+// The signal vertex is the decay vertex of the first incoming particle.
+func HepMCFromLHEF(evt *lhef.HEPEUP) (*hepmc.Event, error) {
+	hep, err := HEPEVTFromLHEF(evt)
+	if err != nil {
+		return nil, err
+	}
+	o, err := HepMCFromHEPEVT(hep)
+	if err != nil {
+		return nil, err
+	}
+
+	o.SignalProcessID = int(evt.IDPRUP)
+	o.Scale = evt.SCALUP
+	o.AlphaQCD = evt.AQCDUP
+	o.AlphaQED = evt.AQEDUP
+
+	err = o.Weights.Add("0", evt.XWGTUP)
+	if err != nil {
+		return nil, fmt.Errorf("convert: could not add event weight: %w", err)
+	}
+	for _, w := range evt.Weights {
+		for i, v := range w.Weights {
+			name := w.Name
+			if len(w.Weights) > 1 {
+				name = fmt.Sprintf("%s_%d", w.Name, i)
+			}
+			err = o.Weights.Add(name, v)
+			if err != nil {
+				return nil, fmt.Errorf("convert: could not add weight %q: %w", name, err)
+			}
+		}
+	}
+
+	if evt.PdfInfo != (lhef.PDFInfo{}) {
+		o.PdfInfo = &hepmc.PdfInfo{
+			ID1:      int(evt.PdfInfo.P1),
+			ID2:      int(evt.PdfInfo.P2),
+			X1:       evt.PdfInfo.X1,
+			X2:       evt.PdfInfo.X2,
+			ScalePDF: evt.PdfInfo.Scale,
+			Pdf1:     evt.PdfInfo.XF1,
+			Pdf2:     evt.PdfInfo.XF2,
+		}
+	}
+
+	for i := 0; i < len(evt.ICOLUP) && i < hep.Nhep; i++ {
+		col := evt.ICOLUP[i]
+		if col == [2]int32{} {
+			continue
+		}
+		p := o.Particles[i+1]
+		p.Flow.Icode = make(map[int]int, 2)
+		for j, c := range col {
+			if c != 0 {
+				p.Flow.Icode[j+1] = int(c)
+			}
+		}
+	}
+
+	if p := o.Beams[0]; p != nil {
+		o.SignalVertex = p.EndVertex
+	}
+
+	return o, nil
+}
+
+// LHEFFromHepMC converts a HepMC event into a LHEF event.
+//
+// The event weight is the first weight of the HepMC event (or 1 if the event
+// has no weight.)
+// Colour lines are read from the flow codes 1 (colour) and 2 (anti-colour)
+// of each particle.
+func LHEFFromHepMC(evt *hepmc.Event) (*lhef.HEPEUP, error) {
+	hep, ps, err := hepevtFromHepMC(evt)
+	if err != nil {
+		return nil, err
+	}
+	o, err := LHEFFromHEPEVT(hep)
+	if err != nil {
+		return nil, err
+	}
+
+	o.IDPRUP = int32(evt.SignalProcessID)
+	o.SCALUP = evt.Scale
+	o.AQCDUP = evt.AlphaQCD
+	o.AQEDUP = evt.AlphaQED
+	if len(evt.Weights.Slice) > 0 {
+		o.XWGTUP = evt.Weights.Slice[0]
+	}
+
+	if pdf := evt.PdfInfo; pdf != nil {
+		o.PdfInfo = lhef.PDFInfo{
+			P1:    int64(pdf.ID1),
+			P2:    int64(pdf.ID2),
+			X1:    pdf.X1,
+			X2:    pdf.X2,
+			XF1:   pdf.Pdf1,
+			XF2:   pdf.Pdf2,
+			Scale: pdf.ScalePDF,
+		}
+	}
+
+	for i, p := range ps {
+		o.ICOLUP[i] = [2]int32{
+			int32(p.Flow.Icode[1]),
+			int32(p.Flow.Icode[2]),
+		}
+	}
+
+	return o, nil
+}
@@ -0,0 +1,405 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert_test
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"go-hep.org/x/hep/hepevt"
+	"go-hep.org/x/hep/hepmc"
+	"go-hep.org/x/hep/heputils/convert"
+	"go-hep.org/x/hep/lcio"
+	"go-hep.org/x/hep/lhef"
+)
+
+func TestStatus(t *testing.T) {
+	for _, tc := range []struct {
+		lhe int32
+		hep int
+	}{
+		{-1, 4},
+		{1, 1},
+		{2, 2},
+		{-2, -2},
+		{3, 3},
+		{-9, -9},
+	} {
+		if got, want := convert.StatusFromLHEF(tc.lhe), tc.hep; got != want {
+			t.Fatalf("invalid status from LHEF %d: got=%d, want=%d", tc.lhe, got, want)
+		}
+		if got, want := convert.StatusToLHEF(tc.hep), tc.lhe; got != want {
+			t.Fatalf("invalid status to LHEF %d: got=%d, want=%d", tc.hep, got, want)
+		}
+	}
+}
+
+func TestHepMC(t *testing.T) {
+	f, err := os.Open("../../hepmc/testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec := hepmc.NewDecoder(f)
+	for i := 0; ; i++ {
+		var evt hepmc.Event
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+
+		hep, err := convert.HEPEVTFromHepMC(&evt)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert to HEPEVT: %+v", i, err)
+		}
+		if got, want := hep.Nhep, len(evt.Particles); got != want {
+			t.Fatalf("evt %d: invalid number of particles: got=%d, want=%d", i, got, want)
+		}
+
+		got, err := convert.HepMCFromHEPEVT(hep)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert from HEPEVT: %+v", i, err)
+		}
+		if got.EventNumber != evt.EventNumber {
+			t.Fatalf("evt %d: invalid event number: got=%d, want=%d", i, got.EventNumber, evt.EventNumber)
+		}
+
+		if got, want := len(got.Vertices), len(evt.Vertices); got != want {
+			t.Fatalf("evt %d: invalid number of vertices: got=%d, want=%d", i, got, want)
+		}
+		for _, p := range got.Particles {
+			if p.ProdVertex == nil && p.EndVertex == nil {
+				t.Fatalf("evt %d: particle %d not attached to any vertex", i, p.Barcode)
+			}
+		}
+
+		// the rebuilt graph yields the same HEPEVT event.
+		hep2, err := convert.HEPEVTFromHepMC(got)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert back to HEPEVT: %+v", i, err)
+		}
+		if !reflect.DeepEqual(hep2, hep) {
+			t.Fatalf("evt %d: invalid round-trip:\ngot= %+v\nwant=%+v", i, hep2, hep)
+		}
+		if got.Beams[0].Barcode != 1 || got.Beams[1].Barcode != 2 {
+			t.Fatalf("evt %d: invalid beams: %d, %d", i, got.Beams[0].Barcode, got.Beams[1].Barcode)
+		}
+	}
+}
+
+func TestLCIO(t *testing.T) {
+	f, err := os.Open("../../hepmc/testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec := hepmc.NewDecoder(f)
+	for i := 0; ; i++ {
+		var evt hepmc.Event
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+
+		mcs, err := convert.LCIOFromHepMC(&evt)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert to LCIO: %+v", i, err)
+		}
+		if got, want := len(mcs.Particles), len(evt.Particles); got != want {
+			t.Fatalf("evt %d: invalid number of particles: got=%d, want=%d", i, got, want)
+		}
+		for j := range mcs.Particles {
+			mc := &mcs.Particles[j]
+			for _, dau := range mc.Children {
+				if !hasParticle(dau.Parents, mc) {
+					t.Fatalf("evt %d: particle %d is not a parent of its daughters", i, j)
+				}
+			}
+		}
+
+		got, err := convert.HepMCFromLCIO(mcs)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert from LCIO: %+v", i, err)
+		}
+		if got, want := len(got.Vertices), len(evt.Vertices); got != want {
+			t.Fatalf("evt %d: invalid number of vertices: got=%d, want=%d", i, got, want)
+		}
+
+		// the rebuilt graph yields the same HEPEVT event.
+		want, err := convert.HEPEVTFromHepMC(&evt)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert to HEPEVT: %+v", i, err)
+		}
+		hep, err := convert.HEPEVTFromHepMC(got)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert back to HEPEVT: %+v", i, err)
+		}
+		for _, tc := range []struct {
+			name      string
+			got, want interface{}
+		}{
+			{"status", hep.Isthep, want.Isthep},
+			{"pdg", hep.Idhep, want.Idhep},
+			{"mothers", hep.Jmohep, want.Jmohep},
+			{"daughters", hep.Jdahep, want.Jdahep},
+		} {
+			if !reflect.DeepEqual(tc.got, tc.want) {
+				t.Fatalf("evt %d: invalid round-trip of %s:\ngot= %v\nwant=%v", i, tc.name, tc.got, tc.want)
+			}
+		}
+		for j := range hep.Phep {
+			for k := 0; k < 3; k++ {
+				if got, want := hep.Phep[j][k], want.Phep[j][k]; got != want {
+					t.Fatalf("evt %d: invalid momentum of particle %d: got=%v, want=%v", i, j, got, want)
+				}
+			}
+			for k := 0; k < 3; k++ {
+				if got, want := hep.Vhep[j][k], want.Vhep[j][k]; got != want {
+					t.Fatalf("evt %d: invalid vertex of particle %d: got=%v, want=%v", i, j, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestHepMCFromLCIO(t *testing.T) {
+	// e+ e- -> Z -> (mu+, mu-) ; mu- -> (e-, nu_mu, anti-nu_e)
+	mcs := &lcio.McParticleContainer{
+		Particles: []lcio.McParticle{
+			{PDG: -11, GenStatus: 4, P: [3]float64{0, 0, +45}, Charge: +1},
+			{PDG: +11, GenStatus: 4, P: [3]float64{0, 0, -45}, Charge: -1},
+			{PDG: 23, GenStatus: 2, Mass: 90},
+			{PDG: -13, GenStatus: 1, P: [3]float64{+10, 0, 0}, Charge: +1},
+			{PDG: +13, GenStatus: 2, P: [3]float64{-10, 0, 0}, Charge: -1},
+			{PDG: 11, GenStatus: 1, P: [3]float64{-3, 0, 0}, Charge: -1, Vertex: [3]float64{1, 2, 3}, Time: 2},
+			{PDG: 14, GenStatus: 1, P: [3]float64{-3, 1, 0}, Vertex: [3]float64{1, 2, 3}, Time: 2},
+			{PDG: -12, GenStatus: 1, P: [3]float64{-4, -1, 0}, Vertex: [3]float64{1, 2, 3}, Time: 2, ColorFlow: [2]int32{501, 0}},
+		},
+	}
+	ps := mcs.Particles
+	link := func(parents []int, children ...int) {
+		for _, c := range children {
+			for _, p := range parents {
+				ps[p].Children = append(ps[p].Children, &ps[c])
+				ps[c].Parents = append(ps[c].Parents, &ps[p])
+			}
+		}
+	}
+	link([]int{0, 1}, 2)
+	link([]int{2}, 3, 4)
+	link([]int{4}, 5, 6, 7)
+
+	evt, err := convert.HepMCFromLCIO(mcs)
+	if err != nil {
+		t.Fatalf("could not convert from LCIO: %+v", err)
+	}
+	if got, want := len(evt.Vertices), 3; got != want {
+		t.Fatalf("invalid number of vertices: got=%d, want=%d", got, want)
+	}
+	if evt.Beams[0].Barcode != 1 || evt.Beams[1].Barcode != 2 {
+		t.Fatalf("invalid beams: %d, %d", evt.Beams[0].Barcode, evt.Beams[1].Barcode)
+	}
+	vtx := evt.Particles[6].ProdVertex
+	if vtx == nil || vtx != evt.Particles[5].EndVertex {
+		t.Fatalf("invalid decay vertex of the muon")
+	}
+	if got, want := vtx.Position.T(), 2*299.792458; got != want {
+		t.Fatalf("invalid vertex time: got=%v, want=%v", got, want)
+	}
+	if got, want := evt.Particles[8].Flow.Icode[1], 501; got != want {
+		t.Fatalf("invalid colour flow: got=%d, want=%d", got, want)
+	}
+	if got, want := evt.Particles[3].Momentum.E(), 90.0; got != want {
+		t.Fatalf("invalid Z energy: got=%v, want=%v", got, want)
+	}
+
+	got, err := convert.LCIOFromHepMC(evt)
+	if err != nil {
+		t.Fatalf("could not convert to LCIO: %+v", err)
+	}
+	// particle identifiers are derived from their address: ignore them.
+	ids := regexp.MustCompile(`(?m)^\[\d+\]`)
+	if got, want := ids.ReplaceAllString(got.String(), "[id]"), ids.ReplaceAllString(mcs.String(), "[id]"); got != want {
+		t.Fatalf("invalid round-trip:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func hasParticle(ps []*lcio.McParticle, p *lcio.McParticle) bool {
+	for _, pp := range ps {
+		if pp == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHEPEVT(t *testing.T) {
+	// p1 + p2 -> p3 -> (p4, p5) ; p6 is isolated.
+	// p3 only knows about its daughters.
+	hep := &hepevt.Event{
+		Nevhep: 42,
+		Nhep:   6,
+		Isthep: []int{4, 4, 2, 1, 1, 1},
+		Idhep:  []int{2212, 2212, 23, 11, -11, 22},
+		Jmohep: [][2]int{{-1, -1}, {-1, -1}, {0, 1}, {-1, -1}, {-1, -1}, {-1, -1}},
+		Jdahep: [][2]int{{2, -1}, {2, -1}, {3, 4}, {-1, -1}, {-1, -1}, {-1, -1}},
+		Phep: [][5]float64{
+			{0, 0, +7000, 7000, 0.938},
+			{0, 0, -7000, 7000, 0.938},
+			{1, 2, 3, 100, 91.2},
+			{1, 1, 1, 50, 0},
+			{0, 1, 2, 50, 0},
+			{1, 0, 0, 1, 0},
+		},
+		Vhep: [][4]float64{
+			{}, {}, {}, {0, 0, 1, 0}, {0, 0, 1, 0}, {},
+		},
+	}
+
+	evt, err := convert.HepMCFromHEPEVT(hep)
+	if err != nil {
+		t.Fatalf("could not convert from HEPEVT: %+v", err)
+	}
+	if got, want := len(evt.Particles), 6; got != want {
+		t.Fatalf("invalid number of particles: got=%d, want=%d", got, want)
+	}
+	if got, want := len(evt.Vertices), 3; got != want {
+		t.Fatalf("invalid number of vertices: got=%d, want=%d", got, want)
+	}
+	if evt.Beams[0] != evt.Particles[1] || evt.Beams[1] != evt.Particles[2] {
+		t.Fatalf("invalid beams")
+	}
+	z := evt.Particles[3]
+	if z.ProdVertex == nil || len(z.ProdVertex.ParticlesIn) != 2 {
+		t.Fatalf("invalid production vertex for Z")
+	}
+	if z.EndVertex == nil || len(z.EndVertex.ParticlesOut) != 2 {
+		t.Fatalf("invalid decay vertex for Z")
+	}
+	if got, want := z.EndVertex.Position.Z(), 1.0; got != want {
+		t.Fatalf("invalid decay vertex position: got=%v, want=%v", got, want)
+	}
+	if p := evt.Particles[6]; p.ProdVertex == nil || p.EndVertex != nil {
+		t.Fatalf("invalid vertices for isolated particle")
+	}
+
+	got, err := convert.HEPEVTFromHepMC(evt)
+	if err != nil {
+		t.Fatalf("could not convert to HEPEVT: %+v", err)
+	}
+	hep.Jmohep[3] = [2]int{2, -1}
+	hep.Jmohep[4] = [2]int{2, -1}
+	hep.Jdahep[0] = [2]int{2, 2}
+	hep.Jdahep[1] = [2]int{2, 2}
+	if !reflect.DeepEqual(got, hep) {
+		t.Fatalf("invalid round-trip:\ngot= %+v\nwant=%+v", got, hep)
+	}
+
+	for _, tc := range []*hepevt.Event{
+		{Nhep: -1},
+		{Nhep: 1},
+	} {
+		_, err := convert.HepMCFromHEPEVT(tc)
+		if err == nil {
+			t.Fatalf("expected an error for %+v", tc)
+		}
+		_, err = convert.LHEFFromHEPEVT(tc)
+		if err == nil {
+			t.Fatalf("expected an error for %+v", tc)
+		}
+	}
+}
+
+func TestLHEF(t *testing.T) {
+	f, err := os.Open("../../lhef/testdata/ttbar.lhe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec, err := lhef.NewDecoder(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; ; i++ {
+		want, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+
+		hep, err := convert.HEPEVTFromLHEF(want)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert to HEPEVT: %+v", i, err)
+		}
+		for j, mothers := range want.MOTHUP {
+			for _, m := range mothers {
+				if m <= 0 {
+					continue
+				}
+				dau := hep.Jdahep[m-1]
+				if j < dau[0] || j > dau[1] {
+					t.Fatalf("evt %d: particle %d not a daughter of %d: %v", i, j, m-1, dau)
+				}
+			}
+		}
+
+		evt, err := convert.HepMCFromLHEF(want)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert to HepMC: %+v", i, err)
+		}
+		if got, want := len(evt.Particles), int(want.NUP); got != want {
+			t.Fatalf("evt %d: invalid number of particles: got=%d, want=%d", i, got, want)
+		}
+		for _, p := range evt.Beams {
+			if p == nil || p.Status != 4 || p.EndVertex != evt.SignalVertex {
+				t.Fatalf("evt %d: invalid beams", i)
+			}
+		}
+		if got, want := evt.Weights.At("0"), want.XWGTUP; got != want {
+			t.Fatalf("evt %d: invalid weight: got=%v, want=%v", i, got, want)
+		}
+
+		got, err := convert.LHEFFromHepMC(evt)
+		if err != nil {
+			t.Fatalf("evt %d: could not convert from HepMC: %+v", i, err)
+		}
+		for _, tc := range []struct {
+			name      string
+			got, want interface{}
+		}{
+			{"NUP", got.NUP, want.NUP},
+			{"IDPRUP", got.IDPRUP, want.IDPRUP},
+			{"XWGTUP", got.XWGTUP, want.XWGTUP},
+			{"SCALUP", got.SCALUP, want.SCALUP},
+			{"AQEDUP", got.AQEDUP, want.AQEDUP},
+			{"AQCDUP", got.AQCDUP, want.AQCDUP},
+			{"IDUP", got.IDUP, want.IDUP},
+			{"ISTUP", got.ISTUP, want.ISTUP},
+			{"MOTHUP", got.MOTHUP, want.MOTHUP},
+			{"ICOLUP", got.ICOLUP, want.ICOLUP},
+			{"PUP", got.PUP, want.PUP},
+			{"PdfInfo", got.PdfInfo, want.PdfInfo},
+		} {
+			if !reflect.DeepEqual(tc.got, tc.want) {
+				t.Fatalf("evt %d: invalid %s round-trip:\ngot= %v\nwant=%v", i, tc.name, tc.got, tc.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert // import "go-hep.org/x/hep/heputils/convert"
+
+import (
+	"fmt"
+	"sort"
+
+	"go-hep.org/x/hep/fmom"
+	"go-hep.org/x/hep/hepmc"
+	"go-hep.org/x/hep/heppdt"
+	"go-hep.org/x/hep/lcio"
+)
+
+// cLight is the speed of light, in mm/ns.
+const cLight = 299.792458
+
+// HepMCFromLCIO converts a LCIO MCParticle collection into a HepMC event.
+//
+// Particles are given the barcode i+1, where i is their index in the
+// collection, and their generator status as status code.
+// Vertices are rebuilt from the parents and daughters of each particle,
+// positioned at the production vertex and time of their outgoing particles.
+// Colour flows are stored as the flow codes 1 and 2 of each particle.
+// The beams of the HepMC event are the first two particles without parents.
+func HepMCFromLCIO(mcs *lcio.McParticleContainer) (*hepmc.Event, error) {
+	o := &hepmc.Event{
+		Weights:      hepmc.NewWeights(),
+		Vertices:     make(map[int]*hepmc.Vertex),
+		Particles:    make(map[int]*hepmc.Particle),
+		MomentumUnit: hepmc.GEV,
+		LengthUnit:   hepmc.MM,
+	}
+
+	var (
+		n   = len(mcs.Particles)
+		ps  = make([]*hepmc.Particle, n)
+		idx = make(map[*lcio.McParticle]int, n)
+	)
+	for i := range mcs.Particles {
+		mc := &mcs.Particles[i]
+		idx[mc] = i
+		p := &hepmc.Particle{
+			Momentum:      fmom.NewPxPyPzE(mc.P[0], mc.P[1], mc.P[2], mc.Energy()),
+			PdgID:         int64(mc.PDG),
+			Status:        int(mc.GenStatus),
+			Barcode:       i + 1,
+			GeneratedMass: mc.Mass,
+		}
+		p.Flow.Particle = p
+		if mc.ColorFlow != [2]int32{} {
+			p.Flow.Icode = make(map[int]int, 2)
+			for j, c := range mc.ColorFlow {
+				if c != 0 {
+					p.Flow.Icode[j+1] = int(c)
+				}
+			}
+		}
+		ps[i] = p
+	}
+
+	lookup := func(mc *lcio.McParticle) (int, error) {
+		i, ok := idx[mc]
+		if !ok {
+			return -1, fmt.Errorf("particle not in collection")
+		}
+		return i, nil
+	}
+
+	newVertex := func(i int) (*hepmc.Vertex, error) {
+		mc := &mcs.Particles[i]
+		vtx := &hepmc.Vertex{
+			Position: fmom.NewPxPyPzE(
+				mc.Vertex[0], mc.Vertex[1], mc.Vertex[2],
+				float64(mc.Time)*cLight,
+			),
+		}
+		err := o.AddVertex(vtx)
+		if err != nil {
+			return nil, fmt.Errorf("convert: could not add vertex for particle %d: %w", i, err)
+		}
+		return vtx, nil
+	}
+
+	// decay vertices, from daughters.
+	for i := range mcs.Particles {
+		p := ps[i]
+		for _, child := range mcs.Particles[i].Children {
+			d, err := lookup(child)
+			if err != nil {
+				return nil, fmt.Errorf("convert: invalid daughter of particle %d: %w", i, err)
+			}
+			dau := ps[d]
+			switch {
+			case p.EndVertex == nil && dau.ProdVertex == nil:
+				var vtx *hepmc.Vertex
+				vtx, err = newVertex(d)
+				if err != nil {
+					return nil, err
+				}
+				err = vtx.AddParticleIn(p)
+				if err == nil {
+					err = vtx.AddParticleOut(dau)
+				}
+			case p.EndVertex == nil:
+				err = dau.ProdVertex.AddParticleIn(p)
+			case dau.ProdVertex == nil:
+				err = p.EndVertex.AddParticleOut(dau)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("convert: could not attach daughter %d of particle %d: %w", d, i, err)
+			}
+		}
+	}
+
+	// production vertices of the remaining particles, from parents.
+	for i := range mcs.Particles {
+		p := ps[i]
+		if p.ProdVertex != nil || len(mcs.Particles[i].Parents) == 0 {
+			continue
+		}
+		ms := make([]int, len(mcs.Particles[i].Parents))
+		for j, parent := range mcs.Particles[i].Parents {
+			m, err := lookup(parent)
+			if err != nil {
+				return nil, fmt.Errorf("convert: invalid parent of particle %d: %w", i, err)
+			}
+			ms[j] = m
+		}
+		var vtx *hepmc.Vertex
+		for _, m := range ms {
+			if ps[m].EndVertex != nil {
+				vtx = ps[m].EndVertex
+				break
+			}
+		}
+		if vtx == nil {
+			var err error
+			vtx, err = newVertex(i)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, m := range ms {
+			if ps[m].EndVertex != nil {
+				continue
+			}
+			err := vtx.AddParticleIn(ps[m])
+			if err != nil {
+				return nil, fmt.Errorf("convert: could not attach parent %d of particle %d: %w", m, i, err)
+			}
+		}
+		err := vtx.AddParticleOut(p)
+		if err != nil {
+			return nil, fmt.Errorf("convert: could not attach particle %d: %w", i, err)
+		}
+	}
+
+	nbeams := 0
+	for _, p := range ps {
+		if nbeams == len(o.Beams) {
+			break
+		}
+		if p.ProdVertex == nil {
+			o.Beams[nbeams] = p
+			nbeams++
+		}
+	}
+
+	// isolated particles.
+	for i, p := range ps {
+		if p.ProdVertex != nil || p.EndVertex != nil {
+			continue
+		}
+		vtx, err := newVertex(i)
+		if err != nil {
+			return nil, err
+		}
+		err = vtx.AddParticleOut(p)
+		if err != nil {
+			return nil, fmt.Errorf("convert: could not attach particle %d: %w", i, err)
+		}
+	}
+
+	return o, nil
+}
+
+// LCIOFromHepMC converts a HepMC event into a LCIO MCParticle collection.
+//
+// Particles are laid out by increasing barcode.
+// Their mass is the generated mass of the HepMC particle, their charge is
+// taken from the default heppdt table (or 0 for unknown particles), and their
+// status code is stored as generator status.
+// Parents and daughters are the incoming particles of the production vertex
+// and the outgoing particles of the decay vertex of each particle.
+// Colour flows are read from the flow codes 1 and 2 of each particle.
+func LCIOFromHepMC(evt *hepmc.Event) (*lcio.McParticleContainer, error) {
+	mom, err := evt.MomentumUnit.ConversionFactor(hepmc.GEV)
+	if err != nil {
+		return nil, fmt.Errorf("convert: could not convert momenta: %w", err)
+	}
+	pos, err := evt.LengthUnit.ConversionFactor(hepmc.MM)
+	if err != nil {
+		return nil, fmt.Errorf("convert: could not convert positions: %w", err)
+	}
+
+	ps := make([]*hepmc.Particle, 0, len(evt.Particles))
+	for _, p := range evt.Particles {
+		ps = append(ps, p)
+	}
+	sort.Sort(hepmc.Particles(ps))
+
+	o := &lcio.McParticleContainer{
+		Params: lcio.Params{
+			Floats:  map[string][]float32{},
+			Ints:    map[string][]int32{},
+			Strings: map[string][]string{},
+		},
+		Particles: make([]lcio.McParticle, len(ps)),
+	}
+
+	idx := make(map[*hepmc.Particle]int, len(ps))
+	for i, p := range ps {
+		idx[p] = i
+	}
+	links := func(vps []*hepmc.Particle) ([]*lcio.McParticle, error) {
+		if len(vps) == 0 {
+			return nil, nil
+		}
+		mcs := make([]*lcio.McParticle, len(vps))
+		for i, vp := range vps {
+			j, ok := idx[vp]
+			if !ok {
+				return nil, fmt.Errorf("particle %d not in event", vp.Barcode)
+			}
+			mcs[i] = &o.Particles[j]
+		}
+		return mcs, nil
+	}
+
+	for i, p := range ps {
+		mc := &o.Particles[i]
+		mc.PDG = int32(p.PdgID)
+		mc.GenStatus = int32(p.Status)
+		mc.P = [3]float64{
+			p.Momentum.Px() * mom,
+			p.Momentum.Py() * mom,
+			p.Momentum.Pz() * mom,
+		}
+		mc.Mass = p.GeneratedMass * mom
+		if pdt := heppdt.ParticleByID(heppdt.PID(p.PdgID)); pdt != nil {
+			mc.Charge = float32(pdt.Charge)
+		}
+		mc.ColorFlow = [2]int32{
+			int32(p.Flow.Icode[1]),
+			int32(p.Flow.Icode[2]),
+		}
+
+		if vtx := p.ProdVertex; vtx != nil {
+			mc.Vertex = [3]float64{
+				vtx.Position.X() * pos,
+				vtx.Position.Y() * pos,
+				vtx.Position.Z() * pos,
+			}
+			mc.Time = float32(vtx.Position.T() * pos / cLight)
+			mc.Parents, err = links(vtx.ParticlesIn)
+			if err != nil {
+				return nil, fmt.Errorf("convert: invalid parents for particle %d: %w", p.Barcode, err)
+			}
+		}
+		if vtx := p.EndVertex; vtx != nil {
+			mc.Children, err = links(vtx.ParticlesOut)
+			if err != nil {
+				return nil, fmt.Errorf("convert: invalid daughters for particle %d: %w", p.Barcode, err)
+			}
+		}
+	}
+
+	return o, nil
+}
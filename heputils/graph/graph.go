@@ -0,0 +1,184 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package graph provides traversal utilities over the particles graph of
+// HepMC events.
+//
+// HepMC event records are graphs of particles connected by vertices.
+// These graphs are expected to be acyclic but generators (or bugs in event
+// record manipulations) may produce cycles: all the functions of this
+// package visit each particle at most once.
+//
+// Slices of particles returned by this package are sorted by barcode.
+package graph // import "go-hep.org/x/hep/heputils/graph"
+
+import (
+	"sort"
+
+	"go-hep.org/x/hep/hepmc"
+)
+
+// Children returns the particles directly produced by the decay of p.
+func Children(p *hepmc.Particle) []*hepmc.Particle {
+	if p.EndVertex == nil {
+		return nil
+	}
+	return sorted(p.EndVertex.ParticlesOut)
+}
+
+// Parents returns the particles whose decay directly produced p.
+func Parents(p *hepmc.Particle) []*hepmc.Particle {
+	if p.ProdVertex == nil {
+		return nil
+	}
+	return sorted(p.ProdVertex.ParticlesIn)
+}
+
+// Descendants returns all the particles produced, directly or not, by the
+// decay of p.
+func Descendants(p *hepmc.Particle) []*hepmc.Particle {
+	return walk(p, Children)
+}
+
+// Ancestors returns all the particles whose decay produced, directly or not,
+// the particle p.
+func Ancestors(p *hepmc.Particle) []*hepmc.Particle {
+	return walk(p, Parents)
+}
+
+// FinalState returns the final state particles of the event, ie: the
+// particles with status 1 and no decay vertex.
+func FinalState(evt *hepmc.Event) []*hepmc.Particle {
+	var o []*hepmc.Particle
+	for _, p := range evt.Particles {
+		if hepmc.IsFinalState(p) {
+			o = append(o, p)
+		}
+	}
+	sort.Sort(hepmc.Particles(o))
+	return o
+}
+
+// FindDecayChain returns all the decay chains of the event matching the
+// provided PDG IDs, ie: the chains where a particle with ID pdgids[0]
+// decays into a particle with ID pdgids[1], which decays into a particle
+// with ID pdgids[2], and so on.
+//
+// Generators often record copies of a particle (e.g. after a recoil) as a
+// chain of particles with the same PDG ID.
+// These copies are skipped: each element of a returned chain is the last copy
+// of the particle, ie: the one which actually decays.
+//
+// Chains are sorted by the barcode of their first particle.
+func FindDecayChain(evt *hepmc.Event, pdgids ...int64) [][]*hepmc.Particle {
+	if len(pdgids) == 0 {
+		return nil
+	}
+
+	ps := make([]*hepmc.Particle, 0, len(evt.Particles))
+	for _, p := range evt.Particles {
+		ps = append(ps, p)
+	}
+	sort.Sort(hepmc.Particles(ps))
+
+	var chains [][]*hepmc.Particle
+	for _, p := range ps {
+		if p.PdgID != pdgids[0] || isCopy(p) {
+			continue
+		}
+		seen := make(map[*hepmc.Particle]bool)
+		chains = append(chains, decayChains(lastCopy(p, seen), pdgids[1:], seen)...)
+	}
+	return chains
+}
+
+// decayChains returns the decay chains starting with p and matching the
+// provided PDG IDs.
+func decayChains(p *hepmc.Particle, pdgids []int64, seen map[*hepmc.Particle]bool) [][]*hepmc.Particle {
+	if len(pdgids) == 0 {
+		return [][]*hepmc.Particle{{p}}
+	}
+
+	var chains [][]*hepmc.Particle
+	for _, c := range Children(p) {
+		if c.PdgID != pdgids[0] || seen[c] {
+			continue
+		}
+		// particles of sibling chains may be shared.
+		sub := make(map[*hepmc.Particle]bool, len(seen))
+		for k, v := range seen {
+			sub[k] = v
+		}
+		for _, chain := range decayChains(lastCopy(c, sub), pdgids[1:], sub) {
+			chains = append(chains, append([]*hepmc.Particle{p}, chain...))
+		}
+	}
+	return chains
+}
+
+// isCopy returns whether p is a copy of one of its parents.
+func isCopy(p *hepmc.Particle) bool {
+	for _, m := range Parents(p) {
+		if m.PdgID == p.PdgID && m != p {
+			return true
+		}
+	}
+	return false
+}
+
+// lastCopy follows the copies of p and returns the last one.
+// A particle is considered a copy of p if it is the only child of p with
+// the same PDG ID.
+func lastCopy(p *hepmc.Particle, seen map[*hepmc.Particle]bool) *hepmc.Particle {
+	seen[p] = true
+	for {
+		var next *hepmc.Particle
+		for _, c := range Children(p) {
+			if c.PdgID != p.PdgID {
+				continue
+			}
+			if next != nil {
+				// more than one copy: p actually decays.
+				return p
+			}
+			next = c
+		}
+		if next == nil || seen[next] {
+			return p
+		}
+		p = next
+		seen[p] = true
+	}
+}
+
+// walk returns all the particles reachable from p (p excluded), following
+// the provided links.
+func walk(p *hepmc.Particle, next func(p *hepmc.Particle) []*hepmc.Particle) []*hepmc.Particle {
+	var (
+		o     []*hepmc.Particle
+		seen  = map[*hepmc.Particle]bool{p: true}
+		queue = []*hepmc.Particle{p}
+	)
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, n := range next(cur) {
+			if seen[n] {
+				continue
+			}
+			seen[n] = true
+			o = append(o, n)
+			queue = append(queue, n)
+		}
+	}
+	sort.Sort(hepmc.Particles(o))
+	return o
+}
+
+func sorted(ps []*hepmc.Particle) []*hepmc.Particle {
+	o := make([]*hepmc.Particle, len(ps))
+	copy(o, ps)
+	sort.Sort(hepmc.Particles(o))
+	return o
+}
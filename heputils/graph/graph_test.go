@@ -0,0 +1,209 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graph_test
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"go-hep.org/x/hep/hepevt"
+	"go-hep.org/x/hep/hepmc"
+	"go-hep.org/x/hep/heputils/convert"
+	"go-hep.org/x/hep/heputils/graph"
+)
+
+// newEvent creates the following event:
+//
+//	p p -> Z t
+//	Z -> Z (copy) -> e- e+
+//	e- -> e- gamma
+//	t -> b W+
+//	W+ -> mu+ nu_mu
+func newEvent(t *testing.T) *hepmc.Event {
+	t.Helper()
+
+	const n = 13
+	hep := &hepevt.Event{
+		Nevhep: 1,
+		Nhep:   n,
+		Isthep: []int{4, 4, 2, 2, 2, 1, 2, 2, 1, 1, 1, 1, 1},
+		Idhep:  []int{2212, 2212, 23, 6, 23, 5, 24, 11, -11, -13, 14, 11, 22},
+		Jmohep: [][2]int{
+			{-1, -1}, {-1, -1}, {0, 1}, {0, 1}, {2, -1}, {3, -1}, {3, -1},
+			{4, -1}, {4, -1}, {6, -1}, {6, -1}, {7, -1}, {7, -1},
+		},
+		Jdahep: [][2]int{
+			{2, 3}, {2, 3}, {4, 4}, {5, 6}, {7, 8}, {-1, -1}, {9, 10},
+			{11, 12}, {-1, -1}, {-1, -1}, {-1, -1}, {-1, -1}, {-1, -1},
+		},
+		Phep: make([][5]float64, n),
+		Vhep: make([][4]float64, n),
+	}
+	evt, err := convert.HepMCFromHEPEVT(hep)
+	if err != nil {
+		t.Fatalf("could not create event: %+v", err)
+	}
+	return evt
+}
+
+func barcodes(ps []*hepmc.Particle) []int {
+	o := make([]int, len(ps))
+	for i, p := range ps {
+		o[i] = p.Barcode
+	}
+	return o
+}
+
+func TestGraph(t *testing.T) {
+	evt := newEvent(t)
+	p := func(bc int) *hepmc.Particle { return evt.Particles[bc] }
+
+	for _, tc := range []struct {
+		name string
+		got  []*hepmc.Particle
+		want []int
+	}{
+		{"final-state", graph.FinalState(evt), []int{6, 9, 10, 11, 12, 13}},
+		{"children-t", graph.Children(p(4)), []int{6, 7}},
+		{"children-e", graph.Children(p(6)), nil},
+		{"parents-Z", graph.Parents(p(3)), []int{1, 2}},
+		{"parents-p", graph.Parents(p(1)), nil},
+		{"descendants-Z", graph.Descendants(p(3)), []int{5, 8, 9, 12, 13}},
+		{"descendants-p", graph.Descendants(p(1)), []int{3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13}},
+		{"ancestors-gamma", graph.Ancestors(p(13)), []int{1, 2, 3, 5, 8}},
+		{"ancestors-p", graph.Ancestors(p(2)), nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := barcodes(tc.got)
+			if len(got) == 0 && len(tc.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("invalid particles: got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFindDecayChain(t *testing.T) {
+	evt := newEvent(t)
+
+	for _, tc := range []struct {
+		name   string
+		pdgids []int64
+		want   [][]int
+	}{
+		{"none", nil, nil},
+		{"protons", []int64{2212}, [][]int{{1}, {2}}},
+		{"Z", []int64{23}, [][]int{{5}}},
+		{"Z->ee", []int64{23, 11}, [][]int{{5, 12}}},
+		{"Z->ee->gamma", []int64{23, 11, 22}, nil}, // e- -> e- gamma is a copy of the electron
+		{"Z->mumu", []int64{23, 13}, nil},
+		{"t->W->mu", []int64{6, 24, -13}, [][]int{{4, 7, 10}}},
+		{"p->t", []int64{2212, 6}, [][]int{{1, 4}, {2, 4}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got [][]int
+			for _, chain := range graph.FindDecayChain(evt, tc.pdgids...) {
+				got = append(got, barcodes(chain))
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("invalid chains: got=%v, want=%v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCycle(t *testing.T) {
+	// p1 -> p2 -> p3 -> p1
+	evt := &hepmc.Event{
+		Vertices:  make(map[int]*hepmc.Vertex),
+		Particles: make(map[int]*hepmc.Particle),
+	}
+	var (
+		ps = []*hepmc.Particle{
+			{PdgID: 23, Status: 2, Barcode: 1},
+			{PdgID: 23, Status: 2, Barcode: 2},
+			{PdgID: 11, Status: 2, Barcode: 3},
+		}
+		vs = []*hepmc.Vertex{{}, {}, {}}
+	)
+	for i, vtx := range vs {
+		err := evt.AddVertex(vtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = vtx.AddParticleIn(ps[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = vtx.AddParticleOut(ps[(i+1)%len(ps)])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := barcodes(graph.Descendants(ps[0])), []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid descendants: got=%v, want=%v", got, want)
+	}
+	if got, want := barcodes(graph.Ancestors(ps[0])), []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid ancestors: got=%v, want=%v", got, want)
+	}
+	if got := graph.FinalState(evt); len(got) != 0 {
+		t.Fatalf("invalid final state: %v", barcodes(got))
+	}
+
+	// p2 is a copy of p1: the chain starts at p3 and stops at the last copy
+	// of p1.
+	var got [][]int
+	for _, chain := range graph.FindDecayChain(evt, 11, 23) {
+		got = append(got, barcodes(chain))
+	}
+	if want := [][]int{{3, 2}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid chains: got=%v, want=%v", got, want)
+	}
+
+	// p1 -> p3 -> p1 would visit p1 twice.
+	if got := graph.FindDecayChain(evt, 23, 11, 23); len(got) != 0 {
+		t.Fatalf("invalid chains: %v", got)
+	}
+}
+
+func TestHepMCFile(t *testing.T) {
+	f, err := os.Open("../../hepmc/testdata/test.hepmc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec := hepmc.NewDecoder(f)
+	for i := 0; ; i++ {
+		var evt hepmc.Event
+		err := dec.Decode(&evt)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+
+		fs := graph.FinalState(&evt)
+		if len(fs) == 0 {
+			t.Fatalf("evt %d: no final state particles", i)
+		}
+		beams := append(graph.Descendants(evt.Beams[0]), graph.Descendants(evt.Beams[1])...)
+		from := make(map[*hepmc.Particle]bool, len(beams))
+		for _, p := range beams {
+			from[p] = true
+		}
+		for _, p := range fs {
+			if !from[p] {
+				t.Fatalf("evt %d: final state particle %d does not come from the beams", i, p.Barcode)
+			}
+		}
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color/palette"
+	stddraw "image/draw"
+	"image/gif"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+// SaveAnimation saves a sequence of plots to an animated image file,
+// each plot being displayed for the duration delay.
+// The file format is determined by the extension.
+//
+// Supported extensions are:
+//
+//	.gif, .apng and .png.
+//
+// The .png and .apng extensions produce an animated PNG (APNG) file.
+// Animated GIF files are restricted to a 256 colors palette, with
+// dithering, whereas APNG files hold the frames in full colors.
+// The animation loops forever.
+//
+// If w or h are <= 0, the value is chosen such that it follows the Golden Ratio.
+// If w and h are <= 0, the values are chosen such that they follow the Golden Ratio
+// (the width is defaulted to vgimg.DefaultWidth).
+//
+// All the frames are rendered with the DPI of the first frame if it is a *Fig,
+// or with vgimg.DefaultDPI otherwise.
+func SaveAnimation(frames []Drawer, delay time.Duration, w, h vg.Length, fname string) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("hplot: need at least 1 frame")
+	}
+
+	var encode func(w io.Writer, imgs []image.Image, delay time.Duration) error
+	switch format := strings.ToLower(filepath.Ext(fname)); format {
+	case ".gif":
+		encode = encodeGIF
+	case ".apng", ".png":
+		encode = encodeAPNG
+	default:
+		return fmt.Errorf("hplot: unsupported animation format: %q", strings.TrimPrefix(format, "."))
+	}
+
+	w, h = Dims(w, h)
+
+	dpi := float64(vgimg.DefaultDPI)
+	if fig, ok := frames[0].(*Fig); ok {
+		dpi = fig.DPI
+	}
+
+	imgs := make([]image.Image, len(frames))
+	for i, p := range frames {
+		c := vgimg.NewWith(
+			vgimg.UseDPI(int(dpi)),
+			vgimg.UseWH(w, h),
+		)
+		p.Draw(draw.New(c))
+		imgs[i] = c.Image()
+	}
+
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("hplot: could not create animation file: %w", err)
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	err = encode(bw, imgs, delay)
+	if err != nil {
+		return fmt.Errorf("hplot: could not encode animation: %w", err)
+	}
+
+	err = bw.Flush()
+	if err != nil {
+		return fmt.Errorf("hplot: could not flush animation: %w", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("hplot: could not close animation file: %w", err)
+	}
+	return nil
+}
+
+// encodeGIF writes the images as an animated GIF, quantized with the
+// Plan9 palette and Floyd-Steinberg dithering.
+func encodeGIF(w io.Writer, imgs []image.Image, delay time.Duration) error {
+	anim := gif.GIF{
+		Image: make([]*image.Paletted, len(imgs)),
+		Delay: make([]int, len(imgs)),
+	}
+	cs := int(delay / (10 * time.Millisecond))
+	for i, img := range imgs {
+		dst := image.NewPaletted(img.Bounds(), palette.Plan9)
+		stddraw.FloydSteinberg.Draw(dst, img.Bounds(), img, img.Bounds().Min)
+		anim.Image[i] = dst
+		anim.Delay[i] = cs
+	}
+	return gif.EncodeAll(w, &anim)
+}
+
+// encodeAPNG writes the images as an animated PNG.
+// All the images must have the same bounds.
+//
+// The frames are stored as 8-bit RGBA, non-interlaced, images.
+// See https://wiki.mozilla.org/APNG_Specification for the details of
+// the format.
+func encodeAPNG(w io.Writer, imgs []image.Image, delay time.Duration) error {
+	var (
+		rect = imgs[0].Bounds()
+		seq  uint32
+		enc  = apngEncoder{w: w}
+	)
+
+	// express the delay as a fraction of seconds fitting in 16 bits.
+	var (
+		num = delay.Milliseconds()
+		den = int64(1000)
+	)
+	for num > math.MaxUint16 && den > 1 {
+		num /= 10
+		den /= 10
+	}
+	if num > math.MaxUint16 {
+		num = math.MaxUint16
+	}
+
+	enc.write([]byte("\x89PNG\r\n\x1a\n"))
+
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(rect.Dx()))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(rect.Dy()))
+	ihdr[8] = 8  // bit depth
+	ihdr[9] = 6  // color type: RGBA
+	ihdr[10] = 0 // compression method
+	ihdr[11] = 0 // filter method
+	ihdr[12] = 0 // interlace method
+	enc.chunk("IHDR", ihdr)
+
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(len(imgs)))
+	binary.BigEndian.PutUint32(actl[4:], 0) // loop forever
+	enc.chunk("acTL", actl)
+
+	for i, img := range imgs {
+		if img.Bounds() != rect {
+			return fmt.Errorf("invalid bounds for frame %d (got=%v, want=%v)", i, img.Bounds(), rect)
+		}
+
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(rect.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(rect.Dy()))
+		binary.BigEndian.PutUint32(fctl[12:], 0) // x offset
+		binary.BigEndian.PutUint32(fctl[16:], 0) // y offset
+		binary.BigEndian.PutUint16(fctl[20:], uint16(num))
+		binary.BigEndian.PutUint16(fctl[22:], uint16(den))
+		fctl[24] = 0 // dispose op: none
+		fctl[25] = 0 // blend op: source
+		enc.chunk("fcTL", fctl)
+		seq++
+
+		data, err := apngFrame(img)
+		if err != nil {
+			return fmt.Errorf("could not compress frame %d: %w", i, err)
+		}
+
+		if i == 0 {
+			enc.chunk("IDAT", data)
+			continue
+		}
+
+		fdat := make([]byte, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, seq)
+		copy(fdat[4:], data)
+		enc.chunk("fdAT", fdat)
+		seq++
+	}
+
+	enc.chunk("IEND", nil)
+	return enc.err
+}
+
+// apngFrame returns the zlib-compressed, filtered, scanlines of img.
+// Each scanline is filtered with the PNG 'Up' filter.
+func apngFrame(img image.Image) ([]byte, error) {
+	var (
+		rect = img.Bounds()
+		nrgb = image.NewNRGBA(rect)
+		buf  = new(bytes.Buffer)
+		zw   = zlib.NewWriter(buf)
+		row  = make([]byte, 1+4*rect.Dx())
+	)
+	stddraw.Draw(nrgb, rect, img, rect.Min, stddraw.Src)
+
+	row[0] = 2 // filter type: Up
+	for y := 0; y < rect.Dy(); y++ {
+		cur := nrgb.Pix[y*nrgb.Stride : y*nrgb.Stride+4*rect.Dx()]
+		if y == 0 {
+			copy(row[1:], cur)
+		} else {
+			prv := nrgb.Pix[(y-1)*nrgb.Stride:]
+			for i, v := range cur {
+				row[1+i] = v - prv[i]
+			}
+		}
+		_, err := zw.Write(row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// apngEncoder writes PNG chunks, remembering the first error.
+type apngEncoder struct {
+	w   io.Writer
+	err error
+}
+
+func (enc *apngEncoder) write(p []byte) {
+	if enc.err != nil {
+		return
+	}
+	_, enc.err = enc.w.Write(p)
+}
+
+func (enc *apngEncoder) chunk(name string, data []byte) {
+	var hdr [8]byte
+	binary.BigEndian.PutUint32(hdr[:4], uint32(len(data)))
+	copy(hdr[4:], name)
+
+	crc := crc32.NewIEEE()
+	_, _ = crc.Write(hdr[4:])
+	_, _ = crc.Write(data)
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+
+	enc.write(hdr[:])
+	enc.write(data)
+	enc.write(sum[:])
+}
@@ -0,0 +1,57 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of making an animated GIF showing a distribution
+// evolving with a cut threshold.
+func ExampleSaveAnimation() {
+	var (
+		src  = rand.New(rand.NewSource(1234))
+		dist = distuv.Normal{Mu: 0, Sigma: 1, Src: src}
+		xs   = make([]float64, 10000)
+	)
+	for i := range xs {
+		xs[i] = dist.Rand()
+	}
+
+	var frames []hplot.Drawer
+	for _, cut := range []float64{-2, -1, 0, 1, 2} {
+		h := hbook.NewH1D(40, -4, +4)
+		for _, x := range xs {
+			if x > cut {
+				h.Fill(x, 1)
+			}
+		}
+
+		p := hplot.New()
+		p.Title.Text = fmt.Sprintf("x > %v", cut)
+		p.X.Label.Text = "x"
+		p.Y.Label.Text = "entries"
+		p.X.Min = -4
+		p.X.Max = +4
+		p.Y.Min = 0
+		p.Y.Max = 500
+		p.Add(hplot.NewH1D(h), hplot.NewGrid())
+
+		frames = append(frames, p)
+	}
+
+	err := hplot.SaveAnimation(frames, 500*time.Millisecond, 10*vg.Centimeter, -1, "testdata/animation.gif")
+	if err != nil {
+		log.Fatalf("could not save animation: %+v", err)
+	}
+}
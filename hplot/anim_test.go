@@ -0,0 +1,185 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+)
+
+func newAnimFrames(n int) []Drawer {
+	frames := make([]Drawer, n)
+	for i := range frames {
+		p := New()
+		p.Title.Text = fmt.Sprintf("frame %d", i)
+		p.X.Min = 0
+		p.X.Max = float64(n)
+		p.Y.Min = 0
+		p.Y.Max = float64(n)
+		line, _ := plotter.NewLine(plotter.XYs{{X: 0, Y: 0}, {X: float64(i), Y: float64(i)}})
+		p.Add(line)
+		frames[i] = p
+	}
+	return frames
+}
+
+func TestSaveAnimationGIF(t *testing.T) {
+	const n = 3
+	var (
+		frames = newAnimFrames(n)
+		fname  = filepath.Join(t.TempDir(), "anim.gif")
+	)
+
+	err := SaveAnimation(frames, 250*time.Millisecond, 5*vg.Centimeter, 4*vg.Centimeter, fname)
+	if err != nil {
+		t.Fatalf("could not save animation: %+v", err)
+	}
+
+	f, err := os.Open(fname)
+	if err != nil {
+		t.Fatalf("could not open animation: %+v", err)
+	}
+	defer f.Close()
+
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("could not decode animation: %+v", err)
+	}
+
+	if got, want := len(anim.Image), n; got != want {
+		t.Fatalf("invalid number of frames: got=%d, want=%d", got, want)
+	}
+	for i, delay := range anim.Delay {
+		if got, want := delay, 25; got != want {
+			t.Fatalf("invalid delay for frame %d: got=%d, want=%d", i, got, want)
+		}
+	}
+	if got, want := anim.LoopCount, 0; got != want {
+		t.Fatalf("invalid loop count: got=%d, want=%d", got, want)
+	}
+}
+
+func TestSaveAnimationAPNG(t *testing.T) {
+	const n = 3
+	var (
+		frames = newAnimFrames(n)
+		fname  = filepath.Join(t.TempDir(), "anim.apng")
+		w, h   = 5 * vg.Centimeter, 4 * vg.Centimeter
+	)
+
+	err := SaveAnimation(frames, 250*time.Millisecond, w, h, fname)
+	if err != nil {
+		t.Fatalf("could not save animation: %+v", err)
+	}
+
+	raw, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatalf("could not read animation: %+v", err)
+	}
+
+	// decoders unaware of APNG display the first frame.
+	img, err := png.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("could not decode first frame: %+v", err)
+	}
+
+	c := vgimg.New(w, h)
+	frames[0].Draw(draw.New(c))
+	want := c.Image()
+	if got, want := img.Bounds(), want.Bounds(); got != want {
+		t.Fatalf("invalid bounds: got=%v, want=%v", got, want)
+	}
+	for y := want.Bounds().Min.Y; y < want.Bounds().Max.Y; y++ {
+		for x := want.Bounds().Min.X; x < want.Bounds().Max.X; x++ {
+			r1, g1, b1, a1 := img.At(x, y).RGBA()
+			r2, g2, b2, a2 := want.At(x, y).RGBA()
+			if r1>>8 != r2>>8 || g1>>8 != g2>>8 || b1>>8 != b2>>8 || a1>>8 != a2>>8 {
+				t.Fatalf("invalid pixel (%d,%d): got=%v, want=%v", x, y, img.At(x, y), want.At(x, y))
+			}
+		}
+	}
+
+	chunks := make(map[string]int)
+	buf := raw[8:]
+	for len(buf) > 0 {
+		var (
+			size = binary.BigEndian.Uint32(buf[:4])
+			name = string(buf[4:8])
+			data = buf[8 : 8+size]
+			sum  = binary.BigEndian.Uint32(buf[8+size:])
+		)
+		if got, want := sum, crc32.ChecksumIEEE(buf[4:8+size]); got != want {
+			t.Fatalf("invalid CRC for chunk %q: got=0x%x, want=0x%x", name, got, want)
+		}
+		if name == "acTL" {
+			if got, want := binary.BigEndian.Uint32(data), uint32(n); got != want {
+				t.Fatalf("invalid number of frames: got=%d, want=%d", got, want)
+			}
+		}
+		chunks[name]++
+		buf = buf[12+size:]
+	}
+
+	for _, tc := range []struct {
+		name string
+		want int
+	}{
+		{"IHDR", 1},
+		{"acTL", 1},
+		{"fcTL", n},
+		{"IDAT", 1},
+		{"fdAT", n - 1},
+		{"IEND", 1},
+	} {
+		if got := chunks[tc.name]; got != tc.want {
+			t.Fatalf("invalid number of %q chunks: got=%d, want=%d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSaveAnimationErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name   string
+		frames []Drawer
+		fname  string
+		want   error
+	}{
+		{
+			name:  "no-frames",
+			fname: filepath.Join(dir, "anim.gif"),
+			want:  fmt.Errorf("hplot: need at least 1 frame"),
+		},
+		{
+			name:   "unknown-format",
+			frames: newAnimFrames(1),
+			fname:  filepath.Join(dir, "anim.mp4"),
+			want:   fmt.Errorf(`hplot: unsupported animation format: "mp4"`),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := SaveAnimation(tc.frames, time.Second, -1, -1, tc.fname)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got, want := err.Error(), tc.want.Error(); got != want {
+				t.Fatalf("invalid error:\ngot= %v\nwant=%v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// VertSpan draws a shaded vertical span between the Min and Max
+// X values, over the whole height of the plot.
+// VertSpan can be used to highlight e.g. a signal region.
+type VertSpan struct {
+	Min, Max  float64
+	FillColor color.Color
+	LineStyle draw.LineStyle // style of the vertical edges of the span
+}
+
+// VSpan creates a vertical span between min and max, filled with
+// the provided color.
+// The edges of the span are not drawn by default.
+func VSpan(min, max float64, fill color.Color) *VertSpan {
+	return &VertSpan{
+		Min:       min,
+		Max:       max,
+		FillColor: fill,
+	}
+}
+
+// Plot implements the plot.Plotter interface.
+func (vs *VertSpan) Plot(c draw.Canvas, plt *plot.Plot) {
+	var (
+		trX, _ = plt.Transforms(&c)
+		xmin   = math.Max(float64(trX(vs.Min)), float64(c.Min.X))
+		xmax   = math.Min(float64(trX(vs.Max)), float64(c.Max.X))
+	)
+	if xmin > xmax {
+		return
+	}
+
+	rect := vg.Rectangle{
+		Min: vg.Point{X: vg.Length(xmin), Y: c.Min.Y},
+		Max: vg.Point{X: vg.Length(xmax), Y: c.Max.Y},
+	}
+	fillSpan(c, rect, vs.FillColor)
+
+	if vs.LineStyle.Width != 0 {
+		for _, x := range []float64{vs.Min, vs.Max} {
+			x := trX(x)
+			if c.ContainsX(x) {
+				c.StrokeLine2(vs.LineStyle, x, c.Min.Y, x, c.Max.Y)
+			}
+		}
+	}
+}
+
+// Thumbnail returns the thumbnail for the VertSpan,
+// implementing the plot.Thumbnailer interface.
+func (vs *VertSpan) Thumbnail(c *draw.Canvas) {
+	fillSpan(*c, c.Rectangle, vs.FillColor)
+}
+
+// HorizSpan draws a shaded horizontal span between the Min and Max
+// Y values, over the whole width of the plot.
+type HorizSpan struct {
+	Min, Max  float64
+	FillColor color.Color
+	LineStyle draw.LineStyle // style of the horizontal edges of the span
+}
+
+// HSpan creates a horizontal span between min and max, filled with
+// the provided color.
+// The edges of the span are not drawn by default.
+func HSpan(min, max float64, fill color.Color) *HorizSpan {
+	return &HorizSpan{
+		Min:       min,
+		Max:       max,
+		FillColor: fill,
+	}
+}
+
+// Plot implements the plot.Plotter interface.
+func (hs *HorizSpan) Plot(c draw.Canvas, plt *plot.Plot) {
+	var (
+		_, trY = plt.Transforms(&c)
+		ymin   = math.Max(float64(trY(hs.Min)), float64(c.Min.Y))
+		ymax   = math.Min(float64(trY(hs.Max)), float64(c.Max.Y))
+	)
+	if ymin > ymax {
+		return
+	}
+
+	rect := vg.Rectangle{
+		Min: vg.Point{X: c.Min.X, Y: vg.Length(ymin)},
+		Max: vg.Point{X: c.Max.X, Y: vg.Length(ymax)},
+	}
+	fillSpan(c, rect, hs.FillColor)
+
+	if hs.LineStyle.Width != 0 {
+		for _, y := range []float64{hs.Min, hs.Max} {
+			y := trY(y)
+			if c.ContainsY(y) {
+				c.StrokeLine2(hs.LineStyle, c.Min.X, y, c.Max.X, y)
+			}
+		}
+	}
+}
+
+// Thumbnail returns the thumbnail for the HorizSpan,
+// implementing the plot.Thumbnailer interface.
+func (hs *HorizSpan) Thumbnail(c *draw.Canvas) {
+	fillSpan(*c, c.Rectangle, hs.FillColor)
+}
+
+func fillSpan(c draw.Canvas, rect vg.Rectangle, fill color.Color) {
+	if fill == nil {
+		return
+	}
+	c.SetColor(fill)
+	c.Fill(rect.Path())
+}
+
+// Arrow draws an arrow from (X1, Y1) to (X2, Y2), in data coordinates.
+// The head of the arrow is drawn at (X2, Y2).
+type Arrow struct {
+	X1, Y1 float64
+	X2, Y2 float64
+
+	LineStyle draw.LineStyle
+
+	// HeadLength is the length of the head of the arrow.
+	// No head is drawn if HeadLength is zero.
+	HeadLength vg.Length
+
+	// HeadAngle is the angle, in radians, between the body and each
+	// side of the head of the arrow.
+	HeadAngle float64
+}
+
+// NewArrow creates a new arrow from (x1, y1) to (x2, y2), with the
+// default line style.
+func NewArrow(x1, y1, x2, y2 float64) *Arrow {
+	return &Arrow{
+		X1:         x1,
+		Y1:         y1,
+		X2:         x2,
+		Y2:         y2,
+		LineStyle:  plotter.DefaultLineStyle,
+		HeadLength: vg.Points(8),
+		HeadAngle:  math.Pi / 8,
+	}
+}
+
+// Plot implements the plot.Plotter interface.
+func (a *Arrow) Plot(c draw.Canvas, plt *plot.Plot) {
+	var (
+		trX, trY = plt.Transforms(&c)
+		beg      = vg.Point{X: trX(a.X1), Y: trY(a.Y1)}
+		end      = vg.Point{X: trX(a.X2), Y: trY(a.Y2)}
+	)
+	c.StrokeLine2(a.LineStyle, beg.X, beg.Y, end.X, end.Y)
+
+	if a.HeadLength == 0 || beg == end {
+		return
+	}
+
+	var (
+		dir = math.Atan2(float64(end.Y-beg.Y), float64(end.X-beg.X))
+		pts = []vg.Point{end}
+	)
+	for _, angle := range []float64{dir + math.Pi - a.HeadAngle, dir + math.Pi + a.HeadAngle} {
+		pts = append(pts, vg.Point{
+			X: end.X + a.HeadLength*vg.Length(math.Cos(angle)),
+			Y: end.Y + a.HeadLength*vg.Length(math.Sin(angle)),
+		})
+	}
+	c.FillPolygon(a.LineStyle.Color, pts)
+}
+
+// DataRange returns the minimum and maximum x and
+// y values, implementing the plot.DataRanger interface.
+func (a *Arrow) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return math.Min(a.X1, a.X2), math.Max(a.X1, a.X2),
+		math.Min(a.Y1, a.Y2), math.Max(a.Y1, a.Y2)
+}
+
+// TextBox displays a text inside a box, anchored at a position normalized
+// to the data area of the plot.
+//
+// The alignment of the text style controls which point of the box is
+// anchored: e.g. draw.XLeft and draw.YTop anchor the top-left corner of
+// the box.
+// LaTeX content can be displayed by using a text.Latex handler for the
+// text style of the box.
+type TextBox struct {
+	Text string
+
+	// X and Y are the position of the anchor of the box,
+	// normalized to the data area of the plot.
+	X, Y float64
+
+	TextStyle draw.TextStyle
+
+	// Padding is the space between the text and the border of the box.
+	Padding vg.Length
+
+	FillColor color.Color    // color of the background of the box
+	LineStyle draw.LineStyle // style of the border of the box
+}
+
+// NewTextBox creates a new text box whose top-left corner is anchored at
+// the normalized position (x, y).
+func NewTextBox(x, y float64, txt string) *TextBox {
+	return &TextBox{
+		Text: txt,
+		X:    x,
+		Y:    y,
+		TextStyle: draw.TextStyle{
+			Color:   color.Black,
+			Font:    DefaultStyle.Fonts.Legend,
+			XAlign:  draw.XLeft,
+			YAlign:  draw.YTop,
+			Handler: DefaultStyle.TextHandler,
+		},
+		Padding:   vg.Points(4),
+		FillColor: color.White,
+		LineStyle: plotter.DefaultLineStyle,
+	}
+}
+
+// Plot implements the plot.Plotter interface.
+func (tb *TextBox) Plot(c draw.Canvas, plt *plot.Plot) {
+	var (
+		size = c.Size()
+		pt   = vg.Point{
+			X: c.Min.X + vg.Length(tb.X)*size.X,
+			Y: c.Min.Y + vg.Length(tb.Y)*size.Y,
+		}
+		txt = tb.TextStyle.Rectangle(tb.Text)
+		pad = tb.Padding
+	)
+
+	// shift the text so the padded box, rather than the text,
+	// is anchored at pt.
+	pt.X += pad * vg.Length(2*float64(tb.TextStyle.XAlign)+1)
+	pt.Y += pad * vg.Length(2*float64(tb.TextStyle.YAlign)+1)
+
+	box := vg.Rectangle{
+		Min: vg.Point{X: pt.X + txt.Min.X - pad, Y: pt.Y + txt.Min.Y - pad},
+		Max: vg.Point{X: pt.X + txt.Max.X + pad, Y: pt.Y + txt.Max.Y + pad},
+	}
+	if tb.FillColor != nil {
+		c.SetColor(tb.FillColor)
+		c.Fill(box.Path())
+	}
+	if tb.LineStyle.Width != 0 {
+		c.SetLineStyle(tb.LineStyle)
+		c.Stroke(box.Path())
+	}
+	c.FillText(tb.TextStyle, pt, tb.Text)
+}
+
+var (
+	_ plot.Plotter = (*VertSpan)(nil)
+	_ plot.Plotter = (*HorizSpan)(nil)
+	_ plot.Plotter = (*Arrow)(nil)
+	_ plot.Plotter = (*TextBox)(nil)
+
+	_ plot.Thumbnailer = (*VertSpan)(nil)
+	_ plot.Thumbnailer = (*HorizSpan)(nil)
+
+	_ plot.DataRanger = (*Arrow)(nil)
+)
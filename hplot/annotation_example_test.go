@@ -0,0 +1,68 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"image/color"
+	"log"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// An example of annotating a plot with a shaded signal region,
+// a reference line, an arrow and a text box with LaTeX content.
+func ExampleTextBox() {
+	const npoints = 10000
+
+	src := rand.New(rand.NewSource(0))
+	var (
+		dist = distuv.Normal{Mu: 0, Sigma: 1, Src: src}
+		hist = hbook.NewH1D(40, -4, +4)
+	)
+	for i := 0; i < npoints; i++ {
+		hist.Fill(dist.Rand(), 1)
+	}
+
+	p := hplot.New()
+	p.Title.Text = "Annotations"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Entries"
+
+	sr := hplot.VSpan(-1, +1, color.NRGBA{G: 200, A: 64})
+	sr.LineStyle = draw.LineStyle{
+		Color:  color.NRGBA{G: 128, A: 255},
+		Width:  vg.Points(1),
+		Dashes: []vg.Length{vg.Points(3), vg.Points(2)},
+	}
+
+	ref := hplot.HLine(500, nil, nil)
+	ref.Line.Color = color.NRGBA{R: 255, A: 255}
+	ref.Line.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+
+	arrow := hplot.NewArrow(2.5, 700, 0.4, 950)
+	arrow.LineStyle.Color = color.NRGBA{B: 255, A: 255}
+
+	box := hplot.NewTextBox(0.05, 0.95, "$\\mu = 0$, $\\sigma = 1$")
+	box.TextStyle.Handler = &text.Latex{Fonts: hplot.DefaultStyle.Fonts.Cache}
+
+	lbl := hplot.NewTextBox(0.95, 0.55, "signal region")
+	lbl.TextStyle.XAlign = draw.XRight
+	lbl.TextStyle.YAlign = draw.YCenter
+	lbl.LineStyle.Width = 0.5
+
+	p.Add(sr, hplot.NewH1D(hist), ref, arrow, box, lbl)
+	p.Y.Max = 1100
+
+	err := p.Save(10*vg.Centimeter, -1, "testdata/annotations.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
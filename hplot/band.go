@@ -8,8 +8,10 @@ import (
 	"image/color"
 	"math"
 
+	"go-hep.org/x/hep/hbook"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 )
 
@@ -29,6 +31,9 @@ type Band struct {
 	FillColor color.Color
 }
 
+// NewBand creates a new band filled with the provided color,
+// between the top and bottom lines.
+// Transparent fill colors can be used to overlay several bands.
 func NewBand(fill color.Color, top, bottom plotter.XYer) *Band {
 	band := &Band{
 		top:       make(plotter.XYs, top.Len()),
@@ -49,6 +54,34 @@ func NewBand(fill color.Color, top, bottom plotter.XYer) *Band {
 	return band
 }
 
+// NewH1DBand creates a new band filled with the provided color, following
+// the binning of the top and bottom histograms.
+// NewH1DBand can be used to display the envelope of the up and down
+// variations of a histogram, e.g. for systematic uncertainties.
+func NewH1DBand(fill color.Color, top, bottom *hbook.H1D) *Band {
+	return &Band{
+		top:       h1dSteps(top),
+		bottom:    h1dSteps(bottom),
+		FillColor: fill,
+	}
+}
+
+// h1dSteps returns the points of the staircase line following the
+// contents of the provided histogram.
+func h1dSteps(h *hbook.H1D) plotter.XYs {
+	bins := h.Binning.Bins
+	xys := make(plotter.XYs, 0, 2*len(bins))
+	for _, bin := range bins {
+		y := bin.SumW()
+		xys = append(xys,
+			plotter.XY{X: bin.XMin(), Y: y},
+			plotter.XY{X: bin.XMax(), Y: y},
+		)
+	}
+	return xys
+}
+
+// Plot implements the Plotter interface, drawing the band.
 func (band *Band) Plot(c draw.Canvas, plt *plot.Plot) {
 	switch {
 	case len(band.top) <= 1:
@@ -86,9 +119,29 @@ func (band *Band) DataRange() (xmin, xmax, ymin, ymax float64) {
 	return xmin, xmax, ymin, ymax
 }
 
+// Thumbnail implements the plot.Thumbnailer interface,
+// drawing a rectangle in the style of the band.
+func (band *Band) Thumbnail(c *draw.Canvas) {
+	pts := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+	}
+	if band.FillColor != nil {
+		c.FillPolygon(band.FillColor, c.ClipPolygonXY(pts))
+	}
+	if band.LineStyle.Width != 0 {
+		pts = append(pts, pts[0])
+		c.StrokeLines(band.LineStyle, c.ClipLinesXY(pts)...)
+	}
+}
+
 var (
 	_ plot.Plotter    = (*VertLine)(nil)
 	_ plot.Plotter    = (*HorizLine)(nil)
 	_ plot.Plotter    = (*Band)(nil)
 	_ plot.DataRanger = (*Band)(nil)
+
+	_ plot.Thumbnailer = (*Band)(nil)
 )
@@ -9,6 +9,7 @@ import (
 	"log"
 	"math"
 
+	"go-hep.org/x/hep/hbook"
 	"go-hep.org/x/hep/hplot"
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/stat/distuv"
@@ -85,3 +86,47 @@ func ExampleBand() {
 		log.Fatalf("error: %+v", err)
 	}
 }
+
+// An example of making a systematic uncertainty band from the up and down
+// variations of a histogram.
+func ExampleBand_h1d() {
+	const npoints = 10000
+
+	dist := distuv.Normal{
+		Mu:    0,
+		Sigma: 1,
+		Src:   rand.New(rand.NewSource(0)),
+	}
+
+	var (
+		nom  = hbook.NewH1D(20, -4, 4)
+		up   = hbook.NewH1D(20, -4, 4)
+		down = hbook.NewH1D(20, -4, 4)
+	)
+	for i := 0; i < npoints; i++ {
+		v := dist.Rand()
+		nom.Fill(v, 1)
+		up.Fill(v, 1.1+0.05*v)
+		down.Fill(v, 0.9-0.05*v)
+	}
+
+	hnom := hplot.NewH1D(nom)
+	hnom.LineStyle.Color = color.Black
+
+	band := hplot.NewH1DBand(color.NRGBA{R: 255, G: 128, A: 128}, up, down)
+	band.LineStyle = plotter.DefaultLineStyle
+	band.LineStyle.Color = color.NRGBA{R: 255, G: 128, A: 255}
+
+	p := hplot.New()
+	p.Title.Text = "Systematic band"
+	p.X.Label.Text = "x"
+	p.Add(band, hnom)
+	p.Legend.Add("nominal", hnom)
+	p.Legend.Add("syst.", band)
+	p.Legend.Top = true
+
+	err := p.Save(10*vg.Centimeter, -1, "testdata/band_h1d.png")
+	if err != nil {
+		log.Fatalf("error: %+v", err)
+	}
+}
@@ -13,3 +13,7 @@ import (
 func TestBand(t *testing.T) {
 	checkPlot(cmpimg.CheckPlot)(ExampleBand, t, "band.png")
 }
+
+func TestBandH1D(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleBand_h1d, t, "band_h1d.png")
+}
@@ -0,0 +1,264 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// BoxPlot implements the plot.Plotter interface, drawing a box plot
+// of a distribution at a given location along the X axis.
+//
+// The box extends from the first to the third quartile of the
+// distribution and is crossed by the median.
+// The whiskers extend to the furthest values which are within 1.5 times
+// the interquartile range from the box.
+// The values beyond the whiskers are drawn as outliers.
+type BoxPlot struct {
+	// Location is the position of the box along the X axis.
+	Location float64
+
+	// Width is the width of the box.
+	Width vg.Length
+
+	Median    float64
+	Quartile1 float64
+	Quartile3 float64
+
+	// AdjLow and AdjHigh are the ends of the whiskers.
+	AdjLow  float64
+	AdjHigh float64
+
+	// Outliers are the values beyond the whiskers.
+	Outliers []float64
+
+	// FillColor is the color of the inside of the box.
+	// The box is not filled if FillColor is nil.
+	FillColor color.Color
+
+	// BoxStyle is the style of the box and of the median line.
+	BoxStyle draw.LineStyle
+
+	// WhiskerStyle is the style of the whiskers.
+	WhiskerStyle draw.LineStyle
+
+	// CapWidth is the width of the caps of the whiskers.
+	CapWidth vg.Length
+
+	// GlyphStyle is the style of the outliers.
+	GlyphStyle draw.GlyphStyle
+}
+
+// NewBoxPlot returns a box plot of the samples xs, at the location loc.
+func NewBoxPlot(loc float64, xs []float64) *BoxPlot {
+	return newBoxPlot(loc, newQuantiles(xs, nil))
+}
+
+// NewH1DBoxPlot returns a box plot of the distribution of the histogram h,
+// at the location loc.
+// The content of each bin is attributed to the center of the bin.
+func NewH1DBoxPlot(loc float64, h *hbook.H1D) *BoxPlot {
+	xs, ws := h1dSamples(h)
+	return newBoxPlot(loc, newQuantiles(xs, ws))
+}
+
+func newBoxPlot(loc float64, q quantiles) *BoxPlot {
+	box := &BoxPlot{
+		Location:     loc,
+		Width:        vg.Points(20),
+		FillColor:    color.White,
+		BoxStyle:     plotter.DefaultLineStyle,
+		WhiskerStyle: plotter.DefaultLineStyle,
+		CapWidth:     vg.Points(10),
+		GlyphStyle:   plotter.DefaultGlyphStyle,
+	}
+	if len(q.xs) == 0 {
+		return box
+	}
+
+	box.Median = q.at(0.5)
+	box.Quartile1 = q.at(0.25)
+	box.Quartile3 = q.at(0.75)
+
+	var (
+		iqr = box.Quartile3 - box.Quartile1
+		lo  = box.Quartile1 - 1.5*iqr
+		hi  = box.Quartile3 + 1.5*iqr
+	)
+	box.AdjLow = box.Quartile1
+	box.AdjHigh = box.Quartile3
+	for _, x := range q.xs {
+		switch {
+		case x < lo || x > hi:
+			box.Outliers = append(box.Outliers, x)
+		case x < box.AdjLow:
+			box.AdjLow = x
+		case x > box.AdjHigh:
+			box.AdjHigh = x
+		}
+	}
+	return box
+}
+
+// Plot implements the plot.Plotter interface.
+func (box *BoxPlot) Plot(c draw.Canvas, plt *plot.Plot) {
+	var (
+		trX, trY = plt.Transforms(&c)
+		x        = trX(box.Location)
+	)
+	if !c.ContainsX(x) {
+		return
+	}
+
+	var (
+		x0   = x - box.Width/2
+		x1   = x + box.Width/2
+		q1   = trY(box.Quartile1)
+		q3   = trY(box.Quartile3)
+		med  = trY(box.Median)
+		rect = []vg.Point{{X: x0, Y: q1}, {X: x0, Y: q3}, {X: x1, Y: q3}, {X: x1, Y: q1}}
+	)
+
+	if box.FillColor != nil {
+		c.FillPolygon(box.FillColor, c.ClipPolygonY(rect))
+	}
+	c.StrokeLines(box.BoxStyle, c.ClipLinesY(append(rect, rect[0]))...)
+	c.StrokeLines(box.BoxStyle, c.ClipLinesY([]vg.Point{{X: x0, Y: med}, {X: x1, Y: med}})...)
+
+	var (
+		lo = trY(box.AdjLow)
+		hi = trY(box.AdjHigh)
+		cw = box.CapWidth / 2
+	)
+	c.StrokeLines(box.WhiskerStyle, c.ClipLinesY(
+		[]vg.Point{{X: x, Y: q3}, {X: x, Y: hi}},
+		[]vg.Point{{X: x - cw, Y: hi}, {X: x + cw, Y: hi}},
+		[]vg.Point{{X: x, Y: q1}, {X: x, Y: lo}},
+		[]vg.Point{{X: x - cw, Y: lo}, {X: x + cw, Y: lo}},
+	)...)
+
+	for _, v := range box.Outliers {
+		y := trY(v)
+		if c.ContainsY(y) {
+			c.DrawGlyphNoClip(box.GlyphStyle, vg.Point{X: x, Y: y})
+		}
+	}
+}
+
+// DataRange returns the minimum and maximum x and
+// y values, implementing the plot.DataRanger interface.
+func (box *BoxPlot) DataRange() (xmin, xmax, ymin, ymax float64) {
+	ymin = math.Min(box.AdjLow, box.Quartile1)
+	ymax = math.Max(box.AdjHigh, box.Quartile3)
+	for _, v := range box.Outliers {
+		ymin = math.Min(ymin, v)
+		ymax = math.Max(ymax, v)
+	}
+	return box.Location, box.Location, ymin, ymax
+}
+
+// GlyphBoxes returns a slice of GlyphBoxes, one for the box and one for
+// each outlier, implementing the plot.GlyphBoxer interface.
+func (box *BoxPlot) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	bs := make([]plot.GlyphBox, 0, 1+len(box.Outliers))
+	bs = append(bs, plot.GlyphBox{
+		X: plt.X.Norm(box.Location),
+		Y: plt.Y.Norm(box.Median),
+		Rectangle: vg.Rectangle{
+			Min: vg.Point{X: -box.Width / 2},
+			Max: vg.Point{X: +box.Width / 2},
+		},
+	})
+	for _, v := range box.Outliers {
+		bs = append(bs, plot.GlyphBox{
+			X:         plt.X.Norm(box.Location),
+			Y:         plt.Y.Norm(v),
+			Rectangle: box.GlyphStyle.Rectangle(),
+		})
+	}
+	return bs
+}
+
+// Thumbnail returns the thumbnail for the BoxPlot,
+// implementing the plot.Thumbnailer interface.
+func (box *BoxPlot) Thumbnail(c *draw.Canvas) {
+	rect := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+	}
+	if box.FillColor != nil {
+		c.FillPolygon(box.FillColor, rect)
+	}
+	c.StrokeLines(box.BoxStyle, append(rect, rect[0]))
+}
+
+// quantiles computes the quantiles of a weighted sample.
+type quantiles struct {
+	xs []float64 // sorted values
+	ws []float64 // weights of the values, nil for unweighted samples.
+}
+
+func newQuantiles(xs, ws []float64) quantiles {
+	q := quantiles{
+		xs: make([]float64, len(xs)),
+	}
+	copy(q.xs, xs)
+	if ws == nil {
+		sort.Float64s(q.xs)
+		return q
+	}
+
+	q.ws = make([]float64, len(ws))
+	copy(q.ws, ws)
+	sort.Sort(weightedSamples(q))
+	return q
+}
+
+func (q quantiles) at(p float64) float64 {
+	return stat.Quantile(p, stat.Empirical, q.xs, q.ws)
+}
+
+// weightedSamples sorts weighted values by increasing values.
+type weightedSamples quantiles
+
+func (ws weightedSamples) Len() int           { return len(ws.xs) }
+func (ws weightedSamples) Less(i, j int) bool { return ws.xs[i] < ws.xs[j] }
+func (ws weightedSamples) Swap(i, j int) {
+	ws.xs[i], ws.xs[j] = ws.xs[j], ws.xs[i]
+	ws.ws[i], ws.ws[j] = ws.ws[j], ws.ws[i]
+}
+
+// h1dSamples returns the centers and the contents of the non-empty bins
+// of the histogram h.
+func h1dSamples(h *hbook.H1D) (xs, ws []float64) {
+	bins := h.Binning.Bins
+	xs = make([]float64, 0, len(bins))
+	ws = make([]float64, 0, len(bins))
+	for _, bin := range bins {
+		if w := bin.SumW(); w > 0 {
+			xs = append(xs, bin.XMid())
+			ws = append(ws, w)
+		}
+	}
+	return xs, ws
+}
+
+var (
+	_ plot.Plotter     = (*BoxPlot)(nil)
+	_ plot.DataRanger  = (*BoxPlot)(nil)
+	_ plot.GlyphBoxer  = (*BoxPlot)(nil)
+	_ plot.Thumbnailer = (*BoxPlot)(nil)
+)
@@ -0,0 +1,98 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"image/color"
+	"log"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/vg"
+)
+
+// newCategories returns samples of 2 different distributions,
+// and a histogram of a third one.
+func newCategories() (norm, expo []float64, hist *hbook.H1D) {
+	const npoints = 500
+
+	src := rand.New(rand.NewSource(0))
+	var (
+		dnorm = distuv.Normal{Mu: 5, Sigma: 1, Src: src}
+		dexpo = distuv.Exponential{Rate: 0.5, Src: src}
+		dunif = distuv.Uniform{Min: 2, Max: 8, Src: src}
+	)
+	hist = hbook.NewH1D(20, 0, 10)
+	for i := 0; i < npoints; i++ {
+		norm = append(norm, dnorm.Rand())
+		expo = append(expo, dexpo.Rand())
+		hist.Fill(dunif.Rand(), 1)
+	}
+	return norm, expo, hist
+}
+
+// An example of comparing distributions across categories with box plots,
+// built from samples or from a histogram.
+func ExampleBoxPlot() {
+	norm, expo, hist := newCategories()
+
+	p := hplot.New()
+	p.Title.Text = "Box plots"
+	p.Y.Label.Text = "Values"
+
+	fill := color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+	for _, box := range []*hplot.BoxPlot{
+		hplot.NewBoxPlot(0, norm),
+		hplot.NewBoxPlot(1, expo),
+		hplot.NewH1DBoxPlot(2, hist),
+	} {
+		box.FillColor = fill
+		box.Width = vg.Points(30)
+		p.Add(box)
+	}
+	p.NominalX("normal", "exponential", "uniform (H1D)")
+
+	err := p.Save(10*vg.Centimeter, -1, "testdata/boxplot.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
+
+// An example of comparing distributions across categories with violin
+// plots, built from samples or from a histogram.
+func ExampleViolin() {
+	norm, expo, hist := newCategories()
+
+	p := hplot.New()
+	p.Title.Text = "Violin plots"
+	p.Y.Label.Text = "Values"
+
+	for _, v := range []struct {
+		violin *hplot.Violin
+		box    *hplot.BoxPlot
+	}{
+		{hplot.NewViolin(0, norm), hplot.NewBoxPlot(0, norm)},
+		{hplot.NewViolin(1, expo), hplot.NewBoxPlot(1, expo)},
+		{hplot.NewH1DViolin(2, hist), hplot.NewH1DBoxPlot(2, hist)},
+	} {
+		// display the quartiles inside the violin.
+		v.box.Width = vg.Points(6)
+		v.box.CapWidth = 0
+		v.box.FillColor = color.Black
+		v.box.Outliers = nil
+
+		v.violin.Width = vg.Points(60)
+		v.violin.Box = v.box
+		p.Add(v.violin)
+	}
+	p.NominalX("normal", "exponential", "uniform (H1D)")
+
+	err := p.Save(10*vg.Centimeter, -1, "testdata/violin.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
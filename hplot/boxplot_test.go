@@ -0,0 +1,59 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestBoxPlot(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleBoxPlot, t, "boxplot.png")
+}
+
+func TestViolin(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleViolin, t, "violin.png")
+}
+
+func TestBoxPlotQuartiles(t *testing.T) {
+	xs := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 30}
+	h := hbook.NewH1D(30, 0.5, 30.5)
+	for _, x := range xs {
+		h.Fill(x, 1)
+	}
+
+	for _, tc := range []struct {
+		name string
+		box  *hplot.BoxPlot
+	}{
+		{"samples", hplot.NewBoxPlot(0, xs)},
+		{"h1d", hplot.NewH1DBoxPlot(0, h)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			box := tc.box
+			for _, v := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"q1", box.Quartile1, 3},
+				{"median", box.Median, 6},
+				{"q3", box.Quartile3, 9},
+				{"adj-low", box.AdjLow, 1},
+				{"adj-high", box.AdjHigh, 10},
+			} {
+				if math.Abs(v.got-v.want) > 1e-12 {
+					t.Fatalf("invalid %s: got=%v, want=%v", v.name, v.got, v.want)
+				}
+			}
+			if got, want := box.Outliers, []float64{30}; len(got) != 1 || got[0] != want[0] {
+				t.Fatalf("invalid outliers: got=%v, want=%v", got, want)
+			}
+		})
+	}
+}
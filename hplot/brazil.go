@@ -0,0 +1,144 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+var (
+	// DefaultBrazilGreen is the default color of the ±1σ band of the expected limits.
+	DefaultBrazilGreen = color.NRGBA{G: 205, A: 255}
+
+	// DefaultBrazilYellow is the default color of the ±2σ band of the expected limits.
+	DefaultBrazilYellow = color.NRGBA{R: 255, G: 230, A: 255}
+)
+
+// BrazilPlot implements the plot.Plotter interface,
+// drawing the "Brazil plot" of a set of limits: the median expected
+// limits with their ±1σ (green) and ±2σ (yellow) bands, and the
+// observed limits.
+type BrazilPlot struct {
+	// Expected is the line of the median expected limits.
+	Expected *plotter.Line
+
+	// Observed is the line of the observed limits.
+	// Observed may be nil, e.g. for a blinded analysis.
+	Observed *plotter.Line
+
+	// Green is the ±1σ band of the expected limits.
+	Green *Band
+
+	// Yellow is the ±2σ band of the expected limits.
+	Yellow *Band
+}
+
+// NewBrazilPlot returns a new Brazil plot from the median expected limits,
+// the observed limits, and the lower and upper edges of the ±1σ and ±2σ
+// bands of the expected limits.
+// obs may be nil.
+func NewBrazilPlot(exp, obs, lo1, up1, lo2, up2 plotter.XYer) (*BrazilPlot, error) {
+	var (
+		bp  BrazilPlot
+		err error
+	)
+
+	bp.Expected, err = plotter.NewLine(exp)
+	if err != nil {
+		return nil, err
+	}
+	bp.Expected.LineStyle = draw.LineStyle{
+		Color:  color.Black,
+		Width:  vg.Points(1),
+		Dashes: []vg.Length{vg.Points(4), vg.Points(2)},
+	}
+
+	if obs != nil {
+		bp.Observed, err = plotter.NewLine(obs)
+		if err != nil {
+			return nil, err
+		}
+		bp.Observed.LineStyle = draw.LineStyle{
+			Color: color.Black,
+			Width: vg.Points(1.5),
+		}
+	}
+
+	bp.Green = NewBand(DefaultBrazilGreen, up1, lo1)
+	bp.Yellow = NewBand(DefaultBrazilYellow, up2, lo2)
+
+	return &bp, nil
+}
+
+// Plot implements the Plotter interface, drawing the ±2σ and ±1σ bands
+// and then the expected and observed limits.
+func (bp *BrazilPlot) Plot(c draw.Canvas, plt *plot.Plot) {
+	for _, p := range bp.plotters() {
+		p.Plot(c, plt)
+	}
+}
+
+// DataRange returns the minimum and maximum
+// x and y values, implementing the plot.DataRanger interface.
+func (bp *BrazilPlot) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin = math.Inf(+1)
+	xmax = math.Inf(-1)
+	ymin = math.Inf(+1)
+	ymax = math.Inf(-1)
+	for _, p := range bp.plotters() {
+		x1, x2, y1, y2 := p.(plot.DataRanger).DataRange()
+		xmin = math.Min(xmin, x1)
+		xmax = math.Max(xmax, x2)
+		ymin = math.Min(ymin, y1)
+		ymax = math.Max(ymax, y2)
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// AddLegend adds the standard legend entries of the Brazil plot.
+func (bp *BrazilPlot) AddLegend(leg *plot.Legend) {
+	if bp.Observed != nil {
+		leg.Add("Observed", bp.Observed)
+	}
+	if bp.Expected != nil {
+		leg.Add("Expected", bp.Expected)
+	}
+	if bp.Green != nil {
+		leg.Add("Expected ± 1σ", bp.Green)
+	}
+	if bp.Yellow != nil {
+		leg.Add("Expected ± 2σ", bp.Yellow)
+	}
+}
+
+// plotters returns the non-nil plotters of the Brazil plot,
+// in drawing order.
+func (bp *BrazilPlot) plotters() []plot.Plotter {
+	ps := make([]plot.Plotter, 0, 4)
+	if bp.Yellow != nil {
+		ps = append(ps, bp.Yellow)
+	}
+	if bp.Green != nil {
+		ps = append(ps, bp.Green)
+	}
+	if bp.Expected != nil {
+		ps = append(ps, bp.Expected)
+	}
+	if bp.Observed != nil {
+		ps = append(ps, bp.Observed)
+	}
+	return ps
+}
+
+var (
+	_ plot.Plotter    = (*BrazilPlot)(nil)
+	_ plot.DataRanger = (*BrazilPlot)(nil)
+)
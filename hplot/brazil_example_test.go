@@ -0,0 +1,56 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"log"
+	"math"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of making a Brazil plot of upper limits.
+func ExampleBrazilPlot() {
+	const n = 10
+	var (
+		exp = make(plotter.XYs, n)
+		obs = make(plotter.XYs, n)
+		lo1 = make(plotter.XYs, n)
+		up1 = make(plotter.XYs, n)
+		lo2 = make(plotter.XYs, n)
+		up2 = make(plotter.XYs, n)
+	)
+	for i := 0; i < n; i++ {
+		x := 100 + 50*float64(i)
+		y := 0.5 + 3*math.Exp(-float64(i)/3)
+		exp[i] = plotter.XY{X: x, Y: y}
+		obs[i] = plotter.XY{X: x, Y: y * (1 + 0.3*math.Sin(float64(i)))}
+		lo1[i] = plotter.XY{X: x, Y: 0.7 * y}
+		up1[i] = plotter.XY{X: x, Y: 1.4 * y}
+		lo2[i] = plotter.XY{X: x, Y: 0.5 * y}
+		up2[i] = plotter.XY{X: x, Y: 1.9 * y}
+	}
+
+	bp, err := hplot.NewBrazilPlot(exp, obs, lo1, up1, lo2, up2)
+	if err != nil {
+		log.Fatalf("could not create Brazil plot: %+v", err)
+	}
+
+	p := hplot.New()
+	p.Title.Text = "Upper limits"
+	p.X.Label.Text = "m [GeV]"
+	p.Y.Label.Text = "95% CL limit on σ/σ(SM)"
+	p.Add(bp)
+	p.Add(hplot.HLine(1, nil, nil))
+	bp.AddLegend(&p.Legend)
+	p.Legend.Top = true
+
+	err = p.Save(15*vg.Centimeter, -1, "testdata/brazil.png")
+	if err != nil {
+		log.Fatalf("error: %+v", err)
+	}
+}
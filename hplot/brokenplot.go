@@ -0,0 +1,243 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// BrokenYPlot is a plot with a broken (cut) Y axis.
+//
+// The Y axis is split into a set of disjoint ranges, each of them being
+// displayed in its own panel, and zig-zag markers are drawn on the Y axis
+// where it is cut.
+// BrokenYPlot is useful to display spectra with vastly different
+// populated regions (e.g. 0-10 and 100-1000.)
+type BrokenYPlot struct {
+	// Plot holds the plotters, the title, the axes and the legend
+	// of the broken plot.
+	//
+	// The title and the legend are displayed in the top panel,
+	// the X axis is displayed in the bottom panel.
+	// If the style of the plot requests a frame, each panel is framed.
+	Plot *Plot
+
+	// Ranges are the displayed ranges of the Y axis, from bottom to top.
+	Ranges []hbook.Range
+
+	// Ratios controls how the vertical space is partitioned between
+	// the panels, from bottom to top.
+	// The data area of the i-th panel will take Ratios[i]/sum(Ratios)
+	// of the total height of the data areas.
+	// If Ratios is nil, all panels have the same height.
+	Ratios []float64
+
+	// Gap is the vertical space between two panels.
+	// Default is 8 points.
+	Gap vg.Length
+
+	// Break controls the zig-zag markers drawn in the gap between
+	// two panels.
+	Break struct {
+		draw.LineStyle
+
+		// Width is the half-width of the zig-zag markers.
+		Width vg.Length
+	}
+}
+
+// NewBrokenYPlot creates a new plot whose Y axis displays the provided
+// ranges, from bottom to top.
+func NewBrokenYPlot(ranges ...hbook.Range) *BrokenYPlot {
+	bp := &BrokenYPlot{
+		Plot:   New(),
+		Ranges: ranges,
+		Gap:    vg.Points(8),
+	}
+	bp.Break.LineStyle = bp.Plot.Y.LineStyle
+	bp.Break.Width = vg.Points(3)
+	return bp
+}
+
+// Add adds plotters to the plot.
+func (bp *BrokenYPlot) Add(ps ...plot.Plotter) {
+	bp.Plot.Add(ps...)
+}
+
+// Draw draws the broken plot to a draw.Canvas.
+//
+// Plotters are drawn in each panel, in the order in which they were
+// added to the plot.
+func (bp *BrokenYPlot) Draw(c draw.Canvas) {
+	n := len(bp.Ranges)
+	if n == 0 {
+		bp.Plot.Draw(c)
+		return
+	}
+
+	var (
+		ps     = bp.panels()
+		pps    = make([][]*plot.Plot, n)
+		tiles  = draw.Tiles{Rows: n, Cols: 1, PadY: bp.Gap}
+		ratios = bp.Ratios
+	)
+	for i, p := range ps {
+		// panels are laid out from top to bottom.
+		pps[n-1-i] = []*plot.Plot{p.Plot}
+	}
+	if len(ratios) != n {
+		ratios = make([]float64, n)
+		for i := range ratios {
+			ratios[i] = 1
+		}
+	}
+
+	var (
+		tcs  = plot.Align(pps, tiles, c)
+		cs   = make([]draw.Canvas, n)
+		dcs  = make([]draw.Canvas, n)
+		ymin = tcs[n-1][0].Min.Y
+		ymax = tcs[0][0].Max.Y
+		sum  float64
+		data = ymax - ymin - vg.Length(n-1)*bp.Gap
+	)
+	for i, p := range ps {
+		cs[i] = tcs[n-1-i][0]
+		dc := p.Plot.DataCanvas(cs[i])
+		data -= (dc.Min.Y - cs[i].Min.Y) + (cs[i].Max.Y - dc.Max.Y)
+		sum += ratios[i]
+	}
+
+	y := ymin
+	for i, p := range ps {
+		var (
+			dc    = p.Plot.DataCanvas(cs[i])
+			below = dc.Min.Y - cs[i].Min.Y
+			above = cs[i].Max.Y - dc.Max.Y
+		)
+		cs[i].Min.Y = y
+		cs[i].Max.Y = y + below + data*vg.Length(ratios[i]/sum) + above
+		y = cs[i].Max.Y + bp.Gap
+
+		p.Draw(cs[i])
+		dcs[i] = p.Plot.DataCanvas(cs[i])
+	}
+
+	bp.drawYLabel(cs[0], dcs[0].Min.Y, dcs[n-1].Max.Y)
+	for i := 0; i < n-1; i++ {
+		bp.drawBreak(dcs[i], dcs[i+1])
+	}
+}
+
+// panels returns the plots of the panels of the broken plot, from
+// bottom to top.
+func (bp *BrokenYPlot) panels() []*Plot {
+	var (
+		n  = len(bp.Ranges)
+		ps = make([]*Plot, n)
+	)
+	for i, rng := range bp.Ranges {
+		p := *bp.Plot.Plot
+		p.Y.Min = rng.Min
+		p.Y.Max = rng.Max
+
+		// the label of the Y axis is drawn once for all the panels,
+		// it is only kept transparent to reserve its space.
+		p.Y.Label.TextStyle.Color = color.Transparent
+
+		if i > 0 {
+			p.X.Label.Text = ""
+			p.X.Tick.Marker = NoTicks{}
+			if !bp.Plot.Style.Frame {
+				p.X.LineStyle.Width = 0
+			}
+		}
+		if i < n-1 {
+			p.Title.Text = ""
+			p.Legend = plot.NewLegend()
+		}
+		ps[i] = &Plot{
+			Plot:  &p,
+			Style: bp.Plot.Style,
+			Grid:  bp.Plot.Grid,
+		}
+	}
+	return ps
+}
+
+// drawYLabel draws the label of the Y axis, vertically centered on
+// the [ymin, ymax] range.
+func (bp *BrokenYPlot) drawYLabel(c draw.Canvas, ymin, ymax vg.Length) {
+	ax := bp.Plot.Y
+	if ax.Label.Text == "" {
+		return
+	}
+	var (
+		sty = ax.Label.TextStyle
+		x   = c.Min.X + sty.Height(ax.Label.Text)
+		y   = 0.5 * (ymin + ymax)
+	)
+	if ax.Label.Position == draw.PosTop {
+		y = ymax - sty.Width(ax.Label.Text)/2
+	}
+	sty.Rotation += math.Pi / 2
+	descent := sty.FontExtents().Descent
+	c.FillText(sty, vg.Point{X: x - descent, Y: y}, ax.Label.Text)
+}
+
+// drawBreak draws the zig-zag markers of the Y axis between the data
+// areas of two consecutive panels.
+func (bp *BrokenYPlot) drawBreak(lo, hi draw.Canvas) {
+	sty := bp.Break.LineStyle
+	if sty.Width <= 0 {
+		return
+	}
+	xs := []vg.Length{lo.Min.X}
+	if bp.Plot.Style.Frame {
+		xs = append(xs, lo.Max.X)
+	}
+
+	const nzigs = 4
+	var (
+		w  = bp.Break.Width
+		y0 = lo.Max.Y
+		dy = (hi.Min.Y - lo.Max.Y) / nzigs
+	)
+	for _, x := range xs {
+		pts := make([]vg.Point, 0, nzigs+1)
+		pts = append(pts, vg.Point{X: x, Y: y0})
+		for i := 1; i < nzigs; i++ {
+			dx := w
+			if i%2 == 0 {
+				dx = -w
+			}
+			pts = append(pts, vg.Point{X: x + dx, Y: y0 + vg.Length(i)*dy})
+		}
+		pts = append(pts, vg.Point{X: x, Y: hi.Min.Y})
+		lo.StrokeLines(sty, pts)
+	}
+}
+
+// Save saves the plot to an image file.
+// The file format is determined by the extension.
+//
+// Supported extensions are the same ones than hplot.Plot.Save.
+//
+// If w or h are <= 0, the value is chosen such that it follows the Golden Ratio.
+// If w and h are <= 0, the values are chosen such that they follow the Golden Ratio
+// (the width is defaulted to vgimg.DefaultWidth).
+func (bp *BrokenYPlot) Save(w, h vg.Length, file string) error {
+	return Save(bp, w, h, file)
+}
+
+var (
+	_ Drawer = (*BrokenYPlot)(nil)
+)
@@ -0,0 +1,46 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"log"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of making a plot with a broken Y axis, to display both
+// a narrow peak and the tail of a spectrum.
+func ExampleBrokenYPlot() {
+	src := rand.New(rand.NewSource(0))
+	var (
+		peak = distuv.Normal{Mu: 5, Sigma: 0.2, Src: src}
+		tail = distuv.Uniform{Min: 0, Max: 10, Src: src}
+		hist = hbook.NewH1D(50, 0, 10)
+	)
+	for i := 0; i < 2000; i++ {
+		hist.Fill(peak.Rand(), 1)
+	}
+	for i := 0; i < 300; i++ {
+		hist.Fill(tail.Rand(), 1)
+	}
+
+	bp := hplot.NewBrokenYPlot(
+		hbook.Range{Min: 0, Max: 15},
+		hbook.Range{Min: 100, Max: 1000},
+	)
+	bp.Plot.Title.Text = "Broken Y axis"
+	bp.Plot.X.Label.Text = "X"
+	bp.Plot.Y.Label.Text = "Entries"
+
+	bp.Add(hplot.NewH1D(hist))
+
+	if err := bp.Save(10*vg.Centimeter, -1, "testdata/brokenplot.png"); err != nil {
+		log.Fatalf("error saving plot: %+v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// ColorBar implements the plotter.Plotter interface,
+// drawing the colors of a palette along the X axis of a plot,
+// over the [Min, Max] range.
+type ColorBar struct {
+	// Palette is the color palette displayed by the color bar.
+	Palette palette.Palette
+
+	// Min and Max define the range of the color bar.
+	Min, Max float64
+
+	// LogZ indicates whether the colors are logarithmically
+	// distributed over the [Min, Max] range.
+	LogZ bool
+}
+
+// Plot implements the Plotter interface, drawing the color bar.
+func (cb *ColorBar) Plot(c draw.Canvas, p *plot.Plot) {
+	pal := cb.Palette.Colors()
+	if len(pal) == 0 {
+		panic("hplot: empty palette")
+	}
+
+	var (
+		trX, _ = p.Transforms(&c)
+		n      = len(pal)
+		vmin   = cb.Min
+		vmax   = cb.Max
+		value  = func(v float64) float64 { return v }
+	)
+	if cb.LogZ {
+		vmin = math.Log10(vmin)
+		vmax = math.Log10(vmax)
+		value = func(v float64) float64 { return math.Pow(10, v) }
+	}
+
+	var pa vg.Path
+	for i, col := range pal {
+		var (
+			x0 = trX(value(vmin + float64(i)/float64(n)*(vmax-vmin)))
+			x1 = trX(value(vmin + float64(i+1)/float64(n)*(vmax-vmin)))
+		)
+		pa = pa[:0]
+		pa.Move(vg.Point{X: x0, Y: c.Min.Y})
+		pa.Line(vg.Point{X: x1, Y: c.Min.Y})
+		pa.Line(vg.Point{X: x1, Y: c.Max.Y})
+		pa.Line(vg.Point{X: x0, Y: c.Max.Y})
+		pa.Close()
+		c.SetColor(col)
+		c.Fill(pa)
+	}
+}
+
+// DataRange implements the DataRange method
+// of the plot.DataRanger interface.
+func (cb *ColorBar) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return cb.Min, cb.Max, 0, 1
+}
+
+// ColorBarPlot draws a plot, together with a horizontal color bar
+// located below it.
+// The X axes of the data areas of both plots are aligned.
+type ColorBarPlot struct {
+	// Plot is the main plot.
+	Plot *Plot
+
+	// Bar is the plot holding the color bar.
+	// The label of its X axis is the label of the color scale.
+	Bar *Plot
+
+	// Tiles controls the layout of the 2x1 plots grid.
+	// Tiles can be used to customize the padding between plots.
+	Tiles draw.Tiles
+
+	// Ratio controls how the vertical space is partioned between
+	// the main plot and the color bar.
+	// The color bar will take ratio*height.
+	// Default is 0.15.
+	Ratio float64
+}
+
+// NewColorBarPlot returns a new plot displaying the provided 2-dim
+// density plotter (a *H2D or a *HexBin), together with its color bar.
+func NewColorBarPlot(h interface {
+	plot.Plotter
+	ColorBar() *ColorBar
+}) *ColorBarPlot {
+	cp := &ColorBarPlot{
+		Plot:  New(),
+		Bar:   New(),
+		Ratio: 0.15,
+		Tiles: draw.Tiles{Rows: 2, Cols: 1},
+	}
+
+	const pad = 1
+	for _, v := range []*vg.Length{
+		&cp.Tiles.PadTop, &cp.Tiles.PadBottom,
+		&cp.Tiles.PadRight, &cp.Tiles.PadLeft,
+		&cp.Tiles.PadX, &cp.Tiles.PadY,
+	} {
+		if *v == 0 {
+			*v = pad
+		}
+	}
+
+	cb := h.ColorBar()
+	cp.Plot.Add(h)
+	cp.Bar.Add(cb)
+	cp.Bar.HideY()
+	cp.Bar.X.Padding = 0
+	if cb.LogZ {
+		cp.Bar.X.Scale = plot.LogScale{}
+		cp.Bar.X.Tick.Marker = plot.LogTicks{}
+	}
+	return cp
+}
+
+// Draw draws the plot and its color bar to a draw.Canvas.
+func (cp *ColorBarPlot) Draw(dc draw.Canvas) {
+	var (
+		ratio = vg.Length(cp.Ratio)
+		h     = dc.Size().Y
+		ps    = [][]*plot.Plot{
+			{cp.Plot.Plot},
+			{cp.Bar.Plot},
+		}
+		cs = plot.Align(ps, cp.Tiles, dc)
+	)
+
+	top := cs[0][0]
+	bot := cs[1][0]
+
+	top.Rectangle.Min.Y = ratio * h
+	top.Rectangle.Max.Y = h
+	bot.Rectangle.Max.Y = ratio * h
+
+	cp.Plot.Draw(top)
+	cp.Bar.Draw(bot)
+}
+
+var (
+	_ plot.Plotter    = (*ColorBar)(nil)
+	_ plot.DataRanger = (*ColorBar)(nil)
+	_ Drawer          = (*ColorBarPlot)(nil)
+)
@@ -0,0 +1,268 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Contour implements the plotter.Plotter interface,
+// drawing filled and line contours of the values of a grid.
+//
+// Contour can be used to display the confidence level regions of a
+// likelihood scan, e.g. the 68% and 95% CL regions of a 2-dim
+// -2ln(L) scan are delimited by the 2.30 and 5.99 levels.
+type Contour struct {
+	// GridXYZ is the grid of values to display.
+	GridXYZ plotter.GridXYZ
+
+	// Levels describes the contour heights to plot,
+	// in increasing order.
+	Levels []float64
+
+	// LineStyles is the set of styles for contour lines.
+	// Line styles are applied to each level in order,
+	// modulo the length of LineStyles.
+	// No contour line is drawn if LineStyles is empty.
+	LineStyles []draw.LineStyle
+
+	// FillColors is the set of colors used to fill the regions
+	// between the contour levels.
+	// FillColors[i] fills the region whose values are in the
+	// (Levels[i-1], Levels[i]] range, with Levels[-1] = -Inf and
+	// Levels[len(Levels)] = +Inf.
+	// Regions with a nil color (or without a color) are not filled.
+	FillColors []color.Color
+}
+
+// NewContour returns a new contour plotter for the provided grid,
+// drawing line contours at the provided levels.
+func NewContour(g plotter.GridXYZ, levels []float64) *Contour {
+	lvls := make([]float64, len(levels))
+	copy(lvls, levels)
+	sort.Float64s(lvls)
+	return &Contour{
+		GridXYZ:    g,
+		Levels:     lvls,
+		LineStyles: []draw.LineStyle{plotter.DefaultLineStyle},
+	}
+}
+
+// NewH2DContour returns a new contour plotter for the provided
+// 2-dim histogram, drawing line contours at the provided levels.
+func NewH2DContour(h *hbook.H2D, levels []float64) *Contour {
+	return NewContour(h.GridXYZ(), levels)
+}
+
+// NewFuncContour returns a new contour plotter for the provided
+// function, sampled on a grid of nx columns over [xmin, xmax] and
+// ny rows over [ymin, ymax], drawing line contours at the provided levels.
+func NewFuncContour(f func(x, y float64) float64, nx int, xmin, xmax float64, ny int, ymin, ymax float64, levels []float64) *Contour {
+	return NewContour(NewFuncGrid(f, nx, xmin, xmax, ny, ymin, ymax), levels)
+}
+
+// Plot implements the Plotter interface, drawing the filled regions
+// and then the contour lines.
+func (ct *Contour) Plot(c draw.Canvas, p *plot.Plot) {
+	if len(ct.FillColors) > 0 {
+		ct.plotFill(c, p)
+	}
+
+	if len(ct.LineStyles) == 0 || len(ct.Levels) == 0 {
+		return
+	}
+
+	levels := make([]float64, len(ct.Levels))
+	copy(levels, ct.Levels)
+	lines := plotter.Contour{
+		GridXYZ:    ct.GridXYZ,
+		Levels:     levels,
+		LineStyles: ct.LineStyles,
+		Min:        math.Inf(-1),
+		Max:        math.Inf(+1),
+	}
+	lines.Plot(c, p)
+}
+
+// plotFill fills the regions between the contour levels.
+// Each grid cell is split into 2 triangles, inside of which the values
+// are linearly interpolated.
+func (ct *Contour) plotFill(c draw.Canvas, p *plot.Plot) {
+	var (
+		trX, trY = p.Transforms(&c)
+		g        = ct.GridXYZ
+		nx, ny   = g.Dims()
+		pa       vg.Path
+	)
+
+	for k, col := range ct.FillColors {
+		if col == nil || k > len(ct.Levels) {
+			continue
+		}
+		lo := math.Inf(-1)
+		if k > 0 {
+			lo = ct.Levels[k-1]
+		}
+		hi := math.Inf(+1)
+		if k < len(ct.Levels) {
+			hi = ct.Levels[k]
+		}
+
+		// polygons are also stroked with a thin line of the fill color
+		// to hide the anti-aliasing seams between adjacent polygons.
+		c.SetLineStyle(draw.LineStyle{Color: col, Width: vg.Points(0.25)})
+		c.SetColor(col)
+		for i := 0; i < nx-1; i++ {
+			for j := 0; j < ny-1; j++ {
+				var (
+					v00 = cvertex{g.X(i), g.Y(j), g.Z(i, j)}
+					v10 = cvertex{g.X(i + 1), g.Y(j), g.Z(i+1, j)}
+					v11 = cvertex{g.X(i + 1), g.Y(j + 1), g.Z(i+1, j+1)}
+					v01 = cvertex{g.X(i), g.Y(j + 1), g.Z(i, j+1)}
+				)
+				for _, tri := range [2][]cvertex{{v00, v10, v11}, {v00, v11, v01}} {
+					poly := clipBand(tri, lo, hi)
+					if len(poly) < 3 {
+						continue
+					}
+					pa = pa[:0]
+					for ii, v := range poly {
+						pt := vg.Point{X: trX(v.x), Y: trY(v.y)}
+						if ii == 0 {
+							pa.Move(pt)
+							continue
+						}
+						pa.Line(pt)
+					}
+					pa.Close()
+					c.Fill(pa)
+					c.Stroke(pa)
+				}
+			}
+		}
+	}
+}
+
+// DataRange implements the DataRange method
+// of the plot.DataRanger interface.
+func (ct *Contour) DataRange() (xmin, xmax, ymin, ymax float64) {
+	c, r := ct.GridXYZ.Dims()
+	return ct.GridXYZ.X(0), ct.GridXYZ.X(c - 1), ct.GridXYZ.Y(0), ct.GridXYZ.Y(r - 1)
+}
+
+// cvertex is a vertex of a grid triangle.
+type cvertex struct {
+	x, y, z float64
+}
+
+// clipBand returns the polygon of the region of the provided triangle
+// whose values are in the (lo, hi] range.
+func clipBand(tri []cvertex, lo, hi float64) []cvertex {
+	for _, v := range tri {
+		if math.IsNaN(v.z) {
+			return nil
+		}
+	}
+	poly := clipPoly(tri, func(z float64) float64 { return z - lo })
+	return clipPoly(poly, func(z float64) float64 { return hi - z })
+}
+
+// clipPoly clips the provided convex polygon, keeping the region where
+// the linearly interpolated values of dist are positive.
+func clipPoly(poly []cvertex, dist func(z float64) float64) []cvertex {
+	if len(poly) == 0 {
+		return nil
+	}
+	out := make([]cvertex, 0, len(poly)+2)
+	for i, cur := range poly {
+		var (
+			prv = poly[(i+len(poly)-1)%len(poly)]
+			dc  = dist(cur.z)
+			dp  = dist(prv.z)
+		)
+		switch {
+		case dc >= 0:
+			if dp < 0 {
+				out = append(out, cedge(prv, cur, dp, dc))
+			}
+			out = append(out, cur)
+		case dp >= 0:
+			out = append(out, cedge(prv, cur, dp, dc))
+		}
+	}
+	return out
+}
+
+// cedge returns the vertex where the linear interpolation of the
+// distance between v1 and v2 vanishes.
+func cedge(v1, v2 cvertex, d1, d2 float64) cvertex {
+	t := d1 / (d1 - d2)
+	return cvertex{
+		x: v1.x + t*(v2.x-v1.x),
+		y: v1.y + t*(v2.y-v1.y),
+		z: v1.z + t*(v2.z-v1.z),
+	}
+}
+
+// FuncGrid implements the plotter.GridXYZ interface,
+// holding the values of a function sampled on a regular grid.
+type FuncGrid struct {
+	nx, ny int
+	xs, ys []float64
+	zs     []float64
+}
+
+// NewFuncGrid returns a grid of values of the provided function,
+// sampled on nx columns over [xmin, xmax] and ny rows over [ymin, ymax].
+func NewFuncGrid(f func(x, y float64) float64, nx int, xmin, xmax float64, ny int, ymin, ymax float64) *FuncGrid {
+	if nx < 2 || ny < 2 {
+		panic("hplot: invalid number of grid points")
+	}
+	g := &FuncGrid{
+		nx: nx,
+		ny: ny,
+		xs: make([]float64, nx),
+		ys: make([]float64, ny),
+		zs: make([]float64, nx*ny),
+	}
+	for i := range g.xs {
+		g.xs[i] = xmin + float64(i)*(xmax-xmin)/float64(nx-1)
+	}
+	for j := range g.ys {
+		g.ys[j] = ymin + float64(j)*(ymax-ymin)/float64(ny-1)
+	}
+	for j, y := range g.ys {
+		for i, x := range g.xs {
+			g.zs[j*nx+i] = f(x, y)
+		}
+	}
+	return g
+}
+
+// Dims returns the dimensions of the grid.
+func (g *FuncGrid) Dims() (c, r int) { return g.nx, g.ny }
+
+// Z returns the value of the function at the (c, r) grid point.
+func (g *FuncGrid) Z(c, r int) float64 { return g.zs[r*g.nx+c] }
+
+// X returns the coordinate of the c-th column.
+func (g *FuncGrid) X(c int) float64 { return g.xs[c] }
+
+// Y returns the coordinate of the r-th row.
+func (g *FuncGrid) Y(r int) float64 { return g.ys[r] }
+
+var (
+	_ plot.Plotter    = (*Contour)(nil)
+	_ plot.DataRanger = (*Contour)(nil)
+	_ plotter.GridXYZ = (*FuncGrid)(nil)
+)
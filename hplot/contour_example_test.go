@@ -0,0 +1,91 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"image/color"
+	"log"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distmv"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// An example of making the 68% and 95% CL contours of a 2-dim likelihood scan.
+func ExampleContour() {
+	// -2 ln(L) of a correlated 2-dim Gaussian likelihood.
+	const rho = 0.6
+	nll := func(x, y float64) float64 {
+		x = (x - 1) / 0.5
+		y = (y - 2) / 1.5
+		return (x*x + y*y - 2*rho*x*y) / (1 - rho*rho)
+	}
+
+	ct := hplot.NewFuncContour(nll, 100, -1, 3, 100, -3, 7, []float64{2.30, 5.99})
+	ct.FillColors = []color.Color{
+		color.NRGBA{G: 200, A: 255},
+		color.NRGBA{R: 255, G: 220, A: 255},
+	}
+	ct.LineStyles = []draw.LineStyle{{Color: color.Black, Width: vg.Points(1)}}
+
+	p := hplot.New()
+	p.Title.Text = "Likelihood scan"
+	p.X.Label.Text = "a"
+	p.Y.Label.Text = "b"
+
+	p.Add(ct)
+	p.Add(plotter.NewGrid())
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/contour.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// An example of making line contours of a 2-dim histogram.
+func ExampleContour_h2d() {
+	h := hbook.NewH2D(40, -10, 10, 40, -10, 10)
+
+	dist, ok := distmv.NewNormal(
+		[]float64{0, 1},
+		mat.NewSymDense(2, []float64{4, 0, 0, 2}),
+		rand.New(rand.NewSource(1234)),
+	)
+	if !ok {
+		log.Fatalf("error creating distmv.Normal")
+	}
+
+	v := make([]float64, 2)
+	for i := 0; i < 100000; i++ {
+		v = dist.Rand(v)
+		h.Fill(v[0], v[1], 1)
+	}
+
+	ct := hplot.NewH2DContour(h, []float64{50, 200, 500, 1000})
+	ct.LineStyles = []draw.LineStyle{
+		{Color: color.NRGBA{B: 255, A: 255}, Width: vg.Points(1)},
+		{Color: color.NRGBA{G: 180, A: 255}, Width: vg.Points(1)},
+		{Color: color.NRGBA{R: 255, G: 150, A: 255}, Width: vg.Points(1)},
+		{Color: color.NRGBA{R: 255, A: 255}, Width: vg.Points(1)},
+	}
+
+	p := hplot.New()
+	p.Title.Text = "Hist-2D contours"
+	p.X.Label.Text = "x"
+	p.Y.Label.Text = "y"
+
+	p.Add(ct)
+	p.Add(plotter.NewGrid())
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/contour_h2d.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
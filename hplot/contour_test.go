@@ -0,0 +1,16 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"testing"
+
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestContour(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleContour, t, "contour.png")
+	checkPlot(cmpimg.CheckPlot)(ExampleContour_h2d, t, "contour_h2d.png")
+}
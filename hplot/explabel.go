@@ -0,0 +1,121 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"fmt"
+	"image/color"
+
+	xfnt "golang.org/x/image/font"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// ExpLabel implements the plot.Plotter interface, drawing a standard
+// experiment label (e.g. "ATLAS Internal" or "CMS Preliminary"),
+// optionally followed by lines of text (e.g. the center-of-mass energy
+// and the integrated luminosity), in the data area of a plot.
+type ExpLabel struct {
+	Experiment string // name of the experiment (e.g. "ATLAS")
+	Status     string // status of the figure (e.g. "Internal", "Preliminary")
+	Lines      []string
+
+	// X and Y are the position of the top-left corner of the label,
+	// normalized to the data area of the plot.
+	// Default is (0.05, 0.95).
+	X, Y float64
+
+	// ExpStyle is the text style of the experiment name.
+	ExpStyle draw.TextStyle
+
+	// TextStyle is the text style of the status and of the lines of text.
+	TextStyle draw.TextStyle
+}
+
+// NewExpLabel returns a new experiment label for the provided experiment
+// and status, followed by the optional lines of text.
+//
+// The experiment name is displayed in bold italic for ATLAS, and in bold
+// for all the other experiments.
+// The status is displayed in italic for CMS, and in regular font for all
+// the other experiments.
+func NewExpLabel(exp, status string, lines ...string) *ExpLabel {
+	var (
+		txt = draw.TextStyle{
+			Color:   color.Black,
+			Font:    DefaultStyle.Fonts.Label,
+			Handler: DefaultStyle.TextHandler,
+			XAlign:  draw.XLeft,
+			YAlign:  draw.YTop,
+		}
+		sty = txt
+	)
+	sty.Font.Weight = xfnt.WeightBold
+	switch exp {
+	case "ATLAS":
+		sty.Font.Style = xfnt.StyleItalic
+	case "CMS":
+		txt.Font.Style = xfnt.StyleItalic
+	}
+
+	return &ExpLabel{
+		Experiment: exp,
+		Status:     status,
+		Lines:      lines,
+		X:          0.05,
+		Y:          0.95,
+		ExpStyle:   sty,
+		TextStyle:  txt,
+	}
+}
+
+// EnergyLumi returns the standard text displaying the center-of-mass
+// energy, in TeV, and the integrated luminosity, in fb-1.
+// The luminosity is not displayed if lumi is not positive.
+func EnergyLumi(sqrts, lumi float64) string {
+	txt := fmt.Sprintf("√s = %g TeV", sqrts)
+	if lumi > 0 {
+		txt += fmt.Sprintf(", %g fb-1", lumi)
+	}
+	return txt
+}
+
+// Plot implements the Plotter interface, drawing the label.
+func (lbl *ExpLabel) Plot(c draw.Canvas, p *plot.Plot) {
+	var (
+		x = c.Min.X + vg.Length(lbl.X)*(c.Max.X-c.Min.X)
+		y = c.Min.Y + vg.Length(lbl.Y)*(c.Max.Y-c.Min.Y)
+	)
+
+	dx := vg.Length(0)
+	if lbl.Experiment != "" {
+		c.FillText(lbl.ExpStyle, vg.Point{X: x, Y: y}, lbl.Experiment)
+		dx = lbl.ExpStyle.Width(lbl.Experiment) + lbl.TextStyle.Width(" ")
+	}
+	if lbl.Status != "" {
+		c.FillText(lbl.TextStyle, vg.Point{X: x + dx, Y: y}, lbl.Status)
+	}
+
+	if lbl.Experiment != "" || lbl.Status != "" {
+		y -= 1.25 * lbl.lineHeight()
+	}
+	for _, line := range lbl.Lines {
+		c.FillText(lbl.TextStyle, vg.Point{X: x, Y: y}, line)
+		y -= 1.25 * lbl.TextStyle.Height(line)
+	}
+}
+
+func (lbl *ExpLabel) lineHeight() vg.Length {
+	h := lbl.TextStyle.Height(lbl.Status)
+	if v := lbl.ExpStyle.Height(lbl.Experiment); v > h {
+		h = v
+	}
+	return h
+}
+
+var (
+	_ plot.Plotter = (*ExpLabel)(nil)
+)
@@ -0,0 +1,51 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"image/color"
+	"log"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of a figure with an experiment label, using the HEP style.
+func ExampleExpLabel() {
+	const npoints = 10000
+
+	dist := distuv.Normal{
+		Mu:    125,
+		Sigma: 10,
+		Src:   rand.New(rand.NewSource(0)),
+	}
+
+	hist := hbook.NewH1D(40, 80, 180)
+	for i := 0; i < npoints; i++ {
+		hist.Fill(dist.Rand(), 1)
+	}
+
+	p := hplot.New()
+	sty := hplot.HEPStyle()
+	sty.Apply(p)
+
+	p.X.Label.Text = "m [GeV]"
+	p.Y.Label.Text = "Events / 2.5 GeV"
+
+	h := hplot.NewH1D(hist)
+	h.FillColor = color.NRGBA{R: 100, G: 150, B: 255, A: 255}
+	p.Add(h)
+	p.Y.Max = 1400 // leave room for the label
+
+	p.Add(hplot.NewExpLabel("ATLAS", "Internal", hplot.EnergyLumi(13, 139)))
+
+	err := p.Save(12*vg.Centimeter, 10*vg.Centimeter, "testdata/explabel.png")
+	if err != nil {
+		log.Fatalf("error: %+v", err)
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"testing"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestExpLabel(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleExpLabel, t, "explabel.png")
+}
+
+func TestEnergyLumi(t *testing.T) {
+	for _, tc := range []struct {
+		sqrts, lumi float64
+		want        string
+	}{
+		{13, 139, "√s = 13 TeV, 139 fb-1"},
+		{13.6, 0, "√s = 13.6 TeV"},
+		{7, 4.7, "√s = 7 TeV, 4.7 fb-1"},
+	} {
+		if got := hplot.EnergyLumi(tc.sqrts, tc.lumi); got != tc.want {
+			t.Fatalf("invalid text: got=%q, want=%q", got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/gonum/diff/fd"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// FitFunction draws a function fitted to some data, evaluated with the
+// fitted parameters, together with an optional ±1σ confidence band
+// propagated from the covariance matrix of the fitted parameters.
+type FitFunction struct {
+	// F is the fitted function.
+	F func(x float64, ps []float64) float64
+
+	// Ps are the fitted parameters.
+	Ps []float64
+
+	// Cov is the covariance matrix of the fitted parameters.
+	// The confidence band is not drawn if Cov is nil.
+	Cov *mat.SymDense
+
+	// XMin and XMax specify the range of the function.
+	// If both are zero, the X range of the plot is used.
+	XMin, XMax float64
+
+	// Samples is the number of samples used to draw the function.
+	Samples int
+
+	LineStyle draw.LineStyle
+
+	// BandColor is the color of the ±1σ confidence band.
+	// The confidence band is not drawn if BandColor is nil.
+	BandColor color.Color
+}
+
+// NewFitFunction returns a function plotter for the function f evaluated
+// with the parameters of the fit result res.
+//
+// The covariance matrix of the fitted parameters is the inverse of the
+// Hessian matrix of res, as filled by the functions of the fit package.
+// If res holds no Hessian matrix, or if it can not be inverted, the
+// confidence band is not drawn.
+func NewFitFunction(res *optimize.Result, f func(x float64, ps []float64) float64) *FitFunction {
+	fct := &FitFunction{
+		F:         f,
+		Ps:        res.X,
+		Samples:   100,
+		LineStyle: plotter.DefaultLineStyle,
+		BandColor: color.NRGBA{R: 255, G: 204, A: 128},
+	}
+	fct.LineStyle.Color = color.NRGBA{R: 255, A: 255}
+
+	if res.Hessian != nil {
+		var chol mat.Cholesky
+		if chol.Factorize(res.Hessian) {
+			fct.Cov = new(mat.SymDense)
+			err := chol.InverseTo(fct.Cov)
+			if err != nil {
+				fct.Cov = nil
+			}
+		}
+	}
+
+	return fct
+}
+
+// Err returns the ±1σ uncertainty on the value of the function at x,
+// propagated from the covariance matrix of the fitted parameters.
+// Err returns 0 if the covariance matrix is nil.
+func (fct *FitFunction) Err(x float64) float64 {
+	if fct.Cov == nil {
+		return 0
+	}
+	grad := make([]float64, len(fct.Ps))
+	fd.Gradient(grad, func(ps []float64) float64 {
+		return fct.F(x, ps)
+	}, fct.Ps, nil)
+
+	g := mat.NewVecDense(len(grad), grad)
+	return math.Sqrt(math.Max(0, mat.Inner(g, fct.Cov, g)))
+}
+
+// Plot implements the plot.Plotter interface, drawing the confidence
+// band and then the fitted function.
+func (fct *FitFunction) Plot(c draw.Canvas, plt *plot.Plot) {
+	var (
+		trX, trY = plt.Transforms(&c)
+		min, max = fct.XMin, fct.XMax
+		n        = fct.Samples
+	)
+	if min == 0 && max == 0 {
+		min, max = plt.X.Min, plt.X.Max
+	}
+	if n < 2 {
+		n = 2
+	}
+
+	var (
+		line = make([]vg.Point, n)
+		top  = make(plotter.XYs, n)
+		bot  = make(plotter.XYs, n)
+		band = fct.Cov != nil && fct.BandColor != nil
+		dx   = (max - min) / float64(n-1)
+	)
+	for i := range line {
+		x := min + float64(i)*dx
+		y := fct.F(x, fct.Ps)
+		line[i] = vg.Point{X: trX(x), Y: trY(y)}
+		if band {
+			e := fct.Err(x)
+			top[i] = plotter.XY{X: x, Y: y + e}
+			bot[i] = plotter.XY{X: x, Y: y - e}
+		}
+	}
+
+	if band {
+		NewBand(fct.BandColor, top, bot).Plot(c, plt)
+	}
+	c.StrokeLines(fct.LineStyle, c.ClipLinesXY(line)...)
+}
+
+// Thumbnail returns the thumbnail for the FitFunction,
+// implementing the plot.Thumbnailer interface.
+func (fct *FitFunction) Thumbnail(c *draw.Canvas) {
+	if fct.Cov != nil && fct.BandColor != nil {
+		c.SetColor(fct.BandColor)
+		c.Fill(c.Rectangle.Path())
+	}
+	y := c.Center().Y
+	c.StrokeLine2(fct.LineStyle, c.Min.X, y, c.Max.X, y)
+}
+
+var (
+	_ plot.Plotter     = (*FitFunction)(nil)
+	_ plot.Thumbnailer = (*FitFunction)(nil)
+)
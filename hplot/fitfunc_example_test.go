@@ -0,0 +1,63 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"log"
+	"math"
+
+	"go-hep.org/x/hep/fit"
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of drawing the result of a fit, with its ±1σ confidence
+// band, over the fitted data.
+func ExampleFitFunction() {
+	const npoints = 500
+
+	var (
+		dist = distuv.Exponential{Rate: 1, Src: rand.New(rand.NewSource(0))}
+		hist = hbook.NewH1D(20, 0, 5)
+	)
+	for i := 0; i < npoints; i++ {
+		hist.Fill(dist.Rand(), 1)
+	}
+
+	expo := func(x float64, ps []float64) float64 {
+		return ps[0] * math.Exp(-ps[1]*x)
+	}
+
+	res, err := fit.H1D(
+		hist,
+		fit.Func1D{F: expo, Ps: []float64{100, 2}},
+		fit.WithMethod(&optimize.NelderMead{}),
+	)
+	if err != nil {
+		log.Fatalf("could not fit histogram: %+v", err)
+	}
+
+	p := hplot.New()
+	p.Title.Text = "Fit result"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Entries"
+	p.Legend.Top = true
+
+	data := hplot.NewH1D(hist, hplot.WithDataStyle(true))
+	f := hplot.NewFitFunction(&res.Result, expo)
+
+	p.Add(f, data)
+	p.Legend.Add("data", data)
+	p.Legend.Add("fit ±1σ", f)
+
+	err = p.Save(10*vg.Centimeter, -1, "testdata/fitfunc.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestFitFunction(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleFitFunction, t, "fitfunc.png")
+}
+
+func TestFitFunctionErr(t *testing.T) {
+	// f(x) = a + b*x, with uncorrelated errors on a and b.
+	res := &optimize.Result{
+		Location: optimize.Location{
+			X:       []float64{1, 2},
+			Hessian: mat.NewSymDense(2, []float64{1 / 0.04, 0, 0, 1 / 0.09}),
+		},
+	}
+	f := hplot.NewFitFunction(res, func(x float64, ps []float64) float64 {
+		return ps[0] + ps[1]*x
+	})
+	if f.Cov == nil {
+		t.Fatalf("invalid nil covariance matrix")
+	}
+
+	for _, x := range []float64{0, 1, 2} {
+		var (
+			got  = f.Err(x)
+			want = math.Sqrt(0.04 + 0.09*x*x)
+		)
+		if math.Abs(got-want) > 1e-6 {
+			t.Fatalf("invalid error at x=%v: got=%v, want=%v", x, got, want)
+		}
+	}
+
+	res.Hessian = nil
+	f = hplot.NewFitFunction(res, f.F)
+	if f.Cov != nil {
+		t.Fatalf("invalid non-nil covariance matrix")
+	}
+	if got := f.Err(1); got != 0 {
+		t.Fatalf("invalid error: got=%v, want=0", got)
+	}
+}
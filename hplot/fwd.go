@@ -22,6 +22,8 @@ func NewScatter(xys plotter.XYer) (*plotter.Scatter, error) {
 
 // NewGrid returns a new grid with both vertical and
 // horizontal lines using the default grid line style.
+//
+// See NewGridWithMinors for a grid with minor lines and per-axis styles.
 func NewGrid() *plotter.Grid {
 	return plotter.NewGrid()
 }
@@ -0,0 +1,163 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+var (
+	// DefaultMinorGridLineStyle is the default style for minor grid lines.
+	DefaultMinorGridLineStyle = draw.LineStyle{
+		Color:  color.Gray{Y: 192},
+		Width:  vg.Points(0.2),
+		Dashes: []vg.Length{vg.Points(1), vg.Points(2)},
+	}
+)
+
+// Grid implements the plot.Plotter interface, drawing major and minor
+// grid lines at the ticks of the X and Y axes of a plot.
+//
+// Unlike plotter.Grid, the major and minor grid lines of each axis can be
+// styled independently.
+// A Grid can be added to a plot as any other plotter, or attached to a
+// Plot via its Grid field to be drawn behind the data.
+type Grid struct {
+	X GridLines // vertical grid lines, at the ticks of the X-axis
+	Y GridLines // horizontal grid lines, at the ticks of the Y-axis
+}
+
+// GridLines describes the major and minor grid lines along an axis.
+// Lines with a nil color are not drawn.
+type GridLines struct {
+	Major draw.LineStyle // style of the major grid lines
+	Minor draw.LineStyle // style of the minor grid lines
+
+	// MinorN is the number of minor intervals between two
+	// consecutive major ticks.
+	// If MinorN <= 1, minor grid lines are drawn at the minor ticks
+	// of the axis.
+	MinorN int
+}
+
+// NewGridWithMinors returns a new grid with major and minor lines along
+// both axes, using the default grid line styles.
+func NewGridWithMinors() *Grid {
+	lines := GridLines{
+		Major: plotter.DefaultGridLineStyle,
+		Minor: DefaultMinorGridLineStyle,
+	}
+	return &Grid{X: lines, Y: lines}
+}
+
+// Plot implements the plot.Plotter interface.
+func (g *Grid) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+
+	var (
+		xmin = c.Min.X
+		xmax = c.Max.X
+		ymin = c.Min.Y
+		ymax = c.Max.Y
+	)
+
+	g.X.draw(plt.X, func(sty draw.LineStyle, v float64) {
+		x := trX(v)
+		if x < xmin || x > xmax {
+			return
+		}
+		c.StrokeLine2(sty, x, ymin, x, ymax)
+	})
+
+	g.Y.draw(plt.Y, func(sty draw.LineStyle, v float64) {
+		y := trY(v)
+		if y < ymin || y > ymax {
+			return
+		}
+		c.StrokeLine2(sty, xmin, y, xmax, y)
+	})
+}
+
+// draw draws the minor then the major grid lines of the provided axis.
+func (gl *GridLines) draw(axis plot.Axis, line func(sty draw.LineStyle, v float64)) {
+	if gl.Major.Color == nil && gl.Minor.Color == nil {
+		return
+	}
+
+	var (
+		ticks  = axis.Tick.Marker.Ticks(axis.Min, axis.Max)
+		majors = make([]float64, 0, len(ticks))
+		minors = make([]float64, 0, len(ticks))
+	)
+	for _, tk := range ticks {
+		switch {
+		case tk.IsMinor():
+			minors = append(minors, tk.Value)
+		default:
+			majors = append(majors, tk.Value)
+		}
+	}
+
+	if gl.MinorN > 1 && len(majors) > 1 {
+		// subdivide in the space of the axis scale, so minor grid lines
+		// are evenly spaced on the canvas, e.g. for logarithmic axes.
+		fwd := func(v float64) float64 { return v }
+		inv := fwd
+		switch axis.Scale.(type) {
+		case plot.LogScale, *plot.LogScale:
+			fwd = math.Log
+			inv = math.Exp
+		}
+
+		var (
+			vmin = fwd(axis.Min)
+			vmax = fwd(axis.Max)
+			vs   = make([]float64, len(majors))
+		)
+		for i, v := range majors {
+			vs[i] = fwd(v)
+		}
+
+		minors = minors[:0]
+		step := (vs[1] - vs[0]) / float64(gl.MinorN)
+		// extend the subdivisions before the first and after the last
+		// major ticks, up to the axis range.
+		for v := vs[0] - step; v >= vmin; v -= step {
+			minors = append(minors, inv(v))
+		}
+		for i := range vs[:len(vs)-1] {
+			step := (vs[i+1] - vs[i]) / float64(gl.MinorN)
+			for j := 1; j < gl.MinorN; j++ {
+				minors = append(minors, inv(vs[i]+float64(j)*step))
+			}
+		}
+		step = (vs[len(vs)-1] - vs[len(vs)-2]) / float64(gl.MinorN)
+		for v := vs[len(vs)-1] + step; v <= vmax; v += step {
+			minors = append(minors, inv(v))
+		}
+	}
+
+	if gl.Minor.Color != nil {
+		for _, v := range minors {
+			line(gl.Minor, v)
+		}
+	}
+
+	if gl.Major.Color != nil {
+		for _, v := range majors {
+			line(gl.Major, v)
+		}
+	}
+}
+
+var (
+	_ plot.Plotter = (*Grid)(nil)
+)
@@ -0,0 +1,85 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"image/color"
+	"log"
+	"math"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// ExampleGrid shows how to draw major and minor grid lines, with
+// per-axis styles, behind the data of a plot.
+func ExampleGrid() {
+	p := hplot.New()
+	p.Title.Text = "Grid"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.X.Min = 0
+	p.X.Max = 10
+	p.Y.Min = -1.2
+	p.Y.Max = +1.2
+	p.X.Tick.Marker = hplot.Ticks{N: 5}
+	p.Y.Tick.Marker = hplot.Ticks{N: 5}
+
+	grid := hplot.NewGridWithMinors()
+	grid.X.MinorN = 4
+	grid.Y.MinorN = 2
+	grid.Y.Major.Color = color.RGBA{B: 255, A: 255}
+	grid.Y.Major.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+	p.Grid = grid
+
+	f := hplot.NewFunction(math.Sin)
+	f.Color = color.RGBA{R: 255, A: 255}
+	f.Width = vg.Points(2)
+	f.Samples = 1000
+	p.Add(f)
+
+	p.BackgroundColor = color.RGBA{R: 255, G: 255, B: 224, A: 255}
+
+	// a regular plotter.Grid may still be drawn on top of the data.
+	p.Add(&plotter.Grid{Vertical: plotter.DefaultGridLineStyle})
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/grid.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// ExampleGrid_logScale shows how minor grid lines are evenly spaced
+// on a logarithmic axis.
+func ExampleGrid_logScale() {
+	p := hplot.New()
+	p.Title.Text = "Grid (log-scale)"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.X.Min = 0
+	p.X.Max = 10
+	p.Y.Min = 1
+	p.Y.Max = 1e5
+	p.Y.Scale = plot.LogScale{}
+	p.Y.Tick.Marker = plot.LogTicks{Prec: -1}
+
+	grid := hplot.NewGridWithMinors()
+	grid.X.MinorN = 4
+	grid.Y.MinorN = 5
+	p.Grid = grid
+
+	f := hplot.NewFunction(math.Exp)
+	f.Color = color.RGBA{R: 255, A: 255}
+	f.Width = vg.Points(2)
+	f.Samples = 1000
+	p.Add(f)
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/grid_log.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
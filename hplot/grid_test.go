@@ -0,0 +1,19 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"testing"
+
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestGrid(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleGrid, t, "grid.png")
+}
+
+func TestGridLogScale(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleGrid_logScale, t, "grid_log.png")
+}
@@ -0,0 +1,243 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// GridFig is a figure made of a grid of NxM aligned plots, with optional
+// shared axes, a figure-level title and a figure-level legend.
+type GridFig struct {
+	// Plots are the plots of the grid, in row-major order.
+	Plots []*Plot
+
+	// Tiles controls the layout of the grid.
+	// Tiles can be used to customize the padding between plots.
+	Tiles draw.Tiles
+
+	// ShareX shares the X axis of the plots of a column:
+	// all the plots of a column are drawn with the same X range,
+	// and only the plots of the bottom row display the tick labels
+	// and the label of their X axis.
+	ShareX bool
+
+	// ShareY shares the Y axis of the plots of a row:
+	// all the plots of a row are drawn with the same Y range,
+	// and only the plots of the left column display the tick labels
+	// and the label of their Y axis.
+	ShareY bool
+
+	// Title is the figure-level title, drawn above the grid.
+	Title struct {
+		Text      string
+		Padding   vg.Length // padding between the title and the grid
+		TextStyle text.Style
+	}
+
+	// Legend is the figure-level legend, drawn on the right of the grid.
+	Legend plot.Legend
+}
+
+// NewGridFig creates a new figure with a grid of rows x cols plots.
+// By default, NewGridFig will put a 1 vg.Length space between each plot.
+func NewGridFig(rows, cols int) *GridFig {
+	fig := &GridFig{
+		Plots: make([]*Plot, rows*cols),
+		Tiles: draw.Tiles{Rows: rows, Cols: cols},
+	}
+
+	const pad = 1
+	for _, v := range []*vg.Length{
+		&fig.Tiles.PadTop, &fig.Tiles.PadBottom,
+		&fig.Tiles.PadRight, &fig.Tiles.PadLeft,
+		&fig.Tiles.PadX, &fig.Tiles.PadY,
+	} {
+		if *v == 0 {
+			*v = pad
+		}
+	}
+
+	for i := range fig.Plots {
+		fig.Plots[i] = New()
+	}
+
+	fig.Title.Padding = vg.Points(5)
+	fig.Title.TextStyle = text.Style{
+		Color:   color.Black,
+		Font:    DefaultStyle.Fonts.Title,
+		XAlign:  draw.XCenter,
+		YAlign:  draw.YTop,
+		Handler: DefaultStyle.TextHandler,
+	}
+
+	fig.Legend = plot.NewLegend()
+	fig.Legend.TextStyle.Font = DefaultStyle.Fonts.Legend
+	fig.Legend.TextStyle.Handler = DefaultStyle.TextHandler
+	fig.Legend.Top = true
+
+	return fig
+}
+
+// Plot returns the plot at the i-th row and j-th column of the grid.
+// (0,0) is at the top-left of the grid.
+func (fig *GridFig) Plot(i, j int) *Plot {
+	return fig.Plots[i*fig.Tiles.Cols+j]
+}
+
+// Draw draws the figure to a draw.Canvas.
+func (fig *GridFig) Draw(c draw.Canvas) {
+	if fig.Title.Text != "" {
+		descent := fig.Title.TextStyle.FontExtents().Descent
+		c.FillText(fig.Title.TextStyle, vg.Point{X: c.Center().X, Y: c.Max.Y + descent}, fig.Title.Text)
+
+		rect := fig.Title.TextStyle.Rectangle(fig.Title.Text)
+		c.Max.Y -= rect.Size().Y
+		c.Max.Y -= fig.Title.Padding
+	}
+
+	if leg := fig.Legend.Rectangle(c); leg.Size().Y > 0 {
+		lc := c
+		lc.Min.X = c.Max.X - leg.Size().X - fig.Tiles.PadRight
+		fig.Legend.Draw(lc)
+		c.Max.X = lc.Min.X - fig.Tiles.PadX
+	}
+
+	restore := fig.share()
+	defer restore()
+
+	var (
+		rows = fig.Tiles.Rows
+		cols = fig.Tiles.Cols
+		ps   = make([][]*plot.Plot, rows)
+	)
+	for i := range ps {
+		ps[i] = make([]*plot.Plot, cols)
+		for j := range ps[i] {
+			if p := fig.Plot(i, j); p != nil {
+				ps[i][j] = p.Plot
+			}
+		}
+	}
+
+	cs := plot.Align(ps, fig.Tiles, c)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			p := fig.Plot(i, j)
+			if p == nil {
+				continue
+			}
+			p.Draw(cs[i][j])
+		}
+	}
+}
+
+// share applies the shared axes settings to the plots of the grid,
+// and returns a function restoring their original settings.
+func (fig *GridFig) share() func() {
+	var (
+		rows  = fig.Tiles.Rows
+		cols  = fig.Tiles.Cols
+		saved = make([]plot.Plot, len(fig.Plots))
+	)
+	for i, p := range fig.Plots {
+		if p != nil {
+			saved[i] = *p.Plot
+		}
+	}
+	restore := func() {
+		for i, p := range fig.Plots {
+			if p != nil {
+				*p.Plot = saved[i]
+			}
+		}
+	}
+
+	if fig.ShareX {
+		for j := 0; j < cols; j++ {
+			min, max := math.Inf(+1), math.Inf(-1)
+			for i := 0; i < rows; i++ {
+				if p := fig.Plot(i, j); p != nil {
+					min = math.Min(min, p.X.Min)
+					max = math.Max(max, p.X.Max)
+				}
+			}
+			for i := 0; i < rows; i++ {
+				p := fig.Plot(i, j)
+				if p == nil {
+					continue
+				}
+				p.X.Min = min
+				p.X.Max = max
+				if i < rows-1 {
+					p.X.Label.Text = ""
+					p.X.Tick.Marker = unlabeledTicks{p.X.Tick.Marker}
+				}
+			}
+		}
+	}
+
+	if fig.ShareY {
+		for i := 0; i < rows; i++ {
+			min, max := math.Inf(+1), math.Inf(-1)
+			for j := 0; j < cols; j++ {
+				if p := fig.Plot(i, j); p != nil {
+					min = math.Min(min, p.Y.Min)
+					max = math.Max(max, p.Y.Max)
+				}
+			}
+			for j := 0; j < cols; j++ {
+				p := fig.Plot(i, j)
+				if p == nil {
+					continue
+				}
+				p.Y.Min = min
+				p.Y.Max = max
+				if j > 0 {
+					p.Y.Label.Text = ""
+					p.Y.Tick.Marker = unlabeledTicks{p.Y.Tick.Marker}
+				}
+			}
+		}
+	}
+
+	return restore
+}
+
+// Save saves the figure to an image file.
+// The file format is determined by the extension.
+//
+// Supported extensions are the same ones than hplot.Plot.Save.
+//
+// If w or h are <= 0, the value is chosen such that it follows the Golden Ratio.
+// If w and h are <= 0, the values are chosen such that they follow the Golden Ratio
+// (the width is defaulted to vgimg.DefaultWidth).
+func (fig *GridFig) Save(w, h vg.Length, file string) error {
+	return Save(fig, w, h, file)
+}
+
+// unlabeledTicks implements plot.Ticker, returning the ticks of the
+// wrapped ticker without their labels.
+type unlabeledTicks struct {
+	plot.Ticker
+}
+
+func (t unlabeledTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := t.Ticker.Ticks(min, max)
+	for i := range ticks {
+		ticks[i].Label = ""
+	}
+	return ticks
+}
+
+var (
+	_ Drawer = (*GridFig)(nil)
+)
@@ -0,0 +1,67 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of making a grid of plots with shared axes and
+// a common legend.
+func ExampleGridFig() {
+	fig := hplot.NewGridFig(2, 2)
+	fig.ShareX = true
+	fig.ShareY = true
+	fig.Title.Text = "Shared axes"
+
+	src := rand.New(rand.NewSource(0))
+	var (
+		sig = color.NRGBA{R: 255, A: 128}
+		bkg = color.NRGBA{B: 255, A: 128}
+	)
+
+	var hs [2]*hplot.H1D
+	for i := 0; i < fig.Tiles.Rows; i++ {
+		for j := 0; j < fig.Tiles.Cols; j++ {
+			var (
+				p    = fig.Plot(i, j)
+				hsig = hbook.NewH1D(20, -4, +4)
+				hbkg = hbook.NewH1D(20, -4, +4)
+				gaus = distuv.Normal{Mu: float64(j) - 0.5, Sigma: 0.5 + 0.5*float64(i), Src: src}
+				flat = distuv.Uniform{Min: -4, Max: +4, Src: src}
+			)
+			for k := 0; k < 5000; k++ {
+				hsig.Fill(gaus.Rand(), 1)
+				hbkg.Fill(flat.Rand(), 1)
+			}
+
+			hs[0] = hplot.NewH1D(hsig)
+			hs[0].FillColor = sig
+			hs[1] = hplot.NewH1D(hbkg)
+			hs[1].FillColor = bkg
+			p.Add(hs[0], hs[1])
+
+			p.Title.Text = fmt.Sprintf("(%d, %d)", i, j)
+			p.X.Label.Text = "x"
+			p.Y.Label.Text = "entries"
+		}
+	}
+
+	fig.Legend.Add("signal", hs[0])
+	fig.Legend.Add("background", hs[1])
+
+	err := fig.Save(15*vg.Centimeter, 12*vg.Centimeter, "testdata/gridfig.png")
+	if err != nil {
+		log.Fatalf("error: %+v\n", err)
+	}
+}
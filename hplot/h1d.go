@@ -11,6 +11,7 @@ import (
 	"math"
 
 	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/stat/distuv"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/font"
 	"gonum.org/v1/plot/plotter"
@@ -120,11 +121,39 @@ func NewH1D(h *hbook.H1D, opts ...Options) *H1D {
 
 	if cfg.band {
 		h1.Band = h1.withBand()
+		if cfg.hatch != nil {
+			h1.Band.FillColor = nil
+			h1.Band.Hatch = *cfg.hatch
+		}
 	}
 
-	if cfg.bars.yerrs {
+	if cfg.data {
+		h1.LineStyle.Width = 0
+		h1.GlyphStyle = draw.GlyphStyle{
+			Color:  color.Black,
+			Radius: vg.Points(2.5),
+			Shape:  draw.CircleGlyph{},
+		}
+		cfg.poisson = true
+	}
+
+	if cfg.line != nil {
+		h1.LineStyle = *cfg.line
+	}
+
+	if cfg.fill != nil {
+		h1.FillColor = cfg.fill
+	}
+
+	switch {
+	case cfg.poisson:
+		h1.YErrs = h1.withPoissonErrBars()
+	case cfg.bars.yerrs:
 		h1.YErrs = h1.withYErrBars(nil)
 	}
+	if h1.YErrs != nil && cfg.bars.capw != nil {
+		h1.YErrs.CapWidth = *cfg.bars.capw
+	}
 
 	if cfg.glyph != (draw.GlyphStyle{}) {
 		h1.GlyphStyle = cfg.glyph
@@ -168,6 +197,52 @@ func (h *H1D) withYErrBars(yoffs []float64) *plotter.YErrorBars {
 	return yplt
 }
 
+// withPoissonErrBars enables the Poisson Y error bars.
+func (h *H1D) withPoissonErrBars() *plotter.YErrorBars {
+	bins := h.Hist.Binning.Bins
+	data := make(plotter.XYs, 0, len(bins))
+	yerr := make(plotter.YErrors, 0, len(bins))
+	for _, bin := range bins {
+		if bin.Entries() == 0 {
+			continue
+		}
+		n := bin.SumW()
+		lo, hi := poissonInterval(n)
+		data = append(data, plotter.XY{X: bin.XMid(), Y: n})
+		yerr = append(yerr, struct{ Low, High float64 }{n - lo, hi - n})
+	}
+
+	type yerrT struct {
+		plotter.XYer
+		plotter.YErrorer
+	}
+
+	yplt, err := plotter.NewYErrorBars(yerrT{data, yerr})
+	if err != nil {
+		panic(err)
+	}
+	yplt.LineStyle.Color = color.Black
+	yplt.LineStyle.Width = vg.Points(1)
+	if h.LineStyle.Width != 0 {
+		yplt.LineStyle.Color = h.LineStyle.Color
+		yplt.LineStyle.Width = h.LineStyle.Width
+	}
+	yplt.CapWidth = 0
+
+	return yplt
+}
+
+// poissonInterval returns the 68.27% Garwood confidence interval of the
+// mean of a Poisson distribution, for n observed counts.
+func poissonInterval(n float64) (lo, hi float64) {
+	const alpha = 1 - 0.682689492137086
+	if n > 0 {
+		lo = 0.5 * distuv.ChiSquared{K: 2 * n}.Quantile(0.5*alpha)
+	}
+	hi = 0.5 * distuv.ChiSquared{K: 2 * (n + 1)}.Quantile(1-0.5*alpha)
+	return lo, hi
+}
+
 // withBand enables the band between ymin-ymax error bars.
 func (h1 *H1D) withBand() *BinnedErrBand {
 	b := NewBinnedErrBand(h1.Hist.Counts())
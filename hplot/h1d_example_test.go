@@ -526,3 +526,51 @@ func ExampleH1D_legendStyle() {
 		log.Fatalf("error saving plot: %v\n", err)
 	}
 }
+
+// An example of displaying histograms with the data, outline-only
+// and filled styles.
+func ExampleH1D_dataStyle() {
+	const npoints = 500
+
+	src := rand.New(rand.NewSource(0))
+	var (
+		sig  = distuv.Normal{Mu: 0, Sigma: 0.5, Src: src}
+		bkg  = distuv.Uniform{Min: -4, Max: +4, Src: src}
+		data = hbook.NewH1D(20, -4, +4)
+		hsig = hbook.NewH1D(20, -4, +4)
+		hbkg = hbook.NewH1D(20, -4, +4)
+	)
+	for i := 0; i < npoints; i++ {
+		data.Fill(sig.Rand(), 1)
+		data.Fill(bkg.Rand(), 1)
+		hsig.Fill(sig.Rand(), 1)
+		hbkg.Fill(bkg.Rand(), 1)
+	}
+
+	p := hplot.New()
+	p.Title.Text = "Histogram styles"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Entries"
+	p.Legend.Top = true
+
+	hb := hplot.NewH1D(hbkg,
+		hplot.WithFillColor(color.NRGBA{B: 255, A: 80}),
+	)
+	hs := hplot.NewH1D(hsig,
+		hplot.WithLineStyle(draw.LineStyle{
+			Color:  color.NRGBA{R: 255, A: 255},
+			Width:  vg.Points(1.5),
+			Dashes: []vg.Length{vg.Points(4), vg.Points(2)},
+		}),
+	)
+	hd := hplot.NewH1D(data, hplot.WithDataStyle(true))
+
+	p.Add(hb, hs, hd)
+	p.Legend.Add("data", hd)
+	p.Legend.Add("signal", hs)
+	p.Legend.Add("background", hb)
+
+	if err := p.Save(6*vg.Inch, -1, "testdata/h1d_data_style.png"); err != nil {
+		log.Fatalf("error saving plot: %v\n", err)
+	}
+}
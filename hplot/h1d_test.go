@@ -44,6 +44,34 @@ func TestH1DAsData(t *testing.T) {
 	checkPlot(cmpimg.CheckPlot)(ExampleH1D_withYErrBarsAndData, t, "h1d_glyphs.png")
 }
 
+func TestH1DDataStyle(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleH1D_dataStyle, t, "h1d_data_style.png")
+}
+
+func TestH1DPoissonErrBars(t *testing.T) {
+	hist := hbook.NewH1D(3, 0, 3)
+	hist.Fill(0.5, 1)
+	for i := 0; i < 10; i++ {
+		hist.Fill(2.5, 1)
+	}
+
+	h := hplot.NewH1D(hist, hplot.WithPoissonErrBars(true))
+	if got, want := len(h.YErrs.YErrors), 2; got != want {
+		t.Fatalf("invalid number of error bars: got=%d, want=%d", got, want)
+	}
+
+	// Garwood intervals for n=1 and n=10.
+	for i, want := range [][2]float64{
+		{1 - 0.1727, 3.2995 - 1},
+		{10 - 6.8913, 14.2670 - 10},
+	} {
+		got := h.YErrs.YErrors[i]
+		if math.Abs(got.Low-want[0]) > 1e-3 || math.Abs(got.High-want[1]) > 1e-3 {
+			t.Fatalf("invalid error bar[%d]: got=%+v, want=%v", i, got, want)
+		}
+	}
+}
+
 func TestH1DLegendStyle(t *testing.T) {
 	checkPlot(cmpimg.CheckPlot)(ExampleH1D_legendStyle, t, "h1d_legend.png")
 }
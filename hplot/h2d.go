@@ -5,6 +5,8 @@
 package hplot
 
 import (
+	"math"
+
 	"go-hep.org/x/hep/hbook"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/palette"
@@ -26,6 +28,19 @@ type H2D struct {
 	// HeatMap implements the Plotter interface, drawing
 	// a heat map of the values in the 2-d histogram.
 	HeatMap *plotter.HeatMap
+
+	// LogZ enables the logarithmic scaling of the colors of the heat map.
+	// Bins with a non-positive content are drawn with the NaN color of
+	// the heat map.
+	// If HeatMap.Min is not positive, the smallest positive bin content
+	// is used as the lower bound of the dynamic range.
+	LogZ bool
+
+	// Clamp draws the bins whose content lies outside the
+	// [HeatMap.Min, HeatMap.Max] dynamic range with the first or last
+	// color of the palette, unless the Underflow or Overflow colors
+	// of the heat map are explicitly set.
+	Clamp bool
 }
 
 // NewH2D returns a new 2-dim histogram from a hbook.H2D.
@@ -42,7 +57,59 @@ func NewH2D(h *hbook.H2D, p palette.Palette) *H2D {
 // Plot implements the Plotter interface, drawing a line
 // that connects each point in the Line.
 func (h *H2D) Plot(c draw.Canvas, p *plot.Plot) {
-	h.HeatMap.Plot(c, p)
+	h.heatMap().Plot(c, p)
+}
+
+// heatMap returns the heat map to draw, taking into account the
+// log-z and clamping options.
+func (h *H2D) heatMap() *plotter.HeatMap {
+	if !h.LogZ && !h.Clamp {
+		return h.HeatMap
+	}
+
+	hm := *h.HeatMap
+	if h.Clamp {
+		pal := hm.Palette.Colors()
+		if hm.Underflow == nil && len(pal) > 0 {
+			hm.Underflow = pal[0]
+		}
+		if hm.Overflow == nil && len(pal) > 0 {
+			hm.Overflow = pal[len(pal)-1]
+		}
+	}
+	if h.LogZ {
+		zmin, zmax := h.zrange()
+		hm.GridXYZ = logGridXYZ{hm.GridXYZ}
+		hm.Min = math.Log10(zmin)
+		hm.Max = math.Log10(zmax)
+	}
+	return &hm
+}
+
+// zrange returns the dynamic range of the heat map.
+func (h *H2D) zrange() (zmin, zmax float64) {
+	zmin, zmax = h.HeatMap.Min, h.HeatMap.Max
+	if !h.LogZ || zmin > 0 {
+		return zmin, zmax
+	}
+
+	zmin = math.Inf(+1)
+	c, r := h.HeatMap.GridXYZ.Dims()
+	for i := 0; i < c; i++ {
+		for j := 0; j < r; j++ {
+			v := h.HeatMap.GridXYZ.Z(i, j)
+			if v > 0 && v < zmin {
+				zmin = v
+			}
+		}
+	}
+	if math.IsInf(zmin, +1) {
+		zmin = 1
+	}
+	if zmax < zmin {
+		zmax = zmin
+	}
+	return zmin, zmax
 }
 
 // DataRange implements the DataRange method
@@ -58,6 +125,33 @@ func (h *H2D) GlyphBoxes(p *plot.Plot) []plot.GlyphBox {
 	return h.HeatMap.GlyphBoxes(p)
 }
 
+// ColorBar returns a color bar displaying the palette and the dynamic
+// range of the heat map.
+func (h *H2D) ColorBar() *ColorBar {
+	zmin, zmax := h.zrange()
+	return &ColorBar{
+		Palette: h.HeatMap.Palette,
+		Min:     zmin,
+		Max:     zmax,
+		LogZ:    h.LogZ,
+	}
+}
+
+// logGridXYZ wraps a plotter.GridXYZ, returning the base-10 logarithm
+// of its Z values.
+// Non-positive values are returned as NaN.
+type logGridXYZ struct {
+	plotter.GridXYZ
+}
+
+func (g logGridXYZ) Z(c, r int) float64 {
+	v := g.GridXYZ.Z(c, r)
+	if v <= 0 {
+		return math.NaN()
+	}
+	return math.Log10(v)
+}
+
 // check interfaces
 var _ plot.Plotter = (*H2D)(nil)
 var _ plot.DataRanger = (*H2D)(nil)
@@ -50,3 +50,39 @@ func ExampleH2D() {
 		log.Fatal(err)
 	}
 }
+
+func ExampleH2D_logZ() {
+	h := hbook.NewH2D(50, -10, 10, 50, -10, 10)
+
+	const npoints = 100000
+
+	dist, ok := distmv.NewNormal(
+		[]float64{0, 1},
+		mat.NewSymDense(2, []float64{4, 0, 0, 2}),
+		rand.New(rand.NewSource(1234)),
+	)
+	if !ok {
+		log.Fatalf("error creating distmv.Normal")
+	}
+
+	v := make([]float64, 2)
+	for i := 0; i < npoints; i++ {
+		v = dist.Rand(v)
+		h.Fill(v[0], v[1], 1)
+	}
+
+	hh := hplot.NewH2D(h, hplot.Viridis(64))
+	hh.LogZ = true
+	hh.Clamp = true
+
+	p := hplot.NewColorBarPlot(hh)
+	p.Plot.Title.Text = "Hist-2D (log-z)"
+	p.Plot.X.Label.Text = "x"
+	p.Plot.Y.Label.Text = "y"
+	p.Bar.X.Label.Text = "entries"
+
+	err := hplot.Save(p, 10*vg.Centimeter, 12*vg.Centimeter, "testdata/h2d_logz.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 func TestH2D(t *testing.T) {
 	checkPlot(cmpimg.CheckPlot)(ExampleH2D, t, "h2d_plot.png")
+	checkPlot(cmpimg.CheckPlot)(ExampleH2D_logZ, t, "h2d_logz.png")
 }
 
 func TestH2DABCD(t *testing.T) {
@@ -0,0 +1,88 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+var (
+	// DefaultHatchStyle is the default style for hatched areas.
+	DefaultHatchStyle = HatchStyle{
+		LineStyle: draw.LineStyle{
+			Color: color.Gray{Y: 64},
+			Width: vg.Points(0.5),
+		},
+		Spacing: vg.Points(4),
+	}
+)
+
+// HatchStyle describes the hatching of an area with parallel lines
+// going up at 45 degrees.
+// Hatch lines with a nil color or a non-positive spacing are not drawn.
+type HatchStyle struct {
+	draw.LineStyle // style of the hatch lines
+
+	// Spacing is the vertical distance between two hatch lines.
+	Spacing vg.Length
+}
+
+// draw hatches the part of the provided rectangle, in canvas coordinates,
+// that lies within the canvas.
+// Hatch lines are anchored on the canvas origin so that adjacent
+// rectangles are hatched seamlessly.
+func (sty HatchStyle) draw(c draw.Canvas, rect vg.Rectangle) {
+	if sty.Color == nil || sty.Spacing <= 0 {
+		return
+	}
+
+	var (
+		x0, x1 = rect.Min.X, rect.Max.X
+		y0, y1 = rect.Min.Y, rect.Max.Y
+	)
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+
+	// clip to the canvas.
+	if x0 < c.Min.X {
+		x0 = c.Min.X
+	}
+	if x1 > c.Max.X {
+		x1 = c.Max.X
+	}
+	if y0 < c.Min.Y {
+		y0 = c.Min.Y
+	}
+	if y1 > c.Max.Y {
+		y1 = c.Max.Y
+	}
+	if x0 >= x1 || y0 >= y1 {
+		return
+	}
+
+	// hatch lines are y = x + k, with k a multiple of the spacing.
+	k := sty.Spacing * vg.Length(int((y0-x1)/sty.Spacing))
+	for ; k <= y1-x0; k += sty.Spacing {
+		xa := x0
+		if v := y0 - k; v > xa {
+			xa = v
+		}
+		xb := x1
+		if v := y1 - k; v < xb {
+			xb = v
+		}
+		if xa >= xb {
+			continue
+		}
+		c.StrokeLine2(sty.LineStyle, xa, xa+k, xb, xb+k)
+	}
+}
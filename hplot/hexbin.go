@@ -0,0 +1,280 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// HexBin implements the plot.Plotter interface, drawing the density of
+// 2-dim data points, binned in a grid of hexagons.
+//
+// The data points are binned as they are filled: only the content of the
+// hexagons is kept in memory, so that data sets with millions of points
+// can be displayed and rendered quickly.
+type HexBin struct {
+	// Palette is the color palette used to shade the hexagons.
+	Palette palette.Palette
+
+	// Min and Max define the dynamic range of the colors.
+	// If Min and Max are both zero, the dynamic range is computed
+	// from the content of the non-empty hexagons.
+	// Hexagons whose content lies outside the dynamic range are drawn
+	// with the first or last color of the palette.
+	Min, Max float64
+
+	// LogZ enables the logarithmic scaling of the colors.
+	LogZ bool
+
+	// LineStyle is the style of the outline of the hexagons.
+	// Outlines are not drawn if the width of the line is zero.
+	LineStyle draw.LineStyle
+
+	nx, ny  int
+	xmin    float64
+	xmax    float64
+	ymin    float64
+	ymax    float64
+	sx, sy  float64   // size of the hexagons, in data coordinates
+	cells1  []float64 // hexagons centered on the (i, j) grid
+	cells2  []float64 // hexagons centered on the (i+1/2, j+1/2) grid
+	entries int64
+}
+
+// NewHexBin returns a new, empty, hexagonal binning of the
+// [xmin, xmax]x[ymin, ymax] region, with n hexagons along the X axis.
+// The number of hexagons along the Y axis is chosen so that the
+// hexagons are regular when the region is displayed as a square.
+//
+// If p is nil, the viridis palette is used.
+func NewHexBin(n int, xmin, xmax, ymin, ymax float64, p palette.Palette) *HexBin {
+	if n <= 0 {
+		panic("hplot: invalid number of hexagons")
+	}
+	if xmin >= xmax || ymin >= ymax {
+		panic("hplot: invalid hexbin range")
+	}
+	if p == nil {
+		p = Viridis(64)
+	}
+
+	ny := int(math.Round(float64(n) / math.Sqrt(3)))
+	if ny < 1 {
+		ny = 1
+	}
+
+	return &HexBin{
+		Palette: p,
+		nx:      n,
+		ny:      ny,
+		xmin:    xmin,
+		xmax:    xmax,
+		ymin:    ymin,
+		ymax:    ymax,
+		sx:      (xmax - xmin) / float64(n),
+		sy:      (ymax - ymin) / float64(ny),
+		cells1:  make([]float64, (n+1)*(ny+1)),
+		cells2:  make([]float64, n*ny),
+	}
+}
+
+// NewHexBinFromXYer returns a new hexagonal binning of the data points,
+// with n hexagons along the X axis, spanning the range of the data.
+//
+// If p is nil, the viridis palette is used.
+func NewHexBinFromXYer(data plotter.XYer, n int, p palette.Palette) *HexBin {
+	xmin, xmax, ymin, ymax := plotter.XYRange(data)
+	if xmin == xmax {
+		xmin -= 0.5
+		xmax += 0.5
+	}
+	if ymin == ymax {
+		ymin -= 0.5
+		ymax += 0.5
+	}
+
+	hb := NewHexBin(n, xmin, xmax, ymin, ymax, p)
+	for i := 0; i < data.Len(); i++ {
+		x, y := data.XY(i)
+		hb.Fill(x, y, 1)
+	}
+	return hb
+}
+
+// Fill adds the weight w to the hexagon containing the (x, y) point.
+// Points outside the binned region are ignored.
+func (hb *HexBin) Fill(x, y, w float64) {
+	cell := hb.cell(x, y)
+	if cell == nil {
+		return
+	}
+	*cell += w
+	hb.entries++
+}
+
+// Entries returns the number of points filled in the hexagons.
+func (hb *HexBin) Entries() int64 {
+	return hb.entries
+}
+
+// Count returns the content of the hexagon containing the (x, y) point,
+// or zero if the point is outside the binned region.
+func (hb *HexBin) Count(x, y float64) float64 {
+	cell := hb.cell(x, y)
+	if cell == nil {
+		return 0
+	}
+	return *cell
+}
+
+// cell returns the content of the hexagon containing the (x, y) point.
+func (hb *HexBin) cell(x, y float64) *float64 {
+	if !(hb.xmin <= x && x <= hb.xmax && hb.ymin <= y && y <= hb.ymax) {
+		return nil
+	}
+
+	var (
+		u = (x - hb.xmin) / hb.sx
+		v = (y - hb.ymin) / hb.sy
+
+		i1 = int(math.Round(u))
+		j1 = int(math.Round(v))
+		i2 = int(math.Floor(u))
+		j2 = int(math.Floor(v))
+
+		du1 = u - float64(i1)
+		dv1 = v - float64(j1)
+		du2 = u - float64(i2) - 0.5
+		dv2 = v - float64(j2) - 0.5
+	)
+
+	if du1*du1+3*dv1*dv1 <= du2*du2+3*dv2*dv2 || i2 >= hb.nx || j2 >= hb.ny {
+		return &hb.cells1[j1*(hb.nx+1)+i1]
+	}
+	return &hb.cells2[j2*hb.nx+i2]
+}
+
+// Plot implements the Plotter interface, drawing the non-empty hexagons.
+func (hb *HexBin) Plot(c draw.Canvas, p *plot.Plot) {
+	pal := hb.Palette.Colors()
+	if len(pal) == 0 {
+		panic("hplot: empty palette")
+	}
+
+	var (
+		trX, trY   = p.Transforms(&c)
+		zmin, zmax = hb.zrange()
+		n          = float64(len(pal))
+		hex        = make([]vg.Point, 7)
+	)
+	if hb.LogZ {
+		zmin = math.Log10(zmin)
+		zmax = math.Log10(zmax)
+	}
+
+	// vertices of the hexagons, relative to their center.
+	var (
+		dx = []float64{+0.5, +0.5, 0, -0.5, -0.5, 0}
+		dy = []float64{-1.0 / 6, +1.0 / 6, +1.0 / 3, +1.0 / 6, -1.0 / 6, -1.0 / 3}
+	)
+
+	fill := func(u, v, z float64) {
+		if z <= 0 && (hb.LogZ || z == 0) {
+			return
+		}
+		if hb.LogZ {
+			z = math.Log10(z)
+		}
+
+		idx := 0
+		if zmax > zmin {
+			idx = int((z - zmin) / (zmax - zmin) * n)
+		}
+		switch {
+		case idx < 0:
+			idx = 0
+		case idx >= len(pal):
+			idx = len(pal) - 1
+		}
+
+		for k := range dx {
+			x := hb.xmin + (u+dx[k])*hb.sx
+			y := hb.ymin + (v+dy[k])*hb.sy
+			hex[k] = vg.Point{X: trX(x), Y: trY(y)}
+		}
+		hex[6] = hex[0]
+
+		c.FillPolygon(pal[idx], c.ClipPolygonXY(hex[:6]))
+		if hb.LineStyle.Width > 0 {
+			c.StrokeLines(hb.LineStyle, c.ClipLinesXY(hex)...)
+		}
+	}
+
+	for j := 0; j <= hb.ny; j++ {
+		for i := 0; i <= hb.nx; i++ {
+			fill(float64(i), float64(j), hb.cells1[j*(hb.nx+1)+i])
+		}
+	}
+	for j := 0; j < hb.ny; j++ {
+		for i := 0; i < hb.nx; i++ {
+			fill(float64(i)+0.5, float64(j)+0.5, hb.cells2[j*hb.nx+i])
+		}
+	}
+}
+
+// zrange returns the dynamic range of the colors.
+func (hb *HexBin) zrange() (zmin, zmax float64) {
+	zmin, zmax = hb.Min, hb.Max
+	if zmin != 0 || zmax != 0 {
+		if hb.LogZ && zmin <= 0 {
+			zmin = math.Min(1, zmax)
+		}
+		return zmin, zmax
+	}
+
+	zmin = math.Inf(+1)
+	zmax = math.Inf(-1)
+	for _, cells := range [][]float64{hb.cells1, hb.cells2} {
+		for _, v := range cells {
+			if v == 0 || (hb.LogZ && v < 0) {
+				continue
+			}
+			zmin = math.Min(zmin, v)
+			zmax = math.Max(zmax, v)
+		}
+	}
+	if math.IsInf(zmin, +1) {
+		zmin, zmax = 1, 1
+	}
+	return zmin, zmax
+}
+
+// DataRange implements the DataRange method
+// of the plot.DataRanger interface.
+func (hb *HexBin) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return hb.xmin, hb.xmax, hb.ymin, hb.ymax
+}
+
+// ColorBar returns a color bar displaying the palette and the dynamic
+// range of the hexagons.
+func (hb *HexBin) ColorBar() *ColorBar {
+	zmin, zmax := hb.zrange()
+	return &ColorBar{
+		Palette: hb.Palette,
+		Min:     zmin,
+		Max:     zmax,
+		LogZ:    hb.LogZ,
+	}
+}
+
+// check interfaces
+var _ plot.Plotter = (*HexBin)(nil)
+var _ plot.DataRanger = (*HexBin)(nil)
@@ -0,0 +1,50 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"log"
+
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distmv"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of displaying the density of a large data set,
+// binned in hexagons on the fly, with a logarithmic color scale.
+func ExampleHexBin() {
+	const npoints = 1000000
+
+	dist, ok := distmv.NewNormal(
+		[]float64{0, 1},
+		mat.NewSymDense(2, []float64{4, 1.5, 1.5, 2}),
+		rand.New(rand.NewSource(1234)),
+	)
+	if !ok {
+		log.Fatalf("error creating distmv.Normal")
+	}
+
+	hb := hplot.NewHexBin(40, -8, 8, -6, 8, nil)
+	hb.LogZ = true
+
+	v := make([]float64, 2)
+	for i := 0; i < npoints; i++ {
+		v = dist.Rand(v)
+		hb.Fill(v[0], v[1], 1)
+	}
+
+	p := hplot.NewColorBarPlot(hb)
+	p.Plot.Title.Text = "Hexbin (log-z)"
+	p.Plot.X.Label.Text = "x"
+	p.Plot.Y.Label.Text = "y"
+	p.Bar.X.Label.Text = "entries"
+
+	err := hplot.Save(p, 10*vg.Centimeter, 12*vg.Centimeter, "testdata/hexbin.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
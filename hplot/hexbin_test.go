@@ -0,0 +1,82 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"testing"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/cmpimg"
+	"gonum.org/v1/plot/plotter"
+)
+
+func TestHexBin(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleHexBin, t, "hexbin.png")
+}
+
+func TestHexBinFill(t *testing.T) {
+	hb := hplot.NewHexBin(10, 0, 10, 0, 10, nil)
+
+	for _, pt := range []struct{ x, y, w float64 }{
+		{0, 0, 1},
+		{0.1, 0.1, 2},   // same hexagon as (0,0)
+		{0.5, 0.85, 1},  // first hexagon of the shifted grid
+		{10, 10, 4},     // upper-right corner
+		{-1, 5, 10},     // outside
+		{5, 10.5, 10},   // outside
+		{5.02, 5.3, 3},  // middle
+		{4.98, 5.28, 1}, // middle, same hexagon
+	} {
+		hb.Fill(pt.x, pt.y, pt.w)
+	}
+
+	if got, want := hb.Entries(), int64(6); got != want {
+		t.Fatalf("invalid number of entries: got=%d, want=%d", got, want)
+	}
+
+	for _, tc := range []struct {
+		x, y float64
+		want float64
+	}{
+		{0, 0, 3},
+		{0.05, 0.02, 3},
+		{0.5, 0.85, 1},
+		{10, 10, 4},
+		{9.9, 9.95, 4},
+		{5, 5.3, 4},
+		{-1, 5, 0},
+		{3, 3, 0},
+	} {
+		if got := hb.Count(tc.x, tc.y); got != tc.want {
+			t.Errorf("invalid count at (%v, %v): got=%v, want=%v", tc.x, tc.y, got, tc.want)
+		}
+	}
+
+	cb := hb.ColorBar()
+	if cb.Min != 1 || cb.Max != 4 {
+		t.Fatalf("invalid color bar range: got=[%v, %v], want=[1, 4]", cb.Min, cb.Max)
+	}
+
+	xmin, xmax, ymin, ymax := hb.DataRange()
+	if xmin != 0 || xmax != 10 || ymin != 0 || ymax != 10 {
+		t.Fatalf("invalid data range: got=[%v, %v]x[%v, %v]", xmin, xmax, ymin, ymax)
+	}
+}
+
+func TestHexBinFromXYer(t *testing.T) {
+	xys := plotter.XYs{{X: 1, Y: 2}, {X: 3, Y: 2}, {X: 1, Y: 2}}
+	hb := hplot.NewHexBinFromXYer(xys, 4, nil)
+	if got, want := hb.Entries(), int64(3); got != want {
+		t.Fatalf("invalid number of entries: got=%d, want=%d", got, want)
+	}
+	if got, want := hb.Count(1, 2), 2.0; got != want {
+		t.Fatalf("invalid count: got=%v, want=%v", got, want)
+	}
+
+	xmin, xmax, ymin, ymax := hb.DataRange()
+	if xmin != 1 || xmax != 3 || ymin != 1.5 || ymax != 2.5 {
+		t.Fatalf("invalid data range: got=[%v, %v]x[%v, %v]", xmin, xmax, ymin, ymax)
+	}
+}
@@ -67,6 +67,11 @@ func (hsk HStackKind) yoffs(i int, ys []float64, v float64) {
 }
 
 // NewHStack creates a new histogram stack from the provided list of histograms.
+//
+// The fill colors of the histograms can be set with the WithFillColors option.
+// The total uncertainty band, enabled with WithBand(true), can be hatched
+// with the WithBandHatch option.
+//
 // NewHStack panicks if the list of histograms is empty.
 // NewHStack panicks if the histograms have different binning.
 func NewHStack(histos []*H1D, opts ...Options) *HStack {
@@ -84,21 +89,43 @@ func NewHStack(histos []*H1D, opts ...Options) *HStack {
 	copy(hstack.hs, histos)
 
 	ref := hstack.hs[0].Hist.Binning.Bins
-	for _, h := range hstack.hs {
+	for i, h := range hstack.hs {
 		h.LogY = cfg.log.y
 		hstack.checkBins(ref, h.Hist.Binning.Bins)
+		if len(cfg.colors) > 0 {
+			h.FillColor = cfg.colors[i%len(cfg.colors)]
+		}
 	}
 
 	if cfg.band {
-		hstack.Band = NewH1D(hstack.summedH1D(),
-			WithBand(true),
-			WithLogY(cfg.log.y),
-		).Band
+		opts := []Options{WithBand(true), WithLogY(cfg.log.y)}
+		if cfg.hatch != nil {
+			opts = append(opts, WithBandHatch(*cfg.hatch))
+		}
+		hstack.Band = NewH1D(hstack.summedH1D(), opts...).Band
 	}
 
 	return hstack
 }
 
+// AddLegend adds legend entries for the histograms of the stack, from the
+// top to the bottom of the stack, with the provided names.
+// names[i] is the name of the i-th histogram of the stack.
+// If band is not empty and the stack has a total uncertainty band, a legend
+// entry with that name is added for the band.
+func (hstack *HStack) AddLegend(leg *plot.Legend, names []string, band string) {
+	n := len(names)
+	if n > len(hstack.hs) {
+		n = len(hstack.hs)
+	}
+	for i := n - 1; i >= 0; i-- {
+		leg.Add(names[i], hstack.hs[i])
+	}
+	if band != "" && hstack.Band != nil {
+		leg.Add(band, hstack.Band)
+	}
+}
+
 // summedH1D returns the summed histogram
 func (hstack *HStack) summedH1D() *hbook.H1D {
 	bookHtot := hstack.hs[0].Hist
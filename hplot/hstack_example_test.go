@@ -346,6 +346,48 @@ func ExampleHStack_withLogY() {
 	}
 }
 
+func ExampleHStack_withHatchedBand() {
+	h1 := hbook.NewH1D(50, -8, 12)
+	h2 := hbook.NewH1D(50, -8, 12)
+	h3 := hbook.NewH1D(50, -8, 12)
+
+	const seed = 1234
+	fillH1(h1, 2000, -2, 1, seed)
+	fillH1(h2, 2000, +3, 3, seed)
+	fillH1(h3, 2000, +4, 1, seed)
+
+	hs := []*hplot.H1D{
+		hplot.NewH1D(h1),
+		hplot.NewH1D(h2),
+		hplot.NewH1D(h3),
+	}
+
+	hstack := hplot.NewHStack(
+		hs,
+		hplot.WithFillColors(
+			color.NRGBA{122, 195, 106, 255},
+			color.NRGBA{90, 155, 212, 255},
+			color.NRGBA{250, 167, 91, 255},
+		),
+		hplot.WithBand(true),
+		hplot.WithBandHatch(hplot.DefaultHatchStyle),
+	)
+
+	p := hplot.New()
+	p.Title.Text = "HStack - hatched total uncertainty"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.Add(hstack, hplot.NewGrid())
+	hstack.AddLegend(&p.Legend, []string{"h1", "h2", "h3"}, "Stat. unc.")
+	p.Legend.Top = true
+	p.Legend.Left = true
+
+	err := p.Save(15*vg.Centimeter, 10*vg.Centimeter, "testdata/hstack_hatch.png")
+	if err != nil {
+		log.Fatalf("error: %+v", err)
+	}
+}
+
 func fillH1(h *hbook.H1D, n int, mu, sigma float64, seed uint64) {
 	dist := distuv.Normal{
 		Mu:    mu,
@@ -20,6 +20,7 @@ func TestHStack(t *testing.T) {
 	checkPlot(cmpimg.CheckPlot)(ExampleHStack, t, "hstack.png")
 	checkPlot(cmpimg.CheckPlot)(ExampleHStack_withBand, t, "hstack_band.png")
 	checkPlot(cmpimg.CheckPlot)(ExampleHStack_withLogY, t, "hstack_logy.png")
+	checkPlot(cmpimg.CheckPlot)(ExampleHStack_withHatchedBand, t, "hstack_hatch.png")
 }
 
 func TestHStackPanic(t *testing.T) {
@@ -7,6 +7,7 @@ package htex_test
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"go-hep.org/x/hep/hplot"
 	"go-hep.org/x/hep/hplot/htex"
@@ -36,3 +37,31 @@ func ExampleGoHandler() {
 		log.Fatalf("error compiling latex: %+v", err)
 	}
 }
+
+func ExamplePool() {
+	pool := htex.NewPool(-1, htex.LuaLatex, 2*time.Minute)
+	defer pool.Close()
+
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("plot-%0d", i)
+		p := hplot.New()
+		p.Title.Text = name
+		p.X.Label.Text = "x"
+		p.Y.Label.Text = "y"
+
+		err := hplot.Save(
+			hplot.Figure(p, hplot.WithLatexHandler(pool)),
+			-1, -1, name+".tex",
+		)
+		if err != nil {
+			log.Fatalf("could not save plot: %+v", err)
+		}
+	}
+
+	err := pool.Wait()
+	if err != nil {
+		// err holds the diagnostics extracted from the LaTeX logs
+		// of all the failed compilations.
+		log.Fatalf("error compiling latex: %+v", err)
+	}
+}
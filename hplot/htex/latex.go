@@ -70,6 +70,12 @@ func (pdf *pdfLatex) CompileLatex(fname string) error {
 		)
 	}
 
+	return copyPDF(tmp, fname)
+}
+
+// copyPDF copies the PDF file generated from the fname .tex document
+// in the tmp directory, next to the .tex document.
+func copyPDF(tmp, fname string) error {
 	oname := fname[:len(fname)-len(".tex")] + ".pdf"
 	o, err := os.Create(oname)
 	if err != nil {
@@ -0,0 +1,299 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package htex
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LaTeX engines known to work with the documents generated by hplot.
+const (
+	PDFLatex = "pdflatex"
+	LuaLatex = "lualatex"
+	XeLatex  = "xelatex"
+)
+
+// Pool is a Latex handler that compiles Latex documents with a pool
+// of background workers.
+//
+// Each compilation is run in batch mode and is killed if it does not
+// complete within the timeout of the pool.
+// Failed compilations are reported by Wait as *Error values, holding
+// the output and the log file of the LaTeX engine.
+type Pool struct {
+	engine  string
+	timeout time.Duration
+
+	jobs    chan poolJob
+	pending sync.WaitGroup // queued and running jobs
+	workers sync.WaitGroup
+
+	mu     sync.Mutex
+	next   int // index of the next job
+	errs   []poolJobError
+	closed bool
+}
+
+type poolJob struct {
+	idx   int
+	fname string
+}
+
+type poolJobError struct {
+	idx int
+	err error
+}
+
+// NewPool creates a new Latex handler that compiles Latex documents
+// with n workers, running the engine executable.
+// The engine can be one of PDFLatex, LuaLatex or XeLatex.
+//
+// If n<=0, the number of workers will be set to the number of cores+1.
+// If timeout>0, each compilation is killed after the timeout duration.
+//
+// The workers are released by calling Close.
+func NewPool(n int, engine string, timeout time.Duration) *Pool {
+	if n <= 0 {
+		n = runtime.NumCPU() + 1
+	}
+
+	p := &Pool{
+		engine:  engine,
+		timeout: timeout,
+		jobs:    make(chan poolJob, n),
+	}
+
+	p.workers.Add(n)
+	for i := 0; i < n; i++ {
+		go p.run()
+	}
+
+	return p
+}
+
+func (p *Pool) run() {
+	defer p.workers.Done()
+	for job := range p.jobs {
+		err := p.compile(job.fname)
+		if err != nil {
+			p.mu.Lock()
+			p.errs = append(p.errs, poolJobError{idx: job.idx, err: err})
+			p.mu.Unlock()
+		}
+		p.pending.Done()
+	}
+}
+
+// CompileLatex queues the provided .tex document for compilation.
+// CompileLatex blocks when all the workers are busy and the queue is full.
+//
+// Compilation errors are reported by Wait.
+func (p *Pool) CompileLatex(fname string) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return fmt.Errorf("htex: could not compile %q: pool is closed", fname)
+	}
+	idx := p.next
+	p.next++
+	p.pending.Add(1)
+	p.mu.Unlock()
+
+	p.jobs <- poolJob{idx: idx, fname: fname}
+	return nil
+}
+
+// Wait waits for all the queued documents to be compiled.
+// Wait returns the errors of all the failed compilations since the
+// previous call to Wait, in the order the documents were queued.
+func (p *Pool) Wait() error {
+	p.pending.Wait()
+
+	p.mu.Lock()
+	errs := p.errs
+	p.errs = nil
+	p.mu.Unlock()
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].idx < errs[j].idx
+	})
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0].err
+	default:
+		list := make(errorList, len(errs))
+		for i, err := range errs {
+			list[i] = err.err
+		}
+		return list
+	}
+}
+
+// Close waits for all the queued documents to be compiled and
+// releases the workers of the pool.
+// Close returns the same errors than Wait.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	err := p.Wait()
+	close(p.jobs)
+	p.workers.Wait()
+	return err
+}
+
+func (p *Pool) compile(fname string) error {
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	tmp, err := os.MkdirTemp("", "hplot-htex-")
+	if err != nil {
+		return fmt.Errorf("htex: could not create tmp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	var (
+		stdout = new(bytes.Buffer)
+		args   = []string{
+			"-interaction=nonstopmode",
+			"-halt-on-error",
+			fmt.Sprintf("-output-directory=%s", tmp),
+			fname,
+		}
+	)
+
+	cmd := exec.CommandContext(ctx, p.engine, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stdout
+
+	err = cmd.Run()
+	if err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("timeout after %v: %w", p.timeout, ctx.Err())
+		}
+		base := strings.TrimSuffix(filepath.Base(fname), ".tex")
+		log, _ := os.ReadFile(filepath.Join(tmp, base+".log"))
+		return &Error{
+			File:   fname,
+			Engine: p.engine,
+			Output: stdout.Bytes(),
+			Log:    log,
+			Err:    err,
+		}
+	}
+
+	return copyPDF(tmp, fname)
+}
+
+// Error describes the failed compilation of a LaTeX document.
+type Error struct {
+	File   string // name of the .tex document
+	Engine string // LaTeX engine used to compile the document
+	Output []byte // standard output and error of the LaTeX engine
+	Log    []byte // content of the LaTeX log file, if any
+	Err    error  // underlying error
+}
+
+func (e *Error) Error() string {
+	o := new(strings.Builder)
+	fmt.Fprintf(o, "htex: could not compile %q with %s: %v", e.File, e.Engine, e.Err)
+	diags := e.Diagnostics()
+	if len(diags) == 0 && len(e.Output) > 0 {
+		fmt.Fprintf(o, "\n%s", bytes.TrimSpace(e.Output))
+	}
+	for _, diag := range diags {
+		fmt.Fprintf(o, "\n%s", diag)
+	}
+	return o.String()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Diagnostics returns the error messages reported in the LaTeX log file,
+// or in the output of the LaTeX engine if there is no log file.
+//
+// Each diagnostic is made of the error line, starting with "!", and of
+// the "l.<line>" line locating the error in the .tex document, if any.
+func (e *Error) Diagnostics() []string {
+	log := e.Log
+	if len(log) == 0 {
+		log = e.Output
+	}
+
+	var (
+		diags []string
+		diag  []string
+		scan  = bufio.NewScanner(bytes.NewReader(log))
+	)
+	flush := func() {
+		if len(diag) > 0 {
+			diags = append(diags, strings.Join(diag, "\n"))
+		}
+		diag = nil
+	}
+
+	// number of lines after the error message where the location
+	// of the error is looked for.
+	const ctx = 5
+	n := 0
+	for scan.Scan() {
+		line := scan.Text()
+		switch {
+		case strings.HasPrefix(line, "!"):
+			flush()
+			diag = []string{line}
+			n = 0
+		case diag != nil && strings.HasPrefix(line, "l."):
+			diag = append(diag, line)
+			flush()
+		case diag != nil:
+			n++
+			if n >= ctx {
+				flush()
+			}
+		}
+	}
+	flush()
+	return diags
+}
+
+// errorList holds the errors of multiple compilations.
+type errorList []error
+
+func (errs errorList) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+var (
+	_ Handler = (*Pool)(nil)
+	_ error   = (*Error)(nil)
+)
@@ -0,0 +1,186 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package htex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeEngine creates a shell script mimicking a LaTeX engine, running
+// the provided body with the $out and $base variables set to the output
+// directory and to the base name of the .tex document.
+func fakeEngine(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skipf("fake LaTeX engines need a POSIX shell")
+	}
+
+	fname := filepath.Join(dir, name)
+	err := os.WriteFile(fname, []byte(`#!/bin/sh
+for arg; do
+	case "$arg" in
+	-output-directory=*) out="${arg#-output-directory=}";;
+	-*) ;;
+	*) tex="$arg";;
+	esac
+done
+base=$(basename "$tex" .tex)
+`+body+"\n"), 0755)
+	if err != nil {
+		t.Fatalf("could not create fake engine: %+v", err)
+	}
+	return fname
+}
+
+func TestPool(t *testing.T) {
+	dir := t.TempDir()
+	engine := fakeEngine(t, dir, "fakelatex", `echo "%PDF-$base" > "$out/$base.pdf"`)
+
+	pool := NewPool(3, engine, 10*time.Second)
+	const n = 10
+	for i := 0; i < n; i++ {
+		err := pool.CompileLatex(filepath.Join(dir, fmt.Sprintf("plot-%02d.tex", i)))
+		if err != nil {
+			t.Fatalf("could not queue document %d: %+v", i, err)
+		}
+	}
+
+	err := pool.Close()
+	if err != nil {
+		t.Fatalf("could not compile documents: %+v", err)
+	}
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("plot-%02d", i)
+		raw, err := os.ReadFile(filepath.Join(dir, name+".pdf"))
+		if err != nil {
+			t.Fatalf("could not read PDF %d: %+v", i, err)
+		}
+		if got, want := string(raw), "%PDF-"+name+"\n"; got != want {
+			t.Fatalf("invalid PDF %d content: got=%q, want=%q", i, got, want)
+		}
+	}
+
+	err = pool.CompileLatex(filepath.Join(dir, "plot.tex"))
+	if err == nil {
+		t.Fatalf("expected an error compiling with a closed pool")
+	}
+}
+
+func TestPoolErrors(t *testing.T) {
+	dir := t.TempDir()
+	engine := fakeEngine(t, dir, "fakelatex", `cat > "$out/$base.log" <<LOG
+This is a fake TeX engine.
+! Undefined control sequence.
+l.12 \foo
+         {bar}
+Here is how much of TeX's memory you used:
+LOG
+echo "fatal error in $base"
+exit 1`)
+
+	pool := NewPool(2, engine, 0)
+	defer pool.Close()
+
+	for _, name := range []string{"p1.tex", "p2.tex"} {
+		err := pool.CompileLatex(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("could not queue document %q: %+v", name, err)
+		}
+	}
+
+	err := pool.Wait()
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	errs, ok := err.(errorList)
+	if !ok {
+		t.Fatalf("invalid error type %T", err)
+	}
+	if got, want := len(errs), 2; got != want {
+		t.Fatalf("invalid number of errors: got=%d, want=%d", got, want)
+	}
+
+	for i, err := range errs {
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Fatalf("invalid error type %T", err)
+		}
+		name := filepath.Join(dir, fmt.Sprintf("p%d.tex", i+1))
+		if got, want := e.File, name; got != want {
+			t.Fatalf("invalid file: got=%q, want=%q", got, want)
+		}
+		if got, want := strings.Join(e.Diagnostics(), "\n"), "! Undefined control sequence.\nl.12 \\foo"; got != want {
+			t.Fatalf("invalid diagnostics:\ngot= %q\nwant=%q", got, want)
+		}
+		want := fmt.Sprintf("htex: could not compile %q with %s: exit status 1\n! Undefined control sequence.\nl.12 \\foo", name, engine)
+		if got := e.Error(); got != want {
+			t.Fatalf("invalid error message:\ngot= %q\nwant=%q", got, want)
+		}
+	}
+
+	// errors are reset by Wait.
+	err = pool.Wait()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+}
+
+func TestPoolTimeout(t *testing.T) {
+	dir := t.TempDir()
+	engine := fakeEngine(t, dir, "fakelatex", `exec sleep 10`)
+
+	pool := NewPool(1, engine, 100*time.Millisecond)
+	err := pool.CompileLatex(filepath.Join(dir, "slow.tex"))
+	if err != nil {
+		t.Fatalf("could not queue document: %+v", err)
+	}
+
+	start := time.Now()
+	err = pool.Close()
+	if err == nil {
+		t.Fatalf("expected a timeout error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("invalid error: %+v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("compilation not killed after timeout (%v)", d)
+	}
+}
+
+func TestPoolEngineNotThere(t *testing.T) {
+	pool := NewPool(-1, "pdflatex-not-there", 0)
+	defer pool.Close()
+
+	err := pool.CompileLatex("plot.tex")
+	if err != nil {
+		t.Fatalf("could not queue document: %+v", err)
+	}
+
+	err = pool.Wait()
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("invalid error type %T", err)
+	}
+	if got, want := e.Engine, "pdflatex-not-there"; got != want {
+		t.Fatalf("invalid engine: got=%q, want=%q", got, want)
+	}
+	if e.Diagnostics() != nil {
+		t.Fatalf("unexpected diagnostics: %q", e.Diagnostics())
+	}
+}
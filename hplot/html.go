@@ -0,0 +1,505 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"reflect"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgsvg"
+)
+
+// htmlCanvas implements the vg.CanvasWriterTo interface, writing a
+// self-contained interactive HTML page that embeds the SVG rendering
+// of the plots.
+//
+// The HTML page provides zoom (with the mouse wheel) and pan (by dragging)
+// of the figure, and displays the data coordinates under the mouse cursor
+// for each plot of the figure.
+// Double-clicking resets the view.
+//
+// Each plotter added to a Plot is drawn into its own SVG group, so the
+// series of the figure can be shown or hidden from the legend of the page.
+// Hovering a data point displays its values.
+type htmlCanvas struct {
+	*vgsvg.Canvas // current layer
+
+	w, h   vg.Length
+	plots  []htmlPlot
+	series []htmlSeries
+	layers []htmlLayer
+}
+
+// htmlLayer is a SVG canvas holding either the drawing of a series, or
+// the drawing between two series.
+type htmlLayer struct {
+	series int // index of the series, -1 if none
+	c      *vgsvg.Canvas
+}
+
+// htmlSeries describes the data of a series drawn on a htmlCanvas.
+type htmlSeries struct {
+	Plot   int          `json:"plot"` // index of the plot of the series
+	Name   string       `json:"name"`
+	Points [][2]float64 `json:"points"` // data points, in data coordinates
+
+	p plot.Plotter
+}
+
+// htmlPlot describes the data area of a plot drawn on a htmlCanvas.
+type htmlPlot struct {
+	// Rectangle of the data area, in SVG coordinates.
+	X0 float64 `json:"x0"`
+	Y0 float64 `json:"y0"`
+	X1 float64 `json:"x1"`
+	Y1 float64 `json:"y1"`
+
+	// Ranges of the axes.
+	XMin float64 `json:"xmin"`
+	XMax float64 `json:"xmax"`
+	YMin float64 `json:"ymin"`
+	YMax float64 `json:"ymax"`
+
+	XLog bool `json:"xlog"`
+	YLog bool `json:"ylog"`
+}
+
+func newHTMLCanvas(w, h vg.Length) *htmlCanvas {
+	c := &htmlCanvas{w: w, h: h}
+	c.push(-1)
+	return c
+}
+
+// push starts a new layer for the provided series index.
+func (c *htmlCanvas) push(series int) {
+	c.Canvas = vgsvg.New(c.w, c.h)
+	c.layers = append(c.layers, htmlLayer{series: series, c: c.Canvas})
+}
+
+// beginSeries starts the drawing of the provided plotter.
+func (c *htmlCanvas) beginSeries(p plot.Plotter) {
+	c.series = append(c.series, htmlSeries{
+		Plot:   len(c.plots),
+		Points: htmlPoints(p),
+		p:      p,
+	})
+	c.push(len(c.series) - 1)
+}
+
+// endSeries ends the drawing of the current series.
+func (c *htmlCanvas) endSeries() {
+	c.push(-1)
+}
+
+// register records the data area of the provided plot, and names its
+// series after the entries of its legend.
+func (c *htmlCanvas) register(p *plot.Plot, dc draw.Canvas) {
+	names := legendNames(&p.Legend)
+	for i := range c.series {
+		s := &c.series[i]
+		if s.Plot != len(c.plots) {
+			continue
+		}
+		if v := reflect.ValueOf(s.p); v.Kind() == reflect.Ptr {
+			s.Name = names[v.Pointer()]
+		}
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("series %d", i)
+		}
+	}
+
+	_, xlog := p.X.Scale.(plot.LogScale)
+	_, ylog := p.Y.Scale.(plot.LogScale)
+	c.plots = append(c.plots, htmlPlot{
+		X0:   dc.Min.X.Points(),
+		Y0:   (c.h - dc.Max.Y).Points(),
+		X1:   dc.Max.X.Points(),
+		Y1:   (c.h - dc.Min.Y).Points(),
+		XMin: p.X.Min,
+		XMax: p.X.Max,
+		YMin: p.Y.Min,
+		YMax: p.Y.Max,
+		XLog: xlog,
+		YLog: ylog,
+	})
+}
+
+// htmlCanvasOf returns the htmlCanvas underlying the provided canvas,
+// if any.
+func htmlCanvasOf(c vg.Canvas) (*htmlCanvas, bool) {
+	for {
+		switch cc := c.(type) {
+		case *htmlCanvas:
+			return cc, true
+		case draw.Canvas:
+			c = cc.Canvas
+		case *draw.Canvas:
+			c = cc.Canvas
+		default:
+			return nil, false
+		}
+	}
+}
+
+// legendNames returns the names of the entries of the provided legend,
+// indexed by the address of the plotters they describe.
+//
+// plot.Legend does not export its entries: they are inspected via reflect.
+func legendNames(l *plot.Legend) map[uintptr]string {
+	entries := reflect.ValueOf(l).Elem().FieldByName("entries")
+	if entries.Kind() != reflect.Slice {
+		return nil
+	}
+
+	names := make(map[uintptr]string, entries.Len())
+	for i := 0; i < entries.Len(); i++ {
+		var (
+			entry  = entries.Index(i)
+			name   = entry.FieldByName("text")
+			thumbs = entry.FieldByName("thumbs")
+		)
+		if name.Kind() != reflect.String || thumbs.Kind() != reflect.Slice {
+			continue
+		}
+		for j := 0; j < thumbs.Len(); j++ {
+			v := thumbs.Index(j)
+			if v.Kind() == reflect.Interface {
+				v = v.Elem()
+			}
+			if v.Kind() != reflect.Ptr {
+				continue
+			}
+			if _, dup := names[v.Pointer()]; !dup {
+				names[v.Pointer()] = name.String()
+			}
+		}
+	}
+	return names
+}
+
+// htmlPoints returns the finite data points of the provided plotter, if any.
+func htmlPoints(p plot.Plotter) [][2]float64 {
+	pts := [][2]float64{}
+	add := func(x, y float64) {
+		if math.IsNaN(x) || math.IsInf(x, 0) || math.IsNaN(y) || math.IsInf(y, 0) {
+			return
+		}
+		pts = append(pts, [2]float64{x, y})
+	}
+
+	switch p := p.(type) {
+	case *H1D:
+		for _, bin := range p.Hist.Binning.Bins {
+			add(bin.XMid(), bin.SumW())
+		}
+	case *S2D:
+		for i := 0; i < p.Data.Len(); i++ {
+			add(p.Data.XY(i))
+		}
+	case plotter.XYer:
+		for i := 0; i < p.Len(); i++ {
+			add(p.XY(i))
+		}
+	}
+	return pts
+}
+
+// WriteTo writes the HTML page to the provided writer.
+func (c *htmlCanvas) WriteTo(w io.Writer) (int64, error) {
+	svg := new(bytes.Buffer)
+	for i, layer := range c.layers {
+		buf := new(bytes.Buffer)
+		_, err := layer.c.WriteTo(buf)
+		if err != nil {
+			return 0, fmt.Errorf("hplot: could not render SVG: %w", err)
+		}
+		raw := buf.Bytes()
+		end := bytes.LastIndex(raw, []byte("</svg>"))
+		if i == 0 {
+			// drop the XML prolog, not allowed inside an HTML document.
+			if i := bytes.Index(raw, []byte("<svg")); i > 0 {
+				raw = raw[i:]
+				end -= i
+			}
+			svg.Write(raw[:end])
+			continue
+		}
+
+		// only keep the content of the layer, inside the <svg> element.
+		beg := bytes.Index(raw, []byte("<svg"))
+		beg += bytes.IndexByte(raw[beg:], '>') + 1
+		if layer.series < 0 {
+			svg.Write(raw[beg:end])
+			continue
+		}
+		fmt.Fprintf(svg, "<g id=\"hplot-series-%d\" class=\"hplot-series\">", layer.series)
+		svg.Write(raw[beg:end])
+		svg.WriteString("</g>\n")
+	}
+	svg.WriteString("</svg>\n")
+
+	plots, err := json.Marshal(c.plots)
+	if err != nil {
+		return 0, fmt.Errorf("hplot: could not encode plots layout: %w", err)
+	}
+
+	series := c.series
+	if series == nil {
+		series = []htmlSeries{}
+	}
+	data, err := json.Marshal(series)
+	if err != nil {
+		return 0, fmt.Errorf("hplot: could not encode plots series: %w", err)
+	}
+
+	out := new(bytes.Buffer)
+	err = htmlTmpl.Execute(out, struct {
+		SVG    template.HTML
+		Plots  template.JS
+		Series template.JS
+	}{
+		SVG:    template.HTML(svg.Bytes()),
+		Plots:  template.JS(plots),
+		Series: template.JS(data),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("hplot: could not generate HTML page: %w", err)
+	}
+
+	return out.WriteTo(w)
+}
+
+var htmlTmpl = template.Must(template.New("hplot").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>hplot</title>
+<style>
+#hplot-fig { display: inline-block; position: relative; }
+#hplot-fig svg { cursor: crosshair; }
+#hplot-tip {
+	position: absolute; display: none; pointer-events: none;
+	padding: 2px 6px; font: 12px monospace;
+	background: rgba(255, 255, 255, 0.9); border: 1px solid #888;
+}
+#hplot-legend { font: 12px sans-serif; }
+#hplot-legend label { margin-right: 1em; cursor: pointer; }
+</style>
+</head>
+<body>
+<div id="hplot-fig">
+{{.SVG}}
+<div id="hplot-tip"></div>
+</div>
+<div id="hplot-legend"></div>
+<script>
+(function() {
+	var plots = {{.Plots}};
+	var series = {{.Series}};
+	var legend = document.getElementById("hplot-legend");
+	var fig = document.getElementById("hplot-fig");
+	var tip = document.getElementById("hplot-tip");
+	var svg = fig.querySelector("svg");
+	var vb0 = svg.getAttribute("viewBox").split(" ").map(Number);
+	var vb = vb0.slice();
+	var drag = null;
+
+	function setViewBox() { svg.setAttribute("viewBox", vb.join(" ")); }
+
+	function svgPoint(evt) {
+		var pt = svg.createSVGPoint();
+		pt.x = evt.clientX;
+		pt.y = evt.clientY;
+		return pt.matrixTransform(svg.getScreenCTM().inverse());
+	}
+
+	function coord(v, v0, v1, min, max, log) {
+		var f = (v - v0) / (v1 - v0);
+		if (log) {
+			return Math.exp(Math.log(min) + f * (Math.log(max) - Math.log(min)));
+		}
+		return min + f * (max - min);
+	}
+
+	function pos(v, v0, v1, min, max, log) {
+		if (log) {
+			if (v <= 0) {
+				return NaN;
+			}
+			return v0 + (Math.log(v) - Math.log(min)) / (Math.log(max) - Math.log(min)) * (v1 - v0);
+		}
+		return v0 + (v - min) / (max - min) * (v1 - v0);
+	}
+
+	// nearest returns the visible data point of the i-th plot closest to
+	// the provided SVG point, if any.
+	function nearest(i, pt) {
+		var p = plots[i];
+		var best = null;
+		var dmax = 6 * vb[2] / vb0[2];
+		for (var j = 0; j < series.length; j++) {
+			var s = series[j];
+			if (s.plot !== i || s.hidden) {
+				continue;
+			}
+			for (var k = 0; k < s.points.length; k++) {
+				var xy = s.points[k];
+				var dx = pos(xy[0], p.x0, p.x1, p.xmin, p.xmax, p.xlog) - pt.x;
+				var dy = pos(xy[1], p.y1, p.y0, p.ymin, p.ymax, p.ylog) - pt.y;
+				var d = Math.sqrt(dx * dx + dy * dy);
+				if (d <= dmax) {
+					dmax = d;
+					best = {name: s.name, x: xy[0], y: xy[1]};
+				}
+			}
+		}
+		return best;
+	}
+
+	series.forEach(function(s, i) {
+		var lbl = document.createElement("label");
+		var box = document.createElement("input");
+		box.type = "checkbox";
+		box.checked = true;
+		box.setAttribute("data-series", i);
+		box.addEventListener("change", function() {
+			s.hidden = !box.checked;
+			var g = document.getElementById("hplot-series-" + i);
+			if (g !== null) {
+				g.style.display = s.hidden ? "none" : "";
+			}
+		});
+		lbl.appendChild(box);
+		lbl.appendChild(document.createTextNode(s.name));
+		legend.appendChild(lbl);
+	});
+
+	svg.addEventListener("wheel", function(evt) {
+		evt.preventDefault();
+		var pt = svgPoint(evt);
+		var k = evt.deltaY < 0 ? 0.8 : 1.25;
+		vb[0] = pt.x - (pt.x - vb[0]) * k;
+		vb[1] = pt.y - (pt.y - vb[1]) * k;
+		vb[2] *= k;
+		vb[3] *= k;
+		setViewBox();
+	});
+
+	svg.addEventListener("mousedown", function(evt) {
+		drag = svgPoint(evt);
+	});
+
+	window.addEventListener("mouseup", function() { drag = null; });
+
+	svg.addEventListener("dblclick", function() {
+		vb = vb0.slice();
+		setViewBox();
+	});
+
+	svg.addEventListener("mouseleave", function() { tip.style.display = "none"; });
+
+	svg.addEventListener("mousemove", function(evt) {
+		var pt = svgPoint(evt);
+		if (drag !== null) {
+			vb[0] -= pt.x - drag.x;
+			vb[1] -= pt.y - drag.y;
+			setViewBox();
+			return;
+		}
+		for (var i = 0; i < plots.length; i++) {
+			var p = plots[i];
+			if (pt.x < p.x0 || p.x1 < pt.x || pt.y < p.y0 || p.y1 < pt.y) {
+				continue;
+			}
+			var x = coord(pt.x, p.x0, p.x1, p.xmin, p.xmax, p.xlog);
+			var y = coord(pt.y, p.y1, p.y0, p.ymin, p.ymax, p.ylog);
+			var box = fig.getBoundingClientRect();
+			var hit = nearest(i, pt);
+			if (hit !== null) {
+				tip.textContent = hit.name + ": x=" + hit.x.toPrecision(4) + ", y=" + hit.y.toPrecision(4);
+			} else {
+				tip.textContent = "x=" + x.toPrecision(4) + ", y=" + y.toPrecision(4);
+			}
+			tip.style.left = (evt.clientX - box.left + 12) + "px";
+			tip.style.top = (evt.clientY - box.top + 12) + "px";
+			tip.style.display = "block";
+			return;
+		}
+		tip.style.display = "none";
+	});
+})();
+</script>
+</body>
+</html>
+`))
+
+// withSeries replaces the plotters of p with plotters grouping their
+// drawing into series, and returns a function restoring them.
+// It is used while drawing p on a htmlCanvas, so the plotters of p are
+// left untouched for other formats and for users of p.
+//
+// The plotters are left as is if some were added directly to the
+// underlying plot.Plot, as hplot.Plot does not know about them.
+func (p *Plot) withSeries() func() {
+	n := reflect.ValueOf(p.Plot).Elem().FieldByName("plotters")
+	if n.Kind() != reflect.Slice || n.Len() != len(p.plotters) {
+		return func() {}
+	}
+
+	saved := *p.Plot
+	q := plot.Plot{
+		Title:           saved.Title,
+		BackgroundColor: saved.BackgroundColor,
+		X:               saved.X,
+		Y:               saved.Y,
+		Legend:          saved.Legend,
+		TextHandler:     saved.TextHandler,
+	}
+	for _, d := range p.plotters {
+		q.Add(seriesPlotter{d})
+	}
+	*p.Plot = q
+	return func() { *p.Plot = saved }
+}
+
+// seriesPlotter wraps the plotters of a Plot drawn on a htmlCanvas, so
+// their drawing is grouped into a series.
+type seriesPlotter struct {
+	plot.Plotter
+}
+
+// Plot implements the plot.Plotter interface.
+func (s seriesPlotter) Plot(c draw.Canvas, p *plot.Plot) {
+	hc, ok := htmlCanvasOf(c.Canvas)
+	if !ok {
+		s.Plotter.Plot(c, p)
+		return
+	}
+	hc.beginSeries(s.Plotter)
+	defer hc.endSeries()
+	s.Plotter.Plot(c, p)
+}
+
+// GlyphBoxes implements the plot.GlyphBoxer interface.
+func (s seriesPlotter) GlyphBoxes(p *plot.Plot) []plot.GlyphBox {
+	if gb, ok := s.Plotter.(plot.GlyphBoxer); ok {
+		return gb.GlyphBoxes(p)
+	}
+	return nil
+}
+
+var (
+	_ vg.CanvasWriterTo = (*htmlCanvas)(nil)
+	_ plot.GlyphBoxer   = (*seriesPlotter)(nil)
+)
@@ -0,0 +1,167 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !cross_compile
+
+package hwin
+
+import (
+	"sync"
+	"time"
+
+	"gioui.org/app"
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/io/system"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vggio"
+)
+
+// Main runs the event loop of the windowing system.
+// Main must be called from the main goroutine, and never returns.
+func Main() {
+	app.Main()
+}
+
+// Window displays a plot, redrawing it periodically.
+type Window struct {
+	w   *app.Window
+	cfg *config
+
+	mu  sync.Mutex // protects the plot and its data
+	plt hplot.Drawer
+
+	quit chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Show opens a new window displaying the plot p.
+//
+// The window is redrawn at the refresh period of the window, or whenever
+// it is resized or exposed.
+// The data of the plot must only be modified via Update while the window
+// is opened.
+// The window is closed by pressing 'q' or 'Escape', or by calling Close.
+//
+// Show does not block, but the windows are only displayed once Main
+// has been called.
+func Show(p hplot.Drawer, opts ...Option) *Window {
+	cfg := newConfig(opts)
+	win := &Window{
+		w: app.NewWindow(
+			app.Title(cfg.title),
+			app.Size(
+				unit.Px(float32(cfg.w.Dots(cfg.dpi))),
+				unit.Px(float32(cfg.h.Dots(cfg.dpi))),
+			),
+		),
+		cfg:  cfg,
+		plt:  p,
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go win.run()
+	return win
+}
+
+// Update runs f with exclusive access to the plot and its data.
+// Update should be used to fill the histograms and data series
+// displayed by the window.
+func (win *Window) Update(f func()) {
+	win.mu.Lock()
+	defer win.mu.Unlock()
+	f()
+}
+
+// Redraw requests the window to be redrawn, independently of its
+// refresh period.
+func (win *Window) Redraw() {
+	win.w.Invalidate()
+}
+
+// Close requests the window to be closed.
+// Done is closed once the window has been destroyed.
+func (win *Window) Close() error {
+	win.once.Do(func() { close(win.quit) })
+	return nil
+}
+
+// Done returns a channel that is closed when the window has been closed.
+func (win *Window) Done() <-chan struct{} {
+	return win.done
+}
+
+func (win *Window) run() {
+	defer close(win.done)
+
+	var tick <-chan time.Time
+	if win.cfg.refresh > 0 {
+		t := time.NewTicker(win.cfg.refresh)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	for {
+		select {
+		case e := <-win.w.Events():
+			if win.handle(e) == winStop {
+				return
+			}
+		case <-tick:
+			win.w.Invalidate()
+		case <-win.quit:
+			win.w.Close()
+			// drain the events until the window is destroyed.
+			for e := range win.w.Events() {
+				if _, ok := e.(system.DestroyEvent); ok {
+					return
+				}
+			}
+			return
+		}
+	}
+}
+
+type winState byte
+
+const (
+	winContinue winState = iota
+	winStop
+)
+
+func (win *Window) handle(e event.Event) winState {
+	switch e := e.(type) {
+	case system.DestroyEvent:
+		return winStop
+
+	case system.FrameEvent:
+		var (
+			dpi = win.cfg.dpi
+			w   = vg.Length(float64(e.Size.X)/dpi) * vg.Inch
+			h   = vg.Length(float64(e.Size.Y)/dpi) * vg.Inch
+			cnv = vggio.New(
+				layout.NewContext(new(op.Ops), e),
+				w, h,
+				vggio.UseDPI(int(dpi)),
+			)
+		)
+		win.mu.Lock()
+		win.plt.Draw(draw.New(cnv))
+		win.mu.Unlock()
+		e.Frame(cnv.Paint())
+
+	case key.Event:
+		switch e.Name {
+		case "Q", key.NameEscape:
+			win.w.Close()
+		}
+	}
+	return winContinue
+}
@@ -0,0 +1,79 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !cross_compile
+
+package hwin
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"gioui.org/io/router"
+	"gioui.org/io/system"
+	"gioui.org/op"
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/vg"
+)
+
+func TestConfig(t *testing.T) {
+	cfg := newConfig(nil)
+	if got, want := cfg.refresh, 500*time.Millisecond; got != want {
+		t.Fatalf("invalid default refresh: got=%v, want=%v", got, want)
+	}
+	if w, h := hplot.Dims(-1, -1); cfg.w != w || cfg.h != h {
+		t.Fatalf("invalid default size: got=(%v, %v), want=(%v, %v)", cfg.w, cfg.h, w, h)
+	}
+
+	cfg = newConfig([]Option{
+		WithTitle("monitoring"),
+		WithSize(10*vg.Centimeter, 5*vg.Centimeter),
+		WithDPI(72),
+		WithRefresh(time.Second),
+	})
+	if got, want := *cfg, (config{
+		title:   "monitoring",
+		w:       10 * vg.Centimeter,
+		h:       5 * vg.Centimeter,
+		dpi:     72,
+		refresh: time.Second,
+	}); got != want {
+		t.Fatalf("invalid config:\ngot= %+v\nwant=%+v", got, want)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	h := hbook.NewH1D(10, 0, 10)
+	p := hplot.New()
+	p.Add(hplot.NewH1D(h))
+
+	win := Show(p, WithRefresh(0))
+	defer win.Close()
+
+	win.Update(func() {
+		for i := 0; i < 10; i++ {
+			h.Fill(float64(i), 1)
+		}
+	})
+
+	var painted bool
+	rc := win.handle(system.FrameEvent{
+		Size:  image.Pt(400, 250),
+		Frame: func(*op.Ops) { painted = true },
+		Queue: new(router.Router),
+	})
+	if got, want := rc, winContinue; got != want {
+		t.Fatalf("invalid window state: got=%v, want=%v", got, want)
+	}
+	if !painted {
+		t.Fatalf("frame was not painted")
+	}
+
+	rc = win.handle(system.DestroyEvent{})
+	if got, want := rc, winStop; got != want {
+		t.Fatalf("invalid window state: got=%v, want=%v", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build cross_compile
+
+package hwin
+
+import (
+	"sync"
+
+	"go-hep.org/x/hep/hplot"
+)
+
+// Main blocks forever.
+// No window can be displayed in cross-compiled programs.
+func Main() {
+	select {}
+}
+
+// Window displays a plot.
+// No window can be displayed in cross-compiled programs: the windows
+// created by Show are closed right away.
+type Window struct {
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+// Show returns a closed window.
+func Show(p hplot.Drawer, opts ...Option) *Window {
+	_ = newConfig(opts)
+	win := &Window{done: make(chan struct{})}
+	close(win.done)
+	return win
+}
+
+// Update runs f with exclusive access to the plot and its data.
+func (win *Window) Update(f func()) {
+	win.mu.Lock()
+	defer win.mu.Unlock()
+	f()
+}
+
+// Redraw is a no-op.
+func (win *Window) Redraw() {}
+
+// Close is a no-op.
+func (win *Window) Close() error { return nil }
+
+// Done returns a channel that is closed when the window has been closed.
+func (win *Window) Done() <-chan struct{} {
+	return win.done
+}
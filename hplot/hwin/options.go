@@ -0,0 +1,94 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package hwin displays hplot plots in interactive windows, redrawn
+// periodically to follow the data being filled.
+//
+// Package hwin is meant for the online monitoring of long event loops:
+//
+//	func main() {
+//		go run()
+//		hwin.Main()
+//	}
+//
+//	func run() {
+//		h := hbook.NewH1D(100, -5, 5)
+//		p := hplot.New()
+//		p.Add(hplot.NewH1D(h))
+//
+//		win := hwin.Show(p, hwin.WithRefresh(200*time.Millisecond))
+//		for i := 0; i < nevts; i++ {
+//			v := process(i)
+//			win.Update(func() { h.Fill(v, 1) })
+//		}
+//		<-win.Done()
+//		os.Exit(0)
+//	}
+package hwin // import "go-hep.org/x/hep/hplot/hwin"
+
+import (
+	"time"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/vg"
+)
+
+// Option configures a window.
+type Option func(cfg *config)
+
+type config struct {
+	title   string
+	w, h    vg.Length
+	dpi     float64
+	refresh time.Duration
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		title:   "hplot",
+		dpi:     96,
+		refresh: 500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	cfg.w, cfg.h = hplot.Dims(cfg.w, cfg.h)
+	return cfg
+}
+
+// WithTitle sets the title of the window.
+func WithTitle(title string) Option {
+	return func(cfg *config) {
+		cfg.title = title
+	}
+}
+
+// WithSize sets the initial size of the window.
+//
+// If w or h are <= 0, the value is chosen such that it follows the Golden Ratio.
+// If w and h are <= 0, the values are chosen such that they follow the Golden Ratio
+// (the width is defaulted to vgimg.DefaultWidth).
+func WithSize(w, h vg.Length) Option {
+	return func(cfg *config) {
+		cfg.w = w
+		cfg.h = h
+	}
+}
+
+// WithDPI sets the resolution used to draw the plot in the window.
+// The default resolution is 96 DPI.
+func WithDPI(dpi float64) Option {
+	return func(cfg *config) {
+		cfg.dpi = dpi
+	}
+}
+
+// WithRefresh sets the period at which the window is redrawn.
+// The default period is 500ms.
+// If d <= 0, the window is only redrawn when it is resized or exposed.
+func WithRefresh(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.refresh = d
+	}
+}
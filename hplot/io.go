@@ -5,6 +5,7 @@
 package hplot
 
 import (
+	"bytes"
 	"fmt"
 	"image/color"
 	"io"
@@ -32,11 +33,22 @@ type Drawer interface {
 //
 // Supported extensions are:
 //
-//  .eps, .jpg, .jpeg, .pdf, .png, .svg, .tex, .tif and .tiff.
+//  .eps, .html, .jpg, .jpeg, .pdf, .png, .svg, .tex, .tif and .tiff.
+//
+// The .html format produces a self-contained interactive HTML page,
+// embedding the SVG rendering of the plot, with zoom/pan support and
+// the display of the data coordinates under the mouse cursor.
+// Each plotter can be shown or hidden from the legend of the page, and
+// hovering a data point displays its values.
 //
 // If w or h are <= 0, the value is chosen such that it follows the Golden Ratio.
 // If w and h are <= 0, the values are chosen such that they follow the Golden Ratio
 // (the width is defaulted to vgimg.DefaultWidth).
+//
+// The plot is rendered once per format, and written to all the files of
+// that format.
+// Save tries to write all the files, even if some of them could not be
+// written, and returns an error combining all the failures.
 func Save(p Drawer, w, h vg.Length, fnames ...string) (err error) {
 	if len(fnames) == 0 {
 		return fmt.Errorf("hplot: need at least 1 file name")
@@ -44,29 +56,31 @@ func Save(p Drawer, w, h vg.Length, fnames ...string) (err error) {
 
 	w, h = Dims(w, h)
 
+	// the figure is rendered only once per format.
+	rendered := make(map[string][]byte)
+
 	save := func(file string) error {
 		format := strings.ToLower(filepath.Ext(file))
 		if len(format) != 0 {
 			format = format[1:]
 		}
 
-		dc, err := WriterTo(p, w, h, format)
-		if err != nil {
-			return err
-		}
-
-		f, err := os.Create(file)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-
-		_, err = dc.WriteTo(f)
-		if err != nil {
-			return err
+		raw, ok := rendered[format]
+		if !ok {
+			dc, err := WriterTo(p, w, h, format)
+			if err != nil {
+				return err
+			}
+			buf := new(bytes.Buffer)
+			_, err = dc.WriteTo(buf)
+			if err != nil {
+				return err
+			}
+			raw = buf.Bytes()
+			rendered[format] = raw
 		}
 
-		err = f.Close()
+		err := os.WriteFile(file, raw, 0644)
 		if err != nil {
 			return err
 		}
@@ -82,14 +96,39 @@ func Save(p Drawer, w, h vg.Length, fnames ...string) (err error) {
 		return nil
 	}
 
+	var errs saveErrors
 	for _, file := range fnames {
 		err := save(file)
 		if err != nil {
-			return fmt.Errorf("hplot: could not save plot: %w", err)
+			errs = append(errs, fmt.Errorf("hplot: could not save plot: %w", err))
 		}
 	}
 
-	return nil
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errs
+	}
+}
+
+// saveErrors holds the errors that occurred while saving a plot
+// to multiple files.
+type saveErrors []error
+
+func (errs saveErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors that occurred while saving the plot.
+func (errs saveErrors) Unwrap() []error {
+	return errs
 }
 
 // WriterTo returns an io.WriterTo that will write the plots as
@@ -122,13 +161,16 @@ func WriterTo(p Drawer, w, h vg.Length, format string) (io.WriterTo, error) {
 //
 // Supported formats are:
 //
-//  eps, jpg|jpeg, pdf, png, svg, tex and tif|tiff.
+//  eps, html, jpg|jpeg, pdf, png, svg, tex and tif|tiff.
 func newFormattedCanvas(w, h vg.Length, format string, dpi float64) (vg.CanvasWriterTo, error) {
 	var c vg.CanvasWriterTo
 	switch format {
 	case "eps":
 		c = vgeps.New(w, h)
 
+	case "html":
+		c = newHTMLCanvas(w, h)
+
 	case "jpg", "jpeg":
 		c = vgimg.JpegCanvas{Canvas: vgimg.NewWith(
 			vgimg.UseDPI(int(dpi)),
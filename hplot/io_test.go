@@ -5,8 +5,19 @@
 package hplot
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
 )
 
 func TestSave(t *testing.T) {
@@ -35,6 +46,12 @@ func TestSave(t *testing.T) {
 			files: []string{"file.txt"},
 			want:  fmt.Errorf(`hplot: could not save plot: hplot: could not create canvas: unsupported format: "txt"`),
 		},
+		{
+			name:  "unknown-formats",
+			files: []string{"file.txt", "file.xyz"},
+			want: fmt.Errorf(`hplot: could not save plot: hplot: could not create canvas: unsupported format: "txt"` + "\n" +
+				`hplot: could not save plot: hplot: could not create canvas: unsupported format: "xyz"`),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			err := Save(p, -1, -1, tc.files...)
@@ -48,3 +65,185 @@ func TestSave(t *testing.T) {
 		})
 	}
 }
+
+func TestSaveMultiFormats(t *testing.T) {
+	p := New()
+	p.Title.Text = "my title"
+	p.X.Label.Text = "x"
+	p.Y.Label.Text = "y"
+
+	var (
+		dir   = t.TempDir()
+		files = []string{
+			filepath.Join(dir, "fig.png"),
+			filepath.Join(dir, "fig.txt"),
+			filepath.Join(dir, "fig.pdf"),
+			filepath.Join(dir, "fig.svg"),
+			filepath.Join(dir, "fig-copy.png"),
+		}
+	)
+
+	err := Save(p, -1, -1, files...)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if got, want := err.Error(), `hplot: could not save plot: hplot: could not create canvas: unsupported format: "txt"`; got != want {
+		t.Fatalf("invalid error:\ngot= %v\nwant=%v", got, want)
+	}
+
+	for _, fname := range files {
+		if filepath.Ext(fname) == ".txt" {
+			continue
+		}
+		fi, err := os.Stat(fname)
+		if err != nil {
+			t.Fatalf("could not stat %q: %+v", fname, err)
+		}
+		if fi.Size() == 0 {
+			t.Fatalf("empty file %q", fname)
+		}
+	}
+
+	png1, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("could not read file: %+v", err)
+	}
+	png2, err := os.ReadFile(files[4])
+	if err != nil {
+		t.Fatalf("could not read file: %+v", err)
+	}
+	if !bytes.Equal(png1, png2) {
+		t.Fatalf("files of the same format differ")
+	}
+}
+
+func TestSaveErrorsUnwrap(t *testing.T) {
+	p := New()
+	err := Save(p, -1, -1, "file.txt", "file.xyz")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	errs := err.(interface{ Unwrap() []error }).Unwrap()
+	if got, want := len(errs), 2; got != want {
+		t.Fatalf("invalid number of errors: got=%d, want=%d", got, want)
+	}
+	for _, e := range errs {
+		if !errors.Is(err, e) {
+			t.Fatalf("error %q is not wrapped by %q", e, err)
+		}
+	}
+}
+
+func TestSaveHTML(t *testing.T) {
+	rp := NewRatioPlot()
+	rp.Top.Add(NewFunction(func(x float64) float64 { return x * x }))
+	rp.Top.X.Min = 1
+	rp.Top.X.Max = 10
+	rp.Top.Y.Min = 1
+	rp.Top.Y.Max = 100
+	rp.Top.Y.Scale = plot.LogScale{}
+	rp.Top.Y.Tick.Marker = plot.LogTicks{}
+	rp.Bottom.X.Min = 1
+	rp.Bottom.X.Max = 10
+	rp.Bottom.Y.Min = -1
+	rp.Bottom.Y.Max = +1
+
+	wt, err := WriterTo(rp, 10*vg.Centimeter, 10*vg.Centimeter, "html")
+	if err != nil {
+		t.Fatalf("could not create HTML canvas: %+v", err)
+	}
+
+	c := wt.(*htmlCanvas)
+	if got, want := len(c.plots), 2; got != want {
+		t.Fatalf("invalid number of registered plots: got=%d, want=%d", got, want)
+	}
+	if top, bot := c.plots[0], c.plots[1]; !top.YLog || bot.YLog || top.Y1 > bot.Y0 {
+		t.Fatalf("invalid plots layout: %+v", c.plots)
+	}
+
+	out := new(bytes.Buffer)
+	_, err = wt.WriteTo(out)
+	if err != nil {
+		t.Fatalf("could not write HTML page: %+v", err)
+	}
+
+	for _, want := range []string{"<!DOCTYPE html>", "<svg", `"ylog":true`, "</html>"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("HTML page does not contain %q", want)
+		}
+	}
+}
+
+func TestSaveHTMLSeries(t *testing.T) {
+	h := hbook.NewH1D(4, 0, 4)
+	h.Fill(0.5, 1)
+	h.Fill(2.5, 3)
+
+	p := New()
+	hh := NewH1D(h)
+	fct := NewFunction(func(x float64) float64 { return x })
+	pts := NewS2D(plotter.XYs{{X: 1, Y: 2}, {X: 3, Y: 4}})
+	p.Add(hh, fct, pts)
+	p.Legend.Add("histo", hh)
+	p.Legend.Add("points", pts)
+
+	wt, err := WriterTo(p, 10*vg.Centimeter, 10*vg.Centimeter, "html")
+	if err != nil {
+		t.Fatalf("could not create HTML canvas: %+v", err)
+	}
+
+	c := wt.(*htmlCanvas)
+	want := []htmlSeries{
+		{Plot: 0, Name: "histo", Points: [][2]float64{{0.5, 1}, {1.5, 0}, {2.5, 3}, {3.5, 0}}},
+		{Plot: 0, Name: "series 1", Points: [][2]float64{}},
+		{Plot: 0, Name: "points", Points: [][2]float64{{1, 2}, {3, 4}}},
+	}
+	if got, want := len(c.series), len(want); got != want {
+		t.Fatalf("invalid number of series: got=%d, want=%d", got, want)
+	}
+	for i := range want {
+		got := c.series[i]
+		got.p = nil
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("invalid series %d:\ngot= %+v\nwant=%+v", i, got, want[i])
+		}
+	}
+
+	out := new(bytes.Buffer)
+	_, err = wt.WriteTo(out)
+	if err != nil {
+		t.Fatalf("could not write HTML page: %+v", err)
+	}
+
+	page := out.String()
+	for _, want := range []string{
+		`<g id="hplot-series-0" class="hplot-series">`,
+		`<g id="hplot-series-1" class="hplot-series">`,
+		`<g id="hplot-series-2" class="hplot-series">`,
+		`<div id="hplot-legend"></div>`,
+		`"name":"histo","points":[[0.5,1],[1.5,0],[2.5,3],[3.5,0]]`,
+		`"name":"points","points":[[1,2],[3,4]]`,
+		`getElementById("hplot-series-" + i)`,
+		`function nearest(i, pt)`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("HTML page does not contain %q", want)
+		}
+	}
+
+	if got, want := strings.Count(page, "<svg"), 1; got != want {
+		t.Fatalf("invalid number of <svg> elements: got=%d, want=%d", got, want)
+	}
+	if got, want := strings.Count(page, "</svg>"), 1; got != want {
+		t.Fatalf("invalid number of </svg> elements: got=%d, want=%d", got, want)
+	}
+
+	// the plotters of the plot are only wrapped while rendering HTML.
+	ps := reflect.ValueOf(p.Plot).Elem().FieldByName("plotters")
+	for i, want := range []plot.Plotter{hh, fct, pts} {
+		if got, want := ps.Index(i).Elem().Type(), reflect.TypeOf(want); got != want {
+			t.Fatalf("invalid type of plotter %d: got=%v, want=%v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,524 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Lego implements the plot.Plotter interface, drawing a pseudo-3D view of
+// a 2-dim histogram, either as a lego plot (one box per bin) or as a surface
+// going through the centers of the bins.
+//
+// The 3D view is drawn in the whole data area of the plot, independently
+// of the axes of the plot, which should be hidden (see plot.Plot.HideAxes).
+// Hidden surfaces are removed by drawing the faces from back to front.
+type Lego struct {
+	// H is the histogramming data.
+	H *hbook.H2D
+
+	// Surface draws a surface going through the centers of the bins,
+	// instead of one box per bin.
+	Surface bool
+
+	// Elevation is the angle, in degrees, between the line of sight
+	// and the (x,y) plane.
+	Elevation float64
+
+	// Azimuth is the angle, in degrees, of the rotation of the
+	// histogram around the vertical axis.
+	Azimuth float64
+
+	// Palette is used to color the faces according to their height.
+	// If Palette is nil, FillColor is used.
+	Palette   palette.Palette
+	FillColor color.Color
+
+	// LineStyle is the style of the edges of the faces.
+	LineStyle draw.LineStyle
+
+	// BoxStyle is the style of the back panes of the 3D box.
+	BoxStyle draw.LineStyle
+
+	// TextStyle is the style of the tick labels and of the labels
+	// of the axes.
+	TextStyle draw.TextStyle
+
+	// Labels are the labels of the X, Y and Z axes.
+	Labels struct {
+		X, Y, Z string
+	}
+}
+
+// NewLego returns a new lego plotter for the 2-dim histogram h,
+// seen with a 30 degrees elevation and a 30 degrees azimuth.
+func NewLego(h *hbook.H2D) *Lego {
+	return &Lego{
+		H:         h,
+		Elevation: 30,
+		Azimuth:   30,
+		Palette:   Viridis(64),
+		FillColor: color.NRGBA{R: 100, G: 150, B: 255, A: 255},
+		LineStyle: draw.LineStyle{
+			Color: color.Black,
+			Width: vg.Points(0.5),
+		},
+		BoxStyle: draw.LineStyle{
+			Color: color.Gray{Y: 128},
+			Width: vg.Points(0.5),
+		},
+		TextStyle: draw.TextStyle{
+			Color:   color.Black,
+			Font:    DefaultStyle.Fonts.Tick,
+			Handler: DefaultStyle.TextHandler,
+		},
+	}
+}
+
+// NewSurface returns a new plotter drawing the 2-dim histogram h as a
+// surface going through the centers of its bins.
+func NewSurface(h *hbook.H2D) *Lego {
+	lego := NewLego(h)
+	lego.Surface = true
+	return lego
+}
+
+// DataRange implements the plot.DataRanger interface.
+func (lego *Lego) DataRange() (xmin, xmax, ymin, ymax float64) {
+	return lego.H.XMin(), lego.H.XMax(), lego.H.YMin(), lego.H.YMax()
+}
+
+// legoFace is a polygon of the 3D view, in normalized coordinates.
+type legoFace struct {
+	pts   [][3]float64
+	depth float64
+	fill  color.Color
+}
+
+// Plot implements the plot.Plotter interface.
+func (lego *Lego) Plot(c draw.Canvas, plt *plot.Plot) {
+	v := lego.view()
+
+	var faces []legoFace
+	switch {
+	case lego.Surface:
+		faces = lego.surfaceFaces(v)
+	default:
+		faces = lego.legoFaces(v)
+	}
+
+	prj := lego.projector(c, v)
+	lego.drawBox(c, v, prj)
+	for _, f := range faces {
+		pts := make([]vg.Point, len(f.pts))
+		for i, p := range f.pts {
+			pts[i] = prj(p)
+		}
+		if f.fill != nil {
+			c.FillPolygon(f.fill, pts)
+		}
+		if lego.LineStyle.Width > 0 {
+			c.StrokeLines(lego.LineStyle, append(pts, pts[0]))
+		}
+	}
+	lego.drawAxes(c, v, prj)
+}
+
+// view returns the projection of the histogram on the screen.
+func (lego *Lego) view() legoView {
+	var (
+		phi   = lego.Azimuth * math.Pi / 180
+		theta = lego.Elevation * math.Pi / 180
+		v     = legoView{
+			sinp: math.Sin(phi), cosp: math.Cos(phi),
+			sint: math.Sin(theta), cost: math.Cos(theta),
+			xmin: lego.H.XMin(), xmax: lego.H.XMax(),
+			ymin: lego.H.YMin(), ymax: lego.H.YMax(),
+			zmin: 0, zmax: math.Inf(-1),
+		}
+	)
+	for i := range lego.H.Binning.Bins {
+		z := lego.H.Binning.Bins[i].SumW()
+		v.zmin = math.Min(v.zmin, z)
+		v.zmax = math.Max(v.zmax, z)
+	}
+	if !(v.zmax > v.zmin) {
+		v.zmax = v.zmin + 1
+	}
+	return v
+}
+
+func (lego *Lego) color(z float64) color.Color {
+	if lego.Palette == nil {
+		return lego.FillColor
+	}
+	cs := lego.Palette.Colors()
+	if len(cs) == 0 {
+		return lego.FillColor
+	}
+	i := int(z * float64(len(cs)))
+	switch {
+	case i < 0:
+		i = 0
+	case i >= len(cs):
+		i = len(cs) - 1
+	}
+	return cs[i]
+}
+
+// legoFaces returns the visible faces of the boxes of the histogram,
+// ordered from back to front.
+func (lego *Lego) legoFaces(v legoView) []legoFace {
+	type box struct {
+		faces []legoFace
+		depth float64
+	}
+
+	var (
+		bins  = lego.H.Binning.Bins
+		boxes = make([]box, 0, len(bins))
+	)
+	for i := range bins {
+		bin := &bins[i]
+		z := v.w(bin.SumW())
+		if z == v.w(0) {
+			continue
+		}
+		var (
+			u0, u1 = v.u(bin.XMin()), v.u(bin.XMax())
+			v0, v1 = v.v(bin.YMin()), v.v(bin.YMax())
+			w0, w1 = v.w(0), z
+			fill   = lego.color(z)
+		)
+		if w1 < w0 {
+			w0, w1 = w1, w0
+		}
+
+		var faces []legoFace
+		// sides facing the viewer.
+		if v.sinp > 0 {
+			faces = append(faces, legoFace{
+				pts:  [][3]float64{{u0, v0, w0}, {u0, v1, w0}, {u0, v1, w1}, {u0, v0, w1}},
+				fill: shade(fill, 0.75),
+			})
+		}
+		if v.sinp < 0 {
+			faces = append(faces, legoFace{
+				pts:  [][3]float64{{u1, v0, w0}, {u1, v1, w0}, {u1, v1, w1}, {u1, v0, w1}},
+				fill: shade(fill, 0.75),
+			})
+		}
+		if v.cosp > 0 {
+			faces = append(faces, legoFace{
+				pts:  [][3]float64{{u0, v0, w0}, {u1, v0, w0}, {u1, v0, w1}, {u0, v0, w1}},
+				fill: shade(fill, 0.55),
+			})
+		}
+		if v.cosp < 0 {
+			faces = append(faces, legoFace{
+				pts:  [][3]float64{{u0, v1, w0}, {u1, v1, w0}, {u1, v1, w1}, {u0, v1, w1}},
+				fill: shade(fill, 0.55),
+			})
+		}
+		faces = append(faces, legoFace{
+			pts:  [][3]float64{{u0, v0, w1}, {u1, v0, w1}, {u1, v1, w1}, {u0, v1, w1}},
+			fill: fill,
+		})
+
+		boxes = append(boxes, box{
+			faces: faces,
+			depth: v.depth(0.5*(u0+u1), 0.5*(v0+v1), 0),
+		})
+	}
+	sort.SliceStable(boxes, func(i, j int) bool {
+		return boxes[i].depth > boxes[j].depth
+	})
+
+	faces := make([]legoFace, 0, 3*len(boxes))
+	for _, b := range boxes {
+		faces = append(faces, b.faces...)
+	}
+	return faces
+}
+
+// surfaceFaces returns the faces of the surface going through the centers
+// of the bins of the histogram, ordered from back to front.
+func (lego *Lego) surfaceFaces(v legoView) []legoFace {
+	var (
+		grid   = lego.H.GridXYZ()
+		nx, ny = grid.Dims()
+		faces  = make([]legoFace, 0, (nx-1)*(ny-1))
+	)
+	pt := func(i, j int) [3]float64 {
+		return [3]float64{v.u(grid.X(i)), v.v(grid.Y(j)), v.w(grid.Z(i, j))}
+	}
+	for i := 0; i < nx-1; i++ {
+		for j := 0; j < ny-1; j++ {
+			f := legoFace{
+				pts: [][3]float64{pt(i, j), pt(i+1, j), pt(i+1, j+1), pt(i, j+1)},
+			}
+			var mid [3]float64
+			for _, p := range f.pts {
+				for k := range mid {
+					mid[k] += 0.25 * p[k]
+				}
+			}
+			f.depth = v.depth(mid[0], mid[1], mid[2])
+			f.fill = lego.color(mid[2])
+			faces = append(faces, f)
+		}
+	}
+	sort.SliceStable(faces, func(i, j int) bool {
+		return faces[i].depth > faces[j].depth
+	})
+	return faces
+}
+
+// projector returns the function projecting normalized coordinates onto
+// the canvas.
+func (lego *Lego) projector(c draw.Canvas, v legoView) func(p [3]float64) vg.Point {
+	var (
+		xmin, xmax = math.Inf(+1), math.Inf(-1)
+		ymin, ymax = math.Inf(+1), math.Inf(-1)
+	)
+	for _, p := range v.corners() {
+		x, y := v.project(p)
+		xmin = math.Min(xmin, x)
+		xmax = math.Max(xmax, x)
+		ymin = math.Min(ymin, y)
+		ymax = math.Max(ymax, y)
+	}
+
+	var (
+		h   = lego.TextStyle.Height("0")
+		pad = 3*h + vg.Points(6)
+		min = vg.Point{X: c.Min.X + pad, Y: c.Min.Y + pad}
+		max = vg.Point{X: c.Max.X - pad, Y: c.Max.Y - h}
+		sx  = (max.X - min.X) / vg.Length(xmax-xmin)
+		sy  = (max.Y - min.Y) / vg.Length(ymax-ymin)
+	)
+	return func(p [3]float64) vg.Point {
+		x, y := v.project(p)
+		return vg.Point{
+			X: min.X + vg.Length(x-xmin)*sx,
+			Y: min.Y + vg.Length(y-ymin)*sy,
+		}
+	}
+}
+
+// drawBox draws the floor and the back panes of the 3D box.
+func (lego *Lego) drawBox(c draw.Canvas, v legoView, prj func([3]float64) vg.Point) {
+	sty := lego.BoxStyle
+	if sty.Width <= 0 {
+		return
+	}
+	var (
+		floor = v.floor()
+		front = v.front()
+	)
+	for i, p := range floor {
+		q := floor[(i+1)%len(floor)]
+		c.StrokeLine2(sty, prj(p).X, prj(p).Y, prj(q).X, prj(q).Y)
+		if i == front {
+			continue
+		}
+		top := [3]float64{p[0], p[1], 1}
+		c.StrokeLine2(sty, prj(p).X, prj(p).Y, prj(top).X, prj(top).Y)
+		for _, k := range []int{(i + 1) % len(floor), (i + 3) % len(floor)} {
+			if k == front {
+				continue
+			}
+			o := [3]float64{floor[k][0], floor[k][1], 1}
+			c.StrokeLine2(sty, prj(top).X, prj(top).Y, prj(o).X, prj(o).Y)
+		}
+	}
+}
+
+// drawAxes draws the ticks and the labels of the X and Y axes along the
+// front edges of the floor, and of the Z axis along the leftmost vertical
+// edge of the box.
+func (lego *Lego) drawAxes(c draw.Canvas, v legoView, prj func([3]float64) vg.Point) {
+	var (
+		floor = v.floor()
+		front = floor[v.front()]
+		sty   = lego.BoxStyle
+		tick  = vg.Points(4)
+		txt   = lego.TextStyle
+	)
+	if sty.Width <= 0 {
+		sty = lego.LineStyle
+	}
+
+	// axis draws the ticks of an axis, pointing in the out direction.
+	// The label of a vertical axis is drawn above its top end, and its
+	// lowest tick is omitted as it lies on the floor of the box.
+	axis := func(min, max float64, label string, pos func(t float64) [3]float64, out [3]float64, vertical bool) {
+		var (
+			o   = prj(pos(0.5))
+			e   = prj([3]float64{pos(0.5)[0] + out[0], pos(0.5)[1] + out[1], pos(0.5)[2] + out[2]})
+			dx  = float64(e.X - o.X)
+			dy  = float64(e.Y - o.Y)
+			n   = math.Hypot(dx, dy)
+			dir = vg.Point{X: vg.Length(dx / n), Y: vg.Length(dy / n)}
+			sty = txt
+		)
+		sty.XAlign = draw.XCenter
+		switch {
+		case dir.X < -0.3:
+			sty.XAlign = draw.XRight
+		case dir.X > 0.3:
+			sty.XAlign = draw.XLeft
+		}
+		sty.YAlign = draw.YCenter
+		if dir.Y < -0.3 {
+			sty.YAlign = draw.YTop
+		}
+
+		var width vg.Length
+		for _, t := range (plot.DefaultTicks{}).Ticks(min, max) {
+			if t.IsMinor() || t.Value < min || t.Value > max {
+				continue
+			}
+			if vertical && t.Value == min {
+				continue
+			}
+			var (
+				p   = prj(pos((t.Value - min) / (max - min)))
+				end = p.Add(dir.Scale(tick))
+				lbl = end.Add(dir.Scale(vg.Points(2)))
+			)
+			c.StrokeLine2(lego.LineStyle, p.X, p.Y, end.X, end.Y)
+			c.FillText(sty, lbl, t.Label)
+			width = vg.Length(math.Max(float64(width), float64(sty.Width(t.Label))))
+		}
+		if label == "" {
+			return
+		}
+		if vertical {
+			sty.XAlign = draw.XCenter
+			sty.YAlign = draw.YBottom
+			c.FillText(sty, prj(pos(1)).Add(vg.Point{Y: tick}), label)
+			return
+		}
+		off := tick + vg.Points(4) + sty.Height(label)
+		if math.Abs(float64(dir.X)) > 0.3 {
+			off += width
+		}
+		c.FillText(sty, o.Add(dir.Scale(off)), label)
+	}
+
+	var (
+		sx = math.Copysign(1, front[0])
+		sy = math.Copysign(1, front[1])
+	)
+	axis(v.xmin, v.xmax, lego.Labels.X,
+		func(t float64) [3]float64 { return [3]float64{t - 0.5, front[1], 0} },
+		[3]float64{0, sy * 0.1, 0}, false,
+	)
+	axis(v.ymin, v.ymax, lego.Labels.Y,
+		func(t float64) [3]float64 { return [3]float64{front[0], t - 0.5, 0} },
+		[3]float64{sx * 0.1, 0, 0}, false,
+	)
+
+	left := floor[0]
+	for _, p := range floor[1:] {
+		if prj(p).X < prj(left).X {
+			left = p
+		}
+	}
+	axis(v.zmin, v.zmax, lego.Labels.Z,
+		func(t float64) [3]float64 { return [3]float64{left[0], left[1], t} },
+		[3]float64{math.Copysign(0.1, left[0]), math.Copysign(0.1, left[1]), 0}, true,
+	)
+}
+
+// shade returns the color c darkened by the factor f.
+func shade(c color.Color, f float64) color.Color {
+	r, g, b, a := c.RGBA()
+	return color.NRGBA64{
+		R: uint16(float64(r) * f),
+		G: uint16(float64(g) * f),
+		B: uint16(float64(b) * f),
+		A: uint16(a),
+	}
+}
+
+// legoView describes the projection of the 3D box containing a histogram
+// onto the screen.
+//
+// Points of the box are described with normalized coordinates:
+// u and v in [-0.5, +0.5] along the X and Y axes, w in [0, 1] along the
+// Z axis.
+type legoView struct {
+	sinp, cosp float64 // azimuth
+	sint, cost float64 // elevation
+
+	xmin, xmax float64
+	ymin, ymax float64
+	zmin, zmax float64
+}
+
+func (v legoView) u(x float64) float64 { return (x-v.xmin)/(v.xmax-v.xmin) - 0.5 }
+func (v legoView) v(y float64) float64 { return (y-v.ymin)/(v.ymax-v.ymin) - 0.5 }
+func (v legoView) w(z float64) float64 { return (z - v.zmin) / (v.zmax - v.zmin) }
+
+// project returns the screen coordinates of the normalized point p.
+func (v legoView) project(p [3]float64) (x, y float64) {
+	x = p[0]*v.cosp - p[1]*v.sinp
+	y = p[2]*v.cost + (p[0]*v.sinp+p[1]*v.cosp)*v.sint
+	return x, y
+}
+
+// depth returns the distance of the normalized point (u,v,w) to the
+// viewer, up to a constant.
+func (v legoView) depth(u, vv, w float64) float64 {
+	return (u*v.sinp+vv*v.cosp)*v.cost - w*v.sint
+}
+
+// floor returns the corners of the floor of the box, in order.
+func (v legoView) floor() [4][3]float64 {
+	return [4][3]float64{
+		{-0.5, -0.5, 0},
+		{+0.5, -0.5, 0},
+		{+0.5, +0.5, 0},
+		{-0.5, +0.5, 0},
+	}
+}
+
+// front returns the index of the floor corner closest to the viewer.
+func (v legoView) front() int {
+	var (
+		floor = v.floor()
+		imin  = 0
+	)
+	for i, p := range floor {
+		if v.depth(p[0], p[1], p[2]) < v.depth(floor[imin][0], floor[imin][1], floor[imin][2]) {
+			imin = i
+		}
+	}
+	return imin
+}
+
+// corners returns the corners of the box.
+func (v legoView) corners() [][3]float64 {
+	var ps [][3]float64
+	for _, p := range v.floor() {
+		ps = append(ps, p, [3]float64{p[0], p[1], 1})
+	}
+	return ps
+}
+
+var (
+	_ plot.Plotter    = (*Lego)(nil)
+	_ plot.DataRanger = (*Lego)(nil)
+)
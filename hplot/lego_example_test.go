@@ -0,0 +1,80 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"log"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distmv"
+	"gonum.org/v1/plot/vg"
+)
+
+func newLegoH2D() *hbook.H2D {
+	const npoints = 10000
+
+	dist, ok := distmv.NewNormal(
+		[]float64{0, 1},
+		mat.NewSymDense(2, []float64{4, 0, 0, 2}),
+		rand.New(rand.NewSource(1234)),
+	)
+	if !ok {
+		log.Fatalf("error creating distmv.Normal")
+	}
+
+	h := hbook.NewH2D(20, -8, +8, 20, -4, +6)
+	for i := 0; i < npoints; i++ {
+		v := dist.Rand(nil)
+		h.Fill(v[0], v[1], 1)
+	}
+	return h
+}
+
+// An example of drawing a 2-dim histogram as a lego plot.
+func ExampleLego() {
+	h := newLegoH2D()
+
+	p := hplot.New()
+	p.Title.Text = "Lego plot"
+	p.HideAxes()
+
+	lego := hplot.NewLego(h)
+	lego.Labels.X = "X"
+	lego.Labels.Y = "Y"
+	lego.Labels.Z = "Entries"
+	p.Add(lego)
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/h2d_lego.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// An example of drawing a 2-dim histogram as a surface, with a
+// custom point of view.
+func ExampleLego_surface() {
+	h := newLegoH2D()
+
+	p := hplot.New()
+	p.Title.Text = "Surface plot"
+	p.HideAxes()
+
+	surf := hplot.NewSurface(h)
+	surf.Elevation = 40
+	surf.Azimuth = -60
+	surf.Palette = hplot.Bird(64)
+	surf.LineStyle.Width = vg.Points(0.25)
+	surf.Labels.X = "X"
+	surf.Labels.Y = "Y"
+	p.Add(surf)
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/h2d_surface.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
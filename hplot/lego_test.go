@@ -0,0 +1,16 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"testing"
+
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestLego(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleLego, t, "h2d_lego.png")
+	checkPlot(cmpimg.CheckPlot)(ExampleLego_surface, t, "h2d_surface.png")
+}
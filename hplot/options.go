@@ -5,6 +5,9 @@
 package hplot
 
 import (
+	"image/color"
+
+	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 )
 
@@ -23,6 +26,7 @@ type config struct {
 	bars struct {
 		xerrs bool
 		yerrs bool
+		capw  *vg.Length // width of the caps of the error bars
 	}
 	band   bool
 	hinfos HInfos
@@ -31,6 +35,14 @@ type config struct {
 	}
 	glyph draw.GlyphStyle
 	steps StepsKind
+
+	line    *draw.LineStyle // outline style of a histogram
+	fill    color.Color     // fill color of a histogram
+	poisson bool            // Poisson error bars of a histogram
+	data    bool            // data-style histogram
+
+	colors []color.Color // fill colors of the components of a stack
+	hatch  *HatchStyle   // hatching of the uncertainty band
 }
 
 func newConfig(opts []Options) *config {
@@ -63,6 +75,14 @@ func WithYErrBars(v bool) Options {
 	}
 }
 
+// WithCapWidth sets the width of the caps of the error bars.
+// Use zero width to disable the drawing of the caps.
+func WithCapWidth(w vg.Length) Options {
+	return func(c *config) {
+		c.bars.capw = &w
+	}
+}
+
 // WithBand enables or disables the display of a colored band between Y-error bars.
 func WithBand(v bool) Options {
 	return func(c *config) {
@@ -84,9 +104,60 @@ func WithGlyphStyle(sty draw.GlyphStyle) Options {
 	}
 }
 
+// WithLineStyle sets the line style of a plotter,
+// e.g. the style of the outline of a histogram.
+func WithLineStyle(sty draw.LineStyle) Options {
+	return func(c *config) {
+		c.line = &sty
+	}
+}
+
+// WithFillColor sets the fill color of a histogram.
+// The area between the histogram and zero is filled with that color.
+func WithFillColor(col color.Color) Options {
+	return func(c *config) {
+		c.fill = col
+	}
+}
+
+// WithPoissonErrBars enables or disables the display of Poisson error bars.
+// The bin contents are then interpreted as a number of counts and the
+// error bars display the 68.27% Garwood confidence interval of the
+// mean of a Poisson distribution.
+func WithPoissonErrBars(v bool) Options {
+	return func(c *config) {
+		c.poisson = v
+	}
+}
+
+// WithDataStyle enables or disables the display of a histogram in the
+// usual style of data: markers at the bins centers, with Poisson error
+// bars and without any outline.
+func WithDataStyle(v bool) Options {
+	return func(c *config) {
+		c.data = v
+	}
+}
+
 // WithHInfo sets a given histogram info style.
 func WithHInfo(v HInfoStyle) Options {
 	return func(c *config) {
 		c.hinfos.Style = v
 	}
 }
+
+// WithFillColors sets the fill colors of the histograms of a stack.
+// The i-th histogram of the stack is filled with the color cs[i%len(cs)].
+func WithFillColors(cs ...color.Color) Options {
+	return func(c *config) {
+		c.colors = cs
+	}
+}
+
+// WithBandHatch sets the hatching style of the uncertainty band.
+// The band is not filled with a color when a hatching style is provided.
+func WithBandHatch(sty HatchStyle) Options {
+	return func(c *config) {
+		c.hatch = &sty
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot/palette"
+)
+
+// colors implements the palette.Palette interface.
+type colors []color.Color
+
+func (cs colors) Colors() []color.Color { return cs }
+
+// NewGradient returns a palette of n colors, linearly interpolated between
+// the provided color stops.
+// The stops are equally spaced along the palette.
+//
+// NewGradient panics if n < 2 or if less than 2 stops are provided.
+func NewGradient(n int, stops ...color.Color) palette.Palette {
+	if n < 2 {
+		panic("hplot: invalid number of gradient colors")
+	}
+	if len(stops) < 2 {
+		panic("hplot: not enough gradient color stops")
+	}
+
+	rgba := make([][4]float64, len(stops))
+	for i, c := range stops {
+		r, g, b, a := c.RGBA()
+		rgba[i] = [4]float64{float64(r), float64(g), float64(b), float64(a)}
+	}
+
+	cs := make(colors, n)
+	for i := range cs {
+		var (
+			f = float64(i) / float64(n-1) * float64(len(stops)-1)
+			j = int(f)
+		)
+		if j >= len(stops)-1 {
+			j = len(stops) - 2
+		}
+		var (
+			t  = f - float64(j)
+			c0 = rgba[j]
+			c1 = rgba[j+1]
+			v  [4]uint16
+		)
+		for k := range v {
+			v[k] = uint16(math.Round(c0[k] + t*(c1[k]-c0[k])))
+		}
+		cs[i] = color.RGBA64{R: v[0], G: v[1], B: v[2], A: v[3]}
+	}
+	return cs
+}
+
+func rgbStops(rs, gs, bs []float64) []color.Color {
+	stops := make([]color.Color, len(rs))
+	for i := range stops {
+		stops[i] = color.NRGBA{
+			R: uint8(math.Round(255 * rs[i])),
+			G: uint8(math.Round(255 * gs[i])),
+			B: uint8(math.Round(255 * bs[i])),
+			A: 255,
+		}
+	}
+	return stops
+}
+
+// Viridis returns the perceptually uniform viridis palette, with n colors.
+func Viridis(n int) palette.Palette {
+	return NewGradient(n, rgbStops(
+		[]float64{26. / 255, 51. / 255, 43. / 255, 33. / 255, 28. / 255, 35. / 255, 74. / 255, 144. / 255, 246. / 255},
+		[]float64{9. / 255, 24. / 255, 55. / 255, 87. / 255, 118. / 255, 150. / 255, 180. / 255, 200. / 255, 222. / 255},
+		[]float64{30. / 255, 96. / 255, 112. / 255, 114. / 255, 112. / 255, 101. / 255, 72. / 255, 35. / 255, 0. / 255},
+	)...)
+}
+
+// Bird returns the ROOT kBird palette (the default palette of ROOT),
+// with n colors.
+func Bird(n int) palette.Palette {
+	return NewGradient(n, rgbStops(
+		[]float64{0.2082, 0.0592, 0.0780, 0.0232, 0.1802, 0.5301, 0.8186, 0.9956, 0.9764},
+		[]float64{0.1664, 0.3599, 0.5041, 0.6419, 0.7178, 0.7492, 0.7328, 0.7862, 0.9832},
+		[]float64{0.5293, 0.8684, 0.8385, 0.7914, 0.6425, 0.4662, 0.3499, 0.1968, 0.0539},
+	)...)
+}
+
+// hexColors returns the colors described by their 0xRRGGBB values.
+func hexColors(vs ...uint32) colors {
+	cs := make(colors, len(vs))
+	for i, v := range vs {
+		cs[i] = color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+	}
+	return cs
+}
+
+// OkabeIto returns the 8 colors palette of Okabe and Ito, distinguishable
+// by people with the most common forms of color vision deficiency.
+//
+// See https://jfly.uni-koeln.de/color/ for more details.
+func OkabeIto() palette.Palette {
+	return hexColors(
+		0x000000, // black
+		0xe69f00, // orange
+		0x56b4e9, // sky blue
+		0x009e73, // bluish green
+		0xf0e442, // yellow
+		0x0072b2, // blue
+		0xd55e00, // vermillion
+		0xcc79a7, // reddish purple
+	)
+}
+
+// Petroff6 returns the 6 colors palette of M. A. Petroff, optimized for
+// accessibility and aesthetics of the plots of data series.
+//
+// See https://arxiv.org/abs/2107.02270 for more details.
+func Petroff6() palette.Palette {
+	return hexColors(0x5790fc, 0xf89c20, 0xe42536, 0x964a8b, 0x9c9ca1, 0x7a21dd)
+}
+
+// Petroff8 returns the 8 colors palette of M. A. Petroff, optimized for
+// accessibility and aesthetics of the plots of data series.
+//
+// See https://arxiv.org/abs/2107.02270 for more details.
+func Petroff8() palette.Palette {
+	return hexColors(0x1845fb, 0xff5e02, 0xc91f16, 0xc849a9, 0xadad7d, 0x86c8dd, 0x578dff, 0x656364)
+}
+
+// Petroff10 returns the 10 colors palette of M. A. Petroff, optimized for
+// accessibility and aesthetics of the plots of data series.
+//
+// See https://arxiv.org/abs/2107.02270 for more details.
+func Petroff10() palette.Palette {
+	return hexColors(0x3f90da, 0xffa90e, 0xbd1f01, 0x94a4a2, 0x832db6, 0xa96b59, 0xe76300, 0xb9ac70, 0x717581, 0x92dadd)
+}
+
+var (
+	_ palette.Palette = (colors)(nil)
+)
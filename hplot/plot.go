@@ -10,14 +10,21 @@ import (
 	"sync"
 
 	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/text"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgtex"
 )
 
 // Plot is the basic type representing a plot.
 type Plot struct {
 	*plot.Plot
 	Style Style
+
+	// Grid, if any, is drawn behind all the plotters of the plot.
+	Grid *Grid
+
+	plotters []plot.Plotter // plotters added with Add
 }
 
 // muNewPlot protects access to gonum/plot.DefaultFont
@@ -62,6 +69,7 @@ func (p *Plot) Add(ps ...plot.Plotter) {
 	}
 
 	p.Plot.Add(ps...)
+	p.plotters = append(p.plotters, ps...)
 }
 
 // Save saves the plot to an image file.  The file format is determined
@@ -69,7 +77,7 @@ func (p *Plot) Add(ps ...plot.Plotter) {
 //
 // Supported extensions are:
 //
-//  .eps, .jpg, .jpeg, .pdf, .png, .svg, .tex, .tif and .tiff.
+//  .eps, .html, .jpg, .jpeg, .pdf, .png, .svg, .tex, .tif and .tiff.
 //
 // If w or h are <= 0, the value is chosen such that it follows the Golden Ratio.
 // If w and h are <= 0, the values are chosen such that they follow the Golden Ratio
@@ -83,7 +91,7 @@ func (p *Plot) Save(w, h vg.Length, file string) error {
 //
 // Supported formats are:
 //
-//  eps, jpg|jpeg, pdf, png, svg, tex and tif|tiff.
+//  eps, html, jpg|jpeg, pdf, png, svg, tex and tif|tiff.
 func (p *Plot) WriterTo(w, h vg.Length, format string) (io.WriterTo, error) {
 	return WriterTo(p, w, h, format)
 }
@@ -95,8 +103,95 @@ func (p *Plot) WriterTo(w, h vg.Length, format string) (io.WriterTo, error) {
 // GlyphBoxer interface will have their GlyphBoxes
 // taken into account when padding the plot so that
 // none of their glyphs are clipped.
+//
+// If the plot has a Grid, it is drawn on top of the background and
+// behind all the plotters.
+//
+// The tick marks drawn inside the data area and the frame around the
+// data area, if requested by the style of the plot, are drawn last.
+//
+// The axes using the hplot tick markers ExpTicks, UnitTicks and LogTicks
+// have their tick labels adapted to the text backend, and their label
+// decorated with the multiplier or the unit of the axis.
 func (p *Plot) Draw(dc draw.Canvas) {
+	defer p.setupAxes(dc)()
+
+	c, html := htmlCanvasOf(dc.Canvas)
+	if html {
+		defer p.withSeries()()
+	}
+
+	if p.Grid != nil {
+		if bkg := p.Plot.BackgroundColor; bkg != nil {
+			dc.SetColor(bkg)
+			dc.Fill(dc.Rectangle.Path())
+			p.Plot.BackgroundColor = nil
+			defer func() { p.Plot.BackgroundColor = bkg }()
+		}
+		p.Grid.Plot(p.Plot.DataCanvas(dc), p.Plot)
+	}
 	p.Plot.Draw(dc)
+	p.Style.decorate(p, dc)
+
+	if html {
+		c.register(p.Plot, p.Plot.DataCanvas(dc))
+	}
+}
+
+// setupAxes configures the tick markers and the labels of the axes using
+// hplot tick markers, and returns a function restoring the axes.
+func (p *Plot) setupAxes(dc draw.Canvas) func() {
+	tex := false
+	switch dc.Canvas.(type) {
+	case *vgtex.Canvas:
+		tex = true
+	}
+	switch p.Plot.TextHandler.(type) {
+	case text.Latex, *text.Latex:
+		tex = true
+	}
+
+	var restore []func()
+	for _, ax := range []*plot.Axis{&p.Plot.X, &p.Plot.Y} {
+		tck, ok := ax.Tick.Marker.(axisTicker)
+		if !ok {
+			continue
+		}
+		var (
+			ax     = ax
+			marker = ax.Tick.Marker
+			label  = ax.Label
+			tick   = ax.Tick.Label
+		)
+		var (
+			suffix string
+			sup    bool
+		)
+		ax.Tick.Marker, suffix, sup = tck.axis(ax.Min, ax.Max, tex)
+		if sup {
+			ax.Tick.Label.Handler = superscripts{text.Plain{Fonts: p.Style.Fonts.Cache}}
+		}
+		if suffix != "" {
+			if label.Text != "" {
+				suffix = label.Text + " " + suffix
+			}
+			ax.Label.Text = suffix
+			if sup {
+				ax.Label.TextStyle.Handler = ax.Tick.Label.Handler
+			}
+		}
+		restore = append(restore, func() {
+			ax.Tick.Marker = marker
+			ax.Label = label
+			ax.Tick.Label = tick
+		})
+	}
+
+	return func() {
+		for _, f := range restore {
+			f()
+		}
+	}
 }
 
 var (
@@ -0,0 +1,388 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// PolarPlot is a plot in polar coordinates (r, φ).
+//
+// The angle φ is measured in radians, counterclockwise from the
+// horizontal axis.
+// The data area of the plot is a disk of radius RMax, with a polar grid
+// made of concentric rings and of radial spokes.
+//
+// Plotters added to a PolarPlot are drawn in a cartesian frame centered
+// on the origin of the polar coordinates, with equal scales along both
+// axes: PolarLine converts polar coordinates to that frame.
+type PolarPlot struct {
+	// Plot holds the plotters, the title and the legend of the plot.
+	// The axes of Plot are not drawn.
+	Plot *Plot
+
+	// RMax is the radius of the outermost ring of the polar grid.
+	// If RMax is zero, it is computed from the data.
+	RMax float64
+
+	// Degrees selects the labelling of the spokes in degrees,
+	// rather than in fractions of π radians.
+	Degrees bool
+
+	// Spokes is the number of radial spokes of the polar grid.
+	Spokes int
+
+	// GridStyle is the style of the rings and of the spokes.
+	GridStyle draw.LineStyle
+
+	// TextStyle is the style of the labels of the rings and of the spokes.
+	TextStyle draw.TextStyle
+}
+
+// NewPolarPlot returns a new polar plot, with 8 spokes labelled in degrees.
+func NewPolarPlot() *PolarPlot {
+	return &PolarPlot{
+		Plot:    New(),
+		Degrees: true,
+		Spokes:  8,
+		GridStyle: draw.LineStyle{
+			Color: color.Gray{Y: 160},
+			Width: vg.Points(0.5),
+		},
+		TextStyle: draw.TextStyle{
+			Color:   color.Black,
+			Font:    DefaultStyle.Fonts.Tick,
+			Handler: DefaultStyle.TextHandler,
+		},
+	}
+}
+
+// Add adds plotters to the plot.
+func (pp *PolarPlot) Add(ps ...plot.Plotter) {
+	pp.Plot.Add(ps...)
+}
+
+// Draw draws the polar plot to a draw.Canvas.
+func (pp *PolarPlot) Draw(c draw.Canvas) {
+	var (
+		q = *pp.Plot.Plot
+		p = &Plot{Plot: &q, Style: pp.Plot.Style}
+	)
+	p.Style.Frame = false
+	p.Style.Ticks.Inside = false
+	q.HideAxes()
+	q.X.Label.Text = ""
+	q.Y.Label.Text = ""
+	q.X.Padding = 0
+	q.Y.Padding = 0
+
+	rmax := pp.RMax
+	if rmax <= 0 {
+		for _, v := range []float64{q.X.Min, q.X.Max, q.Y.Min, q.Y.Max} {
+			rmax = math.Max(rmax, math.Abs(v))
+		}
+		if rmax <= 0 || math.IsInf(rmax, 0) {
+			rmax = 1
+		}
+	}
+	rmax, rings := polarRings(rmax, pp.RMax <= 0)
+
+	// make the data area square, with some room for the labels of
+	// the spokes.
+	dc := q.DataCanvas(c)
+	switch w, h := dc.Size().X, dc.Size().Y; {
+	case w > h:
+		c.Min.X += (w - h) / 2
+		c.Max.X -= (w - h) / 2
+	case h > w:
+		c.Min.Y += (h - w) / 2
+		c.Max.Y -= (h - w) / 2
+	}
+	dc = q.DataCanvas(c)
+
+	var (
+		half = dc.Size().X / 2
+		pad  = 2.5 * pp.TextStyle.Height("0")
+		rng  = rmax
+	)
+	if half > pad {
+		rng = rmax * float64(half/(half-pad))
+	}
+	q.X.Min, q.X.Max = -rng, +rng
+	q.Y.Min, q.Y.Max = -rng, +rng
+
+	if bkg := q.BackgroundColor; bkg != nil {
+		c.SetColor(bkg)
+		c.Fill(c.Rectangle.Path())
+		q.BackgroundColor = nil
+	}
+	pp.drawGrid(dc, &q, rmax, rings)
+	p.Draw(c)
+}
+
+// polarRings returns the radii of the rings of the polar grid, at the
+// major ticks of the [0, rmax] range.
+// If round is true, rmax is rounded up to the next major tick.
+func polarRings(rmax float64, round bool) (float64, []float64) {
+	var rings []float64
+	for _, t := range (plot.DefaultTicks{}).Ticks(0, rmax) {
+		if !t.IsMinor() && t.Value > 0 {
+			rings = append(rings, t.Value)
+		}
+	}
+	sort.Float64s(rings)
+
+	step := rmax
+	switch n := len(rings); {
+	case n > 1:
+		step = rings[n-1] - rings[n-2]
+	case n == 1:
+		step = rings[0]
+	}
+	if round && step > 0 {
+		rmax = math.Ceil(rmax/step) * step
+	}
+	for len(rings) > 0 && rings[len(rings)-1] > rmax {
+		rings = rings[:len(rings)-1]
+	}
+	for n := len(rings); n > 0 && rings[n-1]+step <= rmax*(1+1e-9); n = len(rings) {
+		rings = append(rings, rings[n-1]+step)
+	}
+	if n := len(rings); n == 0 || rings[n-1] < rmax {
+		rings = append(rings, rmax)
+	}
+	return rmax, rings
+}
+
+// drawGrid draws the rings and the spokes of the polar grid.
+func (pp *PolarPlot) drawGrid(c draw.Canvas, plt *plot.Plot, rmax float64, rings []float64) {
+	var (
+		trX, trY = plt.Transforms(&c)
+		o        = vg.Point{X: trX(0), Y: trY(0)}
+		scale    = trX(1) - trX(0)
+		sty      = pp.TextStyle
+	)
+
+	// rings, labelled along the first half-spoke.
+	phi0 := math.Pi / 8
+	if pp.Spokes > 0 {
+		phi0 = math.Pi / float64(pp.Spokes)
+	}
+	sty.XAlign = draw.XCenter
+	sty.YAlign = draw.YCenter
+	for _, v := range rings {
+		r := scale * vg.Length(v)
+		c.StrokeLines(pp.GridStyle, arc(o, r, 0, 2*math.Pi))
+		c.FillText(sty, polarPoint(o, r, phi0), strconv.FormatFloat(v, 'g', -1, 64))
+	}
+
+	// spokes, labelled outside the outermost ring.
+	var (
+		r   = scale * vg.Length(rmax)
+		off = r + pp.TextStyle.Height("0")
+	)
+	for i := 0; i < pp.Spokes; i++ {
+		phi := 2 * math.Pi * float64(i) / float64(pp.Spokes)
+		end := polarPoint(o, r, phi)
+		c.StrokeLine2(pp.GridStyle, o.X, o.Y, end.X, end.Y)
+
+		sty := pp.TextStyle
+		sty.XAlign = draw.XAlignment(-0.5 + 0.5*math.Cos(phi))
+		sty.YAlign = draw.YAlignment(-0.5 + 0.5*math.Sin(phi))
+		c.FillText(sty, polarPoint(o, off, phi), pp.spokeLabel(i))
+	}
+}
+
+// spokeLabel returns the label of the i-th spoke.
+func (pp *PolarPlot) spokeLabel(i int) string {
+	if pp.Degrees {
+		return fmt.Sprintf("%g°", 360*float64(i)/float64(pp.Spokes))
+	}
+
+	// φ = 2i/n π, reduced to an irreducible fraction.
+	num, den := 2*i, pp.Spokes
+	if num == 0 {
+		return "0"
+	}
+	g := gcd(num, den)
+	num /= g
+	den /= g
+	switch {
+	case num == 1 && den == 1:
+		return "π"
+	case den == 1:
+		return fmt.Sprintf("%dπ", num)
+	case num == 1:
+		return fmt.Sprintf("π/%d", den)
+	default:
+		return fmt.Sprintf("%dπ/%d", num, den)
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Save saves the plot to an image file.
+// The file format is determined by the extension.
+//
+// Supported extensions are the same ones than hplot.Plot.Save.
+//
+// If w or h are <= 0, the value is chosen such that it follows the Golden Ratio.
+// If w and h are <= 0, the values are chosen such that they follow the Golden Ratio
+// (the width is defaulted to vgimg.DefaultWidth).
+func (pp *PolarPlot) Save(w, h vg.Length, file string) error {
+	return Save(pp, w, h, file)
+}
+
+// polarPoint returns the point at a distance r from o, along the
+// direction φ.
+func polarPoint(o vg.Point, r vg.Length, phi float64) vg.Point {
+	return vg.Point{
+		X: o.X + r*vg.Length(math.Cos(phi)),
+		Y: o.Y + r*vg.Length(math.Sin(phi)),
+	}
+}
+
+// arc returns the points of the arc of radius r centered on o,
+// from φ0 to φ1.
+func arc(o vg.Point, r vg.Length, phi0, phi1 float64) []vg.Point {
+	n := int(math.Ceil(math.Abs(phi1-phi0)/(math.Pi/180))) + 1
+	pts := make([]vg.Point, n)
+	for i := range pts {
+		phi := phi0 + (phi1-phi0)*float64(i)/float64(n-1)
+		pts[i] = polarPoint(o, r, phi)
+	}
+	return pts
+}
+
+// PolarLine implements the plot.Plotter interface, drawing a line
+// connecting points given in polar coordinates.
+//
+// Consecutive points are connected along spirals, so points with the
+// same radius are connected by arcs of circle.
+type PolarLine struct {
+	// Phis and Rs are the angles, in radians, and the radii of the points.
+	Phis []float64
+	Rs   []float64
+
+	LineStyle draw.LineStyle
+
+	// FillColor is the color of the area between the origin and
+	// the line.
+	// The area is not filled if FillColor is nil.
+	FillColor color.Color
+}
+
+// NewPolarLine returns a line connecting the points (phis[i], rs[i]).
+func NewPolarLine(phis, rs []float64) *PolarLine {
+	if len(phis) != len(rs) {
+		panic("hplot: polar line with mismatched lengths")
+	}
+	return &PolarLine{
+		Phis:      phis,
+		Rs:        rs,
+		LineStyle: plotter.DefaultLineStyle,
+	}
+}
+
+// NewH1DPolar returns a closed line following the contents of the
+// histogram h, whose X axis is interpreted as the angle φ, in radians.
+// NewH1DPolar is useful e.g. to display the φ occupancy of a detector.
+func NewH1DPolar(h *hbook.H1D) *PolarLine {
+	var (
+		bins = h.Binning.Bins
+		phis = make([]float64, 0, 2*len(bins)+1)
+		rs   = make([]float64, 0, 2*len(bins)+1)
+	)
+	for _, bin := range bins {
+		r := bin.SumW()
+		phis = append(phis, bin.XMin(), bin.XMax())
+		rs = append(rs, r, r)
+	}
+	if len(phis) > 0 {
+		phis = append(phis, phis[0])
+		rs = append(rs, rs[0])
+	}
+	return NewPolarLine(phis, rs)
+}
+
+// Plot implements the plot.Plotter interface.
+func (pl *PolarLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	if len(pl.Phis) == 0 {
+		return
+	}
+
+	var (
+		trX, trY = plt.Transforms(&c)
+		pts      = make([]vg.Point, 0, len(pl.Phis))
+		xy       = func(phi, r float64) vg.Point {
+			return vg.Point{X: trX(r * math.Cos(phi)), Y: trY(r * math.Sin(phi))}
+		}
+	)
+	pts = append(pts, xy(pl.Phis[0], pl.Rs[0]))
+	for i := 1; i < len(pl.Phis); i++ {
+		var (
+			phi0, phi1 = pl.Phis[i-1], pl.Phis[i]
+			r0, r1     = pl.Rs[i-1], pl.Rs[i]
+			n          = int(math.Ceil(math.Abs(phi1-phi0)/(math.Pi/180))) + 1
+		)
+		for j := 1; j <= n; j++ {
+			f := float64(j) / float64(n)
+			pts = append(pts, xy(phi0+f*(phi1-phi0), r0+f*(r1-r0)))
+		}
+	}
+
+	if pl.FillColor != nil {
+		o := vg.Point{X: trX(0), Y: trY(0)}
+		poly := append([]vg.Point{o}, pts...)
+		c.FillPolygon(pl.FillColor, c.ClipPolygonXY(poly))
+	}
+	c.StrokeLines(pl.LineStyle, c.ClipLinesXY(pts)...)
+}
+
+// DataRange returns the minimum and maximum x and y values,
+// implementing the plot.DataRanger interface.
+// The returned range is the square enclosing the circle whose radius is
+// the largest radius of the line.
+func (pl *PolarLine) DataRange() (xmin, xmax, ymin, ymax float64) {
+	var rmax float64
+	for _, r := range pl.Rs {
+		rmax = math.Max(rmax, math.Abs(r))
+	}
+	return -rmax, +rmax, -rmax, +rmax
+}
+
+// Thumbnail returns the thumbnail for the PolarLine,
+// implementing the plot.Thumbnailer interface.
+func (pl *PolarLine) Thumbnail(c *draw.Canvas) {
+	if pl.FillColor != nil {
+		c.SetColor(pl.FillColor)
+		c.Fill(c.Rectangle.Path())
+	}
+	y := c.Center().Y
+	c.StrokeLine2(pl.LineStyle, c.Min.X, y, c.Max.X, y)
+}
+
+var (
+	_ Drawer = (*PolarPlot)(nil)
+
+	_ plot.Plotter     = (*PolarLine)(nil)
+	_ plot.DataRanger  = (*PolarLine)(nil)
+	_ plot.Thumbnailer = (*PolarLine)(nil)
+)
@@ -0,0 +1,80 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"image/color"
+	"log"
+	"math"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of displaying the φ occupancy of a detector in a polar plot.
+func ExamplePolarPlot() {
+	const npoints = 10000
+
+	var (
+		src  = rand.New(rand.NewSource(0))
+		flat = distuv.Uniform{Min: -math.Pi, Max: +math.Pi, Src: src}
+		hot  = distuv.Normal{Mu: math.Pi / 4, Sigma: 0.1, Src: src}
+		hist = hbook.NewH1D(36, -math.Pi, +math.Pi)
+	)
+	for i := 0; i < npoints; i++ {
+		hist.Fill(flat.Rand(), 1)
+	}
+	for i := 0; i < npoints/10; i++ {
+		hist.Fill(hot.Rand(), 1)
+	}
+
+	p := hplot.NewPolarPlot()
+	p.Plot.Title.Text = "φ occupancy"
+
+	occ := hplot.NewH1DPolar(hist)
+	occ.FillColor = color.NRGBA{B: 255, A: 64}
+	occ.LineStyle.Color = color.NRGBA{B: 255, A: 255}
+	p.Add(occ)
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/polar.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
+
+// An example of displaying an angular distribution in a polar plot,
+// with the angles labelled in radians.
+func ExamplePolarPlot_radians() {
+	var (
+		n    = 200
+		phis = make([]float64, n)
+		rs   = make([]float64, n)
+	)
+	for i := range phis {
+		phi := 2 * math.Pi * float64(i) / float64(n-1)
+		phis[i] = phi
+		rs[i] = 1 + math.Cos(phi)*math.Cos(phi)
+	}
+
+	p := hplot.NewPolarPlot()
+	p.Plot.Title.Text = "1 + cos²(φ)"
+	p.Degrees = false
+	p.Spokes = 12
+	p.Plot.Legend.Top = true
+
+	line := hplot.NewPolarLine(phis, rs)
+	line.LineStyle.Color = color.NRGBA{R: 255, A: 255}
+	line.LineStyle.Width = vg.Points(1.5)
+	p.Add(line)
+	p.Plot.Legend.Add("dσ/dΩ", line)
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/polar_radians.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
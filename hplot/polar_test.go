@@ -0,0 +1,16 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"testing"
+
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestPolarPlot(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExamplePolarPlot, t, "polar.png")
+	checkPlot(cmpimg.CheckPlot)(ExamplePolarPlot_radians, t, "polar_radians.png")
+}
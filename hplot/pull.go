@@ -0,0 +1,213 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Pulls implements the plot.Plotter interface,
+// drawing the per-bin pulls, (data-model)/sigma, of a binned data set
+// with regard to a model, as bars or as points.
+//
+// Pulls is typically displayed below the fit of the data, e.g. in the
+// bottom plot of a RatioPlot.
+type Pulls struct {
+	// Ranges are the X ranges of the bins.
+	Ranges []hbook.Range
+
+	// Values are the pulls of the bins.
+	// Bins with a vanishing uncertainty have a NaN pull and
+	// are not drawn.
+	Values []float64
+
+	// Bars enables the drawing of the pulls as bars.
+	// Pulls are drawn as points otherwise.
+	Bars bool
+
+	// FillColor is the color used to fill the bars.
+	// Use nil to disable the filling.
+	FillColor color.Color
+
+	// LineStyle is the style of the outline of the bars.
+	// Use zero width to disable.
+	LineStyle draw.LineStyle
+
+	// GlyphStyle is the style of the points.
+	GlyphStyle draw.GlyphStyle
+
+	// RefLines are the Y values of the horizontal reference lines.
+	// Default is {0, -2, +2}.
+	RefLines []float64
+
+	// RefStyle is the style of the reference lines.
+	RefStyle draw.LineStyle
+}
+
+// NewH1DPulls returns the pulls of the data histogram with regard to
+// the model histogram.
+// The uncertainty of each bin is the quadratic sum of the errors of the
+// data and model histograms.
+//
+// NewH1DPulls panics if the histograms do not have the same number of bins.
+func NewH1DPulls(data, model *hbook.H1D) *Pulls {
+	if data.Len() != model.Len() {
+		panic("hplot: data and model histograms with different number of bins")
+	}
+	return newPulls(data, func(i int) (float64, float64) {
+		return model.Value(i), model.Error(i)
+	})
+}
+
+// NewFuncPulls returns the pulls of the data histogram with regard to
+// the model function, evaluated at the center of each bin.
+// The uncertainty of each bin is the error of the data histogram.
+func NewFuncPulls(data *hbook.H1D, f func(x float64) float64) *Pulls {
+	return newPulls(data, func(i int) (float64, float64) {
+		return f(data.Binning.Bins[i].XMid()), 0
+	})
+}
+
+func newPulls(data *hbook.H1D, model func(i int) (v, err float64)) *Pulls {
+	n := data.Len()
+	p := &Pulls{
+		Ranges:     make([]hbook.Range, n),
+		Values:     make([]float64, n),
+		FillColor:  color.Gray{Y: 128},
+		LineStyle:  plotter.DefaultLineStyle,
+		GlyphStyle: plotter.DefaultGlyphStyle,
+		RefLines:   []float64{0, -2, +2},
+		RefStyle: draw.LineStyle{
+			Color:  color.Gray{Y: 100},
+			Width:  vg.Points(1),
+			Dashes: []vg.Length{vg.Points(4), vg.Points(2)},
+		},
+	}
+	p.GlyphStyle.Shape = draw.CircleGlyph{}
+
+	for i, bin := range data.Binning.Bins {
+		p.Ranges[i] = bin.Range
+		var (
+			v, e  = model(i)
+			de    = data.Error(i)
+			sigma = math.Sqrt(de*de + e*e)
+		)
+		switch sigma {
+		case 0:
+			p.Values[i] = math.NaN()
+		default:
+			p.Values[i] = (data.Value(i) - v) / sigma
+		}
+	}
+	return p
+}
+
+// Plot implements the Plotter interface, drawing the reference lines
+// and then the pulls.
+func (p *Pulls) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+
+	if p.RefStyle.Width != 0 {
+		for _, y := range p.RefLines {
+			line := c.ClipLinesX([]vg.Point{
+				{X: trX(plt.X.Min), Y: trY(y)},
+				{X: trX(plt.X.Max), Y: trY(y)},
+			})
+			c.StrokeLines(p.RefStyle, line...)
+		}
+	}
+
+	for i, v := range p.Values {
+		if math.IsNaN(v) {
+			continue
+		}
+		rng := p.Ranges[i]
+		if !p.Bars {
+			pt := vg.Point{X: trX(0.5 * (rng.Min + rng.Max)), Y: trY(v)}
+			if c.Contains(pt) {
+				c.DrawGlyph(p.GlyphStyle, pt)
+			}
+			continue
+		}
+
+		var (
+			xmin = trX(rng.Min)
+			xmax = trX(rng.Max)
+			y0   = trY(0)
+			y1   = trY(v)
+		)
+		pts := []vg.Point{
+			{X: xmin, Y: y0},
+			{X: xmax, Y: y0},
+			{X: xmax, Y: y1},
+			{X: xmin, Y: y1},
+		}
+		if p.FillColor != nil {
+			c.FillPolygon(p.FillColor, c.ClipPolygonXY(pts))
+		}
+		if p.LineStyle.Width != 0 {
+			pts = append(pts, pts[0])
+			c.StrokeLines(p.LineStyle, c.ClipLinesXY(pts)...)
+		}
+	}
+}
+
+// DataRange returns the minimum and maximum
+// x and y values, implementing the plot.DataRanger interface.
+// The Y range always contains the reference lines.
+func (p *Pulls) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin = math.Inf(+1)
+	xmax = math.Inf(-1)
+	ymin = math.Inf(+1)
+	ymax = math.Inf(-1)
+	for i, rng := range p.Ranges {
+		xmin = math.Min(xmin, rng.Min)
+		xmax = math.Max(xmax, rng.Max)
+		if v := p.Values[i]; !math.IsNaN(v) {
+			ymin = math.Min(ymin, v)
+			ymax = math.Max(ymax, v)
+		}
+	}
+	for _, y := range p.RefLines {
+		ymin = math.Min(ymin, y)
+		ymax = math.Max(ymax, y)
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// GlyphBoxes returns a slice of GlyphBoxes,
+// one for each of the points, implementing the
+// plot.GlyphBoxer interface.
+func (p *Pulls) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	if p.Bars {
+		return nil
+	}
+	bs := make([]plot.GlyphBox, 0, len(p.Values))
+	for i, v := range p.Values {
+		if math.IsNaN(v) {
+			continue
+		}
+		rng := p.Ranges[i]
+		bs = append(bs, plot.GlyphBox{
+			X:         plt.X.Norm(0.5 * (rng.Min + rng.Max)),
+			Y:         plt.Y.Norm(v),
+			Rectangle: p.GlyphStyle.Rectangle(),
+		})
+	}
+	return bs
+}
+
+var (
+	_ plot.Plotter    = (*Pulls)(nil)
+	_ plot.DataRanger = (*Pulls)(nil)
+	_ plot.GlyphBoxer = (*Pulls)(nil)
+)
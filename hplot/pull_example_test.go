@@ -0,0 +1,73 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"image/color"
+	"log"
+	"math"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of displaying the pulls of a histogram with regard to
+// a model, below the histogram.
+func ExamplePulls() {
+	const npoints = 10000
+
+	dist := distuv.Normal{
+		Mu:    0,
+		Sigma: 1,
+		Src:   rand.New(rand.NewSource(0)),
+	}
+
+	hist := hbook.NewH1D(20, -4, +4)
+	for i := 0; i < npoints; i++ {
+		hist.Fill(dist.Rand(), 1)
+	}
+
+	// expected number of entries per bin.
+	model := func(x float64) float64 {
+		const width = 8.0 / 20
+		return npoints * width * dist.Prob(x)
+	}
+
+	rp := hplot.NewRatioPlot()
+	rp.Ratio = 0.3
+
+	rp.Top.Title.Text = "Pulls"
+	rp.Top.Y.Label.Text = "Entries"
+
+	h := hplot.NewH1D(hist, hplot.WithYErrBars(true))
+	h.LineStyle.Width = 0
+	rp.Top.Add(h)
+
+	f := hplot.NewFunction(model)
+	f.LineStyle.Color = color.NRGBA{R: 255, A: 255}
+	f.Samples = 1000
+	rp.Top.Add(f)
+
+	pulls := hplot.NewFuncPulls(hist, model)
+	pulls.Bars = true
+	pulls.FillColor = color.NRGBA{B: 255, A: 128}
+
+	rp.Bottom.X.Label.Text = "X"
+	rp.Bottom.Y.Label.Text = "Pull"
+	rp.Bottom.Add(pulls)
+
+	const (
+		width  = 15 * vg.Centimeter
+		height = width / math.Phi
+	)
+
+	err := hplot.Save(rp, width, height, "testdata/pulls.png")
+	if err != nil {
+		log.Fatalf("error: %v\n", err)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestPulls(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExamplePulls, t, "pulls.png")
+}
+
+func TestH1DPulls(t *testing.T) {
+	data := hbook.NewH1D(3, 0, 3)
+	data.Fill(0.5, 4)
+	data.Fill(1.5, 9)
+
+	model := hbook.NewH1D(3, 0, 3)
+	model.Fill(0.5, 3)
+	model.Fill(1.5, 12)
+
+	pulls := hplot.NewH1DPulls(data, model)
+	want := []float64{1. / 5, -3. / 15}
+	for i, v := range want {
+		if got := pulls.Values[i]; math.Abs(got-v) > 1e-12 {
+			t.Fatalf("invalid pull[%d]: got=%v, want=%v", i, got, v)
+		}
+	}
+	if got := pulls.Values[2]; !math.IsNaN(got) {
+		t.Fatalf("invalid pull[2]: got=%v, want=NaN", got)
+	}
+}
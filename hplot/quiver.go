@@ -0,0 +1,314 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// VectorFielder wraps the Len and Vector methods.
+type VectorFielder interface {
+	// Len returns the number of vectors.
+	Len() int
+
+	// Vector returns the position (x, y) and the components (dx, dy)
+	// of a vector.
+	Vector(i int) (x, y, dx, dy float64)
+}
+
+// Vector is a 2-dim vector (DX, DY) located at the (X, Y) point.
+type Vector struct {
+	X, Y   float64
+	DX, DY float64
+}
+
+// Vectors implements the VectorFielder interface.
+type Vectors []Vector
+
+func (vs Vectors) Len() int { return len(vs) }
+func (vs Vectors) Vector(i int) (x, y, dx, dy float64) {
+	v := vs[i]
+	return v.X, v.Y, v.DX, v.DY
+}
+
+// VectorsFromFunc samples the vector field f over a regular grid of
+// nx by ny points spanning [xmin, xmax]x[ymin, ymax].
+func VectorsFromFunc(f func(x, y float64) (dx, dy float64), nx int, xmin, xmax float64, ny int, ymin, ymax float64) Vectors {
+	step := func(n int, min, max float64) float64 {
+		if n <= 1 {
+			return 0
+		}
+		return (max - min) / float64(n-1)
+	}
+
+	var (
+		sx = step(nx, xmin, xmax)
+		sy = step(ny, ymin, ymax)
+		vs = make(Vectors, 0, nx*ny)
+	)
+	for j := 0; j < ny; j++ {
+		y := ymin + float64(j)*sy
+		for i := 0; i < nx; i++ {
+			x := xmin + float64(i)*sx
+			dx, dy := f(x, y)
+			vs = append(vs, Vector{X: x, Y: y, DX: dx, DY: dy})
+		}
+	}
+	return vs
+}
+
+// Quiver implements the plot.Plotter interface, drawing a vector field
+// as arrows.
+//
+// The arrows start at the position of the vectors and have a length,
+// in data coordinates, equal to the components of the vectors times Scale.
+type Quiver struct {
+	Data VectorFielder
+
+	// Scale is the factor applied to the components of the vectors to
+	// get the length of the arrows, in data coordinates.
+	// If Scale is zero, it is chosen such that the longest arrow has
+	// the length of the typical distance between two vectors.
+	Scale float64
+
+	// LineStyle is the style of the arrows.
+	LineStyle draw.LineStyle
+
+	// HeadLength is the length of the heads of the arrows.
+	// The heads of the arrows shorter than twice HeadLength are
+	// reduced to half of the arrows.
+	HeadLength vg.Length
+
+	// HeadAngle is the half-angle of the heads of the arrows, in degrees.
+	HeadAngle float64
+}
+
+// NewQuiver returns a new Quiver plotter drawing the provided vector field.
+func NewQuiver(data VectorFielder) *Quiver {
+	return &Quiver{
+		Data:       data,
+		LineStyle:  plotter.DefaultLineStyle,
+		HeadLength: vg.Points(5),
+		HeadAngle:  25,
+	}
+}
+
+// scale returns the factor applied to the vector components.
+func (q *Quiver) scale() float64 {
+	if q.Scale != 0 {
+		return q.Scale
+	}
+
+	var (
+		n    = q.Data.Len()
+		xmin = math.Inf(+1)
+		xmax = math.Inf(-1)
+		ymin = math.Inf(+1)
+		ymax = math.Inf(-1)
+		vmax = 0.0
+	)
+	for i := 0; i < n; i++ {
+		x, y, dx, dy := q.Data.Vector(i)
+		xmin = math.Min(xmin, x)
+		xmax = math.Max(xmax, x)
+		ymin = math.Min(ymin, y)
+		ymax = math.Max(ymax, y)
+		vmax = math.Max(vmax, math.Hypot(dx, dy))
+	}
+	if vmax == 0 {
+		return 1
+	}
+
+	// typical distance between two vectors, assuming they are laid
+	// out over a square grid.
+	dist := 1.0
+	if m := math.Sqrt(float64(n)); m > 1 {
+		var (
+			wx = xmax - xmin
+			wy = ymax - ymin
+		)
+		switch {
+		case wx == 0:
+			dist = wy / (m - 1)
+		case wy == 0:
+			dist = wx / (m - 1)
+		default:
+			dist = math.Min(wx, wy) / (m - 1)
+		}
+	}
+	return 0.9 * dist / vmax
+}
+
+// Plot implements the Plotter interface, drawing the arrows.
+func (q *Quiver) Plot(c draw.Canvas, p *plot.Plot) {
+	var (
+		trX, trY = p.Transforms(&c)
+		scale    = q.scale()
+	)
+	for i := 0; i < q.Data.Len(); i++ {
+		x, y, dx, dy := q.Data.Vector(i)
+		var (
+			beg = vg.Point{X: trX(x), Y: trY(y)}
+			end = vg.Point{X: trX(x + scale*dx), Y: trY(y + scale*dy)}
+		)
+		q.arrow(&c, beg, end)
+	}
+}
+
+// arrow draws an arrow from beg to end.
+func (q *Quiver) arrow(c *draw.Canvas, beg, end vg.Point) {
+	var (
+		d   = end.Sub(beg)
+		n   = vg.Length(math.Hypot(float64(d.X), float64(d.Y)))
+		sty = q.LineStyle
+	)
+	if n == 0 || sty.Width == 0 {
+		return
+	}
+
+	var (
+		head = q.HeadLength
+		cos  = float64(d.X / n)
+		sin  = float64(d.Y / n)
+		rad  = q.HeadAngle * math.Pi / 180
+	)
+	if head > n/2 {
+		head = n / 2
+	}
+
+	barb := func(angle float64) vg.Point {
+		var (
+			ca = math.Cos(angle)
+			sa = math.Sin(angle)
+		)
+		return vg.Point{
+			X: end.X - head*vg.Length(cos*ca-sin*sa),
+			Y: end.Y - head*vg.Length(sin*ca+cos*sa),
+		}
+	}
+
+	var (
+		left  = barb(+rad)
+		right = barb(-rad)
+		base  = vg.Point{
+			X: end.X - head*vg.Length(cos*math.Cos(rad)),
+			Y: end.Y - head*vg.Length(sin*math.Cos(rad)),
+		}
+	)
+
+	c.StrokeLines(sty, c.ClipLinesXY([]vg.Point{beg, base})...)
+	if sty.Color == nil {
+		return
+	}
+	sty.Dashes = nil
+	tri := []vg.Point{end, left, right}
+	c.FillPolygon(sty.Color, c.ClipPolygonXY(tri))
+	c.StrokeLines(sty, c.ClipLinesXY(append(tri, end))...)
+}
+
+// DataRange implements the DataRange method
+// of the plot.DataRanger interface.
+// The data range includes the tips of the arrows.
+func (q *Quiver) DataRange() (xmin, xmax, ymin, ymax float64) {
+	xmin = math.Inf(+1)
+	xmax = math.Inf(-1)
+	ymin = math.Inf(+1)
+	ymax = math.Inf(-1)
+
+	scale := q.scale()
+	for i := 0; i < q.Data.Len(); i++ {
+		x, y, dx, dy := q.Data.Vector(i)
+		for _, pt := range [][2]float64{
+			{x, y},
+			{x + scale*dx, y + scale*dy},
+		} {
+			xmin = math.Min(xmin, pt[0])
+			xmax = math.Max(xmax, pt[0])
+			ymin = math.Min(ymin, pt[1])
+			ymax = math.Max(ymax, pt[1])
+		}
+	}
+	return xmin, xmax, ymin, ymax
+}
+
+// Thumbnail draws an arrow, implementing the plot.Thumbnailer interface.
+func (q *Quiver) Thumbnail(c *draw.Canvas) {
+	y := c.Center().Y
+	q.arrow(c, vg.Point{X: c.Min.X, Y: y}, vg.Point{X: c.Max.X, Y: y})
+}
+
+// QuiverKey implements the plot.Plotter interface, drawing a horizontal
+// reference arrow with the length of a vector of magnitude U, scaled as
+// the arrows of a Quiver, followed by a label.
+type QuiverKey struct {
+	Quiver *Quiver
+
+	// X and Y are the position of the tail of the arrow, normalized
+	// with regard to the data area of the plot: (0, 0) is the
+	// bottom-left corner and (1, 1) the top-right corner.
+	X, Y float64
+
+	// U is the magnitude of the reference vector.
+	U float64
+
+	Label     string         // label of the reference arrow
+	TextStyle draw.TextStyle // text style of the label
+}
+
+// Key returns a new reference arrow for a vector of magnitude u, located
+// at the top-left corner of the data area.
+func (q *Quiver) Key(u float64, label string) *QuiverKey {
+	return &QuiverKey{
+		Quiver: q,
+		X:      0.05,
+		Y:      0.95,
+		U:      u,
+		Label:  label,
+		TextStyle: draw.TextStyle{
+			Color:   color.Black,
+			Font:    DefaultStyle.Fonts.Tick,
+			Handler: DefaultStyle.TextHandler,
+			YAlign:  draw.YCenter,
+		},
+	}
+}
+
+// Plot implements the Plotter interface, drawing the reference arrow
+// and its label.
+func (key *QuiverKey) Plot(c draw.Canvas, p *plot.Plot) {
+	var (
+		trX, _ = p.Transforms(&c)
+		size   = c.Size()
+		beg    = vg.Point{
+			X: c.Min.X + vg.Length(key.X)*size.X,
+			Y: c.Min.Y + vg.Length(key.Y)*size.Y,
+		}
+		x0  = 0.5 * (p.X.Min + p.X.Max)
+		n   = trX(x0+key.Quiver.scale()*key.U) - trX(x0)
+		end = vg.Point{X: beg.X + n, Y: beg.Y}
+	)
+	key.Quiver.arrow(&c, beg, end)
+
+	if key.Label == "" {
+		return
+	}
+	pad := key.TextStyle.Font.Size / 2
+	c.FillText(key.TextStyle, vg.Point{X: end.X + pad, Y: end.Y}, key.Label)
+}
+
+// check interfaces
+var (
+	_ VectorFielder    = (Vectors)(nil)
+	_ plot.Plotter     = (*Quiver)(nil)
+	_ plot.DataRanger  = (*Quiver)(nil)
+	_ plot.Thumbnailer = (*Quiver)(nil)
+	_ plot.Plotter     = (*QuiverKey)(nil)
+)
@@ -0,0 +1,79 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"image/color"
+	"log"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// An example of displaying the magnetic field map of two parallel wires,
+// carrying opposite currents, with a reference arrow.
+func ExampleQuiver() {
+	wires := []struct{ x, y, i float64 }{
+		{-1, 0, +1},
+		{+1, 0, -1},
+	}
+	field := func(x, y float64) (bx, by float64) {
+		for _, w := range wires {
+			var (
+				dx = x - w.x
+				dy = y - w.y
+				r2 = dx*dx + dy*dy
+			)
+			if r2 < 0.1 {
+				return 0, 0
+			}
+			bx += -w.i * dy / r2
+			by += +w.i * dx / r2
+		}
+		return bx, by
+	}
+
+	q := hplot.NewQuiver(hplot.VectorsFromFunc(field, 17, -2, 2, 17, -2, 2))
+	q.Scale = 0.1
+	q.LineStyle.Color = color.NRGBA{R: 0, G: 114, B: 178, A: 255}
+
+	key := q.Key(2, "2 T")
+	key.X = 0.05
+	key.Y = 0.05
+
+	p := hplot.New()
+	p.Title.Text = "Magnetic field of two wires"
+	p.X.Label.Text = "x [cm]"
+	p.Y.Label.Text = "y [cm]"
+	p.Add(q, key)
+	p.Legend.Add("B field", q)
+	p.Legend.Top = true
+
+	// wires with a current flowing out of and into the page.
+	for _, w := range wires {
+		sca, err := plotter.NewScatter(plotter.XYs{{X: w.x, Y: w.y}})
+		if err != nil {
+			log.Fatalf("could not create wire glyph: %+v", err)
+		}
+		sca.GlyphStyle.Color = color.NRGBA{R: 213, G: 94, A: 255}
+		sca.GlyphStyle.Radius = vg.Points(4)
+		sca.GlyphStyle.Shape = draw.RingGlyph{}
+		if w.i < 0 {
+			sca.GlyphStyle.Shape = draw.CrossGlyph{}
+		}
+		p.Add(sca)
+	}
+
+	// make room for the legend and the reference arrow.
+	p.Y.Min = -2.6
+	p.Y.Max = +2.6
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/quiver.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/cmpimg"
+)
+
+func TestQuiver(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleQuiver, t, "quiver.png")
+}
+
+func TestQuiverDataRange(t *testing.T) {
+	vs := hplot.VectorsFromFunc(func(x, y float64) (float64, float64) {
+		return x, 2 * y
+	}, 3, 0, 2, 2, 0, 1)
+
+	if got, want := vs, (hplot.Vectors{
+		{X: 0, Y: 0, DX: 0, DY: 0},
+		{X: 1, Y: 0, DX: 1, DY: 0},
+		{X: 2, Y: 0, DX: 2, DY: 0},
+		{X: 0, Y: 1, DX: 0, DY: 2},
+		{X: 1, Y: 1, DX: 1, DY: 2},
+		{X: 2, Y: 1, DX: 2, DY: 2},
+	}); len(got) != len(want) {
+		t.Fatalf("invalid number of vectors: got=%d, want=%d", len(got), len(want))
+	} else {
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("invalid vector %d: got=%v, want=%v", i, got[i], want[i])
+			}
+		}
+	}
+
+	q := hplot.NewQuiver(vs)
+	q.Scale = 0.5
+	xmin, xmax, ymin, ymax := q.DataRange()
+	if xmin != 0 || xmax != 3 || ymin != 0 || ymax != 2 {
+		t.Fatalf("invalid data range: got=[%v, %v]x[%v, %v]", xmin, xmax, ymin, ymax)
+	}
+
+	// the longest arrow spans 90% of the typical distance between vectors.
+	q.Scale = 0
+	_, xmax, _, ymax = q.DataRange()
+	var (
+		dist = 1 / (math.Sqrt(6) - 1)
+		want = 0.9 * dist / math.Hypot(2, 2)
+	)
+	if got, want := ymax, 1+2*want; math.Abs(got-want) > 1e-12 {
+		t.Fatalf("invalid auto-scaled y-max: got=%v, want=%v", got, want)
+	}
+	if got, want := xmax, 2+2*want; math.Abs(got-want) > 1e-12 {
+		t.Fatalf("invalid auto-scaled x-max: got=%v, want=%v", got, want)
+	}
+}
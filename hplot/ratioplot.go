@@ -5,6 +5,11 @@
 package hplot
 
 import (
+	"fmt"
+	"image/color"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/floats/scalar"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
@@ -49,6 +54,68 @@ func NewRatioPlot() *RatioPlot {
 	return rp
 }
 
+// NewH1DRatioPlot returns a new ratio plot comparing the data and model
+// 1-dim histograms.
+//
+// The top panel displays the data histogram, as markers with error bars,
+// on top of the filled model histogram.
+// The bottom panel displays the data/model ratio, with the uncertainties of
+// both histograms propagated, and a reference line at 1.
+// Bins with an empty model are not displayed in the bottom panel.
+//
+// NewH1DRatioPlot returns an error if the binnings of the two histograms
+// are not compatible.
+func NewH1DRatioPlot(data, model *hbook.H1D) (*RatioPlot, error) {
+	if data.Len() != model.Len() {
+		return nil, fmt.Errorf(
+			"hplot: data and model histograms have different number of bins (%d != %d)",
+			data.Len(), model.Len(),
+		)
+	}
+	for i, bin := range data.Binning.Bins {
+		ref := model.Binning.Bins[i]
+		if !scalar.EqualWithinAbsOrRel(bin.XMin(), ref.XMin(), 1e-12, 1e-12) ||
+			!scalar.EqualWithinAbsOrRel(bin.XMax(), ref.XMax(), 1e-12, 1e-12) {
+			return nil, fmt.Errorf(
+				"hplot: data and model histograms have different edges for bin %d ([%v, %v] != [%v, %v])",
+				i, bin.XMin(), bin.XMax(), ref.XMin(), ref.XMax(),
+			)
+		}
+	}
+
+	ratio, err := hbook.DivideH1D(data, model, hbook.DivIgnoreNaNs())
+	if err != nil {
+		return nil, fmt.Errorf("hplot: could not compute data/model ratio: %w", err)
+	}
+
+	rp := NewRatioPlot()
+
+	hmodel := NewH1D(model)
+	hmodel.FillColor = color.NRGBA{B: 255, A: 100}
+
+	hdata := NewH1D(data, WithYErrBars(true), WithGlyphStyle(draw.GlyphStyle{
+		Shape:  draw.CircleGlyph{},
+		Color:  color.Black,
+		Radius: vg.Points(2),
+	}))
+	hdata.LineStyle.Width = 0
+	rp.Top.Add(hmodel, hdata)
+
+	ref := HLine(1, nil, nil)
+	ref.Line.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+	rp.Bottom.Add(ref)
+	rp.Bottom.Add(NewS2D(ratio, WithXErrBars(true), WithYErrBars(true)))
+	rp.Bottom.Y.Label.Text = "Ratio"
+
+	// share the same X range between both panels.
+	for _, p := range []*Plot{rp.Top, rp.Bottom} {
+		p.X.Min = model.XMin()
+		p.X.Max = model.XMax()
+	}
+
+	return rp, nil
+}
+
 // Draw draws a ratio plot to a draw.Canvas.
 //
 // Plotters are drawn in the order in which they were
@@ -81,3 +81,43 @@ func ExampleRatioPlot() {
 		log.Fatalf("error: %v\n", err)
 	}
 }
+
+func ExampleNewH1DRatioPlot() {
+	const npoints = 10000
+
+	var (
+		rnd  = rand.New(rand.NewSource(1234))
+		sig  = distuv.Normal{Mu: 0, Sigma: 1, Src: rnd}
+		data = hbook.NewH1D(20, -4, +4)
+		mc   = hbook.NewH1D(20, -4, +4)
+	)
+
+	for i := 0; i < npoints; i++ {
+		data.Fill(sig.Rand()+0.1, 1)
+		mc.Fill(sig.Rand(), 1)
+	}
+
+	rp, err := hplot.NewH1DRatioPlot(data, mc)
+	if err != nil {
+		log.Fatalf("could not create ratio plot: %+v", err)
+	}
+
+	rp.Top.Title.Text = "Data/MC"
+	rp.Top.Y.Label.Text = "Entries"
+	rp.Top.Add(hplot.NewGrid())
+
+	rp.Bottom.X.Label.Text = "X"
+	rp.Bottom.Y.Min = 0
+	rp.Bottom.Y.Max = 2
+	rp.Bottom.Add(hplot.NewGrid())
+
+	const (
+		width  = 15 * vg.Centimeter
+		height = width / math.Phi
+	)
+
+	err = hplot.Save(rp, width, height, "testdata/h1d_ratio_plot.png")
+	if err != nil {
+		log.Fatalf("error: %v\n", err)
+	}
+}
@@ -7,9 +7,46 @@ package hplot_test
 import (
 	"testing"
 
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
 	"gonum.org/v1/plot/cmpimg"
 )
 
 func TestRatioPlot(t *testing.T) {
 	checkPlot(cmpimg.CheckPlot)(ExampleRatioPlot, t, "diff_plot.png")
 }
+
+func TestH1DRatioPlot(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleNewH1DRatioPlot, t, "h1d_ratio_plot.png")
+}
+
+func TestH1DRatioPlotInvalidBinning(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		data  *hbook.H1D
+		model *hbook.H1D
+	}{
+		{
+			name:  "edges",
+			data:  hbook.NewH1D(10, 0, 10),
+			model: hbook.NewH1D(10, 0, 20),
+		},
+		{
+			name:  "more-data-bins",
+			data:  hbook.NewH1D(20, 0, 10),
+			model: hbook.NewH1D(10, 0, 10),
+		},
+		{
+			name:  "more-model-bins",
+			data:  hbook.NewH1D(10, 0, 10),
+			model: hbook.NewH1D(20, 0, 10),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := hplot.NewH1DRatioPlot(tc.data, tc.model)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
@@ -158,6 +158,15 @@ func NewS2D(data plotter.XYer, opts ...Options) *S2D {
 		_ = s.withBand()
 	}
 
+	if w := cfg.bars.capw; w != nil {
+		if s.XErrs != nil {
+			s.XErrs.CapWidth = *w
+		}
+		if s.YErrs != nil {
+			s.YErrs.CapWidth = *w
+		}
+	}
+
 	if cfg.glyph != (draw.GlyphStyle{}) {
 		s.GlyphStyle = cfg.glyph
 	}
@@ -187,3 +187,42 @@ func ExampleS2D_withStepsKind_withBand() {
 		log.Fatal(err)
 	}
 }
+
+// ExampleS2D_withAsymErrorBars draws some data points with asymmetric
+// y-errors and x-errors spanning the bin widths, in the style of a ROOT
+// TGraphAsymmErrors.
+func ExampleS2D_withAsymErrorBars() {
+	pts := []hbook.Point2D{
+		{X: 0.5, Y: 2, ErrX: hbook.Range{Min: 0.5, Max: 0.5}, ErrY: hbook.Range{Min: 1.3, Max: 2.6}},
+		{X: 1.5, Y: 5, ErrX: hbook.Range{Min: 0.5, Max: 0.5}, ErrY: hbook.Range{Min: 2.2, Max: 3.4}},
+		{X: 3.0, Y: 9, ErrX: hbook.Range{Min: 1.0, Max: 1.0}, ErrY: hbook.Range{Min: 2.9, Max: 4.1}},
+		{X: 5.0, Y: 4, ErrX: hbook.Range{Min: 1.0, Max: 1.0}, ErrY: hbook.Range{Min: 1.9, Max: 3.2}},
+		{X: 7.0, Y: 1, ErrX: hbook.Range{Min: 1.0, Max: 1.0}, ErrY: hbook.Range{Min: 0.8, Max: 2.3}},
+	}
+	s2d := hbook.NewS2D(pts...)
+
+	p := hplot.New()
+	p.Title.Text = "Scatter-2D (with asymmetric error bars)"
+	p.X.Label.Text = "X"
+	p.Y.Label.Text = "Y"
+	p.Add(plotter.NewGrid())
+
+	s := hplot.NewS2D(s2d,
+		hplot.WithXErrBars(true),
+		hplot.WithYErrBars(true),
+		hplot.WithCapWidth(0),
+		hplot.WithGlyphStyle(draw.GlyphStyle{
+			Color:  color.Black,
+			Radius: vg.Points(2.5),
+			Shape:  draw.CircleGlyph{},
+		}),
+	)
+
+	p.Add(s)
+	p.Legend.Add("data", s)
+
+	err := p.Save(10*vg.Centimeter, 10*vg.Centimeter, "testdata/s2d_asym_errbars.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -16,6 +16,7 @@ func TestS2D(t *testing.T) {
 
 func TestScatter2DWithErrorBars(t *testing.T) {
 	checkPlot(cmpimg.CheckPlot)(ExampleS2D_withErrorBars, t, "s2d_errbars.png")
+	checkPlot(cmpimg.CheckPlot)(ExampleS2D_withAsymErrorBars, t, "s2d_asym_errbars.png")
 }
 
 func TestScatter2DWithBand(t *testing.T) {
@@ -0,0 +1,632 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Spec is a declarative description of a plot, holding its data and
+// its style.
+//
+// A Spec can be serialized to (and deserialized from) JSON with the
+// encoding/json package, and turned into a plot with Load.
+type Spec struct {
+	Title string   `json:"title,omitempty"`
+	X     AxisSpec `json:"x"`
+	Y     AxisSpec `json:"y"`
+
+	Frame       bool `json:"frame,omitempty"`        // draw a frame around the data area
+	InnerTicks  bool `json:"inner_ticks,omitempty"`  // draw the tick marks inside the data area
+	MirrorTicks bool `json:"mirror_ticks,omitempty"` // draw the tick marks on all the edges
+
+	Grid   bool        `json:"grid,omitempty"` // attach a grid with major and minor lines
+	Legend *LegendSpec `json:"legend,omitempty"`
+
+	Plotters []PlotterSpec `json:"plotters"`
+}
+
+// AxisSpec describes an axis of a plot.
+type AxisSpec struct {
+	Label string `json:"label,omitempty"`
+
+	// Min and Max are the range of the axis.
+	// If nil, the range is computed from the data.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+
+	// Log selects a logarithmic scale for the axis.
+	Log bool `json:"log,omitempty"`
+}
+
+// LegendSpec describes the position of the legend of a plot.
+type LegendSpec struct {
+	Top  bool `json:"top,omitempty"`
+	Left bool `json:"left,omitempty"`
+}
+
+// Types of the plotters described by a PlotterSpec.
+const (
+	SpecH1D     = "h1d"     // an hplot.H1D
+	SpecS2D     = "s2d"     // an hplot.S2D
+	SpecLine    = "line"    // a gonum/plot/plotter.Line
+	SpecScatter = "scatter" // a gonum/plot/plotter.Scatter
+)
+
+// PlotterSpec describes a plotter and its data.
+type PlotterSpec struct {
+	// Type is the type of the plotter: h1d, s2d, line or scatter.
+	Type string `json:"type"`
+
+	// Legend is the label of the legend entry of the plotter.
+	// No legend entry is added if Legend is empty.
+	Legend string `json:"legend,omitempty"`
+
+	// H1D is the histogram of a h1d plotter.
+	H1D *H1DSpec `json:"h1d,omitempty"`
+
+	// Points are the data points of s2d, line and scatter plotters.
+	Points []PointSpec `json:"points,omitempty"`
+
+	XErrs bool `json:"xerrs,omitempty"` // display the X error bars
+	YErrs bool `json:"yerrs,omitempty"` // display the Y error bars
+	Band  bool `json:"band,omitempty"`  // display a band between the Y error bars
+	Steps bool `json:"steps,omitempty"` // connect the points of a s2d plotter with steps
+	LogY  bool `json:"logy,omitempty"`  // handle a log-scaled Y axis
+
+	Style StyleSpec `json:"style"`
+}
+
+// H1DSpec describes the content of a 1-dim histogram.
+type H1DSpec struct {
+	Name     string      `json:"name,omitempty"`
+	Bins     []BinSpec   `json:"bins"`
+	Outflows [2]DistSpec `json:"outflows"` // underflow and overflow
+}
+
+// BinSpec describes a bin of a 1-dim histogram.
+type BinSpec struct {
+	XMin float64 `json:"xmin"`
+	XMax float64 `json:"xmax"`
+	DistSpec
+}
+
+// DistSpec describes the moments of a 1-dim distribution.
+type DistSpec struct {
+	N      int64   `json:"n"`
+	SumW   float64 `json:"sumw"`
+	SumW2  float64 `json:"sumw2"`
+	SumWX  float64 `json:"sumwx"`
+	SumWX2 float64 `json:"sumwx2"`
+}
+
+// PointSpec describes a 2-dim data point and its asymmetric errors.
+type PointSpec struct {
+	X    float64     `json:"x"`
+	Y    float64     `json:"y"`
+	XErr *[2]float64 `json:"xerr,omitempty"` // low and high X errors
+	YErr *[2]float64 `json:"yerr,omitempty"` // low and high Y errors
+}
+
+// StyleSpec describes the style of a plotter.
+//
+// Colors are written as "#rrggbb" or "#rrggbbaa" hexadecimal strings.
+// Lengths are expressed in points.
+type StyleSpec struct {
+	// LineColor is the color of the lines.
+	// If empty, the default color of the plotter is used.
+	LineColor string `json:"line_color,omitempty"`
+
+	// LineWidth is the width of the lines.
+	// If nil, the default width of the plotter is used.
+	LineWidth *float64 `json:"line_width,omitempty"`
+
+	// Dashes is the dash pattern of the lines.
+	Dashes []float64 `json:"dashes,omitempty"`
+
+	// FillColor is the fill color of the plotter.
+	// The plotter is not filled if FillColor is empty.
+	FillColor string `json:"fill_color,omitempty"`
+
+	// Glyph is the shape of the glyphs: circle, ring, square, box,
+	// triangle, pyramid, cross or plus.
+	// If empty, the default shape of the plotter is used.
+	Glyph string `json:"glyph,omitempty"`
+
+	// GlyphColor is the color of the glyphs.
+	// If empty, the default color of the plotter is used.
+	GlyphColor string `json:"glyph_color,omitempty"`
+
+	// GlyphRadius is the radius of the glyphs.
+	// If nil, the default radius of the plotter is used.
+	GlyphRadius *float64 `json:"glyph_radius,omitempty"`
+}
+
+// Spec returns the declarative description of the plot, its data and its
+// style.
+//
+// Only the plotters added with Plot.Add are described.
+// The supported plotters are *hplot.H1D, *hplot.S2D, *plotter.Line and
+// *plotter.Scatter.
+// Spec returns an error if the plot holds any other plotter.
+//
+// The entries of the legend of the plot can not be retrieved from a
+// gonum/plot.Legend: the Legend fields of the returned PlotterSpecs are
+// left empty.
+func (p *Plot) Spec() (Spec, error) {
+	spec := Spec{
+		Title:       p.Title.Text,
+		X:           axisSpec(&p.X),
+		Y:           axisSpec(&p.Y),
+		Frame:       p.Style.Frame,
+		InnerTicks:  p.Style.Ticks.Inside,
+		MirrorTicks: p.Style.Ticks.Mirror,
+		Grid:        p.Grid != nil,
+		Legend: &LegendSpec{
+			Top:  p.Legend.Top,
+			Left: p.Legend.Left,
+		},
+	}
+
+	for _, v := range p.plotters {
+		var ps PlotterSpec
+		switch v := v.(type) {
+		case *H1D:
+			ps = h1dSpec(v)
+		case *S2D:
+			ps = s2dSpec(v)
+		case *plotter.Line:
+			ps = PlotterSpec{
+				Type:   SpecLine,
+				Points: xysSpec(v.XYs),
+				Style: StyleSpec{
+					FillColor: colorSpec(v.FillColor),
+				},
+			}
+			lineSpec(&ps.Style, v.LineStyle)
+			ps.Steps = v.StepStyle != plotter.NoStep
+			if ps.Steps && v.StepStyle != plotter.PreStep {
+				return spec, fmt.Errorf("hplot: line step style %v not supported by Spec", v.StepStyle)
+			}
+		case *plotter.Scatter:
+			ps = PlotterSpec{
+				Type:   SpecScatter,
+				Points: xysSpec(v.XYs),
+			}
+			glyphSpec(&ps.Style, v.GlyphStyle)
+		default:
+			return spec, fmt.Errorf("hplot: plotter %T not supported by Spec", v)
+		}
+		spec.Plotters = append(spec.Plotters, ps)
+	}
+
+	return spec, nil
+}
+
+// Load creates a new plot from its declarative description.
+func Load(spec Spec) (*Plot, error) {
+	p := New()
+	p.Title.Text = spec.Title
+	p.X.Label.Text = spec.X.Label
+	p.Y.Label.Text = spec.Y.Label
+
+	sty := p.Style
+	sty.Frame = spec.Frame
+	sty.Ticks.Inside = spec.InnerTicks
+	sty.Ticks.Mirror = spec.MirrorTicks
+	sty.Apply(p)
+
+	if spec.Grid {
+		p.Grid = NewGridWithMinors()
+	}
+
+	if spec.Legend != nil {
+		p.Legend.Top = spec.Legend.Top
+		p.Legend.Left = spec.Legend.Left
+	}
+
+	for i, ps := range spec.Plotters {
+		v, err := loadPlotter(ps)
+		if err != nil {
+			return nil, fmt.Errorf("hplot: could not load plotter %d: %w", i, err)
+		}
+		p.Add(v)
+		if ps.Legend != "" {
+			p.Legend.Add(ps.Legend, v.(plot.Thumbnailer))
+		}
+	}
+
+	for _, ax := range []struct {
+		spec AxisSpec
+		axis *plot.Axis
+	}{
+		{spec.X, &p.X},
+		{spec.Y, &p.Y},
+	} {
+		if ax.spec.Min != nil {
+			ax.axis.Min = *ax.spec.Min
+		}
+		if ax.spec.Max != nil {
+			ax.axis.Max = *ax.spec.Max
+		}
+		if ax.spec.Log {
+			ax.axis.Scale = plot.LogScale{}
+			ax.axis.Tick.Marker = LogTicks{}
+		}
+	}
+
+	return p, nil
+}
+
+func loadPlotter(ps PlotterSpec) (plot.Plotter, error) {
+	switch ps.Type {
+	case SpecH1D:
+		if ps.H1D == nil {
+			return nil, fmt.Errorf("missing histogram of h1d plotter")
+		}
+		h, err := ps.H1D.hist()
+		if err != nil {
+			return nil, err
+		}
+		opts := []Options{
+			WithYErrBars(ps.YErrs),
+			WithBand(ps.Band),
+			WithLogY(ps.LogY),
+		}
+		v := NewH1D(h, opts...)
+		err = ps.Style.line(&v.LineStyle)
+		if err != nil {
+			return nil, err
+		}
+		err = ps.Style.glyph(&v.GlyphStyle)
+		if err != nil {
+			return nil, err
+		}
+		v.FillColor, err = parseColor(ps.Style.FillColor)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+
+	case SpecS2D:
+		pts := make([]hbook.Point2D, len(ps.Points))
+		for i, pt := range ps.Points {
+			pts[i] = hbook.Point2D{X: pt.X, Y: pt.Y}
+			if pt.XErr != nil {
+				pts[i].ErrX = hbook.Range{Min: pt.XErr[0], Max: pt.XErr[1]}
+			}
+			if pt.YErr != nil {
+				pts[i].ErrY = hbook.Range{Min: pt.YErr[0], Max: pt.YErr[1]}
+			}
+		}
+		opts := []Options{
+			WithXErrBars(ps.XErrs),
+			WithYErrBars(ps.YErrs),
+			WithBand(ps.Band),
+		}
+		if ps.Steps {
+			opts = append(opts, WithStepsKind(HiSteps))
+		}
+		v := NewS2D(hbook.NewS2D(pts...), opts...)
+		err := ps.Style.line(&v.LineStyle)
+		if err != nil {
+			return nil, err
+		}
+		err = ps.Style.glyph(&v.GlyphStyle)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+
+	case SpecLine:
+		v, err := plotter.NewLine(ps.xys())
+		if err != nil {
+			return nil, err
+		}
+		err = ps.Style.line(&v.LineStyle)
+		if err != nil {
+			return nil, err
+		}
+		v.FillColor, err = parseColor(ps.Style.FillColor)
+		if err != nil {
+			return nil, err
+		}
+		if ps.Steps {
+			v.StepStyle = plotter.PreStep
+		}
+		return v, nil
+
+	case SpecScatter:
+		v, err := plotter.NewScatter(ps.xys())
+		if err != nil {
+			return nil, err
+		}
+		err = ps.Style.glyph(&v.GlyphStyle)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+
+	default:
+		return nil, fmt.Errorf("unknown plotter type %q", ps.Type)
+	}
+}
+
+func axisSpec(ax *plot.Axis) AxisSpec {
+	var (
+		min = ax.Min
+		max = ax.Max
+	)
+	_, log := ax.Scale.(plot.LogScale)
+	return AxisSpec{
+		Label: ax.Label.Text,
+		Min:   &min,
+		Max:   &max,
+		Log:   log,
+	}
+}
+
+func h1dSpec(v *H1D) PlotterSpec {
+	var (
+		bng = &v.Hist.Binning
+		h   = &H1DSpec{
+			Name: v.Hist.Name(),
+			Bins: make([]BinSpec, len(bng.Bins)),
+			Outflows: [2]DistSpec{
+				distSpec(bng.Outflows[0]),
+				distSpec(bng.Outflows[1]),
+			},
+		}
+	)
+	for i, bin := range bng.Bins {
+		h.Bins[i] = BinSpec{
+			XMin:     bin.Range.Min,
+			XMax:     bin.Range.Max,
+			DistSpec: distSpec(bin.Dist),
+		}
+	}
+
+	ps := PlotterSpec{
+		Type:  SpecH1D,
+		H1D:   h,
+		YErrs: v.YErrs != nil,
+		Band:  v.Band != nil,
+		LogY:  v.LogY,
+		Style: StyleSpec{
+			FillColor: colorSpec(v.FillColor),
+		},
+	}
+	lineSpec(&ps.Style, v.LineStyle)
+	glyphSpec(&ps.Style, v.GlyphStyle)
+	return ps
+}
+
+func distSpec(d hbook.Dist1D) DistSpec {
+	return DistSpec{
+		N:      d.Dist.N,
+		SumW:   d.Dist.SumW,
+		SumW2:  d.Dist.SumW2,
+		SumWX:  d.Stats.SumWX,
+		SumWX2: d.Stats.SumWX2,
+	}
+}
+
+func (d DistSpec) dist() hbook.Dist1D {
+	var o hbook.Dist1D
+	o.Dist.N = d.N
+	o.Dist.SumW = d.SumW
+	o.Dist.SumW2 = d.SumW2
+	o.Stats.SumWX = d.SumWX
+	o.Stats.SumWX2 = d.SumWX2
+	return o
+}
+
+func (d DistSpec) add(o DistSpec) DistSpec {
+	d.N += o.N
+	d.SumW += o.SumW
+	d.SumW2 += o.SumW2
+	d.SumWX += o.SumWX
+	d.SumWX2 += o.SumWX2
+	return d
+}
+
+// hist creates the histogram described by the H1DSpec.
+func (spec *H1DSpec) hist() (h *hbook.H1D, err error) {
+	if len(spec.Bins) == 0 {
+		return nil, fmt.Errorf("histogram with no bins")
+	}
+	defer func() {
+		// hbook panics for invalid binnings.
+		if e := recover(); e != nil {
+			h = nil
+			err = fmt.Errorf("invalid histogram binning: %v", e)
+		}
+	}()
+
+	bins := make([]hbook.Range, len(spec.Bins))
+	for i, bin := range spec.Bins {
+		bins[i] = hbook.Range{Min: bin.XMin, Max: bin.XMax}
+	}
+	h = hbook.NewH1DFromBins(bins...)
+	if spec.Name != "" {
+		h.Annotation()["name"] = spec.Name
+	}
+
+	var (
+		bng = &h.Binning
+		tot = spec.Outflows[0].add(spec.Outflows[1])
+	)
+	for i, bin := range spec.Bins {
+		bng.Bins[i].Dist = bin.DistSpec.dist()
+		tot = tot.add(bin.DistSpec)
+	}
+	bng.Outflows[0] = spec.Outflows[0].dist()
+	bng.Outflows[1] = spec.Outflows[1].dist()
+	bng.Dist = tot.dist()
+
+	return h, nil
+}
+
+func s2dSpec(v *S2D) PlotterSpec {
+	ps := PlotterSpec{
+		Type:   SpecS2D,
+		Points: make([]PointSpec, v.Data.Len()),
+		XErrs:  v.XErrs != nil,
+		YErrs:  v.YErrs != nil,
+		Band:   v.Band != nil,
+		Steps:  v.Steps == HiSteps,
+	}
+	xerr, _ := v.Data.(plotter.XErrorer)
+	yerr, _ := v.Data.(plotter.YErrorer)
+	for i := range ps.Points {
+		pt := &ps.Points[i]
+		pt.X, pt.Y = v.Data.XY(i)
+		if xerr != nil {
+			lo, hi := xerr.XError(i)
+			pt.XErr = &[2]float64{lo, hi}
+		}
+		if yerr != nil {
+			lo, hi := yerr.YError(i)
+			pt.YErr = &[2]float64{lo, hi}
+		}
+	}
+	lineSpec(&ps.Style, v.LineStyle)
+	glyphSpec(&ps.Style, v.GlyphStyle)
+	return ps
+}
+
+func xysSpec(xys plotter.XYs) []PointSpec {
+	pts := make([]PointSpec, len(xys))
+	for i, xy := range xys {
+		pts[i] = PointSpec{X: xy.X, Y: xy.Y}
+	}
+	return pts
+}
+
+func (ps PlotterSpec) xys() plotter.XYs {
+	xys := make(plotter.XYs, len(ps.Points))
+	for i, pt := range ps.Points {
+		xys[i] = plotter.XY{X: pt.X, Y: pt.Y}
+	}
+	return xys
+}
+
+func lineSpec(spec *StyleSpec, sty draw.LineStyle) {
+	w := float64(sty.Width / vg.Points(1))
+	spec.LineColor = colorSpec(sty.Color)
+	spec.LineWidth = &w
+	for _, d := range sty.Dashes {
+		spec.Dashes = append(spec.Dashes, float64(d/vg.Points(1)))
+	}
+}
+
+func (spec StyleSpec) line(sty *draw.LineStyle) error {
+	if spec.LineColor != "" {
+		c, err := parseColor(spec.LineColor)
+		if err != nil {
+			return err
+		}
+		sty.Color = c
+	}
+	if spec.LineWidth != nil {
+		sty.Width = vg.Points(*spec.LineWidth)
+	}
+	sty.Dashes = nil
+	for _, d := range spec.Dashes {
+		sty.Dashes = append(sty.Dashes, vg.Points(d))
+	}
+	return nil
+}
+
+var glyphShapes = []struct {
+	name  string
+	shape draw.GlyphDrawer
+}{
+	{"circle", draw.CircleGlyph{}},
+	{"ring", draw.RingGlyph{}},
+	{"square", draw.SquareGlyph{}},
+	{"box", draw.BoxGlyph{}},
+	{"triangle", draw.TriangleGlyph{}},
+	{"pyramid", draw.PyramidGlyph{}},
+	{"cross", draw.CrossGlyph{}},
+	{"plus", draw.PlusGlyph{}},
+}
+
+func glyphSpec(spec *StyleSpec, sty draw.GlyphStyle) {
+	r := float64(sty.Radius / vg.Points(1))
+	spec.GlyphColor = colorSpec(sty.Color)
+	spec.GlyphRadius = &r
+	for _, g := range glyphShapes {
+		if g.shape == sty.Shape {
+			spec.Glyph = g.name
+		}
+	}
+}
+
+func (spec StyleSpec) glyph(sty *draw.GlyphStyle) error {
+	if spec.GlyphColor != "" {
+		c, err := parseColor(spec.GlyphColor)
+		if err != nil {
+			return err
+		}
+		sty.Color = c
+	}
+	if spec.GlyphRadius != nil {
+		sty.Radius = vg.Points(*spec.GlyphRadius)
+	}
+	if spec.Glyph == "" {
+		return nil
+	}
+	for _, g := range glyphShapes {
+		if g.name == spec.Glyph {
+			sty.Shape = g.shape
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown glyph shape %q", spec.Glyph)
+}
+
+// colorSpec returns the "#rrggbbaa" representation of c.
+// colorSpec returns "#rrggbb" for opaque colors, and an empty string
+// for a nil color.
+func colorSpec(c color.Color) string {
+	if c == nil {
+		return ""
+	}
+	v := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if v.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", v.R, v.G, v.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", v.R, v.G, v.B, v.A)
+}
+
+// parseColor parses a "#rrggbb" or "#rrggbbaa" color.
+// parseColor returns a nil color for an empty string.
+func parseColor(s string) (color.Color, error) {
+	if s == "" {
+		return nil, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 || !strings.HasPrefix(s, "#") {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
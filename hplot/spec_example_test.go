@@ -0,0 +1,85 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of creating a plot from a declarative JSON document,
+// as could be read from a configuration file or returned by a web service.
+func ExampleLoad() {
+	const doc = `{
+	"title": "Plot from a JSON spec",
+	"x": {"label": "m [GeV]", "min": 0, "max": 10},
+	"y": {"label": "entries", "min": 0, "max": 30},
+	"grid": true,
+	"frame": true,
+	"inner_ticks": true,
+	"legend": {"top": true},
+	"plotters": [
+		{
+			"type": "h1d",
+			"legend": "MC",
+			"h1d": {
+				"name": "mc",
+				"bins": [
+					{"xmin": 0, "xmax": 2, "n": 4, "sumw": 4, "sumw2": 4},
+					{"xmin": 2, "xmax": 4, "n": 12, "sumw": 12, "sumw2": 12},
+					{"xmin": 4, "xmax": 6, "n": 20, "sumw": 20, "sumw2": 20},
+					{"xmin": 6, "xmax": 8, "n": 9, "sumw": 9, "sumw2": 9},
+					{"xmin": 8, "xmax": 10, "n": 3, "sumw": 3, "sumw2": 3}
+				]
+			},
+			"style": {"line_color": "#0072b2", "fill_color": "#56b4e980"}
+		},
+		{
+			"type": "s2d",
+			"legend": "data",
+			"yerrs": true,
+			"points": [
+				{"x": 1, "y": 5, "yerr": [2.2, 2.2]},
+				{"x": 3, "y": 11, "yerr": [3.3, 3.3]},
+				{"x": 5, "y": 22, "yerr": [4.7, 4.7]},
+				{"x": 7, "y": 8, "yerr": [2.8, 2.8]},
+				{"x": 9, "y": 2, "yerr": [1.4, 1.4]}
+			],
+			"style": {"glyph": "circle", "glyph_color": "#000000", "glyph_radius": 2.5}
+		},
+		{
+			"type": "line",
+			"legend": "fit",
+			"points": [
+				{"x": 0, "y": 1.5}, {"x": 1, "y": 4.2}, {"x": 2, "y": 8.4},
+				{"x": 3, "y": 13.3}, {"x": 4, "y": 17.6}, {"x": 5, "y": 19.3},
+				{"x": 6, "y": 17.6}, {"x": 7, "y": 13.3}, {"x": 8, "y": 8.4},
+				{"x": 9, "y": 4.2}, {"x": 10, "y": 1.5}
+			],
+			"style": {"line_color": "#d55e00", "line_width": 2, "dashes": [4, 2]}
+		}
+	]
+}`
+
+	var spec hplot.Spec
+	err := json.NewDecoder(strings.NewReader(doc)).Decode(&spec)
+	if err != nil {
+		log.Fatalf("could not decode plot spec: %+v", err)
+	}
+
+	p, err := hplot.Load(spec)
+	if err != nil {
+		log.Fatalf("could not load plot spec: %+v", err)
+	}
+
+	err = p.Save(10*vg.Centimeter, -1, "testdata/spec.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/color"
+	"math"
+	"testing"
+
+	"go-hep.org/x/hep/hbook"
+	"go-hep.org/x/hep/hplot"
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+	"gonum.org/v1/plot/cmpimg"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+func TestLoad(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleLoad, t, "spec.png")
+}
+
+func TestSpecRoundTrip(t *testing.T) {
+	dist := distuv.Normal{Mu: 0, Sigma: 1, Src: rand.New(rand.NewSource(1234))}
+	h := hbook.NewH1D(20, -4, 4)
+	for i := 0; i < 1000; i++ {
+		h.Fill(dist.Rand(), 1)
+	}
+	h.Annotation()["name"] = "h1"
+
+	s2 := hbook.NewS2D(
+		hbook.Point2D{X: -2, Y: 50, ErrX: hbook.Range{Min: 0.5, Max: 0.5}, ErrY: hbook.Range{Min: 5, Max: 10}},
+		hbook.Point2D{X: 0, Y: 150, ErrX: hbook.Range{Min: 0.5, Max: 0.5}, ErrY: hbook.Range{Min: 12, Max: 12}},
+		hbook.Point2D{X: 2, Y: 60, ErrX: hbook.Range{Min: 0.5, Max: 0.5}, ErrY: hbook.Range{Min: 8, Max: 6}},
+	)
+
+	xys := make(plotter.XYs, 50)
+	for i := range xys {
+		x := -4 + 8*float64(i)/float64(len(xys)-1)
+		xys[i] = plotter.XY{X: x, Y: 160 * math.Exp(-0.5*x*x)}
+	}
+
+	p := hplot.New()
+	p.Title.Text = "round-trip"
+	p.X.Label.Text = "x"
+	p.Y.Label.Text = "y"
+	p.Style.Frame = true
+	p.Style.Ticks.Inside = true
+	p.Style.Apply(p)
+	p.Grid = hplot.NewGridWithMinors()
+
+	hh := hplot.NewH1D(h, hplot.WithYErrBars(true))
+	hh.LineStyle.Color = color.NRGBA{R: 255, A: 255}
+	hh.FillColor = color.NRGBA{R: 255, A: 64}
+	p.Add(hh)
+
+	ss := hplot.NewS2D(s2, hplot.WithXErrBars(true), hplot.WithYErrBars(true))
+	ss.GlyphStyle.Shape = draw.CircleGlyph{}
+	p.Add(ss)
+
+	line, err := plotter.NewLine(xys)
+	if err != nil {
+		t.Fatalf("could not create line: %+v", err)
+	}
+	line.LineStyle.Dashes = []vg.Length{vg.Points(4), vg.Points(2)}
+	p.Add(line)
+
+	pts := make(plotter.XYs, 0, len(xys)/5)
+	for i := 0; i < len(xys); i += 5 {
+		pts = append(pts, xys[i])
+	}
+	sca, err := plotter.NewScatter(pts)
+	if err != nil {
+		t.Fatalf("could not create scatter: %+v", err)
+	}
+	sca.GlyphStyle.Shape = draw.TriangleGlyph{}
+	sca.GlyphStyle.Color = color.NRGBA{B: 200, A: 255}
+	p.Add(sca)
+
+	spec, err := p.Spec()
+	if err != nil {
+		t.Fatalf("could not create spec: %+v", err)
+	}
+
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatalf("could not encode spec: %+v", err)
+	}
+
+	var got hplot.Spec
+	err = json.Unmarshal(raw, &got)
+	if err != nil {
+		t.Fatalf("could not decode spec: %+v", err)
+	}
+
+	pp, err := hplot.Load(got)
+	if err != nil {
+		t.Fatalf("could not load spec: %+v", err)
+	}
+
+	render := func(p *hplot.Plot) []byte {
+		t.Helper()
+		wt, err := p.WriterTo(10*vg.Centimeter, -1, "png")
+		if err != nil {
+			t.Fatalf("could not create writer: %+v", err)
+		}
+		buf := new(bytes.Buffer)
+		_, err = wt.WriteTo(buf)
+		if err != nil {
+			t.Fatalf("could not render plot: %+v", err)
+		}
+		return buf.Bytes()
+	}
+
+	if !bytes.Equal(render(p), render(pp)) {
+		t.Fatalf("round-tripped plot differs from original plot")
+	}
+}
+
+func TestSpecErrors(t *testing.T) {
+	p := hplot.New()
+	p.Add(hplot.NewFunction(math.Sin))
+	_, err := p.Spec()
+	if err == nil {
+		t.Fatalf("expected an error for an unsupported plotter")
+	}
+
+	for _, tc := range []struct {
+		spec hplot.PlotterSpec
+		want string
+	}{
+		{
+			spec: hplot.PlotterSpec{Type: "hexbin"},
+			want: `hplot: could not load plotter 0: unknown plotter type "hexbin"`,
+		},
+		{
+			spec: hplot.PlotterSpec{Type: hplot.SpecH1D},
+			want: `hplot: could not load plotter 0: missing histogram of h1d plotter`,
+		},
+		{
+			spec: hplot.PlotterSpec{
+				Type:  hplot.SpecLine,
+				Style: hplot.StyleSpec{LineColor: "red"},
+			},
+			want: `hplot: could not load plotter 0: invalid color "red"`,
+		},
+		{
+			spec: hplot.PlotterSpec{
+				Type:  hplot.SpecScatter,
+				Style: hplot.StyleSpec{Glyph: "star"},
+			},
+			want: `hplot: could not load plotter 0: unknown glyph shape "star"`,
+		},
+	} {
+		t.Run(tc.spec.Type, func(t *testing.T) {
+			_, err := hplot.Load(hplot.Spec{Plotters: []hplot.PlotterSpec{tc.spec}})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if got, want := err.Error(), tc.want; got != want {
+				t.Fatalf("invalid error:\ngot= %q\nwant=%q", got, want)
+			}
+		})
+	}
+}
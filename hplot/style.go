@@ -6,11 +6,13 @@ package hplot
 
 import (
 	"fmt"
+	"image/color"
 
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/font"
 	"gonum.org/v1/plot/font/liberation"
 	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 )
 
@@ -32,10 +34,65 @@ type Style struct {
 		Cache *font.Cache // cache of fonts for this plot.
 	}
 	TextHandler text.Handler
+
+	// LineWidth, if non-zero, is the width of the lines of the axes
+	// and of their tick marks.
+	LineWidth vg.Length
+
+	// Ticks controls how the tick marks of the axes are drawn.
+	Ticks struct {
+		Inside bool      // draw the tick marks inside the data area
+		Length vg.Length // length of the major tick marks drawn inside the data area
+		Mirror bool      // also draw the tick marks on the top and right edges
+
+		// Marker, if not nil, computes the tick marks and formats the
+		// tick labels of the X and Y axes, e.g. hplot.Ticks{Format: "%.1f"}.
+		Marker plot.Ticker
+	}
+
+	// Frame draws the top and right edges of the data area,
+	// closing the box formed by the X and Y axes.
+	Frame bool
+}
+
+// HEPStyle returns a style following the usual conventions of HEP
+// publications: larger fonts, tick marks drawn inside the data area
+// and mirrored on all edges, and a frame around the data area.
+func HEPStyle() Style {
+	sty := DefaultStyle
+	sty.Fonts.Title.Size = 14
+	sty.Fonts.Label.Size = 14
+	sty.Fonts.Legend.Size = 12
+	sty.Fonts.Tick.Size = 12
+	sty.Ticks.Inside = true
+	sty.Ticks.Length = vg.Points(6)
+	sty.Ticks.Mirror = true
+	sty.Frame = true
+	return sty
+}
+
+// SetDefaultStyle sets the style used by all the plots subsequently
+// created with New, and returns the previous default style.
+//
+// SetDefaultStyle allows to enforce a house style across all the plots of
+// a program:
+//
+//	sty := hplot.HEPStyle()
+//	sty.Ticks.Marker = hplot.Ticks{N: 5}
+//	hplot.SetDefaultStyle(sty)
+func SetDefaultStyle(sty Style) Style {
+	muNewPlot.Lock()
+	defer muNewPlot.Unlock()
+
+	old := DefaultStyle
+	DefaultStyle = sty
+	return old
 }
 
 // Apply setups the plot p with the current style.
+// Apply also sets the style of the plot to s.
 func (s *Style) Apply(p *Plot) {
+	p.Style = *s
 	p.Plot.Title.TextStyle.Font = s.Fonts.Title
 	p.Plot.X.Label.TextStyle.Font = s.Fonts.Label
 	p.Plot.Y.Label.TextStyle.Font = s.Fonts.Label
@@ -44,6 +101,93 @@ func (s *Style) Apply(p *Plot) {
 	p.Plot.Legend.TextStyle.Font = s.Fonts.Legend
 	p.Plot.Legend.YPosition = draw.PosCenter
 	p.Plot.TextHandler = s.TextHandler
+
+	for _, ax := range []*plot.Axis{&p.Plot.X, &p.Plot.Y} {
+		if s.LineWidth > 0 {
+			ax.LineStyle.Width = s.LineWidth
+			ax.Tick.LineStyle.Width = s.LineWidth
+		}
+		if s.Ticks.Marker != nil {
+			ax.Tick.Marker = s.Ticks.Marker
+		}
+	}
+
+	if s.Ticks.Inside || s.Frame {
+		p.Plot.X.Padding = 0
+		p.Plot.Y.Padding = 0
+	}
+	if s.Ticks.Inside {
+		for _, ax := range []*plot.Axis{&p.Plot.X, &p.Plot.Y} {
+			// outside tick marks are only kept as a gap between
+			// the axis line and the tick labels.
+			ax.Tick.Color = color.Transparent
+			ax.Tick.Length = vg.Points(3)
+		}
+	}
+}
+
+// decorate draws the tick marks inside the data area and the frame
+// around the data area of the plot p, according to the style.
+func (s *Style) decorate(p *Plot, dc draw.Canvas) {
+	if !s.Ticks.Inside && !s.Frame {
+		return
+	}
+
+	var (
+		c    = p.Plot.DataCanvas(dc)
+		xmin = c.Min.X
+		xmax = c.Max.X
+		ymin = c.Min.Y
+		ymax = c.Max.Y
+	)
+
+	if s.Ticks.Inside {
+		length := s.Ticks.Length
+		if length == 0 {
+			length = vg.Points(6)
+		}
+		if sty := p.Plot.X.LineStyle; sty.Width > 0 {
+			for _, t := range p.Plot.X.Tick.Marker.Ticks(p.Plot.X.Min, p.Plot.X.Max) {
+				x := c.X(p.Plot.X.Norm(t.Value))
+				if !c.ContainsX(x) {
+					continue
+				}
+				l := length
+				if t.IsMinor() {
+					l /= 2
+				}
+				c.StrokeLine2(sty, x, ymin, x, ymin+l)
+				if s.Ticks.Mirror {
+					c.StrokeLine2(sty, x, ymax, x, ymax-l)
+				}
+			}
+		}
+		if sty := p.Plot.Y.LineStyle; sty.Width > 0 {
+			for _, t := range p.Plot.Y.Tick.Marker.Ticks(p.Plot.Y.Min, p.Plot.Y.Max) {
+				y := c.Y(p.Plot.Y.Norm(t.Value))
+				if !c.ContainsY(y) {
+					continue
+				}
+				l := length
+				if t.IsMinor() {
+					l /= 2
+				}
+				c.StrokeLine2(sty, xmin, y, xmin+l, y)
+				if s.Ticks.Mirror {
+					c.StrokeLine2(sty, xmax, y, xmax-l, y)
+				}
+			}
+		}
+	}
+
+	if s.Frame {
+		if sty := p.Plot.X.LineStyle; sty.Width > 0 {
+			c.StrokeLine2(sty, xmin, ymax, xmax, ymax)
+		}
+		if sty := p.Plot.Y.LineStyle; sty.Width > 0 {
+			c.StrokeLine2(sty, xmax, ymin, xmax, ymax)
+		}
+	}
 }
 
 func (s *Style) reset(fnt font.Font) {
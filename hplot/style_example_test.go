@@ -0,0 +1,62 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// An example of enforcing a house style on all the plots of a program,
+// and of drawing data series with a colorblind-safe palette.
+func ExampleSetDefaultStyle() {
+	sty := hplot.HEPStyle()
+	sty.LineWidth = vg.Points(1.5)
+	sty.Ticks.Marker = hplot.Ticks{N: 5, Format: "%.1f"}
+
+	// restore the previous default style at the end of the example.
+	defer hplot.SetDefaultStyle(hplot.SetDefaultStyle(sty))
+
+	p := hplot.New()
+	p.Title.Text = "Petroff 6-colors palette"
+	p.X.Label.Text = "x"
+	p.Y.Label.Text = "y"
+
+	for i, c := range hplot.Petroff6().Colors() {
+		var (
+			n   = 100
+			pts = make(plotter.XYs, n)
+		)
+		for j := range pts {
+			x := 2 * math.Pi * float64(j) / float64(n-1)
+			pts[j].X = x
+			pts[j].Y = math.Sin(x+float64(i)*math.Pi/6) + 0.5*float64(i)
+		}
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			log.Fatalf("could not create line: %+v", err)
+		}
+		line.LineStyle.Color = c
+		line.LineStyle.Width = vg.Points(2)
+		p.Add(line)
+		p.Legend.Add(fmt.Sprintf("series %d", i), line)
+	}
+	p.Legend.Top = true
+	p.Legend.Left = true
+	p.Legend.XOffs = vg.Points(5)
+	p.Legend.YOffs = -vg.Points(5)
+	p.Y.Min = -1.5
+	p.Y.Max = 7
+
+	err := p.Save(10*vg.Centimeter, -1, "testdata/house_style.png")
+	if err != nil {
+		log.Fatalf("could not save plot: %+v", err)
+	}
+}
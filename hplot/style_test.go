@@ -0,0 +1,87 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot_test
+
+import (
+	"testing"
+
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/cmpimg"
+	"gonum.org/v1/plot/palette"
+	"gonum.org/v1/plot/vg"
+)
+
+func TestSetDefaultStyle(t *testing.T) {
+	sty := hplot.DefaultStyle
+	sty.Fonts.Title.Size = 20
+	sty.LineWidth = vg.Points(2)
+	sty.Ticks.Marker = hplot.Ticks{N: 5, Format: "%.2f"}
+
+	old := hplot.SetDefaultStyle(sty)
+	p := hplot.New()
+	if got := hplot.SetDefaultStyle(old); got.Fonts.Title.Size != 20 {
+		t.Fatalf("invalid previous default style")
+	}
+
+	if got, want := p.Title.TextStyle.Font.Size, sty.Fonts.Title.Size; got != want {
+		t.Fatalf("invalid title font size: got=%v, want=%v", got, want)
+	}
+	for _, ax := range []*plot.Axis{&p.X, &p.Y} {
+		if got, want := ax.LineStyle.Width, vg.Points(2); got != want {
+			t.Fatalf("invalid axis line width: got=%v, want=%v", got, want)
+		}
+		if got, want := ax.Tick.LineStyle.Width, vg.Points(2); got != want {
+			t.Fatalf("invalid tick line width: got=%v, want=%v", got, want)
+		}
+		if got, want := ax.Tick.Marker, sty.Ticks.Marker; got != want {
+			t.Fatalf("invalid tick marker: got=%v, want=%v", got, want)
+		}
+	}
+
+	p = hplot.New()
+	if got, want := p.Title.TextStyle.Font.Size, old.Fonts.Title.Size; got != want {
+		t.Fatalf("default style not restored: got=%v, want=%v", got, want)
+	}
+	if _, ok := p.X.Tick.Marker.(hplot.Ticks); ok {
+		t.Fatalf("default tick marker not restored")
+	}
+}
+
+func TestColorblindPalettes(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		p    palette.Palette
+		n    int
+	}{
+		{"okabe-ito", hplot.OkabeIto(), 8},
+		{"petroff-6", hplot.Petroff6(), 6},
+		{"petroff-8", hplot.Petroff8(), 8},
+		{"petroff-10", hplot.Petroff10(), 10},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := tc.p.Colors()
+			if got, want := len(cs), tc.n; got != want {
+				t.Fatalf("invalid number of colors: got=%d, want=%d", got, want)
+			}
+			seen := make(map[[4]uint32]bool)
+			for i, c := range cs {
+				r, g, b, a := c.RGBA()
+				if a != 0xffff {
+					t.Fatalf("color %d is not opaque", i)
+				}
+				k := [4]uint32{r, g, b, a}
+				if seen[k] {
+					t.Fatalf("duplicate color %d: %v", i, c)
+				}
+				seen[k] = true
+			}
+		})
+	}
+}
+
+func TestHouseStyle(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleSetDefaultStyle, t, "house_style.png")
+}
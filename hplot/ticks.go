@@ -8,10 +8,15 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
+	"time"
 
 	"go-hep.org/x/hep/hplot/internal/talbot"
 	"gonum.org/v1/gonum/floats/scalar"
 	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/text"
+	"gonum.org/v1/plot/vg"
 )
 
 const (
@@ -94,3 +99,513 @@ func (tck Ticks) Ticks(min, max float64) []plot.Tick {
 	}
 	return ticks
 }
+
+// axisTicker is implemented by the tick markers whose labels depend on the
+// text backend used to render the plot, or which decorate the label of
+// their axis (with a multiplier or a unit.)
+//
+// hplot.Plot.Draw uses the ticker and the label suffix returned by
+// the axis method while drawing the axis.
+type axisTicker interface {
+	plot.Ticker
+
+	// axis returns the ticker to use to draw an axis spanning the
+	// [min, max] range, and the suffix of the label of the axis.
+	// tex reports whether the text of the plot is rendered by LaTeX.
+	// If sup is true, the tick labels and the suffix hold exponents
+	// written as "^{n}", to be rendered with the superscripts text handler.
+	axis(min, max float64, tex bool) (tck plot.Ticker, suffix string, sup bool)
+}
+
+// ExpTicks implements plot.Ticker, factoring a power of ten out of the
+// labels of the major ticks.
+// The power of ten is displayed as a "×10ⁿ" multiplier appended to the
+// label of the axis, when the plot is drawn by hplot.Plot.
+type ExpTicks struct {
+	// Ticker computes the ticks of the axis.
+	// If nil, plot.DefaultTicks is used.
+	Ticker plot.Ticker
+
+	// Exp is the power of ten factored out of the labels.
+	// If zero, Exp is chosen as a multiple of 3 from the magnitude of
+	// the major ticks, and no multiplier is used for values in the
+	// [0.1, 10000) range.
+	Exp int
+}
+
+// Ticks returns Ticks in a specified range.
+func (tck ExpTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := tickerOrDefault(tck.Ticker).Ticks(min, max)
+	exp := tck.exp(ticks)
+	if exp == 0 {
+		return ticks
+	}
+	scale := math.Pow10(-exp)
+	for i, t := range ticks {
+		if t.IsMinor() {
+			continue
+		}
+		ticks[i].Label = formatFloatTick(t.Value*scale, displayPrecision)
+	}
+	return ticks
+}
+
+func (tck ExpTicks) exp(ticks []plot.Tick) int {
+	if tck.Exp != 0 {
+		return tck.Exp
+	}
+	var vmax float64
+	for _, t := range ticks {
+		if !t.IsMinor() {
+			vmax = math.Max(vmax, math.Abs(t.Value))
+		}
+	}
+	if vmax == 0 {
+		return 0
+	}
+	e := int(math.Floor(math.Log10(vmax)))
+	if -1 <= e && e < 4 {
+		return 0
+	}
+	return 3 * int(math.Floor(float64(e)/3))
+}
+
+func (tck ExpTicks) axis(min, max float64, tex bool) (plot.Ticker, string, bool) {
+	exp := tck.exp(tickerOrDefault(tck.Ticker).Ticks(min, max))
+	if exp == 0 {
+		return tck, "", false
+	}
+	tck.Exp = exp
+	if tex {
+		return tck, fmt.Sprintf(`$\times 10^{%d}$`, exp), false
+	}
+	return tck, "×10^{" + formatExp(exp) + "}", true
+}
+
+// electronvolts holds the multiples of the electronvolt used by UnitTicks.
+var electronvolts = []string{"eV", "keV", "MeV", "GeV", "TeV", "PeV"}
+
+// UnitTicks implements plot.Ticker for axes holding energies, masses or
+// momenta, labelling the major ticks with the most legible multiple of
+// the electronvolt (eV, keV, MeV, GeV, TeV or PeV).
+// The selected unit is displayed as a "[MeV]" suffix appended to the
+// label of the axis, when the plot is drawn by hplot.Plot.
+type UnitTicks struct {
+	// Ticker computes the ticks of the axis.
+	// If nil, plot.DefaultTicks is used.
+	Ticker plot.Ticker
+
+	// Unit is the unit of the values of the axis.
+	// The default is "GeV".
+	Unit string
+}
+
+// Ticks returns Ticks in a specified range.
+func (tck UnitTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := tickerOrDefault(tck.Ticker).Ticks(min, max)
+	src, dst := tck.units(ticks)
+	scale := math.Pow10(3 * (src - dst))
+	for i, t := range ticks {
+		if t.IsMinor() {
+			continue
+		}
+		ticks[i].Label = formatFloatTick(t.Value*scale, displayPrecision)
+	}
+	return ticks
+}
+
+// units returns the indices in electronvolts of the unit of the values
+// and of the unit of the labels.
+func (tck UnitTicks) units(ticks []plot.Tick) (src, dst int) {
+	unit := tck.Unit
+	if unit == "" {
+		unit = "GeV"
+	}
+	src = -1
+	for i, u := range electronvolts {
+		if u == unit {
+			src = i
+		}
+	}
+	if src < 0 {
+		panic(fmt.Errorf("hplot: invalid energy unit %q", tck.Unit))
+	}
+
+	var vmax float64
+	for _, t := range ticks {
+		if !t.IsMinor() {
+			vmax = math.Max(vmax, math.Abs(t.Value))
+		}
+	}
+	if vmax == 0 {
+		return src, src
+	}
+
+	dst = src + int(math.Floor(math.Log10(vmax)/3))
+	switch {
+	case dst < 0:
+		dst = 0
+	case dst >= len(electronvolts):
+		dst = len(electronvolts) - 1
+	}
+	return src, dst
+}
+
+func (tck UnitTicks) axis(min, max float64, tex bool) (plot.Ticker, string, bool) {
+	_, dst := tck.units(tickerOrDefault(tck.Ticker).Ticks(min, max))
+	return tck, "[" + electronvolts[dst] + "]", false
+}
+
+// TimeTicks implements plot.Ticker for axes holding Unix timestamps,
+// expressed in seconds.
+// The major ticks are placed at round dates and times, and labelled with
+// a format adapted to the range of the axis.
+type TimeTicks struct {
+	// N is the suggested number of major ticks to display.
+	// The default is 5.
+	N int
+
+	// Format is the time.Time layout of the labels of the major ticks.
+	// If empty, a layout is chosen from the range of the axis.
+	Format string
+
+	// Location is the time zone of the labels.
+	// The default is UTC.
+	Location *time.Location
+}
+
+// Ticks returns Ticks in a specified range.
+func (tck TimeTicks) Ticks(min, max float64) []plot.Tick {
+	n := tck.N
+	if n <= 0 {
+		n = 5
+	}
+	loc := tck.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	span := max - min
+	if !(span > 0) {
+		return nil
+	}
+
+	var (
+		ideal = span / float64(n)
+		beg   = time.Unix(int64(math.Floor(min)), 0).In(loc)
+		end   = time.Unix(int64(math.Ceil(max)), 0).In(loc)
+		major []time.Time
+	)
+
+	const (
+		minute = 60
+		hour   = 60 * minute
+		day    = 24 * hour
+	)
+	steps := []float64{
+		1, 2, 5, 10, 15, 30,
+		minute, 2 * minute, 5 * minute, 10 * minute, 15 * minute, 30 * minute,
+		hour, 2 * hour, 3 * hour, 6 * hour, 12 * hour,
+		day, 2 * day, 7 * day, 14 * day,
+	}
+
+	switch {
+	case ideal <= steps[len(steps)-1]:
+		step := steps[len(steps)-1]
+		for _, s := range steps {
+			if s >= ideal {
+				step = s
+				break
+			}
+		}
+		// align the ticks on the local midnight of the first day.
+		var (
+			t0   = time.Date(beg.Year(), beg.Month(), beg.Day(), 0, 0, 0, 0, loc)
+			orig = float64(t0.Unix())
+			k    = math.Ceil((min - orig) / step)
+		)
+		if step == 7*day || step == 14*day {
+			// align weeks on mondays.
+			wd := (int(t0.Weekday()) + 6) % 7
+			orig -= float64(wd * day)
+			k = math.Ceil((min - orig) / step)
+		}
+		for v := orig + k*step; v <= max; v += step {
+			major = append(major, time.Unix(int64(v), 0).In(loc))
+		}
+
+	default:
+		// ticks on the first day of the months.
+		months := int(math.Ceil(ideal / (30 * day)))
+		for _, m := range []int{1, 2, 3, 4, 6, 12} {
+			if m >= months {
+				months = m
+				break
+			}
+		}
+		if months > 12 {
+			months = 12 * int(math.Ceil(float64(months)/12))
+		}
+		t := time.Date(beg.Year(), 1, 1, 0, 0, 0, 0, loc)
+		for !t.After(end) {
+			if v := float64(t.Unix()); min <= v && v <= max {
+				major = append(major, t)
+			}
+			t = t.AddDate(0, months, 0)
+		}
+	}
+
+	layout := tck.Format
+	if layout == "" {
+		switch {
+		case span >= 2*365*day:
+			layout = "2006"
+		case span >= 60*day:
+			layout = "2006-01"
+		case span >= 2*day:
+			layout = "2006-01-02"
+		case span >= 2*minute:
+			layout = "15:04"
+		default:
+			layout = "15:04:05"
+		}
+	}
+
+	ticks := make([]plot.Tick, len(major))
+	for i, t := range major {
+		ticks[i] = plot.Tick{Value: float64(t.Unix()), Label: t.Format(layout)}
+	}
+	return ticks
+}
+
+// LogTicks implements plot.Ticker for logarithmic axes.
+//
+// Major ticks are placed at the powers of ten.
+// When the axis spans many decades, the labels are decimated so that at
+// most N major ticks are labelled, the other powers of ten being drawn as
+// minor ticks.
+// Otherwise, minor ticks are drawn at 2..9 times the powers of ten.
+//
+// When the plot is drawn by hplot.Plot, the labels are rendered as 10ⁿ,
+// or as the LaTeX math expression $10^{n}$ with a LaTeX backend.
+// Otherwise, the labels are formatted as 0.01, 0.1, 1, 10, ...
+type LogTicks struct {
+	// N is the maximum number of labelled major ticks.
+	// The default is 6.
+	N int
+
+	fmt int // format of the labels: 0 for plain text, 1 for superscripts, 2 for LaTeX.
+}
+
+// Ticks returns Ticks in a specified range.
+func (tck LogTicks) Ticks(min, max float64) []plot.Tick {
+	if min <= 0 || max <= 0 || !(max > min) {
+		return nil
+	}
+	n := tck.N
+	if n <= 0 {
+		n = 6
+	}
+
+	var (
+		lo   = int(math.Floor(math.Log10(min)))
+		hi   = int(math.Ceil(math.Log10(max)))
+		step = 1
+	)
+	for (hi-lo)/step+1 > n {
+		step++
+	}
+
+	var (
+		ticks []plot.Tick
+		nlbl  int
+	)
+	for e := lo; e <= hi; e++ {
+		v := math.Pow10(e)
+		if min <= v && v <= max {
+			var label string
+			if e%step == 0 {
+				label = tck.label(e)
+				nlbl++
+			}
+			ticks = append(ticks, plot.Tick{Value: v, Label: label})
+		}
+		if step > 1 {
+			continue
+		}
+		for k := 2; k < 10; k++ {
+			v := float64(k) * math.Pow10(e)
+			if min <= v && v <= max {
+				ticks = append(ticks, plot.Tick{Value: v})
+			}
+		}
+	}
+
+	if nlbl == 0 {
+		// no power of ten within the range: label the minor ticks.
+		for i, t := range ticks {
+			ticks[i].Label = formatFloatTick(t.Value, precisionOf(t.Value))
+		}
+	}
+	return ticks
+}
+
+func (tck LogTicks) label(e int) string {
+	switch tck.fmt {
+	case 1:
+		return "10^{" + formatExp(e) + "}"
+	case 2:
+		return fmt.Sprintf("$10^{%d}$", e)
+	default:
+		return strconv.FormatFloat(math.Pow10(e), 'g', -1, 64)
+	}
+}
+
+func (tck LogTicks) axis(min, max float64, tex bool) (plot.Ticker, string, bool) {
+	if tex {
+		tck.fmt = 2
+		return tck, "", false
+	}
+	tck.fmt = 1
+	return tck, "", true
+}
+
+// formatExp formats the exponent e, using the Unicode minus sign.
+func formatExp(e int) string {
+	if e < 0 {
+		return "−" + strconv.Itoa(-e)
+	}
+	return strconv.Itoa(e)
+}
+
+// superscripts is a text/plain handler rendering the "^{...}" parts of
+// the text as superscripts.
+type superscripts struct {
+	text.Plain
+}
+
+// supSize and supRise are the size and the rise of superscripts,
+// relative to the size of the font.
+const (
+	supSize = 0.7
+	supRise = 0.4
+)
+
+// segments splits a line of text into its regular and superscript parts.
+func (superscripts) segments(txt string) (segs []string, sups []bool) {
+	for len(txt) > 0 {
+		i := strings.Index(txt, "^{")
+		j := -1
+		if i >= 0 {
+			j = strings.Index(txt[i:], "}")
+		}
+		if i < 0 || j < 0 {
+			segs = append(segs, txt)
+			sups = append(sups, false)
+			break
+		}
+		if i > 0 {
+			segs = append(segs, txt[:i])
+			sups = append(sups, false)
+		}
+		segs = append(segs, txt[i+2:i+j])
+		sups = append(sups, true)
+		txt = txt[i+j+1:]
+	}
+	return segs, sups
+}
+
+func (hdlr superscripts) width(txt string, fnt font.Font) vg.Length {
+	var (
+		face = hdlr.Fonts.Lookup(fnt, fnt.Size)
+		sup  = hdlr.Fonts.Lookup(fnt, supSize*fnt.Size)
+		w    vg.Length
+	)
+	segs, sups := hdlr.segments(txt)
+	for i, seg := range segs {
+		if sups[i] {
+			w += sup.Width(seg)
+			continue
+		}
+		w += face.Width(seg)
+	}
+	return w
+}
+
+// Box returns the bounding box of the given non-multiline text.
+func (hdlr superscripts) Box(txt string, fnt font.Font) (width, height, depth vg.Length) {
+	width, height, depth = hdlr.Plain.Box(txt, fnt)
+	if !strings.Contains(txt, "^{") {
+		return width, height, depth
+	}
+	sup := hdlr.Fonts.Lookup(fnt, supSize*fnt.Size)
+	width = hdlr.width(txt, fnt)
+	height = vg.Length(math.Max(float64(height), float64(supRise*fnt.Size+sup.Extents().Ascent)))
+	return width, height, depth
+}
+
+// Draw renders the given text with the provided style and position
+// on the canvas.
+func (hdlr superscripts) Draw(c vg.Canvas, txt string, sty text.Style, pt vg.Point) {
+	txt = strings.TrimRight(txt, "\n")
+	if len(txt) == 0 {
+		return
+	}
+
+	var (
+		face = hdlr.Fonts.Lookup(sty.Font, sty.Font.Size)
+		sup  = hdlr.Fonts.Lookup(sty.Font, supSize*sty.Font.Size)
+	)
+	c.SetColor(sty.Color)
+
+	if sty.Rotation != 0 {
+		c.Push()
+		c.Rotate(sty.Rotation)
+	}
+
+	sin64, cos64 := math.Sincos(sty.Rotation)
+	cos := vg.Length(cos64)
+	sin := vg.Length(sin64)
+	pt.X, pt.Y = pt.Y*sin+pt.X*cos, pt.Y*cos-pt.X*sin
+
+	lines := hdlr.Lines(txt)
+	ht := sty.Height(txt)
+	pt.Y += ht*vg.Length(sty.YAlign) - face.Extents().Ascent
+	for i, line := range lines {
+		var (
+			xoffs = vg.Length(sty.XAlign) * hdlr.width(line, sty.Font)
+			n     = vg.Length(len(lines) - i)
+			pos   = pt.Add(vg.Point{X: xoffs, Y: n * sty.Font.Size})
+		)
+		segs, sups := hdlr.segments(line)
+		for j, seg := range segs {
+			if sups[j] {
+				c.FillString(sup, pos.Add(vg.Point{Y: supRise * sty.Font.Size}), seg)
+				pos.X += sup.Width(seg)
+				continue
+			}
+			c.FillString(face, pos, seg)
+			pos.X += face.Width(seg)
+		}
+	}
+
+	if sty.Rotation != 0 {
+		c.Pop()
+	}
+}
+
+func tickerOrDefault(tck plot.Ticker) plot.Ticker {
+	if tck == nil {
+		return plot.DefaultTicks{}
+	}
+	return tck
+}
+
+var (
+	_ axisTicker = ExpTicks{}
+	_ axisTicker = UnitTicks{}
+	_ axisTicker = LogTicks{}
+
+	_ plot.Ticker = TimeTicks{}
+
+	_ text.Handler = superscripts{}
+)
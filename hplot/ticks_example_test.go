@@ -6,8 +6,10 @@ package hplot_test
 
 import (
 	"log"
+	"time"
 
 	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/vg"
 	"gonum.org/v1/plot/vg/draw"
 )
@@ -75,3 +77,61 @@ func ExampleTicks() {
 		log.Fatalf("error: %+v\n", err)
 	}
 }
+
+// An example of tick markers following the HEP conventions:
+// power-of-ten multipliers, energy units, time axes and decimated
+// labels on logarithmic axes.
+func ExampleExpTicks() {
+	tp := hplot.NewTiledPlot(draw.Tiles{Cols: 2, Rows: 2, PadX: 0.5 * vg.Centimeter, PadY: 0.5 * vg.Centimeter})
+
+	p := tp.Plot(0, 0)
+	p.Title.Text = "hplot.ExpTicks"
+	p.X.Label.Text = "x"
+	p.Y.Label.Text = "entries"
+	p.X.Min = 0
+	p.X.Max = 1
+	p.Y.Min = 0
+	p.Y.Max = 25000
+	p.Y.Tick.Marker = hplot.ExpTicks{}
+	p.Add(hplot.NewGrid())
+
+	p = tp.Plot(0, 1)
+	p.Title.Text = "hplot.UnitTicks"
+	p.X.Label.Text = "m"
+	p.X.Min = 0
+	p.X.Max = 0.5
+	p.X.Tick.Marker = hplot.UnitTicks{Unit: "GeV"}
+	p.Y.Label.Text = "E"
+	p.Y.Min = 0
+	p.Y.Max = 13600
+	p.Y.Tick.Marker = hplot.UnitTicks{Unit: "GeV"}
+	p.Add(hplot.NewGrid())
+
+	p = tp.Plot(1, 0)
+	p.Title.Text = "hplot.TimeTicks"
+	p.X.Label.Text = "time (UTC)"
+	p.X.Min = float64(time.Date(2022, 7, 5, 8, 30, 0, 0, time.UTC).Unix())
+	p.X.Max = float64(time.Date(2022, 7, 5, 20, 0, 0, 0, time.UTC).Unix())
+	p.X.Tick.Marker = hplot.TimeTicks{}
+	p.Y.Label.Text = "luminosity"
+	p.Add(hplot.NewGrid())
+
+	p = tp.Plot(1, 1)
+	p.Title.Text = "hplot.LogTicks"
+	p.X.Label.Text = "x"
+	p.X.Min = 1e-2
+	p.X.Max = 1e2
+	p.X.Scale = plot.LogScale{}
+	p.X.Tick.Marker = hplot.LogTicks{}
+	p.Y.Label.Text = "y"
+	p.Y.Min = 1
+	p.Y.Max = 1e12
+	p.Y.Scale = plot.LogScale{}
+	p.Y.Tick.Marker = hplot.LogTicks{N: 4}
+	p.Add(hplot.NewGrid())
+
+	err := tp.Save(20*vg.Centimeter, 15*vg.Centimeter, "testdata/ticks_hep.png")
+	if err != nil {
+		log.Fatalf("error: %+v\n", err)
+	}
+}
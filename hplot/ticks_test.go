@@ -5,11 +5,171 @@
 package hplot_test
 
 import (
+	"bytes"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"go-hep.org/x/hep/hplot"
+	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/cmpimg"
+	"gonum.org/v1/plot/vg"
 )
 
 func TestTicks(t *testing.T) {
 	checkPlot(cmpimg.CheckPlot)(ExampleTicks, t, "ticks.png")
 }
+
+func TestHEPTicks(t *testing.T) {
+	checkPlot(cmpimg.CheckPlot)(ExampleExpTicks, t, "ticks_hep.png")
+}
+
+func majorLabels(ticks []plot.Tick) []string {
+	var labels []string
+	for _, t := range ticks {
+		if !t.IsMinor() {
+			labels = append(labels, t.Label)
+		}
+	}
+	return labels
+}
+
+func TestHEPTickLabels(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		tck      plot.Ticker
+		min, max float64
+		want     []string
+	}{
+		{
+			name: "exp-auto",
+			tck:  hplot.ExpTicks{},
+			min:  0, max: 25000,
+			want: []string{"0", "10", "20"},
+		},
+		{
+			name: "exp-auto-small",
+			tck:  hplot.ExpTicks{},
+			min:  0, max: 0.0025,
+			want: []string{"0", "1", "2"},
+		},
+		{
+			name: "exp-no-multiplier",
+			tck:  hplot.ExpTicks{},
+			min:  0, max: 250,
+			want: []string{"0", "100", "200"},
+		},
+		{
+			name: "exp-fixed",
+			tck:  hplot.ExpTicks{Exp: 2},
+			min:  0, max: 250,
+			want: []string{"0", "1", "2"},
+		},
+		{
+			name: "unit-mev",
+			tck:  hplot.UnitTicks{},
+			min:  0, max: 0.5,
+			want: []string{"0", "250", "500"},
+		},
+		{
+			name: "unit-tev",
+			tck:  hplot.UnitTicks{Unit: "MeV"},
+			min:  0, max: 13.6e6,
+			want: []string{"0", "4", "8", "12"},
+		},
+		{
+			name: "time-hours",
+			tck:  hplot.TimeTicks{},
+			min:  float64(time.Date(2022, 7, 5, 8, 30, 0, 0, time.UTC).Unix()),
+			max:  float64(time.Date(2022, 7, 5, 20, 0, 0, 0, time.UTC).Unix()),
+			want: []string{"09:00", "12:00", "15:00", "18:00"},
+		},
+		{
+			name: "time-days",
+			tck:  hplot.TimeTicks{N: 4},
+			min:  float64(time.Date(2022, 7, 5, 8, 30, 0, 0, time.UTC).Unix()),
+			max:  float64(time.Date(2022, 7, 12, 20, 0, 0, 0, time.UTC).Unix()),
+			want: []string{"2022-07-07", "2022-07-09", "2022-07-11"},
+		},
+		{
+			name: "time-weeks",
+			tck:  hplot.TimeTicks{},
+			min:  float64(time.Date(2022, 7, 5, 0, 0, 0, 0, time.UTC).Unix()),
+			max:  float64(time.Date(2022, 8, 15, 0, 0, 0, 0, time.UTC).Unix()),
+			want: []string{"2022-07-18", "2022-08-01", "2022-08-15"},
+		},
+		{
+			name: "time-months",
+			tck:  hplot.TimeTicks{},
+			min:  float64(time.Date(2022, 1, 15, 0, 0, 0, 0, time.UTC).Unix()),
+			max:  float64(time.Date(2022, 12, 15, 0, 0, 0, 0, time.UTC).Unix()),
+			want: []string{"2022-04", "2022-07", "2022-10"},
+		},
+		{
+			name: "log",
+			tck:  hplot.LogTicks{},
+			min:  1e-2, max: 1e2,
+			want: []string{"0.01", "0.1", "1", "10", "100"},
+		},
+		{
+			name: "log-decimated",
+			tck:  hplot.LogTicks{N: 4},
+			min:  1, max: 1e12,
+			want: []string{"1", "10000", "1e+08", "1e+12"},
+		},
+		{
+			name: "log-within-decade",
+			tck:  hplot.LogTicks{},
+			min:  2, max: 5,
+			want: []string{"2", "3", "4", "5"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := majorLabels(tc.tck.Ticks(tc.min, tc.max))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("invalid labels:\ngot= %q\nwant=%q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHEPTicksLatex(t *testing.T) {
+	p := hplot.New()
+	p.X.Label.Text = "x"
+	p.X.Min = 1e-2
+	p.X.Max = 1e2
+	p.X.Scale = plot.LogScale{}
+	p.X.Tick.Marker = hplot.LogTicks{}
+	p.Y.Label.Text = "entries"
+	p.Y.Min = 0
+	p.Y.Max = 25000
+	p.Y.Tick.Marker = hplot.ExpTicks{}
+
+	w, err := p.WriterTo(10*vg.Centimeter, 10*vg.Centimeter, "tex")
+	if err != nil {
+		t.Fatalf("could not create tex writer: %+v", err)
+	}
+	out := new(bytes.Buffer)
+	_, err = w.WriteTo(out)
+	if err != nil {
+		t.Fatalf("could not write tex: %+v", err)
+	}
+
+	for _, want := range []string{
+		`$10^{-2}$`, `$10^{0}$`, `$10^{2}$`,
+		`entries $\times 10^{3}$`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("missing %q in LaTeX output", want)
+		}
+	}
+
+	// the axes are restored after drawing.
+	if got, want := p.Y.Label.Text, "entries"; got != want {
+		t.Fatalf("invalid Y label: got=%q, want=%q", got, want)
+	}
+	if _, ok := p.X.Tick.Marker.(hplot.LogTicks); !ok {
+		t.Fatalf("invalid X tick marker: %T", p.X.Tick.Marker)
+	}
+}
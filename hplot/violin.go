@@ -0,0 +1,206 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hplot
+
+import (
+	"image/color"
+	"math"
+
+	"go-hep.org/x/hep/hbook"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Violin implements the plot.Plotter interface, drawing a violin plot
+// of a distribution at a given location along the X axis.
+//
+// The outline of the violin is the density of the distribution,
+// mirrored on both sides of the location.
+type Violin struct {
+	// Location is the position of the violin along the X axis.
+	Location float64
+
+	// Width is the width of the violin at the maximum of the density.
+	Width vg.Length
+
+	// Density is the density of the distribution:
+	// the X field of each point is the value along the Y axis of
+	// the plot, the Y field is the density at that value.
+	Density plotter.XYs
+
+	// FillColor is the color of the inside of the violin.
+	// The violin is not filled if FillColor is nil.
+	FillColor color.Color
+
+	// LineStyle is the style of the outline of the violin.
+	LineStyle draw.LineStyle
+
+	// Box, if not nil, is drawn inside the violin.
+	Box *BoxPlot
+}
+
+// NewViolin returns a violin plot of the samples xs, at the location loc.
+//
+// The density of the samples is estimated with a Gaussian kernel, whose
+// bandwidth is chosen with the Silverman's rule of thumb.
+func NewViolin(loc float64, xs []float64) *Violin {
+	return newViolin(loc, kde(xs, 100))
+}
+
+// NewH1DViolin returns a violin plot of the distribution of the
+// histogram h, at the location loc.
+// The outline of the violin follows the contents of the bins, leading
+// and trailing empty bins being discarded.
+func NewH1DViolin(loc float64, h *hbook.H1D) *Violin {
+	pts := h1dSteps(h)
+	for len(pts) > 0 && pts[0].Y == 0 {
+		pts = pts[1:]
+	}
+	for len(pts) > 0 && pts[len(pts)-1].Y == 0 {
+		pts = pts[:len(pts)-1]
+	}
+	return newViolin(loc, pts)
+}
+
+func newViolin(loc float64, density plotter.XYs) *Violin {
+	return &Violin{
+		Location:  loc,
+		Width:     vg.Points(40),
+		Density:   density,
+		FillColor: color.NRGBA{R: 100, G: 150, B: 255, A: 255},
+		LineStyle: plotter.DefaultLineStyle,
+	}
+}
+
+// kde returns the Gaussian kernel density estimate of the samples xs,
+// evaluated at n points spanning the range of the samples.
+func kde(xs []float64, n int) plotter.XYs {
+	if len(xs) == 0 {
+		return nil
+	}
+
+	q := newQuantiles(xs, nil)
+	var (
+		min = q.xs[0]
+		max = q.xs[len(q.xs)-1]
+		sd  = stat.StdDev(q.xs, nil)
+		iqr = q.at(0.75) - q.at(0.25)
+		bw  = 0.9 * math.Min(sd, iqr/1.34) * math.Pow(float64(len(xs)), -0.2)
+	)
+	if !(bw > 0) {
+		bw = math.Max(sd, 1) * math.Pow(float64(len(xs)), -0.2)
+	}
+
+	var (
+		pts  = make(plotter.XYs, n)
+		dx   = (max - min) / float64(n-1)
+		norm = 1 / (float64(len(xs)) * bw * math.Sqrt(2*math.Pi))
+	)
+	for i := range pts {
+		x := min + float64(i)*dx
+		var sum float64
+		for _, v := range q.xs {
+			u := (x - v) / bw
+			sum += math.Exp(-0.5 * u * u)
+		}
+		pts[i] = plotter.XY{X: x, Y: sum * norm}
+	}
+	return pts
+}
+
+// Plot implements the plot.Plotter interface.
+func (v *Violin) Plot(c draw.Canvas, plt *plot.Plot) {
+	var (
+		trX, trY = plt.Transforms(&c)
+		x        = trX(v.Location)
+		n        = len(v.Density)
+	)
+	if !c.ContainsX(x) || n == 0 {
+		return
+	}
+
+	var dmax float64
+	for _, pt := range v.Density {
+		dmax = math.Max(dmax, pt.Y)
+	}
+	if dmax <= 0 {
+		dmax = 1
+	}
+
+	poly := make([]vg.Point, 2*n)
+	for i, pt := range v.Density {
+		var (
+			y = trY(pt.X)
+			w = v.Width / 2 * vg.Length(pt.Y/dmax)
+		)
+		poly[i] = vg.Point{X: x + w, Y: y}
+		poly[2*n-1-i] = vg.Point{X: x - w, Y: y}
+	}
+
+	if v.FillColor != nil {
+		c.FillPolygon(v.FillColor, c.ClipPolygonY(poly))
+	}
+	c.StrokeLines(v.LineStyle, c.ClipLinesY(append(poly, poly[0]))...)
+
+	if v.Box != nil {
+		v.Box.Plot(c, plt)
+	}
+}
+
+// DataRange returns the minimum and maximum x and
+// y values, implementing the plot.DataRanger interface.
+func (v *Violin) DataRange() (xmin, xmax, ymin, ymax float64) {
+	ymin = math.Inf(+1)
+	ymax = math.Inf(-1)
+	for _, pt := range v.Density {
+		ymin = math.Min(ymin, pt.X)
+		ymax = math.Max(ymax, pt.X)
+	}
+	if v.Box != nil {
+		_, _, bmin, bmax := v.Box.DataRange()
+		ymin = math.Min(ymin, bmin)
+		ymax = math.Max(ymax, bmax)
+	}
+	return v.Location, v.Location, ymin, ymax
+}
+
+// GlyphBoxes returns a GlyphBox covering the width of the violin,
+// implementing the plot.GlyphBoxer interface.
+func (v *Violin) GlyphBoxes(plt *plot.Plot) []plot.GlyphBox {
+	_, _, ymin, ymax := v.DataRange()
+	return []plot.GlyphBox{{
+		X: plt.X.Norm(v.Location),
+		Y: plt.Y.Norm(0.5 * (ymin + ymax)),
+		Rectangle: vg.Rectangle{
+			Min: vg.Point{X: -v.Width / 2},
+			Max: vg.Point{X: +v.Width / 2},
+		},
+	}}
+}
+
+// Thumbnail returns the thumbnail for the Violin,
+// implementing the plot.Thumbnailer interface.
+func (v *Violin) Thumbnail(c *draw.Canvas) {
+	rect := []vg.Point{
+		{X: c.Min.X, Y: c.Min.Y},
+		{X: c.Min.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Max.Y},
+		{X: c.Max.X, Y: c.Min.Y},
+	}
+	if v.FillColor != nil {
+		c.FillPolygon(v.FillColor, rect)
+	}
+	c.StrokeLines(v.LineStyle, append(rect, rect[0]))
+}
+
+var (
+	_ plot.Plotter     = (*Violin)(nil)
+	_ plot.DataRanger  = (*Violin)(nil)
+	_ plot.GlyphBoxer  = (*Violin)(nil)
+	_ plot.Thumbnailer = (*Violin)(nil)
+)
@@ -66,7 +66,31 @@ type Index struct {
 }
 
 func (idx *Index) MarshalSio(w sio.Writer) error {
-	panic("not implemented")
+	enc := sio.NewEncoder(w)
+	enc.Encode(&idx.ControlWord)
+	enc.Encode(&idx.RunMin)
+	enc.Encode(&idx.BaseOffset)
+	enc.Encode(int32(len(idx.Offsets)))
+	for i := range idx.Offsets {
+		v := &idx.Offsets[i]
+		if idx.ControlWord&1 == 0 {
+			enc.Encode(&v.RunOffset)
+		}
+
+		enc.Encode(&v.EventNumber)
+		switch {
+		case idx.ControlWord&2 != 0:
+			enc.Encode(&v.Location)
+		default:
+			enc.Encode(int32(v.Location))
+		}
+		if idx.ControlWord&4 != 0 {
+			enc.Encode(&v.Ints)
+			enc.Encode(&v.Floats)
+			enc.Encode(&v.Strings)
+		}
+	}
+	return enc.Err()
 }
 
 func (idx *Index) UnmarshalSio(r sio.Reader) error {
@@ -85,14 +109,14 @@ func (idx *Index) UnmarshalSio(r sio.Reader) error {
 
 		dec.Decode(&v.EventNumber)
 		switch {
-		case idx.ControlWord&2 == 1:
+		case idx.ControlWord&2 != 0:
 			dec.Decode(&v.Location)
 		default:
 			var loc int32
 			dec.Decode(&loc)
 			v.Location = int64(loc)
 		}
-		if idx.ControlWord&4 == 1 {
+		if idx.ControlWord&4 != 0 {
 			dec.Decode(&v.Ints)
 			dec.Decode(&v.Floats)
 			dec.Decode(&v.Strings)
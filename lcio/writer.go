@@ -6,6 +6,9 @@ package lcio
 
 import (
 	"compress/flate"
+	"io"
+	"math"
+	"sort"
 
 	"go-hep.org/x/hep/sio"
 )
@@ -21,9 +24,19 @@ func Create(fname string) (*Writer, error) {
 		clvl: flate.DefaultCompression,
 	}
 	w.err = w.init()
+	if w.err != nil {
+		return w, w.err
+	}
+
+	// reserve the LCIORandomAccess file record, updated on Close.
+	w.data.rnd.RecordSize = raRecordSize
+	w.err = w.f.WriteRecord(w.recs.rnd)
 	return w, w.err
 }
 
+// raRecordSize is the size in bytes of an (uncompressed) LCIORandomAccess record.
+const raRecordSize = 136
+
 // Writer provides a way to write LCIO RunHeaders and Events to an
 // output SIO stream.
 type Writer struct {
@@ -42,18 +55,33 @@ type Writer struct {
 		rhdr RunHeader
 		ehdr EventHeader
 	}
+	locs   []runEvent // locations of the run headers and events written so far
 	closed bool
 	err    error
 }
 
+// runEvent is the location of a run header (with evt=-1) or of an event.
+type runEvent struct {
+	run int32
+	evt int32
+	loc int64
+}
+
 // Close closes the underlying output stream and makes it unavailable for
 // further I/O operations.
-// Close will synchronize and commit to disk any lingering data before closing
-// the output stream.
+// Close writes the LCIOIndex and LCIORandomAccess records, and will
+// synchronize and commit to disk any lingering data before closing the
+// output stream.
 func (w *Writer) Close() error {
 	if w.closed {
 		return w.err
 	}
+	if w.err == nil {
+		w.err = w.writeRandomAccess()
+		if w.err != nil {
+			return w.err
+		}
+	}
 	w.err = w.f.Sync()
 	if w.err != nil {
 		return w.err
@@ -115,13 +143,14 @@ func (w *Writer) init() error {
 // SetCompressionLevel sets the compression level to lvl.
 // lvl must be a compress/flate compression value.
 // SetCompressionLevel must be called before WriteRunHeader or WriteEvent.
+//
+// LCIOIndex and LCIORandomAccess records are never compressed, so they can be
+// located at a fixed offset from the end of the file.
 func (w *Writer) SetCompressionLevel(lvl int) {
 	w.clvl = lvl
 	compress := w.clvl != flate.NoCompression
 	w.f.SetCompressionLevel(lvl)
 	for _, rec := range []*sio.Record{
-		w.recs.idx,
-		w.recs.rnd,
 		w.recs.rhdr,
 		w.recs.ehdr,
 		w.recs.evt,
@@ -141,6 +170,11 @@ func (w *Writer) WriteRunHeader(run *RunHeader) error {
 	w.data.rhdr.SubDetectors = make([]string, len(run.SubDetectors))
 	copy(w.data.rhdr.SubDetectors, run.SubDetectors)
 
+	w.err = w.mark(run.RunNumber, -1)
+	if w.err != nil {
+		return w.err
+	}
+
 	w.err = w.f.WriteRecord(w.recs.rhdr)
 	return w.err
 }
@@ -159,6 +193,11 @@ func (w *Writer) WriteEvent(evt *Event) error {
 	}
 	w.data.ehdr.Params = evt.Params
 
+	w.err = w.mark(evt.RunNumber, evt.EventNumber)
+	if w.err != nil {
+		return w.err
+	}
+
 	w.err = w.f.WriteRecord(w.recs.ehdr)
 	if w.err != nil {
 		return w.err
@@ -181,3 +220,115 @@ func (w *Writer) WriteEvent(evt *Event) error {
 
 	return w.err
 }
+
+// mark records the location of the run header or event about to be written.
+func (w *Writer) mark(run, evt int32) error {
+	loc, err := w.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	w.locs = append(w.locs, runEvent{run: run, evt: evt, loc: loc})
+	return nil
+}
+
+// writeRandomAccess writes the LCIOIndex record of the run headers and events
+// written to the file, followed by the LCIORandomAccess record describing it,
+// and updates the LCIORandomAccess file record at the beginning of the file.
+func (w *Writer) writeRandomAccess() error {
+	if len(w.locs) == 0 {
+		return nil
+	}
+
+	less := func(i, j int) bool {
+		li := w.locs[i]
+		lj := w.locs[j]
+		if li.run != lj.run {
+			return li.run < lj.run
+		}
+		return li.evt < lj.evt
+	}
+	inOrder := sort.SliceIsSorted(w.locs, less)
+	sort.SliceStable(w.locs, less)
+
+	var (
+		beg = w.locs[0]
+		end = w.locs[len(w.locs)-1]
+		idx = &w.data.idx
+		ra  = &w.data.rnd
+	)
+
+	*idx = Index{
+		RunMin:  beg.run,
+		Offsets: make([]Offset, len(w.locs)),
+	}
+	if beg.run == end.run {
+		idx.ControlWord |= 1
+	}
+
+	*ra = RandomAccess{
+		RunMin:     beg.run,
+		EventMin:   beg.evt,
+		RunMax:     end.run,
+		EventMax:   end.evt,
+		RecordSize: raRecordSize,
+	}
+	if inOrder {
+		ra.RecordsInOrder = 1
+	}
+
+	for i, loc := range w.locs {
+		idx.Offsets[i] = Offset{
+			RunOffset:   loc.run - idx.RunMin,
+			EventNumber: loc.evt,
+			Location:    loc.loc - idx.BaseOffset,
+		}
+		if loc.loc > math.MaxInt32 {
+			idx.ControlWord |= 2
+		}
+		switch {
+		case loc.evt < 0:
+			ra.RunHeaders++
+		default:
+			ra.Events++
+		}
+	}
+
+	var err error
+	ra.IndexLoc, err = w.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	err = w.f.WriteRecord(w.recs.idx)
+	if err != nil {
+		return err
+	}
+
+	raLoc, err := w.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	err = w.f.WriteRecord(w.recs.rnd)
+	if err != nil {
+		return err
+	}
+
+	eof, err := w.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	// the file record points to the last LCIORandomAccess record of the file.
+	ra.IndexLoc = 0
+	ra.NextLoc = raLoc
+	_, err = w.f.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	err = w.f.WriteRecord(w.recs.rnd)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.f.Seek(eof, io.SeekStart)
+	return err
+}
@@ -8,13 +8,16 @@ import (
 	"compress/flate"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 
 	"go-hep.org/x/hep/lcio"
+	"go-hep.org/x/hep/sio"
 )
 
 func ExampleWriter() {
@@ -363,3 +366,292 @@ func testCreateEvent(t *testing.T, compLevel int, fname string) {
 
 	os.Remove(fname)
 }
+
+func TestCreateCollections(t *testing.T) {
+	for _, compLevel := range []int{flate.NoCompression, flate.BestCompression} {
+		t.Run(fmt.Sprintf("compression=%d", compLevel), func(t *testing.T) {
+			testCreateCollections(t, compLevel)
+		})
+	}
+}
+
+func testCreateCollections(t *testing.T, compLevel int) {
+	fname := filepath.Join(t.TempDir(), "collections.slcio")
+
+	w, err := lcio.Create(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetCompressionLevel(compLevel)
+
+	rhdr := lcio.RunHeader{
+		RunNumber: 42,
+		Detector:  "my detector",
+	}
+	err = w.WriteRunHeader(&rhdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := lcio.Params{
+		Floats:  map[string][]float32{},
+		Ints:    map[string][]int32{},
+		Strings: map[string][]string{},
+	}
+
+	mcparts := lcio.McParticleContainer{
+		Params: params,
+		Particles: []lcio.McParticle{
+			{PDG: 11, Mass: 0.5, Charge: -1, P: [3]float64{1, 2, 3}},
+		},
+	}
+
+	trkhits := lcio.TrackerHitContainer{
+		Flags:  lcio.BitsThID1,
+		Params: params,
+		Hits: []lcio.TrackerHit{
+			{CellID0: 1, CellID1: 2, Type: 3, Pos: [3]float64{1, 2, 3}, EDep: 4, Time: 5, RawHits: []lcio.Hit{}},
+			{CellID0: 2, CellID1: 3, Type: 4, Pos: [3]float64{2, 3, 4}, EDep: 5, Time: 6, RawHits: []lcio.Hit{}},
+		},
+	}
+
+	calhits := lcio.CalorimeterHitContainer{
+		Flags:  lcio.BitsRChLong | lcio.BitsRChID1 | lcio.BitsRChTime,
+		Params: params,
+		Hits: []lcio.CalorimeterHit{
+			{CellID0: 1024, CellID1: 2048, Energy: 1000, Time: 1234, Pos: [3]float32{11, 22, 33}},
+			{CellID0: 1025, CellID1: 2049, Energy: 1001, Time: 1235, Pos: [3]float32{12, 23, 34}},
+		},
+	}
+
+	trks := lcio.TrackContainer{
+		Flags:  lcio.BitsTrHits,
+		Params: params,
+		Tracks: []lcio.Track{
+			{
+				Type: 1,
+				States: []lcio.TrackState{
+					{Loc: 1, D0: 2, Phi: 3, Omega: 4, Z0: 5, TanL: 6, Ref: [3]float32{7, 8, 9}},
+				},
+				Chi2:       10,
+				NdF:        11,
+				SubDetHits: []int32{1, 1},
+				Tracks:     []*lcio.Track{},
+				Hits:       []*lcio.TrackerHit{&trkhits.Hits[0], &trkhits.Hits[1]},
+			},
+		},
+	}
+
+	clus := lcio.ClusterContainer{
+		Flags:  lcio.BitsClHits,
+		Params: params,
+		Clusters: []lcio.Cluster{
+			{
+				Type:       1,
+				Energy:     2001,
+				Pos:        [3]float32{11, 22, 33},
+				Shape:      []float32{},
+				PIDs:       []lcio.ParticleID{},
+				Clusters:   []*lcio.Cluster{},
+				Hits:       []*lcio.CalorimeterHit{&calhits.Hits[0], &calhits.Hits[1]},
+				Weights:    []float32{0.5, 0.5},
+				SubDetEnes: []float32{},
+			},
+		},
+	}
+
+	recs := lcio.RecParticleContainer{
+		Params: params,
+		Parts: []lcio.RecParticle{
+			{
+				Type:     11,
+				P:        [3]float32{1, 2, 3},
+				Energy:   4,
+				Mass:     0.5,
+				Charge:   -1,
+				PIDs:     []lcio.ParticleID{{Likelihood: 1, Type: 2, PDG: 11, AlgType: 3, Params: []float32{}}},
+				Recs:     []*lcio.RecParticle{},
+				Tracks:   []*lcio.Track{&trks.Tracks[0]},
+				Clusters: []*lcio.Cluster{&clus.Clusters[0]},
+			},
+		},
+	}
+	recs.Parts[0].PIDUsed = &recs.Parts[0].PIDs[0]
+
+	rels := lcio.RelationContainer{
+		Flags:  lcio.BitsRelWeighted,
+		Params: params,
+		Rels: []lcio.Relation{
+			{From: &trkhits.Hits[0], To: &mcparts.Particles[0], Weight: 0.5},
+			{From: &trkhits.Hits[1], To: &mcparts.Particles[0], Weight: 0.25},
+		},
+	}
+
+	evt := lcio.Event{
+		RunNumber:   rhdr.RunNumber,
+		Detector:    rhdr.Detector,
+		EventNumber: 52,
+	}
+	evt.Add("McParticles", &mcparts)
+	evt.Add("TrackerHits", &trkhits)
+	evt.Add("CaloHits", &calhits)
+	evt.Add("Tracks", &trks)
+	evt.Add("Clusters", &clus)
+	evt.Add("RecParticles", &recs)
+	evt.Add("Relations", &rels)
+
+	const nevts = 3
+	for i := 0; i < nevts; i++ {
+		evt.EventNumber = 52 + int32(i)
+		err = w.WriteEvent(&evt)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := lcio.Open(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	n := 0
+	for r.Next() {
+		got := r.Event()
+		if got, want := got.EventNumber, 52+int32(n); got != want {
+			t.Fatalf("invalid event number: got=%d, want=%d", got, want)
+		}
+		for _, name := range evt.Names() {
+			if got, want := got.Get(name), evt.Get(name); !reflect.DeepEqual(got, want) {
+				t.Fatalf("evt #%d: collection %q differ.\ngot:\n%v\nwant:\n%v\n", n, name, got, want)
+			}
+		}
+		n++
+	}
+	if err := r.Err(); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if n != nevts {
+		t.Fatalf("invalid number of events: got=%d, want=%d", n, nevts)
+	}
+
+	testRandomAccess(t, fname, rhdr.RunNumber, 52, 52+nevts-1, nevts)
+}
+
+// testRandomAccess checks the LCIOIndex and LCIORandomAccess records of a file
+// with a single run header and nevts events.
+func testRandomAccess(t *testing.T, fname string, run, evtMin, evtMax int32, nevts int) {
+	t.Helper()
+
+	f, err := sio.Open(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	idx, err := f.BuildIndex()
+	if err != nil {
+		t.Fatalf("could not build index: %v", err)
+	}
+
+	recs := idx.Entries()
+	if got, want := recs[0].Name, lcio.Records.RandomAccess; got != want {
+		t.Fatalf("invalid first record: got=%q, want=%q", got, want)
+	}
+	if got, want := recs[len(recs)-1].Name, lcio.Records.RandomAccess; got != want {
+		t.Fatalf("invalid last record: got=%q, want=%q", got, want)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fi.Size()-recs[len(recs)-1].Pos, int64(136); got != want {
+		t.Fatalf("invalid size of last random access record: got=%d, want=%d", got, want)
+	}
+
+	var (
+		ra   lcio.RandomAccess
+		file lcio.RandomAccess
+		lidx lcio.Index
+	)
+	for _, tc := range []struct {
+		rec string
+		blk string
+		seq int64
+		ptr interface{}
+	}{
+		{lcio.Records.RandomAccess, lcio.Blocks.RandomAccess, 0, &file},
+		{lcio.Records.RandomAccess, lcio.Blocks.RandomAccess, 1, &ra},
+		{lcio.Records.Index, lcio.Blocks.Index, 0, &lidx},
+	} {
+		rec := f.Record(tc.rec)
+		rec.Disconnect()
+		err = rec.Connect(tc.blk, tc.ptr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec.SetUnpack(true)
+		err = f.SeekRecord(idx, tc.rec, tc.seq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = f.ReadRecord()
+		if err != nil {
+			t.Fatalf("could not read record %q: %v", tc.rec, err)
+		}
+	}
+
+	want := lcio.RandomAccess{
+		RunMin:         run,
+		EventMin:       -1,
+		RunMax:         run,
+		EventMax:       evtMax,
+		RunHeaders:     1,
+		Events:         int32(nevts),
+		RecordsInOrder: 1,
+		IndexLoc:       recs[len(recs)-2].Pos,
+		RecordSize:     136,
+	}
+	if !reflect.DeepEqual(ra, want) {
+		t.Fatalf("invalid random access record:\ngot= %+v\nwant=%+v", ra, want)
+	}
+
+	want.IndexLoc = 0
+	want.NextLoc = recs[len(recs)-1].Pos
+	if !reflect.DeepEqual(file, want) {
+		t.Fatalf("invalid random access file record:\ngot= %+v\nwant=%+v", file, want)
+	}
+
+	if got, want := lidx.ControlWord, uint32(1); got != want {
+		t.Fatalf("invalid index control word: got=%d, want=%d", got, want)
+	}
+	if got, want := len(lidx.Offsets), nevts+1; got != want {
+		t.Fatalf("invalid number of index offsets: got=%d, want=%d", got, want)
+	}
+	for i, off := range lidx.Offsets {
+		evt := int32(-1)
+		name := lcio.Records.RunHeader
+		if i > 0 {
+			evt = evtMin + int32(i-1)
+			name = lcio.Records.EventHeader
+		}
+		if off.EventNumber != evt {
+			t.Fatalf("invalid index offset %d: got=%d, want=%d", i, off.EventNumber, evt)
+		}
+		var rec *sio.RecordEntry
+		for j := range recs {
+			if recs[j].Pos == off.Location+lidx.BaseOffset {
+				rec = &recs[j]
+			}
+		}
+		if rec == nil || rec.Name != name {
+			t.Fatalf("invalid index offset %d location: %d (%+v)", i, off.Location, rec)
+		}
+	}
+}
@@ -15,6 +15,35 @@ type XSecInfo struct {
 	VarWeights bool    // does the file contain varying weights ?
 }
 
+// Generator represents the information in a generator tag.
+type Generator struct {
+	Name    string // the name of the generator.
+	Version string // the version of the generator.
+	Content string // additional information about the generator.
+}
+
+// WeightInfo represents the information in a weight tag of the initrwgt
+// block, describing one of the weights given for each event.
+type WeightInfo struct {
+	ID   string // the identifier of the weight.
+	Desc string // the description of the weight.
+}
+
+// WeightGroup represents the information in a weightgroup tag.
+type WeightGroup struct {
+	Name    string       // the name (or type) of the group.
+	Combine string       // how the weights of the group should be combined.
+	Weights []WeightInfo // the weights in this group.
+}
+
+// Scales represents the information in a scales tag.
+type Scales struct {
+	MuF   float64            // the factorization scale in GeV.
+	MuR   float64            // the renormalization scale in GeV.
+	MuPS  float64            // the starting scale of the parton shower in GeV.
+	Extra map[string]float64 // additional scales, indexed by their attribute name.
+}
+
 // Cut represents a cut used by the Matrix Element generator.
 type Cut struct {
 	Type string  // the variable in which to cut.
@@ -81,23 +110,25 @@ type PDFInfo struct {
 // However, FORTRAN arrays are represented by slices, except for the arrays of
 // length 2 which are represented as arrays (of size 2.)
 type HEPRUP struct {
-	IDBMUP     [2]int64            // PDG id's of beam particles.
-	EBMUP      [2]float64          // Energy of beam particles (in GeV.)
-	PDFGUP     [2]int32            // Author group for the PDF used for the beams according to the PDFLib specifications.
-	PDFSUP     [2]int32            // Id number of the PDF used for the beams according to the PDFLib specifications.
-	IDWTUP     int32               // Master switch indicating how the ME generator envisages the events weights should be interpreted according to the Les Houches accord.
-	NPRUP      int32               // number of different subprocesses in this file.
-	XSECUP     []float64           // cross-sections for the different subprocesses in pb.
-	XERRUP     []float64           // statistical error in the cross sections for the different subprocesses in pb.
-	XMAXUP     []float64           // maximum event weights (in HEPEUP.XWGTUP) for different subprocesses.
-	LPRUP      []int32             // subprocess code for the different subprocesses.
-	XSecInfo   XSecInfo            // contents of the xsecinfo tag
-	Cuts       []Cut               // contents of the cuts tag.
-	PTypes     map[string][]int64  // a map of codes for different particle types.
-	ProcInfo   map[int64]ProcInfo  // contents of the procinfo tags
-	MergeInfo  map[int64]MergeInfo // contents of the mergeinfo tags
-	GenName    string              // name of the generator which produced the file.
-	GenVersion string              // version of the generator which produced the file.
+	IDBMUP       [2]int64            // PDG id's of beam particles.
+	EBMUP        [2]float64          // Energy of beam particles (in GeV.)
+	PDFGUP       [2]int32            // Author group for the PDF used for the beams according to the PDFLib specifications.
+	PDFSUP       [2]int32            // Id number of the PDF used for the beams according to the PDFLib specifications.
+	IDWTUP       int32               // Master switch indicating how the ME generator envisages the events weights should be interpreted according to the Les Houches accord.
+	NPRUP        int32               // number of different subprocesses in this file.
+	XSECUP       []float64           // cross-sections for the different subprocesses in pb.
+	XERRUP       []float64           // statistical error in the cross sections for the different subprocesses in pb.
+	XMAXUP       []float64           // maximum event weights (in HEPEUP.XWGTUP) for different subprocesses.
+	LPRUP        []int32             // subprocess code for the different subprocesses.
+	XSecInfo     XSecInfo            // contents of the xsecinfo tag
+	Cuts         []Cut               // contents of the cuts tag.
+	PTypes       map[string][]int64  // a map of codes for different particle types.
+	ProcInfo     map[int64]ProcInfo  // contents of the procinfo tags
+	MergeInfo    map[int64]MergeInfo // contents of the mergeinfo tags
+	GenName      string              // name of the generator which produced the file.
+	GenVersion   string              // version of the generator which produced the file.
+	Generators   []Generator         // contents of the generator tags.
+	WeightGroups []WeightGroup       // contents of the initrwgt tag.
 }
 
 // EventGroup represents a set of events which are to be considered together.
@@ -126,9 +157,10 @@ type HEPEUP struct {
 	PUP        [][5]float64 // lab frame momentum (Px, Py, Pz, E and M in GeV) for the particle entries in this event.
 	VTIMUP     []float64    // invariant lifetime (c*tau, distance from production to decay in mm) for the particle entries in this event.
 	SPINUP     []float64    // spin info for the particle entries in this event given as the cosine of the angle between the spin vector of a particle and the 3-momentum of the decaying particle, specified in the lab frame.
-	Weights    []Weight     // weights associated with this event.
+	Weights    []Weight     // weights associated with this event. Weights of the rwgt tag are named after their id.
 	Clustering []Clus       // contents of the clustering tag.
 	PdfInfo    PDFInfo      // contents of the pdfinfo tag.
+	Scales     Scales       // contents of the scales tag.
 	SubEvents  EventGroup   // events included in the group if this is not a single event.
 }
 
@@ -5,27 +5,45 @@
 package lhef
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 )
 
 // Decoder represents an LHEF parser reading a particular input stream.
 //
 // A Decoder is initialized with an input io.Reader from which to read a version 1.0
 // Les Houches Accord event file.
+// The generator, xsecinfo and initrwgt tags as well as the per-event rwgt,
+// weights and scales tags of version 3.0 files are also decoded.
+//
+// Gzip-compressed input streams are transparently decompressed.
 type Decoder struct {
 	r       io.Reader
 	dec     *xml.Decoder
 	evt     xml.StartElement // the current xml.Token holding a HEPEUP
 	Version int              // LHEF file version
 	Run     HEPRUP           // User process run common block
+	Header  []byte           // header block data, without the decoded initrwgt and generator tags
 }
 
 func NewDecoder(r io.Reader) (*Decoder, error) {
 	var err error
-	dec := xml.NewDecoder(r)
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("lhef: could not open gzip stream: %w", err)
+		}
+		br = bufio.NewReader(gz)
+	}
+	rec := &recorder{r: br, on: true}
+	dec := xml.NewDecoder(rec)
 	d := &Decoder{
 		r:       r,
 		dec:     dec,
@@ -59,15 +77,21 @@ func NewDecoder(r io.Reader) (*Decoder, error) {
 		d.Version = 1
 	case "2.0":
 		d.Version = 2
+	case "3.0":
+		d.Version = 3
 	}
 
 	var (
 		init xml.StartElement
-		// header xml.StartElement // FIXME(sbinet)
+		// byte ranges of the header content and of the decoded tags
+		// it contains.
+		hdr     = [2]int64{-1, -1}
+		decoded [][2]int64
 	)
 
 Loop:
 	for {
+		pos := dec.InputOffset()
 		tok, err = dec.Token()
 		if err != nil || tok == nil {
 			return nil, err
@@ -83,13 +107,28 @@ Loop:
 				init = tok
 				break Loop
 			case "header":
-				// FIXME(sbinet): do something about header's content.
-				//		header = tok //FIXME
-				//panic(fmt.Errorf("header not implemented: %v", header))
+				if hdr[0] < 0 {
+					hdr[0] = dec.InputOffset()
+				}
+			case "initrwgt", "generator":
+				err = d.decodeInit(tok)
+				if err != nil {
+					return nil, err
+				}
+				decoded = append(decoded, [2]int64{pos, dec.InputOffset()})
+			}
+		case xml.EndElement:
+			if tok.Name.Local == "header" && hdr[0] >= 0 && hdr[1] < 0 {
+				hdr[1] = pos
 			}
 		}
 	}
 
+	if hdr[0] >= 0 && hdr[1] >= 0 {
+		d.Header = rec.extract(hdr, decoded)
+	}
+	rec.stop()
+
 	if init.Name.Local != "init" {
 		return nil, fmt.Errorf("lhef.Decoder: missing init start-tag")
 	}
@@ -141,19 +180,124 @@ Loop:
 		}
 	}
 
-	// FIXME(sbinet): implement v2 specific stuff
-	// if d.Version >= 2 {
-	// 	// do version-2 specific stuff
-	// }
-
-	err = d.seek(init.End())
-	if err != nil {
-		return nil, fmt.Errorf("lhef: could not find 'init' end tag: %w", err)
+	// extract optional initialization information
+LoopInit:
+	for {
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("lhef: could not find 'init' end tag: %w", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			err = d.decodeInit(tok)
+			if err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if tok.Name.Local == init.Name.Local {
+				break LoopInit
+			}
+		}
 	}
 
 	return d, nil
 }
 
+// decodeInit decodes the optional initialization element started by tok.
+// Unknown elements are skipped.
+func (d *Decoder) decodeInit(tok xml.StartElement) error {
+	switch tok.Name.Local {
+	case "generator":
+		var gen struct {
+			Name    string `xml:"name,attr"`
+			Version string `xml:"version,attr"`
+			Content string `xml:",chardata"`
+		}
+		err := d.dec.DecodeElement(&gen, &tok)
+		if err != nil {
+			return fmt.Errorf("lhef: could not decode generator: %w", err)
+		}
+		d.Run.Generators = append(d.Run.Generators, Generator{
+			Name:    gen.Name,
+			Version: gen.Version,
+			Content: strings.TrimSpace(gen.Content),
+		})
+		if d.Run.GenName == "" {
+			d.Run.GenName = gen.Name
+			d.Run.GenVersion = gen.Version
+		}
+		return nil
+
+	case "xsecinfo":
+		for _, attr := range tok.Attr {
+			var err error
+			switch attr.Name.Local {
+			case "neve":
+				d.Run.XSecInfo.Neve, err = strconv.ParseInt(attr.Value, 10, 64)
+			case "totxsec":
+				d.Run.XSecInfo.TotXSec, err = parseFloat(attr.Value)
+			case "maxweight":
+				d.Run.XSecInfo.MaxWeight, err = parseFloat(attr.Value)
+			case "meanweight":
+				d.Run.XSecInfo.MeanWeight, err = parseFloat(attr.Value)
+			case "negweights":
+				d.Run.XSecInfo.NegWeights = attr.Value == "yes"
+			case "varweights":
+				d.Run.XSecInfo.VarWeights = attr.Value == "yes"
+			}
+			if err != nil {
+				return fmt.Errorf("lhef: could not decode xsecinfo %s: %w", attr.Name.Local, err)
+			}
+		}
+		return d.dec.Skip()
+
+	case "initrwgt":
+		type weight struct {
+			ID   string `xml:"id,attr"`
+			Desc string `xml:",chardata"`
+		}
+		var rwgt struct {
+			Groups []struct {
+				Name    string   `xml:"name,attr"`
+				Type    string   `xml:"type,attr"`
+				Combine string   `xml:"combine,attr"`
+				Weights []weight `xml:"weight"`
+			} `xml:"weightgroup"`
+			Weights []weight `xml:"weight"`
+		}
+		err := d.dec.DecodeElement(&rwgt, &tok)
+		if err != nil {
+			return fmt.Errorf("lhef: could not decode initrwgt: %w", err)
+		}
+		infos := func(ws []weight) []WeightInfo {
+			o := make([]WeightInfo, len(ws))
+			for i, w := range ws {
+				o[i] = WeightInfo{ID: w.ID, Desc: strings.TrimSpace(w.Desc)}
+			}
+			return o
+		}
+		for _, grp := range rwgt.Groups {
+			name := grp.Name
+			if name == "" {
+				name = grp.Type
+			}
+			d.Run.WeightGroups = append(d.Run.WeightGroups, WeightGroup{
+				Name:    name,
+				Combine: grp.Combine,
+				Weights: infos(grp.Weights),
+			})
+		}
+		if len(rwgt.Weights) > 0 {
+			d.Run.WeightGroups = append(d.Run.WeightGroups, WeightGroup{
+				Weights: infos(rwgt.Weights),
+			})
+		}
+		return nil
+	}
+
+	return d.dec.Skip()
+}
+
 // advance to next event
 func (d *Decoder) next() error {
 LoopEvt:
@@ -179,21 +323,6 @@ LoopEvt:
 	return nil
 }
 
-func (d *Decoder) seek(tok xml.Token) error {
-seek:
-	for {
-		tk, err := d.dec.Token()
-		if err != nil {
-			return err
-		}
-		if tk == tok {
-			break seek
-		}
-	}
-
-	return nil
-}
-
 // Read an event from the file
 func (d *Decoder) Decode() (*HEPEUP, error) {
 
@@ -274,13 +403,166 @@ func (d *Decoder) Decode() (*HEPEUP, error) {
 	// read any additional comments...
 	_ /*evtComments*/ = buf.Bytes()
 
-	// do
+	// extract optional event information and put "cursor" to next event...
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("lhef: could not find 'event' end tag: %w", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			err = d.decodeEvent(evt, tok)
+			if err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if tok.Name.Local == d.evt.Name.Local {
+				return evt, nil
+			}
+		}
+	}
+}
 
-	// put "cursor" to next event...
-	err = d.seek(d.evt.End())
-	if err != nil {
-		return nil, fmt.Errorf("lhef: could not find 'event' end tag: %w", err)
+// decodeEvent decodes the optional event element started by tok.
+// Unknown elements are skipped.
+func (d *Decoder) decodeEvent(evt *HEPEUP, tok xml.StartElement) error {
+	switch tok.Name.Local {
+	case "rwgt":
+		var rwgt struct {
+			Weights []struct {
+				ID    string `xml:"id,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"wgt"`
+		}
+		err := d.dec.DecodeElement(&rwgt, &tok)
+		if err != nil {
+			return fmt.Errorf("lhef: could not decode rwgt: %w", err)
+		}
+		for _, w := range rwgt.Weights {
+			v, err := parseFloat(w.Value)
+			if err != nil {
+				return fmt.Errorf("lhef: could not decode weight %q: %w", w.ID, err)
+			}
+			evt.Weights = append(evt.Weights, Weight{
+				Name:    w.ID,
+				Weights: []float64{v},
+			})
+		}
+		return nil
+
+	case "weights":
+		var w struct {
+			Born    float64 `xml:"born,attr"`
+			Sudakov float64 `xml:"sudakov,attr"`
+			Values  string  `xml:",chardata"`
+		}
+		err := d.dec.DecodeElement(&w, &tok)
+		if err != nil {
+			return fmt.Errorf("lhef: could not decode weights: %w", err)
+		}
+		vs := strings.Fields(w.Values)
+		wgt := Weight{
+			Born:    w.Born,
+			Sudakov: w.Sudakov,
+			Weights: make([]float64, len(vs)),
+		}
+		for i, v := range vs {
+			wgt.Weights[i], err = parseFloat(v)
+			if err != nil {
+				return fmt.Errorf("lhef: could not decode weights: %w", err)
+			}
+		}
+		evt.Weights = append(evt.Weights, wgt)
+		return nil
+
+	case "scales":
+		for _, attr := range tok.Attr {
+			v, err := parseFloat(attr.Value)
+			if err != nil {
+				return fmt.Errorf("lhef: could not decode scale %s: %w", attr.Name.Local, err)
+			}
+			switch attr.Name.Local {
+			case "muf":
+				evt.Scales.MuF = v
+			case "mur":
+				evt.Scales.MuR = v
+			case "mups":
+				evt.Scales.MuPS = v
+			default:
+				if evt.Scales.Extra == nil {
+					evt.Scales.Extra = make(map[string]float64)
+				}
+				evt.Scales.Extra[attr.Name.Local] = v
+			}
+		}
+		return d.dec.Skip()
 	}
 
-	return evt, nil
+	return d.dec.Skip()
+}
+
+// parseFloat parses a floating point value, accepting FORTRAN-style
+// exponents (e.g. 1.5D+02).
+func parseFloat(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case 'd', 'D':
+			return 'e'
+		}
+		return r
+	}, s)
+	return strconv.ParseFloat(s, 64)
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// recorder records the bytes read from the underlying reader, until stopped.
+// recorder implements io.ByteReader so the xml.Decoder does not read ahead
+// and its input offsets match the recorded bytes.
+type recorder struct {
+	r   *bufio.Reader
+	buf []byte
+	on  bool
+}
+
+func (r *recorder) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if r.on {
+		r.buf = append(r.buf, p[:n]...)
+	}
+	return n, err
+}
+
+func (r *recorder) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil && r.on {
+		r.buf = append(r.buf, b)
+	}
+	return b, err
+}
+
+func (r *recorder) stop() {
+	r.on = false
+	r.buf = nil
+}
+
+// extract returns a copy of the recorded bytes in the [beg, end) range,
+// without the provided sub-ranges and their trailing newline.
+func (r *recorder) extract(rng [2]int64, skip [][2]int64) []byte {
+	var (
+		o   []byte
+		beg = rng[0]
+	)
+	for _, s := range skip {
+		if s[0] < beg || s[1] > rng[1] {
+			continue
+		}
+		o = append(o, r.buf[beg:s[0]]...)
+		beg = s[1]
+		if beg < rng[1] && r.buf[beg] == '\n' {
+			beg++
+		}
+	}
+	return append(o, r.buf[beg:rng[1]]...)
 }
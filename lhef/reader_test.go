@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"testing"
 
 	"go-hep.org/x/hep/lhef"
@@ -46,3 +47,97 @@ func TestLhefReading(t *testing.T) {
 		}
 	}
 }
+
+func TestLhefReadingV3(t *testing.T) {
+	f, err := os.Open("testdata/v3.lhe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec, err := lhef.NewDecoder(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := dec.Version, 3; got != want {
+		t.Fatalf("invalid version: got=%d, want=%d", got, want)
+	}
+
+	run := dec.Run
+	if got, want := run.Generators, []lhef.Generator{{
+		Name:    "MadGraph5_aMC@NLO",
+		Version: "2.6.7",
+		Content: "please cite 1405.0301",
+	}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid generators:\ngot= %+v\nwant=%+v", got, want)
+	}
+	if run.GenName != "MadGraph5_aMC@NLO" || run.GenVersion != "2.6.7" {
+		t.Fatalf("invalid generator name/version: %q %q", run.GenName, run.GenVersion)
+	}
+	if got, want := run.XSecInfo, (lhef.XSecInfo{
+		Neve:       2,
+		TotXSec:    5.047026e+02,
+		MaxWeight:  5.047026e+02,
+		MeanWeight: 5.047026e+02,
+		VarWeights: true,
+	}); got != want {
+		t.Fatalf("invalid xsecinfo:\ngot= %+v\nwant=%+v", got, want)
+	}
+	if got, want := run.WeightGroups, []lhef.WeightGroup{
+		{
+			Name:    "scale_variation",
+			Combine: "envelope",
+			Weights: []lhef.WeightInfo{
+				{ID: "1001", Desc: "muR=0.10000E+01 muF=0.10000E+01"},
+				{ID: "1002", Desc: "muR=0.20000E+01 muF=0.10000E+01"},
+			},
+		},
+		{
+			Name:    "PDF_variation",
+			Combine: "hessian",
+			Weights: []lhef.WeightInfo{{ID: "2001", Desc: "PDF=260000"}},
+		},
+		{
+			Weights: []lhef.WeightInfo{{ID: "3001", Desc: "dyn_scale"}},
+		},
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid weight groups:\ngot= %+v\nwant=%+v", got, want)
+	}
+
+	evt, err := dec.Decode()
+	if err != nil {
+		t.Fatalf("could not decode event: %+v", err)
+	}
+	if got, want := evt.Weights, []lhef.Weight{
+		{Name: "1001", Weights: []float64{5.0470260e+02}},
+		{Name: "1002", Weights: []float64{4.3012345e+02}},
+		{Name: "2001", Weights: []float64{5.1234567e+02}},
+		{Name: "3001", Weights: []float64{4.9876543e+02}},
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid event weights:\ngot= %+v\nwant=%+v", got, want)
+	}
+	if got, want := evt.Scales, (lhef.Scales{
+		MuF:   1.80649500e+02,
+		MuR:   1.80649500e+02,
+		MuPS:  9.1188e+01,
+		Extra: map[string]float64{"scale_3": 50},
+	}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid event scales:\ngot= %+v\nwant=%+v", got, want)
+	}
+
+	evt, err = dec.Decode()
+	if err != nil {
+		t.Fatalf("could not decode event: %+v", err)
+	}
+	if got, want := evt.Weights, []lhef.Weight{
+		{Born: 1.5, Weights: []float64{1, 0.5, 2}},
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid event weights:\ngot= %+v\nwant=%+v", got, want)
+	}
+
+	_, err = dec.Decode()
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF, got %+v", err)
+	}
+}
@@ -5,13 +5,24 @@
 package lhef
 
 import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 )
 
 // Encoder encodes a LHEF event to the underlying writer, following the
 // Les Houches Event File format.
+//
+// Events are written as they are encoded.
+// The header block data (e.g. a MadGraph banner or SLHA blocks) is copied
+// verbatim to the output, so the Header of a Decoder may be passed through.
 type Encoder struct {
 	w      io.Writer
 	once   sync.Once
@@ -33,10 +44,14 @@ func (e *Encoder) init() error {
 	var err error
 	run := &e.Run
 
+	gens := run.Generators
+	if len(gens) == 0 && run.GenName != "" {
+		gens = []Generator{{Name: run.GenName, Version: run.GenVersion}}
+	}
+
 	version := 1.0
-	if run.XSecInfo.Neve > 0 {
-		version = 2.0
-		panic("not implemented")
+	if run.XSecInfo.Neve > 0 || len(gens) > 0 || len(run.WeightGroups) > 0 {
+		version = 3.0
 	}
 	_, err = fmt.Fprintf(
 		e.w,
@@ -48,16 +63,32 @@ func (e *Encoder) init() error {
 		return err
 	}
 
-	if len(e.Header) > 0 {
-		hdr := string(e.Header)
-		if hdr[len(hdr)-1] == '\n' {
-			hdr = hdr[:len(hdr)-1]
+	if len(e.Header) > 0 || len(run.WeightGroups) > 0 {
+		_, err = fmt.Fprintf(e.w, "<header>\n")
+		if err != nil {
+			return err
 		}
-		_, err = fmt.Fprintf(
-			e.w,
-			"<header>\n%v\n</header>\n",
-			hdr,
-		)
+		if hdr := e.Header; len(hdr) > 0 {
+			// header data is written verbatim, only adding the newlines
+			// around it if needed.
+			if hdr[0] == '\n' {
+				hdr = hdr[1:]
+			}
+			if n := len(hdr); n > 0 && hdr[n-1] != '\n' {
+				hdr = append(hdr[:n:n], '\n')
+			}
+			_, err = e.w.Write(hdr)
+			if err != nil {
+				return err
+			}
+		}
+		if len(run.WeightGroups) > 0 {
+			err = e.writeInitRwgt()
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(e.w, "</header>\n")
 		if err != nil {
 			return err
 		}
@@ -90,18 +121,94 @@ func (e *Encoder) init() error {
 		}
 	}
 
-	if run.XSecInfo.Neve <= 0 {
+	_, err = fmt.Fprintf(
+		e.w,
+		"#%s\n",
+		"",
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, gen := range gens {
+		_, err = fmt.Fprintf(
+			e.w,
+			"<generator%s%s>%s</generator>\n",
+			xmlAttr("name", gen.Name),
+			xmlAttr("version", gen.Version),
+			xmlText(gen.Content),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if xsec := run.XSecInfo; xsec.Neve > 0 {
 		_, err = fmt.Fprintf(
 			e.w,
-			"#%s\n</init>\n",
-			"",
+			"<xsecinfo neve=\"%d\" totxsec=\"%s\" maxweight=\"%s\" meanweight=\"%s\" negweights=\"%s\" varweights=\"%s\"/>\n",
+			xsec.Neve,
+			xmlFloat(xsec.TotXSec),
+			xmlFloat(xsec.MaxWeight),
+			xmlFloat(xsec.MeanWeight),
+			xmlBool(xsec.NegWeights),
+			xmlBool(xsec.VarWeights),
 		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(e.w, "</init>\n")
+	return err
+}
+
+func (e *Encoder) writeInitRwgt() error {
+	_, err := fmt.Fprintf(e.w, "<initrwgt>\n")
+	if err != nil {
 		return err
 	}
 
+	for _, grp := range e.Run.WeightGroups {
+		group := grp.Name != "" || grp.Combine != ""
+		if group {
+			_, err = fmt.Fprintf(
+				e.w,
+				"<weightgroup%s%s>\n",
+				xmlAttr("name", grp.Name),
+				xmlAttr("combine", grp.Combine),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		for _, w := range grp.Weights {
+			_, err = fmt.Fprintf(
+				e.w,
+				"<weight%s>%s</weight>\n",
+				xmlAttr("id", w.ID),
+				xmlText(w.Desc),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		if group {
+			_, err = fmt.Fprintf(e.w, "</weightgroup>\n")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = fmt.Fprintf(e.w, "</initrwgt>\n")
 	return err
 }
 
+// Encode writes the event to the underlying writer.
+// The run information and header data are written before the first event.
+//
+// Events with sub-events are written as an eventgroup of these sub-events.
 func (e *Encoder) Encode(evt *HEPEUP) error {
 	var err error
 	e.once.Do(func() { err = e.init() })
@@ -126,12 +233,25 @@ func (e *Encoder) Encode(evt *HEPEUP) error {
 				return err
 			}
 		}
-		_, err = fmt.Fprintf(e.w, "</eventgroup>\n")
+		_, err = fmt.Fprintf(e.w, ">\n")
 		if err != nil {
 			return err
 		}
+		for i := range evt.SubEvents.Events {
+			err = e.encode(&evt.SubEvents.Events[i])
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(e.w, "</eventgroup>\n")
+		return err
 	}
 
+	return e.encode(evt)
+}
+
+func (e *Encoder) encode(evt *HEPEUP) error {
+	var err error
 	_, err = fmt.Fprintf(
 		e.w,
 		"<event>\n %5d %5d %13.6E %13.6E %13.6E %13.6E\n",
@@ -149,7 +269,7 @@ func (e *Encoder) Encode(evt *HEPEUP) error {
 	for i := 0; i < int(evt.NUP); i++ {
 		_, err = fmt.Fprintf(
 			e.w,
-			" %7d %4d %4d %4d %4d %4d %17.10E %17.10E %17.10E %17.10E %17.10E %1.f. %1.f.\n",
+			" %7d %4d %4d %4d %4d %4d %17.10E %17.10E %17.10E %17.10E %17.10E %.4E %.4E\n",
 			evt.IDUP[i],
 			evt.ISTUP[i],
 			evt.MOTHUP[i][0], evt.MOTHUP[i][1],
@@ -163,22 +283,157 @@ func (e *Encoder) Encode(evt *HEPEUP) error {
 		}
 	}
 
-	if e.Run.XSecInfo.Neve > 0 {
-		panic("not implemented")
-	}
-
 	_, err = fmt.Fprintf(
 		e.w,
-		"#%s\n</event>\n",
+		"#%s\n",
 		"",
 	)
+	if err != nil {
+		return err
+	}
+
+	err = e.writeWeights(evt)
+	if err != nil {
+		return err
+	}
+
+	err = e.writeScales(evt)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(e.w, "</event>\n")
+	return err
+}
+
+// writeWeights writes the weights of the event.
+// Named weights with a single value are written in the rwgt tag,
+// the other ones in weights tags.
+func (e *Encoder) writeWeights(evt *HEPEUP) error {
+	var (
+		err   error
+		named = 0
+	)
+	for _, w := range evt.Weights {
+		if w.Name != "" && len(w.Weights) == 1 {
+			named++
+			continue
+		}
+		_, err = fmt.Fprintf(e.w, "<weights")
+		if err != nil {
+			return err
+		}
+		if w.Born != 0 {
+			_, err = fmt.Fprintf(e.w, " born=\"%s\"", xmlFloat(w.Born))
+			if err != nil {
+				return err
+			}
+		}
+		if w.Sudakov != 0 {
+			_, err = fmt.Fprintf(e.w, " sudakov=\"%s\"", xmlFloat(w.Sudakov))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(e.w, ">")
+		if err != nil {
+			return err
+		}
+		for i, v := range w.Weights {
+			sep := " "
+			if i == 0 {
+				sep = ""
+			}
+			_, err = fmt.Fprintf(e.w, "%s%s", sep, xmlFloat(v))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(e.w, "</weights>\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	if named == 0 {
+		return nil
+	}
+
+	_, err = fmt.Fprintf(e.w, "<rwgt>\n")
+	if err != nil {
+		return err
+	}
+	for _, w := range evt.Weights {
+		if w.Name == "" || len(w.Weights) != 1 {
+			continue
+		}
+		_, err = fmt.Fprintf(
+			e.w,
+			"<wgt%s> %s </wgt>\n",
+			xmlAttr("id", w.Name),
+			xmlFloat(w.Weights[0]),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(e.w, "</rwgt>\n")
+	return err
+}
+
+func (e *Encoder) writeScales(evt *HEPEUP) error {
+	scales := &evt.Scales
+	if scales.MuF == 0 && scales.MuR == 0 && scales.MuPS == 0 && len(scales.Extra) == 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(e.w, "<scales")
+	if err != nil {
+		return err
+	}
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{
+		{"muf", scales.MuF},
+		{"mur", scales.MuR},
+		{"mups", scales.MuPS},
+	} {
+		if v.value == 0 {
+			continue
+		}
+		_, err = fmt.Fprintf(e.w, " %s=\"%s\"", v.name, xmlFloat(v.value))
+		if err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(scales.Extra))
+	for k := range scales.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_, err = fmt.Fprintf(e.w, "%s", xmlAttr(k, xmlFloat(scales.Extra[k])))
+		if err != nil {
+			return err
+		}
+	}
 
+	_, err = fmt.Fprintf(e.w, "/>\n")
 	return err
 }
 
+// Close writes the end of the event file and closes the underlying writer,
+// if it implements io.Closer.
 func (e *Encoder) Close() error {
+	var err error
+	e.once.Do(func() { err = e.init() })
+	if err != nil {
+		return err
+	}
 
-	_, err := fmt.Fprintf(
+	_, err = fmt.Fprintf(
 		e.w,
 		"</LesHouchesEvents>\n",
 	)
@@ -191,3 +446,70 @@ func (e *Encoder) Close() error {
 	}
 	return err
 }
+
+// Create creates the named LHEF file and returns an encoder writing to it.
+// The file is gzip-compressed when its name ends with ".gz".
+//
+// Closing the encoder closes the file.
+func Create(fname string) (*Encoder, error) {
+	f, err := os.Create(fname)
+	if err != nil {
+		return nil, err
+	}
+	w := &file{f: f, bw: bufio.NewWriter(f)}
+	w.w = w.bw
+	if strings.HasSuffix(fname, ".gz") {
+		w.gz = gzip.NewWriter(w.bw)
+		w.w = w.gz
+	}
+	return NewEncoder(w)
+}
+
+// file is a buffered, possibly compressed, output file.
+type file struct {
+	f  *os.File
+	bw *bufio.Writer
+	gz *gzip.Writer
+	w  io.Writer
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	return f.w.Write(p)
+}
+
+func (f *file) Close() error {
+	if f.gz != nil {
+		err := f.gz.Close()
+		if err != nil {
+			_ = f.f.Close()
+			return fmt.Errorf("lhef: could not close gzip stream: %w", err)
+		}
+	}
+	err := f.bw.Flush()
+	if err != nil {
+		_ = f.f.Close()
+		return fmt.Errorf("lhef: could not flush file: %w", err)
+	}
+	return f.f.Close()
+}
+
+func xmlAttr(name, value string) string {
+	return " " + name + "=\"" + xmlText(value) + "\""
+}
+
+func xmlText(v string) string {
+	buf := new(strings.Builder)
+	_ = xml.EscapeText(buf, []byte(v))
+	return buf.String()
+}
+
+func xmlFloat(v float64) string {
+	return strconv.FormatFloat(v, 'E', -1, 64)
+}
+
+func xmlBool(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
@@ -5,8 +5,12 @@
 package lhef_test
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"go-hep.org/x/hep/lhef"
@@ -137,3 +141,175 @@ func TestLhefWriting(t *testing.T) {
 		}()
 	}
 }
+
+func TestLhefWritingV3(t *testing.T) {
+	raw, err := os.ReadFile("testdata/v3.lhe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decodeAll := func(r io.Reader) (lhef.HEPRUP, []*lhef.HEPEUP) {
+		t.Helper()
+		dec, err := lhef.NewDecoder(r)
+		if err != nil {
+			t.Fatalf("could not create decoder: %+v", err)
+		}
+		var evts []*lhef.HEPEUP
+		for {
+			evt, err := dec.Decode()
+			if err == io.EOF {
+				return dec.Run, evts
+			}
+			if err != nil {
+				t.Fatalf("could not decode event: %+v", err)
+			}
+			evts = append(evts, evt)
+		}
+	}
+
+	run, evts := decodeAll(bytes.NewReader(raw))
+
+	buf := new(bytes.Buffer)
+	enc, err := lhef.NewEncoder(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Run = run
+	for i, evt := range evts {
+		err = enc.Encode(evt)
+		if err != nil {
+			t.Fatalf("could not encode event %d: %+v", i, err)
+		}
+	}
+	err = enc.Close()
+	if err != nil {
+		t.Fatalf("could not close encoder: %+v", err)
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte(`<LesHouchesEvents version="3.0">`)) {
+		t.Fatalf("invalid version:\n%s", buf.Bytes())
+	}
+
+	got, gevts := decodeAll(buf)
+	if !reflect.DeepEqual(got, run) {
+		t.Fatalf("invalid run round-trip:\ngot= %+v\nwant=%+v", got, run)
+	}
+	if !reflect.DeepEqual(gevts, evts) {
+		t.Fatalf("invalid events round-trip:\ngot= %+v\nwant=%+v", gevts, evts)
+	}
+}
+
+func TestLhefHeaderPassthrough(t *testing.T) {
+	f, err := os.Open("testdata/v3.lhe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	dec, err := lhef.NewDecoder(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(dec.Header, []byte("<MGVersion>\n2.6.7\n</MGVersion>\n")) {
+		t.Fatalf("invalid header:\n%s", dec.Header)
+	}
+	if bytes.Contains(dec.Header, []byte("initrwgt")) {
+		t.Fatalf("header contains decoded initrwgt tag:\n%s", dec.Header)
+	}
+
+	var evts []*lhef.HEPEUP
+	for {
+		evt, err := dec.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not decode event: %+v", err)
+		}
+		evts = append(evts, evt)
+	}
+
+	fname := filepath.Join(t.TempDir(), "out.lhe.gz")
+	enc, err := lhef.Create(fname)
+	if err != nil {
+		t.Fatalf("could not create file: %+v", err)
+	}
+	enc.Run = dec.Run
+	enc.Header = dec.Header
+	for i, evt := range evts {
+		err = enc.Encode(evt)
+		if err != nil {
+			t.Fatalf("could not encode event %d: %+v", i, err)
+		}
+	}
+	err = enc.Encode(&lhef.HEPEUP{
+		SubEvents: lhef.EventGroup{
+			Events: []lhef.HEPEUP{*evts[0], *evts[1]},
+			Nreal:  1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not encode event group: %+v", err)
+	}
+	err = enc.Close()
+	if err != nil {
+		t.Fatalf("could not close file: %+v", err)
+	}
+
+	raw, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+		t.Fatalf("output file is not gzip-compressed")
+	}
+
+	dec2, err := lhef.NewDecoder(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("could not decode compressed file: %+v", err)
+	}
+	if !bytes.Equal(dec2.Header, dec.Header) {
+		t.Fatalf("invalid header round-trip:\ngot:\n%s\nwant:\n%s", dec2.Header, dec.Header)
+	}
+	if !reflect.DeepEqual(dec2.Run, dec.Run) {
+		t.Fatalf("invalid run round-trip:\ngot= %+v\nwant=%+v", dec2.Run, dec.Run)
+	}
+	for i, want := range append(evts, evts...) {
+		got, err := dec2.Decode()
+		if err != nil {
+			t.Fatalf("could not decode event %d: %+v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("invalid event %d round-trip:\ngot= %+v\nwant=%+v", i, got, want)
+		}
+	}
+	_, err = dec2.Decode()
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF, got %+v", err)
+	}
+}
+
+func TestLhefWritingEmpty(t *testing.T) {
+	buf := new(bytes.Buffer)
+	enc, err := lhef.NewEncoder(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Header = []byte("<slha>\nBLOCK MASS\n</slha>")
+	err = enc.Close()
+	if err != nil {
+		t.Fatalf("could not close encoder: %+v", err)
+	}
+
+	dec, err := lhef.NewDecoder(buf)
+	if err != nil {
+		t.Fatalf("could not decode empty file: %+v", err)
+	}
+	if got, want := string(dec.Header), "\n<slha>\nBLOCK MASS\n</slha>\n"; got != want {
+		t.Fatalf("invalid header: got=%q, want=%q", got, want)
+	}
+	_, err = dec.Decode()
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF, got %+v", err)
+	}
+}
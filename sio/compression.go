@@ -0,0 +1,148 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Compression is a compression algorithm of the data of records.
+type Compression uint8
+
+const (
+	Zlib Compression = iota // Zlib compresses records with zlib (the default).
+	LZ4                     // LZ4 compresses records with LZ4 blocks.
+	Zstd                    // Zstd compresses records with Zstandard frames.
+)
+
+func (codec Compression) String() string {
+	switch codec {
+	case Zlib:
+		return "zlib"
+	case LZ4:
+		return "lz4"
+	case Zstd:
+		return "zstd"
+	}
+	return fmt.Sprintf("Compression(%d)", uint8(codec))
+}
+
+// codecFrom returns the codec of compressed records with the provided options.
+func codecFrom(options uint32) Compression {
+	return Compression((options & optCodecMask) >> optCodecShift)
+}
+
+// compress compresses src with the codec at the provided level,
+// and returns the compressed data.
+// compress returns nil if src could not be compressed.
+func (codec Compression) compress(src []byte, lvl int) ([]byte, error) {
+	var b bytes.Buffer
+	switch codec {
+	case Zlib:
+		zip, err := zlib.NewWriterLevel(&b, lvl)
+		if err != nil {
+			return nil, err
+		}
+		_, err = zip.Write(src)
+		if err != nil {
+			return nil, err
+		}
+		err = zip.Close()
+		if err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+
+	case LZ4:
+		dst := make([]byte, lz4.CompressBlockBound(len(src)))
+		var (
+			n   int
+			err error
+		)
+		switch {
+		case lvl > 1:
+			c := lz4.CompressorHC{Level: lz4.CompressionLevel(1 << (8 + lvl))}
+			n, err = c.CompressBlock(src, dst)
+		default:
+			var c lz4.Compressor
+			n, err = c.CompressBlock(src, dst)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			// not compressible.
+			return nil, nil
+		}
+		return dst[:n], nil
+
+	case Zstd:
+		zw, err := zstd.NewWriter(&b, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(lvl)))
+		if err != nil {
+			return nil, err
+		}
+		_, err = zw.Write(src)
+		if err != nil {
+			return nil, err
+		}
+		err = zw.Close()
+		if err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("sio: unknown compression codec %v", codec)
+}
+
+// decompress decompresses src with the codec into dst.
+func (codec Compression) decompress(dst, src []byte) error {
+	var (
+		n   int
+		err error
+	)
+	switch codec {
+	case Zlib:
+		unzip, err := zlib.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return err
+		}
+		defer unzip.Close()
+		n, err = io.ReadFull(unzip, dst)
+		if err != nil {
+			return err
+		}
+
+	case LZ4:
+		n, err = lz4.UncompressBlock(src, dst)
+		if err != nil {
+			return err
+		}
+
+	case Zstd:
+		zr, err := zstd.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		n, err = io.ReadFull(zr, dst)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("sio: unknown compression codec %v", codec)
+	}
+
+	if n != len(dst) {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
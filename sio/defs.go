@@ -18,6 +18,8 @@ const (
 	pntrMarker     uint32 = 0x00000000
 	optCompress    uint32 = 0x00000001
 	optNotCompress uint32 = 0xfffffffe
+	optCodecMask   uint32 = 0x0000000e // codec of compressed records
+	optCodecShift         = 1
 	alignLen       uint32 = 0x00000003
 )
 
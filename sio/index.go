@@ -0,0 +1,180 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sio
+
+import (
+	"fmt"
+	"io"
+)
+
+// RecordEntry describes the location of a record in a stream.
+type RecordEntry struct {
+	Name string // name of the record
+	Seq  int64  // sequence number of the record, among the records with the same name
+	Pos  int64  // position of the record header in the stream
+}
+
+// Index locates the records of a stream, by name and sequence number,
+// so they can be read without sequentially scanning the stream.
+//
+// Index implements Codec, so it can be stored as a block of a record
+// and read back from a stream.
+type Index struct {
+	recs []RecordEntry
+	seqs map[string][]int // indices of records in recs, by record name
+}
+
+// NewIndex creates a new index from the provided record entries.
+// The sequence numbers of the entries are recomputed from their order.
+func NewIndex(recs []RecordEntry) *Index {
+	idx := &Index{}
+	for _, rec := range recs {
+		idx.add(rec.Name, rec.Pos)
+	}
+	return idx
+}
+
+func (idx *Index) add(name string, pos int64) {
+	if idx.seqs == nil {
+		idx.seqs = make(map[string][]int)
+	}
+	seq := idx.seqs[name]
+	idx.recs = append(idx.recs, RecordEntry{
+		Name: name,
+		Seq:  int64(len(seq)),
+		Pos:  pos,
+	})
+	idx.seqs[name] = append(seq, len(idx.recs)-1)
+}
+
+// Entries returns the entries of the index, in stream order.
+func (idx *Index) Entries() []RecordEntry {
+	return idx.recs
+}
+
+// Len returns the number of indexed records with the provided name.
+func (idx *Index) Len(name string) int {
+	return len(idx.seqs[name])
+}
+
+// Find returns the entry of the seq-th record with the provided name.
+func (idx *Index) Find(name string, seq int64) (RecordEntry, bool) {
+	recs := idx.seqs[name]
+	if seq < 0 || seq >= int64(len(recs)) {
+		return RecordEntry{}, false
+	}
+	return idx.recs[recs[seq]], true
+}
+
+// MarshalSio implements sio.Marshaler
+func (idx *Index) MarshalSio(w Writer) error {
+	enc := NewEncoder(w)
+	enc.Encode(int64(len(idx.recs)))
+	for _, rec := range idx.recs {
+		enc.Encode(rec.Name)
+		enc.Encode(rec.Pos)
+	}
+	return enc.Err()
+}
+
+// UnmarshalSio implements sio.Unmarshaler
+func (idx *Index) UnmarshalSio(r Reader) error {
+	dec := NewDecoder(r)
+	var n int64
+	dec.Decode(&n)
+	if dec.Err() != nil {
+		return dec.Err()
+	}
+	*idx = Index{}
+	for i := int64(0); i < n; i++ {
+		var (
+			name string
+			pos  int64
+		)
+		dec.Decode(&name)
+		dec.Decode(&pos)
+		if dec.Err() != nil {
+			return dec.Err()
+		}
+		idx.add(name, pos)
+	}
+	return nil
+}
+
+// BuildIndex scans the whole stream and returns the index of its records.
+// The current position of the stream is left unchanged.
+func (stream *Stream) BuildIndex() (*Index, error) {
+	cur, err := stream.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Seek(cur, io.SeekStart)
+
+	fi, err := stream.f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+
+	pos, err := stream.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{}
+	for {
+		var rechdr recordHeader
+		err = stream.read(&rechdr)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if rechdr.Typ != recMarker {
+			return nil, ErrStreamNoRecMarker
+		}
+
+		var recdata recordData
+		err = stream.read(&recdata)
+		if err != nil {
+			return nil, err
+		}
+		if recdata.NameLen > rechdr.Len {
+			return nil, fmt.Errorf("sio: invalid record name length (%d) at %d", recdata.NameLen, pos)
+		}
+		name := make([]byte, recdata.NameLen)
+		_, err = io.ReadFull(stream.f, name)
+		if err != nil {
+			return nil, err
+		}
+		idx.add(string(name), pos)
+
+		// skip over the record data, and over any padding bytes inserted
+		// to make the next record header start on a 4-bytes boundary.
+		next := pos + int64(rechdr.Len) + int64(align4U32(recdata.DataLen))
+		if next > size {
+			return nil, io.ErrUnexpectedEOF
+		}
+		pos, err = stream.Seek(next, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return idx, nil
+}
+
+// SeekRecord positions the stream so that the next call to ReadRecord
+// reads the seq-th record (starting at 0) with the provided name,
+// as located by the index.
+func (stream *Stream) SeekRecord(idx *Index, name string, seq int64) error {
+	rec, ok := idx.Find(name, seq)
+	if !ok {
+		return fmt.Errorf("sio: no record %q with sequence number %d in index", name, seq)
+	}
+	_, err := stream.Seek(rec.Pos, io.SeekStart)
+	return err
+}
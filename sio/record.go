@@ -63,6 +63,13 @@ func (rec *Record) SetCompress(compress bool) {
 	}
 }
 
+// Compression returns the compression codec of this record,
+// as last read from a stream.
+// The codec of written records is set by Stream.SetCompression.
+func (rec *Record) Compression() Compression {
+	return codecFrom(rec.options)
+}
+
 // Options returns the options of this record.
 func (rec *Record) Options() uint32 {
 	return rec.options
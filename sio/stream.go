@@ -7,7 +7,6 @@ package sio
 import (
 	"bytes"
 	"compress/flate"
-	"compress/zlib"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -58,8 +57,9 @@ type Stream struct {
 	name string   // stream name
 	f    *os.File // file handle
 
-	recpos  int64 // start position of last record read
-	complvl int   // compression level
+	recpos  int64       // start position of last record read
+	complvl int         // compression level
+	codec   Compression // compression codec of written records
 
 	recs map[string]*Record // records to read/write
 }
@@ -106,7 +106,7 @@ func (stream *Stream) Mode() (os.FileMode, error) {
 	return fi.Mode(), nil
 }
 
-// SetCompressionLevel sets the (zlib) compression level
+// SetCompressionLevel sets the compression level, from 0 to 9.
 func (stream *Stream) SetCompressionLevel(lvl int) {
 	if lvl < 0 {
 		stream.complvl = flate.DefaultCompression
@@ -117,8 +117,20 @@ func (stream *Stream) SetCompressionLevel(lvl int) {
 	}
 }
 
+// SetCompression sets the compression codec of the records written to the stream.
+// The codec of read records is recorded in their options.
+func (stream *Stream) SetCompression(codec Compression) {
+	stream.codec = codec
+}
+
+// Compression returns the compression codec of the records written to the stream.
+func (stream *Stream) Compression() Compression {
+	return stream.codec
+}
+
 // CurPos returns the current position in the file
-//  -1 if error
+//
+//	-1 if error
 func (stream *Stream) CurPos() int64 {
 	pos, err := stream.f.Seek(0, 1)
 	if err != nil {
@@ -276,19 +288,11 @@ func (stream *Stream) ReadRecord() (*Record, error) {
 				}
 			}
 
-			unzip, err := zlib.NewReader(bytes.NewBuffer(cbuf))
-			if err != nil {
-				return nil, err
-			}
 			buf = make([]byte, recdata.UCmpLen)
-			nb, err := io.ReadFull(unzip, buf)
-			unzip.Close()
+			err = codecFrom(record.options).decompress(buf, cbuf)
 			if err != nil {
 				return nil, err
 			}
-			if nb != len(buf) {
-				return nil, io.ErrUnexpectedEOF
-			}
 			//stream.recpos = recstart
 		}
 		recbuf := newReader(buf)
@@ -331,26 +335,33 @@ func (stream *Stream) WriteRecord(record *Record) error {
 	recdata.DataLen = ucmplen
 
 	if record.Compress() {
-		var b bytes.Buffer
-		zip, err := zlib.NewWriterLevel(&b, stream.complvl)
+		cbuf, err := stream.codec.compress(buf.Bytes(), stream.complvl)
 		if err != nil {
 			return err
 		}
-		_, err = zip.Write(buf.Bytes())
-		if err != nil {
-			return err
-		}
-		err = zip.Close()
-		if err != nil {
-			return err
-		}
-		recdata.DataLen = align4U32(uint32(b.Len()))
-		if n := int(recdata.DataLen - uint32(b.Len())); n > 0 {
-			var tmp [4]byte
-			b.Write(tmp[:n])
+		switch cbuf {
+		case nil:
+			// data could not be compressed: store it as is.
+			recdata.Options &= optNotCompress
+		default:
+			recdata.Options &^= optCodecMask
+			recdata.Options |= uint32(stream.codec) << optCodecShift
+
+			b := bytes.NewBuffer(cbuf)
+			recdata.DataLen = uint32(b.Len())
+			if n := int(align4U32(recdata.DataLen) - recdata.DataLen); n > 0 {
+				var tmp [4]byte
+				b.Write(tmp[:n])
+			}
+			if stream.codec == Zlib {
+				// zlib records are written with the padding bytes inserted
+				// to make the next record header start on a 4-bytes boundary
+				// counted in their data, as zlib ignores them.
+				// LZ4 blocks can not be followed by extra bytes.
+				recdata.DataLen = uint32(b.Len())
+			}
+			buf.buf = b
 		}
-
-		buf.buf = &b
 	}
 
 	err = stream.write(&rechdr)
@@ -5,8 +5,10 @@
 package sio_test
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -106,6 +108,83 @@ func TestReadWrite(t *testing.T) {
 	testReadStream(t, fname)
 }
 
+func TestReadWriteCompression(t *testing.T) {
+	for _, tc := range []struct {
+		codec sio.Compression
+		lvl   int
+	}{
+		{codec: sio.Zlib, lvl: 5},
+		{codec: sio.LZ4, lvl: 1},
+		{codec: sio.LZ4, lvl: 9},
+		{codec: sio.Zstd, lvl: 1},
+		{codec: sio.Zstd, lvl: 9},
+	} {
+		t.Run(fmt.Sprintf("%v-%d", tc.codec, tc.lvl), func(t *testing.T) {
+			fname := filepath.Join(t.TempDir(), "rw.sio")
+			testWriteStream(t, fname, func(f *sio.Stream, rec *sio.Record) {
+				f.SetCompression(tc.codec)
+				f.SetCompressionLevel(tc.lvl)
+				rec.SetCompress(true)
+			})
+			testReadStream(t, fname)
+
+			// check the codec of a large, compressible, record.
+			type Data struct {
+				Floats []float64
+			}
+			data := Data{Floats: make([]float64, 1024)}
+			for i := range data.Floats {
+				data.Floats[i] = float64(i % 16)
+			}
+
+			w, err := sio.Create(fname)
+			if err != nil {
+				t.Fatalf("could not create [%s]: %v", fname, err)
+			}
+			w.SetCompression(tc.codec)
+			w.SetCompressionLevel(tc.lvl)
+			wrec := w.Record("Data")
+			wrec.SetCompress(true)
+			err = wrec.Connect("Data", &data)
+			if err != nil {
+				t.Fatalf("could not connect block: %v", err)
+			}
+			err = w.WriteRecord(wrec)
+			if err != nil {
+				t.Fatalf("could not write record: %v", err)
+			}
+			err = w.Close()
+			if err != nil {
+				t.Fatalf("could not close stream: %v", err)
+			}
+
+			r, err := sio.Open(fname)
+			if err != nil {
+				t.Fatalf("could not open [%s]: %v", fname, err)
+			}
+			defer r.Close()
+
+			var got Data
+			rrec := r.Record("Data")
+			rrec.SetUnpack(true)
+			err = rrec.Connect("Data", &got)
+			if err != nil {
+				t.Fatalf("could not connect block: %v", err)
+			}
+			_, err = r.ReadRecord()
+			if err != nil {
+				t.Fatalf("could not read record: %v", err)
+			}
+			if !rrec.Compress() || rrec.Compression() != tc.codec {
+				t.Fatalf("invalid record compression: compress=%v, codec=%v", rrec.Compress(), rrec.Compression())
+			}
+			if !reflect.DeepEqual(got, data) {
+				t.Fatalf("invalid record data")
+			}
+		})
+	}
+}
+
 func testReadStream(t *testing.T, fname string) {
 
 	f, err := sio.Open(fname)
@@ -220,7 +299,7 @@ func testReadStream(t *testing.T, fname string) {
 	}
 }
 
-func testWriteStream(t *testing.T, fname string) {
+func testWriteStream(t *testing.T, fname string, opts ...func(f *sio.Stream, rec *sio.Record)) {
 	f, err := sio.Create(fname)
 	if err != nil {
 		t.Fatalf("could not create [%s]: %v", fname, err)
@@ -239,6 +318,9 @@ func testWriteStream(t *testing.T, fname string) {
 	if !rec.Unpack() {
 		t.Fatalf("expected record to unpack now")
 	}
+	for _, opt := range opts {
+		opt(f, rec)
+	}
 
 	err = rec.Connect("RunHeader", &runhdr)
 	if err != nil {
@@ -769,3 +851,144 @@ func TestShortBlockRead(t *testing.T) {
 		}
 	}()
 }
+
+func TestIndex(t *testing.T) {
+	type Header struct {
+		Evt int32
+	}
+	type Data struct {
+		Evt    int32
+		Floats []float64
+	}
+
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compress=%v", compress), func(t *testing.T) {
+			fname := filepath.Join(t.TempDir(), "index.sio")
+
+			w, err := sio.Create(fname)
+			if err != nil {
+				t.Fatalf("could not create [%s]: %v", fname, err)
+			}
+			var (
+				hdr  Header
+				data Data
+			)
+			whdr := w.Record("Header")
+			err = whdr.Connect("Header", &hdr)
+			if err != nil {
+				t.Fatalf("could not connect header: %v", err)
+			}
+			wdata := w.Record("Data")
+			err = wdata.Connect("Data", &data)
+			if err != nil {
+				t.Fatalf("could not connect data: %v", err)
+			}
+			whdr.SetCompress(compress)
+			wdata.SetCompress(compress)
+
+			const nevts = 10
+			for i := 0; i < nevts; i++ {
+				hdr.Evt = int32(i)
+				data.Evt = int32(i)
+				data.Floats = make([]float64, i)
+				err = w.WriteRecord(whdr)
+				if err != nil {
+					t.Fatalf("could not write header %d: %v", i, err)
+				}
+				err = w.WriteRecord(wdata)
+				if err != nil {
+					t.Fatalf("could not write data %d: %v", i, err)
+				}
+			}
+
+			idx, err := w.BuildIndex()
+			if err != nil {
+				t.Fatalf("could not build index: %v", err)
+			}
+
+			irec := w.Record("Index")
+			err = irec.Connect("Index", idx)
+			if err != nil {
+				t.Fatalf("could not connect index: %v", err)
+			}
+			err = w.WriteRecord(irec)
+			if err != nil {
+				t.Fatalf("could not write index: %v", err)
+			}
+			err = w.Close()
+			if err != nil {
+				t.Fatalf("could not close [%s]: %v", fname, err)
+			}
+
+			r, err := sio.Open(fname)
+			if err != nil {
+				t.Fatalf("could not open [%s]: %v", fname, err)
+			}
+			defer r.Close()
+
+			idx, err = r.BuildIndex()
+			if err != nil {
+				t.Fatalf("could not build index: %v", err)
+			}
+			if got, want := len(idx.Entries()), 2*nevts+1; got != want {
+				t.Fatalf("invalid number of entries: got=%d, want=%d", got, want)
+			}
+			if got, want := idx.Len("Data"), nevts; got != want {
+				t.Fatalf("invalid number of data records: got=%d, want=%d", got, want)
+			}
+			if r.CurPos() != 0 {
+				t.Fatalf("invalid position after index build: %d", r.CurPos())
+			}
+
+			// read the index stored in the stream back.
+			err = r.SeekRecord(idx, "Index", 0)
+			if err != nil {
+				t.Fatalf("could not seek index record: %v", err)
+			}
+			var stored sio.Index
+			irec = r.Record("Index")
+			err = irec.Connect("Index", &stored)
+			if err != nil {
+				t.Fatalf("could not connect index: %v", err)
+			}
+			irec.SetUnpack(true)
+			_, err = r.ReadRecord()
+			if err != nil {
+				t.Fatalf("could not read index record: %v", err)
+			}
+			if got, want := stored.Entries(), idx.Entries()[:2*nevts]; !reflect.DeepEqual(got, want) {
+				t.Fatalf("invalid stored index:\ngot= %v\nwant=%v", got, want)
+			}
+			irec.SetUnpack(false)
+
+			rdata := r.Record("Data")
+			err = rdata.Connect("Data", &data)
+			if err != nil {
+				t.Fatalf("could not connect data: %v", err)
+			}
+			rdata.SetUnpack(true)
+
+			for _, i := range []int64{7, 2, 9, 0} {
+				err = r.SeekRecord(&stored, "Data", i)
+				if err != nil {
+					t.Fatalf("could not seek data %d: %v", i, err)
+				}
+				rec, err := r.ReadRecord()
+				if err != nil {
+					t.Fatalf("could not read data %d: %v", i, err)
+				}
+				if rec.Name() != "Data" {
+					t.Fatalf("invalid record name: %q", rec.Name())
+				}
+				if data.Evt != int32(i) || len(data.Floats) != int(i) {
+					t.Fatalf("invalid data %d: %+v", i, data)
+				}
+			}
+
+			err = r.SeekRecord(idx, "Data", nevts)
+			if err == nil {
+				t.Fatalf("expected an error seeking a missing record")
+			}
+		})
+	}
+}
@@ -34,14 +34,23 @@ func Decode(r io.Reader) (*SLHA, error) {
 	var blk *Block
 	var part *Particle
 	var data SLHA
+	var comments []string // comment lines preceding the current line
+	preamble := func() []string {
+		o := comments
+		comments = nil
+		if o == nil {
+			o = []string{}
+		}
+		return o
+	}
 	scan := bufio.NewScanner(r)
 	for scan.Scan() {
 		bline := scan.Bytes()
 		if len(bline) <= 0 {
 			continue
 		}
-		if bline[0] == '#' {
-			// TODO(sbinet) store block/entry comments
+		if bytes.HasPrefix(bytes.TrimSpace(bline), []byte("#")) {
+			comments = append(comments, string(bline))
 			continue
 		}
 		bup := bytes.ToUpper(bline)
@@ -65,10 +74,11 @@ func Decode(r io.Reader) (*SLHA, error) {
 			// }
 			i := len(data.Blocks)
 			data.Blocks = append(data.Blocks, Block{
-				Name:    groups[0],
-				Comment: comment,
-				Q:       math.NaN(),
-				Data:    make(DataArray, 0),
+				Name:     groups[0],
+				Comment:  comment,
+				Q:        math.NaN(),
+				Data:     make(DataArray, 0),
+				Preamble: preamble(),
 			})
 			blk = &data.Blocks[i]
 			if len(groups) > 1 && groups[1] != "" {
@@ -110,11 +120,12 @@ func Decode(r io.Reader) (*SLHA, error) {
 			}
 			i := len(data.Particles)
 			data.Particles = append(data.Particles, Particle{
-				PdgID:   pdgid,
-				Width:   width,
-				Mass:    math.NaN(),
-				Comment: comment,
-				Decays:  make(Decays, 0, 2),
+				PdgID:    pdgid,
+				Width:    width,
+				Mass:     math.NaN(),
+				Comment:  comment,
+				Decays:   make(Decays, 0, 2),
+				Preamble: preamble(),
 			})
 			part = &data.Particles[i]
 
@@ -126,17 +137,23 @@ func Decode(r io.Reader) (*SLHA, error) {
 				if err != nil {
 					return nil, err
 				}
+				blk.Data[len(blk.Data)-1].Preamble = comments
+				comments = nil
 			case stDecay:
 				err = addDecayEntry(bline, part)
 				if err != nil {
 					return nil, err
 				}
+				part.Decays[len(part.Decays)-1].Preamble = comments
+				comments = nil
 			}
 		default:
 
 			fmt.Fprintf(os.Stderr, "**WARN** ignoring unknown section [%s]\n", string(bup))
 		}
 	}
+	data.Trailer = comments
+
 	err = scan.Err()
 	if err != nil {
 		if err != io.EOF {
@@ -196,7 +213,16 @@ func addBlockEntry(line []byte, blk *Block) error {
 	case "MODSEL":
 		v, err := strconv.Atoi(sval)
 		if err != nil {
-			return err
+			// SLHA2 allows for real-valued entries (e.g. MODSEL 12)
+			v, err := anyvalue(sval)
+			if err != nil {
+				return err
+			}
+			if _, ok := v.(float64); !ok {
+				return fmt.Errorf("slha.decode: invalid MODSEL entry %q", sval)
+			}
+			val.v = reflect.ValueOf(v)
+			break
 		}
 		val.v = reflect.ValueOf(v)
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"reflect"
 	"strings"
 )
 
@@ -68,10 +69,17 @@ const (
 )
 
 // Encode writes the SLHA informations to w.
+//
+// The comment lines held by the Preamble of blocks, data lines, decay tables
+// and decay lines, and by the Trailer, are written verbatim.
 func Encode(w io.Writer, data *SLHA) error {
 	var err error
 	for i := range data.Blocks {
 		blk := &data.Blocks[i]
+		err = writeComments(w, blk.Preamble)
+		if err != nil {
+			return err
+		}
 		str := []string{"BLOCK", blk.Name}
 		if !math.IsNaN(blk.Q) {
 			str = append(str, fmt.Sprintf("Q=%16.8E", blk.Q))
@@ -85,6 +93,10 @@ func Encode(w io.Writer, data *SLHA) error {
 		}
 
 		for _, item := range blk.Data {
+			err = writeComments(w, item.Preamble)
+			if err != nil {
+				return err
+			}
 			v := item.Value
 			idx := item.Index.Index() //
 			args := make([]interface{}, 0, len(idx)+2)
@@ -95,39 +107,66 @@ func Encode(w io.Writer, data *SLHA) error {
 			}
 			args = append(args, v.Interface(), v.Comment())
 			format := blockFormat(blk.Name, len(args))
+			if blk.Name == "MODSEL" && v.Kind() == reflect.Float64 {
+				format = " %5d   %16.8E   # %s\n"
+			}
+			if strings.Contains(format, "E") {
+				// make sure integer values are written as floats.
+				if f, ok := toFloat(v.Interface()); ok {
+					args[len(args)-2] = f
+				}
+			}
 			_, err = fmt.Fprintf(w, format, args...)
 			if err != nil {
 				return err
 			}
 		}
-		_, err = fmt.Fprintf(w, "#\n")
-		if err != nil {
-			return err
+		if blk.Preamble == nil {
+			_, err = fmt.Fprintf(w, "#\n")
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	for i := range data.Particles {
 		part := &data.Particles[i]
 
-		_, err = fmt.Fprintf(w, "%sDECAY %9d   %16.8E   # %s\n", particleHeader, part.PdgID, part.Width, part.Comment)
+		hdr := particleHeader
+		if part.Preamble != nil {
+			hdr = ""
+			err = writeComments(w, part.Preamble)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintf(w, "%sDECAY %9d   %16.8E   # %s\n", hdr, part.PdgID, part.Width, part.Comment)
 		if err != nil {
 			return err
 		}
 		if len(part.Decays) <= 0 {
-			_, err = fmt.Fprintf(w, "#\n")
-			if err != nil {
-				return err
+			if part.Preamble == nil {
+				_, err = fmt.Fprintf(w, "#\n")
+				if err != nil {
+					return err
+				}
 			}
 			continue
 		}
 
-		_, err = w.Write([]byte(decayHeader))
-		if err != nil {
-			return err
+		if part.Preamble == nil {
+			_, err = w.Write([]byte(decayHeader))
+			if err != nil {
+				return err
+			}
 		}
 
 		for j := range part.Decays {
 			decay := &part.Decays[j]
+			err = writeComments(w, decay.Preamble)
+			if err != nil {
+				return err
+			}
 			_, err = fmt.Fprintf(w, decayLineFront, decay.Br, len(decay.IDs))
 			if err != nil {
 				return err
@@ -144,11 +183,24 @@ func Encode(w io.Writer, data *SLHA) error {
 			}
 		}
 
-		_, err = fmt.Fprintf(w, "#\n")
+		if part.Preamble == nil {
+			_, err = fmt.Fprintf(w, "#\n")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = writeComments(w, data.Trailer)
+	return err
+}
+
+func writeComments(w io.Writer, lines []string) error {
+	for _, line := range lines {
+		_, err := fmt.Fprintf(w, "%s\n", line)
 		if err != nil {
 			return err
 		}
-
 	}
-	return err
+	return nil
 }
@@ -7,15 +7,66 @@ package slha // import "go-hep.org/x/hep/slha"
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 // SLHA holds informations about a SUSY Les Houches Accords file.
+//
+// Full comment lines of a decoded file are kept in the Preamble of the
+// block, data line, decay table or decay line they precede, and in the
+// Trailer for the comment lines ending the file, so they can be re-emitted
+// by Encode.
 type SLHA struct {
 	Blocks    Blocks
 	Particles Particles
+	Trailer   []string // comment lines at the end of the file
+}
+
+// Mass returns the mass of the particle with the provided PDG ID, as given
+// in the MASS block.
+func (s *SLHA) Mass(pdgid int) (float64, error) {
+	blk := s.Blocks.Get("MASS")
+	if blk == nil {
+		return 0, fmt.Errorf("slha: no MASS block")
+	}
+	return blk.Float(pdgid)
+}
+
+// SetMass sets the mass of the particle with the provided PDG ID in the MASS
+// block, creating that block if needed.
+// The mass of the corresponding decay table, if any, is updated as well.
+func (s *SLHA) SetMass(pdgid int, m float64) error {
+	blk := s.Blocks.Get("MASS")
+	if blk == nil {
+		s.Blocks = append(s.Blocks, Block{
+			Name:    "MASS",
+			Comment: "Mass Spectrum",
+			Q:       math.NaN(),
+		})
+		blk = &s.Blocks[len(s.Blocks)-1]
+	}
+	err := blk.Set(m, pdgid)
+	if err != nil {
+		return err
+	}
+	if part := s.Particles.Get(pdgid); part != nil {
+		part.Mass = m
+	}
+	return nil
+}
+
+// Mixing returns the mixing matrix held by the named block (e.g. NMIX,
+// UMIX, STOPMIX, ...)
+func (s *SLHA) Mixing(name string) ([][]float64, error) {
+	blk := s.Blocks.Get(name)
+	if blk == nil {
+		return nil, fmt.Errorf("slha: no %s block", name)
+	}
+	return blk.Matrix()
 }
 
 // Value represents a value (string,int,float64) + comment in a SLHA line.
@@ -57,6 +108,11 @@ type Block struct {
 	Comment string
 	Q       float64
 	Data    DataArray
+
+	// Preamble holds the comment lines preceding the block.
+	// Encode writes a default separator line after blocks with a nil
+	// Preamble.
+	Preamble []string
 }
 
 // Get returns the Value at index args.
@@ -73,6 +129,97 @@ func (b *Block) Get(args ...int) (Value, error) {
 	return val, err
 }
 
+// Float returns the value at index args as a float64.
+// Integer values are converted to float64.
+// Note that args are 1-based indices.
+func (b *Block) Float(args ...int) (float64, error) {
+	val, err := b.Get(args...)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := toFloat(val.Interface())
+	if !ok {
+		return 0, fmt.Errorf("slha: value at index (%s) in block %q is not a number", strings.Join(strindex(args...), ", "), b.Name)
+	}
+	return v, nil
+}
+
+// Int returns the value at index args as an int64.
+// Note that args are 1-based indices.
+func (b *Block) Int(args ...int) (int64, error) {
+	val, err := b.Get(args...)
+	if err != nil {
+		return 0, err
+	}
+	switch v := val.Interface().(type) {
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	}
+	return 0, fmt.Errorf("slha: value at index (%s) in block %q is not an integer", strings.Join(strindex(args...), ", "), b.Name)
+}
+
+// Text returns the value at index args as a string.
+// Note that args are 1-based indices.
+func (b *Block) Text(args ...int) (string, error) {
+	val, err := b.Get(args...)
+	if err != nil {
+		return "", err
+	}
+	v, ok := val.Interface().(string)
+	if !ok {
+		return "", fmt.Errorf("slha: value at index (%s) in block %q is not a string", strings.Join(strindex(args...), ", "), b.Name)
+	}
+	return v, nil
+}
+
+// Matrix returns the values of a block of 2-dim indices as a matrix, with
+// matrix[i-1][j-1] holding the value at index (i, j).
+// The size of the matrix is given by the largest indices of the block,
+// missing entries being set to 0.
+func (b *Block) Matrix() ([][]float64, error) {
+	n, m := 0, 0
+	for _, item := range b.Data {
+		idx := item.Index.Index()
+		if len(idx) != 2 || idx[0] < 1 || idx[1] < 1 {
+			return nil, fmt.Errorf("slha: block %q is not a matrix (index=%v)", b.Name, idx)
+		}
+		if idx[0] > n {
+			n = idx[0]
+		}
+		if idx[1] > m {
+			m = idx[1]
+		}
+	}
+
+	mat := make([][]float64, n)
+	for i := range mat {
+		mat[i] = make([]float64, m)
+	}
+	for _, item := range b.Data {
+		idx := item.Index.Index()
+		v, ok := toFloat(item.Value.Interface())
+		if !ok {
+			return nil, fmt.Errorf("slha: value at index (%d, %d) in block %q is not a number", idx[0], idx[1], b.Name)
+		}
+		mat[idx[0]-1][idx[1]-1] = v
+	}
+	return mat, nil
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
 // Set sets the Value at index args with v.
 // Set creates a new empty Value if none exists at args.
 // Note that args are 1-based indices.
@@ -98,8 +245,9 @@ type DataArray []DataItem
 // DataItem is a pair of (Index,Value).
 // Index is a n-dim index (1-based indices)
 type DataItem struct {
-	Index Index
-	Value Value
+	Index    Index
+	Value    Value
+	Preamble []string // comment lines preceding the data line
 }
 
 // Get returns the value at the n-dim index idx.
@@ -190,14 +338,47 @@ func (b Blocks) Get(name string) *Block {
 
 // Decay is a decay line in an SLHA file.
 type Decay struct {
-	Br      float64 // Branching Ratio
-	IDs     []int   // list of PDG IDs to which the decay occur
-	Comment string  // comment attached to this decay line - if any
+	Br       float64  // Branching Ratio
+	IDs      []int    // list of PDG IDs to which the decay occur
+	Comment  string   // comment attached to this decay line - if any
+	Preamble []string // comment lines preceding the decay line
 }
 
 // Decays is a list of decays in a Decay block.
 type Decays []Decay
 
+// Br returns the sum of the branching ratios of the decays into the
+// provided decay products, irrespective of their order.
+func (ds Decays) Br(ids ...int) float64 {
+	want := sortedIDs(ids)
+	br := 0.0
+	for _, d := range ds {
+		if len(d.IDs) != len(want) {
+			continue
+		}
+		if reflect.DeepEqual(sortedIDs(d.IDs), want) {
+			br += d.Br
+		}
+	}
+	return br
+}
+
+// Sum returns the sum of all the branching ratios.
+func (ds Decays) Sum() float64 {
+	sum := 0.0
+	for _, d := range ds {
+		sum += d.Br
+	}
+	return sum
+}
+
+func sortedIDs(ids []int) []int {
+	o := make([]int, len(ids))
+	copy(o, ids)
+	sort.Ints(o)
+	return o
+}
+
 // Particle is the representation of a single, specific particle, decay block from a SLHA file.
 type Particle struct {
 	PdgID   int     // PDG-ID code
@@ -205,6 +386,11 @@ type Particle struct {
 	Mass    float64 // mass of that particle
 	Comment string
 	Decays  Decays
+
+	// Preamble holds the comment lines preceding the decay table.
+	// Encode writes default header lines for decay tables with a nil
+	// Preamble.
+	Preamble []string
 }
 
 // Particles is a block of particle's decays in an SLHA file.
@@ -5,6 +5,7 @@
 package slha_test
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"os"
@@ -257,3 +258,212 @@ func compareSLHA(a, b slha.SLHA) (bool, string) {
 
 	return ok, strings.Join(str, "\n")
 }
+
+func TestTypedAccess(t *testing.T) {
+	f, err := os.Open("testdata/sps1a.spc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data, err := slha.Decode(f)
+	if err != nil {
+		t.Fatalf("could not decode file: %+v", err)
+	}
+
+	mass, err := data.Mass(1000021)
+	if err != nil {
+		t.Fatalf("could not get gluino mass: %+v", err)
+	}
+	if got, want := mass, 6.07713704e+02; got != want {
+		t.Fatalf("invalid gluino mass: got=%v, want=%v", got, want)
+	}
+
+	modsel := data.Blocks.Get("MODSEL")
+	if v, err := modsel.Int(1); err != nil || v != 1 {
+		t.Fatalf("invalid MODSEL(1): v=%v, err=%v", v, err)
+	}
+	if v, err := modsel.Float(1); err != nil || v != 1 {
+		t.Fatalf("invalid MODSEL(1) as float: v=%v, err=%v", v, err)
+	}
+	if v, err := data.Blocks.Get("SPINFO").Text(1); err != nil || v != "SOFTSUSY" {
+		t.Fatalf("invalid SPINFO(1): v=%v, err=%v", v, err)
+	}
+	if _, err := data.Blocks.Get("SPINFO").Float(1); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, err := data.Blocks.Get("MASS").Int(5); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	nmix, err := data.Mixing("NMIX")
+	if err != nil {
+		t.Fatalf("could not get NMIX: %+v", err)
+	}
+	if len(nmix) != 4 || len(nmix[0]) != 4 {
+		t.Fatalf("invalid NMIX shape: %v", nmix)
+	}
+	if got, want := nmix[0][1], -5.31103553e-02; got != want {
+		t.Fatalf("invalid N_12: got=%v, want=%v", got, want)
+	}
+
+	stopmix, err := data.Mixing("STOPMIX")
+	if err != nil {
+		t.Fatalf("could not get STOPMIX: %+v", err)
+	}
+	if got, want := stopmix, [][]float64{
+		{5.53644960e-01, 8.32752820e-01},
+		{8.32752820e-01, -5.53644960e-01},
+	}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid STOPMIX:\ngot= %v\nwant=%v", got, want)
+	}
+
+	if _, err := data.Mixing("MASS"); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if _, err := data.Mixing("NOSUCHBLOCK"); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	top := data.Particles.Get(6)
+	if got, want := top.Decays.Br(24, 5), 1.0; got != want {
+		t.Fatalf("invalid BR(t->bW): got=%v, want=%v", got, want)
+	}
+	if got, want := top.Decays.Br(24, 24), 0.0; got != want {
+		t.Fatalf("invalid BR(t->WW): got=%v, want=%v", got, want)
+	}
+	if got, want := top.Decays.Sum(), 1.0; got != want {
+		t.Fatalf("invalid sum of BRs: got=%v, want=%v", got, want)
+	}
+}
+
+func TestComments(t *testing.T) {
+	raw, err := os.ReadFile("testdata/sps1a.spc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := slha.Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("could not decode file: %+v", err)
+	}
+
+	if got, want := data.Blocks[0].Preamble[4], "##  param_card corresponding the SPS point 1a (by SoftSusy 2.0.5)  *"; got != want {
+		t.Fatalf("invalid preamble:\ngot= %q\nwant=%q", got, want)
+	}
+	if got, want := data.Blocks.Get("MASS").Data[0].Preamble, []string{"# PDG code           mass       particle"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid data preamble:\ngot= %q\nwant=%q", got, want)
+	}
+
+	buf := new(bytes.Buffer)
+	err = slha.Encode(buf, data)
+	if err != nil {
+		t.Fatalf("could not encode file: %+v", err)
+	}
+
+	// comment lines are re-emitted at the same place.
+	var (
+		want = comments(raw)
+		got  = comments(buf.Bytes())
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid comment lines:\ngot= %q\nwant=%q", got, want)
+	}
+}
+
+func TestModify(t *testing.T) {
+	f, err := os.Open("testdata/sps1a.spc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data, err := slha.Decode(f)
+	if err != nil {
+		t.Fatalf("could not decode file: %+v", err)
+	}
+
+	err = data.SetMass(1000021, 1500)
+	if err != nil {
+		t.Fatalf("could not set gluino mass: %+v", err)
+	}
+	if got, want := data.Particles.Get(1000021).Mass, 1500.0; got != want {
+		t.Fatalf("invalid gluino decay table mass: got=%v, want=%v", got, want)
+	}
+	err = data.Blocks.Get("MINPAR").Set(int64(20), 3)
+	if err != nil {
+		t.Fatalf("could not set tanb: %+v", err)
+	}
+	data.Blocks = append(data.Blocks, slha.Block{
+		Name:    "QEXTPAR",
+		Comment: "extra parameters",
+		Q:       math.NaN(),
+	})
+	err = data.Blocks.Get("QEXTPAR").Set(1000.0, 1)
+	if err != nil {
+		t.Fatalf("could not set QEXTPAR: %+v", err)
+	}
+	data.Particles = append(data.Particles, slha.Particle{
+		PdgID: 1000039,
+		Width: 1,
+		Decays: slha.Decays{
+			{Br: 0.5, IDs: []int{1000022, 22}},
+			{Br: 0.5, IDs: []int{1000022, 23}},
+		},
+	})
+
+	buf := new(bytes.Buffer)
+	err = slha.Encode(buf, data)
+	if err != nil {
+		t.Fatalf("could not encode file: %+v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("%!")) {
+		t.Fatalf("invalid formatting:\n%s", buf.Bytes())
+	}
+
+	got, err := slha.Decode(buf)
+	if err != nil {
+		t.Fatalf("could not decode modified file: %+v", err)
+	}
+	if m, err := got.Mass(1000021); err != nil || m != 1500 {
+		t.Fatalf("invalid gluino mass: m=%v, err=%v", m, err)
+	}
+	if v, err := got.Blocks.Get("MINPAR").Float(3); err != nil || v != 20 {
+		t.Fatalf("invalid tanb: v=%v, err=%v", v, err)
+	}
+	if v, err := got.Blocks.Get("QEXTPAR").Float(1); err != nil || v != 1000 {
+		t.Fatalf("invalid QEXTPAR: v=%v, err=%v", v, err)
+	}
+	if br := got.Particles.Get(1000039).Decays.Br(22, 1000022); br != 0.5 {
+		t.Fatalf("invalid gravitino BR: %v", br)
+	}
+}
+
+func TestModselReal(t *testing.T) {
+	data, err := slha.Decode(strings.NewReader("BLOCK MODSEL\n    1    1   # sugra\n   12    1.00000000E+03   # Q_max\n"))
+	if err != nil {
+		t.Fatalf("could not decode MODSEL: %+v", err)
+	}
+	if v, err := data.Blocks.Get("MODSEL").Float(12); err != nil || v != 1000 {
+		t.Fatalf("invalid MODSEL(12): v=%v, err=%v", v, err)
+	}
+
+	buf := new(bytes.Buffer)
+	err = slha.Encode(buf, data)
+	if err != nil {
+		t.Fatalf("could not encode MODSEL: %+v", err)
+	}
+	if !strings.Contains(buf.String(), "1.00000000E+03") {
+		t.Fatalf("invalid MODSEL encoding:\n%s", buf.String())
+	}
+}
+
+func comments(raw []byte) []string {
+	var o []string
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			o = append(o, strings.TrimRight(line, " "))
+		}
+	}
+	return o
+}
@@ -7,9 +7,11 @@ package xrootd // import "go-hep.org/x/hep/xrootd"
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 
 	"go-hep.org/x/hep/xrootd/xrdproto/auth"
+	"go-hep.org/x/hep/xrootd/xrdproto/auth/gsi"
 	"go-hep.org/x/hep/xrootd/xrdproto/auth/host"
 	"go-hep.org/x/hep/xrootd/xrdproto/auth/krb5"
 	"go-hep.org/x/hep/xrootd/xrdproto/auth/unix"
@@ -18,6 +20,7 @@ import (
 // defaultProviders is the list of authentification providers a xrootd client will use by default.
 var defaultProviders = []auth.Auther{
 	krb5.Default,
+	gsi.Default,
 	unix.Default,
 	host.Default,
 }
@@ -42,19 +45,53 @@ func (sess *cliSession) auth(ctx context.Context, securityInformation []byte) er
 			errs = append(errs, fmt.Errorf("xrootd: could not authorize using %s: provider was not found", provider))
 			continue
 		}
-		r, err := auther.Request(params)
+		err := sess.authenticate(ctx, auther, params)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("xrootd: could not authorize using %s: %w", provider, err))
 			continue
 		}
-		_, err = sess.Send(ctx, nil, r)
+		return nil
+	}
+
+	return fmt.Errorf("xrootd: could not authorize:\n%v", errs)
+}
+
+// authenticate sends the kXR_auth requests formed by auther, until the
+// server accepts or rejects the credentials.
+func (sess *cliSession) authenticate(ctx context.Context, auther auth.Auther, params []string) error {
+	hs, ok := auther.(auth.Handshaker)
+	if !ok {
+		r, err := auther.Request(params)
+		if err != nil {
+			return err
+		}
 		// TODO: should we react somehow to redirection?
+		_, err = sess.Send(ctx, nil, r)
+		return err
+	}
+
+	h, r, err := hs.Handshake(params)
+	if err != nil {
+		return err
+	}
+	for {
+		_, err = sess.Send(ctx, nil, r)
+		var more *authMoreError
+		if !errors.As(err, &more) {
+			return err
+		}
+		r, err = h.Next(more.data)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("xrootd: could not authorize using %s: %w", provider, err))
-			continue
+			return err
 		}
-		return nil
 	}
+}
 
-	return fmt.Errorf("xrootd: could not authorize:\n%v", errs)
+// authMoreError is returned by requests answered with kXR_authmore.
+type authMoreError struct {
+	data []byte
+}
+
+func (err *authMoreError) Error() string {
+	return "xrootd: server requested more authentication data"
 }
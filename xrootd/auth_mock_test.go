@@ -0,0 +1,106 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xrootd // import "go-hep.org/x/hep/xrootd"
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+
+	"go-hep.org/x/hep/xrootd/internal/xrdenc"
+	"go-hep.org/x/hep/xrootd/xrdproto"
+	"go-hep.org/x/hep/xrootd/xrdproto/auth"
+)
+
+// tstHandshaker is a security provider answering the challenges of the server.
+type tstHandshaker struct {
+	params []string
+}
+
+func (*tstHandshaker) Provider() string { return "tst" }
+
+func (*tstHandshaker) Request(params []string) (*auth.Request, error) {
+	return nil, fmt.Errorf("tst: handshake required")
+}
+
+func (a *tstHandshaker) Handshake(params []string) (auth.Handshake, *auth.Request, error) {
+	a.params = params
+	return tstHandshake{}, &auth.Request{Type: [4]byte{'t', 's', 't'}, Credentials: "hello"}, nil
+}
+
+type tstHandshake struct{}
+
+func (tstHandshake) Next(data []byte) (*auth.Request, error) {
+	return &auth.Request{Type: [4]byte{'t', 's', 't'}, Credentials: "answer:" + string(data)}, nil
+}
+
+// rawResponse is a response made of raw bytes.
+type rawResponse []byte
+
+func (o rawResponse) MarshalXrd(wBuffer *xrdenc.WBuffer) error {
+	wBuffer.WriteBytes(o)
+	return nil
+}
+
+func TestSession_Auth_Mock(t *testing.T) {
+	var (
+		challenges = []string{"challenge-1", "challenge-2"}
+		want       = []string{"hello", "answer:challenge-1", "answer:challenge-2"}
+		got        []string
+	)
+
+	serverFunc := func(cancel func(), conn net.Conn) {
+		for i := range want {
+			data, err := xrdproto.ReadRequest(conn)
+			if err != nil {
+				cancel()
+				t.Errorf("could not read request: %v", err)
+				return
+			}
+
+			var req auth.Request
+			header, err := unmarshalRequest(data, &req)
+			if err != nil {
+				cancel()
+				t.Errorf("could not unmarshal request: %v", err)
+				return
+			}
+			got = append(got, req.Credentials)
+
+			status := xrdproto.Ok
+			var resp xrdproto.Marshaler
+			if i < len(challenges) {
+				status = xrdproto.AuthMore
+				resp = rawResponse(challenges[i])
+			}
+			err = xrdproto.WriteResponse(conn, header.StreamID, status, resp)
+			if err != nil {
+				cancel()
+				t.Errorf("could not write response: %v", err)
+				return
+			}
+		}
+	}
+
+	hs := &tstHandshaker{}
+	clientFunc := func(cancel func(), client *Client) {
+		client.auths = map[string]auth.Auther{hs.Provider(): hs}
+		err := client.sessions[client.initialSessionID].auth(context.Background(), []byte("&P=tst,v:1,c:x"))
+		if err != nil {
+			t.Fatalf("could not authenticate: %v", err)
+		}
+	}
+
+	testClientWithMockServer(serverFunc, clientFunc)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid credentials:\ngot = %q\nwant= %q", got, want)
+	}
+	if got, want := hs.params, []string{"v:1", "c:x"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid parameters:\ngot = %q\nwant= %q", got, want)
+	}
+}
@@ -6,9 +6,11 @@ package xrootd // import "go-hep.org/x/hep/xrootd"
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"go-hep.org/x/hep/xrootd/xrdproto"
 	"go-hep.org/x/hep/xrootd/xrdproto/auth"
@@ -18,6 +20,7 @@ import (
 // Concurrent requests are supported.
 // Zero value is invalid, Client should be instantiated using NewClient.
 type Client struct {
+	ctx      context.Context // ctx bounds the lifetime of the connections to the servers.
 	cancel   context.CancelFunc
 	auths    map[string]auth.Auther
 	username string
@@ -30,6 +33,12 @@ type Client struct {
 	sessions         map[string]*cliSession
 
 	maxRedirections int
+	timeout         time.Duration // timeout of each attempt of a request, if not zero.
+	maxRetries      int           // maximum number of retries of a request.
+	backoff         time.Duration // duration to wait before the first retry of a request.
+	tracer          Tracer        // tracer of the requests, if not nil.
+	streams         int           // maximum number of parallel data streams to each server.
+	stripeSize      int           // size of the chunks of the reads and writes striped across data streams.
 }
 
 // Option configures an XRootD client.
@@ -44,6 +53,67 @@ func WithAuth(a auth.Auther) Option {
 	}
 }
 
+// WithTimeout sets the maximum duration of each attempt to send a request
+// and receive its response.
+// Attempts that time out are retried as configured by WithRetries.
+// A zero duration, the default, means no timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(client *Client) error {
+		if d < 0 {
+			return fmt.Errorf("xrootd: invalid negative timeout %v", d)
+		}
+		client.timeout = d
+		return nil
+	}
+}
+
+// WithRetries sets the maximum number of times a request is retried after
+// a transient failure (lost connection to the server or timeout), and the
+// duration to wait before the first retry.
+// The waiting duration is doubled after each retry.
+// Before a retry, lost connections are re-established and logged in.
+// By default, requests are retried 3 times, waiting 1s before the first retry.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(client *Client) error {
+		if n < 0 || backoff < 0 {
+			return fmt.Errorf("xrootd: invalid retries (n=%d, backoff=%v)", n, backoff)
+		}
+		client.maxRetries = n
+		client.backoff = backoff
+		return nil
+	}
+}
+
+// WithMaxRedirections sets the maximum number of redirections followed by
+// a request, 10 by default.
+func WithMaxRedirections(n int) Option {
+	return func(client *Client) error {
+		if n < 0 {
+			return fmt.Errorf("xrootd: invalid negative number of redirections %d", n)
+		}
+		client.maxRedirections = n
+		return nil
+	}
+}
+
+// WithStreams sets the maximum number of parallel data streams opened to
+// each server with kXR_bind requests, and the size of the chunks large reads
+// and writes are split into to be striped across these streams.
+// A zero number of streams sends all the data over the connection of the
+// session, and a zero size disables the striping.
+// By default, up to 8 streams are opened, and reads and writes are striped
+// in chunks of 4 MiB.
+func WithStreams(n, size int) Option {
+	return func(client *Client) error {
+		if n < 0 || n > 255 || size < 0 {
+			return fmt.Errorf("xrootd: invalid parallel streams (n=%d, size=%d)", n, size)
+		}
+		client.streams = n
+		client.stripeSize = size
+		return nil
+	}
+}
+
 func (client *Client) addAuth(auth auth.Auther) error {
 	client.auths[auth.Provider()] = auth
 	return nil
@@ -66,11 +136,16 @@ func NewClient(ctx context.Context, address string, username string, opts ...Opt
 	ctx, cancel := context.WithCancel(ctx)
 
 	client := &Client{
+		ctx:             ctx,
 		cancel:          cancel,
 		auths:           make(map[string]auth.Auther),
 		username:        username,
 		sessions:        make(map[string]*cliSession),
 		maxRedirections: 10,
+		maxRetries:      3,
+		backoff:         1 * time.Second,
+		streams:         8,
+		stripeSize:      4 << 20,
 	}
 
 	client.initSecurityProviders()
@@ -128,12 +203,59 @@ func (client *Client) Send(ctx context.Context, resp xrdproto.Response, req xrdp
 	return client.sendSession(ctx, client.initialSessionID, resp, req)
 }
 
+// sendSession sends the request to the server identified by sessionID,
+// retrying it after transient failures.
 func (client *Client) sendSession(ctx context.Context, sessionID string, resp xrdproto.Response, req xrdproto.Request) (string, error) {
-	client.mu.RLock()
-	session, ok := client.sessions[sessionID]
-	client.mu.RUnlock()
-	if !ok {
-		return "", fmt.Errorf("xrootd: session with id = %q was not found", sessionID)
+	return client.trace(ctx, req, func(ctx context.Context) (string, int, error) {
+		return client.sendRetried(ctx, sessionID, resp, req)
+	})
+}
+
+// sendRetried sends the request to the server identified by sessionID,
+// retrying it after transient failures.
+// sendRetried returns the number of retries that were made.
+func (client *Client) sendRetried(ctx context.Context, sessionID string, resp xrdproto.Response, req xrdproto.Request) (string, int, error) {
+	backoff := client.backoff
+	for retry := 0; ; retry++ {
+		id, err := client.sendSessionOnce(ctx, sessionID, resp, req)
+		if err == nil || retry >= client.maxRetries || !isTransient(err) || ctx.Err() != nil {
+			return id, retry, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return id, retry, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// sendSessionOnce makes one attempt to send the request to the server
+// identified by sessionID, following the redirections.
+func (client *Client) sendSessionOnce(ctx context.Context, sessionID string, resp xrdproto.Response, req xrdproto.Request) (string, error) {
+	parent := ctx
+	if client.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.timeout)
+		defer cancel()
+	}
+
+	id, err := client.sendRedirected(ctx, sessionID, resp, req)
+	if err != nil && parent.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		err = &transientError{err: fmt.Errorf("xrootd: request timed out after %v: %w", client.timeout, err)}
+	}
+	return id, err
+}
+
+// sendRedirected sends the request to the server identified by sessionID,
+// following the redirections.
+func (client *Client) sendRedirected(ctx context.Context, sessionID string, resp xrdproto.Response, req xrdproto.Request) (string, error) {
+	sessionID, session, err := client.sessionFor(ctx, sessionID)
+	if err != nil {
+		return sessionID, err
 	}
 
 	redirection, err := session.Send(ctx, resp, req)
@@ -143,9 +265,12 @@ func (client *Client) sendSession(ctx context.Context, sessionID string, resp xr
 
 	for cnt := client.maxRedirections; redirection != nil && cnt > 0; cnt-- {
 		sessionID = redirection.Addr
+		statsFrom(ctx).redirect(sessionID)
 		session, err = client.getSession(ctx, sessionID, redirection.Token)
 		if err != nil {
-			return sessionID, err
+			return sessionID, &transientError{
+				err: fmt.Errorf("xrootd: could not follow redirection to %q: %w", sessionID, err),
+			}
 		}
 		if fp, ok := req.(xrdproto.FilepathRequest); ok {
 			fp.SetOpaque(redirection.Opaque)
@@ -164,6 +289,52 @@ func (client *Client) sendSession(ctx context.Context, sessionID string, resp xr
 	return sessionID, err
 }
 
+// sessionFor returns the session to use for a request to the server identified by sessionID.
+// Lost connections to the initial server are re-established, while requests
+// to other servers whose connection was lost are sent to the initial server.
+// See http://xrootd.org/doc/dev45/XRdv310.pdf, p. 11 for details.
+func (client *Client) sessionFor(ctx context.Context, sessionID string) (string, *cliSession, error) {
+	client.mu.RLock()
+	session, ok := client.sessions[sessionID]
+	initial := client.initialSessionID
+	client.mu.RUnlock()
+	if ok {
+		return sessionID, session, nil
+	}
+	if initial == "" {
+		return sessionID, nil, fmt.Errorf("xrootd: session with id = %q was not found", sessionID)
+	}
+
+	session, err := client.getSession(ctx, initial, "")
+	if err != nil {
+		return initial, nil, &transientError{
+			err: fmt.Errorf("xrootd: could not reconnect to %q: %w", initial, err),
+		}
+	}
+	return initial, session, nil
+}
+
+// session returns the session identified by sessionID, or nil.
+func (client *Client) session(sessionID string) *cliSession {
+	client.mu.RLock()
+	defer client.mu.RUnlock()
+	return client.sessions[sessionID]
+}
+
+// dropSession removes the session from the client, after its connection was lost.
+func (client *Client) dropSession(session *cliSession) {
+	if client == nil {
+		return
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	for id, v := range client.sessions {
+		if v == session {
+			delete(client.sessions, id)
+		}
+	}
+}
+
 func (client *Client) getSession(ctx context.Context, address, token string) (*cliSession, error) {
 	client.mu.RLock()
 	v, ok := client.sessions[address]
@@ -173,6 +344,9 @@ func (client *Client) getSession(ctx context.Context, address, token string) (*c
 	}
 	client.mu.Lock()
 	defer client.mu.Unlock()
+	if v, ok := client.sessions[address]; ok {
+		return v, nil
+	}
 	session, err := newSession(ctx, address, client.username, token, client)
 	if err != nil {
 		return nil, err
@@ -187,3 +361,18 @@ func (client *Client) getSession(ctx context.Context, address, token string) (*c
 
 	return session, nil
 }
+
+// transientError is an error after which a request may be retried,
+// such as the loss of the connection to the server or a timeout.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// isTransient returns whether err is a transientError.
+func isTransient(err error) bool {
+	var terr *transientError
+	return errors.As(err, &terr)
+}
@@ -3,7 +3,7 @@
 // license that can be found in the LICENSE file.
 
 // Command xrd-cp copies files and directories from a remote xrootd server
-// to local storage.
+// to local storage, or files from a remote xrootd server to another one.
 //
 // Usage:
 //
@@ -17,9 +17,32 @@
 //  $> xrd-cp root://server.example.com/some/file1.txt - > foo.txt
 //  $> xrd-cp -r root://server.example.com/some/dir .
 //  $> xrd-cp -r root://server.example.com/some/dir outdir
+//  $> xrd-cp root://server.example.com/some/file1.txt root://other.example.com/dir/
+//  $> xrd-cp -tpc=only root://server.example.com/some/file1.txt root://other.example.com/file1.txt
+//
+//  $> xrd-cp -j=4 'root://server.example.com/some/dir/*.root' outdir
+//  $> xrd-cp -resume root://server.example.com/some/file1.txt foo.txt
+//
+// Remote source paths may contain the wildcards of path.Match, matched against
+// the files of the remote server.
+//
+// Files copied to a remote xrootd server are streamed through the local host,
+// unless a third-party copy (TPC) is requested with the -tpc option.
+// With a third-party copy, the data is directly transferred from the source
+// server to the destination server.
+//
+// With the -resume option, partially copied files are completed instead of
+// being copied again, and the checksums of the copied files are verified
+// against the ones computed by the source server.
 //
 // Options:
+//   -j int
+//     	number of parallel file transfers (default 1)
 //   -r	copy directories recursively
+//   -resume
+//     	resume partial copies and verify checksums after copy
+//   -tpc string
+//     	third-party copy mode for remote destinations (first, only)
 //   -v	enable verbose mode
 package main
 
@@ -27,19 +50,26 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"hash/adler32"
 	"io"
 	"log"
 	"os"
 	stdpath "path"
+	"sort"
+	"strings"
+	"sync/atomic"
 
 	"go-hep.org/x/hep/xrootd"
 	"go-hep.org/x/hep/xrootd/xrdfs"
 	"go-hep.org/x/hep/xrootd/xrdio"
+	"go-hep.org/x/hep/xrootd/xrdproto/query"
+	"golang.org/x/sync/errgroup"
 )
 
 func init() {
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, `xrd-cp copies files and directories from a remote xrootd server to local storage.
+		fmt.Fprintf(os.Stderr, `xrd-cp copies files and directories from a remote xrootd server to local storage,
+or to another remote xrootd server.
 
 Usage:
 
@@ -53,6 +83,22 @@ Example:
  $> xrd-cp root://server.example.com/some/file1.txt - > foo.txt
  $> xrd-cp -r root://server.example.com/some/dir .
  $> xrd-cp -r root://server.example.com/some/dir outdir
+ $> xrd-cp root://server.example.com/some/file1.txt root://other.example.com/dir/
+ $> xrd-cp -tpc=only root://server.example.com/some/file1.txt root://other.example.com/file1.txt
+ $> xrd-cp -j=4 'root://server.example.com/some/dir/*.root' outdir
+ $> xrd-cp -resume root://server.example.com/some/file1.txt foo.txt
+
+Remote source paths may contain the wildcards of path.Match, matched against
+the files of the remote server.
+
+Files copied to a remote xrootd server are streamed through the local host,
+unless a third-party copy (TPC) is requested with the -tpc option.
+With a third-party copy, the data is directly transferred from the source
+server to the destination server.
+
+With the -resume option, partially copied files are completed instead of
+being copied again, and the checksums of the copied files are verified
+against the ones computed by the source server.
 
 Options:
 `)
@@ -65,12 +111,35 @@ func main() {
 	log.SetFlags(0)
 
 	var (
+		jobsFlag    = flag.Int("j", 1, "number of parallel file transfers")
 		recFlag     = flag.Bool("r", false, "copy directories recursively")
+		resumeFlag  = flag.Bool("resume", false, "resume partial copies and verify checksums after copy")
+		tpcFlag     = flag.String("tpc", "", "third-party copy mode for remote destinations (first, only)")
 		verboseFlag = flag.Bool("v", false, "enable verbose mode")
 	)
 
 	flag.Parse()
 
+	switch *tpcFlag {
+	case "", "first", "only":
+	default:
+		flag.Usage()
+		log.Fatalf("invalid third-party copy mode %q", *tpcFlag)
+	}
+
+	if *jobsFlag < 1 {
+		flag.Usage()
+		log.Fatalf("invalid number of parallel file transfers %d", *jobsFlag)
+	}
+
+	opts := options{
+		recursive: *recFlag,
+		verbose:   *verboseFlag,
+		tpc:       *tpcFlag,
+		jobs:      *jobsFlag,
+		resume:    *resumeFlag,
+	}
+
 	switch n := flag.NArg(); n {
 	case 0:
 		flag.Usage()
@@ -79,14 +148,14 @@ func main() {
 		flag.Usage()
 		log.Fatalf("missing destination file operand after %q", flag.Arg(0))
 	case 2:
-		err := xrdcopy(flag.Arg(1), flag.Arg(0), *recFlag, *verboseFlag)
+		err := xrdcopy(flag.Arg(1), flag.Arg(0), opts)
 		if err != nil {
 			log.Fatalf("could not copy %q to %q: %v", flag.Arg(0), flag.Arg(1), err)
 		}
 	default:
 		dst := flag.Arg(flag.NArg() - 1)
 		for _, src := range flag.Args()[:flag.NArg()-1] {
-			err := xrdcopy(dst, src, *recFlag, *verboseFlag)
+			err := xrdcopy(dst, src, opts)
 			if err != nil {
 				log.Fatalf("could not copy %q to %q: %v", src, dst, err)
 			}
@@ -94,8 +163,17 @@ func main() {
 	}
 }
 
-func xrdcopy(dst, srcPath string, recursive, verbose bool) error {
-	cli, src, err := xrdremote(srcPath)
+// options configures a copy.
+type options struct {
+	recursive bool   // copy directories recursively
+	verbose   bool   // enable verbose mode
+	tpc       string // third-party copy mode for remote destinations ("", "first" or "only")
+	jobs      int    // number of parallel file transfers
+	resume    bool   // resume partial copies and verify checksums after copy
+}
+
+func xrdcopy(dst, srcPath string, opts options) error {
+	cli, pattern, err := xrdremote(srcPath)
 	if err != nil {
 		return err
 	}
@@ -103,8 +181,25 @@ func xrdcopy(dst, srcPath string, recursive, verbose bool) error {
 
 	ctx := context.Background()
 
+	c := copier{src: cli, opts: opts}
+	if isRemote(dst) {
+		c.dst, dst, err = xrdremote(dst)
+		if err != nil {
+			return err
+		}
+		defer c.dst.Close()
+	}
+
 	fs := cli.FS()
-	var jobs jobs
+	srcs, err := glob(ctx, fs, pattern)
+	if err != nil {
+		return fmt.Errorf("could not expand %q: %w", pattern, err)
+	}
+	if len(srcs) == 0 {
+		return fmt.Errorf("no remote file matches %q", pattern)
+	}
+
+	var jobs []job
 	var addDir func(root, src string) error
 
 	addDir = func(root, src string) error {
@@ -114,11 +209,11 @@ func xrdcopy(dst, srcPath string, recursive, verbose bool) error {
 		}
 		switch {
 		case fi.IsDir():
-			if !recursive {
+			if !opts.recursive {
 				return fmt.Errorf("xrd-cp: -r not specified; omitting directory %q", src)
 			}
 			dst := stdpath.Join(root, stdpath.Base(src))
-			err = os.MkdirAll(dst, 0755)
+			err = c.mkdirAll(ctx, dst)
 			if err != nil {
 				return fmt.Errorf("could not create output directory: %w", err)
 			}
@@ -134,8 +229,7 @@ func xrdcopy(dst, srcPath string, recursive, verbose bool) error {
 				}
 			}
 		default:
-			jobs.add(job{
-				fs:  fs,
+			jobs = append(jobs, job{
 				src: src,
 				dst: stdpath.Join(root, stdpath.Base(src)),
 			})
@@ -143,64 +237,78 @@ func xrdcopy(dst, srcPath string, recursive, verbose bool) error {
 		return nil
 	}
 
-	fiSrc, err := fs.Stat(ctx, src)
+	exists, isDir, err := c.stat(ctx, dst)
 	if err != nil {
-		return fmt.Errorf("could not stat remote src: %w", err)
+		return err
+	}
+	if !exists && strings.HasSuffix(dst, "/") {
+		err = c.mkdirAll(ctx, dst)
+		if err != nil {
+			return fmt.Errorf("could not create output directory: %w", err)
+		}
+		exists, isDir = true, true
+	}
+	if len(srcs) > 1 && !isDir {
+		return fmt.Errorf("target %q is not a directory", dst)
 	}
 
-	fiDst, errDst := os.Stat(dst)
-	switch {
-	case fiSrc.IsDir():
+	for _, src := range srcs {
+		fiSrc, err := fs.Stat(ctx, src)
+		if err != nil {
+			return fmt.Errorf("could not stat remote src: %w", err)
+		}
+
 		switch {
-		case errDst != nil && os.IsNotExist(errDst):
-			err = os.MkdirAll(dst, 0755)
-			if err != nil {
-				return fmt.Errorf("could not create output directory: %w", err)
-			}
-			ents, err := fs.Dirlist(ctx, src)
-			if err != nil {
-				return fmt.Errorf("could not list directory: %w", err)
-			}
-			for _, e := range ents {
-				err = addDir(dst, stdpath.Join(src, e.Name()))
+		case fiSrc.IsDir():
+			switch {
+			case !exists:
+				err = c.mkdirAll(ctx, dst)
+				if err != nil {
+					return fmt.Errorf("could not create output directory: %w", err)
+				}
+				ents, err := fs.Dirlist(ctx, src)
+				if err != nil {
+					return fmt.Errorf("could not list directory: %w", err)
+				}
+				for _, e := range ents {
+					err = addDir(dst, stdpath.Join(src, e.Name()))
+					if err != nil {
+						return err
+					}
+				}
+			case isDir:
+				err = addDir(dst, src)
 				if err != nil {
 					return err
 				}
+			default:
+				return fmt.Errorf("cannot overwrite non-directory %q with directory %q", dst, src)
 			}
 
-		case errDst != nil:
-			return fmt.Errorf("could not stat local dst: %w", errDst)
-		case fiDst.IsDir():
-			err = addDir(dst, src)
-			if err != nil {
-				return err
+		default:
+			name := dst
+			if isDir {
+				name = stdpath.Join(dst, stdpath.Base(src))
 			}
+			jobs = append(jobs, job{
+				src: src,
+				dst: name,
+			})
 		}
-
-	default:
-		switch {
-		case errDst != nil && os.IsNotExist(errDst):
-			// ok... dst will be the output file.
-		case errDst != nil:
-			return fmt.Errorf("could not stat local dst: %w", errDst)
-		case fiDst.IsDir():
-			dst = stdpath.Join(dst, stdpath.Base(src))
-		}
-
-		jobs.add(job{
-			fs:  fs,
-			src: src,
-			dst: dst,
-		})
 	}
 
-	n, err := jobs.run(ctx)
-	if verbose {
+	n, err := c.run(ctx, jobs)
+	if opts.verbose {
 		log.Printf("transferred %d bytes", n)
 	}
 	return err
 }
 
+// isRemote returns whether name is the URL of a file on a remote xrootd server.
+func isRemote(name string) bool {
+	return strings.HasPrefix(name, "root://") || strings.HasPrefix(name, "xroot://")
+}
+
 func xrdremote(name string) (client *xrootd.Client, path string, err error) {
 	url, err := xrdio.Parse(name)
 	if err != nil {
@@ -212,68 +320,388 @@ func xrdremote(name string) (client *xrootd.Client, path string, err error) {
 	return client, path, err
 }
 
+// glob returns the names of the remote files matching pattern, with the syntax
+// of path.Match, or pattern if it does not contain any wildcard.
+func glob(ctx context.Context, fs xrdfs.FileSystem, pattern string) ([]string, error) {
+	if !hasMeta(pattern) {
+		return []string{pattern}, nil
+	}
+	if _, err := stdpath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	dir, file := stdpath.Split(pattern)
+	if dir != "/" {
+		dir = strings.TrimSuffix(dir, "/")
+	}
+
+	dirs := []string{dir}
+	if hasMeta(dir) {
+		var err error
+		dirs, err = glob(ctx, fs, dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []string
+	for _, dir := range dirs {
+		ents, err := fs.Dirlist(ctx, dir)
+		if err != nil {
+			if len(dirs) > 1 {
+				// dir may be a file matching a wildcard.
+				continue
+			}
+			return nil, fmt.Errorf("could not list directory: %w", err)
+		}
+		for _, e := range ents {
+			if ok, _ := stdpath.Match(file, e.Name()); ok {
+				matches = append(matches, stdpath.Join(dir, e.Name()))
+			}
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// hasMeta returns whether path contains any of the wildcards of path.Match.
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[\`)
+}
+
 type job struct {
-	fs  xrdfs.FileSystem
 	src string
 	dst string
 }
 
-func (j job) run(ctx context.Context) (int, error) {
+// copier copies files from a remote xrootd server to local storage, or to
+// another remote xrootd server.
+type copier struct {
+	src  *xrootd.Client
+	dst  *xrootd.Client // dst is the client to the remote destination, or nil for local storage.
+	opts options
+}
+
+// run runs the jobs in parallel, and returns the number of transferred bytes.
+func (c *copier) run(ctx context.Context, jobs []job) (int64, error) {
 	var (
-		o   io.WriteCloser
-		err error
+		n   int64
+		ch  = make(chan job)
+		grp *errgroup.Group
 	)
-	switch j.dst {
-	case "-", "":
-		o = os.Stdout
-	case ".":
-		j.dst = stdpath.Base(j.src)
-		fallthrough
-	default:
-		o, err = os.Create(j.dst)
-		if err != nil {
-			return 0, fmt.Errorf("could not create output file: %w", err)
+	grp, ctx = errgroup.WithContext(ctx)
+
+	grp.Go(func() error {
+		defer close(ch)
+		for _, j := range jobs {
+			select {
+			case ch <- j:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	njobs := c.opts.jobs
+	if njobs < 1 {
+		njobs = 1
+	}
+	for i := 0; i < njobs; i++ {
+		grp.Go(func() error {
+			for j := range ch {
+				nn, err := c.copy(ctx, j)
+				atomic.AddInt64(&n, nn)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	err := grp.Wait()
+	return atomic.LoadInt64(&n), err
+}
+
+// copy runs a job, and returns the number of transferred bytes.
+func (c *copier) copy(ctx context.Context, j job) (int64, error) {
+	if c.dst != nil && c.opts.tpc != "" {
+		n, err := c.tpc(ctx, j)
+		switch {
+		case err == nil:
+			return n, nil
+		case c.opts.tpc == "only":
+			return n, fmt.Errorf("could not run third-party copy: %w", err)
+		}
+		if c.opts.verbose {
+			log.Printf("could not run third-party copy of %q, streaming data instead: %v", j.src, err)
 		}
 	}
-	defer o.Close()
 
-	f, err := xrdio.OpenFrom(j.fs, j.src)
+	f, err := xrdio.OpenFrom(c.src.FS(), j.src)
 	if err != nil {
 		return 0, err
 	}
 	defer f.Close()
 
-	// TODO(sbinet): make buffer a field of job to reduce memory pressure.
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("could not stat remote src: %w", err)
+	}
+
+	o, off, err := c.create(ctx, &j, fi.Size())
+	if err != nil {
+		return 0, fmt.Errorf("could not create output file: %w", err)
+	}
+	defer o.Close()
+
+	if off > 0 {
+		if c.opts.verbose {
+			log.Printf("resuming copy of %q at byte %d", j.src, off)
+		}
+		_, err = f.Seek(off, io.SeekStart)
+		if err != nil {
+			return 0, fmt.Errorf("could not seek remote src: %w", err)
+		}
+	}
+
 	// TODO(sbinet): use clever heuristics for buffer size?
 	n, err := io.CopyBuffer(o, f, make([]byte, 16*1024*1024))
 	if err != nil {
-		return int(n), fmt.Errorf("could not copy to output file: %w", err)
+		return n, fmt.Errorf("could not copy to output file: %w", err)
 	}
 
 	err = o.Close()
 	if err != nil {
-		return int(n), fmt.Errorf("could not close output file: %w", err)
+		return n, fmt.Errorf("could not close output file: %w", err)
 	}
 
-	return int(n), nil
-}
+	if c.opts.resume && j.dst != "-" {
+		err = c.verify(ctx, j)
+		if err != nil {
+			return n, err
+		}
+	}
 
-type jobs struct {
-	slice []job
+	return n, nil
 }
 
-func (js *jobs) add(j job) {
-	js.slice = append(js.slice, j)
+// tpc runs a job with a third-party copy.
+func (c *copier) tpc(ctx context.Context, j job) (int64, error) {
+	var progress func(n int64)
+	if c.opts.verbose {
+		progress = func(n int64) {
+			log.Printf("%s: transferred %d bytes", j.dst, n)
+		}
+	}
+
+	err := xrootd.ThirdPartyCopy(ctx, c.dst, j.dst, c.src, j.src, progress)
+	if err != nil {
+		return 0, err
+	}
+
+	fi, err := c.dst.FS().Stat(ctx, j.dst)
+	if err != nil {
+		return 0, fmt.Errorf("could not stat remote dst: %w", err)
+	}
+
+	if c.opts.resume {
+		err = c.verify(ctx, j)
+		if err != nil {
+			return fi.EntrySize, err
+		}
+	}
+
+	return fi.EntrySize, nil
 }
 
-func (js *jobs) run(ctx context.Context) (int, error) {
-	var n int
-	for _, j := range js.slice {
-		nn, err := j.run(ctx)
-		n += nn
+// create creates the output file of the job, whose source has the provided size.
+// In resume mode, the already copied part of an existing output file is kept,
+// and create returns the offset at which the copy should resume.
+func (c *copier) create(ctx context.Context, j *job, size int64) (io.WriteCloser, int64, error) {
+	switch {
+	case c.dst != nil:
+		var (
+			off     int64
+			options = xrdfs.OpenOptionsOpenUpdate | xrdfs.OpenOptionsDelete | xrdfs.OpenOptionsMkPath
+		)
+		if c.opts.resume {
+			fi, err := c.dst.FS().Stat(ctx, j.dst)
+			if err == nil && fi.EntrySize <= size {
+				off = fi.EntrySize
+				options = xrdfs.OpenOptionsOpenUpdate
+			}
+		}
+		f, err := c.dst.FS().Open(ctx, j.dst,
+			xrdfs.OpenModeOwnerRead|xrdfs.OpenModeOwnerWrite|xrdfs.OpenModeGroupRead|xrdfs.OpenModeOtherRead,
+			options,
+		)
 		if err != nil {
-			return n, err
+			return nil, 0, err
+		}
+		return &remoteWriter{ctx: ctx, f: f, off: off}, off, nil
+
+	case j.dst == "-" || j.dst == "":
+		j.dst = "-"
+		return os.Stdout, 0, nil
+
+	case j.dst == ".":
+		j.dst = stdpath.Base(j.src)
+	}
+
+	if !c.opts.resume {
+		f, err := os.Create(j.dst)
+		return f, 0, err
+	}
+
+	f, err := os.OpenFile(j.dst, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	off := fi.Size()
+	if off > size {
+		// not a partial copy of the source.
+		off = 0
+		err = f.Truncate(0)
+		if err != nil {
+			f.Close()
+			return nil, 0, err
 		}
 	}
-	return n, nil
+	_, err = f.Seek(off, io.SeekStart)
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, off, nil
+}
+
+// verify verifies the checksum of the output file of the job against the one
+// of its source.
+func (c *copier) verify(ctx context.Context, j job) error {
+	want, err := checksum(ctx, c.src, j.src)
+	if err != nil {
+		return fmt.Errorf("could not retrieve checksum of %q: %w", j.src, err)
+	}
+
+	var got string
+	switch c.dst {
+	case nil:
+		got, err = localChecksum(j.dst)
+	default:
+		got, err = checksum(ctx, c.dst, j.dst)
+	}
+	if err != nil {
+		return fmt.Errorf("could not compute checksum of %q: %w", j.dst, err)
+	}
+
+	if got != want {
+		return fmt.Errorf("checksum mismatch for %q (got=%s, want=%s)", j.dst, got, want)
+	}
+
+	if c.opts.verbose {
+		log.Printf("verified checksum of %q: %s", j.dst, got)
+	}
+	return nil
+}
+
+// checksum returns the adler32 checksum of the remote file name, as computed by the server.
+func checksum(ctx context.Context, cli *xrootd.Client, name string) (string, error) {
+	var resp query.Response
+	_, err := cli.Send(ctx, &resp, &query.Request{
+		Query: query.Checksum,
+		Args:  []byte(name),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// the response is of the form "<type> <value>".
+	typ, sum, ok := strings.Cut(strings.TrimRight(string(resp.Data), "\x00\n "), " ")
+	switch {
+	case !ok:
+		return "", fmt.Errorf("invalid checksum response %q", resp.Data)
+	case typ != "adler32":
+		return "", fmt.Errorf("unsupported checksum type %q", typ)
+	}
+	return "adler32:" + strings.ToLower(sum), nil
+}
+
+// localChecksum returns the adler32 checksum of the local file name.
+func localChecksum(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := adler32.New()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("adler32:%08x", hash.Sum32()), nil
+}
+
+// stat returns whether name exists and is a directory, on the destination.
+func (c *copier) stat(ctx context.Context, name string) (exists, isDir bool, err error) {
+	if c.dst == nil {
+		fi, err := os.Stat(name)
+		switch {
+		case os.IsNotExist(err):
+			return false, false, nil
+		case err != nil:
+			return false, false, fmt.Errorf("could not stat local dst: %w", err)
+		}
+		return true, fi.IsDir(), nil
+	}
+
+	fi, err := c.dst.FS().Stat(ctx, name)
+	if err != nil {
+		// servers do not consistently report missing files.
+		return false, false, nil
+	}
+	return true, fi.IsDir(), nil
+}
+
+// mkdirAll creates the directory name, along with any necessary parents, on the destination.
+func (c *copier) mkdirAll(ctx context.Context, name string) error {
+	if c.dst == nil {
+		return os.MkdirAll(name, 0755)
+	}
+	const perm = xrdfs.OpenModeOwnerRead | xrdfs.OpenModeOwnerWrite | xrdfs.OpenModeOwnerExecute |
+		xrdfs.OpenModeGroupRead | xrdfs.OpenModeGroupExecute |
+		xrdfs.OpenModeOtherRead | xrdfs.OpenModeOtherExecute
+	return c.dst.FS().MkdirAll(ctx, name, perm)
+}
+
+// remoteWriter writes sequentially to a remote file.
+type remoteWriter struct {
+	ctx context.Context
+	f   xrdfs.File
+	off int64
+}
+
+func (w *remoteWriter) Write(p []byte) (int, error) {
+	err := w.f.WriteAtContext(w.ctx, p, w.off)
+	if err != nil {
+		return 0, err
+	}
+	w.off += int64(len(p))
+	return len(p), nil
+}
+
+func (w *remoteWriter) Close() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.f.Close(w.ctx)
+	w.f = nil
+	return err
 }
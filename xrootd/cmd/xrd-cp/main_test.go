@@ -5,9 +5,16 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"go-hep.org/x/hep/xrootd"
 )
 
 func TestXrdCp(t *testing.T) {
@@ -25,12 +32,244 @@ func TestXrdCp(t *testing.T) {
 		verbose   = true
 	)
 
-	err = xrdcopy(dst, src, recursive, verbose)
+	err = xrdcopy(dst, src, options{recursive: recursive, verbose: verbose})
 	if err != nil {
 		t.Fatalf("could not copy remote file: %v", err)
 	}
 }
 
+// serve serves dir with a local xrootd server, and returns the URL of the server.
+func serve(t *testing.T, dir string) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	srv := xrootd.NewServer(xrootd.NewFSHandler(dir), func(err error) {
+		t.Logf("server error: %v", err)
+	})
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+	return "root://" + l.Addr().String()
+}
+
+func TestXrdCpRemote(t *testing.T) {
+	var (
+		srcDir = t.TempDir()
+		dstDir = t.TempDir()
+		srcURL = serve(t, srcDir)
+		dstURL = serve(t, dstDir)
+		want   = []byte("hello from a remote xrootd server\n")
+	)
+
+	err := os.WriteFile(filepath.Join(srcDir, "file.txt"), want, 0644)
+	if err != nil {
+		t.Fatalf("could not create source file: %v", err)
+	}
+
+	for _, tc := range []struct {
+		tpc string
+		dst string
+		err bool
+	}{
+		{tpc: "", dst: "/file1.txt"},
+		{tpc: "", dst: "/"},
+		{tpc: "first", dst: "/sub/file2.txt"},
+		// the Go server does not support third-party copies.
+		{tpc: "only", dst: "/file3.txt", err: true},
+	} {
+		t.Run(tc.tpc+tc.dst, func(t *testing.T) {
+			err := xrdcopy(dstURL+"/"+tc.dst, srcURL+"//file.txt", options{tpc: tc.tpc})
+			switch {
+			case err != nil && !tc.err:
+				t.Fatalf("could not copy remote file: %v", err)
+			case err == nil && tc.err:
+				t.Fatalf("expected an error")
+			case tc.err:
+				return
+			}
+
+			name := tc.dst
+			if name == "/" {
+				name = "/file.txt"
+			}
+			got, err := os.ReadFile(filepath.Join(dstDir, name))
+			if err != nil {
+				t.Fatalf("could not read copied file: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("invalid copied file:\ngot= %q\nwant=%q", got, want)
+			}
+		})
+	}
+}
+
+func TestXrdCpGlob(t *testing.T) {
+	srcDir := t.TempDir()
+	srcURL := serve(t, srcDir)
+
+	files := map[string]string{
+		"a.txt":         "a",
+		"b.txt":         "bb",
+		"c.dat":         "ccc",
+		"sub/d.txt":     "dddd",
+		"sub/sub/e.txt": "eeeee",
+	}
+	for name, data := range files {
+		fname := filepath.Join(srcDir, name)
+		err := os.MkdirAll(filepath.Dir(fname), 0755)
+		if err != nil {
+			t.Fatalf("could not create source dir: %v", err)
+		}
+		err = os.WriteFile(fname, []byte(data), 0644)
+		if err != nil {
+			t.Fatalf("could not create source file: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		src    string
+		remote bool
+		opts   options
+		want   map[string]string
+	}{
+		{
+			name: "glob",
+			src:  "/*.txt",
+			opts: options{jobs: 2},
+			want: map[string]string{"a.txt": "a", "b.txt": "bb"},
+		},
+		{
+			name: "glob-dirs",
+			src:  "/s*/*.txt",
+			opts: options{jobs: 2},
+			want: map[string]string{"d.txt": "dddd"},
+		},
+		{
+			name: "recursive",
+			src:  "/sub",
+			opts: options{recursive: true, jobs: 4},
+			want: map[string]string{"sub/d.txt": "dddd", "sub/sub/e.txt": "eeeee"},
+		},
+		{
+			name:   "recursive-remote",
+			src:    "/sub",
+			remote: true,
+			opts:   options{recursive: true, jobs: 4},
+			want:   map[string]string{"sub/d.txt": "dddd", "sub/sub/e.txt": "eeeee"},
+		},
+		{
+			name:   "glob-remote-verify",
+			src:    "/[ab].txt",
+			remote: true,
+			opts:   options{jobs: 4, resume: true},
+			want:   map[string]string{"a.txt": "a", "b.txt": "bb"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dstDir := t.TempDir()
+			dst := dstDir
+			if tc.remote {
+				dst = serve(t, dstDir) + "//"
+			}
+
+			err := xrdcopy(dst, srcURL+"/"+tc.src, tc.opts)
+			if err != nil {
+				t.Fatalf("could not copy remote files: %v", err)
+			}
+
+			got := make(map[string]string)
+			err = filepath.Walk(dstDir, func(path string, fi os.FileInfo, err error) error {
+				if err != nil || fi.IsDir() {
+					return err
+				}
+				name, err := filepath.Rel(dstDir, path)
+				if err != nil {
+					return err
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				got[filepath.ToSlash(name)] = string(data)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("could not walk output dir: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("invalid copied files:\ngot= %q\nwant=%q", got, tc.want)
+			}
+		})
+	}
+
+	err := xrdcopy(t.TempDir(), srcURL+"//*.none", options{})
+	if err == nil {
+		t.Fatalf("expected an error for a pattern without match")
+	}
+
+	err = xrdcopy(filepath.Join(t.TempDir(), "file.txt"), srcURL+"//*.txt", options{})
+	if err == nil {
+		t.Fatalf("expected an error for many files copied to a non-directory")
+	}
+}
+
+func TestXrdCpResume(t *testing.T) {
+	srcDir := t.TempDir()
+	srcURL := serve(t, srcDir)
+
+	want := bytes.Repeat([]byte("0123456789"), 1000)
+	err := os.WriteFile(filepath.Join(srcDir, "file.txt"), want, 0644)
+	if err != nil {
+		t.Fatalf("could not create source file: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		partial []byte
+		err     bool
+	}{
+		{name: "missing"},
+		{name: "partial", partial: want[:1234]},
+		{name: "complete", partial: want},
+		{name: "larger", partial: append(want[:len(want):len(want)], "extra"...)},
+		{name: "corrupted", partial: []byte("not the start of the file"), err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "file.txt")
+			if tc.partial != nil {
+				err := os.WriteFile(dst, tc.partial, 0644)
+				if err != nil {
+					t.Fatalf("could not create partial file: %v", err)
+				}
+			}
+
+			err := xrdcopy(dst, srcURL+"//file.txt", options{resume: true})
+			switch {
+			case err != nil && !tc.err:
+				t.Fatalf("could not copy remote file: %v", err)
+			case err == nil && tc.err:
+				t.Fatalf("expected a checksum error")
+			case tc.err:
+				if !strings.Contains(err.Error(), "checksum mismatch") {
+					t.Fatalf("invalid error: %v", err)
+				}
+				return
+			}
+
+			got, err := os.ReadFile(dst)
+			if err != nil {
+				t.Fatalf("could not read copied file: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("invalid copied file")
+			}
+		})
+	}
+}
+
 func BenchmarkXrdCp_Small(b *testing.B) {
 	benchmarkXrdCp(b, "root://ccxrootdgotest.in2p3.fr:9001/tmp/rootio/testdata/chain.1.root")
 }
@@ -60,7 +299,7 @@ func benchmarkXrdCp(b *testing.B, src string) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		os.RemoveAll(dst)
-		err = xrdcopy(dst, src, recursive, verbose)
+		err = xrdcopy(dst, src, options{recursive: recursive, verbose: verbose})
 		if err != nil {
 			b.Fatalf("could not copy remote file: %v", err)
 		}
@@ -0,0 +1,122 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+
+// Command xrd-fuse mounts a remote xrootd directory as a local read-only
+// filesystem.
+//
+// Usage:
+//
+//	$> xrd-fuse [OPTIONS] <remote-dir> <mount-point>
+//
+// Example:
+//
+//	$> xrd-fuse root://server.example.com/some/dir /mnt/xrootd
+//	$> ls -l /mnt/xrootd
+//	$> fusermount -u /mnt/xrootd
+//
+// Options:
+//
+//	-debug	enable FUSE debug output
+//
+// The filesystem is unmounted when xrd-fuse receives SIGINT or SIGTERM.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"go-hep.org/x/hep/xrootd"
+	"go-hep.org/x/hep/xrootd/xrdfuse"
+	"go-hep.org/x/hep/xrootd/xrdio"
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, `xrd-fuse mounts a remote xrootd directory as a local read-only filesystem.
+
+Usage:
+
+ $> xrd-fuse [OPTIONS] <remote-dir> <mount-point>
+
+Example:
+
+ $> xrd-fuse root://server.example.com/some/dir /mnt/xrootd
+ $> ls -l /mnt/xrootd
+ $> fusermount -u /mnt/xrootd
+
+Options:
+`)
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	log.SetPrefix("xrd-fuse: ")
+	log.SetFlags(0)
+
+	debug := flag.Bool("debug", false, "enable FUSE debug output")
+
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		log.Fatalf("missing remote directory and/or mount point operands")
+	}
+
+	err := mount(flag.Arg(0), flag.Arg(1), *debug)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+}
+
+func mount(name, dir string, debug bool) error {
+	url, err := xrdio.Parse(name)
+	if err != nil {
+		return fmt.Errorf("could not parse %q: %w", name, err)
+	}
+
+	ctx := context.Background()
+
+	c, err := xrootd.NewClient(ctx, url.Addr, url.User)
+	if err != nil {
+		return fmt.Errorf("could not create client: %w", err)
+	}
+	defer c.Close()
+
+	fi, err := c.FS().Stat(ctx, url.Path)
+	if err != nil {
+		return fmt.Errorf("could not stat %q: %w", url.Path, err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%q is not a directory", url.Path)
+	}
+
+	var opts fs.Options
+	opts.Debug = debug
+	srv, err := xrdfuse.Mount(dir, c.FS(), url.Path, &opts)
+	if err != nil {
+		return fmt.Errorf("could not mount %q on %q: %w", name, dir, err)
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigc
+		err := srv.Unmount()
+		if err != nil {
+			log.Printf("could not unmount %q: %+v", dir, err)
+		}
+	}()
+
+	srv.Wait()
+	return nil
+}
@@ -6,6 +6,7 @@ package xrootd // import "go-hep.org/x/hep/xrootd"
 
 import (
 	"go-hep.org/x/hep/xrootd/xrdproto"
+	"go-hep.org/x/hep/xrootd/xrdproto/chmod"
 	"go-hep.org/x/hep/xrootd/xrdproto/dirlist"
 	"go-hep.org/x/hep/xrootd/xrdproto/handshake"
 	"go-hep.org/x/hep/xrootd/xrdproto/login"
@@ -14,12 +15,15 @@ import (
 	"go-hep.org/x/hep/xrootd/xrdproto/open"
 	"go-hep.org/x/hep/xrootd/xrdproto/ping"
 	"go-hep.org/x/hep/xrootd/xrdproto/protocol"
+	"go-hep.org/x/hep/xrootd/xrdproto/query"
 	"go-hep.org/x/hep/xrootd/xrdproto/read"
+	"go-hep.org/x/hep/xrootd/xrdproto/readv"
 	"go-hep.org/x/hep/xrootd/xrdproto/rm"
 	"go-hep.org/x/hep/xrootd/xrdproto/rmdir"
 	"go-hep.org/x/hep/xrootd/xrdproto/stat"
 	"go-hep.org/x/hep/xrootd/xrdproto/sync"
 	"go-hep.org/x/hep/xrootd/xrdproto/truncate"
+	"go-hep.org/x/hep/xrootd/xrdproto/verifyw"
 	"go-hep.org/x/hep/xrootd/xrdproto/write"
 	"go-hep.org/x/hep/xrootd/xrdproto/xrdclose"
 )
@@ -84,12 +88,24 @@ func (h *defaultHandler) Read(sessionID [16]byte, request *read.Request) (xrdpro
 	return resp, xrdproto.Error
 }
 
+// ReadV implements Handler.ReadV.
+func (h *defaultHandler) ReadV(sessionID [16]byte, request *readv.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	resp := xrdproto.ServerError{Code: xrdproto.InvalidRequest, Message: "ReadV request is not implemented"}
+	return resp, xrdproto.Error
+}
+
 // Write implements Handler.Write.
 func (h *defaultHandler) Write(sessionID [16]byte, request *write.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
 	resp := xrdproto.ServerError{Code: xrdproto.InvalidRequest, Message: "Write request is not implemented"}
 	return resp, xrdproto.Error
 }
 
+// VerifyWrite implements Handler.VerifyWrite.
+func (h *defaultHandler) VerifyWrite(sessionID [16]byte, request *verifyw.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	resp := xrdproto.ServerError{Code: xrdproto.InvalidRequest, Message: "VerifyWrite request is not implemented"}
+	return resp, xrdproto.Error
+}
+
 // Stat implements Handler.Stat.
 func (h *defaultHandler) Stat(sessionID [16]byte, request *stat.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
 	resp := xrdproto.ServerError{Code: xrdproto.InvalidRequest, Message: "Stat request is not implemented"}
@@ -131,3 +147,15 @@ func (h *defaultHandler) RemoveDir(sessionID [16]byte, request *rmdir.Request) (
 	resp := xrdproto.ServerError{Code: xrdproto.InvalidRequest, Message: "RemoveDir request is not implemented"}
 	return resp, xrdproto.Error
 }
+
+// Chmod implements Handler.Chmod.
+func (h *defaultHandler) Chmod(sessionID [16]byte, request *chmod.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	resp := xrdproto.ServerError{Code: xrdproto.InvalidRequest, Message: "Chmod request is not implemented"}
+	return resp, xrdproto.Error
+}
+
+// Query implements Handler.Query.
+func (h *defaultHandler) Query(sessionID [16]byte, request *query.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	resp := xrdproto.ServerError{Code: xrdproto.InvalidRequest, Message: "Query request is not implemented"}
+	return resp, xrdproto.Error
+}
@@ -6,10 +6,14 @@ package xrootd // import "go-hep.org/x/hep/xrootd"
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	rsync "sync"
 
 	"go-hep.org/x/hep/xrootd/xrdfs"
+	"go-hep.org/x/hep/xrootd/xrdproto/open"
 	"go-hep.org/x/hep/xrootd/xrdproto/read"
+	"go-hep.org/x/hep/xrootd/xrdproto/readv"
 	"go-hep.org/x/hep/xrootd/xrdproto/stat"
 	"go-hep.org/x/hep/xrootd/xrdproto/sync"
 	"go-hep.org/x/hep/xrootd/xrdproto/truncate"
@@ -24,9 +28,16 @@ type file struct {
 	handle      xrdfs.FileHandle
 	compression *xrdfs.FileCompression
 
+	// path, mode and options are the parameters the file was opened with,
+	// to reopen it after the loss of the connection to the server.
+	path    string
+	mode    xrdfs.OpenMode
+	options xrdfs.OpenOptions
+
 	mu        rsync.RWMutex
 	info      *xrdfs.EntryStat
 	sessionID string
+	sess      *cliSession // session the file was opened on, if known.
 }
 
 // Compression returns the compression info.
@@ -42,13 +53,15 @@ func (f *file) Info() *xrdfs.EntryStat {
 
 // Handle returns the file handle.
 func (f *file) Handle() xrdfs.FileHandle {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
 	return f.handle
 }
 
 // Close closes the file.
 func (f *file) Close(ctx context.Context) error {
 	return f.do(ctx, func(ctx context.Context, sid string) (string, error) {
-		return f.fs.c.sendSession(ctx, sid, nil, &xrdclose.Request{Handle: f.handle})
+		return f.fs.c.sendSession(ctx, sid, nil, &xrdclose.Request{Handle: f.Handle()})
 	})
 }
 
@@ -56,22 +69,38 @@ func (f *file) Close(ctx context.Context) error {
 // A zero size suppresses the verification.
 func (f *file) CloseVerify(ctx context.Context, size int64) error {
 	return f.do(ctx, func(ctx context.Context, sid string) (string, error) {
-		return f.fs.c.sendSession(ctx, sid, nil, &xrdclose.Request{Handle: f.handle, Size: size})
+		return f.fs.c.sendSession(ctx, sid, nil, &xrdclose.Request{Handle: f.Handle(), Size: size})
 	})
 }
 
 // Sync commits all pending writes to an open file.
 func (f *file) Sync(ctx context.Context) error {
 	return f.do(ctx, func(ctx context.Context, sid string) (string, error) {
-		return f.fs.c.sendSession(ctx, sid, nil, &sync.Request{Handle: f.handle})
+		return f.fs.c.sendSession(ctx, sid, nil, &sync.Request{Handle: f.Handle()})
 	})
 }
 
 // ReadAtContext reads len(p) bytes into p starting at offset off.
+// Large reads are striped across the parallel data streams of the client.
 func (f *file) ReadAtContext(ctx context.Context, p []byte, off int64) (n int, err error) {
+	ns, err := f.stripe(ctx, p, off, f.readAt)
+	if err != nil {
+		return 0, err
+	}
+	for i, v := range ns {
+		n += v
+		if v < f.chunkLen(p, i) {
+			// short read: the following chunks are past the end of the file.
+			break
+		}
+	}
+	return n, nil
+}
+
+func (f *file) readAt(ctx context.Context, p []byte, off int64) (int, error) {
 	resp := read.Response{Data: p}
-	req := &read.Request{Handle: f.handle, Offset: off, Length: int32(len(p))}
-	err = f.do(ctx, func(ctx context.Context, sid string) (string, error) {
+	err := f.do(ctx, func(ctx context.Context, sid string) (string, error) {
+		req := &read.Request{Handle: f.Handle(), Offset: off, Length: int32(len(p))}
 		return f.fs.c.sendSession(ctx, sid, &resp, req)
 	})
 	if err != nil {
@@ -80,16 +109,156 @@ func (f *file) ReadAtContext(ctx context.Context, p []byte, off int64) (n int, e
 	return len(resp.Data), nil
 }
 
+// stripe calls fct concurrently on the consecutive chunks of p, with up to
+// one call in flight per parallel data stream of the client, plus one for the
+// connection of the session.
+// stripe returns the number of bytes processed for each chunk.
+func (f *file) stripe(ctx context.Context, p []byte, off int64, fct func(ctx context.Context, p []byte, off int64) (int, error)) ([]int, error) {
+	chunk := f.fs.c.stripeSize
+	if chunk <= 0 || len(p) <= chunk {
+		n, err := fct(ctx, p, off)
+		return []int{n}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		ns   = make([]int, (len(p)+chunk-1)/chunk)
+		errs = make(chan error, len(ns))
+		sem  = make(chan struct{}, f.fs.c.streams+1)
+	)
+	for i := range ns {
+		beg := i * chunk
+		end := beg + f.chunkLen(p, i)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem }()
+			var err error
+			ns[i], err = fct(ctx, p[beg:end:end], off+int64(beg))
+			if err != nil {
+				cancel()
+			}
+			errs <- err
+		}(i)
+	}
+
+	var err error
+	for range ns {
+		if e := <-errs; e != nil && (err == nil || errors.Is(err, context.Canceled)) {
+			err = e
+		}
+	}
+	return ns, err
+}
+
+// chunkLen returns the length of the i-th chunk of p, as striped by stripe.
+func (f *file) chunkLen(p []byte, i int) int {
+	chunk := f.fs.c.stripeSize
+	if chunk <= 0 {
+		return len(p)
+	}
+	if n := len(p) - i*chunk; n < chunk {
+		return n
+	}
+	return chunk
+}
+
 // ReadAt reads len(p) bytes into p starting at offset off.
 func (f *file) ReadAt(p []byte, off int64) (n int, err error) {
 	return f.ReadAtContext(context.Background(), p, off)
 }
 
+// ReadV reads the segments segs of the file using vectored read requests,
+// fetching up to readv.MaxSegments segments in a single round trip.
+// Segments longer than readv.MaxSegmentLength are read as several chunks.
+// The Data of a segment is shortened to the number of bytes read
+// when the segment extends past the end of the file.
+func (f *file) ReadV(ctx context.Context, segs []xrdfs.Segment) error {
+	var (
+		req  readv.Request
+		resp readv.Response
+		idx  []int // index of the segment of each chunk
+		ns   = make([]int, len(segs))
+	)
+
+	flush := func() error {
+		if len(req.Segments) == 0 {
+			return nil
+		}
+		err := f.do(ctx, func(ctx context.Context, sid string) (string, error) {
+			handle := f.Handle()
+			for i := range req.Segments {
+				req.Segments[i].Handle = handle
+			}
+			return f.fs.c.sendSession(ctx, sid, &resp, &req)
+		})
+		if err != nil {
+			return err
+		}
+		if len(resp.Chunks) != len(req.Segments) {
+			return fmt.Errorf("xrootd: invalid readv response: got %d chunks, want %d", len(resp.Chunks), len(req.Segments))
+		}
+		for i, chunk := range resp.Chunks {
+			seg := req.Segments[i]
+			if chunk.Handle != seg.Handle || chunk.Offset != seg.Offset || chunk.Length > seg.Length {
+				return fmt.Errorf("xrootd: invalid readv response chunk %d: got %+v, want %+v", i, chunk.Segment, seg)
+			}
+			ns[idx[i]] += int(chunk.Length)
+		}
+		req.Segments = req.Segments[:0]
+		resp.Chunks = resp.Chunks[:0]
+		idx = idx[:0]
+		return nil
+	}
+
+	for i, seg := range segs {
+		for beg := 0; beg < len(seg.Data); beg += readv.MaxSegmentLength {
+			end := beg + readv.MaxSegmentLength
+			if end > len(seg.Data) {
+				end = len(seg.Data)
+			}
+			req.Segments = append(req.Segments, readv.Segment{
+				Length: int32(end - beg),
+				Offset: seg.Offset + int64(beg),
+			})
+			// read directly into the caller's buffer.
+			resp.Chunks = append(resp.Chunks, readv.Chunk{Data: seg.Data[beg:end:end]})
+			idx = append(idx, i)
+			if len(req.Segments) == readv.MaxSegments {
+				err := flush()
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	err := flush()
+	if err != nil {
+		return err
+	}
+
+	for i := range segs {
+		segs[i].Data = segs[i].Data[:ns[i]]
+	}
+	return nil
+}
+
 // WriteAtContext writes len(p) bytes from p to the file at offset off.
+// Large writes are striped across the parallel data streams of the client.
 func (f *file) WriteAtContext(ctx context.Context, p []byte, off int64) error {
-	return f.do(ctx, func(ctx context.Context, sid string) (string, error) {
-		return f.fs.c.sendSession(ctx, sid, nil, &write.Request{Handle: f.handle, Offset: off, Data: p})
+	_, err := f.stripe(ctx, p, off, f.writeAt)
+	return err
+}
+
+func (f *file) writeAt(ctx context.Context, p []byte, off int64) (int, error) {
+	err := f.do(ctx, func(ctx context.Context, sid string) (string, error) {
+		return f.fs.c.sendSession(ctx, sid, nil, &write.Request{Handle: f.Handle(), Offset: off, Data: p})
 	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
 }
 
 // WriteAt writes len(p) bytes from p to the file at offset off.
@@ -104,7 +273,7 @@ func (f *file) WriteAt(p []byte, off int64) (n int, err error) {
 // Truncate changes the size of the named file.
 func (f *file) Truncate(ctx context.Context, size int64) error {
 	return f.do(ctx, func(ctx context.Context, sid string) (string, error) {
-		return f.fs.c.sendSession(ctx, sid, nil, &truncate.Request{Handle: f.handle, Size: size})
+		return f.fs.c.sendSession(ctx, sid, nil, &truncate.Request{Handle: f.Handle(), Size: size})
 	})
 }
 
@@ -114,7 +283,7 @@ func (f *file) Truncate(ctx context.Context, size int64) error {
 func (f *file) StatVirtualFS(ctx context.Context) (xrdfs.VirtualFSStat, error) {
 	var resp stat.VirtualFSResponse
 	err := f.do(ctx, func(ctx context.Context, sid string) (string, error) {
-		return f.fs.c.sendSession(ctx, sid, &resp, &stat.Request{FileHandle: f.handle, Options: stat.OptionsVFS})
+		return f.fs.c.sendSession(ctx, sid, &resp, &stat.Request{FileHandle: f.Handle(), Options: stat.OptionsVFS})
 	})
 	if err != nil {
 		return xrdfs.VirtualFSStat{}, err
@@ -126,18 +295,15 @@ func (f *file) StatVirtualFS(ctx context.Context) (xrdfs.VirtualFSStat, error) {
 // Note that Stat re-fetches value returned by the Info, so after the call to Stat
 // calls to Info may return different value than before.
 func (f *file) Stat(ctx context.Context) (xrdfs.EntryStat, error) {
-	f.mu.RLock()
-	sid := f.sessionID
-	f.mu.RUnlock()
-
 	var resp stat.DefaultResponse
-	sid, err := f.fs.c.sendSession(ctx, sid, &resp, &stat.Request{FileHandle: f.handle})
+	err := f.do(ctx, func(ctx context.Context, sid string) (string, error) {
+		return f.fs.c.sendSession(ctx, sid, &resp, &stat.Request{FileHandle: f.Handle()})
+	})
 	if err != nil {
 		return xrdfs.EntryStat{}, err
 	}
 
 	f.mu.Lock()
-	f.sessionID = sid
 	f.info = &resp.EntryStat
 	f.mu.Unlock()
 
@@ -146,11 +312,18 @@ func (f *file) Stat(ctx context.Context) (xrdfs.EntryStat, error) {
 
 // VerifyWriteAt writes len(p) bytes from p to the file at offset off using crc32 verification.
 //
+// sessID returns the ID of the session the file is currently opened on.
+func (f *file) sessID() string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.sessionID
+}
+
 // TODO: note that verifyw is not supported by the XRootD server.
 // See https://github.com/xrootd/xrootd/issues/738 for the details.
 func (f *file) VerifyWriteAt(ctx context.Context, p []byte, off int64) error {
 	return f.do(ctx, func(ctx context.Context, sid string) (string, error) {
-		return f.fs.c.sendSession(ctx, sid, nil, verifyw.NewRequestCRC32(f.handle, off, p))
+		return f.fs.c.sendSession(ctx, sid, nil, verifyw.NewRequestCRC32(f.Handle(), off, p))
 	})
 }
 
@@ -160,17 +333,58 @@ func (f *file) do(ctx context.Context, fct func(ctx context.Context, sid string)
 	f.mu.RUnlock()
 
 	id, err := fct(ctx, sid)
+	if err != nil && f.stale() {
+		// the connection the file was opened on was lost, and the file handle
+		// with it: reopen the file on a new connection and retry.
+		if err := f.reopen(ctx); err != nil {
+			return err
+		}
+		f.mu.RLock()
+		sid = f.sessionID
+		f.mu.RUnlock()
+		id, err = fct(ctx, sid)
+	}
 	if err != nil {
 		return err
 	}
 
 	f.mu.Lock()
-	f.sessionID = id
+	if id != f.sessionID {
+		f.sessionID = id
+		f.sess = f.fs.c.session(id)
+	}
 	f.mu.Unlock()
 
 	return nil
 }
 
+// stale returns whether the session the file was opened on was lost.
+func (f *file) stale() bool {
+	f.mu.RLock()
+	sid, sess := f.sessionID, f.sess
+	f.mu.RUnlock()
+	return sess != nil && f.fs.c.session(sid) != sess
+}
+
+// reopen reopens the file, after the loss of the session it was opened on.
+func (f *file) reopen(ctx context.Context) error {
+	// do not truncate nor fail on the file created by the initial open.
+	options := f.options &^ (xrdfs.OpenOptionsDelete | xrdfs.OpenOptionsNew)
+
+	var resp open.Response
+	sid, err := f.fs.c.Send(ctx, &resp, open.NewRequest(f.path, f.mode, options))
+	if err != nil {
+		return fmt.Errorf("xrootd: could not reopen %q: %w", f.path, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handle = resp.FileHandle
+	f.sessionID = sid
+	f.sess = f.fs.c.session(sid)
+	return nil
+}
+
 var (
 	_ xrdfs.File = (*file)(nil)
 )
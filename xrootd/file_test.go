@@ -132,6 +132,48 @@ func TestFile_ReadAt(t *testing.T) {
 	}
 }
 
+func testFile_ReadV(t *testing.T, addr string) {
+	t.Parallel()
+
+	client, err := NewClient(context.Background(), addr, "gopher")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer client.Close()
+
+	fs := client.FS()
+
+	file, err := fs.Open(context.Background(), "/tmp/file1.txt", xrdfs.OpenModeOtherRead, xrdfs.OpenOptionsNone)
+	if err != nil {
+		t.Fatalf("invalid open call: %v", err)
+	}
+	defer file.Close(context.Background())
+
+	segs := []xrdfs.Segment{
+		{Offset: 6, Data: make([]byte, 6)},
+		{Offset: 0, Data: make([]byte, 5)},
+		{Offset: 12, Data: make([]byte, 10)},
+	}
+	err = file.ReadV(context.Background(), segs)
+	if err != nil {
+		t.Fatalf("invalid readv call: %v", err)
+	}
+
+	for i, want := range []string{"XRootD", "Hello", ".\n"} {
+		if got := string(segs[i].Data); got != want {
+			t.Fatalf("read data does not match for segment %d:\ngot = %q\nwant = %q", i, got, want)
+		}
+	}
+}
+
+func TestFile_ReadV(t *testing.T) {
+	for _, addr := range testClientAddrs {
+		t.Run(addr, func(t *testing.T) {
+			testFile_ReadV(t, addr)
+		})
+	}
+}
+
 func testFile_WriteAt(t *testing.T, addr string) {
 	t.Parallel()
 
@@ -48,7 +48,17 @@ func (fs *fileSystem) Open(ctx context.Context, path string, mode xrdfs.OpenMode
 	if err != nil {
 		return nil, err
 	}
-	return &file{fs: fs, handle: resp.FileHandle, compression: resp.Compression, info: resp.Stat, sessionID: server}, nil
+	return &file{
+		fs:          fs,
+		handle:      resp.FileHandle,
+		compression: resp.Compression,
+		info:        resp.Stat,
+		path:        path,
+		mode:        mode,
+		options:     options,
+		sessionID:   server,
+		sess:        fs.c.session(server),
+	}, nil
 }
 
 // RemoveFile removes a file.
@@ -5,25 +5,37 @@
 package xrootd // import "go-hep.org/x/hep/xrootd"
 
 import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"hash/adler32"
+	"hash/crc32"
 	"io"
 	"math/rand"
+	"net/url"
 	"os"
 	"path"
+	"strings"
 	"sync"
 
 	"go-hep.org/x/hep/xrootd/xrdfs"
 	"go-hep.org/x/hep/xrootd/xrdproto"
+	"go-hep.org/x/hep/xrootd/xrdproto/chmod"
 	"go-hep.org/x/hep/xrootd/xrdproto/dirlist"
 	"go-hep.org/x/hep/xrootd/xrdproto/mkdir"
 	"go-hep.org/x/hep/xrootd/xrdproto/mv"
 	"go-hep.org/x/hep/xrootd/xrdproto/open"
+	"go-hep.org/x/hep/xrootd/xrdproto/query"
 	"go-hep.org/x/hep/xrootd/xrdproto/read"
+	"go-hep.org/x/hep/xrootd/xrdproto/readv"
 	"go-hep.org/x/hep/xrootd/xrdproto/rm"
 	"go-hep.org/x/hep/xrootd/xrdproto/rmdir"
 	"go-hep.org/x/hep/xrootd/xrdproto/stat"
 	xrdsync "go-hep.org/x/hep/xrootd/xrdproto/sync"
 	"go-hep.org/x/hep/xrootd/xrdproto/truncate"
+	"go-hep.org/x/hep/xrootd/xrdproto/verifyw"
 	"go-hep.org/x/hep/xrootd/xrdproto/write"
 	"go-hep.org/x/hep/xrootd/xrdproto/xrdclose"
 )
@@ -53,9 +65,16 @@ func NewFSHandler(basePath string) Handler {
 	}
 }
 
+// fspath returns the path on the backing filesystem of the named file,
+// with any opaque data ("?key=value&...") stripped.
+func (h *fshandler) fspath(name string) string {
+	name, _, _ = strings.Cut(name, "?")
+	return path.Join(h.basePath, name)
+}
+
 // Dirlist implements server.Handler.Dirlist.
 func (h *fshandler) Dirlist(sessionID [16]byte, request *dirlist.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
-	files, err := os.ReadDir(path.Join(h.basePath, request.Path))
+	files, err := os.ReadDir(h.fspath(request.Path))
 	if err != nil {
 		return xrdproto.ServerError{
 			Code:    xrdproto.IOError,
@@ -86,6 +105,14 @@ func (h *fshandler) Dirlist(sessionID [16]byte, request *dirlist.Request) (xrdpr
 
 // Open implements server.Handler.Open.
 func (h *fshandler) Open(sessionID [16]byte, request *open.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	cgi, _ := url.ParseQuery(request.Opaque())
+	if cgi.Get("tpc.key") != "" {
+		return xrdproto.ServerError{
+			Code:    xrdproto.Unsupported,
+			Message: "Third-party copies are not supported",
+		}, xrdproto.Error
+	}
+
 	var flag int
 	if request.Options&xrdfs.OpenOptionsOpenRead != 0 {
 		flag |= os.O_RDONLY
@@ -105,9 +132,10 @@ func (h *fshandler) Open(sessionID [16]byte, request *open.Request) (xrdproto.Ma
 		}
 	}
 
-	filePath := path.Join(h.basePath, request.Path)
+	filePath := h.fspath(request.Path)
 	if request.Options&xrdfs.OpenOptionsMkPath != 0 {
-		if err := os.MkdirAll(path.Dir(filePath), os.FileMode(request.Mode)); err != nil {
+		// request.Mode is the mode of the file: directories need to be traversable.
+		if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
 			return xrdproto.ServerError{
 				Code:    xrdproto.IOError,
 				Message: fmt.Sprintf("An IO error occurred: %v", err),
@@ -231,6 +259,46 @@ func (h *fshandler) Read(sessionID [16]byte, request *read.Request) (xrdproto.Ma
 	return read.Response{Data: buf[:n]}, xrdproto.Ok
 }
 
+// ReadV implements server.Handler.ReadV.
+func (h *fshandler) ReadV(sessionID [16]byte, request *readv.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	if len(request.Segments) > readv.MaxSegments {
+		return xrdproto.ServerError{
+			Code:    xrdproto.InvalidRequest,
+			Message: fmt.Sprintf("Too many readv segments: %d (max=%d)", len(request.Segments), readv.MaxSegments),
+		}, xrdproto.Error
+	}
+
+	resp := readv.Response{Chunks: make([]readv.Chunk, len(request.Segments))}
+	for i, seg := range request.Segments {
+		if seg.Length < 0 || seg.Length > readv.MaxSegmentLength {
+			return xrdproto.ServerError{
+				Code:    xrdproto.InvalidRequest,
+				Message: fmt.Sprintf("Invalid readv segment length: %d", seg.Length),
+			}, xrdproto.Error
+		}
+
+		file := h.getFile(sessionID, seg.Handle)
+		if file == nil {
+			return xrdproto.ServerError{
+				Code:    xrdproto.InvalidRequest,
+				Message: fmt.Sprintf("Invalid file handle: %v", seg.Handle),
+			}, xrdproto.Error
+		}
+
+		buf := make([]byte, seg.Length)
+		n, err := file.ReadAt(buf, seg.Offset)
+		if err != nil && err != io.EOF {
+			return xrdproto.ServerError{
+				Code:    xrdproto.IOError,
+				Message: fmt.Sprintf("An IO error occurred: %v", err),
+			}, xrdproto.Error
+		}
+		resp.Chunks[i] = readv.Chunk{Segment: seg, Data: buf[:n]}
+	}
+
+	return resp, xrdproto.Ok
+}
+
 // Write implements server.Handler.Write.
 func (h *fshandler) Write(sessionID [16]byte, request *write.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
 	file := h.getFile(sessionID, request.Handle)
@@ -252,6 +320,52 @@ func (h *fshandler) Write(sessionID [16]byte, request *write.Request) (xrdproto.
 	return nil, xrdproto.Ok
 }
 
+// VerifyWrite implements server.Handler.VerifyWrite.
+func (h *fshandler) VerifyWrite(sessionID [16]byte, request *verifyw.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	file := h.getFile(sessionID, request.Handle)
+	if file == nil {
+		return xrdproto.ServerError{
+			Code:    xrdproto.InvalidRequest,
+			Message: fmt.Sprintf("Invalid file handle: %v", request.Handle),
+		}, xrdproto.Error
+	}
+
+	data := request.Data
+	switch request.Verification {
+	case verifyw.NoCRC:
+	case verifyw.CRC32:
+		if len(data) < 4 {
+			return xrdproto.ServerError{
+				Code:    xrdproto.InvalidRequest,
+				Message: "Missing CRC32 checksum",
+			}, xrdproto.Error
+		}
+		want := binary.BigEndian.Uint32(data[:4])
+		data = data[4:]
+		if got := crc32.ChecksumIEEE(data); got != want {
+			return xrdproto.ServerError{
+				Code:    xrdproto.ChecksumError,
+				Message: fmt.Sprintf("CRC32 checksum mismatch: got=0x%08x, want=0x%08x", got, want),
+			}, xrdproto.Error
+		}
+	default:
+		return xrdproto.ServerError{
+			Code:    xrdproto.Unsupported,
+			Message: fmt.Sprintf("Verification type %d is not supported", request.Verification),
+		}, xrdproto.Error
+	}
+
+	_, err := file.WriteAt(data, request.Offset)
+	if err != nil {
+		return xrdproto.ServerError{
+			Code:    xrdproto.IOError,
+			Message: fmt.Sprintf("An IO error occurred: %v", err),
+		}, xrdproto.Error
+	}
+
+	return nil, xrdproto.Ok
+}
+
 func (h *fshandler) getFile(sessionID [16]byte, handle xrdfs.FileHandle) *os.File {
 	h.mu.RLock()
 	sess, ok := h.sessions[sessionID]
@@ -290,9 +404,15 @@ func (h *fshandler) Stat(sessionID [16]byte, request *stat.Request) (xrdproto.Ma
 		}
 		fi, err = file.Stat()
 	} else {
-		fi, err = os.Stat(path.Join(h.basePath, request.Path))
+		fi, err = os.Stat(h.fspath(request.Path))
 	}
 
+	if os.IsNotExist(err) {
+		return xrdproto.ServerError{
+			Code:    xrdproto.NotFound,
+			Message: fmt.Sprintf("No such file or directory: %v", request.Path),
+		}, xrdproto.Error
+	}
 	if err != nil {
 		return xrdproto.ServerError{
 			Code:    xrdproto.IOError,
@@ -316,7 +436,7 @@ func (h *fshandler) Truncate(sessionID [16]byte, request *truncate.Request) (xrd
 		}
 		err = file.Truncate(request.Size)
 	} else {
-		err = os.Truncate(path.Join(h.basePath, request.Path), request.Size)
+		err = os.Truncate(h.fspath(request.Path), request.Size)
 	}
 
 	if err != nil {
@@ -351,7 +471,7 @@ func (h *fshandler) Sync(sessionID [16]byte, request *xrdsync.Request) (xrdproto
 
 // Rename implements server.Handler.Rename.
 func (h *fshandler) Rename(sessionID [16]byte, request *mv.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
-	if err := os.Rename(path.Join(h.basePath, request.OldPath), path.Join(h.basePath, request.NewPath)); err != nil {
+	if err := os.Rename(h.fspath(request.OldPath), h.fspath(request.NewPath)); err != nil {
 		return xrdproto.ServerError{
 			Code:    xrdproto.IOError,
 			Message: fmt.Sprintf("An IO error occurred: %v", err),
@@ -368,7 +488,7 @@ func (h *fshandler) Mkdir(sessionID [16]byte, request *mkdir.Request) (xrdproto.
 		mkdirFunc = os.MkdirAll
 	}
 
-	if err := mkdirFunc(path.Join(h.basePath, request.Path), os.FileMode(request.Mode)); err != nil {
+	if err := mkdirFunc(h.fspath(request.Path), os.FileMode(request.Mode)); err != nil {
 		return xrdproto.ServerError{
 			Code:    xrdproto.IOError,
 			Message: fmt.Sprintf("An IO error occurred: %v", err),
@@ -379,7 +499,7 @@ func (h *fshandler) Mkdir(sessionID [16]byte, request *mkdir.Request) (xrdproto.
 
 // Remove implements server.Handler.Remove.
 func (h *fshandler) Remove(sessionID [16]byte, request *rm.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
-	if err := os.Remove(path.Join(h.basePath, request.Path)); err != nil {
+	if err := os.Remove(h.fspath(request.Path)); err != nil {
 		return xrdproto.ServerError{
 			Code:    xrdproto.IOError,
 			Message: fmt.Sprintf("An IO error occurred: %v", err),
@@ -390,7 +510,7 @@ func (h *fshandler) Remove(sessionID [16]byte, request *rm.Request) (xrdproto.Ma
 
 // RemoveDir implements server.Handler.RemoveDir.
 func (h *fshandler) RemoveDir(sessionID [16]byte, request *rmdir.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
-	if err := os.Remove(path.Join(h.basePath, request.Path)); err != nil {
+	if err := os.Remove(h.fspath(request.Path)); err != nil {
 		return xrdproto.ServerError{
 			Code:    xrdproto.IOError,
 			Message: fmt.Sprintf("An IO error occurred: %v", err),
@@ -399,6 +519,70 @@ func (h *fshandler) RemoveDir(sessionID [16]byte, request *rmdir.Request) (xrdpr
 	return nil, xrdproto.Ok
 }
 
+// Chmod implements server.Handler.Chmod.
+func (h *fshandler) Chmod(sessionID [16]byte, request *chmod.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	if err := os.Chmod(h.fspath(request.Path), os.FileMode(request.Mode)); err != nil {
+		return xrdproto.ServerError{
+			Code:    xrdproto.IOError,
+			Message: fmt.Sprintf("An IO error occurred: %v", err),
+		}, xrdproto.Error
+	}
+	return nil, xrdproto.Ok
+}
+
+// checksums lists the checksum algorithms supported by fshandler.Query.
+var checksums = map[string]func() hash.Hash{
+	"adler32": func() hash.Hash { return adler32.New() },
+	"crc32c":  func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	"md5":     md5.New,
+}
+
+// Query implements server.Handler.Query.
+// Only the checksum query is supported, with the adler32 (default),
+// crc32c and md5 algorithms.
+func (h *fshandler) Query(sessionID [16]byte, request *query.Request) (xrdproto.Marshaler, xrdproto.ResponseStatus) {
+	if request.Query != query.Checksum {
+		return xrdproto.ServerError{
+			Code:    xrdproto.InvalidRequest,
+			Message: fmt.Sprintf("Query request with query %d is not implemented", request.Query),
+		}, xrdproto.Error
+	}
+
+	name, opaque, _ := strings.Cut(string(request.Args), "?")
+	cgi, _ := url.ParseQuery(opaque)
+	typ := cgi.Get("cks.type")
+	if typ == "" {
+		typ = "adler32"
+	}
+	newHash, ok := checksums[typ]
+	if !ok {
+		return xrdproto.ServerError{
+			Code:    xrdproto.InvalidRequest,
+			Message: fmt.Sprintf("Checksum type %q is not supported", typ),
+		}, xrdproto.Error
+	}
+
+	f, err := os.Open(h.fspath(name))
+	if err != nil {
+		return xrdproto.ServerError{
+			Code:    xrdproto.IOError,
+			Message: fmt.Sprintf("An IO error occurred: %v", err),
+		}, xrdproto.Error
+	}
+	defer f.Close()
+
+	sum := newHash()
+	_, err = io.Copy(sum, f)
+	if err != nil {
+		return xrdproto.ServerError{
+			Code:    xrdproto.IOError,
+			Message: fmt.Sprintf("An IO error occurred: %v", err),
+		}, xrdproto.Error
+	}
+
+	return query.Response{Data: []byte(typ + " " + hex.EncodeToString(sum.Sum(nil)))}, xrdproto.Ok
+}
+
 // CloseSession implements server.Handler.CloseSession.
 func (h *fshandler) CloseSession(sessionID [16]byte) error {
 	h.mu.Lock()
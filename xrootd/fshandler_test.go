@@ -14,11 +14,14 @@ import (
 	"reflect"
 	"sync"
 	"testing"
+	"time"
 
 	"go-hep.org/x/hep/xrootd"
 	"go-hep.org/x/hep/xrootd/xrdfs"
 	"go-hep.org/x/hep/xrootd/xrdproto"
 	"go-hep.org/x/hep/xrootd/xrdproto/ping"
+	"go-hep.org/x/hep/xrootd/xrdproto/query"
+	"go-hep.org/x/hep/xrootd/xrdproto/verifyw"
 )
 
 func getTCPAddr() (string, error) {
@@ -432,6 +435,115 @@ func TestHandler_Read(t *testing.T) {
 	}
 }
 
+func TestHandler_ReadV(t *testing.T) {
+	data := make([]byte, 3*1024*1024)
+	_, err := rand.Read(data)
+	if err != nil {
+		t.Fatalf("could not prepare test data: %v", err)
+	}
+
+	srv, addr, baseDir, err := createServer(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	defer func() {
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	err = os.WriteFile(path.Join(baseDir, "file1.txt"), data, 0777)
+	if err != nil {
+		t.Fatalf("could not create test file: %v", err)
+	}
+
+	cli, err := createClient(addr)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	f, err := cli.FS().Open(context.Background(), "file1.txt", xrdfs.OpenModeOwnerRead, xrdfs.OpenOptionsOpenRead)
+	if err != nil {
+		t.Fatalf("could not call Open: %v", err)
+	}
+	defer f.Close(context.Background())
+
+	for _, tc := range []struct {
+		testName string
+		offsets  []int64
+		lengths  []int
+	}{
+		{
+			testName: "Single segment",
+			offsets:  []int64{1},
+			lengths:  []int{6},
+		},
+		{
+			testName: "Many segments",
+			offsets:  []int64{40, 0, 1024, 1024, 2000},
+			lengths:  []int{10, 20, 0, 512, 4096},
+		},
+		{
+			testName: "With EOF",
+			offsets:  []int64{10, int64(len(data)) - 10, int64(len(data)) + 10},
+			lengths:  []int{10, 20, 10},
+		},
+		{
+			testName: "With big segment",
+			offsets:  []int64{5},
+			lengths:  []int{len(data)},
+		},
+		{
+			testName: "With more than 1024 segments",
+			offsets: func() []int64 {
+				offs := make([]int64, 2500)
+				for i := range offs {
+					offs[i] = int64(i * 100)
+				}
+				return offs
+			}(),
+			lengths: func() []int {
+				lens := make([]int, 2500)
+				for i := range lens {
+					lens[i] = 10 + i%50
+				}
+				return lens
+			}(),
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			segs := make([]xrdfs.Segment, len(tc.offsets))
+			for i := range segs {
+				segs[i] = xrdfs.Segment{
+					Offset: tc.offsets[i],
+					Data:   make([]byte, tc.lengths[i]),
+				}
+			}
+
+			err := f.ReadV(context.Background(), segs)
+			if err != nil {
+				t.Fatalf("could not call ReadV: %v", err)
+			}
+
+			for i, seg := range segs {
+				beg := tc.offsets[i]
+				end := beg + int64(tc.lengths[i])
+				if beg > int64(len(data)) {
+					beg = int64(len(data))
+				}
+				if end > int64(len(data)) {
+					end = int64(len(data))
+				}
+				if want := data[beg:end]; !reflect.DeepEqual(seg.Data, want) {
+					t.Fatalf("wrong data for segment %d (len=%d, want=%d)", i, len(seg.Data), len(want))
+				}
+			}
+		})
+	}
+}
+
 func TestHandler_Write(t *testing.T) {
 	bigData := make([]byte, 10*1024)
 	_, err := rand.Read(bigData)
@@ -539,6 +651,67 @@ func TestHandler_Write(t *testing.T) {
 	}
 }
 
+func TestHandler_VerifyWrite(t *testing.T) {
+	srv, addr, baseDir, err := createServer(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	defer func() {
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	file := path.Join(baseDir, "file1.txt")
+	err = os.WriteFile(file, []byte{1, 2, 3, 4, 5, 6, 7, 8}, 0644)
+	if err != nil {
+		t.Fatalf("could not create test file: %v", err)
+	}
+
+	cli, err := createClient(addr)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	f, err := cli.FS().Open(context.Background(), "file1.txt", xrdfs.OpenModeOwnerWrite, xrdfs.OpenOptionsOpenUpdate)
+	if err != nil {
+		t.Fatalf("could not call Open: %v", err)
+	}
+	defer f.Close(context.Background())
+
+	err = f.VerifyWriteAt(context.Background(), []byte{9, 8, 7}, 2)
+	if err != nil {
+		t.Fatalf("could not call VerifyWriteAt: %v", err)
+	}
+
+	req := verifyw.NewRequestCRC32(f.Handle(), 0, []byte{0, 0})
+	req.Data[0]++ // corrupt the checksum.
+	_, err = cli.Send(context.Background(), nil, req)
+	if err == nil {
+		t.Fatalf("expected an error for a corrupted checksum")
+	}
+	if err, ok := err.(xrdproto.ServerError); !ok || err.Code != xrdproto.ChecksumError {
+		t.Fatalf("invalid error: %v", err)
+	}
+
+	_, err = cli.Send(context.Background(), nil, &verifyw.Request{Handle: f.Handle(), Offset: 8, Data: []byte{10}})
+	if err != nil {
+		t.Fatalf("could not write without checksum: %v", err)
+	}
+
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("could not read written data: %v", err)
+	}
+
+	want := []byte{1, 2, 9, 8, 7, 6, 7, 8, 10}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("wrong data:\ngot = %v\nwant = %v", got, want)
+	}
+}
+
 func TestHandler_Stat(t *testing.T) {
 	for _, tc := range []struct {
 		testName string
@@ -1017,6 +1190,99 @@ func TestHandler_RemoveDir(t *testing.T) {
 	}
 }
 
+func TestHandler_Chmod(t *testing.T) {
+	srv, addr, baseDir, err := createServer(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	defer func() {
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	file := path.Join(baseDir, "file1.txt")
+	err = os.WriteFile(file, nil, 0600)
+	if err != nil {
+		t.Fatalf("could not create test file: %v", err)
+	}
+
+	cli, err := createClient(addr)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	err = cli.FS().Chmod(context.Background(), "file1.txt", xrdfs.OpenModeOwnerRead|xrdfs.OpenModeOwnerWrite|xrdfs.OpenModeGroupRead)
+	if err != nil {
+		t.Fatalf("could not call Chmod: %v", err)
+	}
+
+	fi, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("could not stat file: %v", err)
+	}
+	if got, want := fi.Mode().Perm(), os.FileMode(0640); got != want {
+		t.Fatalf("invalid mode: got=%v, want=%v", got, want)
+	}
+
+	err = cli.FS().Chmod(context.Background(), "missing.txt", xrdfs.OpenModeOwnerRead)
+	if err == nil {
+		t.Fatalf("expected an error for a missing file")
+	}
+}
+
+func TestHandler_OpenOpaque(t *testing.T) {
+	srv, addr, baseDir, err := createServer(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	defer func() {
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	cli, err := createClient(addr)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	const mode = xrdfs.OpenModeOwnerRead | xrdfs.OpenModeOwnerWrite
+	f, err := cli.FS().Open(context.Background(), "dir/file1.txt?oss.asize=42", mode, xrdfs.OpenOptionsNew|xrdfs.OpenOptionsMkPath)
+	if err != nil {
+		t.Fatalf("could not call Open: %v", err)
+	}
+	err = f.Close(context.Background())
+	if err != nil {
+		t.Fatalf("could not call Close: %v", err)
+	}
+
+	fi, err := os.Stat(path.Join(baseDir, "dir"))
+	if err != nil {
+		t.Fatalf("could not stat created dir: %v", err)
+	}
+	if got := fi.Mode().Perm(); got&0700 != 0700 {
+		t.Fatalf("invalid dir mode: got=%v", got)
+	}
+
+	_, err = os.Stat(path.Join(baseDir, "dir", "file1.txt"))
+	if err != nil {
+		t.Fatalf("could not stat created file: %v", err)
+	}
+
+	_, err = cli.FS().Open(context.Background(), "dir/file1.txt?tpc.key=1234&tpc.stage=copy", mode, xrdfs.OpenOptionsOpenRead)
+	if err == nil {
+		t.Fatalf("expected an error for a third-party copy")
+	}
+	if err, ok := err.(xrdproto.ServerError); !ok || err.Code != xrdproto.Unsupported {
+		t.Fatalf("invalid error: %v", err)
+	}
+}
+
 func TestHandler_Ping(t *testing.T) {
 	srv, addr, baseDir, err := createServer(func(err error) {
 		t.Error(err)
@@ -1040,3 +1306,131 @@ func TestHandler_Ping(t *testing.T) {
 		t.Fatalf("could not call Ping: %v", err)
 	}
 }
+
+func TestHandler_Query(t *testing.T) {
+	srv, addr, baseDir, err := createServer(func(err error) {
+		t.Error(err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	defer func() {
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	data := []byte("Wikipedia")
+	err = os.WriteFile(path.Join(baseDir, "file1.txt"), data, 0644)
+	if err != nil {
+		t.Fatalf("could not create test file: %v", err)
+	}
+
+	cli, err := createClient(addr)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	for _, tc := range []struct {
+		args string
+		want string
+	}{
+		{args: "file1.txt", want: "adler32 11e60398"},
+		{args: "file1.txt?cks.type=adler32", want: "adler32 11e60398"},
+		{args: "file1.txt?cks.type=crc32c", want: "crc32c 2d0e3663"},
+		{args: "file1.txt?cks.type=md5", want: "md5 9c677286866aad38f8e9b660f5411814"},
+	} {
+		var resp query.Response
+		_, err = cli.Send(context.Background(), &resp, &query.Request{Query: query.Checksum, Args: []byte(tc.args)})
+		if err != nil {
+			t.Fatalf("could not query checksum of %q: %v", tc.args, err)
+		}
+		if got := string(resp.Data); got != tc.want {
+			t.Fatalf("invalid checksum of %q: got=%q, want=%q", tc.args, got, tc.want)
+		}
+	}
+
+	for _, req := range []*query.Request{
+		{Query: query.Checksum, Args: []byte("file1.txt?cks.type=sha1")},
+		{Query: query.Checksum, Args: []byte("missing.txt")},
+		{Query: query.Stats},
+	} {
+		_, err = cli.Send(context.Background(), &query.Response{}, req)
+		if err == nil {
+			t.Fatalf("expected an error for query %d with args %q", req.Query, req.Args)
+		}
+	}
+}
+
+func TestHandler_Reconnect(t *testing.T) {
+	baseDir, err := os.MkdirTemp("", "xrd-srv-")
+	if err != nil {
+		t.Fatalf("could not create test dir: %v", err)
+	}
+	defer os.RemoveAll(baseDir)
+
+	addr, err := getTCPAddr()
+	if err != nil {
+		t.Fatalf("could not get free port to listen: %v", err)
+	}
+
+	serve := func() *xrootd.Server {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Fatalf("could not listen on %q: %v", addr, err)
+		}
+		srv := xrootd.NewServer(xrootd.NewFSHandler(baseDir), func(err error) {
+			// errors are expected when the server is shut down.
+			t.Log(err)
+		})
+		go func() {
+			_ = srv.Serve(listener)
+		}()
+		return srv
+	}
+
+	want := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	err = os.WriteFile(path.Join(baseDir, "file1.txt"), want, 0777)
+	if err != nil {
+		t.Fatalf("could not create test file: %v", err)
+	}
+
+	srv := serve()
+
+	cli, err := xrootd.NewClient(context.Background(), addr, "gopher", xrootd.WithRetries(5, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	f, err := cli.FS().Open(context.Background(), "file1.txt", xrdfs.OpenModeOwnerRead, xrdfs.OpenOptionsOpenRead)
+	if err != nil {
+		t.Fatalf("could not call Open: %v", err)
+	}
+	defer f.Close(context.Background())
+
+	// restart the server: the connection and the file handle are lost.
+	err = srv.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("could not shutdown server: %v", err)
+	}
+	srv = serve()
+	defer func() {
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	got := make([]byte, len(want))
+	_, err = f.ReadAt(got, 0)
+	if err != nil {
+		t.Fatalf("could not call ReadAt after reconnection: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("wrong data:\ngot = %v\nwant = %v", got, want)
+	}
+
+	_, err = cli.Send(context.Background(), nil, &ping.Request{})
+	if err != nil {
+		t.Fatalf("could not call Ping after reconnection: %v", err)
+	}
+}
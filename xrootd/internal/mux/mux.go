@@ -151,15 +151,16 @@ func (m *Mux) Close() {
 	close(m.quit)
 
 	response := ServerResponse{Err: errors.New("xrootd: close was called before response was fully received")}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	for streamID := range m.dataWaiters {
-		_ = m.SendData(streamID, response)
-		m.Unclaim(streamID)
+		m.abort(streamID, response)
 	}
 }
 
 // Claim searches for unclaimed id and returns corresponding channel.
 func (m *Mux) Claim() (xrdproto.StreamID, DataRecvChan, error) {
-	ch := make(chan ServerResponse)
+	ch := make(chan ServerResponse, 1)
 
 	for {
 		id := <-m.freeIDs
@@ -188,7 +189,7 @@ func (m *Mux) ClaimWithID(id xrdproto.StreamID) (DataRecvChan, error) {
 	if m.closed {
 		return nil, errors.New("mux: ClaimWithID was called on closed Mux")
 	}
-	ch := make(chan ServerResponse)
+	ch := make(chan ServerResponse, 1)
 
 	if _, claimed := m.dataWaiters[id]; claimed {
 		return nil, fmt.Errorf("mux: channel with id %v is already claimed", id)
@@ -210,6 +211,34 @@ func (m *Mux) Unclaim(id xrdproto.StreamID) {
 	}
 }
 
+// Abort sends the final response resp to the channel with specified id,
+// and marks that channel as unclaimed.
+// Contrary to SendData, Abort does not wait for resp to be received.
+func (m *Mux) Abort(id xrdproto.StreamID, resp ServerResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.abort(id, resp)
+}
+
+func (m *Mux) abort(id xrdproto.StreamID, resp ServerResponse) {
+	ch, ok := m.dataWaiters[id]
+	if !ok {
+		return
+	}
+	delete(m.dataWaiters, id)
+
+	select {
+	case ch <- resp:
+		close(ch)
+	default:
+		// the previous response was not received yet.
+		go func() {
+			ch <- resp
+			close(ch)
+		}()
+	}
+}
+
 // SendData sends data to channel with specific id.
 func (m *Mux) SendData(id xrdproto.StreamID, data ServerResponse) error {
 	m.mu.Lock()
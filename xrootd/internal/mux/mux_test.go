@@ -262,3 +262,38 @@ func BenchmarkMux_SendData(b *testing.B) {
 	m.Unclaim(id)
 	close(done)
 }
+
+func TestMux_Abort(t *testing.T) {
+	m := New()
+	defer m.Close()
+
+	id, channel, err := m.Claim()
+	if err != nil {
+		t.Fatalf("could not claim: %v", err)
+	}
+
+	err = m.SendData(id, ServerResponse{Data: []byte{1, 2, 3}})
+	if err != nil {
+		t.Fatalf("could not SendData: %v", err)
+	}
+
+	want := ServerResponse{Err: fmt.Errorf("aborted")}
+	m.Abort(id, want)
+
+	err = m.SendData(id, ServerResponse{})
+	if err == nil {
+		t.Fatalf("should not be able to SendData after Abort")
+	}
+
+	got := <-channel
+	if !reflect.DeepEqual(got.Data, []byte{1, 2, 3}) {
+		t.Fatalf("invalid data\ngot = %v\nwant = %v", got.Data, []byte{1, 2, 3})
+	}
+	got = <-channel
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid data\ngot = %v\nwant = %v", got, want)
+	}
+	if _, more := <-channel; more {
+		t.Fatalf("channel should be closed after Abort")
+	}
+}
@@ -7,6 +7,7 @@ package xrootd // import "go-hep.org/x/hep/xrootd"
 import (
 	"context"
 	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +17,8 @@ import (
 
 	"go-hep.org/x/hep/xrootd/internal/xrdenc"
 	"go-hep.org/x/hep/xrootd/xrdproto"
+	"go-hep.org/x/hep/xrootd/xrdproto/bind"
+	"go-hep.org/x/hep/xrootd/xrdproto/chmod"
 	"go-hep.org/x/hep/xrootd/xrdproto/dirlist"
 	"go-hep.org/x/hep/xrootd/xrdproto/handshake"
 	"go-hep.org/x/hep/xrootd/xrdproto/login"
@@ -24,12 +27,15 @@ import (
 	"go-hep.org/x/hep/xrootd/xrdproto/open"
 	"go-hep.org/x/hep/xrootd/xrdproto/ping"
 	"go-hep.org/x/hep/xrootd/xrdproto/protocol"
+	"go-hep.org/x/hep/xrootd/xrdproto/query"
 	"go-hep.org/x/hep/xrootd/xrdproto/read"
+	"go-hep.org/x/hep/xrootd/xrdproto/readv"
 	"go-hep.org/x/hep/xrootd/xrdproto/rm"
 	"go-hep.org/x/hep/xrootd/xrdproto/rmdir"
 	"go-hep.org/x/hep/xrootd/xrdproto/stat"
 	xrdsync "go-hep.org/x/hep/xrootd/xrdproto/sync"
 	"go-hep.org/x/hep/xrootd/xrdproto/truncate"
+	"go-hep.org/x/hep/xrootd/xrdproto/verifyw"
 	"go-hep.org/x/hep/xrootd/xrdproto/write"
 	"go-hep.org/x/hep/xrootd/xrdproto/xrdclose"
 )
@@ -57,6 +63,24 @@ type Server struct {
 
 	connMu     sync.Mutex
 	activeConn map[net.Conn]struct{}
+
+	bindMu   sync.RWMutex
+	bindings map[[16]byte]*binding
+}
+
+// binding holds the data paths bound to a session with kXR_bind requests.
+// Data paths are additional connections of a client, used to exchange the
+// data of the read and write requests sent over the connection of the session.
+type binding struct {
+	mu    sync.RWMutex
+	paths map[xrdproto.PathID]net.Conn
+	done  chan struct{} // done is closed when the session is closed.
+}
+
+func (b *binding) path(id xrdproto.PathID) net.Conn {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.paths[id]
 }
 
 // NewServer creates a XRootD server which uses specified handler to handle requests
@@ -70,6 +94,7 @@ func NewServer(handler Handler, errorHandler ErrorHandler) *Server {
 		handler:      handler,
 		errorHandler: errorHandler,
 		activeConn:   make(map[net.Conn]struct{}),
+		bindings:     make(map[[16]byte]*binding),
 	}
 }
 
@@ -155,11 +180,25 @@ func (s *Server) handleConnection(conn net.Conn) {
 		return
 	}
 
+	b := &binding{
+		paths: make(map[xrdproto.PathID]net.Conn),
+		done:  make(chan struct{}),
+	}
+	s.bindMu.Lock()
+	s.bindings[sessionID] = b
+	s.bindMu.Unlock()
+	defer func() {
+		s.bindMu.Lock()
+		delete(s.bindings, sessionID)
+		s.bindMu.Unlock()
+		close(b.done)
+	}()
+
 	for {
 		// We are using conn for read access only in that place
 		// and only once at time for each conn, so no additional
 		// serialization is needed.
-		reqData, err := xrdproto.ReadRequest(conn)
+		reqData, err := s.readRequest(conn, b)
 		if err == io.EOF || err == io.ErrClosedPipe {
 			// Client closed the connection.
 			return
@@ -176,6 +215,17 @@ func (s *Server) handleConnection(conn net.Conn) {
 			return
 		}
 
+		if binary.BigEndian.Uint16(reqData[2:]) == bind.RequestID {
+			parent, ok := s.handleBind(conn, reqData)
+			if !ok {
+				continue
+			}
+			// The connection is now a data path of the parent session:
+			// its data is read and written by the parent session.
+			<-parent.done
+			return
+		}
+
 		// Performing a request may take some time so we are running it
 		// in the separate goroutine. We follow the XRootD protocol and
 		// write results back with StreamID provided in the request,
@@ -194,7 +244,16 @@ func (s *Server) handleConnection(conn net.Conn) {
 				resp, status = s.handleRequest(sessionID, reqHeader.RequestID, rBuffer)
 			}
 
-			if err := xrdproto.WriteResponse(conn, reqHeader.StreamID, status, resp); err != nil {
+			// The response to a read request is sent over the data path
+			// specified by the request, if any.
+			w := conn
+			if reqHeader.RequestID == read.RequestID && len(req) > readPathIDOffset {
+				if path := b.path(xrdproto.PathID(req[readPathIDOffset])); path != nil {
+					w = path
+				}
+			}
+
+			if err := xrdproto.WriteResponse(w, reqHeader.StreamID, status, resp); err != nil {
 				s.closedMu.RLock()
 				defer s.closedMu.RUnlock()
 				// TODO: wait for active requests to be processed while closing.
@@ -209,6 +268,96 @@ func (s *Server) handleConnection(conn net.Conn) {
 	}
 }
 
+const (
+	// readPathIDOffset is the offset of the path ID in a read request with arguments.
+	readPathIDOffset = xrdproto.RequestHeaderLength + 16 + 4
+	// writePathIDOffset is the offset of the path ID in a write request.
+	writePathIDOffset = xrdproto.RequestHeaderLength + 4 + 8
+)
+
+// readRequest reads a request from the connection of a session.
+// The data of a write request sent over a data path is read from that path.
+func (s *Server) readRequest(conn net.Conn, b *binding) ([]byte, error) {
+	// 16 is for the request options and 4 is for the data length
+	const requestSize = xrdproto.RequestHeaderLength + 16 + 4
+	request := make([]byte, requestSize)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return nil, err
+	}
+
+	dataLength := binary.BigEndian.Uint32(request[xrdproto.RequestHeaderLength+16:])
+	if dataLength == 0 {
+		return request, nil
+	}
+
+	var r io.Reader = conn
+	if binary.BigEndian.Uint16(request[2:]) == write.RequestID && request[writePathIDOffset] != 0 {
+		id := xrdproto.PathID(request[writePathIDOffset])
+		path := b.path(id)
+		if path == nil {
+			return nil, fmt.Errorf("xrootd: write request on unknown data path %d", id)
+		}
+		r = path
+	}
+
+	data := make([]byte, dataLength)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return append(request, data...), nil
+}
+
+// handleBind binds the connection to the session specified by the kXR_bind request,
+// and returns the binding of that session.
+func (s *Server) handleBind(conn net.Conn, req []byte) (*binding, bool) {
+	var (
+		header  xrdproto.RequestHeader
+		rBuffer = xrdenc.NewRBuffer(req)
+	)
+	if err := header.UnmarshalXrd(rBuffer); err != nil {
+		s.errorHandler(fmt.Errorf("could not unmarshal bind request: %w", err))
+		return nil, false
+	}
+
+	resp, status, b := s.bind(conn, rBuffer)
+	if err := xrdproto.WriteResponse(conn, header.StreamID, status, resp); err != nil {
+		s.errorHandler(fmt.Errorf("could not write bind response: %w", err))
+	}
+	return b, status == xrdproto.Ok
+}
+
+func (s *Server) bind(conn net.Conn, rBuffer *xrdenc.RBuffer) (xrdproto.Marshaler, xrdproto.ResponseStatus, *binding) {
+	var request bind.Request
+	if err := request.UnmarshalXrd(rBuffer); err != nil {
+		resp, status := newUnmarshalingErrorResponse(err)
+		return resp, status, nil
+	}
+
+	s.bindMu.RLock()
+	b, ok := s.bindings[request.SessionID]
+	s.bindMu.RUnlock()
+	if !ok {
+		return xrdproto.ServerError{
+			Code:    xrdproto.InvalidRequest,
+			Message: fmt.Sprintf("Invalid session ID: %v", request.SessionID),
+		}, xrdproto.Error, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id := xrdproto.PathID(1); id != 0; id++ {
+		if _, dup := b.paths[id]; !dup {
+			b.paths[id] = conn
+			return bind.Response{PathID: id}, xrdproto.Ok, b
+		}
+	}
+	return xrdproto.ServerError{
+		Code:    xrdproto.InvalidRequest,
+		Message: "data path limit exceeded",
+	}, xrdproto.Error, nil
+}
+
 func (s *Server) handleHandshake(conn net.Conn) error {
 	data := make([]byte, handshake.RequestLength)
 	if _, err := io.ReadFull(conn, data); err != nil {
@@ -283,6 +432,13 @@ func (s *Server) handleRequest(sessionID [16]byte, requestID uint16, rBuffer *xr
 			return newUnmarshalingErrorResponse(err)
 		}
 		return s.handler.Read(sessionID, &request)
+	case readv.RequestID:
+		var request readv.Request
+		err := request.UnmarshalXrd(rBuffer)
+		if err != nil {
+			return newUnmarshalingErrorResponse(err)
+		}
+		return s.handler.ReadV(sessionID, &request)
 	case write.RequestID:
 		var request write.Request
 		err := request.UnmarshalXrd(rBuffer)
@@ -290,6 +446,13 @@ func (s *Server) handleRequest(sessionID [16]byte, requestID uint16, rBuffer *xr
 			return newUnmarshalingErrorResponse(err)
 		}
 		return s.handler.Write(sessionID, &request)
+	case verifyw.RequestID:
+		var request verifyw.Request
+		err := request.UnmarshalXrd(rBuffer)
+		if err != nil {
+			return newUnmarshalingErrorResponse(err)
+		}
+		return s.handler.VerifyWrite(sessionID, &request)
 	case stat.RequestID:
 		var request stat.Request
 		err := request.UnmarshalXrd(rBuffer)
@@ -332,6 +495,20 @@ func (s *Server) handleRequest(sessionID [16]byte, requestID uint16, rBuffer *xr
 			return newUnmarshalingErrorResponse(err)
 		}
 		return s.handler.Ping(sessionID, &request)
+	case chmod.RequestID:
+		var request chmod.Request
+		err := request.UnmarshalXrd(rBuffer)
+		if err != nil {
+			return newUnmarshalingErrorResponse(err)
+		}
+		return s.handler.Chmod(sessionID, &request)
+	case query.RequestID:
+		var request query.Request
+		err := request.UnmarshalXrd(rBuffer)
+		if err != nil {
+			return newUnmarshalingErrorResponse(err)
+		}
+		return s.handler.Query(sessionID, &request)
 	case rm.RequestID:
 		var request rm.Request
 		err := request.UnmarshalXrd(rBuffer)
@@ -76,8 +76,15 @@ type pendingRequest struct {
 	PathID xrdproto.PathID
 }
 
+// newSession creates a new session to the server at address.
+// The connection is established and logged in within ctx, while its lifetime
+// is bound to the context of the client.
 func newSession(ctx context.Context, address, username, token string, client *Client) (*cliSession, error) {
-	ctx, cancel := context.WithCancel(ctx)
+	lifetime := ctx
+	if client != nil && client.ctx != nil {
+		lifetime = client.ctx
+	}
+	sctx, cancel := context.WithCancel(lifetime)
 
 	var d net.Dialer
 	addr := parseAddr(address)
@@ -88,7 +95,7 @@ func newSession(ctx context.Context, address, username, token string, client *Cl
 	}
 
 	sess := &cliSession{
-		ctx:       ctx,
+		ctx:       sctx,
 		cancel:    cancel,
 		conn:      conn,
 		mux:       mux.New(),
@@ -98,7 +105,10 @@ func newSession(ctx context.Context, address, username, token string, client *Cl
 		client:    client,
 		sessionID: addr,
 		addr:      addr,
-		maxSubs:   8, // TODO: The value of 8 is just a guess. Change it?
+		maxSubs:   8,
+	}
+	if client != nil {
+		sess.maxSubs = client.streams
 	}
 
 	go sess.consume()
@@ -166,24 +176,35 @@ func (sess *cliSession) Close() error {
 	return nil
 }
 
-// handleReadError handles an error encountered while reading and parsing a response.
-// If the current session is equal to the initial, the error is considered critical and handleReadError panics.
-// Otherwise, the current session is closed and all requests are redirected to the initial session.
+// handleReadError handles an error encountered while reading and parsing a response,
+// ie: the loss of the connection to the server.
+// The session is closed and removed from the client, so a new connection is
+// established (and logged in) by the next request to that server.
+// If the current session is equal to the initial, all pending requests fail
+// with a transient error, so they may be retried on the new connection.
+// Otherwise, all pending requests are redirected to the initial session.
 // See http://xrootd.org/doc/dev45/XRdv310.pdf, p. 11 for details.
 func (sess *cliSession) handleReadError(err error) {
-	if sess.sessionID == sess.client.initialSessionID {
-		// TODO: what should we do in case initial session is aborted?
-		// Should we try to reconnect to the server and re-issue all requests?
-		panic(err)
+	sess.client.dropSession(sess)
+
+	resp := mux.ServerResponse{Err: &transientError{
+		err: fmt.Errorf("xrootd: lost connection to %q: %w", sess.addr, err),
+	}}
+	if sess.client != nil && sess.sessionID != sess.client.initialSessionID {
+		resp = mux.ServerResponse{Redirection: &mux.Redirection{Addr: sess.client.initialSessionID}}
 	}
+
 	sess.mu.RLock()
-	resp := mux.ServerResponse{Redirection: &mux.Redirection{Addr: sess.client.initialSessionID}}
+	streamIDs := make([]xrdproto.StreamID, 0, len(sess.requests))
 	for streamID := range sess.requests {
-		err := sess.mux.SendData(streamID, resp)
-		// TODO: should we log error somehow? We have nowhere to send it.
-		_ = err
+		streamIDs = append(streamIDs, streamID)
 	}
 	sess.mu.RUnlock()
+
+	for _, streamID := range streamIDs {
+		sess.mux.Abort(streamID, resp)
+		sess.cleanupRequest(streamID)
+	}
 	sess.Close()
 }
 
@@ -205,9 +226,17 @@ func (sess *cliSession) handleWaitResponse(streamID xrdproto.StreamID, data []by
 	}
 
 	go func(req pendingRequest) {
-		time.Sleep(resp.Duration)
+		timer := time.NewTimer(resp.Duration)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-sess.ctx.Done():
+			return
+		}
 		if err := sess.writeRequest(req); err != nil {
-			resp := mux.ServerResponse{Err: fmt.Errorf("xrootd: could not send data to the server: %w", err)}
+			resp := mux.ServerResponse{Err: &transientError{
+				err: fmt.Errorf("xrootd: could not send data to the server: %w", err),
+			}}
 			err := sess.mux.SendData(streamID, resp)
 			// TODO: should we log error somehow? We have nowhere to send it.
 			_ = err
@@ -221,7 +250,6 @@ func (sess *cliSession) handleWaitResponse(streamID xrdproto.StreamID, data []by
 func (sess *cliSession) consume() {
 	var header xrdproto.ResponseHeader
 	var headerBytes = make([]byte, xrdproto.ResponseHeaderLength)
-	var resp mux.ServerResponse
 
 	for {
 		select {
@@ -229,8 +257,7 @@ func (sess *cliSession) consume() {
 			// TODO: Should wait for active requests to be completed?
 			return
 		default:
-			var err error
-			resp.Data, err = xrdproto.ReadResponseWithReuse(sess.conn, headerBytes, &header)
+			data, err := xrdproto.ReadResponseWithReuse(sess.conn, headerBytes, &header)
 			if err != nil {
 				if sess.ctx.Err() != nil {
 					// something happened to the context.
@@ -238,39 +265,78 @@ func (sess *cliSession) consume() {
 					return
 				}
 				sess.handleReadError(err)
-			}
-			resp.Err = nil
-			resp.Redirection = nil
-
-			switch header.Status {
-			case xrdproto.Error:
-				resp.Err = header.Error(resp.Data)
-			case xrdproto.Wait:
-				resp.Err = sess.handleWaitResponse(header.StreamID, resp.Data)
-				if resp.Err == nil {
-					continue
-				}
-			case xrdproto.Redirect:
-				resp.Redirection, resp.Err = mux.ParseRedirection(resp.Data)
+				return
 			}
 
-			if err := sess.mux.SendData(header.StreamID, resp); err != nil {
-				if sess.ctx.Err() != nil {
-					// something happened to the context.
-					// ignore this error.
+			if header.Status == xrdproto.Attn {
+				var ok bool
+				header, data, ok = asyncResponse(data)
+				if !ok {
+					// other asynchronous actions are not supported.
 					continue
 				}
-				panic(err)
-				// TODO: should we just ignore responses to unclaimed stream IDs?
 			}
 
-			if header.Status != xrdproto.OkSoFar {
-				sess.cleanupRequest(header.StreamID)
-			}
+			sess.dispatch(header, data)
 		}
 	}
 }
 
+// dispatch dispatches the response with the given header and data
+// to the request it answers.
+func (sess *cliSession) dispatch(header xrdproto.ResponseHeader, data []byte) {
+	resp := mux.ServerResponse{Data: data}
+
+	switch header.Status {
+	case xrdproto.Error:
+		resp.Err = header.Error(data)
+	case xrdproto.Wait:
+		resp.Err = sess.handleWaitResponse(header.StreamID, data)
+		if resp.Err == nil {
+			return
+		}
+	case xrdproto.WaitResp:
+		// the response will be sent later, as part of an Attn response.
+		return
+	case xrdproto.Redirect:
+		resp.Redirection, resp.Err = mux.ParseRedirection(data)
+	case xrdproto.AuthMore:
+		resp.Err = &authMoreError{data: data}
+	}
+
+	if err := sess.mux.SendData(header.StreamID, resp); err != nil {
+		// the request was abandoned: drop its response.
+		return
+	}
+
+	if header.Status != xrdproto.OkSoFar {
+		sess.cleanupRequest(header.StreamID)
+	}
+}
+
+// asyncResponse extracts the header and data of the deferred response held
+// by the data of an Attn response.
+// asyncResponse returns false if the Attn response does not hold a response.
+func asyncResponse(data []byte) (xrdproto.ResponseHeader, []byte, bool) {
+	var header xrdproto.ResponseHeader
+	if len(data) < 8+xrdproto.ResponseHeaderLength {
+		return header, nil, false
+	}
+	rBuffer := xrdenc.NewRBuffer(data)
+	if rBuffer.ReadI32() != xrdproto.AttnAsyncResponse {
+		return header, nil, false
+	}
+	rBuffer.Skip(4)
+	if err := header.UnmarshalXrd(rBuffer); err != nil {
+		return header, nil, false
+	}
+	data = rBuffer.Bytes()
+	if n := int(header.DataLength); n >= 0 && n < len(data) {
+		data = data[:n]
+	}
+	return header, data, true
+}
+
 func (sess *cliSession) cleanupRequest(streamID xrdproto.StreamID) {
 	sess.mux.Unclaim(streamID)
 	sess.mu.Lock()
@@ -311,7 +377,10 @@ func (sess *cliSession) send(ctx context.Context, streamID xrdproto.StreamID, re
 	sess.mu.Unlock()
 
 	if err := sess.writeRequest(request); err != nil {
-		return nil, nil, err
+		sess.cleanupRequest(streamID)
+		return nil, nil, &transientError{
+			err: fmt.Errorf("xrootd: could not send request to %q: %w", sess.addr, err),
+		}
 	}
 
 	var data []byte
@@ -333,9 +402,13 @@ func (sess *cliSession) send(ctx context.Context, streamID xrdproto.StreamID, re
 
 			data = append(data, resp.Data...)
 		case <-ctx.Done():
-			if err := ctx.Err(); err != nil {
-				return nil, nil, err
-			}
+			// the request is abandoned: drain its responses until the server
+			// completes it, so its stream ID is not reused in the meantime.
+			go func() {
+				for range responseChannel {
+				}
+			}()
+			return nil, nil, ctx.Err()
 		}
 	}
 }
@@ -380,7 +453,9 @@ func (sess *cliSession) Send(ctx context.Context, resp xrdproto.Response, req xr
 		}
 	}
 
+	sent := len(data) + len(pathData)
 	data, redirection, err := sess.send(ctx, streamID, responseChannel, data, pathData, pathID)
+	statsFrom(ctx).add(sent, len(data))
 	if err != nil || redirection != nil || resp == nil {
 		return redirection, err
 	}
@@ -446,7 +521,7 @@ func (sess *cliSession) sign(streamID xrdproto.StreamID, requestID uint16, data
 }
 
 func newSubSession(ctx context.Context, parent *cliSession) (*cliSession, error) {
-	ctx, cancel := context.WithCancel(ctx)
+	sctx, cancel := context.WithCancel(parent.ctx)
 
 	var d net.Dialer
 	conn, err := d.DialContext(ctx, "tcp", parent.addr)
@@ -456,7 +531,7 @@ func newSubSession(ctx context.Context, parent *cliSession) (*cliSession, error)
 	}
 
 	sess := &cliSession{
-		ctx:       ctx,
+		ctx:       sctx,
 		cancel:    cancel,
 		conn:      conn,
 		mux:       parent.mux,
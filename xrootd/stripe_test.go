@@ -0,0 +1,93 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xrootd // import "go-hep.org/x/hep/xrootd"
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-hep.org/x/hep/xrootd/xrdfs"
+)
+
+func TestFile_Stripe(t *testing.T) {
+	dir := t.TempDir()
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	srv := NewServer(NewFSHandler(dir), func(err error) {
+		t.Errorf("server error: %v", err)
+	})
+	go srv.Serve(l)
+	defer srv.Shutdown(context.Background())
+
+	const streams = 3
+	cli, err := NewClient(context.Background(), l.Addr().String(), "gopher", WithStreams(streams, 1000))
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	want := make([]byte, 10500)
+	rand.New(rand.NewSource(1234)).Read(want)
+
+	f, err := cli.FS().Open(context.Background(), "file.dat", xrdfs.OpenModeOwnerRead|xrdfs.OpenModeOwnerWrite, xrdfs.OpenOptionsNew|xrdfs.OpenOptionsOpenUpdate)
+	if err != nil {
+		t.Fatalf("could not open file: %v", err)
+	}
+	defer f.Close(context.Background())
+
+	_, err = f.WriteAt(want, 0)
+	if err != nil {
+		t.Fatalf("could not write file: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "file.dat"))
+	if err != nil {
+		t.Fatalf("could not read written file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("invalid written data")
+	}
+
+	for _, tc := range []struct {
+		name string
+		off  int64
+		len  int
+		n    int
+	}{
+		{name: "whole", len: len(want), n: len(want)},
+		{name: "unaligned", off: 123, len: 5678, n: 5678},
+		{name: "past-eof", off: 2500, len: 10000, n: len(want) - 2500},
+		{name: "at-eof", off: int64(len(want)), len: 3000, n: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := make([]byte, tc.len)
+			n, err := f.ReadAt(buf, tc.off)
+			if err != nil {
+				t.Fatalf("could not read file: %v", err)
+			}
+			if n != tc.n {
+				t.Fatalf("invalid number of bytes read: got=%d, want=%d", n, tc.n)
+			}
+			if !bytes.Equal(buf[:n], want[tc.off:tc.off+int64(n)]) {
+				t.Fatalf("invalid read data")
+			}
+		})
+	}
+
+	sess := cli.session(cli.initialSessionID)
+	sess.subsMu.RLock()
+	n := len(sess.subs)
+	sess.subsMu.RUnlock()
+	if n == 0 || n > streams {
+		t.Fatalf("invalid number of data streams: got=%d, want in [1, %d]", n, streams)
+	}
+}
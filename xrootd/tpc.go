@@ -0,0 +1,146 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xrootd // import "go-hep.org/x/hep/xrootd"
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"go-hep.org/x/hep/xrootd/xrdfs"
+	"go-hep.org/x/hep/xrootd/xrdproto/auth/gsi"
+)
+
+// tpcPollInterval is the interval between two polls of the progress of a
+// third-party copy.
+const tpcPollInterval = 2500 * time.Millisecond
+
+// ThirdPartyCopy copies the file srcPath, served by the server of the src client,
+// to the file dstPath, served by the server of the dst client.
+// The data is transferred directly from the source to the destination server,
+// instead of through the client, using the native XRootD third-party copy (TPC)
+// protocol: the client opens the source and the destination files with the
+// same random key, and asks the destination server to pull the data from the
+// source server.
+// When the dst client delegates its gsi credentials (see gsi.Auth.Delegate),
+// the destination server is asked to use these delegated credentials to
+// access the source server.
+//
+// If progress is not nil, it is called periodically with the number of bytes
+// copied so far.
+// An existing dstPath is overwritten.
+func ThirdPartyCopy(ctx context.Context, dst *Client, dstPath string, src *Client, srcPath string, progress func(n int64)) error {
+	key, err := tpcKey()
+	if err != nil {
+		return fmt.Errorf("xrootd: could not generate third-party copy key: %w", err)
+	}
+
+	dstHost := dst.initialSessionID
+	if host, _, err := net.SplitHostPort(dstHost); err == nil {
+		dstHost = host
+	}
+
+	fsrc, err := src.FS().Open(ctx,
+		fmt.Sprintf("%s?tpc.key=%s&tpc.dst=%s&tpc.stage=copy", srcPath, key, dstHost),
+		xrdfs.OpenModeOwnerRead, xrdfs.OpenOptionsOpenRead,
+	)
+	if err != nil {
+		return fmt.Errorf("xrootd: could not open third-party copy source %q: %w", srcPath, err)
+	}
+	defer fsrc.Close(ctx)
+
+	// the destination server must contact the server actually serving the
+	// source file, after redirections.
+	srcHost := fsrc.(*file).sessID()
+	if src.username != "" {
+		srcHost = src.username + "@" + srcHost
+	}
+
+	dlgon := 0
+	if dst.delegates() {
+		dlgon = 1
+	}
+
+	fdst, err := dst.FS().Open(ctx,
+		fmt.Sprintf(
+			"%s?tpc.key=%s&tpc.src=%s&tpc.lfn=%s&tpc.stage=copy&tpc.dlgon=%d&tpc.spr=root&tpc.tpr=root",
+			dstPath, key, srcHost, url.QueryEscape(srcPath), dlgon,
+		),
+		xrdfs.OpenModeOwnerRead|xrdfs.OpenModeOwnerWrite|xrdfs.OpenModeGroupRead|xrdfs.OpenModeOtherRead,
+		xrdfs.OpenOptionsOpenUpdate|xrdfs.OpenOptionsDelete|xrdfs.OpenOptionsMkPath,
+	)
+	if err != nil {
+		return fmt.Errorf("xrootd: could not open third-party copy destination %q: %w", dstPath, err)
+	}
+
+	err = tpcRun(ctx, fdst, progress)
+	if err != nil {
+		_ = fdst.Close(ctx)
+		return fmt.Errorf("xrootd: could not copy %q to %q: %w", srcPath, dstPath, err)
+	}
+
+	err = fdst.Close(ctx)
+	if err != nil {
+		return fmt.Errorf("xrootd: could not close third-party copy destination %q: %w", dstPath, err)
+	}
+	return nil
+}
+
+// tpcRun runs the third-party copy to the destination file f, reporting its
+// progress if progress is not nil.
+// The copy is started by a sync request on the destination file, which
+// is answered once the copy is completed.
+func tpcRun(ctx context.Context, f xrdfs.File, progress func(n int64)) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f.Sync(ctx)
+	}()
+
+	ticker := time.NewTicker(tpcPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+
+		case <-ticker.C:
+			if progress == nil {
+				continue
+			}
+			fi, err := f.Stat(ctx)
+			if err != nil {
+				// the progress is only informative.
+				continue
+			}
+			progress(fi.EntrySize)
+		}
+	}
+}
+
+// delegates returns whether the client delegates its credentials to the
+// servers it authenticates with.
+func (client *Client) delegates() bool {
+	for _, a := range client.auths {
+		if a, ok := a.(*gsi.Auth); ok && a.Delegate {
+			return true
+		}
+	}
+	return false
+}
+
+// tpcKey returns a new random third-party copy key.
+func tpcKey() (string, error) {
+	var key [16]byte
+	_, err := rand.Read(key[:])
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key[:]), nil
+}
@@ -0,0 +1,164 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xrootd // import "go-hep.org/x/hep/xrootd"
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-hep.org/x/hep/xrootd/xrdfs"
+	"go-hep.org/x/hep/xrootd/xrdproto"
+	"go-hep.org/x/hep/xrootd/xrdproto/auth"
+	"go-hep.org/x/hep/xrootd/xrdproto/auth/gsi"
+	"go-hep.org/x/hep/xrootd/xrdproto/open"
+	"go-hep.org/x/hep/xrootd/xrdproto/sync"
+	"go-hep.org/x/hep/xrootd/xrdproto/xrdclose"
+)
+
+func TestThirdPartyCopy_Mock(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		src      string
+		delegate bool
+	}{
+		{name: "default", src: "/tmp/src"},
+		{name: "escape", src: "/tmp/src&tpc.key=x y"},
+		{name: "delegate", src: "/tmp/src", delegate: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			testThirdPartyCopyMock(t, tc.src, tc.delegate)
+		})
+	}
+}
+
+func testThirdPartyCopyMock(t *testing.T, src string, delegate bool) {
+	var (
+		srcHandle = xrdfs.FileHandle{1, 2, 3, 4}
+		dstHandle = xrdfs.FileHandle{5, 6, 7, 8}
+		dlgon     = "0"
+	)
+	if delegate {
+		dlgon = "1"
+	}
+
+	serverFunc := func(cancel func(), conn net.Conn) {
+		var (
+			gotOpen open.Request
+			key     string
+		)
+		for i, tc := range []struct {
+			path string
+			want map[string]string
+		}{
+			{
+				path: strings.Split(src, "?")[0],
+				want: map[string]string{"tpc.dst": "test.org", "tpc.stage": "copy"},
+			},
+			{
+				path: "/tmp/dst",
+				want: map[string]string{"tpc.src": "test.org:1234", "tpc.lfn": src, "tpc.stage": "copy", "tpc.dlgon": dlgon},
+			},
+		} {
+			data, err := xrdproto.ReadRequest(conn)
+			if err != nil {
+				cancel()
+				t.Fatalf("could not read request: %v", err)
+			}
+
+			gotHeader, err := unmarshalRequest(data, &gotOpen)
+			if err != nil {
+				cancel()
+				t.Fatalf("could not unmarshal request: %v", err)
+			}
+
+			if got := strings.Split(gotOpen.Path, "?")[0]; got != tc.path {
+				cancel()
+				t.Fatalf("invalid path: got=%q, want=%q", got, tc.path)
+			}
+			opaque, err := url.ParseQuery(gotOpen.Opaque())
+			if err != nil {
+				cancel()
+				t.Fatalf("could not parse opaque data: %v", err)
+			}
+			for k, v := range tc.want {
+				if got := opaque.Get(k); got != v {
+					cancel()
+					t.Fatalf("invalid %s: got=%q, want=%q", k, got, v)
+				}
+			}
+			switch {
+			case i == 0:
+				key = opaque.Get("tpc.key")
+			case opaque.Get("tpc.key") != key:
+				cancel()
+				t.Fatalf("invalid tpc.key: got=%q, want=%q", opaque.Get("tpc.key"), key)
+			}
+
+			handle := srcHandle
+			if i == 1 {
+				handle = dstHandle
+			}
+			err = xrdproto.WriteResponse(conn, gotHeader.StreamID, xrdproto.Ok, open.Response{FileHandle: handle})
+			if err != nil {
+				cancel()
+				t.Fatalf("could not write response: %v", err)
+			}
+		}
+		if key == "" {
+			cancel()
+			t.Fatalf("missing tpc.key")
+		}
+
+		for _, want := range []xrdproto.Request{
+			&sync.Request{Handle: dstHandle},
+			&xrdclose.Request{Handle: dstHandle},
+			&xrdclose.Request{Handle: srcHandle},
+		} {
+			data, err := xrdproto.ReadRequest(conn)
+			if err != nil {
+				cancel()
+				t.Fatalf("could not read request: %v", err)
+			}
+
+			got := reflect.New(reflect.TypeOf(want).Elem()).Interface().(xrdproto.Request)
+			gotHeader, err := unmarshalRequest(data, got)
+			if err != nil {
+				cancel()
+				t.Fatalf("could not unmarshal request: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				cancel()
+				t.Fatalf("request info does not match:\ngot = %v\nwant = %v", got, want)
+			}
+
+			err = xrdproto.WriteResponse(conn, gotHeader.StreamID, xrdproto.Ok, nil)
+			if err != nil {
+				cancel()
+				t.Fatalf("could not write response: %v", err)
+			}
+		}
+	}
+
+	clientFunc := func(cancel func(), client *Client) {
+		if delegate {
+			client.auths = map[string]auth.Auther{"gsi": &gsi.Auth{Delegate: true}}
+		}
+		err := ThirdPartyCopy(context.Background(), client, "/tmp/dst", client, src, nil)
+		if err != nil {
+			t.Fatalf("invalid third-party copy: %v", err)
+		}
+	}
+
+	testClientWithMockServer(serverFunc, clientFunc)
+}
@@ -0,0 +1,117 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xrootd // import "go-hep.org/x/hep/xrootd"
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go-hep.org/x/hep/xrootd/xrdproto"
+)
+
+// RequestInfo describes a request sent by a Client, once it has completed.
+type RequestInfo struct {
+	Request       xrdproto.Request // Request is the request sent to the servers.
+	Server        string           // Server is the address of the server that handled the last attempt of the request.
+	Start         time.Time        // Start is the time at which the request was started.
+	Duration      time.Duration    // Duration is the latency of the request, including retries and redirections.
+	BytesSent     int64            // BytesSent is the number of bytes sent to the servers, headers included.
+	BytesReceived int64            // BytesReceived is the number of bytes of the responses received from the servers.
+	Redirections  []string         // Redirections lists the addresses of the servers the request was redirected to.
+	Retries       int              // Retries is the number of times the request was retried after a transient failure.
+	Err           error            // Err is the error returned by the request, if any.
+}
+
+// Tracer traces the requests sent by a Client.
+//
+// StartRequest is called before a request is sent.
+// The returned context is used to send the request, and is then passed to
+// EndRequest with the description of the completed request.
+// This allows to map requests onto the spans of a distributed tracing
+// system, such as OpenTelemetry.
+//
+// Tracers must be safe for concurrent use by multiple goroutines.
+type Tracer interface {
+	StartRequest(ctx context.Context, req xrdproto.Request) context.Context
+	EndRequest(ctx context.Context, info RequestInfo)
+}
+
+// TracerFunc is a Tracer that calls the function with the description of
+// each completed request, e.g. to collect metrics.
+type TracerFunc func(ctx context.Context, info RequestInfo)
+
+// StartRequest implements Tracer.
+func (f TracerFunc) StartRequest(ctx context.Context, req xrdproto.Request) context.Context {
+	return ctx
+}
+
+// EndRequest implements Tracer.
+func (f TracerFunc) EndRequest(ctx context.Context, info RequestInfo) {
+	f(ctx, info)
+}
+
+// WithTracer sets the tracer of the requests sent by the XRootD client.
+func WithTracer(t Tracer) Option {
+	return func(client *Client) error {
+		client.tracer = t
+		return nil
+	}
+}
+
+// requestStats collects the statistics of a request being traced.
+type requestStats struct {
+	sent         int64 // sent is the number of bytes sent, accessed atomically.
+	recv         int64 // recv is the number of bytes received, accessed atomically.
+	redirections []string
+}
+
+type requestStatsKey struct{}
+
+// statsFrom returns the statistics of the request traced with ctx, or nil.
+func statsFrom(ctx context.Context) *requestStats {
+	stats, _ := ctx.Value(requestStatsKey{}).(*requestStats)
+	return stats
+}
+
+func (stats *requestStats) add(sent, recv int) {
+	if stats == nil {
+		return
+	}
+	atomic.AddInt64(&stats.sent, int64(sent))
+	atomic.AddInt64(&stats.recv, int64(recv))
+}
+
+func (stats *requestStats) redirect(addr string) {
+	if stats == nil {
+		return
+	}
+	stats.redirections = append(stats.redirections, addr)
+}
+
+// trace sends the request with send, and reports it to the tracer of the client.
+func (client *Client) trace(ctx context.Context, req xrdproto.Request, send func(ctx context.Context) (string, int, error)) (string, error) {
+	if client.tracer == nil {
+		id, _, err := send(ctx)
+		return id, err
+	}
+
+	ctx = client.tracer.StartRequest(ctx, req)
+	stats := new(requestStats)
+	start := time.Now()
+	id, retries, err := send(context.WithValue(ctx, requestStatsKey{}, stats))
+	client.tracer.EndRequest(ctx, RequestInfo{
+		Request:       req,
+		Server:        id,
+		Start:         start,
+		Duration:      time.Since(start),
+		BytesSent:     atomic.LoadInt64(&stats.sent),
+		BytesReceived: atomic.LoadInt64(&stats.recv),
+		Redirections:  stats.redirections,
+		Retries:       retries,
+		Err:           err,
+	})
+	return id, err
+}
@@ -0,0 +1,141 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xrootd_test // import "go-hep.org/x/hep/xrootd"
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"go-hep.org/x/hep/xrootd"
+	"go-hep.org/x/hep/xrootd/xrdfs"
+	"go-hep.org/x/hep/xrootd/xrdproto"
+	"go-hep.org/x/hep/xrootd/xrdproto/ping"
+	"go-hep.org/x/hep/xrootd/xrdproto/read"
+	"go-hep.org/x/hep/xrootd/xrdproto/write"
+)
+
+type ctxKey struct{}
+
+type testTracer struct {
+	mu    sync.Mutex
+	infos map[uint16][]xrootd.RequestInfo
+}
+
+func (tr *testTracer) StartRequest(ctx context.Context, req xrdproto.Request) context.Context {
+	return context.WithValue(ctx, ctxKey{}, req.ReqID())
+}
+
+func (tr *testTracer) EndRequest(ctx context.Context, info xrootd.RequestInfo) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if id := ctx.Value(ctxKey{}); id != info.Request.ReqID() {
+		panic("invalid tracing context")
+	}
+	tr.infos[info.Request.ReqID()] = append(tr.infos[info.Request.ReqID()], info)
+}
+
+func (tr *testTracer) last(id uint16) xrootd.RequestInfo {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	infos := tr.infos[id]
+	if len(infos) == 0 {
+		return xrootd.RequestInfo{}
+	}
+	return infos[len(infos)-1]
+}
+
+func TestClient_Tracer(t *testing.T) {
+	srv, addr, baseDir, err := createServer(func(err error) {
+		t.Logf("server error: %v", err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(baseDir)
+	defer func() {
+		_ = srv.Shutdown(context.Background())
+	}()
+
+	err = os.WriteFile(path.Join(baseDir, "file1.txt"), nil, 0644)
+	if err != nil {
+		t.Fatalf("could not create test file: %v", err)
+	}
+
+	tracer := &testTracer{infos: make(map[uint16][]xrootd.RequestInfo)}
+	cli, err := xrootd.NewClient(
+		context.Background(), addr, "gopher",
+		xrootd.WithTracer(tracer),
+		xrootd.WithRetries(2, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	f, err := cli.FS().Open(context.Background(), "file1.txt", xrdfs.OpenModeOwnerWrite, xrdfs.OpenOptionsOpenUpdate)
+	if err != nil {
+		t.Fatalf("could not call Open: %v", err)
+	}
+	defer f.Close(context.Background())
+
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	_, err = f.WriteAt(data, 0)
+	if err != nil {
+		t.Fatalf("could not call WriteAt: %v", err)
+	}
+
+	info := tracer.last(write.RequestID)
+	// the request header and the data, and possibly the binding of a new
+	// data stream to the session.
+	if got, want := info.BytesSent, int64(len(data)+24); got < want {
+		t.Fatalf("invalid number of bytes sent: got=%d, want=%d", got, want)
+	}
+
+	buf := make([]byte, len(data)+10)
+	n, err := f.ReadAt(buf, 0)
+	if err != nil {
+		t.Fatalf("could not call ReadAt: %v", err)
+	}
+	if !bytes.Equal(buf[:n], data) {
+		t.Fatalf("invalid data")
+	}
+
+	info = tracer.last(read.RequestID)
+	if got, want := info.BytesReceived, int64(len(data)); got < want {
+		t.Fatalf("invalid number of bytes received: got=%d, want=%d", got, want)
+	}
+	if got, want := info.Server, addr; got != want {
+		t.Fatalf("invalid server: got=%q, want=%q", got, want)
+	}
+	if info.Retries != 0 || info.Redirections != nil || info.Err != nil {
+		t.Fatalf("invalid request info: %+v", info)
+	}
+	if info.Start.IsZero() || info.Duration <= 0 {
+		t.Fatalf("invalid request timing: start=%v, duration=%v", info.Start, info.Duration)
+	}
+
+	err = srv.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("could not shutdown server: %v", err)
+	}
+
+	_, err = cli.Send(context.Background(), nil, &ping.Request{})
+	if err == nil {
+		t.Fatalf("expected an error after the server was shut down")
+	}
+
+	info = tracer.last(ping.RequestID)
+	if got, want := info.Retries, 2; got != want {
+		t.Fatalf("invalid number of retries: got=%d, want=%d", got, want)
+	}
+	if info.Err != err {
+		t.Fatalf("invalid error: got=%v, want=%v", info.Err, err)
+	}
+}
@@ -39,6 +39,12 @@ type File interface {
 	// ReadAtContext reads len(p) bytes into p starting at offset off.
 	ReadAtContext(ctx context.Context, p []byte, off int64) (n int, err error)
 
+	// ReadV reads the segments segs of the file using vectored read requests,
+	// fetching up to 1024 segments in a single round trip.
+	// The Data of a segment is shortened to the number of bytes read
+	// when the segment extends past the end of the file.
+	ReadV(ctx context.Context, segs []Segment) error
+
 	// WriteAtContext writes len(p) bytes from p to the file at offset off.
 	WriteAtContext(ctx context.Context, p []byte, off int64) error
 
@@ -60,6 +66,12 @@ type File interface {
 	VerifyWriteAt(ctx context.Context, p []byte, off int64) error
 }
 
+// Segment is a segment of a file, as read by File.ReadV.
+type Segment struct {
+	Offset int64  // offset of the segment in the file
+	Data   []byte // buffer receiving the len(Data) bytes of the segment
+}
+
 // FileHandle is the file handle, which should be treated as opaque data.
 type FileHandle [4]byte
 
@@ -0,0 +1,241 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+
+// Package xrdfuse exposes a remote xrootd namespace as a read-only FUSE
+// filesystem.
+//
+// Directories are listed with kXR_dirlist, attributes are retrieved with
+// kXR_stat and file contents are read with kXR_read, so that unmodified
+// tools may browse remote storage through a local mount point.
+package xrdfuse // import "go-hep.org/x/hep/xrootd/xrdfuse"
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"go-hep.org/x/hep/xrootd/xrdfs"
+	"go-hep.org/x/hep/xrootd/xrdproto"
+)
+
+// Mount mounts the remote directory root of the provided xrootd filesystem
+// at the local directory dir.
+// The returned server should be unmounted once done with it.
+func Mount(dir string, xfs xrdfs.FileSystem, root string, opts *fs.Options) (*fuse.Server, error) {
+	if opts == nil {
+		opts = &fs.Options{}
+	}
+	timeout := 1 * time.Second
+	if opts.EntryTimeout == nil {
+		opts.EntryTimeout = &timeout
+	}
+	if opts.AttrTimeout == nil {
+		opts.AttrTimeout = &timeout
+	}
+	opts.MountOptions.Options = append(opts.MountOptions.Options, "ro")
+	if opts.MountOptions.FsName == "" {
+		opts.MountOptions.FsName = "xrootd"
+	}
+	if opts.MountOptions.Name == "" {
+		opts.MountOptions.Name = "xrdfuse"
+	}
+
+	return fs.Mount(dir, NewRoot(xfs, root), opts)
+}
+
+// NewRoot returns the root node of a read-only FUSE filesystem backed by
+// the remote directory root of the provided xrootd filesystem.
+func NewRoot(xfs xrdfs.FileSystem, root string) fs.InodeEmbedder {
+	return &node{fs: xfs, path: path.Clean("/" + root)}
+}
+
+// node is a file or a directory of the remote filesystem.
+type node struct {
+	fs.Inode
+
+	fs   xrdfs.FileSystem
+	path string
+}
+
+var (
+	_ fs.NodeGetattrer = (*node)(nil)
+	_ fs.NodeLookuper  = (*node)(nil)
+	_ fs.NodeReaddirer = (*node)(nil)
+	_ fs.NodeOpener    = (*node)(nil)
+	_ fs.NodeReader    = (*node)(nil)
+	_ fs.NodeStatfser  = (*node)(nil)
+)
+
+// Getattr implements fs.NodeGetattrer.
+func (n *node) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	if fh, ok := fh.(*handle); ok {
+		fi, err := fh.f.Stat(ctx)
+		if err != nil {
+			return errno(err)
+		}
+		fillAttr(&out.Attr, fi)
+		return fs.OK
+	}
+
+	fi, err := n.fs.Stat(ctx, n.path)
+	if err != nil {
+		return errno(err)
+	}
+	fillAttr(&out.Attr, fi)
+	return fs.OK
+}
+
+// Lookup implements fs.NodeLookuper.
+func (n *node) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	name = path.Join(n.path, name)
+	fi, err := n.fs.Stat(ctx, name)
+	if err != nil {
+		return nil, errno(err)
+	}
+	fillAttr(&out.Attr, fi)
+
+	child := &node{fs: n.fs, path: name}
+	return n.NewInode(ctx, child, fs.StableAttr{Mode: out.Attr.Mode & syscall.S_IFMT}), fs.OK
+}
+
+// Readdir implements fs.NodeReaddirer.
+func (n *node) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	ents, err := n.fs.Dirlist(ctx, n.path)
+	if err != nil {
+		return nil, errno(err)
+	}
+
+	list := make([]fuse.DirEntry, 0, len(ents))
+	for _, e := range ents {
+		list = append(list, fuse.DirEntry{
+			Name: e.Name(),
+			Mode: mode(e) & syscall.S_IFMT,
+		})
+	}
+	return fs.NewListDirStream(list), fs.OK
+}
+
+// Open implements fs.NodeOpener.
+func (n *node) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if flags&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_APPEND|syscall.O_TRUNC) != 0 {
+		return nil, 0, syscall.EROFS
+	}
+
+	f, err := n.fs.Open(ctx, n.path, xrdfs.OpenModeOwnerRead, xrdfs.OpenOptionsOpenRead)
+	if err != nil {
+		return nil, 0, errno(err)
+	}
+
+	// remote files are not expected to change while being read:
+	// let the kernel cache their content.
+	return &handle{f: f}, fuse.FOPEN_KEEP_CACHE, fs.OK
+}
+
+// Read implements fs.NodeReader.
+func (n *node) Read(ctx context.Context, fh fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	h, ok := fh.(*handle)
+	if !ok {
+		return nil, syscall.EBADF
+	}
+	return h.Read(ctx, dest, off)
+}
+
+// Statfs implements fs.NodeStatfser.
+func (n *node) Statfs(ctx context.Context, out *fuse.StatfsOut) syscall.Errno {
+	// xrootd servers do not provide the number of blocks or inodes of their
+	// backing storage: report an empty filesystem rather than an error, so
+	// tools like df still work.
+	*out = fuse.StatfsOut{Bsize: 4096, NameLen: 255}
+	return fs.OK
+}
+
+// handle is an opened remote file.
+type handle struct {
+	f xrdfs.File
+}
+
+var (
+	_ fs.FileReader   = (*handle)(nil)
+	_ fs.FileReleaser = (*handle)(nil)
+)
+
+// Read implements fs.FileReader.
+func (h *handle) Read(ctx context.Context, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	n, err := h.f.ReadAtContext(ctx, dest, off)
+	if err != nil {
+		return nil, errno(err)
+	}
+	return fuse.ReadResultData(dest[:n]), fs.OK
+}
+
+// Release implements fs.FileReleaser.
+func (h *handle) Release(ctx context.Context) syscall.Errno {
+	return errno(h.f.Close(ctx))
+}
+
+func fillAttr(out *fuse.Attr, fi xrdfs.EntryStat) {
+	out.Mode = mode(fi)
+	out.Size = uint64(fi.Size())
+	out.Blocks = (out.Size + 511) / 512
+	out.Mtime = uint64(fi.Mtime)
+	out.Atime = out.Mtime
+	out.Ctime = out.Mtime
+	out.Nlink = 1
+}
+
+// mode returns the FUSE mode of a remote entry, without any write permission.
+func mode(fi xrdfs.EntryStat) uint32 {
+	var mode uint32
+	switch {
+	case fi.IsDir():
+		mode = syscall.S_IFDIR | 0111
+	case fi.IsOther():
+		mode = syscall.S_IFIFO
+	default:
+		mode = syscall.S_IFREG
+		if fi.IsExecutable() {
+			mode |= 0111
+		}
+	}
+	if fi.IsReadable() {
+		mode |= 0444
+	}
+	return mode
+}
+
+// errno converts an error returned by an xrootd server to a syscall.Errno.
+func errno(err error) syscall.Errno {
+	if err == nil {
+		return fs.OK
+	}
+
+	var serr xrdproto.ServerError
+	if errors.As(err, &serr) {
+		switch serr.Code {
+		case xrdproto.NotFound:
+			return syscall.ENOENT
+		case xrdproto.NotAuthorized:
+			return syscall.EACCES
+		case xrdproto.Unsupported:
+			return syscall.ENOTSUP
+		}
+	}
+
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return syscall.ENOENT
+	case errors.Is(err, os.ErrPermission):
+		return syscall.EACCES
+	case errors.Is(err, context.Canceled):
+		return syscall.EINTR
+	}
+	return syscall.EIO
+}
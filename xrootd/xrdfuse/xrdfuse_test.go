@@ -0,0 +1,128 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+
+package xrdfuse_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"syscall"
+	"testing"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"go-hep.org/x/hep/xrootd"
+	"go-hep.org/x/hep/xrootd/xrdfuse"
+)
+
+func TestMount(t *testing.T) {
+	baseDir := t.TempDir()
+	for name, data := range map[string]string{
+		"dir/file1.txt":     "hello",
+		"dir/sub/file2.txt": "hello from a subdirectory",
+		"file3.txt":         "not exported",
+	} {
+		fname := filepath.Join(baseDir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(fname), 0755)
+		if err != nil {
+			t.Fatalf("could not create test dir: %v", err)
+		}
+		err = os.WriteFile(fname, []byte(data), 0644)
+		if err != nil {
+			t.Fatalf("could not create test file: %v", err)
+		}
+	}
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	srv := xrootd.NewServer(xrootd.NewFSHandler(baseDir), func(err error) {
+		t.Logf("server error: %v", err)
+	})
+	go srv.Serve(l)
+	defer srv.Shutdown(context.Background())
+
+	cli, err := xrootd.NewClient(context.Background(), l.Addr().String(), "gopher")
+	if err != nil {
+		t.Fatalf("could not create client: %v", err)
+	}
+	defer cli.Close()
+
+	mnt := t.TempDir()
+	fsrv, err := xrdfuse.Mount(mnt, cli.FS(), "/dir", &fs.Options{
+		MountOptions: fuse.MountOptions{DirectMount: true},
+	})
+	if err != nil {
+		t.Skipf("could not mount FUSE filesystem: %v", err)
+	}
+	defer fsrv.Unmount()
+
+	ents, err := os.ReadDir(mnt)
+	if err != nil {
+		t.Fatalf("could not read mount point: %v", err)
+	}
+	var names []string
+	for _, e := range ents {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	if got, want := names, []string{"file1.txt", "sub"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("invalid entries:\ngot = %q\nwant = %q", got, want)
+	}
+
+	for name, want := range map[string]string{
+		"file1.txt":     "hello",
+		"sub/file2.txt": "hello from a subdirectory",
+	} {
+		got, err := os.ReadFile(filepath.Join(mnt, name))
+		if err != nil {
+			t.Fatalf("could not read %q: %v", name, err)
+		}
+		if string(got) != want {
+			t.Fatalf("invalid content of %q: got=%q, want=%q", name, got, want)
+		}
+
+		fi, err := os.Stat(filepath.Join(mnt, name))
+		if err != nil {
+			t.Fatalf("could not stat %q: %v", name, err)
+		}
+		if got, want := fi.Size(), int64(len(want)); got != want {
+			t.Fatalf("invalid size of %q: got=%d, want=%d", name, got, want)
+		}
+		if fi.Mode().Perm()&0222 != 0 {
+			t.Fatalf("invalid mode of %q: %v", name, fi.Mode())
+		}
+	}
+
+	fi, err := os.Stat(filepath.Join(mnt, "sub"))
+	if err != nil {
+		t.Fatalf("could not stat sub directory: %v", err)
+	}
+	if !fi.IsDir() {
+		t.Fatalf("sub is not a directory: %v", fi.Mode())
+	}
+
+	_, err = os.Stat(filepath.Join(mnt, "missing.txt"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("invalid error for a missing file: %v", err)
+	}
+
+	_, err = os.OpenFile(filepath.Join(mnt, "file1.txt"), os.O_WRONLY, 0)
+	if !errors.Is(err, syscall.EROFS) {
+		t.Fatalf("invalid error for a write: %v", err)
+	}
+
+	err = os.WriteFile(filepath.Join(mnt, "new.txt"), []byte("data"), 0644)
+	if err == nil {
+		t.Fatalf("expected an error creating a file")
+	}
+}
@@ -135,6 +135,14 @@ func (f *File) ReadAt(data []byte, offset int64) (int, error) {
 	return f.f.ReadAt(data, offset)
 }
 
+// ReadV reads the segments segs of the file with vectored read requests,
+// fetching many segments in a single round trip.
+// The Data of a segment is shortened to the number of bytes read
+// when the segment extends past the end of the file.
+func (f *File) ReadV(segs []xrdfs.Segment) error {
+	return f.f.ReadV(context.Background(), segs)
+}
+
 // Write implements io.Writer.
 func (f *File) Write(data []byte) (int, error) {
 	n, err := f.f.WriteAt(data, f.pos)
@@ -47,3 +47,21 @@ type Auther interface {
 	Provider() string                          // Provider returns the name of the security provider.
 	Request(params []string) (*Request, error) // Request forms an authorization Request according to passed parameters.
 }
+
+// Handshaker is the interface implemented by security providers whose
+// authentication takes several kXR_auth requests.
+//
+// Handshake starts a new authentication from the parameters sent by the
+// server, and returns the first request to send.
+// While the server answers with kXR_authmore, the next request is formed by
+// the Handshake from the data of the server response.
+type Handshaker interface {
+	Auther
+	Handshake(params []string) (Handshake, *Request, error)
+}
+
+// Handshake is an authentication in progress.
+type Handshake interface {
+	// Next forms the next request from the data of the kXR_authmore response.
+	Next(data []byte) (*Request, error)
+}
@@ -0,0 +1,457 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gsi
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"go-hep.org/x/hep/xrootd/xrdproto/auth"
+)
+
+// provider is the name of the gsi security provider.
+const provider = "gsi"
+
+// version is the version of the gsi protocol implemented by the client.
+const version int32 = 10400
+
+// Type indicates the gsi authentication protocol is used.
+var Type = [4]byte{'g', 's', 'i', 0}
+
+// Default is a gsi security provider configured from the proxy of the user
+// (see ProxyPath) and the certificate authorities of CertDir.
+// If the proxy could not be loaded, Default will be nil.
+var Default auth.Auther
+
+func init() {
+	p, err := LoadProxy(ProxyPath())
+	if err != nil {
+		return
+	}
+	Default = &Auth{Proxy: p}
+}
+
+// Auth implements the gsi security provider.
+type Auth struct {
+	// Proxy is the proxy credential of the user.
+	Proxy *Proxy
+
+	// Roots holds the certificate authorities trusted to verify the
+	// certificate of the server.
+	// If nil, the certificate authorities are loaded from CertDir at
+	// each handshake.
+	Roots *x509.CertPool
+
+	// Delegate allows the server to request a proxy delegated from
+	// the proxy of the user, e.g. to access other storage elements on
+	// behalf of the user.
+	Delegate bool
+
+	// Lifetime is the maximal lifetime of delegated proxies.
+	// Lifetimes are limited to the validity of the proxy of the user, and
+	// to 12 hours if Lifetime is zero.
+	Lifetime time.Duration
+}
+
+// Provider implements auth.Auther
+func (*Auth) Provider() string {
+	return provider
+}
+
+// Request implements auth.Auther.
+// The gsi authentication takes several requests: it is only available
+// through Handshake.
+func (*Auth) Request(params []string) (*auth.Request, error) {
+	return nil, errors.New("auth/gsi: gsi authentication requires a handshake")
+}
+
+// Handshake implements auth.Handshaker.
+//
+// The gsi handshake goes as follows:
+//   - the client sends a random tag,
+//   - the server sends its certificate, the random tag signed with its key,
+//     its own random tag and its Diffie-Hellman public key,
+//   - the client verifies the server, and sends its Diffie-Hellman public
+//     key along with its proxy chain and the random tag of the server signed
+//     with the proxy key, encrypted with the key shared with the server,
+//   - the server may then request a delegated proxy, that the client signs
+//     with the proxy key if Delegate is set.
+func (a *Auth) Handshake(params []string) (auth.Handshake, *auth.Request, error) {
+	if a.Proxy == nil {
+		return nil, nil, errors.New("auth/gsi: no proxy credential")
+	}
+	for _, p := range params {
+		if v := strings.TrimPrefix(p, "c:"); v != p && !contains(strings.Split(v, ":"), "ssl") {
+			return nil, nil, fmt.Errorf("auth/gsi: unsupported crypto modules %q", v)
+		}
+	}
+
+	roots := a.Roots
+	if roots == nil {
+		var err error
+		roots, err = LoadCAs(CertDir())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	hs := &handshake{auth: a, roots: roots, rtag: make([]byte, 16)}
+	if _, err := rand.Read(hs.rtag); err != nil {
+		return nil, nil, fmt.Errorf("auth/gsi: could not generate random tag: %w", err)
+	}
+
+	var opts int32
+	if a.Delegate {
+		opts |= optSigReq
+	}
+
+	mbuf := buffer{step: stepCertReq}
+	mbuf.add(kindRTag, hs.rtag)
+	mbuf.add(kindClntOpts, i32(opts))
+
+	buf := buffer{step: stepCertReq}
+	buf.add(kindCryptoMod, []byte("ssl"))
+	buf.add(kindVersion, i32(version))
+	buf.add(kindMain, mbuf.marshal())
+
+	hs.step = stepCertReq
+	return hs, newRequest(&buf), nil
+}
+
+// handshake is a gsi authentication in progress.
+type handshake struct {
+	auth  *Auth
+	roots *x509.CertPool
+	rtag  []byte   // random tag sent to the server.
+	step  int32    // last step of the client.
+	sess  *session // session cipher, once the keys were exchanged.
+}
+
+// Next implements auth.Handshake.
+func (hs *handshake) Next(data []byte) (*auth.Request, error) {
+	buf, err := unmarshalBuffer(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case hs.step == stepCertReq && buf.step == stepSrvCert:
+		return hs.cert(buf)
+	case hs.step == stepCert && buf.step == stepSrvPxReq:
+		return hs.sigpxy(buf)
+	default:
+		return nil, fmt.Errorf("auth/gsi: unexpected server step %d after client step %d", buf.step, hs.step)
+	}
+}
+
+// cert verifies the certificate of the server and forms the request holding
+// the proxy chain of the client.
+func (hs *handshake) cert(buf *buffer) (*auth.Request, error) {
+	raw, ok := buf.get(kindX509)
+	if !ok {
+		return nil, errors.New("auth/gsi: missing server certificate")
+	}
+	chain, err := parseCerts(raw)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not parse server certificate: %w", err)
+	}
+	inters := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		inters.AddCert(cert)
+	}
+	_, err = chain[0].Verify(x509.VerifyOptions{
+		Roots:         hs.roots,
+		Intermediates: inters,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not verify server certificate: %w", err)
+	}
+
+	raw, ok = buf.get(kindMain)
+	if !ok {
+		return nil, errors.New("auth/gsi: missing main buffer")
+	}
+	mbuf, err := unmarshalBuffer(raw)
+	if err != nil {
+		return nil, err
+	}
+	sig, ok := mbuf.get(kindSignedRTag)
+	if !ok {
+		return nil, errors.New("auth/gsi: missing signed random tag")
+	}
+	err = verify(chain[0].PublicKey, hs.rtag, sig)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not verify server signature: %w", err)
+	}
+	srvTag, ok := mbuf.get(kindRTag)
+	if !ok {
+		return nil, errors.New("auth/gsi: missing server random tag")
+	}
+
+	if raw, ok := buf.get(kindMDAlg); ok && !contains(strings.Split(string(raw), ":"), "sha256") {
+		return nil, fmt.Errorf("auth/gsi: no supported message digest in %q", raw)
+	}
+	raw, ok = buf.get(kindCipherAlg)
+	if !ok {
+		return nil, errors.New("auth/gsi: missing server ciphers")
+	}
+	alg, err := selectCipher(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok = buf.get(kindPuk)
+	if !ok {
+		return nil, errors.New("auth/gsi: missing server public key")
+	}
+	params, srvPub, err := unmarshalPuk(raw)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newDHKey(params)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := key.secret(srvPub)
+	if err != nil {
+		return nil, err
+	}
+	hs.sess, err = newSession(alg, secret)
+	if err != nil {
+		return nil, err
+	}
+	puk, err := key.marshalPuk()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := hs.auth.Proxy
+	sig, err = sign(proxy.Key, srvTag)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not sign server random tag: %w", err)
+	}
+
+	out := buffer{step: stepCert}
+	out.add(kindSignedRTag, sig)
+	out.add(kindX509, encodeCerts(proxy.Chain))
+
+	req := buffer{step: stepCert}
+	req.add(kindCryptoMod, []byte("ssl"))
+	req.add(kindPuk, puk)
+	req.add(kindCipherAlg, []byte(alg))
+	req.add(kindMDAlg, []byte("sha256"))
+	err = hs.addMain(&req, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	hs.step = stepCert
+	return newRequest(&req), nil
+}
+
+// sigpxy signs the proxy request of the server.
+func (hs *handshake) sigpxy(buf *buffer) (*auth.Request, error) {
+	if !hs.auth.Delegate {
+		return nil, errors.New("auth/gsi: server requested a delegated proxy, but delegation is disabled")
+	}
+
+	mbuf, err := hs.main(buf)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := mbuf.get(kindX509Req)
+	if !ok {
+		return nil, errors.New("auth/gsi: missing proxy request")
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil || !strings.HasSuffix(block.Type, "CERTIFICATE REQUEST") {
+		return nil, errors.New("auth/gsi: invalid proxy request")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not parse proxy request: %w", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("auth/gsi: invalid proxy request signature: %w", err)
+	}
+
+	cert, err := hs.auth.Proxy.delegate(csr.PublicKey, hs.auth.Lifetime, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	out := buffer{step: stepSigPxy}
+	out.add(kindX509, encodeCerts(append([]*x509.Certificate{cert}, hs.auth.Proxy.Chain...)))
+
+	req := buffer{step: stepSigPxy}
+	req.add(kindCryptoMod, []byte("ssl"))
+	err = hs.addMain(&req, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	hs.step = stepSigPxy
+	return newRequest(&req), nil
+}
+
+// main returns the decrypted main buffer of buf.
+func (hs *handshake) main(buf *buffer) (*buffer, error) {
+	raw, ok := buf.get(kindMain)
+	if !ok {
+		return nil, errors.New("auth/gsi: missing main buffer")
+	}
+	raw, err := hs.sess.decrypt(raw)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalBuffer(raw)
+}
+
+// addMain adds the encrypted main buffer mbuf to buf.
+func (hs *handshake) addMain(buf, mbuf *buffer) error {
+	raw, err := hs.sess.encrypt(mbuf.marshal())
+	if err != nil {
+		return err
+	}
+	buf.add(kindMain, raw)
+	return nil
+}
+
+// delegate returns a RFC 3820 proxy certificate for the public key pub,
+// issued and signed by the proxy p.
+func (p *Proxy) delegate(pub interface{}, lifetime time.Duration, now time.Time) (*x509.Certificate, error) {
+	if lifetime <= 0 {
+		lifetime = 12 * time.Hour
+	}
+	issuer := p.Chain[0]
+	if now.After(issuer.NotAfter) {
+		return nil, fmt.Errorf("auth/gsi: proxy %q expired on %v", dn(issuer.RawSubject), issuer.NotAfter)
+	}
+	notAfter := now.Add(lifetime)
+	if notAfter.After(issuer.NotAfter) {
+		notAfter = issuer.NotAfter
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not generate serial number: %w", err)
+	}
+
+	var subject pkix.RDNSequence
+	if _, err := asn1.Unmarshal(issuer.RawSubject, &subject); err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not parse proxy subject: %w", err)
+	}
+	subject = append(subject, pkix.RelativeDistinguishedNameSET{
+		{Type: oidCommonName, Value: serial.String()},
+	})
+	rawSubject, err := asn1.Marshal(subject)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not marshal delegated proxy subject: %w", err)
+	}
+
+	pci, err := asn1.Marshal(proxyCertInfo{Policy: proxyPolicy{Language: oidInheritAll}})
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not marshal proxy certificate info: %w", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		RawSubject:   rawSubject,
+		NotBefore:    now.Add(-5 * time.Minute),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtraExtensions: []pkix.Extension{{
+			Id:       oidProxyCertInfo,
+			Critical: true,
+			Value:    pci,
+		}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, pub, p.Key)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not sign delegated proxy: %w", err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+// oidInheritAll is the policy language of proxies inheriting all the
+// rights of their issuer.
+var oidInheritAll = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 21, 1}
+
+// proxyCertInfo is the value of the proxyCertInfo extension of RFC 3820.
+type proxyCertInfo struct {
+	Policy proxyPolicy
+}
+
+type proxyPolicy struct {
+	Language asn1.ObjectIdentifier
+}
+
+func newRequest(buf *buffer) *auth.Request {
+	return &auth.Request{Type: Type, Credentials: string(buf.marshal())}
+}
+
+func i32(v int32) []byte {
+	var o [4]byte
+	binary.BigEndian.PutUint32(o[:], uint32(v))
+	return o[:]
+}
+
+func contains(vs []string, v string) bool {
+	for _, s := range vs {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCerts parses a list of PEM-encoded certificates.
+func parseCerts(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate")
+	}
+	return certs, nil
+}
+
+// encodeCerts returns the PEM encoding of certs.
+func encodeCerts(certs []*x509.Certificate) []byte {
+	var o bytes.Buffer
+	for _, cert := range certs {
+		_ = pem.Encode(&o, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return o.Bytes()
+}
+
+var (
+	_ auth.Auther     = (*Auth)(nil)
+	_ auth.Handshaker = (*Auth)(nil)
+)
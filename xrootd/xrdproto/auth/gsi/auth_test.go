@@ -0,0 +1,368 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gsi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"go-hep.org/x/hep/xrootd/xrdproto/auth"
+)
+
+// tstGroup is the 2048-bit MODP group of RFC 3526.
+var tstGroup = dhParams{
+	P: func() *big.Int {
+		p, _ := new(big.Int).SetString(""+
+			"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74"+
+			"020BBEA63B139B22514A08798E3404DDEF9519B3CD3A431B302B0A6DF25F1437"+
+			"4FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+			"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3DC2007CB8A163BF05"+
+			"98DA48361C55D39A69163FA8FD24CF5F83655D23DCA3AD961C62F356208552BB"+
+			"9ED529077096966D670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B"+
+			"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9DE2BCBF695581718"+
+			"3995497CEA956AE515D2261898FA051015728E5A8AACAA68FFFFFFFFFFFFFFFF", 16)
+		return p
+	}(),
+	G: big.NewInt(2),
+}
+
+// tstServer is the server side of the gsi handshake.
+type tstServer struct {
+	t     *testing.T
+	id    *tstCert
+	roots *x509.CertPool
+
+	rtag   []byte
+	sess   *session
+	client *Proxy
+	csrKey *ecdsa.PrivateKey
+}
+
+// unmarshal decodes the buffer of the request, with the expected step.
+func (srv *tstServer) unmarshal(req *auth.Request, step int32) *buffer {
+	srv.t.Helper()
+
+	if req.Type != Type {
+		srv.t.Fatalf("invalid request type: %q", req.Type)
+	}
+	buf, err := unmarshalBuffer([]byte(req.Credentials))
+	if err != nil {
+		srv.t.Fatalf("could not unmarshal request: %+v", err)
+	}
+	if buf.step != step {
+		srv.t.Fatalf("invalid client step: got=%d, want=%d", buf.step, step)
+	}
+	return buf
+}
+
+// main returns the main buffer of buf, decrypted with the session cipher
+// when the keys were exchanged.
+func (srv *tstServer) main(buf *buffer) *buffer {
+	srv.t.Helper()
+
+	raw, ok := buf.get(kindMain)
+	if !ok {
+		srv.t.Fatalf("missing main buffer")
+	}
+	if srv.sess != nil {
+		var err error
+		raw, err = srv.sess.decrypt(raw)
+		if err != nil {
+			srv.t.Fatalf("could not decrypt main buffer: %+v", err)
+		}
+	}
+	mbuf, err := unmarshalBuffer(raw)
+	if err != nil {
+		srv.t.Fatalf("could not unmarshal main buffer: %+v", err)
+	}
+	return mbuf
+}
+
+// certreq answers the first request of the client with the certificate of
+// the server.
+func (srv *tstServer) certreq(req *auth.Request) (*dhKey, []byte) {
+	srv.t.Helper()
+
+	buf := srv.unmarshal(req, stepCertReq)
+	if v, _ := buf.get(kindCryptoMod); string(v) != "ssl" {
+		srv.t.Fatalf("invalid crypto module %q", v)
+	}
+	rtag, ok := srv.main(buf).get(kindRTag)
+	if !ok {
+		srv.t.Fatalf("missing client random tag")
+	}
+	sig, err := sign(srv.id.key, rtag)
+	if err != nil {
+		srv.t.Fatalf("could not sign random tag: %+v", err)
+	}
+
+	key, err := newDHKey(tstGroup)
+	if err != nil {
+		srv.t.Fatalf("could not generate DH key: %+v", err)
+	}
+	puk, err := key.marshalPuk()
+	if err != nil {
+		srv.t.Fatalf("could not marshal DH key: %+v", err)
+	}
+
+	srv.rtag = []byte("server-random-tag")
+	mbuf := buffer{step: stepSrvCert}
+	mbuf.add(kindSignedRTag, sig)
+	mbuf.add(kindRTag, srv.rtag)
+
+	out := buffer{step: stepSrvCert}
+	out.add(kindCryptoMod, []byte("ssl"))
+	out.add(kindPuk, puk)
+	out.add(kindCipherAlg, []byte("bf-cbc:aes-128-cbc:aes-256-cbc"))
+	out.add(kindMDAlg, []byte("sha256:sha1"))
+	out.add(kindX509, encodeCerts([]*x509.Certificate{srv.id.cert}))
+	out.add(kindMain, mbuf.marshal())
+	return key, out.marshal()
+}
+
+// cert verifies the proxy chain of the client.
+func (srv *tstServer) cert(req *auth.Request, key *dhKey) {
+	srv.t.Helper()
+
+	buf := srv.unmarshal(req, stepCert)
+	if v, _ := buf.get(kindCipherAlg); string(v) != "aes-128-cbc" {
+		srv.t.Fatalf("invalid cipher %q", v)
+	}
+	raw, _ := buf.get(kindPuk)
+	_, pub, err := unmarshalPuk(raw)
+	if err != nil {
+		srv.t.Fatalf("could not unmarshal client DH key: %+v", err)
+	}
+	secret, err := key.secret(pub)
+	if err != nil {
+		srv.t.Fatalf("could not compute shared secret: %+v", err)
+	}
+	srv.sess, err = newSession("aes-128-cbc", secret)
+	if err != nil {
+		srv.t.Fatalf("could not create session: %+v", err)
+	}
+
+	mbuf := srv.main(buf)
+	raw, _ = mbuf.get(kindX509)
+	chain, err := parseCerts(raw)
+	if err != nil {
+		srv.t.Fatalf("could not parse client chain: %+v", err)
+	}
+	srv.client = &Proxy{Chain: chain}
+	err = srv.client.Verify(srv.roots, time.Now())
+	if err != nil {
+		srv.t.Fatalf("could not verify client chain: %+v", err)
+	}
+	sig, _ := mbuf.get(kindSignedRTag)
+	err = verify(chain[0].PublicKey, srv.rtag, sig)
+	if err != nil {
+		srv.t.Fatalf("could not verify client signature: %+v", err)
+	}
+}
+
+// pxyreq requests a delegated proxy to the client.
+func (srv *tstServer) pxyreq() []byte {
+	srv.t.Helper()
+
+	var err error
+	srv.csrKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		srv.t.Fatalf("could not generate key: %+v", err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "proxy request"},
+	}, srv.csrKey)
+	if err != nil {
+		srv.t.Fatalf("could not create proxy request: %+v", err)
+	}
+
+	mbuf := buffer{step: stepSrvPxReq}
+	mbuf.add(kindX509Req, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+	raw, err := srv.sess.encrypt(mbuf.marshal())
+	if err != nil {
+		srv.t.Fatalf("could not encrypt main buffer: %+v", err)
+	}
+
+	out := buffer{step: stepSrvPxReq}
+	out.add(kindMain, raw)
+	return out.marshal()
+}
+
+// sigpxy verifies the proxy delegated by the client.
+func (srv *tstServer) sigpxy(req *auth.Request) *Proxy {
+	srv.t.Helper()
+
+	raw, _ := srv.main(srv.unmarshal(req, stepSigPxy)).get(kindX509)
+	chain, err := parseCerts(raw)
+	if err != nil {
+		srv.t.Fatalf("could not parse delegated proxy: %+v", err)
+	}
+	return &Proxy{Chain: chain, Key: srv.csrKey}
+}
+
+func TestHandshake(t *testing.T) {
+	now := time.Now()
+	ca, eec, pxy1, _ := newTstChainAt(t, now)
+	srvCert := newTstCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "xrootd.example.org"},
+		DNSNames:    []string{"xrootd.example.org"},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(+time.Hour),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	proxy, err := ParseProxy(encodeProxy(t, pxy1.key, pxy1, eec))
+	if err != nil {
+		t.Fatalf("could not parse proxy: %+v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		delegate bool
+		pxyreq   bool
+		err      string
+	}{
+		{name: "no-delegation"},
+		{name: "delegation", delegate: true, pxyreq: true},
+		{name: "delegation-disabled", pxyreq: true, err: "delegation is disabled"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := &tstServer{t: t, id: srvCert, roots: roots}
+			cli := &Auth{Proxy: proxy, Roots: roots, Delegate: tc.delegate, Lifetime: time.Hour}
+
+			hs, req, err := cli.Handshake([]string{"v:10400", "c:ssl", "ca:1d879c6c"})
+			if err != nil {
+				t.Fatalf("could not start handshake: %+v", err)
+			}
+
+			key, resp := srv.certreq(req)
+			req, err = hs.Next(resp)
+			if err != nil {
+				t.Fatalf("could not answer server certificate: %+v", err)
+			}
+			srv.cert(req, key)
+			if got, want := srv.client.Identity(), "/DC=org/DC=example/OU=Users/CN=Gopher"; got != want {
+				t.Fatalf("invalid client identity:\ngot= %q\nwant=%q", got, want)
+			}
+			if !tc.pxyreq {
+				return
+			}
+
+			req, err = hs.Next(srv.pxyreq())
+			switch {
+			case err != nil && tc.err == "":
+				t.Fatalf("could not sign delegated proxy: %+v", err)
+			case err != nil && tc.err != "":
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("invalid error:\ngot= %v\nwant=%s", err, tc.err)
+				}
+				return
+			case err == nil && tc.err != "":
+				t.Fatalf("expected an error (%s)", tc.err)
+			}
+
+			dlg := srv.sigpxy(req)
+			if got, want := len(dlg.Chain), 3; got != want {
+				t.Fatalf("invalid delegated chain length: got=%d, want=%d", got, want)
+			}
+			if !IsProxy(dlg.Chain[0]) {
+				t.Fatalf("delegated certificate is not a proxy")
+			}
+			if !dlg.Chain[0].PublicKey.(*ecdsa.PublicKey).Equal(&srv.csrKey.PublicKey) {
+				t.Fatalf("delegated proxy does not certify the requested key")
+			}
+			if dlg.Chain[0].NotAfter.After(now.Add(time.Hour)) {
+				t.Fatalf("invalid delegated proxy lifetime: %v", dlg.Chain[0].NotAfter)
+			}
+			err = dlg.Verify(roots, time.Now())
+			if err != nil {
+				t.Fatalf("could not verify delegated proxy: %+v", err)
+			}
+			if got, want := dlg.Identity(), srv.client.Identity(); got != want {
+				t.Fatalf("invalid delegated identity:\ngot= %q\nwant=%q", got, want)
+			}
+		})
+	}
+}
+
+func TestHandshakeUnknownServer(t *testing.T) {
+	now := time.Now()
+	ca, eec, pxy1, _ := newTstChainAt(t, now)
+	other, _, _, _ := newTstChainAt(t, now)
+	srvCert := newTstCert(t, &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "xrootd.example.org"},
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.Add(+time.Hour),
+		KeyUsage:  x509.KeyUsageDigitalSignature,
+	}, other)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	proxy, err := ParseProxy(encodeProxy(t, pxy1.key, pxy1, eec))
+	if err != nil {
+		t.Fatalf("could not parse proxy: %+v", err)
+	}
+
+	srv := &tstServer{t: t, id: srvCert, roots: roots}
+	cli := &Auth{Proxy: proxy, Roots: roots}
+
+	hs, req, err := cli.Handshake(nil)
+	if err != nil {
+		t.Fatalf("could not start handshake: %+v", err)
+	}
+	_, resp := srv.certreq(req)
+	_, err = hs.Next(resp)
+	if err == nil || !strings.Contains(err.Error(), "could not verify server certificate") {
+		t.Fatalf("expected a server verification error, got: %v", err)
+	}
+
+	_, _, err = cli.Handshake([]string{"c:krb5"})
+	if err == nil {
+		t.Fatalf("expected an error for unsupported crypto modules")
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	var buf buffer
+	buf.step = stepCert
+	buf.add(kindRTag, []byte("tag"))
+	buf.add(kindInactive, []byte("ignored"))
+	buf.add(kindX509, nil)
+
+	raw := buf.marshal()
+	if !strings.HasPrefix(string(raw), "gsi\x00") {
+		t.Fatalf("invalid buffer protocol: %q", raw)
+	}
+	got, err := unmarshalBuffer(raw)
+	if err != nil {
+		t.Fatalf("could not unmarshal buffer: %+v", err)
+	}
+	if got.step != stepCert || len(got.buckets) != 2 {
+		t.Fatalf("invalid buffer: %+v", got)
+	}
+	if v, ok := got.get(kindRTag); !ok || string(v) != "tag" {
+		t.Fatalf("invalid rtag bucket: %q", v)
+	}
+
+	for i := range raw[:len(raw)-1] {
+		_, err = unmarshalBuffer(raw[:i])
+		if err == nil {
+			t.Fatalf("expected an error for truncated buffer (n=%d)", i)
+		}
+	}
+}
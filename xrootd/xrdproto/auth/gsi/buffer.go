@@ -0,0 +1,129 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gsi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Steps of the gsi handshake.
+const (
+	stepCertReq  int32 = 1000 // kXGC_certreq: the client requests the server certificate.
+	stepCert     int32 = 1001 // kXGC_cert: the client sends its proxy chain.
+	stepSigPxy   int32 = 1002 // kXGC_sigpxy: the client sends the delegated proxy it signed.
+	stepSrvCert  int32 = 2001 // kXGS_cert: the server sends its certificate.
+	stepSrvPxReq int32 = 2002 // kXGS_pxyreq: the server requests a delegated proxy.
+)
+
+// Types of the buckets of a gsi buffer.
+const (
+	kindNone       int32 = 0    // kXRS_none: end of the buffer.
+	kindInactive   int32 = 1    // kXRS_inactive: ignored bucket.
+	kindCryptoMod  int32 = 3000 // kXRS_cryptomod: name of the crypto module.
+	kindMain       int32 = 3001 // kXRS_main: serialized (and, possibly, encrypted) main buffer.
+	kindPuk        int32 = 3004 // kXRS_puk: Diffie-Hellman public key.
+	kindRTag       int32 = 3006 // kXRS_rtag: random tag to be signed by the peer.
+	kindSignedRTag int32 = 3007 // kXRS_signed_rtag: random tag of the peer, signed.
+	kindVersion    int32 = 3014 // kXRS_version: version of the protocol.
+	kindClntOpts   int32 = 3019 // kXRS_clnt_opts: options of the client.
+	kindX509       int32 = 3022 // kXRS_x509: PEM-encoded certificate chain.
+	kindX509Req    int32 = 3024 // kXRS_x509_req: PEM-encoded certificate request.
+	kindCipherAlg  int32 = 3025 // kXRS_cipher_alg: list of session ciphers.
+	kindMDAlg      int32 = 3026 // kXRS_md_alg: list of message digests.
+)
+
+// optSigReq is the client option telling the server that the client signs
+// delegated proxy requests.
+const optSigReq = 4
+
+// bucket is a typed chunk of data of a gsi buffer.
+type bucket struct {
+	kind int32
+	data []byte
+}
+
+// buffer is the serialized form of the messages of the gsi handshake:
+// the null-terminated name of the protocol, the step of the handshake and
+// a list of buckets, each made of its type, its size and its data.
+// The list is terminated by a bucket of type kindNone.
+type buffer struct {
+	step    int32
+	buckets []bucket
+}
+
+// add appends a bucket of the given kind to the buffer.
+func (buf *buffer) add(kind int32, data []byte) {
+	buf.buckets = append(buf.buckets, bucket{kind: kind, data: data})
+}
+
+// get returns the data of the first bucket of the given kind.
+func (buf *buffer) get(kind int32) ([]byte, bool) {
+	for _, b := range buf.buckets {
+		if b.kind == kind {
+			return b.data, true
+		}
+	}
+	return nil, false
+}
+
+// marshal returns the serialized form of the buffer.
+func (buf *buffer) marshal() []byte {
+	o := new(bytes.Buffer)
+	o.WriteString(provider)
+	o.WriteByte(0)
+	_ = binary.Write(o, binary.BigEndian, buf.step)
+	for _, b := range buf.buckets {
+		if b.kind == kindInactive {
+			continue
+		}
+		_ = binary.Write(o, binary.BigEndian, b.kind)
+		_ = binary.Write(o, binary.BigEndian, int32(len(b.data)))
+		o.Write(b.data)
+	}
+	_ = binary.Write(o, binary.BigEndian, kindNone)
+	return o.Bytes()
+}
+
+// unmarshalBuffer decodes a serialized gsi buffer.
+func unmarshalBuffer(data []byte) (*buffer, error) {
+	i := bytes.IndexByte(data, 0)
+	if i < 0 {
+		return nil, errors.New("auth/gsi: invalid buffer: missing protocol name")
+	}
+	if name := string(data[:i]); name != provider {
+		return nil, fmt.Errorf("auth/gsi: invalid buffer protocol %q", name)
+	}
+	data = data[i+1:]
+
+	if len(data) < 4 {
+		return nil, errors.New("auth/gsi: invalid buffer: missing step")
+	}
+	buf := &buffer{step: int32(binary.BigEndian.Uint32(data))}
+	data = data[4:]
+
+	for {
+		if len(data) < 4 {
+			return nil, errors.New("auth/gsi: invalid buffer: missing end of buffer")
+		}
+		kind := int32(binary.BigEndian.Uint32(data))
+		data = data[4:]
+		if kind == kindNone {
+			return buf, nil
+		}
+		if len(data) < 4 {
+			return nil, fmt.Errorf("auth/gsi: invalid buffer: missing size of bucket %d", kind)
+		}
+		n := int(binary.BigEndian.Uint32(data))
+		data = data[4:]
+		if n < 0 || n > len(data) {
+			return nil, fmt.Errorf("auth/gsi: invalid buffer: invalid size of bucket %d", kind)
+		}
+		buf.add(kind, data[:n:n])
+		data = data[n:]
+	}
+}
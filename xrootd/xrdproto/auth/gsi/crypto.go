@@ -0,0 +1,230 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gsi
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// minDHBits is the minimal size of the prime of Diffie-Hellman groups.
+	minDHBits = 1024
+
+	pukBegin = "---BPUB---"
+	pukEnd   = "---EPUB---"
+)
+
+// dhParams are the parameters of a Diffie-Hellman group.
+type dhParams struct {
+	P *big.Int
+	G *big.Int
+	L int `asn1:"optional"`
+}
+
+// dhKey is a Diffie-Hellman key.
+type dhKey struct {
+	params dhParams
+	x      *big.Int // private value
+	y      *big.Int // public value
+}
+
+// newDHKey generates a Diffie-Hellman key in the group of params.
+func newDHKey(params dhParams) (*dhKey, error) {
+	if params.P == nil || params.P.BitLen() < minDHBits {
+		return nil, errors.New("auth/gsi: Diffie-Hellman prime is too small")
+	}
+	if params.G == nil || params.G.Cmp(big.NewInt(1)) <= 0 || params.G.Cmp(params.P) >= 0 {
+		return nil, errors.New("auth/gsi: invalid Diffie-Hellman generator")
+	}
+	n := new(big.Int).Sub(params.P, big.NewInt(3))
+	x, err := rand.Int(rand.Reader, n)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not generate Diffie-Hellman key: %w", err)
+	}
+	x.Add(x, big.NewInt(2))
+	return &dhKey{
+		params: params,
+		x:      x,
+		y:      new(big.Int).Exp(params.G, x, params.P),
+	}, nil
+}
+
+// secret returns the secret shared with the owner of the public value y,
+// padded to the size of the prime.
+func (key *dhKey) secret(y *big.Int) ([]byte, error) {
+	p := key.params.P
+	if y.Cmp(big.NewInt(1)) <= 0 || y.Cmp(new(big.Int).Sub(p, big.NewInt(1))) >= 0 {
+		return nil, errors.New("auth/gsi: invalid Diffie-Hellman public value")
+	}
+	s := new(big.Int).Exp(y, key.x, p)
+	return s.FillBytes(make([]byte, (p.BitLen()+7)/8)), nil
+}
+
+// marshalPuk returns the public part of the key, as exchanged in puk
+// buckets: the PEM-encoded parameters of the group followed by the
+// hex-encoded public value.
+func (key *dhKey) marshalPuk() ([]byte, error) {
+	der, err := asn1.Marshal(dhParams{P: key.params.P, G: key.params.G})
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not marshal Diffie-Hellman parameters: %w", err)
+	}
+	o := pem.EncodeToMemory(&pem.Block{Type: "DH PARAMETERS", Bytes: der})
+	o = append(o, pukBegin+hex.EncodeToString(key.y.Bytes())+pukEnd...)
+	return o, nil
+}
+
+// unmarshalPuk decodes the content of a puk bucket.
+func unmarshalPuk(data []byte) (dhParams, *big.Int, error) {
+	var params dhParams
+	block, rest := pem.Decode(data)
+	if block == nil || block.Type != "DH PARAMETERS" {
+		return params, nil, errors.New("auth/gsi: missing Diffie-Hellman parameters")
+	}
+	if _, err := asn1.Unmarshal(block.Bytes, &params); err != nil {
+		return params, nil, fmt.Errorf("auth/gsi: could not parse Diffie-Hellman parameters: %w", err)
+	}
+
+	beg := bytes.Index(rest, []byte(pukBegin))
+	end := bytes.Index(rest, []byte(pukEnd))
+	if beg < 0 || end < beg {
+		return params, nil, errors.New("auth/gsi: missing Diffie-Hellman public value")
+	}
+	raw, err := hex.DecodeString(string(rest[beg+len(pukBegin) : end]))
+	if err != nil {
+		return params, nil, fmt.Errorf("auth/gsi: could not decode Diffie-Hellman public value: %w", err)
+	}
+	return params, new(big.Int).SetBytes(raw), nil
+}
+
+// ciphers holds the key sizes of the supported session ciphers, by order of
+// preference.
+var ciphers = []struct {
+	name string
+	size int
+}{
+	{"aes-256-cbc", 32},
+	{"aes-192-cbc", 24},
+	{"aes-128-cbc", 16},
+}
+
+// selectCipher returns the first supported cipher of the colon-separated
+// list of ciphers of the server.
+func selectCipher(list string) (string, error) {
+	algs := strings.Split(list, ":")
+	for _, alg := range algs {
+		for _, c := range ciphers {
+			if alg == c.name {
+				return alg, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("auth/gsi: no supported cipher in %q", list)
+}
+
+// session encrypts the main buffers exchanged once the Diffie-Hellman keys
+// were exchanged.
+// Messages are padded with PKCS#7 and encrypted in CBC mode, prefixed by
+// their random IV.
+type session struct {
+	block cipher.Block
+}
+
+// newSession creates the session cipher alg, keyed by the leading bytes of
+// the shared secret.
+func newSession(alg string, secret []byte) (*session, error) {
+	for _, c := range ciphers {
+		if c.name != alg {
+			continue
+		}
+		if len(secret) < c.size {
+			return nil, errors.New("auth/gsi: shared secret is too short")
+		}
+		block, err := aes.NewCipher(secret[:c.size])
+		if err != nil {
+			return nil, fmt.Errorf("auth/gsi: could not create session cipher: %w", err)
+		}
+		return &session{block: block}, nil
+	}
+	return nil, fmt.Errorf("auth/gsi: unsupported cipher %q", alg)
+}
+
+func (sess *session) encrypt(msg []byte) ([]byte, error) {
+	bs := sess.block.BlockSize()
+	pad := bs - len(msg)%bs
+	out := make([]byte, bs+len(msg)+pad)
+	if _, err := rand.Read(out[:bs]); err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not generate IV: %w", err)
+	}
+	copy(out[bs:], msg)
+	for i := len(out) - pad; i < len(out); i++ {
+		out[i] = byte(pad)
+	}
+	cipher.NewCBCEncrypter(sess.block, out[:bs]).CryptBlocks(out[bs:], out[bs:])
+	return out, nil
+}
+
+func (sess *session) decrypt(msg []byte) ([]byte, error) {
+	bs := sess.block.BlockSize()
+	if len(msg) < 2*bs || len(msg)%bs != 0 {
+		return nil, errors.New("auth/gsi: invalid encrypted message size")
+	}
+	out := make([]byte, len(msg)-bs)
+	cipher.NewCBCDecrypter(sess.block, msg[:bs]).CryptBlocks(out, msg[bs:])
+	pad := int(out[len(out)-1])
+	if pad == 0 || pad > bs {
+		return nil, errors.New("auth/gsi: invalid encrypted message padding")
+	}
+	for _, v := range out[len(out)-pad:] {
+		if int(v) != pad {
+			return nil, errors.New("auth/gsi: invalid encrypted message padding")
+		}
+	}
+	return out[:len(out)-pad], nil
+}
+
+// sign signs the random tag rtag with key, using SHA-256 digests.
+func sign(key crypto.Signer, rtag []byte) ([]byte, error) {
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		return key.Sign(rand.Reader, rtag, crypto.Hash(0))
+	}
+	h := sha256.Sum256(rtag)
+	return key.Sign(rand.Reader, h[:], crypto.SHA256)
+}
+
+// verify checks the signature sig of the random tag rtag with pub.
+func verify(pub crypto.PublicKey, rtag, sig []byte) error {
+	h := sha256.Sum256(rtag)
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, h[:], sig) {
+			return errors.New("invalid ECDSA signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, rtag, sig) {
+			return errors.New("invalid Ed25519 signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
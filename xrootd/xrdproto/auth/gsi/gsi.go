@@ -0,0 +1,361 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gsi contains the implementation of the gsi (Grid Security
+// Infrastructure) security provider.
+//
+// Package gsi loads proxy certificates (RFC 3820 and legacy Globus proxies,
+// including VOMS proxies as created by voms-proxy-init) and verifies their
+// chain against the certificate authorities of the grid.
+//
+// The gsi handshake is a multi-step exchange of Diffie-Hellman keys and
+// certificate buckets, encrypted with the key shared with the server.
+// The client may also sign proxies delegated to the server.
+package gsi // import "go-hep.org/x/hep/xrootd/xrdproto/auth/gsi"
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// oidProxyCertInfo is the proxyCertInfo extension of RFC 3820 proxies.
+	oidProxyCertInfo = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 14}
+	// oidProxyCertInfoDraft is the proxyCertInfo extension of pre-RFC proxies.
+	oidProxyCertInfoDraft = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 3536, 1, 222}
+	// oidCommonName is the CN attribute of distinguished names.
+	oidCommonName = asn1.ObjectIdentifier{2, 5, 4, 3}
+)
+
+// ProxyPath returns the location of the user proxy: $X509_USER_PROXY or,
+// if not set, /tmp/x509up_u<uid>.
+func ProxyPath() string {
+	if v := os.Getenv("X509_USER_PROXY"); v != "" {
+		return v
+	}
+	uid := ""
+	if usr, err := user.Current(); err == nil {
+		uid = usr.Uid
+	}
+	return filepath.Join(os.TempDir(), "x509up_u"+uid)
+}
+
+// CertDir returns the location of the certificate authorities: $X509_CERT_DIR
+// or, if not set, /etc/grid-security/certificates.
+func CertDir() string {
+	if v := os.Getenv("X509_CERT_DIR"); v != "" {
+		return v
+	}
+	return "/etc/grid-security/certificates"
+}
+
+// LoadCAs loads the certificate authorities from the PEM files of dir,
+// named after their hash (e.g. 1d879c6c.0) or with a .pem extension.
+func LoadCAs(dir string) (*x509.CertPool, error) {
+	fnames, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not list certificate authorities: %w", err)
+	}
+
+	var (
+		pool = x509.NewCertPool()
+		n    = 0
+	)
+	for _, fname := range fnames {
+		ext := filepath.Ext(fname)
+		if ext != ".pem" && !isHashExt(ext) {
+			continue
+		}
+		raw, err := os.ReadFile(fname)
+		if err != nil {
+			return nil, fmt.Errorf("auth/gsi: could not read certificate authority: %w", err)
+		}
+		if pool.AppendCertsFromPEM(raw) {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("auth/gsi: no certificate authority in %q", dir)
+	}
+	return pool, nil
+}
+
+// isHashExt returns whether ext is the .N extension of an OpenSSL hashed
+// certificate file name.
+func isHashExt(ext string) bool {
+	if len(ext) < 2 {
+		return false
+	}
+	for _, c := range ext[1:] {
+		if c < '0' || '9' < c {
+			return false
+		}
+	}
+	return true
+}
+
+// Proxy is an X.509 proxy credential.
+type Proxy struct {
+	// Chain is the certificate chain, from the proxy certificate to the
+	// end-entity certificate of the user (and, possibly, its issuers.)
+	Chain []*x509.Certificate
+
+	// Key is the private key of the proxy certificate.
+	Key crypto.Signer
+}
+
+// LoadProxy loads a proxy credential from the PEM file fname, holding the
+// proxy certificate, its private key and the rest of the certificate chain.
+func LoadProxy(fname string) (*Proxy, error) {
+	raw, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not read proxy: %w", err)
+	}
+	p, err := ParseProxy(raw)
+	if err != nil {
+		return nil, fmt.Errorf("auth/gsi: could not load proxy %q: %w", fname, err)
+	}
+	return p, nil
+}
+
+// ParseProxy parses a PEM-encoded proxy credential.
+func ParseProxy(data []byte) (*Proxy, error) {
+	var p Proxy
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse certificate: %w", err)
+			}
+			p.Chain = append(p.Chain, cert)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			if p.Key != nil {
+				return nil, errors.New("more than one private key")
+			}
+			key, err := parseKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			p.Key = key
+		}
+	}
+
+	switch {
+	case len(p.Chain) == 0:
+		return nil, errors.New("no certificate")
+	case p.Key == nil:
+		return nil, errors.New("no private key")
+	}
+
+	pub, ok := p.Key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(p.Chain[0].PublicKey) {
+		return nil, errors.New("private key does not match the proxy certificate")
+	}
+
+	return &p, nil
+}
+
+func parseKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %w", err)
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// IsProxy returns whether cert is a proxy certificate, either a RFC 3820
+// proxy (with a proxyCertInfo extension) or a legacy Globus proxy (whose
+// subject is its issuer with an additional "proxy" or "limited proxy" CN.)
+func IsProxy(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidProxyCertInfo) || ext.Id.Equal(oidProxyCertInfoDraft) {
+			return true
+		}
+	}
+	if !isSubjectExtension(cert) {
+		return false
+	}
+	cn := cert.Subject.CommonName
+	return cn == "proxy" || cn == "limited proxy"
+}
+
+// EEC returns the end-entity certificate of the proxy chain, ie the
+// certificate of the user that issued the proxies.
+func (p *Proxy) EEC() *x509.Certificate {
+	for _, cert := range p.Chain {
+		if !IsProxy(cert) {
+			return cert
+		}
+	}
+	return nil
+}
+
+// Identity returns the distinguished name of the user, in the OpenSSL
+// format used by grid-mapfiles (e.g. "/DC=org/DC=example/CN=Gopher".)
+func (p *Proxy) Identity() string {
+	eec := p.EEC()
+	if eec == nil {
+		return ""
+	}
+	return dn(eec.RawSubject)
+}
+
+// Verify verifies the proxy chain at time now, against the roots certificate
+// authorities.
+//
+// Each proxy must be signed by the next certificate of the chain and have
+// a subject made of the subject of its issuer with one more CN.
+// The end-entity certificate must be verified by the roots, using the rest
+// of the chain as intermediates.
+func (p *Proxy) Verify(roots *x509.CertPool, now time.Time) error {
+	if len(p.Chain) == 0 {
+		return errors.New("auth/gsi: empty proxy chain")
+	}
+
+	var i int
+	for i = 0; i < len(p.Chain) && IsProxy(p.Chain[i]); i++ {
+		cert := p.Chain[i]
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fmt.Errorf("auth/gsi: proxy %q is not valid at %v (validity: [%v, %v])",
+				dn(cert.RawSubject), now, cert.NotBefore, cert.NotAfter,
+			)
+		}
+		if i+1 >= len(p.Chain) {
+			return fmt.Errorf("auth/gsi: missing issuer of proxy %q", dn(cert.RawSubject))
+		}
+		issuer := p.Chain[i+1]
+		if !bytes.Equal(cert.RawIssuer, issuer.RawSubject) {
+			return fmt.Errorf("auth/gsi: proxy %q was not issued by %q", dn(cert.RawSubject), dn(issuer.RawSubject))
+		}
+		if !isSubjectExtension(cert) {
+			return fmt.Errorf("auth/gsi: invalid subject of proxy %q", dn(cert.RawSubject))
+		}
+		// CheckSignatureFrom would require the issuer to be a CA.
+		err := issuer.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)
+		if err != nil {
+			return fmt.Errorf("auth/gsi: invalid signature of proxy %q: %w", dn(cert.RawSubject), err)
+		}
+	}
+	if i == len(p.Chain) {
+		return errors.New("auth/gsi: no end-entity certificate in proxy chain")
+	}
+
+	inters := x509.NewCertPool()
+	for _, cert := range p.Chain[i+1:] {
+		inters.AddCert(cert)
+	}
+	_, err := p.Chain[i].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: inters,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("auth/gsi: could not verify end-entity certificate: %w", err)
+	}
+	return nil
+}
+
+// isSubjectExtension returns whether the subject of cert is its issuer with
+// one additional CN.
+func isSubjectExtension(cert *x509.Certificate) bool {
+	var sub, iss pkix.RDNSequence
+	if _, err := asn1.Unmarshal(cert.RawSubject, &sub); err != nil {
+		return false
+	}
+	if _, err := asn1.Unmarshal(cert.RawIssuer, &iss); err != nil {
+		return false
+	}
+	if len(sub) != len(iss)+1 {
+		return false
+	}
+	for i, rdn := range iss {
+		if !equalRDN(rdn, sub[i]) {
+			return false
+		}
+	}
+	last := sub[len(sub)-1]
+	return len(last) == 1 && last[0].Type.Equal(oidCommonName)
+}
+
+func equalRDN(a, b pkix.RelativeDistinguishedNameSET) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Type.Equal(b[i].Type) || fmt.Sprint(a[i].Value) != fmt.Sprint(b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// dnNames holds the OpenSSL short names of distinguished name attributes.
+var dnNames = map[string]string{
+	"2.5.4.3":                    "CN",
+	"2.5.4.5":                    "serialNumber",
+	"2.5.4.6":                    "C",
+	"2.5.4.7":                    "L",
+	"2.5.4.8":                    "ST",
+	"2.5.4.10":                   "O",
+	"2.5.4.11":                   "OU",
+	"0.9.2342.19200300.100.1.1":  "UID",
+	"0.9.2342.19200300.100.1.25": "DC",
+	"1.2.840.113549.1.9.1":       "emailAddress",
+}
+
+// dn returns the OpenSSL one-line representation of a DER-encoded
+// distinguished name.
+func dn(raw []byte) string {
+	var seq pkix.RDNSequence
+	if _, err := asn1.Unmarshal(raw, &seq); err != nil {
+		return ""
+	}
+	var o strings.Builder
+	for _, rdn := range seq {
+		for _, atv := range rdn {
+			name, ok := dnNames[atv.Type.String()]
+			if !ok {
+				name = atv.Type.String()
+			}
+			fmt.Fprintf(&o, "/%s=%v", name, atv.Value)
+		}
+	}
+	return o.String()
+}
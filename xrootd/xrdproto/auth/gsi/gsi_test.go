@@ -0,0 +1,253 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gsi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	oidDC  = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+	tstNow = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+)
+
+type tstCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTstCert(t *testing.T, tmpl *x509.Certificate, parent *tstCert) *tstCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	if tmpl.NotBefore.IsZero() {
+		tmpl.NotBefore = tstNow.Add(-time.Hour)
+		tmpl.NotAfter = tstNow.Add(+time.Hour)
+	}
+
+	var (
+		issuer    = tmpl
+		issuerKey = key
+	)
+	if parent != nil {
+		issuer = parent.cert
+		issuerKey = parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatalf("could not create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("could not parse certificate: %v", err)
+	}
+	return &tstCert{cert: cert, key: key}
+}
+
+func rawName(t *testing.T, seq pkix.RDNSequence) []byte {
+	t.Helper()
+
+	raw, err := asn1.Marshal(seq)
+	if err != nil {
+		t.Fatalf("could not marshal name: %v", err)
+	}
+	return raw
+}
+
+// proxySubject returns the DER-encoded subject of a proxy of issuer.
+func proxySubject(t *testing.T, issuer *x509.Certificate, cn string) []byte {
+	t.Helper()
+
+	var seq pkix.RDNSequence
+	_, err := asn1.Unmarshal(issuer.RawSubject, &seq)
+	if err != nil {
+		t.Fatalf("could not unmarshal subject: %v", err)
+	}
+	seq = append(seq, pkix.RelativeDistinguishedNameSET{{Type: oidCommonName, Value: cn}})
+	return rawName(t, seq)
+}
+
+func newTstChain(t *testing.T) (ca, eec, pxy1, pxy2 *tstCert) {
+	return newTstChainAt(t, tstNow)
+}
+
+// newTstChainAt returns a chain of certificates valid at now.
+func newTstChainAt(t *testing.T, now time.Time) (ca, eec, pxy1, pxy2 *tstCert) {
+	ca = newTstCert(t, &x509.Certificate{
+		Subject:               pkix.Name{Organization: []string{"Gopher CA"}, CommonName: "Gopher Root CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(+time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}, nil)
+
+	eec = newTstCert(t, &x509.Certificate{
+		RawSubject: rawName(t, pkix.RDNSequence{
+			{{Type: oidDC, Value: "org"}},
+			{{Type: oidDC, Value: "example"}},
+			{{Type: asn1.ObjectIdentifier{2, 5, 4, 11}, Value: "Users"}},
+			{{Type: oidCommonName, Value: "Gopher"}},
+		}),
+		NotBefore: now.Add(-time.Hour),
+		NotAfter:  now.Add(+time.Hour),
+		KeyUsage:  x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}, ca)
+
+	// RFC 3820 proxy, as created by voms-proxy-init.
+	pxy1 = newTstCert(t, &x509.Certificate{
+		RawSubject: proxySubject(t, eec.cert, "1234567890"),
+		NotBefore:  now.Add(-time.Hour),
+		NotAfter:   now.Add(+time.Hour),
+		KeyUsage:   x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtraExtensions: []pkix.Extension{{
+			Id:       oidProxyCertInfo,
+			Critical: true,
+			// ProxyCertInfo{ProxyPolicy{policyLanguage: id-ppl-inheritAll}}
+			Value: []byte{0x30, 0x0c, 0x30, 0x0a, 0x06, 0x08, 0x2b, 0x06, 0x01, 0x05, 0x05, 0x07, 0x15, 0x01},
+		}},
+	}, eec)
+
+	// legacy Globus proxy of the proxy.
+	pxy2 = newTstCert(t, &x509.Certificate{
+		RawSubject: proxySubject(t, pxy1.cert, "proxy"),
+		NotBefore:  now.Add(-time.Hour),
+		NotAfter:   now.Add(+time.Hour),
+		KeyUsage:   x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}, pxy1)
+
+	return ca, eec, pxy1, pxy2
+}
+
+func encodeProxy(t *testing.T, key *ecdsa.PrivateKey, chain ...*tstCert) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("could not marshal key: %v", err)
+	}
+
+	var raw []byte
+	for i, c := range chain {
+		raw = append(raw, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})...)
+		if i == 0 {
+			raw = append(raw, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})...)
+		}
+	}
+	return raw
+}
+
+func TestProxy(t *testing.T) {
+	ca, eec, pxy1, pxy2 := newTstChain(t)
+
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "x509up_u1000")
+	err := os.WriteFile(fname, encodeProxy(t, pxy2.key, pxy2, pxy1, eec), 0600)
+	if err != nil {
+		t.Fatalf("could not write proxy: %v", err)
+	}
+
+	cadir := filepath.Join(dir, "certificates")
+	err = os.Mkdir(cadir, 0755)
+	if err != nil {
+		t.Fatalf("could not create CA dir: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(cadir, "1d879c6c.0"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0644)
+	if err != nil {
+		t.Fatalf("could not write CA: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(cadir, "1d879c6c.signing_policy"), []byte("access_id_CA X509 '/O=Gopher CA/CN=Gopher Root CA'\n"), 0644)
+	if err != nil {
+		t.Fatalf("could not write signing policy: %v", err)
+	}
+
+	t.Setenv("X509_USER_PROXY", fname)
+	t.Setenv("X509_CERT_DIR", cadir)
+
+	p, err := LoadProxy(ProxyPath())
+	if err != nil {
+		t.Fatalf("could not load proxy: %v", err)
+	}
+	roots, err := LoadCAs(CertDir())
+	if err != nil {
+		t.Fatalf("could not load CAs: %v", err)
+	}
+
+	if got, want := len(p.Chain), 3; got != want {
+		t.Fatalf("invalid chain length: got=%d, want=%d", got, want)
+	}
+	if !IsProxy(p.Chain[0]) || !IsProxy(p.Chain[1]) || IsProxy(p.Chain[2]) {
+		t.Fatalf("invalid proxy detection")
+	}
+	if got, want := p.Identity(), "/DC=org/DC=example/OU=Users/CN=Gopher"; got != want {
+		t.Fatalf("invalid identity:\ngot= %q\nwant=%q", got, want)
+	}
+
+	err = p.Verify(roots, tstNow)
+	if err != nil {
+		t.Fatalf("could not verify proxy: %v", err)
+	}
+
+	err = p.Verify(roots, tstNow.Add(2*time.Hour))
+	if err == nil {
+		t.Fatalf("expected an error for an expired proxy")
+	}
+
+	other, _, _, _ := newTstChain(t)
+	others := x509.NewCertPool()
+	others.AddCert(other.cert)
+	err = p.Verify(others, tstNow)
+	if err == nil {
+		t.Fatalf("expected an error for an unknown CA")
+	}
+
+	// proxy signed by the wrong issuer.
+	bad := &Proxy{Chain: []*x509.Certificate{pxy2.cert, eec.cert}, Key: pxy2.key}
+	err = bad.Verify(roots, tstNow)
+	if err == nil {
+		t.Fatalf("expected an error for a wrong issuer")
+	}
+
+	// proxy without its end-entity certificate.
+	bad = &Proxy{Chain: []*x509.Certificate{pxy1.cert}, Key: pxy1.key}
+	err = bad.Verify(roots, tstNow)
+	if err == nil {
+		t.Fatalf("expected an error for a missing end-entity certificate")
+	}
+}
+
+func TestParseProxy(t *testing.T) {
+	_, eec, pxy1, _ := newTstChain(t)
+
+	_, err := ParseProxy(encodeProxy(t, pxy1.key, pxy1, eec))
+	if err != nil {
+		t.Fatalf("could not parse proxy: %v", err)
+	}
+
+	_, err = ParseProxy(encodeProxy(t, eec.key, pxy1, eec))
+	if err == nil {
+		t.Fatalf("expected an error for a mismatched key")
+	}
+
+	_, err = ParseProxy(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: pxy1.cert.Raw}))
+	if err == nil {
+		t.Fatalf("expected an error for a missing key")
+	}
+}
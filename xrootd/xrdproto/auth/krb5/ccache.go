@@ -0,0 +1,155 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package krb5
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/jcmturner/gokrb5/v8/config"
+)
+
+// defaultCCacheName is the name of the credentials cache used when neither
+// $KRB5CCNAME nor the default_ccache_name configuration value are set.
+var defaultCCacheName = "FILE:" + filepath.Join(os.TempDir(), "krb5cc_%{uid}")
+
+// loadConfig loads the Kerberos 5 configuration from the first existing file
+// listed in $KRB5_CONFIG or, if not set, in the default locations.
+// loadConfig returns the configuration and the name of the file it was loaded from.
+func loadConfig() (*config.Config, string, error) {
+	fnames := defaultConfigPaths
+	if v := os.Getenv("KRB5_CONFIG"); v != "" {
+		fnames = filepath.SplitList(v)
+	}
+
+	for _, fname := range fnames {
+		if _, err := os.Stat(fname); err != nil {
+			continue
+		}
+		cfg, err := config.Load(fname)
+		if err != nil {
+			switch err.(type) {
+			case config.UnsupportedDirective:
+				// ok. just ignore it.
+			default:
+				return nil, "", fmt.Errorf("could not load %q: %w", fname, err)
+			}
+		}
+		return cfg, fname, nil
+	}
+
+	return nil, "", fmt.Errorf("could not find configuration file (candidates: %q)", fnames)
+}
+
+// ccacheName returns the name of the default credentials cache, following
+// the same rules as the MIT Kerberos library used by the XRootD C++ client:
+// $KRB5CCNAME, then the default_ccache_name value of the libdefaults section
+// of the cfg configuration file, then FILE:$TMPDIR/krb5cc_<uid>.
+func ccacheName(cfg string) string {
+	if v := os.Getenv("KRB5CCNAME"); v != "" {
+		return v
+	}
+	if v := libdefault(cfg, "default_ccache_name"); v != "" {
+		return v
+	}
+	return defaultCCacheName
+}
+
+// libdefault returns the value of the key entry of the libdefaults section
+// of the cfg configuration file.
+// gokrb5 does not expose entries it does not know about, like default_ccache_name.
+func libdefault(cfg, key string) string {
+	if cfg == "" {
+		return ""
+	}
+	f, err := os.Open(cfg)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var (
+		sc      = bufio.NewScanner(f)
+		section string
+	)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "", line[0] == '#', line[0] == ';':
+			continue
+		case line[0] == '[':
+			section = strings.Trim(line, "[] ")
+			continue
+		case section != "libdefaults":
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(k) != key {
+			continue
+		}
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+// cachePath returns the path to the file holding the credentials of the
+// name credentials cache.
+//
+// Only the file-based cache types are supported:
+//   - FILE:path (or a plain path),
+//   - DIR:dir, where the primary file of dir names the cache file of dir,
+//   - DIR::path, naming a cache file of a directory collection.
+func cachePath(name string) (string, error) {
+	name = expandCCacheName(name)
+
+	typ, res := "FILE", name
+	// a single-letter prefix is a drive letter of a Windows path.
+	if i := strings.Index(name, ":"); i > 1 {
+		typ, res = name[:i], name[i+1:]
+	}
+
+	switch typ {
+	case "FILE":
+		return res, nil
+	case "DIR":
+		if strings.HasPrefix(res, ":") {
+			return res[1:], nil
+		}
+		primary := "tkt"
+		raw, err := os.ReadFile(filepath.Join(res, "primary"))
+		if err == nil {
+			primary = strings.TrimSpace(string(raw))
+		}
+		return filepath.Join(res, primary), nil
+	default:
+		return "", fmt.Errorf("unsupported credentials cache type %q in %q", typ, name)
+	}
+}
+
+// expandCCacheName expands the %{xxx} tokens of a credentials cache name.
+func expandCCacheName(name string) string {
+	if !strings.Contains(name, "%{") {
+		return name
+	}
+
+	var uid, username string
+	if usr, err := user.Current(); err == nil {
+		uid = usr.Uid
+		username = usr.Username
+	}
+
+	return strings.NewReplacer(
+		"%{uid}", uid,
+		"%{euid}", uid,
+		"%{USERID}", uid,
+		"%{username}", username,
+		"%{TEMP}", os.TempDir(),
+		"%{null}", "",
+	).Replace(name)
+}
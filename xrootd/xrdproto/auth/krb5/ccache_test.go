@@ -0,0 +1,108 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package krb5
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "krb5.conf")
+	err := os.WriteFile(fname, []byte(`[libdefaults]
+  default_realm = EXAMPLE.ORG
+  default_ccache_name = DIR:/run/user/%{uid}/krb5cc
+
+[realms]
+  EXAMPLE.ORG = {
+    kdc = kdc.example.org
+  }
+`), 0644)
+	if err != nil {
+		t.Fatalf("could not create configuration file: %v", err)
+	}
+
+	t.Setenv("KRB5_CONFIG", filepath.Join(dir, "not-there.conf")+string(filepath.ListSeparator)+fname)
+	cfg, got, err := loadConfig()
+	if err != nil {
+		t.Fatalf("could not load configuration: %v", err)
+	}
+	if got != fname {
+		t.Fatalf("invalid configuration file: got=%q, want=%q", got, fname)
+	}
+	if got, want := cfg.LibDefaults.DefaultRealm, "EXAMPLE.ORG"; got != want {
+		t.Fatalf("invalid default realm: got=%q, want=%q", got, want)
+	}
+
+	t.Setenv("KRB5CCNAME", "")
+	if got, want := ccacheName(fname), "DIR:/run/user/%{uid}/krb5cc"; got != want {
+		t.Fatalf("invalid ccache name: got=%q, want=%q", got, want)
+	}
+	if got, want := ccacheName(""), defaultCCacheName; got != want {
+		t.Fatalf("invalid default ccache name: got=%q, want=%q", got, want)
+	}
+
+	t.Setenv("KRB5CCNAME", "FILE:/tmp/krb5cc_test")
+	if got, want := ccacheName(fname), "FILE:/tmp/krb5cc_test"; got != want {
+		t.Fatalf("invalid ccache name: got=%q, want=%q", got, want)
+	}
+
+	t.Setenv("KRB5_CONFIG", filepath.Join(dir, "not-there.conf"))
+	_, _, err = loadConfig()
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestCachePath(t *testing.T) {
+	usr, err := user.Current()
+	if err != nil {
+		t.Skipf("could not retrieve current user: %v", err)
+	}
+
+	dir := t.TempDir()
+	coll := filepath.Join(dir, "coll")
+	err = os.Mkdir(coll, 0755)
+	if err != nil {
+		t.Fatalf("could not create collection: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(coll, "primary"), []byte("tktXYZ\n"), 0644)
+	if err != nil {
+		t.Fatalf("could not create primary file: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		want string
+		err  bool
+	}{
+		{name: "/tmp/krb5cc_1000", want: "/tmp/krb5cc_1000"},
+		{name: "FILE:/tmp/krb5cc_1000", want: "/tmp/krb5cc_1000"},
+		{name: "FILE:/tmp/krb5cc_%{uid}", want: "/tmp/krb5cc_" + usr.Uid},
+		{name: "FILE:%{TEMP}/krb5cc_%{USERID}", want: filepath.Join(os.TempDir(), "krb5cc_"+usr.Uid)},
+		{name: "DIR:" + coll, want: filepath.Join(coll, "tktXYZ")},
+		{name: "DIR:" + dir, want: filepath.Join(dir, "tkt")},
+		{name: "DIR::" + filepath.Join(coll, "tktABC"), want: filepath.Join(coll, "tktABC")},
+		{name: `C:\Users\gopher\krb5cc`, want: `C:\Users\gopher\krb5cc`},
+		{name: "KEYRING:persistent:1000", err: true},
+		{name: "KCM:", err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := cachePath(tc.name)
+			switch {
+			case err != nil && !tc.err:
+				t.Fatalf("could not resolve cache path: %v", err)
+			case err == nil && tc.err:
+				t.Fatalf("expected an error")
+			}
+			if got != tc.want {
+				t.Fatalf("invalid cache path: got=%q, want=%q", got, tc.want)
+			}
+		})
+	}
+}
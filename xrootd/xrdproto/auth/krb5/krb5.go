@@ -11,7 +11,6 @@ import (
 	"strings"
 
 	"github.com/jcmturner/gokrb5/v8/client"
-	"github.com/jcmturner/gokrb5/v8/config"
 	"github.com/jcmturner/gokrb5/v8/credentials"
 	"github.com/jcmturner/gokrb5/v8/crypto"
 	"github.com/jcmturner/gokrb5/v8/messages"
@@ -37,7 +36,7 @@ type Auth struct {
 
 // WithPassword creates a new Auth configured from the provided user, realm and password.
 func WithPassword(user, realm, password string) (*Auth, error) {
-	cfg, err := config.Load(configPath)
+	cfg, _, err := loadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("auth/krb5: could not load kerberos-5 configuration: %w", err)
 	}
@@ -53,18 +52,24 @@ func WithPassword(user, realm, password string) (*Auth, error) {
 }
 
 // WithCredCache creates a new Auth configured from cached credentials.
+//
+// As for the XRootD C++ client, the Kerberos 5 configuration is read from
+// $KRB5_CONFIG or the system default location, and the credentials cache is
+// located from $KRB5CCNAME, the default_ccache_name configuration value or
+// the default $TMPDIR/krb5cc_<uid> file.
+// Only file-based (FILE: and DIR:) credentials caches are supported.
 func WithCredCache() (*Auth, error) {
-	cfg, err := config.Load(configPath)
+	cfg, fname, err := loadConfig()
 	if err != nil {
-		switch err.(type) {
-		case config.UnsupportedDirective:
-			// ok. just ignore it.
-		default:
-			return nil, fmt.Errorf("auth/krb5: could not load kerberos-5 configuration: %w", err)
-		}
+		return nil, fmt.Errorf("auth/krb5: could not load kerberos-5 configuration: %w", err)
+	}
+
+	ccache, err := cachePath(ccacheName(fname))
+	if err != nil {
+		return nil, fmt.Errorf("auth/krb5: could not locate kerberos-5 cached credentials: %w", err)
 	}
 
-	cred, err := credentials.LoadCCache(cachePath())
+	cred, err := credentials.LoadCCache(ccache)
 	if err != nil {
 		return nil, fmt.Errorf("auth/krb5: could not load kerberos-5 cached credentials: %w", err)
 	}
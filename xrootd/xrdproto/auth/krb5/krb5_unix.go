@@ -6,33 +6,10 @@
 
 package krb5
 
-import (
-	"fmt"
-	"os"
-	"os/user"
-	"path/filepath"
-	"strings"
-)
-
-// FIXME(sbinet): may be overwritten by $KRB5_CONFIG
-// FIXME(sbinet): Linux puts it at:     /etc/krb5.conf
-//                others may put it at: /etc/krb5/krb5.conf
-
-const configPath = "/etc/krb5.conf"
-
-func cachePath() string {
-	if v := os.Getenv("KRB5CCNAME"); v != "" {
-		if strings.HasPrefix(v, "FILE:") {
-			v = string(v[len("FILE:"):])
-		}
-		return v
-	}
-
-	usr, err := user.Current()
-	if err != nil {
-		return ""
-	}
-
-	v := filepath.Join(os.TempDir(), fmt.Sprintf("krb5cc_%s", usr.Uid))
-	return v
+// defaultConfigPaths are the locations of the Kerberos 5 configuration
+// when $KRB5_CONFIG is not set.
+// Linux puts it at /etc/krb5.conf, others may put it at /etc/krb5/krb5.conf.
+var defaultConfigPaths = []string{
+	"/etc/krb5.conf",
+	"/etc/krb5/krb5.conf",
 }
@@ -0,0 +1,165 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package readv contains the structures describing request and response for readv request.
+// The readv request reads many segments of one or more files in a single round trip.
+// See xrootd protocol specification (http://xrootd.org/doc/dev45/XRdv310.pdf, kXR_readv) for details.
+package readv // import "go-hep.org/x/hep/xrootd/xrdproto/readv"
+
+import (
+	"fmt"
+
+	"go-hep.org/x/hep/xrootd/internal/xrdenc"
+	"go-hep.org/x/hep/xrootd/xrdfs"
+	"go-hep.org/x/hep/xrootd/xrdproto"
+)
+
+// RequestID is the id of the request, it is sent as part of message.
+// See xrootd protocol specification for details: http://xrootd.org/doc/dev45/XRdv310.pdf, 2.3 Client Request Format.
+const RequestID uint16 = 3025
+
+const (
+	// MaxSegments is the maximum number of segments of a single request,
+	// as advertized by the readv_iov_max configuration value of XRootD servers.
+	MaxSegments = 1024
+
+	// MaxSegmentLength is the maximum length of a single segment,
+	// as advertized by the readv_ior_max configuration value of XRootD servers.
+	MaxSegmentLength = 2097136
+)
+
+// segmentLength is the length of a marshaled Segment.
+const segmentLength = 16
+
+// Segment describes a segment of an open file.
+type Segment struct {
+	Handle xrdfs.FileHandle
+	Length int32
+	Offset int64
+}
+
+// MarshalXrd implements xrdproto.Marshaler.
+func (o Segment) MarshalXrd(wBuffer *xrdenc.WBuffer) error {
+	wBuffer.WriteBytes(o.Handle[:])
+	wBuffer.WriteI32(o.Length)
+	wBuffer.WriteI64(o.Offset)
+	return nil
+}
+
+// UnmarshalXrd implements xrdproto.Unmarshaler.
+func (o *Segment) UnmarshalXrd(rBuffer *xrdenc.RBuffer) error {
+	rBuffer.ReadBytes(o.Handle[:])
+	o.Length = rBuffer.ReadI32()
+	o.Offset = rBuffer.ReadI64()
+	return nil
+}
+
+// Request holds readv request parameters.
+type Request struct {
+	_        [15]uint8
+	PathID   xrdproto.PathID
+	Segments []Segment
+}
+
+// ReqID implements xrdproto.Request.ReqID.
+func (req *Request) ReqID() uint16 { return RequestID }
+
+// ShouldSign implements xrdproto.Request.ShouldSign.
+func (req *Request) ShouldSign() bool { return false }
+
+// MarshalXrd implements xrdproto.Marshaler.
+func (o Request) MarshalXrd(wBuffer *xrdenc.WBuffer) error {
+	wBuffer.Next(15)
+	wBuffer.WriteU8(uint8(o.PathID))
+	wBuffer.WriteLen(len(o.Segments) * segmentLength)
+	for _, seg := range o.Segments {
+		err := seg.MarshalXrd(wBuffer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalXrd implements xrdproto.Unmarshaler.
+func (o *Request) UnmarshalXrd(rBuffer *xrdenc.RBuffer) error {
+	rBuffer.Skip(15)
+	o.PathID = xrdproto.PathID(rBuffer.ReadU8())
+	dlen := rBuffer.ReadLen()
+	if dlen < 0 || dlen%segmentLength != 0 || dlen > rBuffer.Len() {
+		return fmt.Errorf("xrootd: invalid readv request length %d", dlen)
+	}
+	o.Segments = make([]Segment, dlen/segmentLength)
+	for i := range o.Segments {
+		err := o.Segments[i].UnmarshalXrd(rBuffer)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Chunk is a segment of a file, with its data.
+type Chunk struct {
+	Segment
+	Data []uint8
+}
+
+// Response is a response for the readv request, which contains the read segments.
+// The length of each segment is the number of bytes actually read, which may be
+// smaller than the requested length at the end of a file.
+type Response struct {
+	Chunks []Chunk
+}
+
+// RespID implements xrdproto.Response.RespID.
+func (resp *Response) RespID() uint16 { return RequestID }
+
+// MarshalXrd implements xrdproto.Marshaler.
+func (o Response) MarshalXrd(wBuffer *xrdenc.WBuffer) error {
+	for _, chunk := range o.Chunks {
+		seg := chunk.Segment
+		seg.Length = int32(len(chunk.Data))
+		err := seg.MarshalXrd(wBuffer)
+		if err != nil {
+			return err
+		}
+		wBuffer.WriteBytes(chunk.Data)
+	}
+	return nil
+}
+
+// UnmarshalXrd implements xrdproto.Unmarshaler.
+//
+// As for read.Response, the data of the chunks already present in o is reused
+// when large enough, so data may be read directly into the caller's buffers.
+func (o *Response) UnmarshalXrd(rBuffer *xrdenc.RBuffer) error {
+	n := 0
+	for rBuffer.Len() > 0 {
+		if rBuffer.Len() < segmentLength {
+			return fmt.Errorf("xrootd: truncated readv response segment")
+		}
+		var seg Segment
+		err := seg.UnmarshalXrd(rBuffer)
+		if err != nil {
+			return err
+		}
+		if seg.Length < 0 || int(seg.Length) > rBuffer.Len() {
+			return fmt.Errorf("xrootd: invalid readv response segment length %d", seg.Length)
+		}
+		if n == len(o.Chunks) {
+			o.Chunks = append(o.Chunks, Chunk{})
+		}
+		chunk := &o.Chunks[n]
+		chunk.Segment = seg
+		if int(seg.Length) > cap(chunk.Data) {
+			chunk.Data = make([]uint8, seg.Length)
+		}
+		chunk.Data = chunk.Data[:seg.Length]
+		rBuffer.ReadBytes(chunk.Data)
+		n++
+	}
+	o.Chunks = o.Chunks[:n]
+	return nil
+}
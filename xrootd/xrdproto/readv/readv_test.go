@@ -0,0 +1,173 @@
+// Copyright ©2022 The go-hep Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package readv_test
+
+import (
+	"reflect"
+	"testing"
+
+	"go-hep.org/x/hep/xrootd/internal/xrdenc"
+	"go-hep.org/x/hep/xrootd/xrdproto/readv"
+)
+
+func TestRequest(t *testing.T) {
+	for _, want := range []readv.Request{
+		{
+			Segments: []readv.Segment{},
+		},
+		{
+			Segments: []readv.Segment{
+				{Handle: [4]byte{1, 2, 3, 4}, Length: 10, Offset: 0},
+				{Handle: [4]byte{1, 2, 3, 4}, Length: 20, Offset: 1 << 40},
+				{Handle: [4]byte{5, 6, 7, 8}, Length: readv.MaxSegmentLength, Offset: 42},
+			},
+		},
+		{
+			PathID: 2,
+			Segments: []readv.Segment{
+				{Handle: [4]byte{1, 2, 3, 4}, Length: 10, Offset: 0},
+			},
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			var (
+				err error
+				w   = new(xrdenc.WBuffer)
+				got readv.Request
+			)
+
+			if want.ReqID() != readv.RequestID {
+				t.Fatalf("invalid request ID: got=%d want=%d", want.ReqID(), readv.RequestID)
+			}
+
+			if want.ShouldSign() {
+				t.Fatalf("invalid")
+			}
+
+			err = want.MarshalXrd(w)
+			if err != nil {
+				t.Fatalf("could not marshal request: %v", err)
+			}
+
+			if got, want := len(w.Bytes()), 20+16*len(want.Segments); got != want {
+				t.Fatalf("invalid request size: got=%d want=%d", got, want)
+			}
+
+			r := xrdenc.NewRBuffer(w.Bytes())
+			err = got.UnmarshalXrd(r)
+			if err != nil {
+				t.Fatalf("could not unmarshal request: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("round trip failed:\ngot = %#v\nwant= %#v\n", got, want)
+			}
+		})
+	}
+}
+
+func TestResponse(t *testing.T) {
+	for _, want := range []readv.Response{
+		{
+			Chunks: []readv.Chunk{},
+		},
+		{
+			Chunks: []readv.Chunk{
+				{
+					Segment: readv.Segment{Handle: [4]byte{1, 2, 3, 4}, Length: 4, Offset: 0},
+					Data:    []byte("1234"),
+				},
+				{
+					Segment: readv.Segment{Handle: [4]byte{1, 2, 3, 4}, Length: 0, Offset: 1024},
+				},
+				{
+					Segment: readv.Segment{Handle: [4]byte{5, 6, 7, 8}, Length: 13, Offset: 42},
+					Data:    []byte("Hello XRootD."),
+				},
+			},
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			var (
+				err error
+				w   = new(xrdenc.WBuffer)
+				got = readv.Response{Chunks: []readv.Chunk{}}
+			)
+
+			if want.RespID() != readv.RequestID {
+				t.Fatalf("invalid response ID: got=%d want=%d", want.RespID(), readv.RequestID)
+			}
+
+			err = want.MarshalXrd(w)
+			if err != nil {
+				t.Fatalf("could not marshal response: %v", err)
+			}
+
+			r := xrdenc.NewRBuffer(w.Bytes())
+			err = got.UnmarshalXrd(r)
+			if err != nil {
+				t.Fatalf("could not unmarshal response: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("round trip failed:\ngot = %#v\nwant= %#v\n", got, want)
+			}
+		})
+	}
+}
+
+func TestResponseReuse(t *testing.T) {
+	var (
+		w    = new(xrdenc.WBuffer)
+		buf  = make([]byte, 8)
+		want = readv.Response{
+			Chunks: []readv.Chunk{
+				{
+					Segment: readv.Segment{Length: 3, Offset: 0},
+					Data:    []byte("abc"),
+				},
+				{
+					Segment: readv.Segment{Length: 2, Offset: 10},
+					Data:    []byte("de"),
+				},
+			},
+		}
+	)
+
+	err := want.MarshalXrd(w)
+	if err != nil {
+		t.Fatalf("could not marshal response: %v", err)
+	}
+
+	got := readv.Response{
+		Chunks: []readv.Chunk{
+			{Data: buf[0:4:4]},
+			{Data: buf[4:8:8]},
+		},
+	}
+	err = got.UnmarshalXrd(xrdenc.NewRBuffer(w.Bytes()))
+	if err != nil {
+		t.Fatalf("could not unmarshal response: %v", err)
+	}
+
+	if got, want := string(buf), "abc\x00de\x00\x00"; got != want {
+		t.Fatalf("data was not read into the provided buffers:\ngot = %q\nwant= %q", got, want)
+	}
+}
+
+func TestResponseInvalid(t *testing.T) {
+	w := new(xrdenc.WBuffer)
+	err := readv.Segment{Length: 10}.MarshalXrd(w)
+	if err != nil {
+		t.Fatalf("could not marshal segment: %v", err)
+	}
+	w.WriteBytes([]byte("12345"))
+
+	var resp readv.Response
+	err = resp.UnmarshalXrd(xrdenc.NewRBuffer(w.Bytes()))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
@@ -64,6 +64,13 @@ const (
 	// OkSoFar indicates that server provides partial response and client should be prepared
 	// to receive additional responses on same stream.
 	OkSoFar ResponseStatus = 4000
+	// Attn indicates an unsolicited response from the server, e.g. the deferred
+	// response to a request that was answered with WaitResp.
+	// The action to take is sent as part of response (see AttnAsyncResponse).
+	Attn ResponseStatus = 4001
+	// AuthMore indicates that the authentication is not complete: the client
+	// must send another kXR_auth request, formed from the data of the response.
+	AuthMore ResponseStatus = 4002
 	// Error indicates that an error occurred during request handling.
 	// Error code and error message are sent as part of response (see xrootd protocol specification v3.1.0, p. 27).
 	Error ResponseStatus = 4003
@@ -71,8 +78,16 @@ const (
 	Redirect ResponseStatus = 4004
 	// Wait indicates that the client must wait the indicated number of seconds and retry the request.
 	Wait ResponseStatus = 4005
+	// WaitResp indicates that the client must wait for the response to the request,
+	// which will be sent later by the server as part of an Attn response.
+	WaitResp ResponseStatus = 4006
 )
 
+// AttnAsyncResponse is the action code of an Attn response that holds the
+// deferred response to a request, including its own response header.
+// See http://xrootd.org/doc/dev45/XRdv310.pdf, kXR_attn and kXR_asynresp, for details.
+const AttnAsyncResponse int32 = 5008
+
 // WaitResponse is the response indicating that the client must wait and retry the request.
 // See http://xrootd.org/doc/dev45/XRdv310.pdf, p. 35 for details.
 type WaitResponse struct {
@@ -105,6 +120,8 @@ const (
 	IOError        ServerErrorCode = 3007 // IOError indicates that an IO error has occurred on the server side.
 	NotAuthorized  ServerErrorCode = 3010 // NotAuthorized indicates that user was not authorized for operation.
 	NotFound       ServerErrorCode = 3011 // NotFound indicates that path was not found on the remote server.
+	Unsupported    ServerErrorCode = 3013 // Unsupported indicates that the requested operation is not supported.
+	ChecksumError  ServerErrorCode = 3019 // ChecksumError indicates that the checksum of the data sent to the server is invalid.
 )
 
 func (err ServerError) Error() string {